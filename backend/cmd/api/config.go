@@ -1,74 +1,22 @@
 package main
 
 import (
-	"fmt"
-	"os"
-)
-
-// Config holds application configuration loaded from environment variables
-type Config struct {
-	// AWS
-	AWSRegion string
-
-	// DynamoDB
-	DynamoDBTableName string
-
-	// S3
-	MediaBucketName string
-
-	// Step Functions
-	StepFunctionsARN string
-
-	// Nixiesearch
-	NixiesearchFunctionName string
-
-	// CloudFront (optional)
-	CloudFrontDomain     string
-	CloudFrontKeyPairID  string
-	CloudFrontPrivateKey string
-
-	// Cognito (for admin operations)
-	CognitoUserPoolID string
-
-	// Server (for local development)
-	ServerPort string
-}
-
-// LoadConfig loads configuration from environment variables
-func LoadConfig() (*Config, error) {
-	cfg := &Config{
-		AWSRegion:               getEnvOrDefault("AWS_REGION", "us-east-1"),
-		DynamoDBTableName:       os.Getenv("DYNAMODB_TABLE_NAME"),
-		MediaBucketName:         os.Getenv("MEDIA_BUCKET"),
-		StepFunctionsARN:        os.Getenv("STEP_FUNCTIONS_ARN"),
-		NixiesearchFunctionName: os.Getenv("NIXIESEARCH_FUNCTION_NAME"),
-		CloudFrontDomain:        os.Getenv("CLOUDFRONT_DOMAIN"),
-		CloudFrontKeyPairID:     os.Getenv("CLOUDFRONT_KEY_PAIR_ID"),
-		CloudFrontPrivateKey:    os.Getenv("CLOUDFRONT_PRIVATE_KEY"),
-		CognitoUserPoolID:       os.Getenv("COGNITO_USER_POOL_ID"),
-		ServerPort:              getEnvOrDefault("PORT", "8080"),
-	}
+	"context"
+	"time"
 
-	// Validate required fields
-	if cfg.DynamoDBTableName == "" {
-		return nil, fmt.Errorf("DYNAMODB_TABLE_NAME environment variable is required")
-	}
-	if cfg.MediaBucketName == "" {
-		return nil, fmt.Errorf("MEDIA_BUCKET environment variable is required")
-	}
-
-	return cfg, nil
-}
+	"github.com/gvasels/personal-music-searchengine/internal/config"
+)
 
-// IsLambda returns true if running in AWS Lambda environment
-func IsLambda() bool {
-	return os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != ""
-}
+// secretCacheTTL bounds how long resolved secrets are reused before re-reading them
+const secretCacheTTL = 15 * time.Minute
 
-// getEnvOrDefault returns the environment variable value or a default
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// LoadConfig loads the API configuration. In Lambda, secrets referenced by name
+// (e.g. CLOUDFRONT_SIGNING_KEY_SECRET) are resolved through the Parameters and
+// Secrets Lambda Extension; locally they must be provided as literal values.
+func LoadConfig(ctx context.Context) (*config.API, error) {
+	var secrets config.SecretLoader
+	if config.IsLambda() {
+		secrets = config.NewCachingSecretLoader(config.NewExtensionSecretLoader(), secretCacheTTL)
 	}
-	return defaultValue
+	return config.LoadAPI(ctx, secrets)
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/gvasels/personal-music-searchengine/internal/capability"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+)
+
+// demoUserID is the seeded account; send it as X-User-ID when calling the demo API
+const demoUserID = "demo-user"
+
+// newDemoServices wires the services to in-memory stores so the API runs with
+// no AWS account or LocalStack. Search, admin and the upload pipeline need AWS
+// and report as disabled on GET /status.
+func newDemoServices(ctx context.Context, capabilities *capability.Registry) *service.Services {
+	repo := repository.NewMemoryRepository()
+	s3Repo := repository.NewMemoryS3Repository("")
+
+	err := repo.CreateUser(ctx, models.User{
+		ID:          demoUserID,
+		Email:       "demo@example.com",
+		DisplayName: "Demo User",
+		Role:        models.RoleSubscriber,
+		Settings:    models.DefaultUserSettings(),
+	})
+	if err != nil {
+		log.Printf("Failed to seed demo user: %v", err)
+	}
+
+	householdSvc := service.NewHouseholdService(repo)
+	libraryRepo := repository.NewLibraryScopedRepository(repo, householdSvc.ResolveLibraryID)
+
+	services := service.NewServices(libraryRepo, s3Repo, nil, "demo-media", "")
+	services.Share = service.NewShareService(repo, s3Repo)
+	services.Household = householdSvc
+	services.SearchHistory = service.NewSearchHistoryService(repo)
+	services.PlayerState = service.NewPlayerStateService(repo)
+	services.Remote = service.NewRemoteService(repo)
+	services.BoostSearch(service.NewSearchBoostService(repo))
+	// With no table stream, collection counts follow the repository's track writes
+	services.Collections = service.NewCollectionService(repo, libraryRepo, s3Repo)
+	repo.ObserveTracks(func(ctx context.Context, before, after *models.Track) {
+		if err := services.Collections.ApplyTrackChange(ctx, before, after); err != nil {
+			log.Printf("Failed to update collection counts: %v", err)
+		}
+	})
+	services.Discover = service.NewDiscoverService(repo, repo, s3Repo)
+	services.LogAccess(service.NewAccessLogService(repo, libraryRepo))
+	services.Comparisons = service.NewLibraryComparisonService(repo, libraryRepo)
+	trash := service.NewTrashService(repo, s3Repo)
+	services.ArchiveSuggestions = service.NewArchiveSuggestionService(repo, libraryRepo, trash)
+	services.RecordOperations(service.NewOperationService(repo, libraryRepo, trash))
+	// Nothing is transcoded or cut in demo mode, so only originals play
+	services.DescribePlayback(service.NewPlaybackAvailabilityService(models.PlaybackFeatures{}))
+	services.CacheUsers(libraryRepo, service.DefaultUserCacheTTL)
+
+	const reason = "not available in demo mode"
+	capabilities.Disable(capability.SignedURLs, reason)
+	capabilities.Disable(capability.UploadProcessing, reason)
+	capabilities.Disable(capability.Search, reason)
+	capabilities.Disable(capability.Admin, reason)
+
+	return services
+}
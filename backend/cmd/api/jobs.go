@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+	"github.com/gvasels/personal-music-searchengine/internal/jobs"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+)
+
+// Job types a self-hosted server runs in-process
+const (
+	// jobArchiveInsights replaces every library's cold track report, the
+	// work of the insights Lambda
+	jobArchiveInsights = "archive-insights"
+	// jobCoverThumbnails makes the cover thumbnails the upload pipeline
+	// failed to store
+	jobCoverThumbnails = "cover-thumbnails"
+	// jobStatsRollup recomputes each user's storage, track and album counts
+	jobStatsRollup = "stats-rollup"
+)
+
+// Job schedules. The insights report matches the insights Lambda's
+// EventBridge schedule; thumbnails run in batches, so more often.
+const (
+	archiveInsightsInterval = 24 * time.Hour
+	coverThumbnailsInterval = time.Hour
+	statsRollupInterval     = 24 * time.Hour
+)
+
+// startJobs runs the worker pool and schedule of a self-hosted server until
+// shutdown, when running jobs are cancelled and left queued to run again
+func startJobs(appCfg *appconfig.API, services *service.Services, server *localServer) {
+	worker := jobs.NewWorker(services.Jobs, appCfg.JobWorkers)
+	scheduler := jobs.NewScheduler(services.Jobs)
+
+	if services.ArchiveSuggestions != nil {
+		worker.Handle(jobArchiveInsights, func(ctx context.Context, job jobs.Job) error {
+			libraries, err := services.ArchiveSuggestions.GenerateAll(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to report cold tracks after %d libraries: %w", libraries, err)
+			}
+			log.Printf("Reported cold tracks of %d libraries", libraries)
+			return nil
+		})
+		scheduler.Every(archiveInsightsInterval, jobArchiveInsights, nil)
+	}
+	if services.CoverThumbnails != nil {
+		worker.Handle(jobCoverThumbnails, func(ctx context.Context, job jobs.Job) error {
+			made, err := services.CoverThumbnails.GenerateMissing(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to make cover thumbnails after %d: %w", made, err)
+			}
+			if made > 0 {
+				log.Printf("Made %d missing cover thumbnails", made)
+			}
+			return nil
+		})
+		scheduler.Every(coverThumbnailsInterval, jobCoverThumbnails, nil)
+	}
+	if services.StatsRollup != nil {
+		worker.Handle(jobStatsRollup, func(ctx context.Context, job jobs.Job) error {
+			updated, err := services.StatsRollup.RollupAll(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to roll up user stats after %d users: %w", updated, err)
+			}
+			log.Printf("Rolled up stats, %d users changed", updated)
+			return nil
+		})
+		scheduler.Every(statsRollupInterval, jobStatsRollup, nil)
+	}
+
+	// Each step of an upload is a job that queues the next
+	if services.UploadPipeline != nil {
+		worker.Handle(service.UploadPipelineJob, services.UploadPipeline.RunStep)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		go scheduler.Run(ctx)
+		worker.Run(ctx)
+	}()
+
+	server.OnShutdown("jobs", func(hookCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-hookCtx.Done():
+			return fmt.Errorf("jobs still running: %w", hookCtx.Err())
+		}
+	})
+}
+
+// runUploadPipeline processes confirmed uploads on the job workers instead of
+// Step Functions, indexing their tracks when search is wired
+func runUploadPipeline(services *service.Services, repo service.UploadPipelineRepository, media service.UploadPipelineMedia) {
+	services.UploadPipeline = service.NewUploadPipelineService(repo, media, services.Jobs)
+	if services.Search != nil {
+		services.UploadPipeline.SetIndexer(services.Search)
+	}
+	if uploadSvc, ok := services.Upload.(*service.UploadServiceImpl); ok {
+		uploadSvc.SetStepFunctionsClient(services.UploadPipeline)
+	}
+}
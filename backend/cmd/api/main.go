@@ -2,95 +2,331 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
-	"os"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	_ "time/tzdata" // provided.al2023 has no zoneinfo; settings.timeZone is validated against it
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	awslambda "github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	echoadapter "github.com/awslabs/aws-lambda-go-api-proxy/echo"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/gvasels/personal-music-searchengine/internal/alerting"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/capability"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
 	"github.com/gvasels/personal-music-searchengine/internal/handlers"
+	authmw "github.com/gvasels/personal-music-searchengine/internal/handlers/middleware"
+	"github.com/gvasels/personal-music-searchengine/internal/jobs"
+	"github.com/gvasels/personal-music-searchengine/internal/logs"
+	"github.com/gvasels/personal-music-searchengine/internal/metrics"
+	"github.com/gvasels/personal-music-searchengine/internal/migrations"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/nixiesearch"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
 	"github.com/gvasels/personal-music-searchengine/internal/search"
 	"github.com/gvasels/personal-music-searchengine/internal/service"
 )
 
-var echoLambda *echoadapter.EchoLambdaV2
+// echoLambda builds the Echo app on the first request rather than in init(), so
+// a configuration error answers 503 and is retried instead of failing every
+// invocation of the sandbox
+var echoLambda = bootstrap.NewLazy("API", func(ctx context.Context) (*echoadapter.EchoLambdaV2, error) {
+	appCfg, err := LoadConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	e, err := setupEcho(appCfg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup Echo: %w", err)
+	}
+	return echoadapter.NewV2(e), nil
+})
 
-func init() {
-	// Initialize in init() for Lambda cold start optimization
-	if IsLambda() {
-		e, err := setupEcho()
-		if err != nil {
-			log.Fatalf("Failed to setup Echo: %v", err)
-		}
-		echoLambda = echoadapter.NewV2(e)
+// handleRequest proxies API Gateway requests to the Echo app
+func handleRequest(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	adapter, err := echoLambda.Get(ctx)
+	if err != nil {
+		log.Printf("Startup failed: %v", err)
+		return bootstrap.UnavailableResponse("api"), nil
 	}
+	return adapter.ProxyWithContext(ctx, req)
+}
+
+// warm builds the Echo app, loading configuration, secrets and AWS clients
+func warm(ctx context.Context) error {
+	_, err := echoLambda.Get(ctx)
+	return err
 }
 
 func main() {
-	if IsLambda() {
-		// Run as Lambda
-		lambda.Start(echoLambda.ProxyWithContext)
+	if appconfig.IsLambda() {
+		// Run as Lambda; warmup events and provisioned-concurrency init load
+		// dependencies ahead of the first request
+		bootstrap.WarmIfProvisioned(warm)
+		lambda.Start(bootstrap.WithWarmup(warm, handleRequest))
 	} else {
 		// Run as HTTP server for local development
-		e, err := setupEcho()
+		appCfg, err := LoadConfig(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+
+		// Self-hosted servers are scraped by Prometheus; Lambda reports to CloudWatch
+		server := newLocalServer(appCfg.MetricsEnabled)
+
+		e, err := setupEcho(appCfg, server)
 		if err != nil {
 			log.Fatalf("Failed to setup Echo: %v", err)
 		}
 
-		cfg, err := LoadConfig()
+		log.Printf("Starting server on port %s", appCfg.ServerPort)
+		if err := server.run(e, ":"+appCfg.ServerPort, appCfg.DrainDelay, appCfg.ShutdownTimeout); err != nil {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}
+}
+
+// setupEcho builds the Echo app; server is nil in Lambda
+func setupEcho(appCfg *appconfig.API, server *localServer) (*echo.Echo, error) {
+	serverMetrics := server.Metrics()
+
+	// Record which optional subsystems could be wired so affected endpoints
+	// answer 503 with a reason and operators can inspect GET /status
+	capabilities := capability.NewRegistry()
+	// and the circuit breakers of the AWS dependencies
+	dependencies := resilience.NewRegistry()
+	// Table scans, bulk edits and index rebuilds get a few slots each so admin
+	// work cannot use up the table's read capacity
+	dependencies.RegisterLimit(resilience.LimitScan, appCfg.ScanConcurrency, appCfg.ConcurrencyWait)
+	dependencies.RegisterLimit(resilience.LimitBulk, appCfg.BulkConcurrency, appCfg.ConcurrencyWait)
+	dependencies.RegisterLimit(resilience.LimitReindex, appCfg.ReindexConcurrency, appCfg.ConcurrencyWait)
+
+	var services *service.Services
+	if appCfg.DemoMode {
+		log.Printf("DEMO_MODE enabled: serving from in-memory stores, data is lost on restart")
+		services = newDemoServices(context.Background(), capabilities)
+	} else {
+		if appCfg.SelfHosted {
+			log.Printf("SELF_HOSTED enabled: search, the upload pipeline and background jobs run in the API process; users sign in with %s", appCfg.AuthIssuer)
+			if appCfg.JobsQueueURL == "" || appCfg.DataStore == appconfig.DataStoreLocal {
+				log.Printf("Background jobs are queued in memory: uploads being processed at shutdown are left processing")
+			}
+		}
+		if appCfg.MediaStore == appconfig.MediaStoreLocal {
+			log.Printf("MEDIA_STORE=local: media is kept in %s and served at %s", appCfg.MediaDir, appCfg.MediaPublicURL)
+		}
+		var err error
+		if appCfg.DataStore == appconfig.DataStoreLocal {
+			log.Printf("DATA_STORE=local: library data is kept in %s, saved every %s", filepath.Join(appCfg.MediaDir, dataSnapshotFile), appCfg.DataSaveInterval)
+			services, err = newLocalServices(appCfg, capabilities, dependencies, serverMetrics, server)
+		} else {
+			services, err = newServices(context.Background(), appCfg, capabilities, dependencies, serverMetrics)
+		}
 		if err != nil {
-			log.Fatalf("Failed to load config: %v", err)
+			return nil, err
+		}
+	}
+
+	// Create handlers
+	h := handlers.NewHandlers(services)
+	h.SetCapabilities(capabilities)
+	h.SetDependencies(dependencies)
+
+	// Create Echo instance
+	e := echo.New()
+	e.HideBanner = true
+	e.Validator = NewValidator()
+	// c.RealIP() keys rate limits and access logs, so it must not believe
+	// an X-Forwarded-For header the client wrote itself
+	trustedProxies, err := authmw.ParseTrustedProxies(appCfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	e.IPExtractor = authmw.ClientIP(trustedProxies)
+
+	// Middleware
+	e.Use(middleware.Logger())
+	e.Use(middleware.Recover())
+	if serverMetrics != nil {
+		e.Use(authmw.Metrics(serverMetrics.ObserveRequest))
+	}
+	e.Use(authmw.SecurityHeaders(authmw.SecurityConfig{
+		HSTSMaxAge:     appCfg.HSTSMaxAge,
+		FrameAncestors: authmw.ParseCORSOrigins(appCfg.FrameAncestors),
+	}))
+	e.Use(authmw.CORS(authmw.CORSConfig{
+		AllowOrigins:     authmw.ParseCORSOrigins(appCfg.CORSAllowOrigins),
+		AllowCredentials: appCfg.CORSAllowCredentials,
+		MaxAge:           appCfg.CORSMaxAge,
+		RouteMethods:     authmw.ParseCORSRouteMethods(appCfg.CORSRouteMethods),
+	}))
+	// CSRF runs after CORS so rejected requests still carry CORS headers
+	e.Use(authmw.CSRF(authmw.CSRFConfig{
+		SessionCookie: appCfg.SessionCookieName,
+		Secure:        appCfg.CSRFCookieSecure,
+		SkipPrefixes:  []string{"/media/"},
+	}))
+	e.Use(authmw.WithRequestContext(service.WithRequestUserCache))
+	// With no API Gateway authorizer in front, a self-hosted server verifies
+	// its users' bearer tokens itself
+	if appCfg.SelfHosted {
+		verifier := authmw.NewTokenVerifier(appCfg.AuthIssuer, appCfg.AuthAudience, appCfg.AuthJWKSURL)
+		e.Use(authmw.VerifyBearerToken(verifier, provisionUsers(services.User)))
+	}
+	if appCfg.MultiTenantMode {
+		e.Use(authmw.RequireTenant(authmw.TenantConfig{
+			ClaimName: appCfg.TenantClaim,
+			Domains:   authmw.ParseTenantDomains(appCfg.TenantDomains),
+			SkipPaths: []string{"/health", "/ready", "/status", "/metrics"},
+		}))
+	}
+	e.Use(authmw.Language(func(ctx context.Context, userID string) string {
+		settings, err := services.User.GetSettings(ctx, userID)
+		if err != nil {
+			return ""
+		}
+		return settings.Language
+	}))
+
+	// Register routes
+	h.RegisterRoutes(e)
+
+	// Register admin routes if admin service is configured
+	if services.Admin != nil {
+		adminHandler := handlers.NewAdminHandler(services.Admin)
+		if services.Migrations != nil {
+			adminHandler.SetMigrations(services.Migrations)
+		}
+		if services.Moderation != nil {
+			adminHandler.SetModeration(services.Moderation)
+		}
+		if services.Search != nil {
+			adminHandler.SetSearchIndex(services.Search)
+			adminHandler.SetSearchIndexTransfer(services.Search)
+		}
+		if services.TrackTransfer != nil {
+			adminHandler.SetTrackTransfer(services.TrackTransfer)
+		}
+		if services.UserImport != nil {
+			adminHandler.SetUserImport(services.UserImport)
+		}
+		if services.Discover != nil {
+			adminHandler.SetFeatured(services.Discover)
+		}
+		if services.UploadDebug != nil {
+			adminHandler.SetUploadDebug(services.UploadDebug)
 		}
+		adminHandler.SetBulkLimiter(dependencies.Limiter(resilience.LimitBulk))
+		// Create a role resolver that checks the database for real-time role updates
+		roleResolver := services.User.GetUserRole
+		handlers.RegisterAdminRoutes(e, adminHandler, roleResolver)
+	}
+
+	// Health check endpoint
+	e.GET("/health", func(c echo.Context) error {
+		return c.JSON(200, map[string]string{"status": "ok"})
+	})
+
+	// Files of a local media store, authorized by the token of their signed
+	// URL rather than by a user
+	if services.MediaFiles != nil {
+		e.Match([]string{http.MethodGet, http.MethodHead, http.MethodPut}, "/media/*",
+			echo.WrapHandler(http.StripPrefix("/media", services.MediaFiles)))
+	}
+
+	// Prometheus scrape endpoint, served without auth like /health
+	if serverMetrics != nil {
+		e.GET("/metrics", echo.WrapHandler(serverMetrics.Registry.Handler()))
+	}
 
-		log.Printf("Starting server on port %s", cfg.ServerPort)
-		if err := e.Start(":" + cfg.ServerPort); err != nil {
-			log.Fatalf("Failed to start server: %v", err)
+	// Readiness turns 503 while the plain HTTP server drains on shutdown
+	if server != nil {
+		e.GET("/ready", server.ready)
+		server.OnShutdown("services", services.Close)
+		// Jobs stop before the services they use are closed
+		if services.Jobs != nil {
+			startJobs(appCfg, services, server)
 		}
 	}
+
+	return e, nil
 }
 
-func setupEcho() (*echo.Echo, error) {
-	// Load configuration
-	appCfg, err := LoadConfig()
+// searchIndexDir is where a self-hosted server with a local media store keeps
+// the search index, inside MEDIA_DIR; object keys never start with a dot
+const searchIndexDir = ".search-index"
+
+// mediaStore is a repository.MediaStore that can also list object versions
+// for the upload debug bundle and read and write whole objects for the
+// thumbnail job, as every store the API selects can
+type mediaStore interface {
+	repository.MediaStore
+	service.ObjectVersionLister
+	service.ObjectReadWriter
+}
+
+// newMediaStore selects the media store of MEDIA_STORE. MinIO is an S3 bucket
+// behind S3_ENDPOINT, so it shares the S3 store and its clients.
+func newMediaStore(appCfg *appconfig.API, objectClient repository.S3Client, presignClient repository.S3PresignClient) (mediaStore, error) {
+	if appCfg.MediaStore != appconfig.MediaStoreLocal {
+		return repository.NewS3Repository(objectClient, presignClient, appCfg.MediaBucketName), nil
+	}
+	keys, err := reqsign.ParseKeyring(appCfg.MediaSigningKeys)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("MEDIA_SIGNING_KEYS: %w", err)
 	}
+	return repository.NewFileMediaStore(appCfg.MediaDir, appCfg.MediaPublicURL, keys)
+}
 
+// newServices wires the services to DynamoDB, S3 and the optional AWS
+// integrations, recording which of those could be configured
+func newServices(ctx context.Context, appCfg *appconfig.API, capabilities *capability.Registry, dependencies *resilience.Registry, serverMetrics *metrics.Server) (*service.Services, error) {
 	// Load AWS configuration
-	ctx := context.Background()
 	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(appCfg.AWSRegion))
 	if err != nil {
 		return nil, err
 	}
 
 	// Check for LocalStack endpoint (local development)
-	localEndpoint := os.Getenv("AWS_ENDPOINT")
+	localEndpoint := appCfg.AWSEndpoint
+
+	// DynamoDB and S3 default to the LocalStack endpoint; self-hosted servers
+	// may point them at DynamoDB Local and MinIO instead
+	dynamoClient := dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+		if appCfg.DynamoDBEndpoint != "" {
+			o.BaseEndpoint = &appCfg.DynamoDBEndpoint
+		}
+	})
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if appCfg.S3Endpoint != "" {
+			o.BaseEndpoint = &appCfg.S3Endpoint
+			o.UsePathStyle = true
+		}
+	})
 
-	// Create AWS clients with optional LocalStack endpoint
-	var dynamoClient *dynamodb.Client
-	var s3Client *s3.Client
+	// Create the other AWS clients with optional LocalStack endpoint
 	var sfnClient *sfn.Client
 	var lambdaClient *awslambda.Client
 	var cognitoClient *cognitoidentityprovider.Client
 
 	if localEndpoint != "" {
 		// LocalStack configuration
-		dynamoClient = dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
-			o.BaseEndpoint = &localEndpoint
-		})
-		s3Client = s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-			o.BaseEndpoint = &localEndpoint
-			o.UsePathStyle = true
-		})
 		sfnClient = sfn.NewFromConfig(awsCfg, func(o *sfn.Options) {
 			o.BaseEndpoint = &localEndpoint
 		})
@@ -101,80 +337,257 @@ func setupEcho() (*echo.Echo, error) {
 			o.BaseEndpoint = &localEndpoint
 		})
 	} else {
-		dynamoClient = dynamodb.NewFromConfig(awsCfg)
-		s3Client = s3.NewFromConfig(awsCfg)
 		sfnClient = sfn.NewFromConfig(awsCfg)
 		lambdaClient = awslambda.NewFromConfig(awsCfg)
 		cognitoClient = cognitoidentityprovider.NewFromConfig(awsCfg)
 	}
 
+	// DynamoDB, S3 and search calls retry and circuit-break through the
+	// resilience layer, so their SDK clients make a single attempt each
+	policy := resilience.Policy{
+		MaxAttempts:      appCfg.RetryMaxAttempts,
+		BaseDelay:        appCfg.RetryBaseDelay,
+		MaxDelay:         appCfg.RetryMaxDelay,
+		MinAttemptTime:   100 * time.Millisecond,
+		BreakerThreshold: appCfg.BreakerThreshold,
+		BreakerCooldown:  appCfg.BreakerCooldown,
+		Retryable:        resilience.AWSRetryable,
+		Failure:          resilience.AWSFailure,
+	}
+	searchPolicy := policy
+	searchPolicy.BreakerThreshold = appCfg.SearchBreakerThreshold
+	searchPolicy.BreakerCooldown = appCfg.SearchBreakerCooldown
+	searchPolicy.Failure = search.Failure
+	dynamoClient = dynamodb.New(dynamoClient.Options(), func(o *dynamodb.Options) { o.Retryer = aws.NopRetryer{} })
+	s3Client = s3.New(s3Client.Options(), func(o *s3.Options) { o.Retryer = aws.NopRetryer{} })
+	searchLambdaClient := awslambda.New(lambdaClient.Options(), func(o *awslambda.Options) { o.Retryer = aws.NopRetryer{} })
+
+	// In multi-tenant mode every partition key and object key is prefixed with
+	// the request's tenant, so the repositories below stay tenant-unaware
+	var tableClient repository.DynamoDBClient = dynamoClient
+	var objectClient repository.S3Client = s3Client
+	var presignClient repository.S3PresignClient = s3.NewPresignClient(s3Client)
+	if serverMetrics != nil {
+		tableClient = repository.NewInstrumentedDynamoDBClient(tableClient, serverMetrics.ObserveStoreCall)
+		objectClient = repository.NewInstrumentedS3Client(objectClient, serverMetrics.ObserveStoreCall)
+	}
+	tableClient = repository.NewResilientDynamoDBClient(tableClient, dependencies.Register("dynamodb", policy))
+	tableClient = repository.NewScanLimitedDynamoDBClient(tableClient, dependencies.Limiter(resilience.LimitScan))
+	objectClient = repository.NewResilientS3Client(objectClient, dependencies.Register("s3", policy))
+	if appCfg.MultiTenantMode {
+		tableClient = repository.NewTenantDynamoDBClient(tableClient)
+		objectClient = repository.NewTenantS3Client(objectClient)
+		presignClient = repository.NewTenantS3PresignClient(presignClient)
+	}
+
 	// Create repositories
-	repo := repository.NewDynamoDBRepository(dynamoClient, appCfg.DynamoDBTableName)
-	s3Repo := repository.NewS3Repository(s3Client, s3.NewPresignClient(s3Client), appCfg.MediaBucketName)
+	repo := repository.NewDynamoDBRepository(tableClient, appCfg.DynamoDBTableName)
+	media, err := newMediaStore(appCfg, objectClient, presignClient)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create CloudFront signer (optional)
 	var cloudfront repository.CloudFrontSigner
-	if appCfg.CloudFrontDomain != "" && appCfg.CloudFrontKeyPairID != "" && appCfg.CloudFrontPrivateKey != "" {
+	if appCfg.CloudFrontEnabled() {
 		// CloudFront signer would be initialized here
 		// For now, we use S3 presigned URLs as fallback
 		cloudfront = nil
 	}
+	if cloudfront != nil && appCfg.MultiTenantMode {
+		cloudfront = repository.NewTenantCloudFrontSigner(cloudfront)
+	}
+	capabilities.Set(capability.SignedURLs, cloudfront != nil, "CloudFront signing not configured; serving S3 presigned URLs")
+
+	// Household members share one library partition; the scoped repository maps
+	// each user to their library before touching tracks, albums, tags or uploads
+	householdSvc := service.NewHouseholdService(repo)
+	libraryRepo := repository.NewLibraryScopedRepository(repo, householdSvc.ResolveLibraryID)
 
 	// Create services
 	services := service.NewServices(
-		repo,
-		s3Repo,
+		libraryRepo,
+		media,
 		cloudfront,
 		appCfg.MediaBucketName,
 		appCfg.StepFunctionsARN,
 	)
+	// A local store answers its own presigned URLs
+	if files, ok := media.(http.Handler); ok {
+		services.MediaFiles = files
+	}
 
-	// Set Step Functions client on upload service
+	// Set Step Functions client on upload service; self-hosted servers run
+	// the pipeline on their job workers instead, wired below
 	if uploadSvc, ok := services.Upload.(*service.UploadServiceImpl); ok {
-		sfnAdapter := service.NewSFNClientAdapter(sfnClient)
-		uploadSvc.SetStepFunctionsClient(sfnAdapter)
+		if appCfg.StepFunctionsARN != "" && !appCfg.SelfHosted {
+			uploadSvc.SetStepFunctionsClient(service.NewSFNClientAdapter(sfnClient))
+		}
+
+		// Uploads refused over a storage limit are reported when an alert topic is set
+		alertCfg := awsCfg.Copy()
+		if localEndpoint != "" {
+			alertCfg.BaseEndpoint = &localEndpoint
+		}
+		uploadSvc.SetAlerter(alerting.New(alerting.NewSNSPublisher(alertCfg), appCfg.AlertTopicARN))
+	}
+	// Support's upload debug bundle reads each source it is given and
+	// reports the rest as missing; it finds uploads through their library
+	services.UploadDebug = service.NewUploadDebugService(libraryRepo, media)
+	if appCfg.StepFunctionsARN != "" {
+		services.UploadDebug.SetExecutions(service.NewSFNClientAdapter(sfnClient), appCfg.StepFunctionsARN)
+	}
+	if appCfg.PipelineLogGroups != "" {
+		logsCfg := awsCfg.Copy()
+		if localEndpoint != "" {
+			logsCfg.BaseEndpoint = &localEndpoint
+		}
+		services.UploadDebug.SetLogs(logs.NewReader(logsCfg), strings.Split(appCfg.PipelineLogGroups, ","))
 	}
 
-	// Initialize search service if Nixiesearch function name is configured
-	if appCfg.NixiesearchFunctionName != "" {
-		searchClient := search.NewClient(lambdaClient, appCfg.NixiesearchFunctionName)
-		services.Search = service.NewSearchService(searchClient, repo, s3Repo)
+	// Track sharing needs share persistence beyond the core repository interface
+	services.Share = service.NewShareService(repo, media)
+	services.Household = householdSvc
+	services.SearchHistory = service.NewSearchHistoryService(repo)
+	services.PlayerState = service.NewPlayerStateService(repo)
+	services.Remote = service.NewRemoteService(repo)
+	// Preview share links, party links and listening room invites are signed
+	// with their own keyring. Links read tracks unscoped, since the person
+	// opening one need not have an account; rooms play the host's library.
+	if appCfg.PreviewSigningKeys != "" {
+		previewKeys, err := reqsign.ParseKeyring(appCfg.PreviewSigningKeys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PREVIEW_SIGNING_KEYS: %w", err)
+		}
+		services.Previews = service.NewPreviewService(repo, media, cloudfront, previewKeys)
+		services.Party = service.NewPartyService(repo, services.PlayerState, previewKeys)
+		services.Rooms = service.NewListeningRoomService(repo, libraryRepo, media, cloudfront, previewKeys)
 	}
 
-	// Initialize admin service if Cognito User Pool ID is configured
-	if appCfg.CognitoUserPoolID != "" {
-		cognitoSvc := service.NewCognitoClient(cognitoClient, appCfg.CognitoUserPoolID)
-		services.Admin = service.NewAdminService(repo, cognitoSvc)
+	// Initialize search service if Nixiesearch function name is configured.
+	// Self-hosted servers run the search engine in-process instead, keeping
+	// its index in SEARCH_INDEX_BUCKET, or beside the media of a local store.
+	var searchClient *search.Client
+	if appCfg.SelfHosted {
+		alertCfg := awsCfg.Copy()
+		if localEndpoint != "" {
+			alertCfg.BaseEndpoint = &localEndpoint
+		}
+		var indexStore nixiesearch.Store = nixiesearch.NewS3Store(s3Client, appCfg.SearchIndexBucket)
+		indexLocation := appCfg.SearchIndexBucket
+		if appCfg.MediaStore == appconfig.MediaStoreLocal {
+			indexLocation = filepath.Join(appCfg.MediaDir, searchIndexDir)
+			indexStore = nixiesearch.NewDirStore(indexLocation)
+		}
+		nixiesearch.Configure(indexStore, indexLocation, alerting.New(alerting.NewSNSPublisher(alertCfg), appCfg.AlertTopicARN))
+		searchClient = search.NewInProcessClient(nixiesearch.HandleRequest)
+	} else if appCfg.NixiesearchFunctionName != "" {
+		searchClient = search.NewClient(searchLambdaClient, appCfg.NixiesearchFunctionName)
+	}
+	if searchClient != nil {
+		searchClient.SetTimeout(appCfg.SearchTimeout)
+		searchClient.SetDependency(dependencies.Register("search", searchPolicy))
+		if serverMetrics != nil {
+			searchClient.SetObserver(serverMetrics.ObserveSearch)
+		}
+		services.Search = service.NewSearchService(searchClient, libraryRepo, media)
+		services.UploadDebug.SetSearchIndex(searchClient)
+		if limited, ok := services.Search.(service.ReindexLimitAware); ok {
+			limited.SetReindexLimiter(dependencies.Limiter(resilience.LimitReindex))
+		}
 	}
+	capabilities.Set(capability.Search, services.Search != nil, "NIXIESEARCH_FUNCTION_NAME or SELF_HOSTED not set")
 
-	// Create handlers
-	h := handlers.NewHandlers(services)
+	// Personal pins and artist boosts reorder Search results once it is wired
+	services.BoostSearch(service.NewSearchBoostService(repo))
 
-	// Create Echo instance
-	e := echo.New()
-	e.HideBanner = true
-	e.Validator = NewValidator()
+	// Collections are kept per library; the collections Lambda adjusts their
+	// counts from the table's stream as tracks change
+	services.Collections = service.NewCollectionService(repo, libraryRepo, media)
 
-	// Middleware
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	// Admins' featured items lead the discover feed; featured playlists live in
+	// their owners' partitions, so the feed reads the unscoped repository
+	services.Discover = service.NewDiscoverService(repo, repo, media)
 
-	// Register routes
-	h.RegisterRoutes(e)
+	// Track, playlist and search responses say which playback options clients
+	// can offer: HLS needs CloudFront signing and preview clips their keyring
+	services.DescribePlayback(service.NewPlaybackAvailabilityService(models.PlaybackFeatures{
+		HLSStreaming: cloudfront != nil,
+		Previews:     services.Previews != nil,
+	}))
 
-	// Register admin routes if admin service is configured
-	if services.Admin != nil {
-		adminHandler := handlers.NewAdminHandler(services.Admin)
-		// Create a role resolver that checks the database for real-time role updates
-		roleResolver := services.User.GetUserRole
-		handlers.RegisterAdminRoutes(e, adminHandler, roleResolver)
+	// Public visibility changes wait for the moderation Lambda when configured.
+	// Reviews are global, so the service reads tracks unscoped.
+	if appCfg.ModerationFunctionName != "" {
+		dispatcher := service.NewLambdaModerationDispatcher(lambdaClient, appCfg.ModerationFunctionName)
+		services.Moderate(service.NewModerationService(repo, dispatcher))
 	}
+	capabilities.Set(capability.Moderation, services.Moderation != nil, "MODERATION_FUNCTION_NAME not set")
 
-	// Health check endpoint
-	e.GET("/health", func(c echo.Context) error {
-		return c.JSON(200, map[string]string{"status": "ok"})
-	})
+	// Protected tracks are handed to other users as watermarked copies made by
+	// the watermark Lambda; without it their downloads are refused
+	if appCfg.WatermarkFunctionName != "" {
+		dispatcher := service.NewLambdaWatermarkDispatcher(lambdaClient, appCfg.WatermarkFunctionName)
+		services.ProtectDownloads(service.NewWatermarkService(repo, libraryRepo, media, dispatcher))
+	}
+	capabilities.Set(capability.DownloadProtection, services.Watermarks != nil, "WATERMARK_FUNCTION_NAME not set")
 
-	return e, nil
+	// Streams, downloads and preview link opens of tracks from outside their
+	// library are logged in the library's partition with running counters
+	services.LogAccess(service.NewAccessLogService(repo, libraryRepo))
+
+	// Users who both consent can compare their household libraries
+	services.Comparisons = service.NewLibraryComparisonService(repo, libraryRepo)
+
+	// The insights Lambda reports each library's cold tracks daily; libraries
+	// it has not reached yet are reported on request
+	trash := service.NewTrashService(repo, media)
+	services.ArchiveSuggestions = service.NewArchiveSuggestionService(repo, libraryRepo, trash)
+
+	// Bulk edits keep an undo record under the acting user for
+	// models.UndoWindow; deleted tracks wait in the trash as long
+	services.RecordOperations(service.NewOperationService(repo, libraryRepo, trash))
+
+	// Self-hosted servers run uploads and scheduled work such as the insights
+	// report, missing thumbnails and user stats on their own workers, from an
+	// SQS queue when several servers share it
+	if appCfg.SelfHosted {
+		if appCfg.JobsQueueURL != "" {
+			sqsClient := sqs.NewFromConfig(awsCfg, func(o *sqs.Options) {
+				if localEndpoint != "" {
+					o.BaseEndpoint = &localEndpoint
+				}
+			})
+			services.Jobs = jobs.NewSQSQueue(sqsClient, appCfg.JobsQueueURL, 0)
+		} else {
+			services.Jobs = jobs.NewMemoryQueue(0)
+		}
+		services.CoverThumbnails = service.NewCoverThumbnailService(repo, media)
+		services.StatsRollup = service.NewStatsRollupService(repo)
+		// Uploads are stored under their library, which the pipeline is given
+		runUploadPipeline(services, repo, media)
+	}
+	capabilities.Set(capability.UploadProcessing, appCfg.StepFunctionsARN != "" || services.UploadPipeline != nil, "STEP_FUNCTIONS_ARN not set")
+
+	// Admins move tracks between libraries; the new owner is reindexed when search is wired
+	services.TrackTransfer = service.NewTrackTransferService(libraryRepo, media)
+	if services.Search != nil {
+		services.TrackTransfer.SetIndexer(services.Search)
+	}
+
+	// Initialize admin service if Cognito User Pool ID is configured
+	if appCfg.CognitoUserPoolID != "" {
+		cognitoSvc := service.NewCognitoClient(cognitoClient, appCfg.CognitoUserPoolID)
+		services.Admin = service.NewAdminService(repo, cognitoSvc)
+		services.UserImport = service.NewUserImportService(repo, cognitoSvc)
+		// Migrations run over the whole table, so status reads it unscoped
+		services.Migrations = migrations.NewRunner(repo, repo)
+	}
+	capabilities.Set(capability.Admin, services.Admin != nil, "COGNITO_USER_POOL_ID not set")
+
+	// Role checks read users through a cache; admin role changes on this
+	// instance evict immediately, other instances within USER_CACHE_TTL
+	services.CacheUsers(libraryRepo, appCfg.UserCacheTTL)
+
+	return services, nil
 }
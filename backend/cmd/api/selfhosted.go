@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/capability"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+	authmw "github.com/gvasels/personal-music-searchengine/internal/handlers/middleware"
+	"github.com/gvasels/personal-music-searchengine/internal/jobs"
+	"github.com/gvasels/personal-music-searchengine/internal/metrics"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/nixiesearch"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
+	"github.com/gvasels/personal-music-searchengine/internal/search"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+)
+
+// dataSnapshotFile is where a local data store is saved, inside MEDIA_DIR
+// beside the search index; object keys never start with a dot
+const dataSnapshotFile = ".library.gob"
+
+// newLocalServices wires the services of a self-hosted server that keeps
+// everything on its own disk: library data in memory, saved to a snapshot
+// file as it changes, media in a local store, and search, uploads and
+// scheduled work in-process. It needs no AWS account. Features backed by
+// Lambdas or Cognito report as disabled on GET /status.
+func newLocalServices(appCfg *appconfig.API, capabilities *capability.Registry, dependencies *resilience.Registry, serverMetrics *metrics.Server, server *localServer) (*service.Services, error) {
+	snapshotPath := filepath.Join(appCfg.MediaDir, dataSnapshotFile)
+	repo, err := repository.OpenMemoryRepository(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("DATA_STORE=local: %w", err)
+	}
+	// A local media store has no S3 clients
+	media, err := newMediaStore(appCfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	keepSnapshots(repo, snapshotPath, appCfg.DataSaveInterval, server)
+
+	householdSvc := service.NewHouseholdService(repo)
+	libraryRepo := repository.NewLibraryScopedRepository(repo, householdSvc.ResolveLibraryID)
+
+	services := service.NewServices(libraryRepo, media, nil, appCfg.MediaBucketName, "")
+	if files, ok := media.(http.Handler); ok {
+		services.MediaFiles = files
+	}
+	services.UploadDebug = service.NewUploadDebugService(libraryRepo, media)
+	services.Share = service.NewShareService(repo, media)
+	services.Household = householdSvc
+	services.SearchHistory = service.NewSearchHistoryService(repo)
+	services.PlayerState = service.NewPlayerStateService(repo)
+	services.Remote = service.NewRemoteService(repo)
+	if appCfg.PreviewSigningKeys != "" {
+		previewKeys, err := reqsign.ParseKeyring(appCfg.PreviewSigningKeys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PREVIEW_SIGNING_KEYS: %w", err)
+		}
+		services.Previews = service.NewPreviewService(repo, media, nil, previewKeys)
+		services.Party = service.NewPartyService(repo, services.PlayerState, previewKeys)
+		services.Rooms = service.NewListeningRoomService(repo, libraryRepo, media, nil, previewKeys)
+	}
+
+	// The search index is kept on disk beside the media
+	indexLocation := filepath.Join(appCfg.MediaDir, searchIndexDir)
+	nixiesearch.Configure(nixiesearch.NewDirStore(indexLocation), indexLocation, nil)
+	searchClient := search.NewInProcessClient(nixiesearch.HandleRequest)
+	searchClient.SetTimeout(appCfg.SearchTimeout)
+	if serverMetrics != nil {
+		searchClient.SetObserver(serverMetrics.ObserveSearch)
+	}
+	services.Search = service.NewSearchService(searchClient, libraryRepo, media)
+	services.UploadDebug.SetSearchIndex(searchClient)
+	if limited, ok := services.Search.(service.ReindexLimitAware); ok {
+		limited.SetReindexLimiter(dependencies.Limiter(resilience.LimitReindex))
+	}
+	services.BoostSearch(service.NewSearchBoostService(repo))
+
+	// With no table stream, collection counts follow the repository's track writes
+	services.Collections = service.NewCollectionService(repo, libraryRepo, media)
+	repo.ObserveTracks(func(ctx context.Context, before, after *models.Track) {
+		if err := services.Collections.ApplyTrackChange(ctx, before, after); err != nil {
+			log.Printf("Failed to update collection counts: %v", err)
+		}
+	})
+	services.Discover = service.NewDiscoverService(repo, repo, media)
+	// Nothing is transcoded in-process, so originals and preview clips play
+	services.DescribePlayback(service.NewPlaybackAvailabilityService(models.PlaybackFeatures{
+		Previews: services.Previews != nil,
+	}))
+	services.LogAccess(service.NewAccessLogService(repo, libraryRepo))
+	services.Comparisons = service.NewLibraryComparisonService(repo, libraryRepo)
+	trash := service.NewTrashService(repo, media)
+	services.ArchiveSuggestions = service.NewArchiveSuggestionService(repo, libraryRepo, trash)
+	services.RecordOperations(service.NewOperationService(repo, libraryRepo, trash))
+
+	// One server holds the data, so its jobs need no shared queue
+	services.Jobs = jobs.NewMemoryQueue(0)
+	services.CoverThumbnails = service.NewCoverThumbnailService(repo, media)
+	services.StatsRollup = service.NewStatsRollupService(repo)
+	runUploadPipeline(services, repo, media)
+
+	services.TrackTransfer = service.NewTrackTransferService(libraryRepo, media)
+	services.TrackTransfer.SetIndexer(services.Search)
+	services.CacheUsers(libraryRepo, appCfg.UserCacheTTL)
+
+	const reason = "not available with DATA_STORE=local"
+	capabilities.Disable(capability.SignedURLs, reason)
+	capabilities.Set(capability.UploadProcessing, true, "")
+	capabilities.Set(capability.Search, true, "")
+	capabilities.Disable(capability.Moderation, reason)
+	capabilities.Disable(capability.DownloadProtection, reason)
+	capabilities.Disable(capability.Admin, reason)
+
+	return services, nil
+}
+
+// keepSnapshots saves repo to path every interval when it changed, and a last
+// time once the server has drained and its jobs have stopped. A crash loses
+// at most the writes of one interval.
+func keepSnapshots(repo *repository.MemoryRepository, path string, interval time.Duration, server *localServer) {
+	saved := repo.Writes()
+	save := func() error {
+		writes := repo.Writes()
+		if writes == saved {
+			return nil
+		}
+		if err := repo.SaveSnapshot(path); err != nil {
+			return err
+		}
+		saved = writes
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := save(); err != nil {
+					log.Printf("Failed to save library data: %v", err)
+				}
+			}
+		}
+	}()
+
+	// Registered before the jobs, so it runs after them
+	server.OnShutdown("data", func(context.Context) error {
+		cancel()
+		<-done
+		return save()
+	})
+}
+
+// provisionUsers creates the user of a verified token on their first
+// request, as the post-confirmation trigger does for Cognito sign-ups
+func provisionUsers(users service.UserService) func(context.Context, *authmw.TokenClaims) error {
+	var known sync.Map
+	return func(ctx context.Context, claims *authmw.TokenClaims) error {
+		if _, ok := known.Load(claims.Subject); ok {
+			return nil
+		}
+		if _, err := users.CreateUserFromCognito(ctx, claims.Subject, claims.Email, claims.Name); err != nil {
+			return fmt.Errorf("failed to set up user: %w", err)
+		}
+		known.Store(claims.Subject, struct{}{})
+		return nil
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/gvasels/personal-music-searchengine/internal/metrics"
+)
+
+// cleanupTimeout bounds the shutdown hooks run after requests have drained
+const cleanupTimeout = 5 * time.Second
+
+// localServer is the state of the API when it runs as a plain HTTP server
+// rather than in Lambda: its metrics, readiness and shutdown hooks. setupEcho
+// receives nil in Lambda.
+type localServer struct {
+	// metrics is nil when METRICS_ENABLED=false
+	metrics  *metrics.Server
+	draining atomic.Bool
+
+	mu    sync.Mutex
+	hooks []shutdownHook
+}
+
+type shutdownHook struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// newLocalServer creates the server state, with metrics when enabled
+func newLocalServer(metricsEnabled bool) *localServer {
+	s := &localServer{}
+	if metricsEnabled {
+		s.metrics = metrics.NewServer()
+	}
+	return s
+}
+
+// Metrics returns the server metrics, or nil in Lambda or when disabled
+func (s *localServer) Metrics() *metrics.Server {
+	if s == nil {
+		return nil
+	}
+	return s.metrics
+}
+
+// OnShutdown registers fn to run once in-flight requests have drained.
+// Hooks run in reverse registration order, like defers. Safe on nil (Lambda).
+func (s *localServer) OnShutdown(name string, fn func(context.Context) error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// ready handles GET /ready: 200 while serving, 503 once draining so load
+// balancers stop sending new requests. /health stays 200 until exit.
+func (s *localServer) ready(c echo.Context) error {
+	if s.draining.Load() {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// run serves e on addr until SIGINT or SIGTERM, then drains: /ready reports
+// 503 for drainDelay while requests (and a last metrics scrape) are still
+// served, then the listener closes and in-flight requests get up to timeout
+// to finish before their connections are closed. Shutdown hooks run last.
+// A second signal during the drain exits immediately.
+func (s *localServer) run(e *echo.Echo, addr string, drainDelay, timeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- e.Start(addr)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	// Restore default signal handling so a second signal kills the process
+	stop()
+
+	log.Printf("Shutdown signal received, draining for %s", drainDelay)
+	s.draining.Store(true)
+	time.Sleep(drainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := e.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Printf("In-flight requests did not finish within %s, closing connections: %v", timeout, err)
+		if closeErr := e.Close(); closeErr != nil {
+			log.Printf("Failed to close server: %v", closeErr)
+		}
+	}
+	if stopErr := <-serveErr; stopErr != nil && !errors.Is(stopErr, http.ErrServerClosed) {
+		log.Printf("Server stopped with error: %v", stopErr)
+	}
+
+	s.runHooks()
+	log.Printf("Server stopped")
+	return err
+}
+
+// runHooks runs the shutdown hooks in reverse order, logging failures
+func (s *localServer) runHooks() {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	hooks := append([]shutdownHook(nil), s.hooks...)
+	s.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			log.Printf("Shutdown hook %s failed: %v", hooks[i].name, err)
+		}
+	}
+}
@@ -2,9 +2,10 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
-	"os"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
@@ -12,56 +13,91 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
 	"github.com/gvasels/personal-music-searchengine/internal/clients"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
 	"github.com/gvasels/personal-music-searchengine/internal/handlers"
+	authmw "github.com/gvasels/personal-music-searchengine/internal/handlers/middleware"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
 )
 
-var echoLambda *echoadapter.EchoLambdaV2
+// echoLambda builds the Echo app on the first request rather than in init(), so
+// a configuration error answers 503 and is retried instead of failing every
+// invocation of the sandbox
+var echoLambda = bootstrap.NewLazy("gateway", func(ctx context.Context) (*echoadapter.EchoLambdaV2, error) {
+	gatewayCfg, err := loadConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	e, err := setupEcho(gatewayCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup Echo: %w", err)
+	}
+	return echoadapter.NewV2(e), nil
+})
 
-func init() {
-	// Initialize in init() for Lambda cold start optimization
-	if isLambda() {
-		e, err := setupEcho()
-		if err != nil {
-			log.Fatalf("Failed to setup Echo: %v", err)
-		}
-		echoLambda = echoadapter.NewV2(e)
+// handleRequest proxies API Gateway requests to the Echo app
+func handleRequest(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	adapter, err := echoLambda.Get(ctx)
+	if err != nil {
+		log.Printf("Startup failed: %v", err)
+		return bootstrap.UnavailableResponse("gateway"), nil
 	}
+	return adapter.ProxyWithContext(ctx, req)
+}
+
+// warm builds the Echo app, loading configuration, secrets and AWS clients
+func warm(ctx context.Context) error {
+	_, err := echoLambda.Get(ctx)
+	return err
 }
 
 func main() {
-	if isLambda() {
-		// Run as Lambda
-		lambda.Start(echoLambda.ProxyWithContext)
+	if appconfig.IsLambda() {
+		// Run as Lambda; warmup events and provisioned-concurrency init load
+		// dependencies ahead of the first request
+		bootstrap.WarmIfProvisioned(warm)
+		lambda.Start(bootstrap.WithWarmup(warm, handleRequest))
 	} else {
 		// Run as HTTP server for local development
-		e, err := setupEcho()
+		gatewayCfg, err := loadConfig(context.Background())
 		if err != nil {
-			log.Fatalf("Failed to setup Echo: %v", err)
+			log.Fatalf("Failed to load config: %v", err)
 		}
 
-		port := os.Getenv("PORT")
-		if port == "" {
-			port = "8081"
+		e, err := setupEcho(gatewayCfg)
+		if err != nil {
+			log.Fatalf("Failed to setup Echo: %v", err)
 		}
 
-		log.Printf("Starting Bedrock Gateway server on port %s", port)
-		if err := e.Start(":" + port); err != nil {
+		log.Printf("Starting Bedrock Gateway server on port %s", gatewayCfg.ServerPort)
+		if err := e.Start(":" + gatewayCfg.ServerPort); err != nil {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}
 }
 
-func setupEcho() (*echo.Echo, error) {
+// loadConfig loads the gateway configuration. In Lambda the API key may be
+// referenced by name (API_KEY_SECRET or API_KEY_PARAM) and is resolved through
+// the Parameters and Secrets Lambda Extension.
+func loadConfig(ctx context.Context) (*appconfig.Gateway, error) {
+	var secrets appconfig.SecretLoader
+	if appconfig.IsLambda() {
+		secrets = appconfig.NewExtensionSecretLoader()
+	}
+	return appconfig.LoadGateway(ctx, secrets)
+}
+
+func setupEcho(gatewayCfg *appconfig.Gateway) (*echo.Echo, error) {
 	ctx := context.Background()
 
-	// Load AWS configuration
-	region := os.Getenv("AWS_REGION")
-	if region == "" {
-		region = "us-east-1"
+	signingKeys, err := reqsign.ParseKeyring(gatewayCfg.SigningKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing keys: %w", err)
 	}
 
-	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	// Load AWS configuration
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(gatewayCfg.AWSRegion))
 	if err != nil {
 		return nil, err
 	}
@@ -85,10 +121,15 @@ func setupEcho() (*echo.Echo, error) {
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
 
+	// Internal services authenticate with signed requests; without an API key
+	// for external clients, signatures are required
+	if len(signingKeys) > 0 {
+		e.Use(authmw.VerifySignature(reqsign.NewVerifier(signingKeys, 0), gatewayCfg.APIKey == "", "/health"))
+	}
+
 	// API key authentication middleware (optional)
-	apiKey := os.Getenv("API_KEY")
-	if apiKey != "" {
-		e.Use(apiKeyAuth(apiKey))
+	if gatewayCfg.APIKey != "" {
+		e.Use(apiKeyAuth(gatewayCfg.APIKey))
 	}
 
 	// Register gateway routes
@@ -102,18 +143,12 @@ func setupEcho() (*echo.Echo, error) {
 	return e, nil
 }
 
-// isLambda returns true if running in AWS Lambda
-func isLambda() bool {
-	return os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" ||
-		os.Getenv("LAMBDA_TASK_ROOT") != ""
-}
-
 // apiKeyAuth creates middleware for API key authentication
 func apiKeyAuth(validKey string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// Skip health check
-			if c.Path() == "/health" {
+			// Skip health check and requests already authenticated by signature
+			if c.Path() == "/health" || authmw.IsSignedRequest(c) {
 				return next(c)
 			}
 
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+)
+
+// Word lists the synthetic library is built from. Titles and artists combine
+// them so that common words match thousands of tracks and rare combinations
+// match a handful, which is the mix real searches see.
+var (
+	adjectives = []string{
+		"midnight", "golden", "electric", "silent", "neon", "broken", "velvet", "crystal",
+		"endless", "hidden", "wild", "lonely", "burning", "frozen", "distant", "restless",
+		"hollow", "bright", "secret", "faded", "northern", "paper", "silver", "slow",
+	}
+	nouns = []string{
+		"drive", "river", "heart", "city", "dream", "signal", "horizon", "echo",
+		"garden", "highway", "mirror", "ocean", "storm", "shadow", "harbor", "fire",
+		"window", "orbit", "season", "letter", "machine", "summer", "station", "tide",
+	}
+	artistWords = []string{
+		"coast", "lights", "theory", "collective", "union", "society", "club", "brothers",
+		"parade", "engine", "avenue", "district", "project", "kids", "choir", "motel",
+	}
+	genres = []string{
+		"Electronic", "House", "Techno", "Ambient", "Rock", "Indie", "Pop", "Hip-Hop",
+		"Jazz", "Soul", "Drum & Bass", "Synthwave",
+	}
+)
+
+// library is a deterministic synthetic music library for one user
+type library struct {
+	UserID  string
+	Tracks  []models.Track
+	Artists []string
+}
+
+// generateLibrary builds n tracks for userID. The same seed always produces
+// the same library, so runs against different builds are comparable.
+func generateLibrary(userID string, n int, seed int64) *library {
+	rng := rand.New(rand.NewSource(seed))
+
+	// Roughly 12 tracks per artist and album, like a typical collection
+	artistCount := n/12 + 1
+	artists := make([]string, artistCount)
+	for i := range artists {
+		artists[i] = titleCase(pick(rng, adjectives) + " " + pick(rng, artistWords))
+	}
+
+	now := time.Now()
+	tracks := make([]models.Track, n)
+	for i := range tracks {
+		artist := artists[rng.Intn(len(artists))]
+		// UUID-shaped so the search Lambda accepts the documents
+		id := fmt.Sprintf("00000000-0000-4000-8000-%012d", i)
+		tracks[i] = models.Track{
+			ID:         id,
+			UserID:     userID,
+			Title:      titleCase(pick(rng, adjectives) + " " + pick(rng, nouns)),
+			Artist:     artist,
+			Album:      titleCase(pick(rng, nouns) + " " + pick(rng, nouns)),
+			Genre:      pick(rng, genres),
+			Year:       1970 + rng.Intn(56),
+			Duration:   120 + rng.Intn(360),
+			BPM:        70 + rng.Intn(110),
+			Format:     models.AudioFormatMP3,
+			S3Key:      fmt.Sprintf("media/%s/%s.mp3", userID, id),
+			Visibility: models.VisibilityPrivate,
+			Timestamps: models.Timestamps{CreatedAt: now, UpdatedAt: now},
+		}
+	}
+
+	return &library{UserID: userID, Tracks: tracks, Artists: artists}
+}
+
+// documents converts the library to search documents the way the indexer does
+func (l *library) documents() []searchproto.Document {
+	docs := make([]searchproto.Document, len(l.Tracks))
+	for i, track := range l.Tracks {
+		docs[i] = searchproto.Document{
+			ID:        track.ID,
+			UserID:    track.UserID,
+			Title:     track.Title,
+			Artist:    track.Artist,
+			Album:     track.Album,
+			Genre:     track.Genre,
+			Year:      track.Year,
+			Duration:  track.Duration,
+			Bitrate:   track.Bitrate,
+			Filename:  track.S3Key,
+			IndexedAt: track.CreatedAt,
+		}
+	}
+	return docs
+}
+
+// searchQueries returns the queries the search scenario cycles through:
+// single common words, full titles, artist names and a misspelling.
+func (l *library) searchQueries(rng *rand.Rand, n int) []string {
+	queries := make([]string, n)
+	for i := range queries {
+		track := l.Tracks[rng.Intn(len(l.Tracks))]
+		switch i % 4 {
+		case 0:
+			queries[i] = pick(rng, nouns)
+		case 1:
+			queries[i] = track.Title
+		case 2:
+			queries[i] = track.Artist
+		default:
+			queries[i] = misspell(rng, pick(rng, adjectives))
+		}
+	}
+	return queries
+}
+
+func pick(rng *rand.Rand, words []string) string {
+	return words[rng.Intn(len(words))]
+}
+
+// misspell swaps two adjacent letters to exercise fuzzy matching
+func misspell(rng *rand.Rand, word string) string {
+	if len(word) < 3 {
+		return word
+	}
+	b := []byte(word)
+	i := 1 + rng.Intn(len(b)-2)
+	b[i], b[i+1] = b[i+1], b[i]
+	return string(b)
+}
+
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,236 @@
+// Package main implements a load generator for the search and list endpoints.
+//
+// It builds a deterministic synthetic library (100k tracks by default), writes
+// it to DynamoDB, bulk-indexes it through the Nixiesearch Lambda, and then
+// drives concurrent search and list scenarios against a running API, printing
+// latency percentiles per scenario. Runs with the same -seed and -tracks are
+// comparable across builds.
+//
+//	DYNAMODB_TABLE_NAME=MusicLibrary AWS_ENDPOINT=http://localhost:4566 \
+//	NIXIESEARCH_FUNCTION_NAME=nixiesearch \
+//	go run ./cmd/loadtest -api http://localhost:8080 -tracks 100000
+//
+// Pass -skip-seed/-skip-index to reuse a library from an earlier run.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	awslambda "github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/search"
+)
+
+type options struct {
+	apiURL         string
+	token          string
+	userID         string
+	tracks         int
+	seed           int64
+	concurrency    int
+	requests       int
+	scenarios      string
+	pageSize       int
+	maxPages       int
+	batchSize      int
+	skipSeed       bool
+	skipIndex      bool
+	region         string
+	endpoint       string
+	tableName      string
+	searchFunction string
+}
+
+func parseFlags() options {
+	var o options
+	flag.StringVar(&o.apiURL, "api", appconfig.GetEnvOrDefault("LOADTEST_API_URL", "http://localhost:8080"), "base URL of the API under test")
+	flag.StringVar(&o.token, "token", os.Getenv("LOADTEST_TOKEN"), "bearer token for a deployed API (optional)")
+	flag.StringVar(&o.userID, "user", "loadtest-user", "user that owns the synthetic library")
+	flag.IntVar(&o.tracks, "tracks", 100000, "number of synthetic tracks")
+	flag.Int64Var(&o.seed, "seed", 1, "seed for the library and query mix")
+	flag.IntVar(&o.concurrency, "concurrency", 16, "concurrent workers per scenario")
+	flag.IntVar(&o.requests, "requests", 2000, "operations per scenario")
+	flag.StringVar(&o.scenarios, "scenarios", "search,list", "comma-separated scenarios to run (search, list)")
+	flag.IntVar(&o.pageSize, "page-size", 50, "page size for search and list requests")
+	flag.IntVar(&o.maxPages, "max-pages", 10, "deepest page a list operation follows")
+	flag.IntVar(&o.batchSize, "batch", 500, "documents per bulk index call")
+	flag.BoolVar(&o.skipSeed, "skip-seed", false, "do not write tracks to DynamoDB")
+	flag.BoolVar(&o.skipIndex, "skip-index", false, "do not bulk-index the library")
+	flag.StringVar(&o.region, "region", appconfig.GetEnvOrDefault("AWS_REGION", "us-east-1"), "AWS region")
+	flag.StringVar(&o.endpoint, "endpoint", os.Getenv("AWS_ENDPOINT"), "AWS endpoint override (LocalStack)")
+	flag.StringVar(&o.tableName, "table", os.Getenv("DYNAMODB_TABLE_NAME"), "DynamoDB table to seed")
+	flag.StringVar(&o.searchFunction, "search-function", os.Getenv("NIXIESEARCH_FUNCTION_NAME"), "Nixiesearch Lambda to bulk-index into")
+	flag.Parse()
+	return o
+}
+
+func main() {
+	o := parseFlags()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	lib := generateLibrary(o.userID, o.tracks, o.seed)
+	log.Printf("Generated %d tracks by %d artists for %s", len(lib.Tracks), len(lib.Artists), lib.UserID)
+
+	if err := prepare(ctx, o, lib); err != nil {
+		log.Fatalf("Failed to prepare library: %v", err)
+	}
+
+	api := &apiClient{
+		baseURL: strings.TrimSuffix(o.apiURL, "/"),
+		userID:  o.userID,
+		token:   o.token,
+		http: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{MaxIdleConnsPerHost: o.concurrency},
+		},
+	}
+
+	rng := rand.New(rand.NewSource(o.seed))
+	available := map[string]scenario{
+		"search": searchScenario(lib.searchQueries(rng, 500), o.pageSize),
+		"list":   listScenario(o.pageSize, o.maxPages),
+	}
+
+	var results []*result
+	for _, name := range strings.Split(o.scenarios, ",") {
+		s, ok := available[strings.TrimSpace(name)]
+		if !ok {
+			log.Fatalf("Unknown scenario %q", name)
+		}
+		log.Printf("Running %s: %d operations, %d workers", s.Name, o.requests, o.concurrency)
+		results = append(results, runScenario(ctx, api, s, o.requests, o.concurrency, o.seed))
+	}
+
+	fmt.Println()
+	writeReport(os.Stdout, results)
+}
+
+// prepare writes the library to DynamoDB and the search index unless skipped
+func prepare(ctx context.Context, o options, lib *library) error {
+	if o.skipSeed && o.skipIndex {
+		return nil
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(o.region))
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	if !o.skipSeed {
+		if o.tableName == "" {
+			return errors.New("-table (DYNAMODB_TABLE_NAME) is required to seed; pass -skip-seed to reuse existing data")
+		}
+		client := dynamodb.NewFromConfig(awsCfg, func(opts *dynamodb.Options) {
+			if o.endpoint != "" {
+				opts.BaseEndpoint = &o.endpoint
+			}
+		})
+		repo := repository.NewDynamoDBRepository(client, o.tableName)
+		if err := seedTracks(ctx, repo, lib, o.concurrency); err != nil {
+			return err
+		}
+	}
+
+	if !o.skipIndex {
+		if o.searchFunction == "" {
+			return errors.New("-search-function (NIXIESEARCH_FUNCTION_NAME) is required to index; pass -skip-index to reuse the existing index")
+		}
+		client := awslambda.NewFromConfig(awsCfg, func(opts *awslambda.Options) {
+			if o.endpoint != "" {
+				opts.BaseEndpoint = &o.endpoint
+			}
+		})
+		if err := indexLibrary(ctx, search.NewClient(client, o.searchFunction), lib, o.batchSize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedTracks writes every track with concurrent PutItem calls. Tracks left by
+// an earlier run with the same seed are kept.
+func seedTracks(ctx context.Context, repo *repository.DynamoDBRepository, lib *library, concurrency int) error {
+	start := time.Now()
+	var (
+		next     int64 = -1
+		existing int64
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := atomic.AddInt64(&next, 1)
+				if i >= int64(len(lib.Tracks)) || ctx.Err() != nil {
+					return
+				}
+				err := repo.CreateTrack(ctx, lib.Tracks[i])
+				var condErr *types.ConditionalCheckFailedException
+				if errors.As(err, &condErr) {
+					atomic.AddInt64(&existing, 1)
+					continue
+				}
+				if err != nil {
+					once.Do(func() { firstErr = err })
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return fmt.Errorf("failed to seed tracks: %w", firstErr)
+	}
+	log.Printf("Seeded %d tracks (%d already present) in %s", len(lib.Tracks), existing, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// indexLibrary bulk-indexes the library in batches
+func indexLibrary(ctx context.Context, client *search.Client, lib *library, batchSize int) error {
+	start := time.Now()
+	docs := lib.documents()
+	indexed := 0
+
+	for i := 0; i < len(docs); i += batchSize {
+		end := i + batchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+		resp, err := client.BulkIndex(ctx, docs[i:end])
+		if err != nil {
+			return fmt.Errorf("failed to index documents %d-%d: %w", i, end, err)
+		}
+		indexed += resp.Indexed
+		for _, failure := range resp.Failures {
+			log.Printf("Document %d not indexed: %s", i+failure.Index, failure.Error)
+		}
+	}
+
+	log.Printf("Indexed %d documents in %s", indexed, time.Since(start).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// scenario issues one logical operation against the API. A single call may
+// make several HTTP requests (list follows cursors), and its latency is the
+// latency of the whole operation.
+type scenario struct {
+	Name string
+	Run  func(ctx context.Context, api *apiClient, rng *rand.Rand) error
+}
+
+// apiClient calls the REST API as one user. Outside Lambda the API takes the
+// caller from X-User-ID; a bearer token is sent as well when targeting a
+// deployed API behind the JWT authorizer.
+type apiClient struct {
+	baseURL string
+	userID  string
+	token   string
+	http    *http.Client
+}
+
+func (a *apiClient) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-User-ID", a.userID)
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GET %s: %d %s", path, resp.StatusCode, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// searchScenario runs GET /api/v1/search with queries drawn from the library
+func searchScenario(queries []string, limit int) scenario {
+	return scenario{
+		Name: "search",
+		Run: func(ctx context.Context, api *apiClient, rng *rand.Rand) error {
+			var resp struct {
+				TotalResults int `json:"totalResults"`
+			}
+			query := url.Values{
+				"q":     {queries[rng.Intn(len(queries))]},
+				"limit": {fmt.Sprint(limit)},
+			}
+			return api.get(ctx, "/api/v1/search", query, &resp)
+		},
+	}
+}
+
+// listScenario pages through GET /api/v1/tracks, following up to maxPages
+// cursors so that deep pagination is part of the measurement
+func listScenario(pageSize, maxPages int) scenario {
+	return scenario{
+		Name: "list",
+		Run: func(ctx context.Context, api *apiClient, rng *rand.Rand) error {
+			pages := 1 + rng.Intn(maxPages)
+			cursor := ""
+			for i := 0; i < pages; i++ {
+				var resp struct {
+					NextCursor string `json:"nextCursor"`
+					HasMore    bool   `json:"hasMore"`
+				}
+				query := url.Values{"limit": {fmt.Sprint(pageSize)}}
+				if cursor != "" {
+					query.Set("lastKey", cursor)
+				}
+				if err := api.get(ctx, "/api/v1/tracks", query, &resp); err != nil {
+					return err
+				}
+				if !resp.HasMore {
+					return nil
+				}
+				cursor = resp.NextCursor
+			}
+			return nil
+		},
+	}
+}
+
+// result collects the latencies of one scenario run
+type result struct {
+	Scenario  string
+	Latencies []time.Duration
+	Errors    int64
+	FirstErr  error
+	Elapsed   time.Duration
+}
+
+// runScenario executes s total times across concurrency workers
+func runScenario(ctx context.Context, api *apiClient, s scenario, total, concurrency int, seed int64) *result {
+	var (
+		next      int64 = -1
+		errCount  int64
+		mu        sync.Mutex
+		firstErr  error
+		latencies = make([]time.Duration, 0, total)
+		wg        sync.WaitGroup
+	)
+
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed + int64(worker)))
+			local := make([]time.Duration, 0, total/concurrency+1)
+
+			for atomic.AddInt64(&next, 1) < int64(total) {
+				if ctx.Err() != nil {
+					break
+				}
+				began := time.Now()
+				err := s.Run(ctx, api, rng)
+				if err != nil {
+					atomic.AddInt64(&errCount, 1)
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					continue
+				}
+				local = append(local, time.Since(began))
+			}
+
+			mu.Lock()
+			latencies = append(latencies, local...)
+			mu.Unlock()
+		}(w)
+	}
+	wg.Wait()
+
+	return &result{
+		Scenario:  s.Name,
+		Latencies: latencies,
+		Errors:    errCount,
+		FirstErr:  firstErr,
+		Elapsed:   time.Since(start),
+	}
+}
+
+// stats summarizes a latency distribution
+type stats struct {
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// summarize computes percentiles using the nearest-rank method
+func summarize(latencies []time.Duration) stats {
+	if len(latencies) == 0 {
+		return stats{}
+	}
+
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, l := range sorted {
+		sum += l
+	}
+
+	return stats{
+		Count: len(sorted),
+		Mean:  sum / time.Duration(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P90:   percentile(sorted, 90),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the p-th percentile of an ascending slice
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// writeReport prints one row per scenario
+func writeReport(w io.Writer, results []*result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "scenario\tok\terrors\treq/s\tmean\tp50\tp90\tp95\tp99\tmax\t")
+	for _, r := range results {
+		s := summarize(r.Latencies)
+		throughput := 0.0
+		if r.Elapsed > 0 {
+			throughput = float64(s.Count) / r.Elapsed.Seconds()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			r.Scenario, s.Count, r.Errors, throughput,
+			ms(s.Mean), ms(s.P50), ms(s.P90), ms(s.P95), ms(s.P99), ms(s.Max))
+	}
+	tw.Flush()
+
+	for _, r := range results {
+		if r.FirstErr != nil {
+			fmt.Fprintf(w, "%s: first error: %v\n", r.Scenario, r.FirstErr)
+		}
+	}
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
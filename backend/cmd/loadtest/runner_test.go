@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateLibrary(t *testing.T) {
+	a := generateLibrary("u1", 200, 7)
+	b := generateLibrary("u1", 200, 7)
+	require.Len(t, a.Tracks, 200)
+	assert.Equal(t, a.Tracks[123].Title, b.Tracks[123].Title, "same seed must give the same library")
+	assert.NotEqual(t, a.Tracks[0].Title, generateLibrary("u1", 200, 8).Tracks[0].Title)
+
+	ids := make(map[string]bool)
+	for _, track := range a.Tracks {
+		assert.Equal(t, "u1", track.UserID)
+		assert.NotEmpty(t, track.Title)
+		assert.NotEmpty(t, track.Artist)
+		ids[track.ID] = true
+	}
+	assert.Len(t, ids, 200, "track IDs must be unique")
+
+	docs := a.documents()
+	require.Len(t, docs, 200)
+	assert.Equal(t, a.Tracks[5].S3Key, docs[5].Filename)
+}
+
+func TestSummarize(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[len(latencies)-1-i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	s := summarize(latencies)
+	assert.Equal(t, 100, s.Count)
+	assert.Equal(t, 50*time.Millisecond, s.P50)
+	assert.Equal(t, 90*time.Millisecond, s.P90)
+	assert.Equal(t, 99*time.Millisecond, s.P99)
+	assert.Equal(t, 100*time.Millisecond, s.Max)
+	assert.Equal(t, 50500*time.Microsecond, s.Mean)
+	assert.Equal(t, 100*time.Millisecond, latencies[0], "input must not be reordered")
+
+	assert.Equal(t, stats{}, summarize(nil))
+	assert.Equal(t, time.Second, summarize([]time.Duration{time.Second}).P99)
+}
+
+func TestScenarios(t *testing.T) {
+	var listCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "u1", r.Header.Get("X-User-ID"))
+		switch r.URL.Path {
+		case "/api/v1/search":
+			if r.URL.Query().Get("q") == "fail" {
+				http.Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]int{"totalResults": 1})
+		case "/api/v1/tracks":
+			listCalls++
+			page, _ := strconv.Atoi(r.URL.Query().Get("lastKey"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"nextCursor": strconv.Itoa(page + 1),
+				"hasMore":    page < 2,
+			})
+		}
+	}))
+	defer server.Close()
+
+	api := &apiClient{baseURL: server.URL, userID: "u1", http: server.Client()}
+	ctx := context.Background()
+
+	t.Run("list follows cursors until the last page", func(t *testing.T) {
+		require.NoError(t, listScenario(10, 100).Run(ctx, api, rand.New(rand.NewSource(1))))
+		assert.LessOrEqual(t, listCalls, 3)
+	})
+
+	t.Run("failures are counted", func(t *testing.T) {
+		r := runScenario(ctx, api, searchScenario([]string{"ok", "fail"}, 20), 40, 4, 1)
+		assert.Equal(t, int64(40), int64(len(r.Latencies))+r.Errors)
+		assert.Positive(t, r.Errors)
+		require.Error(t, r.FirstErr)
+		assert.Contains(t, r.FirstErr.Error(), "500")
+
+		var out bytes.Buffer
+		writeReport(&out, []*result{r})
+		assert.Contains(t, out.String(), "search")
+		assert.Contains(t, out.String(), "first error")
+	})
+}
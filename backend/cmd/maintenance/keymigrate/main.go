@@ -0,0 +1,101 @@
+// Package main implements the key-prefix migration Lambda, an admin
+// maintenance task that moves S3 objects to a new key layout and repoints the
+// tracks that reference them.
+//
+// Invoke it with a named migration (see service.KeyMigrations) or a custom
+// one, and reinvoke with the returned nextCursor until done is true:
+//
+//	{"name": "covers-by-track", "batchSize": 200, "maxBatches": 5, "dryRun": true}
+//	{"migration": {"field": "s3Key", "from": "media/{userId}/{trackId}{ext}", "to": "audio/{userId}/{trackId}{ext}"}}
+//
+// Runs are idempotent, so a failed or timed-out invocation is retried from
+// the last cursor it returned (or from the start).
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+)
+
+// Event selects the migration and the batch to run
+type Event struct {
+	// Name selects a migration from service.KeyMigrations; when empty the
+	// request's migration is used as given
+	Name string `json:"name,omitempty"`
+	service.KeyMigrationRequest
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// batchTimeReserve is the time left for the last batch before the Lambda times out
+const batchTimeReserve = 30 * time.Second
+
+// deps builds the AWS clients on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
+
+// setup builds the migration service over the media bucket
+func setup(ctx context.Context) (*service.KeyMigrationService, error) {
+	cfg, err := deps.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s3Client, err := deps.S3(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := deps.Repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	migrationRepo, ok := repo.(service.KeyMigrationRepository)
+	if !ok {
+		return nil, fmt.Errorf("repository does not support key migrations")
+	}
+	// Copies and deletes only; nothing is presigned
+	s3Repo := repository.NewS3Repository(s3Client, nil, cfg.MediaBucketName)
+	return service.NewKeyMigrationService(migrationRepo, s3Repo), nil
+}
+
+func handleRequest(ctx context.Context, event Event) (*service.KeyMigrationResult, error) {
+	ctx = tenant.WithID(ctx, event.TenantID)
+
+	svc, err := setup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := event.KeyMigrationRequest
+	if event.Name != "" {
+		migration, ok := service.KeyMigrations[event.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown key migration %q", event.Name)
+		}
+		req.Migration = migration
+	}
+
+	// Stop starting new batches shortly before the Lambda times out; the
+	// returned cursor resumes where this run stopped
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Until = deadline.Add(-batchTimeReserve)
+	}
+
+	result, err := svc.Run(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Key migration %s: scanned %d, moved %d, skipped %d, conflicts %d, errors %d, done %t\n",
+		result.Migration, result.Scanned, result.Moved, result.Skipped, result.Conflicts, len(result.Errors), result.Done)
+	return result, nil
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
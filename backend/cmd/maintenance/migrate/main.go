@@ -0,0 +1,78 @@
+// Package main implements the data migration Lambda, an admin maintenance
+// task that runs the versioned DynamoDB migrations of internal/migrations.
+//
+// Each invocation resumes every pending migration from its checkpoint and
+// stops shortly before the Lambda times out; reinvoke until done is true:
+//
+//	{"dryRun": true}
+//	{"target": 2, "batchSize": 500}
+//
+// Progress is also reported by GET /api/v1/admin/migrations.
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/migrations"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+)
+
+// Event selects the migrations to run
+type Event struct {
+	migrations.RunOptions
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// batchTimeReserve is the time left for the last batch before the Lambda times out
+const batchTimeReserve = 30 * time.Second
+
+// deps builds the AWS clients on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
+
+// migrationRepository is what the runner needs from the repository
+type migrationRepository interface {
+	migrations.Repository
+	migrations.StateStore
+}
+
+func handleRequest(ctx context.Context, event Event) (*migrations.RunResult, error) {
+	ctx = tenant.WithID(ctx, event.TenantID)
+
+	repo, err := deps.Repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	migrationRepo, ok := repo.(migrationRepository)
+	if !ok {
+		return nil, fmt.Errorf("repository does not support data migrations")
+	}
+
+	// Stop starting new batches shortly before the Lambda times out; the
+	// checkpoints resume where this run stopped
+	opts := event.RunOptions
+	if deadline, ok := ctx.Deadline(); ok {
+		opts.Until = deadline.Add(-batchTimeReserve)
+	}
+
+	result, err := migrations.NewRunner(migrationRepo, migrationRepo).Run(ctx, opts)
+	if result != nil {
+		for _, state := range result.Migrations {
+			fmt.Printf("Migration %d %s: %s, %d batches, scanned %d, updated %d, dry run %t\n",
+				state.Version, state.Name, state.Status, state.Batches, state.Scanned, state.Updated, result.DryRun)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
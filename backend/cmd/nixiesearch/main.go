@@ -1,153 +1,30 @@
-// Package main implements the Nixiesearch Lambda function.
-// This is a simple search engine with S3-based index storage.
-// For MVP, uses in-memory index with S3 persistence.
+// Package main implements the Nixiesearch Lambda function: the search engine
+// of internal/nixiesearch with its index persisted to the index bucket.
 package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
-	"sort"
-	"strings"
-	"sync"
-	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/gvasels/personal-music-searchengine/internal/alerting"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+	"github.com/gvasels/personal-music-searchengine/internal/nixiesearch"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
 )
 
 var (
-	s3Client    *s3.Client
-	indexBucket string
-	indexPath   string
-	index       *SearchIndex
-	indexMutex  sync.RWMutex
+	appCfg      = appconfig.LoadNixiesearch()
 	initialized bool
 )
 
-// SearchIndex holds the in-memory search index
-type SearchIndex struct {
-	Documents map[string]Document `json:"documents"`
-	UpdatedAt time.Time           `json:"updatedAt"`
-}
-
-// Document represents a searchable track
-type Document struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"userId"`
-	Title     string    `json:"title"`
-	Artist    string    `json:"artist"`
-	Album     string    `json:"album"`
-	Genre     string    `json:"genre"`
-	Year      int       `json:"year"`
-	Duration  int       `json:"duration"`
-	Filename  string    `json:"filename"`
-	IndexedAt time.Time `json:"indexedAt"`
-}
-
-// Request represents the incoming Lambda request
-type Request struct {
-	Operation string      `json:"operation"` // search, index, delete, bulk_index
-	Payload   interface{} `json:"payload"`
-}
-
-// Response represents the Lambda response
-type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-}
-
-// SearchQuery represents a search request
-type SearchQuery struct {
-	Query   string        `json:"query"`
-	Filters SearchFilters `json:"filters"`
-	Sort    *SortOption   `json:"sort,omitempty"`
-	Limit   int           `json:"limit"`
-	Cursor  string        `json:"cursor"`
-}
-
-// SearchFilters for narrowing results
-type SearchFilters struct {
-	UserID   string `json:"userId"`
-	Artist   string `json:"artist"`
-	Album    string `json:"album"`
-	Genre    string `json:"genre"`
-	YearFrom int    `json:"yearFrom"`
-	YearTo   int    `json:"yearTo"`
-}
-
-// SortOption for result ordering
-type SortOption struct {
-	Field string `json:"field"`
-	Order string `json:"order"` // asc or desc
-}
-
-// SearchResponse contains search results
-type SearchResponse struct {
-	Results []SearchResult `json:"results"`
-	Total   int            `json:"total"`
-	Cursor  string         `json:"cursor,omitempty"`
-}
-
-// SearchResult represents a single search hit (flat structure matching client expectations)
-type SearchResult struct {
-	ID       string  `json:"id"`
-	Title    string  `json:"title"`
-	Artist   string  `json:"artist"`
-	Album    string  `json:"album"`
-	Genre    string  `json:"genre"`
-	Year     int     `json:"year,omitempty"`
-	Duration int     `json:"duration,omitempty"`
-	Score    float64 `json:"score"`
-}
-
-// IndexRequest for adding a document
-type IndexRequest struct {
-	Document Document `json:"document"`
-}
-
-// IndexResponse after indexing
-type IndexResponse struct {
-	ID      string `json:"id"`
-	Indexed bool   `json:"indexed"`
-}
-
-// DeleteRequest for removing a document
-type DeleteRequest struct {
-	ID string `json:"id"`
-}
-
-// DeleteResponse after deletion
-type DeleteResponse struct {
-	ID      string `json:"id"`
-	Deleted bool   `json:"deleted"`
-}
-
-// BulkIndexRequest for batch indexing
-type BulkIndexRequest struct {
-	Documents []Document `json:"documents"`
-}
-
-// BulkIndexResponse after bulk indexing
-type BulkIndexResponse struct {
-	Indexed int      `json:"indexed"`
-	Failed  int      `json:"failed"`
-	Errors  []string `json:"errors,omitempty"`
-}
-
-func init() {
-	indexBucket = os.Getenv("SEARCH_INDEX_BUCKET")
-	indexPath = os.Getenv("INDEX_PATH")
-	if indexPath == "" {
-		indexPath = "/tmp/nixiesearch"
-	}
-}
-
+// initializeAWS points the engine at the index bucket on the first invocation
 func initializeAWS(ctx context.Context) error {
-	if s3Client != nil {
+	if initialized {
 		return nil
 	}
 
@@ -156,412 +33,31 @@ func initializeAWS(ctx context.Context) error {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	s3Client = s3.NewFromConfig(cfg)
-	return nil
-}
-
-func loadIndex(ctx context.Context) error {
-	if initialized {
-		return nil
-	}
-
-	indexMutex.Lock()
-	defer indexMutex.Unlock()
-
-	if initialized {
-		return nil
-	}
-
-	// Try to load from S3
-	result, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: &indexBucket,
-		Key:    stringPtr("index.json"),
-	})
-	if err != nil {
-		// Index doesn't exist yet, create empty
-		index = &SearchIndex{
-			Documents: make(map[string]Document),
-			UpdatedAt: time.Now(),
-		}
-		initialized = true
-		return nil
-	}
-	defer result.Body.Close()
-
-	var loadedIndex SearchIndex
-	if err := json.NewDecoder(result.Body).Decode(&loadedIndex); err != nil {
-		return fmt.Errorf("failed to decode index: %w", err)
-	}
-
-	index = &loadedIndex
+	store := nixiesearch.NewS3Store(s3.NewFromConfig(cfg), appCfg.IndexBucket)
+	nixiesearch.Configure(store, appCfg.IndexBucket, alerting.New(alerting.NewSNSPublisher(cfg), appCfg.AlertTopicARN))
 	initialized = true
 	return nil
 }
 
-func saveIndex(ctx context.Context) error {
-	indexMutex.RLock()
-	data, err := json.Marshal(index)
-	indexMutex.RUnlock()
-
-	if err != nil {
-		return fmt.Errorf("failed to marshal index: %w", err)
-	}
-
-	_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      &indexBucket,
-		Key:         stringPtr("index.json"),
-		Body:        strings.NewReader(string(data)),
-		ContentType: stringPtr("application/json"),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to save index to S3: %w", err)
-	}
-
-	return nil
-}
-
-func handleRequest(ctx context.Context, req Request) (Response, error) {
+func handleRequest(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
 	if err := initializeAWS(ctx); err != nil {
-		return Response{Success: false, Error: err.Error()}, nil
-	}
-
-	if err := loadIndex(ctx); err != nil {
-		return Response{Success: false, Error: err.Error()}, nil
+		return searchproto.ErrorResponse("%s", err), nil
 	}
 
-	switch req.Operation {
-	case "search":
-		return handleSearch(ctx, req.Payload)
-	case "index":
-		return handleIndex(ctx, req.Payload)
-	case "delete":
-		return handleDelete(ctx, req.Payload)
-	case "bulk_index":
-		return handleBulkIndex(ctx, req.Payload)
-	default:
-		return Response{Success: false, Error: fmt.Sprintf("unknown operation: %s", req.Operation)}, nil
-	}
+	return nixiesearch.HandleRequest(ctx, req)
 }
 
-func handleSearch(ctx context.Context, payload interface{}) (Response, error) {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return Response{Success: false, Error: "invalid search payload"}, nil
-	}
-
-	var query SearchQuery
-	if err := json.Unmarshal(data, &query); err != nil {
-		return Response{Success: false, Error: "invalid search query"}, nil
-	}
-
-	if query.Limit <= 0 {
-		query.Limit = 20
-	}
-	if query.Limit > 100 {
-		query.Limit = 100
-	}
-
-	indexMutex.RLock()
-	defer indexMutex.RUnlock()
-
-	var results []SearchResult
-	queryLower := strings.ToLower(query.Query)
-
-	for _, doc := range index.Documents {
-		// Filter by user
-		if query.Filters.UserID != "" && doc.UserID != query.Filters.UserID {
-			continue
-		}
-
-		// Apply filters
-		if query.Filters.Artist != "" && !strings.Contains(strings.ToLower(doc.Artist), strings.ToLower(query.Filters.Artist)) {
-			continue
-		}
-		if query.Filters.Album != "" && !strings.Contains(strings.ToLower(doc.Album), strings.ToLower(query.Filters.Album)) {
-			continue
-		}
-		if query.Filters.Genre != "" && doc.Genre != query.Filters.Genre {
-			continue
-		}
-		if query.Filters.YearFrom > 0 && doc.Year < query.Filters.YearFrom {
-			continue
-		}
-		if query.Filters.YearTo > 0 && doc.Year > query.Filters.YearTo {
-			continue
-		}
-
-		// Calculate relevance score
-		score := calculateScore(doc, queryLower)
-		if queryLower == "" || score > 0 {
-			results = append(results, SearchResult{
-				ID:       doc.ID,
-				Title:    doc.Title,
-				Artist:   doc.Artist,
-				Album:    doc.Album,
-				Genre:    doc.Genre,
-				Year:     doc.Year,
-				Duration: doc.Duration,
-				Score:    score,
-			})
-		}
-	}
-
-	// Sort by score descending
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
-
-	// Apply limit
-	total := len(results)
-	if len(results) > query.Limit {
-		results = results[:query.Limit]
-	}
-
-	return Response{
-		Success: true,
-		Data: SearchResponse{
-			Results: results,
-			Total:   total,
-		},
-	}, nil
-}
-
-func calculateScore(doc Document, query string) float64 {
-	if query == "" {
-		return 1.0
-	}
-
-	var score float64
-
-	// Score each field with different weights
-	score += scoreField(doc.Title, query, 3.0)    // Title: highest weight
-	score += scoreField(doc.Artist, query, 2.0)   // Artist: high weight
-	score += scoreField(doc.Album, query, 1.5)    // Album: medium weight
-	score += scoreField(doc.Filename, query, 0.5) // Filename: low weight
-
-	return score
-}
-
-// scoreField calculates match score for a single field using multiple strategies
-func scoreField(field, query string, weight float64) float64 {
-	if field == "" {
-		return 0
-	}
-
-	fieldLower := strings.ToLower(field)
-	queryLower := strings.ToLower(query)
-	var score float64
-
-	// Strategy 1: Exact match (highest score)
-	if fieldLower == queryLower {
-		return weight * 5.0
-	}
-
-	// Strategy 2: Prefix match on full field
-	if strings.HasPrefix(fieldLower, queryLower) {
-		score += weight * 3.0
-	}
-
-	// Strategy 3: Contains match
-	if strings.Contains(fieldLower, queryLower) {
-		score += weight * 2.0
-	}
-
-	// Strategy 4: Word prefix match (any word starts with query)
-	words := strings.Fields(fieldLower)
-	for _, word := range words {
-		if strings.HasPrefix(word, queryLower) {
-			score += weight * 2.5
-			break
-		}
-	}
-
-	// Strategy 5: Fuzzy match using Levenshtein distance
-	// Check each word in the field
-	for _, word := range words {
-		distance := levenshtein(word, queryLower)
-		wordLen := len(word)
-		queryLen := len(queryLower)
-		maxLen := wordLen
-		if queryLen > maxLen {
-			maxLen = queryLen
-		}
-
-		// Allow up to 2 character differences for short words, more for longer
-		maxDistance := 1
-		if maxLen > 4 {
-			maxDistance = 2
-		}
-		if maxLen > 8 {
-			maxDistance = 3
-		}
-
-		if distance <= maxDistance && distance > 0 {
-			// Fuzzy match found - score inversely proportional to distance
-			fuzzyScore := weight * (1.0 - float64(distance)/float64(maxLen+1))
-			if fuzzyScore > score {
-				score = fuzzyScore
-			}
-		}
-	}
-
-	return score
-}
-
-// levenshtein calculates the Levenshtein distance between two strings
-func levenshtein(a, b string) int {
-	if len(a) == 0 {
-		return len(b)
-	}
-	if len(b) == 0 {
-		return len(a)
-	}
-
-	// Create matrix
-	matrix := make([][]int, len(a)+1)
-	for i := range matrix {
-		matrix[i] = make([]int, len(b)+1)
-		matrix[i][0] = i
-	}
-	for j := 0; j <= len(b); j++ {
-		matrix[0][j] = j
-	}
-
-	// Fill matrix
-	for i := 1; i <= len(a); i++ {
-		for j := 1; j <= len(b); j++ {
-			cost := 1
-			if a[i-1] == b[j-1] {
-				cost = 0
-			}
-			matrix[i][j] = min(
-				matrix[i-1][j]+1,      // deletion
-				matrix[i][j-1]+1,      // insertion
-				matrix[i-1][j-1]+cost, // substitution
-			)
-		}
-	}
-
-	return matrix[len(a)][len(b)]
-}
-
-// min returns the minimum of three integers
-func min(a, b, c int) int {
-	if a < b {
-		if a < c {
-			return a
-		}
-		return c
-	}
-	if b < c {
-		return b
-	}
-	return c
-}
-
-func handleIndex(ctx context.Context, payload interface{}) (Response, error) {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return Response{Success: false, Error: "invalid index payload"}, nil
-	}
-
-	var req IndexRequest
-	if err := json.Unmarshal(data, &req); err != nil {
-		return Response{Success: false, Error: "invalid index request"}, nil
-	}
-
-	req.Document.IndexedAt = time.Now()
-
-	indexMutex.Lock()
-	index.Documents[req.Document.ID] = req.Document
-	index.UpdatedAt = time.Now()
-	indexMutex.Unlock()
-
-	if err := saveIndex(ctx); err != nil {
-		return Response{Success: false, Error: err.Error()}, nil
-	}
-
-	return Response{
-		Success: true,
-		Data: IndexResponse{
-			ID:      req.Document.ID,
-			Indexed: true,
-		},
-	}, nil
-}
-
-func handleDelete(ctx context.Context, payload interface{}) (Response, error) {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return Response{Success: false, Error: "invalid delete payload"}, nil
-	}
-
-	var req DeleteRequest
-	if err := json.Unmarshal(data, &req); err != nil {
-		return Response{Success: false, Error: "invalid delete request"}, nil
-	}
-
-	indexMutex.Lock()
-	_, exists := index.Documents[req.ID]
-	if exists {
-		delete(index.Documents, req.ID)
-		index.UpdatedAt = time.Now()
-	}
-	indexMutex.Unlock()
-
-	if exists {
-		if err := saveIndex(ctx); err != nil {
-			return Response{Success: false, Error: err.Error()}, nil
-		}
-	}
-
-	return Response{
-		Success: true,
-		Data: DeleteResponse{
-			ID:      req.ID,
-			Deleted: exists,
-		},
-	}, nil
-}
-
-func handleBulkIndex(ctx context.Context, payload interface{}) (Response, error) {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return Response{Success: false, Error: "invalid bulk index payload"}, nil
-	}
-
-	var req BulkIndexRequest
-	if err := json.Unmarshal(data, &req); err != nil {
-		return Response{Success: false, Error: "invalid bulk index request"}, nil
-	}
-
-	indexMutex.Lock()
-	indexed := 0
-	for _, doc := range req.Documents {
-		doc.IndexedAt = time.Now()
-		index.Documents[doc.ID] = doc
-		indexed++
-	}
-	index.UpdatedAt = time.Now()
-	indexMutex.Unlock()
-
-	if err := saveIndex(ctx); err != nil {
-		return Response{Success: false, Error: err.Error()}, nil
+// warm creates the S3 client and loads the search index
+func warm(ctx context.Context) error {
+	if err := initializeAWS(ctx); err != nil {
+		return err
 	}
-
-	return Response{
-		Success: true,
-		Data: BulkIndexResponse{
-			Indexed: indexed,
-			Failed:  0,
-		},
-	}, nil
-}
-
-func stringPtr(s string) *string {
-	return &s
+	return nixiesearch.Warm(ctx)
 }
 
 func main() {
-	lambda.Start(handleRequest)
+	// Warmup events and provisioned-concurrency init load the index ahead of
+	// the first search
+	bootstrap.WarmIfProvisioned(warm)
+	lambda.Start(bootstrap.WithWarmup(warm, handleRequest))
 }
@@ -3,13 +3,16 @@ package main
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
 	"github.com/gvasels/personal-music-searchengine/internal/analysis"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 	"github.com/gvasels/personal-music-searchengine/internal/validation"
 )
 
@@ -20,6 +23,8 @@ type Event struct {
 	S3Key      string `json:"s3Key"`
 	FileName   string `json:"fileName"`
 	BucketName string `json:"bucketName"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
 }
 
 // Response represents the output to Step Functions
@@ -28,26 +33,34 @@ type Response struct {
 	MusicalKey string `json:"musicalKey,omitempty"`
 	KeyMode    string `json:"keyMode,omitempty"`
 	KeyCamelot string `json:"keyCamelot,omitempty"`
-	Analyzed   bool   `json:"analyzed"`
-	Error      string `json:"error,omitempty"`
+	// KeyConfidence (0-1) and KeyCandidates, the runner-up keys, qualify the key
+	KeyConfidence float64               `json:"keyConfidence,omitempty"`
+	KeyCandidates []models.KeyCandidate `json:"keyCandidates,omitempty"`
+	// PreviewStart is the second the track's preview clip starts at
+	PreviewStart int    `json:"previewStart,omitempty"`
+	Analyzed     bool   `json:"analyzed"`
+	Error        string `json:"error,omitempty"`
 }
 
-var s3Client *s3.Client
-var analyzer *analysis.Analyzer
+// deps builds the S3 client on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
 
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.Background())
-	if err != nil {
-		panic(fmt.Sprintf("failed to load AWS config: %v", err))
-	}
-	s3Client = s3.NewFromConfig(cfg)
-	analyzer = analysis.NewAnalyzer()
-}
+var analyzer = analysis.NewAnalyzer()
 
 func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Add timeout to context (allow up to 25 seconds for analysis)
 	ctx, cancel := context.WithTimeout(ctx, 25*time.Second)
 	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+
+	s3Client, err := deps.S3(ctx)
+	if err != nil {
+		// Return success with error message - don't fail the workflow
+		return &Response{
+			Analyzed: false,
+			Error:    fmt.Sprintf("setup failed: %v", err),
+		}, nil
+	}
 
 	// Validate file size before download
 	if err := validation.ValidateFileSize(ctx, s3Client, event.BucketName, event.S3Key); err != nil {
@@ -82,14 +95,29 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	}
 
 	return &Response{
-		BPM:        analysisResult.BPM,
-		MusicalKey: analysisResult.MusicalKey,
-		KeyMode:    analysisResult.KeyMode,
-		KeyCamelot: analysisResult.KeyCamelot,
-		Analyzed:   true,
+		BPM:           analysisResult.BPM,
+		MusicalKey:    analysisResult.MusicalKey,
+		KeyMode:       analysisResult.KeyMode,
+		KeyCamelot:    analysisResult.KeyCamelot,
+		KeyConfidence: analysisResult.KeyConfidence,
+		KeyCandidates: keyCandidates(analysisResult.KeyCandidates),
+		PreviewStart:  analysisResult.PreviewStart,
+		Analyzed:      true,
 	}, nil
 }
 
+// keyCandidates converts the analyzer's runner-up keys for the track record
+func keyCandidates(candidates []analysis.KeyCandidate) []models.KeyCandidate {
+	if len(candidates) == 0 {
+		return nil
+	}
+	result := make([]models.KeyCandidate, len(candidates))
+	for i, c := range candidates {
+		result[i] = models.KeyCandidate{Key: c.Key, Camelot: c.Camelot, Score: math.Round(c.Score*1000) / 1000}
+	}
+	return result
+}
+
 func main() {
 	lambda.Start(handleRequest)
 }
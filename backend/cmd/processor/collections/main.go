@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+	"github.com/gvasels/personal-music-searchengine/internal/validation"
+)
+
+// deps builds the repository on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
+
+// handleRequest adjusts library collection counts for a batch of the table's
+// stream records. The event source mapping only passes track items. Records
+// are applied in order; on the first failure the rest of the batch is left
+// unapplied and reported, since Lambda retries the shard from the first
+// failed record and would otherwise apply the later ones twice.
+func handleRequest(ctx context.Context, event events.DynamoDBEvent) (events.DynamoDBEventResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
+	defer cancel()
+
+	var response events.DynamoDBEventResponse
+	for _, record := range event.Records {
+		if err := applyRecord(ctx, record); err != nil {
+			fmt.Printf("Failed to apply %s %s to collections: %v\n", record.EventName, record.Change.SequenceNumber, err)
+			response.BatchItemFailures = append(response.BatchItemFailures, events.DynamoDBBatchItemFailure{
+				ItemIdentifier: record.Change.SequenceNumber,
+			})
+			break
+		}
+	}
+	return response, nil
+}
+
+// applyRecord applies one track write to the collections of the track's library
+func applyRecord(ctx context.Context, record events.DynamoDBEventRecord) error {
+	if pk, ok := record.Change.Keys["PK"]; ok && pk.DataType() == events.DataTypeString {
+		if id, _, ok := tenant.SplitKey(pk.String()); ok {
+			ctx = tenant.WithID(ctx, id)
+		}
+	}
+
+	before, err := trackImage(record.Change.OldImage)
+	if err != nil {
+		return err
+	}
+	after, err := trackImage(record.Change.NewImage)
+	if err != nil {
+		return err
+	}
+	if before == nil && after == nil {
+		return nil
+	}
+
+	repo, err := deps.Repository(ctx)
+	if err != nil {
+		return err
+	}
+	store, ok := repo.(service.CollectionRepository)
+	if !ok {
+		return fmt.Errorf("repository does not support collections")
+	}
+	// Only counts are adjusted here; tracks are never listed
+	return service.NewCollectionService(store, repo, nil).ApplyTrackChange(ctx, before, after)
+}
+
+// trackImage unmarshals a stream image, returning nil when the image is
+// missing or not a track
+func trackImage(image map[string]events.DynamoDBAttributeValue) (*models.Track, error) {
+	if len(image) == 0 {
+		return nil, nil
+	}
+	item := make(map[string]types.AttributeValue, len(image))
+	for name, value := range image {
+		item[name] = attributeValue(value)
+	}
+
+	var track models.TrackItem
+	if err := attributevalue.UnmarshalMap(item, &track); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal track image: %w", err)
+	}
+	if track.Type != string(models.EntityTrack) {
+		return nil, nil
+	}
+	return &track.Track, nil
+}
+
+// attributeValue converts a stream attribute to the SDK's type
+func attributeValue(value events.DynamoDBAttributeValue) types.AttributeValue {
+	switch value.DataType() {
+	case events.DataTypeString:
+		return &types.AttributeValueMemberS{Value: value.String()}
+	case events.DataTypeNumber:
+		return &types.AttributeValueMemberN{Value: value.Number()}
+	case events.DataTypeBinary:
+		return &types.AttributeValueMemberB{Value: value.Binary()}
+	case events.DataTypeBoolean:
+		return &types.AttributeValueMemberBOOL{Value: value.Boolean()}
+	case events.DataTypeStringSet:
+		return &types.AttributeValueMemberSS{Value: value.StringSet()}
+	case events.DataTypeNumberSet:
+		return &types.AttributeValueMemberNS{Value: value.NumberSet()}
+	case events.DataTypeBinarySet:
+		return &types.AttributeValueMemberBS{Value: value.BinarySet()}
+	case events.DataTypeList:
+		list := make([]types.AttributeValue, 0, len(value.List()))
+		for _, element := range value.List() {
+			list = append(list, attributeValue(element))
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	case events.DataTypeMap:
+		m := make(map[string]types.AttributeValue, len(value.Map()))
+		for name, element := range value.Map() {
+			m[name] = attributeValue(element)
+		}
+		return &types.AttributeValueMemberM{Value: m}
+	default:
+		return &types.AttributeValueMemberNULL{Value: true}
+	}
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
@@ -5,19 +5,20 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
+	"github.com/gvasels/personal-music-searchengine/internal/artwork"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
 	"github.com/gvasels/personal-music-searchengine/internal/metadata"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 	"github.com/gvasels/personal-music-searchengine/internal/validation"
 )
 
@@ -28,37 +29,50 @@ type Event struct {
 	S3Key      string                 `json:"s3Key"`
 	Metadata   *models.UploadMetadata `json:"metadata"`
 	BucketName string                 `json:"bucketName"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
 }
 
 // Response represents the output to Step Functions
 type Response struct {
 	CoverArtKey string `json:"coverArtKey"`
+	// Artwork lists the other embedded images (back cover, booklet, ...)
+	Artwork []models.Artwork `json:"artwork,omitempty"`
+	// CoverStyle holds the cover's dominant colors, placeholder and crop
+	CoverStyle *models.CoverStyle `json:"coverStyle,omitempty"`
+	// ThumbnailKey is the small copy of the cover data saver lists show
+	ThumbnailKey string `json:"thumbnailKey,omitempty"`
 }
 
-var s3Client *s3.Client
-var extractor *metadata.Extractor
-var repo repository.Repository
+// maxArtwork bounds how many embedded images besides the cover are stored
+const maxArtwork = 16
 
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.Background())
-	if err != nil {
-		panic(fmt.Sprintf("failed to load AWS config: %v", err))
-	}
-	s3Client = s3.NewFromConfig(cfg)
-	extractor = metadata.NewExtractor()
+// deps builds the AWS clients on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
 
-	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
-	if tableName == "" {
-		tableName = "MusicLibrary"
+var s3Client repository.S3Client
+var extractor = metadata.NewExtractor()
+var repo repository.Repository
+
+// setup resolves the clients used by handleRequest
+func setup(ctx context.Context) (err error) {
+	if s3Client, err = deps.S3(ctx); err != nil {
+		return err
 	}
-	dynamoClient := dynamodb.NewFromConfig(cfg)
-	repo = repository.NewDynamoDBRepository(dynamoClient, tableName)
+	repo, err = deps.Repository(ctx)
+	return err
 }
 
 func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Add timeout to context (5 seconds less than Lambda timeout)
 	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
 	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+	fmt.Printf("Processing upload %s\n", event.UploadID)
+
+	if err := setup(ctx); err != nil {
+		return nil, err
+	}
 
 	// Check if metadata indicates cover art is present
 	if event.Metadata == nil || !event.Metadata.HasCoverArt {
@@ -80,14 +94,15 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		return nil, fmt.Errorf("failed to download from S3: %w", err)
 	}
 
-	// Extract cover art
+	// Extract every embedded image; the front cover becomes the track's cover art
 	reader := bytes.NewReader(data)
-	coverData, mimeType, err := extractor.ExtractCoverArt(reader)
+	images, err := extractor.ExtractImages(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract cover art: %w", err)
 	}
 
-	if coverData == nil {
+	cover := metadata.FrontCover(images)
+	if cover < 0 {
 		// Mark step as complete even if no cover art extracted
 		if err := repo.UpdateUploadStep(ctx, event.UserID, event.UploadID, models.StepExtractCover, true); err != nil {
 			fmt.Printf("Warning: failed to update step progress: %v\n", err)
@@ -95,22 +110,74 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		return &Response{CoverArtKey: ""}, nil
 	}
 
-	// Determine file extension from MIME type
-	ext := getExtensionFromMIME(mimeType)
-
 	// Upload cover art to S3
-	coverKey := fmt.Sprintf("covers/%s/%s%s", event.UserID, event.UploadID, ext)
-	err = uploadToS3(ctx, event.BucketName, coverKey, coverData, mimeType)
+	coverImage := images[cover]
+	coverKey, err := sanitize.Key("covers", event.UserID, event.UploadID+getExtensionFromMIME(coverImage.MIMEType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build cover art key: %w", err)
+	}
+	err = uploadToS3(ctx, event.BucketName, coverKey, coverImage.Data, coverImage.MIMEType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload cover art: %w", err)
 	}
 
+	response := &Response{CoverArtKey: coverKey}
+
+	// The style is a display hint, so an image Go cannot decode (e.g. WebP)
+	// still gets stored without one
+	if response.CoverStyle, err = artwork.Analyze(coverImage.Data); err != nil {
+		fmt.Printf("Warning: failed to analyze cover art: %v\n", err)
+	} else if response.ThumbnailKey, err = uploadThumbnail(ctx, event, coverImage.Data); err != nil {
+		fmt.Printf("Warning: failed to store cover thumbnail: %v\n", err)
+	}
+
+	// Store the remaining images under covers/{userId}/{uploadId}/
+	for i, image := range images {
+		if i == cover || len(image.Data) == 0 {
+			continue
+		}
+		if len(response.Artwork) == maxArtwork {
+			fmt.Printf("Skipping %d further embedded images\n", len(images)-i)
+			break
+		}
+		name := fmt.Sprintf("%d-%s%s", i, image.Type, getExtensionFromMIME(image.MIMEType))
+		key, err := sanitize.Key("covers", event.UserID, event.UploadID, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build artwork key: %w", err)
+		}
+		if err := uploadToS3(ctx, event.BucketName, key, image.Data, image.MIMEType); err != nil {
+			return nil, fmt.Errorf("failed to upload artwork: %w", err)
+		}
+		response.Artwork = append(response.Artwork, models.Artwork{
+			Type:        image.Type,
+			Key:         key,
+			MIMEType:    image.MIMEType,
+			Description: image.Description,
+		})
+	}
+
 	// Update step progress
 	if err := repo.UpdateUploadStep(ctx, event.UserID, event.UploadID, models.StepExtractCover, true); err != nil {
 		fmt.Printf("Warning: failed to update step progress: %v\n", err)
 	}
 
-	return &Response{CoverArtKey: coverKey}, nil
+	return response, nil
+}
+
+// uploadThumbnail stores the small JPEG copy of the cover next to it
+func uploadThumbnail(ctx context.Context, event Event, cover []byte) (string, error) {
+	thumbnail, err := artwork.Thumbnail(cover, artwork.ThumbnailEdge)
+	if err != nil {
+		return "", err
+	}
+	key, err := sanitize.Key("covers", event.UserID, event.UploadID+"-thumb.jpg")
+	if err != nil {
+		return "", err
+	}
+	if err := uploadToS3(ctx, event.BucketName, key, thumbnail, "image/jpeg"); err != nil {
+		return "", err
+	}
+	return key, nil
 }
 
 func downloadFromS3(ctx context.Context, bucket, key string) ([]byte, error) {
@@ -3,16 +3,15 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	awslambda "github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
-	"github.com/gvasels/personal-music-searchengine/internal/repository"
 	"github.com/gvasels/personal-music-searchengine/internal/search"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 	"github.com/gvasels/personal-music-searchengine/internal/validation"
 )
 
@@ -24,6 +23,8 @@ type Event struct {
 	Metadata  *models.UploadMetadata `json:"metadata"`
 	S3Key     string                 `json:"s3Key"`
 	TableName string                 `json:"tableName"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
 }
 
 // Response represents the output to Step Functions
@@ -32,37 +33,34 @@ type Response struct {
 	Reason  string `json:"reason,omitempty"`
 }
 
-var searchClient *search.Client
-var repo repository.Repository
+// deps builds the repository and AWS clients on the first invocation rather
+// than in init()
+var deps = bootstrap.NewProcessor()
 
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.Background())
+// indexClient builds a search client, or nil when NIXIESEARCH_FUNCTION_NAME is not set
+var indexClient = bootstrap.NewLazy("search client", func(ctx context.Context) (*search.Client, error) {
+	appCfg, err := deps.Config(ctx)
 	if err != nil {
-		fmt.Printf("Failed to load AWS config: %v\n", err)
-		return
-	}
-
-	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
-	if tableName == "" {
-		tableName = "MusicLibrary"
+		return nil, err
 	}
-	dynamoClient := dynamodb.NewFromConfig(cfg)
-	repo = repository.NewDynamoDBRepository(dynamoClient, tableName)
-
-	nixieFunctionName := os.Getenv("NIXIESEARCH_FUNCTION_NAME")
-	if nixieFunctionName == "" {
+	if appCfg.NixiesearchFunctionName == "" {
 		fmt.Println("NIXIESEARCH_FUNCTION_NAME not set, search indexing disabled")
-		return
+		return nil, nil
 	}
 
-	lambdaClient := awslambda.NewFromConfig(cfg)
-	searchClient = search.NewClient(lambdaClient, nixieFunctionName)
-}
+	cfg, err := deps.AWS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return search.NewClient(awslambda.NewFromConfig(cfg), appCfg.NixiesearchFunctionName), nil
+})
 
 func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Add timeout to context (5 seconds less than Lambda timeout)
 	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
 	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+	fmt.Printf("Processing upload %s\n", event.UploadID)
 
 	// Validate required fields
 	if err := validation.ValidateUUID(event.TrackID, "trackId"); err != nil {
@@ -79,7 +77,14 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		}, nil
 	}
 
-	// If search client not initialized, skip indexing
+	// If search client not configured, skip indexing
+	searchClient, err := indexClient.Get(ctx)
+	if err != nil {
+		return &Response{
+			Indexed: false,
+			Reason:  fmt.Sprintf("setup_failed: %v", err),
+		}, nil
+	}
 	if searchClient == nil {
 		return &Response{
 			Indexed: false,
@@ -96,7 +101,7 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	}
 
 	// Build search document from metadata
-	doc := search.Document{
+	doc := searchproto.Document{
 		ID:        event.TrackID,
 		UserID:    event.UserID,
 		Title:     event.Metadata.Title,
@@ -105,9 +110,12 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		Genre:     event.Metadata.Genre,
 		Year:      event.Metadata.Year,
 		Duration:  event.Metadata.Duration,
+		Bitrate:   event.Metadata.Bitrate,
 		Filename:  event.S3Key,
 		IndexedAt: time.Now(),
 	}
+	// The transcode starts before indexing, so the track already has its state
+	applyTranscodeState(ctx, event.UserID, event.TrackID, &doc)
 
 	// Index the document
 	resp, err := searchClient.Index(ctx, doc)
@@ -126,8 +134,12 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	}
 
 	// Update step progress
-	if event.UploadID != "" && repo != nil {
-		if err := repo.UpdateUploadStep(ctx, event.UserID, event.UploadID, models.StepIndex, true); err != nil {
+	if event.UploadID != "" {
+		repo, err := deps.Repository(ctx)
+		if err == nil {
+			err = repo.UpdateUploadStep(ctx, event.UserID, event.UploadID, models.StepIndex, true)
+		}
+		if err != nil {
 			fmt.Printf("Warning: failed to update step progress: %v\n", err)
 		}
 	}
@@ -137,6 +149,26 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	}, nil
 }
 
+// applyTranscodeState copies the track's HLS status into doc. The
+// transcode-complete Lambda keeps it current from then on. The status is
+// optional for search, so a failed read is logged and the document indexed
+// without it.
+func applyTranscodeState(ctx context.Context, userID, trackID string, doc *searchproto.Document) {
+	repo, err := deps.Repository(ctx)
+	var track *models.Track
+	if err == nil {
+		track, err = repo.GetTrack(ctx, userID, trackID)
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to read transcode status of track %s: %v\n", trackID, err)
+		return
+	}
+
+	doc.HLSStatus = string(track.HLSStatus)
+	doc.Processing = track.HLSStatus.InProgress()
+	doc.StatusUpdatedAt = &track.UpdatedAt
+}
+
 func main() {
 	lambda.Start(handleRequest)
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/gvasels/personal-music-searchengine/internal/validation"
+)
+
+// Response reports how many libraries had their cold tracks reported
+type Response struct {
+	Libraries int `json:"libraries"`
+}
+
+// deps builds the repository on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
+
+// handleRequest runs on an EventBridge schedule and replaces each library's
+// archive insight: its tracks nobody has played in two years, which users
+// review through GET /me/insights/archive. The whole table is scanned in one
+// run, so it must finish within the processor timeout.
+func handleRequest(ctx context.Context) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
+	defer cancel()
+
+	repo, err := deps.Repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	store, ok := repo.(service.ArchiveSuggestionRepository)
+	if !ok {
+		return nil, fmt.Errorf("repository does not support archive insights")
+	}
+
+	libraries, err := service.NewArchiveSuggestionService(store, repo, nil).GenerateAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to report cold tracks after %d libraries: %w", libraries, err)
+	}
+
+	fmt.Printf("Reported cold tracks of %d libraries\n", libraries)
+	return &Response{Libraries: libraries}, nil
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
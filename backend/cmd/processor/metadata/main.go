@@ -3,19 +3,20 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"os"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
 	"github.com/gvasels/personal-music-searchengine/internal/metadata"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 	"github.com/gvasels/personal-music-searchengine/internal/validation"
 )
 
@@ -26,6 +27,8 @@ type Event struct {
 	S3Key      string `json:"s3Key"`
 	FileName   string `json:"fileName"`
 	BucketName string `json:"bucketName"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
 }
 
 // Response represents the output to Step Functions
@@ -33,30 +36,32 @@ type Response struct {
 	*models.UploadMetadata
 }
 
-var s3Client *s3.Client
-var extractor *metadata.Extractor
-var repo repository.Repository
+// deps builds the AWS clients on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
 
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.Background())
-	if err != nil {
-		panic(fmt.Sprintf("failed to load AWS config: %v", err))
-	}
-	s3Client = s3.NewFromConfig(cfg)
-	extractor = metadata.NewExtractor()
+var s3Client repository.S3Client
+var extractor = metadata.NewExtractor()
+var repo repository.Repository
 
-	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
-	if tableName == "" {
-		tableName = "MusicLibrary"
+// setup resolves the clients used by handleRequest
+func setup(ctx context.Context) (err error) {
+	if s3Client, err = deps.S3(ctx); err != nil {
+		return err
 	}
-	dynamoClient := dynamodb.NewFromConfig(cfg)
-	repo = repository.NewDynamoDBRepository(dynamoClient, tableName)
+	repo, err = deps.Repository(ctx)
+	return err
 }
 
 func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Add timeout to context (5 seconds less than Lambda timeout)
 	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
 	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+	fmt.Printf("Processing upload %s\n", event.UploadID)
+
+	if err := setup(ctx); err != nil {
+		return nil, err
+	}
 
 	// Validate file size before download to prevent OOM
 	if err := validation.ValidateFileSize(ctx, s3Client, event.BucketName, event.S3Key); err != nil {
@@ -76,6 +81,17 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		return nil, fmt.Errorf("failed to extract metadata: %w", err)
 	}
 
+	// Record the file's hash so later uploads of the same bytes are recognized
+	sum := sha256.Sum256(data)
+	meta.ContentHash = hex.EncodeToString(sum[:])
+
+	// Flag uploads whose tags look mis-decoded so the owner can review the fixes
+	if len(meta.EncodingFixes) > 0 {
+		if err := flagSuspectEncoding(ctx, event.UserID, event.UploadID, meta.EncodingFixes); err != nil {
+			fmt.Printf("Warning: failed to flag suspect tag encoding: %v\n", err)
+		}
+	}
+
 	// Update step progress
 	if err := repo.UpdateUploadStep(ctx, event.UserID, event.UploadID, models.StepExtractMetadata, true); err != nil {
 		fmt.Printf("Warning: failed to update step progress: %v\n", err)
@@ -84,6 +100,16 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	return &Response{UploadMetadata: meta}, nil
 }
 
+func flagSuspectEncoding(ctx context.Context, userID, uploadID string, fixes []models.EncodingFix) error {
+	upload, err := repo.GetUpload(ctx, userID, uploadID)
+	if err != nil {
+		return err
+	}
+	upload.SuspectEncoding = true
+	upload.EncodingFixes = fixes
+	return repo.UpdateUpload(ctx, *upload)
+}
+
 func downloadFromS3(ctx context.Context, bucket, key string) ([]byte, error) {
 	result, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: &bucket,
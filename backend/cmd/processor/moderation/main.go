@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/clients"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/moderation"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+	"github.com/gvasels/personal-music-searchengine/internal/validation"
+)
+
+// Response represents the outcome of one check
+type Response struct {
+	TrackID string                  `json:"trackId"`
+	Status  models.ModerationStatus `json:"status"`
+}
+
+var (
+	// moderationConfig, deps and checker are built on the first invocation
+	// rather than in init()
+	moderationConfig = bootstrap.NewLazy("configuration", func(context.Context) (*appconfig.Moderation, error) {
+		return appconfig.LoadModeration()
+	})
+	deps = bootstrap.NewProcessorWith(func() (*appconfig.Processor, error) {
+		appCfg, err := moderationConfig.Get(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &appCfg.Processor, nil
+	})
+	checker = bootstrap.NewLazy("moderation checker", newChecker)
+)
+
+func newChecker(ctx context.Context) (*moderation.Checker, error) {
+	appCfg, err := moderationConfig.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	awsCfg, err := deps.AWS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var transcriber moderation.Transcriber
+	if appCfg.TranscribeURL != "" {
+		transcriber = moderation.NewHTTPTranscriber(appCfg.TranscribeURL, appCfg.TranscribeModel, nil)
+	} else {
+		fmt.Println("TRANSCRIBE_URL not set, classifying metadata and lyrics only")
+	}
+
+	completer := bedrockCompleter{
+		client: clients.NewBedrockClient(bedrockruntime.NewFromConfig(awsCfg)),
+		model:  appCfg.ModerationModel,
+	}
+	return moderation.NewChecker(transcriber, completer), nil
+}
+
+// bedrockCompleter classifies through the Bedrock client shared with the gateway
+type bedrockCompleter struct {
+	client *clients.BedrockClient
+	model  string
+}
+
+func (b bedrockCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	resp, err := b.client.CreateChatCompletion(ctx, clients.ChatCompletionRequest{
+		Model:     b.model,
+		Messages:  []clients.ChatMessage{{Role: "user", Content: prompt}},
+		MaxTokens: 512,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("model returned no choices")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+func handleRequest(ctx context.Context, event models.ModerationRequest) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
+	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+
+	if err := validation.ValidateUUID(event.TrackID, "trackId"); err != nil {
+		return nil, err
+	}
+
+	baseRepo, err := deps.Repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repo, ok := baseRepo.(service.ModerationRepository)
+	if !ok {
+		return nil, fmt.Errorf("repository does not support moderation reviews")
+	}
+	// The dispatcher is only used to start checks, which the API does
+	moderationSvc := service.NewModerationService(repo, nil)
+
+	track, err := repo.GetTrack(ctx, event.UserID, event.TrackID)
+	if err != nil && err != repository.ErrNotFound {
+		return nil, fmt.Errorf("failed to get track: %w", err)
+	}
+
+	var verdict models.ModerationVerdict
+	if track != nil && track.PendingVisibility != "" {
+		verdict = check(ctx, *track)
+	}
+	// A deleted or no longer pending track withdraws the review
+	review, err := moderationSvc.RecordVerdict(ctx, event.UserID, event.TrackID, verdict)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Moderation of track %s: %s %v\n", event.TrackID, review.Status, review.Categories)
+	return &Response{TrackID: event.TrackID, Status: review.Status}, nil
+}
+
+// check runs the automatic check; a check that fails is flagged for an admin
+// rather than retried indefinitely or published unchecked
+func check(ctx context.Context, track models.Track) models.ModerationVerdict {
+	c, err := checker.Get(ctx)
+	if err != nil {
+		return failedCheck(err)
+	}
+
+	snippet, err := audioSnippet(ctx, track)
+	if err != nil {
+		return failedCheck(err)
+	}
+	if snippet != nil {
+		defer snippet.Close()
+	}
+
+	verdict, err := c.Check(ctx, moderation.Content{
+		Title:   track.Title,
+		Artist:  track.Artist,
+		Album:   track.Album,
+		Genre:   track.Genre,
+		Comment: track.Comment,
+		Lyrics:  track.Lyrics,
+	}, snippet, path.Base(track.S3Key))
+	if err != nil {
+		return failedCheck(err)
+	}
+	return verdict
+}
+
+func failedCheck(err error) models.ModerationVerdict {
+	fmt.Printf("Moderation check failed: %v\n", err)
+	return models.ModerationVerdict{Flagged: true, Reason: fmt.Sprintf("automatic check failed: %v", err)}
+}
+
+// audioSnippet reads the start of the audio file for transcription, or
+// returns nil when there is no transcriber or no file
+func audioSnippet(ctx context.Context, track models.Track) (io.ReadCloser, error) {
+	appCfg, err := moderationConfig.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if appCfg.TranscribeURL == "" || track.S3Key == "" || appCfg.SnippetBytes <= 0 {
+		return nil, nil
+	}
+
+	s3Client, err := deps.S3(ctx)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(appCfg.MediaBucketName),
+		Key:    aws.String(track.S3Key),
+		Range:  aws.String(fmt.Sprintf("bytes=0-%d", appCfg.SnippetBytes-1)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio snippet: %w", err)
+	}
+	return obj.Body, nil
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
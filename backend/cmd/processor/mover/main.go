@@ -3,18 +3,17 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 	"github.com/gvasels/personal-music-searchengine/internal/validation"
 )
 
@@ -25,6 +24,10 @@ type Event struct {
 	SourceKey  string `json:"sourceKey"`
 	TrackID    string `json:"trackId"` // Direct trackId from Step Functions
 	BucketName string `json:"bucketName"`
+	// ReplaceTrackID is set when the upload replaces the file of an existing track
+	ReplaceTrackID string `json:"replaceTrackId,omitempty"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
 }
 
 // Response represents the output to Step Functions
@@ -32,28 +35,31 @@ type Response struct {
 	NewKey string `json:"newKey"` // Matches Step Functions expected output
 }
 
-var s3Client *s3.Client
-var repo repository.Repository
+// deps builds the AWS clients on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
 
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.Background())
-	if err != nil {
-		panic(fmt.Sprintf("failed to load AWS config: %v", err))
-	}
-	s3Client = s3.NewFromConfig(cfg)
+var s3Client repository.S3Client
+var repo repository.Repository
 
-	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
-	if tableName == "" {
-		tableName = "MusicLibrary"
+// setup resolves the clients used by handleRequest
+func setup(ctx context.Context) (err error) {
+	if s3Client, err = deps.S3(ctx); err != nil {
+		return err
 	}
-	dynamoClient := dynamodb.NewFromConfig(cfg)
-	repo = repository.NewDynamoDBRepository(dynamoClient, tableName)
+	repo, err = deps.Repository(ctx)
+	return err
 }
 
 func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Add timeout to context (5 seconds less than Lambda timeout)
 	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
 	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+	fmt.Printf("Processing upload %s\n", event.UploadID)
+
+	if err := setup(ctx); err != nil {
+		return nil, err
+	}
 
 	if event.TrackID == "" {
 		return nil, fmt.Errorf("track ID is required")
@@ -67,23 +73,40 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		return nil, err
 	}
 
-	// Determine file extension from source key
-	ext := filepath.Ext(event.SourceKey)
+	// Determine file extension from source key (the upload's file name)
+	ext := sanitize.Extension(event.SourceKey)
 	if ext == "" {
 		ext = ".mp3" // Default extension
 	}
 
 	// Create destination key
-	destKey := fmt.Sprintf("media/%s/%s%s", event.UserID, event.TrackID, ext)
+	destKey, err := sanitize.Key("media", event.UserID, event.TrackID+ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build destination key: %w", err)
+	}
 
-	// Copy file to new location
-	copySource := fmt.Sprintf("%s/%s", event.BucketName, event.SourceKey)
-	_, err := s3Client.CopyObject(ctx, &s3.CopyObjectInput{
-		Bucket:     &event.BucketName,
-		CopySource: aws.String(copySource),
-		Key:        &destKey,
-	})
+	track, err := repo.GetTrack(ctx, event.UserID, event.TrackID)
 	if err != nil {
+		return nil, fmt.Errorf("failed to get track: %w", err)
+	}
+
+	// For file replacements, archive the current file before the new one can overwrite it
+	replacing := event.ReplaceTrackID != "" && track.S3Key != ""
+	previousKey := track.S3Key
+	now := time.Now()
+	if replacing {
+		archiveKey := models.ArchivedTrackFileKey(event.UserID, event.TrackID, now, sanitize.Extension(previousKey))
+		if err := copyObject(ctx, event.BucketName, previousKey, archiveKey); err != nil {
+			return nil, fmt.Errorf("failed to archive previous file: %w", err)
+		}
+		expiresAt := now.Add(models.ReplacedFileGracePeriod)
+		track.ArchivedS3Key = archiveKey
+		track.ArchiveExpiresAt = &expiresAt
+		track.FileReplacedAt = &now
+	}
+
+	// Copy file to new location
+	if err := copyObject(ctx, event.BucketName, event.SourceKey, destKey); err != nil {
 		return nil, fmt.Errorf("failed to copy file: %w", err)
 	}
 
@@ -97,12 +120,18 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		fmt.Printf("Warning: failed to delete original file: %v\n", err)
 	}
 
-	// Update track with new S3 key
-	track, err := repo.GetTrack(ctx, event.UserID, event.TrackID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get track: %w", err)
+	// A replacement with a different extension leaves the old file behind; it is archived already
+	if replacing && previousKey != destKey {
+		_, err = s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: &event.BucketName,
+			Key:    &previousKey,
+		})
+		if err != nil {
+			fmt.Printf("Warning: failed to delete replaced file: %v\n", err)
+		}
 	}
 
+	// Update track with new S3 key
 	track.S3Key = destKey
 	if err := repo.UpdateTrack(ctx, *track); err != nil {
 		return nil, fmt.Errorf("failed to update track S3 key: %w", err)
@@ -116,6 +145,17 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	return &Response{NewKey: destKey}, nil
 }
 
+// copyObject copies an object within the media bucket
+func copyObject(ctx context.Context, bucket, sourceKey, destKey string) error {
+	copySource := fmt.Sprintf("%s/%s", bucket, sourceKey)
+	_, err := s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     &bucket,
+		CopySource: aws.String(copySource),
+		Key:        &destKey,
+	})
+	return err
+}
+
 func main() {
 	lambda.Start(handleRequest)
 }
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/gvasels/personal-music-searchengine/internal/validation"
+)
+
+const (
+	// previewBatchSize bounds the clip jobs started per run so one run never
+	// floods the MediaConvert queue; the schedule works through the backlog
+	previewBatchSize = 25
+	// previewPageSize is how many public tracks are read per page
+	previewPageSize = 100
+)
+
+// Response reports how many public tracks were checked and clips started
+type Response struct {
+	Checked int `json:"checked"`
+	Started int `json:"started"`
+	Failed  int `json:"failed"`
+}
+
+var (
+	// transcodeConfig, deps and transcoder are built on the first invocation
+	// rather than in init()
+	transcodeConfig = bootstrap.NewLazy("configuration", func(context.Context) (*appconfig.Transcode, error) {
+		return appconfig.LoadTranscode()
+	})
+	deps = bootstrap.NewProcessorWith(func() (*appconfig.Processor, error) {
+		appCfg, err := transcodeConfig.Get(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &appCfg.Processor, nil
+	})
+	// transcoder builds the transcode service, or nil when MediaConvert is not configured
+	transcoder = bootstrap.NewLazy("transcode service", newTranscodeService)
+)
+
+func newTranscodeService(ctx context.Context) (*service.TranscodeService, error) {
+	appCfg, err := transcodeConfig.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !appCfg.Enabled() {
+		return nil, nil
+	}
+
+	cfg, err := deps.AWS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mcClient := mediaconvert.NewFromConfig(cfg, func(o *mediaconvert.Options) {
+		o.BaseEndpoint = &appCfg.MediaConvertEndpoint
+	})
+	return service.NewTranscodeService(mcClient, appCfg.MediaBucketName, appCfg.MediaConvertRoleARN, appCfg.MediaConvertQueueARN), nil
+}
+
+// handleRequest runs on an EventBridge schedule and starts preview clip jobs
+// for up to previewBatchSize public tracks that have no clip yet. Tracks are
+// claimed before their job starts, so overlapping runs never cut the same
+// clip twice.
+func handleRequest(ctx context.Context) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
+	defer cancel()
+
+	transcodeSvc, err := transcoder.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if transcodeSvc == nil {
+		fmt.Println("MediaConvert configuration incomplete, preview clips are not generated")
+		return &Response{}, nil
+	}
+	repo, err := deps.Repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response{}
+	cursor := ""
+	for response.Started+response.Failed < previewBatchSize {
+		page, err := repo.ListPublicTracks(ctx, previewPageSize, cursor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list public tracks: %w", err)
+		}
+		for _, track := range page.Items {
+			response.Checked++
+			if !track.NeedsPreview() || track.S3Key == "" {
+				continue
+			}
+			started, err := startPreview(ctx, transcodeSvc, track)
+			switch {
+			case err != nil:
+				fmt.Printf("Warning: failed to start preview clip of track %s: %v\n", track.ID, err)
+				response.Failed++
+			case started:
+				response.Started++
+			}
+			if response.Started+response.Failed >= previewBatchSize {
+				break
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if response.Started > 0 || response.Failed > 0 {
+		fmt.Printf("Started %d preview clips (%d failed) after checking %d public tracks\n", response.Started, response.Failed, response.Checked)
+	}
+	return response, nil
+}
+
+// startPreview claims a track and starts its clip job. A track claimed by
+// another run is skipped and reports false.
+func startPreview(ctx context.Context, transcodeSvc *service.TranscodeService, track models.Track) (bool, error) {
+	if err := claimTrack(ctx, track); err != nil {
+		var conditionFailed *dynamodbtypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	start, length := track.PreviewClip()
+	job, err := transcodeSvc.StartPreview(ctx, service.PreviewRequest{
+		TrackID: track.ID,
+		UserID:  track.UserID,
+		S3Key:   track.S3Key,
+		Start:   start,
+		Length:  length,
+	})
+	if err != nil {
+		if markErr := setPreviewStatus(ctx, track, models.HLSStatusFailed, ""); markErr != nil {
+			fmt.Printf("Warning: failed to mark preview of track %s failed: %v\n", track.ID, markErr)
+		}
+		return false, err
+	}
+
+	return true, setPreviewStatus(ctx, track, models.HLSStatusProcessing, job.JobID)
+}
+
+// claimTrack marks a track's preview as processing, unless another run
+// already has
+func claimTrack(ctx context.Context, track models.Track) error {
+	appCfg, err := deps.Config(ctx)
+	if err != nil {
+		return err
+	}
+	dynamoClient, err := deps.DynamoDB(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(appCfg.DynamoDBTableName),
+		Key:                 trackKey(track),
+		UpdateExpression:    aws.String("SET previewStatus = :status"),
+		ConditionExpression: aws.String("attribute_exists(PK) AND attribute_not_exists(previewStatus)"),
+		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+			":status": &dynamodbtypes.AttributeValueMemberS{Value: string(models.HLSStatusProcessing)},
+		},
+	})
+	return err
+}
+
+// setPreviewStatus records the preview status of a claimed track and, once
+// its job started, the job ID the completion handler matches
+func setPreviewStatus(ctx context.Context, track models.Track, status models.HLSStatus, jobID string) error {
+	appCfg, err := deps.Config(ctx)
+	if err != nil {
+		return err
+	}
+	dynamoClient, err := deps.DynamoDB(ctx)
+	if err != nil {
+		return err
+	}
+
+	updateExpr := "SET previewStatus = :status"
+	exprValues := map[string]dynamodbtypes.AttributeValue{
+		":status": &dynamodbtypes.AttributeValueMemberS{Value: string(status)},
+	}
+	if jobID != "" {
+		updateExpr += ", previewJobId = :job"
+		exprValues[":job"] = &dynamodbtypes.AttributeValueMemberS{Value: jobID}
+	}
+
+	_, err = dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(appCfg.DynamoDBTableName),
+		Key:                       trackKey(track),
+		UpdateExpression:          aws.String(updateExpr),
+		ExpressionAttributeValues: exprValues,
+	})
+	return err
+}
+
+func trackKey(track models.Track) map[string]dynamodbtypes.AttributeValue {
+	return map[string]dynamodbtypes.AttributeValue{
+		"PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", track.UserID)},
+		"SK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("TRACK#%s", track.ID)},
+	}
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/scan"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+	"github.com/gvasels/personal-music-searchengine/internal/validation"
+)
+
+// scanTimeout leaves clamscan time to load its signature database; the
+// Lambda's own timeout is set above it in lambda-processors.tf
+const scanTimeout = 280 * time.Second
+
+// Event represents the input from Step Functions
+type Event struct {
+	UploadID   string `json:"uploadId"`
+	UserID     string `json:"userId"`
+	S3Key      string `json:"s3Key"`
+	FileName   string `json:"fileName"`
+	BucketName string `json:"bucketName"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// Response represents the output to Step Functions, which fails the upload
+// with Reason when Clean is false
+type Response struct {
+	Clean         bool   `json:"clean"`
+	Engine        string `json:"engine,omitempty"`
+	Signature     string `json:"signature,omitempty"`
+	QuarantineKey string `json:"quarantineKey,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+var (
+	// scanConfig, deps and scanner are built on the first invocation rather
+	// than in init()
+	scanConfig = bootstrap.NewLazy("configuration", func(context.Context) (*appconfig.Scan, error) {
+		return appconfig.LoadScan()
+	})
+	deps = bootstrap.NewProcessorWith(func() (*appconfig.Processor, error) {
+		appCfg, err := scanConfig.Get(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &appCfg.Processor, nil
+	})
+	// scanner is nil when scanning is disabled
+	scanner = bootstrap.NewLazy("scanner", newScanner)
+)
+
+func newScanner(ctx context.Context) (scan.Scanner, error) {
+	appCfg, err := scanConfig.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch appCfg.Scanner {
+	case appconfig.ScannerClamAV:
+		return scan.NewClamScan(appCfg.ClamScanPath, appCfg.ClamDatabaseDir), nil
+	case appconfig.ScannerHTTP:
+		return scan.NewHTTPScanner(appCfg.ScannerURL, nil), nil
+	default:
+		fmt.Println("SCANNER not set, uploads are not scanned")
+		return nil, nil
+	}
+}
+
+func handleRequest(ctx context.Context, event Event) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+	fmt.Printf("Processing upload %s\n", event.UploadID)
+
+	s, err := scanner.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return &Response{Clean: true}, nil
+	}
+
+	if err := validation.ValidateUUID(event.UploadID, "uploadId"); err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateUUID(event.UserID, "userId"); err != nil {
+		return nil, err
+	}
+
+	s3Client, err := deps.S3(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &event.BucketName,
+		Key:    &event.S3Key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+	defer obj.Body.Close()
+
+	verdict, err := s.Scan(ctx, obj.Body, event.FileName)
+	if err != nil {
+		// Fail closed: an upload that could not be scanned is not processed
+		return nil, fmt.Errorf("failed to scan upload: %w", err)
+	}
+	if verdict.Clean {
+		return &Response{Clean: true, Engine: verdict.Engine}, nil
+	}
+
+	fmt.Printf("Upload %s of user %s flagged by %s: %s\n", event.UploadID, event.UserID, verdict.Engine, verdict.Signature)
+
+	quarantineKey, err := quarantine(ctx, s3Client, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Clean:         false,
+		Engine:        verdict.Engine,
+		Signature:     verdict.Signature,
+		QuarantineKey: quarantineKey,
+		Reason:        verdict.Reason(),
+	}, nil
+}
+
+// quarantine moves a flagged upload under quarantine/ for review
+func quarantine(ctx context.Context, s3Client repository.S3Client, event Event) (string, error) {
+	quarantineKey := models.QuarantineKey(event.UserID, event.UploadID, path.Base(event.S3Key))
+	_, err := s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     &event.BucketName,
+		CopySource: aws.String(fmt.Sprintf("%s/%s", event.BucketName, event.S3Key)),
+		Key:        &quarantineKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to quarantine upload: %w", err)
+	}
+
+	_, err = s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &event.BucketName,
+		Key:    &event.S3Key,
+	})
+	if err != nil {
+		// The upload is never processed, so the leftover copy is only clutter
+		fmt.Printf("Warning: failed to delete quarantined upload: %v\n", err)
+	}
+	return quarantineKey, nil
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
@@ -3,15 +3,14 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 	"github.com/gvasels/personal-music-searchengine/internal/validation"
 )
 
@@ -23,6 +22,8 @@ type Event struct {
 	Status    string `json:"status"`
 	Error     *Error `json:"error,omitempty"`
 	TableName string `json:"tableName"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
 }
 
 // Error represents error information from Step Functions
@@ -37,52 +38,66 @@ type Response struct {
 	Message string `json:"message"`
 }
 
-var repo repository.Repository
-
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.Background())
-	if err != nil {
-		panic(fmt.Sprintf("failed to load AWS config: %v", err))
-	}
+// deps builds the repository on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
 
-	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
-	if tableName == "" {
-		tableName = "MusicLibrary"
-	}
+var repo repository.Repository
 
-	dynamoClient := dynamodb.NewFromConfig(cfg)
-	repo = repository.NewDynamoDBRepository(dynamoClient, tableName)
+// setup resolves the repository used by handleRequest
+func setup(ctx context.Context) (err error) {
+	repo, err = deps.Repository(ctx)
+	return err
 }
 
 func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Add timeout to context (5 seconds less than Lambda timeout)
 	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
 	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+	fmt.Printf("Processing upload %s\n", event.UploadID)
+
+	if err := setup(ctx); err != nil {
+		return nil, err
+	}
 
 	var status models.UploadStatus
 	var errorMsg string
+	var reason models.UploadFailureReason
 
 	switch event.Status {
 	case "COMPLETED":
 		status = models.UploadStatusCompleted
 	case "FAILED":
 		status = models.UploadStatusFailed
+		reason = models.UploadFailedProcessing
 		if event.Error != nil {
 			errorMsg = event.Error.Error
 			if event.Error.Cause != "" {
 				errorMsg = fmt.Sprintf("%s: %s", errorMsg, event.Error.Cause)
 			}
+			reason = models.ClassifyUploadFailure(event.Error.Error, event.Error.Cause)
 		}
 	default:
 		status = models.UploadStatus(event.Status)
 	}
 
 	// Update upload status
-	err := repo.UpdateUploadStatus(ctx, event.UserID, event.UploadID, status, event.TrackID, errorMsg)
+	err := repo.UpdateUploadStatus(ctx, event.UserID, event.UploadID, status, errorMsg, event.TrackID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update upload status: %w", err)
 	}
 
+	// If failed, record the reason users see, translated when it is read
+	if status == models.UploadStatusFailed {
+		upload, err := repo.GetUpload(ctx, event.UserID, event.UploadID)
+		if err == nil {
+			upload.FailureReason = reason
+			if err := repo.UpdateUpload(ctx, *upload); err != nil {
+				fmt.Printf("Warning: failed to record failure reason: %v\n", err)
+			}
+		}
+	}
+
 	// If completed, also update the completion timestamp and step flags
 	if status == models.UploadStatusCompleted {
 		upload, err := repo.GetUpload(ctx, event.UserID, event.UploadID)
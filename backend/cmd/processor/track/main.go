@@ -2,17 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/google/uuid"
 
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 	"github.com/gvasels/personal-music-searchengine/internal/validation"
 )
 
@@ -27,21 +28,59 @@ type Event struct {
 	Analysis   *AnalysisResult        `json:"analysis"`
 	BucketName string                 `json:"bucketName"`
 	TableName  string                 `json:"tableName"`
+	// ReplaceTrackID is set when the upload replaces the file of an existing track
+	ReplaceTrackID string `json:"replaceTrackId,omitempty"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
 }
 
 // CoverArtResult represents the cover art extraction result
 type CoverArtResult struct {
-	CoverArtKey string `json:"coverArtKey"`
+	CoverArtKey string             `json:"coverArtKey"`
+	Artwork     []models.Artwork   `json:"artwork,omitempty"`
+	CoverStyle  *models.CoverStyle `json:"coverStyle,omitempty"`
+	// ThumbnailKey is not part of CoverStyle's JSON, so it travels separately
+	ThumbnailKey string `json:"thumbnailKey,omitempty"`
+}
+
+// style returns the cover's style with its thumbnail attached
+func (r *CoverArtResult) style() *models.CoverStyle {
+	if r.CoverStyle != nil {
+		r.CoverStyle.ThumbnailKey = r.ThumbnailKey
+	}
+	return r.CoverStyle
+}
+
+// albumCoverSetter is implemented by repositories that can give an album
+// without cover art the cover of one of its tracks
+type albumCoverSetter interface {
+	SetAlbumCover(ctx context.Context, userID, albumID, coverArtKey string, style *models.CoverStyle) error
 }
 
 // AnalysisResult represents the audio analysis result
 type AnalysisResult struct {
-	BPM        int    `json:"bpm,omitempty"`
-	MusicalKey string `json:"musicalKey,omitempty"`
-	KeyMode    string `json:"keyMode,omitempty"`
-	KeyCamelot string `json:"keyCamelot,omitempty"`
-	Analyzed   bool   `json:"analyzed"`
-	Error      string `json:"error,omitempty"`
+	BPM           int                   `json:"bpm,omitempty"`
+	MusicalKey    string                `json:"musicalKey,omitempty"`
+	KeyMode       string                `json:"keyMode,omitempty"`
+	KeyCamelot    string                `json:"keyCamelot,omitempty"`
+	KeyConfidence float64               `json:"keyConfidence,omitempty"`
+	KeyCandidates []models.KeyCandidate `json:"keyCandidates,omitempty"`
+	PreviewStart  int                   `json:"previewStart,omitempty"`
+	Analyzed      bool                  `json:"analyzed"`
+	Error         string                `json:"error,omitempty"`
+}
+
+// trackAnalysis returns the analysis result as applied to a track
+func (r *AnalysisResult) trackAnalysis() models.TrackAnalysis {
+	return models.TrackAnalysis{
+		BPM:           r.BPM,
+		MusicalKey:    r.MusicalKey,
+		KeyMode:       r.KeyMode,
+		KeyCamelot:    r.KeyCamelot,
+		KeyConfidence: r.KeyConfidence,
+		KeyCandidates: r.KeyCandidates,
+		PreviewStart:  r.PreviewStart,
+	}
 }
 
 // Response represents the output to Step Functions
@@ -50,27 +89,39 @@ type Response struct {
 	AlbumID string `json:"albumId,omitempty"`
 }
 
+// deps builds the repository on the first invocation rather than in init()
+var deps = bootstrap.NewProcessor()
+
 var repo repository.Repository
 
-func init() {
-	cfg, err := config.LoadDefaultConfig(context.Background())
-	if err != nil {
-		panic(fmt.Sprintf("failed to load AWS config: %v", err))
-	}
+// setup resolves the repository used by handleRequest
+func setup(ctx context.Context) (err error) {
+	repo, err = deps.Repository(ctx)
+	return err
+}
 
-	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
-	if tableName == "" {
-		tableName = "MusicLibrary"
+// withSortLocale returns ctx carrying the sort locale of the library the
+// upload belongs to, or ctx unchanged (the root collation) if the settings
+// cannot be read
+func withSortLocale(ctx context.Context, userID string) context.Context {
+	settings, err := repo.GetUserSettings(ctx, userID)
+	if err != nil {
+		fmt.Printf("Warning: failed to read sort locale: %v\n", err)
+		return ctx
 	}
-
-	dynamoClient := dynamodb.NewFromConfig(cfg)
-	repo = repository.NewDynamoDBRepository(dynamoClient, tableName)
+	return collation.WithLocale(ctx, settings.Library.SortLocale)
 }
 
 func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Add timeout to context (5 seconds less than Lambda timeout)
 	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
 	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+	fmt.Printf("Processing upload %s\n", event.UploadID)
+
+	if err := setup(ctx); err != nil {
+		return nil, err
+	}
 
 	// Validate input UUIDs to prevent injection attacks
 	if err := validation.ValidateUUID(event.UserID, "userId"); err != nil {
@@ -80,6 +131,13 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		return nil, err
 	}
 
+	if event.ReplaceTrackID != "" {
+		return replaceTrackFile(ctx, event)
+	}
+
+	// The track and any album created for it sort in the library's order
+	ctx = withSortLocale(ctx, event.UserID)
+
 	trackID := uuid.New().String()
 	now := time.Now()
 
@@ -106,22 +164,23 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	track.CreatedAt = now
 	track.UpdatedAt = now
 
-	// Set cover art key if available
+	// Set cover art key and the other embedded images if available
 	if event.CoverArt != nil && event.CoverArt.CoverArtKey != "" {
 		track.CoverArtKey = event.CoverArt.CoverArtKey
+		track.Artwork = event.CoverArt.Artwork
+		track.CoverStyle = event.CoverArt.style()
 	}
 
 	// Set audio analysis results if available
 	if event.Analysis != nil && event.Analysis.Analyzed {
-		track.BPM = event.Analysis.BPM
-		track.MusicalKey = event.Analysis.MusicalKey
-		track.KeyMode = event.Analysis.KeyMode
-		track.KeyCamelot = event.Analysis.KeyCamelot
+		track.ApplyAnalysis(event.Analysis.trackAnalysis())
 	}
 
 	// Set additional metadata fields if available
 	if event.Metadata != nil {
 		track.Bitrate = event.Metadata.Bitrate
+		track.Chapters = event.Metadata.Chapters
+		track.ContentHash = event.Metadata.ContentHash
 	}
 
 	// Create the track
@@ -144,12 +203,96 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 			fmt.Printf("Warning: failed to create/update album: %v\n", err)
 		} else {
 			response.AlbumID = album.ID
+			setAlbumCover(ctx, album, track)
 		}
 	}
 
 	return response, nil
 }
 
+// setAlbumCover gives an album without cover art the cover of its first track
+// that has one, so album listings get artwork and its style too
+func setAlbumCover(ctx context.Context, album *models.Album, track models.Track) {
+	if album.CoverArtKey != "" || track.CoverArtKey == "" {
+		return
+	}
+	setter, ok := repo.(albumCoverSetter)
+	if !ok {
+		return
+	}
+	// ErrConflict means another track of the album set it first
+	err := setter.SetAlbumCover(ctx, album.UserID, album.ID, track.CoverArtKey, track.CoverStyle)
+	if err != nil && !errors.Is(err, repository.ErrConflict) {
+		fmt.Printf("Warning: failed to set album cover: %v\n", err)
+	}
+}
+
+// replaceTrackFile refreshes the file-derived properties of an existing track from a
+// replacement upload. Everything the user curated (title, tags, play counts,
+// visibility, hot cues) is preserved; the S3 key is swapped later by the mover.
+func replaceTrackFile(ctx context.Context, event Event) (*Response, error) {
+	if err := validation.ValidateUUID(event.ReplaceTrackID, "replaceTrackId"); err != nil {
+		return nil, err
+	}
+
+	track, err := repo.GetTrack(ctx, event.UserID, event.ReplaceTrackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track to replace: %w", err)
+	}
+
+	if event.Metadata != nil {
+		if event.Metadata.Format != "" {
+			track.Format = models.AudioFormat(event.Metadata.Format)
+		}
+		if event.Metadata.Duration != 0 {
+			track.Duration = event.Metadata.Duration
+		}
+		track.Bitrate = event.Metadata.Bitrate
+		track.SampleRate = event.Metadata.SampleRate
+		track.Channels = event.Metadata.Channels
+		track.Chapters = event.Metadata.Chapters
+		track.ContentHash = event.Metadata.ContentHash
+	}
+
+	if upload, err := repo.GetUpload(ctx, event.UserID, event.UploadID); err == nil {
+		track.FileSize = upload.FileSize
+	}
+
+	// Keep user-supplied cover art; only fill it in when the track has none
+	if track.CoverArtKey == "" && event.CoverArt != nil && event.CoverArt.CoverArtKey != "" {
+		track.CoverArtKey = event.CoverArt.CoverArtKey
+		track.CoverStyle = event.CoverArt.style()
+	}
+	if len(track.Artwork) == 0 && event.CoverArt != nil {
+		track.Artwork = event.CoverArt.Artwork
+	}
+
+	// Values the owner corrected are kept
+	if event.Analysis != nil && event.Analysis.Analyzed {
+		track.ApplyAnalysis(event.Analysis.trackAnalysis())
+	}
+
+	// HLS renditions belong to the old file and are regenerated by the pipeline
+	track.HLSStatus = models.HLSStatusPending
+	track.HLSPlaylistKey = ""
+	track.HLSJobID = ""
+	track.HLSTranscodedAt = nil
+	// So does the preview clip; the preview processor cuts a new one
+	track.PreviewStatus = ""
+	track.PreviewKey = ""
+	track.PreviewJobID = ""
+
+	if err := repo.UpdateTrack(ctx, *track); err != nil {
+		return nil, fmt.Errorf("failed to update track: %w", err)
+	}
+
+	if err := repo.UpdateUploadStep(ctx, event.UserID, event.UploadID, models.StepCreateTrack, true); err != nil {
+		fmt.Printf("Warning: failed to update step progress: %v\n", err)
+	}
+
+	return &Response{TrackID: track.ID, AlbumID: track.AlbumID}, nil
+}
+
 func getOrDefault(meta *models.UploadMetadata, field, defaultVal string) string {
 	if meta == nil {
 		return defaultVal
@@ -2,17 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	awslambda "github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
+	"github.com/gvasels/personal-music-searchengine/internal/alerting"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/search"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
 	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 	"github.com/gvasels/personal-music-searchengine/internal/validation"
 )
 
@@ -27,21 +37,61 @@ type Response struct {
 }
 
 var (
-	dynamoClient *dynamodb.Client
-	tableName    string
+	// transcodeConfig and deps are built on the first invocation rather than in init()
+	transcodeConfig = bootstrap.NewLazy("configuration", func(context.Context) (*appconfig.Transcode, error) {
+		return appconfig.LoadTranscode()
+	})
+	deps = bootstrap.NewProcessorWith(func() (*appconfig.Processor, error) {
+		appCfg, err := transcodeConfig.Get(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &appCfg.Processor, nil
+	})
+	// transcoder starts fallback jobs, or is nil when MediaConvert is not configured
+	transcoder = bootstrap.NewLazy("transcode service", func(ctx context.Context) (*service.TranscodeService, error) {
+		appCfg, err := transcodeConfig.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !appCfg.Enabled() {
+			return nil, nil
+		}
+		cfg, err := deps.AWS(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s3Client, err := deps.S3(ctx)
+		if err != nil {
+			return nil, err
+		}
+		mcClient := mediaconvert.NewFromConfig(cfg, func(o *mediaconvert.Options) {
+			o.BaseEndpoint = &appCfg.MediaConvertEndpoint
+		})
+		transcodeSvc := service.NewTranscodeService(mcClient, appCfg.MediaBucketName, appCfg.MediaConvertRoleARN, appCfg.MediaConvertQueueARN)
+		transcodeSvc.SetBulkQueue(appCfg.MediaConvertBulkQueueARN)
+		// The failed job's partial output is cleared before the fallback job
+		transcodeSvc.SetOutputCleaner(repository.NewS3Repository(s3Client, nil, appCfg.MediaBucketName))
+		return transcodeSvc, nil
+	})
 )
 
-func init() {
-	tableName = os.Getenv("DYNAMODB_TABLE_NAME")
-
-	cfg, err := config.LoadDefaultConfig(context.Background())
+// indexClient builds a search client, or nil when NIXIESEARCH_FUNCTION_NAME is not set
+var indexClient = bootstrap.NewLazy("search client", func(ctx context.Context) (*search.Client, error) {
+	appCfg, err := deps.Config(ctx)
 	if err != nil {
-		fmt.Printf("Failed to load AWS config: %v\n", err)
-		return
+		return nil, err
+	}
+	if appCfg.NixiesearchFunctionName == "" {
+		return nil, nil
 	}
 
-	dynamoClient = dynamodb.NewFromConfig(cfg)
-}
+	cfg, err := deps.AWS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return search.NewClient(awslambda.NewFromConfig(cfg), appCfg.NixiesearchFunctionName), nil
+})
 
 func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Add timeout to context
@@ -53,6 +103,7 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Extract track ID and user ID from job tags
 	trackID := detail.UserMetadata["trackId"]
 	userID := detail.UserMetadata["userId"]
+	ctx = tenant.WithID(ctx, detail.UserMetadata["tenantId"])
 
 	if trackID == "" || userID == "" {
 		return &Response{
@@ -61,6 +112,11 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		}, nil
 	}
 
+	// Preview clip jobs share the completion event with HLS jobs
+	if detail.UserMetadata[service.JobOutputKey] == service.JobOutputPreview {
+		return handlePreview(ctx, userID, trackID, detail)
+	}
+
 	// Handle based on job status
 	switch detail.Status {
 	case "COMPLETE":
@@ -85,23 +141,32 @@ func handleSuccess(ctx context.Context, userID, trackID string, detail service.M
 			// Extract the S3 key from the full path
 			// Format: s3://bucket/hls/userId/trackId/master.m3u8
 			playlistKey = extractS3Key(og.PlaylistFilePaths[0])
+			// Tracks store logical keys; drop the physical tenant prefix
+			if tenantID, ok := tenant.FromContext(ctx); ok {
+				playlistKey = strings.TrimPrefix(playlistKey, tenant.ObjectPrefix(tenantID))
+			}
 			break
 		}
 	}
 
 	if playlistKey == "" {
 		// Fallback to constructed key
-		playlistKey = service.BuildHLSPlaylistKey(userID, trackID)
+		key, err := service.BuildHLSPlaylistKey(userID, trackID)
+		if err != nil {
+			return nil, err
+		}
+		playlistKey = key
 	}
 
 	// Update track in DynamoDB
-	if err := updateTrackHLSStatus(ctx, userID, trackID, models.HLSStatusReady, playlistKey, ""); err != nil {
+	if _, err := updateTrackHLSStatus(ctx, userID, trackID, models.HLSStatusReady, playlistKey, ""); err != nil {
 		return &Response{
 			TrackID: trackID,
 			Status:  "failed",
 			Reason:  fmt.Sprintf("db_update_failed: %v", err),
 		}, nil
 	}
+	syncSearchStatus(ctx, trackID, models.HLSStatusReady)
 
 	return &Response{
 		TrackID: trackID,
@@ -115,14 +180,25 @@ func handleFailure(ctx context.Context, userID, trackID string, detail service.M
 		errorMsg = fmt.Sprintf("Job failed with code %d", detail.ErrorCode)
 	}
 
+	// Codec and container errors get another try with tolerant settings
+	// before the track is marked failed
+	if detail.Status == "ERROR" {
+		if resp, retried := retryWithFallback(ctx, userID, trackID, detail, errorMsg); retried {
+			return resp, nil
+		}
+	}
+
 	// Update track in DynamoDB
-	if err := updateTrackHLSStatus(ctx, userID, trackID, models.HLSStatusFailed, "", errorMsg); err != nil {
+	failures, err := updateTrackHLSStatus(ctx, userID, trackID, models.HLSStatusFailed, "", errorMsg)
+	if err != nil {
 		return &Response{
 			TrackID: trackID,
 			Status:  "failed",
 			Reason:  fmt.Sprintf("db_update_failed: %v", err),
 		}, nil
 	}
+	syncSearchStatus(ctx, trackID, models.HLSStatusFailed)
+	alertRepeatedFailures(ctx, userID, trackID, failures, errorMsg)
 
 	return &Response{
 		TrackID: trackID,
@@ -131,10 +207,180 @@ func handleFailure(ctx context.Context, userID, trackID string, detail service.M
 	}, nil
 }
 
-func updateTrackHLSStatus(ctx context.Context, userID, trackID string, status models.HLSStatus, playlistKey, errorMsg string) error {
-	if dynamoClient == nil || tableName == "" {
-		return fmt.Errorf("DynamoDB not configured")
+// retryWithFallback starts a fallback job for an HLS job that failed on a
+// codec or container error. It reports false when the failure stands: another
+// kind of error, no retries left, or a retry that could not start.
+func retryWithFallback(ctx context.Context, userID, trackID string, detail service.MediaConvertEventDetail, errorMsg string) (*Response, bool) {
+	appCfg, err := transcodeConfig.Get(ctx)
+	if err != nil || appCfg.FallbackRetries <= 0 {
+		return nil, false
+	}
+
+	repo, err := deps.Repository(ctx)
+	if err != nil {
+		fmt.Printf("Warning: fallback transcode of track %s not started: %v\n", trackID, err)
+		return nil, false
+	}
+	track, err := repo.GetTrack(ctx, userID, trackID)
+	if err != nil {
+		fmt.Printf("Warning: fallback transcode of track %s not started: %v\n", trackID, err)
+		return nil, false
+	}
+	if !service.ShouldRetryTranscode(detail, track.HLSRetries, appCfg.FallbackRetries) {
+		return nil, false
+	}
+
+	transcodeSvc, err := transcoder.Get(ctx)
+	if err != nil || transcodeSvc == nil {
+		fmt.Printf("Warning: fallback transcode of track %s not started: transcoding is not configured\n", trackID)
+		return nil, false
+	}
+	tenantID, _ := tenant.FromContext(ctx)
+	job, err := transcodeSvc.StartTranscode(ctx, service.TranscodeRequest{
+		TrackID:  trackID,
+		UserID:   userID,
+		S3Key:    track.S3Key,
+		TenantID: tenantID,
+		Priority: service.TranscodePriority(detail.UserMetadata["priority"]),
+		Fallback: true,
+	})
+	if err != nil {
+		fmt.Printf("Warning: fallback transcode of track %s not started: %v\n", trackID, err)
+		return nil, false
+	}
+
+	// The fallback job's own completion event settles the track either way
+	if err := updateTrackRetry(ctx, userID, trackID, job.JobID, errorMsg); err != nil {
+		fmt.Printf("Warning: failed to record fallback transcode of track %s: %v\n", trackID, err)
+	}
+	return &Response{
+		TrackID: trackID,
+		Status:  "retrying",
+		Reason:  errorMsg,
+	}, true
+}
+
+// updateTrackRetry records a started fallback job on the track and counts the
+// retry. The failed job's error is kept until the fallback finishes.
+func updateTrackRetry(ctx context.Context, userID, trackID, jobID, errorMsg string) error {
+	appCfg, err := deps.Config(ctx)
+	if err != nil {
+		return err
 	}
+	dynamoClient, err := deps.DynamoDB(ctx)
+	if err != nil {
+		return err
+	}
+	tableName := appCfg.DynamoDBTableName
+
+	_, err = dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &tableName,
+		Key: map[string]dynamodbtypes.AttributeValue{
+			"PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("TRACK#%s", trackID)},
+		},
+		UpdateExpression: aws.String("SET hlsStatus = :status, hlsJobId = :job, hlsError = :error, updatedAt = :now ADD hlsRetries :one"),
+		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+			":status": &dynamodbtypes.AttributeValueMemberS{Value: string(models.HLSStatusProcessing)},
+			":job":    &dynamodbtypes.AttributeValueMemberS{Value: jobID},
+			":error":  &dynamodbtypes.AttributeValueMemberS{Value: errorMsg},
+			":now":    &dynamodbtypes.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":one":    &dynamodbtypes.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	return err
+}
+
+// handlePreview records the outcome of a preview clip job. Failed clips are
+// not retried; the preview processor skips tracks with a preview status.
+func handlePreview(ctx context.Context, userID, trackID string, detail service.MediaConvertEventDetail) (*Response, error) {
+	var status models.HLSStatus
+	switch detail.Status {
+	case "COMPLETE":
+		status = models.HLSStatusReady
+	case "ERROR", "CANCELED":
+		status = models.HLSStatusFailed
+		fmt.Printf("Preview clip of track %s failed: %s (code %d)\n", trackID, detail.ErrorMessage, detail.ErrorCode)
+	default:
+		return &Response{
+			TrackID: trackID,
+			Status:  "ignored",
+			Reason:  fmt.Sprintf("status_%s", detail.Status),
+		}, nil
+	}
+
+	previewKey, err := service.BuildPreviewKey(userID, trackID)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateTrackPreviewStatus(ctx, userID, trackID, detail.JobID, status, previewKey); err != nil {
+		var conditionFailed *dynamodbtypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			// The file was replaced or the track deleted while the clip was cut
+			return &Response{TrackID: trackID, Status: "ignored", Reason: "stale_preview_job"}, nil
+		}
+		return &Response{
+			TrackID: trackID,
+			Status:  "failed",
+			Reason:  fmt.Sprintf("db_update_failed: %v", err),
+		}, nil
+	}
+
+	return &Response{
+		TrackID: trackID,
+		Status:  "preview_" + strings.ToLower(string(status)),
+	}, nil
+}
+
+// updateTrackPreviewStatus records the outcome of a preview clip job on the
+// track, provided the job is still the track's current preview job
+func updateTrackPreviewStatus(ctx context.Context, userID, trackID, jobID string, status models.HLSStatus, previewKey string) error {
+	appCfg, err := deps.Config(ctx)
+	if err != nil {
+		return err
+	}
+	dynamoClient, err := deps.DynamoDB(ctx)
+	if err != nil {
+		return err
+	}
+	tableName := appCfg.DynamoDBTableName
+
+	updateExpr := "SET previewStatus = :status, updatedAt = :now"
+	exprValues := map[string]dynamodbtypes.AttributeValue{
+		":status": &dynamodbtypes.AttributeValueMemberS{Value: string(status)},
+		":now":    &dynamodbtypes.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		":job":    &dynamodbtypes.AttributeValueMemberS{Value: jobID},
+	}
+	if status == models.HLSStatusReady {
+		updateExpr += ", previewKey = :key"
+		exprValues[":key"] = &dynamodbtypes.AttributeValueMemberS{Value: previewKey}
+	}
+
+	_, err = dynamoClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: &tableName,
+		Key: map[string]dynamodbtypes.AttributeValue{
+			"PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("TRACK#%s", trackID)},
+		},
+		UpdateExpression:          aws.String(updateExpr),
+		ConditionExpression:       aws.String("previewJobId = :job"),
+		ExpressionAttributeValues: exprValues,
+	})
+	return err
+}
+
+// updateTrackHLSStatus records the transcode outcome on the track. Failures
+// are counted until a transcode succeeds; the new count is returned.
+func updateTrackHLSStatus(ctx context.Context, userID, trackID string, status models.HLSStatus, playlistKey, errorMsg string) (int, error) {
+	appCfg, err := deps.Config(ctx)
+	if err != nil {
+		return 0, err
+	}
+	dynamoClient, err := deps.DynamoDB(ctx)
+	if err != nil {
+		return 0, err
+	}
+	tableName := appCfg.DynamoDBTableName
 
 	pk := fmt.Sprintf("USER#%s", userID)
 	sk := fmt.Sprintf("TRACK#%s", trackID)
@@ -157,6 +403,14 @@ func updateTrackHLSStatus(ctx context.Context, userID, trackID string, status mo
 		exprValues[":error"] = &dynamodbtypes.AttributeValueMemberS{Value: errorMsg}
 	}
 
+	switch status {
+	case models.HLSStatusFailed:
+		updateExpr += " ADD hlsFailures :one"
+		exprValues[":one"] = &dynamodbtypes.AttributeValueMemberN{Value: "1"}
+	case models.HLSStatusReady:
+		updateExpr += " REMOVE hlsFailures"
+	}
+
 	input := &dynamodb.UpdateItemInput{
 		TableName: &tableName,
 		Key: map[string]dynamodbtypes.AttributeValue{
@@ -165,10 +419,63 @@ func updateTrackHLSStatus(ctx context.Context, userID, trackID string, status mo
 		},
 		UpdateExpression:          aws.String(updateExpr),
 		ExpressionAttributeValues: exprValues,
+		ReturnValues:              dynamodbtypes.ReturnValueUpdatedNew,
 	}
 
-	_, err := dynamoClient.UpdateItem(ctx, input)
-	return err
+	output, err := dynamoClient.UpdateItem(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+	failures := 0
+	if n, ok := output.Attributes["hlsFailures"].(*dynamodbtypes.AttributeValueMemberN); ok {
+		failures, _ = strconv.Atoi(n.Value)
+	}
+	return failures, nil
+}
+
+// alertRepeatedFailures raises an alert once a track has failed to transcode
+// ALERT_TRANSCODE_FAILURES times in a row, and again on each later failure
+func alertRepeatedFailures(ctx context.Context, userID, trackID string, failures int, errorMsg string) {
+	appCfg, err := deps.Config(ctx)
+	if err != nil || appCfg.TranscodeFailureAlerts <= 0 || failures < appCfg.TranscodeFailureAlerts {
+		return
+	}
+	alerter, err := deps.Alerter(ctx)
+	if err != nil {
+		fmt.Printf("Warning: failed to create alerter: %v\n", err)
+		return
+	}
+	alerter.Send(ctx, alerting.Alert{
+		Kind:    alerting.KindTranscodeFailures,
+		Key:     trackID,
+		Subject: "Transcoding keeps failing",
+		Message: fmt.Sprintf("Track %s has failed to transcode %d times in a row.", trackID, failures),
+		Details: map[string]string{
+			"userId":    userID,
+			"trackId":   trackID,
+			"failures":  strconv.Itoa(failures),
+			"lastError": errorMsg,
+		},
+	})
+}
+
+// syncSearchStatus copies the new HLS status to the track's search document so
+// readiness and processing filters stay current. The track is already updated,
+// so a failure is logged rather than failing the event; the next index rebuild
+// corrects the document.
+func syncSearchStatus(ctx context.Context, trackID string, status models.HLSStatus) {
+	client, err := indexClient.Get(ctx)
+	if err == nil && client != nil {
+		_, err = client.UpdateStatus(ctx, searchproto.StatusUpdate{
+			ID:         trackID,
+			HLSStatus:  string(status),
+			Processing: status.InProgress(),
+			UpdatedAt:  time.Now(),
+		})
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to update search status of track %s: %v\n", trackID, err)
+	}
 }
 
 // extractS3Key extracts the S3 key from an S3 URI
@@ -3,16 +3,19 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/capability"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
 	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 	"github.com/gvasels/personal-music-searchengine/internal/validation"
 )
 
@@ -22,6 +25,11 @@ type Event struct {
 	UserID    string `json:"userId"`
 	S3Key     string `json:"s3Key"`
 	TableName string `json:"tableName"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
+	// Priority is "interactive" for uploads and "bulk" for re-transcodes;
+	// empty is interactive
+	Priority string `json:"priority,omitempty"`
 }
 
 // Response represents the output to Step Functions
@@ -33,44 +41,68 @@ type Response struct {
 }
 
 var (
-	transcodeSvc *service.TranscodeService
-	dynamoClient *dynamodb.Client
-	tableName    string
+	// transcodeConfig, deps and transcoder are built on the first invocation
+	// rather than in init()
+	transcodeConfig = bootstrap.NewLazy("configuration", func(context.Context) (*appconfig.Transcode, error) {
+		return appconfig.LoadTranscode()
+	})
+	deps = bootstrap.NewProcessorWith(func() (*appconfig.Processor, error) {
+		appCfg, err := transcodeConfig.Get(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &appCfg.Processor, nil
+	})
+	// transcoder builds the transcode service, or nil when MediaConvert is not configured
+	transcoder = bootstrap.NewLazy("transcode service", newTranscodeService)
+	// capabilities records why transcoding is disabled when it could not be wired
+	capabilities = capability.NewRegistry()
 )
 
-func init() {
-	mediaConvertEndpoint := os.Getenv("MEDIACONVERT_ENDPOINT")
-	mediaConvertRole := os.Getenv("MEDIACONVERT_ROLE_ARN")
-	mediaConvertQueue := os.Getenv("MEDIACONVERT_QUEUE_ARN")
-	mediaBucket := os.Getenv("MEDIA_BUCKET")
-	tableName = os.Getenv("DYNAMODB_TABLE_NAME")
+func newTranscodeService(ctx context.Context) (*service.TranscodeService, error) {
+	appCfg, err := transcodeConfig.Get(ctx)
+	if err != nil {
+		fmt.Printf("Invalid configuration: %v\n", err)
+		capabilities.Disable(capability.Transcode, fmt.Sprintf("invalid configuration: %v", err))
+		return nil, nil
+	}
 
-	if mediaConvertEndpoint == "" || mediaConvertRole == "" || mediaBucket == "" {
+	if !appCfg.Enabled() {
 		fmt.Println("MediaConvert configuration incomplete, transcoding disabled")
 		fmt.Printf("MEDIACONVERT_ENDPOINT=%s, MEDIACONVERT_ROLE_ARN=%s, MEDIA_BUCKET=%s\n",
-			mediaConvertEndpoint, mediaConvertRole, mediaBucket)
-		return
+			appCfg.MediaConvertEndpoint, appCfg.MediaConvertRoleARN, appCfg.MediaBucketName)
+		capabilities.Disable(capability.Transcode, "MEDIACONVERT_ENDPOINT, MEDIACONVERT_ROLE_ARN and MEDIA_BUCKET are required")
+		return nil, nil
 	}
 
-	cfg, err := config.LoadDefaultConfig(context.Background())
+	cfg, err := deps.AWS(ctx)
 	if err != nil {
-		fmt.Printf("Failed to load AWS config: %v\n", err)
-		return
+		return nil, err
 	}
 
 	// Create MediaConvert client with custom endpoint
 	mcClient := mediaconvert.NewFromConfig(cfg, func(o *mediaconvert.Options) {
-		o.BaseEndpoint = &mediaConvertEndpoint
+		o.BaseEndpoint = &appCfg.MediaConvertEndpoint
 	})
 
-	transcodeSvc = service.NewTranscodeService(mcClient, mediaBucket, mediaConvertRole, mediaConvertQueue)
-	dynamoClient = dynamodb.NewFromConfig(cfg)
+	s3Client, err := deps.S3(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities.Enable(capability.Transcode)
+	transcodeSvc := service.NewTranscodeService(mcClient, appCfg.MediaBucketName, appCfg.MediaConvertRoleARN, appCfg.MediaConvertQueueARN)
+	transcodeSvc.SetBulkQueue(appCfg.MediaConvertBulkQueueARN)
+	transcodeSvc.SetOutputCleaner(repository.NewS3Repository(s3Client, nil, appCfg.MediaBucketName))
+	return transcodeSvc, nil
 }
 
 func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	// Add timeout to context
 	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
 	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+	fmt.Printf("Starting transcode of track %s\n", event.TrackID)
 
 	// Validate required fields
 	if err := validation.ValidateUUID(event.TrackID, "trackId"); err != nil {
@@ -94,8 +126,14 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 		}, nil
 	}
 
-	// Check if transcode service is available
-	if transcodeSvc == nil {
+	// Check if transcode service is available. AWS setup errors are not
+	// memoized, so the next invocation tries again.
+	transcodeSvc, err := transcoder.Get(ctx)
+	if err != nil {
+		capabilities.Disable(capability.Transcode, err.Error())
+	}
+	if status := capabilities.Get(capability.Transcode); !status.Enabled || transcodeSvc == nil {
+		fmt.Printf("Transcoding skipped for track %s: %s\n", event.TrackID, status.Reason)
 		return &Response{
 			Status: "skipped",
 			Reason: "transcode_disabled",
@@ -104,9 +142,11 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 
 	// Start transcode job
 	req := service.TranscodeRequest{
-		TrackID: event.TrackID,
-		UserID:  event.UserID,
-		S3Key:   event.S3Key,
+		TrackID:  event.TrackID,
+		UserID:   event.UserID,
+		S3Key:    event.S3Key,
+		TenantID: event.TenantID,
+		Priority: service.TranscodePriority(event.Priority),
 	}
 
 	resp, err := transcodeSvc.StartTranscode(ctx, req)
@@ -118,11 +158,9 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 	}
 
 	// Update track HLS status in DynamoDB
-	if dynamoClient != nil && tableName != "" {
-		if err := updateTrackHLSStatus(ctx, event.UserID, event.TrackID, models.HLSStatusProcessing, resp.JobID, resp.PlaylistKey); err != nil {
-			fmt.Printf("Warning: failed to update track HLS status: %v\n", err)
-			// Continue - job was created successfully
-		}
+	if err := updateTrackHLSStatus(ctx, event.UserID, event.TrackID, models.HLSStatusProcessing, resp.JobID, resp.PlaylistKey); err != nil {
+		fmt.Printf("Warning: failed to update track HLS status: %v\n", err)
+		// Continue - job was created successfully
 	}
 
 	return &Response{
@@ -133,14 +171,21 @@ func handleRequest(ctx context.Context, event Event) (*Response, error) {
 }
 
 func updateTrackHLSStatus(ctx context.Context, userID, trackID string, status models.HLSStatus, jobID, playlistKey string) error {
-	if dynamoClient == nil || tableName == "" {
-		return fmt.Errorf("DynamoDB not configured")
+	appCfg, err := deps.Config(ctx)
+	if err != nil {
+		return err
+	}
+	dynamoClient, err := deps.DynamoDB(ctx)
+	if err != nil {
+		return err
 	}
+	tableName := appCfg.DynamoDBTableName
 
 	pk := fmt.Sprintf("USER#%s", userID)
 	sk := fmt.Sprintf("TRACK#%s", trackID)
 
-	updateExpr := "SET hlsStatus = :status, hlsJobId = :jobId, hlsPlaylistKey = :playlist, updatedAt = :now"
+	// A new transcode gets its own fallback retries
+	updateExpr := "SET hlsStatus = :status, hlsJobId = :jobId, hlsPlaylistKey = :playlist, updatedAt = :now REMOVE hlsRetries"
 	exprValues := map[string]dynamodbtypes.AttributeValue{
 		":status":   &dynamodbtypes.AttributeValueMemberS{Value: string(status)},
 		":jobId":    &dynamodbtypes.AttributeValueMemberS{Value: jobID},
@@ -158,7 +203,7 @@ func updateTrackHLSStatus(ctx context.Context, userID, trackID string, status mo
 		ExpressionAttributeValues: exprValues,
 	}
 
-	_, err := dynamoClient.UpdateItem(ctx, input)
+	_, err = dynamoClient.UpdateItem(ctx, input)
 	return err
 }
 
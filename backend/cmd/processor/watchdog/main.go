@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/gvasels/personal-music-searchengine/internal/alerting"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/validation"
+)
+
+// Response reports how many uploads were found stuck in processing
+type Response struct {
+	Checked int `json:"checked"`
+	Stuck   int `json:"stuck"`
+}
+
+// deps builds the repository and AWS clients on the first invocation rather
+// than in init()
+var deps = bootstrap.NewProcessor()
+
+// handleRequest runs on an EventBridge schedule and raises a pipeline_stuck
+// alert for every upload that has been processing for longer than
+// ALERT_PIPELINE_STUCK_AFTER
+func handleRequest(ctx context.Context) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
+	defer cancel()
+
+	appCfg, err := deps.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alerter, err := deps.Alerter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if alerter == nil {
+		fmt.Println("ALERT_TOPIC_ARN not set, stuck pipelines are not reported")
+		return &Response{}, nil
+	}
+	repo, err := deps.Repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uploads, err := repo.ListUploadsByStatus(ctx, models.UploadStatusProcessing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processing uploads: %w", err)
+	}
+
+	cutoff := time.Now().Add(-appCfg.PipelineStuckAfter)
+	response := &Response{Checked: len(uploads)}
+	for _, upload := range uploads {
+		if upload.UpdatedAt.After(cutoff) {
+			continue
+		}
+		response.Stuck++
+		alerter.Send(ctx, alerting.Alert{
+			Kind:    alerting.KindPipelineStuck,
+			Key:     upload.ID,
+			Subject: "Upload processing stuck",
+			Message: fmt.Sprintf("Upload %s has been processing since %s", upload.FileName, upload.UpdatedAt.Format(time.RFC3339)),
+			Details: map[string]string{
+				"uploadId": upload.ID,
+				"userId":   upload.UserID,
+				"stuckFor": time.Since(upload.UpdatedAt).Round(time.Minute).String(),
+			},
+		})
+	}
+
+	if response.Stuck > 0 {
+		fmt.Printf("Found %d of %d processing uploads stuck for over %s\n", response.Stuck, response.Checked, appCfg.PipelineStuckAfter)
+	}
+	return response, nil
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+	"github.com/gvasels/personal-music-searchengine/internal/validation"
+	"github.com/gvasels/personal-music-searchengine/internal/watermark"
+)
+
+// Response represents the outcome of one copy
+type Response struct {
+	Token  string                     `json:"token"`
+	Status models.DownloadTokenStatus `json:"status,omitempty"`
+}
+
+var (
+	// watermarkConfig, deps and embedder are built on the first invocation
+	// rather than in init()
+	watermarkConfig = bootstrap.NewLazy("configuration", func(context.Context) (*appconfig.Watermark, error) {
+		return appconfig.LoadWatermark()
+	})
+	deps = bootstrap.NewProcessorWith(func() (*appconfig.Processor, error) {
+		appCfg, err := watermarkConfig.Get(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &appCfg.Processor, nil
+	})
+	embedder = bootstrap.NewLazy("watermark embedder", func(ctx context.Context) (*watermark.Embedder, error) {
+		appCfg, err := watermarkConfig.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return watermark.NewEmbedder(appCfg.FFmpegPath)
+	})
+)
+
+// handleRequest produces the watermarked copy of one download. Failures are
+// recorded on the download token rather than retried; the recipient asks for
+// a new download.
+func handleRequest(ctx context.Context, event models.WatermarkRequest) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, validation.ProcessorTimeoutSeconds*time.Second)
+	defer cancel()
+	ctx = tenant.WithID(ctx, event.TenantID)
+
+	if err := validation.ValidateUUID(event.TrackID, "trackId"); err != nil {
+		return nil, err
+	}
+	if _, err := watermark.ParseToken(event.Token); err != nil {
+		return nil, err
+	}
+
+	baseRepo, err := deps.Repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repo, ok := baseRepo.(service.WatermarkRepository)
+	if !ok {
+		return nil, fmt.Errorf("repository does not support download tokens")
+	}
+	// Copies are only claimed and finished here; the API issues them
+	watermarkSvc := service.NewWatermarkService(repo, baseRepo, nil, nil)
+
+	token, track, err := watermarkSvc.Claim(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		// Already produced, failed or deleted: a retried invocation
+		return &Response{Token: event.Token}, nil
+	}
+
+	key := models.WatermarkedFileKey(token.OwnerID, token.Token, track.Format)
+	produceErr := produce(ctx, *track, *token, key)
+	if produceErr != nil {
+		fmt.Printf("Watermarking download %s of track %s failed: %v\n", token.Token, track.ID, produceErr)
+	}
+
+	result, err := watermarkSvc.Finish(ctx, *token, key, produceErr)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Token: result.Token, Status: result.Status}, nil
+}
+
+// produce downloads the track's audio, mixes the token's mark in and uploads
+// the copy to key
+func produce(ctx context.Context, track models.Track, token models.DownloadToken, key string) error {
+	appCfg, err := watermarkConfig.Get(ctx)
+	if err != nil {
+		return err
+	}
+	e, err := embedder.Get(ctx)
+	if err != nil {
+		return err
+	}
+	s3Client, err := deps.S3(ctx)
+	if err != nil {
+		return err
+	}
+
+	mark, err := watermark.ParseToken(token.Token)
+	if err != nil {
+		return err
+	}
+	style := watermark.Inaudible
+	if token.Mode == models.WatermarkAudible {
+		style = watermark.Audible
+	}
+
+	dir, err := os.MkdirTemp("", "watermark-*")
+	if err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ext := track.Format.Extension()
+	inputPath := filepath.Join(dir, "source"+ext)
+	outputPath := filepath.Join(dir, "marked"+ext)
+
+	if err := validation.ValidateFileSize(ctx, s3Client, appCfg.MediaBucketName, track.S3Key); err != nil {
+		return fmt.Errorf("file validation failed: %w", err)
+	}
+	obj, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(appCfg.MediaBucketName),
+		Key:    aws.String(track.S3Key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download track: %w", err)
+	}
+	err = writeFile(inputPath, obj.Body)
+	obj.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	if err := e.Embed(ctx, inputPath, outputPath, mark, style); err != nil {
+		return err
+	}
+
+	marked, err := os.Open(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open watermarked copy: %w", err)
+	}
+	defer marked.Close()
+	_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(appCfg.MediaBucketName),
+		Key:    aws.String(key),
+		Body:   marked,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload watermarked copy: %w", err)
+	}
+	return nil
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Base(path), err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return f.Close()
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
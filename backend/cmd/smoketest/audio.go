@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// toneWAV returns a 16-bit mono PCM WAV file holding a 440 Hz tone. It has
+// no tags, so the pipeline names the track after the file.
+func toneWAV(seconds, sampleRate int) []byte {
+	samples := seconds * sampleRate
+	dataSize := uint32(samples * 2)
+
+	var buf bytes.Buffer
+	buf.Grow(44 + int(dataSize))
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))           // chunk size
+	binary.Write(&buf, binary.LittleEndian, uint16(1))            // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(1))            // mono
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))   // sample rate
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*2)) // byte rate
+	binary.Write(&buf, binary.LittleEndian, uint16(2))            // block align
+	binary.Write(&buf, binary.LittleEndian, uint16(16))           // bits per sample
+
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataSize)
+	for i := 0; i < samples; i++ {
+		sample := int16(8000 * math.Sin(2*math.Pi*440*float64(i)/float64(sampleRate)))
+		binary.Write(&buf, binary.LittleEndian, sample)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,139 @@
+// Package main implements an end-to-end smoke test of a deployed environment.
+//
+// It signs in as a test user, uploads a two-second WAV tone, waits for the
+// upload pipeline to turn it into a track, finds it through search, reads its
+// HLS manifest, tags it, adds it to a playlist and deletes everything again,
+// printing PASS, FAIL or SKIP per step. It exits non-zero when a step fails,
+// so it can gate a deployment.
+//
+//	COGNITO_CLIENT_ID=... SMOKETEST_USERNAME=smoke@example.com SMOKETEST_PASSWORD=... \
+//	go run ./cmd/smoketest -api https://api.example.com
+//
+// Against a self-hosted API, pass -token with a token of its issuer; against
+// a local one, -user to send X-User-ID instead.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+)
+
+type options struct {
+	apiURL          string
+	token           string
+	userID          string
+	username        string
+	password        string
+	clientID        string
+	region          string
+	pollInterval    time.Duration
+	pipelineTimeout time.Duration
+	searchTimeout   time.Duration
+}
+
+func parseFlags() options {
+	var o options
+	flag.StringVar(&o.apiURL, "api", appconfig.GetEnvOrDefault("SMOKETEST_API_URL", "http://localhost:8080"), "base URL of the API under test")
+	flag.StringVar(&o.token, "token", os.Getenv("SMOKETEST_TOKEN"), "bearer token, instead of signing in")
+	flag.StringVar(&o.userID, "user", os.Getenv("SMOKETEST_USER_ID"), "user sent as X-User-ID to a local API, instead of signing in")
+	flag.StringVar(&o.username, "username", os.Getenv("SMOKETEST_USERNAME"), "test user to sign in as")
+	flag.StringVar(&o.password, "password", os.Getenv("SMOKETEST_PASSWORD"), "password of the test user")
+	flag.StringVar(&o.clientID, "client-id", os.Getenv("COGNITO_CLIENT_ID"), "Cognito app client allowing USER_PASSWORD_AUTH")
+	flag.StringVar(&o.region, "region", appconfig.GetEnvOrDefault("AWS_REGION", "us-east-1"), "AWS region of the user pool")
+	flag.DurationVar(&o.pollInterval, "poll", 5*time.Second, "how often to check the upload, search index and HLS rendition")
+	flag.DurationVar(&o.pipelineTimeout, "pipeline-timeout", 10*time.Minute, "how long the pipeline and HLS transcoding may each take")
+	flag.DurationVar(&o.searchTimeout, "search-timeout", time.Minute, "how long indexing may take after the pipeline completes")
+	flag.Parse()
+	return o
+}
+
+func main() {
+	o := parseFlags()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	s := &session{
+		api: &apiClient{
+			baseURL: strings.TrimSuffix(o.apiURL, "/"),
+			userID:  o.userID,
+			token:   o.token,
+			http:    &http.Client{Timeout: 30 * time.Second},
+		},
+		runID:           newRunID(),
+		audio:           toneWAV(2, 8000),
+		pollInterval:    o.pollInterval,
+		pipelineTimeout: o.pipelineTimeout,
+		searchTimeout:   o.searchTimeout,
+	}
+	if o.token == "" && o.userID == "" {
+		if o.username == "" || o.password == "" || o.clientID == "" {
+			fmt.Fprintln(os.Stderr, "smoketest: pass -username, -password and -client-id to sign in, or -token or -user")
+			os.Exit(2)
+		}
+		s.signIn = cognitoSignIn(o.region, o.clientID, o.username, o.password)
+	}
+
+	log.Printf("Smoke testing %s (run %s)", s.api.baseURL, s.runID)
+	results := run(ctx, s, scenario(), os.Stderr)
+
+	fmt.Println()
+	writeReport(os.Stdout, results)
+	if !passed(results) {
+		os.Exit(1)
+	}
+}
+
+// newRunID names this run's track, tag and playlist
+func newRunID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprint(time.Now().Unix())
+	}
+	return hex.EncodeToString(b)
+}
+
+// cognitoSignIn signs in with USER_PASSWORD_AUTH and returns the ID token the
+// API Gateway authorizer expects
+func cognitoSignIn(region, clientID, username, password string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+		if err != nil {
+			return "", fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		result, err := cognitoidentityprovider.NewFromConfig(awsCfg).InitiateAuth(ctx, &cognitoidentityprovider.InitiateAuthInput{
+			AuthFlow: types.AuthFlowTypeUserPasswordAuth,
+			ClientId: aws.String(clientID),
+			AuthParameters: map[string]string{
+				"USERNAME": username,
+				"PASSWORD": password,
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to sign in as %s: %w", username, err)
+		}
+		if result.AuthenticationResult == nil {
+			return "", fmt.Errorf("sign-in as %s needs a %s challenge answered", username, result.ChallengeName)
+		}
+		if result.AuthenticationResult.IdToken == nil {
+			return "", fmt.Errorf("sign-in as %s returned no ID token", username)
+		}
+		return *result.AuthenticationResult.IdToken, nil
+	}
+}
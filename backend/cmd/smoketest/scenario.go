@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// Step outcomes
+const (
+	statusPass = "PASS"
+	statusFail = "FAIL"
+	statusSkip = "SKIP"
+)
+
+// skipError reports why a step did not run
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string { return e.reason }
+
+func skip(format string, args ...interface{}) error {
+	return &skipError{reason: fmt.Sprintf(format, args...)}
+}
+
+// step is one stage of the scenario. Once a step fails the remaining steps
+// are skipped, except cleanup steps, which remove whatever was created.
+type step struct {
+	Name    string
+	Cleanup bool
+	Run     func(ctx context.Context, s *session) error
+}
+
+// session carries the scenario's settings and what earlier steps created
+type session struct {
+	api *apiClient
+	// signIn returns a token for api; nil when a token or user ID was given
+	signIn func(ctx context.Context) (string, error)
+
+	runID           string
+	audio           []byte
+	pollInterval    time.Duration
+	pipelineTimeout time.Duration
+	searchTimeout   time.Duration
+
+	uploadID   string
+	trackID    string
+	tag        string
+	playlistID string
+}
+
+// title is the name the pipeline gives the uploaded track: its file name
+// without the extension
+func (s *session) title() string {
+	return "smoketest-" + s.runID
+}
+
+// stepResult is the outcome of one step
+type stepResult struct {
+	Name    string
+	Status  string
+	Elapsed time.Duration
+	Detail  string
+}
+
+// scenario lists the steps in the order they run
+func scenario() []step {
+	return []step{
+		{Name: "sign in", Run: signInStep},
+		{Name: "upload", Run: uploadStep},
+		{Name: "pipeline", Run: pipelineStep},
+		{Name: "search", Run: searchStep},
+		{Name: "stream HLS", Run: streamStep},
+		{Name: "tag", Run: tagStep},
+		{Name: "playlist", Run: playlistStep},
+		{Name: "delete", Cleanup: true, Run: deleteStep},
+	}
+}
+
+// run executes steps in order and reports each one
+func run(ctx context.Context, s *session, steps []step, progress io.Writer) []stepResult {
+	results := make([]stepResult, 0, len(steps))
+	failed := false
+	for _, st := range steps {
+		result := stepResult{Name: st.Name}
+		if failed && !st.Cleanup {
+			result.Status = statusSkip
+			result.Detail = "an earlier step failed"
+		} else {
+			began := time.Now()
+			err := st.Run(ctx, s)
+			result.Elapsed = time.Since(began)
+
+			var skipped *skipError
+			switch {
+			case err == nil:
+				result.Status = statusPass
+			case errors.As(err, &skipped):
+				result.Status = statusSkip
+				result.Detail = skipped.reason
+			default:
+				result.Status = statusFail
+				result.Detail = err.Error()
+				failed = true
+			}
+		}
+		results = append(results, result)
+		fmt.Fprintf(progress, "%s %s %s\n", result.Status, result.Name, result.Detail)
+	}
+	return results
+}
+
+// passed reports whether no step failed
+func passed(results []stepResult) bool {
+	for _, r := range results {
+		if r.Status == statusFail {
+			return false
+		}
+	}
+	return true
+}
+
+// writeReport prints one row per step
+func writeReport(w io.Writer, results []stepResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "step\tresult\ttime\tdetail")
+	for _, r := range results {
+		elapsed := "-"
+		if r.Status != statusSkip || r.Elapsed > 0 {
+			elapsed = r.Elapsed.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, r.Status, elapsed, r.Detail)
+	}
+	tw.Flush()
+}
+
+// poll calls check every interval until it reports done or returns an
+// error, failing with the last state it described once timeout passes
+func poll(ctx context.Context, interval, timeout time.Duration, check func() (done bool, state string, err error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		done, state, err := check()
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up after %s: %s", timeout, state)
+		case <-time.After(interval):
+		}
+	}
+}
+
+func signInStep(ctx context.Context, s *session) error {
+	if s.signIn == nil {
+		if s.api.token != "" {
+			return skip("using the given token")
+		}
+		return skip("sending X-User-ID %s", s.api.userID)
+	}
+	token, err := s.signIn(ctx)
+	if err != nil {
+		return err
+	}
+	s.api.token = token
+	return nil
+}
+
+// uploadStep uploads the tone through a presigned URL and confirms it
+func uploadStep(ctx context.Context, s *session) error {
+	var presigned models.PresignedUploadResponse
+	err := s.api.do(ctx, http.MethodPost, "/api/v1/upload/presigned", models.PresignedUploadRequest{
+		FileName:    s.title() + ".wav",
+		FileSize:    int64(len(s.audio)),
+		ContentType: "audio/wav",
+	}, http.StatusOK, &presigned)
+	if err != nil {
+		return err
+	}
+	s.uploadID = presigned.UploadID
+
+	status, body, err := s.api.fetch(ctx, http.MethodPut, presigned.UploadURL, "audio/wav", s.audio, 512)
+	if err != nil {
+		return fmt.Errorf("failed to upload the file: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("upload URL answered %d %s", status, bytes.TrimSpace(body))
+	}
+
+	return s.api.do(ctx, http.MethodPost, "/api/v1/upload/confirm", models.ConfirmUploadRequest{
+		UploadID: s.uploadID,
+	}, http.StatusOK, nil)
+}
+
+// pipelineStep waits for the upload to become a track
+func pipelineStep(ctx context.Context, s *session) error {
+	return poll(ctx, s.pollInterval, s.pipelineTimeout, func() (bool, string, error) {
+		var upload models.UploadResponse
+		if err := s.api.do(ctx, http.MethodGet, "/api/v1/uploads/"+s.uploadID, nil, http.StatusOK, &upload); err != nil {
+			return false, "", err
+		}
+		switch upload.Status {
+		case models.UploadStatusCompleted:
+			if upload.TrackID == "" {
+				return false, "", errors.New("upload completed without a track")
+			}
+			s.trackID = upload.TrackID
+			return true, "", nil
+		case models.UploadStatusFailed:
+			return false, "", fmt.Errorf("upload failed: %s %s", upload.FailureReason, upload.ErrorMsg)
+		}
+		return false, fmt.Sprintf("upload %s is %s", s.uploadID, upload.Status), nil
+	})
+}
+
+// searchStep waits for the track to be found by its title
+func searchStep(ctx context.Context, s *session) error {
+	query := url.Values{"q": {s.title()}}
+	return poll(ctx, s.pollInterval, s.searchTimeout, func() (bool, string, error) {
+		var results models.SearchResponse
+		if err := s.api.do(ctx, http.MethodGet, "/api/v1/search?"+query.Encode(), nil, http.StatusOK, &results); err != nil {
+			return false, "", err
+		}
+		for _, track := range results.Tracks {
+			if track.ID == s.trackID {
+				return true, "", nil
+			}
+		}
+		return false, fmt.Sprintf("track %s not among %d results", s.trackID, results.TotalResults), nil
+	})
+}
+
+// streamStep waits for the HLS rendition and reads its manifest
+func streamStep(ctx context.Context, s *session) error {
+	var stream models.StreamResponse
+	err := poll(ctx, s.pollInterval, s.pipelineTimeout, func() (bool, string, error) {
+		if err := s.api.do(ctx, http.MethodGet, "/api/v1/stream/"+s.trackID, nil, http.StatusOK, &stream); err != nil {
+			return false, "", err
+		}
+		return stream.HLSReady && stream.HLSURL != "", "HLS is not ready", nil
+	})
+	if err != nil {
+		return err
+	}
+
+	status, body, err := s.api.fetch(ctx, http.MethodGet, stream.HLSURL, "", nil, 4096)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the HLS manifest: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("HLS manifest answered %d", status)
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("#EXTM3U")) {
+		return errors.New("HLS manifest does not start with #EXTM3U")
+	}
+	return nil
+}
+
+func tagStep(ctx context.Context, s *session) error {
+	tag := s.title()
+	var resp struct {
+		Tags []string `json:"tags"`
+	}
+	err := s.api.do(ctx, http.MethodPost, "/api/v1/tracks/"+s.trackID+"/tags", models.AddTagsToTrackRequest{
+		Tags: []string{tag},
+	}, http.StatusOK, &resp)
+	if err != nil {
+		return err
+	}
+	s.tag = tag
+	for _, t := range resp.Tags {
+		if t == tag {
+			return nil
+		}
+	}
+	return fmt.Errorf("track tags %v lack %s", resp.Tags, tag)
+}
+
+func playlistStep(ctx context.Context, s *session) error {
+	var playlist models.PlaylistResponse
+	err := s.api.do(ctx, http.MethodPost, "/api/v1/playlists", models.CreatePlaylistRequest{
+		Name: "Smoke test " + s.runID,
+	}, http.StatusCreated, &playlist)
+	if err != nil {
+		return err
+	}
+	s.playlistID = playlist.ID
+
+	err = s.api.do(ctx, http.MethodPost, "/api/v1/playlists/"+s.playlistID+"/tracks", models.AddTracksToPlaylistRequest{
+		TrackIDs: []string{s.trackID},
+	}, http.StatusOK, &playlist)
+	if err != nil {
+		return err
+	}
+	if playlist.TrackCount != 1 {
+		return fmt.Errorf("playlist has %d tracks, want 1", playlist.TrackCount)
+	}
+	return nil
+}
+
+// deleteStep removes the playlist, tag and track, then checks the track is
+// gone. An upload that never became a track is left to the pipeline.
+func deleteStep(ctx context.Context, s *session) error {
+	if s.trackID == "" && s.playlistID == "" {
+		if s.uploadID != "" {
+			return skip("upload %s has no track to delete", s.uploadID)
+		}
+		return skip("nothing was created")
+	}
+
+	var errs []error
+	if s.playlistID != "" {
+		errs = append(errs, s.api.do(ctx, http.MethodDelete, "/api/v1/playlists/"+s.playlistID, nil, http.StatusNoContent, nil))
+	}
+	if s.tag != "" {
+		errs = append(errs, s.api.do(ctx, http.MethodDelete, "/api/v1/tags/"+url.PathEscape(s.tag), nil, http.StatusNoContent, nil))
+	}
+	if s.trackID != "" {
+		if err := s.api.do(ctx, http.MethodDelete, "/api/v1/tracks/"+s.trackID, nil, http.StatusNoContent, nil); err != nil {
+			errs = append(errs, err)
+		} else {
+			errs = append(errs, s.api.do(ctx, http.MethodGet, "/api/v1/tracks/"+s.trackID, nil, http.StatusNotFound, nil))
+		}
+	}
+	return errors.Join(errs...)
+}
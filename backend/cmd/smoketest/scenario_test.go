@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+const testTrackID = "7f0c6f3e-3b7a-4f7e-9d8e-0a1b2c3d4e5f"
+
+// fakeAPI answers the scenario's requests the way a deployment whose
+// pipeline takes two polls would
+type fakeAPI struct {
+	t          *testing.T
+	failUpload bool
+
+	mu       sync.Mutex
+	polls    int
+	uploaded []byte
+	deleted  []string
+	server   *httptest.Server
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	f := &fakeAPI{t: t}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeAPI) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Presigned and signed URLs carry no user
+	if !strings.HasPrefix(r.URL.Path, "/s3/") && !strings.HasPrefix(r.URL.Path, "/cdn/") {
+		assert.Equal(f.t, "u1", r.Header.Get("X-User-ID"))
+	}
+
+	respond := func(status int, body interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+
+	switch route := r.Method + " " + r.URL.Path; {
+	case route == "POST /api/v1/upload/presigned":
+		var req models.PresignedUploadRequest
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(f.t, "audio/wav", req.ContentType)
+		respond(http.StatusOK, models.PresignedUploadResponse{UploadID: "up1", UploadURL: f.server.URL + "/s3/upload?sig=x"})
+	case route == "PUT /s3/upload":
+		assert.Equal(f.t, "audio/wav", r.Header.Get("Content-Type"))
+		f.uploaded, _ = io.ReadAll(r.Body)
+	case route == "POST /api/v1/upload/confirm":
+		respond(http.StatusOK, models.ConfirmUploadResponse{UploadID: "up1", Status: models.UploadStatusProcessing})
+	case route == "GET /api/v1/uploads/up1":
+		f.polls++
+		upload := models.UploadResponse{ID: "up1", Status: models.UploadStatusProcessing}
+		switch {
+		case f.polls < 2:
+		case f.failUpload:
+			upload.Status = models.UploadStatusFailed
+			upload.ErrorMsg = "transcode failed"
+		default:
+			upload.Status = models.UploadStatusCompleted
+			upload.TrackID = testTrackID
+		}
+		respond(http.StatusOK, upload)
+	case route == "GET /api/v1/search":
+		assert.True(f.t, strings.HasPrefix(r.URL.Query().Get("q"), "smoketest-"))
+		respond(http.StatusOK, models.SearchResponse{TotalResults: 1, Tracks: []models.TrackResponse{{ID: testTrackID}}})
+	case route == "GET /api/v1/stream/"+testTrackID:
+		respond(http.StatusOK, models.StreamResponse{TrackID: testTrackID, HLSReady: true, HLSURL: f.server.URL + "/cdn/master.m3u8"})
+	case route == "GET /cdn/master.m3u8":
+		_, _ = io.WriteString(w, "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=128000\n128k.m3u8\n")
+	case route == "POST /api/v1/tracks/"+testTrackID+"/tags":
+		var req models.AddTagsToTrackRequest
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&req))
+		respond(http.StatusOK, map[string][]string{"tags": req.Tags})
+	case route == "POST /api/v1/playlists":
+		respond(http.StatusCreated, models.PlaylistResponse{ID: "pl1"})
+	case route == "POST /api/v1/playlists/pl1/tracks":
+		respond(http.StatusOK, models.PlaylistResponse{ID: "pl1", TrackCount: 1})
+	case r.Method == http.MethodDelete:
+		f.deleted = append(f.deleted, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	case route == "GET /api/v1/tracks/"+testTrackID:
+		respond(http.StatusNotFound, models.NewErrorResponse(models.NewNotFoundError("Track", testTrackID)))
+	default:
+		f.t.Errorf("unexpected request %s", route)
+		w.WriteHeader(http.StatusTeapot)
+	}
+}
+
+func (f *fakeAPI) session() *session {
+	return &session{
+		api:             &apiClient{baseURL: f.server.URL, userID: "u1", http: f.server.Client()},
+		runID:           "abcd1234",
+		audio:           toneWAV(1, 8000),
+		pollInterval:    time.Millisecond,
+		pipelineTimeout: time.Second,
+		searchTimeout:   time.Second,
+	}
+}
+
+func statuses(results []stepResult) map[string]string {
+	byName := make(map[string]string, len(results))
+	for _, r := range results {
+		byName[r.Name] = r.Status
+	}
+	return byName
+}
+
+func TestScenario(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("passes every step and cleans up", func(t *testing.T) {
+		api := newFakeAPI(t)
+		s := api.session()
+		var progress bytes.Buffer
+		results := run(ctx, s, scenario(), &progress)
+
+		assert.True(t, passed(results), progress.String())
+		assert.Equal(t, map[string]string{
+			"sign in": statusSkip, "upload": statusPass, "pipeline": statusPass, "search": statusPass,
+			"stream HLS": statusPass, "tag": statusPass, "playlist": statusPass, "delete": statusPass,
+		}, statuses(results))
+		assert.Equal(t, s.audio, api.uploaded)
+		assert.Equal(t, []string{
+			"/api/v1/playlists/pl1",
+			"/api/v1/tags/smoketest-abcd1234",
+			"/api/v1/tracks/" + testTrackID,
+		}, api.deleted)
+
+		var report bytes.Buffer
+		writeReport(&report, results)
+		assert.Contains(t, report.String(), "stream HLS")
+		assert.Contains(t, report.String(), "sending X-User-ID u1")
+	})
+
+	t.Run("skips the rest after a failure", func(t *testing.T) {
+		api := newFakeAPI(t)
+		api.failUpload = true
+		results := run(ctx, api.session(), scenario(), io.Discard)
+
+		assert.False(t, passed(results))
+		byName := statuses(results)
+		assert.Equal(t, statusFail, byName["pipeline"])
+		assert.Equal(t, statusSkip, byName["search"])
+		assert.Equal(t, statusSkip, byName["delete"], "a failed upload leaves no track")
+		assert.Contains(t, results[2].Detail, "transcode failed")
+		assert.Empty(t, api.deleted)
+	})
+
+	t.Run("gives up waiting with the last state", func(t *testing.T) {
+		err := poll(ctx, time.Millisecond, 20*time.Millisecond, func() (bool, string, error) {
+			return false, "upload up1 is PROCESSING", nil
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "upload up1 is PROCESSING")
+	})
+}
+
+func TestToneWAV(t *testing.T) {
+	wav := toneWAV(2, 8000)
+	require.Len(t, wav, 44+2*8000*2)
+	assert.Equal(t, "RIFF", string(wav[0:4]))
+	assert.Equal(t, "WAVE", string(wav[8:12]))
+	assert.Equal(t, uint32(len(wav)-8), binary.LittleEndian.Uint32(wav[4:8]))
+	assert.Equal(t, uint32(8000), binary.LittleEndian.Uint32(wav[24:28]))
+	assert.Equal(t, "data", string(wav[36:40]))
+}
@@ -6,49 +6,26 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/bootstrap"
 	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 )
 
-var (
-	userService   service.UserService
-	cognitoClient *cognitoidentityprovider.Client
-	userPoolID    string
-)
-
-func init() {
-	ctx := context.Background()
+// deps builds the repository and AWS clients on the first invocation rather
+// than in init(), so a configuration error is logged without blocking signups
+var deps = bootstrap.NewProcessor()
 
-	// Load AWS config
-	cfg, err := config.LoadDefaultConfig(ctx)
+var cognitoClient = bootstrap.NewLazy("Cognito client", func(ctx context.Context) (*cognitoidentityprovider.Client, error) {
+	cfg, err := deps.AWS(ctx)
 	if err != nil {
-		log.Fatalf("Failed to load AWS config: %v", err)
+		return nil, err
 	}
-
-	// Initialize DynamoDB client
-	dynamoClient := dynamodb.NewFromConfig(cfg)
-	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
-	if tableName == "" {
-		tableName = "music-library"
-	}
-
-	// Initialize repository
-	repo := repository.NewDynamoDBRepository(dynamoClient, tableName)
-
-	// Initialize user service
-	userService = service.NewUserService(repo)
-
-	// Initialize Cognito client
-	cognitoClient = cognitoidentityprovider.NewFromConfig(cfg)
-	userPoolID = os.Getenv("COGNITO_USER_POOL_ID")
-}
+	return cognitoidentityprovider.NewFromConfig(cfg), nil
+})
 
 func handler(ctx context.Context, event events.CognitoEventUserPoolsPostConfirmation) (events.CognitoEventUserPoolsPostConfirmation, error) {
 	log.Printf("Processing post-confirmation for user: %s", event.UserName)
@@ -57,6 +34,7 @@ func handler(ctx context.Context, event events.CognitoEventUserPoolsPostConfirma
 	cognitoSub := event.Request.UserAttributes["sub"]
 	email := event.Request.UserAttributes["email"]
 	name := event.Request.UserAttributes["name"]
+	ctx = tenant.WithID(ctx, event.Request.UserAttributes["custom:tenant_id"])
 
 	if cognitoSub == "" || email == "" {
 		log.Printf("Warning: Missing required attributes for user %s", event.UserName)
@@ -64,8 +42,15 @@ func handler(ctx context.Context, event events.CognitoEventUserPoolsPostConfirma
 		return event, nil
 	}
 
+	repo, err := deps.Repository(ctx)
+	if err != nil {
+		log.Printf("Error initializing repository: %v", err)
+		// Return event without error to not block signup
+		return event, nil
+	}
+
 	// Create user in DynamoDB
-	user, err := userService.CreateUserFromCognito(ctx, cognitoSub, email, name)
+	user, err := service.NewUserService(repo).CreateUserFromCognito(ctx, cognitoSub, email, name)
 	if err != nil {
 		log.Printf("Error creating user in DynamoDB: %v", err)
 		// Return event without error to not block signup
@@ -76,12 +61,19 @@ func handler(ctx context.Context, event events.CognitoEventUserPoolsPostConfirma
 	log.Printf("Created user profile: %s (%s)", user.Email, user.ID)
 
 	// Add user to subscriber group in Cognito
-	if userPoolID != "" {
-		_, err = cognitoClient.AdminAddUserToGroup(ctx, &cognitoidentityprovider.AdminAddUserToGroupInput{
-			UserPoolId: &userPoolID,
-			Username:   &event.UserName,
-			GroupName:  stringPtr("subscriber"),
-		})
+	appCfg, err := deps.Config(ctx)
+	if err != nil {
+		return event, nil
+	}
+	if userPoolID := appCfg.CognitoUserPoolID; userPoolID != "" {
+		client, err := cognitoClient.Get(ctx)
+		if err == nil {
+			_, err = client.AdminAddUserToGroup(ctx, &cognitoidentityprovider.AdminAddUserToGroupInput{
+				UserPoolId: &userPoolID,
+				Username:   &event.UserName,
+				GroupName:  stringPtr("subscriber"),
+			})
+		}
 		if err != nil {
 			log.Printf("Warning: Failed to add user to subscriber group: %v", err)
 			// Don't fail the signup for this
@@ -0,0 +1,38 @@
+// Pre-Token-Generation Lambda Trigger
+// Triggered when Cognito issues ID and access tokens (V2 event).
+// Copies the user's custom:tenant_id attribute into the access token, which
+// otherwise carries no custom attributes, for the API's tenant check.
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// tenantAttribute is the user attribute, and the claim, naming the user's tenant
+const tenantAttribute = "custom:tenant_id"
+
+func handler(ctx context.Context, event events.CognitoEventUserPoolsPreTokenGenV2) (events.CognitoEventUserPoolsPreTokenGenV2, error) {
+	// Leave the user's groups as they are
+	event.Response.ClaimsAndScopeOverrideDetails.GroupOverrideDetails = event.Request.GroupConfiguration
+
+	tenantID := event.Request.UserAttributes[tenantAttribute]
+	if tenantID == "" {
+		// The API rejects the user's requests in multi-tenant mode; signing in
+		// still works so an admin can assign a tenant
+		log.Printf("Warning: User %s has no %s attribute", event.UserName, tenantAttribute)
+		return event, nil
+	}
+
+	event.Response.ClaimsAndScopeOverrideDetails.AccessTokenGeneration.ClaimsToAddOrOverride = map[string]string{
+		tenantAttribute: tenantID,
+	}
+	return event, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	event := func(t *testing.T, attributes string) events.CognitoEventUserPoolsPreTokenGenV2 {
+		t.Helper()
+		var e events.CognitoEventUserPoolsPreTokenGenV2
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"version": "2",
+			"triggerSource": "TokenGeneration_Authentication",
+			"userName": "ana",
+			"request": {
+				"userAttributes": `+attributes+`,
+				"groupConfiguration": {"groupsToOverride": ["subscriber"], "iamRolesToOverride": [], "preferredRole": null},
+				"scopes": ["openid", "email"]
+			},
+			"response": {}
+		}`), &e))
+		return e
+	}
+
+	t.Run("adds the tenant to the access token", func(t *testing.T) {
+		resp, err := handler(context.Background(), event(t, `{"sub": "user-1", "custom:tenant_id": "acme"}`))
+
+		require.NoError(t, err)
+		details := resp.Response.ClaimsAndScopeOverrideDetails
+		assert.Equal(t, map[string]string{"custom:tenant_id": "acme"}, details.AccessTokenGeneration.ClaimsToAddOrOverride)
+		assert.Empty(t, details.IDTokenGeneration.ClaimsToAddOrOverride, "ID tokens carry custom attributes already")
+		assert.Equal(t, []string{"subscriber"}, details.GroupOverrideDetails.GroupsToOverride)
+	})
+
+	t.Run("issues tokens without a tenant when the user has none", func(t *testing.T) {
+		resp, err := handler(context.Background(), event(t, `{"sub": "user-1"}`))
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Response.ClaimsAndScopeOverrideDetails.AccessTokenGeneration.ClaimsToAddOrOverride)
+		assert.Equal(t, []string{"subscriber"}, resp.Response.ClaimsAndScopeOverrideDetails.GroupOverrideDetails.GroupsToOverride)
+	})
+}
@@ -0,0 +1,129 @@
+// Package alerting publishes operational alerts (repeated transcode failures,
+// a corrupt search index, uploads over quota, stuck pipelines) to an SNS
+// topic. Alerts are best effort: a failed publish is logged and never fails
+// the work that raised it. A nil *Alerter is valid and drops every alert, so
+// binaries without a topic configured need no special casing.
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind identifies the event an alert reports; subscribers can filter on it
+// through the "kind" message attribute
+type Kind string
+
+const (
+	// KindTranscodeFailures is raised when a track's transcode keeps failing
+	KindTranscodeFailures Kind = "transcode_failures"
+	// KindIndexCorruption is raised when the stored search index cannot be read
+	KindIndexCorruption Kind = "index_corruption"
+	// KindQuotaExceeded is raised when a user tries to upload past their storage limit
+	KindQuotaExceeded Kind = "quota_exceeded"
+	// KindPipelineStuck is raised when an upload has been processing too long
+	KindPipelineStuck Kind = "pipeline_stuck"
+)
+
+// Alert is one operational event
+type Alert struct {
+	Kind Kind
+	// Key identifies what the alert is about (a track, user or upload);
+	// alerts of the same kind and key are sent at most once per cooldown
+	Key     string
+	Subject string
+	Message string
+	// Details are appended to the message as "name: value" lines
+	Details map[string]string
+}
+
+// Body is the message text published for the alert
+func (a Alert) Body() string {
+	if len(a.Details) == 0 {
+		return a.Message
+	}
+	names := make([]string, 0, len(a.Details))
+	for name := range a.Details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(a.Message)
+	b.WriteString("\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n%s: %s", name, a.Details[name])
+	}
+	return b.String()
+}
+
+// Publisher delivers an alert to a topic
+type Publisher interface {
+	Publish(ctx context.Context, topicARN string, alert Alert) error
+}
+
+// DefaultCooldown is how long repeats of an alert are suppressed
+const DefaultCooldown = 15 * time.Minute
+
+// Alerter sends alerts to one topic, suppressing repeats of the same kind and
+// key within the cooldown. Suppression is per process, so each Lambda
+// sandbox may send its own copy. Alerter is safe for concurrent use.
+type Alerter struct {
+	publisher Publisher
+	topicARN  string
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// New returns an Alerter publishing to topicARN, or nil when topicARN is empty
+func New(publisher Publisher, topicARN string) *Alerter {
+	if topicARN == "" {
+		return nil
+	}
+	return &Alerter{
+		publisher: publisher,
+		topicARN:  topicARN,
+		cooldown:  DefaultCooldown,
+		now:       time.Now,
+		sent:      make(map[string]time.Time),
+	}
+}
+
+// SetCooldown changes how long repeats are suppressed; 0 sends every alert
+func (a *Alerter) SetCooldown(cooldown time.Duration) {
+	if a != nil {
+		a.cooldown = cooldown
+	}
+}
+
+// Send publishes the alert unless a copy was sent within the cooldown.
+// Failures are logged; a failed alert may be sent again straight away.
+func (a *Alerter) Send(ctx context.Context, alert Alert) {
+	if a == nil {
+		return
+	}
+
+	id := string(alert.Kind) + "#" + alert.Key
+	now := a.now()
+	a.mu.Lock()
+	if last, ok := a.sent[id]; ok && now.Sub(last) < a.cooldown {
+		a.mu.Unlock()
+		return
+	}
+	a.sent[id] = now
+	a.mu.Unlock()
+
+	if err := a.publisher.Publish(ctx, a.topicARN, alert); err != nil {
+		fmt.Printf("Warning: failed to publish %s alert: %v\n", alert.Kind, err)
+		a.mu.Lock()
+		delete(a.sent, id)
+		a.mu.Unlock()
+	}
+}
@@ -0,0 +1,130 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const topicARN = "arn:aws:sns:us-east-1:123456789012:alerts"
+
+// recordingPublisher records the alerts it is asked to publish
+type recordingPublisher struct {
+	alerts []Alert
+	err    error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, alert Alert) error {
+	p.alerts = append(p.alerts, alert)
+	return p.err
+}
+
+func TestAlerter_Send(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("nil alerter drops alerts", func(t *testing.T) {
+		alerter := New(&recordingPublisher{}, "")
+		assert.Nil(t, alerter)
+		alerter.SetCooldown(0)
+		alerter.Send(ctx, Alert{Kind: KindQuotaExceeded})
+	})
+
+	t.Run("suppresses repeats within the cooldown", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		alerter := New(publisher, topicARN)
+		now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		alerter.now = func() time.Time { return now }
+
+		alerter.Send(ctx, Alert{Kind: KindTranscodeFailures, Key: "t1"})
+		alerter.Send(ctx, Alert{Kind: KindTranscodeFailures, Key: "t1"})
+		alerter.Send(ctx, Alert{Kind: KindTranscodeFailures, Key: "t2"})
+		alerter.Send(ctx, Alert{Kind: KindQuotaExceeded, Key: "t1"})
+		assert.Len(t, publisher.alerts, 3)
+
+		now = now.Add(DefaultCooldown)
+		alerter.Send(ctx, Alert{Kind: KindTranscodeFailures, Key: "t1"})
+		assert.Len(t, publisher.alerts, 4)
+	})
+
+	t.Run("retries after a failed publish", func(t *testing.T) {
+		publisher := &recordingPublisher{err: errors.New("throttled")}
+		alerter := New(publisher, topicARN)
+
+		alerter.Send(ctx, Alert{Kind: KindPipelineStuck, Key: "u1"})
+		alerter.Send(ctx, Alert{Kind: KindPipelineStuck, Key: "u1"})
+		assert.Len(t, publisher.alerts, 2)
+	})
+}
+
+func TestAlert_Body(t *testing.T) {
+	alert := Alert{Message: "Transcoding keeps failing", Details: map[string]string{"trackId": "t1", "failures": "3"}}
+	assert.Equal(t, "Transcoding keeps failing\n\nfailures: 3\ntrackId: t1", alert.Body())
+	assert.Equal(t, "plain", Alert{Message: "plain"}.Body())
+}
+
+func TestSNSPublisher_Publish(t *testing.T) {
+	ctx := context.Background()
+	cfg := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+	}
+
+	t.Run("posts a signed publish request", func(t *testing.T) {
+		var form map[string][]string
+		var authorization string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			require.NoError(t, r.ParseForm())
+			form = r.PostForm
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		cfg := cfg
+		cfg.BaseEndpoint = aws.String(server.URL)
+		err := NewSNSPublisher(cfg).Publish(ctx, topicARN, Alert{
+			Kind:    KindIndexCorruption,
+			Subject: "Search index\ncorrupt",
+			Message: "index.json could not be decoded",
+		})
+
+		require.NoError(t, err)
+		assert.Contains(t, authorization, "AWS4-HMAC-SHA256 Credential=AKID/")
+		assert.Contains(t, authorization, "/us-east-1/sns/aws4_request")
+		assert.Equal(t, []string{"Publish"}, form["Action"])
+		assert.Equal(t, []string{topicARN}, form["TopicArn"])
+		assert.Equal(t, []string{"Search index corrupt"}, form["Subject"])
+		assert.Equal(t, []string{"index.json could not be decoded"}, form["Message"])
+		assert.Equal(t, []string{"kind"}, form["MessageAttributes.entry.1.Name"])
+		assert.Equal(t, []string{"index_corruption"}, form["MessageAttributes.entry.1.Value.StringValue"])
+	})
+
+	t.Run("returns SNS errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "AuthorizationError", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		cfg := cfg
+		cfg.BaseEndpoint = aws.String(server.URL)
+		err := NewSNSPublisher(cfg).Publish(ctx, topicARN, Alert{Kind: KindQuotaExceeded, Message: "over quota"})
+
+		assert.ErrorContains(t, err, "SNS returned 403: AuthorizationError")
+	})
+}
+
+func TestSNSSubject(t *testing.T) {
+	assert.Equal(t, "Upload stuck", snsSubject(" Upload\tstuck\x00 "))
+	assert.Equal(t, "Caf", snsSubject("Café"))
+	assert.Len(t, snsSubject(strings.Repeat("a", 150)), maxSubjectLength)
+}
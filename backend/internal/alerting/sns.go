@@ -0,0 +1,116 @@
+package alerting
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// maxSubjectLength is the longest subject SNS accepts
+const maxSubjectLength = 100
+
+// SNSPublisher publishes alerts with the SNS Publish action of the query
+// API, signed with the Lambda's credentials. It needs sns:Publish on the topic.
+type SNSPublisher struct {
+	client      aws.HTTPClient
+	credentials aws.CredentialsProvider
+	region      string
+	endpoint    string
+	signer      *v4.Signer
+}
+
+// NewSNSPublisher publishes with the region, credentials and HTTP client of
+// cfg. cfg.BaseEndpoint overrides the regional endpoint (LocalStack).
+func NewSNSPublisher(cfg aws.Config) *SNSPublisher {
+	endpoint := aws.ToString(cfg.BaseEndpoint)
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://sns.%s.amazonaws.com/", cfg.Region)
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &SNSPublisher{
+		client:      client,
+		credentials: cfg.Credentials,
+		region:      cfg.Region,
+		endpoint:    endpoint,
+		signer:      v4.NewSigner(),
+	}
+}
+
+// Publish sends the alert with its kind as the "kind" message attribute
+func (p *SNSPublisher) Publish(ctx context.Context, topicARN string, alert Alert) error {
+	if p.credentials == nil {
+		return fmt.Errorf("no AWS credentials to sign the publish request")
+	}
+
+	form := url.Values{
+		"Action":   {"Publish"},
+		"Version":  {"2010-03-31"},
+		"TopicArn": {topicARN},
+		"Message":  {alert.Body()},
+	}
+	form.Set("MessageAttributes.entry.1.Name", "kind")
+	form.Set("MessageAttributes.entry.1.Value.DataType", "String")
+	form.Set("MessageAttributes.entry.1.Value.StringValue", string(alert.Kind))
+	if subject := snsSubject(alert.Subject); subject != "" {
+		form.Set("Subject", subject)
+	}
+	body := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create publish request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	creds, err := p.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	hash := sha256.Sum256([]byte(body))
+	if err := p.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "sns", p.region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign publish request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("publish request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("SNS returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// snsSubject makes a subject SNS accepts: printable ASCII on one line, at
+// most maxSubjectLength characters
+func snsSubject(subject string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case r < ' ' || r > '~':
+			return -1
+		}
+		return r
+	}, subject)
+	cleaned = strings.TrimSpace(cleaned)
+	if len(cleaned) > maxSubjectLength {
+		cleaned = strings.TrimSpace(cleaned[:maxSubjectLength])
+	}
+	return cleaned
+}
@@ -21,6 +21,11 @@ type Result struct {
 	MusicalKey string // Musical key (e.g., "Am", "C", "F#m")
 	KeyMode    string // "major" or "minor"
 	KeyCamelot string // Camelot notation (e.g., "8A", "11B")
+	// KeyConfidence is 0-1; low values mean the key is close to a call between candidates
+	KeyConfidence float64
+	KeyCandidates []KeyCandidate // Runner-up keys, best first
+	// PreviewStart is the second the loudest PreviewSeconds of the track start at
+	PreviewStart int
 }
 
 // Analyzer performs audio analysis for BPM and key detection
@@ -118,8 +123,20 @@ func (a *Analyzer) Analyze(ctx context.Context, reader io.Reader, fileName strin
 		result.BPM = bpm
 	}
 
-	// Key detection is more complex - skip for now
-	// Would require pitch/chroma analysis
+	// Detect key
+	if candidates, confidence := a.detectKey(samples); len(candidates) > 0 {
+		best := candidates[0]
+		result.MusicalKey = best.Key
+		result.KeyMode = "major"
+		if strings.HasSuffix(best.Key, "m") {
+			result.KeyMode = "minor"
+		}
+		result.KeyCamelot = best.Camelot
+		result.KeyConfidence = confidence
+		result.KeyCandidates = candidates[1:]
+	}
+
+	result.PreviewStart = a.detectPreviewStart(samples)
 
 	return result, nil
 }
@@ -3,6 +3,7 @@ package analysis
 import (
 	"bytes"
 	"context"
+	"math"
 	"os/exec"
 	"testing"
 	"time"
@@ -469,3 +470,42 @@ func BenchmarkGetCamelotNotation(b *testing.B) {
 		GetCamelotNotation(keys[idx], modes[idx])
 	}
 }
+
+// clickTrack synthesizes seconds of mono audio with a decaying 60Hz kick on
+// every beat, enough for detectBPM to lock on to bpm
+func clickTrack(seconds, sampleRate int, bpm float64) []float64 {
+	samples := make([]float64, seconds*sampleRate)
+	beat := int(60.0 / bpm * float64(sampleRate))
+	for start := 0; start < len(samples); start += beat {
+		for i := 0; i < sampleRate/10 && start+i < len(samples); i++ {
+			t := float64(i) / float64(sampleRate)
+			samples[start+i] = math.Sin(2*math.Pi*60*t) * math.Exp(-t*30)
+		}
+	}
+	return samples
+}
+
+func BenchmarkDetectBPM(b *testing.B) {
+	analyzer := NewAnalyzer()
+	samples := clickTrack(180, analyzer.sampleRate, 124) // a three-minute track
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		analyzer.detectBPM(samples)
+	}
+}
+
+func BenchmarkAutocorrelationBPM(b *testing.B) {
+	const sampleRate, hopSize = 22050, 551
+	onset := make([]float64, 15*sampleRate/hopSize) // one 15s segment
+	for i := range onset {
+		if i%19 == 0 {
+			onset[i] = 1
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		autocorrelationBPMImproved(onset, hopSize, sampleRate)
+	}
+}
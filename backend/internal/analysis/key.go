@@ -0,0 +1,158 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// KeyCandidate is a musical key scored against the track's pitch content
+type KeyCandidate struct {
+	Key     string  // Musical key (e.g., "Am", "C")
+	Camelot string  // Camelot notation (e.g., "8A")
+	Score   float64 // Correlation with the key profile (-1 to 1)
+}
+
+// Key detection parameters
+const (
+	keyFrameSize = 4096 // Samples per chroma frame (~186ms at 22kHz)
+	keyMaxFrames = 240  // Frames sampled across the track
+	keyLowNote   = 48   // MIDI C3; lower notes are too close together to resolve
+	keyHighNote  = 95   // MIDI B6
+	// keyMinScore is the lowest correlation reported as a key at all
+	keyMinScore = 0.3
+	// keyConfidenceMargin is the lead over the runner-up that gives full confidence
+	keyConfidenceMargin = 0.15
+	// keyAlternatives is the number of runner-up keys kept as candidates
+	keyAlternatives = 2
+)
+
+// Krumhansl-Kessler key profiles, starting from the tonic
+var (
+	majorProfile = [12]float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorProfile = [12]float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+)
+
+// Key names by pitch class, spelled as in CamelotWheel
+var (
+	majorKeyNames = [12]string{"C", "Db", "D", "Eb", "E", "F", "F#", "G", "Ab", "A", "Bb", "B"}
+	minorKeyNames = [12]string{"Cm", "C#m", "Dm", "Ebm", "Em", "Fm", "F#m", "Gm", "G#m", "Am", "Bbm", "Bm"}
+)
+
+// detectKey estimates the musical key from a chromagram of the samples. It
+// returns the keys ranked best first, up to the best plus keyAlternatives, and
+// a 0-1 confidence that grows with the best key's lead over the runner-up.
+// No candidates are returned when no key fits well enough.
+func (a *Analyzer) detectKey(samples []float64) ([]KeyCandidate, float64) {
+	chroma := chromagram(samples, a.sampleRate)
+
+	candidates := make([]KeyCandidate, 0, 24)
+	for tonic := 0; tonic < 12; tonic++ {
+		for _, mode := range []struct {
+			profile [12]float64
+			names   [12]string
+		}{{majorProfile, majorKeyNames}, {minorProfile, minorKeyNames}} {
+			var rotated [12]float64
+			for pc := 0; pc < 12; pc++ {
+				rotated[(pc+tonic)%12] = mode.profile[pc]
+			}
+			score, ok := pearson(chroma, rotated)
+			if !ok {
+				return nil, 0
+			}
+			key := mode.names[tonic]
+			candidates = append(candidates, KeyCandidate{Key: key, Camelot: CamelotWheel[key], Score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	best, runnerUp := candidates[0].Score, candidates[1].Score
+	if best < keyMinScore {
+		return nil, 0
+	}
+
+	confidence := math.Min(best, 1) * math.Min((best-runnerUp)/keyConfidenceMargin, 1)
+	return candidates[:1+keyAlternatives], math.Round(confidence*100) / 100
+}
+
+// chromagram sums the energy of each pitch class over frames sampled evenly
+// across the track. Each frame is normalized so loud passages do not dominate.
+func chromagram(samples []float64, sampleRate int) [12]float64 {
+	var chroma [12]float64
+	if len(samples) < keyFrameSize {
+		return chroma
+	}
+
+	window := make([]float64, keyFrameSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(keyFrameSize-1))
+	}
+
+	type note struct {
+		coeff      float64
+		pitchClass int
+	}
+	notes := make([]note, 0, keyHighNote-keyLowNote+1)
+	for midi := keyLowNote; midi <= keyHighNote; midi++ {
+		freq := 440 * math.Pow(2, float64(midi-69)/12)
+		notes = append(notes, note{coeff: 2 * math.Cos(2*math.Pi*freq/float64(sampleRate)), pitchClass: midi % 12})
+	}
+
+	step := (len(samples) - keyFrameSize) / keyMaxFrames
+	if step < keyFrameSize {
+		step = keyFrameSize
+	}
+
+	frame := make([]float64, keyFrameSize)
+	for start := 0; start+keyFrameSize <= len(samples); start += step {
+		for i := range frame {
+			frame[i] = samples[start+i] * window[i]
+		}
+
+		var frameChroma [12]float64
+		total := 0.0
+		for _, n := range notes {
+			// Goertzel filter tuned to the note's frequency
+			s1, s2 := 0.0, 0.0
+			for _, x := range frame {
+				s1, s2 = x+n.coeff*s1-s2, s1
+			}
+			power := s1*s1 + s2*s2 - n.coeff*s1*s2
+			magnitude := math.Sqrt(math.Max(power, 0))
+			frameChroma[n.pitchClass] += magnitude
+			total += magnitude
+		}
+		if total == 0 {
+			continue
+		}
+		for pc := range chroma {
+			chroma[pc] += frameChroma[pc] / total
+		}
+	}
+	return chroma
+}
+
+// pearson returns the correlation of two pitch-class vectors. It reports
+// false when either vector is flat and the correlation is undefined.
+func pearson(x, y [12]float64) (float64, bool) {
+	var meanX, meanY float64
+	for i := 0; i < 12; i++ {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= 12
+	meanY /= 12
+
+	var cov, varX, varY float64
+	for i := 0; i < 12; i++ {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varX*varY), true
+}
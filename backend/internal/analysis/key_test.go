@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chordSamples synthesizes seconds of the given MIDI notes played together
+func chordSamples(sampleRate int, seconds float64, notes ...int) []float64 {
+	samples := make([]float64, int(float64(sampleRate)*seconds))
+	for _, midi := range notes {
+		freq := 440 * math.Pow(2, float64(midi-69)/12)
+		for i := range samples {
+			samples[i] += 0.2 * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate))
+		}
+	}
+	return samples
+}
+
+func TestDetectKey(t *testing.T) {
+	a := &Analyzer{sampleRate: 22050}
+
+	tests := []struct {
+		name    string
+		notes   []int
+		wantKey string
+	}{
+		{"A minor triad", []int{57, 60, 64, 69}, "Am"},
+		{"C major triad", []int{60, 64, 67, 72}, "C"},
+		{"F# minor triad", []int{54, 57, 61, 66}, "F#m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates, confidence := a.detectKey(chordSamples(a.sampleRate, 6, tt.notes...))
+
+			require.Len(t, candidates, 1+keyAlternatives)
+			assert.Equal(t, tt.wantKey, candidates[0].Key)
+			assert.Equal(t, CamelotWheel[tt.wantKey], candidates[0].Camelot)
+			assert.GreaterOrEqual(t, candidates[0].Score, candidates[1].Score)
+			assert.Greater(t, confidence, 0.0)
+			assert.LessOrEqual(t, confidence, 1.0)
+		})
+	}
+
+	t.Run("silence has no key", func(t *testing.T) {
+		candidates, confidence := a.detectKey(make([]float64, a.sampleRate*6))
+
+		assert.Empty(t, candidates)
+		assert.Zero(t, confidence)
+	})
+
+	t.Run("ambiguous pitch content lowers confidence", func(t *testing.T) {
+		_, clear := a.detectKey(chordSamples(a.sampleRate, 6, 57, 60, 64, 69))
+		// A lone fifth fits A major and A minor equally well
+		_, ambiguous := a.detectKey(chordSamples(a.sampleRate, 6, 57, 64))
+
+		assert.Less(t, ambiguous, clear)
+	})
+}
+
+func TestPearson(t *testing.T) {
+	score, ok := pearson(majorProfile, majorProfile)
+	require.True(t, ok)
+	assert.InDelta(t, 1.0, score, 1e-9)
+
+	_, ok = pearson([12]float64{}, majorProfile)
+	assert.False(t, ok)
+}
@@ -0,0 +1,36 @@
+package analysis
+
+// PreviewSeconds is the length of the preview clip the analyzer picks a start for
+const PreviewSeconds = 30
+
+// detectPreviewStart returns the second at which the loudest PreviewSeconds
+// stretch of the track starts, a cheap stand-in for the chorus or drop.
+// Tracks no longer than a clip start at 0.
+func (a *Analyzer) detectPreviewStart(samples []float64) int {
+	seconds := len(samples) / a.sampleRate
+	if seconds <= PreviewSeconds {
+		return 0
+	}
+
+	// Energy of each whole second
+	energy := make([]float64, seconds)
+	for s := range energy {
+		for _, v := range samples[s*a.sampleRate : (s+1)*a.sampleRate] {
+			energy[s] += v * v
+		}
+	}
+
+	// Slide a clip-long window over the seconds; the first loudest wins
+	window := 0.0
+	for _, e := range energy[:PreviewSeconds] {
+		window += e
+	}
+	best, bestStart := window, 0
+	for start := 1; start+PreviewSeconds <= seconds; start++ {
+		window += energy[start+PreviewSeconds-1] - energy[start-1]
+		if window > best {
+			best, bestStart = window, start
+		}
+	}
+	return bestStart
+}
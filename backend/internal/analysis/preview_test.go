@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loudnessSamples builds a track of the given per-second amplitudes
+func loudnessSamples(sampleRate int, amplitudes []float64) []float64 {
+	samples := make([]float64, 0, sampleRate*len(amplitudes))
+	for _, amplitude := range amplitudes {
+		for i := 0; i < sampleRate; i++ {
+			// Alternate the sign so each second is a square wave of that amplitude
+			if i%2 == 0 {
+				samples = append(samples, amplitude)
+			} else {
+				samples = append(samples, -amplitude)
+			}
+		}
+	}
+	return samples
+}
+
+// section repeats an amplitude for a number of seconds
+func section(amplitude float64, seconds int) []float64 {
+	s := make([]float64, seconds)
+	for i := range s {
+		s[i] = amplitude
+	}
+	return s
+}
+
+func TestDetectPreviewStart(t *testing.T) {
+	a := &Analyzer{sampleRate: 100}
+
+	tests := []struct {
+		name       string
+		amplitudes []float64
+		want       int
+	}{
+		{
+			name:       "quiet intro, loud chorus",
+			amplitudes: append(append(section(0.1, 45), section(0.8, 30)...), section(0.3, 60)...),
+			want:       45,
+		},
+		{
+			name:       "loudest at the start",
+			amplitudes: append(section(0.9, 40), section(0.2, 40)...),
+			want:       0,
+		},
+		{
+			name:       "peak at the very end",
+			amplitudes: append(section(0.2, 50), section(0.7, 20)...),
+			want:       40,
+		},
+		{
+			name:       "shorter than a clip",
+			amplitudes: section(0.5, PreviewSeconds-5),
+			want:       0,
+		},
+		{
+			name:       "evenly loud takes the first window",
+			amplitudes: section(0.5, 90),
+			want:       0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, a.detectPreviewStart(loudnessSamples(a.sampleRate, tt.amplitudes)))
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package artwork derives display hints from cover art: its dominant colors,
+// a BlurHash placeholder and a square crop around the busiest part of the
+// image. Clients use them to render color-matched UI before the image loads.
+package artwork
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // register decoders for embedded cover formats
+	"image/jpeg"
+	_ "image/png"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// ErrUnsupportedImage is returned for data that is not a JPEG, PNG or GIF image
+var ErrUnsupportedImage = errors.New("artwork: unsupported image")
+
+// ErrImageTooLarge is returned for images whose decoded pixels would not fit
+// the processor's memory
+var ErrImageTooLarge = errors.New("artwork: image too large")
+
+const (
+	// maxPixels bounds the decoded size of an image (about 200 MB as RGBA)
+	maxPixels = 50_000_000
+	// sampleEdge is the long edge of the thumbnail every analysis runs on
+	sampleEdge = 64
+	// ThumbnailEdge is the long edge of the thumbnails lists show under data saver
+	ThumbnailEdge = 256
+	// thumbnailQuality is the JPEG quality of those thumbnails
+	thumbnailQuality = 80
+)
+
+// Analyze decodes an image and derives its CoverStyle
+func Analyze(data []byte) (*models.CoverStyle, error) {
+	img, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return Style(img), nil
+}
+
+// Thumbnail decodes an image and encodes a JPEG copy whose long edge is at
+// most edge pixels. Transparent areas turn white.
+func Thumbnail(data []byte, edge int) ([]byte, error) {
+	img, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	thumb := newThumbnail(img, edge)
+	out := image.NewRGBA(image.Rect(0, 0, thumb.width, thumb.height))
+	for y := 0; y < thumb.height; y++ {
+		for x := 0; x < thumb.width; x++ {
+			p := thumb.at(x, y)
+			out.SetRGBA(x, y, color.RGBA{R: p[0], G: p[1], B: p[2], A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("artwork: encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decode decodes a JPEG, PNG or GIF image, refusing ones over maxPixels
+// before their pixels are allocated
+func decode(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: empty image", ErrUnsupportedImage)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	return img, nil
+}
+
+// Style derives the CoverStyle of a decoded image
+func Style(img image.Image) *models.CoverStyle {
+	thumb := newThumbnail(img, sampleEdge)
+	componentsX, componentsY := 4, 3
+	if thumb.height > thumb.width {
+		componentsX, componentsY = 3, 4
+	}
+	return &models.CoverStyle{
+		Colors:   dominantColors(thumb, maxColors),
+		BlurHash: blurHash(thumb, componentsX, componentsY),
+		Crop:     smartCrop(thumb),
+	}
+}
+
+// thumbnail is a downsampled copy of an image as 8-bit RGB. Transparent
+// pixels are composited over white, as a browser shows them.
+type thumbnail struct {
+	width, height int
+	pix           [][3]uint8
+}
+
+func (t *thumbnail) at(x, y int) [3]uint8 {
+	return t.pix[y*t.width+x]
+}
+
+// newThumbnail scales img down so its long edge is at most edge pixels, each
+// thumbnail pixel averaging a grid of up to 4x4 samples of its source area
+func newThumbnail(img image.Image, edge int) *thumbnail {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	w, h := srcW, srcH
+	if w > edge || h > edge {
+		if w >= h {
+			w, h = edge, max(1, srcH*edge/srcW)
+		} else {
+			w, h = max(1, srcW*edge/srcH), edge
+		}
+	}
+
+	t := &thumbnail{width: w, height: h, pix: make([][3]uint8, w*h)}
+	for ty := 0; ty < h; ty++ {
+		y0, y1 := ty*srcH/h, max((ty+1)*srcH/h, ty*srcH/h+1)
+		for tx := 0; tx < w; tx++ {
+			x0, x1 := tx*srcW/w, max((tx+1)*srcW/w, tx*srcW/w+1)
+			stepX, stepY := max(1, (x1-x0)/4), max(1, (y1-y0)/4)
+
+			var sum [3]uint32
+			var n uint32
+			for y := y0; y < y1; y += stepY {
+				for x := x0; x < x1; x += stepX {
+					c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+					a := uint32(c.A)
+					sum[0] += (uint32(c.R)*a + 255*(255-a)) / 255
+					sum[1] += (uint32(c.G)*a + 255*(255-a)) / 255
+					sum[2] += (uint32(c.B)*a + 255*(255-a)) / 255
+					n++
+				}
+			}
+			t.pix[ty*w+tx] = [3]uint8{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n)}
+		}
+	}
+	return t
+}
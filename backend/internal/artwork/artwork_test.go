@@ -0,0 +1,160 @@
+package artwork
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fill returns a w x h image colored by paint
+func fill(w, h int, paint func(x, y int) color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, paint(x, y))
+		}
+	}
+	return img
+}
+
+func solid(c color.Color) func(x, y int) color.Color {
+	return func(int, int) color.Color { return c }
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Run("decodes and styles a PNG", func(t *testing.T) {
+		red := color.NRGBA{R: 255, A: 255}
+		style, err := Analyze(encodePNG(t, fill(300, 300, solid(red))))
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"#ff0000"}, style.Colors)
+		assert.Len(t, style.BlurHash, 28)
+		assert.Nil(t, style.Crop)
+	})
+
+	t.Run("rejects data that is not an image", func(t *testing.T) {
+		_, err := Analyze([]byte("not an image"))
+		assert.ErrorIs(t, err, ErrUnsupportedImage)
+	})
+
+	t.Run("transparent pixels show as white", func(t *testing.T) {
+		style := Style(fill(10, 10, solid(color.NRGBA{})))
+		assert.Equal(t, []string{"#ffffff"}, style.Colors)
+	})
+}
+
+func TestThumbnail(t *testing.T) {
+	t.Run("scales the long edge down to a JPEG", func(t *testing.T) {
+		blue := color.NRGBA{B: 255, A: 255}
+		data, err := Thumbnail(encodePNG(t, fill(1200, 600, solid(blue))), ThumbnailEdge)
+		require.NoError(t, err)
+
+		img, format, err := image.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, image.Rect(0, 0, 256, 128), img.Bounds())
+		_, _, b, _ := img.At(128, 64).RGBA()
+		assert.Greater(t, b>>8, uint32(240))
+	})
+
+	t.Run("keeps the size of small images", func(t *testing.T) {
+		data, err := Thumbnail(encodePNG(t, fill(40, 30, solid(color.NRGBA{}))), ThumbnailEdge)
+		require.NoError(t, err)
+
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, 40, cfg.Width)
+		assert.Equal(t, 30, cfg.Height)
+	})
+
+	t.Run("rejects data that is not an image", func(t *testing.T) {
+		_, err := Thumbnail([]byte("not an image"), ThumbnailEdge)
+		assert.ErrorIs(t, err, ErrUnsupportedImage)
+	})
+}
+
+func TestDominantColors(t *testing.T) {
+	t.Run("most common first", func(t *testing.T) {
+		img := fill(100, 100, func(x, y int) color.Color {
+			switch {
+			case x < 70:
+				return color.NRGBA{R: 20, G: 40, B: 200, A: 255}
+			case x < 90:
+				return color.NRGBA{R: 240, G: 200, B: 10, A: 255}
+			default:
+				return color.NRGBA{R: 250, G: 250, B: 250, A: 255}
+			}
+		})
+
+		assert.Equal(t, []string{"#1428c8", "#f0c80a", "#fafafa"}, dominantColors(newThumbnail(img, sampleEdge), maxColors))
+	})
+
+	t.Run("close shades are reported once", func(t *testing.T) {
+		img := fill(64, 64, func(x, y int) color.Color {
+			return color.NRGBA{R: uint8(100 + x/4), G: 50, B: 50, A: 255}
+		})
+
+		assert.Len(t, dominantColors(newThumbnail(img, sampleEdge), maxColors), 1)
+	})
+}
+
+func TestBlurHash(t *testing.T) {
+	t.Run("encodes the average color", func(t *testing.T) {
+		hash := blurHash(newThumbnail(fill(32, 32, solid(color.NRGBA{R: 255, A: 255})), sampleEdge), 4, 3)
+
+		assert.Len(t, hash, 6+2*11)
+		assert.Equal(t, "L", hash[:1]) // 4x3 components
+		assert.Equal(t, encode83(0xff0000, 4), hash[2:6])
+	})
+
+	t.Run("a single component has no AC part", func(t *testing.T) {
+		hash := blurHash(newThumbnail(fill(8, 8, solid(color.White)), sampleEdge), 1, 1)
+		assert.Equal(t, "00"+encode83(0xffffff, 4), hash)
+	})
+
+	t.Run("portrait images use more vertical components", func(t *testing.T) {
+		style := Style(fill(30, 60, func(x, y int) color.Color { return color.Gray{Y: uint8(y * 4)} }))
+		assert.Equal(t, encode83(2+3*9, 1), style.BlurHash[:1])
+	})
+}
+
+func TestSmartCrop(t *testing.T) {
+	// busyAt draws a checkerboard between from and to on a flat background
+	busyAt := func(from, to int) func(x, y int) color.Color {
+		return func(x, y int) color.Color {
+			if x >= from && x < to && (x/2+y/2)%2 == 0 {
+				return color.Black
+			}
+			return color.White
+		}
+	}
+
+	t.Run("centers on the detail of a wide image", func(t *testing.T) {
+		crop := Style(fill(200, 100, busyAt(100, 200))).Crop
+		require.NotNil(t, crop)
+		assert.InDelta(t, 0.5, crop.X, 0.02)
+		assert.Equal(t, models.CropRect{X: crop.X, Y: 0, Width: 0.5, Height: 1}, *crop)
+	})
+
+	t.Run("flat images crop the middle", func(t *testing.T) {
+		crop := Style(fill(100, 200, solid(color.White))).Crop
+		assert.Equal(t, &models.CropRect{X: 0, Y: 0.25, Width: 1, Height: 0.5}, crop)
+	})
+
+	t.Run("square images are not cropped", func(t *testing.T) {
+		assert.Nil(t, Style(fill(100, 102, busyAt(0, 50))).Crop)
+	})
+}
@@ -0,0 +1,93 @@
+package artwork
+
+import (
+	"math"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// squareTolerance is how far the aspect ratio of an image may be from 1 for
+// it to count as square and get no crop
+const squareTolerance = 0.05
+
+// smartCrop returns the square that covers the most detail of a non-square
+// image, as fractions of its width and height, or nil for square images.
+// Detail is the luminance gradient plus saturation of each pixel, so the
+// crop favors subjects and text over flat backgrounds; among equally busy
+// squares the most central wins.
+func smartCrop(t *thumbnail) *models.CropRect {
+	if math.Abs(float64(t.width)/float64(t.height)-1) <= squareTolerance {
+		return nil
+	}
+
+	horizontal := t.width > t.height
+	side, length := t.height, t.width
+	if !horizontal {
+		side, length = t.width, t.height
+	}
+
+	// Detail summed per column (landscape) or row (portrait)
+	lines := make([]float64, length)
+	for y := 0; y < t.height; y++ {
+		for x := 0; x < t.width; x++ {
+			line := y
+			if horizontal {
+				line = x
+			}
+			lines[line] += detail(t, x, y)
+		}
+	}
+
+	window := 0.0
+	for i := 0; i < side; i++ {
+		window += lines[i]
+	}
+	best, bestScore := 0, window
+	center := float64(length-side) / 2
+	for offset := 1; offset+side <= length; offset++ {
+		window += lines[offset+side-1] - lines[offset-1]
+		switch {
+		case window > bestScore+1e-9:
+			best, bestScore = offset, window
+		case math.Abs(window-bestScore) <= 1e-9 && math.Abs(float64(offset)-center) < math.Abs(float64(best)-center):
+			best = offset
+		}
+	}
+
+	start := roundFraction(float64(best) / float64(length))
+	size := roundFraction(float64(side) / float64(length))
+	if horizontal {
+		return &models.CropRect{X: start, Y: 0, Width: size, Height: 1}
+	}
+	return &models.CropRect{X: 0, Y: start, Width: 1, Height: size}
+}
+
+// detail is the luminance gradient towards the right and lower neighbours
+// plus the saturation of a pixel, all in 0-1
+func detail(t *thumbnail, x, y int) float64 {
+	p := t.at(x, y)
+	lum := luminance(p)
+	var gradient float64
+	if x+1 < t.width {
+		gradient += math.Abs(luminance(t.at(x+1, y)) - lum)
+	}
+	if y+1 < t.height {
+		gradient += math.Abs(luminance(t.at(x, y+1)) - lum)
+	}
+
+	hi := max(p[0], p[1], p[2])
+	lo := min(p[0], p[1], p[2])
+	saturation := 0.0
+	if hi > 0 {
+		saturation = float64(hi-lo) / float64(hi)
+	}
+	return gradient + 0.25*saturation
+}
+
+func luminance(p [3]uint8) float64 {
+	return (0.2126*float64(p[0]) + 0.7152*float64(p[1]) + 0.0722*float64(p[2])) / 255
+}
+
+func roundFraction(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,87 @@
+package artwork
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// maxColors is how many dominant colors are reported
+	maxColors = 5
+	// minColorShare drops colors covering less than this share of the image
+	minColorShare = 0.02
+	// minColorDistance is how far apart in RGB two reported colors must be,
+	// so a gradient is not reported as five shades of one color
+	minColorDistance = 48
+)
+
+// bucket collects the pixels quantized to one color cell
+type bucket struct {
+	sum   [3]int
+	count int
+}
+
+func (b bucket) mean() [3]int {
+	return [3]int{b.sum[0] / b.count, b.sum[1] / b.count, b.sum[2] / b.count}
+}
+
+// dominantColors returns up to limit "#rrggbb" colors, most common first.
+// Pixels are grouped into cells of 16 levels per channel; each cell reports
+// the mean of its pixels, and cells too close to a more common one are skipped.
+func dominantColors(t *thumbnail, limit int) []string {
+	cells := make(map[int]*bucket)
+	for _, p := range t.pix {
+		id := int(p[0]>>4)<<8 | int(p[1]>>4)<<4 | int(p[2]>>4)
+		b, ok := cells[id]
+		if !ok {
+			b = &bucket{}
+			cells[id] = b
+		}
+		b.sum[0] += int(p[0])
+		b.sum[1] += int(p[1])
+		b.sum[2] += int(p[2])
+		b.count++
+	}
+
+	ranked := make([]bucket, 0, len(cells))
+	for _, b := range cells {
+		ranked = append(ranked, *b)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].count != ranked[j].count {
+			return ranked[i].count > ranked[j].count
+		}
+		// Deterministic order for equally common colors
+		mi, mj := ranked[i].mean(), ranked[j].mean()
+		return mi[0]<<16|mi[1]<<8|mi[2] < mj[0]<<16|mj[1]<<8|mj[2]
+	})
+
+	minCount := int(minColorShare * float64(len(t.pix)))
+	var chosen [][3]int
+	for _, b := range ranked {
+		if len(chosen) == limit || (len(chosen) > 0 && b.count < minCount) {
+			break
+		}
+		c := b.mean()
+		if nearAny(c, chosen) {
+			continue
+		}
+		chosen = append(chosen, c)
+	}
+
+	colors := make([]string, len(chosen))
+	for i, c := range chosen {
+		colors[i] = fmt.Sprintf("#%02x%02x%02x", c[0], c[1], c[2])
+	}
+	return colors
+}
+
+func nearAny(c [3]int, chosen [][3]int) bool {
+	for _, o := range chosen {
+		dr, dg, db := c[0]-o[0], c[1]-o[1], c[2]-o[2]
+		if dr*dr+dg*dg+db*db < minColorDistance*minColorDistance {
+			return true
+		}
+	}
+	return false
+}
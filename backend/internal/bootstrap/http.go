@@ -0,0 +1,24 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// UnavailableResponse is the API Gateway response of an HTTP Lambda whose
+// dependencies could not be built. The cause is left to the function's logs,
+// since configuration errors can name secrets.
+func UnavailableResponse(service string) events.APIGatewayV2HTTPResponse {
+	body, _ := json.Marshal(models.NewErrorResponse(
+		models.NewServiceUnavailableError(service, "startup failed; see function logs"),
+	))
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: http.StatusServiceUnavailable,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}
+}
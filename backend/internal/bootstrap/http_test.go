@@ -0,0 +1,23 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+func TestUnavailableResponse(t *testing.T) {
+	resp := UnavailableResponse("api")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Headers["Content-Type"])
+
+	var body models.ErrorResponse
+	require.NoError(t, json.Unmarshal([]byte(resp.Body), &body))
+	assert.Equal(t, "SERVICE_UNAVAILABLE", body.Error.Code)
+	assert.Equal(t, "api is not available", body.Error.Message)
+}
@@ -0,0 +1,96 @@
+// Package bootstrap builds the configuration and AWS clients of the Lambda
+// binaries on first use instead of in init(). A cold start only pays for the
+// clients its invocation touches, and a configuration or AWS error is returned
+// from the handler, and retried on the next invocation, instead of panicking
+// in init() and failing every invocation of the sandbox.
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Lazy is a value built on first use and memoized. A failed build is not
+// memoized: Get returns the error and the next call builds again, so a
+// transient failure only fails the invocations that hit it. Lazy is safe for
+// concurrent use.
+type Lazy[T any] struct {
+	name  string
+	build func(ctx context.Context) (T, error)
+
+	mu    sync.Mutex
+	built bool
+	value T
+}
+
+// NewLazy returns a Lazy that builds its value with build. The name labels
+// errors and build timings.
+func NewLazy[T any](name string, build func(ctx context.Context) (T, error)) *Lazy[T] {
+	return &Lazy[T]{name: name, build: build}
+}
+
+// Get returns the value, building it on the first call
+func (l *Lazy[T]) Get(ctx context.Context) (T, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.built {
+		return l.value, nil
+	}
+
+	start := time.Now()
+	value, err := l.build(ctx)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("%s: %w", l.name, err)
+	}
+	l.value, l.built = value, true
+	record(l.name, time.Since(start))
+	return value, nil
+}
+
+// Set replaces the value without building it, for tests and for binaries that
+// construct a dependency themselves
+func (l *Lazy[T]) Set(value T) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.value, l.built = value, true
+}
+
+// Built reports whether the value has been built
+func (l *Lazy[T]) Built() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.built
+}
+
+// Timing is how long one dependency took to build
+type Timing struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+var timings struct {
+	mu   sync.Mutex
+	list []Timing
+}
+
+// record logs and keeps a build timing, so cold-start cost shows up per
+// dependency in the function's logs
+func record(name string, d time.Duration) {
+	log.Printf("bootstrap: %s ready in %s", name, d.Round(time.Microsecond))
+
+	timings.mu.Lock()
+	defer timings.mu.Unlock()
+	timings.list = append(timings.list, Timing{Name: name, Duration: d})
+}
+
+// Timings returns the build time of every dependency built so far, in build order
+func Timings() []Timing {
+	timings.mu.Lock()
+	defer timings.mu.Unlock()
+	return append([]Timing(nil), timings.list...)
+}
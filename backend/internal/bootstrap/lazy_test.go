@@ -0,0 +1,69 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazy_BuildsOnce(t *testing.T) {
+	builds := 0
+	lazy := NewLazy("counter", func(context.Context) (int, error) {
+		builds++
+		return 42, nil
+	})
+	assert.False(t, lazy.Built())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := lazy.Get(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, builds)
+	assert.True(t, lazy.Built())
+	timings := Timings()
+	require.NotEmpty(t, timings)
+	assert.Equal(t, "counter", timings[len(timings)-1].Name)
+}
+
+func TestLazy_RetriesFailedBuild(t *testing.T) {
+	fail := true
+	lazy := NewLazy("flaky client", func(context.Context) (string, error) {
+		if fail {
+			return "", errors.New("throttled")
+		}
+		return "client", nil
+	})
+
+	_, err := lazy.Get(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, "flaky client: throttled", err.Error())
+	assert.False(t, lazy.Built())
+
+	fail = false
+	value, err := lazy.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "client", value)
+}
+
+func TestLazy_Set(t *testing.T) {
+	lazy := NewLazy("stub", func(context.Context) (string, error) {
+		return "", errors.New("must not build")
+	})
+	lazy.Set("stubbed")
+
+	value, err := lazy.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "stubbed", value)
+}
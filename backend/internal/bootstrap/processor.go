@@ -0,0 +1,130 @@
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/gvasels/personal-music-searchengine/internal/alerting"
+	appconfig "github.com/gvasels/personal-music-searchengine/internal/config"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// Processor holds the lazily built configuration and clients shared by the
+// upload pipeline Lambdas and the Cognito triggers. In multi-tenant mode the
+// DynamoDB and S3 clients are tenant-scoped.
+type Processor struct {
+	config   *Lazy[*appconfig.Processor]
+	aws      *Lazy[aws.Config]
+	dynamoDB *Lazy[repository.DynamoDBClient]
+	s3       *Lazy[repository.S3Client]
+	repo     *Lazy[repository.Repository]
+	alerter  *Lazy[*alerting.Alerter]
+}
+
+// NewProcessor returns a Processor configured by appconfig.LoadProcessor
+func NewProcessor() *Processor {
+	return NewProcessorWith(appconfig.LoadProcessor)
+}
+
+// NewProcessorWith returns a Processor configured by load, for binaries whose
+// configuration extends appconfig.Processor
+func NewProcessorWith(load func() (*appconfig.Processor, error)) *Processor {
+	p := &Processor{}
+	p.config = NewLazy("configuration", func(context.Context) (*appconfig.Processor, error) {
+		return load()
+	})
+	p.aws = NewLazy("AWS config", func(ctx context.Context) (aws.Config, error) {
+		return awsconfig.LoadDefaultConfig(ctx)
+	})
+	p.dynamoDB = NewLazy("DynamoDB client", func(ctx context.Context) (repository.DynamoDBClient, error) {
+		cfg, awsCfg, err := p.configs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var client repository.DynamoDBClient = dynamodb.NewFromConfig(awsCfg)
+		if cfg.MultiTenantMode {
+			client = repository.NewTenantDynamoDBClient(client)
+		}
+		return client, nil
+	})
+	p.s3 = NewLazy("S3 client", func(ctx context.Context) (repository.S3Client, error) {
+		cfg, awsCfg, err := p.configs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var client repository.S3Client = s3.NewFromConfig(awsCfg)
+		if cfg.MultiTenantMode {
+			client = repository.NewTenantS3Client(client)
+		}
+		return client, nil
+	})
+	p.repo = NewLazy("repository", func(ctx context.Context) (repository.Repository, error) {
+		cfg, err := p.Config(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client, err := p.DynamoDB(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewDynamoDBRepository(client, cfg.DynamoDBTableName), nil
+	})
+	p.alerter = NewLazy("alerter", func(ctx context.Context) (*alerting.Alerter, error) {
+		cfg, err := p.Config(ctx)
+		if err != nil || cfg.AlertTopicARN == "" {
+			return nil, err
+		}
+		awsCfg, err := p.AWS(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return alerting.New(alerting.NewSNSPublisher(awsCfg), cfg.AlertTopicARN), nil
+	})
+	return p
+}
+
+// Config returns the validated configuration
+func (p *Processor) Config(ctx context.Context) (*appconfig.Processor, error) {
+	return p.config.Get(ctx)
+}
+
+// AWS returns the default AWS configuration
+func (p *Processor) AWS(ctx context.Context) (aws.Config, error) {
+	return p.aws.Get(ctx)
+}
+
+// DynamoDB returns the table client
+func (p *Processor) DynamoDB(ctx context.Context) (repository.DynamoDBClient, error) {
+	return p.dynamoDB.Get(ctx)
+}
+
+// S3 returns the media bucket client
+func (p *Processor) S3(ctx context.Context) (repository.S3Client, error) {
+	return p.s3.Get(ctx)
+}
+
+// Repository returns the DynamoDB repository
+func (p *Processor) Repository(ctx context.Context) (repository.Repository, error) {
+	return p.repo.Get(ctx)
+}
+
+// Alerter returns the operational alerter, or nil when ALERT_TOPIC_ARN is not set
+func (p *Processor) Alerter(ctx context.Context) (*alerting.Alerter, error) {
+	return p.alerter.Get(ctx)
+}
+
+func (p *Processor) configs(ctx context.Context) (*appconfig.Processor, aws.Config, error) {
+	cfg, err := p.Config(ctx)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	awsCfg, err := p.AWS(ctx)
+	if err != nil {
+		return nil, aws.Config{}, err
+	}
+	return cfg, awsCfg, nil
+}
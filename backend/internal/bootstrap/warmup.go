@@ -0,0 +1,83 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// WarmupEvent is the payload scheduled warmers and provisioned-concurrency
+// hooks send to keep a Lambda's dependencies loaded: {"warmup": true}. The
+// API, search and gateway Lambdas answer it without running a request.
+type WarmupEvent struct {
+	Warmup bool `json:"warmup"`
+}
+
+// WarmupResponse reports what a warmup invocation loaded
+type WarmupResponse struct {
+	Warmed     bool     `json:"warmed"`
+	DurationMs int64    `json:"durationMs"`
+	Loaded     []Timing `json:"loaded"` // Dependencies built by this invocation; empty when the sandbox was already warm
+	Error      string   `json:"error,omitempty"`
+}
+
+// IsWarmup reports whether a raw invocation payload is a warmup event
+func IsWarmup(payload []byte) bool {
+	var event WarmupEvent
+	return json.Unmarshal(payload, &event) == nil && event.Warmup
+}
+
+// Warm runs warm and reports the dependencies it built. Failures are logged
+// and reported rather than returned, so a warmer never retries into a broken
+// configuration.
+func Warm(ctx context.Context, warm func(ctx context.Context) error) WarmupResponse {
+	start := time.Now()
+	before := len(Timings())
+
+	err := warm(ctx)
+
+	resp := WarmupResponse{
+		Warmed:     err == nil,
+		DurationMs: time.Since(start).Milliseconds(),
+		Loaded:     Timings()[before:],
+	}
+	if err != nil {
+		log.Printf("Warmup failed: %v", err)
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+// WithWarmup returns a Lambda handler that answers warmup events by running
+// warm and decodes every other payload into E for handler
+func WithWarmup[E, R any](warm func(ctx context.Context) error, handler func(ctx context.Context, event E) (R, error)) func(ctx context.Context, payload json.RawMessage) (any, error) {
+	return func(ctx context.Context, payload json.RawMessage) (any, error) {
+		if IsWarmup(payload) {
+			return Warm(ctx, warm), nil
+		}
+
+		var event E
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("invalid event: %w", err)
+		}
+		return handler(ctx, event)
+	}
+}
+
+// ProvisionedConcurrency reports whether the sandbox is being initialized for
+// provisioned concurrency, where init time is not on any request's path
+func ProvisionedConcurrency() bool {
+	return os.Getenv("AWS_LAMBDA_INITIALIZATION_TYPE") == "provisioned-concurrency"
+}
+
+// WarmIfProvisioned runs warm during init in provisioned-concurrency sandboxes,
+// so their first request finds every dependency built. On-demand sandboxes
+// stay lazy. Errors are logged; the lazy builders retry on the first request.
+func WarmIfProvisioned(warm func(ctx context.Context) error) {
+	if ProvisionedConcurrency() {
+		Warm(context.Background(), warm)
+	}
+}
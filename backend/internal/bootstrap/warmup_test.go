@@ -0,0 +1,86 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWarmup(t *testing.T) {
+	assert.True(t, IsWarmup([]byte(`{"warmup": true}`)))
+	assert.False(t, IsWarmup([]byte(`{"warmup": false}`)))
+	assert.False(t, IsWarmup([]byte(`{"operation": "search"}`)))
+	assert.False(t, IsWarmup([]byte(`[1, 2]`)))
+	assert.False(t, IsWarmup(nil))
+}
+
+type echoEvent struct {
+	Message string `json:"message"`
+}
+
+func TestWithWarmup(t *testing.T) {
+	lazy := NewLazy("warmup test dependency", func(context.Context) (string, error) {
+		return "ready", nil
+	})
+	warm := func(ctx context.Context) error {
+		_, err := lazy.Get(ctx)
+		return err
+	}
+	handled := 0
+	handler := WithWarmup(warm, func(ctx context.Context, event echoEvent) (string, error) {
+		handled++
+		return "echo: " + event.Message, nil
+	})
+
+	t.Run("warmup builds dependencies once", func(t *testing.T) {
+		result, err := handler(context.Background(), json.RawMessage(`{"warmup": true}`))
+		require.NoError(t, err)
+		resp := result.(WarmupResponse)
+		assert.True(t, resp.Warmed)
+		require.Len(t, resp.Loaded, 1)
+		assert.Equal(t, "warmup test dependency", resp.Loaded[0].Name)
+
+		result, err = handler(context.Background(), json.RawMessage(`{"warmup": true}`))
+		require.NoError(t, err)
+		assert.Empty(t, result.(WarmupResponse).Loaded, "already warm")
+		assert.Zero(t, handled)
+	})
+
+	t.Run("other events reach the handler", func(t *testing.T) {
+		result, err := handler(context.Background(), json.RawMessage(`{"message": "hi"}`))
+		require.NoError(t, err)
+		assert.Equal(t, "echo: hi", result)
+		assert.Equal(t, 1, handled)
+
+		_, err = handler(context.Background(), json.RawMessage(`"not an object"`))
+		assert.Error(t, err)
+	})
+}
+
+func TestWarm_ReportsFailure(t *testing.T) {
+	resp := Warm(context.Background(), func(context.Context) error {
+		return errors.New("configuration: DYNAMODB_TABLE_NAME not set")
+	})
+	assert.False(t, resp.Warmed)
+	assert.Equal(t, "configuration: DYNAMODB_TABLE_NAME not set", resp.Error)
+}
+
+func TestWarmIfProvisioned(t *testing.T) {
+	calls := 0
+	warm := func(context.Context) error {
+		calls++
+		return nil
+	}
+
+	t.Setenv("AWS_LAMBDA_INITIALIZATION_TYPE", "on-demand")
+	WarmIfProvisioned(warm)
+	assert.Zero(t, calls)
+
+	t.Setenv("AWS_LAMBDA_INITIALIZATION_TYPE", "provisioned-concurrency")
+	WarmIfProvisioned(warm)
+	assert.Equal(t, 1, calls)
+}
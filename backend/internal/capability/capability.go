@@ -0,0 +1,129 @@
+// Package capability records which optional subsystems a binary was able to
+// wire at startup (search, admin, upload processing, ...) and why the others
+// are disabled, so endpoints can answer 503 with a reason instead of failing on
+// a nil service and operators can inspect the state via GET /status.
+package capability
+
+import (
+	"sort"
+	"sync"
+)
+
+// Name identifies an optional subsystem
+type Name string
+
+const (
+	// Search is full-text search through the Nixiesearch Lambda
+	Search Name = "search"
+	// Admin is user management through Cognito
+	Admin Name = "admin"
+	// UploadProcessing is the Step Functions upload pipeline, or the
+	// in-process one of a self-hosted server
+	UploadProcessing Name = "upload_processing"
+	// SignedURLs is CloudFront signed streaming URLs (S3 presigned URLs otherwise)
+	SignedURLs Name = "signed_urls"
+	// Transcode is HLS transcoding through MediaConvert
+	Transcode Name = "transcode"
+	// Moderation is the content check before tracks are made public
+	Moderation Name = "moderation"
+	// DownloadProtection is watermarking of downloads through the watermark Lambda
+	DownloadProtection Name = "download_protection"
+)
+
+// Overall service states reported by Report
+const (
+	StateOK       = "ok"
+	StateDegraded = "degraded"
+)
+
+// Status is the state of one capability
+type Status struct {
+	Name    Name   `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Report is the operator-facing view of all registered capabilities
+type Report struct {
+	Status       string   `json:"status"`
+	Capabilities []Status `json:"capabilities"`
+}
+
+// Registry holds the enabled/disabled state of each capability. It is safe for
+// concurrent use; a nil Registry reports every capability as enabled.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[Name]Status
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[Name]Status)}
+}
+
+// Enable marks a capability as available
+func (r *Registry) Enable(name Name) {
+	r.set(Status{Name: name, Enabled: true})
+}
+
+// Disable marks a capability as unavailable with an operator-readable reason
+func (r *Registry) Disable(name Name, reason string) {
+	r.set(Status{Name: name, Enabled: false, Reason: reason})
+}
+
+// Set enables the capability when enabled is true and disables it with reason otherwise
+func (r *Registry) Set(name Name, enabled bool, reason string) {
+	if enabled {
+		r.Enable(name)
+		return
+	}
+	r.Disable(name, reason)
+}
+
+func (r *Registry) set(status Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[status.Name] = status
+}
+
+// Get returns the status of a capability; unregistered capabilities report as enabled
+func (r *Registry) Get(name Name) Status {
+	if r == nil {
+		return Status{Name: name, Enabled: true}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if status, ok := r.entries[name]; ok {
+		return status
+	}
+	return Status{Name: name, Enabled: true}
+}
+
+// Enabled reports whether a capability is available
+func (r *Registry) Enabled(name Name) bool {
+	return r.Get(name).Enabled
+}
+
+// Report returns every registered capability sorted by name. The overall status
+// is degraded when any capability is disabled.
+func (r *Registry) Report() Report {
+	report := Report{Status: StateOK, Capabilities: []Status{}}
+	if r == nil {
+		return report
+	}
+
+	r.mu.RLock()
+	for _, status := range r.entries {
+		report.Capabilities = append(report.Capabilities, status)
+		if !status.Enabled {
+			report.Status = StateDegraded
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(report.Capabilities, func(i, j int) bool {
+		return report.Capabilities[i].Name < report.Capabilities[j].Name
+	})
+	return report
+}
@@ -0,0 +1,49 @@
+package capability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("unregistered capabilities are enabled", func(t *testing.T) {
+		r := NewRegistry()
+
+		assert.True(t, r.Enabled(Search))
+		assert.Equal(t, StateOK, r.Report().Status)
+	})
+
+	t.Run("disabled capability carries its reason", func(t *testing.T) {
+		r := NewRegistry()
+		r.Disable(Search, "NIXIESEARCH_FUNCTION_NAME not set")
+
+		status := r.Get(Search)
+
+		assert.False(t, status.Enabled)
+		assert.Equal(t, "NIXIESEARCH_FUNCTION_NAME not set", status.Reason)
+	})
+
+	t.Run("report is sorted and degraded when anything is disabled", func(t *testing.T) {
+		r := NewRegistry()
+		r.Set(UploadProcessing, true, "")
+		r.Set(Admin, false, "COGNITO_USER_POOL_ID not set")
+		r.Enable(Search)
+
+		report := r.Report()
+
+		assert.Equal(t, StateDegraded, report.Status)
+		assert.Equal(t, []Status{
+			{Name: Admin, Enabled: false, Reason: "COGNITO_USER_POOL_ID not set"},
+			{Name: Search, Enabled: true},
+			{Name: UploadProcessing, Enabled: true},
+		}, report.Capabilities)
+	})
+
+	t.Run("nil registry reports everything enabled", func(t *testing.T) {
+		var r *Registry
+
+		assert.True(t, r.Enabled(Admin))
+		assert.Equal(t, StateOK, r.Report().Status)
+	})
+}
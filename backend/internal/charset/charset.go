@@ -0,0 +1,119 @@
+// Package charset repairs tag text that was decoded with the wrong character
+// set ("mojibake"), as happens with legacy ID3v1 and ID3v2.3 tags that declare
+// ISO-8859-1 but hold UTF-8 or Windows-1251 bytes.
+package charset
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Encoding names the character set text was actually written in
+type Encoding string
+
+const (
+	// UTF8 is UTF-8 text that was read as Latin-1 or Windows-1252 ("CafÃ©")
+	UTF8 Encoding = "utf-8"
+	// Windows1251 is Cyrillic text that was read as Latin-1 ("Êèíî")
+	Windows1251 Encoding = "windows-1251"
+)
+
+// minCyrillicLetters is how many misread letters a value needs before it is
+// considered Windows-1251; shorter values are too ambiguous
+const minCyrillicLetters = 3
+
+// Repair returns the text s most likely was before it was decoded with the
+// wrong character set, and that character set. ok is false when s looks
+// correctly decoded.
+func Repair(s string) (repaired string, encoding Encoding, ok bool) {
+	raw, ok := latin1Bytes(s)
+	if !ok {
+		return "", "", false
+	}
+
+	if utf8.Valid(raw) {
+		if decoded := string(raw); decoded != s {
+			return decoded, UTF8, true
+		}
+		return "", "", false
+	}
+
+	if looksLikeMisreadCyrillic(s) {
+		return decodeWindows1251(raw), Windows1251, true
+	}
+	return "", "", false
+}
+
+// latin1Bytes re-encodes s as the single-byte string it was decoded from.
+// It fails when s holds characters outside Latin-1 and Windows-1252, or only
+// ASCII, which needs no repair.
+func latin1Bytes(s string) ([]byte, bool) {
+	raw := make([]byte, 0, len(s))
+	high := false
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			raw = append(raw, byte(r))
+		case r <= 0xFF:
+			raw = append(raw, byte(r))
+			high = true
+		default:
+			b, ok := windows1252Bytes[r]
+			if !ok {
+				return nil, false
+			}
+			raw = append(raw, b)
+			high = true
+		}
+	}
+	return raw, high
+}
+
+// looksLikeMisreadCyrillic reports whether most letters of s are accented
+// Latin-1 letters (U+00C0-U+00FF), as Cyrillic read as Latin-1 is. Real
+// Western European text has a few accented letters among many plain ones.
+func looksLikeMisreadCyrillic(s string) bool {
+	accented, plain := 0, 0
+	for _, r := range s {
+		switch {
+		case r >= 0xC0 && r <= 0xFF && r != 0xD7 && r != 0xF7:
+			accented++
+		case unicode.IsLetter(r):
+			plain++
+		}
+	}
+	return accented >= minCyrillicLetters && accented > 2*plain
+}
+
+func decodeWindows1251(raw []byte) string {
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		switch {
+		case b < 0x80:
+			runes[i] = rune(b)
+		case b >= 0xC0:
+			runes[i] = rune(b-0xC0) + 'А'
+		default:
+			runes[i] = windows1251High[b-0x80]
+		}
+	}
+	return string(runes)
+}
+
+// windows1251High maps Windows-1251 bytes 0x80-0xBF to Unicode; 0xC0-0xFF are
+// the contiguous letters А-я
+var windows1251High = [64]rune{
+	'Ђ', 'Ѓ', '‚', 'ѓ', '„', '…', '†', '‡', '€', '‰', 'Љ', '‹', 'Њ', 'Ќ', 'Ћ', 'Џ',
+	'ђ', '‘', '’', '“', '”', '•', '–', '—', utf8.RuneError, '™', 'љ', '›', 'њ', 'ќ', 'ћ', 'џ',
+	'\u00a0', 'Ў', 'ў', 'Ј', '¤', 'Ґ', '¦', '§', 'Ё', '©', 'Є', '«', '¬', '\u00ad', '®', 'Ї',
+	'°', '±', 'І', 'і', 'ґ', 'µ', '¶', '·', 'ё', '№', 'є', '»', 'ј', 'Ѕ', 'ѕ', 'ї',
+}
+
+// windows1252Bytes maps the characters Windows-1252 places in 0x80-0x9F back
+// to their bytes, for text that was decoded as Windows-1252 rather than Latin-1
+var windows1252Bytes = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
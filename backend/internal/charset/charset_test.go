@@ -0,0 +1,46 @@
+package charset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// misread decodes b byte by byte as Latin-1, as a legacy tag reader does
+func misread(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func TestRepair(t *testing.T) {
+	cp1251Kino := []byte{0xCA, 0xE8, 0xED, 0xEE, ' ', '-', ' ', 0xC3, 0xF0, 0xF3, 0xEF, 0xEF, 0xE0, ' ', 0xEA, 0xF0, 0xEE, 0xE2, 0xE8}
+
+	tests := []struct {
+		name     string
+		input    string
+		want     string
+		encoding Encoding
+		ok       bool
+	}{
+		{"UTF-8 read as Latin-1", misread([]byte("Café del Mar")), "Café del Mar", UTF8, true},
+		{"UTF-8 read as Windows-1252", "BeyoncÃ© â€“ Halo", "Beyoncé – Halo", UTF8, true},
+		{"Cyrillic read as Latin-1", misread(cp1251Kino), "Кино - Группа крови", Windows1251, true},
+		{"plain ASCII", "Daft Punk", "", "", false},
+		{"correct accented text", "Sigur Rós – Ágætis byrjun", "", "", false},
+		{"correct German text", "Die Ärzte", "", "", false},
+		{"correct Cyrillic", "Кино", "", "", false},
+		{"too short to tell", misread([]byte{0xC4, 0xC0}), "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, encoding, ok := Repair(tt.input)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.encoding, encoding)
+		})
+	}
+}
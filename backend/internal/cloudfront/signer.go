@@ -12,8 +12,11 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
 )
 
 // Expiration bounds for signed URLs
@@ -118,10 +121,9 @@ func (s *Signer) GenerateSignedURLWithOptions(ctx context.Context, key string, e
 			parts := strings.Split(key, "/")
 			filename = parts[len(parts)-1]
 		}
-		// URL-encode the filename for Content-Disposition header
-		encodedFilename := strings.ReplaceAll(filename, " ", "%20")
-		encodedFilename = strings.ReplaceAll(encodedFilename, "\"", "%22")
-		queryParams = fmt.Sprintf("response-content-disposition=attachment%%3B%%20filename%%3D%%22%s%%22", encodedFilename)
+		// URL-encode the Content-Disposition header
+		disposition := strings.ReplaceAll(url.QueryEscape(sanitize.ContentDisposition(filename)), "+", "%20")
+		queryParams = "response-content-disposition=" + disposition
 		resourceURL = baseURL + "?" + queryParams
 	}
 
@@ -158,7 +160,10 @@ func (s *Signer) GenerateSignedURLWithOptions(ctx context.Context, key string, e
 
 // SignStreamURL generates a signed URL for HLS streaming.
 func (s *Signer) SignStreamURL(ctx context.Context, userID, trackID string, expiry time.Duration) (string, error) {
-	key := fmt.Sprintf("hls/%s/%s/master.m3u8", userID, trackID)
+	key, err := sanitize.Key("hls", userID, trackID, "master.m3u8")
+	if err != nil {
+		return "", err
+	}
 	return s.GenerateSignedURL(ctx, key, expiry)
 }
 
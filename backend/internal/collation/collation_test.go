@@ -0,0 +1,93 @@
+package collation
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sortByKey sorts texts by their collation keys compared as plain strings,
+// the way DynamoDB orders an index
+func sortByKey(locale string, texts ...string) []string {
+	sorted := append([]string(nil), texts...)
+	sort.SliceStable(sorted, func(i, j int) bool { return Key(locale, sorted[i]) < Key(locale, sorted[j]) })
+	return sorted
+}
+
+func TestKey(t *testing.T) {
+	t.Run("accents sort with their base letter", func(t *testing.T) {
+		assert.Equal(t,
+			[]string{"Daft Punk", "Édith Piaf", "Elvis Presley", "Zaz"},
+			sortByKey(Root, "Zaz", "Elvis Presley", "Édith Piaf", "Daft Punk"))
+	})
+
+	t.Run("case does not separate names", func(t *testing.T) {
+		assert.Equal(t, []string{"abba", "Blur", "cream"}, sortByKey(Root, "cream", "Blur", "abba"))
+	})
+
+	t.Run("numbers sort by value", func(t *testing.T) {
+		assert.Equal(t, []string{"Track 2", "Track 10"}, sortByKey(Root, "Track 10", "Track 2"))
+	})
+
+	t.Run("locale rules apply", func(t *testing.T) {
+		// Swedish sorts Ö after Z; German sorts it with O
+		assert.Equal(t, []string{"Zara", "Östen"}, sortByKey("sv", "Östen", "Zara"))
+		assert.Equal(t, []string{"Östen", "Zara"}, sortByKey("de", "Östen", "Zara"))
+	})
+
+	t.Run("Chinese sorts by pinyin", func(t *testing.T) {
+		// zhāng, wáng, lǐ
+		assert.Equal(t, []string{"李", "王", "张"}, sortByKey("zh", "张", "王", "李"))
+	})
+
+	t.Run("keys are hex and bounded", func(t *testing.T) {
+		key := Key(Root, string(make([]rune, 2000)))
+		assert.Regexp(t, `^[0-9a-f]*$`, key)
+		assert.LessOrEqual(t, len(key), 2*maxKeyBytes)
+	})
+
+	t.Run("invalid locales fall back to root", func(t *testing.T) {
+		assert.Equal(t, Key(Root, "Édith"), Key("not a locale", "Édith"))
+	})
+
+	t.Run("safe for concurrent use", func(t *testing.T) {
+		want := Key("fr", "Édith Piaf")
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					assert.Equal(t, want, Key("fr", "Édith Piaf"))
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestCompare(t *testing.T) {
+	assert.Equal(t, -1, Compare(Root, "Édith", "Elvis"))
+	assert.Equal(t, 1, Compare("sv", "Östen", "Zara"))
+	assert.Equal(t, 0, Compare(Root, "Blur", "Blur"))
+}
+
+func TestValidate(t *testing.T) {
+	for _, locale := range []string{Root, "fr", "sv", "de-AT", "zh-Hans", "ja"} {
+		assert.NoError(t, Validate(locale), locale)
+	}
+	for _, locale := range []string{"not a locale", "xx-invalid-123456789"} {
+		assert.ErrorIs(t, Validate(locale), ErrUnsupportedLocale, locale)
+	}
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, Root, FromContext(context.Background()))
+
+	ctx := WithLocale(context.Background(), "fr")
+	require.Equal(t, "fr", FromContext(ctx))
+}
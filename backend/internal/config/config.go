@@ -0,0 +1,659 @@
+// Package config loads typed, validated configuration for every binary in the
+// backend (API, gateway, processors, triggers and the search Lambda). Values
+// come from environment variables; secrets may instead be referenced by name
+// and resolved from SSM Parameter Store or Secrets Manager (see secrets.go).
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMissingRequired is wrapped by validation errors for unset required variables
+var ErrMissingRequired = errors.New("config: required variable not set")
+
+// Base holds settings shared by every binary that talks to the data stores.
+type Base struct {
+	// AWSRegion defaults to us-east-1
+	AWSRegion string
+	// AWSEndpoint overrides AWS endpoints (LocalStack in local development)
+	AWSEndpoint string
+	// DynamoDBEndpoint and S3Endpoint override AWSEndpoint for one service,
+	// e.g. DynamoDB Local and MinIO on a self-hosted server
+	DynamoDBEndpoint string
+	S3Endpoint       string
+	// DynamoDBTableName is the single-table design table
+	DynamoDBTableName string
+	// MediaBucketName holds uploads, media, covers and HLS output
+	MediaBucketName string
+	// MultiTenantMode prefixes all partition and object keys with the request tenant
+	MultiTenantMode bool
+	// AlertTopicARN is the SNS topic that receives operational alerts; empty disables them
+	AlertTopicARN string
+}
+
+// API is the configuration of the main REST API (cmd/api).
+type API struct {
+	Base
+
+	StepFunctionsARN        string
+	NixiesearchFunctionName string
+	// SearchTimeout bounds each search Lambda attempt; after SearchBreakerThreshold
+	// consecutive failures the client stops calling it for SearchBreakerCooldown
+	// and searches fall back to a scan of the library
+	SearchTimeout          time.Duration
+	SearchBreakerThreshold int
+	SearchBreakerCooldown  time.Duration
+	CognitoUserPoolID      string
+
+	// Retries and circuit breaking of DynamoDB, S3 and search Lambda calls
+	// (see internal/resilience). Each dependency has its own breaker; the
+	// search breaker defaults to these settings
+	RetryMaxAttempts int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Concurrent table scans, bulk edits and search index rebuilds (see
+	// resilience.Limiter); operations wait up to ConcurrencyWait for a slot.
+	// 0 removes a limit
+	ScanConcurrency    int
+	BulkConcurrency    int
+	ReindexConcurrency int
+	ConcurrencyWait    time.Duration
+	// ModerationFunctionName holds tracks made public for a moderation check when set
+	ModerationFunctionName string
+	// WatermarkFunctionName enables watermarked downloads of protected tracks when set
+	WatermarkFunctionName string
+	// PipelineLogGroups lists the upload pipeline Lambdas' log groups,
+	// comma-separated, for the logs of the upload debug bundle
+	PipelineLogGroups string
+
+	// CloudFront signed URLs (optional; S3 presigned URLs are used otherwise)
+	CloudFrontDomain     string
+	CloudFrontKeyPairID  string
+	CloudFrontPrivateKey string
+
+	// PreviewSigningKeys ("id:secret,...", parsed by reqsign.ParseKeyring)
+	// signs the share links of preview clips; empty disables preview links
+	PreviewSigningKeys string
+
+	// Tenant resolution in multi-tenant mode
+	TenantClaim   string
+	TenantDomains string
+
+	// Cross-origin policy (see middleware.CORSConfig). Origins and route
+	// methods are raw lists, parsed by the middleware package
+	CORSAllowOrigins     string
+	CORSAllowCredentials bool
+	CORSRouteMethods     string
+	CORSMaxAge           time.Duration
+
+	// Browser hardening (see middleware.SecurityConfig and middleware.CSRFConfig).
+	// CSRF checks are enabled by naming the session cookie
+	HSTSMaxAge        time.Duration
+	FrameAncestors    string
+	SessionCookieName string
+	CSRFCookieSecure  bool
+
+	// TrustedProxies lists the reverse proxies whose X-Forwarded-For a plain
+	// HTTP server believes (see middleware.ClientIP); a raw list parsed by
+	// the middleware package
+	TrustedProxies string
+
+	// ServerPort is used when running as a plain HTTP server (local development)
+	ServerPort string
+
+	// UserCacheTTL is how long user roles are cached across requests (0 caches per request only)
+	UserCacheTTL time.Duration
+
+	// DemoMode serves the API from in-memory stores with no AWS dependencies
+	DemoMode bool
+
+	// SelfHosted runs the search engine and the upload pipeline inside the
+	// API, with the search index in SearchIndexBucket, so a plain HTTP server
+	// needs no Lambda functions or Step Functions
+	SelfHosted        bool
+	SearchIndexBucket string
+
+	// DataStore selects where library data is kept: DataStoreDynamoDB
+	// (DynamoDBTableName) or DataStoreLocal (a snapshot file in MediaDir,
+	// saved every DataSaveInterval when something changed and on shutdown)
+	DataStore        string
+	DataSaveInterval time.Duration
+
+	// AuthIssuer is the OpenID Connect issuer whose RS256 ID or access tokens
+	// a self-hosted server accepts as bearer tokens, with its keys published
+	// at AuthJWKSURL. AuthAudience is the client the tokens must be issued
+	// to, so tokens the issuer grants other clients are refused.
+	AuthIssuer   string
+	AuthAudience string
+	AuthJWKSURL  string
+
+	// MediaStore selects where media is kept: MediaStoreS3 (MediaBucketName),
+	// MediaStoreMinIO (MediaBucketName through S3Endpoint) or MediaStoreLocal
+	// (MediaDir, served by the API itself at MediaPublicURL with URLs signed
+	// by MediaSigningKeys)
+	MediaStore       string
+	MediaDir         string
+	MediaPublicURL   string
+	MediaSigningKeys string
+
+	// JobsQueueURL is the SQS queue holding a self-hosted server's background
+	// jobs; without it they are kept in memory. JobWorkers bounds how many run
+	// at once.
+	JobsQueueURL string
+	JobWorkers   int
+
+	// MetricsEnabled serves Prometheus metrics on /metrics when running as a
+	// plain HTTP server; Lambda deployments report to CloudWatch instead
+	MetricsEnabled bool
+
+	// DrainDelay is how long a plain HTTP server reports not ready on /ready
+	// before it stops accepting connections, so load balancers stop routing to it
+	DrainDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may finish on shutdown
+	ShutdownTimeout time.Duration
+}
+
+// CloudFrontEnabled reports whether signed CloudFront URLs can be generated
+func (c *API) CloudFrontEnabled() bool {
+	return c.CloudFrontDomain != "" && c.CloudFrontKeyPairID != "" && c.CloudFrontPrivateKey != ""
+}
+
+// Processor is the configuration of the upload pipeline Lambdas (cmd/processor/*)
+// and Cognito triggers.
+type Processor struct {
+	Base
+
+	// NixiesearchFunctionName enables search indexing when set
+	NixiesearchFunctionName string
+	// CognitoUserPoolID is used by triggers that manage group membership
+	CognitoUserPoolID string
+	// TranscodeFailureAlerts is how many consecutive failed transcodes of a
+	// track raise an alert
+	TranscodeFailureAlerts int
+	// PipelineStuckAfter is how long an upload may stay in processing before
+	// the watchdog raises an alert
+	PipelineStuckAfter time.Duration
+}
+
+// Transcode is the configuration of the MediaConvert processors.
+type Transcode struct {
+	Processor
+
+	MediaConvertEndpoint string
+	MediaConvertRoleARN  string
+	MediaConvertQueueARN string
+	// MediaConvertBulkQueueARN takes bulk re-transcodes; empty sends them to
+	// MediaConvertQueueARN at a lower job priority
+	MediaConvertBulkQueueARN string
+	// FallbackRetries is how many times a transcode failing on a codec or
+	// container error is retried with the fallback settings (0 disables)
+	FallbackRetries int
+}
+
+// Enabled reports whether enough MediaConvert settings are present to transcode
+func (c *Transcode) Enabled() bool {
+	return c.MediaConvertEndpoint != "" && c.MediaConvertRoleARN != "" && c.MediaBucketName != ""
+}
+
+// Scan is the configuration of the upload malware scanner (cmd/processor/scan).
+type Scan struct {
+	Processor
+
+	// Scanner selects the engine: "clamav" or "http"; empty disables scanning
+	Scanner string
+	// ClamScanPath is the clamscan binary, provided by the ClamAV layer
+	ClamScanPath string
+	// ClamDatabaseDir holds the signature database; empty uses clamscan's default
+	ClamDatabaseDir string
+	// ScannerURL is the external scanning service used by the "http" engine
+	ScannerURL string
+}
+
+// Enabled reports whether uploads are scanned
+func (c *Scan) Enabled() bool {
+	return c.Scanner != ""
+}
+
+// Scan engines
+const (
+	ScannerClamAV = "clamav"
+	ScannerHTTP   = "http"
+)
+
+// Media stores
+const (
+	MediaStoreS3    = "s3"
+	MediaStoreMinIO = "minio"
+	MediaStoreLocal = "local"
+)
+
+// Data stores
+const (
+	DataStoreDynamoDB = "dynamodb"
+	DataStoreLocal    = "local"
+)
+
+// Moderation is the configuration of the moderation Lambda (cmd/processor/moderation).
+type Moderation struct {
+	Processor
+
+	// ModerationModel is the Bedrock model (or gateway alias) that classifies tracks
+	ModerationModel string
+	// TranscribeURL is an OpenAI-compatible speech-to-text endpoint; empty
+	// classifies metadata and lyrics only
+	TranscribeURL   string
+	TranscribeModel string
+	// SnippetBytes is how much of the start of the audio file is transcribed
+	SnippetBytes int
+}
+
+// Watermark is the configuration of the download watermark Lambda
+// (cmd/processor/watermark).
+type Watermark struct {
+	Processor
+
+	// FFmpegPath is the ffmpeg binary, provided by the FFmpeg layer
+	FFmpegPath string
+}
+
+// Gateway is the configuration of the Bedrock gateway (cmd/gateway).
+type Gateway struct {
+	AWSRegion string
+	// APIKey enables API key authentication when set
+	APIKey string
+	// SigningKeys ("id:secret,...", parsed by reqsign.ParseKeyring) lets
+	// internal services authenticate with signed requests
+	SigningKeys string
+	ServerPort  string
+}
+
+// Nixiesearch is the configuration of the search Lambda (cmd/nixiesearch).
+type Nixiesearch struct {
+	IndexBucket string
+	IndexPath   string
+	// AlertTopicARN receives an alert when the stored index cannot be decoded
+	AlertTopicARN string
+}
+
+// localCORSOrigins are the frontend dev servers (Vite, then Create React App)
+// allowed to call an API running outside Lambda when no origins are configured
+const localCORSOrigins = "http://localhost:5173,http://localhost:3000"
+
+// LoadAPI loads and validates the API configuration. Secrets referenced by
+// name are resolved through secrets; pass nil to only read literal values.
+func LoadAPI(ctx context.Context, secrets SecretLoader) (*API, error) {
+	// Local servers default to the frontend dev servers; deployed APIs allow
+	// no cross-origin callers unless CORS_ALLOW_ORIGINS lists them
+	corsOrigins, corsCredentials := "", false
+	if !IsLambda() {
+		corsOrigins, corsCredentials = localCORSOrigins, true
+	}
+
+	breakerThreshold := GetEnvInt("BREAKER_THRESHOLD", 5)
+	breakerCooldown := GetEnvDuration("BREAKER_COOLDOWN", 30*time.Second)
+
+	cfg := &API{
+		Base:                    loadBase(),
+		StepFunctionsARN:        os.Getenv("STEP_FUNCTIONS_ARN"),
+		NixiesearchFunctionName: os.Getenv("NIXIESEARCH_FUNCTION_NAME"),
+		SearchTimeout:           GetEnvDuration("SEARCH_TIMEOUT", 5*time.Second),
+		SearchBreakerThreshold:  GetEnvInt("SEARCH_BREAKER_THRESHOLD", breakerThreshold),
+		SearchBreakerCooldown:   GetEnvDuration("SEARCH_BREAKER_COOLDOWN", breakerCooldown),
+		RetryMaxAttempts:        GetEnvInt("RETRY_MAX_ATTEMPTS", 3),
+		RetryBaseDelay:          GetEnvDuration("RETRY_BASE_DELAY", 50*time.Millisecond),
+		RetryMaxDelay:           GetEnvDuration("RETRY_MAX_DELAY", time.Second),
+		BreakerThreshold:        breakerThreshold,
+		BreakerCooldown:         breakerCooldown,
+		ScanConcurrency:         GetEnvInt("CONCURRENCY_LIMIT_SCAN", 2),
+		BulkConcurrency:         GetEnvInt("CONCURRENCY_LIMIT_BULK", 2),
+		ReindexConcurrency:      GetEnvInt("CONCURRENCY_LIMIT_REINDEX", 1),
+		ConcurrencyWait:         GetEnvDuration("CONCURRENCY_WAIT", 2*time.Second),
+		CognitoUserPoolID:       os.Getenv("COGNITO_USER_POOL_ID"),
+		ModerationFunctionName:  os.Getenv("MODERATION_FUNCTION_NAME"),
+		WatermarkFunctionName:   os.Getenv("WATERMARK_FUNCTION_NAME"),
+		PipelineLogGroups:       os.Getenv("PIPELINE_LOG_GROUPS"),
+		CloudFrontDomain:        os.Getenv("CLOUDFRONT_DOMAIN"),
+		CloudFrontKeyPairID:     os.Getenv("CLOUDFRONT_KEY_PAIR_ID"),
+		TenantClaim:             os.Getenv("TENANT_CLAIM"),
+		TenantDomains:           os.Getenv("TENANT_DOMAINS"),
+		CORSAllowOrigins:        GetEnvOrDefault("CORS_ALLOW_ORIGINS", corsOrigins),
+		CORSAllowCredentials:    GetEnvBool("CORS_ALLOW_CREDENTIALS", corsCredentials),
+		CORSRouteMethods:        os.Getenv("CORS_ROUTE_METHODS"),
+		CORSMaxAge:              GetEnvDuration("CORS_MAX_AGE", time.Hour),
+		HSTSMaxAge:              GetEnvDuration("HSTS_MAX_AGE", 365*24*time.Hour),
+		FrameAncestors:          os.Getenv("FRAME_ANCESTORS"),
+		SessionCookieName:       os.Getenv("SESSION_COOKIE_NAME"),
+		CSRFCookieSecure:        GetEnvBool("CSRF_COOKIE_SECURE", IsLambda()),
+		TrustedProxies:          os.Getenv("TRUSTED_PROXIES"),
+		ServerPort:              GetEnvOrDefault("PORT", "8080"),
+		UserCacheTTL:            GetEnvDuration("USER_CACHE_TTL", 30*time.Second),
+		DemoMode:                GetEnvBool("DEMO_MODE", false),
+		SelfHosted:              GetEnvBool("SELF_HOSTED", false),
+		SearchIndexBucket:       os.Getenv("SEARCH_INDEX_BUCKET"),
+		MediaStore:              GetEnvOrDefault("MEDIA_STORE", MediaStoreS3),
+		MediaDir:                os.Getenv("MEDIA_DIR"),
+		DataStore:               GetEnvOrDefault("DATA_STORE", DataStoreDynamoDB),
+		DataSaveInterval:        GetEnvDuration("DATA_SAVE_INTERVAL", 5*time.Second),
+		AuthIssuer:              strings.TrimSuffix(os.Getenv("AUTH_ISSUER"), "/"),
+		AuthAudience:            os.Getenv("AUTH_AUDIENCE"),
+		JobsQueueURL:            os.Getenv("JOBS_QUEUE_URL"),
+		JobWorkers:              GetEnvInt("JOB_WORKERS", 2),
+		MetricsEnabled:          GetEnvBool("METRICS_ENABLED", true),
+		DrainDelay:              GetEnvDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+		ShutdownTimeout:         GetEnvDuration("SHUTDOWN_TIMEOUT", 25*time.Second),
+	}
+
+	privateKey, err := resolveSecret(ctx, secrets, SecretRef{
+		Env:       "CLOUDFRONT_PRIVATE_KEY",
+		SecretEnv: "CLOUDFRONT_SIGNING_KEY_SECRET",
+		ParamEnv:  "CLOUDFRONT_PRIVATE_KEY_PARAM",
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.CloudFrontPrivateKey = privateKey
+
+	previewKeys, err := resolveSecret(ctx, secrets, SecretRef{
+		Env:       "PREVIEW_SIGNING_KEYS",
+		SecretEnv: "PREVIEW_SIGNING_KEYS_SECRET",
+		ParamEnv:  "PREVIEW_SIGNING_KEYS_PARAM",
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.PreviewSigningKeys = previewKeys
+
+	mediaKeys, err := resolveSecret(ctx, secrets, SecretRef{
+		Env:       "MEDIA_SIGNING_KEYS",
+		SecretEnv: "MEDIA_SIGNING_KEYS_SECRET",
+		ParamEnv:  "MEDIA_SIGNING_KEYS_PARAM",
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.MediaSigningKeys = mediaKeys
+	cfg.MediaPublicURL = GetEnvOrDefault("MEDIA_PUBLIC_URL", "http://localhost:"+cfg.ServerPort+"/media")
+	// Cognito and most OIDC providers publish their keys here
+	cfg.AuthJWKSURL = os.Getenv("AUTH_JWKS_URL")
+	if cfg.AuthJWKSURL == "" && cfg.AuthIssuer != "" {
+		cfg.AuthJWKSURL = cfg.AuthIssuer + "/.well-known/jwks.json"
+	}
+
+	// Demo mode keeps everything in memory, so no table or bucket is needed
+	if cfg.DemoMode {
+		return cfg, nil
+	}
+
+	required := map[string]string{}
+	switch cfg.DataStore {
+	case DataStoreDynamoDB:
+		required["DYNAMODB_TABLE_NAME"] = cfg.DynamoDBTableName
+	case DataStoreLocal:
+		// The snapshot is one server's file beside its local media
+		if !cfg.SelfHosted || cfg.MediaStore != MediaStoreLocal {
+			return nil, errors.New("config: DATA_STORE=local needs SELF_HOSTED and MEDIA_STORE=local")
+		}
+		if cfg.DataSaveInterval <= 0 {
+			return nil, fmt.Errorf("config: DATA_SAVE_INTERVAL must be positive, got %s", cfg.DataSaveInterval)
+		}
+	default:
+		return nil, fmt.Errorf("config: unknown DATA_STORE %q (want %s or %s)", cfg.DataStore, DataStoreDynamoDB, DataStoreLocal)
+	}
+	switch cfg.MediaStore {
+	case MediaStoreS3:
+		required["MEDIA_BUCKET"] = cfg.MediaBucketName
+	case MediaStoreMinIO:
+		required["MEDIA_BUCKET"] = cfg.MediaBucketName
+		required["S3_ENDPOINT"] = cfg.S3Endpoint
+	case MediaStoreLocal:
+		// Files on one server's disk are neither shared between Lambda
+		// instances nor split by tenant
+		if IsLambda() || cfg.MultiTenantMode {
+			return nil, errors.New("config: MEDIA_STORE=local is for single-tenant plain HTTP servers")
+		}
+		required["MEDIA_DIR"] = cfg.MediaDir
+		required["MEDIA_SIGNING_KEYS"] = cfg.MediaSigningKeys
+	default:
+		return nil, fmt.Errorf("config: unknown MEDIA_STORE %q (want %s, %s or %s)", cfg.MediaStore, MediaStoreS3, MediaStoreMinIO, MediaStoreLocal)
+	}
+	if cfg.SelfHosted {
+		// The search engine runs inside the API, which Lambda would start
+		// once per instance with an index of its own
+		if IsLambda() {
+			return nil, errors.New("config: SELF_HOSTED is for plain HTTP servers, not Lambda")
+		}
+		// A local media store keeps the index on disk next to the media
+		if cfg.MediaStore != MediaStoreLocal {
+			required["SEARCH_INDEX_BUCKET"] = cfg.SearchIndexBucket
+		}
+		if cfg.JobWorkers < 1 {
+			return nil, fmt.Errorf("config: JOB_WORKERS must be at least 1, got %d", cfg.JobWorkers)
+		}
+		// With no API Gateway authorizer in front, the server verifies
+		// bearer tokens itself and never trusts the X-User-ID header. Without
+		// an audience, a token of any client of the issuer would do.
+		required["AUTH_ISSUER"] = cfg.AuthIssuer
+		required["AUTH_AUDIENCE"] = cfg.AuthAudience
+	}
+	if err := requireAll(required); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadProcessor loads and validates the configuration shared by pipeline Lambdas
+func LoadProcessor() (*Processor, error) {
+	cfg := &Processor{
+		Base:                    loadBase(),
+		NixiesearchFunctionName: os.Getenv("NIXIESEARCH_FUNCTION_NAME"),
+		CognitoUserPoolID:       os.Getenv("COGNITO_USER_POOL_ID"),
+		TranscodeFailureAlerts:  GetEnvInt("ALERT_TRANSCODE_FAILURES", 3),
+		PipelineStuckAfter:      GetEnvDuration("ALERT_PIPELINE_STUCK_AFTER", 30*time.Minute),
+	}
+
+	if err := requireAll(map[string]string{
+		"DYNAMODB_TABLE_NAME": cfg.DynamoDBTableName,
+	}); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadTranscode loads the MediaConvert processor configuration. Missing
+// MediaConvert settings are not an error; check Enabled instead.
+func LoadTranscode() (*Transcode, error) {
+	processor, err := LoadProcessor()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transcode{
+		Processor:                *processor,
+		MediaConvertEndpoint:     os.Getenv("MEDIACONVERT_ENDPOINT"),
+		MediaConvertRoleARN:      os.Getenv("MEDIACONVERT_ROLE_ARN"),
+		MediaConvertQueueARN:     os.Getenv("MEDIACONVERT_QUEUE_ARN"),
+		MediaConvertBulkQueueARN: os.Getenv("MEDIACONVERT_BULK_QUEUE_ARN"),
+		FallbackRetries:          GetEnvInt("TRANSCODE_FALLBACK_RETRIES", 1),
+	}, nil
+}
+
+// LoadScan loads the malware scanner configuration. An empty SCANNER disables
+// scanning; an unknown engine, or "http" without SCANNER_URL, is an error.
+func LoadScan() (*Scan, error) {
+	processor, err := LoadProcessor()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Scan{
+		Processor:       *processor,
+		Scanner:         strings.ToLower(strings.TrimSpace(os.Getenv("SCANNER"))),
+		ClamScanPath:    GetEnvOrDefault("CLAMSCAN_PATH", "/opt/bin/clamscan"),
+		ClamDatabaseDir: os.Getenv("CLAMAV_DATABASE_DIR"),
+		ScannerURL:      os.Getenv("SCANNER_URL"),
+	}
+
+	switch cfg.Scanner {
+	case "", ScannerClamAV:
+	case ScannerHTTP:
+		if err := requireAll(map[string]string{"SCANNER_URL": cfg.ScannerURL}); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown SCANNER %q: expected %s or %s", cfg.Scanner, ScannerClamAV, ScannerHTTP)
+	}
+
+	return cfg, nil
+}
+
+// LoadModeration loads the moderation Lambda configuration
+func LoadModeration() (*Moderation, error) {
+	processor, err := LoadProcessor()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Moderation{
+		Processor:       *processor,
+		ModerationModel: GetEnvOrDefault("MODERATION_MODEL", "claude-3-haiku"),
+		TranscribeURL:   os.Getenv("TRANSCRIBE_URL"),
+		TranscribeModel: GetEnvOrDefault("TRANSCRIBE_MODEL", "whisper-1"),
+		SnippetBytes:    GetEnvInt("MODERATION_SNIPPET_BYTES", 1<<20),
+	}, nil
+}
+
+// LoadWatermark loads the watermark Lambda configuration. The copies it
+// produces are read from and written to the media bucket, so MEDIA_BUCKET is
+// required.
+func LoadWatermark() (*Watermark, error) {
+	processor, err := LoadProcessor()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireAll(map[string]string{"MEDIA_BUCKET": processor.MediaBucketName}); err != nil {
+		return nil, err
+	}
+
+	return &Watermark{
+		Processor:  *processor,
+		FFmpegPath: GetEnvOrDefault("FFMPEG_PATH", "/opt/bin/ffmpeg"),
+	}, nil
+}
+
+// LoadGateway loads the Bedrock gateway configuration
+func LoadGateway(ctx context.Context, secrets SecretLoader) (*Gateway, error) {
+	apiKey, err := resolveSecret(ctx, secrets, SecretRef{
+		Env:       "API_KEY",
+		SecretEnv: "API_KEY_SECRET",
+		ParamEnv:  "API_KEY_PARAM",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signingKeys, err := resolveSecret(ctx, secrets, SecretRef{
+		Env:       "SIGNING_KEYS",
+		SecretEnv: "SIGNING_KEYS_SECRET",
+		ParamEnv:  "SIGNING_KEYS_PARAM",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Gateway{
+		AWSRegion:   GetEnvOrDefault("AWS_REGION", "us-east-1"),
+		APIKey:      apiKey,
+		SigningKeys: signingKeys,
+		ServerPort:  GetEnvOrDefault("PORT", "8081"),
+	}, nil
+}
+
+// LoadNixiesearch loads the search Lambda configuration
+func LoadNixiesearch() *Nixiesearch {
+	return &Nixiesearch{
+		IndexBucket:   os.Getenv("SEARCH_INDEX_BUCKET"),
+		IndexPath:     GetEnvOrDefault("INDEX_PATH", "/tmp/nixiesearch"),
+		AlertTopicARN: os.Getenv("ALERT_TOPIC_ARN"),
+	}
+}
+
+// IsLambda returns true if running in AWS Lambda environment
+func IsLambda() bool {
+	return os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" || os.Getenv("LAMBDA_TASK_ROOT") != ""
+}
+
+// GetEnvOrDefault returns the environment variable value or a default
+func GetEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// GetEnvBool parses a boolean environment variable ("true", "1", ...), falling back to defaultValue
+func GetEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvInt parses an integer environment variable, falling back to defaultValue
+func GetEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvDuration parses a duration environment variable ("30s", "5m"), falling back to defaultValue
+func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func loadBase() Base {
+	awsEndpoint := os.Getenv("AWS_ENDPOINT")
+	return Base{
+		AWSRegion:         GetEnvOrDefault("AWS_REGION", "us-east-1"),
+		AWSEndpoint:       awsEndpoint,
+		DynamoDBEndpoint:  GetEnvOrDefault("DYNAMODB_ENDPOINT", awsEndpoint),
+		S3Endpoint:        GetEnvOrDefault("S3_ENDPOINT", awsEndpoint),
+		DynamoDBTableName: os.Getenv("DYNAMODB_TABLE_NAME"),
+		MediaBucketName:   os.Getenv("MEDIA_BUCKET"),
+		MultiTenantMode:   GetEnvBool("MULTI_TENANT_MODE", false),
+		AlertTopicARN:     os.Getenv("ALERT_TOPIC_ARN"),
+	}
+}
+
+// requireAll returns an error naming every unset required variable
+func requireAll(values map[string]string) error {
+	var missing []string
+	for name, value := range values {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("%w: %s", ErrMissingRequired, strings.Join(missing, ", "))
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrSecretsUnavailable is returned when a secret is referenced but no loader is configured
+var ErrSecretsUnavailable = errors.New("config: secret referenced but no secret loader configured")
+
+// SecretLoader resolves secrets from Secrets Manager and SSM Parameter Store.
+type SecretLoader interface {
+	// GetSecret returns the SecretString of a Secrets Manager secret (name or ARN)
+	GetSecret(ctx context.Context, secretID string) (string, error)
+	// GetParameter returns the decrypted value of an SSM parameter
+	GetParameter(ctx context.Context, name string) (string, error)
+}
+
+// SecretRef names the environment variables that may supply one secret. A
+// literal value in Env wins; otherwise SecretEnv names a Secrets Manager secret
+// and ParamEnv an SSM parameter.
+type SecretRef struct {
+	Env       string
+	SecretEnv string
+	ParamEnv  string
+}
+
+// resolveSecret returns the secret's value, or "" when none of its variables are set
+func resolveSecret(ctx context.Context, loader SecretLoader, ref SecretRef) (string, error) {
+	if value := os.Getenv(ref.Env); value != "" {
+		return value, nil
+	}
+
+	if ref.SecretEnv != "" {
+		if secretID := os.Getenv(ref.SecretEnv); secretID != "" {
+			if loader == nil {
+				return "", fmt.Errorf("%w: %s", ErrSecretsUnavailable, ref.SecretEnv)
+			}
+			value, err := loader.GetSecret(ctx, secretID)
+			if err != nil {
+				return "", fmt.Errorf("config: failed to load %s from Secrets Manager: %w", ref.SecretEnv, err)
+			}
+			return value, nil
+		}
+	}
+
+	if ref.ParamEnv != "" {
+		if name := os.Getenv(ref.ParamEnv); name != "" {
+			if loader == nil {
+				return "", fmt.Errorf("%w: %s", ErrSecretsUnavailable, ref.ParamEnv)
+			}
+			value, err := loader.GetParameter(ctx, name)
+			if err != nil {
+				return "", fmt.Errorf("config: failed to load %s from SSM: %w", ref.ParamEnv, err)
+			}
+			return value, nil
+		}
+	}
+
+	return "", nil
+}
+
+// defaultExtensionPort is the AWS Parameters and Secrets Lambda Extension's default port
+const defaultExtensionPort = "2773"
+
+// ExtensionSecretLoader reads secrets through the AWS Parameters and Secrets
+// Lambda Extension, which exposes Secrets Manager and SSM on localhost and
+// caches values inside the execution environment. This avoids pulling the
+// Secrets Manager and SSM SDKs into every binary.
+type ExtensionSecretLoader struct {
+	client   *http.Client
+	endpoint string
+	token    string
+}
+
+// NewExtensionSecretLoader creates a loader for the extension running in this Lambda
+func NewExtensionSecretLoader() *ExtensionSecretLoader {
+	port := GetEnvOrDefault("PARAMETERS_SECRETS_EXTENSION_HTTP_PORT", defaultExtensionPort)
+	return &ExtensionSecretLoader{
+		client:   &http.Client{Timeout: 5 * time.Second},
+		endpoint: "http://localhost:" + port,
+		token:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// GetSecret returns the SecretString of a Secrets Manager secret
+func (l *ExtensionSecretLoader) GetSecret(ctx context.Context, secretID string) (string, error) {
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	path := "/secretsmanager/get?secretId=" + url.QueryEscape(secretID)
+	if err := l.get(ctx, path, &out); err != nil {
+		return "", err
+	}
+	return out.SecretString, nil
+}
+
+// GetParameter returns the decrypted value of an SSM parameter
+func (l *ExtensionSecretLoader) GetParameter(ctx context.Context, name string) (string, error) {
+	var out struct {
+		Parameter struct {
+			Value string `json:"Value"`
+		} `json:"Parameter"`
+	}
+	path := "/systemsmanager/parameters/get?withDecryption=true&name=" + url.QueryEscape(name)
+	if err := l.get(ctx, path, &out); err != nil {
+		return "", err
+	}
+	return out.Parameter.Value, nil
+}
+
+func (l *ExtensionSecretLoader) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.endpoint+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Aws-Parameters-Secrets-Token", l.token)
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("secrets extension request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("secrets extension returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode secrets extension response: %w", err)
+	}
+	return nil
+}
+
+// CachingSecretLoader memoizes another loader's values for a TTL so repeated
+// lookups (e.g. on every warm invocation) do not hit the backing store.
+type CachingSecretLoader struct {
+	inner SecretLoader
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewCachingSecretLoader wraps inner with a TTL cache
+func NewCachingSecretLoader(inner SecretLoader, ttl time.Duration) *CachingSecretLoader {
+	return &CachingSecretLoader{
+		inner:   inner,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cachedSecret),
+	}
+}
+
+// GetSecret returns a cached Secrets Manager value, loading it on a miss
+func (l *CachingSecretLoader) GetSecret(ctx context.Context, secretID string) (string, error) {
+	return l.cached("secret:"+secretID, func() (string, error) {
+		return l.inner.GetSecret(ctx, secretID)
+	})
+}
+
+// GetParameter returns a cached SSM value, loading it on a miss
+func (l *CachingSecretLoader) GetParameter(ctx context.Context, name string) (string, error) {
+	return l.cached("param:"+name, func() (string, error) {
+		return l.inner.GetParameter(ctx, name)
+	})
+}
+
+func (l *CachingSecretLoader) cached(key string, load func() (string, error)) (string, error) {
+	l.mu.Lock()
+	entry, ok := l.entries[key]
+	l.mu.Unlock()
+	if ok && l.now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return "", err
+	}
+
+	l.mu.Lock()
+	l.entries[key] = cachedSecret{value: value, expiresAt: l.now().Add(l.ttl)}
+	l.mu.Unlock()
+
+	return value, nil
+}
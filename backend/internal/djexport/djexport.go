@@ -0,0 +1,270 @@
+// Package djexport writes track analysis (BPM, beat grid anchor, key and hot
+// cues) in formats DJ software can import: a Rekordbox XML library and the
+// Serato beat grid and marker tags.
+package djexport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// Playlist is an ordered set of exported tracks
+type Playlist struct {
+	Name   string
+	Tracks []models.Track
+}
+
+// Options controls how exported tracks are located on the DJ's machine
+type Options struct {
+	// Root is the local folder downloaded tracks were saved in. Track
+	// locations are Root joined with the track's download file name.
+	Root string
+}
+
+// Product names the exporting application in Rekordbox XML
+const (
+	productName    = "personal-music-searchengine"
+	productVersion = "1.0"
+)
+
+type rekordboxLibrary struct {
+	XMLName    xml.Name            `xml:"DJ_PLAYLISTS"`
+	Version    string              `xml:"Version,attr"`
+	Product    rekordboxProduct    `xml:"PRODUCT"`
+	Collection rekordboxCollection `xml:"COLLECTION"`
+	Playlists  *rekordboxPlaylists `xml:"PLAYLISTS,omitempty"`
+}
+
+type rekordboxProduct struct {
+	Name    string `xml:"Name,attr"`
+	Version string `xml:"Version,attr"`
+	Company string `xml:"Company,attr"`
+}
+
+type rekordboxCollection struct {
+	Entries int              `xml:"Entries,attr"`
+	Tracks  []rekordboxTrack `xml:"TRACK"`
+}
+
+type rekordboxTrack struct {
+	TrackID     int               `xml:"TrackID,attr"`
+	Name        string            `xml:"Name,attr"`
+	Artist      string            `xml:"Artist,attr"`
+	Composer    string            `xml:"Composer,attr,omitempty"`
+	Album       string            `xml:"Album,attr,omitempty"`
+	Genre       string            `xml:"Genre,attr,omitempty"`
+	Kind        string            `xml:"Kind,attr"`
+	Size        int64             `xml:"Size,attr,omitempty"`
+	TotalTime   int               `xml:"TotalTime,attr"`
+	DiscNumber  int               `xml:"DiscNumber,attr,omitempty"`
+	TrackNumber int               `xml:"TrackNumber,attr,omitempty"`
+	Year        int               `xml:"Year,attr,omitempty"`
+	AverageBpm  string            `xml:"AverageBpm,attr,omitempty"`
+	BitRate     int               `xml:"BitRate,attr,omitempty"`
+	SampleRate  int               `xml:"SampleRate,attr,omitempty"`
+	Comments    string            `xml:"Comments,attr,omitempty"`
+	PlayCount   int               `xml:"PlayCount,attr"`
+	Rating      int               `xml:"Rating,attr"` // 51 per star
+	Tonality    string            `xml:"Tonality,attr,omitempty"`
+	Location    string            `xml:"Location,attr"`
+	Tempos      []rekordboxTempo  `xml:"TEMPO"`
+	Marks       []rekordboxMarker `xml:"POSITION_MARK"`
+}
+
+// rekordboxTempo anchors the beat grid: Inizio is the first downbeat in
+// seconds and Battito the beat of the bar it falls on
+type rekordboxTempo struct {
+	Inizio  string `xml:"Inizio,attr"`
+	Bpm     string `xml:"Bpm,attr"`
+	Metro   string `xml:"Metro,attr"`
+	Battito int    `xml:"Battito,attr"`
+}
+
+// rekordboxMarker is a hot cue; Num 0-7 are pads A-H
+type rekordboxMarker struct {
+	Name  string `xml:"Name,attr"`
+	Type  int    `xml:"Type,attr"`
+	Start string `xml:"Start,attr"`
+	Num   int    `xml:"Num,attr"`
+	Red   int    `xml:"Red,attr"`
+	Green int    `xml:"Green,attr"`
+	Blue  int    `xml:"Blue,attr"`
+}
+
+type rekordboxPlaylists struct {
+	Root rekordboxFolder `xml:"NODE"`
+}
+
+type rekordboxFolder struct {
+	Type      int                 `xml:"Type,attr"`
+	Name      string              `xml:"Name,attr"`
+	Count     int                 `xml:"Count,attr"`
+	Playlists []rekordboxPlaylist `xml:"NODE"`
+}
+
+type rekordboxPlaylist struct {
+	Type    int                 `xml:"Type,attr"`
+	Name    string              `xml:"Name,attr"`
+	KeyType int                 `xml:"KeyType,attr"`
+	Entries int                 `xml:"Entries,attr"`
+	Tracks  []rekordboxTrackKey `xml:"TRACK"`
+}
+
+type rekordboxTrackKey struct {
+	Key int `xml:"Key,attr"`
+}
+
+// WriteRekordbox writes a Rekordbox XML library of the tracks. When playlist
+// is set its tracks make up the collection and are listed, in order, as a
+// playlist under the root folder.
+func WriteRekordbox(w io.Writer, tracks []models.Track, playlist *Playlist, opts Options) error {
+	if playlist != nil {
+		tracks = playlist.Tracks
+	}
+
+	library := rekordboxLibrary{
+		Version: "1.0.0",
+		Product: rekordboxProduct{Name: productName, Version: productVersion},
+	}
+
+	ids := make(map[string]int, len(tracks))
+	for _, track := range tracks {
+		if _, ok := ids[track.ID]; ok {
+			continue
+		}
+		ids[track.ID] = len(ids) + 1
+		library.Collection.Tracks = append(library.Collection.Tracks, rekordboxTrackOf(track, ids[track.ID], opts))
+	}
+	library.Collection.Entries = len(library.Collection.Tracks)
+
+	if playlist != nil {
+		list := rekordboxPlaylist{Type: 1, Name: playlist.Name, Entries: len(playlist.Tracks)}
+		for _, track := range playlist.Tracks {
+			list.Tracks = append(list.Tracks, rekordboxTrackKey{Key: ids[track.ID]})
+		}
+		library.Playlists = &rekordboxPlaylists{Root: rekordboxFolder{Name: "ROOT", Count: 1, Playlists: []rekordboxPlaylist{list}}}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(library); err != nil {
+		return fmt.Errorf("failed to encode rekordbox library: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func rekordboxTrackOf(track models.Track, id int, opts Options) rekordboxTrack {
+	rt := rekordboxTrack{
+		TrackID:     id,
+		Name:        track.Title,
+		Artist:      track.Artist,
+		Composer:    track.Composer,
+		Album:       track.Album,
+		Genre:       track.Genre,
+		Kind:        kind(track.Format),
+		Size:        track.FileSize,
+		TotalTime:   track.Duration,
+		DiscNumber:  track.DiscNumber,
+		TrackNumber: track.TrackNumber,
+		Year:        track.Year,
+		BitRate:     track.Bitrate,
+		SampleRate:  track.SampleRate,
+		Comments:    track.Comment,
+		PlayCount:   track.PlayCount,
+		Rating:      track.Rating * 51,
+		Tonality:    track.MusicalKey,
+		Location:    location(track, opts.Root),
+	}
+
+	if track.BPM > 0 {
+		rt.AverageBpm = fmt.Sprintf("%.2f", float64(track.BPM))
+		rt.Tempos = []rekordboxTempo{{
+			Inizio:  fmt.Sprintf("%.3f", GridAnchor(track)),
+			Bpm:     fmt.Sprintf("%.2f", float64(track.BPM)),
+			Metro:   "4/4",
+			Battito: 1,
+		}}
+	}
+
+	for _, cue := range HotCues(track) {
+		r, g, b := rgb(cue.Color)
+		rt.Marks = append(rt.Marks, rekordboxMarker{
+			Name:  cue.Label,
+			Start: fmt.Sprintf("%.3f", cue.Position),
+			Num:   cue.Slot - 1,
+			Red:   r,
+			Green: g,
+			Blue:  b,
+		})
+	}
+	return rt
+}
+
+// GridAnchor returns the position in seconds of the track's first downbeat:
+// the first beat of the analyzed beat grid, or the start of the track when
+// it has none
+func GridAnchor(track models.Track) float64 {
+	if len(track.BeatGrid) == 0 {
+		return 0
+	}
+	return float64(track.BeatGrid[0]) / 1000
+}
+
+// HotCues returns the track's hot cues ordered by slot
+func HotCues(track models.Track) []models.HotCue {
+	cues := make([]models.HotCue, 0, len(track.HotCues))
+	for slot, cue := range track.HotCues {
+		if cue == nil || !models.IsValidSlot(slot) {
+			continue
+		}
+		c := *cue
+		c.Slot = slot
+		cues = append(cues, c)
+	}
+	sort.Slice(cues, func(i, j int) bool { return cues[i].Slot < cues[j].Slot })
+	return cues
+}
+
+// location returns the file URL Rekordbox looks for the track at
+func location(track models.Track, root string) string {
+	p := path.Join("/", strings.ReplaceAll(root, `\`, "/"), track.DownloadFileName())
+	return "file://localhost" + (&url.URL{Path: p}).EscapedPath()
+}
+
+// kind returns Rekordbox's description of a file format
+func kind(format models.AudioFormat) string {
+	switch format {
+	case models.AudioFormatMP3:
+		return "MP3 File"
+	case models.AudioFormatFLAC:
+		return "FLAC File"
+	case models.AudioFormatWAV:
+		return "WAV File"
+	case models.AudioFormatAAC:
+		return "M4A File"
+	case models.AudioFormatOGG:
+		return "OGG File"
+	default:
+		return strings.ToUpper(strings.TrimPrefix(format.Extension(), ".")) + " File"
+	}
+}
+
+// rgb parses a "#RRGGBB" hot cue color, falling back to red
+func rgb(color models.HotCueColor) (int, int, int) {
+	var r, g, b int
+	if _, err := fmt.Sscanf(string(color), "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return 0xFF, 0, 0
+	}
+	return r, g, b
+}
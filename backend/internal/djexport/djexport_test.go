@@ -0,0 +1,142 @@
+package djexport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+func analyzedTrack() models.Track {
+	return models.Track{
+		ID:         "track-1",
+		Title:      "Strobe",
+		Artist:     "deadmau5",
+		Album:      "For Lack of a Better Name",
+		Format:     models.AudioFormatMP3,
+		Duration:   634,
+		BPM:        128,
+		MusicalKey: "Bbm",
+		BeatGrid:   []int64{352, 821, 1290},
+		HotCues: map[int]*models.HotCue{
+			3: {Position: 96.5, Label: "Drop", Color: models.HotCueColorBlue},
+			1: {Position: 0.352, Color: models.HotCueColorGreen},
+		},
+	}
+}
+
+func TestWriteRekordbox_Track(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteRekordbox(&buf, []models.Track{analyzedTrack()}, nil, Options{Root: "Music/Exports"}))
+
+	var library rekordboxLibrary
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &library))
+	assert.Equal(t, 1, library.Collection.Entries)
+	assert.Nil(t, library.Playlists)
+
+	track := library.Collection.Tracks[0]
+	assert.Equal(t, "Strobe", track.Name)
+	assert.Equal(t, "MP3 File", track.Kind)
+	assert.Equal(t, "128.00", track.AverageBpm)
+	assert.Equal(t, "Bbm", track.Tonality)
+	assert.Equal(t, "file://localhost/Music/Exports/deadmau5%20-%20Strobe.mp3", track.Location)
+
+	require.Len(t, track.Tempos, 1)
+	assert.Equal(t, "0.352", track.Tempos[0].Inizio)
+	assert.Equal(t, "4/4", track.Tempos[0].Metro)
+
+	require.Len(t, track.Marks, 2)
+	assert.Equal(t, 0, track.Marks[0].Num)
+	assert.Equal(t, 2, track.Marks[1].Num)
+	assert.Equal(t, "Drop", track.Marks[1].Name)
+	assert.Equal(t, "96.500", track.Marks[1].Start)
+	assert.Equal(t, [3]int{0, 0, 255}, [3]int{track.Marks[1].Red, track.Marks[1].Green, track.Marks[1].Blue})
+}
+
+func TestWriteRekordbox_Playlist(t *testing.T) {
+	first := analyzedTrack()
+	second := models.Track{ID: "track-2", Title: "Untitled", Artist: "Unknown", Format: models.AudioFormatFLAC}
+	playlist := &Playlist{Name: "Warmup", Tracks: []models.Track{first, second, first}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteRekordbox(&buf, nil, playlist, Options{}))
+	assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var library rekordboxLibrary
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &library))
+	assert.Equal(t, 2, library.Collection.Entries, "repeated tracks appear once in the collection")
+
+	unanalyzed := library.Collection.Tracks[1]
+	assert.Equal(t, "FLAC File", unanalyzed.Kind)
+	assert.Empty(t, unanalyzed.Tempos)
+	assert.Equal(t, "file://localhost/Unknown%20-%20Untitled.flac", unanalyzed.Location)
+
+	require.NotNil(t, library.Playlists)
+	list := library.Playlists.Root.Playlists[0]
+	assert.Equal(t, "Warmup", list.Name)
+	assert.Equal(t, 3, list.Entries)
+	assert.Equal(t, []rekordboxTrackKey{{Key: 1}, {Key: 2}, {Key: 1}}, list.Tracks)
+}
+
+func TestSeratoBeatGrid(t *testing.T) {
+	data := SeratoBeatGrid(0.352, 128)
+	require.Len(t, data, 15)
+	assert.Equal(t, []byte{0x01, 0x00}, data[:2])
+	assert.Equal(t, uint32(1), binary.BigEndian.Uint32(data[2:6]))
+	assert.InDelta(t, 0.352, math.Float32frombits(binary.BigEndian.Uint32(data[6:10])), 1e-6)
+	assert.Equal(t, float32(128), math.Float32frombits(binary.BigEndian.Uint32(data[10:14])))
+}
+
+func TestSeratoMarkers(t *testing.T) {
+	track := analyzedTrack()
+	track.BPMOverridden = true
+	data := SeratoMarkers(track)
+
+	assert.Equal(t, []byte{0x01, 0x01}, data[:2])
+	assert.Equal(t, byte(0x00), data[len(data)-1])
+	entries, err := base64.StdEncoding.DecodeString(string(data[2 : len(data)-1]))
+	require.NoError(t, err)
+
+	var kinds []string
+	var cues [][]byte
+	var lock []byte
+	for len(entries) > 1 {
+		end := bytes.IndexByte(entries, 0x00)
+		kind := string(entries[:end])
+		size := binary.BigEndian.Uint32(entries[end+1 : end+5])
+		body := entries[end+5 : end+5+int(size)]
+		entries = entries[end+5+int(size):]
+		kinds = append(kinds, kind)
+		switch kind {
+		case "CUE":
+			cues = append(cues, body)
+		case "BPMLOCK":
+			lock = body
+		}
+	}
+
+	assert.Equal(t, []string{"COLOR", "CUE", "CUE", "BPMLOCK"}, kinds)
+	drop := cues[1]
+	assert.Equal(t, byte(2), drop[1], "slot 3 is cue index 2")
+	assert.Equal(t, uint32(96500), binary.BigEndian.Uint32(drop[2:6]))
+	assert.Equal(t, []byte{0x00, 0x00, 0xFF}, drop[7:10])
+	assert.Equal(t, "Drop\x00", string(drop[12:]))
+	assert.Equal(t, []byte{0x01}, lock)
+}
+
+func TestSerato_NoBPM(t *testing.T) {
+	track := analyzedTrack()
+	track.BPM = 0
+	tags := Serato(track)
+	assert.Nil(t, tags.BeatGrid)
+	assert.NotEmpty(t, tags.Markers)
+	assert.Equal(t, "deadmau5 - Strobe.mp3", tags.FileName)
+}
@@ -0,0 +1,100 @@
+package djexport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// Serato GEOB tag descriptions
+const (
+	SeratoBeatGridTag = "Serato BeatGrid"
+	SeratoMarkersTag  = "Serato Markers2"
+)
+
+// seratoTrackColor is Serato's default track color
+var seratoTrackColor = [3]byte{0xFF, 0xFF, 0xFF}
+
+// SeratoTags holds the Serato tag payloads for one track. Each payload is the
+// binary content of the ID3 GEOB frame (or MP4/FLAC field) Serato reads.
+type SeratoTags struct {
+	TrackID    string `json:"trackId"`
+	FileName   string `json:"fileName"`
+	BPM        int    `json:"bpm,omitempty"`
+	MusicalKey string `json:"musicalKey,omitempty"`
+	BeatGrid   []byte `json:"beatGrid,omitempty"` // Serato BeatGrid, base64 in JSON
+	Markers    []byte `json:"markers"`            // Serato Markers2, base64 in JSON
+}
+
+// Serato returns the Serato tag payloads for a track. Tracks without a BPM
+// get no beat grid.
+func Serato(track models.Track) SeratoTags {
+	tags := SeratoTags{
+		TrackID:    track.ID,
+		FileName:   track.DownloadFileName(),
+		BPM:        track.BPM,
+		MusicalKey: track.MusicalKey,
+		Markers:    SeratoMarkers(track),
+	}
+	if track.BPM > 0 {
+		tags.BeatGrid = SeratoBeatGrid(GridAnchor(track), float64(track.BPM))
+	}
+	return tags
+}
+
+// SeratoBeatGrid encodes a constant-tempo beat grid: a single terminal marker
+// at the first downbeat carrying the BPM
+func SeratoBeatGrid(anchor, bpm float64) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x01, 0x00})
+	_ = binary.Write(&buf, binary.BigEndian, uint32(1))
+	_ = binary.Write(&buf, binary.BigEndian, float32(anchor))
+	_ = binary.Write(&buf, binary.BigEndian, float32(bpm))
+	buf.WriteByte(0x00)
+	return buf.Bytes()
+}
+
+// SeratoMarkers encodes the track color, hot cues and BPM lock as a Serato
+// Markers2 payload. Serato stores the entries base64-encoded after the
+// version header.
+func SeratoMarkers(track models.Track) []byte {
+	var entries bytes.Buffer
+	writeSeratoEntry(&entries, "COLOR", append([]byte{0x00}, seratoTrackColor[:]...))
+
+	for _, cue := range HotCues(track) {
+		r, g, b := rgb(cue.Color)
+		var data bytes.Buffer
+		data.WriteByte(0x00)
+		data.WriteByte(byte(cue.Slot - 1))
+		_ = binary.Write(&data, binary.BigEndian, uint32(math.Round(cue.Position*1000)))
+		data.WriteByte(0x00)
+		data.Write([]byte{byte(r), byte(g), byte(b)})
+		data.Write([]byte{0x00, 0x00})
+		data.WriteString(cue.Label)
+		data.WriteByte(0x00)
+		writeSeratoEntry(&entries, "CUE", data.Bytes())
+	}
+
+	locked := byte(0x00)
+	if track.BPMOverridden {
+		locked = 0x01
+	}
+	writeSeratoEntry(&entries, "BPMLOCK", []byte{locked})
+	entries.WriteByte(0x00)
+
+	encoded := base64.StdEncoding.EncodeToString(entries.Bytes())
+	payload := append([]byte{0x01, 0x01}, encoded...)
+	return append(payload, 0x00)
+}
+
+// writeSeratoEntry writes a Markers2 entry: its NUL-terminated type, the
+// length of its data and the data
+func writeSeratoEntry(buf *bytes.Buffer, kind string, data []byte) {
+	buf.WriteString(kind)
+	buf.WriteByte(0x00)
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+}
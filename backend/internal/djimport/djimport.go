@@ -0,0 +1,76 @@
+// Package djimport parses library exports of DJ software — a Rekordbox XML
+// library, Serato crate files and a Traktor collection.nml — into tracks with
+// their cues and ratings, and the crates or playlists holding them.
+package djimport
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrInvalidLibrary is returned when an export cannot be parsed
+var ErrInvalidLibrary = errors.New("invalid DJ library export")
+
+// Library is the content of a DJ software export
+type Library struct {
+	Tracks []Track
+	Crates []Crate
+}
+
+// Track is a track as the DJ software knows it. Serato crates only record
+// the file path, so tracks parsed from them have just Key and Location.
+type Track struct {
+	// Key identifies the track within the export; crates refer to it
+	Key      string
+	Title    string
+	Artist   string
+	Album    string
+	Location string // Path of the file on the DJ's machine
+	Duration int    // Seconds
+	BPM      float64
+	Rating   int // Stars, 0-5
+	Cues     []Cue
+}
+
+// Cue is a hot cue
+type Cue struct {
+	Slot     int     // 1-8
+	Position float64 // Seconds
+	Label    string
+	Color    string // "#RRGGBB", empty when the export has none
+}
+
+// Crate is a crate or playlist: named, ordered track keys
+type Crate struct {
+	Name      string
+	TrackKeys []string
+}
+
+// FileName returns the base name of the track's file without its extension,
+// used to match tracks exported without tags
+func (t Track) FileName() string {
+	name := path.Base(strings.ReplaceAll(t.Location, `\`, "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// stars converts a 0-255 rating (51 per star) to 0-5 stars
+func stars(rating int) int {
+	if rating <= 0 {
+		return 0
+	}
+	s := (rating + 25) / 51
+	if s > 5 {
+		return 5
+	}
+	return s
+}
+
+// invalid wraps a parse failure in ErrInvalidLibrary
+func invalid(format string, err error) error {
+	return fmt.Errorf("%w: %s: %v", ErrInvalidLibrary, format, err)
+}
@@ -0,0 +1,151 @@
+package djimport
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rekordboxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<DJ_PLAYLISTS Version="1.0.0">
+  <PRODUCT Name="rekordbox" Version="6.7.4" Company="AlphaTheta"/>
+  <COLLECTION Entries="2">
+    <TRACK TrackID="11" Name="Strobe" Artist="deadmau5" Album="For Lack of a Better Name" TotalTime="634"
+           AverageBpm="128.00" Rating="204" Location="file://localhost/Users/dj/Music/deadmau5%20-%20Strobe.mp3">
+      <TEMPO Inizio="0.352" Bpm="128.00" Metro="4/4" Battito="1"/>
+      <POSITION_MARK Name="" Type="0" Start="0.352" Num="-1"/>
+      <POSITION_MARK Name="Drop" Type="0" Start="96.500" Num="2" Red="40" Green="226" Blue="20"/>
+    </TRACK>
+    <TRACK TrackID="12" Name="Ghosts" Artist="deadmau5" Location="file://localhost/C:/Music/Ghosts.flac"/>
+  </COLLECTION>
+  <PLAYLISTS>
+    <NODE Type="0" Name="ROOT" Count="2">
+      <NODE Type="0" Name="Sets" Count="1">
+        <NODE Type="1" Name="Warmup" KeyType="0" Entries="2">
+          <TRACK Key="12"/>
+          <TRACK Key="11"/>
+        </NODE>
+      </NODE>
+      <NODE Type="1" Name="By Location" KeyType="1" Entries="1">
+        <TRACK Key="file://localhost/C:/Music/Ghosts.flac"/>
+      </NODE>
+    </NODE>
+  </PLAYLISTS>
+</DJ_PLAYLISTS>`
+
+func TestParseRekordbox(t *testing.T) {
+	library, err := ParseRekordbox(strings.NewReader(rekordboxXML))
+	require.NoError(t, err)
+
+	require.Len(t, library.Tracks, 2)
+	strobe := library.Tracks[0]
+	assert.Equal(t, "11", strobe.Key)
+	assert.Equal(t, "/Users/dj/Music/deadmau5 - Strobe.mp3", strobe.Location)
+	assert.Equal(t, "deadmau5 - Strobe", strobe.FileName())
+	assert.Equal(t, 4, strobe.Rating)
+	assert.Equal(t, 128.0, strobe.BPM)
+	assert.Equal(t, []Cue{{Slot: 3, Position: 96.5, Label: "Drop", Color: "#28E214"}}, strobe.Cues, "memory cues are not hot cues")
+	assert.Equal(t, "C:/Music/Ghosts.flac", library.Tracks[1].Location)
+
+	assert.Equal(t, []Crate{
+		{Name: "Warmup", TrackKeys: []string{"12", "11"}},
+		{Name: "By Location", TrackKeys: []string{"12"}},
+	}, library.Crates)
+}
+
+func TestParseRekordbox_Invalid(t *testing.T) {
+	_, err := ParseRekordbox(strings.NewReader("<NML></NML>"))
+	assert.ErrorIs(t, err, ErrInvalidLibrary)
+}
+
+// encodeField encodes a Serato tag-length-value field
+func encodeField(tag string, data []byte) []byte {
+	out := make([]byte, 8, 8+len(data))
+	copy(out, tag)
+	binary.BigEndian.PutUint32(out[4:], uint32(len(data)))
+	return append(out, data...)
+}
+
+func utf16BE(s string) []byte {
+	var out []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = binary.BigEndian.AppendUint16(out, u)
+	}
+	return out
+}
+
+func TestParseSerato(t *testing.T) {
+	var data []byte
+	data = append(data, encodeField("vrsn", utf16BE("1.0/Serato ScratchLive Crate"))...)
+	data = append(data, encodeField("osrt", encodeField("tvcn", utf16BE("song")))...)
+	data = append(data, encodeField("otrk", encodeField("ptrk", utf16BE("Music/Café - Été.mp3")))...)
+	data = append(data, encodeField("otrk", encodeField("ptrk", utf16BE("Music/Ghosts.flac")))...)
+
+	library, err := ParseSerato("Subcrates/House%%Deep.crate", data)
+	require.NoError(t, err)
+
+	require.Len(t, library.Tracks, 2)
+	assert.Equal(t, "Music/Café - Été.mp3", library.Tracks[0].Location)
+	assert.Equal(t, "Café - Été", library.Tracks[0].FileName())
+	assert.Equal(t, []Crate{{Name: "House / Deep", TrackKeys: []string{"Music/Café - Été.mp3", "Music/Ghosts.flac"}}}, library.Crates)
+}
+
+func TestParseSerato_Invalid(t *testing.T) {
+	t.Run("truncated", func(t *testing.T) {
+		_, err := ParseSerato("x.crate", []byte("vrsn\x00\x00\x00\xff"))
+		assert.ErrorIs(t, err, ErrInvalidLibrary)
+	})
+	t.Run("not a crate", func(t *testing.T) {
+		_, err := ParseSerato("x.crate", encodeField("vrsn", utf16BE("2.0/Serato Scratch LIVE Database")))
+		assert.ErrorIs(t, err, ErrInvalidLibrary)
+	})
+}
+
+const traktorNML = `<?xml version="1.0" encoding="UTF-8" standalone="no" ?>
+<NML VERSION="19">
+  <COLLECTION ENTRIES="1">
+    <ENTRY TITLE="Strobe" ARTIST="deadmau5">
+      <LOCATION DIR="/:Users/:dj/:Music/:" FILE="Strobe.mp3" VOLUME="Macintosh HD"/>
+      <ALBUM TITLE="For Lack of a Better Name"/>
+      <INFO RANKING="255" PLAYTIME="634"/>
+      <TEMPO BPM="128.000000"/>
+      <CUE_V2 NAME="AutoGrid" TYPE="4" START="352.0" HOTCUE="-1"/>
+      <CUE_V2 NAME="Drop" TYPE="0" START="96500.0" HOTCUE="0"/>
+    </ENTRY>
+  </COLLECTION>
+  <PLAYLISTS>
+    <NODE TYPE="FOLDER" NAME="$ROOT">
+      <SUBNODES COUNT="1">
+        <NODE TYPE="PLAYLIST" NAME="Peak Time">
+          <PLAYLIST ENTRIES="1" TYPE="LIST">
+            <ENTRY><PRIMARYKEY TYPE="TRACK" KEY="Macintosh HD/:Users/:dj/:Music/:Strobe.mp3"/></ENTRY>
+          </PLAYLIST>
+        </NODE>
+      </SUBNODES>
+    </NODE>
+  </PLAYLISTS>
+</NML>`
+
+func TestParseTraktor(t *testing.T) {
+	library, err := ParseTraktor(strings.NewReader(traktorNML))
+	require.NoError(t, err)
+
+	require.Len(t, library.Tracks, 1)
+	track := library.Tracks[0]
+	assert.Equal(t, "/Users/dj/Music/Strobe.mp3", track.Location)
+	assert.Equal(t, "For Lack of a Better Name", track.Album)
+	assert.Equal(t, 5, track.Rating)
+	assert.Equal(t, 634, track.Duration)
+	assert.Equal(t, []Cue{{Slot: 1, Position: 96.5, Label: "Drop"}}, track.Cues)
+	assert.Equal(t, []Crate{{Name: "Peak Time", TrackKeys: []string{track.Key}}}, library.Crates)
+}
+
+func TestStars(t *testing.T) {
+	for rating, want := range map[int]int{0: 0, 51: 1, 102: 2, 153: 3, 204: 4, 255: 5, 100: 2, 300: 5} {
+		assert.Equal(t, want, stars(rating), "rating %d", rating)
+	}
+}
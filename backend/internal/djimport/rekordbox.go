@@ -0,0 +1,129 @@
+package djimport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+type rekordboxLibrary struct {
+	XMLName    xml.Name `xml:"DJ_PLAYLISTS"`
+	Collection struct {
+		Tracks []rekordboxTrack `xml:"TRACK"`
+	} `xml:"COLLECTION"`
+	Playlists struct {
+		Nodes []rekordboxNode `xml:"NODE"`
+	} `xml:"PLAYLISTS"`
+}
+
+type rekordboxTrack struct {
+	TrackID    string            `xml:"TrackID,attr"`
+	Name       string            `xml:"Name,attr"`
+	Artist     string            `xml:"Artist,attr"`
+	Album      string            `xml:"Album,attr"`
+	TotalTime  int               `xml:"TotalTime,attr"`
+	AverageBpm float64           `xml:"AverageBpm,attr"`
+	Rating     int               `xml:"Rating,attr"`
+	Location   string            `xml:"Location,attr"`
+	Marks      []rekordboxMarker `xml:"POSITION_MARK"`
+}
+
+type rekordboxMarker struct {
+	Name  string  `xml:"Name,attr"`
+	Start float64 `xml:"Start,attr"`
+	// Num is the hot cue pad (0-7); memory cues are -1
+	Num   int  `xml:"Num,attr"`
+	Red   *int `xml:"Red,attr"`
+	Green *int `xml:"Green,attr"`
+	Blue  *int `xml:"Blue,attr"`
+}
+
+// rekordboxNode is a folder (Type 0) or playlist (Type 1). Playlists list
+// tracks by TrackID, or by Location when KeyType is 1.
+type rekordboxNode struct {
+	Type    int             `xml:"Type,attr"`
+	Name    string          `xml:"Name,attr"`
+	KeyType int             `xml:"KeyType,attr"`
+	Nodes   []rekordboxNode `xml:"NODE"`
+	Tracks  []struct {
+		Key string `xml:"Key,attr"`
+	} `xml:"TRACK"`
+}
+
+// ParseRekordbox parses a Rekordbox XML library (File > Export Collection in
+// xml format). Playlists in folders are flattened; their names are kept.
+func ParseRekordbox(r io.Reader) (*Library, error) {
+	var doc rekordboxLibrary
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, invalid("rekordbox", err)
+	}
+
+	library := &Library{}
+	locations := make(map[string]string, len(doc.Collection.Tracks))
+	for _, rt := range doc.Collection.Tracks {
+		track := Track{
+			Key:      rt.TrackID,
+			Title:    rt.Name,
+			Artist:   rt.Artist,
+			Album:    rt.Album,
+			Location: rekordboxPath(rt.Location),
+			Duration: rt.TotalTime,
+			BPM:      rt.AverageBpm,
+			Rating:   stars(rt.Rating),
+		}
+		for _, mark := range rt.Marks {
+			if mark.Num < 0 || mark.Num >= 8 {
+				continue
+			}
+			cue := Cue{Slot: mark.Num + 1, Position: mark.Start, Label: mark.Name}
+			if mark.Red != nil && mark.Green != nil && mark.Blue != nil {
+				cue.Color = fmt.Sprintf("#%02X%02X%02X", *mark.Red&0xFF, *mark.Green&0xFF, *mark.Blue&0xFF)
+			}
+			track.Cues = append(track.Cues, cue)
+		}
+		locations[rt.Location] = rt.TrackID
+		library.Tracks = append(library.Tracks, track)
+	}
+
+	var walk func(nodes []rekordboxNode)
+	walk = func(nodes []rekordboxNode) {
+		for _, node := range nodes {
+			if node.Type == 0 {
+				walk(node.Nodes)
+				continue
+			}
+			crate := Crate{Name: node.Name}
+			for _, t := range node.Tracks {
+				key := t.Key
+				if node.KeyType == 1 {
+					key = locations[t.Key]
+				}
+				if key != "" {
+					crate.TrackKeys = append(crate.TrackKeys, key)
+				}
+			}
+			library.Crates = append(library.Crates, crate)
+		}
+	}
+	walk(doc.Playlists.Nodes)
+	return library, nil
+}
+
+// rekordboxPath converts a "file://localhost/..." location to a file path
+func rekordboxPath(location string) string {
+	if !strings.HasPrefix(location, "file://") {
+		return location
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	p := u.Path
+	// Windows locations look like file://localhost/C:/Music/...
+	if len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return p
+}
@@ -0,0 +1,92 @@
+package djimport
+
+import (
+	"encoding/binary"
+	"errors"
+	"path"
+	"strings"
+	"unicode/utf16"
+)
+
+// seratoCrateSeparator joins parent and child crate names in crate file names
+const seratoCrateSeparator = "%%"
+
+// ParseSerato parses a Serato .crate file. Crates record only the paths of
+// their tracks, so the tracks carry no tags, cues or ratings. fileName is the
+// crate's file name, which holds the crate's name: "House%%Deep.crate" is the
+// Deep subcrate of House.
+func ParseSerato(fileName string, data []byte) (*Library, error) {
+	fields, err := seratoFields(data)
+	if err != nil {
+		return nil, invalid("serato", err)
+	}
+
+	version := ""
+	var paths []string
+	for _, f := range fields {
+		switch f.tag {
+		case "vrsn":
+			version = utf16String(f.data)
+		case "otrk":
+			inner, err := seratoFields(f.data)
+			if err != nil {
+				return nil, invalid("serato", err)
+			}
+			for _, g := range inner {
+				if g.tag == "ptrk" {
+					paths = append(paths, utf16String(g.data))
+				}
+			}
+		}
+	}
+	if !strings.Contains(version, "Crate") {
+		return nil, invalid("serato", errors.New("not a crate file"))
+	}
+
+	name := strings.TrimSuffix(path.Base(strings.ReplaceAll(fileName, `\`, "/")), path.Ext(fileName))
+	crate := Crate{Name: strings.ReplaceAll(name, seratoCrateSeparator, " / ")}
+	library := &Library{}
+	seen := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		// Serato stores paths relative to the drive root
+		if !seen[p] {
+			seen[p] = true
+			library.Tracks = append(library.Tracks, Track{Key: p, Location: p})
+		}
+		crate.TrackKeys = append(crate.TrackKeys, p)
+	}
+	library.Crates = []Crate{crate}
+	return library, nil
+}
+
+type seratoField struct {
+	tag  string
+	data []byte
+}
+
+// seratoFields splits Serato's tag-length-value encoding: a four-character
+// tag, a big-endian uint32 length and the data
+func seratoFields(data []byte) ([]seratoField, error) {
+	var fields []seratoField
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, errors.New("truncated field header")
+		}
+		size := binary.BigEndian.Uint32(data[4:8])
+		if uint64(size) > uint64(len(data)-8) {
+			return nil, errors.New("field length exceeds data")
+		}
+		fields = append(fields, seratoField{tag: string(data[:4]), data: data[8 : 8+size]})
+		data = data[8+size:]
+	}
+	return fields, nil
+}
+
+// utf16String decodes Serato's UTF-16BE strings
+func utf16String(data []byte) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, binary.BigEndian.Uint16(data[i:]))
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,111 @@
+package djimport
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+type traktorCollection struct {
+	XMLName    xml.Name `xml:"NML"`
+	Collection struct {
+		Entries []traktorEntry `xml:"ENTRY"`
+	} `xml:"COLLECTION"`
+	Playlists struct {
+		Nodes []traktorNode `xml:"NODE"`
+	} `xml:"PLAYLISTS"`
+}
+
+type traktorEntry struct {
+	Title    string `xml:"TITLE,attr"`
+	Artist   string `xml:"ARTIST,attr"`
+	Location struct {
+		Dir    string `xml:"DIR,attr"`
+		File   string `xml:"FILE,attr"`
+		Volume string `xml:"VOLUME,attr"`
+	} `xml:"LOCATION"`
+	Album struct {
+		Title string `xml:"TITLE,attr"`
+	} `xml:"ALBUM"`
+	Info struct {
+		Ranking  int `xml:"RANKING,attr"`
+		Playtime int `xml:"PLAYTIME,attr"`
+	} `xml:"INFO"`
+	Tempo struct {
+		BPM float64 `xml:"BPM,attr"`
+	} `xml:"TEMPO"`
+	Cues []struct {
+		Name  string  `xml:"NAME,attr"`
+		Start float64 `xml:"START,attr"` // Milliseconds
+		// HotCue is the hot cue pad (0-7); stored cues are -1
+		HotCue int `xml:"HOTCUE,attr"`
+	} `xml:"CUE_V2"`
+}
+
+// traktorNode is a folder (TYPE FOLDER, holding SUBNODES) or a playlist
+type traktorNode struct {
+	Type     string `xml:"TYPE,attr"`
+	Name     string `xml:"NAME,attr"`
+	Subnodes struct {
+		Nodes []traktorNode `xml:"NODE"`
+	} `xml:"SUBNODES"`
+	Playlist struct {
+		Entries []struct {
+			Key struct {
+				Key string `xml:"KEY,attr"`
+			} `xml:"PRIMARYKEY"`
+		} `xml:"ENTRY"`
+	} `xml:"PLAYLIST"`
+}
+
+// ParseTraktor parses a Traktor collection.nml. Playlists in folders are
+// flattened; their names are kept.
+func ParseTraktor(r io.Reader) (*Library, error) {
+	var doc traktorCollection
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, invalid("traktor", err)
+	}
+
+	library := &Library{}
+	for _, entry := range doc.Collection.Entries {
+		// Playlists refer to tracks by volume, ":"-separated directory and file
+		loc := entry.Location
+		track := Track{
+			Key:      loc.Volume + loc.Dir + loc.File,
+			Title:    entry.Title,
+			Artist:   entry.Artist,
+			Album:    entry.Album.Title,
+			Location: strings.ReplaceAll(loc.Dir, "/:", "/") + loc.File,
+			Duration: entry.Info.Playtime,
+			BPM:      entry.Tempo.BPM,
+			Rating:   stars(entry.Info.Ranking),
+		}
+		for _, cue := range entry.Cues {
+			if cue.HotCue < 0 || cue.HotCue >= 8 {
+				continue
+			}
+			track.Cues = append(track.Cues, Cue{Slot: cue.HotCue + 1, Position: cue.Start / 1000, Label: cue.Name})
+		}
+		library.Tracks = append(library.Tracks, track)
+	}
+
+	var walk func(nodes []traktorNode)
+	walk = func(nodes []traktorNode) {
+		for _, node := range nodes {
+			if node.Type == "FOLDER" {
+				walk(node.Subnodes.Nodes)
+				continue
+			}
+			if node.Type != "PLAYLIST" {
+				continue
+			}
+			crate := Crate{Name: node.Name}
+			for _, entry := range node.Playlist.Entries {
+				crate.TrackKeys = append(crate.TrackKeys, entry.Key.Key)
+			}
+			library.Crates = append(library.Crates, crate)
+		}
+	}
+	walk(doc.Playlists.Nodes)
+	return library, nil
+}
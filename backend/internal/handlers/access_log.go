@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// ListTrackAccessLog returns a page of the access log of one of the current
+// user's tracks, newest first, with its view, play and download counters
+// GET /api/v1/tracks/:id/access-log
+func (h *Handlers) ListTrackAccessLog(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.AccessLog == nil {
+		return handleError(c, models.NewServiceUnavailableError("access log", "track access logs are not configured"))
+	}
+
+	var filter models.TrackAccessFilter
+	if err := c.Bind(&filter); err != nil {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	log, err := h.services.AccessLog.List(c.Request().Context(), userID, c.Param("id"), filter)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, log)
+}
+
+// accessContext returns the request's context carrying the client's address
+// and user agent, for the access log of the track it reaches. The address is
+// the one the server's IPExtractor (middleware.ClientIP) trusts.
+func accessContext(c echo.Context) context.Context {
+	return service.WithAccessClient(c.Request().Context(), models.AccessClient{
+		Addr:      c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+}
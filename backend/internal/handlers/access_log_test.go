@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/handlers/middleware"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessContext_RecordsTheTrustedClientAddress(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	track := models.Track{ID: "t1", UserID: "owner", Title: "Night Drive", Visibility: models.VisibilityPublic}
+	require.NoError(t, repo.CreateTrack(ctx, track))
+	accessLog := service.NewAccessLogService(repo, repo)
+
+	// The server's extractor, as cmd/api sets it up without trusted proxies
+	e := echo.New()
+	e.IPExtractor = middleware.ClientIP(nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tracks/t1/stream", nil)
+	req.RemoteAddr = "203.0.113.9:51234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	req.Header.Set("User-Agent", "curl/8.5")
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	accessLog.Record(accessContext(c), track, models.AccessStream, "")
+
+	log, err := accessLog.List(ctx, "owner", "t1", models.TrackAccessFilter{})
+	require.NoError(t, err)
+	require.Len(t, log.Items, 1)
+	assert.Equal(t, "203.0.113.0", log.Items[0].ClientIP, "a client-sent X-Forwarded-For is not logged")
+	assert.Equal(t, "curl/8.5", log.Items[0].UserAgent)
+}
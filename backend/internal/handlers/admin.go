@@ -1,36 +1,115 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/gvasels/personal-music-searchengine/internal/handlers/middleware"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
 	"github.com/gvasels/personal-music-searchengine/internal/service"
 	"github.com/labstack/echo/v4"
 )
 
 // AdminHandler handles admin management endpoints.
 type AdminHandler struct {
-	adminService service.AdminService
+	adminService  service.AdminService
+	featured      *service.DiscoverService
+	migrations    MigrationStatusReader
+	moderation    *service.ModerationService
+	searchIndex   SearchIndexReader
+	indexTransfer SearchIndexTransfer
+	transfer      *service.TrackTransferService
+	uploadDebug   *service.UploadDebugService
+	userImport    *service.UserImportService
+	// bulk bounds concurrent imports and transfers; nil admits every request
+	bulk *resilience.Limiter
 }
 
+// MigrationStatusReader reports the progress of the versioned data migrations.
+type MigrationStatusReader interface {
+	Status(ctx context.Context) (*models.MigrationStatusResponse, error)
+}
+
+// SearchIndexReader reports the size of the search index and the tracks
+// stuck in processing.
+type SearchIndexReader interface {
+	IndexStats(ctx context.Context) (*models.SearchIndexStatsResponse, error)
+	StuckTranscodes(ctx context.Context, olderThan time.Duration) (*models.StuckTranscodesResponse, error)
+}
+
+// SearchIndexTransfer exports the search index to, and imports it from, files
+// in the search index bucket.
+type SearchIndexTransfer interface {
+	ExportIndex(ctx context.Context, req models.ExportSearchIndexRequest) (*models.SearchIndexExportResponse, error)
+	ImportIndex(ctx context.Context, req models.ImportSearchIndexRequest) (*models.SearchIndexImportResponse, error)
+}
+
+// defaultStuckTranscodeAge is how long a transcode may be pending or running
+// before the stuck transcodes endpoint lists it
+const defaultStuckTranscodeAge = time.Hour
+
 // NewAdminHandler creates a new AdminHandler.
 func NewAdminHandler(adminService service.AdminService) *AdminHandler {
 	return &AdminHandler{adminService: adminService}
 }
 
+// SetFeatured enables the featured content endpoints.
+func (h *AdminHandler) SetFeatured(featured *service.DiscoverService) {
+	h.featured = featured
+}
+
+// SetMigrations enables the migrations status endpoint.
+func (h *AdminHandler) SetMigrations(migrations MigrationStatusReader) {
+	h.migrations = migrations
+}
+
+// SetModeration enables the moderation review queue endpoints.
+func (h *AdminHandler) SetModeration(moderation *service.ModerationService) {
+	h.moderation = moderation
+}
+
+// SetSearchIndex enables the search index stats and stuck transcodes endpoints.
+func (h *AdminHandler) SetSearchIndex(searchIndex SearchIndexReader) {
+	h.searchIndex = searchIndex
+}
+
+// SetSearchIndexTransfer enables the search index export and import endpoints.
+func (h *AdminHandler) SetSearchIndexTransfer(indexTransfer SearchIndexTransfer) {
+	h.indexTransfer = indexTransfer
+}
+
+// SetTrackTransfer enables the track ownership transfer endpoint.
+func (h *AdminHandler) SetTrackTransfer(transfer *service.TrackTransferService) {
+	h.transfer = transfer
+}
+
+// SetUploadDebug enables the upload debug bundle endpoint.
+func (h *AdminHandler) SetUploadDebug(uploadDebug *service.UploadDebugService) {
+	h.uploadDebug = uploadDebug
+}
+
+// SetUserImport enables the bulk user import endpoint.
+func (h *AdminHandler) SetUserImport(userImport *service.UserImportService) {
+	h.userImport = userImport
+}
+
+// SetBulkLimiter bounds how many user imports and track transfers run at once.
+func (h *AdminHandler) SetBulkLimiter(bulk *resilience.Limiter) {
+	h.bulk = bulk
+}
+
 // SearchUsers handles GET /api/v1/admin/users?search=query&limit=20
 // Admin only - searches for users by email or display name.
 func (h *AdminHandler) SearchUsers(c echo.Context) error {
 	var req models.AdminSearchUsersRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	if req.Search == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			models.NewValidationError("search query parameter is required"),
-		))
+		return handleError(c, models.NewValidationError("search query parameter is required"))
 	}
 
 	limit := req.Limit
@@ -56,7 +135,7 @@ func (h *AdminHandler) SearchUsers(c echo.Context) error {
 func (h *AdminHandler) GetUserDetails(c echo.Context) error {
 	userID := c.Param("id")
 	if userID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	details, err := h.adminService.GetUserDetails(c.Request().Context(), userID)
@@ -72,13 +151,13 @@ func (h *AdminHandler) GetUserDetails(c echo.Context) error {
 func (h *AdminHandler) UpdateUserRole(c echo.Context) error {
 	userID := c.Param("id")
 	if userID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	// Get admin's own user ID to prevent self-modification
 	adminID := middleware.GetUserID(c)
 	if adminID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	var req models.UpdateRoleRequest
@@ -89,9 +168,7 @@ func (h *AdminHandler) UpdateUserRole(c echo.Context) error {
 	// Validate and convert role string
 	newRole, valid := models.ValidateRole(req.Role)
 	if !valid {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			models.NewValidationError("invalid role"),
-		))
+		return handleError(c, models.NewValidationError("invalid role"))
 	}
 
 	// Use the admin-aware version that prevents self-modification
@@ -114,25 +191,23 @@ func (h *AdminHandler) UpdateUserRole(c echo.Context) error {
 func (h *AdminHandler) UpdateUserStatus(c echo.Context) error {
 	userID := c.Param("id")
 	if userID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	// Get admin's own user ID to prevent self-disabling
 	adminID := middleware.GetUserID(c)
 	if adminID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	// Prevent admin from disabling themselves
 	if adminID == userID {
-		return c.JSON(http.StatusForbidden, models.NewErrorResponse(
-			models.NewForbiddenError("cannot modify your own status"),
-		))
+		return handleError(c, models.NewForbiddenError("cannot modify your own status"))
 	}
 
 	var req models.UpdateStatusRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	err := h.adminService.SetUserStatus(c.Request().Context(), userID, req.Disabled)
@@ -148,3 +223,208 @@ func (h *AdminHandler) UpdateUserStatus(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, details)
 }
+
+// ImportUsers handles POST /api/v1/admin/users/import
+// Admin only - provisions a CSV of users and reports the outcome of each row.
+func (h *AdminHandler) ImportUsers(c echo.Context) error {
+	if h.userImport == nil {
+		return handleError(c, models.NewServiceUnavailableError("admin", "user import is not configured"))
+	}
+
+	var req models.ImportUsersRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+	if err := bindDryRun(c, &req.DryRun); err != nil {
+		return handleError(c, err)
+	}
+
+	resp, err := h.userImport.Import(c.Request().Context(), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListMigrations handles GET /api/v1/admin/migrations
+// Admin only - lists the data migrations with their status and progress.
+func (h *AdminHandler) ListMigrations(c echo.Context) error {
+	if h.migrations == nil {
+		return handleError(c, models.NewServiceUnavailableError("migrations", "data migrations are not configured"))
+	}
+
+	status, err := h.migrations.Status(c.Request().Context())
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+// GetSearchIndexStats handles GET /api/v1/admin/search/stats
+// Admin only - reports document counts per library and the size of the search index.
+func (h *AdminHandler) GetSearchIndexStats(c echo.Context) error {
+	if h.searchIndex == nil {
+		return handleError(c, models.NewServiceUnavailableError("search", "search is not configured"))
+	}
+
+	stats, err := h.searchIndex.IndexStats(c.Request().Context())
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+// ListStuckTranscodes handles GET /api/v1/admin/search/stuck-transcodes?olderThan=1h
+// Admin only - lists tracks of all libraries whose HLS transcode has been
+// pending or running for longer than olderThan (1h by default).
+func (h *AdminHandler) ListStuckTranscodes(c echo.Context) error {
+	if h.searchIndex == nil {
+		return handleError(c, models.NewServiceUnavailableError("search", "search is not configured"))
+	}
+
+	olderThan := defaultStuckTranscodeAge
+	if value := c.QueryParam("olderThan"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return handleError(c, models.NewValidationError("olderThan must be a positive duration such as 30m or 2h"))
+		}
+		olderThan = d
+	}
+
+	stuck, err := h.searchIndex.StuckTranscodes(c.Request().Context(), olderThan)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, stuck)
+}
+
+// ExportSearchIndex handles POST /api/v1/admin/search/export
+// Admin only - writes the search index, or one library's documents, to an
+// NDJSON export file under exports/ in the search index bucket.
+func (h *AdminHandler) ExportSearchIndex(c echo.Context) error {
+	if h.indexTransfer == nil {
+		return handleError(c, models.NewServiceUnavailableError("search", "search is not configured"))
+	}
+
+	var req models.ExportSearchIndexRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	resp, err := h.indexTransfer.ExportIndex(c.Request().Context(), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ImportSearchIndex handles POST /api/v1/admin/search/import
+// Admin only - loads an export file from the search index bucket into the
+// index, replacing documents with the same ID.
+func (h *AdminHandler) ImportSearchIndex(c echo.Context) error {
+	if h.indexTransfer == nil {
+		return handleError(c, models.NewServiceUnavailableError("search", "search is not configured"))
+	}
+
+	var req models.ImportSearchIndexRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	resp, err := h.indexTransfer.ImportIndex(c.Request().Context(), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// TransferTrack handles POST /api/v1/admin/tracks/:trackId/transfer
+// Admin only - moves a track with its files to another user's library.
+func (h *AdminHandler) TransferTrack(c echo.Context) error {
+	if h.transfer == nil {
+		return handleError(c, models.NewServiceUnavailableError("admin", "track transfer is not configured"))
+	}
+
+	trackID := c.Param("trackId")
+	if trackID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.TransferTrackRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	resp, err := h.transfer.Transfer(c.Request().Context(), trackID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListModerationReviews handles GET /api/v1/admin/moderation?status=FLAGGED&limit=20&cursor=
+// Admin only - lists held visibility changes, oldest first; flagged ones by default.
+func (h *AdminHandler) ListModerationReviews(c echo.Context) error {
+	if h.moderation == nil {
+		return handleError(c, models.NewServiceUnavailableError("moderation", "content moderation is not configured"))
+	}
+
+	var filter models.ModerationFilter
+	if err := c.Bind(&filter); err != nil {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	reviews, err := h.moderation.ListReviews(c.Request().Context(), filter)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, reviews)
+}
+
+// ApproveModerationReview handles POST /api/v1/admin/moderation/:trackId/approve
+// Admin only - publishes a held visibility change.
+func (h *AdminHandler) ApproveModerationReview(c echo.Context) error {
+	return h.decideModerationReview(c, h.moderation.Approve)
+}
+
+// RejectModerationReview handles POST /api/v1/admin/moderation/:trackId/reject
+// Admin only - refuses a held visibility change; the track keeps its visibility.
+func (h *AdminHandler) RejectModerationReview(c echo.Context) error {
+	return h.decideModerationReview(c, h.moderation.Reject)
+}
+
+func (h *AdminHandler) decideModerationReview(c echo.Context, decide func(ctx context.Context, adminID, trackID, note string) (*models.ModerationReview, error)) error {
+	if h.moderation == nil {
+		return handleError(c, models.NewServiceUnavailableError("moderation", "content moderation is not configured"))
+	}
+
+	trackID := c.Param("trackId")
+	if trackID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	adminID := middleware.GetUserID(c)
+	if adminID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var req models.ModerationDecisionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	review, err := decide(c.Request().Context(), adminID, trackID, req.Note)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, review)
+}
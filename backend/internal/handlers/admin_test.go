@@ -12,6 +12,9 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
+	"github.com/gvasels/personal-music-searchengine/internal/service"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -447,6 +450,396 @@ func TestAdminHandler_UpdateUserStatus(t *testing.T) {
 	}
 }
 
+// stubMigrationStatus returns a fixed migration status
+type stubMigrationStatus struct {
+	status *models.MigrationStatusResponse
+	err    error
+}
+
+func (s stubMigrationStatus) Status(ctx context.Context) (*models.MigrationStatusResponse, error) {
+	return s.status, s.err
+}
+
+func TestAdminHandler_ListMigrations(t *testing.T) {
+	e := setupAdminTestEcho()
+
+	tests := []struct {
+		name           string
+		migrations     MigrationStatusReader
+		expectedStatus int
+	}{
+		{
+			name: "lists migrations",
+			migrations: stubMigrationStatus{status: &models.MigrationStatusResponse{
+				Items: []models.MigrationState{
+					{Version: 1, Name: "backfill-track-indexes", Status: models.MigrationCompleted},
+					{Version: 2, Name: "default-track-visibility", Status: models.MigrationRunning, Scanned: 200},
+				},
+				Pending: 1,
+			}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "store error",
+			migrations:     stubMigrationStatus{err: errors.New("dynamodb unavailable")},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "not configured",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandler(new(MockAdminService))
+			if tt.migrations != nil {
+				handler.SetMigrations(tt.migrations)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/migrations", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, handler.ListMigrations(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.MigrationStatusResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Len(t, response.Items, 2)
+				assert.Equal(t, 1, response.Pending)
+			}
+		})
+	}
+}
+
+// stubSearchIndex returns fixed search index stats and stuck transcodes
+type stubSearchIndex struct {
+	stats     *models.SearchIndexStatsResponse
+	stuck     *models.StuckTranscodesResponse
+	olderThan *time.Duration
+	err       error
+}
+
+func (s stubSearchIndex) IndexStats(ctx context.Context) (*models.SearchIndexStatsResponse, error) {
+	return s.stats, s.err
+}
+
+func (s stubSearchIndex) StuckTranscodes(ctx context.Context, olderThan time.Duration) (*models.StuckTranscodesResponse, error) {
+	if s.olderThan != nil {
+		*s.olderThan = olderThan
+	}
+	return s.stuck, s.err
+}
+
+func TestAdminHandler_GetSearchIndexStats(t *testing.T) {
+	e := setupAdminTestEcho()
+
+	tests := []struct {
+		name           string
+		searchIndex    SearchIndexReader
+		expectedStatus int
+	}{
+		{
+			name: "reports stats",
+			searchIndex: stubSearchIndex{stats: &models.SearchIndexStatsResponse{
+				Documents:  3,
+				IndexBytes: 2048,
+				Users:      []models.SearchIndexUserCount{{UserID: "u2", Documents: 2}, {UserID: "u1", Documents: 1}},
+			}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "lambda error",
+			searchIndex:    stubSearchIndex{err: errors.New("lambda invocation failed")},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "not configured",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandler(new(MockAdminService))
+			if tt.searchIndex != nil {
+				handler.SetSearchIndex(tt.searchIndex)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/search/stats", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, handler.GetSearchIndexStats(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.SearchIndexStatsResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, 3, response.Documents)
+				assert.Equal(t, int64(2048), response.IndexBytes)
+				assert.Len(t, response.Users, 2)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ListStuckTranscodes(t *testing.T) {
+	e := setupAdminTestEcho()
+	stuck := &models.StuckTranscodesResponse{
+		Items:     []models.StuckTranscode{{TrackID: "t1", UserID: "u1", HLSStatus: models.HLSStatusProcessing}},
+		Total:     1,
+		OlderThan: "1h0m0s",
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		configured     bool
+		expectedStatus int
+		expectedAge    time.Duration
+	}{
+		{name: "default age", configured: true, expectedStatus: http.StatusOK, expectedAge: time.Hour},
+		{name: "custom age", query: "?olderThan=30m", configured: true, expectedStatus: http.StatusOK, expectedAge: 30 * time.Minute},
+		{name: "invalid age", query: "?olderThan=soon", configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "negative age", query: "?olderThan=-1h", configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "not configured", expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var olderThan time.Duration
+			handler := NewAdminHandler(new(MockAdminService))
+			if tt.configured {
+				handler.SetSearchIndex(stubSearchIndex{stuck: stuck, olderThan: &olderThan})
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/search/stuck-transcodes"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, handler.ListStuckTranscodes(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedAge, olderThan)
+				var response models.StuckTranscodesResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				require.Len(t, response.Items, 1)
+				assert.Equal(t, "t1", response.Items[0].TrackID)
+			}
+		})
+	}
+}
+
+// stubSearchIndexTransfer returns fixed export and import results
+type stubSearchIndexTransfer struct {
+	err error
+}
+
+func (s stubSearchIndexTransfer) ExportIndex(ctx context.Context, req models.ExportSearchIndexRequest) (*models.SearchIndexExportResponse, error) {
+	return &models.SearchIndexExportResponse{Key: "exports/20261016T093000Z-all.ndjson", UserID: req.UserID, Documents: 3}, s.err
+}
+
+func (s stubSearchIndexTransfer) ImportIndex(ctx context.Context, req models.ImportSearchIndexRequest) (*models.SearchIndexImportResponse, error) {
+	return &models.SearchIndexImportResponse{Key: req.Key, Imported: 3}, s.err
+}
+
+func TestAdminHandler_ExportSearchIndex(t *testing.T) {
+	e := setupAdminTestEcho()
+
+	tests := []struct {
+		name           string
+		body           string
+		transfer       SearchIndexTransfer
+		expectedStatus int
+	}{
+		{name: "exports every library", body: `{}`, transfer: stubSearchIndexTransfer{}, expectedStatus: http.StatusOK},
+		{name: "exports one library", body: `{"userId":"u1"}`, transfer: stubSearchIndexTransfer{}, expectedStatus: http.StatusOK},
+		{name: "lambda error", body: `{}`, transfer: stubSearchIndexTransfer{err: errors.New("lambda invocation failed")}, expectedStatus: http.StatusInternalServerError},
+		{name: "not configured", body: `{}`, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandler(new(MockAdminService))
+			if tt.transfer != nil {
+				handler.SetSearchIndexTransfer(tt.transfer)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/search/export", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, handler.ExportSearchIndex(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.SearchIndexExportResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, 3, response.Documents)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ImportSearchIndex(t *testing.T) {
+	e := setupAdminTestEcho()
+
+	tests := []struct {
+		name           string
+		body           string
+		transfer       SearchIndexTransfer
+		expectedStatus int
+	}{
+		{name: "imports", body: `{"key":"exports/a.ndjson"}`, transfer: stubSearchIndexTransfer{}, expectedStatus: http.StatusOK},
+		{name: "missing key", body: `{}`, transfer: stubSearchIndexTransfer{}, expectedStatus: http.StatusBadRequest},
+		{name: "not configured", body: `{"key":"exports/a.ndjson"}`, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandler(new(MockAdminService))
+			if tt.transfer != nil {
+				handler.SetSearchIndexTransfer(tt.transfer)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/search/import", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, handler.ImportSearchIndex(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.SearchIndexImportResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, 3, response.Imported)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_TransferTrack(t *testing.T) {
+	e := setupAdminTestEcho()
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		trackID        string
+		body           string
+		configured     bool
+		expectedStatus int
+	}{
+		{name: "transfers", trackID: "t1", body: `{"toUserId":"bob"}`, configured: true, expectedStatus: http.StatusOK},
+		{name: "missing recipient", trackID: "t1", body: `{}`, configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "unknown track", trackID: "missing", body: `{"toUserId":"bob"}`, configured: true, expectedStatus: http.StatusNotFound},
+		{name: "not configured", trackID: "t1", body: `{"toUserId":"bob"}`, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandler(new(MockAdminService))
+			if tt.configured {
+				repo := repository.NewMemoryRepository()
+				s3 := repository.NewMemoryS3Repository("")
+				require.NoError(t, repo.CreateUser(ctx, models.User{ID: "bob"}))
+				require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "alice", S3Key: "media/alice/t1.mp3"}))
+				s3.PutObject("media/alice/t1.mp3", nil)
+				handler.SetTrackTransfer(service.NewTrackTransferService(repo, s3))
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/tracks/"+tt.trackID+"/transfer", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("trackId")
+			c.SetParamValues(tt.trackID)
+
+			require.NoError(t, handler.TransferTrack(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.TrackTransferResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "alice", response.FromUserID)
+				assert.Equal(t, "bob", response.ToUserID)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ImportUsers(t *testing.T) {
+	e := setupAdminTestEcho()
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		configured     bool
+		expectedStatus int
+	}{
+		{name: "dry run", body: `{"csv":"email\nnew@example.com\nold@example.com\n","dryRun":true}`, configured: true, expectedStatus: http.StatusOK},
+		{name: "dry run from the query", query: "?dryRun=true", body: `{"csv":"email\nnew@example.com\nold@example.com\n"}`, configured: true, expectedStatus: http.StatusOK},
+		{name: "invalid dry run flag", query: "?dryRun=maybe", body: `{"csv":"email\nnew@example.com\n"}`, configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "missing csv", body: `{}`, configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "invalid default role", body: `{"csv":"email\nnew@example.com\n","defaultRole":"owner"}`, configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "no email column", body: `{"csv":"name\nNew\n","dryRun":true}`, configured: true, expectedStatus: http.StatusBadRequest},
+		{name: "not configured", body: `{"csv":"email\nnew@example.com\n"}`, expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandler(new(MockAdminService))
+			if tt.configured {
+				repo := repository.NewMemoryRepository()
+				require.NoError(t, repo.CreateUser(ctx, models.User{ID: "old", Email: "old@example.com"}))
+				// Dry runs never reach Cognito
+				handler.SetUserImport(service.NewUserImportService(repo, nil))
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/import"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, handler.ImportUsers(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.UserImportResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.True(t, response.DryRun)
+				assert.Equal(t, 1, response.Created)
+				assert.Equal(t, 1, response.Existing)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_BulkLimit(t *testing.T) {
+	e := setupAdminTestEcho()
+	bulk := resilience.NewLimiter(resilience.LimitBulk, 1, 0)
+	handler := NewAdminHandler(new(MockAdminService))
+	handler.SetBulkLimiter(bulk)
+	RegisterAdminRoutes(e, handler, func(ctx context.Context, userID string) (models.UserRole, error) {
+		return models.RoleAdmin, nil
+	})
+
+	// An import already holds the only slot
+	release, err := bulk.Acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/tracks/t1/transfer", strings.NewReader(`{"toUserId":"bob"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-User-ID", "admin")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), `"TOO_BUSY"`)
+}
+
 func TestNewAdminHandler(t *testing.T) {
 	mockService := new(MockAdminService)
 	handler := NewAdminHandler(mockService)
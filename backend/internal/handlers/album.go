@@ -5,7 +5,8 @@ import (
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 )
 
-// ListAlbums returns a paginated list of albums
+// ListAlbums returns a paginated list of albums, or only their count for
+// HEAD requests and countOnly=true
 func (h *Handlers) ListAlbums(c echo.Context) error {
 	userID := getUserIDFromContext(c)
 	if userID == "" {
@@ -17,6 +18,14 @@ func (h *Handlers) ListAlbums(c echo.Context) error {
 		return handleError(c, models.ErrBadRequest)
 	}
 
+	if wantsCount(c, filter.CountOnly) {
+		count, err := h.services.Album.CountAlbums(c.Request().Context(), userID)
+		if err != nil {
+			return handleError(c, err)
+		}
+		return successCount(c, count)
+	}
+
 	albums, err := h.services.Album.ListAlbums(c.Request().Context(), userID, filter)
 	if err != nil {
 		return handleError(c, err)
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// ListArchiveSuggestions lists the tracks of the current user's library that
+// nobody has played in two years, oldest first, from the insights job's last
+// report
+// GET /api/v1/me/insights/archive
+func (h *Handlers) ListArchiveSuggestions(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.ArchiveSuggestions == nil {
+		return handleError(c, archiveSuggestionsUnavailable())
+	}
+
+	suggestions, err := h.services.ArchiveSuggestions.Suggestions(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, suggestions)
+}
+
+// ApplyArchiveSuggestions deletes or dismisses suggested tracks in bulk.
+// Deleted tracks go to the trash; the result's operation ID undoes that.
+// POST /api/v1/me/insights/archive/apply
+func (h *Handlers) ApplyArchiveSuggestions(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.ArchiveSuggestions == nil {
+		return handleError(c, archiveSuggestionsUnavailable())
+	}
+
+	var req models.ApplyArchiveSuggestionsRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	result, err := h.services.ArchiveSuggestions.Apply(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, result)
+}
+
+func archiveSuggestionsUnavailable() error {
+	return models.NewServiceUnavailableError("archive suggestions", "archive suggestions are not configured")
+}
@@ -24,7 +24,7 @@ func NewArtistProfileHandler(profileService service.ArtistProfileService) *Artis
 func (h *ArtistProfileHandler) CreateProfile(c echo.Context) error {
 	userID := middleware.GetUserID(c)
 	if userID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	var req models.CreateArtistProfileRequest
@@ -45,7 +45,7 @@ func (h *ArtistProfileHandler) CreateProfile(c echo.Context) error {
 func (h *ArtistProfileHandler) GetProfile(c echo.Context) error {
 	profileUserID := c.Param("id")
 	if profileUserID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	profile, err := h.profileService.GetProfile(c.Request().Context(), profileUserID)
@@ -61,12 +61,12 @@ func (h *ArtistProfileHandler) GetProfile(c echo.Context) error {
 func (h *ArtistProfileHandler) UpdateProfile(c echo.Context) error {
 	requestingUserID := middleware.GetUserID(c)
 	if requestingUserID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	profileUserID := c.Param("id")
 	if profileUserID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	var req models.UpdateArtistProfileRequest
@@ -87,12 +87,12 @@ func (h *ArtistProfileHandler) UpdateProfile(c echo.Context) error {
 func (h *ArtistProfileHandler) DeleteProfile(c echo.Context) error {
 	requestingUserID := middleware.GetUserID(c)
 	if requestingUserID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	profileUserID := c.Param("id")
 	if profileUserID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	err := h.profileService.DeleteProfile(c.Request().Context(), requestingUserID, profileUserID)
@@ -122,7 +122,7 @@ func (h *ArtistProfileHandler) ListProfiles(c echo.Context) error {
 func (h *ArtistProfileHandler) GetMyProfile(c echo.Context) error {
 	userID := middleware.GetUserID(c)
 	if userID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	profile, err := h.profileService.GetProfile(c.Request().Context(), userID)
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// ListCollections returns the collections of the current user's library in
+// sidebar order
+// GET /api/v1/me/collections
+func (h *Handlers) ListCollections(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Collections == nil {
+		return handleError(c, collectionsUnavailable())
+	}
+
+	collections, err := h.services.Collections.List(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return successList(c, collections)
+}
+
+// CreateCollection adds a filter or manual collection to the end of the sidebar
+// POST /api/v1/me/collections
+func (h *Handlers) CreateCollection(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Collections == nil {
+		return handleError(c, collectionsUnavailable())
+	}
+
+	var req models.CreateCollectionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	collection, err := h.services.Collections.Create(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, collection)
+}
+
+// UpdateCollection renames a collection or replaces its filter
+// PATCH /api/v1/me/collections/:id
+func (h *Handlers) UpdateCollection(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Collections == nil {
+		return handleError(c, collectionsUnavailable())
+	}
+
+	var req models.UpdateCollectionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	collection, err := h.services.Collections.Update(c.Request().Context(), userID, c.Param("id"), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, collection)
+}
+
+// DeleteCollection removes a collection; its tracks stay in the library
+// DELETE /api/v1/me/collections/:id
+func (h *Handlers) DeleteCollection(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Collections == nil {
+		return handleError(c, collectionsUnavailable())
+	}
+
+	if err := h.services.Collections.Delete(c.Request().Context(), userID, c.Param("id")); err != nil {
+		return handleError(c, err)
+	}
+
+	return noContent(c)
+}
+
+// ReorderCollections sets the sidebar order of the library's collections
+// PUT /api/v1/me/collections/order
+func (h *Handlers) ReorderCollections(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Collections == nil {
+		return handleError(c, collectionsUnavailable())
+	}
+
+	var req models.ReorderCollectionsRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	collections, err := h.services.Collections.Reorder(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return successList(c, collections)
+}
+
+// ListCollectionTracks returns a page of a collection's tracks
+// GET /api/v1/me/collections/:id/tracks?limit={limit}&lastKey={cursor}
+func (h *Handlers) ListCollectionTracks(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Collections == nil {
+		return handleError(c, collectionsUnavailable())
+	}
+
+	limit := 0
+	if l := c.QueryParam("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil {
+			limit = parsed
+		}
+	}
+
+	tracks, err := h.services.Collections.Tracks(c.Request().Context(), userID, c.Param("id"), limit, c.QueryParam("lastKey"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, tracks)
+}
+
+// AddCollectionTracks adds library tracks to a manual collection
+// POST /api/v1/me/collections/:id/tracks
+func (h *Handlers) AddCollectionTracks(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Collections == nil {
+		return handleError(c, collectionsUnavailable())
+	}
+
+	var req models.CollectionTracksRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	collection, err := h.services.Collections.AddTracks(c.Request().Context(), userID, c.Param("id"), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, collection)
+}
+
+// RemoveCollectionTracks removes tracks from a manual collection
+// DELETE /api/v1/me/collections/:id/tracks
+func (h *Handlers) RemoveCollectionTracks(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Collections == nil {
+		return handleError(c, collectionsUnavailable())
+	}
+
+	var req models.CollectionTracksRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	collection, err := h.services.Collections.RemoveTracks(c.Request().Context(), userID, c.Param("id"), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, collection)
+}
+
+func collectionsUnavailable() error {
+	return models.NewServiceUnavailableError("collections", "collections are not configured")
+}
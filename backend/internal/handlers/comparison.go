@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// ListComparisonConsents lists the library comparison consents the current
+// user gave and received, each marked mutual when both users consented
+// GET /api/v1/me/comparisons
+func (h *Handlers) ListComparisonConsents(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Comparisons == nil {
+		return handleError(c, comparisonsUnavailable())
+	}
+
+	consents, err := h.services.Comparisons.ListConsents(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, consents)
+}
+
+// CreateComparisonConsent consents to compare libraries with the user
+// registered under an email address
+// POST /api/v1/me/comparisons
+func (h *Handlers) CreateComparisonConsent(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Comparisons == nil {
+		return handleError(c, comparisonsUnavailable())
+	}
+
+	var req models.CreateComparisonConsentRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	peer, err := h.services.Comparisons.Consent(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, peer)
+}
+
+// DeleteComparisonConsent withdraws the current user's consent to compare
+// with another user
+// DELETE /api/v1/me/comparisons/:userId
+func (h *Handlers) DeleteComparisonConsent(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Comparisons == nil {
+		return handleError(c, comparisonsUnavailable())
+	}
+
+	if err := h.services.Comparisons.Withdraw(c.Request().Context(), userID, c.Param("userId")); err != nil {
+		return handleError(c, err)
+	}
+
+	return noContent(c)
+}
+
+// CompareLibraries compares the current user's library with another user's;
+// both users must have consented
+// GET /api/v1/me/comparisons/:userId
+func (h *Handlers) CompareLibraries(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Comparisons == nil {
+		return handleError(c, comparisonsUnavailable())
+	}
+
+	comparison, err := h.services.Comparisons.Compare(c.Request().Context(), userID, c.Param("userId"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, comparison)
+}
+
+func comparisonsUnavailable() error {
+	return models.NewServiceUnavailableError("library comparison", "library comparison is not configured")
+}
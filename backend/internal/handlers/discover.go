@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"github.com/gvasels/personal-music-searchengine/internal/handlers/middleware"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetDiscoverFeed returns the home feed: featured content in effect merged
+// with public playlists, artists and new tracks
+// GET /api/v1/discover
+func (h *Handlers) GetDiscoverFeed(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Discover == nil {
+		return handleError(c, discoverUnavailable())
+	}
+
+	feed, err := h.services.Discover.Discover(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, feed)
+}
+
+// ListFeaturedItems handles GET /api/v1/admin/featured
+// Admin only - lists featured items with their schedule status, in feed order.
+func (h *AdminHandler) ListFeaturedItems(c echo.Context) error {
+	if h.featured == nil {
+		return handleError(c, discoverUnavailable())
+	}
+
+	items, err := h.featured.ListFeatured(c.Request().Context())
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return successList(c, items)
+}
+
+// CreateFeaturedItem handles POST /api/v1/admin/featured
+// Admin only - features a public playlist, an artist profile or an editorial
+// section, optionally between featuredFrom and featuredUntil.
+func (h *AdminHandler) CreateFeaturedItem(c echo.Context) error {
+	if h.featured == nil {
+		return handleError(c, discoverUnavailable())
+	}
+
+	adminID := middleware.GetUserID(c)
+	if adminID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var req models.CreateFeaturedItemRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	item, err := h.featured.CreateFeatured(c.Request().Context(), adminID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, item)
+}
+
+// UpdateFeaturedItem handles PUT /api/v1/admin/featured/:id
+// Admin only - replaces a featured item's text, position and schedule.
+func (h *AdminHandler) UpdateFeaturedItem(c echo.Context) error {
+	if h.featured == nil {
+		return handleError(c, discoverUnavailable())
+	}
+
+	var req models.UpdateFeaturedItemRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	item, err := h.featured.UpdateFeatured(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, item)
+}
+
+// DeleteFeaturedItem handles DELETE /api/v1/admin/featured/:id
+// Admin only - stops featuring an item.
+func (h *AdminHandler) DeleteFeaturedItem(c echo.Context) error {
+	if h.featured == nil {
+		return handleError(c, discoverUnavailable())
+	}
+
+	if err := h.featured.DeleteFeatured(c.Request().Context(), c.Param("id")); err != nil {
+		return handleError(c, err)
+	}
+
+	return noContent(c)
+}
+
+func discoverUnavailable() error {
+	return models.NewServiceUnavailableError("discover", "the discover feed is not configured")
+}
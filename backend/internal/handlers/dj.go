@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+	"github.com/labstack/echo/v4"
+)
+
+// ExportTrackForDJ downloads a track's BPM, beat grid anchor, key and hot cues
+// in the requested DJ software format
+func (h *Handlers) ExportTrackForDJ(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	trackID := c.Param("id")
+	if trackID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.DJExportRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	export, err := h.services.DJExport.ExportTrack(c.Request().Context(), userID, trackID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return sendDJExport(c, export)
+}
+
+// ExportPlaylistForDJ downloads the analysis of a playlist's tracks, as a
+// playlist where the format supports one
+func (h *Handlers) ExportPlaylistForDJ(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	playlistID := c.Param("id")
+	if playlistID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.DJExportRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	export, err := h.services.DJExport.ExportPlaylist(c.Request().Context(), userID, playlistID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return sendDJExport(c, export)
+}
+
+// ImportDJLibrary imports hot cues, ratings and crates from a Rekordbox,
+// Serato or Traktor library export into the tracks it matches
+func (h *Handlers) ImportDJLibrary(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var req models.DJImportRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+	if err := bindDryRun(c, &req.DryRun); err != nil {
+		return handleError(c, err)
+	}
+
+	result, err := h.services.DJImport.ImportLibrary(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, result)
+}
+
+// sendDJExport writes an export as a file attachment
+func sendDJExport(c echo.Context, export *models.DJExport) error {
+	c.Response().Header().Set(echo.HeaderContentDisposition, sanitize.ContentDisposition(export.FileName))
+	return c.Blob(http.StatusOK, export.ContentType, export.Body)
+}
@@ -25,12 +25,12 @@ func NewFollowHandler(followService service.FollowService) *FollowHandler {
 func (h *FollowHandler) Follow(c echo.Context) error {
 	followerUserID := middleware.GetUserID(c)
 	if followerUserID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	followedUserID := c.Param("id")
 	if followedUserID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	err := h.followService.Follow(c.Request().Context(), followerUserID, followedUserID)
@@ -49,12 +49,12 @@ func (h *FollowHandler) Follow(c echo.Context) error {
 func (h *FollowHandler) Unfollow(c echo.Context) error {
 	followerUserID := middleware.GetUserID(c)
 	if followerUserID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	followedUserID := c.Param("id")
 	if followedUserID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	err := h.followService.Unfollow(c.Request().Context(), followerUserID, followedUserID)
@@ -70,7 +70,7 @@ func (h *FollowHandler) Unfollow(c echo.Context) error {
 func (h *FollowHandler) GetFollowers(c echo.Context) error {
 	userID := c.Param("id")
 	if userID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	limit := 20
@@ -94,7 +94,7 @@ func (h *FollowHandler) GetFollowers(c echo.Context) error {
 func (h *FollowHandler) GetFollowing(c echo.Context) error {
 	userID := middleware.GetUserID(c)
 	if userID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	limit := 20
@@ -118,12 +118,12 @@ func (h *FollowHandler) GetFollowing(c echo.Context) error {
 func (h *FollowHandler) IsFollowing(c echo.Context) error {
 	followerUserID := middleware.GetUserID(c)
 	if followerUserID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	followedUserID := c.Param("id")
 	if followedUserID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	following, err := h.followService.IsFollowing(c.Request().Context(), followerUserID, followedUserID)
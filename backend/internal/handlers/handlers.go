@@ -4,18 +4,26 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/go-playground/validator/v10"
+	"github.com/gvasels/personal-music-searchengine/internal/capability"
 	"github.com/gvasels/personal-music-searchengine/internal/handlers/middleware"
+	"github.com/gvasels/personal-music-searchengine/internal/i18n"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
 	"github.com/gvasels/personal-music-searchengine/internal/service"
 	"github.com/labstack/echo/v4"
 )
 
 // Handlers contains all HTTP handlers
 type Handlers struct {
-	services *service.Services
+	services     *service.Services
+	capabilities *capability.Registry
+	dependencies *resilience.Registry
 }
 
 // NewHandlers creates a new Handlers instance
@@ -33,6 +41,47 @@ func (h *Handlers) RegisterRoutes(e *echo.Echo) {
 	// User routes
 	api.GET("/me", h.GetProfile)
 	api.PUT("/me", h.UpdateProfile)
+	api.GET("/me/searches", h.ListRecentSearches)
+	api.DELETE("/me/searches", h.ClearRecentSearches)
+	api.GET("/me/search-boosts", h.GetSearchBoosts)
+	api.POST("/me/search-boosts/pins", h.PinSearchResult)
+	api.DELETE("/me/search-boosts/pins", h.UnpinSearchResult)
+	api.PUT("/me/search-boosts/artists", h.SetArtistBoost)
+	api.DELETE("/me/search-boosts/artists", h.RemoveArtistBoost)
+	api.GET("/me/collections", h.ListCollections)
+	api.POST("/me/collections", h.CreateCollection)
+	api.PUT("/me/collections/order", h.ReorderCollections)
+	api.PATCH("/me/collections/:id", h.UpdateCollection)
+	api.DELETE("/me/collections/:id", h.DeleteCollection)
+	api.GET("/me/collections/:id/tracks", h.ListCollectionTracks)
+	api.POST("/me/collections/:id/tracks", h.AddCollectionTracks)
+	api.DELETE("/me/collections/:id/tracks", h.RemoveCollectionTracks)
+	api.GET("/me/comparisons", h.ListComparisonConsents)
+	api.POST("/me/comparisons", h.CreateComparisonConsent)
+	api.GET("/me/comparisons/:userId", h.CompareLibraries)
+	api.DELETE("/me/comparisons/:userId", h.DeleteComparisonConsent)
+	api.GET("/me/insights/archive", h.ListArchiveSuggestions)
+	api.POST("/me/insights/archive/apply", h.ApplyArchiveSuggestions, limitConcurrency(h.dependencies.Limiter(resilience.LimitBulk)))
+	api.GET("/me/player", h.GetPlayerState)
+	api.POST("/me/player/queue", h.ApplyQueueAction)
+	api.GET("/me/devices", h.ListDevices)
+	api.PUT("/me/devices/:deviceId", h.RegisterDevice)
+	api.DELETE("/me/devices/:deviceId", h.DisconnectDevice)
+	api.POST("/me/devices/:deviceId/commands", h.SendRemoteCommand)
+	api.GET("/me/devices/:deviceId/events", h.StreamRemoteCommands)
+	api.POST("/me/parties", h.CreateParty)
+	api.DELETE("/me/parties/:id", h.EndParty)
+	api.GET("/me/parties/:id/requests", h.ListPartyRequests)
+	api.POST("/me/parties/:id/requests/:requestId/approve", h.ApprovePartyRequest)
+	api.POST("/me/parties/:id/requests/:requestId/decline", h.DeclinePartyRequest)
+	api.POST("/me/rooms", h.OpenListeningRoom)
+	api.DELETE("/me/rooms/:id", h.CloseListeningRoom)
+	api.POST("/me/rooms/:id/playback", h.ControlListeningRoom)
+	api.POST("/rooms/join", h.JoinListeningRoom)
+	api.GET("/rooms/:id", h.GetListeningRoom)
+	api.GET("/rooms/:id/stream", h.GetListeningRoomStream)
+	api.GET("/rooms/:id/events", h.StreamListeningRoomEvents)
+	api.DELETE("/rooms/:id/membership", h.LeaveListeningRoom)
 	api.GET("/users/me/settings", h.GetSettings)
 	api.PATCH("/users/me/settings", h.UpdateSettings)
 	api.GET("/features", h.GetFeatures)
@@ -40,6 +89,10 @@ func (h *Handlers) RegisterRoutes(e *echo.Echo) {
 
 	// Track routes
 	api.GET("/tracks", h.ListTracks)
+	api.HEAD("/tracks", h.ListTracks)
+	api.GET("/tracks/cleanup-suggestions", h.ListCleanupSuggestions)
+	api.POST("/tracks/cleanup-suggestions/apply", h.ApplyCleanupSuggestions, limitConcurrency(h.dependencies.Limiter(resilience.LimitBulk)))
+	api.POST("/tracks/import/dj", h.ImportDJLibrary, limitConcurrency(h.dependencies.Limiter(resilience.LimitBulk)))
 	api.GET("/tracks/:id", h.GetTrack)
 	api.PUT("/tracks/:id", h.UpdateTrack)
 	api.DELETE("/tracks/:id", h.DeleteTrack)
@@ -47,9 +100,39 @@ func (h *Handlers) RegisterRoutes(e *echo.Echo) {
 	api.DELETE("/tracks/:id/tags/:tag", h.RemoveTagFromTrack)
 	api.PUT("/tracks/:id/cover", h.UploadCoverArt)
 	api.PUT("/tracks/:id/visibility", h.UpdateTrackVisibility)
+	api.POST("/tracks/:id/replace-file", h.ReplaceTrackFile)
+	api.PUT("/tracks/:id/lock", h.UpdateTrackLock)
+	api.PATCH("/tracks/:id/analysis", h.UpdateTrackAnalysis)
+	api.PATCH("/tracks/:id/playback-settings", h.UpdateTrackPlaybackSettings)
+	api.PUT("/tracks/:id/download-protection", h.SetDownloadProtection)
+	api.GET("/tracks/:id/downloads", h.ListTrackDownloads)
+	api.GET("/tracks/:id/downloads/:token", h.TraceTrackDownload)
+	api.GET("/tracks/:id/access-log", h.ListTrackAccessLog)
+	api.GET("/tracks/:id/export/dj", h.ExportTrackForDJ)
+	api.POST("/tracks/:id/share", h.ShareTrack)
+	api.GET("/tracks/:id/preview", h.GetPreviewLink)
+
+	// Household routes
+	api.GET("/household", h.GetHousehold)
+	api.POST("/household", h.CreateHousehold)
+	api.PUT("/household", h.UpdateHousehold)
+	api.DELETE("/household", h.DeleteHousehold)
+	api.POST("/household/members", h.AddHouseholdMember)
+	api.PUT("/household/members/:userId/role", h.UpdateHouseholdMemberRole)
+	api.DELETE("/household/members/:userId", h.RemoveHouseholdMember)
+
+	// Share routes
+	api.GET("/shares", h.ListReceivedShares)
+	api.GET("/shares/sent", h.ListSentShares)
+	api.POST("/shares/:id/accept", h.AcceptShare)
+	api.POST("/shares/:id/decline", h.DeclineShare)
+
+	// Operation routes: undo shares the bulk limit with the edits it reverts
+	api.POST("/operations/:id/undo", h.UndoOperation, limitConcurrency(h.dependencies.Limiter(resilience.LimitBulk)))
 
 	// Album routes
 	api.GET("/albums", h.ListAlbums)
+	api.HEAD("/albums", h.ListAlbums)
 	api.GET("/albums/:id", h.GetAlbum)
 
 	// Artist routes (legacy name-based)
@@ -69,6 +152,7 @@ func (h *Handlers) RegisterRoutes(e *echo.Echo) {
 
 	// Playlist routes
 	api.GET("/playlists", h.ListPlaylists)
+	api.HEAD("/playlists", h.ListPlaylists)
 	api.POST("/playlists", h.CreatePlaylist)
 	api.GET("/playlists/public", h.ListPublicPlaylists) // Public playlist discovery
 	api.GET("/playlists/:id", h.GetPlaylist)
@@ -78,6 +162,7 @@ func (h *Handlers) RegisterRoutes(e *echo.Echo) {
 	api.DELETE("/playlists/:id/tracks", h.RemoveTracksFromPlaylist)
 	api.PUT("/playlists/:id/reorder", h.ReorderPlaylistTracks)
 	api.PUT("/playlists/:id/visibility", h.UpdatePlaylistVisibility)
+	api.GET("/playlists/:id/export/dj", h.ExportPlaylistForDJ)
 
 	// Tag routes
 	api.GET("/tags", h.ListTags)
@@ -89,20 +174,43 @@ func (h *Handlers) RegisterRoutes(e *echo.Echo) {
 
 	// Upload routes
 	api.POST("/upload/presigned", h.CreatePresignedUpload)
-	api.POST("/upload/confirm", h.ConfirmUpload)
-	api.POST("/upload/complete-multipart", h.CompleteMultipartUpload)
+	api.POST("/upload/confirm", h.ConfirmUpload, h.requireCapability(capability.UploadProcessing))
+	api.POST("/upload/complete-multipart", h.CompleteMultipartUpload, h.requireCapability(capability.UploadProcessing))
+	api.POST("/uploads/precheck", h.PrecheckUpload)
 	api.GET("/uploads", h.ListUploads)
 	api.GET("/uploads/:id", h.GetUploadStatus)
-	api.POST("/uploads/:id/reprocess", h.ReprocessUpload)
+	api.POST("/uploads/:id/reprocess", h.ReprocessUpload, h.requireCapability(capability.UploadProcessing))
+	api.POST("/uploads/:id/encoding-fixes", h.ApplyEncodingFixes)
 
 	// Streaming routes
 	api.GET("/stream/:trackId", h.GetStreamURL)
 	api.GET("/download/:trackId", h.GetDownloadURL)
+	api.GET("/download/:trackId/watermarked/:token", h.GetWatermarkedDownload)
+
+	// Discover feed (featured content merged with public listings)
+	api.GET("/discover", h.GetDiscoverFeed)
 
 	// Search routes
-	api.GET("/search", h.SimpleSearch)
-	api.POST("/search", h.AdvancedSearch)
-	api.GET("/search/autocomplete", h.Autocomplete)
+	api.GET("/search", h.SimpleSearch, h.requireCapability(capability.Search))
+	api.POST("/search", h.AdvancedSearch, h.requireCapability(capability.Search))
+	api.GET("/search/autocomplete", h.Autocomplete, h.requireCapability(capability.Search))
+	api.GET("/search/all", h.QuickSearch, h.requireCapability(capability.Search))
+
+	// Admin routes are registered separately when the admin service is configured
+	if h.services.Admin == nil {
+		api.Any("/admin/*", h.capabilityUnavailable(capability.Admin))
+	}
+
+	// Operator status (no auth required, like /health)
+	e.GET("/status", h.GetStatus)
+
+	// Preview share links (no auth required; the signed token is the credential)
+	e.GET("/api/v1/previews/:token", h.OpenPreview)
+
+	// Party links (no auth required; the signed token is the credential)
+	e.GET("/api/v1/parties/:token", h.OpenParty)
+	e.GET("/api/v1/parties/:token/tracks", h.SearchPartyTracks)
+	e.POST("/api/v1/parties/:token/requests", h.RequestPartyTrack)
 }
 
 // RegisterAdminRoutes registers admin routes with proper middleware protection.
@@ -117,6 +225,37 @@ func RegisterAdminRoutes(e *echo.Echo, adminHandler *AdminHandler, roleResolver
 	admin.GET("/users/:id", adminHandler.GetUserDetails)      // Get user details
 	admin.PUT("/users/:id/role", adminHandler.UpdateUserRole) // Update user role
 	admin.PUT("/users/:id/status", adminHandler.UpdateUserStatus) // Enable/disable user
+
+	// Bulk operations share the bulk concurrency limit
+	admin.POST("/users/import", adminHandler.ImportUsers, limitConcurrency(adminHandler.bulk))
+
+	// Data migration progress
+	admin.GET("/migrations", adminHandler.ListMigrations)
+
+	// Search index capacity
+	admin.GET("/search/stats", adminHandler.GetSearchIndexStats)
+	admin.GET("/search/stuck-transcodes", adminHandler.ListStuckTranscodes)
+
+	// Search index export and import, for moving the index between deployments
+	admin.POST("/search/export", adminHandler.ExportSearchIndex, limitConcurrency(adminHandler.bulk))
+	admin.POST("/search/import", adminHandler.ImportSearchIndex, limitConcurrency(adminHandler.bulk))
+
+	// Track ownership transfer
+	admin.POST("/tracks/:trackId/transfer", adminHandler.TransferTrack, limitConcurrency(adminHandler.bulk))
+
+	// Content moderation review queue
+	admin.GET("/moderation", adminHandler.ListModerationReviews)
+	admin.POST("/moderation/:trackId/approve", adminHandler.ApproveModerationReview)
+	admin.POST("/moderation/:trackId/reject", adminHandler.RejectModerationReview)
+
+	// Featured content of the discover feed
+	admin.GET("/featured", adminHandler.ListFeaturedItems)
+	admin.POST("/featured", adminHandler.CreateFeaturedItem)
+	admin.PUT("/featured/:id", adminHandler.UpdateFeaturedItem)
+	admin.DELETE("/featured/:id", adminHandler.DeleteFeaturedItem)
+
+	// Support's debug bundle of an upload, next to the user upload routes
+	e.GET("/api/v1/uploads/:id/debug", adminHandler.GetUploadDebugBundle, middleware.RequireRoleWithDBCheck(models.RoleAdmin, roleResolver))
 }
 
 // AuthContext contains user authentication and permission information
@@ -238,16 +377,28 @@ func (h *Handlers) getAuthContextWithDBRole(c echo.Context) AuthContext {
 	return ctx
 }
 
-// handleError converts errors to appropriate HTTP responses
+// handleError converts errors to appropriate HTTP responses, with messages
+// in the request's language (see middleware.Language)
 func handleError(c echo.Context, err error) error {
 	// Check for APIError
 	var apiErr *models.APIError
 	if errors.As(err, &apiErr) {
-		return c.JSON(apiErr.StatusCode, models.NewErrorResponse(apiErr))
+		if seconds, ok := apiErr.MessageArgs["seconds"]; ok && apiErr.StatusCode == http.StatusTooManyRequests {
+			c.Response().Header().Set("Retry-After", seconds)
+		}
+		lang := i18n.FromContext(c.Request().Context())
+		return c.JSON(apiErr.StatusCode, models.NewErrorResponse(apiErr.Localize(lang)))
+	}
+
+	// Operations turned away by a concurrency limit can be retried shortly
+	var limitErr *resilience.LimitError
+	if errors.As(err, &limitErr) {
+		c.Response().Header().Set("Retry-After", "5")
+		return handleError(c, models.NewTooBusyError(limitErr.Name))
 	}
 
 	// Default to internal server error
-	return c.JSON(http.StatusInternalServerError, models.NewErrorResponse(models.ErrInternalServer))
+	return handleError(c, models.ErrInternalServer)
 }
 
 // bindAndValidate binds the request body and validates it
@@ -257,17 +408,72 @@ func bindAndValidate(c echo.Context, v interface{}) error {
 	}
 
 	if err := c.Validate(v); err != nil {
-		return models.NewValidationError(err.Error())
+		return models.NewValidationError(validationDetails(c, err))
 	}
 
 	return nil
 }
 
+// validationDetails describes the fields that failed validation in the
+// request's language, one sentence per field
+func validationDetails(c echo.Context, err error) string {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err.Error()
+	}
+	lang := i18n.FromContext(c.Request().Context())
+	messages := make([]string, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		kind := ""
+		switch fe.Kind() {
+		case reflect.String:
+			kind = "string"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			kind = "items"
+		}
+		messages = append(messages, i18n.FieldError(lang, fe.Field(), fe.Tag(), fe.Param(), kind))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// bindDryRun sets *dryRun when the query asks for a dry run (?dryRun=true).
+// Destructive endpoints accept it alongside their dryRun body field; a dry run
+// plans the change and reports exactly what it would affect without saving.
+func bindDryRun(c echo.Context, dryRun *bool) error {
+	value := c.QueryParam("dryRun")
+	if value == "" {
+		return nil
+	}
+	requested, err := strconv.ParseBool(value)
+	if err != nil {
+		details, _ := i18n.Text(i18n.FromContext(c.Request().Context()), "validation.dryRun", nil)
+		return models.NewValidationError(details)
+	}
+	*dryRun = *dryRun || requested
+	return nil
+}
+
 // success returns a JSON success response
 func success(c echo.Context, data interface{}) error {
 	return c.JSON(http.StatusOK, data)
 }
 
+// wantsCount reports whether a list request asks only for the total: a HEAD
+// request, or a GET with countOnly=true
+func wantsCount(c echo.Context, countOnly bool) bool {
+	return countOnly || c.Request().Method == http.MethodHead
+}
+
+// successCount returns a list total in the X-Total-Count header, with a
+// CountResponse body unless the request is a HEAD
+func successCount(c echo.Context, count int) error {
+	c.Response().Header().Set(models.TotalCountHeader, strconv.Itoa(count))
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(http.StatusOK)
+	}
+	return c.JSON(http.StatusOK, models.CountResponse{Count: count})
+}
+
 // ListResponse wraps a slice in a list response with items array
 type ListResponse[T any] struct {
 	Items []T `json:"items"`
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetHousehold returns the current user's household and its members
+func (h *Handlers) GetHousehold(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	household, err := h.services.Household.GetHousehold(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, household)
+}
+
+// CreateHousehold creates a household owned by the current user
+func (h *Handlers) CreateHousehold(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var req models.CreateHouseholdRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	household, err := h.services.Household.CreateHousehold(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, household)
+}
+
+// UpdateHousehold renames the current user's household
+func (h *Handlers) UpdateHousehold(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var req models.UpdateHouseholdRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	household, err := h.services.Household.UpdateHousehold(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, household)
+}
+
+// DeleteHousehold dissolves the current user's household; with ?dryRun=true
+// it reports the members it would release without dissolving it
+func (h *Handlers) DeleteHousehold(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var dryRun bool
+	if err := bindDryRun(c, &dryRun); err != nil {
+		return handleError(c, err)
+	}
+
+	result, err := h.services.Household.DeleteHousehold(c.Request().Context(), userID, dryRun)
+	if err != nil {
+		return handleError(c, err)
+	}
+	if result.DryRun {
+		return success(c, result)
+	}
+
+	return noContent(c)
+}
+
+// AddHouseholdMember attaches a user to the current user's household
+func (h *Handlers) AddHouseholdMember(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var req models.AddHouseholdMemberRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	member, err := h.services.Household.AddMember(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, member)
+}
+
+// UpdateHouseholdMemberRole changes a household member's role
+func (h *Handlers) UpdateHouseholdMemberRole(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	memberID := c.Param("userId")
+	if memberID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.UpdateHouseholdMemberRoleRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	member, err := h.services.Household.UpdateMemberRole(c.Request().Context(), userID, memberID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, member)
+}
+
+// RemoveHouseholdMember removes a member from the household (or leaves it when removing yourself)
+func (h *Handlers) RemoveHouseholdMember(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	memberID := c.Param("userId")
+	if memberID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+	if memberID == "me" {
+		memberID = userID
+	}
+
+	if err := h.services.Household.RemoveMember(c.Request().Context(), userID, memberID); err != nil {
+		return handleError(c, err)
+	}
+
+	return noContent(c)
+}
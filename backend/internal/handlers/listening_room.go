@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// OpenListeningRoom opens a listening room on the current user's play queue
+// and returns the invite listeners join it with
+// POST /api/v1/me/rooms
+func (h *Handlers) OpenListeningRoom(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Rooms == nil {
+		return handleError(c, roomsUnavailable())
+	}
+
+	var req models.CreateListeningRoomRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	invite, err := h.services.Rooms.Open(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, invite)
+}
+
+// CloseListeningRoom closes one of the current user's rooms for everyone in it
+// DELETE /api/v1/me/rooms/:id
+func (h *Handlers) CloseListeningRoom(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Rooms == nil {
+		return handleError(c, roomsUnavailable())
+	}
+
+	if err := h.services.Rooms.Close(c.Request().Context(), userID, c.Param("id")); err != nil {
+		return handleError(c, err)
+	}
+
+	return noContent(c)
+}
+
+// ControlListeningRoom plays, pauses, seeks or changes the track of one of the
+// current user's rooms. A change made against an outdated version fails with
+// 409 and the current state.
+// POST /api/v1/me/rooms/:id/playback
+func (h *Handlers) ControlListeningRoom(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Rooms == nil {
+		return handleError(c, roomsUnavailable())
+	}
+
+	var req models.RoomPlaybackRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	state, err := h.services.Rooms.Control(c.Request().Context(), userID, c.Param("id"), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, state)
+}
+
+// JoinListeningRoom joins the room an invite token points at
+// POST /api/v1/rooms/join
+func (h *Handlers) JoinListeningRoom(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Rooms == nil {
+		return handleError(c, roomsUnavailable())
+	}
+
+	var req models.JoinListeningRoomRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	state, err := h.services.Rooms.Join(c.Request().Context(), userID, req.Token)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, state)
+}
+
+// LeaveListeningRoom leaves a room the current user joined
+// DELETE /api/v1/rooms/:id/membership
+func (h *Handlers) LeaveListeningRoom(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Rooms == nil {
+		return handleError(c, roomsUnavailable())
+	}
+
+	if err := h.services.Rooms.Leave(c.Request().Context(), userID, c.Param("id")); err != nil {
+		return handleError(c, err)
+	}
+
+	return noContent(c)
+}
+
+// GetListeningRoom returns where a room's playback is now
+// GET /api/v1/rooms/:id
+func (h *Handlers) GetListeningRoom(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Rooms == nil {
+		return handleError(c, roomsUnavailable())
+	}
+
+	state, err := h.services.Rooms.State(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, state)
+}
+
+// GetListeningRoomStream returns a stream URL for the track a room is playing
+// GET /api/v1/rooms/:id/stream
+func (h *Handlers) GetListeningRoomStream(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Rooms == nil {
+		return handleError(c, roomsUnavailable())
+	}
+
+	stream, err := h.services.Rooms.Stream(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, stream)
+}
+
+// StreamListeningRoomEvents delivers a room's state as server-sent "state"
+// events whenever its version moves past ?version= (at once without it). Like
+// the remote control stream it ends after remoteStreamDuration with a retry
+// hint, and early once a state is delivered, so behind API Gateway it works
+// as a long poll.
+// GET /api/v1/rooms/:id/events
+func (h *Handlers) StreamListeningRoomEvents(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Rooms == nil {
+		return handleError(c, roomsUnavailable())
+	}
+
+	seen := int64(-1)
+	if v := c.QueryParam("version"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return handleError(c, models.NewValidationError("version must be a number"))
+		}
+		seen = parsed
+	}
+
+	ctx := c.Request().Context()
+	roomID := c.Param("id")
+	state, err := h.services.Rooms.State(ctx, userID, roomID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	c.Response().Header().Set("Content-Type", "text/event-stream")
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	c.Response().Header().Set("Connection", "keep-alive")
+	c.Response().WriteHeader(http.StatusOK)
+	fmt.Fprintf(c.Response(), "retry: %d\n\n", remoteRetryMs)
+	c.Response().Flush()
+
+	deadline := time.NewTimer(remoteStreamDuration)
+	defer deadline.Stop()
+	poll := time.NewTicker(remotePollInterval)
+	defer poll.Stop()
+
+	for {
+		if state.Version != seen {
+			data, _ := json.Marshal(state)
+			fmt.Fprintf(c.Response(), "id: %d\nevent: state\ndata: %s\n\n", state.Version, data)
+			c.Response().Flush()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-deadline.C:
+			return nil
+		case <-poll.C:
+			fmt.Fprint(c.Response(), ": keep-alive\n\n")
+			c.Response().Flush()
+		}
+
+		state, err = h.services.Rooms.State(ctx, userID, roomID)
+		if err != nil {
+			// Headers are sent; a closed room is told as an event, anything
+			// else ends the stream and the client reconnects
+			var apiErr *models.APIError
+			if errors.As(err, &apiErr) {
+				data, _ := json.Marshal(apiErr)
+				fmt.Fprintf(c.Response(), "event: error\ndata: %s\n\n", data)
+				c.Response().Flush()
+			}
+			return nil
+		}
+	}
+}
+
+func roomsUnavailable() error {
+	return models.NewServiceUnavailableError("listening rooms", "listening rooms are not configured")
+}
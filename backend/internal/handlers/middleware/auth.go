@@ -155,7 +155,15 @@ func extractAuthFromContext(c echo.Context) (string, models.UserRole, []string)
 		}
 	}
 
-	// Fall back to headers for local development/testing
+	// Then a bearer token verified by the server itself (self-hosted)
+	if claims, ok := TokenClaimsFromContext(c.Request().Context()); ok && userID == "" {
+		userID = claims.Subject
+		groups = claims.Groups
+		role = roleFromGroups(groups)
+	}
+
+	// Fall back to headers for local development/testing; VerifyBearerToken
+	// removes them when the server checks tokens itself
 	if userID == "" {
 		userID = c.Request().Header.Get("X-User-ID")
 	}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/labstack/echo/v4"
+)
+
+// ClientIP returns the IPExtractor behind c.RealIP(), which rate limits and
+// access logs key on. It only believes addresses something trusted reports:
+// behind API Gateway, the request context's source IP, which the gateway
+// takes from the connection, since X-Forwarded-For arrives as the client sent
+// it. Otherwise it uses the connection's address or, behind proxies in
+// trustedProxies, the nearest X-Forwarded-For address that is not one of them.
+func ClientIP(trustedProxies []*net.IPNet) echo.IPExtractor {
+	fallback := echo.ExtractIPDirect()
+	if len(trustedProxies) > 0 {
+		// Only the listed proxies, not every private address
+		options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+		for _, proxies := range trustedProxies {
+			options = append(options, echo.TrustIPRange(proxies))
+		}
+		fallback = echo.ExtractIPFromXFFHeader(options...)
+	}
+
+	return func(req *http.Request) string {
+		if requestCtx, ok := core.GetAPIGatewayV2ContextFromContext(req.Context()); ok && requestCtx.HTTP.SourceIP != "" {
+			return requestCtx.HTTP.SourceIP
+		}
+		return fallback(req)
+	}
+}
+
+// ParseTrustedProxies parses a comma-separated list of proxy addresses and
+// CIDR ranges, e.g. "10.0.0.0/8, 192.168.1.10"
+func ParseTrustedProxies(raw string) ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy range %q", entry)
+		}
+		proxies = append(proxies, ipNet)
+	}
+	return proxies, nil
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	direct := func(remoteAddr, forwardedFor string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		return req
+	}
+
+	t.Run("behind API Gateway uses the source IP, not the forwarded header", func(t *testing.T) {
+		accessor := core.RequestAccessorV2{}
+		req, err := accessor.EventToRequestWithContext(context.Background(), events.APIGatewayV2HTTPRequest{
+			RawPath: "/api/v1/party/abc/requests",
+			Headers: map[string]string{"x-forwarded-for": "198.51.100.7, 203.0.113.9"},
+			RequestContext: events.APIGatewayV2HTTPRequestContext{
+				HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodPost, SourceIP: "203.0.113.9"},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "203.0.113.9", ClientIP(nil)(req))
+	})
+
+	t.Run("without trusted proxies uses the connection address", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.9", ClientIP(nil)(direct("203.0.113.9:51234", "198.51.100.7")))
+		assert.Equal(t, "10.0.0.2", ClientIP(nil)(direct("10.0.0.2:51234", "198.51.100.7")), "private addresses are not trusted by default")
+	})
+
+	t.Run("behind trusted proxies uses the nearest untrusted forwarded address", func(t *testing.T) {
+		proxies, err := ParseTrustedProxies("10.0.0.0/8")
+		require.NoError(t, err)
+		extract := ClientIP(proxies)
+
+		// The client prepended a forged address; the proxy appended the real one
+		assert.Equal(t, "203.0.113.9", extract(direct("10.0.0.2:51234", "198.51.100.7, 203.0.113.9")))
+		// A connection that is not from a proxy is taken at its word only
+		assert.Equal(t, "192.168.1.5", extract(direct("192.168.1.5:51234", "198.51.100.7")))
+	})
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies(" 10.0.0.0/8, 192.168.1.10,,fd00::1 ")
+	require.NoError(t, err)
+	require.Len(t, proxies, 3)
+	assert.Equal(t, "10.0.0.0/8", proxies[0].String())
+	assert.Equal(t, "192.168.1.10/32", proxies[1].String())
+	assert.Equal(t, "fd00::1/128", proxies[2].String())
+
+	proxies, err = ParseTrustedProxies("")
+	require.NoError(t, err)
+	assert.Empty(t, proxies)
+
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies("proxy.internal")
+	assert.Error(t, err)
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WithRequestContext derives each request's context with wrap, for request-
+// scoped values such as the user cache (service.WithRequestUserCache).
+func WithRequestContext(wrap func(context.Context) context.Context) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.SetRequest(c.Request().WithContext(wrap(c.Request().Context())))
+			return next(c)
+		}
+	}
+}
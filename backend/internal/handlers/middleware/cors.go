@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+)
+
+// DefaultCORSAllowHeaders are the request headers browsers may send cross-origin
+var DefaultCORSAllowHeaders = []string{echo.HeaderAuthorization, echo.HeaderContentType, "X-User-ID", echo.HeaderXCSRFToken}
+
+// DefaultCORSExposeHeaders are the response headers browser code may read
+var DefaultCORSExposeHeaders = []string{models.TotalCountHeader, echo.HeaderXRequestID}
+
+// CORSConfig is the API's cross-origin policy
+type CORSConfig struct {
+	// AllowOrigins lists the origins that may call the API, e.g.
+	// "https://music.example.com"; "*" allows any origin and "https://*.example.com"
+	// any subdomain. Empty allows none: no CORS headers are sent at all.
+	AllowOrigins []string
+	// AllowCredentials lets browsers send cookies and Authorization headers.
+	// It is ignored when AllowOrigins contains "*".
+	AllowCredentials bool
+	// AllowHeaders defaults to DefaultCORSAllowHeaders
+	AllowHeaders []string
+	// ExposeHeaders defaults to DefaultCORSExposeHeaders
+	ExposeHeaders []string
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+	// RouteMethods restricts the methods cross-origin callers may use under
+	// a path prefix; the longest matching prefix wins. Other routes allow the
+	// methods they are registered with.
+	RouteMethods []CORSRouteMethods
+}
+
+// CORSRouteMethods allows only Methods on requests under Prefix
+type CORSRouteMethods struct {
+	Prefix  string
+	Methods []string
+}
+
+// corsRoute is a CORSRouteMethods with its own preflight responder
+type corsRoute struct {
+	CORSRouteMethods
+	cors echo.MiddlewareFunc
+}
+
+// CORS applies cfg. Preflights on restricted routes advertise only the
+// allowed methods, and actual cross-origin requests with other methods are
+// rejected with 405, since simple requests (e.g. form POSTs) are sent by the
+// browser without a preflight.
+func CORS(cfg CORSConfig) echo.MiddlewareFunc {
+	if len(cfg.AllowOrigins) == 0 {
+		// Without CORS headers browsers keep the API same-origin only
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+
+	base := echomw.CORSConfig{
+		AllowOrigins:     cfg.AllowOrigins,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    cfg.ExposeHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           int(cfg.MaxAge.Seconds()),
+	}
+	if len(base.AllowHeaders) == 0 {
+		base.AllowHeaders = DefaultCORSAllowHeaders
+	}
+	if len(base.ExposeHeaders) == 0 {
+		base.ExposeHeaders = DefaultCORSExposeHeaders
+	}
+	for _, origin := range cfg.AllowOrigins {
+		if origin == "*" {
+			base.AllowCredentials = false
+		}
+	}
+
+	routes := make([]corsRoute, 0, len(cfg.RouteMethods))
+	for _, rm := range cfg.RouteMethods {
+		routeCfg := base
+		routeCfg.AllowMethods = rm.Methods
+		routes = append(routes, corsRoute{CORSRouteMethods: rm, cors: echomw.CORSWithConfig(routeCfg)})
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].Prefix) > len(routes[j].Prefix)
+	})
+	defaultCORS := echomw.CORSWithConfig(base)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		defaultHandler := defaultCORS(next)
+		routeHandlers := make([]echo.HandlerFunc, len(routes))
+		for i, route := range routes {
+			routeHandlers[i] = route.cors(next)
+		}
+
+		return func(c echo.Context) error {
+			req := c.Request()
+			for i, route := range routes {
+				if !strings.HasPrefix(req.URL.Path, route.Prefix) {
+					continue
+				}
+				if req.Method != http.MethodOptions && isCrossOrigin(req) && !containsMethod(route.Methods, req.Method) {
+					return echo.NewHTTPError(http.StatusMethodNotAllowed, "method not allowed for cross-origin requests")
+				}
+				return routeHandlers[i](c)
+			}
+			return defaultHandler(c)
+		}
+	}
+}
+
+// ParseCORSOrigins parses a comma-separated origin list
+func ParseCORSOrigins(raw string) []string {
+	var origins []string
+	for _, origin := range strings.Split(raw, ",") {
+		if origin = strings.TrimSuffix(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// ParseCORSRouteMethods parses "/prefix=GET,HEAD;/prefix2=POST" into route
+// restrictions; rules without a prefix or methods are skipped
+func ParseCORSRouteMethods(raw string) []CORSRouteMethods {
+	var routes []CORSRouteMethods
+	for _, rule := range strings.Split(raw, ";") {
+		prefix, methods, ok := strings.Cut(strings.TrimSpace(rule), "=")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || prefix == "" {
+			continue
+		}
+		route := CORSRouteMethods{Prefix: prefix}
+		for _, method := range strings.Split(methods, ",") {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+				route.Methods = append(route.Methods, method)
+			}
+		}
+		if len(route.Methods) > 0 {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// isCrossOrigin reports whether the request comes from another origin than
+// the API's host; browsers also send Origin on same-origin POSTs
+func isCrossOrigin(req *http.Request) bool {
+	origin := req.Header.Get(echo.HeaderOrigin)
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return true
+	}
+	return !strings.EqualFold(u.Host, req.Host)
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
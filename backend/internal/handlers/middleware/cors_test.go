@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSTestServer(cfg CORSConfig) *echo.Echo {
+	e := echo.New()
+	e.Use(CORS(cfg))
+	ok := func(c echo.Context) error {
+		c.Response().Header().Set("X-Total-Count", "3")
+		return c.String(http.StatusOK, "OK")
+	}
+	e.GET("/api/v1/tracks", ok)
+	e.POST("/api/v1/tracks", ok)
+	e.GET("/api/v1/admin/users", ok)
+	e.DELETE("/api/v1/admin/users", ok)
+	return e
+}
+
+func serveCORS(e *echo.Echo, method, path, origin string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Host = "api.example.com"
+	if origin != "" {
+		req.Header.Set(echo.HeaderOrigin, origin)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORS(t *testing.T) {
+	cfg := CORSConfig{
+		AllowOrigins:     []string{"https://music.example.com"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+		RouteMethods:     []CORSRouteMethods{{Prefix: "/api/v1/admin", Methods: []string{http.MethodGet}}},
+	}
+
+	t.Run("allows listed origins with credentials and exposed headers", func(t *testing.T) {
+		rec := serveCORS(newCORSTestServer(cfg), http.MethodGet, "/api/v1/tracks", "https://music.example.com", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://music.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Equal(t, "true", rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+		assert.Contains(t, rec.Header().Get(echo.HeaderAccessControlExposeHeaders), "X-Total-Count")
+	})
+
+	t.Run("sends no CORS headers to other origins", func(t *testing.T) {
+		rec := serveCORS(newCORSTestServer(cfg), http.MethodGet, "/api/v1/tracks", "https://evil.example.net", nil)
+
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+
+	t.Run("preflights advertise the restricted methods", func(t *testing.T) {
+		rec := serveCORS(newCORSTestServer(cfg), http.MethodOptions, "/api/v1/admin/users", "https://music.example.com",
+			map[string]string{echo.HeaderAccessControlRequestMethod: http.MethodDelete})
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, http.MethodGet, rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+		assert.Equal(t, "3600", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+	})
+
+	t.Run("rejects cross-origin requests with restricted methods", func(t *testing.T) {
+		e := newCORSTestServer(cfg)
+
+		rec := serveCORS(e, http.MethodDelete, "/api/v1/admin/users", "https://music.example.com", nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+		rec = serveCORS(e, http.MethodDelete, "/api/v1/admin/users", "https://api.example.com", nil)
+		assert.Equal(t, http.StatusOK, rec.Code, "same-origin requests are not restricted")
+
+		rec = serveCORS(e, http.MethodPost, "/api/v1/tracks", "https://music.example.com", nil)
+		assert.Equal(t, http.StatusOK, rec.Code, "unrestricted routes allow their methods")
+	})
+
+	t.Run("without origins no CORS headers are sent", func(t *testing.T) {
+		rec := serveCORS(newCORSTestServer(CORSConfig{}), http.MethodGet, "/api/v1/tracks", "https://music.example.com", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	})
+}
+
+func TestParseCORSRouteMethods(t *testing.T) {
+	routes := ParseCORSRouteMethods(" /api/v1/admin = get, head ;/api/v1/upload=POST;broken;/empty=")
+
+	assert.Equal(t, []CORSRouteMethods{
+		{Prefix: "/api/v1/admin", Methods: []string{"GET", "HEAD"}},
+		{Prefix: "/api/v1/upload", Methods: []string{"POST"}},
+	}, routes)
+}
+
+func TestParseCORSOrigins(t *testing.T) {
+	assert.Equal(t, []string{"http://localhost:5173", "https://music.example.com"},
+		ParseCORSOrigins("http://localhost:5173, https://music.example.com/,"))
+	assert.Empty(t, ParseCORSOrigins(""))
+}
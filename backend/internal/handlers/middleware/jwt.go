@@ -0,0 +1,376 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Token verification errors
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+const (
+	// tokenLeeway tolerates clock skew between the server and the issuer
+	tokenLeeway = time.Minute
+	// jwksMaxAge is how long fetched signing keys are used before refetching
+	jwksMaxAge = time.Hour
+	// jwksMinRefresh bounds how often an unknown key ID refetches the keys,
+	// so forged tokens cannot make every request call the issuer
+	jwksMinRefresh = time.Minute
+)
+
+// TokenClaims are the verified claims of a bearer token
+type TokenClaims struct {
+	Subject string
+	Email   string
+	Name    string
+	// Groups are the user's groups, from cognito:groups or groups
+	Groups []string
+	// Extra holds the token's other string claims, such as custom:tenant_id
+	Extra map[string]string
+}
+
+// TokenVerifier verifies RS256 bearer tokens of an OpenID Connect issuer
+// against the keys it publishes as a JWKS, for servers with no API Gateway
+// authorizer in front of them
+type TokenVerifier struct {
+	issuer   string
+	audience string
+	jwksURL  string
+	client   *http.Client
+	now      func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+	fetch     *keyFetch
+}
+
+// NewTokenVerifier creates a verifier accepting tokens of issuer signed with
+// the keys at jwksURL. An empty audience accepts tokens issued to any client.
+func NewTokenVerifier(issuer, audience, jwksURL string) *TokenVerifier {
+	return &TokenVerifier{
+		issuer:   issuer,
+		audience: audience,
+		jwksURL:  jwksURL,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      time.Now,
+	}
+}
+
+// tokenHeader is the JOSE header of a token
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// tokenPayload holds the registered and user claims the verifier reads
+type tokenPayload struct {
+	Issuer        string      `json:"iss"`
+	Subject       string      `json:"sub"`
+	Audience      audience    `json:"aud"`
+	ClientID      string      `json:"client_id"`
+	ExpiresAt     json.Number `json:"exp"`
+	NotBefore     json.Number `json:"nbf"`
+	Email         string      `json:"email"`
+	Name          string      `json:"name"`
+	Username      string      `json:"preferred_username"`
+	CognitoGroups []string    `json:"cognito:groups"`
+	Groups        []string    `json:"groups"`
+}
+
+// payloadClaims are the claims tokenPayload reads, left out of TokenClaims.Extra
+var payloadClaims = map[string]bool{
+	"iss": true, "sub": true, "aud": true, "client_id": true, "exp": true, "nbf": true,
+	"email": true, "name": true, "preferred_username": true, "cognito:groups": true, "groups": true,
+}
+
+// audience is the aud claim, a single string or an array of them
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Verify checks token's signature, issuer, audience and validity period and
+// returns its claims
+func (v *TokenVerifier) Verify(ctx context.Context, token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: signature", ErrInvalidToken)
+	}
+
+	var payload tokenPayload
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if payload.Issuer != v.issuer {
+		return nil, fmt.Errorf("%w: issuer %q", ErrInvalidToken, payload.Issuer)
+	}
+	if payload.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidToken)
+	}
+	// Cognito access tokens name their client in client_id rather than aud
+	if v.audience != "" && !containsString(payload.Audience, v.audience) && payload.ClientID != v.audience {
+		return nil, fmt.Errorf("%w: audience", ErrInvalidToken)
+	}
+	now := v.now()
+	exp, err := payload.ExpiresAt.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("%w: no expiry", ErrInvalidToken)
+	}
+	if now.After(time.Unix(exp, 0).Add(tokenLeeway)) {
+		return nil, ErrTokenExpired
+	}
+	if nbf, err := payload.NotBefore.Int64(); err == nil && now.Add(tokenLeeway).Before(time.Unix(nbf, 0)) {
+		return nil, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+
+	claims := &TokenClaims{
+		Subject: payload.Subject,
+		Email:   payload.Email,
+		Name:    payload.Name,
+		Groups:  payload.CognitoGroups,
+	}
+	if claims.Name == "" {
+		claims.Name = payload.Username
+	}
+	if len(claims.Groups) == 0 {
+		claims.Groups = payload.Groups
+	}
+	var all map[string]interface{}
+	if err := decodeSegment(parts[1], &all); err == nil {
+		for name, value := range all {
+			if value, ok := value.(string); ok && !payloadClaims[name] {
+				if claims.Extra == nil {
+					claims.Extra = make(map[string]string)
+				}
+				claims.Extra[name] = value
+			}
+		}
+	}
+	return claims, nil
+}
+
+// key returns the signing key kid, fetching the issuer's keys when they are
+// stale or do not have it
+func (v *TokenVerifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	age := v.now().Sub(v.fetchedAt)
+	key, ok := v.keys[kid]
+	if ok && age < jwksMaxAge {
+		v.mu.Unlock()
+		return key, nil
+	}
+	if !ok && v.keys != nil && age < jwksMinRefresh {
+		v.mu.Unlock()
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+
+	// One request fetches the keys, outside the lock, and any others
+	// arriving meanwhile wait for its result
+	fetch := v.fetch
+	if fetch == nil {
+		fetch = &keyFetch{done: make(chan struct{})}
+		v.fetch = fetch
+		v.mu.Unlock()
+
+		keys, err := v.fetchKeys(ctx)
+		v.mu.Lock()
+		if err == nil {
+			v.keys = keys
+			v.fetchedAt = v.now()
+		}
+		fetch.err = err
+		v.fetch = nil
+		v.mu.Unlock()
+		close(fetch.done)
+	} else {
+		v.mu.Unlock()
+		select {
+		case <-fetch.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if fetch.err != nil {
+		// Keep verifying with the keys we have while the issuer is down
+		if ok {
+			return key, nil
+		}
+		return nil, fetch.err
+	}
+	v.mu.Lock()
+	key, ok = v.keys[kid]
+	v.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// keyFetch is a fetch of the issuer's keys that concurrent requests share
+type keyFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// jwks is a JSON Web Key Set
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// fetchKeys reads the RSA signing keys of the issuer's JWKS
+func (v *TokenVerifier) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch signing keys: %s", resp.Status)
+	}
+
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode signing keys: %w", err)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token into v
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenClaimsKey struct{}
+
+// TokenClaimsFromContext returns the claims VerifyBearerToken verified
+func TokenClaimsFromContext(ctx context.Context) (*TokenClaims, bool) {
+	claims, ok := ctx.Value(tokenClaimsKey{}).(*TokenClaims)
+	return claims, ok
+}
+
+// VerifyBearerToken authenticates users by the bearer token in the
+// Authorization header. Valid tokens identify the user to RequireAuth and the
+// other auth middleware, after onVerified (which may be nil) has seen them,
+// e.g. to create users on first sign-in; invalid ones are rejected with 401.
+// Requests without a token pass on unauthenticated. The X-User-ID and
+// X-User-Role headers are always removed, as nothing in front of the server
+// vouches for them.
+func VerifyBearerToken(verifier *TokenVerifier, onVerified func(ctx context.Context, claims *TokenClaims) error) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			req.Header.Del("X-User-ID")
+			req.Header.Del("X-User-Role")
+
+			token, ok := strings.CutPrefix(req.Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || token == "" {
+				return next(c)
+			}
+			claims, err := verifier.Verify(req.Context(), token)
+			if err != nil {
+				if errors.Is(err, ErrTokenExpired) {
+					return echo.NewHTTPError(http.StatusUnauthorized, "token expired")
+				}
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+			}
+			if onVerified != nil {
+				if err := onVerified(req.Context(), claims); err != nil {
+					return err
+				}
+			}
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), tokenClaimsKey{}, claims)))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "https://auth.example.com"
+
+// testIdentityProvider signs tokens and serves its JWKS, holding each
+// response until gate is closed when one is set
+type testIdentityProvider struct {
+	key     *rsa.PrivateKey
+	kid     string
+	gate    chan struct{}
+	fetches atomic.Int32
+	server  *httptest.Server
+}
+
+func newTestIdentityProvider(t *testing.T) *testIdentityProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p := &testIdentityProvider{key: key, kid: "k1"}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.fetches.Add(1)
+		if p.gate != nil {
+			<-p.gate
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": p.kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// token signs claims with kid, filling in iss, sub and exp unless given
+func (p *testIdentityProvider) token(t *testing.T, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	full := map[string]interface{}{
+		"iss": testIssuer,
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		full[name] = value
+	}
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	require.NoError(t, err)
+	payload, err := json.Marshal(full)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestTokenVerifier(t *testing.T) {
+	ctx := context.Background()
+	provider := newTestIdentityProvider(t)
+
+	t.Run("accepts tokens of the issuer", func(t *testing.T) {
+		verifier := NewTokenVerifier(testIssuer, "web-client", provider.server.URL)
+		claims, err := verifier.Verify(ctx, provider.token(t, "k1", map[string]interface{}{
+			"aud":            "web-client",
+			"email":          "ana@example.com",
+			"name":           "Ana",
+			"cognito:groups": []string{"subscribers"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, &TokenClaims{Subject: "user-1", Email: "ana@example.com", Name: "Ana", Groups: []string{"subscribers"}}, claims)
+
+		// Access tokens name the client in client_id; others send groups
+		claims, err = verifier.Verify(ctx, provider.token(t, "k1", map[string]interface{}{
+			"client_id":          "web-client",
+			"preferred_username": "ana",
+			"groups":             []string{"admins"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "ana", claims.Name)
+		assert.Equal(t, []string{"admins"}, claims.Groups)
+
+		// Claims it does not read are kept by name
+		claims, err = verifier.Verify(ctx, provider.token(t, "k1", map[string]interface{}{
+			"aud":              "web-client",
+			"custom:tenant_id": "acme",
+			"token_use":        "access",
+			"auth_time":        time.Now().Unix(),
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"custom:tenant_id": "acme", "token_use": "access"}, claims.Extra)
+	})
+
+	t.Run("rejects tokens that do not verify", func(t *testing.T) {
+		verifier := NewTokenVerifier(testIssuer, "web-client", provider.server.URL)
+		other := newTestIdentityProvider(t)
+
+		for name, token := range map[string]string{
+			"malformed":       "not-a-token",
+			"wrong issuer":    provider.token(t, "k1", map[string]interface{}{"aud": "web-client", "iss": "https://evil.example.com"}),
+			"wrong audience":  provider.token(t, "k1", map[string]interface{}{"aud": "other-client"}),
+			"no subject":      provider.token(t, "k1", map[string]interface{}{"aud": "web-client", "sub": ""}),
+			"not valid yet":   provider.token(t, "k1", map[string]interface{}{"aud": "web-client", "nbf": time.Now().Add(time.Hour).Unix()}),
+			"unknown key":     provider.token(t, "k2", map[string]interface{}{"aud": "web-client"}),
+			"other signature": other.token(t, "k1", map[string]interface{}{"aud": "web-client"}),
+		} {
+			_, err := verifier.Verify(ctx, token)
+			assert.ErrorIs(t, err, ErrInvalidToken, name)
+		}
+
+		_, err := verifier.Verify(ctx, provider.token(t, "k1", map[string]interface{}{
+			"aud": "web-client",
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		}))
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+
+	t.Run("refetches keys for an unknown key at most once a minute", func(t *testing.T) {
+		verifier := NewTokenVerifier(testIssuer, "", provider.server.URL)
+		now := time.Now()
+		verifier.now = func() time.Time { return now }
+		before := provider.fetches.Load()
+
+		_, err := verifier.Verify(ctx, provider.token(t, "k1", nil))
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err = verifier.Verify(ctx, provider.token(t, "rotated", nil))
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		}
+		assert.Equal(t, before+1, provider.fetches.Load())
+
+		// The issuer rotates its key
+		provider.kid = "rotated"
+		now = now.Add(2 * time.Minute)
+		_, err = verifier.Verify(ctx, provider.token(t, "rotated", nil))
+		require.NoError(t, err)
+		assert.Equal(t, before+2, provider.fetches.Load())
+		provider.kid = "k1"
+	})
+
+	t.Run("requests share one fetch and do not wait on it under the lock", func(t *testing.T) {
+		slow := newTestIdentityProvider(t)
+		slow.gate = make(chan struct{})
+		verifier := NewTokenVerifier(testIssuer, "", slow.server.URL)
+		token := slow.token(t, "k1", nil)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 5)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = verifier.Verify(ctx, token)
+			}(i)
+		}
+		require.Eventually(t, func() bool { return slow.fetches.Load() == 1 }, time.Second, time.Millisecond)
+
+		// A request giving up does not queue behind the fetch
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := verifier.Verify(cancelled, token)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		close(slow.gate)
+		wg.Wait()
+		for _, err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), slow.fetches.Load())
+	})
+}
+
+func TestVerifyBearerToken(t *testing.T) {
+	provider := newTestIdentityProvider(t)
+	verifier := NewTokenVerifier(testIssuer, "", provider.server.URL)
+
+	serve := func(onVerified func(context.Context, *TokenClaims) error, req *http.Request) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Use(VerifyBearerToken(verifier, onVerified))
+		e.GET("/me", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, map[string]string{"user": GetUserID(c), "role": string(GetUserRole(c))})
+		}, RequireAuth())
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("authenticates by token and ignores the user headers", func(t *testing.T) {
+		var seen []string
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+provider.token(t, "k1", map[string]interface{}{"cognito:groups": []string{"artists"}}))
+		req.Header.Set("X-User-ID", "someone-else")
+		req.Header.Set("X-User-Role", string(models.RoleAdmin))
+
+		rec := serve(func(ctx context.Context, claims *TokenClaims) error {
+			seen = append(seen, claims.Subject)
+			return nil
+		}, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"user":"user-1","role":"artist"}`, rec.Body.String())
+		assert.Equal(t, []string{"user-1"}, seen)
+	})
+
+	t.Run("the user headers alone do not authenticate", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("X-User-ID", "user-1")
+
+		assert.Equal(t, http.StatusUnauthorized, serve(nil, req).Code)
+	})
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+provider.token(t, "k1", map[string]interface{}{"iss": "https://evil.example.com"}))
+
+		assert.Equal(t, http.StatusUnauthorized, serve(nil, req).Code)
+	})
+
+	t.Run("fails when the user cannot be set up", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+provider.token(t, "k1", nil))
+
+		rec := serve(func(ctx context.Context, claims *TokenClaims) error {
+			return errors.New("table unavailable")
+		}, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/gvasels/personal-music-searchengine/internal/i18n"
+)
+
+// LanguageResolver returns the language a user chose in their settings,
+// empty if they chose none or it cannot be read.
+type LanguageResolver func(ctx context.Context, userID string) string
+
+// Language sets the language of a request's error messages and other
+// translated text (i18n.FromContext): the signed-in user's language setting,
+// else the best match for Accept-Language, else English. The setting is
+// looked up only when a message is translated, so requests that succeed
+// cost nothing. Register it after the middleware a resolver's lookups
+// depend on, such as RequireTenant.
+func Language(resolve LanguageResolver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := req.Context()
+			header := req.Header.Get("Accept-Language")
+			userID, _, _ := extractAuthFromContext(c)
+
+			c.SetRequest(req.WithContext(i18n.WithLanguageFunc(ctx, func() string {
+				var preferred string
+				if userID != "" && resolve != nil {
+					preferred = resolve(ctx, userID)
+				}
+				return i18n.Match(preferred, header)
+			})))
+			c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gvasels/personal-music-searchengine/internal/i18n"
+)
+
+func TestLanguage(t *testing.T) {
+	settings := map[string]string{"user-de": "de", "user-none": ""}
+	lookups := 0
+	resolve := func(_ context.Context, userID string) string {
+		lookups++
+		return settings[userID]
+	}
+
+	e := echo.New()
+	e.Use(Language(resolve))
+	e.GET("/lang", func(c echo.Context) error {
+		return c.String(http.StatusOK, i18n.FromContext(c.Request().Context()))
+	})
+	e.GET("/ok", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		userID         string
+		acceptLanguage string
+		want           string
+	}{
+		{"no preference", "", "", "en"},
+		{"accept-language", "", "fr-CA,fr;q=0.9,en;q=0.5", "fr"},
+		{"unsupported accept-language", "", "ja", "en"},
+		{"user setting wins", "user-de", "es", "de"},
+		{"user without setting", "user-none", "es", "es"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/lang", nil)
+			if tt.userID != "" {
+				req.Header.Set("X-User-ID", tt.userID)
+			}
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Body.String())
+			assert.Contains(t, rec.Header().Values(echo.HeaderVary), "Accept-Language")
+		})
+	}
+
+	t.Run("settings are not read unless needed", func(t *testing.T) {
+		lookups = 0
+		req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+		req.Header.Set("X-User-ID", "user-de")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, 0, lookups)
+	})
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestObserver is told the route template, status and latency of every request
+type RequestObserver func(method, route string, status int, d time.Duration)
+
+// unmatchedRoute labels requests that matched no route, so scanners probing
+// random paths cannot grow the metric series without bound
+const unmatchedRoute = "unmatched"
+
+// Metrics reports every request to observe (e.g. metrics.Server.ObserveRequest).
+func Metrics(observe RequestObserver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// Let the error handler write the response so its status is recorded
+				c.Error(err)
+			}
+
+			// The router answers paths it cannot match with echo.ErrNotFound
+			route := c.Path()
+			if route == "" || errors.Is(err, echo.ErrNotFound) {
+				route = unmatchedRoute
+			}
+			observe(c.Request().Method, route, c.Response().Status, time.Since(start))
+			return nil
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type observedRequest struct {
+	method, route string
+	status        int
+}
+
+func TestMetrics(t *testing.T) {
+	var observed []observedRequest
+	observe := func(method, route string, status int, _ time.Duration) {
+		observed = append(observed, observedRequest{method, route, status})
+	}
+
+	e := echo.New()
+	e.Use(Metrics(observe))
+	e.GET("/tracks/:id", func(c echo.Context) error {
+		if c.Param("id") == "missing" {
+			return echo.NewHTTPError(http.StatusNotFound, "track not found")
+		}
+		return c.String(http.StatusOK, "OK")
+	})
+
+	for _, path := range []string{"/tracks/t1", "/tracks/missing", "/no/such/route"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, []observedRequest{
+		{http.MethodGet, "/tracks/:id", http.StatusOK},
+		{http.MethodGet, "/tracks/:id", http.StatusNotFound},
+		{http.MethodGet, unmatchedRoute, http.StatusNotFound},
+	}, observed)
+}
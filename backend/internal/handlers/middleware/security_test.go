@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	ok := func(c echo.Context) error { return c.String(http.StatusOK, "OK") }
+
+	t.Run("forbids framing and sends HSTS over HTTPS", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderXForwardedProto, "https")
+		rec := httptest.NewRecorder()
+
+		err := SecurityHeaders(SecurityConfig{HSTSMaxAge: time.Hour})(ok)(e.NewContext(req, rec))
+
+		require.NoError(t, err)
+		assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+		assert.Equal(t, "DENY", rec.Header().Get(echo.HeaderXFrameOptions))
+		assert.Equal(t, "frame-ancestors 'none'", rec.Header().Get(echo.HeaderContentSecurityPolicy))
+		assert.Equal(t, "max-age=3600; includeSubdomains", rec.Header().Get(echo.HeaderStrictTransportSecurity))
+	})
+
+	t.Run("allows the embedding origins to frame responses", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		cfg := SecurityConfig{FrameAncestors: []string{"https://blog.example.com", "https://*.example.org"}}
+
+		err := SecurityHeaders(cfg)(ok)(e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec))
+
+		require.NoError(t, err)
+		assert.Empty(t, rec.Header().Get(echo.HeaderXFrameOptions))
+		assert.Equal(t, "frame-ancestors https://blog.example.com https://*.example.org", rec.Header().Get(echo.HeaderContentSecurityPolicy))
+		assert.Empty(t, rec.Header().Get(echo.HeaderStrictTransportSecurity), "HSTS is only sent over HTTPS")
+	})
+}
+
+func TestCSRF(t *testing.T) {
+	e := echo.New()
+	e.Use(CSRF(CSRFConfig{SessionCookie: "session", SkipPrefixes: []string{"/media/"}}))
+	ok := func(c echo.Context) error { return c.String(http.StatusOK, "OK") }
+	e.GET("/api/v1/tracks", ok)
+	e.POST("/api/v1/playlists", ok)
+	e.PUT("/media/*", ok)
+
+	serve := func(method, path string, cookies []*http.Cookie, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		if token != "" {
+			req.Header.Set(CSRFHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	session := &http.Cookie{Name: "session", Value: "s1"}
+
+	t.Run("requests without the session cookie are not checked", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/api/v1/playlists", nil, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Result().Cookies())
+	})
+
+	t.Run("cookie sessions must echo the issued token", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/api/v1/tracks", []*http.Cookie{session}, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var csrf *http.Cookie
+		for _, cookie := range rec.Result().Cookies() {
+			if cookie.Name == CSRFCookie {
+				csrf = cookie
+			}
+		}
+		require.NotNil(t, csrf, "safe requests receive a token")
+
+		rec = serve(http.MethodPost, "/api/v1/playlists", []*http.Cookie{session, csrf}, "")
+		assert.NotEqual(t, http.StatusOK, rec.Code, "missing header")
+
+		rec = serve(http.MethodPost, "/api/v1/playlists", []*http.Cookie{session, csrf}, "forged")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = serve(http.MethodPost, "/api/v1/playlists", []*http.Cookie{session, csrf}, csrf.Value)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("skipped prefixes are not checked", func(t *testing.T) {
+		rec := serve(http.MethodPut, "/media/uploads/a.mp3", []*http.Cookie{session}, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("disabled without a session cookie name", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(session)
+
+		err := CSRF(CSRFConfig{})(ok)(e.NewContext(req, rec))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
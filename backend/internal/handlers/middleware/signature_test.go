@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSigningSecret = "0123456789abcdef0123456789abcdef"
+
+// signRequest signs req following the scheme in reqsign's documentation, as
+// a caller of the gateway would
+func signRequest(req *http.Request, body string) {
+	bodyHash := sha256.Sum256([]byte(body))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s", req.Method, req.URL.EscapedPath(), req.URL.RawQuery, timestamp, hex.EncodeToString(bodyHash[:]))
+	req.Header.Set(reqsign.HeaderKeyID, "k1")
+	req.Header.Set(reqsign.HeaderTimestamp, timestamp)
+	req.Header.Set(reqsign.HeaderSignature, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestVerifySignature(t *testing.T) {
+	keys, err := reqsign.ParseKeyring("k1:" + testSigningSecret)
+	require.NoError(t, err)
+	verifier := reqsign.NewVerifier(keys, 0)
+
+	serve := func(required bool, req *http.Request) (*httptest.ResponseRecorder, bool) {
+		e := echo.New()
+		e.Use(VerifySignature(verifier, required, "/health"))
+		var signed bool
+		handler := func(c echo.Context) error {
+			signed = IsSignedRequest(c)
+			return c.String(http.StatusOK, "OK")
+		}
+		e.POST("/v1/embeddings", handler)
+		e.GET("/health", handler)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec, signed
+	}
+	const body = `{"input":"hi"}`
+	newRequest := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(body))
+	}
+
+	t.Run("accepts valid signatures", func(t *testing.T) {
+		req := newRequest()
+		signRequest(req, body)
+
+		rec, signed := serve(true, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, signed)
+	})
+
+	t.Run("rejects invalid signatures even when optional", func(t *testing.T) {
+		req := newRequest()
+		signRequest(req, body)
+		req.Header.Set(reqsign.HeaderSignature, "00")
+
+		rec, _ := serve(false, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("passes unsigned requests on unless required", func(t *testing.T) {
+		rec, signed := serve(false, newRequest())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.False(t, signed)
+
+		rec, _ = serve(true, newRequest())
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec, _ = serve(true, httptest.NewRequest(http.MethodGet, "/health", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+	"github.com/labstack/echo/v4"
+)
+
+// TenantIDKey is the Echo context key for the resolved tenant ID
+const TenantIDKey = "tenant_id"
+
+// DefaultTenantClaim is the custom Cognito attribute carrying the user's tenant
+const DefaultTenantClaim = "custom:tenant_id"
+
+// TenantConfig configures how RequireTenant resolves the tenant for a request.
+type TenantConfig struct {
+	// ClaimName is the JWT claim holding the tenant ID (defaults to custom:tenant_id)
+	ClaimName string
+	// Domains maps request hosts to tenant IDs, e.g. "music.acme.com" -> "acme"
+	Domains map[string]string
+	// SkipPaths are served without a tenant (health checks)
+	SkipPaths []string
+}
+
+// RequireTenant resolves the tenant for a request and stores it on the request
+// context for the tenant-aware storage decorators. Authenticated requests take
+// it from the token's tenant claim, which Cognito puts in access tokens
+// through the pre-token-generation trigger, and are rejected without one; the
+// request host only has to agree with it. Requests with no token take the
+// tenant of their host, and are rejected when the host has none.
+func RequireTenant(cfg TenantConfig) echo.MiddlewareFunc {
+	claimName := cfg.ClaimName
+	if claimName == "" {
+		claimName = DefaultTenantClaim
+	}
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, p := range cfg.SkipPaths {
+		skip[p] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skip[c.Path()] {
+				return next(c)
+			}
+
+			claimTenant, authenticated := tenantFromClaims(c, claimName)
+			domainTenant := cfg.Domains[hostWithoutPort(c.Request().Host)]
+
+			tenantID := domainTenant
+			if authenticated {
+				// A token without the claim must not fall back to the host,
+				// which the client chooses
+				if claimTenant == "" {
+					return echo.NewHTTPError(http.StatusForbidden, "token has no tenant")
+				}
+				if domainTenant != "" && claimTenant != domainTenant {
+					return echo.NewHTTPError(http.StatusForbidden, "tenant mismatch")
+				}
+				tenantID = claimTenant
+			}
+			if tenantID == "" {
+				return echo.NewHTTPError(http.StatusForbidden, "tenant could not be determined")
+			}
+			if err := tenant.ValidateID(tenantID); err != nil {
+				return echo.NewHTTPError(http.StatusForbidden, "invalid tenant")
+			}
+
+			c.Set(TenantIDKey, tenantID)
+			c.SetRequest(c.Request().WithContext(tenant.WithID(c.Request().Context(), tenantID)))
+
+			return next(c)
+		}
+	}
+}
+
+// GetTenantID retrieves the tenant ID from the Echo context.
+func GetTenantID(c echo.Context) string {
+	if tenantID, ok := c.Get(TenantIDKey).(string); ok {
+		return tenantID
+	}
+	return ""
+}
+
+// ParseTenantDomains parses "host=tenant,host2=tenant2" into a host map
+func ParseTenantDomains(raw string) map[string]string {
+	domains := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		host, tenantID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || host == "" || tenantID == "" {
+			continue
+		}
+		domains[strings.ToLower(strings.TrimSpace(host))] = strings.TrimSpace(tenantID)
+	}
+	return domains
+}
+
+// tenantFromClaims reads the tenant claim of the request's verified token, from
+// the API Gateway V2 JWT authorizer or VerifyBearerToken. authenticated is false
+// when the request has no verified token.
+func tenantFromClaims(c echo.Context, claimName string) (tenantID string, authenticated bool) {
+	ctx := c.Request().Context()
+	if requestCtx, ok := core.GetAPIGatewayV2ContextFromContext(ctx); ok && requestCtx.Authorizer != nil && requestCtx.Authorizer.JWT != nil {
+		return requestCtx.Authorizer.JWT.Claims[claimName], true
+	}
+	if claims, ok := TokenClaimsFromContext(ctx); ok {
+		return claims.Extra[claimName], true
+	}
+	return "", false
+}
+
+func hostWithoutPort(host string) string {
+	if i := strings.LastIndex(host, ":"); i != -1 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.ToLower(host)
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireTenant(t *testing.T) {
+	cfg := TenantConfig{
+		Domains:   map[string]string{"music.acme.com": "acme"},
+		SkipPaths: []string{"/health"},
+	}
+
+	t.Run("resolves tenant from the request host", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = "music.acme.com:443"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		var ctxTenant string
+		handler := func(c echo.Context) error {
+			ctxTenant, _ = tenant.FromContext(c.Request().Context())
+			return c.String(http.StatusOK, "OK")
+		}
+
+		err := RequireTenant(cfg)(handler)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "acme", ctxTenant)
+		assert.Equal(t, "acme", GetTenantID(c))
+	})
+
+	t.Run("rejects requests without a tenant", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = "unknown.example.com"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := func(c echo.Context) error {
+			return c.String(http.StatusOK, "OK")
+		}
+
+		err := RequireTenant(cfg)(handler)(c)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code)
+	})
+
+	// gatewayRequest is a request through API Gateway whose JWT authorizer
+	// verified claims
+	gatewayRequest := func(t *testing.T, host string, claims map[string]string) *http.Request {
+		t.Helper()
+		accessor := core.RequestAccessorV2{}
+		req, err := accessor.EventToRequestWithContext(context.Background(), events.APIGatewayV2HTTPRequest{
+			RawPath: "/api/v1/tracks",
+			Headers: map[string]string{"host": host},
+			RequestContext: events.APIGatewayV2HTTPRequestContext{
+				HTTP:       events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodGet, Path: "/api/v1/tracks"},
+				Authorizer: &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{JWT: &events.APIGatewayV2HTTPRequestContextAuthorizerJWTDescription{Claims: claims}},
+			},
+		})
+		require.NoError(t, err)
+		req.Host = host
+		return req
+	}
+	serve := func(req *http.Request) (string, error) {
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+		var ctxTenant string
+		err := RequireTenant(cfg)(func(c echo.Context) error {
+			ctxTenant, _ = tenant.FromContext(c.Request().Context())
+			return nil
+		})(c)
+		return ctxTenant, err
+	}
+	assertForbidden := func(t *testing.T, err error) {
+		t.Helper()
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code)
+	}
+
+	t.Run("authenticated requests take the tenant from the claim", func(t *testing.T) {
+		got, err := serve(gatewayRequest(t, "music.acme.com", map[string]string{"sub": "user-1", DefaultTenantClaim: "acme"}))
+		require.NoError(t, err)
+		assert.Equal(t, "acme", got)
+
+		// A host without a mapping does not matter
+		got, err = serve(gatewayRequest(t, "api.example.com", map[string]string{"sub": "user-1", DefaultTenantClaim: "globex"}))
+		require.NoError(t, err)
+		assert.Equal(t, "globex", got)
+	})
+
+	t.Run("authenticated requests without the claim do not fall back to the host", func(t *testing.T) {
+		_, err := serve(gatewayRequest(t, "music.acme.com", map[string]string{"sub": "user-1"}))
+		assertForbidden(t, err)
+	})
+
+	t.Run("rejects a claim the host disagrees with", func(t *testing.T) {
+		_, err := serve(gatewayRequest(t, "music.acme.com", map[string]string{"sub": "user-1", DefaultTenantClaim: "globex"}))
+		assertForbidden(t, err)
+	})
+
+	t.Run("reads the claim of a token the server verified itself", func(t *testing.T) {
+		withClaims := func(claims *TokenClaims) *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = "music.acme.com"
+			return req.WithContext(context.WithValue(req.Context(), tokenClaimsKey{}, claims))
+		}
+
+		got, err := serve(withClaims(&TokenClaims{Subject: "user-1", Extra: map[string]string{DefaultTenantClaim: "acme"}}))
+		require.NoError(t, err)
+		assert.Equal(t, "acme", got)
+
+		_, err = serve(withClaims(&TokenClaims{Subject: "user-1"}))
+		assertForbidden(t, err)
+	})
+
+	t.Run("skips configured paths", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/health")
+
+		handlerCalled := false
+		handler := func(c echo.Context) error {
+			handlerCalled = true
+			return c.String(http.StatusOK, "OK")
+		}
+
+		err := RequireTenant(cfg)(handler)(c)
+
+		assert.NoError(t, err)
+		assert.True(t, handlerCalled)
+	})
+}
+
+func TestParseTenantDomains(t *testing.T) {
+	domains := ParseTenantDomains("music.acme.com=acme, Tunes.Globex.com = globex,broken")
+
+	assert.Equal(t, map[string]string{
+		"music.acme.com":   "acme",
+		"tunes.globex.com": "globex",
+	}, domains)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// UndoOperation reverts a bulk edit made by the current user within the last
+// models.UndoWindow; the operation ID is returned by the bulk endpoint
+// POST /api/v1/operations/:id/undo
+func (h *Handlers) UndoOperation(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Operations == nil {
+		return handleError(c, models.NewServiceUnavailableError("undo", "undo is not configured"))
+	}
+
+	result, err := h.services.Operations.Undo(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, result)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateParty starts a guest DJ party and returns the link guests open it with
+// POST /api/v1/me/parties
+func (h *Handlers) CreateParty(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Party == nil {
+		return handleError(c, partyUnavailable())
+	}
+
+	var req models.CreatePartyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	link, err := h.services.Party.Create(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, link)
+}
+
+// EndParty ends one of the current user's parties; its link stops working
+// DELETE /api/v1/me/parties/:id
+func (h *Handlers) EndParty(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Party == nil {
+		return handleError(c, partyUnavailable())
+	}
+
+	if err := h.services.Party.End(c.Request().Context(), userID, c.Param("id")); err != nil {
+		return handleError(c, err)
+	}
+
+	return noContent(c)
+}
+
+// ListPartyRequests returns the tracks guests requested at a party, oldest first
+// GET /api/v1/me/parties/:id/requests
+func (h *Handlers) ListPartyRequests(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Party == nil {
+		return handleError(c, partyUnavailable())
+	}
+
+	requests, err := h.services.Party.ListRequests(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return successList(c, requests)
+}
+
+// ApprovePartyRequest adds a requested track to the end of the current user's
+// play queue
+// POST /api/v1/me/parties/:id/requests/:requestId/approve
+func (h *Handlers) ApprovePartyRequest(c echo.Context) error {
+	return h.respondToPartyRequest(c, true)
+}
+
+// DeclinePartyRequest turns down a requested track
+// POST /api/v1/me/parties/:id/requests/:requestId/decline
+func (h *Handlers) DeclinePartyRequest(c echo.Context) error {
+	return h.respondToPartyRequest(c, false)
+}
+
+func (h *Handlers) respondToPartyRequest(c echo.Context, approve bool) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Party == nil {
+		return handleError(c, partyUnavailable())
+	}
+
+	request, err := h.services.Party.Respond(c.Request().Context(), userID, c.Param("id"), c.Param("requestId"), approve)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, request)
+}
+
+// OpenParty shows a party link's guests the party's name and end. Party
+// links work without an account, so the guest routes are registered outside
+// the authenticated routes.
+// GET /api/v1/parties/:token
+func (h *Handlers) OpenParty(c echo.Context) error {
+	if h.services.Party == nil {
+		return handleError(c, partyUnavailable())
+	}
+
+	party, err := h.services.Party.Open(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, party)
+}
+
+// SearchPartyTracks searches the tracks a party offers its guests
+// GET /api/v1/parties/:token/tracks
+func (h *Handlers) SearchPartyTracks(c echo.Context) error {
+	if h.services.Party == nil {
+		return handleError(c, partyUnavailable())
+	}
+
+	var filter models.PartySearchFilter
+	if err := bindAndValidate(c, &filter); err != nil {
+		return handleError(c, err)
+	}
+
+	tracks, err := h.services.Party.Search(c.Request().Context(), c.Param("token"), filter)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return successList(c, tracks)
+}
+
+// RequestPartyTrack asks the party's host to queue a track. Guests making too
+// many requests get 429 with Retry-After.
+// POST /api/v1/parties/:token/requests
+func (h *Handlers) RequestPartyTrack(c echo.Context) error {
+	if h.services.Party == nil {
+		return handleError(c, partyUnavailable())
+	}
+
+	var req models.GuestTrackRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	// Guests are told apart by the address the server's IPExtractor
+	// (middleware.ClientIP) trusts, not by a forwarded header they could set
+	request, err := h.services.Party.RequestTrack(c.Request().Context(), c.Param("token"), c.RealIP(), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, request)
+}
+
+func partyUnavailable() error {
+	return models.NewServiceUnavailableError("parties", "party links are not configured")
+}
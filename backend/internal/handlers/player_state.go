@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetPlayerState returns the current user's play queue and its version
+// GET /api/v1/me/player
+func (h *Handlers) GetPlayerState(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.PlayerState == nil {
+		return handleError(c, playerStateUnavailable())
+	}
+
+	state, err := h.services.PlayerState.Get(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, state)
+}
+
+// ApplyQueueAction adds tracks to play next or last, removes the track at an
+// index, or clears the current user's play queue. A stale version is refused
+// with 409 and the current queue in the error details.
+// POST /api/v1/me/player/queue
+func (h *Handlers) ApplyQueueAction(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.PlayerState == nil {
+		return handleError(c, playerStateUnavailable())
+	}
+
+	var req models.QueueActionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	state, err := h.services.PlayerState.ApplyQueueAction(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, state)
+}
+
+func playerStateUnavailable() error {
+	return models.NewServiceUnavailableError("player state", "player state sync is not configured")
+}
@@ -5,7 +5,8 @@ import (
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 )
 
-// ListPlaylists returns a paginated list of playlists
+// ListPlaylists returns a paginated list of playlists, or only their count
+// for HEAD requests and countOnly=true
 func (h *Handlers) ListPlaylists(c echo.Context) error {
 	userID := getUserIDFromContext(c)
 	if userID == "" {
@@ -17,6 +18,14 @@ func (h *Handlers) ListPlaylists(c echo.Context) error {
 		return handleError(c, models.ErrBadRequest)
 	}
 
+	if wantsCount(c, filter.CountOnly) {
+		count, err := h.services.Playlist.CountPlaylists(c.Request().Context(), userID)
+		if err != nil {
+			return handleError(c, err)
+		}
+		return successCount(c, count)
+	}
+
 	playlists, err := h.services.Playlist.ListPlaylists(c.Request().Context(), userID, filter)
 	if err != nil {
 		return handleError(c, err)
@@ -90,7 +99,8 @@ func (h *Handlers) UpdatePlaylist(c echo.Context) error {
 	return success(c, playlist)
 }
 
-// DeletePlaylist deletes a playlist
+// DeletePlaylist deletes a playlist; with ?dryRun=true it reports the
+// playlist without deleting it
 func (h *Handlers) DeletePlaylist(c echo.Context) error {
 	userID := getUserIDFromContext(c)
 	if userID == "" {
@@ -102,10 +112,19 @@ func (h *Handlers) DeletePlaylist(c echo.Context) error {
 		return handleError(c, models.ErrBadRequest)
 	}
 
-	if err := h.services.Playlist.DeletePlaylist(c.Request().Context(), userID, playlistID); err != nil {
+	var dryRun bool
+	if err := bindDryRun(c, &dryRun); err != nil {
 		return handleError(c, err)
 	}
 
+	result, err := h.services.Playlist.DeletePlaylist(c.Request().Context(), userID, playlistID, dryRun)
+	if err != nil {
+		return handleError(c, err)
+	}
+	if result.DryRun {
+		return success(c, result)
+	}
+
 	return noContent(c)
 }
 
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetPreviewLink returns a share link to the preview clip of a public track
+// GET /api/v1/tracks/:id/preview
+func (h *Handlers) GetPreviewLink(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Previews == nil {
+		return handleError(c, models.NewServiceUnavailableError("previews", "preview links are not configured"))
+	}
+
+	link, err := h.services.Previews.Link(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, link)
+}
+
+// OpenPreview redirects a preview share link to the clip. Links work without
+// an account, so this route is registered outside the authenticated routes.
+// GET /api/v1/previews/:token
+func (h *Handlers) OpenPreview(c echo.Context) error {
+	if h.services.Previews == nil {
+		return handleError(c, models.NewServiceUnavailableError("previews", "preview links are not configured"))
+	}
+
+	url, err := h.services.Previews.Resolve(accessContext(c), c.Param("token"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	// The clip URL expires long before the link does, so the redirect must not be cached
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Redirect(http.StatusFound, url)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// remoteStreamDuration keeps an event stream well inside the API Lambda's
+	// 30 second timeout; the client reconnects when it ends
+	remoteStreamDuration = 20 * time.Second
+	// remotePollInterval is how often an open stream checks for commands
+	remotePollInterval = time.Second
+	// remoteRetryMs is the reconnect delay the stream suggests to EventSource
+	remoteRetryMs = 1000
+)
+
+// ListDevices returns the current user's active devices
+// GET /api/v1/me/devices
+func (h *Handlers) ListDevices(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Remote == nil {
+		return handleError(c, remoteUnavailable())
+	}
+
+	devices, err := h.services.Remote.ListDevices(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return successList(c, devices)
+}
+
+// RegisterDevice starts or refreshes the session of one of the current user's
+// devices so others can control it
+// PUT /api/v1/me/devices/:deviceId
+func (h *Handlers) RegisterDevice(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Remote == nil {
+		return handleError(c, remoteUnavailable())
+	}
+
+	var req models.RegisterDeviceRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	session, err := h.services.Remote.RegisterDevice(c.Request().Context(), userID, c.Param("deviceId"), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, session)
+}
+
+// DisconnectDevice ends a device's session
+// DELETE /api/v1/me/devices/:deviceId
+func (h *Handlers) DisconnectDevice(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Remote == nil {
+		return handleError(c, remoteUnavailable())
+	}
+
+	if err := h.services.Remote.DisconnectDevice(c.Request().Context(), userID, c.Param("deviceId")); err != nil {
+		return handleError(c, err)
+	}
+
+	return noContent(c)
+}
+
+// SendRemoteCommand queues a play, pause, next, previous, seek or volume
+// command for one of the current user's active devices. The command is
+// accepted, not yet delivered; it expires if the device does not pick it up.
+// POST /api/v1/me/devices/:deviceId/commands
+func (h *Handlers) SendRemoteCommand(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Remote == nil {
+		return handleError(c, remoteUnavailable())
+	}
+
+	var req models.RemoteCommandRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	cmd, err := h.services.Remote.SendCommand(c.Request().Context(), userID, c.Param("deviceId"), req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, cmd)
+}
+
+// StreamRemoteCommands delivers commands sent to a registered device as
+// server-sent "command" events. The stream ends after remoteStreamDuration
+// with a retry hint, and the device's session stays alive while it listens.
+// Behind API Gateway the response is buffered, so each stream arrives at once
+// when it ends, like a long poll; it ends early once a command is delivered.
+// GET /api/v1/me/devices/:deviceId/events
+func (h *Handlers) StreamRemoteCommands(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Remote == nil {
+		return handleError(c, remoteUnavailable())
+	}
+
+	ctx := c.Request().Context()
+	deviceID := c.Param("deviceId")
+	if _, err := h.services.Remote.Connect(ctx, userID, deviceID); err != nil {
+		return handleError(c, err)
+	}
+
+	c.Response().Header().Set("Content-Type", "text/event-stream")
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	c.Response().Header().Set("Connection", "keep-alive")
+	c.Response().WriteHeader(http.StatusOK)
+	fmt.Fprintf(c.Response(), "retry: %d\n\n", remoteRetryMs)
+	c.Response().Flush()
+
+	deadline := time.NewTimer(remoteStreamDuration)
+	defer deadline.Stop()
+	poll := time.NewTicker(remotePollInterval)
+	defer poll.Stop()
+
+	for {
+		commands, err := h.services.Remote.Receive(ctx, userID, deviceID)
+		if err != nil {
+			// Headers are sent; the client reconnects and the command, if
+			// it was not taken, is still waiting
+			return nil
+		}
+		for _, cmd := range commands {
+			data, _ := json.Marshal(cmd)
+			fmt.Fprintf(c.Response(), "id: %s\nevent: command\ndata: %s\n\n", cmd.ID, data)
+		}
+		if len(commands) > 0 {
+			c.Response().Flush()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-deadline.C:
+			return nil
+		case <-poll.C:
+			// A comment line keeps proxies from closing an idle stream
+			fmt.Fprint(c.Response(), ": keep-alive\n\n")
+			c.Response().Flush()
+		}
+	}
+}
+
+func remoteUnavailable() error {
+	return models.NewServiceUnavailableError("remote control", "remote control is not configured")
+}
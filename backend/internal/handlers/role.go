@@ -24,7 +24,7 @@ func NewRoleHandler(roleService service.RoleService) *RoleHandler {
 func (h *RoleHandler) GetUserRole(c echo.Context) error {
 	userID := c.Param("id")
 	if userID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	role, err := h.roleService.GetUserRole(c.Request().Context(), userID)
@@ -48,7 +48,7 @@ type SetUserRoleRequest struct {
 func (h *RoleHandler) SetUserRole(c echo.Context) error {
 	userID := c.Param("id")
 	if userID == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(models.ErrBadRequest))
+		return handleError(c, models.ErrBadRequest)
 	}
 
 	var req SetUserRoleRequest
@@ -72,7 +72,7 @@ func (h *RoleHandler) SetUserRole(c echo.Context) error {
 func (h *RoleHandler) GetMyRole(c echo.Context) error {
 	userID := middleware.GetUserID(c)
 	if userID == "" {
-		return c.JSON(http.StatusUnauthorized, models.NewErrorResponse(models.ErrUnauthorized))
+		return handleError(c, models.ErrUnauthorized)
 	}
 
 	role, err := h.roleService.GetUserRole(c.Request().Context(), userID)
@@ -91,16 +91,12 @@ func (h *RoleHandler) GetMyRole(c echo.Context) error {
 func (h *RoleHandler) ListUsersByRole(c echo.Context) error {
 	roleStr := c.QueryParam("role")
 	if roleStr == "" {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			models.NewValidationError("role query parameter is required"),
-		))
+		return handleError(c, models.NewValidationError("role query parameter is required"))
 	}
 
 	role := models.UserRole(roleStr)
 	if !role.IsValid() {
-		return c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			models.NewValidationError("invalid role"),
-		))
+		return handleError(c, models.NewValidationError("invalid role"))
 	}
 
 	limit := 20
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"strconv"
+
 	"github.com/labstack/echo/v4"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 )
@@ -34,10 +36,20 @@ func (h *Handlers) SimpleSearch(c echo.Context) error {
 		req.Limit = 20 // Keep default, service will validate
 	}
 
+	// Parse optional hydrate flag (full tracks with cover URLs)
+	if hydrateStr := c.QueryParam("hydrate"); hydrateStr != "" {
+		hydrate, err := strconv.ParseBool(hydrateStr)
+		if err != nil {
+			return handleError(c, models.NewValidationError("hydrate must be true or false"))
+		}
+		req.Hydrate = hydrate
+	}
+
 	resp, err := h.services.Search.Search(c.Request().Context(), userID, req)
 	if err != nil {
 		return handleError(c, err)
 	}
+	h.recordSearch(c, userID, req)
 
 	return success(c, resp)
 }
@@ -58,6 +70,7 @@ func (h *Handlers) AdvancedSearch(c echo.Context) error {
 	if err != nil {
 		return handleError(c, err)
 	}
+	h.recordSearch(c, userID, req)
 
 	return success(c, resp)
 }
@@ -78,6 +91,29 @@ func (h *Handlers) Autocomplete(c echo.Context) error {
 	if err != nil {
 		return handleError(c, err)
 	}
+	if h.services.SearchHistory != nil {
+		resp.Recent = h.services.SearchHistory.Suggest(c.Request().Context(), userID, query)
+	}
+
+	return success(c, resp)
+}
+
+// QuickSearch searches every entity type at once for a global search box
+func (h *Handlers) QuickSearch(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var req models.QuickSearchRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	resp, err := h.services.Search.QuickSearch(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
 
 	return success(c, resp)
 }
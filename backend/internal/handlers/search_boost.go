@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetSearchBoosts returns the current user's pinned results and artist boosts
+// GET /api/v1/me/search-boosts
+func (h *Handlers) GetSearchBoosts(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.SearchBoosts == nil {
+		return handleError(c, searchBoostsUnavailable())
+	}
+
+	boosts, err := h.services.SearchBoosts.Get(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, boosts)
+}
+
+// PinSearchResult pins a track to the top of the results for a query
+// POST /api/v1/me/search-boosts/pins
+func (h *Handlers) PinSearchResult(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.SearchBoosts == nil {
+		return handleError(c, searchBoostsUnavailable())
+	}
+
+	var req models.PinSearchResultRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	boosts, err := h.services.SearchBoosts.PinTrack(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, boosts)
+}
+
+// UnpinSearchResult removes a pin
+// DELETE /api/v1/me/search-boosts/pins?query={query}&trackId={trackId}
+func (h *Handlers) UnpinSearchResult(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.SearchBoosts == nil {
+		return handleError(c, searchBoostsUnavailable())
+	}
+
+	query := c.QueryParam("query")
+	trackID := c.QueryParam("trackId")
+	if query == "" || trackID == "" {
+		return handleError(c, models.NewValidationError("query and trackId are required"))
+	}
+
+	if err := h.services.SearchBoosts.UnpinTrack(c.Request().Context(), userID, query, trackID); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetArtistBoost boosts or demotes an artist in the current user's searches
+// PUT /api/v1/me/search-boosts/artists
+func (h *Handlers) SetArtistBoost(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.SearchBoosts == nil {
+		return handleError(c, searchBoostsUnavailable())
+	}
+
+	var req models.SetArtistBoostRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	boosts, err := h.services.SearchBoosts.SetArtistBoost(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, boosts)
+}
+
+// RemoveArtistBoost removes an artist's boost
+// DELETE /api/v1/me/search-boosts/artists?artist={artist}
+func (h *Handlers) RemoveArtistBoost(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.SearchBoosts == nil {
+		return handleError(c, searchBoostsUnavailable())
+	}
+
+	artist := c.QueryParam("artist")
+	if artist == "" {
+		return handleError(c, models.NewValidationError("artist is required"))
+	}
+
+	if err := h.services.SearchBoosts.RemoveArtistBoost(c.Request().Context(), userID, artist); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func searchBoostsUnavailable() error {
+	return models.NewServiceUnavailableError("search boosts", "search boosts are not configured")
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// ListRecentSearches returns the current user's recent searches, newest first
+// GET /api/v1/me/searches
+func (h *Handlers) ListRecentSearches(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.SearchHistory == nil {
+		return handleError(c, models.NewServiceUnavailableError("search history", "search history is not configured"))
+	}
+
+	resp, err := h.services.SearchHistory.List(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, resp)
+}
+
+// ClearRecentSearches forgets the current user's recent searches
+// DELETE /api/v1/me/searches
+func (h *Handlers) ClearRecentSearches(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.SearchHistory == nil {
+		return handleError(c, models.NewServiceUnavailableError("search history", "search history is not configured"))
+	}
+
+	if err := h.services.SearchHistory.Clear(c.Request().Context(), userID); err != nil {
+		return handleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// recordSearch adds the first page of a search to the user's recent
+// searches. History is a convenience, so a failure does not fail the search.
+func (h *Handlers) recordSearch(c echo.Context, userID string, req models.SearchRequest) {
+	if h.services.SearchHistory == nil || req.Cursor != "" {
+		return
+	}
+	if err := h.services.SearchHistory.Record(c.Request().Context(), userID, req.Query); err != nil {
+		fmt.Printf("Warning: failed to record search for %s: %v\n", userID, err)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// ShareTrack shares one of the user's tracks with another user by email
+func (h *Handlers) ShareTrack(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	trackID := c.Param("id")
+	if trackID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.CreateTrackShareRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	share, err := h.services.Share.ShareTrack(c.Request().Context(), userID, trackID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, share)
+}
+
+// ListReceivedShares lists tracks other users have shared with the user
+func (h *Handlers) ListReceivedShares(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var filter models.TrackShareFilter
+	if err := c.Bind(&filter); err != nil {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	shares, err := h.services.Share.ListReceivedShares(c.Request().Context(), userID, filter)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, shares)
+}
+
+// ListSentShares lists tracks the user has shared with others
+func (h *Handlers) ListSentShares(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var filter models.TrackShareFilter
+	if err := c.Bind(&filter); err != nil {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	shares, err := h.services.Share.ListSentShares(c.Request().Context(), userID, filter)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, shares)
+}
+
+// AcceptShare copies a shared track into the user's library
+func (h *Handlers) AcceptShare(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	shareID := c.Param("id")
+	if shareID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	track, err := h.services.Share.AcceptShare(c.Request().Context(), userID, shareID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return created(c, track)
+}
+
+// DeclineShare declines a pending share
+func (h *Handlers) DeclineShare(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	shareID := c.Param("id")
+	if shareID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	share, err := h.services.Share.DeclineShare(c.Request().Context(), userID, shareID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, share)
+}
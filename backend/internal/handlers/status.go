@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/gvasels/personal-music-searchengine/internal/capability"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
+)
+
+// SetCapabilities sets the registry used to report and guard optional subsystems
+func (h *Handlers) SetCapabilities(registry *capability.Registry) {
+	h.capabilities = registry
+}
+
+// SetDependencies sets the registry whose circuit breakers and concurrency
+// limits GET /status reports and whose bulk limiter guards bulk edit routes
+func (h *Handlers) SetDependencies(registry *resilience.Registry) {
+	h.dependencies = registry
+}
+
+// statusReport is the capability report plus the breaker state of each AWS
+// dependency and the occupancy of each concurrency limit
+type statusReport struct {
+	capability.Report
+	Dependencies []resilience.Status      `json:"dependencies"`
+	Limits       []resilience.LimitStatus `json:"limits"`
+}
+
+// GetStatus reports which optional subsystems are enabled and why the others
+// are not, the circuit breaker of each dependency and the concurrency limits
+// in use. The service is degraded while any breaker is not closed.
+func (h *Handlers) GetStatus(c echo.Context) error {
+	report := statusReport{
+		Report:       h.capabilities.Report(),
+		Dependencies: h.dependencies.Statuses(),
+		Limits:       h.dependencies.LimitStatuses(),
+	}
+	for _, dependency := range report.Dependencies {
+		if dependency.State != resilience.StateClosed {
+			report.Status = capability.StateDegraded
+		}
+	}
+	return success(c, report)
+}
+
+// requireCapability returns 503 with the registry's reason when a capability is disabled
+func (h *Handlers) requireCapability(name capability.Name) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if status := h.capabilities.Get(name); !status.Enabled {
+				return handleError(c, models.NewServiceUnavailableError(string(name), status.Reason))
+			}
+			return next(c)
+		}
+	}
+}
+
+// capabilityUnavailable answers every request with 503 for a disabled capability
+func (h *Handlers) capabilityUnavailable(name capability.Name) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		status := h.capabilities.Get(name)
+		return handleError(c, models.NewServiceUnavailableError(string(name), status.Reason))
+	}
+}
+
+// limitConcurrency runs the route holding a slot of limiter, answering 503
+// when none frees up in time; a nil limiter admits every request
+func limitConcurrency(limiter *resilience.Limiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			release, err := limiter.Acquire(c.Request().Context())
+			if err != nil {
+				return handleError(c, err)
+			}
+			defer release()
+			return next(c)
+		}
+	}
+}
@@ -18,7 +18,7 @@ func (h *Handlers) GetStreamURL(c echo.Context) error {
 		return handleError(c, models.ErrBadRequest)
 	}
 
-	resp, err := h.services.Stream.GetStreamURL(c.Request().Context(), auth.UserID, trackID, auth.HasGlobal)
+	resp, err := h.services.Stream.GetStreamURL(accessContext(c), auth.UserID, trackID, auth.HasGlobal)
 	if err != nil {
 		return handleError(c, err)
 	}
@@ -26,7 +26,9 @@ func (h *Handlers) GetStreamURL(c echo.Context) error {
 	return success(c, resp)
 }
 
-// GetDownloadURL returns a signed URL for downloading a track
+// GetDownloadURL returns a signed URL for downloading a track. Other users'
+// downloads of a protected track answer 202 with a watermark token instead;
+// the URL follows from GetWatermarkedDownload once the copy is ready.
 func (h *Handlers) GetDownloadURL(c echo.Context) error {
 	// Use DB role for real-time permission checking
 	auth := h.getAuthContextWithDBRole(c)
@@ -39,10 +41,10 @@ func (h *Handlers) GetDownloadURL(c echo.Context) error {
 		return handleError(c, models.ErrBadRequest)
 	}
 
-	resp, err := h.services.Stream.GetDownloadURL(c.Request().Context(), auth.UserID, trackID, auth.HasGlobal)
+	resp, err := h.services.Stream.GetDownloadURL(accessContext(c), auth.UserID, trackID, auth.HasGlobal)
 	if err != nil {
 		return handleError(c, err)
 	}
 
-	return success(c, resp)
+	return downloadResponse(c, resp)
 }
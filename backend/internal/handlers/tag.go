@@ -85,7 +85,8 @@ func (h *Handlers) UpdateTag(c echo.Context) error {
 	return success(c, tag)
 }
 
-// DeleteTag deletes a tag
+// DeleteTag deletes a tag; with ?dryRun=true it reports the tag without
+// deleting it
 func (h *Handlers) DeleteTag(c echo.Context) error {
 	userID := getUserIDFromContext(c)
 	if userID == "" {
@@ -97,10 +98,19 @@ func (h *Handlers) DeleteTag(c echo.Context) error {
 		return handleError(c, models.ErrBadRequest)
 	}
 
-	if err := h.services.Tag.DeleteTag(c.Request().Context(), userID, tagName); err != nil {
+	var dryRun bool
+	if err := bindDryRun(c, &dryRun); err != nil {
 		return handleError(c, err)
 	}
 
+	result, err := h.services.Tag.DeleteTag(c.Request().Context(), userID, tagName, dryRun)
+	if err != nil {
+		return handleError(c, err)
+	}
+	if result.DryRun {
+		return success(c, result)
+	}
+
 	return noContent(c)
 }
 
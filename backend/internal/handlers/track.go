@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 )
 
-// ListTracks returns a paginated list of tracks
+// ListTracks returns a paginated list of tracks, or only their count for
+// HEAD requests and countOnly=true
 // If user has GLOBAL permission, returns tracks from all users
 func (h *Handlers) ListTracks(c echo.Context) error {
 	// Use DB role for real-time permission checking
@@ -22,6 +25,14 @@ func (h *Handlers) ListTracks(c echo.Context) error {
 	// Set global scope if user has GLOBAL permission (admin)
 	filter.GlobalScope = auth.HasGlobal
 
+	if wantsCount(c, filter.CountOnly) {
+		count, err := h.services.Track.CountTracks(c.Request().Context(), auth.UserID, auth.HasGlobal)
+		if err != nil {
+			return handleError(c, err)
+		}
+		return successCount(c, count)
+	}
+
 	tracks, err := h.services.Track.ListTracks(c.Request().Context(), auth.UserID, filter)
 	if err != nil {
 		return handleError(c, err)
@@ -193,6 +204,32 @@ func (h *Handlers) UploadCoverArt(c echo.Context) error {
 	return success(c, resp)
 }
 
+// ReplaceTrackFile generates a presigned URL for uploading a replacement audio file.
+// The track keeps its ID, play counts, tags and playlist membership once processed.
+func (h *Handlers) ReplaceTrackFile(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	trackID := c.Param("id")
+	if trackID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.PresignedUploadRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	resp, err := h.services.Upload.CreateReplaceFileUpload(c.Request().Context(), userID, trackID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, resp)
+}
+
 // UpdateTrackVisibilityRequest is the request body for updating track visibility.
 type UpdateTrackVisibilityRequest struct {
 	Visibility models.TrackVisibility `json:"visibility" validate:"required"`
@@ -215,13 +252,131 @@ func (h *Handlers) UpdateTrackVisibility(c echo.Context) error {
 		return handleError(c, err)
 	}
 
-	err := h.services.Track.UpdateVisibility(c.Request().Context(), userID, trackID, req.Visibility)
+	update, err := h.services.Track.UpdateVisibility(c.Request().Context(), userID, trackID, req.Visibility)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	// A change held for moderation is accepted but not yet applied
+	if update.PendingVisibility != "" {
+		return c.JSON(http.StatusAccepted, update)
+	}
+	return success(c, update)
+}
+
+// UpdateTrackLock locks or unlocks a track.
+// Locked tracks reject metadata edits, deletion and bulk operations until unlocked.
+func (h *Handlers) UpdateTrackLock(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	trackID := c.Param("id")
+	if trackID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.UpdateTrackLockRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	track, err := h.services.Track.SetLocked(c.Request().Context(), userID, trackID, *req.Locked)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, track)
+}
+
+// UpdateTrackAnalysis corrects a track's analyzed BPM or key.
+// Corrections are kept when the track is analyzed again.
+func (h *Handlers) UpdateTrackAnalysis(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	trackID := c.Param("id")
+	if trackID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.UpdateTrackAnalysisRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	track, err := h.services.Track.UpdateAnalysis(c.Request().Context(), userID, trackID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, track)
+}
+
+// UpdateTrackPlaybackSettings changes a track's gain offset and EQ preset.
+// Clients read them from the stream URL response and apply them on playback.
+func (h *Handlers) UpdateTrackPlaybackSettings(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	trackID := c.Param("id")
+	if trackID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.UpdatePlaybackSettingsRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	track, err := h.services.Track.UpdatePlaybackSettings(c.Request().Context(), userID, trackID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, track)
+}
+
+// ListCleanupSuggestions suggests normalized titles and artist names for the user's tracks
+func (h *Handlers) ListCleanupSuggestions(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	suggestions, err := h.services.Cleanup.SuggestCleanups(c.Request().Context(), userID)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, suggestions)
+}
+
+// ApplyCleanupSuggestions applies accepted cleanup suggestions, one or many at a
+// time; with ?dryRun=true it reports the edits and tracks without saving them
+func (h *Handlers) ApplyCleanupSuggestions(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var req models.ApplyCleanupRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+	if err := bindDryRun(c, &req.DryRun); err != nil {
+		return handleError(c, err)
+	}
+
+	result, err := h.services.Cleanup.ApplyCleanups(c.Request().Context(), userID, req)
 	if err != nil {
 		return handleError(c, err)
 	}
 
-	return success(c, map[string]interface{}{
-		"trackId":    trackID,
-		"visibility": req.Visibility,
-	})
+	return success(c, result)
 }
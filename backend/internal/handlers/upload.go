@@ -25,6 +25,27 @@ func (h *Handlers) CreatePresignedUpload(c echo.Context) error {
 	return success(c, resp)
 }
 
+// PrecheckUpload reports whether an upload would be rejected before the
+// client transfers the file
+func (h *Handlers) PrecheckUpload(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	var req models.UploadPrecheckRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	resp, err := h.services.Upload.PrecheckUpload(c.Request().Context(), userID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, resp)
+}
+
 // ConfirmUpload confirms an upload and triggers processing
 func (h *Handlers) ConfirmUpload(c echo.Context) error {
 	userID := getUserIDFromContext(c)
@@ -129,3 +150,28 @@ func (h *Handlers) ReprocessUpload(c echo.Context) error {
 
 	return success(c, upload)
 }
+
+// ApplyEncodingFixes applies suggested tag encoding repairs to an upload's track
+func (h *Handlers) ApplyEncodingFixes(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+
+	uploadID := c.Param("id")
+	if uploadID == "" {
+		return handleError(c, models.ErrBadRequest)
+	}
+
+	var req models.ApplyEncodingFixesRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	result, err := h.services.TagRepair.ApplyEncodingFixes(c.Request().Context(), userID, uploadID, req)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, result)
+}
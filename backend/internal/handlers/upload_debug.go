@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetUploadDebugBundle handles GET /api/v1/uploads/:id/debug?userId=
+// Admin only - assembles the upload record, its state machine executions,
+// pipeline Lambda logs, object versions and search index entry for support.
+// Uploads are stored per library, so userId names the uploader.
+func (h *AdminHandler) GetUploadDebugBundle(c echo.Context) error {
+	if h.uploadDebug == nil {
+		return handleError(c, models.NewServiceUnavailableError("upload debug", "the upload debug bundle is not configured"))
+	}
+
+	userID := c.QueryParam("userId")
+	if userID == "" {
+		return handleError(c, models.NewValidationError("userId is required"))
+	}
+
+	bundle, err := h.uploadDebug.Bundle(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, bundle)
+}
@@ -218,9 +218,9 @@ func (h *Handlers) UpdateSettings(c echo.Context) error {
 			return handleError(c, models.NewNotFoundError("User", userID))
 		}
 		if err == service.ErrValidation {
-			return c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-				models.NewAPIError("VALIDATION_ERROR", "Invalid settings values", http.StatusBadRequest),
-			))
+			apiErr := models.NewAPIError("VALIDATION_ERROR", "Invalid settings values", http.StatusBadRequest)
+			apiErr.MessageKey = "validation.settings"
+			return handleError(c, apiErr)
 		}
 		return handleError(c, err)
 	}
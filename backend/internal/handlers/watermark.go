@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// SetDownloadProtection sets how other users' downloads of one of the current
+// user's tracks are watermarked: none, audible or inaudible
+// PUT /api/v1/tracks/:id/download-protection
+func (h *Handlers) SetDownloadProtection(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Watermarks == nil {
+		return handleError(c, watermarkUnavailable())
+	}
+
+	var req models.DownloadProtectionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return handleError(c, err)
+	}
+
+	protection, err := h.services.Watermarks.SetProtection(c.Request().Context(), userID, c.Param("id"), req.Mode())
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, protection)
+}
+
+// ListTrackDownloads returns the watermarked downloads of one of the current
+// user's tracks and who each was handed to
+// GET /api/v1/tracks/:id/downloads
+func (h *Handlers) ListTrackDownloads(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Watermarks == nil {
+		return handleError(c, watermarkUnavailable())
+	}
+
+	downloads, err := h.services.Watermarks.ListDownloads(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return successList(c, downloads)
+}
+
+// TraceTrackDownload returns who the token found in a leaked copy of one of
+// the current user's tracks was handed to
+// GET /api/v1/tracks/:id/downloads/:token
+func (h *Handlers) TraceTrackDownload(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Watermarks == nil {
+		return handleError(c, watermarkUnavailable())
+	}
+
+	download, err := h.services.Watermarks.TraceDownload(c.Request().Context(), userID, c.Param("id"), c.Param("token"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return success(c, download)
+}
+
+// GetWatermarkedDownload returns the download URL of a watermarked copy once
+// it is ready, and 202 while it is still being produced
+// GET /api/v1/download/:trackId/watermarked/:token
+func (h *Handlers) GetWatermarkedDownload(c echo.Context) error {
+	userID := getUserIDFromContext(c)
+	if userID == "" {
+		return handleError(c, models.ErrUnauthorized)
+	}
+	if h.services.Watermarks == nil {
+		return handleError(c, watermarkUnavailable())
+	}
+
+	resp, err := h.services.Watermarks.GetDownload(c.Request().Context(), userID, c.Param("trackId"), c.Param("token"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return downloadResponse(c, resp)
+}
+
+// downloadResponse answers 202 for a watermarked copy that has no URL yet
+func downloadResponse(c echo.Context, resp *models.DownloadResponse) error {
+	if resp.WatermarkStatus == models.DownloadTokenPending {
+		return c.JSON(http.StatusAccepted, resp)
+	}
+	return success(c, resp)
+}
+
+func watermarkUnavailable() error {
+	return models.NewServiceUnavailableError("download protection", "watermarking is not configured")
+}
@@ -0,0 +1,233 @@
+package i18n
+
+// catalog holds the message texts by language and key. Error messages are
+// keyed by their API error code, validation messages by "validation.<rule>",
+// upload failures by "upload.failed.<reason>" and notification templates by
+// "notification.<name>.subject" and ".body". Every key needs an English text;
+// a key missing in another language falls back to it.
+var catalog = map[string]map[string]string{
+	English: {
+		"NOT_FOUND":                "The requested resource was not found",
+		"NOT_FOUND.resource":       "{resource} with ID '{id}' was not found",
+		"UNAUTHORIZED":             "Authentication is required",
+		"FORBIDDEN":                "You do not have permission to access this resource",
+		"BAD_REQUEST":              "The request was invalid",
+		"INTERNAL_ERROR":           "An internal server error occurred",
+		"CONFLICT":                 "The request conflicts with existing data",
+		"PAYLOAD_TOO_LARGE":        "The file size exceeds the maximum allowed",
+		"UNSUPPORTED_MEDIA_TYPE":   "The file format is not supported",
+		"STORAGE_LIMIT_EXCEEDED":   "Your storage limit has been exceeded",
+		"UPLOAD_EXPIRED":           "The upload session has expired",
+		"UPLOAD_NOT_FOUND":         "The upload session was not found",
+		"MULTIPART_UPLOAD_FAILED":  "One or more parts of the multipart upload failed",
+		"UPLOAD_PROCESSING_FAILED": "Upload processing failed",
+		"TRACK_LOCKED":             "The track is locked and must be unlocked before it can be modified",
+		"NOT_IN_HOUSEHOLD":         "You are not a member of a household",
+		"INVALID_CURSOR":           "The pagination cursor is invalid or expired",
+		"UNDO_EXPIRED":             "The undo window for this operation has closed",
+		"VALIDATION_ERROR":         "The request failed validation",
+		"SERVICE_UNAVAILABLE":      "{capability} is not available",
+		"TOO_BUSY":                 "Too many {operation} operations are running, try again shortly",
+		"RATE_LIMITED":             "Too many requests, try again in {seconds} seconds",
+
+		"validation.invalid":     "{field} is invalid",
+		"validation.required":    "{field} is required",
+		"validation.min":         "{field} must be at least {param}",
+		"validation.min.string":  "{field} must be at least {param} characters long",
+		"validation.min.items":   "{field} must contain at least {param} items",
+		"validation.max":         "{field} must be at most {param}",
+		"validation.max.string":  "{field} must be at most {param} characters long",
+		"validation.max.items":   "{field} must contain at most {param} items",
+		"validation.len":         "{field} must be {param}",
+		"validation.len.string":  "{field} must be {param} characters long",
+		"validation.len.items":   "{field} must contain {param} items",
+		"validation.gte":         "{field} must be at least {param}",
+		"validation.gtefield":    "{field} must not be less than {param}",
+		"validation.oneof":       "{field} must be one of: {param}",
+		"validation.uuid":        "{field} must be a valid UUID",
+		"validation.url":         "{field} must be a valid URL",
+		"validation.email":       "{field} must be a valid email address",
+		"validation.hexcolor":    "{field} must be a hex color such as #1db954",
+		"validation.hexadecimal": "{field} must be hexadecimal",
+		"validation.dryRun":      "dryRun must be true or false",
+		"validation.settings":    "Invalid settings values",
+
+		"upload.failed.malware_detected":  "The file was rejected because it may contain malware",
+		"upload.failed.invalid_file":      "The file is not a supported audio file or is damaged",
+		"upload.failed.timeout":           "Processing took too long; try uploading the file again",
+		"upload.failed.processing_failed": "The file could not be processed; try uploading it again",
+
+		"notification.upload_failed.subject":  "Your upload of {fileName} failed",
+		"notification.upload_failed.body":     "We could not add {fileName} to your library. {reason}",
+		"notification.share_received.subject": "{sender} shared a track with you",
+		"notification.share_received.body":    "{sender} shared \"{track}\" with you. Open your library to accept or decline it.",
+	},
+	"de": {
+		"NOT_FOUND":                "Die angeforderte Ressource wurde nicht gefunden",
+		"NOT_FOUND.resource":       "{resource} mit der ID '{id}' wurde nicht gefunden",
+		"UNAUTHORIZED":             "Eine Anmeldung ist erforderlich",
+		"FORBIDDEN":                "Sie haben keine Berechtigung für diese Ressource",
+		"BAD_REQUEST":              "Die Anfrage ist ungültig",
+		"INTERNAL_ERROR":           "Ein interner Serverfehler ist aufgetreten",
+		"CONFLICT":                 "Die Anfrage steht im Konflikt mit vorhandenen Daten",
+		"PAYLOAD_TOO_LARGE":        "Die Datei ist größer als erlaubt",
+		"UNSUPPORTED_MEDIA_TYPE":   "Das Dateiformat wird nicht unterstützt",
+		"STORAGE_LIMIT_EXCEEDED":   "Ihr Speicherplatz ist aufgebraucht",
+		"UPLOAD_EXPIRED":           "Die Upload-Sitzung ist abgelaufen",
+		"UPLOAD_NOT_FOUND":         "Die Upload-Sitzung wurde nicht gefunden",
+		"MULTIPART_UPLOAD_FAILED":  "Mindestens ein Teil des mehrteiligen Uploads ist fehlgeschlagen",
+		"UPLOAD_PROCESSING_FAILED": "Die Verarbeitung des Uploads ist fehlgeschlagen",
+		"TRACK_LOCKED":             "Der Titel ist gesperrt und muss vor einer Änderung entsperrt werden",
+		"NOT_IN_HOUSEHOLD":         "Sie sind kein Mitglied eines Haushalts",
+		"INVALID_CURSOR":           "Der Seiten-Cursor ist ungültig oder abgelaufen",
+		"UNDO_EXPIRED":             "Diese Aktion kann nicht mehr rückgängig gemacht werden",
+		"VALIDATION_ERROR":         "Die Anfrage ist fehlerhaft",
+		"SERVICE_UNAVAILABLE":      "{capability} ist nicht verfügbar",
+		"TOO_BUSY":                 "Zu viele {operation}-Vorgänge laufen gerade, bitte versuchen Sie es gleich noch einmal",
+		"RATE_LIMITED":             "Zu viele Anfragen, bitte versuchen Sie es in {seconds} Sekunden erneut",
+
+		"validation.invalid":     "{field} ist ungültig",
+		"validation.required":    "{field} ist erforderlich",
+		"validation.min":         "{field} muss mindestens {param} sein",
+		"validation.min.string":  "{field} muss mindestens {param} Zeichen lang sein",
+		"validation.min.items":   "{field} muss mindestens {param} Einträge enthalten",
+		"validation.max":         "{field} darf höchstens {param} sein",
+		"validation.max.string":  "{field} darf höchstens {param} Zeichen lang sein",
+		"validation.max.items":   "{field} darf höchstens {param} Einträge enthalten",
+		"validation.len":         "{field} muss {param} sein",
+		"validation.len.string":  "{field} muss {param} Zeichen lang sein",
+		"validation.len.items":   "{field} muss {param} Einträge enthalten",
+		"validation.gte":         "{field} muss mindestens {param} sein",
+		"validation.gtefield":    "{field} darf nicht kleiner als {param} sein",
+		"validation.oneof":       "{field} muss einer dieser Werte sein: {param}",
+		"validation.uuid":        "{field} muss eine gültige UUID sein",
+		"validation.url":         "{field} muss eine gültige URL sein",
+		"validation.email":       "{field} muss eine gültige E-Mail-Adresse sein",
+		"validation.hexcolor":    "{field} muss eine Hex-Farbe wie #1db954 sein",
+		"validation.hexadecimal": "{field} muss hexadezimal sein",
+		"validation.dryRun":      "dryRun muss true oder false sein",
+		"validation.settings":    "Ungültige Einstellungen",
+
+		"upload.failed.malware_detected":  "Die Datei wurde abgelehnt, weil sie Schadsoftware enthalten könnte",
+		"upload.failed.invalid_file":      "Die Datei ist keine unterstützte Audiodatei oder beschädigt",
+		"upload.failed.timeout":           "Die Verarbeitung hat zu lange gedauert; bitte laden Sie die Datei erneut hoch",
+		"upload.failed.processing_failed": "Die Datei konnte nicht verarbeitet werden; bitte laden Sie sie erneut hoch",
+
+		"notification.upload_failed.subject":  "Der Upload von {fileName} ist fehlgeschlagen",
+		"notification.upload_failed.body":     "{fileName} konnte nicht zu Ihrer Bibliothek hinzugefügt werden. {reason}",
+		"notification.share_received.subject": "{sender} hat einen Titel mit Ihnen geteilt",
+		"notification.share_received.body":    "{sender} hat „{track}“ mit Ihnen geteilt. Öffnen Sie Ihre Bibliothek, um ihn anzunehmen oder abzulehnen.",
+	},
+	"fr": {
+		"NOT_FOUND":                "La ressource demandée est introuvable",
+		"NOT_FOUND.resource":       "{resource} avec l'ID '{id}' est introuvable",
+		"UNAUTHORIZED":             "Une authentification est requise",
+		"FORBIDDEN":                "Vous n'avez pas l'autorisation d'accéder à cette ressource",
+		"BAD_REQUEST":              "La requête est invalide",
+		"INTERNAL_ERROR":           "Une erreur interne du serveur s'est produite",
+		"CONFLICT":                 "La requête est en conflit avec des données existantes",
+		"PAYLOAD_TOO_LARGE":        "La taille du fichier dépasse le maximum autorisé",
+		"UNSUPPORTED_MEDIA_TYPE":   "Le format de fichier n'est pas pris en charge",
+		"STORAGE_LIMIT_EXCEEDED":   "Votre limite de stockage est dépassée",
+		"UPLOAD_EXPIRED":           "La session d'envoi a expiré",
+		"UPLOAD_NOT_FOUND":         "La session d'envoi est introuvable",
+		"MULTIPART_UPLOAD_FAILED":  "Une ou plusieurs parties de l'envoi en plusieurs parties ont échoué",
+		"UPLOAD_PROCESSING_FAILED": "Le traitement de l'envoi a échoué",
+		"TRACK_LOCKED":             "Le morceau est verrouillé et doit être déverrouillé avant d'être modifié",
+		"NOT_IN_HOUSEHOLD":         "Vous n'êtes membre d'aucun foyer",
+		"INVALID_CURSOR":           "Le curseur de pagination est invalide ou expiré",
+		"UNDO_EXPIRED":             "Le délai d'annulation de cette opération est écoulé",
+		"VALIDATION_ERROR":         "La requête n'est pas valide",
+		"SERVICE_UNAVAILABLE":      "{capability} n'est pas disponible",
+		"TOO_BUSY":                 "Trop d'opérations {operation} sont en cours, réessayez dans un instant",
+		"RATE_LIMITED":             "Trop de requêtes, réessayez dans {seconds} secondes",
+
+		"validation.invalid":     "{field} est invalide",
+		"validation.required":    "{field} est obligatoire",
+		"validation.min":         "{field} doit être au moins {param}",
+		"validation.min.string":  "{field} doit contenir au moins {param} caractères",
+		"validation.min.items":   "{field} doit contenir au moins {param} éléments",
+		"validation.max":         "{field} doit être au plus {param}",
+		"validation.max.string":  "{field} doit contenir au plus {param} caractères",
+		"validation.max.items":   "{field} doit contenir au plus {param} éléments",
+		"validation.len":         "{field} doit être {param}",
+		"validation.len.string":  "{field} doit contenir {param} caractères",
+		"validation.len.items":   "{field} doit contenir {param} éléments",
+		"validation.gte":         "{field} doit être au moins {param}",
+		"validation.gtefield":    "{field} ne doit pas être inférieur à {param}",
+		"validation.oneof":       "{field} doit être l'une de ces valeurs : {param}",
+		"validation.uuid":        "{field} doit être un UUID valide",
+		"validation.url":         "{field} doit être une URL valide",
+		"validation.email":       "{field} doit être une adresse e-mail valide",
+		"validation.hexcolor":    "{field} doit être une couleur hexadécimale comme #1db954",
+		"validation.hexadecimal": "{field} doit être hexadécimal",
+		"validation.dryRun":      "dryRun doit valoir true ou false",
+		"validation.settings":    "Valeurs de paramètres invalides",
+
+		"upload.failed.malware_detected":  "Le fichier a été refusé car il pourrait contenir un logiciel malveillant",
+		"upload.failed.invalid_file":      "Le fichier n'est pas un fichier audio pris en charge ou il est endommagé",
+		"upload.failed.timeout":           "Le traitement a pris trop de temps ; essayez d'envoyer le fichier à nouveau",
+		"upload.failed.processing_failed": "Le fichier n'a pas pu être traité ; essayez de l'envoyer à nouveau",
+
+		"notification.upload_failed.subject":  "L'envoi de {fileName} a échoué",
+		"notification.upload_failed.body":     "{fileName} n'a pas pu être ajouté à votre bibliothèque. {reason}",
+		"notification.share_received.subject": "{sender} a partagé un morceau avec vous",
+		"notification.share_received.body":    "{sender} a partagé « {track} » avec vous. Ouvrez votre bibliothèque pour l'accepter ou le refuser.",
+	},
+	"es": {
+		"NOT_FOUND":                "No se encontró el recurso solicitado",
+		"NOT_FOUND.resource":       "No se encontró {resource} con el ID '{id}'",
+		"UNAUTHORIZED":             "Se requiere autenticación",
+		"FORBIDDEN":                "No tienes permiso para acceder a este recurso",
+		"BAD_REQUEST":              "La solicitud no es válida",
+		"INTERNAL_ERROR":           "Se produjo un error interno del servidor",
+		"CONFLICT":                 "La solicitud entra en conflicto con datos existentes",
+		"PAYLOAD_TOO_LARGE":        "El archivo supera el tamaño máximo permitido",
+		"UNSUPPORTED_MEDIA_TYPE":   "El formato de archivo no es compatible",
+		"STORAGE_LIMIT_EXCEEDED":   "Has superado tu límite de almacenamiento",
+		"UPLOAD_EXPIRED":           "La sesión de subida ha caducado",
+		"UPLOAD_NOT_FOUND":         "No se encontró la sesión de subida",
+		"MULTIPART_UPLOAD_FAILED":  "Una o más partes de la subida multiparte fallaron",
+		"UPLOAD_PROCESSING_FAILED": "El procesamiento de la subida falló",
+		"TRACK_LOCKED":             "La pista está bloqueada y debe desbloquearse antes de modificarla",
+		"NOT_IN_HOUSEHOLD":         "No eres miembro de ningún hogar",
+		"INVALID_CURSOR":           "El cursor de paginación no es válido o ha caducado",
+		"UNDO_EXPIRED":             "El plazo para deshacer esta operación ha terminado",
+		"VALIDATION_ERROR":         "La solicitud no superó la validación",
+		"SERVICE_UNAVAILABLE":      "{capability} no está disponible",
+		"TOO_BUSY":                 "Hay demasiadas operaciones de {operation} en curso, inténtalo de nuevo en breve",
+		"RATE_LIMITED":             "Demasiadas solicitudes, inténtalo de nuevo en {seconds} segundos",
+
+		"validation.invalid":     "{field} no es válido",
+		"validation.required":    "{field} es obligatorio",
+		"validation.min":         "{field} debe ser al menos {param}",
+		"validation.min.string":  "{field} debe tener al menos {param} caracteres",
+		"validation.min.items":   "{field} debe contener al menos {param} elementos",
+		"validation.max":         "{field} debe ser como máximo {param}",
+		"validation.max.string":  "{field} debe tener como máximo {param} caracteres",
+		"validation.max.items":   "{field} debe contener como máximo {param} elementos",
+		"validation.len":         "{field} debe ser {param}",
+		"validation.len.string":  "{field} debe tener {param} caracteres",
+		"validation.len.items":   "{field} debe contener {param} elementos",
+		"validation.gte":         "{field} debe ser al menos {param}",
+		"validation.gtefield":    "{field} no debe ser menor que {param}",
+		"validation.oneof":       "{field} debe ser uno de: {param}",
+		"validation.uuid":        "{field} debe ser un UUID válido",
+		"validation.url":         "{field} debe ser una URL válida",
+		"validation.email":       "{field} debe ser una dirección de correo válida",
+		"validation.hexcolor":    "{field} debe ser un color hexadecimal como #1db954",
+		"validation.hexadecimal": "{field} debe ser hexadecimal",
+		"validation.dryRun":      "dryRun debe ser true o false",
+		"validation.settings":    "Valores de configuración no válidos",
+
+		"upload.failed.malware_detected":  "El archivo fue rechazado porque podría contener malware",
+		"upload.failed.invalid_file":      "El archivo no es un archivo de audio compatible o está dañado",
+		"upload.failed.timeout":           "El procesamiento tardó demasiado; vuelve a subir el archivo",
+		"upload.failed.processing_failed": "No se pudo procesar el archivo; vuelve a subirlo",
+
+		"notification.upload_failed.subject":  "La subida de {fileName} falló",
+		"notification.upload_failed.body":     "No pudimos añadir {fileName} a tu biblioteca. {reason}",
+		"notification.share_received.subject": "{sender} compartió una pista contigo",
+		"notification.share_received.body":    "{sender} compartió «{track}» contigo. Abre tu biblioteca para aceptarla o rechazarla.",
+	},
+}
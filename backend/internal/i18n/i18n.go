@@ -0,0 +1,149 @@
+// Package i18n translates user-facing messages: API error messages,
+// validation failures, upload failure reasons and notification templates.
+// Each message has an English text and may have translations; anything not
+// translated falls back to English.
+package i18n
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// English is the fallback language every message has a text in
+const English = "en"
+
+// Languages are the languages with a catalog, English first
+var Languages = []string{English, "de", "fr", "es"}
+
+var matcher = language.NewMatcher([]language.Tag{
+	language.English, language.German, language.French, language.Spanish,
+})
+
+// ErrUnsupported is returned by Validate for a language without a catalog
+var ErrUnsupported = errors.New("unsupported language")
+
+// Match returns the supported language closest to the first preference that
+// matches one. A preference is a BCP 47 tag ("de-AT") or an Accept-Language
+// header ("fr-CH, fr;q=0.9, en;q=0.8"); empty and unparsable ones are
+// skipped. It returns English if none matches.
+func Match(preferences ...string) string {
+	for _, preference := range preferences {
+		if preference == "" {
+			continue
+		}
+		tags, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+		if _, index, confidence := matcher.Match(tags...); confidence != language.No {
+			return Languages[index]
+		}
+	}
+	return English
+}
+
+// Validate returns an error if lang is neither empty, meaning no preference,
+// nor a tag matching one of the supported languages
+func Validate(lang string) error {
+	if lang == "" {
+		return nil
+	}
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return err
+	}
+	if _, _, confidence := matcher.Match(tag); confidence == language.No {
+		return ErrUnsupported
+	}
+	return nil
+}
+
+// Text returns the message key in lang with its {placeholders} replaced by
+// args, the English text if lang has no translation of it, and false if the
+// key is unknown.
+func Text(lang, key string, args map[string]string) (string, bool) {
+	text, ok := catalog[lang][key]
+	if !ok {
+		text, ok = catalog[English][key]
+	}
+	if !ok {
+		return "", false
+	}
+	if len(args) == 0 {
+		return text, true
+	}
+	pairs := make([]string, 0, 2*len(args))
+	for name, value := range args {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text), true
+}
+
+// FieldError returns the message for a struct field that failed the
+// validation rule (a validator tag such as "required" or "max") with its
+// parameter. kind is "string" or "items" for length rules on text and
+// collections, so "max" reads as characters or items; empty otherwise.
+func FieldError(lang, field, rule, param, kind string) string {
+	args := map[string]string{"field": field, "param": param}
+	if kind != "" {
+		if text, ok := Text(lang, "validation."+rule+"."+kind, args); ok {
+			return text
+		}
+	}
+	if text, ok := Text(lang, "validation."+rule, args); ok {
+		return text
+	}
+	text, _ := Text(lang, "validation.invalid", args)
+	return text
+}
+
+// Notification returns the subject and body of the notification template
+// name in lang, falling back to English like Text
+func Notification(lang, name string, args map[string]string) (subject, body string, ok bool) {
+	subject, ok = Text(lang, "notification."+name+".subject", args)
+	if !ok {
+		return "", "", false
+	}
+	body, ok = Text(lang, "notification."+name+".body", args)
+	return subject, body, ok
+}
+
+type contextKey struct{}
+
+// preference resolves a request's language once, on first use
+type preference struct {
+	once    sync.Once
+	resolve func() string
+	lang    string
+}
+
+// WithLanguage returns a context whose messages are in lang
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	p := &preference{lang: lang}
+	p.once.Do(func() {})
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// WithLanguageFunc returns a context whose message language is resolved by
+// calling resolve the first time it is needed, so requests that never
+// produce a message skip looking up the user's setting
+func WithLanguageFunc(ctx context.Context, resolve func() string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &preference{resolve: resolve})
+}
+
+// FromContext returns the message language of ctx, English if none was set
+func FromContext(ctx context.Context) string {
+	p, ok := ctx.Value(contextKey{}).(*preference)
+	if !ok {
+		return English
+	}
+	p.once.Do(func() { p.lang = p.resolve() })
+	if p.lang == "" {
+		return English
+	}
+	return p.lang
+}
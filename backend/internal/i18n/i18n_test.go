@@ -0,0 +1,121 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences []string
+		want        string
+	}{
+		{"no preference", nil, English},
+		{"plain tag", []string{"de"}, "de"},
+		{"regional variant", []string{"fr-CA"}, "fr"},
+		{"accept-language order", []string{"it-IT, es;q=0.8, en;q=0.5"}, "es"},
+		{"quality weights", []string{"en;q=0.3, de;q=0.9"}, "de"},
+		{"unsupported falls back", []string{"ja"}, English},
+		{"unparsable is skipped", []string{"!!", "fr"}, "fr"},
+		{"setting before header", []string{"es", "de-DE,de;q=0.9"}, "es"},
+		{"empty setting uses header", []string{"", "de-DE,de;q=0.9"}, "de"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(tt.preferences...))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(""))
+	assert.NoError(t, Validate("de"))
+	assert.NoError(t, Validate("es-MX"))
+	assert.ErrorIs(t, Validate("ja"), ErrUnsupported)
+	assert.Error(t, Validate("not a language"))
+}
+
+func TestText(t *testing.T) {
+	t.Run("replaces placeholders", func(t *testing.T) {
+		text, ok := Text("de", "NOT_FOUND.resource", map[string]string{"resource": "Track", "id": "t1"})
+		require.True(t, ok)
+		assert.Equal(t, "Track mit der ID 't1' wurde nicht gefunden", text)
+	})
+
+	t.Run("falls back to English", func(t *testing.T) {
+		text, ok := Text("xx", "TRACK_LOCKED", nil)
+		require.True(t, ok)
+		assert.Equal(t, catalog[English]["TRACK_LOCKED"], text)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, ok := Text(English, "NO_SUCH_KEY", nil)
+		assert.False(t, ok)
+	})
+
+	t.Run("placeholders in values are not expanded", func(t *testing.T) {
+		text, _ := Text(English, "NOT_FOUND.resource", map[string]string{"resource": "{id}", "id": "x"})
+		assert.Equal(t, "{id} with ID 'x' was not found", text)
+	})
+}
+
+func TestFieldError(t *testing.T) {
+	assert.Equal(t, "Name is required", FieldError(English, "Name", "required", "", "string"))
+	assert.Equal(t, "Name must be at most 255 characters long", FieldError(English, "Name", "max", "255", "string"))
+	assert.Equal(t, "TrackIDs must contain at most 100 items", FieldError(English, "TrackIDs", "max", "100", "items"))
+	assert.Equal(t, "Limit must be at most 100", FieldError(English, "Limit", "max", "100", ""))
+	assert.Equal(t, "Name darf höchstens 255 Zeichen lang sein", FieldError("de", "Name", "max", "255", "string"))
+	assert.Equal(t, "Code est invalide", FieldError("fr", "Code", "startswith", "x", "string"))
+}
+
+func TestNotification(t *testing.T) {
+	subject, body, ok := Notification("fr", "upload_failed", map[string]string{
+		"fileName": "song.mp3",
+		"reason":   "Le fichier n'a pas pu être traité.",
+	})
+	require.True(t, ok)
+	assert.Equal(t, "L'envoi de song.mp3 a échoué", subject)
+	assert.Contains(t, body, "song.mp3")
+
+	_, _, ok = Notification(English, "no_such_template", nil)
+	assert.False(t, ok)
+}
+
+func TestCatalog(t *testing.T) {
+	placeholder := regexp.MustCompile(`\{[a-zA-Z]+\}`)
+	for _, lang := range Languages {
+		require.Contains(t, catalog, lang)
+	}
+	for lang, messages := range catalog {
+		for key, text := range messages {
+			english, ok := catalog[English][key]
+			if !assert.True(t, ok, "%s: %q has no English text", lang, key) {
+				continue
+			}
+			assert.ElementsMatch(t, placeholder.FindAllString(english, -1), placeholder.FindAllString(text, -1),
+				"%s: %q uses different placeholders than English", lang, key)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, English, FromContext(context.Background()))
+	assert.Equal(t, "de", FromContext(WithLanguage(context.Background(), "de")))
+
+	calls := 0
+	ctx := WithLanguageFunc(context.Background(), func() string {
+		calls++
+		return "fr"
+	})
+	assert.Equal(t, 0, calls, "resolved before use")
+	assert.Equal(t, "fr", FromContext(ctx))
+	assert.Equal(t, "fr", FromContext(ctx))
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, English, FromContext(WithLanguageFunc(context.Background(), func() string { return "" })))
+}
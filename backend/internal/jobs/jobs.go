@@ -0,0 +1,104 @@
+// Package jobs runs background work outside the request path, for servers
+// without Step Functions or dedicated Lambdas. A Queue holds jobs, a Worker
+// runs them on a pool of goroutines with retries, and a Scheduler enqueues
+// periodic jobs. MemoryQueue suits a single self-hosted server; SQSQueue
+// survives restarts and shares the work between servers.
+//
+// Delivery is at least once: a job whose worker stops before acknowledging it
+// is delivered again, so handlers must be safe to repeat.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job is one unit of background work
+type Job struct {
+	// ID identifies the job in logs
+	ID string `json:"id"`
+	// Type selects the Handler that runs the job
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	EnqueuedAt time.Time       `json:"enqueuedAt"`
+}
+
+// New creates a job of jobType carrying payload as JSON; a nil payload is
+// left out
+func New(jobType string, payload interface{}) (Job, error) {
+	job := Job{ID: uuid.NewString(), Type: jobType, EnqueuedAt: time.Now().UTC()}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return Job{}, fmt.Errorf("failed to encode %s job: %w", jobType, err)
+		}
+		job.Payload = data
+	}
+	return job, nil
+}
+
+// Decode unmarshals the job's payload into v
+func (j Job) Decode(v interface{}) error {
+	if len(j.Payload) == 0 {
+		return Permanent(fmt.Errorf("%s job has no payload", j.Type))
+	}
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return Permanent(fmt.Errorf("invalid %s job payload: %w", j.Type, err))
+	}
+	return nil
+}
+
+// Delivery is a job received from a queue, to be acknowledged or retried
+type Delivery struct {
+	Job Job
+	// Attempt counts the deliveries of the job, from 1
+	Attempt int
+	// receipt identifies the delivery to its queue
+	receipt string
+}
+
+// Queue holds jobs until a worker runs them
+type Queue interface {
+	// Enqueue adds a job, delivered no sooner than delay from now
+	Enqueue(ctx context.Context, job Job, delay time.Duration) error
+	// Receive waits up to wait for jobs and returns at most max of them. A
+	// job neither acknowledged nor retried within the queue's visibility
+	// timeout is delivered again.
+	Receive(ctx context.Context, max int, wait time.Duration) ([]Delivery, error)
+	// Ack removes a delivered job from the queue
+	Ack(ctx context.Context, delivery Delivery) error
+	// Retry delivers a job again after delay
+	Retry(ctx context.Context, delivery Delivery, delay time.Duration) error
+}
+
+// Handler runs one job. A returned error retries the job, unless it is
+// wrapped with Permanent.
+type Handler func(ctx context.Context, job Job) error
+
+// permanentError marks a failure retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as a failure that retrying cannot fix, such as an
+// invalid payload; the worker gives the job up at once
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
@@ -0,0 +1,265 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runWorker runs w until the test ends
+func runWorker(t *testing.T, w *Worker) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func newTestWorker(queue Queue) *Worker {
+	w := NewWorker(queue, 2)
+	w.SetRetries(3, time.Millisecond, 5*time.Millisecond)
+	w.SetPollWait(50 * time.Millisecond)
+	return w
+}
+
+func TestMemoryQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("delivers jobs after their delay", func(t *testing.T) {
+		q := NewMemoryQueue(time.Minute)
+		now := time.Now()
+		q.now = func() time.Time { return now }
+
+		later, _ := New("later", nil)
+		soon, _ := New("soon", nil)
+		require.NoError(t, q.Enqueue(ctx, later, time.Hour))
+		require.NoError(t, q.Enqueue(ctx, soon, 0))
+
+		deliveries, err := q.Receive(ctx, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, "soon", deliveries[0].Job.Type)
+		assert.Equal(t, 1, deliveries[0].Attempt)
+
+		now = now.Add(2 * time.Hour)
+		deliveries, err = q.Receive(ctx, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2, "the unacknowledged job is delivered again")
+		attempts := map[string]int{}
+		for _, delivery := range deliveries {
+			attempts[delivery.Job.Type] = delivery.Attempt
+		}
+		assert.Equal(t, map[string]int{"later": 1, "soon": 2}, attempts)
+	})
+
+	t.Run("only the latest delivery acknowledges", func(t *testing.T) {
+		q := NewMemoryQueue(time.Minute)
+		now := time.Now()
+		q.now = func() time.Time { return now }
+
+		job, _ := New("a", nil)
+		require.NoError(t, q.Enqueue(ctx, job, 0))
+		first, err := q.Receive(ctx, 1, 0)
+		require.NoError(t, err)
+		now = now.Add(2 * time.Minute)
+		second, err := q.Receive(ctx, 1, 0)
+		require.NoError(t, err)
+
+		assert.Error(t, q.Ack(ctx, first[0]))
+		assert.NoError(t, q.Ack(ctx, second[0]))
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("wakes a waiting receiver", func(t *testing.T) {
+		q := NewMemoryQueue(0)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			job, _ := New("a", nil)
+			_ = q.Enqueue(ctx, job, 0)
+		}()
+		deliveries, err := q.Receive(ctx, 1, 5*time.Second)
+		require.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+	})
+}
+
+func TestWorker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries failed jobs until they succeed", func(t *testing.T) {
+		q := NewMemoryQueue(0)
+		w := newTestWorker(q)
+		var calls atomic.Int32
+		done := make(chan struct{})
+		w.Handle("flaky", func(ctx context.Context, job Job) error {
+			if calls.Add(1) < 3 {
+				return errors.New("temporarily unavailable")
+			}
+			close(done)
+			return nil
+		})
+		runWorker(t, w)
+
+		job, _ := New("flaky", nil)
+		require.NoError(t, q.Enqueue(ctx, job, 0))
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("job did not succeed")
+		}
+		assert.Eventually(t, func() bool { return q.Len() == 0 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("gives up permanent failures, unknown types and exhausted retries", func(t *testing.T) {
+		q := NewMemoryQueue(0)
+		w := newTestWorker(q)
+		var mu sync.Mutex
+		failed := map[string]int{}
+		w.OnFailure(func(ctx context.Context, delivery Delivery, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed[delivery.Job.Type] = delivery.Attempt
+		})
+		w.Handle("invalid", func(ctx context.Context, job Job) error {
+			var payload struct{ TrackID string }
+			return job.Decode(&payload)
+		})
+		w.Handle("broken", func(ctx context.Context, job Job) error {
+			panic("nil map")
+		})
+		runWorker(t, w)
+
+		for _, jobType := range []string{"invalid", "unknown", "broken"} {
+			job, _ := New(jobType, nil)
+			require.NoError(t, q.Enqueue(ctx, job, 0))
+		}
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(failed) == 3
+		}, 5*time.Second, 5*time.Millisecond)
+
+		assert.Equal(t, map[string]int{"invalid": 1, "unknown": 1, "broken": 3}, failed)
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("leaves jobs cut short by shutdown on the queue", func(t *testing.T) {
+		q := NewMemoryQueue(0)
+		w := newTestWorker(q)
+		started := make(chan struct{})
+		w.Handle("slow", func(ctx context.Context, job Job) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			w.Run(runCtx)
+			close(done)
+		}()
+
+		job, _ := New("slow", nil)
+		require.NoError(t, q.Enqueue(ctx, job, 0))
+		<-started
+		cancel()
+		<-done
+		assert.Equal(t, 1, q.Len())
+	})
+}
+
+func TestScheduler(t *testing.T) {
+	q := NewMemoryQueue(0)
+	s := NewScheduler(q)
+	s.Every(20*time.Millisecond, "rollup", map[string]string{"period": "daily"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return q.Len() >= 2 }, 5*time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	deliveries, err := q.Receive(context.Background(), 1, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	var payload map[string]string
+	require.NoError(t, deliveries[0].Job.Decode(&payload))
+	assert.Equal(t, "daily", payload["period"])
+}
+
+// fakeSQS records the calls made by SQSQueue
+type fakeSQS struct {
+	sent       []*sqs.SendMessageInput
+	messages   []types.Message
+	deleted    []string
+	visibility map[string]int32
+}
+
+func (f *fakeSQS) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	f.sent = append(f.sent, params)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func (f *fakeSQS) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	return &sqs.ReceiveMessageOutput{Messages: f.messages}, nil
+}
+
+func (f *fakeSQS) DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	f.deleted = append(f.deleted, aws.ToString(params.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (f *fakeSQS) ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	f.visibility[aws.ToString(params.ReceiptHandle)] = params.VisibilityTimeout
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+func TestSQSQueue(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeSQS{visibility: map[string]int32{}}
+	q := NewSQSQueue(client, "https://sqs.example/jobs", 0)
+
+	job, err := New("rollup", map[string]int{"days": 1})
+	require.NoError(t, err)
+	require.NoError(t, q.Enqueue(ctx, job, time.Hour))
+	require.Len(t, client.sent, 1)
+	assert.Equal(t, int32(900), client.sent[0].DelaySeconds, "delays are capped at 15 minutes")
+
+	client.messages = []types.Message{
+		{Body: client.sent[0].MessageBody, ReceiptHandle: aws.String("r1"), Attributes: map[string]string{"ApproximateReceiveCount": "2"}},
+		{Body: aws.String("not json"), MessageId: aws.String("m2"), ReceiptHandle: aws.String("r2")},
+	}
+	deliveries, err := q.Receive(ctx, 20, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, job.ID, deliveries[0].Job.ID)
+	assert.Equal(t, "rollup", deliveries[0].Job.Type)
+	assert.Equal(t, 2, deliveries[0].Attempt)
+	assert.Equal(t, "m2", deliveries[1].Job.ID)
+	assert.Empty(t, deliveries[1].Job.Type)
+	assert.Equal(t, 1, deliveries[1].Attempt)
+
+	require.NoError(t, q.Ack(ctx, deliveries[1]))
+	require.NoError(t, q.Retry(ctx, deliveries[0], 30*time.Second))
+	assert.Equal(t, []string{"r2"}, client.deleted)
+	assert.Equal(t, int32(30), client.visibility["r1"])
+}
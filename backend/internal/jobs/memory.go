@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultVisibilityTimeout is how long a delivered job stays hidden from
+// other receivers before it is delivered again
+const DefaultVisibilityTimeout = 5 * time.Minute
+
+// MemoryQueue is a Queue in process memory, for a single server. Jobs are
+// lost when the process exits.
+type MemoryQueue struct {
+	mu         sync.Mutex
+	jobs       []*memoryJob
+	visibility time.Duration
+	// wake is signalled whenever a job is added or made visible again
+	wake   chan struct{}
+	nextID int
+	now    func() time.Time
+}
+
+// memoryJob is a queued job and when it may next be delivered
+type memoryJob struct {
+	job       Job
+	visibleAt time.Time
+	attempts  int
+	// receipt changes on every delivery, so only the latest one can ack
+	receipt string
+}
+
+// NewMemoryQueue creates an empty queue; visibility 0 uses
+// DefaultVisibilityTimeout
+func NewMemoryQueue(visibility time.Duration) *MemoryQueue {
+	if visibility <= 0 {
+		visibility = DefaultVisibilityTimeout
+	}
+	return &MemoryQueue{
+		visibility: visibility,
+		wake:       make(chan struct{}, 1),
+		now:        time.Now,
+	}
+}
+
+// Len returns the number of jobs queued or in flight
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+func (q *MemoryQueue) Enqueue(ctx context.Context, job Job, delay time.Duration) error {
+	q.mu.Lock()
+	q.jobs = append(q.jobs, &memoryJob{job: job, visibleAt: q.now().Add(delay)})
+	q.mu.Unlock()
+	q.signal()
+	return nil
+}
+
+// signal wakes one waiting receiver, if any
+func (q *MemoryQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *MemoryQueue) Receive(ctx context.Context, max int, wait time.Duration) ([]Delivery, error) {
+	deadline := q.now().Add(wait)
+	for {
+		deliveries, next := q.take(max)
+		if len(deliveries) > 0 {
+			return deliveries, nil
+		}
+
+		now := q.now()
+		if !now.Before(deadline) {
+			return nil, nil
+		}
+		sleep := deadline.Sub(now)
+		if !next.IsZero() && next.Sub(now) < sleep {
+			sleep = next.Sub(now)
+		}
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-q.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// take delivers up to max visible jobs, hiding them for the visibility
+// timeout. With none visible it returns when the next job becomes visible.
+func (q *MemoryQueue) take(max int) ([]Delivery, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	var deliveries []Delivery
+	var next time.Time
+	for _, queued := range q.jobs {
+		if queued.visibleAt.After(now) {
+			if next.IsZero() || queued.visibleAt.Before(next) {
+				next = queued.visibleAt
+			}
+			continue
+		}
+		if len(deliveries) == max {
+			break
+		}
+		q.nextID++
+		queued.attempts++
+		queued.receipt = fmt.Sprintf("%s#%d", queued.job.ID, q.nextID)
+		queued.visibleAt = now.Add(q.visibility)
+		deliveries = append(deliveries, Delivery{Job: queued.job, Attempt: queued.attempts, receipt: queued.receipt})
+	}
+	return deliveries, next
+}
+
+func (q *MemoryQueue) Ack(ctx context.Context, delivery Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, queued := range q.jobs {
+		if queued.receipt == delivery.receipt {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("job %s is no longer delivered to this receiver", delivery.Job.ID)
+}
+
+func (q *MemoryQueue) Retry(ctx context.Context, delivery Delivery, delay time.Duration) error {
+	q.mu.Lock()
+	found := false
+	for _, queued := range q.jobs {
+		if queued.receipt == delivery.receipt {
+			queued.visibleAt = q.now().Add(delay)
+			found = true
+			break
+		}
+	}
+	q.mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("job %s is no longer delivered to this receiver", delivery.Job.ID)
+	}
+	q.signal()
+	return nil
+}
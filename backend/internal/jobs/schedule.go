@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Scheduler enqueues jobs at fixed intervals. Each server running a
+// Scheduler enqueues its own copies, so run one per queue.
+type Scheduler struct {
+	queue   Queue
+	entries []scheduleEntry
+}
+
+// scheduleEntry is a job enqueued every interval
+type scheduleEntry struct {
+	interval time.Duration
+	jobType  string
+	payload  interface{}
+}
+
+// NewScheduler creates a scheduler enqueuing onto queue
+func NewScheduler(queue Queue) *Scheduler {
+	return &Scheduler{queue: queue}
+}
+
+// Every enqueues a jobType job carrying payload every interval, starting
+// when Run is called; call before Run
+func (s *Scheduler) Every(interval time.Duration, jobType string, payload interface{}) {
+	s.entries = append(s.entries, scheduleEntry{interval: interval, jobType: jobType, payload: payload})
+}
+
+// Run enqueues the scheduled jobs until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, entry := range s.entries {
+		if entry.interval <= 0 {
+			log.Printf("Skipping %s schedule with interval %s", entry.jobType, entry.interval)
+			continue
+		}
+		wg.Add(1)
+		go func(entry scheduleEntry) {
+			defer wg.Done()
+			s.run(ctx, entry)
+		}(entry)
+	}
+	wg.Wait()
+}
+
+// run enqueues one entry now and then every interval
+func (s *Scheduler) run(ctx context.Context, entry scheduleEntry) {
+	ticker := time.NewTicker(entry.interval)
+	defer ticker.Stop()
+	for {
+		s.enqueue(ctx, entry)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) enqueue(ctx context.Context, entry scheduleEntry) {
+	job, err := New(entry.jobType, entry.payload)
+	if err == nil {
+		err = s.queue.Enqueue(ctx, job, 0)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Failed to schedule %s job: %v", entry.jobType, err)
+	}
+}
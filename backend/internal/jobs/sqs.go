@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// SQS limits on a single call
+const (
+	sqsMaxMessages   = 10
+	sqsMaxWait       = 20 * time.Second
+	sqsMaxDelay      = 15 * time.Minute
+	sqsMaxVisibility = 12 * time.Hour
+)
+
+// SQSAPI is the part of the SQS client the queue uses
+type SQSAPI interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+}
+
+// SQSQueue is a Queue over an SQS standard queue, one message per job. SQS
+// caps delays at 15 minutes and retry delays at 12 hours; longer ones are
+// shortened to the cap.
+type SQSQueue struct {
+	client     SQSAPI
+	url        string
+	visibility time.Duration
+}
+
+// NewSQSQueue creates a queue over the SQS queue at queueURL; visibility 0
+// uses DefaultVisibilityTimeout
+func NewSQSQueue(client SQSAPI, queueURL string, visibility time.Duration) *SQSQueue {
+	if visibility <= 0 {
+		visibility = DefaultVisibilityTimeout
+	}
+	return &SQSQueue{client: client, url: queueURL, visibility: visibility}
+}
+
+// seconds converts d to whole SQS seconds, capped at limit
+func seconds(d, limit time.Duration) int32 {
+	if d > limit {
+		d = limit
+	}
+	if d < 0 {
+		d = 0
+	}
+	return int32(d / time.Second)
+}
+
+func (q *SQSQueue) Enqueue(ctx context.Context, job Job, delay time.Duration) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", job.ID, err)
+	}
+	_, err = q.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:     &q.url,
+		MessageBody:  aws.String(string(body)),
+		DelaySeconds: seconds(delay, sqsMaxDelay),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// Receive long-polls for messages. A message that is not a job is delivered
+// with an empty Type, which the worker gives up as unhandled.
+func (q *SQSQueue) Receive(ctx context.Context, max int, wait time.Duration) ([]Delivery, error) {
+	if max > sqsMaxMessages {
+		max = sqsMaxMessages
+	}
+	result, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:                    &q.url,
+		MaxNumberOfMessages:         int32(max),
+		WaitTimeSeconds:             seconds(wait, sqsMaxWait),
+		VisibilityTimeout:           seconds(q.visibility, sqsMaxVisibility),
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameApproximateReceiveCount},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive jobs: %w", err)
+	}
+
+	deliveries := make([]Delivery, 0, len(result.Messages))
+	for _, message := range result.Messages {
+		var job Job
+		if message.Body == nil || json.Unmarshal([]byte(*message.Body), &job) != nil {
+			job = Job{}
+		}
+		if job.ID == "" {
+			job.ID = aws.ToString(message.MessageId)
+		}
+		attempt, err := strconv.Atoi(message.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)])
+		if err != nil || attempt < 1 {
+			attempt = 1
+		}
+		deliveries = append(deliveries, Delivery{Job: job, Attempt: attempt, receipt: aws.ToString(message.ReceiptHandle)})
+	}
+	return deliveries, nil
+}
+
+func (q *SQSQueue) Ack(ctx context.Context, delivery Delivery) error {
+	_, err := q.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      &q.url,
+		ReceiptHandle: &delivery.receipt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge job %s: %w", delivery.Job.ID, err)
+	}
+	return nil
+}
+
+// Retry hides the message for delay, after which SQS delivers it again
+func (q *SQSQueue) Retry(ctx context.Context, delivery Delivery, delay time.Duration) error {
+	_, err := q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          &q.url,
+		ReceiptHandle:     &delivery.receipt,
+		VisibilityTimeout: seconds(delay, sqsMaxVisibility),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retry job %s: %w", delivery.Job.ID, err)
+	}
+	return nil
+}
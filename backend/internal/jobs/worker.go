@@ -0,0 +1,187 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Worker defaults
+const (
+	DefaultMaxAttempts = 5
+	DefaultBaseDelay   = time.Second
+	DefaultMaxDelay    = 5 * time.Minute
+	// DefaultPollWait is how long one Receive waits for jobs
+	DefaultPollWait = 20 * time.Second
+	// receiveErrorDelay is the pause after a failed Receive
+	receiveErrorDelay = 5 * time.Second
+)
+
+// Worker runs the jobs of a queue on a pool of goroutines. A failed job is
+// retried with full-jitter exponential backoff until it has been attempted
+// MaxAttempts times or fails permanently; it is then removed from the queue
+// and reported to the failure hook.
+type Worker struct {
+	queue       Queue
+	handlers    map[string]Handler
+	concurrency int
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	pollWait    time.Duration
+	onFailure   func(ctx context.Context, delivery Delivery, err error)
+}
+
+// NewWorker creates a worker running up to concurrency jobs of queue at once
+func NewWorker(queue Queue, concurrency int) *Worker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Worker{
+		queue:       queue,
+		handlers:    make(map[string]Handler),
+		concurrency: concurrency,
+		maxAttempts: DefaultMaxAttempts,
+		baseDelay:   DefaultBaseDelay,
+		maxDelay:    DefaultMaxDelay,
+		pollWait:    DefaultPollWait,
+		onFailure: func(ctx context.Context, delivery Delivery, err error) {
+			log.Printf("Job %s (%s) failed after %d attempts: %v", delivery.Job.ID, delivery.Job.Type, delivery.Attempt, err)
+		},
+	}
+}
+
+// Handle registers the handler of jobType; call before Run
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// SetRetries changes how often and how soon failed jobs are retried
+func (w *Worker) SetRetries(maxAttempts int, baseDelay, maxDelay time.Duration) {
+	w.maxAttempts, w.baseDelay, w.maxDelay = maxAttempts, baseDelay, maxDelay
+}
+
+// SetPollWait changes how long one Receive waits for jobs
+func (w *Worker) SetPollWait(wait time.Duration) {
+	w.pollWait = wait
+}
+
+// OnFailure replaces the hook that reports jobs given up, which logs them
+func (w *Worker) OnFailure(fn func(ctx context.Context, delivery Delivery, err error)) {
+	w.onFailure = fn
+}
+
+// Run receives and runs jobs until ctx is done, then waits for the running
+// jobs to return. Their handlers see ctx cancelled; a job cut short is left
+// on the queue to be delivered again.
+func (w *Worker) Run(ctx context.Context) {
+	slots := make(chan struct{}, w.concurrency)
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	for {
+		// Wait for a free slot, then ask for as many jobs as there are slots
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		free := 1
+	fill:
+		for free < w.concurrency {
+			select {
+			case slots <- struct{}{}:
+				free++
+			default:
+				break fill
+			}
+		}
+
+		deliveries, err := w.queue.Receive(ctx, free, w.pollWait)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to receive jobs: %v", err)
+			sleepCtx(ctx, receiveErrorDelay)
+		}
+		for i := 0; i < free; i++ {
+			if i >= len(deliveries) {
+				<-slots
+				continue
+			}
+			running.Add(1)
+			go func(delivery Delivery) {
+				defer func() { <-slots; running.Done() }()
+				w.process(ctx, delivery)
+			}(deliveries[i])
+		}
+	}
+}
+
+// process runs one delivery and acknowledges, retries or gives it up
+func (w *Worker) process(ctx context.Context, delivery Delivery) {
+	err := w.run(ctx, delivery.Job)
+	if err == nil {
+		if ackErr := w.queue.Ack(ctx, delivery); ackErr != nil {
+			log.Printf("Job %s (%s) succeeded but stays queued: %v", delivery.Job.ID, delivery.Job.Type, ackErr)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutting down: the queue delivers the job again
+		return
+	}
+
+	if IsPermanent(err) || delivery.Attempt >= w.maxAttempts {
+		if ackErr := w.queue.Ack(ctx, delivery); ackErr != nil {
+			log.Printf("Failed to remove job %s (%s): %v", delivery.Job.ID, delivery.Job.Type, ackErr)
+		}
+		w.onFailure(ctx, delivery, err)
+		return
+	}
+	delay := w.backoff(delivery.Attempt)
+	log.Printf("Job %s (%s) attempt %d failed, retrying in %s: %v", delivery.Job.ID, delivery.Job.Type, delivery.Attempt, delay.Round(time.Millisecond), err)
+	if retryErr := w.queue.Retry(ctx, delivery, delay); retryErr != nil {
+		log.Printf("Failed to retry job %s (%s): %v", delivery.Job.ID, delivery.Job.Type, retryErr)
+	}
+}
+
+// run calls the job's handler, turning a panic into an error
+func (w *Worker) run(ctx context.Context, job Job) (err error) {
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for job type %q", job.Type))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// backoff returns the full-jitter delay before retrying after attempt
+func (w *Worker) backoff(attempt int) time.Duration {
+	ceiling := w.baseDelay
+	for i := 1; i < attempt && ceiling < w.maxDelay; i++ {
+		ceiling *= 2
+	}
+	if w.maxDelay > 0 && ceiling > w.maxDelay {
+		ceiling = w.maxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// sleepCtx waits for d or until ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,240 @@
+// Package logs reads Lambda log events from CloudWatch Logs for diagnostics
+// and splits a log stream into the invocations it recorded.
+package logs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// maxPageSize is the most events CloudWatch Logs returns per page; maxPages
+// bounds a search that keeps returning empty pages
+const (
+	maxPageSize = 10000
+	maxPages    = 20
+)
+
+// Event is one log line of a Lambda log stream
+type Event struct {
+	LogStream string
+	Timestamp time.Time
+	Message   string
+}
+
+// Filter selects the events of a log group. Pattern is a CloudWatch Logs
+// filter pattern; LogStreams, when set, narrows the search to those streams.
+// Limit caps the events returned and must be positive.
+type Filter struct {
+	LogGroup   string
+	LogStreams []string
+	Pattern    string
+	Start      time.Time
+	End        time.Time
+	Limit      int
+}
+
+// Reader filters log events with the FilterLogEvents action of the
+// CloudWatch Logs JSON API, signed with the caller's credentials. It needs
+// logs:FilterLogEvents on the log groups it reads.
+type Reader struct {
+	client      aws.HTTPClient
+	credentials aws.CredentialsProvider
+	region      string
+	endpoint    string
+	signer      *v4.Signer
+}
+
+// NewReader reads with the region, credentials and HTTP client of cfg.
+// cfg.BaseEndpoint overrides the regional endpoint (LocalStack).
+func NewReader(cfg aws.Config) *Reader {
+	endpoint := aws.ToString(cfg.BaseEndpoint)
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://logs.%s.amazonaws.com/", cfg.Region)
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Reader{
+		client:      client,
+		credentials: cfg.Credentials,
+		region:      cfg.Region,
+		endpoint:    endpoint,
+		signer:      v4.NewSigner(),
+	}
+}
+
+type filterLogEventsInput struct {
+	LogGroupName   string   `json:"logGroupName"`
+	LogStreamNames []string `json:"logStreamNames,omitempty"`
+	FilterPattern  string   `json:"filterPattern,omitempty"`
+	StartTime      int64    `json:"startTime,omitempty"`
+	EndTime        int64    `json:"endTime,omitempty"`
+	Limit          int      `json:"limit,omitempty"`
+	NextToken      string   `json:"nextToken,omitempty"`
+}
+
+type filterLogEventsOutput struct {
+	Events []struct {
+		LogStreamName string `json:"logStreamName"`
+		Timestamp     int64  `json:"timestamp"`
+		Message       string `json:"message"`
+	} `json:"events"`
+	NextToken string `json:"nextToken"`
+}
+
+// FilterEvents returns up to f.Limit matching events, oldest first. Missing
+// log groups, e.g. of a Lambda that never ran, have no events.
+func (r *Reader) FilterEvents(ctx context.Context, f Filter) ([]Event, error) {
+	if r.credentials == nil {
+		return nil, fmt.Errorf("no AWS credentials to sign the log request")
+	}
+
+	input := filterLogEventsInput{
+		LogGroupName:   f.LogGroup,
+		LogStreamNames: f.LogStreams,
+		FilterPattern:  f.Pattern,
+	}
+	if !f.Start.IsZero() {
+		input.StartTime = f.Start.UnixMilli()
+	}
+	if !f.End.IsZero() {
+		input.EndTime = f.End.UnixMilli()
+	}
+
+	var events []Event
+	for page := 0; page < maxPages && len(events) < f.Limit; page++ {
+		input.Limit = f.Limit - len(events)
+		if input.Limit > maxPageSize {
+			input.Limit = maxPageSize
+		}
+		var output filterLogEventsOutput
+		found, err := r.call(ctx, "FilterLogEvents", input, &output)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, nil
+		}
+		for _, e := range output.Events {
+			events = append(events, Event{
+				LogStream: e.LogStreamName,
+				Timestamp: time.UnixMilli(e.Timestamp).UTC(),
+				Message:   strings.TrimRight(e.Message, "\n"),
+			})
+		}
+		// A page may come back empty with a token while the search continues
+		if output.NextToken == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	if len(events) > f.Limit {
+		events = events[:f.Limit]
+	}
+	return events, nil
+}
+
+// call sends a signed Logs_20140328 action. It reports found=false for
+// ResourceNotFoundException.
+func (r *Reader) call(ctx context.Context, action string, input, output interface{}) (bool, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal %s request: %w", action, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create %s request: %w", action, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "Logs_20140328."+action)
+
+	creds, err := r.credentials.Retrieve(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := r.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "logs", r.region, time.Now()); err != nil {
+		return false, fmt.Errorf("failed to sign %s request: %w", action, err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("%s request failed: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		var apiErr struct {
+			Type string `json:"__type"`
+		}
+		if json.Unmarshal(detail, &apiErr) == nil && strings.HasSuffix(apiErr.Type, "ResourceNotFoundException") {
+			return false, nil
+		}
+		return false, fmt.Errorf("CloudWatch Logs returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(output); err != nil {
+		return false, fmt.Errorf("failed to decode %s response: %w", action, err)
+	}
+	return true, nil
+}
+
+// Invocation is the log output of one Lambda invocation, from its START line
+// to its REPORT line
+type Invocation struct {
+	RequestID string
+	Events    []Event
+	// Complete is false when the events do not reach the REPORT line, e.g.
+	// because the invocation is still running or the read was cut short
+	Complete bool
+}
+
+// Invocations splits the events of one log stream, oldest first, at the
+// START and REPORT lines the Lambda runtime writes around each invocation.
+// A stream belongs to one execution environment, which runs one invocation
+// at a time. Events before the first START line are dropped.
+func Invocations(events []Event) []Invocation {
+	var invocations []Invocation
+	var current *Invocation
+	for _, event := range events {
+		if requestID, ok := runtimeLine(event.Message, "START"); ok {
+			invocations = append(invocations, Invocation{RequestID: requestID})
+			current = &invocations[len(invocations)-1]
+		}
+		if current == nil {
+			continue
+		}
+		current.Events = append(current.Events, event)
+		if requestID, ok := runtimeLine(event.Message, "REPORT"); ok && requestID == current.RequestID {
+			current.Complete = true
+			current = nil
+		}
+	}
+	return invocations
+}
+
+// runtimeLine returns the request ID of a "<kind> RequestId: <id> ..." line
+func runtimeLine(message, kind string) (string, bool) {
+	rest, ok := strings.CutPrefix(message, kind+" RequestId: ")
+	if !ok {
+		return "", false
+	}
+	requestID, _, _ := strings.Cut(rest, " ")
+	requestID, _, _ = strings.Cut(requestID, "\t")
+	requestID = strings.TrimSpace(requestID)
+	return requestID, requestID != ""
+}
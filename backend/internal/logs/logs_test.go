@@ -0,0 +1,124 @@
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_FilterEvents(t *testing.T) {
+	ctx := context.Background()
+	cfg := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+	}
+	start := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("pages through signed requests", func(t *testing.T) {
+		var requests []filterLogEventsInput
+		var authorization, target string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			target = r.Header.Get("X-Amz-Target")
+			var input filterLogEventsInput
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+			requests = append(requests, input)
+
+			if input.NextToken == "" {
+				w.Write([]byte(`{"events":[{"logStreamName":"s1","timestamp":1778414400000,"message":"Processing upload u1\n"}],"nextToken":"page-2"}`))
+				return
+			}
+			w.Write([]byte(`{"events":[{"logStreamName":"s2","timestamp":1778414401000,"message":"done"}]}`))
+		}))
+		defer server.Close()
+
+		cfg := cfg
+		cfg.BaseEndpoint = aws.String(server.URL)
+		events, err := NewReader(cfg).FilterEvents(ctx, Filter{
+			LogGroup: "/aws/lambda/metadata",
+			Pattern:  `"u1"`,
+			Start:    start,
+			End:      start.Add(time.Hour),
+			Limit:    10,
+		})
+
+		require.NoError(t, err)
+		assert.Contains(t, authorization, "/us-east-1/logs/aws4_request")
+		assert.Equal(t, "Logs_20140328.FilterLogEvents", target)
+		require.Len(t, requests, 2)
+		assert.Equal(t, "/aws/lambda/metadata", requests[0].LogGroupName)
+		assert.Equal(t, start.UnixMilli(), requests[0].StartTime)
+		assert.Equal(t, 10, requests[0].Limit)
+		assert.Equal(t, "page-2", requests[1].NextToken)
+		assert.Equal(t, 9, requests[1].Limit, "later pages ask for what is left")
+
+		require.Len(t, events, 2)
+		assert.Equal(t, Event{LogStream: "s1", Timestamp: start, Message: "Processing upload u1"}, events[0])
+		assert.Equal(t, "s2", events[1].LogStream)
+	})
+
+	t.Run("missing log groups have no events", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"com.amazonaws.logs#ResourceNotFoundException","message":"The specified log group does not exist."}`))
+		}))
+		defer server.Close()
+
+		cfg := cfg
+		cfg.BaseEndpoint = aws.String(server.URL)
+		events, err := NewReader(cfg).FilterEvents(ctx, Filter{LogGroup: "/aws/lambda/missing", Limit: 10})
+
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("returns other errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"AccessDeniedException"}`))
+		}))
+		defer server.Close()
+
+		cfg := cfg
+		cfg.BaseEndpoint = aws.String(server.URL)
+		_, err := NewReader(cfg).FilterEvents(ctx, Filter{LogGroup: "/aws/lambda/metadata", Limit: 10})
+
+		assert.ErrorContains(t, err, "CloudWatch Logs returned 400")
+	})
+}
+
+func TestInvocations(t *testing.T) {
+	lines := []string{
+		"Processing upload from an earlier invocation",
+		"START RequestId: req-1 Version: $LATEST",
+		"Processing upload u1",
+		"END RequestId: req-1",
+		"REPORT RequestId: req-1\tDuration: 12.3 ms\tBilled Duration: 13 ms",
+		"START RequestId: req-2 Version: $LATEST",
+		"Processing upload u2",
+	}
+	events := make([]Event, len(lines))
+	for i, line := range lines {
+		events[i] = Event{LogStream: "s1", Message: line}
+	}
+
+	invocations := Invocations(events)
+
+	require.Len(t, invocations, 2)
+	assert.Equal(t, "req-1", invocations[0].RequestID)
+	assert.True(t, invocations[0].Complete)
+	assert.Len(t, invocations[0].Events, 4)
+	assert.Equal(t, "Processing upload u1", invocations[0].Events[1].Message)
+	assert.Equal(t, "req-2", invocations[1].RequestID)
+	assert.False(t, invocations[1].Complete, "no REPORT line yet")
+	assert.Len(t, invocations[1].Events, 2)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/dhowden/tag"
 	"github.com/tcolgate/mp3"
 
+	"github.com/gvasels/personal-music-searchengine/internal/charset"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 )
 
@@ -57,6 +58,8 @@ func (e *Extractor) Extract(reader io.ReadSeeker, filename string) (*models.Uplo
 	disc, _ := m.Disc()
 	metadata.TrackNumber = track
 	metadata.DiscNumber = disc
+	metadata.Chapters = id3Chapters(m)
+	metadata.EncodingFixes = encodingFixes(metadata)
 
 	// Try to get additional metadata from raw tags
 	if raw := m.Raw(); raw != nil {
@@ -71,24 +74,46 @@ func (e *Extractor) Extract(reader io.ReadSeeker, filename string) (*models.Uplo
 	return metadata, nil
 }
 
-// ExtractCoverArt extracts embedded cover art from an audio file
-func (e *Extractor) ExtractCoverArt(reader io.ReadSeeker) ([]byte, string, error) {
-	// Reset reader to beginning
-	if _, err := reader.Seek(0, io.SeekStart); err != nil {
-		return nil, "", fmt.Errorf("failed to seek: %w", err)
+// encodingFixes suggests repairs for text tags that look decoded with the
+// wrong character set. Values are left as read; applying a fix is the user's call.
+func encodingFixes(metadata *models.UploadMetadata) []models.EncodingFix {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{models.TagFieldTitle, metadata.Title},
+		{models.TagFieldArtist, metadata.Artist},
+		{models.TagFieldAlbum, metadata.Album},
+		{models.TagFieldGenre, metadata.Genre},
+	}
+
+	var fixes []models.EncodingFix
+	for _, field := range fields {
+		if repaired, encoding, ok := charset.Repair(field.value); ok {
+			fixes = append(fixes, models.EncodingFix{
+				Field:     field.name,
+				Original:  field.value,
+				Suggested: repaired,
+				Encoding:  string(encoding),
+			})
+		}
 	}
+	return fixes
+}
 
-	m, err := tag.ReadFrom(reader)
+// ExtractCoverArt extracts the embedded front cover from an audio file, see FrontCover
+func (e *Extractor) ExtractCoverArt(reader io.ReadSeeker) ([]byte, string, error) {
+	images, err := e.ExtractImages(reader)
 	if err != nil {
-		return nil, "", nil // No error, just no cover art
+		return nil, "", err
 	}
 
-	picture := m.Picture()
-	if picture == nil {
+	cover := FrontCover(images)
+	if cover < 0 {
 		return nil, "", nil
 	}
 
-	return picture.Data, picture.MIMEType, nil
+	return images[cover].Data, images[cover].MIMEType, nil
 }
 
 // DetectFormat detects the audio format from a reader
@@ -0,0 +1,353 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/dhowden/tag"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// Image is a picture embedded in an audio file
+type Image struct {
+	Type        models.ArtworkType
+	MIMEType    string
+	Description string
+	Data        []byte
+}
+
+// flacPictureBlock is the FLAC metadata block type holding a picture
+const flacPictureBlock = 6
+
+// ExtractImages returns every picture embedded in an audio file, in file
+// order. ID3v2 tags and FLAC files can hold several typed pictures (front and
+// back cover, booklet pages); other formats yield at most one.
+func (e *Extractor) ExtractImages(reader io.ReadSeeker) ([]Image, error) {
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek: %w", err)
+	}
+
+	m, err := tag.ReadFrom(reader)
+	if err != nil {
+		return nil, nil // No error, just no images
+	}
+
+	var images []Image
+	switch {
+	case m.FileType() == tag.FLAC:
+		// The tag library keeps only the last PICTURE block
+		if _, err := reader.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to seek: %w", err)
+		}
+		images, err = readFLACPictures(reader)
+		if err != nil {
+			images = nil
+		}
+	case isID3v2(m.Format()):
+		images = id3Pictures(m.Raw())
+	}
+
+	if len(images) == 0 {
+		if picture := m.Picture(); picture != nil {
+			images = append(images, imageFromPicture(picture))
+		}
+	}
+	return images, nil
+}
+
+// FrontCover returns the index of the image to use as the track's cover art:
+// the first front cover, else the first untyped image, else the first image.
+// It returns -1 when there are no images.
+func FrontCover(images []Image) int {
+	for _, want := range []models.ArtworkType{models.ArtworkFrontCover, models.ArtworkOther} {
+		for i, image := range images {
+			if image.Type == want {
+				return i
+			}
+		}
+	}
+	if len(images) > 0 {
+		return 0
+	}
+	return -1
+}
+
+func isID3v2(format tag.Format) bool {
+	return format == tag.ID3v2_2 || format == tag.ID3v2_3 || format == tag.ID3v2_4
+}
+
+// id3Pictures collects the APIC (ID3v2.3/2.4) and PIC (ID3v2.2) frames
+func id3Pictures(raw map[string]interface{}) []Image {
+	var images []Image
+	for _, name := range []string{"APIC", "PIC"} {
+		for _, frame := range rawFrames(raw, name) {
+			if picture, ok := frame.(*tag.Picture); ok && picture != nil {
+				images = append(images, imageFromPicture(picture))
+			}
+		}
+	}
+	return images
+}
+
+// rawFrames returns the values of every frame called name in file order. The
+// tag library stores repeated frames as name, name_0, name_1, ...
+func rawFrames(raw map[string]interface{}, name string) []interface{} {
+	type frame struct {
+		index int
+		value interface{}
+	}
+	var frames []frame
+	for key, value := range raw {
+		if key == name {
+			frames = append(frames, frame{index: -1, value: value})
+			continue
+		}
+		suffix, ok := strings.CutPrefix(key, name+"_")
+		if !ok {
+			continue
+		}
+		if index, err := strconv.Atoi(suffix); err == nil {
+			frames = append(frames, frame{index: index, value: value})
+		}
+	}
+	sort.Slice(frames, func(i, j int) bool { return frames[i].index < frames[j].index })
+
+	values := make([]interface{}, len(frames))
+	for i, f := range frames {
+		values[i] = f.value
+	}
+	return values
+}
+
+func imageFromPicture(picture *tag.Picture) Image {
+	return Image{
+		Type:        artworkTypeFromName(picture.Type),
+		MIMEType:    picture.MIMEType,
+		Description: picture.Description,
+		Data:        picture.Data,
+	}
+}
+
+// artworkTypeFromName maps the tag library's picture type description
+// ("Cover (front)", "Leaflet page", ...) to an ArtworkType
+func artworkTypeFromName(name string) models.ArtworkType {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "front"):
+		return models.ArtworkFrontCover
+	case strings.Contains(name, "back"):
+		return models.ArtworkBackCover
+	case strings.Contains(name, "leaflet"):
+		return models.ArtworkBooklet
+	case strings.HasPrefix(name, "media"):
+		return models.ArtworkMedia
+	case strings.Contains(name, "artist"), strings.Contains(name, "performer"), strings.Contains(name, "band"),
+		strings.Contains(name, "conductor"), strings.Contains(name, "composer"), strings.Contains(name, "lyricist"):
+		return models.ArtworkArtist
+	default:
+		return models.ArtworkOther
+	}
+}
+
+// readFLACPictures reads every PICTURE metadata block of a FLAC stream,
+// skipping an ID3v2 tag in front of it
+func readFLACPictures(r io.Reader) ([]Image, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if string(header[:3]) == "ID3" {
+		id3 := make([]byte, 6)
+		if _, err := io.ReadFull(r, id3); err != nil {
+			return nil, err
+		}
+		if _, err := io.CopyN(io.Discard, r, int64(syncsafe(id3[2:6]))); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+	}
+	if string(header) != "fLaC" {
+		return nil, errors.New("not a FLAC stream")
+	}
+
+	var images []Image
+	for last := false; !last; {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		last = header[0]&0x80 != 0
+		blockType := header[0] & 0x7f
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+
+		if blockType != flacPictureBlock {
+			if _, err := io.CopyN(io.Discard, r, length); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		block := make([]byte, length)
+		if _, err := io.ReadFull(r, block); err != nil {
+			return nil, err
+		}
+		image, err := parseFLACPicture(block)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// parseFLACPicture decodes a PICTURE block: type, MIME type, description,
+// dimensions and the image data, with big-endian length prefixes
+func parseFLACPicture(block []byte) (Image, error) {
+	r := bytes.NewReader(block)
+	var pictureType, mimeLength uint32
+	if err := binary.Read(r, binary.BigEndian, &pictureType); err != nil {
+		return Image{}, err
+	}
+	mimeType, err := readLengthPrefixed(r, &mimeLength)
+	if err != nil {
+		return Image{}, err
+	}
+	var descriptionLength uint32
+	description, err := readLengthPrefixed(r, &descriptionLength)
+	if err != nil {
+		return Image{}, err
+	}
+	// Width, height, colour depth and palette size
+	if _, err := r.Seek(16, io.SeekCurrent); err != nil {
+		return Image{}, err
+	}
+	var dataLength uint32
+	data, err := readLengthPrefixed(r, &dataLength)
+	if err != nil {
+		return Image{}, err
+	}
+
+	return Image{
+		Type:        models.ArtworkTypeFromPictureType(pictureType),
+		MIMEType:    string(mimeType),
+		Description: string(description),
+		Data:        data,
+	}, nil
+}
+
+func readLengthPrefixed(r *bytes.Reader, length *uint32) ([]byte, error) {
+	if err := binary.Read(r, binary.BigEndian, length); err != nil {
+		return nil, err
+	}
+	if int64(*length) > int64(r.Len()) {
+		return nil, errors.New("picture block truncated")
+	}
+	value := make([]byte, *length)
+	_, err := io.ReadFull(r, value)
+	return value, err
+}
+
+// id3Chapters reads the CHAP frames of an ID3v2.3/2.4 tag, ordered by start time
+func id3Chapters(m tag.Metadata) []models.Chapter {
+	if m.Format() != tag.ID3v2_3 && m.Format() != tag.ID3v2_4 {
+		return nil
+	}
+
+	var chapters []models.Chapter
+	for _, frame := range rawFrames(m.Raw(), "CHAP") {
+		data, ok := frame.([]byte)
+		if !ok {
+			continue
+		}
+		if chapter, ok := parseChapterFrame(data, m.Format() == tag.ID3v2_4); ok {
+			chapters = append(chapters, chapter)
+		}
+	}
+	sort.SliceStable(chapters, func(i, j int) bool { return chapters[i].StartMs < chapters[j].StartMs })
+	return chapters
+}
+
+// parseChapterFrame decodes a CHAP frame: a null-terminated element ID, start
+// and end times in milliseconds, byte offsets, then sub-frames of which the
+// TIT2 title is used. ID3v2.4 sub-frame sizes are syncsafe integers.
+func parseChapterFrame(data []byte, syncsafeSizes bool) (models.Chapter, bool) {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 || len(data) < end+1+16 {
+		return models.Chapter{}, false
+	}
+	data = data[end+1:]
+
+	chapter := models.Chapter{
+		StartMs: int64(binary.BigEndian.Uint32(data[0:4])),
+		EndMs:   int64(binary.BigEndian.Uint32(data[4:8])),
+	}
+	data = data[16:]
+
+	for len(data) >= 10 {
+		id := string(data[0:4])
+		size := int(binary.BigEndian.Uint32(data[4:8]))
+		if syncsafeSizes {
+			size = syncsafe(data[4:8])
+		}
+		if size < 0 || len(data) < 10+size {
+			break
+		}
+		if id == "TIT2" {
+			chapter.Title = decodeText(data[10 : 10+size])
+		}
+		data = data[10+size:]
+	}
+	return chapter, true
+}
+
+// decodeText decodes an ID3v2 text frame body: an encoding byte followed by
+// ISO-8859-1, UTF-16 with BOM, UTF-16BE or UTF-8 text
+func decodeText(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	encoding, b := b[0], b[1:]
+
+	var text string
+	switch encoding {
+	case 1, 2:
+		bigEndian := encoding == 2
+		if len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE {
+			bigEndian, b = false, b[2:]
+		} else if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+			bigEndian, b = true, b[2:]
+		}
+		units := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			if bigEndian {
+				units = append(units, binary.BigEndian.Uint16(b[i:]))
+			} else {
+				units = append(units, binary.LittleEndian.Uint16(b[i:]))
+			}
+		}
+		text = string(utf16.Decode(units))
+	case 3:
+		text = string(b)
+	default:
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		text = string(runes)
+	}
+	return strings.TrimRight(text, "\x00")
+}
+
+// syncsafe decodes a 4-byte ID3v2 syncsafe integer (7 bits per byte)
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
@@ -0,0 +1,144 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/dhowden/tag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+func flacPicture(pictureType uint32, mimeType, description string, data []byte) []byte {
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.BigEndian, pictureType)
+	_ = binary.Write(&b, binary.BigEndian, uint32(len(mimeType)))
+	b.WriteString(mimeType)
+	_ = binary.Write(&b, binary.BigEndian, uint32(len(description)))
+	b.WriteString(description)
+	b.Write(make([]byte, 16))
+	_ = binary.Write(&b, binary.BigEndian, uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func flacBlock(blockType byte, last bool, body []byte) []byte {
+	if last {
+		blockType |= 0x80
+	}
+	n := len(body)
+	return append([]byte{blockType, byte(n >> 16), byte(n >> 8), byte(n)}, body...)
+}
+
+func TestReadFLACPictures(t *testing.T) {
+	var stream bytes.Buffer
+	stream.WriteString("fLaC")
+	stream.Write(flacBlock(0, false, make([]byte, 34))) // STREAMINFO
+	stream.Write(flacBlock(flacPictureBlock, false, flacPicture(4, "image/png", "back", []byte("back-data"))))
+	stream.Write(flacBlock(flacPictureBlock, true, flacPicture(3, "image/jpeg", "", []byte("front-data"))))
+
+	images, err := readFLACPictures(&stream)
+
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	assert.Equal(t, Image{Type: models.ArtworkBackCover, MIMEType: "image/png", Description: "back", Data: []byte("back-data")}, images[0])
+	assert.Equal(t, models.ArtworkFrontCover, images[1].Type)
+	assert.Equal(t, []byte("front-data"), images[1].Data)
+	assert.Equal(t, 1, FrontCover(images))
+}
+
+func TestReadFLACPictures_Truncated(t *testing.T) {
+	picture := flacPicture(3, "image/jpeg", "", []byte("front-data"))
+	binary.BigEndian.PutUint32(picture[len(picture)-14:], 1000) // data length past the block
+
+	_, err := readFLACPictures(bytes.NewReader(append([]byte("fLaC"), flacBlock(flacPictureBlock, true, picture)...)))
+
+	assert.Error(t, err)
+}
+
+func TestFrontCover(t *testing.T) {
+	tests := []struct {
+		name  string
+		types []models.ArtworkType
+		want  int
+	}{
+		{"none", nil, -1},
+		{"front cover wins over earlier images", []models.ArtworkType{models.ArtworkBackCover, models.ArtworkBooklet, models.ArtworkFrontCover}, 2},
+		{"untyped image when there is no front cover", []models.ArtworkType{models.ArtworkBackCover, models.ArtworkOther}, 1},
+		{"first image otherwise", []models.ArtworkType{models.ArtworkBooklet, models.ArtworkBackCover}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images := make([]Image, len(tt.types))
+			for i, artworkType := range tt.types {
+				images[i].Type = artworkType
+			}
+			assert.Equal(t, tt.want, FrontCover(images))
+		})
+	}
+}
+
+func TestID3Pictures(t *testing.T) {
+	raw := map[string]interface{}{
+		"APIC_1": &tag.Picture{Type: "Leaflet page", Data: []byte("3")},
+		"APIC":   &tag.Picture{Type: "Cover (back)", Data: []byte("1")},
+		"APIC_0": &tag.Picture{Type: "Cover (front)", MIMEType: "image/jpeg", Data: []byte("2")},
+		"TIT2":   "Title",
+	}
+
+	images := id3Pictures(raw)
+
+	require.Len(t, images, 3)
+	assert.Equal(t, models.ArtworkBackCover, images[0].Type)
+	assert.Equal(t, models.ArtworkFrontCover, images[1].Type)
+	assert.Equal(t, models.ArtworkBooklet, images[2].Type)
+	assert.Equal(t, 1, FrontCover(images))
+}
+
+func chapterFrame(elementID string, startMs, endMs uint32, subFrames ...[]byte) []byte {
+	b := append([]byte(elementID), 0)
+	b = binary.BigEndian.AppendUint32(b, startMs)
+	b = binary.BigEndian.AppendUint32(b, endMs)
+	b = append(b, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+	for _, f := range subFrames {
+		b = append(b, f...)
+	}
+	return b
+}
+
+func textFrame(id string, body []byte) []byte {
+	b := []byte(id)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(body)))
+	b = append(b, 0, 0)
+	return append(b, body...)
+}
+
+func TestParseChapterFrame(t *testing.T) {
+	t.Run("reads times and UTF-8 title", func(t *testing.T) {
+		frame := chapterFrame("ch0", 0, 61500, textFrame("TIT2", append([]byte{3}, "Intro"...)))
+
+		chapter, ok := parseChapterFrame(frame, false)
+
+		require.True(t, ok)
+		assert.Equal(t, models.Chapter{Title: "Intro", StartMs: 0, EndMs: 61500}, chapter)
+	})
+
+	t.Run("decodes UTF-16 titles", func(t *testing.T) {
+		title := []byte{1, 0xFF, 0xFE, 'P', 0, 'a', 0, 'r', 0, 't', 0, 0, 0}
+		frame := chapterFrame("ch1", 61500, 120000, textFrame("TIT2", title))
+
+		chapter, ok := parseChapterFrame(frame, false)
+
+		require.True(t, ok)
+		assert.Equal(t, "Part", chapter.Title)
+	})
+
+	t.Run("rejects truncated frames", func(t *testing.T) {
+		_, ok := parseChapterFrame([]byte("ch0\x00\x00\x00"), false)
+
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeText(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	require.NoError(t, r.WriteText(&b))
+	return b.String()
+}
+
+func TestCounter(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("requests_total", "Requests.", "method", "status")
+	c.Inc("GET", "200")
+	c.Inc("GET", "200")
+	c.Add(3, "POST", "201")
+	c.Add(-1, "POST", "201")
+
+	assert.Equal(t, `# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{method="GET",status="200"} 2
+requests_total{method="POST",status="201"} 3
+`, writeText(t, r))
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "op")
+	h.Observe(0.05, "get")
+	h.Observe(0.1, "get")
+	h.Observe(0.5, "get")
+	h.Observe(3, "get")
+
+	assert.Equal(t, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{op="get",le="0.1"} 2
+latency_seconds_bucket{op="get",le="1"} 3
+latency_seconds_bucket{op="get",le="+Inf"} 4
+latency_seconds_sum{op="get"} 3.65
+latency_seconds_count{op="get"} 4
+`, writeText(t, r))
+}
+
+func TestLabelEscaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("odd_total", "Line one\nback\\slash.", "path")
+	c.Inc("a\"b\\c\nd\te")
+	c.Inc("missing", "extra")
+
+	out := writeText(t, r)
+	assert.Contains(t, out, `# HELP odd_total Line one\nback\\slash.`)
+	assert.Contains(t, out, `odd_total{path="a\"b\\c\nd`+"\t"+`e"} 1`)
+	assert.Contains(t, out, `odd_total{path="missing"} 1`, "extra label values are dropped")
+}
+
+func TestGaugeFuncAndFormatValue(t *testing.T) {
+	r := NewRegistry()
+	r.NewGaugeFunc("up", "Up.", func() float64 { return 1 })
+	r.NewGaugeFunc("never", "Never.", func() float64 { return math.Inf(1) })
+
+	out := writeText(t, r)
+	assert.Contains(t, out, "# TYPE up gauge\nup 1\n")
+	assert.Contains(t, out, "never +Inf\n")
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("dup_total", "Dup.")
+	assert.Panics(t, func() { r.NewGaugeFunc("dup_total", "Dup.", func() float64 { return 0 }) })
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	s.ObserveRequest("GET", "/api/v1/tracks/:id", 200, 20*time.Millisecond)
+	s.ObserveStoreCall("dynamodb", "GetItem", time.Millisecond, nil)
+	s.ObserveSearch("search", time.Second, errors.New("timeout"))
+
+	rec := httptest.NewRecorder()
+	s.Registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+
+	out := rec.Body.String()
+	assert.Contains(t, out, `http_requests_total{method="GET",route="/api/v1/tracks/:id",status="200"} 1`)
+	assert.Contains(t, out, `http_request_duration_seconds_bucket{method="GET",route="/api/v1/tracks/:id",le="0.025"} 1`)
+	assert.Contains(t, out, `repository_call_duration_seconds_count{service="dynamodb",operation="GetItem",outcome="ok"} 1`)
+	assert.Contains(t, out, `search_duration_seconds_count{operation="search",outcome="error"} 1`)
+	assert.Contains(t, out, "# TYPE go_goroutines gauge")
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Outcome label values
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Server is the set of metrics the standalone API server exports
+type Server struct {
+	Registry *Registry
+
+	// HTTPRequests counts requests by method, route template and status
+	HTTPRequests *Counter
+	// HTTPDuration is request latency by method and route template
+	HTTPDuration *Histogram
+	// StoreCalls is DynamoDB and S3 call latency by service, operation and outcome
+	StoreCalls *Histogram
+	// SearchCalls is Nixiesearch call latency by operation and outcome
+	SearchCalls *Histogram
+}
+
+// NewServer registers the server metrics, plus process gauges, in a new registry
+func NewServer() *Server {
+	r := NewRegistry()
+	start := float64(time.Now().Unix())
+	r.NewGaugeFunc("process_start_time_seconds", "Start time of the process since the Unix epoch in seconds.", func() float64 { return start })
+	r.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 { return float64(runtime.NumGoroutine()) })
+	r.NewGaugeFunc("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", func() float64 {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return float64(stats.HeapAlloc)
+	})
+
+	return &Server{
+		Registry:     r,
+		HTTPRequests: r.NewCounter("http_requests_total", "HTTP requests handled, by method, route and status.", "method", "route", "status"),
+		HTTPDuration: r.NewHistogram("http_request_duration_seconds", "HTTP request latency in seconds, by method and route.", DefaultBuckets, "method", "route"),
+		StoreCalls:   r.NewHistogram("repository_call_duration_seconds", "DynamoDB and S3 call latency in seconds, by service, operation and outcome.", DefaultBuckets, "service", "operation", "outcome"),
+		SearchCalls:  r.NewHistogram("search_duration_seconds", "Nixiesearch call latency in seconds, by operation and outcome.", DefaultBuckets, "operation", "outcome"),
+	}
+}
+
+// ObserveRequest records one handled HTTP request
+func (s *Server) ObserveRequest(method, route string, status int, d time.Duration) {
+	s.HTTPRequests.Inc(method, route, strconv.Itoa(status))
+	s.HTTPDuration.Observe(d.Seconds(), method, route)
+}
+
+// ObserveStoreCall records one DynamoDB or S3 call; it matches the
+// repository's CallObserver
+func (s *Server) ObserveStoreCall(service, operation string, d time.Duration, err error) {
+	s.StoreCalls.Observe(d.Seconds(), service, operation, outcome(err))
+}
+
+// ObserveSearch records one call to the search Lambda; it matches the search
+// client's Observer
+func (s *Server) ObserveSearch(operation string, d time.Duration, err error) {
+	s.SearchCalls.Observe(d.Seconds(), operation, outcome(err))
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeOK
+}
@@ -0,0 +1,275 @@
+// Package migrations runs versioned data migrations over the single DynamoDB
+// table: rewrites of existing items that a code change needs (new index
+// attributes, defaults for new fields, new ID schemes).
+//
+// Migrations run in version order, in batches. The state of each (status,
+// cursor and counts) is checkpointed after every batch, so a run stopped by a
+// Lambda timeout or an error resumes from the last batch it finished. Every
+// migration must be idempotent: a batch interrupted before its checkpoint is
+// run again.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// Batch limits
+const (
+	DefaultBatchSize = 100
+	MaxBatchSize     = 1000
+)
+
+// Batch is one step of a migration
+type Batch struct {
+	// Cursor is where the previous batch stopped; empty for the first batch
+	Cursor string
+	Limit  int
+	// DryRun batches count what they would update without writing anything
+	DryRun bool
+}
+
+// BatchResult is the outcome of one batch. An empty NextCursor completes the
+// migration.
+type BatchResult struct {
+	Scanned    int
+	Updated    int // Would update, on dry runs
+	NextCursor string
+}
+
+// Migration is one versioned change to existing data
+type Migration struct {
+	// Version orders migrations; it is never reused, even if a migration is removed
+	Version     int
+	Name        string
+	Description string
+	Run         func(ctx context.Context, repo Repository, batch Batch) (BatchResult, error)
+}
+
+// Repository is the table access migrations need
+type Repository interface {
+	ScanTrackItems(ctx context.Context, cursor string, limit int) (*repository.PaginatedResult[models.TrackItem], error)
+	ScanAlbums(ctx context.Context, cursor string, limit int) (*repository.PaginatedResult[models.Album], error)
+	PutTrackIndexes(ctx context.Context, item models.TrackItem) error
+	SetDefaultTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) (bool, error)
+	RekeyAlbum(ctx context.Context, userID, fromID, toID string) error
+	ScanSortableItems(ctx context.Context, entity models.EntityType, cursor string, limit int) (*repository.PaginatedResult[models.SortableItem], error)
+	PutSortKey(ctx context.Context, item models.SortableItem) error
+}
+
+// StateStore persists migration checkpoints
+type StateStore interface {
+	GetMigrationState(ctx context.Context, version int) (*models.MigrationState, error)
+	PutMigrationState(ctx context.Context, state models.MigrationState) error
+	ListMigrationStates(ctx context.Context) ([]models.MigrationState, error)
+}
+
+// RunOptions select what a run does
+type RunOptions struct {
+	// Target is the highest version to run; 0 runs every migration
+	Target int `json:"target,omitempty"`
+	// BatchSize is the number of items read per batch (default 100, max 1000)
+	BatchSize int `json:"batchSize,omitempty"`
+	// DryRun reports what each pending migration would update, from its
+	// checkpoint, without writing data or checkpoints
+	DryRun bool `json:"dryRun,omitempty"`
+	// Until stops the run from starting new batches after this time
+	Until time.Time `json:"-"`
+}
+
+// RunResult reports the migrations a run worked on
+type RunResult struct {
+	DryRun     bool                    `json:"dryRun"`
+	Migrations []models.MigrationState `json:"migrations"`
+	// Done is true when every migration up to the target is completed (on dry
+	// runs: was scanned to the end)
+	Done bool `json:"done"`
+}
+
+// Runner runs the registered migrations against one table
+type Runner struct {
+	repo       Repository
+	store      StateStore
+	migrations []Migration
+}
+
+// NewRunner creates a Runner for the registered migrations
+func NewRunner(repo Repository, store StateStore) *Runner {
+	return &Runner{repo: repo, store: store, migrations: Registered}
+}
+
+// Run works through the migrations up to the target in version order,
+// resuming each from its checkpoint, until all are completed, one fails or
+// opts.Until passes. A migration that is not completed stops the run, so
+// later migrations can rely on earlier ones. Failed migrations are retried
+// from their last checkpoint.
+func (r *Runner) Run(ctx context.Context, opts RunOptions) (*RunResult, error) {
+	if err := validate(r.migrations); err != nil {
+		return nil, err
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	batchSize = min(batchSize, MaxBatchSize)
+
+	result := &RunResult{DryRun: opts.DryRun, Migrations: []models.MigrationState{}}
+	for _, m := range r.migrations {
+		if opts.Target > 0 && m.Version > opts.Target {
+			break
+		}
+
+		state, err := r.state(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		if state.Status == models.MigrationCompleted {
+			continue
+		}
+
+		done, err := r.runMigration(ctx, m, state, batchSize, opts)
+		result.Migrations = append(result.Migrations, *state)
+		if err != nil {
+			return result, err
+		}
+		// An unfinished migration stops the run; later ones may rely on it
+		if !done {
+			return result, nil
+		}
+	}
+
+	result.Done = true
+	return result, nil
+}
+
+// runMigration runs batches of one migration, updating state (and, unless
+// dry-running, checkpointing it) after each, and reports whether it finished
+func (r *Runner) runMigration(ctx context.Context, m Migration, state *models.MigrationState, batchSize int, opts RunOptions) (bool, error) {
+	now := time.Now()
+	if !opts.DryRun {
+		if state.StartedAt == nil {
+			state.StartedAt = &now
+		}
+		state.Status = models.MigrationRunning
+		state.LastError = ""
+		state.UpdatedAt = &now
+		if err := r.store.PutMigrationState(ctx, *state); err != nil {
+			return false, err
+		}
+	} else {
+		// Dry runs count only what is left
+		state.Scanned, state.Updated, state.Batches = 0, 0, 0
+	}
+
+	for {
+		batch, err := m.Run(ctx, r.repo, Batch{Cursor: state.Cursor, Limit: batchSize, DryRun: opts.DryRun})
+		if err != nil {
+			err = fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			if opts.DryRun {
+				return false, err
+			}
+			now := time.Now()
+			state.Status = models.MigrationFailed
+			state.LastError = err.Error()
+			state.UpdatedAt = &now
+			if putErr := r.store.PutMigrationState(ctx, *state); putErr != nil {
+				return false, errors.Join(err, putErr)
+			}
+			return false, err
+		}
+
+		now := time.Now()
+		state.Batches++
+		state.Scanned += batch.Scanned
+		state.Updated += batch.Updated
+		state.Cursor = batch.NextCursor
+		state.UpdatedAt = &now
+		done := batch.NextCursor == ""
+		if done && !opts.DryRun {
+			state.Status = models.MigrationCompleted
+			state.CompletedAt = &now
+		}
+		if !opts.DryRun {
+			if err := r.store.PutMigrationState(ctx, *state); err != nil {
+				return false, err
+			}
+		}
+
+		if done {
+			return true, nil
+		}
+		if ctx.Err() != nil || (!opts.Until.IsZero() && now.After(opts.Until)) {
+			return false, nil
+		}
+	}
+}
+
+// state returns a migration's checkpoint, or a pending state if it never ran
+func (r *Runner) state(ctx context.Context, m Migration) (*models.MigrationState, error) {
+	state, err := r.store.GetMigrationState(ctx, m.Version)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &models.MigrationState{Version: m.Version, Name: m.Name, Description: m.Description, Status: models.MigrationPending}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state.Name = m.Name
+	state.Description = m.Description
+	return state, nil
+}
+
+// Status lists every registered migration with its checkpoint, plus any
+// checkpoints of migrations no longer registered, in version order
+func (r *Runner) Status(ctx context.Context) (*models.MigrationStatusResponse, error) {
+	stored, err := r.store.ListMigrationStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]models.MigrationState, len(stored))
+	for _, state := range stored {
+		byVersion[state.Version] = state
+	}
+
+	response := &models.MigrationStatusResponse{Items: []models.MigrationState{}}
+	for _, m := range r.migrations {
+		state, ok := byVersion[m.Version]
+		if !ok {
+			state = models.MigrationState{Version: m.Version, Name: m.Name, Status: models.MigrationPending}
+		}
+		state.Name = m.Name
+		state.Description = m.Description
+		delete(byVersion, m.Version)
+		response.Items = append(response.Items, state)
+	}
+	for _, state := range byVersion {
+		response.Items = append(response.Items, state)
+	}
+	sort.Slice(response.Items, func(i, j int) bool { return response.Items[i].Version < response.Items[j].Version })
+
+	for _, state := range response.Items {
+		if state.Status != models.MigrationCompleted {
+			response.Pending++
+		}
+	}
+	return response, nil
+}
+
+// validate checks that migrations are in strictly increasing version order
+func validate(migrations []Migration) error {
+	for i, m := range migrations {
+		if m.Version <= 0 || m.Name == "" || m.Run == nil {
+			return fmt.Errorf("migration %d is incomplete", m.Version)
+		}
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			return fmt.Errorf("migration %d (%s) is out of version order", m.Version, m.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLegacyLibrary holds tracks without visibility in an album with a legacy
+// name-artist ID
+func newLegacyLibrary(t *testing.T) *repository.MemoryRepository {
+	t.Helper()
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	album, err := repo.GetOrCreateAlbum(ctx, "u1", "Untrue", "Burial")
+	require.NoError(t, err)
+	require.NoError(t, repo.RekeyAlbum(ctx, "u1", album.ID, "Untrue-Burial"))
+
+	for _, track := range []models.Track{
+		{ID: "t1", UserID: "u1", Title: "Archangel", AlbumID: "Untrue-Burial"},
+		{ID: "t2", UserID: "u1", Title: "Ghost Hardware", AlbumID: "Untrue-Burial"},
+		{ID: "t3", UserID: "u2", Title: "Teardrop", Visibility: models.VisibilityPublic},
+	} {
+		require.NoError(t, repo.CreateTrack(ctx, track))
+	}
+	return repo
+}
+
+func TestRun_Registered(t *testing.T) {
+	ctx := context.Background()
+	repo := newLegacyLibrary(t)
+	runner := NewRunner(repo, repo)
+
+	result, err := runner.Run(ctx, RunOptions{BatchSize: 1})
+	require.NoError(t, err)
+	assert.True(t, result.Done)
+	require.Len(t, result.Migrations, len(Registered))
+	for _, state := range result.Migrations {
+		assert.Equal(t, models.MigrationCompleted, state.Status, state.Name)
+		assert.NotNil(t, state.CompletedAt)
+		assert.Empty(t, state.Cursor)
+	}
+	assert.Equal(t, 2, result.Migrations[1].Updated, "tracks without visibility")
+	assert.Equal(t, 1, result.Migrations[2].Updated, "legacy albums")
+
+	t1, err := repo.GetTrack(ctx, "u1", "t1")
+	require.NoError(t, err)
+	assert.Equal(t, models.VisibilityPrivate, t1.Visibility)
+	albumID := repository.AlbumID("Untrue", "Burial")
+	assert.Equal(t, albumID, t1.AlbumID)
+	_, err = repo.GetAlbum(ctx, "u1", albumID)
+	assert.NoError(t, err)
+	_, err = repo.GetAlbum(ctx, "u1", "Untrue-Burial")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	album, err := repo.GetOrCreateAlbum(ctx, "u1", "Untrue", "Burial")
+	require.NoError(t, err)
+	assert.Equal(t, albumID, album.ID)
+
+	result, err = runner.Run(ctx, RunOptions{})
+	require.NoError(t, err)
+	assert.True(t, result.Done)
+	assert.Empty(t, result.Migrations, "completed migrations are not run again")
+}
+
+func TestRun_DryRun(t *testing.T) {
+	ctx := context.Background()
+	repo := newLegacyLibrary(t)
+	runner := NewRunner(repo, repo)
+
+	result, err := runner.Run(ctx, RunOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, result.Done)
+	require.Len(t, result.Migrations, len(Registered))
+	assert.Equal(t, 2, result.Migrations[1].Updated)
+	assert.Equal(t, 1, result.Migrations[2].Updated)
+
+	t1, err := repo.GetTrack(ctx, "u1", "t1")
+	require.NoError(t, err)
+	assert.Empty(t, t1.Visibility)
+	assert.Equal(t, "Untrue-Burial", t1.AlbumID)
+	states, err := repo.ListMigrationStates(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, states, "dry runs save no checkpoints")
+}
+
+func TestRun_GetOrCreateAlbumBeforeMigration(t *testing.T) {
+	repo := newLegacyLibrary(t)
+
+	album, err := repo.GetOrCreateAlbum(context.Background(), "u1", "Untrue", "Burial")
+	require.NoError(t, err)
+	assert.Equal(t, "Untrue-Burial", album.ID, "legacy albums are found until they are moved")
+}
+
+// pagedMigration counts items 0..total-1, one page per batch, and fails the
+// batch starting at failAt once
+func pagedMigration(version, total int, failAt *int) Migration {
+	return Migration{
+		Version: version,
+		Name:    "paged-" + strconv.Itoa(version),
+		Run: func(ctx context.Context, repo Repository, batch Batch) (BatchResult, error) {
+			start := 0
+			if batch.Cursor != "" {
+				start, _ = strconv.Atoi(batch.Cursor)
+			}
+			if failAt != nil && *failAt == start {
+				*failAt = -1
+				return BatchResult{}, errors.New("throttled")
+			}
+			end := min(start+batch.Limit, total)
+			result := BatchResult{Scanned: end - start, Updated: end - start}
+			if end < total {
+				result.NextCursor = strconv.Itoa(end)
+			}
+			return result, nil
+		},
+	}
+}
+
+func TestRun_ResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	failAt := 4
+	runner := &Runner{repo: repo, store: repo, migrations: []Migration{
+		pagedMigration(1, 10, &failAt),
+		pagedMigration(2, 3, nil),
+	}}
+
+	result, err := runner.Run(ctx, RunOptions{BatchSize: 2})
+	require.Error(t, err)
+	assert.False(t, result.Done)
+	state, err := repo.GetMigrationState(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.MigrationFailed, state.Status)
+	assert.Equal(t, "4", state.Cursor)
+	assert.Equal(t, 4, state.Scanned)
+	assert.Contains(t, state.LastError, "throttled")
+	_, err = repo.GetMigrationState(ctx, 2)
+	assert.ErrorIs(t, err, repository.ErrNotFound, "later migrations wait for earlier ones")
+
+	result, err = runner.Run(ctx, RunOptions{BatchSize: 2})
+	require.NoError(t, err)
+	assert.True(t, result.Done)
+	require.Len(t, result.Migrations, 2)
+	assert.Equal(t, 10, result.Migrations[0].Scanned, "the failed run's batches are not repeated")
+	assert.Empty(t, result.Migrations[0].LastError)
+	assert.Equal(t, 3, result.Migrations[1].Updated)
+}
+
+func TestRun_StopsAtDeadlineAndTarget(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	runner := &Runner{repo: repo, store: repo, migrations: []Migration{
+		pagedMigration(1, 10, nil),
+		pagedMigration(2, 3, nil),
+	}}
+
+	// A passed deadline still lets one batch run, so every invocation makes progress
+	result, err := runner.Run(ctx, RunOptions{BatchSize: 3, Until: time.Now().Add(-time.Second)})
+	require.NoError(t, err)
+	assert.False(t, result.Done)
+	require.Len(t, result.Migrations, 1)
+	assert.Equal(t, models.MigrationRunning, result.Migrations[0].Status)
+	assert.Equal(t, "3", result.Migrations[0].Cursor)
+
+	result, err = runner.Run(ctx, RunOptions{BatchSize: 3, Target: 1})
+	require.NoError(t, err)
+	assert.True(t, result.Done)
+	require.Len(t, result.Migrations, 1)
+	assert.Equal(t, models.MigrationCompleted, result.Migrations[0].Status)
+
+	status, err := runner.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.Pending)
+	assert.Equal(t, models.MigrationPending, status.Items[1].Status)
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.PutMigrationState(ctx, models.MigrationState{Version: 1, Name: "old-name", Status: models.MigrationCompleted}))
+	require.NoError(t, repo.PutMigrationState(ctx, models.MigrationState{Version: 99, Name: "removed", Status: models.MigrationCompleted}))
+
+	status, err := NewRunner(repo, repo).Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, status.Items, len(Registered)+1)
+	assert.Equal(t, Registered[0].Name, status.Items[0].Name, "names come from the registry")
+	assert.Equal(t, Registered[0].Description, status.Items[0].Description)
+	assert.Equal(t, models.MigrationCompleted, status.Items[0].Status)
+	assert.Equal(t, "removed", status.Items[len(Registered)].Name)
+	assert.Equal(t, len(Registered)-1, status.Pending)
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, validate(Registered))
+
+	assert.Error(t, validate([]Migration{pagedMigration(2, 1, nil), pagedMigration(1, 1, nil)}))
+	assert.Error(t, validate([]Migration{pagedMigration(1, 1, nil), pagedMigration(1, 1, nil)}))
+	assert.Error(t, validate([]Migration{{Version: 1, Name: "no-run"}}))
+}
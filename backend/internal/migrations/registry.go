@@ -0,0 +1,186 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// Registered are the data migrations, in version order. Append new ones with
+// the next version; never renumber or reorder.
+var Registered = []Migration{
+	{
+		Version:     1,
+		Name:        "backfill-track-indexes",
+		Description: "Write the artist, public-discovery and harmonic GSI attributes of tracks created before those indexes existed",
+		Run:         backfillTrackIndexes,
+	},
+	{
+		Version:     2,
+		Name:        "default-track-visibility",
+		Description: "Set tracks without a visibility to private",
+		Run:         defaultTrackVisibility,
+	},
+	{
+		Version:     3,
+		Name:        "rewrite-album-ids",
+		Description: "Move albums with legacy name-artist IDs to hashed IDs and repoint their tracks",
+		Run:         rewriteAlbumIDs,
+	},
+	{
+		Version:     4,
+		Name:        "backfill-track-sort-keys",
+		Description: "Write the alphabetical browse (GSI5) keys of tracks created before that index existed",
+		Run:         backfillSortKeys(models.EntityTrack),
+	},
+	{
+		Version:     5,
+		Name:        "backfill-album-sort-keys",
+		Description: "Write the alphabetical browse (GSI5) keys of albums created before that index existed",
+		Run:         backfillSortKeys(models.EntityAlbum),
+	},
+	{
+		Version:     6,
+		Name:        "backfill-artist-sort-keys",
+		Description: "Write the alphabetical browse (GSI5) keys of artists created before that index existed",
+		Run:         backfillSortKeys(models.EntityArtist),
+	},
+}
+
+// backfillTrackIndexes rewrites the index attributes of every track item that
+// differs from what NewTrackItem would write today
+func backfillTrackIndexes(ctx context.Context, repo Repository, batch Batch) (BatchResult, error) {
+	page, err := repo.ScanTrackItems(ctx, batch.Cursor, batch.Limit)
+	if err != nil {
+		return BatchResult{}, err
+	}
+
+	result := BatchResult{Scanned: len(page.Items), NextCursor: page.NextCursor}
+	for _, item := range page.Items {
+		want := models.NewTrackItem(item.Track)
+		if sameIndexes(item.DynamoDBItem, want.DynamoDBItem) {
+			continue
+		}
+		if batch.DryRun {
+			result.Updated++
+			continue
+		}
+		err := repo.PutTrackIndexes(ctx, want)
+		if errors.Is(err, repository.ErrConflict) {
+			// Updated since the scan; its writer set the indexes
+			continue
+		}
+		if err != nil {
+			return BatchResult{}, fmt.Errorf("track %s: %w", item.ID, err)
+		}
+		result.Updated++
+	}
+	return result, nil
+}
+
+// sameIndexes reports whether two items have the same track index attributes
+func sameIndexes(a, b models.DynamoDBItem) bool {
+	return a.GSI1PK == b.GSI1PK && a.GSI1SK == b.GSI1SK &&
+		a.GSI3PK == b.GSI3PK && a.GSI3SK == b.GSI3SK &&
+		a.GSI4PK == b.GSI4PK && a.GSI4SK == b.GSI4SK
+}
+
+// defaultTrackVisibility makes tracks created before visibility existed
+// explicitly private, which is how they have been treated
+func defaultTrackVisibility(ctx context.Context, repo Repository, batch Batch) (BatchResult, error) {
+	page, err := repo.ScanTrackItems(ctx, batch.Cursor, batch.Limit)
+	if err != nil {
+		return BatchResult{}, err
+	}
+
+	result := BatchResult{Scanned: len(page.Items), NextCursor: page.NextCursor}
+	for _, item := range page.Items {
+		if item.Visibility != "" {
+			continue
+		}
+		if batch.DryRun {
+			result.Updated++
+			continue
+		}
+		updated, err := repo.SetDefaultTrackVisibility(ctx, item.UserID, item.ID, models.VisibilityPrivate)
+		if err != nil {
+			return BatchResult{}, fmt.Errorf("track %s: %w", item.ID, err)
+		}
+		if updated {
+			result.Updated++
+		}
+	}
+	return result, nil
+}
+
+// hashedAlbumID matches IDs built by repository.AlbumID
+var hashedAlbumID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// rewriteAlbumIDs moves every album whose ID is not a hashed one to the ID
+// repository.AlbumID gives its title and artist
+func rewriteAlbumIDs(ctx context.Context, repo Repository, batch Batch) (BatchResult, error) {
+	page, err := repo.ScanAlbums(ctx, batch.Cursor, batch.Limit)
+	if err != nil {
+		return BatchResult{}, err
+	}
+
+	result := BatchResult{Scanned: len(page.Items), NextCursor: page.NextCursor}
+	for _, album := range page.Items {
+		if hashedAlbumID.MatchString(album.ID) {
+			continue
+		}
+		if batch.DryRun {
+			result.Updated++
+			continue
+		}
+		err := repo.RekeyAlbum(ctx, album.UserID, album.ID, repository.AlbumID(album.Title, album.Artist))
+		if errors.Is(err, repository.ErrNotFound) {
+			// Moved since the scan
+			continue
+		}
+		if err != nil {
+			return BatchResult{}, fmt.Errorf("album %s: %w", album.ID, err)
+		}
+		result.Updated++
+	}
+	return result, nil
+}
+
+// backfillSortKeys returns a batch function that writes the sort key of every
+// item of entity whose key differs from what its own sort locale gives it.
+// Items without a locale get the root collation; owners who pick one rekey
+// their library when saving it.
+func backfillSortKeys(entity models.EntityType) func(context.Context, Repository, Batch) (BatchResult, error) {
+	return func(ctx context.Context, repo Repository, batch Batch) (BatchResult, error) {
+		page, err := repo.ScanSortableItems(ctx, entity, batch.Cursor, batch.Limit)
+		if err != nil {
+			return BatchResult{}, err
+		}
+
+		result := BatchResult{Scanned: len(page.Items), NextCursor: page.NextCursor}
+		for _, item := range page.Items {
+			if item.SortKeyCurrent(item.SortLocale) {
+				continue
+			}
+			if batch.DryRun {
+				result.Updated++
+				continue
+			}
+			err := repo.PutSortKey(ctx, item.WithSortLocale(item.SortLocale))
+			if errors.Is(err, repository.ErrConflict) {
+				// Updated since the scan; its writer set the key
+				continue
+			}
+			if err != nil {
+				return BatchResult{}, fmt.Errorf("%s %s: %w", strings.ToLower(string(entity)), item.ID, err)
+			}
+			result.Updated++
+		}
+		return result, nil
+	}
+}
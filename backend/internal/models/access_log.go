@@ -0,0 +1,189 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// EntityTrackAccess represents the entity type for an access log event
+	EntityTrackAccess EntityType = "TRACK_ACCESS"
+	// EntityTrackAccessCounts represents the entity type for a track's access counters
+	EntityTrackAccessCounts EntityType = "TRACK_ACCESS_COUNTS"
+)
+
+// AccessLogRetention is how long access events are kept; the counters they
+// add to are kept for the life of the track
+const AccessLogRetention = 90 * 24 * time.Hour
+
+// maxUserAgentLength caps the user agent kept per event
+const maxUserAgentLength = 256
+
+// AccessKind names how a track was reached by someone outside its library
+type AccessKind string
+
+const (
+	// AccessStream is a stream of a public or unlisted track
+	AccessStream AccessKind = "stream"
+	// AccessDownload is a download of a public or unlisted track
+	AccessDownload AccessKind = "download"
+	// AccessPreview is an opened preview share link
+	AccessPreview AccessKind = "preview"
+)
+
+// AccessClient describes who made a request, as the handler saw it
+type AccessClient struct {
+	Addr      string
+	UserAgent string
+}
+
+// TrackAccessEvent records one stream, download or preview of a track by
+// someone outside its library. The address is anonymized before it is
+// stored; events expire after AccessLogRetention.
+type TrackAccessEvent struct {
+	ID         string          `json:"id" dynamodbav:"id"`
+	TrackID    string          `json:"trackId" dynamodbav:"trackId"`
+	OwnerID    string          `json:"-" dynamodbav:"ownerId"`
+	Kind       AccessKind      `json:"kind" dynamodbav:"kind"`
+	Visibility TrackVisibility `json:"visibility" dynamodbav:"visibility"` // Of the track when it was reached
+	// Token is the download token of a watermarked download, or the ID of the
+	// preview link that was opened
+	Token      string    `json:"token,omitempty" dynamodbav:"token,omitempty"`
+	ClientIP   string    `json:"clientIp,omitempty" dynamodbav:"clientIp,omitempty"`
+	UserAgent  string    `json:"userAgent,omitempty" dynamodbav:"userAgent,omitempty"`
+	AccessedAt time.Time `json:"accessedAt" dynamodbav:"accessedAt"`
+	ExpiresAt  time.Time `json:"-" dynamodbav:"expiresAt"`
+}
+
+// NewTrackAccessEvent records an access to track by client. The client's
+// address is reduced to its network and its user agent is cut to a
+// readable length.
+func NewTrackAccessEvent(id string, track Track, kind AccessKind, token string, client AccessClient, now time.Time) TrackAccessEvent {
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return TrackAccessEvent{
+		ID:         id,
+		TrackID:    track.ID,
+		OwnerID:    track.UserID,
+		Kind:       kind,
+		Visibility: track.Visibility,
+		Token:      token,
+		ClientIP:   AnonymizeIP(client.Addr),
+		UserAgent:  userAgent,
+		AccessedAt: now,
+		ExpiresAt:  now.Add(AccessLogRetention),
+	}
+}
+
+// AnonymizeIP keeps the network of an address: the first three octets of an
+// IPv4 address and the first 48 bits of an IPv6 one. Unparsable addresses
+// are dropped.
+func AnonymizeIP(addr string) string {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return ""
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// PreviewTokenID identifies a preview link in access logs without storing
+// the link, which is a credential until it expires
+func PreviewTokenID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// TrackAccessCounts totals the accesses to a track from outside its library.
+// Counters are added to as each event is recorded and outlive the events.
+type TrackAccessCounts struct {
+	// Views counts opened preview share links
+	Views int64 `json:"views" dynamodbav:"views"`
+	// Plays counts streams of the track while it was public or unlisted
+	Plays int64 `json:"plays" dynamodbav:"plays"`
+	// Downloads counts downloads of the track while it was public or unlisted
+	Downloads      int64      `json:"downloads" dynamodbav:"downloads"`
+	LastAccessedAt *time.Time `json:"lastAccessedAt,omitempty" dynamodbav:"lastAccessedAt,omitempty"`
+}
+
+// CounterName returns the TrackAccessCounts attribute an access of kind adds to
+func (k AccessKind) CounterName() string {
+	switch k {
+	case AccessStream:
+		return "plays"
+	case AccessDownload:
+		return "downloads"
+	default:
+		return "views"
+	}
+}
+
+// Add counts one access of kind at the given time
+func (c *TrackAccessCounts) Add(kind AccessKind, at time.Time) {
+	switch kind {
+	case AccessStream:
+		c.Plays++
+	case AccessDownload:
+		c.Downloads++
+	default:
+		c.Views++
+	}
+	if c.LastAccessedAt == nil || at.After(*c.LastAccessedAt) {
+		c.LastAccessedAt = &at
+	}
+}
+
+// TrackAccessFilter pages through a track's access log, newest first
+type TrackAccessFilter struct {
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+}
+
+// TrackAccessLogResponse is a page of a track's access log with its counters
+type TrackAccessLogResponse struct {
+	TrackID    string             `json:"trackId"`
+	Counts     TrackAccessCounts  `json:"counts"`
+	Items      []TrackAccessEvent `json:"items"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+	HasMore    bool               `json:"hasMore"`
+}
+
+// TrackAccessEventItem represents a TrackAccessEvent in DynamoDB single-table design
+type TrackAccessEventItem struct {
+	DynamoDBItem
+	TrackAccessEvent
+	TTL int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewTrackAccessEventItem creates a DynamoDB item for an access event. The
+// sort key orders a track's events by when they happened.
+// Primary key pattern: PK=USER#{ownerID}, SK=ACCESS#{trackID}#{accessedAt}#{eventID}
+func NewTrackAccessEventItem(event TrackAccessEvent) TrackAccessEventItem {
+	return TrackAccessEventItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   fmt.Sprintf("USER#%s", event.OwnerID),
+			SK:   fmt.Sprintf("%s%s#%s", GetTrackAccessSKPrefix(event.TrackID), event.AccessedAt.UTC().Format(sortableNano), event.ID),
+			Type: string(EntityTrackAccess),
+		},
+		TrackAccessEvent: event,
+		TTL:              event.ExpiresAt.Unix(),
+	}
+}
+
+// GetTrackAccessSKPrefix returns the sort key prefix of a track's access events
+func GetTrackAccessSKPrefix(trackID string) string {
+	return fmt.Sprintf("ACCESS#%s#", trackID)
+}
+
+// GetTrackAccessCountsSK returns the sort key of a track's access counters.
+// Primary key pattern: PK=USER#{ownerID}, SK=ACCESS_COUNTS#{trackID}
+func GetTrackAccessCountsSK(trackID string) string {
+	return fmt.Sprintf("ACCESS_COUNTS#%s", trackID)
+}
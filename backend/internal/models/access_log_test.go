@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"203.0.113.77", "203.0.113.0"},
+		{"::ffff:203.0.113.77", "203.0.113.0"},
+		{"2001:db8:85a3:8d3:1319:8a2e:370:7348", "2001:db8:85a3::"},
+		{"", ""},
+		{"not-an-address", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, AnonymizeIP(tt.addr), tt.addr)
+	}
+}
+
+func TestNewTrackAccessEvent(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	track := Track{ID: "t1", UserID: "owner", Visibility: VisibilityUnlisted}
+	client := AccessClient{Addr: "198.51.100.9", UserAgent: strings.Repeat("a", 300)}
+
+	event := NewTrackAccessEvent("e1", track, AccessDownload, "tok", client, now)
+
+	assert.Equal(t, "owner", event.OwnerID)
+	assert.Equal(t, VisibilityUnlisted, event.Visibility)
+	assert.Equal(t, "198.51.100.0", event.ClientIP)
+	assert.Len(t, event.UserAgent, maxUserAgentLength)
+	assert.Equal(t, now.Add(AccessLogRetention), event.ExpiresAt)
+
+	item := NewTrackAccessEventItem(event)
+	assert.Equal(t, "USER#owner", item.PK)
+	assert.Equal(t, "ACCESS#t1#2026-05-10T12:00:00.000000000Z#e1", item.SK)
+	assert.Equal(t, now.Add(AccessLogRetention).Unix(), item.TTL)
+	assert.False(t, strings.HasPrefix(GetTrackAccessCountsSK("t1"), GetTrackAccessSKPrefix("t1")), "counters are not listed with the events")
+}
+
+func TestTrackAccessCounts_Add(t *testing.T) {
+	first := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	var counts TrackAccessCounts
+
+	counts.Add(AccessStream, first)
+	counts.Add(AccessPreview, first.Add(time.Minute))
+	counts.Add(AccessDownload, first.Add(-time.Minute))
+
+	assert.Equal(t, TrackAccessCounts{Views: 1, Plays: 1, Downloads: 1, LastAccessedAt: counts.LastAccessedAt}, counts)
+	assert.Equal(t, first.Add(time.Minute), *counts.LastAccessedAt)
+	assert.Equal(t, "plays", AccessStream.CounterName())
+	assert.Equal(t, "downloads", AccessDownload.CounterName())
+	assert.Equal(t, "views", AccessPreview.CounterName())
+}
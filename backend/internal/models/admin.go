@@ -82,3 +82,20 @@ func ValidateRole(role string) (UserRole, bool) {
 	r := UserRole(role)
 	return r, r.IsValid()
 }
+
+// TransferTrackRequest represents a request to move a track to another user.
+type TransferTrackRequest struct {
+	ToUserID string `json:"toUserId" validate:"required"`
+}
+
+// TrackTransferResponse reports the outcome of moving a track between users.
+type TrackTransferResponse struct {
+	TrackID    string `json:"trackId"`
+	FromUserID string `json:"fromUserId"`
+	ToUserID   string `json:"toUserId"`
+	// PlaylistsUpdated counts the previous owner's playlists the track was removed from
+	PlaylistsUpdated int `json:"playlistsUpdated"`
+	// Reindexed is false when the search index could not be updated; the
+	// next index rebuild picks the new owner up
+	Reindexed bool `json:"reindexed"`
+}
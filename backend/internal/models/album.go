@@ -15,9 +15,11 @@ type Album struct {
 	Genre        string `json:"genre,omitempty" dynamodbav:"genre,omitempty"`
 	Year         int    `json:"year,omitempty" dynamodbav:"year,omitempty"`
 	CoverArtKey  string `json:"coverArtKey,omitempty" dynamodbav:"coverArtKey,omitempty"`
+	CoverStyle   *CoverStyle `json:"coverStyle,omitempty" dynamodbav:"coverStyle,omitempty"`
 	TrackCount   int    `json:"trackCount" dynamodbav:"trackCount"`
 	TotalDuration int   `json:"totalDuration" dynamodbav:"totalDuration"` // seconds
 	DiscCount    int    `json:"discCount" dynamodbav:"discCount"`
+	SortLocale   string `json:"-" dynamodbav:"sortLocale,omitempty"` // Locale of the title sort key (GSI5)
 	Timestamps
 }
 
@@ -44,6 +46,10 @@ func NewAlbumItem(album Album) AlbumItem {
 		item.GSI1SK = fmt.Sprintf("ALBUM#%d", album.Year)
 	}
 
+	// Set GSI5 for alphabetical browse by title
+	item.GSI5PK = GetSortGSI5PK(album.UserID, EntityAlbum)
+	item.GSI5SK = GetSortGSI5SK(album.SortLocale, album.Title, album.ID)
+
 	return item
 }
 
@@ -56,6 +62,7 @@ type AlbumResponse struct {
 	Genre         string    `json:"genre,omitempty"`
 	Year          int       `json:"year,omitempty"`
 	CoverArtURL   string    `json:"coverArtUrl,omitempty"`
+	CoverStyle    *CoverStyle `json:"coverStyle,omitempty"`
 	TrackCount    int       `json:"trackCount"`
 	TotalDuration int       `json:"totalDuration"`
 	DurationStr   string    `json:"durationStr"`
@@ -74,6 +81,7 @@ func (a *Album) ToResponse(coverArtURL string) AlbumResponse {
 		Genre:         a.Genre,
 		Year:          a.Year,
 		CoverArtURL:   coverArtURL,
+		CoverStyle:    a.CoverStyle,
 		TrackCount:    a.TrackCount,
 		TotalDuration: a.TotalDuration,
 		DurationStr:   formatDuration(a.TotalDuration),
@@ -98,6 +106,7 @@ type AlbumFilter struct {
 	SortOrder string `query:"sortOrder"` // asc, desc
 	Limit     int    `query:"limit"`
 	LastKey   string `query:"lastKey"`
+	CountOnly bool   `query:"countOnly"` // Return only the total, as a CountResponse
 }
 
 // ArtistSummary represents an artist with aggregated stats
@@ -0,0 +1,89 @@
+package models
+
+// BPM correction actions for analyzers that lock onto half or double the tempo
+const (
+	BPMActionDouble = "double"
+	BPMActionHalve  = "halve"
+)
+
+// Bounds of a valid BPM, matching the analyzer's detection range
+const (
+	MinBPM = 20
+	MaxBPM = 300
+)
+
+// UpdateTrackAnalysisRequest corrects the analyzed BPM or key of a track.
+// Corrected values are marked as overridden so reanalysis keeps them.
+type UpdateTrackAnalysisRequest struct {
+	BPM *int `json:"bpm,omitempty" validate:"omitempty,min=20,max=300"`
+	// BPMAction doubles or halves the current BPM; it cannot be combined with BPM
+	BPMAction  string  `json:"bpmAction,omitempty" validate:"omitempty,oneof=double halve"`
+	MusicalKey *string `json:"musicalKey,omitempty" validate:"omitempty,max=8"`
+	// ClearOverrides hands the BPM and key back to the analyzer. The current
+	// values are kept until the track is next analyzed.
+	ClearOverrides bool `json:"clearOverrides,omitempty"`
+}
+
+// KeyCandidate is a key the analyzer scored for a track
+type KeyCandidate struct {
+	Key     string  `json:"key" dynamodbav:"key"`
+	Camelot string  `json:"camelot,omitempty" dynamodbav:"camelot,omitempty"`
+	Score   float64 `json:"score" dynamodbav:"score"` // Correlation with the key profile (-1 to 1)
+}
+
+// TrackAnalysis is the analyzer's result for a track
+type TrackAnalysis struct {
+	BPM           int
+	MusicalKey    string
+	KeyMode       string
+	KeyCamelot    string
+	KeyConfidence float64
+	KeyCandidates []KeyCandidate
+	PreviewStart  int
+}
+
+// ApplyAnalysis sets the analyzer's BPM, key and preview start on the track,
+// leaving values the owner has overridden unchanged.
+func (t *Track) ApplyAnalysis(a TrackAnalysis) {
+	if !t.BPMOverridden {
+		t.BPM = a.BPM
+	}
+	if !t.KeyOverridden {
+		t.MusicalKey = a.MusicalKey
+		t.KeyMode = a.KeyMode
+		t.KeyCamelot = a.KeyCamelot
+		t.KeyConfidence = a.KeyConfidence
+		t.KeyCandidates = a.KeyCandidates
+	}
+	t.PreviewStart = a.PreviewStart
+}
+
+// KeyReliability weights the track's key in compatibility scores, from 0 to
+// 1. Keys set by the owner, and keys analyzed before confidence was recorded,
+// count fully.
+func (t *Track) KeyReliability() float64 {
+	if t.KeyOverridden || t.KeyConfidence <= 0 {
+		return 1
+	}
+	return min(t.KeyConfidence, 1)
+}
+
+// DJ software formats analysis can be exported in
+const (
+	DJExportRekordbox = "rekordbox"
+	DJExportSerato    = "serato"
+)
+
+// DJExportRequest selects the format of an analysis export
+type DJExportRequest struct {
+	Format string `query:"format" validate:"required,oneof=rekordbox serato"`
+	// Root is the folder the tracks were downloaded to, used for file locations
+	Root string `query:"root" validate:"omitempty,max=500"`
+}
+
+// DJExport is an exported analysis file
+type DJExport struct {
+	FileName    string
+	ContentType string
+	Body        []byte
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack_ApplyAnalysis(t *testing.T) {
+	t.Run("sets analyzed values", func(t *testing.T) {
+		track := Track{}
+
+		track.ApplyAnalysis(TrackAnalysis{BPM: 128, MusicalKey: "Am", KeyMode: "minor", KeyCamelot: "8A", KeyConfidence: 0.8, KeyCandidates: []KeyCandidate{{Key: "C", Camelot: "8B", Score: 0.7}}})
+
+		assert.Equal(t, 128, track.BPM)
+		assert.Equal(t, "Am", track.MusicalKey)
+		assert.Equal(t, "minor", track.KeyMode)
+		assert.Equal(t, "8A", track.KeyCamelot)
+		assert.Equal(t, 0.8, track.KeyConfidence)
+		assert.Equal(t, []KeyCandidate{{Key: "C", Camelot: "8B", Score: 0.7}}, track.KeyCandidates)
+	})
+
+	t.Run("keeps overridden values", func(t *testing.T) {
+		track := Track{BPM: 174, BPMOverridden: true, MusicalKey: "C", KeyMode: "major", KeyCamelot: "8B", KeyOverridden: true}
+
+		track.ApplyAnalysis(TrackAnalysis{BPM: 87, MusicalKey: "Am", KeyMode: "minor", KeyCamelot: "8A", KeyConfidence: 0.4})
+
+		assert.Equal(t, 174, track.BPM)
+		assert.Equal(t, "C", track.MusicalKey)
+		assert.Equal(t, "8B", track.KeyCamelot)
+		assert.Zero(t, track.KeyConfidence)
+	})
+
+	t.Run("overrides are per value", func(t *testing.T) {
+		track := Track{BPM: 174, BPMOverridden: true}
+
+		track.ApplyAnalysis(TrackAnalysis{BPM: 87, MusicalKey: "Am", KeyMode: "minor", KeyCamelot: "8A", KeyConfidence: 0.4})
+
+		assert.Equal(t, 174, track.BPM)
+		assert.Equal(t, "Am", track.MusicalKey)
+	})
+}
+
+func TestTrack_KeyReliability(t *testing.T) {
+	assert.Equal(t, 0.4, (&Track{KeyConfidence: 0.4}).KeyReliability())
+	assert.Equal(t, 1.0, (&Track{KeyConfidence: 0.4, KeyOverridden: true}).KeyReliability(), "owner-set keys are trusted")
+	assert.Equal(t, 1.0, (&Track{}).KeyReliability(), "keys analyzed without a confidence are trusted")
+}
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// EntityArchiveInsight represents the entity type for a library's cold track report
+const EntityArchiveInsight EntityType = "ARCHIVE_INSIGHT"
+
+const (
+	// DefaultColdAfter is how long a track goes unplayed before it is
+	// suggested for archiving or deletion
+	DefaultColdAfter = 2 * 365 * 24 * time.Hour
+	// MaxArchiveSuggestions bounds the cold tracks a report keeps, oldest
+	// first, so the report stays one item; the rest are counted only
+	MaxArchiveSuggestions = 1000
+	// MaxArchiveSuggestionBatch is how many suggestions one request accepts
+	MaxArchiveSuggestionBatch = 200
+)
+
+// ArchiveAction is what accepting an archive suggestion does to its track
+type ArchiveAction string
+
+const (
+	// ArchiveActionDelete moves the track and its files to the trash, from
+	// which undoing the operation restores them within UndoWindow
+	ArchiveActionDelete ArchiveAction = "delete"
+	// ArchiveActionDismiss keeps the track and stops suggesting it
+	ArchiveActionDismiss ArchiveAction = "dismiss"
+)
+
+// ColdSince returns when the track was last played, or when it was added if
+// it never was
+func (t *Track) ColdSince() time.Time {
+	if t.LastPlayed != nil && !t.LastPlayed.IsZero() {
+		return *t.LastPlayed
+	}
+	return t.CreatedAt
+}
+
+// IsCold reports whether the track has gone unplayed for coldAfter at now
+func (t *Track) IsCold(now time.Time, coldAfter time.Duration) bool {
+	since := t.ColdSince()
+	return !since.IsZero() && !since.After(now.Add(-coldAfter))
+}
+
+// ColdTrack is a track of an archive insight
+type ColdTrack struct {
+	TrackID   string    `json:"trackId" dynamodbav:"trackId"`
+	ColdSince time.Time `json:"coldSince" dynamodbav:"coldSince"`
+	FileSize  int64     `json:"fileSize" dynamodbav:"fileSize"`
+}
+
+// ArchiveInsight is the insights job's report of a library's cold tracks.
+// Tracks are kept oldest first, up to MaxArchiveSuggestions; ColdTracks and
+// ColdBytes count all of them.
+type ArchiveInsight struct {
+	LibraryID  string      `json:"libraryId" dynamodbav:"libraryId"`
+	Tracks     []ColdTrack `json:"tracks" dynamodbav:"tracks"`
+	ColdTracks int         `json:"coldTracks" dynamodbav:"coldTracks"`
+	ColdBytes  int64       `json:"coldBytes" dynamodbav:"coldBytes"`
+	// Dismissed are the tracks the library chose to keep; they are not
+	// suggested again
+	Dismissed   []string  `json:"dismissed,omitempty" dynamodbav:"dismissed,omitempty"`
+	ColdAfter   int64     `json:"coldAfterDays" dynamodbav:"coldAfterDays"`
+	GeneratedAt time.Time `json:"generatedAt" dynamodbav:"generatedAt"`
+}
+
+// NewArchiveInsight starts the report of a library's tracks that have gone
+// unplayed for coldAfter at now
+func NewArchiveInsight(libraryID string, coldAfter time.Duration, now time.Time) *ArchiveInsight {
+	return &ArchiveInsight{
+		LibraryID:   libraryID,
+		Tracks:      []ColdTrack{},
+		ColdAfter:   int64(coldAfter / (24 * time.Hour)),
+		GeneratedAt: now,
+	}
+}
+
+// coldAfter returns the report's threshold as a duration
+func (i *ArchiveInsight) coldAfter() time.Duration {
+	return time.Duration(i.ColdAfter) * 24 * time.Hour
+}
+
+// Add counts the track when it is cold and not dismissed. Call Finish once
+// every track is added.
+func (i *ArchiveInsight) Add(track Track) {
+	if !track.IsCold(i.GeneratedAt, i.coldAfter()) || slices.Contains(i.Dismissed, track.ID) {
+		return
+	}
+	i.Tracks = append(i.Tracks, ColdTrack{TrackID: track.ID, ColdSince: track.ColdSince(), FileSize: track.FileSize})
+	i.ColdTracks++
+	i.ColdBytes += track.FileSize
+}
+
+// Finish sorts the cold tracks oldest first and keeps the first
+// MaxArchiveSuggestions
+func (i *ArchiveInsight) Finish() {
+	sort.SliceStable(i.Tracks, func(a, b int) bool {
+		if !i.Tracks[a].ColdSince.Equal(i.Tracks[b].ColdSince) {
+			return i.Tracks[a].ColdSince.Before(i.Tracks[b].ColdSince)
+		}
+		return i.Tracks[a].TrackID < i.Tracks[b].TrackID
+	})
+	if len(i.Tracks) > MaxArchiveSuggestions {
+		i.Tracks = i.Tracks[:MaxArchiveSuggestions]
+	}
+}
+
+// Dismiss carries dismissals over from an earlier report: the dismissed
+// tracks are dropped from this one and stay dismissed while it lists them.
+// Dismissals of tracks no longer cold are forgotten, so a track played again
+// is suggested again once it goes cold.
+func (i *ArchiveInsight) Dismiss(trackIDs []string) {
+	for _, id := range trackIDs {
+		if slices.Contains(i.Dismissed, id) {
+			continue
+		}
+		if i.Remove(id) {
+			i.Dismissed = append(i.Dismissed, id)
+		}
+	}
+}
+
+// Find returns the report's entry for a track
+func (i *ArchiveInsight) Find(trackID string) (ColdTrack, bool) {
+	for _, cold := range i.Tracks {
+		if cold.TrackID == trackID {
+			return cold, true
+		}
+	}
+	return ColdTrack{}, false
+}
+
+// Remove drops a track from the report and its counts, returning false when
+// the report does not list it
+func (i *ArchiveInsight) Remove(trackID string) bool {
+	for n, cold := range i.Tracks {
+		if cold.TrackID == trackID {
+			i.Tracks = slices.Delete(i.Tracks, n, n+1)
+			i.ColdTracks--
+			i.ColdBytes -= cold.FileSize
+			return true
+		}
+	}
+	return false
+}
+
+// ArchiveInsightItem represents an ArchiveInsight in DynamoDB single-table design
+// PK: USER#{libraryId}, SK: INSIGHT#ARCHIVE
+type ArchiveInsightItem struct {
+	DynamoDBItem
+	ArchiveInsight
+}
+
+// ArchiveInsightSK is the sort key of a library's archive insight
+const ArchiveInsightSK = "INSIGHT#ARCHIVE"
+
+// NewArchiveInsightItem creates a DynamoDB item for an archive insight
+func NewArchiveInsightItem(insight ArchiveInsight) ArchiveInsightItem {
+	return ArchiveInsightItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   "USER#" + insight.LibraryID,
+			SK:   ArchiveInsightSK,
+			Type: string(EntityArchiveInsight),
+		},
+		ArchiveInsight: insight,
+	}
+}
+
+// ArchiveSuggestion suggests archiving or deleting a track nobody has played
+// in a long time
+type ArchiveSuggestion struct {
+	TrackID    string     `json:"trackId"`
+	Title      string     `json:"title"`
+	Artist     string     `json:"artist"`
+	Album      string     `json:"album,omitempty"`
+	FileSize   int64      `json:"fileSize"`
+	PlayCount  int        `json:"playCount"`
+	LastPlayed *time.Time `json:"lastPlayed,omitempty"`
+	AddedAt    time.Time  `json:"addedAt"`
+	ColdSince  time.Time  `json:"coldSince"`
+}
+
+// NewArchiveSuggestion creates the suggestion for a cold track
+func NewArchiveSuggestion(track Track) ArchiveSuggestion {
+	return ArchiveSuggestion{
+		TrackID:    track.ID,
+		Title:      track.Title,
+		Artist:     track.Artist,
+		Album:      track.Album,
+		FileSize:   track.FileSize,
+		PlayCount:  track.PlayCount,
+		LastPlayed: track.LastPlayed,
+		AddedAt:    track.CreatedAt,
+		ColdSince:  track.ColdSince(),
+	}
+}
+
+// ArchiveSuggestionsResponse lists a library's cold tracks, oldest first
+type ArchiveSuggestionsResponse struct {
+	Suggestions []ArchiveSuggestion `json:"suggestions"`
+	// ColdTracks and ColdBytes count every cold track, including those past
+	// the suggestions listed
+	ColdTracks    int       `json:"coldTracks"`
+	ColdBytes     int64     `json:"coldBytes"`
+	ColdAfterDays int64     `json:"coldAfterDays"`
+	GeneratedAt   time.Time `json:"generatedAt"`
+}
+
+// ApplyArchiveSuggestionsRequest accepts suggestions in bulk
+type ApplyArchiveSuggestionsRequest struct {
+	TrackIDs []string      `json:"trackIds" validate:"required,min=1,max=200,dive,required"`
+	Action   ArchiveAction `json:"action" validate:"required,oneof=delete dismiss"`
+	// DryRun reports what would be applied without changing anything
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ArchiveSuggestionsResult reports the outcome of accepting archive suggestions
+type ArchiveSuggestionsResult struct {
+	Action  ArchiveAction `json:"action"`
+	Applied []string      `json:"applied"`
+	// Skipped tracks are not suggested, were played since the report, no
+	// longer exist or are locked
+	Skipped []string `json:"skipped,omitempty"`
+	// FreedBytes is the size of the files moved to the trash
+	FreedBytes int64 `json:"freedBytes"`
+	// OperationID undoes the deletions via POST /operations/{id}/undo until
+	// UndoExpiresAt; unset for dismissals and dry runs
+	OperationID   string     `json:"operationId,omitempty"`
+	UndoExpiresAt *time.Time `json:"undoExpiresAt,omitempty"`
+	// DryRun is set when nothing was changed; the result is what a real run does
+	DryRun bool `json:"dryRun,omitempty"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack_IsCold(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(-3, 0, 0)
+	recent := now.AddDate(0, -1, 0)
+
+	never := Track{Timestamps: Timestamps{CreatedAt: old}}
+	assert.True(t, never.IsCold(now, DefaultColdAfter), "a track never played is cold from when it was added")
+	assert.Equal(t, old, never.ColdSince())
+
+	played := Track{Timestamps: Timestamps{CreatedAt: old}, LastPlayed: &recent}
+	assert.False(t, played.IsCold(now, DefaultColdAfter))
+
+	added := Track{Timestamps: Timestamps{CreatedAt: recent}}
+	assert.False(t, added.IsCold(now, DefaultColdAfter))
+	assert.False(t, (&Track{}).IsCold(now, DefaultColdAfter), "a track without dates is never cold")
+}
+
+func TestArchiveInsight(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	track := func(id string, years int, size int64) Track {
+		return Track{ID: id, FileSize: size, Timestamps: Timestamps{CreatedAt: now.AddDate(-years, 0, 0)}}
+	}
+
+	insight := NewArchiveInsight("lib", DefaultColdAfter, now)
+	insight.Dismissed = []string{"kept"}
+	insight.Add(track("newer", 3, 10))
+	insight.Add(track("oldest", 5, 20))
+	insight.Add(track("fresh", 0, 40))
+	insight.Add(track("kept", 4, 80))
+	insight.Finish()
+
+	assert.Equal(t, 2, insight.ColdTracks)
+	assert.Equal(t, int64(30), insight.ColdBytes)
+	assert.Equal(t, "oldest", insight.Tracks[0].TrackID)
+	assert.Equal(t, "newer", insight.Tracks[1].TrackID)
+
+	next := NewArchiveInsight("lib", DefaultColdAfter, now)
+	next.Add(track("newer", 3, 10))
+	next.Add(track("oldest", 5, 20))
+	next.Dismiss([]string{"oldest", "replayed"})
+	next.Finish()
+	assert.Equal(t, []string{"oldest"}, next.Dismissed, "dismissals of tracks no longer cold are forgotten")
+	assert.Equal(t, 1, next.ColdTracks)
+	assert.Equal(t, int64(10), next.ColdBytes)
+
+	assert.False(t, next.Remove("oldest"))
+	_, ok := next.Find("newer")
+	assert.True(t, ok)
+}
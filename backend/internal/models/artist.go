@@ -25,6 +25,7 @@ type Artist struct {
 	ImageURL      string            `json:"imageUrl,omitempty" dynamodbav:"imageUrl,omitempty"`
 	ExternalLinks map[string]string `json:"externalLinks,omitempty" dynamodbav:"externalLinks,omitempty"`
 	IsActive      bool              `json:"isActive" dynamodbav:"isActive"`
+	SortLocale    string            `json:"-" dynamodbav:"sortLocale,omitempty"` // Locale of the name sort key (GSI5)
 	Timestamps
 }
 
@@ -36,7 +37,8 @@ type ArtistItem struct {
 
 // NewArtistItem creates a DynamoDB item for an artist
 // PK: USER#{userId}, SK: ARTIST#{artistId}
-// GSI1PK: USER#{userId}#ARTIST, GSI1SK: name (for name lookups and sorting)
+// GSI1PK: USER#{userId}#ARTIST, GSI1SK: name (for name lookups)
+// GSI5PK: USER#{userId}#SORT#ARTIST, GSI5SK: collation key of the sort name
 func NewArtistItem(artist Artist) ArtistItem {
 	item := ArtistItem{
 		DynamoDBItem: DynamoDBItem{
@@ -45,6 +47,8 @@ func NewArtistItem(artist Artist) ArtistItem {
 			Type:   string(EntityArtist),
 			GSI1PK: fmt.Sprintf("USER#%s#ARTIST", artist.UserID),
 			GSI1SK: artist.Name,
+			GSI5PK: GetSortGSI5PK(artist.UserID, EntityArtist),
+			GSI5SK: GetSortGSI5SK(artist.SortLocale, artistSortText(artist.Name, artist.SortName), artist.ID),
 		},
 		Artist: artist,
 	}
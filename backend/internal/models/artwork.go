@@ -0,0 +1,86 @@
+package models
+
+// ArtworkType classifies an image embedded in an audio file
+type ArtworkType string
+
+const (
+	ArtworkFrontCover ArtworkType = "front_cover"
+	ArtworkBackCover  ArtworkType = "back_cover"
+	ArtworkBooklet    ArtworkType = "booklet"
+	ArtworkMedia      ArtworkType = "media"
+	ArtworkArtist     ArtworkType = "artist"
+	ArtworkOther      ArtworkType = "other"
+)
+
+// ArtworkTypeFromPictureType maps the picture type code shared by ID3v2 APIC
+// frames and FLAC PICTURE blocks to an ArtworkType
+func ArtworkTypeFromPictureType(code uint32) ArtworkType {
+	switch code {
+	case 3:
+		return ArtworkFrontCover
+	case 4:
+		return ArtworkBackCover
+	case 5:
+		return ArtworkBooklet
+	case 6:
+		return ArtworkMedia
+	case 7, 8, 9, 10, 11, 12:
+		return ArtworkArtist
+	default:
+		return ArtworkOther
+	}
+}
+
+// Artwork is an embedded image stored next to the track's cover art
+type Artwork struct {
+	Type        ArtworkType `json:"type" dynamodbav:"type"`
+	Key         string      `json:"key" dynamodbav:"key"`
+	MIMEType    string      `json:"mimeType,omitempty" dynamodbav:"mimeType,omitempty"`
+	Description string      `json:"description,omitempty" dynamodbav:"description,omitempty"`
+}
+
+// ArtworkResponse represents an embedded image in API responses
+type ArtworkResponse struct {
+	Type        ArtworkType `json:"type"`
+	URL         string      `json:"url"`
+	Description string      `json:"description,omitempty"`
+}
+
+// CoverStyle holds display hints derived from cover art, so clients can
+// render color-matched UI before the image loads
+type CoverStyle struct {
+	// Colors are the dominant colors as "#rrggbb", most common first
+	Colors []string `json:"colors" dynamodbav:"colors"`
+	// BlurHash is a compact placeholder of the image (https://blurha.sh)
+	BlurHash string `json:"blurHash" dynamodbav:"blurHash"`
+	// Crop is the square around the busiest part of a non-square image; nil
+	// for square images
+	Crop *CropRect `json:"crop,omitempty" dynamodbav:"crop,omitempty"`
+	// ThumbnailKey is the S3 key of a small JPEG copy of the cover, shown in
+	// lists to users with data saver on; empty if the cover has none
+	ThumbnailKey string `json:"-" dynamodbav:"thumbnailKey,omitempty"`
+}
+
+// ListCoverKey returns the key of the cover image to show in a list: the
+// thumbnail when saveData is set and the cover has one, coverKey otherwise
+func (s *CoverStyle) ListCoverKey(coverKey string, saveData bool) string {
+	if saveData && s != nil && s.ThumbnailKey != "" {
+		return s.ThumbnailKey
+	}
+	return coverKey
+}
+
+// CropRect is a region of an image in fractions (0-1) of its width and height
+type CropRect struct {
+	X      float64 `json:"x" dynamodbav:"x"`
+	Y      float64 `json:"y" dynamodbav:"y"`
+	Width  float64 `json:"width" dynamodbav:"width"`
+	Height float64 `json:"height" dynamodbav:"height"`
+}
+
+// Chapter is a chapter marker read from the file's tags (ID3v2 CHAP frames)
+type Chapter struct {
+	Title   string `json:"title,omitempty" dynamodbav:"title,omitempty"`
+	StartMs int64  `json:"startMs" dynamodbav:"startMs"`
+	EndMs   int64  `json:"endMs" dynamodbav:"endMs"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverStyle_ListCoverKey(t *testing.T) {
+	style := &CoverStyle{ThumbnailKey: "covers/u1/up1-thumb.jpg"}
+
+	assert.Equal(t, "covers/u1/up1.png", style.ListCoverKey("covers/u1/up1.png", false))
+	assert.Equal(t, "covers/u1/up1-thumb.jpg", style.ListCoverKey("covers/u1/up1.png", true))
+	assert.Equal(t, "covers/u1/up1.png", (&CoverStyle{}).ListCoverKey("covers/u1/up1.png", true))
+
+	var none *CoverStyle
+	assert.Equal(t, "covers/u1/up1.png", none.ListCoverKey("covers/u1/up1.png", true))
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// CleanupReason explains why a metadata cleanup was suggested
+type CleanupReason string
+
+const (
+	// CleanupAllCaps is a title or artist written entirely in capitals
+	CleanupAllCaps CleanupReason = "all_caps"
+	// CleanupPlaceholder is a title such as "Track 01" that names no song; the
+	// suggestion has no value and the reviewer supplies one
+	CleanupPlaceholder CleanupReason = "placeholder"
+	// CleanupFeaturedArtist is a featured artist credited in the title, or
+	// credited as "ft."/"featuring" rather than "feat."
+	CleanupFeaturedArtist CleanupReason = "featured_artist"
+	// CleanupWhitespace is leading, trailing or repeated whitespace
+	CleanupWhitespace CleanupReason = "whitespace"
+)
+
+// CleanupSuggestion is a suggested normalized value for a track's title or artist
+type CleanupSuggestion struct {
+	TrackID   string        `json:"trackId" validate:"required"`
+	Field     string        `json:"field" validate:"required,oneof=title artist"`
+	Original  string        `json:"original"`
+	Suggested string        `json:"suggested" validate:"required,max=500"`
+	Reason    CleanupReason `json:"reason,omitempty"`
+}
+
+// CleanupSuggestionsResponse lists the cleanup suggestions for a library
+type CleanupSuggestionsResponse struct {
+	Suggestions   []CleanupSuggestion `json:"suggestions"`
+	TracksScanned int                 `json:"tracksScanned"`
+}
+
+// ApplyCleanupRequest accepts suggestions, one or many at a time. Suggested
+// may be edited by the reviewer; Original must be the value it replaces.
+type ApplyCleanupRequest struct {
+	Suggestions []CleanupSuggestion `json:"suggestions" validate:"required,min=1,max=500,dive"`
+	// DryRun reports what would be applied without editing any track
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// CleanupResult reports the outcome of accepting cleanup suggestions
+type CleanupResult struct {
+	Applied []CleanupSuggestion `json:"applied"`
+	// Skipped suggestions target a missing or locked track, or a field that
+	// no longer holds the original value
+	Skipped []CleanupSuggestion `json:"skipped,omitempty"`
+	// UpdatedTracks are the IDs of the tracks edited, in request order
+	UpdatedTracks []string `json:"updatedTracks"`
+	// DryRun is set when nothing was saved; the result is what a real run does
+	DryRun bool `json:"dryRun,omitempty"`
+	// OperationID undoes the edits via POST /operations/{id}/undo until
+	// UndoExpiresAt; unset for dry runs and when no undo record was kept
+	OperationID   string     `json:"operationId,omitempty"`
+	UndoExpiresAt *time.Time `json:"undoExpiresAt,omitempty"`
+}
+
+// ApplyCleanup replaces the suggested field with the suggested value. It
+// returns false, leaving the track unchanged, when the field no longer holds
+// the original value.
+func (t *Track) ApplyCleanup(s CleanupSuggestion) bool {
+	if s.Field != TagFieldTitle && s.Field != TagFieldArtist {
+		return false
+	}
+	value := t.tagField(s.Field)
+	if *value != s.Original {
+		return false
+	}
+	*value = s.Suggested
+	return true
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack_ApplyCleanup(t *testing.T) {
+	t.Run("replaces the original value", func(t *testing.T) {
+		track := Track{Title: "HELLO WORLD"}
+
+		applied := track.ApplyCleanup(CleanupSuggestion{Field: TagFieldTitle, Original: "HELLO WORLD", Suggested: "Hello World"})
+
+		assert.True(t, applied)
+		assert.Equal(t, "Hello World", track.Title)
+	})
+
+	t.Run("skips fields changed since the suggestion", func(t *testing.T) {
+		track := Track{Artist: "Daft Punk"}
+
+		applied := track.ApplyCleanup(CleanupSuggestion{Field: TagFieldArtist, Original: "DAFT PUNK", Suggested: "Daft Punk!"})
+
+		assert.False(t, applied)
+		assert.Equal(t, "Daft Punk", track.Artist)
+	})
+
+	t.Run("only title and artist can be cleaned up", func(t *testing.T) {
+		track := Track{Album: "LOUD ALBUM"}
+
+		assert.False(t, track.ApplyCleanup(CleanupSuggestion{Field: TagFieldAlbum, Original: "LOUD ALBUM", Suggested: "Loud Album"}))
+		assert.Equal(t, "LOUD ALBUM", track.Album)
+	})
+}
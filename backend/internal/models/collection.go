@@ -0,0 +1,212 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// EntityCollection represents the entity type for a library collection
+const EntityCollection EntityType = "COLLECTION"
+
+// Collection limits
+const (
+	MaxCollectionsPerLibrary = 50
+	// MaxCollectionTracks bounds the members of a manual collection
+	MaxCollectionTracks = 1000
+)
+
+// CollectionKind says how a collection's tracks are chosen
+type CollectionKind string
+
+const (
+	CollectionKindFilter CollectionKind = "filter" // tracks matching a saved filter
+	CollectionKindManual CollectionKind = "manual" // tracks added by hand
+)
+
+// CollectionFilter is the saved filter of a filter collection. Empty fields
+// match every track; a track must match all the others.
+type CollectionFilter struct {
+	// Artist, Album and Genre match whole values, ignoring case
+	Artist string `json:"artist,omitempty" dynamodbav:"artist,omitempty"`
+	Album  string `json:"album,omitempty" dynamodbav:"album,omitempty"`
+	Genre  string `json:"genre,omitempty" dynamodbav:"genre,omitempty"`
+	// YearFrom and YearTo bound the release year, inclusive
+	YearFrom int           `json:"yearFrom,omitempty" dynamodbav:"yearFrom,omitempty" validate:"omitempty,min=1000,max=9999"`
+	YearTo   int           `json:"yearTo,omitempty" dynamodbav:"yearTo,omitempty" validate:"omitempty,min=1000,max=9999"`
+	Formats  []AudioFormat `json:"formats,omitempty" dynamodbav:"formats,omitempty" validate:"max=10,dive,oneof=MP3 FLAC WAV AAC OGG"`
+	// Tags must all be on the track, ignoring case
+	Tags       []string `json:"tags,omitempty" dynamodbav:"tags,omitempty" validate:"max=10"`
+	BPMMin     int      `json:"bpmMin,omitempty" dynamodbav:"bpmMin,omitempty" validate:"omitempty,min=20,max=300"`
+	BPMMax     int      `json:"bpmMax,omitempty" dynamodbav:"bpmMax,omitempty" validate:"omitempty,min=20,max=300"`
+	MusicalKey string   `json:"musicalKey,omitempty" dynamodbav:"musicalKey,omitempty"`
+	// AddedFrom and AddedTo bound when the track was added to the library
+	AddedFrom *time.Time `json:"addedFrom,omitempty" dynamodbav:"addedFrom,omitempty"`
+	AddedTo   *time.Time `json:"addedTo,omitempty" dynamodbav:"addedTo,omitempty"`
+}
+
+// Empty reports whether the filter matches every track
+func (f *CollectionFilter) Empty() bool {
+	return f.Artist == "" && f.Album == "" && f.Genre == "" && f.YearFrom == 0 && f.YearTo == 0 &&
+		len(f.Formats) == 0 && len(f.Tags) == 0 && f.BPMMin == 0 && f.BPMMax == 0 &&
+		f.MusicalKey == "" && f.AddedFrom == nil && f.AddedTo == nil
+}
+
+// Validate checks that the filter narrows the library and its ranges are
+// the right way round
+func (f *CollectionFilter) Validate() error {
+	switch {
+	case f.Empty():
+		return NewValidationError("filter must set at least one field")
+	case f.YearFrom != 0 && f.YearTo != 0 && f.YearFrom > f.YearTo:
+		return NewValidationError("yearFrom must not be after yearTo")
+	case f.BPMMin != 0 && f.BPMMax != 0 && f.BPMMin > f.BPMMax:
+		return NewValidationError("bpmMin must not be above bpmMax")
+	case f.AddedFrom != nil && f.AddedTo != nil && f.AddedFrom.After(*f.AddedTo):
+		return NewValidationError("addedFrom must not be after addedTo")
+	}
+	return nil
+}
+
+// Matches reports whether the track passes every part of the filter
+func (f *CollectionFilter) Matches(t *Track) bool {
+	switch {
+	case f.Artist != "" && !strings.EqualFold(f.Artist, t.Artist),
+		f.Album != "" && !strings.EqualFold(f.Album, t.Album),
+		f.Genre != "" && !strings.EqualFold(f.Genre, t.Genre),
+		f.YearFrom != 0 && t.Year < f.YearFrom,
+		f.YearTo != 0 && (t.Year == 0 || t.Year > f.YearTo),
+		len(f.Formats) > 0 && !slices.Contains(f.Formats, t.Format),
+		f.BPMMin != 0 && t.BPM < f.BPMMin,
+		f.BPMMax != 0 && (t.BPM == 0 || t.BPM > f.BPMMax),
+		f.MusicalKey != "" && !strings.EqualFold(f.MusicalKey, t.MusicalKey),
+		f.AddedFrom != nil && t.CreatedAt.Before(*f.AddedFrom),
+		f.AddedTo != nil && t.CreatedAt.After(*f.AddedTo):
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.ContainsFunc(t.Tags, func(have string) bool { return strings.EqualFold(have, tag) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// Collection is a named view of a library shown in the sidebar, e.g. "Vinyl
+// rips" or "2024 acquisitions". Its tracks match a saved filter or were added
+// by hand. TrackCount is kept up to date as tracks change rather than counted
+// on each request, so it can briefly lag behind the library.
+type Collection struct {
+	ID string `json:"id" dynamodbav:"id"`
+	// UserID is the library the collection belongs to; household members
+	// share their library's collections
+	UserID string            `json:"userId" dynamodbav:"userId"`
+	Name   string            `json:"name" dynamodbav:"name"`
+	Filter *CollectionFilter `json:"filter,omitempty" dynamodbav:"filter,omitempty"`
+	// TrackIDs are the members of a manual collection
+	TrackIDs   []string `json:"trackIds,omitempty" dynamodbav:"trackIds,stringset,omitempty"`
+	TrackCount int      `json:"trackCount" dynamodbav:"trackCount"`
+	// Position orders the library's collections in the sidebar
+	Position int `json:"position" dynamodbav:"position"`
+	Timestamps
+}
+
+// Kind reports how the collection's tracks are chosen
+func (c *Collection) Kind() CollectionKind {
+	if c.Filter != nil {
+		return CollectionKindFilter
+	}
+	return CollectionKindManual
+}
+
+// Contains reports whether the track belongs to the collection; nil tracks
+// belong to none
+func (c *Collection) Contains(t *Track) bool {
+	if t == nil {
+		return false
+	}
+	if c.Filter != nil {
+		return c.Filter.Matches(t)
+	}
+	return slices.Contains(c.TrackIDs, t.ID)
+}
+
+// CollectionItem represents a Collection in DynamoDB single-table design
+// PK: USER#{libraryId}, SK: COLLECTION#{collectionId}
+type CollectionItem struct {
+	DynamoDBItem
+	Collection
+}
+
+// CollectionSKPrefix is the sort key prefix of a library's collections
+const CollectionSKPrefix = "COLLECTION#"
+
+// GetCollectionSK returns the sort key of a collection
+func GetCollectionSK(collectionID string) string {
+	return CollectionSKPrefix + collectionID
+}
+
+// NewCollectionItem creates a DynamoDB item for a collection
+func NewCollectionItem(collection Collection) CollectionItem {
+	return CollectionItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   "USER#" + collection.UserID,
+			SK:   GetCollectionSK(collection.ID),
+			Type: string(EntityCollection),
+		},
+		Collection: collection,
+	}
+}
+
+// CreateCollectionRequest creates a collection. A filter makes a filter
+// collection; without one the collection starts empty and tracks are added
+// by hand.
+type CreateCollectionRequest struct {
+	Name   string            `json:"name" validate:"required,max=100"`
+	Filter *CollectionFilter `json:"filter,omitempty"`
+}
+
+// UpdateCollectionRequest renames a collection or replaces its filter. A
+// manual collection cannot be given a filter, nor a filter collection lose it.
+type UpdateCollectionRequest struct {
+	Name   *string           `json:"name,omitempty" validate:"omitempty,max=100"`
+	Filter *CollectionFilter `json:"filter,omitempty"`
+}
+
+// ReorderCollectionsRequest lists every collection of the library in its new
+// sidebar order
+type ReorderCollectionsRequest struct {
+	CollectionIDs []string `json:"collectionIds" validate:"required,min=1,max=50"`
+}
+
+// CollectionTracksRequest adds tracks to or removes them from a manual collection
+type CollectionTracksRequest struct {
+	TrackIDs []string `json:"trackIds" validate:"required,min=1,max=100"`
+}
+
+// CollectionResponse represents a collection in API responses
+type CollectionResponse struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Kind       CollectionKind    `json:"kind"`
+	Filter     *CollectionFilter `json:"filter,omitempty"`
+	TrackCount int               `json:"trackCount"`
+	Position   int               `json:"position"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+}
+
+// ToResponse converts a Collection to CollectionResponse. Counts adjusted
+// twice by a retried change are clamped until the next recount.
+func (c *Collection) ToResponse() CollectionResponse {
+	return CollectionResponse{
+		ID:         c.ID,
+		Name:       c.Name,
+		Kind:       c.Kind(),
+		Filter:     c.Filter,
+		TrackCount: max(c.TrackCount, 0),
+		Position:   c.Position,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectionFilter_Matches(t *testing.T) {
+	added := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	track := Track{
+		ID:         "t1",
+		Artist:     "Boards of Canada",
+		Album:      "Geogaddi",
+		Genre:      "Electronic",
+		Year:       2002,
+		Format:     AudioFormatFLAC,
+		Tags:       []string{"Vinyl", "favorites"},
+		BPM:        96,
+		MusicalKey: "Am",
+		Timestamps: Timestamps{CreatedAt: added},
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)
+	before := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter CollectionFilter
+		want   bool
+	}{
+		{"empty", CollectionFilter{}, true},
+		{"artist ignoring case", CollectionFilter{Artist: "boards of canada"}, true},
+		{"other artist", CollectionFilter{Artist: "Autechre"}, false},
+		{"year range", CollectionFilter{YearFrom: 2000, YearTo: 2005}, true},
+		{"after year range", CollectionFilter{YearTo: 2001}, false},
+		{"format", CollectionFilter{Formats: []AudioFormat{AudioFormatFLAC, AudioFormatWAV}}, true},
+		{"other format", CollectionFilter{Formats: []AudioFormat{AudioFormatMP3}}, false},
+		{"all tags", CollectionFilter{Tags: []string{"vinyl", "Favorites"}}, true},
+		{"missing tag", CollectionFilter{Tags: []string{"vinyl", "live"}}, false},
+		{"bpm range", CollectionFilter{BPMMin: 90, BPMMax: 100}, true},
+		{"below bpm", CollectionFilter{BPMMin: 120}, false},
+		{"key", CollectionFilter{MusicalKey: "am"}, true},
+		{"added in 2024", CollectionFilter{AddedFrom: &from, AddedTo: &to}, true},
+		{"added before", CollectionFilter{AddedTo: &before}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(&track))
+		})
+	}
+
+	t.Run("unknown values fail upper bounds", func(t *testing.T) {
+		unknown := Track{ID: "t2"}
+		assert.False(t, (&CollectionFilter{YearTo: 2000}).Matches(&unknown))
+		assert.False(t, (&CollectionFilter{BPMMax: 120}).Matches(&unknown))
+	})
+}
+
+func TestCollectionFilter_Validate(t *testing.T) {
+	assert.Error(t, (&CollectionFilter{}).Validate(), "an empty filter is the whole library")
+	assert.Error(t, (&CollectionFilter{YearFrom: 2010, YearTo: 2000}).Validate())
+	assert.Error(t, (&CollectionFilter{BPMMin: 130, BPMMax: 120}).Validate())
+	assert.NoError(t, (&CollectionFilter{YearFrom: 2024, YearTo: 2024}).Validate())
+}
+
+func TestCollection_Contains(t *testing.T) {
+	track := &Track{ID: "t1", Genre: "Jazz"}
+
+	manual := Collection{TrackIDs: []string{"t1", "t2"}}
+	assert.Equal(t, CollectionKindManual, manual.Kind())
+	assert.True(t, manual.Contains(track))
+	assert.False(t, manual.Contains(&Track{ID: "t3"}))
+	assert.False(t, manual.Contains(nil))
+
+	filtered := Collection{Filter: &CollectionFilter{Genre: "jazz"}}
+	assert.Equal(t, CollectionKindFilter, filtered.Kind())
+	assert.True(t, filtered.Contains(track))
+	assert.False(t, filtered.Contains(&Track{ID: "t1", Genre: "Rock"}))
+}
+
+func TestCollection_ToResponse(t *testing.T) {
+	collection := Collection{ID: "c1", Name: "Vinyl rips", TrackCount: -1, Position: 2}
+	response := collection.ToResponse()
+	assert.Equal(t, 0, response.TrackCount, "counts are never negative")
+	assert.Equal(t, CollectionKindManual, response.Kind)
+	assert.Equal(t, 2, response.Position)
+
+	item := NewCollectionItem(Collection{ID: "c1", UserID: "lib-1"})
+	assert.Equal(t, "USER#lib-1", item.PK)
+	assert.Equal(t, "COLLECTION#c1", item.SK)
+}
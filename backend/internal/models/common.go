@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/base64"
 	"encoding/json"
+	"path/filepath"
 	"time"
 )
 
@@ -42,6 +43,43 @@ const (
 	AudioFormatOGG  AudioFormat = "OGG"
 )
 
+// Extension returns the file extension of downloads in the format
+func (f AudioFormat) Extension() string {
+	switch f {
+	case AudioFormatMP3:
+		return ".mp3"
+	case AudioFormatFLAC:
+		return ".flac"
+	case AudioFormatWAV:
+		return ".wav"
+	case AudioFormatAAC:
+		return ".m4a"
+	case AudioFormatOGG:
+		return ".ogg"
+	default:
+		return filepath.Ext(string(f))
+	}
+}
+
+// AudioFormatForExtension returns the format of files with the extension
+// (lowercase, including the dot), or false when uploads of it are not supported
+func AudioFormatForExtension(ext string) (AudioFormat, bool) {
+	switch ext {
+	case ".mp3":
+		return AudioFormatMP3, true
+	case ".flac":
+		return AudioFormatFLAC, true
+	case ".wav":
+		return AudioFormatWAV, true
+	case ".m4a", ".aac":
+		return AudioFormatAAC, true
+	case ".ogg":
+		return AudioFormatOGG, true
+	default:
+		return "", false
+	}
+}
+
 // Timestamps provides common timestamp fields
 type Timestamps struct {
 	CreatedAt time.Time `json:"createdAt" dynamodbav:"createdAt"`
@@ -58,6 +96,10 @@ type DynamoDBItem struct {
 	GSI2SK string `dynamodbav:"GSI2SK,omitempty"` // Used for public playlist discovery
 	GSI3PK string `dynamodbav:"GSI3PK,omitempty"` // Used for public track discovery
 	GSI3SK string `dynamodbav:"GSI3SK,omitempty"` // Used for public track discovery
+	GSI4PK string `dynamodbav:"GSI4PK,omitempty"` // Used for harmonic neighbor queries
+	GSI4SK string `dynamodbav:"GSI4SK,omitempty"` // Used for harmonic neighbor queries
+	GSI5PK string `dynamodbav:"GSI5PK,omitempty"` // Used for alphabetical browse
+	GSI5SK string `dynamodbav:"GSI5SK,omitempty"` // Used for alphabetical browse
 	Type   string `dynamodbav:"Type"`
 }
 
@@ -75,6 +117,15 @@ type PaginatedResponse[T any] struct {
 	Pagination Pagination `json:"pagination"`
 }
 
+// CountResponse is returned by list endpoints called with countOnly=true
+type CountResponse struct {
+	Count int `json:"count"`
+}
+
+// TotalCountHeader carries the item count in responses to HEAD requests on
+// list endpoints
+const TotalCountHeader = "X-Total-Count"
+
 // PaginationCursor represents the internal structure of a pagination cursor
 // This is encoded to base64 and passed to clients as an opaque string
 type PaginationCursor struct {
@@ -82,6 +133,8 @@ type PaginationCursor struct {
 	SK     string `json:"sk"`
 	GSI1PK string `json:"gsi1pk,omitempty"`
 	GSI1SK string `json:"gsi1sk,omitempty"`
+	GSI5PK string `json:"gsi5pk,omitempty"`
+	GSI5SK string `json:"gsi5sk,omitempty"`
 }
 
 // EncodeCursor encodes a PaginationCursor to an opaque base64 string
@@ -71,6 +71,32 @@ func TestAudioFormatConstants(t *testing.T) {
 	}
 }
 
+// TestAudioFormatForExtension verifies upload formats are recognized by extension
+func TestAudioFormatForExtension(t *testing.T) {
+	tests := []struct {
+		ext       string
+		expected  AudioFormat
+		supported bool
+	}{
+		{".mp3", AudioFormatMP3, true},
+		{".flac", AudioFormatFLAC, true},
+		{".wav", AudioFormatWAV, true},
+		{".m4a", AudioFormatAAC, true},
+		{".aac", AudioFormatAAC, true},
+		{".ogg", AudioFormatOGG, true},
+		{".wma", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ext, func(t *testing.T) {
+			format, ok := AudioFormatForExtension(tt.ext)
+			assert.Equal(t, tt.supported, ok)
+			assert.Equal(t, tt.expected, format)
+		})
+	}
+}
+
 // TestFormatDuration verifies duration formatting
 func TestFormatDuration(t *testing.T) {
 	tests := []struct {
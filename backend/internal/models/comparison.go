@@ -0,0 +1,264 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// EntityComparisonConsent represents the entity type for a user's consent to
+// compare libraries with another user
+const EntityComparisonConsent EntityType = "COMPARISON_CONSENT"
+
+const (
+	// MaxComparisonTracks caps each track list of a library comparison; the
+	// counts always cover the whole libraries
+	MaxComparisonTracks = 500
+	// MaxComparisonPlaylists caps the playlists of each user checked for
+	// joint listening
+	MaxComparisonPlaylists = 50
+	// MinJointPlaylistCoverage is the share of a playlist's tracks both
+	// libraries must hold for it to be jointly playable
+	MinJointPlaylistCoverage = 0.8
+	// recordingDurationTolerance is how far apart, in seconds, the durations
+	// of two tracks matched by artist and title may be
+	recordingDurationTolerance = 3
+)
+
+// ComparisonConsent records that a user agreed to compare their library with
+// a peer's. A comparison needs the consent of both users; either can
+// withdraw theirs at any time.
+type ComparisonConsent struct {
+	UserID    string    `json:"userId" dynamodbav:"userId"`
+	UserEmail string    `json:"userEmail" dynamodbav:"userEmail"`
+	PeerID    string    `json:"peerId" dynamodbav:"peerId"`
+	PeerEmail string    `json:"peerEmail" dynamodbav:"peerEmail"`
+	CreatedAt time.Time `json:"createdAt" dynamodbav:"createdAt"`
+}
+
+// ComparisonConsentItem represents a ComparisonConsent in DynamoDB single-table design
+type ComparisonConsentItem struct {
+	DynamoDBItem
+	ComparisonConsent
+}
+
+// NewComparisonConsentItem creates a DynamoDB item for a comparison consent.
+// GSI1 lists the consents given to a user.
+// Primary key pattern: PK=USER#{userID}, SK=COMPARE#{peerID}
+// GSI1 pattern: GSI1PK=COMPARE_WITH#{peerID}, GSI1SK=COMPARE#{userID}
+func NewComparisonConsentItem(consent ComparisonConsent) ComparisonConsentItem {
+	return ComparisonConsentItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:     fmt.Sprintf("USER#%s", consent.UserID),
+			SK:     GetComparisonConsentSK(consent.PeerID),
+			GSI1PK: GetComparisonConsentGSI1PK(consent.PeerID),
+			GSI1SK: GetComparisonConsentSK(consent.UserID),
+			Type:   string(EntityComparisonConsent),
+		},
+		ComparisonConsent: consent,
+	}
+}
+
+// GetComparisonConsentSK returns the sort key of a consent to compare with peerID
+func GetComparisonConsentSK(peerID string) string {
+	return fmt.Sprintf("COMPARE#%s", peerID)
+}
+
+// GetComparisonConsentGSI1PK returns the GSI1 partition key of the consents given to peerID
+func GetComparisonConsentGSI1PK(peerID string) string {
+	return fmt.Sprintf("COMPARE_WITH#%s", peerID)
+}
+
+// CreateComparisonConsentRequest asks to compare libraries with the user
+// registered under Email
+type CreateComparisonConsentRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ComparisonPeer is a user the caller gave consent to, or received consent
+// from. Mutual peers can be compared.
+type ComparisonPeer struct {
+	UserID      string    `json:"userId"`
+	Email       string    `json:"email"`
+	Mutual      bool      `json:"mutual"`
+	ConsentedAt time.Time `json:"consentedAt"`
+}
+
+// ComparisonConsentsResponse lists the consents a user gave and received
+type ComparisonConsentsResponse struct {
+	Given    []ComparisonPeer `json:"given"`
+	Received []ComparisonPeer `json:"received"`
+}
+
+// RecordingMatch names how two tracks were found to be the same recording
+type RecordingMatch string
+
+const (
+	// MatchContent is an identical audio file
+	MatchContent RecordingMatch = "content"
+	// MatchMetadata is the same artist and title with durations within a few seconds
+	MatchMetadata RecordingMatch = "metadata"
+)
+
+// RecordingPair is a recording held by both libraries
+type RecordingPair struct {
+	Mine      Track
+	Theirs    Track
+	MatchedBy RecordingMatch
+}
+
+// MatchRecordings pairs the tracks of two libraries that hold the same
+// recording: first identical audio files, then tracks with the same
+// normalized artist and title whose durations are within a few seconds.
+// Each track is paired at most once; the rest are returned per side.
+func MatchRecordings(mine, theirs []Track) (pairs []RecordingPair, onlyMine, onlyTheirs []Track) {
+	paired := make([]bool, len(theirs))
+	byHash := make(map[string][]int)
+	byKey := make(map[string][]int)
+	for i, track := range theirs {
+		if track.ContentHash != "" {
+			byHash[track.ContentHash] = append(byHash[track.ContentHash], i)
+		}
+		if key := RecordingKey(track); key != "" {
+			byKey[key] = append(byKey[key], i)
+		}
+	}
+
+	var unmatched []Track
+	for _, track := range mine {
+		if j := takeRecording(byHash[track.ContentHash], paired, nil); track.ContentHash != "" && j >= 0 {
+			pairs = append(pairs, RecordingPair{Mine: track, Theirs: theirs[j], MatchedBy: MatchContent})
+			continue
+		}
+		unmatched = append(unmatched, track)
+	}
+	for _, track := range unmatched {
+		key := RecordingKey(track)
+		if key == "" {
+			onlyMine = append(onlyMine, track)
+			continue
+		}
+		j := takeRecording(byKey[key], paired, func(i int) bool { return durationsMatch(track.Duration, theirs[i].Duration) })
+		if j < 0 {
+			onlyMine = append(onlyMine, track)
+			continue
+		}
+		pairs = append(pairs, RecordingPair{Mine: track, Theirs: theirs[j], MatchedBy: MatchMetadata})
+	}
+	for i, track := range theirs {
+		if !paired[i] {
+			onlyTheirs = append(onlyTheirs, track)
+		}
+	}
+	return pairs, onlyMine, onlyTheirs
+}
+
+// takeRecording marks and returns the first unpaired candidate accepted by
+// ok, or -1
+func takeRecording(candidates []int, paired []bool, ok func(int) bool) int {
+	for _, i := range candidates {
+		if !paired[i] && (ok == nil || ok(i)) {
+			paired[i] = true
+			return i
+		}
+	}
+	return -1
+}
+
+// durationsMatch reports whether two durations are close enough for the same
+// recording; an unknown duration matches any other
+func durationsMatch(a, b int) bool {
+	if a <= 0 || b <= 0 {
+		return true
+	}
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= recordingDurationTolerance
+}
+
+// RecordingKey returns the normalized artist and title a track is matched
+// on by metadata, or "" for a track without a title
+func RecordingKey(track Track) string {
+	title := normalizeRecordingText(track.Title)
+	if title == "" {
+		return ""
+	}
+	return normalizeRecordingText(track.Artist) + "\x00" + title
+}
+
+// normalizeRecordingText lowercases s and keeps its letters and digits, one
+// space between words
+func normalizeRecordingText(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// ComparedTrack is a track as shown in a library comparison
+type ComparedTrack struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Album    string `json:"album,omitempty"`
+	Duration int    `json:"duration"`
+}
+
+// NewComparedTrack returns the comparison view of a track
+func NewComparedTrack(track Track) ComparedTrack {
+	return ComparedTrack{ID: track.ID, Title: track.Title, Artist: track.Artist, Album: track.Album, Duration: track.Duration}
+}
+
+// SharedRecording is a recording held by both libraries, with each side's track
+type SharedRecording struct {
+	Mine      ComparedTrack  `json:"mine"`
+	Theirs    ComparedTrack  `json:"theirs"`
+	MatchedBy RecordingMatch `json:"matchedBy"`
+}
+
+// JointPlaylist is a playlist of either user that both libraries can mostly play
+type JointPlaylist struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	OwnerID string `json:"ownerId"`
+	// TrackCount is the playlist's length and SharedTracks how many of its
+	// tracks the other library also holds
+	TrackCount   int     `json:"trackCount"`
+	SharedTracks int     `json:"sharedTracks"`
+	Coverage     float64 `json:"coverage"`
+}
+
+// SortJointPlaylists orders playlists by coverage, then length, then name
+func SortJointPlaylists(playlists []JointPlaylist) {
+	sort.SliceStable(playlists, func(i, j int) bool {
+		a, b := playlists[i], playlists[j]
+		if a.Coverage != b.Coverage {
+			return a.Coverage > b.Coverage
+		}
+		if a.TrackCount != b.TrackCount {
+			return a.TrackCount > b.TrackCount
+		}
+		return a.Name < b.Name
+	})
+}
+
+// LibraryComparison compares the caller's library with a peer's. Track lists
+// are capped at MaxComparisonTracks; the counts cover the whole libraries.
+type LibraryComparison struct {
+	PeerID          string            `json:"peerId"`
+	MyTrackCount    int               `json:"myTrackCount"`
+	TheirTrackCount int               `json:"theirTrackCount"`
+	OverlapCount    int               `json:"overlapCount"`
+	OnlyMineCount   int               `json:"onlyMineCount"`
+	OnlyTheirsCount int               `json:"onlyTheirsCount"`
+	Similarity      float64           `json:"similarity"` // Shared recordings over all distinct recordings
+	Overlap         []SharedRecording `json:"overlap"`
+	OnlyMine        []ComparedTrack   `json:"onlyMine"`
+	OnlyTheirs      []ComparedTrack   `json:"onlyTheirs"`
+	JointPlaylists  []JointPlaylist   `json:"jointPlaylists"`
+	Truncated       bool              `json:"truncated"` // A track list was capped
+}
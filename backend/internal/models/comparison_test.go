@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRecordings(t *testing.T) {
+	mine := []Track{
+		{ID: "m1", Title: "Song A", Artist: "Band", Duration: 200, ContentHash: "h1"},
+		{ID: "m2", Title: "Song B (Live)", Artist: "The Band", Duration: 240},
+		{ID: "m3", Title: "song b live", Artist: "the band", Duration: 300},
+		{ID: "m4", Title: "Only Mine", Artist: "Band", Duration: 100},
+		{ID: "m5", Title: "", Artist: "Band", Duration: 100},
+	}
+	theirs := []Track{
+		{ID: "t1", Title: "Renamed", Artist: "Other", Duration: 200, ContentHash: "h1"},
+		{ID: "t2", Title: "SONG B - live", Artist: "The  Band", Duration: 242},
+		{ID: "t3", Title: "Only Theirs", Artist: "Band", Duration: 100},
+		{ID: "t4", Title: "", Artist: "Band", Duration: 100},
+	}
+
+	pairs, onlyMine, onlyTheirs := MatchRecordings(mine, theirs)
+
+	require.Len(t, pairs, 2)
+	assert.Equal(t, "m1", pairs[0].Mine.ID)
+	assert.Equal(t, "t1", pairs[0].Theirs.ID)
+	assert.Equal(t, MatchContent, pairs[0].MatchedBy)
+	assert.Equal(t, "m2", pairs[1].Mine.ID)
+	assert.Equal(t, "t2", pairs[1].Theirs.ID)
+	assert.Equal(t, MatchMetadata, pairs[1].MatchedBy)
+
+	ids := func(tracks []Track) []string {
+		var out []string
+		for _, track := range tracks {
+			out = append(out, track.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"m3", "m4", "m5"}, ids(onlyMine), "a minute longer is another recording, and untitled tracks never match")
+	assert.Equal(t, []string{"t3", "t4"}, ids(onlyTheirs))
+}
+
+func TestRecordingKey(t *testing.T) {
+	assert.Equal(t, RecordingKey(Track{Artist: "AC/DC", Title: "T.N.T."}), RecordingKey(Track{Artist: "ac dc", Title: "t n t"}))
+	assert.Empty(t, RecordingKey(Track{Artist: "Band", Title: "  ?! "}))
+}
+
+func TestSortJointPlaylists(t *testing.T) {
+	playlists := []JointPlaylist{
+		{Name: "b", TrackCount: 5, Coverage: 0.8},
+		{Name: "c", TrackCount: 10, Coverage: 0.8},
+		{Name: "a", TrackCount: 2, Coverage: 1},
+	}
+	SortJointPlaylists(playlists)
+	assert.Equal(t, "a", playlists[0].Name)
+	assert.Equal(t, "c", playlists[1].Name)
+	assert.Equal(t, "b", playlists[2].Name)
+}
@@ -0,0 +1,59 @@
+package models
+
+// DJ software library exports that can be imported
+const (
+	DJImportRekordbox = "rekordbox"
+	DJImportSerato    = "serato"
+	DJImportTraktor   = "traktor"
+)
+
+// What imported crates and playlists become
+const (
+	DJImportCratesAsPlaylist = "playlist"
+	DJImportCratesAsTag      = "tag"
+)
+
+// DJImportRequest imports cue points, ratings and crates from a DJ software
+// library export
+type DJImportRequest struct {
+	Format string `json:"format" validate:"required,oneof=rekordbox serato traktor"`
+	// Content is the export: Rekordbox XML or Traktor collection.nml text, or
+	// a base64-encoded Serato .crate file
+	Content string `json:"content" validate:"required"`
+	// FileName is the Serato crate's file name, which holds the crate's name
+	FileName string `json:"fileName,omitempty" validate:"omitempty,max=255"`
+	// CratesAs imports crates and playlists as playlists (default) or tags
+	CratesAs string `json:"cratesAs,omitempty" validate:"omitempty,oneof=playlist tag"`
+	// Overwrite replaces hot cues and ratings already set in the library
+	Overwrite bool `json:"overwrite,omitempty"`
+	// DryRun matches tracks and reports the result without changing anything
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// DJImportEntry is a track from the export
+type DJImportEntry struct {
+	Title    string `json:"title,omitempty"`
+	Artist   string `json:"artist,omitempty"`
+	Location string `json:"location,omitempty"`
+}
+
+// DJImportedCrate is a crate imported as a playlist or tag
+type DJImportedCrate struct {
+	Name       string `json:"name"`
+	PlaylistID string `json:"playlistId,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	TrackCount int    `json:"trackCount"`
+}
+
+// DJImportResult reports what an import matched and changed
+type DJImportResult struct {
+	TracksInExport  int               `json:"tracksInExport"`
+	Matched         int               `json:"matched"`
+	CuesImported    int               `json:"cuesImported"`
+	RatingsImported int               `json:"ratingsImported"`
+	Crates          []DJImportedCrate `json:"crates"`
+	// Locked lists matched tracks left unchanged because they are locked
+	Locked    []string        `json:"locked,omitempty"`
+	Unmatched []DJImportEntry `json:"unmatched"`
+	DryRun    bool            `json:"dryRun,omitempty"`
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+)
+
+// EntityTrackEmbedding represents the entity type for stored track embeddings
+const EntityTrackEmbedding EntityType = "TRACK_EMBEDDING"
+
+// TrackEmbedding is a track's embedding vector tagged with the model that
+// produced it. A track can hold vectors from several models while a model
+// migration is in progress; vectors from different models are never compared.
+type TrackEmbedding struct {
+	UserID    string    `json:"userId" dynamodbav:"userId"`
+	TrackID   string    `json:"trackId" dynamodbav:"trackId"`
+	Model     string    `json:"model" dynamodbav:"model"`
+	Dims      int       `json:"dims" dynamodbav:"dims"`
+	Vector    []float32 `json:"vector" dynamodbav:"-"`
+	TextHash  string    `json:"textHash" dynamodbav:"textHash"` // Hash of the embedded text, to skip unchanged tracks
+	CreatedAt time.Time `json:"createdAt" dynamodbav:"createdAt"`
+}
+
+// TrackEmbeddingItem represents TrackEmbedding in DynamoDB single-table design.
+// The vector is stored as packed little-endian float32s in one binary
+// attribute, which is about a third the size of a list of numbers.
+type TrackEmbeddingItem struct {
+	DynamoDBItem
+	TrackEmbedding
+	Data []byte `dynamodbav:"vector"`
+}
+
+// NewTrackEmbeddingItem creates a DynamoDB item for a track embedding.
+// Primary key pattern: PK=USER#{userID}, SK=EMBEDDING#{model}#{trackID}
+func NewTrackEmbeddingItem(embedding TrackEmbedding) TrackEmbeddingItem {
+	embedding.Dims = len(embedding.Vector)
+	return TrackEmbeddingItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   fmt.Sprintf("USER#%s", embedding.UserID),
+			SK:   GetTrackEmbeddingSK(embedding.Model, embedding.TrackID),
+			Type: string(EntityTrackEmbedding),
+		},
+		TrackEmbedding: embedding,
+		Data:           EncodeVector(embedding.Vector),
+	}
+}
+
+// Embedding returns the stored embedding with its vector decoded
+func (i TrackEmbeddingItem) Embedding() (TrackEmbedding, error) {
+	embedding := i.TrackEmbedding
+	vector, err := DecodeVector(i.Data)
+	if err != nil {
+		return TrackEmbedding{}, fmt.Errorf("embedding %s/%s: %w", embedding.Model, embedding.TrackID, err)
+	}
+	if embedding.Dims != 0 && len(vector) != embedding.Dims {
+		return TrackEmbedding{}, fmt.Errorf("embedding %s/%s: has %d dimensions, expected %d", embedding.Model, embedding.TrackID, len(vector), embedding.Dims)
+	}
+	embedding.Vector = vector
+	return embedding, nil
+}
+
+// GetTrackEmbeddingSK returns the sort key of a track's embedding for a model
+func GetTrackEmbeddingSK(model, trackID string) string {
+	return fmt.Sprintf("%s%s", GetTrackEmbeddingSKPrefix(model), trackID)
+}
+
+// GetTrackEmbeddingSKPrefix returns the sort key prefix shared by every
+// embedding of a model, or by every embedding when model is empty
+func GetTrackEmbeddingSKPrefix(model string) string {
+	if model == "" {
+		return "EMBEDDING#"
+	}
+	return fmt.Sprintf("EMBEDDING#%s#", model)
+}
+
+// EmbeddingTextHash returns the hash recorded with an embedding of text
+func EmbeddingTextHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:16])
+}
+
+// EncodeVector packs v as little-endian float32s
+func EncodeVector(v []float32) []byte {
+	data := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(f))
+	}
+	return data
+}
+
+// DecodeVector unpacks a vector written by EncodeVector
+func DecodeVector(data []byte) ([]float32, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("vector data has %d bytes, not a multiple of 4", len(data))
+	}
+	v := make([]float32, len(data)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return v, nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeVector_RoundTrip(t *testing.T) {
+	v := []float32{0, 1, -1, 0.5, float32(math.Pi), -1e-7, math.MaxFloat32}
+
+	data := EncodeVector(v)
+	assert.Len(t, data, 4*len(v))
+
+	decoded, err := DecodeVector(data)
+	require.NoError(t, err)
+	assert.Equal(t, v, decoded)
+
+	_, err = DecodeVector(data[:5])
+	assert.Error(t, err)
+}
+
+func TestNewTrackEmbeddingItem(t *testing.T) {
+	item := NewTrackEmbeddingItem(TrackEmbedding{
+		UserID:  "user-123",
+		TrackID: "track-456",
+		Model:   "text-embedding-3-small",
+		Vector:  []float32{0.25, -0.5, 1},
+	})
+
+	assert.Equal(t, "USER#user-123", item.PK)
+	assert.Equal(t, "EMBEDDING#text-embedding-3-small#track-456", item.SK)
+	assert.Equal(t, string(EntityTrackEmbedding), item.Type)
+	assert.Equal(t, 3, item.Dims)
+
+	embedding, err := item.Embedding()
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.25, -0.5, 1}, embedding.Vector)
+
+	item.Dims = 4
+	_, err = item.Embedding()
+	assert.Error(t, err, "truncated vectors are rejected")
+}
+
+func TestGetTrackEmbeddingSKPrefix(t *testing.T) {
+	assert.Equal(t, "EMBEDDING#", GetTrackEmbeddingSKPrefix(""))
+	assert.Equal(t, "EMBEDDING#m1#", GetTrackEmbeddingSKPrefix("m1"))
+	assert.Len(t, EmbeddingTextHash("a"), 32)
+	assert.NotEqual(t, EmbeddingTextHash("a"), EmbeddingTextHash("b"))
+}
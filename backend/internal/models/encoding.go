@@ -0,0 +1,63 @@
+package models
+
+// Tag fields copied to the track, and checked for character-encoding damage
+const (
+	TagFieldTitle  = "title"
+	TagFieldArtist = "artist"
+	TagFieldAlbum  = "album"
+	TagFieldGenre  = "genre"
+)
+
+// EncodingFix is a suggested repair of a tag value that was decoded with the
+// wrong character set
+type EncodingFix struct {
+	Field     string `json:"field" dynamodbav:"field"`
+	Original  string `json:"original" dynamodbav:"original"`
+	Suggested string `json:"suggested" dynamodbav:"suggested"`
+	// Encoding is the character set the value was actually written in
+	Encoding string `json:"encoding" dynamodbav:"encoding"`
+}
+
+// ApplyEncodingFixesRequest selects the suggested fixes of an upload to apply
+type ApplyEncodingFixesRequest struct {
+	// Fields limits the fixes applied; empty applies all of them
+	Fields []string `json:"fields,omitempty" validate:"omitempty,dive,oneof=title artist album genre"`
+}
+
+// EncodingFixResult reports the outcome of applying encoding fixes to a track
+type EncodingFixResult struct {
+	TrackID string        `json:"trackId"`
+	Applied []EncodingFix `json:"applied"`
+	// Skipped fixes were not applied because the field was edited since upload
+	Skipped []EncodingFix `json:"skipped,omitempty"`
+	// Remaining fixes were not selected and can still be applied
+	Remaining []EncodingFix `json:"remaining,omitempty"`
+}
+
+// tagField returns a pointer to the named text tag of the track, or nil
+func (t *Track) tagField(field string) *string {
+	switch field {
+	case TagFieldTitle:
+		return &t.Title
+	case TagFieldArtist:
+		return &t.Artist
+	case TagFieldAlbum:
+		return &t.Album
+	case TagFieldGenre:
+		return &t.Genre
+	default:
+		return nil
+	}
+}
+
+// ApplyEncodingFix replaces the fixed field with the suggested value. It
+// returns false, leaving the track unchanged, when the field no longer holds
+// the original value.
+func (t *Track) ApplyEncodingFix(fix EncodingFix) bool {
+	value := t.tagField(fix.Field)
+	if value == nil || *value != fix.Original {
+		return false
+	}
+	*value = fix.Suggested
+	return true
+}
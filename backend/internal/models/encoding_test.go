@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack_ApplyEncodingFix(t *testing.T) {
+	t.Run("replaces the original value", func(t *testing.T) {
+		track := Track{Title: "CafÃ©", Album: "CafÃ©"}
+
+		applied := track.ApplyEncodingFix(EncodingFix{Field: TagFieldTitle, Original: "CafÃ©", Suggested: "Café"})
+
+		assert.True(t, applied)
+		assert.Equal(t, "Café", track.Title)
+		assert.Equal(t, "CafÃ©", track.Album, "other fields are untouched")
+	})
+
+	t.Run("skips fields edited since upload", func(t *testing.T) {
+		track := Track{Artist: "Beyoncé"}
+
+		applied := track.ApplyEncodingFix(EncodingFix{Field: TagFieldArtist, Original: "BeyoncÃ©", Suggested: "Beyoncé"})
+
+		assert.False(t, applied)
+		assert.Equal(t, "Beyoncé", track.Artist)
+	})
+
+	t.Run("ignores unknown fields", func(t *testing.T) {
+		track := Track{}
+
+		assert.False(t, track.ApplyEncodingFix(EncodingFix{Field: "comment"}))
+	})
+}
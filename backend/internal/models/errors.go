@@ -3,6 +3,10 @@ package models
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/i18n"
 )
 
 // APIError represents a structured API error response
@@ -11,66 +15,94 @@ type APIError struct {
 	Message    string `json:"message"`
 	Details    any    `json:"details,omitempty"`
 	StatusCode int    `json:"-"`
+	// MessageKey and MessageArgs render Message in the caller's language
+	// (see Localize); empty for messages that are not translated
+	MessageKey  string            `json:"-"`
+	MessageArgs map[string]string `json:"-"`
 }
 
 func (e *APIError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// Localize returns the error with its message in lang, or the error itself
+// if the message is not translated. Details are left as they are.
+func (e *APIError) Localize(lang string) *APIError {
+	if e.MessageKey == "" || lang == i18n.English {
+		return e
+	}
+	message, ok := i18n.Text(lang, e.MessageKey, e.MessageArgs)
+	if !ok {
+		return e
+	}
+	localized := *e
+	localized.Message = message
+	return &localized
+}
+
 // Common API errors
 var (
 	ErrNotFound = &APIError{
 		Code:       "NOT_FOUND",
 		Message:    "The requested resource was not found",
 		StatusCode: http.StatusNotFound,
+		MessageKey: "NOT_FOUND",
 	}
 
 	ErrUnauthorized = &APIError{
 		Code:       "UNAUTHORIZED",
 		Message:    "Authentication is required",
 		StatusCode: http.StatusUnauthorized,
+		MessageKey: "UNAUTHORIZED",
 	}
 
 	ErrForbidden = &APIError{
 		Code:       "FORBIDDEN",
 		Message:    "You do not have permission to access this resource",
 		StatusCode: http.StatusForbidden,
+		MessageKey: "FORBIDDEN",
 	}
 
 	ErrBadRequest = &APIError{
 		Code:       "BAD_REQUEST",
 		Message:    "The request was invalid",
 		StatusCode: http.StatusBadRequest,
+		MessageKey: "BAD_REQUEST",
 	}
 
 	ErrInternalServer = &APIError{
 		Code:       "INTERNAL_ERROR",
 		Message:    "An internal server error occurred",
 		StatusCode: http.StatusInternalServerError,
+		MessageKey: "INTERNAL_ERROR",
 	}
 
 	ErrConflict = &APIError{
 		Code:       "CONFLICT",
 		Message:    "The request conflicts with existing data",
 		StatusCode: http.StatusConflict,
+		MessageKey: "CONFLICT",
 	}
 
 	ErrPayloadTooLarge = &APIError{
 		Code:       "PAYLOAD_TOO_LARGE",
 		Message:    "The file size exceeds the maximum allowed",
 		StatusCode: http.StatusRequestEntityTooLarge,
+		MessageKey: "PAYLOAD_TOO_LARGE",
 	}
 
 	ErrUnsupportedMediaType = &APIError{
 		Code:       "UNSUPPORTED_MEDIA_TYPE",
 		Message:    "The file format is not supported",
 		StatusCode: http.StatusUnsupportedMediaType,
+		MessageKey: "UNSUPPORTED_MEDIA_TYPE",
 	}
 
 	ErrStorageLimitExceeded = &APIError{
 		Code:       "STORAGE_LIMIT_EXCEEDED",
 		Message:    "Your storage limit has been exceeded",
 		StatusCode: http.StatusPaymentRequired,
+		MessageKey: "STORAGE_LIMIT_EXCEEDED",
 	}
 
 	// Upload-specific errors
@@ -78,30 +110,56 @@ var (
 		Code:       "UPLOAD_EXPIRED",
 		Message:    "The upload session has expired",
 		StatusCode: http.StatusGone,
+		MessageKey: "UPLOAD_EXPIRED",
 	}
 
 	ErrUploadNotFound = &APIError{
 		Code:       "UPLOAD_NOT_FOUND",
 		Message:    "The upload session was not found",
 		StatusCode: http.StatusNotFound,
+		MessageKey: "UPLOAD_NOT_FOUND",
 	}
 
 	ErrMultipartUploadFailed = &APIError{
 		Code:       "MULTIPART_UPLOAD_FAILED",
 		Message:    "One or more parts of the multipart upload failed",
 		StatusCode: http.StatusBadRequest,
+		MessageKey: "MULTIPART_UPLOAD_FAILED",
 	}
 
 	ErrUploadProcessingFailed = &APIError{
 		Code:       "UPLOAD_PROCESSING_FAILED",
 		Message:    "Upload processing failed",
 		StatusCode: http.StatusInternalServerError,
+		MessageKey: "UPLOAD_PROCESSING_FAILED",
+	}
+
+	ErrTrackLocked = &APIError{
+		Code:       "TRACK_LOCKED",
+		Message:    "The track is locked and must be unlocked before it can be modified",
+		StatusCode: http.StatusLocked,
+		MessageKey: "TRACK_LOCKED",
+	}
+
+	ErrNotInHousehold = &APIError{
+		Code:       "NOT_IN_HOUSEHOLD",
+		Message:    "You are not a member of a household",
+		StatusCode: http.StatusNotFound,
+		MessageKey: "NOT_IN_HOUSEHOLD",
 	}
 
 	ErrInvalidCursor = &APIError{
 		Code:       "INVALID_CURSOR",
 		Message:    "The pagination cursor is invalid or expired",
 		StatusCode: http.StatusBadRequest,
+		MessageKey: "INVALID_CURSOR",
+	}
+
+	ErrUndoExpired = &APIError{
+		Code:       "UNDO_EXPIRED",
+		Message:    "The undo window for this operation has closed",
+		StatusCode: http.StatusGone,
+		MessageKey: "UNDO_EXPIRED",
 	}
 )
 
@@ -121,15 +179,18 @@ func NewValidationError(details any) *APIError {
 		Message:    "The request failed validation",
 		Details:    details,
 		StatusCode: http.StatusBadRequest,
+		MessageKey: "VALIDATION_ERROR",
 	}
 }
 
 // NewNotFoundError creates a not found error for a specific resource
 func NewNotFoundError(resource, id string) *APIError {
 	return &APIError{
-		Code:       "NOT_FOUND",
-		Message:    fmt.Sprintf("%s with ID '%s' was not found", resource, id),
-		StatusCode: http.StatusNotFound,
+		Code:        "NOT_FOUND",
+		Message:     fmt.Sprintf("%s with ID '%s' was not found", resource, id),
+		StatusCode:  http.StatusNotFound,
+		MessageKey:  "NOT_FOUND.resource",
+		MessageArgs: map[string]string{"resource": resource, "id": id},
 	}
 }
 
@@ -151,6 +212,45 @@ func NewForbiddenError(message string) *APIError {
 	}
 }
 
+// NewServiceUnavailableError creates a 503 error for a subsystem that is not configured
+func NewServiceUnavailableError(capability, reason string) *APIError {
+	return &APIError{
+		Code:        "SERVICE_UNAVAILABLE",
+		Message:     fmt.Sprintf("%s is not available", capability),
+		Details:     map[string]string{"capability": capability, "reason": reason},
+		StatusCode:  http.StatusServiceUnavailable,
+		MessageKey:  "SERVICE_UNAVAILABLE",
+		MessageArgs: map[string]string{"capability": capability},
+	}
+}
+
+// NewTooBusyError creates a 503 error for an operation turned away because
+// too many operations of its kind are already running
+func NewTooBusyError(operation string) *APIError {
+	return &APIError{
+		Code:        "TOO_BUSY",
+		Message:     fmt.Sprintf("Too many %s operations are running, try again shortly", operation),
+		Details:     map[string]string{"operation": operation},
+		StatusCode:  http.StatusServiceUnavailable,
+		MessageKey:  "TOO_BUSY",
+		MessageArgs: map[string]string{"operation": operation},
+	}
+}
+
+// NewRateLimitedError creates a 429 error for a caller who made too many
+// requests and may try again after retryAfter (rounded up to whole seconds)
+func NewRateLimitedError(retryAfter time.Duration) *APIError {
+	seconds := strconv.Itoa(max(1, int((retryAfter+time.Second-1)/time.Second)))
+	return &APIError{
+		Code:        "RATE_LIMITED",
+		Message:     fmt.Sprintf("Too many requests, try again in %s seconds", seconds),
+		Details:     map[string]string{"retryAfterSeconds": seconds},
+		StatusCode:  http.StatusTooManyRequests,
+		MessageKey:  "RATE_LIMITED",
+		MessageArgs: map[string]string{"seconds": seconds},
+	}
+}
+
 // ErrorResponse represents the standard error response format
 type ErrorResponse struct {
 	Error *APIError `json:"error"`
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gvasels/personal-music-searchengine/internal/i18n"
+)
+
+func TestAPIErrorLocalize(t *testing.T) {
+	t.Run("predefined errors", func(t *testing.T) {
+		localized := ErrTrackLocked.Localize("fr")
+		assert.Equal(t, "Le morceau est verrouillé et doit être déverrouillé avant d'être modifié", localized.Message)
+		assert.Equal(t, ErrTrackLocked.Code, localized.Code)
+		assert.Equal(t, ErrTrackLocked.StatusCode, localized.StatusCode)
+		assert.Equal(t, "The track is locked and must be unlocked before it can be modified", ErrTrackLocked.Message, "shared error modified")
+	})
+
+	t.Run("messages with arguments", func(t *testing.T) {
+		assert.Equal(t, "No se encontró Track con el ID 't1'", NewNotFoundError("Track", "t1").Localize("es").Message)
+		assert.Equal(t, "Zu viele import-Vorgänge laufen gerade, bitte versuchen Sie es gleich noch einmal",
+			NewTooBusyError("import").Localize("de").Message)
+	})
+
+	t.Run("details are kept", func(t *testing.T) {
+		err := NewValidationError("Name is required")
+		localized := err.Localize("de")
+		assert.Equal(t, "Die Anfrage ist fehlerhaft", localized.Message)
+		assert.Equal(t, "Name is required", localized.Details)
+	})
+
+	t.Run("custom messages stay as written", func(t *testing.T) {
+		err := NewConflictError("playlist already has this track")
+		assert.Same(t, err, err.Localize("de"))
+		assert.Same(t, ErrNotFound, ErrNotFound.Localize("en"))
+	})
+
+	t.Run("English catalog matches the messages", func(t *testing.T) {
+		for _, err := range []*APIError{
+			ErrNotFound, ErrUnauthorized, ErrForbidden, ErrBadRequest, ErrInternalServer, ErrConflict,
+			ErrPayloadTooLarge, ErrUnsupportedMediaType, ErrStorageLimitExceeded, ErrUploadExpired,
+			ErrUploadNotFound, ErrMultipartUploadFailed, ErrUploadProcessingFailed, ErrTrackLocked,
+			ErrNotInHousehold, ErrInvalidCursor, ErrUndoExpired, NewValidationError(nil),
+			NewNotFoundError("Track", "t1"), NewServiceUnavailableError("Search", "down"), NewTooBusyError("import"),
+		} {
+			text, ok := i18n.Text(i18n.English, err.MessageKey, err.MessageArgs)
+			assert.True(t, ok, err.Code)
+			assert.Equal(t, err.Message, text, err.Code)
+		}
+	})
+}
+
+func TestNewRateLimitedError(t *testing.T) {
+	err := NewRateLimitedError(90*time.Second + time.Millisecond)
+	assert.Equal(t, http.StatusTooManyRequests, err.StatusCode)
+	assert.Equal(t, "91", err.MessageArgs["seconds"])
+
+	assert.Equal(t, "Zu viele Anfragen, bitte versuchen Sie es in 91 Sekunden erneut", err.Localize("de").Message)
+	assert.Equal(t, "1", NewRateLimitedError(0).MessageArgs["seconds"])
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntityFeaturedItem represents the entity type for curated discover content
+const EntityFeaturedItem EntityType = "FEATURED_ITEM"
+
+// Featured content limits
+const (
+	// MaxFeaturedItems bounds the curated items, scheduled and expired included
+	MaxFeaturedItems = 100
+	// DiscoverListSize is how many playlists, artists and new tracks the
+	// discover feed shows; curated entries are shown even beyond it
+	DiscoverListSize = 10
+)
+
+// FeaturedKind says what a featured item puts on the discover feed
+type FeaturedKind string
+
+const (
+	FeaturedKindPlaylist FeaturedKind = "playlist" // a public playlist
+	FeaturedKindArtist   FeaturedKind = "artist"   // an artist profile
+	FeaturedKindSection  FeaturedKind = "section"  // an editorial text section
+)
+
+// FeaturedStatus says where an item is in its schedule
+type FeaturedStatus string
+
+const (
+	FeaturedScheduled FeaturedStatus = "scheduled" // FeaturedFrom is still ahead
+	FeaturedActive    FeaturedStatus = "active"
+	FeaturedExpired   FeaturedStatus = "expired" // FeaturedUntil has passed
+)
+
+// FeaturedItem is an admin's pick for the discover feed. Items without
+// FeaturedFrom are shown from their creation and items without
+// FeaturedUntil until they are deleted.
+type FeaturedItem struct {
+	ID   string       `json:"id" dynamodbav:"id"`
+	Kind FeaturedKind `json:"kind" dynamodbav:"kind"`
+	// OwnerID is the owner of a featured playlist or the user of a featured
+	// artist profile
+	OwnerID    string `json:"ownerId,omitempty" dynamodbav:"ownerId,omitempty"`
+	PlaylistID string `json:"playlistId,omitempty" dynamodbav:"playlistId,omitempty"`
+	// Title and Body head an editorial section, or introduce a playlist or artist
+	Title   string `json:"title,omitempty" dynamodbav:"title,omitempty"`
+	Body    string `json:"body,omitempty" dynamodbav:"body,omitempty"`
+	LinkURL string `json:"linkUrl,omitempty" dynamodbav:"linkUrl,omitempty"`
+	// Position orders the curated items; lower first
+	Position      int        `json:"position" dynamodbav:"position"`
+	FeaturedFrom  *time.Time `json:"featuredFrom,omitempty" dynamodbav:"featuredFrom,omitempty"`
+	FeaturedUntil *time.Time `json:"featuredUntil,omitempty" dynamodbav:"featuredUntil,omitempty"`
+	CreatedBy     string     `json:"createdBy" dynamodbav:"createdBy"`
+	Timestamps
+}
+
+// Status returns where the item is in its schedule at now
+func (f *FeaturedItem) Status(now time.Time) FeaturedStatus {
+	switch {
+	case f.FeaturedFrom != nil && now.Before(*f.FeaturedFrom):
+		return FeaturedScheduled
+	case f.FeaturedUntil != nil && !now.Before(*f.FeaturedUntil):
+		return FeaturedExpired
+	default:
+		return FeaturedActive
+	}
+}
+
+// Blurb returns the editorial text of the item
+func (f *FeaturedItem) Blurb() FeaturedBlurb {
+	return FeaturedBlurb{ID: f.ID, Title: f.Title, Body: f.Body, LinkURL: f.LinkURL}
+}
+
+// ToResponse converts a FeaturedItem to the admin's FeaturedItemResponse
+func (f *FeaturedItem) ToResponse(now time.Time) FeaturedItemResponse {
+	return FeaturedItemResponse{FeaturedItem: *f, Status: f.Status(now)}
+}
+
+// FeaturedItemItem represents a FeaturedItem in DynamoDB single-table design
+type FeaturedItemItem struct {
+	DynamoDBItem
+	FeaturedItem
+}
+
+// FeaturedPK is the partition holding every featured item
+const FeaturedPK = "FEATURED"
+
+// GetFeaturedItemSK returns the sort key for a featured item
+func GetFeaturedItemSK(itemID string) string {
+	return fmt.Sprintf("ITEM#%s", itemID)
+}
+
+// NewFeaturedItemItem creates a DynamoDB item for a featured item.
+// Primary key pattern: PK=FEATURED, SK=ITEM#{itemID}
+func NewFeaturedItemItem(item FeaturedItem) FeaturedItemItem {
+	return FeaturedItemItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   FeaturedPK,
+			SK:   GetFeaturedItemSK(item.ID),
+			Type: string(EntityFeaturedItem),
+		},
+		FeaturedItem: item,
+	}
+}
+
+// FeaturedSchedule is the window in which an item is shown
+type FeaturedSchedule struct {
+	FeaturedFrom  *time.Time `json:"featuredFrom"`
+	FeaturedUntil *time.Time `json:"featuredUntil"`
+}
+
+// Validate checks that the window is not empty
+func (s *FeaturedSchedule) Validate() error {
+	if s.FeaturedFrom != nil && s.FeaturedUntil != nil && !s.FeaturedFrom.Before(*s.FeaturedUntil) {
+		return NewValidationError("featuredFrom must be before featuredUntil")
+	}
+	return nil
+}
+
+// CreateFeaturedItemRequest represents a request to feature content
+type CreateFeaturedItemRequest struct {
+	Kind       FeaturedKind `json:"kind" validate:"required,oneof=playlist artist section"`
+	OwnerID    string       `json:"ownerId" validate:"max=128"`
+	PlaylistID string       `json:"playlistId" validate:"max=128"`
+	Title      string       `json:"title" validate:"max=100"`
+	Body       string       `json:"body" validate:"max=1000"`
+	LinkURL    string       `json:"linkUrl" validate:"omitempty,url,max=2048"`
+	Position   int          `json:"position" validate:"min=0,max=1000"`
+	FeaturedSchedule
+}
+
+// Validate checks that the item names what its kind features
+func (r *CreateFeaturedItemRequest) Validate() error {
+	switch {
+	case r.Kind == FeaturedKindPlaylist && (r.OwnerID == "" || r.PlaylistID == ""):
+		return NewValidationError("a featured playlist needs ownerId and playlistId")
+	case r.Kind == FeaturedKindArtist && r.OwnerID == "":
+		return NewValidationError("a featured artist needs ownerId")
+	case r.Kind == FeaturedKindSection && r.Title == "":
+		return NewValidationError("an editorial section needs a title")
+	}
+	return r.FeaturedSchedule.Validate()
+}
+
+// UpdateFeaturedItemRequest replaces the text, position and schedule of a
+// featured item; what it features cannot change
+type UpdateFeaturedItemRequest struct {
+	Title    string `json:"title" validate:"max=100"`
+	Body     string `json:"body" validate:"max=1000"`
+	LinkURL  string `json:"linkUrl" validate:"omitempty,url,max=2048"`
+	Position int    `json:"position" validate:"min=0,max=1000"`
+	FeaturedSchedule
+}
+
+// FeaturedItemResponse is a featured item as admins see it
+type FeaturedItemResponse struct {
+	FeaturedItem
+	Status FeaturedStatus `json:"status"`
+}
+
+// FeaturedBlurb is the editorial text of a featured item
+type FeaturedBlurb struct {
+	ID      string `json:"id"`
+	Title   string `json:"title,omitempty"`
+	Body    string `json:"body,omitempty"`
+	LinkURL string `json:"linkUrl,omitempty"`
+}
+
+// DiscoverPlaylist is a playlist on the discover feed; Featured is set when
+// an admin picked it
+type DiscoverPlaylist struct {
+	PlaylistResponse
+	Featured *FeaturedBlurb `json:"featured,omitempty"`
+}
+
+// DiscoverArtist is an artist profile on the discover feed; Featured is set
+// when an admin picked it
+type DiscoverArtist struct {
+	ArtistProfileResponse
+	Featured *FeaturedBlurb `json:"featured,omitempty"`
+}
+
+// DiscoverResponse is the discover feed: the editorial sections in effect,
+// then playlists and artists with the curated ones first, and the newest
+// public tracks
+type DiscoverResponse struct {
+	Sections  []FeaturedBlurb    `json:"sections"`
+	Playlists []DiscoverPlaylist `json:"playlists"`
+	Artists   []DiscoverArtist   `json:"artists"`
+	NewTracks []TrackResponse    `json:"newTracks"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeaturedItem_Status(t *testing.T) {
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	item := FeaturedItem{FeaturedFrom: &from, FeaturedUntil: &until}
+
+	assert.Equal(t, FeaturedScheduled, item.Status(from.Add(-time.Second)))
+	assert.Equal(t, FeaturedActive, item.Status(from))
+	assert.Equal(t, FeaturedActive, item.Status(until.Add(-time.Second)))
+	assert.Equal(t, FeaturedExpired, item.Status(until), "featuredUntil is exclusive")
+
+	unscheduled := FeaturedItem{}
+	assert.Equal(t, FeaturedActive, unscheduled.Status(until))
+}
+
+func TestCreateFeaturedItemRequest_Validate(t *testing.T) {
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(24 * time.Hour)
+
+	assert.Error(t, (&CreateFeaturedItemRequest{Kind: FeaturedKindPlaylist, OwnerID: "u1"}).Validate())
+	assert.NoError(t, (&CreateFeaturedItemRequest{Kind: FeaturedKindPlaylist, OwnerID: "u1", PlaylistID: "p1"}).Validate())
+	assert.Error(t, (&CreateFeaturedItemRequest{Kind: FeaturedKindArtist}).Validate())
+	assert.Error(t, (&CreateFeaturedItemRequest{Kind: FeaturedKindSection}).Validate())
+	assert.NoError(t, (&CreateFeaturedItemRequest{Kind: FeaturedKindSection, Title: "Staff picks"}).Validate())
+
+	scheduled := CreateFeaturedItemRequest{Kind: FeaturedKindArtist, OwnerID: "u1"}
+	scheduled.FeaturedFrom, scheduled.FeaturedUntil = &until, &from
+	assert.Error(t, scheduled.Validate(), "the window must not be reversed")
+	scheduled.FeaturedFrom, scheduled.FeaturedUntil = &from, &from
+	assert.Error(t, scheduled.Validate(), "the window must not be empty")
+	scheduled.FeaturedUntil = &until
+	assert.NoError(t, scheduled.Validate())
+}
+
+func TestNewFeaturedItemItem(t *testing.T) {
+	item := NewFeaturedItemItem(FeaturedItem{ID: "f1", Kind: FeaturedKindSection})
+	assert.Equal(t, "FEATURED", item.PK)
+	assert.Equal(t, "ITEM#f1", item.SK)
+	assert.Equal(t, string(EntityFeaturedItem), item.Type)
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntityHousehold and EntityHouseholdMember represent the entity types for family/household accounts
+const (
+	EntityHousehold       EntityType = "HOUSEHOLD"
+	EntityHouseholdMember EntityType = "HOUSEHOLD_MEMBER"
+)
+
+// MaxHouseholdMembers is the maximum number of users attached to one household
+const MaxHouseholdMembers = 6
+
+// HouseholdRole represents a member's role within a household
+type HouseholdRole string
+
+const (
+	HouseholdRoleOwner   HouseholdRole = "owner"   // Owns the shared library and quota; can delete the household
+	HouseholdRoleManager HouseholdRole = "manager" // Can add and remove members
+	HouseholdRoleMember  HouseholdRole = "member"  // Uses the shared library
+)
+
+// IsValid returns true if the role is a known household role.
+func (r HouseholdRole) IsValid() bool {
+	switch r {
+	case HouseholdRoleOwner, HouseholdRoleManager, HouseholdRoleMember:
+		return true
+	}
+	return false
+}
+
+// CanManageMembers returns true if the role may add or remove members.
+func (r HouseholdRole) CanManageMembers() bool {
+	return r == HouseholdRoleOwner || r == HouseholdRoleManager
+}
+
+// Household groups several users around one shared library and storage quota.
+// The shared library is the owner's library partition; members keep their own
+// private playlists.
+type Household struct {
+	ID          string `json:"id" dynamodbav:"id"`
+	Name        string `json:"name" dynamodbav:"name"`
+	OwnerID     string `json:"ownerId" dynamodbav:"ownerId"`
+	MemberCount int    `json:"memberCount" dynamodbav:"memberCount"`
+	Timestamps
+}
+
+// LibraryID returns the ID of the library partition shared by all members.
+func (h *Household) LibraryID() string {
+	return h.OwnerID
+}
+
+// HouseholdItem represents a Household in DynamoDB single-table design
+type HouseholdItem struct {
+	DynamoDBItem
+	Household
+}
+
+// NewHouseholdItem creates a DynamoDB item for a household.
+// Primary key pattern: PK=HOUSEHOLD#{householdID}, SK=METADATA
+func NewHouseholdItem(household Household) HouseholdItem {
+	return HouseholdItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   GetHouseholdPK(household.ID),
+			SK:   "METADATA",
+			Type: string(EntityHousehold),
+		},
+		Household: household,
+	}
+}
+
+// HouseholdMember represents a user's membership in a household
+type HouseholdMember struct {
+	HouseholdID string        `json:"householdId" dynamodbav:"householdId"`
+	UserID      string        `json:"userId" dynamodbav:"userId"`
+	Email       string        `json:"email" dynamodbav:"email"`
+	DisplayName string        `json:"displayName,omitempty" dynamodbav:"displayName,omitempty"`
+	Role        HouseholdRole `json:"role" dynamodbav:"role"`
+	JoinedAt    time.Time     `json:"joinedAt" dynamodbav:"joinedAt"`
+}
+
+// HouseholdMemberItem represents a HouseholdMember in DynamoDB single-table design
+type HouseholdMemberItem struct {
+	DynamoDBItem
+	HouseholdMember
+}
+
+// NewHouseholdMemberItem creates a DynamoDB item for a household member.
+// Primary key pattern: PK=HOUSEHOLD#{householdID}, SK=MEMBER#{userID}
+func NewHouseholdMemberItem(member HouseholdMember) HouseholdMemberItem {
+	return HouseholdMemberItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   GetHouseholdPK(member.HouseholdID),
+			SK:   GetHouseholdMemberSK(member.UserID),
+			Type: string(EntityHouseholdMember),
+		},
+		HouseholdMember: member,
+	}
+}
+
+// GetHouseholdPK returns the partition key for a household and its members.
+func GetHouseholdPK(householdID string) string {
+	return fmt.Sprintf("HOUSEHOLD#%s", householdID)
+}
+
+// GetHouseholdMemberSK returns the sort key for a household member.
+func GetHouseholdMemberSK(userID string) string {
+	return fmt.Sprintf("MEMBER#%s", userID)
+}
+
+// CreateHouseholdRequest represents a request to create a household
+type CreateHouseholdRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// UpdateHouseholdRequest represents a request to rename a household
+type UpdateHouseholdRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// AddHouseholdMemberRequest represents a request to attach a user to a household
+type AddHouseholdMemberRequest struct {
+	Email string        `json:"email" validate:"required,email"`
+	Role  HouseholdRole `json:"role,omitempty" validate:"omitempty,oneof=manager member"`
+}
+
+// UpdateHouseholdMemberRoleRequest represents a request to change a member's role
+type UpdateHouseholdMemberRoleRequest struct {
+	Role HouseholdRole `json:"role" validate:"required,oneof=manager member"`
+}
+
+// HouseholdResponse represents a household with its members in API responses
+type HouseholdResponse struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	OwnerID     string            `json:"ownerId"`
+	LibraryID   string            `json:"libraryId"`
+	MemberCount int               `json:"memberCount"`
+	Members     []HouseholdMember `json:"members"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+// ToResponse converts a Household and its members to a HouseholdResponse
+func (h *Household) ToResponse(members []HouseholdMember) HouseholdResponse {
+	if members == nil {
+		members = []HouseholdMember{}
+	}
+	return HouseholdResponse{
+		ID:          h.ID,
+		Name:        h.Name,
+		OwnerID:     h.OwnerID,
+		LibraryID:   h.LibraryID(),
+		MemberCount: h.MemberCount,
+		Members:     members,
+		CreatedAt:   h.CreatedAt,
+		UpdatedAt:   h.UpdatedAt,
+	}
+}
+
+// DeleteHouseholdResult reports the household a delete dissolves
+type DeleteHouseholdResult struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// MemberIDs are the users who return to their own libraries
+	MemberIDs []string `json:"memberIds"`
+	// DryRun is set when nothing was deleted; the result is what a real run does
+	DryRun bool `json:"dryRun,omitempty"`
+}
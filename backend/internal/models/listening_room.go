@@ -0,0 +1,302 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// EntityListeningRoom represents the entity type for a shared listening room
+	EntityListeningRoom EntityType = "LISTENING_ROOM"
+	// EntityRoomMember represents the entity type for a listener who joined a room
+	EntityRoomMember EntityType = "ROOM_MEMBER"
+)
+
+const (
+	// DefaultRoomDuration is how long a room stays open when the host does not say
+	DefaultRoomDuration = 4 * time.Hour
+	// MaxRoomDuration bounds how long a room stays open
+	MaxRoomDuration = 12 * time.Hour
+	// MaxRoomMembers bounds the listeners who can join a room, besides its host
+	MaxRoomMembers = 20
+	// roomRestartThreshold is how far into a track "previous" restarts it
+	// rather than going back a track
+	roomRestartThreshold = 3 * time.Second
+)
+
+// roomTokenPrefix scopes an invite token to a room, so it is never read as a
+// party or preview link
+const roomTokenPrefix = "room:"
+
+// ListeningRoom plays the host's queue to everyone who joined, in sync. The
+// room is the authority on what plays: the playing track and its position at
+// UpdatedAt, from which every member works out where playback is now. Only
+// the host changes it; Version counts the changes.
+type ListeningRoom struct {
+	ID     string `json:"id" dynamodbav:"id"`
+	HostID string `json:"hostId" dynamodbav:"hostId"`
+	Name   string `json:"name" dynamodbav:"name"`
+	// TrackIDs is the host's play queue when the room was opened
+	TrackIDs     []string `json:"trackIds" dynamodbav:"trackIds"`
+	CurrentIndex int      `json:"currentIndex" dynamodbav:"currentIndex"`
+	// PositionMs is how far into the current track playback was at UpdatedAt
+	PositionMs int64     `json:"positionMs" dynamodbav:"positionMs"`
+	Playing    bool      `json:"playing" dynamodbav:"playing"`
+	Version    int64     `json:"version" dynamodbav:"version"`
+	UpdatedAt  time.Time `json:"updatedAt" dynamodbav:"updatedAt"`
+	CreatedAt  time.Time `json:"createdAt" dynamodbav:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt" dynamodbav:"expiresAt"`
+}
+
+// Active reports whether the room is still open at now
+func (r *ListeningRoom) Active(now time.Time) bool {
+	return now.Before(r.ExpiresAt)
+}
+
+// CurrentTrackID returns the playing track, or "" for an empty queue
+func (r *ListeningRoom) CurrentTrackID() string {
+	if r.CurrentIndex < 0 || r.CurrentIndex >= len(r.TrackIDs) {
+		return ""
+	}
+	return r.TrackIDs[r.CurrentIndex]
+}
+
+// Position returns how far into the current track playback is at now
+func (r *ListeningRoom) Position(now time.Time) int64 {
+	if !r.Playing || now.Before(r.UpdatedAt) {
+		return r.PositionMs
+	}
+	return r.PositionMs + now.Sub(r.UpdatedAt).Milliseconds()
+}
+
+// RoomPlaybackAction is a host's control of a room's playback
+type RoomPlaybackAction string
+
+const (
+	RoomPlay     RoomPlaybackAction = "play"
+	RoomPause    RoomPlaybackAction = "pause"
+	RoomSeek     RoomPlaybackAction = "seek"
+	RoomNext     RoomPlaybackAction = "next"
+	RoomPrevious RoomPlaybackAction = "previous"
+	RoomSkipTo   RoomPlaybackAction = "skip_to"
+)
+
+// RoomPlaybackRequest represents the host changing a room's playback. Seek
+// takes PositionMs and skip_to takes Index. With Version set the change only
+// applies to that version of the room.
+type RoomPlaybackRequest struct {
+	Action     RoomPlaybackAction `json:"action" validate:"required,oneof=play pause seek next previous skip_to"`
+	PositionMs *int64             `json:"positionMs,omitempty" validate:"omitempty,min=0"`
+	Index      *int               `json:"index,omitempty" validate:"omitempty,min=0"`
+	Version    *int64             `json:"version,omitempty" validate:"omitempty,min=0"`
+}
+
+// Apply makes the change req describes at now, leaving the room unchanged and
+// returning a validation error if it cannot be made
+func (r *ListeningRoom) Apply(req RoomPlaybackRequest, now time.Time) error {
+	if len(r.TrackIDs) == 0 {
+		return NewValidationError("the room's queue is empty")
+	}
+
+	index, position, playing := r.CurrentIndex, r.Position(now), r.Playing
+	switch req.Action {
+	case RoomPlay:
+		playing = true
+	case RoomPause:
+		playing = false
+	case RoomSeek:
+		if req.PositionMs == nil {
+			return NewValidationError("positionMs is required to seek")
+		}
+		position = *req.PositionMs
+	case RoomNext:
+		if index+1 >= len(r.TrackIDs) {
+			return NewValidationError("the room is playing the last track of its queue")
+		}
+		index, position = index+1, 0
+	case RoomPrevious:
+		if position < roomRestartThreshold.Milliseconds() && index > 0 {
+			index--
+		}
+		position = 0
+	case RoomSkipTo:
+		if req.Index == nil {
+			return NewValidationError("index is required to skip to a track")
+		}
+		if *req.Index >= len(r.TrackIDs) {
+			return NewValidationError(fmt.Sprintf("index %d is outside the queue of %d tracks", *req.Index, len(r.TrackIDs)))
+		}
+		index, position = *req.Index, 0
+	default:
+		return NewValidationError(fmt.Sprintf("unknown playback action: %s", req.Action))
+	}
+
+	r.CurrentIndex, r.PositionMs, r.Playing, r.UpdatedAt = index, position, playing, now
+	return nil
+}
+
+// Advance moves a playing room past the tracks that have finished by now,
+// given each track's duration in seconds (0 when unknown, which never
+// finishes). Playback stops at the end of the last track. It reports whether
+// the room changed.
+func (r *ListeningRoom) Advance(now time.Time, duration func(trackID string) int) bool {
+	changed := false
+	for r.Playing && r.CurrentTrackID() != "" {
+		length := int64(duration(r.CurrentTrackID())) * 1000
+		position := r.Position(now)
+		if length <= 0 || position < length {
+			break
+		}
+		if r.CurrentIndex+1 >= len(r.TrackIDs) {
+			r.PositionMs, r.Playing = length, false
+		} else {
+			r.CurrentIndex++
+			r.PositionMs = position - length
+		}
+		r.UpdatedAt = now
+		changed = true
+	}
+	return changed
+}
+
+// RoomTokenSubject returns the subject a room's invite token carries
+func RoomTokenSubject(roomID string) string {
+	return roomTokenPrefix + roomID
+}
+
+// ParseRoomTokenSubject returns the room an invite token's subject names, and
+// false for subjects of other links
+func ParseRoomTokenSubject(subject string) (string, bool) {
+	roomID, ok := strings.CutPrefix(subject, roomTokenPrefix)
+	return roomID, ok && roomID != ""
+}
+
+// RoomMember is a listener who joined a room with its invite
+type RoomMember struct {
+	RoomID   string    `json:"roomId" dynamodbav:"roomId"`
+	UserID   string    `json:"userId" dynamodbav:"userId"`
+	JoinedAt time.Time `json:"joinedAt" dynamodbav:"joinedAt"`
+	// ExpiresAt is the room's expiry; members go with their room
+	ExpiresAt time.Time `json:"-" dynamodbav:"expiresAt"`
+}
+
+// CreateListeningRoomRequest represents a request to open a room on the
+// host's play queue
+type CreateListeningRoomRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// DurationMinutes defaults to DefaultRoomDuration
+	DurationMinutes int `json:"durationMinutes,omitempty" validate:"omitempty,min=15,max=720"`
+}
+
+// Duration returns how long the room should stay open
+func (r CreateListeningRoomRequest) Duration() time.Duration {
+	if r.DurationMinutes == 0 {
+		return DefaultRoomDuration
+	}
+	return min(time.Duration(r.DurationMinutes)*time.Minute, MaxRoomDuration)
+}
+
+// JoinListeningRoomRequest represents a listener joining a room with its invite
+type JoinListeningRoomRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ListeningRoomState is a room as its host and members see it. PositionMs is
+// where playback is at ServerTime; clients add the time since while Playing.
+type ListeningRoomState struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	HostID         string    `json:"hostId"`
+	IsHost         bool      `json:"isHost"`
+	TrackIDs       []string  `json:"trackIds"`
+	CurrentIndex   int       `json:"currentIndex"`
+	CurrentTrackID string    `json:"currentTrackId,omitempty"`
+	PositionMs     int64     `json:"positionMs"`
+	Playing        bool      `json:"playing"`
+	Version        int64     `json:"version"`
+	MemberCount    int       `json:"memberCount"`
+	ServerTime     time.Time `json:"serverTime"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+// NewListeningRoomState returns the view of a room for userID at now
+func NewListeningRoomState(room ListeningRoom, userID string, members int, now time.Time) ListeningRoomState {
+	return ListeningRoomState{
+		ID:             room.ID,
+		Name:           room.Name,
+		HostID:         room.HostID,
+		IsHost:         room.HostID == userID,
+		TrackIDs:       room.TrackIDs,
+		CurrentIndex:   room.CurrentIndex,
+		CurrentTrackID: room.CurrentTrackID(),
+		PositionMs:     room.Position(now),
+		Playing:        room.Playing,
+		Version:        room.Version,
+		MemberCount:    members,
+		ServerTime:     now,
+		ExpiresAt:      room.ExpiresAt,
+	}
+}
+
+// ListeningRoomInviteResponse is an opened room and the invite token
+// listeners join it with. The token works until the room closes.
+type ListeningRoomInviteResponse struct {
+	Room  ListeningRoomState `json:"room"`
+	Token string             `json:"token"`
+}
+
+// ListeningRoomItem represents a ListeningRoom in DynamoDB single-table design
+type ListeningRoomItem struct {
+	DynamoDBItem
+	ListeningRoom
+	TTL int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewListeningRoomItem creates a DynamoDB item for a listening room.
+// Primary key pattern: PK=ROOM#{roomID}, SK=METADATA
+func NewListeningRoomItem(room ListeningRoom) ListeningRoomItem {
+	return ListeningRoomItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   GetRoomPK(room.ID),
+			SK:   "METADATA",
+			Type: string(EntityListeningRoom),
+		},
+		ListeningRoom: room,
+		TTL:           room.ExpiresAt.Unix(),
+	}
+}
+
+// RoomMemberItem represents a RoomMember in DynamoDB single-table design
+type RoomMemberItem struct {
+	DynamoDBItem
+	RoomMember
+	TTL int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewRoomMemberItem creates a DynamoDB item for a room member.
+// Primary key pattern: PK=ROOM#{roomID}, SK=MEMBER#{userID}
+func NewRoomMemberItem(member RoomMember) RoomMemberItem {
+	return RoomMemberItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   GetRoomPK(member.RoomID),
+			SK:   GetRoomMemberSK(member.UserID),
+			Type: string(EntityRoomMember),
+		},
+		RoomMember: member,
+		TTL:        member.ExpiresAt.Unix(),
+	}
+}
+
+// GetRoomPK returns the partition key for a room and its members
+func GetRoomPK(roomID string) string {
+	return fmt.Sprintf("ROOM#%s", roomID)
+}
+
+// RoomMemberSKPrefix starts the sort key of every member of a room
+const RoomMemberSKPrefix = "MEMBER#"
+
+// GetRoomMemberSK returns the sort key for a room member
+func GetRoomMemberSK(userID string) string {
+	return RoomMemberSKPrefix + userID
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListeningRoom_Apply(t *testing.T) {
+	start := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	room := ListeningRoom{TrackIDs: []string{"a", "b", "c"}, UpdatedAt: start}
+	position := func(ms int64) *int64 { return &ms }
+	index := func(i int) *int { return &i }
+
+	require.NoError(t, room.Apply(RoomPlaybackRequest{Action: RoomPlay}, start))
+	assert.Equal(t, int64(5000), room.Position(start.Add(5*time.Second)))
+
+	require.NoError(t, room.Apply(RoomPlaybackRequest{Action: RoomPause}, start.Add(10*time.Second)))
+	assert.Equal(t, int64(10000), room.Position(start.Add(time.Minute)), "a paused room holds its position")
+
+	require.NoError(t, room.Apply(RoomPlaybackRequest{Action: RoomSeek, PositionMs: position(42000)}, start.Add(time.Minute)))
+	assert.Equal(t, int64(42000), room.PositionMs)
+
+	require.NoError(t, room.Apply(RoomPlaybackRequest{Action: RoomNext}, start.Add(time.Minute)))
+	assert.Equal(t, "b", room.CurrentTrackID())
+	assert.Zero(t, room.PositionMs)
+
+	require.NoError(t, room.Apply(RoomPlaybackRequest{Action: RoomPrevious}, start.Add(time.Minute)))
+	assert.Equal(t, "a", room.CurrentTrackID(), "previous near the start goes back a track")
+
+	require.NoError(t, room.Apply(RoomPlaybackRequest{Action: RoomSeek, PositionMs: position(30000)}, start.Add(time.Minute)))
+	require.NoError(t, room.Apply(RoomPlaybackRequest{Action: RoomPrevious}, start.Add(time.Minute)))
+	assert.Equal(t, "a", room.CurrentTrackID(), "previous later in a track restarts it")
+	assert.Zero(t, room.PositionMs)
+
+	require.NoError(t, room.Apply(RoomPlaybackRequest{Action: RoomSkipTo, Index: index(2)}, start.Add(time.Minute)))
+	assert.Equal(t, "c", room.CurrentTrackID())
+
+	before := room
+	assert.Error(t, room.Apply(RoomPlaybackRequest{Action: RoomNext}, start.Add(time.Minute)))
+	assert.Error(t, room.Apply(RoomPlaybackRequest{Action: RoomSkipTo, Index: index(3)}, start.Add(time.Minute)))
+	assert.Error(t, room.Apply(RoomPlaybackRequest{Action: RoomSeek}, start.Add(time.Minute)))
+	assert.Equal(t, before, room)
+}
+
+func TestListeningRoom_Advance(t *testing.T) {
+	start := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	durations := map[string]int{"a": 60, "b": 30}
+	duration := func(id string) int { return durations[id] }
+	room := ListeningRoom{TrackIDs: []string{"a", "b"}, Playing: true, UpdatedAt: start}
+
+	assert.False(t, room.Advance(start.Add(59*time.Second), duration))
+
+	now := start.Add(75 * time.Second)
+	assert.True(t, room.Advance(now, duration))
+	assert.Equal(t, "b", room.CurrentTrackID())
+	assert.Equal(t, int64(15000), room.Position(now))
+
+	now = start.Add(5 * time.Minute)
+	assert.True(t, room.Advance(now, duration))
+	assert.False(t, room.Playing, "playback stops at the end of the queue")
+	assert.Equal(t, int64(30000), room.Position(now))
+
+	unknown := ListeningRoom{TrackIDs: []string{"x"}, Playing: true, UpdatedAt: start}
+	assert.False(t, unknown.Advance(start.Add(time.Hour), duration), "tracks of unknown length play on")
+}
+
+func TestRoomTokenSubject(t *testing.T) {
+	id, ok := ParseRoomTokenSubject(RoomTokenSubject("r1"))
+	assert.True(t, ok)
+	assert.Equal(t, "r1", id)
+
+	_, ok = ParseRoomTokenSubject(PartyTokenSubject("r1"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntityMigration represents the entity type for data migration progress
+const EntityMigration EntityType = "MIGRATION"
+
+// MigrationStatus is where a data migration stands
+type MigrationStatus string
+
+const (
+	MigrationPending   MigrationStatus = "pending"
+	MigrationRunning   MigrationStatus = "running" // Started; resumes from Cursor
+	MigrationCompleted MigrationStatus = "completed"
+	MigrationFailed    MigrationStatus = "failed" // Resumes from Cursor on the next run
+)
+
+// MigrationState is the checkpoint of one versioned data migration, saved
+// after every batch so an interrupted run resumes where it stopped
+type MigrationState struct {
+	Version     int             `json:"version" dynamodbav:"version"`
+	Name        string          `json:"name" dynamodbav:"name"`
+	Description string          `json:"description,omitempty" dynamodbav:"-"` // From the registry, not stored
+	Status      MigrationStatus `json:"status" dynamodbav:"status"`
+	Cursor      string          `json:"cursor,omitempty" dynamodbav:"cursor,omitempty"`
+	Batches     int             `json:"batches" dynamodbav:"batches"`
+	Scanned     int             `json:"scanned" dynamodbav:"scanned"`
+	Updated     int             `json:"updated" dynamodbav:"updated"`
+	LastError   string          `json:"lastError,omitempty" dynamodbav:"lastError,omitempty"`
+	StartedAt   *time.Time      `json:"startedAt,omitempty" dynamodbav:"startedAt,omitempty"`
+	UpdatedAt   *time.Time      `json:"updatedAt,omitempty" dynamodbav:"updatedAt,omitempty"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty" dynamodbav:"completedAt,omitempty"`
+}
+
+// MigrationStateItem represents a MigrationState in DynamoDB single-table design
+type MigrationStateItem struct {
+	DynamoDBItem
+	MigrationState
+}
+
+// MigrationStatePK is the partition holding every migration's state
+const MigrationStatePK = "MIGRATION"
+
+// NewMigrationStateItem creates a DynamoDB item for a migration's state.
+// Primary key pattern: PK=MIGRATION, SK=MIGRATION#{version, zero-padded}
+func NewMigrationStateItem(state MigrationState) MigrationStateItem {
+	return MigrationStateItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   MigrationStatePK,
+			SK:   GetMigrationStateSK(state.Version),
+			Type: string(EntityMigration),
+		},
+		MigrationState: state,
+	}
+}
+
+// GetMigrationStateSK returns the sort key of a migration's state; versions
+// are zero-padded so states list in version order
+func GetMigrationStateSK(version int) string {
+	return fmt.Sprintf("MIGRATION#%06d", version)
+}
+
+// MigrationStatusResponse lists every known migration, in version order
+type MigrationStatusResponse struct {
+	Items []MigrationState `json:"items"`
+	// Pending is the number of migrations not yet completed
+	Pending int `json:"pending"`
+}
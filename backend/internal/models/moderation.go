@@ -0,0 +1,127 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntityModerationReview represents the entity type for moderation reviews
+const EntityModerationReview EntityType = "MODERATION_REVIEW"
+
+// ModerationStatus represents the state of a held visibility change
+type ModerationStatus string
+
+const (
+	// ModerationPending means the automatic check is still running
+	ModerationPending ModerationStatus = "PENDING"
+	// ModerationFlagged means the check found a possible violation and an admin must decide
+	ModerationFlagged ModerationStatus = "FLAGGED"
+	// ModerationApproved means the change was applied, automatically or by an admin
+	ModerationApproved ModerationStatus = "APPROVED"
+	// ModerationRejected means an admin refused the change
+	ModerationRejected ModerationStatus = "REJECTED"
+	// ModerationWithdrawn means the owner changed or deleted the track before a decision
+	ModerationWithdrawn ModerationStatus = "WITHDRAWN"
+)
+
+// IsOpen returns true while the review still awaits a decision
+func (s ModerationStatus) IsOpen() bool {
+	return s == ModerationPending || s == ModerationFlagged
+}
+
+// ModerationReview records a visibility change held for a moderation check.
+// There is at most one review per track; a new request replaces a closed one.
+type ModerationReview struct {
+	TrackID             string           `json:"trackId" dynamodbav:"trackId"`
+	UserID              string           `json:"userId" dynamodbav:"userId"`
+	TrackTitle          string           `json:"trackTitle" dynamodbav:"trackTitle"`
+	TrackArtist         string           `json:"trackArtist,omitempty" dynamodbav:"trackArtist,omitempty"`
+	RequestedVisibility TrackVisibility  `json:"requestedVisibility" dynamodbav:"requestedVisibility"`
+	Status              ModerationStatus `json:"status" dynamodbav:"status"`
+	// Result of the automatic check
+	Categories []string   `json:"categories,omitempty" dynamodbav:"categories,omitempty"`
+	Reason     string     `json:"reason,omitempty" dynamodbav:"reason,omitempty"`
+	Transcript string     `json:"transcript,omitempty" dynamodbav:"transcript,omitempty"`
+	CheckedAt  *time.Time `json:"checkedAt,omitempty" dynamodbav:"checkedAt,omitempty"`
+	// Admin decision; ReviewedBy is empty when the check approved automatically
+	ReviewedBy string     `json:"reviewedBy,omitempty" dynamodbav:"reviewedBy,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty" dynamodbav:"reviewedAt,omitempty"`
+	ReviewNote string     `json:"reviewNote,omitempty" dynamodbav:"reviewNote,omitempty"`
+	Timestamps
+}
+
+// ModerationReviewItem represents a ModerationReview in DynamoDB single-table design
+type ModerationReviewItem struct {
+	DynamoDBItem
+	ModerationReview
+}
+
+// NewModerationReviewItem creates a DynamoDB item for a moderation review.
+// Primary key pattern: PK=MODERATION, SK=TRACK#{trackID}
+// GSI1 pattern: GSI1PK=MODERATION#{status}, GSI1SK={createdAt}#{trackID}
+func NewModerationReviewItem(review ModerationReview) ModerationReviewItem {
+	return ModerationReviewItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:     ModerationPK,
+			SK:     GetModerationReviewSK(review.TrackID),
+			GSI1PK: GetModerationStatusGSI1PK(review.Status),
+			GSI1SK: fmt.Sprintf("%s#%s", review.CreatedAt.Format(time.RFC3339), review.TrackID),
+			Type:   string(EntityModerationReview),
+		},
+		ModerationReview: review,
+	}
+}
+
+// ModerationPK is the partition holding every moderation review
+const ModerationPK = "MODERATION"
+
+// GetModerationReviewSK returns the sort key for a track's moderation review.
+func GetModerationReviewSK(trackID string) string {
+	return fmt.Sprintf("TRACK#%s", trackID)
+}
+
+// GetModerationStatusGSI1PK returns the GSI1 partition key for querying reviews by status.
+func GetModerationStatusGSI1PK(status ModerationStatus) string {
+	return fmt.Sprintf("MODERATION#%s", status)
+}
+
+// ModerationVerdict is the result of the automatic moderation check
+type ModerationVerdict struct {
+	Flagged bool `json:"flagged"`
+	// Categories names the policies the content may violate
+	Categories []string `json:"categories,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+	// Transcript is the speech recognized in the audio snippet, if any
+	Transcript string `json:"transcript,omitempty"`
+}
+
+// ModerationRequest asks the moderation Lambda to check a track
+type ModerationRequest struct {
+	UserID  string `json:"userId"`
+	TrackID string `json:"trackId"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// ModerationFilter represents filter options for the admin review queue
+type ModerationFilter struct {
+	// Status defaults to FLAGGED, the reviews waiting on an admin
+	Status ModerationStatus `query:"status"`
+	Limit  int              `query:"limit"`
+	Cursor string           `query:"cursor"`
+}
+
+// ModerationDecisionRequest represents an admin's approval or rejection
+type ModerationDecisionRequest struct {
+	Note string `json:"note,omitempty" validate:"max=1000"`
+}
+
+// TrackVisibilityUpdate reports the outcome of a visibility change. When the
+// change is held for moderation, Visibility is unchanged and PendingVisibility
+// holds the requested value.
+type TrackVisibilityUpdate struct {
+	TrackID           string           `json:"trackId"`
+	Visibility        TrackVisibility  `json:"visibility"`
+	PendingVisibility TrackVisibility  `json:"pendingVisibility,omitempty"`
+	ModerationStatus  ModerationStatus `json:"moderationStatus,omitempty"`
+}
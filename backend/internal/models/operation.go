@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntityOperation represents the entity type for an undoable bulk operation
+const EntityOperation EntityType = "OPERATION"
+
+// UndoWindow is how long after a bulk operation it can be undone
+const UndoWindow = 15 * time.Minute
+
+// OperationKind names the bulk operation a record can undo
+type OperationKind string
+
+const (
+	// OperationCleanup is an accepted batch of title and artist cleanups
+	OperationCleanup OperationKind = "cleanup"
+	// OperationArchiveDelete is an accepted batch of archive suggestions
+	// whose tracks were moved to the trash
+	OperationArchiveDelete OperationKind = "archive-delete"
+)
+
+// FieldChange is one field a bulk operation edited: the value it replaced and
+// the value it wrote
+type FieldChange struct {
+	TrackID string `json:"trackId" dynamodbav:"trackId"`
+	Field   string `json:"field" dynamodbav:"field"`
+	Before  string `json:"before" dynamodbav:"before"`
+	After   string `json:"after" dynamodbav:"after"`
+}
+
+// Operation is the inverse-operation record of a bulk change, kept for
+// UndoWindow so the user who made the change can revert it
+type Operation struct {
+	ID        string        `json:"id" dynamodbav:"id"`
+	UserID    string        `json:"userId" dynamodbav:"userId"`
+	Kind      OperationKind `json:"kind" dynamodbav:"kind"`
+	Changes   []FieldChange `json:"changes" dynamodbav:"changes"`
+	CreatedAt time.Time     `json:"createdAt" dynamodbav:"createdAt"`
+	ExpiresAt time.Time     `json:"expiresAt" dynamodbav:"expiresAt"`
+	// Trashed are the tracks the operation moved to the trash of LibraryID
+	Trashed   []string `json:"trashed,omitempty" dynamodbav:"trashed,omitempty"`
+	LibraryID string   `json:"libraryId,omitempty" dynamodbav:"libraryId,omitempty"`
+}
+
+// Expired reports whether the undo window of the operation has closed
+func (o *Operation) Expired(now time.Time) bool {
+	return !now.Before(o.ExpiresAt)
+}
+
+// RevertChange restores the value a change replaced. It returns false,
+// leaving the track unchanged, when the field no longer holds the value the
+// change wrote.
+func (t *Track) RevertChange(c FieldChange) bool {
+	value := t.tagField(c.Field)
+	if value == nil || *value != c.After {
+		return false
+	}
+	*value = c.Before
+	return true
+}
+
+// OperationItem represents an Operation in DynamoDB single-table design
+type OperationItem struct {
+	DynamoDBItem
+	Operation
+	TTL int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewOperationItem creates a DynamoDB item for an operation.
+// Primary key pattern: PK=USER#{userID}, SK=OPERATION#{operationID}
+func NewOperationItem(op Operation) OperationItem {
+	return OperationItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   fmt.Sprintf("USER#%s", op.UserID),
+			SK:   GetOperationSK(op.ID),
+			Type: string(EntityOperation),
+		},
+		Operation: op,
+		TTL:       op.ExpiresAt.Unix(),
+	}
+}
+
+// GetOperationSK returns the sort key of an operation record
+func GetOperationSK(operationID string) string {
+	return fmt.Sprintf("OPERATION#%s", operationID)
+}
+
+// UndoResult reports the outcome of undoing a bulk operation
+type UndoResult struct {
+	OperationID string        `json:"operationId"`
+	Reverted    []FieldChange `json:"reverted"`
+	// Skipped changes target a track that was deleted or locked, or a field
+	// edited again since the operation
+	Skipped []FieldChange `json:"skipped,omitempty"`
+	// Restored are the trashed tracks put back in the library
+	Restored []string `json:"restored,omitempty"`
+}
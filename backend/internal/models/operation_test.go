@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack_RevertChange(t *testing.T) {
+	t.Run("restores the value the change replaced", func(t *testing.T) {
+		track := Track{Title: "Hello World"}
+
+		reverted := track.RevertChange(FieldChange{Field: TagFieldTitle, Before: "HELLO WORLD", After: "Hello World"})
+
+		assert.True(t, reverted)
+		assert.Equal(t, "HELLO WORLD", track.Title)
+	})
+
+	t.Run("skips fields edited since the change", func(t *testing.T) {
+		track := Track{Artist: "Daft Punk (edited)"}
+
+		reverted := track.RevertChange(FieldChange{Field: TagFieldArtist, Before: "DAFT PUNK", After: "Daft Punk"})
+
+		assert.False(t, reverted)
+		assert.Equal(t, "Daft Punk (edited)", track.Artist)
+	})
+
+	t.Run("skips unknown fields", func(t *testing.T) {
+		track := Track{}
+		assert.False(t, track.RevertChange(FieldChange{Field: "bpm", Before: "120", After: ""}))
+	})
+}
+
+func TestOperation_Expired(t *testing.T) {
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	op := Operation{CreatedAt: created, ExpiresAt: created.Add(UndoWindow)}
+
+	assert.False(t, op.Expired(created.Add(UndoWindow-time.Second)))
+	assert.True(t, op.Expired(created.Add(UndoWindow)))
+}
+
+func TestNewOperationItem(t *testing.T) {
+	expires := time.Date(2026, 10, 1, 12, 15, 0, 0, time.UTC)
+	item := NewOperationItem(Operation{ID: "op1", UserID: "u1", Kind: OperationCleanup, ExpiresAt: expires})
+
+	assert.Equal(t, "USER#u1", item.PK)
+	assert.Equal(t, "OPERATION#op1", item.SK)
+	assert.Equal(t, string(EntityOperation), item.Type)
+	assert.Equal(t, expires.Unix(), item.TTL)
+}
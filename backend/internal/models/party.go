@@ -0,0 +1,201 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// EntityParty represents the entity type for a guest DJ party
+	EntityParty EntityType = "PARTY"
+	// EntityPartyRequest represents the entity type for a track a party guest
+	// asked the host to queue
+	EntityPartyRequest EntityType = "PARTY_REQUEST"
+)
+
+const (
+	// DefaultPartyDuration is how long a party link works when the host does
+	// not say
+	DefaultPartyDuration = 4 * time.Hour
+	// MaxPartyDuration bounds how long a party link works
+	MaxPartyDuration = 12 * time.Hour
+	// PartyGuestRequestLimit is how many tracks one guest may request within
+	// PartyGuestRequestWindow
+	PartyGuestRequestLimit  = 5
+	PartyGuestRequestWindow = 10 * time.Minute
+	// MaxPendingPartyRequests bounds the requests waiting for the host's approval
+	MaxPendingPartyRequests = 50
+)
+
+// partyTokenPrefix scopes a share link token to a party, so a party link is
+// never read as a preview link to a track and the other way around
+const partyTokenPrefix = "party:"
+
+// Party lets guests without an account search some of the host's tracks and
+// ask for them to be queued. Guests reach it through a signed link; nothing
+// they ask for is queued until the host approves it.
+type Party struct {
+	ID     string `json:"id" dynamodbav:"id"`
+	HostID string `json:"hostId" dynamodbav:"hostId"`
+	Name   string `json:"name" dynamodbav:"name"`
+	// PlaylistID limits the party to one of the host's playlists; empty
+	// offers the host's public tracks
+	PlaylistID string    `json:"playlistId,omitempty" dynamodbav:"playlistId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" dynamodbav:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt" dynamodbav:"expiresAt"`
+}
+
+// Active reports whether guests can still use the party at now
+func (p *Party) Active(now time.Time) bool {
+	return now.Before(p.ExpiresAt)
+}
+
+// PartyTokenSubject returns the subject a party's link token carries
+func PartyTokenSubject(partyID string) string {
+	return partyTokenPrefix + partyID
+}
+
+// ParsePartyTokenSubject returns the party a link token's subject names, and
+// false for subjects of other links
+func ParsePartyTokenSubject(subject string) (string, bool) {
+	partyID, ok := strings.CutPrefix(subject, partyTokenPrefix)
+	return partyID, ok && partyID != ""
+}
+
+// PartyRequestStatus represents where a guest's track request stands
+type PartyRequestStatus string
+
+const (
+	PartyRequestPending  PartyRequestStatus = "PENDING"
+	PartyRequestApproved PartyRequestStatus = "APPROVED"
+	PartyRequestDeclined PartyRequestStatus = "DECLINED"
+)
+
+// PartyRequest is a track a guest asked the host to queue
+type PartyRequest struct {
+	ID          string `json:"id" dynamodbav:"id"`
+	PartyID     string `json:"partyId" dynamodbav:"partyId"`
+	TrackID     string `json:"trackId" dynamodbav:"trackId"`
+	TrackTitle  string `json:"trackTitle" dynamodbav:"trackTitle"`
+	TrackArtist string `json:"trackArtist,omitempty" dynamodbav:"trackArtist,omitempty"`
+	GuestName   string `json:"guestName,omitempty" dynamodbav:"guestName,omitempty"`
+	// GuestKey identifies the guest for rate limiting without storing their
+	// address: a hash of the party and the address the request came from
+	GuestKey    string             `json:"-" dynamodbav:"guestKey"`
+	Status      PartyRequestStatus `json:"status" dynamodbav:"status"`
+	CreatedAt   time.Time          `json:"createdAt" dynamodbav:"createdAt"`
+	RespondedAt *time.Time         `json:"respondedAt,omitempty" dynamodbav:"respondedAt,omitempty"`
+	// ExpiresAt is the party's expiry; requests go with their party
+	ExpiresAt time.Time `json:"-" dynamodbav:"expiresAt"`
+}
+
+// CreatePartyRequest represents a request to start a party
+type CreatePartyRequest struct {
+	Name       string `json:"name" validate:"required,max=100"`
+	PlaylistID string `json:"playlistId,omitempty" validate:"omitempty,uuid"`
+	// DurationMinutes defaults to DefaultPartyDuration
+	DurationMinutes int `json:"durationMinutes,omitempty" validate:"omitempty,min=15,max=720"`
+}
+
+// Duration returns how long the party link should work
+func (r CreatePartyRequest) Duration() time.Duration {
+	if r.DurationMinutes == 0 {
+		return DefaultPartyDuration
+	}
+	return min(time.Duration(r.DurationMinutes)*time.Minute, MaxPartyDuration)
+}
+
+// PartyLinkResponse is a started party and the link guests open it with. The
+// link needs no authentication until ExpiresAt or until the host ends the party.
+type PartyLinkResponse struct {
+	Party Party  `json:"party"`
+	URL   string `json:"url"`
+}
+
+// PartyGuestView is what a party link shows guests; it leaves out the host
+type PartyGuestView struct {
+	Name      string    `json:"name"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// PartyTrack is a track as party guests see it
+type PartyTrack struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist,omitempty"`
+	Album    string `json:"album,omitempty"`
+	Duration int    `json:"duration"`
+}
+
+// NewPartyTrack returns the guest view of a track
+func NewPartyTrack(t Track) PartyTrack {
+	return PartyTrack{ID: t.ID, Title: t.Title, Artist: t.Artist, Album: t.Album, Duration: t.Duration}
+}
+
+// PartySearchFilter represents a guest's search of a party's tracks
+type PartySearchFilter struct {
+	Query string `query:"q" validate:"max=100"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+// GuestTrackRequest represents a guest asking for a track to be queued
+type GuestTrackRequest struct {
+	TrackID   string `json:"trackId" validate:"required,uuid"`
+	GuestName string `json:"guestName,omitempty" validate:"max=50"`
+}
+
+// PartyItem represents a Party in DynamoDB single-table design
+type PartyItem struct {
+	DynamoDBItem
+	Party
+	TTL int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewPartyItem creates a DynamoDB item for a party.
+// Primary key pattern: PK=PARTY#{partyID}, SK=METADATA
+func NewPartyItem(party Party) PartyItem {
+	return PartyItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   GetPartyPK(party.ID),
+			SK:   "METADATA",
+			Type: string(EntityParty),
+		},
+		Party: party,
+		TTL:   party.ExpiresAt.Unix(),
+	}
+}
+
+// PartyRequestItem represents a PartyRequest in DynamoDB single-table design
+type PartyRequestItem struct {
+	DynamoDBItem
+	PartyRequest
+	TTL int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewPartyRequestItem creates a DynamoDB item for a guest's track request.
+// Primary key pattern: PK=PARTY#{partyID}, SK=REQUEST#{requestID}
+func NewPartyRequestItem(req PartyRequest) PartyRequestItem {
+	return PartyRequestItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   GetPartyPK(req.PartyID),
+			SK:   GetPartyRequestSK(req.ID),
+			Type: string(EntityPartyRequest),
+		},
+		PartyRequest: req,
+		TTL:          req.ExpiresAt.Unix(),
+	}
+}
+
+// GetPartyPK returns the partition key for a party and its requests
+func GetPartyPK(partyID string) string {
+	return fmt.Sprintf("PARTY#%s", partyID)
+}
+
+// PartyRequestSKPrefix starts the sort key of every request of a party
+const PartyRequestSKPrefix = "REQUEST#"
+
+// GetPartyRequestSK returns the sort key for a guest's track request
+func GetPartyRequestSK(requestID string) string {
+	return PartyRequestSKPrefix + requestID
+}
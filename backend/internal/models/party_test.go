@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartyTokenSubject(t *testing.T) {
+	partyID, ok := ParsePartyTokenSubject(PartyTokenSubject("p1"))
+	assert.True(t, ok)
+	assert.Equal(t, "p1", partyID)
+
+	// A preview link's subject is a track ID
+	_, ok = ParsePartyTokenSubject("11111111-1111-4111-8111-111111111111")
+	assert.False(t, ok)
+	_, ok = ParsePartyTokenSubject(PartyTokenSubject(""))
+	assert.False(t, ok)
+}
+
+func TestCreatePartyRequest_Duration(t *testing.T) {
+	assert.Equal(t, DefaultPartyDuration, CreatePartyRequest{}.Duration())
+	assert.Equal(t, 90*time.Minute, CreatePartyRequest{DurationMinutes: 90}.Duration())
+	assert.Equal(t, MaxPartyDuration, CreatePartyRequest{DurationMinutes: 10000}.Duration())
+}
+
+func TestNewPartyRequestItem(t *testing.T) {
+	expires := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	item := NewPartyRequestItem(PartyRequest{ID: "r1", PartyID: "p1", ExpiresAt: expires})
+
+	assert.Equal(t, "PARTY#p1", item.PK)
+	assert.Equal(t, "REQUEST#r1", item.SK)
+	assert.Equal(t, expires.Unix(), item.TTL)
+}
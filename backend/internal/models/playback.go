@@ -0,0 +1,43 @@
+package models
+
+// EQPresetFlat leaves the track's frequency response unchanged
+const EQPresetFlat = "flat"
+
+// TrackPlaybackSettings are corrections clients apply whenever the track
+// plays, so a quiet master or a harsh mix sounds the same on every device
+type TrackPlaybackSettings struct {
+	GainDB   float64 `json:"gainDb" dynamodbav:"gainDb"`                         // Offset from the file's level, in decibels
+	EQPreset string  `json:"eqPreset,omitempty" dynamodbav:"eqPreset,omitempty"` // Equalizer preset name; empty is flat
+}
+
+// UpdatePlaybackSettingsRequest changes a track's playback settings. Omitted
+// fields are left unchanged; a gain of 0 and the flat preset clear them.
+// Gain is bounded to +/-12 dB so a typo cannot blast the listener.
+type UpdatePlaybackSettingsRequest struct {
+	GainDB   *float64 `json:"gainDb,omitempty" validate:"omitempty,min=-12,max=12"`
+	EQPreset *string  `json:"eqPreset,omitempty" validate:"omitempty,oneof=flat bass_boost bass_cut treble_boost treble_cut vocal loudness"`
+}
+
+// ApplyPlaybackSettings updates the track's playback settings from req,
+// removing them once they no longer change anything
+func (t *Track) ApplyPlaybackSettings(req UpdatePlaybackSettingsRequest) {
+	settings := TrackPlaybackSettings{}
+	if t.PlaybackSettings != nil {
+		settings = *t.PlaybackSettings
+	}
+	if req.GainDB != nil {
+		settings.GainDB = *req.GainDB
+	}
+	if req.EQPreset != nil {
+		settings.EQPreset = *req.EQPreset
+	}
+	if settings.EQPreset == EQPresetFlat {
+		settings.EQPreset = ""
+	}
+
+	if settings == (TrackPlaybackSettings{}) {
+		t.PlaybackSettings = nil
+		return
+	}
+	t.PlaybackSettings = &settings
+}
@@ -0,0 +1,124 @@
+package models
+
+// PlaybackOption identifies one way of playing a track
+type PlaybackOption string
+
+const (
+	PlaybackOriginal PlaybackOption = "original" // the uploaded file through a signed URL
+	PlaybackHLS      PlaybackOption = "hls"      // the adaptive HLS transcode
+	PlaybackPreview  PlaybackOption = "preview"  // the 30-second clip of a public track
+)
+
+// PlaybackReadiness is the state of one playback option
+type PlaybackReadiness string
+
+const (
+	PlaybackReady       PlaybackReadiness = "ready"
+	PlaybackPending     PlaybackReadiness = "pending" // being produced, or will be
+	PlaybackFailed      PlaybackReadiness = "failed"
+	PlaybackUnavailable PlaybackReadiness = "unavailable"
+)
+
+// PlaybackReason says why a playback option is not ready
+type PlaybackReason string
+
+const (
+	PlaybackReasonNoAudioFile       PlaybackReason = "no_audio_file"
+	PlaybackReasonNotTranscoded     PlaybackReason = "not_transcoded"
+	PlaybackReasonTranscoding       PlaybackReason = "transcoding"
+	PlaybackReasonTranscodeFailed   PlaybackReason = "transcode_failed"
+	PlaybackReasonStreamingDisabled PlaybackReason = "streaming_disabled"
+	PlaybackReasonNotPublic         PlaybackReason = "not_public"
+	PlaybackReasonScheduled         PlaybackReason = "scheduled"
+	PlaybackReasonPreviewsDisabled  PlaybackReason = "previews_disabled"
+)
+
+// PlaybackFeatures are the parts of the deployment a track's playback
+// options depend on
+type PlaybackFeatures struct {
+	HLSStreaming bool // HLS playlists are signed through CloudFront
+	Previews     bool // preview clips are handed out through signed links
+}
+
+// PlaybackOptionStatus is the readiness of one playback option, with why it
+// is not ready
+type PlaybackOptionStatus struct {
+	Status PlaybackReadiness `json:"status"`
+	Reason PlaybackReason    `json:"reason,omitempty"`
+	Format AudioFormat       `json:"format,omitempty"`
+}
+
+// PlaybackAvailability tells clients which ways of playing a track they can
+// offer. Preferred is the option GET /stream/:trackId streams, empty when the
+// track cannot be played.
+type PlaybackAvailability struct {
+	Original  PlaybackOptionStatus `json:"original"`
+	HLS       PlaybackOptionStatus `json:"hls"`
+	Preview   PlaybackOptionStatus `json:"preview"`
+	Preferred PlaybackOption       `json:"preferred,omitempty"`
+}
+
+// PlaybackAvailability describes the track's playback options in a
+// deployment with the given features
+func (t *Track) PlaybackAvailability(features PlaybackFeatures) PlaybackAvailability {
+	availability := PlaybackAvailability{
+		Original: t.originalAvailability(),
+		HLS:      t.hlsAvailability(features),
+		Preview:  t.previewAvailability(features),
+	}
+	switch {
+	case availability.HLS.Status == PlaybackReady:
+		availability.Preferred = PlaybackHLS
+	case availability.Original.Status == PlaybackReady:
+		availability.Preferred = PlaybackOriginal
+	}
+	return availability
+}
+
+func (t *Track) originalAvailability() PlaybackOptionStatus {
+	if t.S3Key == "" {
+		return PlaybackOptionStatus{Status: PlaybackUnavailable, Reason: PlaybackReasonNoAudioFile, Format: t.Format}
+	}
+	return PlaybackOptionStatus{Status: PlaybackReady, Format: t.Format}
+}
+
+// hlsAvailability follows the transcode status; HLS variants are AAC
+func (t *Track) hlsAvailability(features PlaybackFeatures) PlaybackOptionStatus {
+	option := PlaybackOptionStatus{Format: AudioFormatAAC}
+	switch {
+	case t.HLSStatus == HLSStatusReady && t.HLSPlaylistKey != "":
+		option.Status = PlaybackReady
+		if !features.HLSStreaming {
+			option.Status, option.Reason = PlaybackUnavailable, PlaybackReasonStreamingDisabled
+		}
+	case t.HLSStatus.InProgress():
+		option.Status, option.Reason = PlaybackPending, PlaybackReasonTranscoding
+	case t.HLSStatus == HLSStatusFailed:
+		option.Status, option.Reason = PlaybackFailed, PlaybackReasonTranscodeFailed
+	default:
+		option.Status, option.Reason = PlaybackUnavailable, PlaybackReasonNotTranscoded
+	}
+	return option
+}
+
+// previewAvailability follows the clip's transcode status. Only public tracks
+// have clips, and links to a clip stop working when its track is made private.
+func (t *Track) previewAvailability(features PlaybackFeatures) PlaybackOptionStatus {
+	option := PlaybackOptionStatus{Format: AudioFormatMP3}
+	switch {
+	case t.Visibility != VisibilityPublic:
+		option.Status, option.Reason = PlaybackUnavailable, PlaybackReasonNotPublic
+	case !features.Previews:
+		option.Status, option.Reason = PlaybackUnavailable, PlaybackReasonPreviewsDisabled
+	case t.PreviewReady():
+		option.Status = PlaybackReady
+	case t.PreviewStatus.InProgress():
+		option.Status, option.Reason = PlaybackPending, PlaybackReasonTranscoding
+	case t.PreviewStatus == HLSStatusFailed:
+		option.Status, option.Reason = PlaybackFailed, PlaybackReasonTranscodeFailed
+	default:
+		// The scheduled preview processor picks up public tracks without a clip
+		option.Status, option.Reason = PlaybackPending, PlaybackReasonScheduled
+	}
+	return option
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack_PlaybackAvailability(t *testing.T) {
+	all := PlaybackFeatures{HLSStreaming: true, Previews: true}
+	transcoded := Track{
+		S3Key:          "media/u/t.flac",
+		Format:         AudioFormatFLAC,
+		HLSStatus:      HLSStatusReady,
+		HLSPlaylistKey: "hls/u/t/master.m3u8",
+		Visibility:     VisibilityPublic,
+		PreviewStatus:  HLSStatusReady,
+		PreviewKey:     "previews/u/t.mp3",
+	}
+
+	t.Run("everything ready", func(t *testing.T) {
+		availability := transcoded.PlaybackAvailability(all)
+		assert.Equal(t, PlaybackAvailability{
+			Original:  PlaybackOptionStatus{Status: PlaybackReady, Format: AudioFormatFLAC},
+			HLS:       PlaybackOptionStatus{Status: PlaybackReady, Format: AudioFormatAAC},
+			Preview:   PlaybackOptionStatus{Status: PlaybackReady, Format: AudioFormatMP3},
+			Preferred: PlaybackHLS,
+		}, availability)
+	})
+
+	t.Run("features the deployment lacks", func(t *testing.T) {
+		availability := transcoded.PlaybackAvailability(PlaybackFeatures{})
+		assert.Equal(t, PlaybackOptionStatus{Status: PlaybackUnavailable, Reason: PlaybackReasonStreamingDisabled, Format: AudioFormatAAC}, availability.HLS)
+		assert.Equal(t, PlaybackReasonPreviewsDisabled, availability.Preview.Reason)
+		assert.Equal(t, PlaybackOriginal, availability.Preferred)
+	})
+
+	t.Run("no audio file", func(t *testing.T) {
+		availability := (&Track{Format: AudioFormatMP3}).PlaybackAvailability(all)
+		assert.Equal(t, PlaybackOptionStatus{Status: PlaybackUnavailable, Reason: PlaybackReasonNoAudioFile, Format: AudioFormatMP3}, availability.Original)
+		assert.Empty(t, availability.Preferred)
+	})
+
+	hlsTests := []struct {
+		status     HLSStatus
+		wantStatus PlaybackReadiness
+		wantReason PlaybackReason
+	}{
+		{"", PlaybackUnavailable, PlaybackReasonNotTranscoded},
+		{HLSStatusPending, PlaybackPending, PlaybackReasonTranscoding},
+		{HLSStatusProcessing, PlaybackPending, PlaybackReasonTranscoding},
+		{HLSStatusFailed, PlaybackFailed, PlaybackReasonTranscodeFailed},
+	}
+	for _, tt := range hlsTests {
+		t.Run("hls "+string(tt.status), func(t *testing.T) {
+			track := Track{S3Key: "media/u/t.mp3", HLSStatus: tt.status}
+			hls := track.PlaybackAvailability(all).HLS
+			assert.Equal(t, tt.wantStatus, hls.Status)
+			assert.Equal(t, tt.wantReason, hls.Reason)
+		})
+	}
+
+	previewTests := []struct {
+		name       string
+		track      Track
+		wantStatus PlaybackReadiness
+		wantReason PlaybackReason
+	}{
+		{"private", Track{Visibility: VisibilityPrivate, PreviewStatus: HLSStatusReady, PreviewKey: "previews/u/t.mp3"}, PlaybackUnavailable, PlaybackReasonNotPublic},
+		{"unlisted", Track{Visibility: VisibilityUnlisted}, PlaybackUnavailable, PlaybackReasonNotPublic},
+		{"public without clip", Track{Visibility: VisibilityPublic}, PlaybackPending, PlaybackReasonScheduled},
+		{"clip being cut", Track{Visibility: VisibilityPublic, PreviewStatus: HLSStatusProcessing}, PlaybackPending, PlaybackReasonTranscoding},
+		{"clip failed", Track{Visibility: VisibilityPublic, PreviewStatus: HLSStatusFailed}, PlaybackFailed, PlaybackReasonTranscodeFailed},
+	}
+	for _, tt := range previewTests {
+		t.Run("preview "+tt.name, func(t *testing.T) {
+			preview := tt.track.PlaybackAvailability(all).Preview
+			assert.Equal(t, tt.wantStatus, preview.Status)
+			assert.Equal(t, tt.wantReason, preview.Reason)
+		})
+	}
+}
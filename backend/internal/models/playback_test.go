@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack_ApplyPlaybackSettings(t *testing.T) {
+	gain := func(v float64) *float64 { return &v }
+	preset := func(v string) *string { return &v }
+
+	t.Run("sets gain and preset", func(t *testing.T) {
+		track := Track{}
+
+		track.ApplyPlaybackSettings(UpdatePlaybackSettingsRequest{GainDB: gain(-3.5), EQPreset: preset("bass_cut")})
+
+		assert.Equal(t, &TrackPlaybackSettings{GainDB: -3.5, EQPreset: "bass_cut"}, track.PlaybackSettings)
+	})
+
+	t.Run("keeps omitted fields", func(t *testing.T) {
+		track := Track{PlaybackSettings: &TrackPlaybackSettings{GainDB: 2, EQPreset: "vocal"}}
+
+		track.ApplyPlaybackSettings(UpdatePlaybackSettingsRequest{GainDB: gain(4)})
+
+		assert.Equal(t, &TrackPlaybackSettings{GainDB: 4, EQPreset: "vocal"}, track.PlaybackSettings)
+	})
+
+	t.Run("flat clears the preset", func(t *testing.T) {
+		track := Track{PlaybackSettings: &TrackPlaybackSettings{GainDB: 2, EQPreset: "vocal"}}
+
+		track.ApplyPlaybackSettings(UpdatePlaybackSettingsRequest{EQPreset: preset(EQPresetFlat)})
+
+		assert.Equal(t, &TrackPlaybackSettings{GainDB: 2}, track.PlaybackSettings)
+	})
+
+	t.Run("removes settings that change nothing", func(t *testing.T) {
+		track := Track{PlaybackSettings: &TrackPlaybackSettings{GainDB: 2, EQPreset: "vocal"}}
+
+		track.ApplyPlaybackSettings(UpdatePlaybackSettingsRequest{GainDB: gain(0), EQPreset: preset(EQPresetFlat)})
+
+		assert.Nil(t, track.PlaybackSettings)
+	})
+
+	t.Run("does not share the previous settings", func(t *testing.T) {
+		previous := &TrackPlaybackSettings{GainDB: 2}
+		track := Track{PlaybackSettings: previous}
+
+		track.ApplyPlaybackSettings(UpdatePlaybackSettingsRequest{GainDB: gain(6)})
+
+		assert.Equal(t, 2.0, previous.GainDB)
+	})
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntityPlayerState represents the entity type for a user's synced player state
+const EntityPlayerState EntityType = "PLAYER_STATE"
+
+// MaxQueueLength bounds the tracks in a play queue
+const MaxQueueLength = 1000
+
+// PlayerState is a user's play queue, shared by every device and remote they
+// play from. Version counts its changes: a change made against an older
+// version is refused, so a remote never removes an index from a queue another
+// device has reordered in the meantime.
+type PlayerState struct {
+	UserID   string   `json:"userId" dynamodbav:"userId"`
+	TrackIDs []string `json:"trackIds" dynamodbav:"trackIds"`
+	// CurrentIndex is the position of the playing track in TrackIDs; 0 for an
+	// empty queue
+	CurrentIndex int       `json:"currentIndex" dynamodbav:"currentIndex"`
+	Version      int64     `json:"version" dynamodbav:"version"`
+	UpdatedAt    time.Time `json:"updatedAt" dynamodbav:"updatedAt"`
+}
+
+// QueueAction is a change to the play queue
+type QueueAction string
+
+const (
+	QueueActionAddNext QueueAction = "add_next"
+	QueueActionAddLast QueueAction = "add_last"
+	QueueActionRemove  QueueAction = "remove"
+	QueueActionClear   QueueAction = "clear"
+)
+
+// QueueActionRequest represents a request to change the play queue. Version is
+// the queue version the change was made against and is required for remove,
+// whose index means nothing on a queue that has changed; the other actions
+// apply to whatever the queue holds when it is omitted.
+type QueueActionRequest struct {
+	Action   QueueAction `json:"action" validate:"required,oneof=add_next add_last remove clear"`
+	TrackIDs []string    `json:"trackIds,omitempty" validate:"omitempty,max=1000,dive,uuid"`
+	Index    *int        `json:"index,omitempty" validate:"omitempty,min=0"`
+	Version  *int64      `json:"version,omitempty" validate:"omitempty,min=0"`
+}
+
+// Apply makes the change req describes, leaving the state unchanged and
+// returning a validation error if it cannot be made
+func (s *PlayerState) Apply(req QueueActionRequest) error {
+	switch req.Action {
+	case QueueActionAddNext:
+		return s.insert(s.nextPosition(), req.TrackIDs)
+	case QueueActionAddLast:
+		return s.insert(len(s.TrackIDs), req.TrackIDs)
+	case QueueActionRemove:
+		if req.Index == nil {
+			return NewValidationError("index is required to remove a track")
+		}
+		return s.removeAt(*req.Index)
+	case QueueActionClear:
+		s.TrackIDs = []string{}
+		s.CurrentIndex = 0
+		return nil
+	default:
+		return NewValidationError(fmt.Sprintf("unknown queue action: %s", req.Action))
+	}
+}
+
+// nextPosition is where "play next" inserts: right after the playing track,
+// or at the front of an empty queue
+func (s *PlayerState) nextPosition() int {
+	if len(s.TrackIDs) == 0 {
+		return 0
+	}
+	return min(s.CurrentIndex+1, len(s.TrackIDs))
+}
+
+func (s *PlayerState) insert(at int, trackIDs []string) error {
+	if len(trackIDs) == 0 {
+		return NewValidationError("trackIds is required to add tracks")
+	}
+	if len(s.TrackIDs)+len(trackIDs) > MaxQueueLength {
+		return NewValidationError(fmt.Sprintf("the queue holds at most %d tracks", MaxQueueLength))
+	}
+
+	queue := make([]string, 0, len(s.TrackIDs)+len(trackIDs))
+	queue = append(queue, s.TrackIDs[:at]...)
+	queue = append(queue, trackIDs...)
+	queue = append(queue, s.TrackIDs[at:]...)
+	s.TrackIDs = queue
+	return nil
+}
+
+// removeAt drops the track at index. The playing track keeps playing; if it
+// is the one removed, the track after it (or, at the end, before it) becomes
+// current.
+func (s *PlayerState) removeAt(index int) error {
+	if index < 0 || index >= len(s.TrackIDs) {
+		return NewValidationError(fmt.Sprintf("index %d is outside the queue of %d tracks", index, len(s.TrackIDs)))
+	}
+
+	s.TrackIDs = append(s.TrackIDs[:index:index], s.TrackIDs[index+1:]...)
+	if index < s.CurrentIndex || s.CurrentIndex >= len(s.TrackIDs) {
+		s.CurrentIndex = max(0, s.CurrentIndex-1)
+	}
+	return nil
+}
+
+// PlayerStateItem represents a PlayerState in DynamoDB single-table design
+// PK: USER#{userId}, SK: PLAYERSTATE
+type PlayerStateItem struct {
+	DynamoDBItem
+	PlayerState
+}
+
+// PlayerStateSK is the sort key of a user's player state
+const PlayerStateSK = "PLAYERSTATE"
+
+// NewPlayerStateItem creates a DynamoDB item for a player state
+func NewPlayerStateItem(state PlayerState) PlayerStateItem {
+	return PlayerStateItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   "USER#" + state.UserID,
+			SK:   PlayerStateSK,
+			Type: string(EntityPlayerState),
+		},
+		PlayerState: state,
+	}
+}
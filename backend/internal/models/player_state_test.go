@@ -0,0 +1,82 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestPlayerState_Apply(t *testing.T) {
+	queue := func(current int, ids ...string) *PlayerState {
+		return &PlayerState{UserID: "u1", TrackIDs: ids, CurrentIndex: current}
+	}
+
+	tests := []struct {
+		name        string
+		state       *PlayerState
+		req         QueueActionRequest
+		wantIDs     []string
+		wantCurrent int
+	}{
+		{"add next after playing track", queue(1, "a", "b", "c"), QueueActionRequest{Action: QueueActionAddNext, TrackIDs: []string{"x", "y"}}, []string{"a", "b", "x", "y", "c"}, 1},
+		{"add next to empty queue", queue(0), QueueActionRequest{Action: QueueActionAddNext, TrackIDs: []string{"x"}}, []string{"x"}, 0},
+		{"add last", queue(0, "a", "b"), QueueActionRequest{Action: QueueActionAddLast, TrackIDs: []string{"x"}}, []string{"a", "b", "x"}, 0},
+		{"remove before playing track", queue(2, "a", "b", "c"), QueueActionRequest{Action: QueueActionRemove, Index: intPtr(0)}, []string{"b", "c"}, 1},
+		{"remove after playing track", queue(0, "a", "b", "c"), QueueActionRequest{Action: QueueActionRemove, Index: intPtr(2)}, []string{"a", "b"}, 0},
+		{"remove playing track", queue(1, "a", "b", "c"), QueueActionRequest{Action: QueueActionRemove, Index: intPtr(1)}, []string{"a", "c"}, 1},
+		{"remove playing last track", queue(2, "a", "b", "c"), QueueActionRequest{Action: QueueActionRemove, Index: intPtr(2)}, []string{"a", "b"}, 1},
+		{"remove only track", queue(0, "a"), QueueActionRequest{Action: QueueActionRemove, Index: intPtr(0)}, []string{}, 0},
+		{"clear", queue(1, "a", "b"), QueueActionRequest{Action: QueueActionClear}, []string{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.state.Apply(tt.req))
+			assert.Equal(t, tt.wantIDs, tt.state.TrackIDs)
+			assert.Equal(t, tt.wantCurrent, tt.state.CurrentIndex)
+		})
+	}
+}
+
+func TestPlayerState_ApplyRejects(t *testing.T) {
+	full := &PlayerState{TrackIDs: make([]string, MaxQueueLength)}
+	tests := []struct {
+		name  string
+		state *PlayerState
+		req   QueueActionRequest
+	}{
+		{"add without tracks", &PlayerState{}, QueueActionRequest{Action: QueueActionAddLast}},
+		{"queue full", full, QueueActionRequest{Action: QueueActionAddNext, TrackIDs: []string{"x"}}},
+		{"remove without index", &PlayerState{TrackIDs: []string{"a"}}, QueueActionRequest{Action: QueueActionRemove}},
+		{"remove out of range", &PlayerState{TrackIDs: []string{"a"}}, QueueActionRequest{Action: QueueActionRemove, Index: intPtr(1)}},
+		{"unknown action", &PlayerState{}, QueueActionRequest{Action: "shuffle"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := append([]string(nil), tt.state.TrackIDs...)
+			err := tt.state.Apply(tt.req)
+
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+			assert.Equal(t, before, append([]string(nil), tt.state.TrackIDs...))
+		})
+	}
+}
+
+func TestPlayerState_RemoveKeepsSource(t *testing.T) {
+	ids := []string{"a", "b", "c"}
+	state := &PlayerState{TrackIDs: ids}
+	require.NoError(t, state.Apply(QueueActionRequest{Action: QueueActionRemove, Index: intPtr(0)}))
+	assert.Equal(t, []string{"a", "b", "c"}, ids)
+}
+
+func TestNewPlayerStateItem(t *testing.T) {
+	item := NewPlayerStateItem(PlayerState{UserID: "u1", Version: 3})
+	assert.Equal(t, "USER#u1", item.PK)
+	assert.Equal(t, PlayerStateSK, item.SK)
+	assert.Equal(t, string(EntityPlayerState), item.Type)
+	assert.Equal(t, int64(3), item.Version)
+}
@@ -152,10 +152,20 @@ type PlaylistWithTracks struct {
 	Tracks   []TrackResponse  `json:"tracks"`
 }
 
+// DeletePlaylistResult reports the playlist a delete removes, with its tracks
+type DeletePlaylistResult struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	TrackCount int    `json:"trackCount"`
+	// DryRun is set when nothing was deleted; the result is what a real run does
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
 // PlaylistFilter represents filter options for listing playlists
 type PlaylistFilter struct {
 	SortBy    string `query:"sortBy"`    // name, createdAt, updatedAt, trackCount
 	SortOrder string `query:"sortOrder"` // asc, desc
 	Limit     int    `query:"limit"`
 	LastKey   string `query:"lastKey"`
+	CountOnly bool   `query:"countOnly"` // Return only the total, as a CountResponse
 }
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// PreviewClipSeconds is the length of a public track's preview clip
+const PreviewClipSeconds = 30
+
+// PreviewLinkTTL is how long a preview share link stays valid
+const PreviewLinkTTL = 7 * 24 * time.Hour
+
+// NeedsPreview reports whether a preview clip should be generated for the
+// track: it is public and has no clip, running clip job or failed clip job
+func (t *Track) NeedsPreview() bool {
+	return t.Visibility == VisibilityPublic && t.PreviewKey == "" && t.PreviewStatus == ""
+}
+
+// PreviewReady reports whether the track's preview clip can be served
+func (t *Track) PreviewReady() bool {
+	return t.PreviewStatus == HLSStatusReady && t.PreviewKey != ""
+}
+
+// PreviewClip returns the start and length in seconds of the track's preview
+// clip. A clip that would run past the end of the track is moved back. Tracks
+// without an analyzed start (PreviewStart 0) that are long enough to have an
+// intro start a third of the way in.
+func (t *Track) PreviewClip() (start, length int) {
+	start, length = t.PreviewStart, PreviewClipSeconds
+	if t.Duration <= 0 {
+		return start, length
+	}
+	if t.Duration <= PreviewClipSeconds {
+		return 0, t.Duration
+	}
+	if start == 0 && t.Duration >= 2*PreviewClipSeconds {
+		start = t.Duration / 3
+	}
+	return min(start, t.Duration-PreviewClipSeconds), length
+}
+
+// PreviewLinkResponse is a share link to a track's preview clip. The URL needs
+// no authentication until ExpiresAt, or until the track stops being public.
+type PreviewLinkResponse struct {
+	TrackID   string    `json:"trackId"`
+	URL       string    `json:"url"`
+	TokenID   string    `json:"tokenId"` // Names the link in the track's access log
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack_NeedsPreview(t *testing.T) {
+	assert.True(t, (&Track{Visibility: VisibilityPublic}).NeedsPreview())
+	assert.False(t, (&Track{Visibility: VisibilityPrivate}).NeedsPreview())
+	assert.False(t, (&Track{Visibility: VisibilityUnlisted}).NeedsPreview())
+	assert.False(t, (&Track{Visibility: VisibilityPublic, PreviewStatus: HLSStatusProcessing}).NeedsPreview())
+	assert.False(t, (&Track{Visibility: VisibilityPublic, PreviewStatus: HLSStatusFailed}).NeedsPreview())
+	assert.False(t, (&Track{Visibility: VisibilityPublic, PreviewKey: "previews/u/t.mp3"}).NeedsPreview())
+}
+
+func TestTrack_PreviewClip(t *testing.T) {
+	tests := []struct {
+		name       string
+		track      Track
+		wantStart  int
+		wantLength int
+	}{
+		{"analyzed start", Track{Duration: 240, PreviewStart: 75}, 75, 30},
+		{"moved back from the end", Track{Duration: 240, PreviewStart: 230}, 210, 30},
+		{"unanalyzed starts a third in", Track{Duration: 240}, 80, 30},
+		{"short track without intro", Track{Duration: 50}, 0, 30},
+		{"track shorter than a clip", Track{Duration: 20, PreviewStart: 5}, 0, 20},
+		{"unknown duration", Track{PreviewStart: 40}, 40, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, length := tt.track.PreviewClip()
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantLength, length)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// EntityDeviceSession represents the entity type for a signed-in device
+	// that can be controlled remotely
+	EntityDeviceSession EntityType = "DEVICE_SESSION"
+	// EntityRemoteCommand represents the entity type for a command waiting to
+	// be delivered to a device
+	EntityRemoteCommand EntityType = "REMOTE_COMMAND"
+)
+
+const (
+	// DeviceSessionTTL is how long a device stays listed as active after it
+	// last registered or listened for commands
+	DeviceSessionTTL = 2 * time.Minute
+	// RemoteCommandTTL is how long a command waits for its device; a pause
+	// delivered minutes late would surprise whoever is listening
+	RemoteCommandTTL = 30 * time.Second
+)
+
+// DeviceSession is one of a user's devices that is open and listening for
+// remote commands
+type DeviceSession struct {
+	UserID     string    `json:"userId" dynamodbav:"userId"`
+	DeviceID   string    `json:"deviceId" dynamodbav:"deviceId"`
+	Name       string    `json:"name" dynamodbav:"name"`
+	LastSeenAt time.Time `json:"lastSeenAt" dynamodbav:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt" dynamodbav:"expiresAt"`
+}
+
+// Active reports whether the device was seen within DeviceSessionTTL of now
+func (d *DeviceSession) Active(now time.Time) bool {
+	return now.Before(d.ExpiresAt)
+}
+
+// RemoteCommandType is a playback command one device sends another
+type RemoteCommandType string
+
+const (
+	RemoteCommandPlay     RemoteCommandType = "play"
+	RemoteCommandPause    RemoteCommandType = "pause"
+	RemoteCommandNext     RemoteCommandType = "next"
+	RemoteCommandPrevious RemoteCommandType = "previous"
+	RemoteCommandSeek     RemoteCommandType = "seek"
+	RemoteCommandVolume   RemoteCommandType = "volume"
+)
+
+// RemoteCommand is a playback command waiting for, or delivered to, a device
+type RemoteCommand struct {
+	ID       string            `json:"id" dynamodbav:"id"`
+	UserID   string            `json:"userId" dynamodbav:"userId"`
+	DeviceID string            `json:"deviceId" dynamodbav:"deviceId"`
+	Command  RemoteCommandType `json:"command" dynamodbav:"command"`
+	// FromDeviceID is the device that sent the command, if it said
+	FromDeviceID string    `json:"fromDeviceId,omitempty" dynamodbav:"fromDeviceId,omitempty"`
+	PositionMs   *int64    `json:"positionMs,omitempty" dynamodbav:"positionMs,omitempty"`
+	Volume       *float64  `json:"volume,omitempty" dynamodbav:"volume,omitempty"`
+	CreatedAt    time.Time `json:"createdAt" dynamodbav:"createdAt"`
+	ExpiresAt    time.Time `json:"expiresAt" dynamodbav:"expiresAt"`
+}
+
+// RegisterDeviceRequest represents a request to register a device for remote
+// control, or to keep its session alive
+type RegisterDeviceRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// RemoteCommandRequest represents a request to send a command to a device
+type RemoteCommandRequest struct {
+	Command      RemoteCommandType `json:"command" validate:"required,oneof=play pause next previous seek volume"`
+	FromDeviceID string            `json:"fromDeviceId,omitempty" validate:"omitempty,uuid"`
+	PositionMs   *int64            `json:"positionMs,omitempty" validate:"omitempty,min=0"`
+	Volume       *float64          `json:"volume,omitempty" validate:"omitempty,min=0,max=1"`
+}
+
+// Validate checks that seek and volume commands carry their value
+func (r RemoteCommandRequest) Validate() error {
+	switch {
+	case r.Command == RemoteCommandSeek && r.PositionMs == nil:
+		return NewValidationError("positionMs is required for seek")
+	case r.Command == RemoteCommandVolume && r.Volume == nil:
+		return NewValidationError("volume is required for volume")
+	}
+	return nil
+}
+
+// DeviceSessionItem represents a DeviceSession in DynamoDB single-table design
+type DeviceSessionItem struct {
+	DynamoDBItem
+	DeviceSession
+	TTL int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewDeviceSessionItem creates a DynamoDB item for a device session.
+// Primary key pattern: PK=USER#{userID}, SK=DEVICE#{deviceID}
+func NewDeviceSessionItem(session DeviceSession) DeviceSessionItem {
+	return DeviceSessionItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   fmt.Sprintf("USER#%s", session.UserID),
+			SK:   GetDeviceSessionSK(session.DeviceID),
+			Type: string(EntityDeviceSession),
+		},
+		DeviceSession: session,
+		TTL:           session.ExpiresAt.Unix(),
+	}
+}
+
+// DeviceSessionSKPrefix starts the sort key of every device session
+const DeviceSessionSKPrefix = "DEVICE#"
+
+// GetDeviceSessionSK returns the sort key of a device session
+func GetDeviceSessionSK(deviceID string) string {
+	return DeviceSessionSKPrefix + deviceID
+}
+
+// RemoteCommandItem represents a RemoteCommand in DynamoDB single-table design
+type RemoteCommandItem struct {
+	DynamoDBItem
+	RemoteCommand
+	TTL int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewRemoteCommandItem creates a DynamoDB item for a remote command. The sort
+// key orders a device's commands by when they were sent.
+// Primary key pattern: PK=USER#{userID}, SK=REMOTE#{deviceID}#{createdAt}#{commandID}
+func NewRemoteCommandItem(cmd RemoteCommand) RemoteCommandItem {
+	return RemoteCommandItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   fmt.Sprintf("USER#%s", cmd.UserID),
+			SK:   fmt.Sprintf("%s%s#%s", GetRemoteCommandSKPrefix(cmd.DeviceID), cmd.CreatedAt.UTC().Format(sortableNano), cmd.ID),
+			Type: string(EntityRemoteCommand),
+		},
+		RemoteCommand: cmd,
+		TTL:           cmd.ExpiresAt.Unix(),
+	}
+}
+
+// GetRemoteCommandSKPrefix returns the sort key prefix of a device's commands
+func GetRemoteCommandSKPrefix(deviceID string) string {
+	return fmt.Sprintf("REMOTE#%s#", deviceID)
+}
+
+// sortableNano is RFC 3339 with fixed-width nanoseconds, so keys sort by time
+const sortableNano = "2006-01-02T15:04:05.000000000Z07:00"
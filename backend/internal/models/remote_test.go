@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteCommandRequest_Validate(t *testing.T) {
+	position := int64(30000)
+	volume := 0.5
+
+	assert.NoError(t, RemoteCommandRequest{Command: RemoteCommandPause}.Validate())
+	assert.NoError(t, RemoteCommandRequest{Command: RemoteCommandSeek, PositionMs: &position}.Validate())
+	assert.NoError(t, RemoteCommandRequest{Command: RemoteCommandVolume, Volume: &volume}.Validate())
+	assert.Error(t, RemoteCommandRequest{Command: RemoteCommandSeek}.Validate())
+	assert.Error(t, RemoteCommandRequest{Command: RemoteCommandVolume}.Validate())
+}
+
+func TestNewRemoteCommandItem_SortsByCreation(t *testing.T) {
+	base := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	first := NewRemoteCommandItem(RemoteCommand{ID: "b", UserID: "u1", DeviceID: "d1", CreatedAt: base.Add(900 * time.Millisecond)})
+	second := NewRemoteCommandItem(RemoteCommand{ID: "a", UserID: "u1", DeviceID: "d1", CreatedAt: base.Add(time.Second)})
+
+	assert.True(t, strings.HasPrefix(first.SK, GetRemoteCommandSKPrefix("d1")))
+	assert.Less(t, first.SK, second.SK)
+	assert.Equal(t, "USER#u1", first.PK)
+}
+
+func TestNewDeviceSessionItem(t *testing.T) {
+	expires := time.Date(2026, 10, 16, 12, 2, 0, 0, time.UTC)
+	item := NewDeviceSessionItem(DeviceSession{UserID: "u1", DeviceID: "d1", ExpiresAt: expires})
+
+	assert.Equal(t, "DEVICE#d1", item.SK)
+	assert.Equal(t, expires.Unix(), item.TTL)
+	assert.True(t, item.Active(expires.Add(-time.Second)))
+	assert.False(t, item.Active(expires))
+}
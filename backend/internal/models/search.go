@@ -1,12 +1,15 @@
 package models
 
+import "time"
+
 // SearchRequest represents a search query
 type SearchRequest struct {
 	Query   string        `json:"query" validate:"required,min=1,max=500"`
 	Filters SearchFilters `json:"filters,omitempty"`
 	Sort    SearchSort    `json:"sort,omitempty"`
 	Limit   int           `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
-	Cursor  string        `json:"cursor,omitempty"` // Opaque base64-encoded pagination cursor
+	Cursor  string        `json:"cursor,omitempty"`  // Opaque base64-encoded pagination cursor
+	Hydrate bool          `json:"hydrate,omitempty"` // Return full stored tracks instead of index fields
 }
 
 // SearchFilters represents filters for search
@@ -17,6 +20,16 @@ type SearchFilters struct {
 	Tags    []string `json:"tags,omitempty"`
 	Years   []int    `json:"years,omitempty"`
 	Formats []string `json:"formats,omitempty"`
+	// Duration (seconds) and bitrate (kbps) ranges; a zero bound is open
+	DurationFrom int `json:"durationFrom,omitempty" validate:"omitempty,min=0"`
+	DurationTo   int `json:"durationTo,omitempty" validate:"omitempty,min=0,gtefield=DurationFrom"`
+	BitrateFrom  int `json:"bitrateFrom,omitempty" validate:"omitempty,min=0"`
+	BitrateTo    int `json:"bitrateTo,omitempty" validate:"omitempty,min=0,gtefield=BitrateFrom"`
+	// HLSStatus keeps tracks in one transcode state, e.g. READY for tracks that
+	// can stream; Processing keeps tracks whose transcode is (or is not) pending
+	// or running
+	HLSStatus  HLSStatus `json:"hlsStatus,omitempty" validate:"omitempty,oneof=PENDING PROCESSING READY FAILED"`
+	Processing *bool     `json:"processing,omitempty"`
 }
 
 // SearchSort represents sort options for search
@@ -37,6 +50,9 @@ type SearchResponse struct {
 	Limit        int                `json:"limit"`
 	NextCursor   string             `json:"nextCursor,omitempty"` // Next page cursor (empty if no more results)
 	HasMore      bool               `json:"hasMore"`
+	// Degraded is set when the search index was unavailable and the results
+	// come from a scan of the most recent library tracks instead
+	Degraded bool `json:"degraded,omitempty"`
 }
 
 // SearchFacets represents aggregated facets for filtering
@@ -69,6 +85,8 @@ type SearchSuggestion struct {
 type AutocompleteResponse struct {
 	Query       string             `json:"query"`
 	Suggestions []SearchSuggestion `json:"suggestions"`
+	// Recent lists the user's recent searches starting with the query
+	Recent []RecentSearch `json:"recent,omitempty"`
 }
 
 // NixieIndexDocument represents a document in the Nixiesearch index
@@ -119,3 +137,128 @@ type NixieHit struct {
 	Score  float64            `json:"_score"`
 	Source NixieIndexDocument `json:"_source"`
 }
+
+// Quick search result types, in the order equally ranked results are listed
+const (
+	QuickSearchTrack    = "track"
+	QuickSearchArtist   = "artist"
+	QuickSearchAlbum    = "album"
+	QuickSearchPlaylist = "playlist"
+	QuickSearchTag      = "tag"
+	QuickSearchUser     = "user" // Users the caller follows
+)
+
+// QuickSearchTypes lists every quick search result type in ranking order
+var QuickSearchTypes = []string{QuickSearchTrack, QuickSearchArtist, QuickSearchAlbum, QuickSearchPlaylist, QuickSearchTag, QuickSearchUser}
+
+// QuickSearchRequest is a search across every entity type, as typed into a
+// global search box
+type QuickSearchRequest struct {
+	Query string `query:"q" validate:"required,min=1,max=500"`
+	// Limit is the most results returned of each type
+	Limit int `query:"limit" validate:"omitempty,min=1,max=20"`
+	// Types restricts the search to these types (comma-separated); all when empty
+	Types string `query:"types" validate:"omitempty,max=100"`
+}
+
+// QuickSearchResult is one result of a quick search
+type QuickSearchResult struct {
+	Type     string  `json:"type"`
+	ID       string  `json:"id"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle,omitempty"` // Artist of a track or album, track count of a tag
+	Score    float64 `json:"score"`
+}
+
+// QuickSearchResponse holds quick search results of all types ranked together
+type QuickSearchResponse struct {
+	Query   string              `json:"query"`
+	Results []QuickSearchResult `json:"results"`
+	// Counts is the number of results returned per type
+	Counts map[string]int `json:"counts"`
+	// FailedTypes lists types whose search failed; the other results are still returned
+	FailedTypes []string `json:"failedTypes,omitempty"`
+}
+
+// SearchIndexStatsResponse reports the size of the search index for capacity
+// monitoring (GET /api/v1/admin/search/stats)
+type SearchIndexStatsResponse struct {
+	Documents  int   `json:"documents"`
+	IndexBytes int64 `json:"indexBytes"`
+	// Users lists indexed documents per library, largest first
+	Users     []SearchIndexUserCount `json:"users"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+	// LastCompactionAt is when the index file was last rewritten whole
+	LastCompactionAt *time.Time `json:"lastCompactionAt,omitempty"`
+	// LastSyncAt is when the index was last loaded from or written to S3
+	LastSyncAt *time.Time `json:"lastSyncAt,omitempty"`
+}
+
+// StuckTranscode is an indexed track whose HLS transcode has been pending or
+// running for a long time
+type StuckTranscode struct {
+	TrackID         string     `json:"trackId"`
+	UserID          string     `json:"userId"`
+	Title           string     `json:"title"`
+	Artist          string     `json:"artist"`
+	HLSStatus       HLSStatus  `json:"hlsStatus"`
+	StatusUpdatedAt *time.Time `json:"statusUpdatedAt,omitempty"`
+}
+
+// StuckTranscodesResponse lists stuck transcodes across all libraries, oldest
+// first (GET /api/v1/admin/search/stuck-transcodes). Total counts all matches;
+// Items holds at most the first 100.
+type StuckTranscodesResponse struct {
+	Items     []StuckTranscode `json:"items"`
+	Total     int              `json:"total"`
+	OlderThan string           `json:"olderThan"`
+}
+
+// ExportSearchIndexRequest is the body of POST /api/v1/admin/search/export.
+// An empty UserID exports every library.
+type ExportSearchIndexRequest struct {
+	UserID string `json:"userId,omitempty" validate:"omitempty,max=128"`
+}
+
+// SearchIndexExportResponse names the export file written to the search
+// index bucket
+type SearchIndexExportResponse struct {
+	Key        string    `json:"key"`
+	UserID     string    `json:"userId,omitempty"`
+	Documents  int       `json:"documents"`
+	Bytes      int64     `json:"bytes"`
+	ExportedAt time.Time `json:"exportedAt"`
+}
+
+// ImportSearchIndexRequest is the body of POST /api/v1/admin/search/import.
+// Key names an export file under exports/ in the search index bucket; a
+// UserID imports that library's documents only.
+type ImportSearchIndexRequest struct {
+	Key    string `json:"key" validate:"required"`
+	UserID string `json:"userId,omitempty" validate:"omitempty,max=128"`
+}
+
+// SearchIndexImportResponse reports an import. Imported documents replace
+// any with the same ID; documents not in the file are left alone. Failures
+// lists at most the first 100 lines that were not imported.
+type SearchIndexImportResponse struct {
+	Key      string `json:"key"`
+	Imported int    `json:"imported"`
+	// Skipped documents belong to other libraries than the requested one
+	Skipped  int                        `json:"skipped,omitempty"`
+	Failed   int                        `json:"failed"`
+	Failures []SearchIndexImportFailure `json:"failures,omitempty"`
+}
+
+// SearchIndexImportFailure is a line of an export file that was not imported
+type SearchIndexImportFailure struct {
+	Line  int    `json:"line"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// SearchIndexUserCount is the number of indexed documents of one library
+type SearchIndexUserCount struct {
+	UserID    string `json:"userId"`
+	Documents int    `json:"documents"`
+}
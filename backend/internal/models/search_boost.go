@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// EntitySearchBoosts represents the entity type for a user's search boost rules
+const EntitySearchBoosts EntityType = "SEARCH_BOOSTS"
+
+// Search boost limits
+const (
+	MaxSearchPins        = 100
+	MaxArtistBoosts      = 50
+	MinArtistBoostWeight = 0.1
+	MaxArtistBoostWeight = 10
+)
+
+// SearchPin puts a track at the top of the results for one query
+type SearchPin struct {
+	// Query is normalized with NormalizeSearchQuery
+	Query     string    `json:"query" dynamodbav:"query"`
+	TrackID   string    `json:"trackId" dynamodbav:"trackId"`
+	CreatedAt time.Time `json:"createdAt" dynamodbav:"createdAt"`
+}
+
+// ArtistBoost scales the relevance score of an artist's tracks in the user's
+// own searches; weights below 1 demote them
+type ArtistBoost struct {
+	Artist string  `json:"artist" dynamodbav:"artist"`
+	Weight float64 `json:"weight" dynamodbav:"weight"`
+}
+
+// SearchBoosts holds a user's personal ranking rules, applied by the search
+// service after the index has answered
+type SearchBoosts struct {
+	UserID string `json:"userId" dynamodbav:"userId"`
+	// Pins are newest first; a query's pins are listed in that order
+	Pins         []SearchPin   `json:"pins" dynamodbav:"pins"`
+	ArtistBoosts []ArtistBoost `json:"artistBoosts" dynamodbav:"artistBoosts"`
+	UpdatedAt    time.Time     `json:"updatedAt" dynamodbav:"updatedAt"`
+}
+
+// NormalizeSearchQuery lowercases a query and collapses its whitespace, so
+// pins match however the query is typed
+func NormalizeSearchQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// PinnedTrackIDs returns the tracks pinned for query, most recently pinned first
+func (b *SearchBoosts) PinnedTrackIDs(query string) []string {
+	query = NormalizeSearchQuery(query)
+	var trackIDs []string
+	for _, pin := range b.Pins {
+		if pin.Query == query {
+			trackIDs = append(trackIDs, pin.TrackID)
+		}
+	}
+	return trackIDs
+}
+
+// ArtistWeight returns the boost weight of artist (ignoring case), or 1
+func (b *SearchBoosts) ArtistWeight(artist string) float64 {
+	for _, boost := range b.ArtistBoosts {
+		if strings.EqualFold(boost.Artist, artist) {
+			return boost.Weight
+		}
+	}
+	return 1
+}
+
+// SearchBoostsItem represents SearchBoosts in DynamoDB single-table design
+// PK: USER#{userId}, SK: SEARCHBOOSTS
+type SearchBoostsItem struct {
+	DynamoDBItem
+	SearchBoosts
+}
+
+// SearchBoostsSK is the sort key of a user's search boosts
+const SearchBoostsSK = "SEARCHBOOSTS"
+
+// NewSearchBoostsItem creates a DynamoDB item for search boosts
+func NewSearchBoostsItem(boosts SearchBoosts) SearchBoostsItem {
+	return SearchBoostsItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   "USER#" + boosts.UserID,
+			SK:   SearchBoostsSK,
+			Type: string(EntitySearchBoosts),
+		},
+		SearchBoosts: boosts,
+	}
+}
+
+// PinSearchResultRequest pins a track to the top of the results for a query
+type PinSearchResultRequest struct {
+	Query   string `json:"query" validate:"required,max=500"`
+	TrackID string `json:"trackId" validate:"required"`
+}
+
+// SetArtistBoostRequest boosts (weight above 1) or demotes (below 1) an artist
+type SetArtistBoostRequest struct {
+	Artist string  `json:"artist" validate:"required,max=200"`
+	Weight float64 `json:"weight" validate:"required,min=0.1,max=10"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSearchQuery(t *testing.T) {
+	assert.Equal(t, "aphex twin", NormalizeSearchQuery("  Aphex   TWIN "))
+	assert.Equal(t, "", NormalizeSearchQuery("   "))
+}
+
+func TestSearchBoosts(t *testing.T) {
+	boosts := SearchBoosts{
+		UserID: "u1",
+		Pins: []SearchPin{
+			{Query: "burial", TrackID: "t2"},
+			{Query: "untrue", TrackID: "t3"},
+			{Query: "burial", TrackID: "t1"},
+		},
+		ArtistBoosts: []ArtistBoost{{Artist: "Burial", Weight: 2}},
+	}
+
+	t.Run("pinned tracks for a query", func(t *testing.T) {
+		assert.Equal(t, []string{"t2", "t1"}, boosts.PinnedTrackIDs(" BURIAL "))
+		assert.Empty(t, boosts.PinnedTrackIDs("archangel"))
+	})
+
+	t.Run("artist weight", func(t *testing.T) {
+		assert.Equal(t, 2.0, boosts.ArtistWeight("burial"))
+		assert.Equal(t, 1.0, boosts.ArtistWeight("Kode9"))
+	})
+}
+
+func TestNewSearchBoostsItem(t *testing.T) {
+	item := NewSearchBoostsItem(SearchBoosts{UserID: "u1"})
+	assert.Equal(t, "USER#u1", item.PK)
+	assert.Equal(t, "SEARCHBOOSTS", item.SK)
+	assert.Equal(t, "SEARCH_BOOSTS", item.Type)
+}
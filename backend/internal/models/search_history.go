@@ -0,0 +1,76 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// EntitySearchHistory represents the entity type for a user's recent searches
+const EntitySearchHistory EntityType = "SEARCH_HISTORY"
+
+// MaxRecentSearches bounds the recent searches kept per user
+const MaxRecentSearches = 20
+
+// RecentSearch is one query from a user's search history
+type RecentSearch struct {
+	Query      string    `json:"query" dynamodbav:"query"`
+	SearchedAt time.Time `json:"searchedAt" dynamodbav:"searchedAt"`
+}
+
+// SearchHistory holds a user's recent searches, newest first. Repeating a
+// query moves it to the front instead of adding it twice.
+type SearchHistory struct {
+	UserID    string         `json:"userId" dynamodbav:"userId"`
+	Searches  []RecentSearch `json:"searches" dynamodbav:"searches"`
+	UpdatedAt time.Time      `json:"updatedAt" dynamodbav:"updatedAt"`
+}
+
+// Add records query as searched at, dropping an earlier copy that differs only
+// in case or surrounding spaces and the oldest searches beyond
+// MaxRecentSearches. Blank queries are ignored.
+func (h *SearchHistory) Add(query string, at time.Time) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return
+	}
+
+	searches := make([]RecentSearch, 0, len(h.Searches)+1)
+	searches = append(searches, RecentSearch{Query: query, SearchedAt: at})
+	for _, search := range h.Searches {
+		if len(searches) == MaxRecentSearches {
+			break
+		}
+		if !strings.EqualFold(search.Query, query) {
+			searches = append(searches, search)
+		}
+	}
+	h.Searches = searches
+	h.UpdatedAt = at
+}
+
+// SearchHistoryItem represents a SearchHistory in DynamoDB single-table design
+// PK: USER#{userId}, SK: SEARCHHISTORY
+type SearchHistoryItem struct {
+	DynamoDBItem
+	SearchHistory
+}
+
+// SearchHistorySK is the sort key of a user's search history
+const SearchHistorySK = "SEARCHHISTORY"
+
+// NewSearchHistoryItem creates a DynamoDB item for a search history
+func NewSearchHistoryItem(history SearchHistory) SearchHistoryItem {
+	return SearchHistoryItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   "USER#" + history.UserID,
+			SK:   SearchHistorySK,
+			Type: string(EntitySearchHistory),
+		},
+		SearchHistory: history,
+	}
+}
+
+// RecentSearchesResponse lists a user's recent searches, newest first
+type RecentSearchesResponse struct {
+	Items []RecentSearch `json:"items"`
+}
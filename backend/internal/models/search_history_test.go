@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchHistory_Add(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("newest first with duplicates moved to the front", func(t *testing.T) {
+		history := SearchHistory{UserID: "u1"}
+		history.Add("burial", start)
+		history.Add("aphex twin", start.Add(time.Minute))
+		history.Add("  Burial ", start.Add(2*time.Minute))
+
+		assert.Equal(t, []RecentSearch{
+			{Query: "Burial", SearchedAt: start.Add(2 * time.Minute)},
+			{Query: "aphex twin", SearchedAt: start.Add(time.Minute)},
+		}, history.Searches)
+		assert.Equal(t, start.Add(2*time.Minute), history.UpdatedAt)
+	})
+
+	t.Run("ignores blank queries", func(t *testing.T) {
+		history := SearchHistory{UserID: "u1"}
+		history.Add("   ", start)
+		assert.Empty(t, history.Searches)
+		assert.True(t, history.UpdatedAt.IsZero())
+	})
+
+	t.Run("keeps the most recent searches", func(t *testing.T) {
+		history := SearchHistory{UserID: "u1"}
+		for i := 0; i < MaxRecentSearches+5; i++ {
+			history.Add(fmt.Sprintf("query %d", i), start.Add(time.Duration(i)*time.Minute))
+		}
+		require.Len(t, history.Searches, MaxRecentSearches)
+		assert.Equal(t, fmt.Sprintf("query %d", MaxRecentSearches+4), history.Searches[0].Query)
+		assert.Equal(t, "query 5", history.Searches[MaxRecentSearches-1].Query)
+	})
+}
+
+func TestNewSearchHistoryItem(t *testing.T) {
+	item := NewSearchHistoryItem(SearchHistory{UserID: "u1"})
+	assert.Equal(t, "USER#u1", item.PK)
+	assert.Equal(t, "SEARCHHISTORY", item.SK)
+	assert.Equal(t, "SEARCH_HISTORY", item.Type)
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntityTrackShare represents the entity type for cross-user track shares
+const EntityTrackShare EntityType = "TRACK_SHARE"
+
+// ShareStatus represents the lifecycle state of a track share
+type ShareStatus string
+
+const (
+	ShareStatusPending  ShareStatus = "PENDING"
+	ShareStatusAccepted ShareStatus = "ACCEPTED"
+	ShareStatusDeclined ShareStatus = "DECLINED"
+)
+
+// TrackShare represents a track offered by one user to another.
+// The recipient owns the share record; accepting it copies the track into their library.
+type TrackShare struct {
+	ID              string      `json:"id" dynamodbav:"id"`
+	TrackID         string      `json:"trackId" dynamodbav:"trackId"`
+	OwnerID         string      `json:"ownerId" dynamodbav:"ownerId"`
+	RecipientID     string      `json:"recipientId" dynamodbav:"recipientId"`
+	RecipientEmail  string      `json:"recipientEmail" dynamodbav:"recipientEmail"`
+	TrackTitle      string      `json:"trackTitle" dynamodbav:"trackTitle"`
+	TrackArtist     string      `json:"trackArtist,omitempty" dynamodbav:"trackArtist,omitempty"`
+	FileSize        int64       `json:"fileSize" dynamodbav:"fileSize"`
+	Message         string      `json:"message,omitempty" dynamodbav:"message,omitempty"`
+	Status          ShareStatus `json:"status" dynamodbav:"status"`
+	AcceptedTrackID string      `json:"acceptedTrackId,omitempty" dynamodbav:"acceptedTrackId,omitempty"` // Track created in the recipient's library
+	RespondedAt     *time.Time  `json:"respondedAt,omitempty" dynamodbav:"respondedAt,omitempty"`
+	Timestamps
+}
+
+// TrackShareItem represents a TrackShare in DynamoDB single-table design
+type TrackShareItem struct {
+	DynamoDBItem
+	TrackShare
+}
+
+// NewTrackShareItem creates a DynamoDB item for a track share.
+// Primary key pattern: PK=USER#{recipientID}, SK=SHARE#{shareID}
+// GSI1 pattern: GSI1PK=SHARES_SENT#{ownerID}, GSI1SK=SHARE#{createdAt}#{shareID}
+func NewTrackShareItem(share TrackShare) TrackShareItem {
+	return TrackShareItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:     fmt.Sprintf("USER#%s", share.RecipientID),
+			SK:     GetTrackShareSK(share.ID),
+			GSI1PK: GetSharesSentGSI1PK(share.OwnerID),
+			GSI1SK: fmt.Sprintf("SHARE#%s#%s", share.CreatedAt.Format(time.RFC3339), share.ID),
+			Type:   string(EntityTrackShare),
+		},
+		TrackShare: share,
+	}
+}
+
+// GetTrackShareSK returns the sort key for a track share.
+func GetTrackShareSK(shareID string) string {
+	return fmt.Sprintf("SHARE#%s", shareID)
+}
+
+// GetSharesSentGSI1PK returns the GSI1 partition key for querying shares sent by a user.
+func GetSharesSentGSI1PK(ownerID string) string {
+	return fmt.Sprintf("SHARES_SENT#%s", ownerID)
+}
+
+// IsPending returns true if the recipient has not yet responded to the share.
+func (s *TrackShare) IsPending() bool {
+	return s.Status == ShareStatusPending
+}
+
+// TrackOrigin records where a track copied in from another user's library came from.
+type TrackOrigin struct {
+	ShareID  string    `json:"shareId" dynamodbav:"shareId"`
+	OwnerID  string    `json:"ownerId" dynamodbav:"ownerId"`
+	TrackID  string    `json:"trackId" dynamodbav:"trackId"`
+	SharedAt time.Time `json:"sharedAt" dynamodbav:"sharedAt"`
+}
+
+// CreateTrackShareRequest represents a request to share a track with another user
+type CreateTrackShareRequest struct {
+	RecipientEmail string `json:"recipientEmail" validate:"required,email"`
+	Message        string `json:"message,omitempty" validate:"max=500"`
+}
+
+// TrackShareFilter represents filter options for listing shares
+type TrackShareFilter struct {
+	Status ShareStatus `query:"status"`
+	Limit  int         `query:"limit"`
+	Cursor string      `query:"cursor"`
+}
+
+// TrackShareResponse represents a track share in API responses
+type TrackShareResponse struct {
+	ID              string     `json:"id"`
+	TrackID         string     `json:"trackId"`
+	OwnerID         string     `json:"ownerId"`
+	RecipientID     string     `json:"recipientId"`
+	RecipientEmail  string     `json:"recipientEmail"`
+	TrackTitle      string     `json:"trackTitle"`
+	TrackArtist     string     `json:"trackArtist,omitempty"`
+	FileSize        int64      `json:"fileSize"`
+	FileSizeStr     string     `json:"fileSizeStr"`
+	Message         string     `json:"message,omitempty"`
+	Status          string     `json:"status"`
+	AcceptedTrackID string     `json:"acceptedTrackId,omitempty"`
+	RespondedAt     *time.Time `json:"respondedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+// ToResponse converts a TrackShare to a TrackShareResponse
+func (s *TrackShare) ToResponse() TrackShareResponse {
+	return TrackShareResponse{
+		ID:              s.ID,
+		TrackID:         s.TrackID,
+		OwnerID:         s.OwnerID,
+		RecipientID:     s.RecipientID,
+		RecipientEmail:  s.RecipientEmail,
+		TrackTitle:      s.TrackTitle,
+		TrackArtist:     s.TrackArtist,
+		FileSize:        s.FileSize,
+		FileSizeStr:     formatFileSize(s.FileSize),
+		Message:         s.Message,
+		Status:          string(s.Status),
+		AcceptedTrackID: s.AcceptedTrackID,
+		RespondedAt:     s.RespondedAt,
+		CreatedAt:       s.CreatedAt,
+	}
+}
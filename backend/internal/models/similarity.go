@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntityTrackNeighbors represents the entity type for cached track neighbors
+const EntityTrackNeighbors EntityType = "TRACK_NEIGHBORS"
+
+// TrackNeighborsTTL is how long a neighbor list is served before DynamoDB
+// expires it and the next request recomputes it, so lists pick up tracks added
+// since they were computed at least daily.
+const TrackNeighborsTTL = 24 * time.Hour
+
+// MaxTrackNeighbors is the number of similar and of mixable tracks cached per track
+const MaxTrackNeighbors = 50
+
+// Neighbor is one cached candidate and its score against the source track
+type Neighbor struct {
+	TrackID string  `json:"trackId" dynamodbav:"trackId"`
+	Score   float64 `json:"score" dynamodbav:"score"`
+}
+
+// TrackNeighbors is the precomputed list of a track's best similar and
+// mixable tracks, highest score first. Similar holds combined-mode scores;
+// Mixable holds mix scores under the default harmonic key mode and BPM
+// tolerance. A Complete flag means every qualifying track is listed rather
+// than only the best MaxTrackNeighbors.
+type TrackNeighbors struct {
+	UserID          string     `json:"userId" dynamodbav:"userId"`
+	TrackID         string     `json:"trackId" dynamodbav:"trackId"`
+	Similar         []Neighbor `json:"similar" dynamodbav:"similar"`
+	SimilarComplete bool       `json:"similarComplete" dynamodbav:"similarComplete"`
+	Mixable         []Neighbor `json:"mixable" dynamodbav:"mixable"`
+	MixableComplete bool       `json:"mixableComplete" dynamodbav:"mixableComplete"`
+	ComputedAt      time.Time  `json:"computedAt" dynamodbav:"computedAt"`
+}
+
+// IsFresh reports whether the list was computed after the source track last
+// changed and is younger than TrackNeighborsTTL
+func (n *TrackNeighbors) IsFresh(source *Track, now time.Time) bool {
+	return !n.ComputedAt.Before(source.UpdatedAt) && now.Sub(n.ComputedAt) < TrackNeighborsTTL
+}
+
+// TrackNeighborsItem represents TrackNeighbors in DynamoDB single-table design
+type TrackNeighborsItem struct {
+	DynamoDBItem
+	TrackNeighbors
+	ExpiresAt int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewTrackNeighborsItem creates a DynamoDB item for a track's neighbors.
+// Primary key pattern: PK=USER#{userID}, SK=NEIGHBORS#{trackID}
+func NewTrackNeighborsItem(neighbors TrackNeighbors) TrackNeighborsItem {
+	return TrackNeighborsItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   fmt.Sprintf("USER#%s", neighbors.UserID),
+			SK:   GetTrackNeighborsSK(neighbors.TrackID),
+			Type: string(EntityTrackNeighbors),
+		},
+		TrackNeighbors: neighbors,
+		ExpiresAt:      neighbors.ComputedAt.Add(TrackNeighborsTTL).Unix(),
+	}
+}
+
+// GetTrackNeighborsSK returns the sort key of a track's cached neighbors
+func GetTrackNeighborsSK(trackID string) string {
+	return fmt.Sprintf("NEIGHBORS#%s", trackID)
+}
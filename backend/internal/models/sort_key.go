@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
+)
+
+// Alphabetical browse (GSI5) keeps a user's tracks and albums by title and
+// artists by sort name, in the collation order of the owner's sort locale:
+// GSI5PK = "USER#{userId}#SORT#{entity}", GSI5SK = "{collation key}#{id}".
+// Each item records the locale its key was built with (sortLocale), so edits
+// rebuild the key in the same order until the owner changes locale.
+
+// GetSortGSI5PK returns the GSI5 partition holding a user's items of one entity
+func GetSortGSI5PK(userID string, entity EntityType) string {
+	return fmt.Sprintf("USER#%s#SORT#%s", userID, entity)
+}
+
+// GetSortGSI5SK returns the GSI5 sort key of an item sorted by text. The ID
+// keeps keys of equal texts unique and orders them.
+func GetSortGSI5SK(locale, text, id string) string {
+	return collation.Key(locale, text) + "#" + id
+}
+
+// SortableItem is the part of a track, album or artist item its sort key is
+// built from, read and written without touching the rest of the item
+type SortableItem struct {
+	PK         string    `dynamodbav:"PK"`
+	SK         string    `dynamodbav:"SK"`
+	Type       string    `dynamodbav:"Type"`
+	ID         string    `dynamodbav:"id"`
+	UserID     string    `dynamodbav:"userId"`
+	Title      string    `dynamodbav:"title,omitempty"`
+	Name       string    `dynamodbav:"name,omitempty"`
+	SortName   string    `dynamodbav:"sortName,omitempty"`
+	SortLocale string    `dynamodbav:"sortLocale,omitempty"`
+	GSI5PK     string    `dynamodbav:"GSI5PK,omitempty"`
+	GSI5SK     string    `dynamodbav:"GSI5SK,omitempty"`
+	UpdatedAt  time.Time `dynamodbav:"updatedAt"`
+}
+
+// SortText is the text the item sorts by: an artist's sort name (or name),
+// otherwise the title
+func (i SortableItem) SortText() string {
+	if EntityType(i.Type) == EntityArtist {
+		return artistSortText(i.Name, i.SortName)
+	}
+	return i.Title
+}
+
+// WithSortLocale returns the item with its sort key built in locale
+func (i SortableItem) WithSortLocale(locale string) SortableItem {
+	i.SortLocale = locale
+	i.GSI5PK = GetSortGSI5PK(i.UserID, EntityType(i.Type))
+	i.GSI5SK = GetSortGSI5SK(locale, i.SortText(), i.ID)
+	return i
+}
+
+// SortKeyCurrent reports whether the item's sort key is the one locale gives it
+func (i SortableItem) SortKeyCurrent(locale string) bool {
+	want := i.WithSortLocale(locale)
+	return i.SortLocale == locale && i.GSI5PK == want.GSI5PK && i.GSI5SK == want.GSI5SK
+}
+
+func artistSortText(name, sortName string) string {
+	if sortName != "" {
+		return sortName
+	}
+	return name
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortKeys(t *testing.T) {
+	t.Run("items sort by title in their locale", func(t *testing.T) {
+		edith := NewTrackItem(Track{ID: "t1", UserID: "u1", Title: "Édith"})
+		elvis := NewTrackItem(Track{ID: "t2", UserID: "u1", Title: "Elvis"})
+		zara := NewTrackItem(Track{ID: "t3", UserID: "u1", Title: "Zara"})
+		osten := NewTrackItem(Track{ID: "t4", UserID: "u1", Title: "Östen", SortLocale: "sv"})
+
+		assert.Equal(t, "USER#u1#SORT#TRACK", edith.GSI5PK)
+		assert.Less(t, edith.GSI5SK, elvis.GSI5SK)
+		assert.Less(t, zara.GSI5SK, osten.GSI5SK)
+	})
+
+	t.Run("equal titles are ordered by ID", func(t *testing.T) {
+		a := NewAlbumItem(Album{ID: "a1", UserID: "u1", Title: "Greatest Hits"})
+		b := NewAlbumItem(Album{ID: "a2", UserID: "u1", Title: "Greatest Hits"})
+
+		assert.Equal(t, "USER#u1#SORT#ALBUM", a.GSI5PK)
+		assert.Less(t, a.GSI5SK, b.GSI5SK)
+	})
+
+	t.Run("sortable items rebuild the keys of their entity", func(t *testing.T) {
+		artist := Artist{ID: "ar1", UserID: "u1", Name: "The Beatles", SortName: "Beatles, The", SortLocale: "fr"}
+		item := NewArtistItem(artist)
+		sortable := SortableItem{
+			Type: string(EntityArtist), ID: artist.ID, UserID: artist.UserID,
+			Name: artist.Name, SortName: artist.SortName,
+		}
+
+		rebuilt := sortable.WithSortLocale("fr")
+		assert.Equal(t, item.GSI5PK, rebuilt.GSI5PK)
+		assert.Equal(t, item.GSI5SK, rebuilt.GSI5SK)
+		assert.False(t, sortable.SortKeyCurrent("fr"))
+		assert.True(t, rebuilt.SortKeyCurrent("fr"))
+		assert.False(t, rebuilt.SortKeyCurrent("sv"))
+
+		track := NewTrackItem(Track{ID: "t1", UserID: "u1", Title: "Östen"})
+		sortable = SortableItem{Type: string(EntityTrack), ID: "t1", UserID: "u1", Title: "Östen"}
+		assert.Equal(t, track.GSI5SK, sortable.WithSortLocale("").GSI5SK)
+	})
+}
+
+func TestUserSettingsValidateSortLocale(t *testing.T) {
+	settings := DefaultUserSettings()
+	settings.Library.SortLocale = "sv"
+	assert.NoError(t, settings.Validate())
+
+	settings.Library.SortLocale = "not a locale"
+	assert.EqualError(t, settings.Validate(), "invalid sortLocale: not a locale")
+}
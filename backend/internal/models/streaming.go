@@ -15,9 +15,12 @@ type StreamResponse struct {
 	HLSURL      string    `json:"hlsUrl,omitempty"`      // HLS adaptive streaming URL
 	FallbackURL string    `json:"fallbackUrl,omitempty"` // Direct audio file URL
 	HLSReady    bool      `json:"hlsReady"`              // Whether HLS is available
+	HLSVariant  string    `json:"hlsVariant,omitempty"`  // Bitrate variant HLSURL is pinned to; empty for the adaptive master playlist
 	ExpiresAt   time.Time `json:"expiresAt"`
 	Format      string    `json:"format"`
 	Bitrate     int       `json:"bitrate,omitempty"`
+	// PlaybackSettings are the gain and EQ corrections the player applies
+	PlaybackSettings *TrackPlaybackSettings `json:"playbackSettings,omitempty"`
 }
 
 // DownloadRequest represents a request for a download URL
@@ -33,6 +36,11 @@ type DownloadResponse struct {
 	FileName    string    `json:"fileName"`
 	FileSize    int64     `json:"fileSize"`
 	Format      string    `json:"format"`
+	// WatermarkToken is set when the track's owner watermarks downloads. The
+	// copy is produced in the background; DownloadURL is empty until it is
+	// ready (WatermarkStatus READY).
+	WatermarkToken  string              `json:"watermarkToken,omitempty"`
+	WatermarkStatus DownloadTokenStatus `json:"watermarkStatus,omitempty"`
 }
 
 // PlaybackEvent represents a playback event for analytics
@@ -52,39 +60,3 @@ type RecordPlayRequest struct {
 	Duration  int    `json:"duration" validate:"required,min=1"` // listened duration in seconds
 	Completed bool   `json:"completed"`
 }
-
-// PlayQueue represents a user's play queue
-type PlayQueue struct {
-	UserID       string   `json:"userId"`
-	CurrentIndex int      `json:"currentIndex"`
-	TrackIDs     []string `json:"trackIds"`
-	ShuffleMode  bool     `json:"shuffleMode"`
-	RepeatMode   string   `json:"repeatMode"` // none, one, all
-	UpdatedAt    time.Time `json:"updatedAt"`
-}
-
-// UpdateQueueRequest represents a request to update the play queue
-type UpdateQueueRequest struct {
-	TrackIDs     []string `json:"trackIds,omitempty"`
-	CurrentIndex *int     `json:"currentIndex,omitempty"`
-	ShuffleMode  *bool    `json:"shuffleMode,omitempty"`
-	RepeatMode   *string  `json:"repeatMode,omitempty"`
-}
-
-// QueueAction represents an action on the play queue
-type QueueAction string
-
-const (
-	QueueActionAddNext   QueueAction = "add_next"
-	QueueActionAddLast   QueueAction = "add_last"
-	QueueActionRemove    QueueAction = "remove"
-	QueueActionClear     QueueAction = "clear"
-	QueueActionShuffle   QueueAction = "shuffle"
-	QueueActionUnshuffle QueueAction = "unshuffle"
-)
-
-// QueueActionRequest represents a request to perform a queue action
-type QueueActionRequest struct {
-	Action   QueueAction `json:"action" validate:"required"`
-	TrackIDs []string    `json:"trackIds,omitempty" validate:"omitempty,dive,uuid"`
-}
@@ -97,6 +97,15 @@ func (t *Tag) ToResponse() TagResponse {
 	}
 }
 
+// DeleteTagResult reports the tag a delete removes
+type DeleteTagResult struct {
+	Name string `json:"name"`
+	// TrackCount is how many tracks carried the tag
+	TrackCount int `json:"trackCount"`
+	// DryRun is set when nothing was deleted; the result is what a real run does
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
 // TagFilter represents filter options for listing tags
 type TagFilter struct {
 	SortBy    string `query:"sortBy"`    // name, trackCount, createdAt
@@ -3,6 +3,8 @@ package models
 import (
 	"fmt"
 	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
 )
 
 // HLSStatus represents the transcoding status for HLS streaming
@@ -15,6 +17,11 @@ const (
 	HLSStatusFailed     HLSStatus = "FAILED"
 )
 
+// InProgress reports whether the transcode is pending or running
+func (s HLSStatus) InProgress() bool {
+	return s == HLSStatusPending || s == HLSStatusProcessing
+}
+
 // Track represents a music track in the library
 type Track struct {
 	ID          string      `json:"id" dynamodbav:"id"`
@@ -38,25 +45,48 @@ type Track struct {
 	Channels    int         `json:"channels,omitempty" dynamodbav:"channels,omitempty"`
 	FileSize    int64       `json:"fileSize" dynamodbav:"fileSize"` // bytes
 	S3Key       string      `json:"s3Key" dynamodbav:"s3Key"`
+	ContentHash string      `json:"-" dynamodbav:"contentHash,omitempty"` // SHA-256 of the audio file, hex encoded
 	CoverArtKey string      `json:"coverArtKey,omitempty" dynamodbav:"coverArtKey,omitempty"`
+	Artwork     []Artwork   `json:"artwork,omitempty" dynamodbav:"artwork,omitempty"` // Embedded images other than the cover
+	CoverStyle  *CoverStyle `json:"coverStyle,omitempty" dynamodbav:"coverStyle,omitempty"` // Colors and placeholder of the cover art
+	Chapters    []Chapter   `json:"chapters,omitempty" dynamodbav:"chapters,omitempty"`
 	Lyrics      string      `json:"lyrics,omitempty" dynamodbav:"lyrics,omitempty"`
 	Comment     string      `json:"comment,omitempty" dynamodbav:"comment,omitempty"`
 	Composer    string      `json:"composer,omitempty" dynamodbav:"composer,omitempty"`
 	PlayCount   int         `json:"playCount" dynamodbav:"playCount"`
 	LastPlayed  *time.Time  `json:"lastPlayed,omitempty" dynamodbav:"lastPlayed,omitempty"`
 	Tags        []string    `json:"tags,omitempty" dynamodbav:"tags,omitempty"`
+	Rating      int         `json:"rating,omitempty" dynamodbav:"rating,omitempty"` // Stars (0-5), e.g. imported from DJ software
+	SortLocale  string      `json:"-" dynamodbav:"sortLocale,omitempty"`          // Locale of the title sort key (GSI5); empty for the root collation
 
 	// Audio analysis fields
 	BPM         int    `json:"bpm,omitempty" dynamodbav:"bpm,omitempty"`                 // Beats per minute (20-300)
 	MusicalKey  string `json:"musicalKey,omitempty" dynamodbav:"musicalKey,omitempty"`   // e.g., "Am", "C", "F#m"
 	KeyMode     string `json:"keyMode,omitempty" dynamodbav:"keyMode,omitempty"`         // "major" or "minor"
 	KeyCamelot  string `json:"keyCamelot,omitempty" dynamodbav:"keyCamelot,omitempty"`   // e.g., "8A", "11B"
+	// Set when the owner corrected the value; reanalysis leaves it unchanged
+	BPMOverridden bool `json:"bpmOverridden,omitempty" dynamodbav:"bpmOverridden,omitempty"`
+	KeyOverridden bool `json:"keyOverridden,omitempty" dynamodbav:"keyOverridden,omitempty"`
+	// Analyzer confidence in the key (0-1) and the runner-up keys it considered
+	KeyConfidence float64        `json:"keyConfidence,omitempty" dynamodbav:"keyConfidence,omitempty"`
+	KeyCandidates []KeyCandidate `json:"keyCandidates,omitempty" dynamodbav:"keyCandidates,omitempty"`
 
 	// HLS streaming fields
 	HLSStatus        HLSStatus `json:"hlsStatus,omitempty" dynamodbav:"hlsStatus,omitempty"`
 	HLSPlaylistKey   string    `json:"hlsPlaylistKey,omitempty" dynamodbav:"hlsPlaylistKey,omitempty"` // S3 key to master.m3u8
 	HLSJobID         string    `json:"hlsJobId,omitempty" dynamodbav:"hlsJobId,omitempty"`             // MediaConvert job ID
 	HLSTranscodedAt  *time.Time `json:"hlsTranscodedAt,omitempty" dynamodbav:"hlsTranscodedAt,omitempty"`
+	// HLSFailures counts consecutive failed transcodes; cleared when one succeeds
+	HLSFailures int `json:"-" dynamodbav:"hlsFailures,omitempty"`
+	// HLSRetries counts fallback jobs started since the track's last transcode
+	// began; cleared when the next one starts
+	HLSRetries int `json:"-" dynamodbav:"hlsRetries,omitempty"`
+
+	// Preview clip fields - public tracks get a short MP3 clip for discovery pages
+	PreviewStart  int       `json:"previewStart,omitempty" dynamodbav:"previewStart,omitempty"`   // Second the loudest stretch starts at (analyzer)
+	PreviewStatus HLSStatus `json:"previewStatus,omitempty" dynamodbav:"previewStatus,omitempty"` // Transcode status of the clip
+	PreviewKey    string    `json:"-" dynamodbav:"previewKey,omitempty"`                          // S3 key of the clip
+	PreviewJobID  string    `json:"-" dynamodbav:"previewJobId,omitempty"`                        // MediaConvert job ID
 
 	// DJ features
 	HotCues map[int]*HotCue `json:"hotCues,omitempty" dynamodbav:"hotCues,omitempty"` // Slot (1-8) -> HotCue
@@ -71,6 +101,28 @@ type Track struct {
 	Visibility  TrackVisibility `json:"visibility" dynamodbav:"Visibility"`                   // private, unlisted, public
 	PublishedAt *time.Time      `json:"publishedAt,omitempty" dynamodbav:"PublishedAt,omitempty"` // When track was made public
 
+	// Moderation fields - a change to public visibility is held here until the moderation check passes
+	PendingVisibility TrackVisibility  `json:"pendingVisibility,omitempty" dynamodbav:"pendingVisibility,omitempty"`
+	ModerationStatus  ModerationStatus `json:"moderationStatus,omitempty" dynamodbav:"moderationStatus,omitempty"`
+
+	// Lock fields - a locked track rejects metadata edits, deletion and bulk operations
+	Locked   bool       `json:"locked" dynamodbav:"locked,omitempty"`
+	LockedAt *time.Time `json:"lockedAt,omitempty" dynamodbav:"lockedAt,omitempty"`
+
+	// File replacement fields (track quality upgrade)
+	FileReplacedAt   *time.Time `json:"fileReplacedAt,omitempty" dynamodbav:"fileReplacedAt,omitempty"`     // When the audio file was last replaced
+	ArchivedS3Key    string     `json:"-" dynamodbav:"archivedS3Key,omitempty"`                             // Previous audio file, kept for the grace period
+	ArchiveExpiresAt *time.Time `json:"-" dynamodbav:"archiveExpiresAt,omitempty"`                          // When the archived file is removed
+
+	// Origin is set on tracks copied in from another user's share
+	Origin *TrackOrigin `json:"origin,omitempty" dynamodbav:"origin,omitempty"`
+
+	// PlaybackSettings are the owner's gain and EQ corrections; nil plays the file as is
+	PlaybackSettings *TrackPlaybackSettings `json:"playbackSettings,omitempty" dynamodbav:"playbackSettings,omitempty"`
+
+	// DownloadWatermark marks other users' downloads of the track with a traceable token
+	DownloadWatermark WatermarkMode `json:"downloadWatermark,omitempty" dynamodbav:"downloadWatermark,omitempty"`
+
 	// For API responses when admin/global views all tracks (not stored in DynamoDB)
 	OwnerDisplayName string `json:"ownerDisplayName,omitempty" dynamodbav:"-"`
 
@@ -83,6 +135,42 @@ type TrackItem struct {
 	Track
 }
 
+// BPMBucketWidth is the width in BPM of the tempo bands tracks are indexed by
+const BPMBucketWidth = 4
+
+// MaxBPMBucket is the highest tempo band a track can be indexed in
+const MaxBPMBucket = 999
+
+// BPMBucket returns the tempo band of a BPM, or -1 when the BPM is unknown
+func BPMBucket(bpm int) int {
+	if bpm <= 0 {
+		return -1
+	}
+	if bpm/BPMBucketWidth > MaxBPMBucket {
+		return MaxBPMBucket
+	}
+	return bpm / BPMBucketWidth
+}
+
+// GetHarmonicGSI4PK returns the GSI4 partition holding a user's tracks in one
+// Camelot key; tracks with no detected key share the NONE partition
+func GetHarmonicGSI4PK(userID, camelotKey string) string {
+	if camelotKey == "" {
+		camelotKey = "NONE"
+	}
+	return fmt.Sprintf("USER#%s#KEY#%s", userID, camelotKey)
+}
+
+// GetBPMBucketSK returns the GSI4 sort key prefix of a tempo band. Unknown
+// tempos sort before every band, so a range of bands starting at -1 includes
+// them.
+func GetBPMBucketSK(bucket int) string {
+	if bucket < 0 {
+		return "BPM#---"
+	}
+	return fmt.Sprintf("BPM#%03d", bucket)
+}
+
 // NewTrackItem creates a DynamoDB item for a track
 func NewTrackItem(track Track) TrackItem {
 	item := TrackItem{
@@ -100,6 +188,10 @@ func NewTrackItem(track Track) TrackItem {
 		item.GSI1SK = fmt.Sprintf("TRACK#%s", track.ID)
 	}
 
+	// Set GSI4 for harmonic neighbor queries by key and tempo
+	item.GSI4PK = GetHarmonicGSI4PK(track.UserID, track.KeyCamelot)
+	item.GSI4SK = fmt.Sprintf("%s#TRACK#%s", GetBPMBucketSK(BPMBucket(track.BPM)), track.ID)
+
 	// Set GSI3 for public track discovery (only when visibility is public)
 	if track.Visibility == VisibilityPublic {
 		item.GSI3PK = "PUBLIC_TRACK"
@@ -107,6 +199,10 @@ func NewTrackItem(track Track) TrackItem {
 		item.GSI3SK = fmt.Sprintf("%s#%s", track.CreatedAt.Format("2006-01-02T15:04:05Z"), track.ID)
 	}
 
+	// Set GSI5 for alphabetical browse by title
+	item.GSI5PK = GetSortGSI5PK(track.UserID, EntityTrack)
+	item.GSI5SK = GetSortGSI5SK(track.SortLocale, track.Title, track.ID)
+
 	return item
 }
 
@@ -143,6 +239,11 @@ type UpdateTrackVisibilityRequest struct {
 	Visibility string `json:"visibility" validate:"required,oneof=private unlisted public"`
 }
 
+// UpdateTrackLockRequest represents a request to lock or unlock a track
+type UpdateTrackLockRequest struct {
+	Locked *bool `json:"locked" validate:"required"`
+}
+
 // TrackResponse represents a track in API responses
 type TrackResponse struct {
 	ID           string                `json:"id"`
@@ -163,13 +264,21 @@ type TrackResponse struct {
 	FileSize     int64     `json:"fileSize"`
 	FileSizeStr  string    `json:"fileSizeStr"`
 	CoverArtURL  string    `json:"coverArtUrl,omitempty"`
+	CoverStyle   *CoverStyle `json:"coverStyle,omitempty"`
+	Artwork      []ArtworkResponse `json:"artwork,omitempty"` // Populated for single-track views
+	Chapters     []Chapter `json:"chapters,omitempty"`
 	PlayCount    int       `json:"playCount"`
 	LastPlayed   *time.Time `json:"lastPlayed,omitempty"`
 	Tags         []string  `json:"tags"`
+	Rating       int       `json:"rating,omitempty"`
 	BPM          int       `json:"bpm,omitempty"`
 	MusicalKey   string    `json:"musicalKey,omitempty"`
 	KeyMode      string    `json:"keyMode,omitempty"`
 	KeyCamelot   string    `json:"keyCamelot,omitempty"`
+	BPMOverridden bool     `json:"bpmOverridden,omitempty"`
+	KeyOverridden bool     `json:"keyOverridden,omitempty"`
+	KeyConfidence float64  `json:"keyConfidence,omitempty"`
+	KeyCandidates []KeyCandidate `json:"keyCandidates,omitempty"`
 	HLSStatus      string     `json:"hlsStatus,omitempty"`
 	HLSReady       bool       `json:"hlsReady"`
 	WaveformURL    string     `json:"waveformUrl,omitempty"`
@@ -178,7 +287,16 @@ type TrackResponse struct {
 	// Visibility fields
 	Visibility       string     `json:"visibility"`
 	PublishedAt      *time.Time `json:"publishedAt,omitempty"`
+	PendingVisibility string    `json:"pendingVisibility,omitempty"`
+	ModerationStatus  string    `json:"moderationStatus,omitempty"`
 	OwnerDisplayName string     `json:"ownerDisplayName,omitempty"` // Populated for admin/global views
+	FileReplacedAt   *time.Time `json:"fileReplacedAt,omitempty"`
+	Locked           bool       `json:"locked"`
+	LockedAt         *time.Time `json:"lockedAt,omitempty"`
+	Origin           *TrackOrigin `json:"origin,omitempty"`
+	PlaybackSettings *TrackPlaybackSettings `json:"playbackSettings,omitempty"`
+	Playback         *PlaybackAvailability  `json:"playback,omitempty"` // Set by the services that describe playback
+	DownloadWatermark string            `json:"downloadWatermark,omitempty"`
 	CreatedAt        time.Time  `json:"createdAt"`
 	UpdatedAt        time.Time  `json:"updatedAt"`
 }
@@ -216,13 +334,20 @@ func (t *Track) ToResponse(coverArtURL string) TrackResponse {
 		FileSize:     t.FileSize,
 		FileSizeStr:  formatFileSize(t.FileSize),
 		CoverArtURL:  coverArtURL,
+		CoverStyle:   t.CoverStyle,
+		Chapters:     t.Chapters,
 		PlayCount:    t.PlayCount,
 		LastPlayed:   t.LastPlayed,
 		Tags:         tags,
+		Rating:       t.Rating,
 		BPM:          t.BPM,
 		MusicalKey:   t.MusicalKey,
 		KeyMode:      t.KeyMode,
 		KeyCamelot:   t.KeyCamelot,
+		BPMOverridden: t.BPMOverridden,
+		KeyOverridden: t.KeyOverridden,
+		KeyConfidence: t.KeyConfidence,
+		KeyCandidates: t.KeyCandidates,
 		HLSStatus:      string(t.HLSStatus),
 		HLSReady:       t.HLSStatus == HLSStatusReady,
 		WaveformURL:    t.WaveformURL,
@@ -230,7 +355,15 @@ func (t *Track) ToResponse(coverArtURL string) TrackResponse {
 		AnalyzedAt:     t.AnalyzedAt,
 		Visibility:       visibility,
 		PublishedAt:      t.PublishedAt,
+		PendingVisibility: string(t.PendingVisibility),
+		ModerationStatus:  string(t.ModerationStatus),
 		OwnerDisplayName: t.OwnerDisplayName,
+		FileReplacedAt:   t.FileReplacedAt,
+		Locked:           t.Locked,
+		LockedAt:         t.LockedAt,
+		Origin:           t.Origin,
+		PlaybackSettings: t.PlaybackSettings,
+		DownloadWatermark: string(t.DownloadWatermark),
 		CreatedAt:        t.CreatedAt,
 		UpdatedAt:        t.UpdatedAt,
 	}
@@ -250,7 +383,8 @@ type TrackFilter struct {
 	SortOrder   string   `query:"sortOrder"`   // asc, desc
 	Limit       int      `query:"limit"`
 	LastKey     string   `query:"lastKey"`
-	GlobalScope bool     `query:"-"` // If true, return tracks from all users (requires GLOBAL permission)
+	CountOnly   bool     `query:"countOnly"` // Return only the total, as a CountResponse
+	GlobalScope bool     `query:"-"`         // If true, return tracks from all users (requires GLOBAL permission)
 
 	// Visibility filtering (admin-panel-track-visibility feature)
 	IncludePublic bool   `query:"includePublic"` // Include public tracks from other users
@@ -258,6 +392,46 @@ type TrackFilter struct {
 	Visibility    string `query:"visibility"`    // Filter by visibility: private, unlisted, public
 }
 
+// ReplacedFileGracePeriod is how long the previous audio file of a track is kept
+// in the archive prefix after the file has been replaced.
+const ReplacedFileGracePeriod = 30 * 24 * time.Hour
+
+// ArchivedTrackFileKey returns the S3 key used to archive a replaced audio file.
+// Archived files live under archive/ so an S3 lifecycle rule can expire them.
+func ArchivedTrackFileKey(userID, trackID string, replacedAt time.Time, ext string) string {
+	return fmt.Sprintf("archive/%s/%s/%d%s", userID, trackID, replacedAt.Unix(), ext)
+}
+
+// TrackKeyField names a track attribute that references a single S3 object
+type TrackKeyField string
+
+const (
+	TrackKeyFieldAudio    TrackKeyField = "s3Key"
+	TrackKeyFieldCoverArt TrackKeyField = "coverArtKey"
+)
+
+// ObjectKey returns the S3 key stored in the given field
+func (t *Track) ObjectKey(field TrackKeyField) string {
+	switch field {
+	case TrackKeyFieldAudio:
+		return t.S3Key
+	case TrackKeyFieldCoverArt:
+		return t.CoverArtKey
+	default:
+		return ""
+	}
+}
+
+// SetObjectKey stores an S3 key in the given field
+func (t *Track) SetObjectKey(field TrackKeyField, key string) {
+	switch field {
+	case TrackKeyFieldAudio:
+		t.S3Key = key
+	case TrackKeyFieldCoverArt:
+		t.CoverArtKey = key
+	}
+}
+
 // Track visibility helper methods
 
 // IsPubliclyAccessible returns true if the track can be accessed by non-owners.
@@ -272,6 +446,21 @@ func (t *Track) IsDiscoverable() bool {
 	return t.Visibility.IsDiscoverable()
 }
 
+// DownloadFileName returns the file name a downloaded copy of the track is
+// saved under, such as "Artist - Title.mp3"
+func (t *Track) DownloadFileName() string {
+	return sanitize.FileName(fmt.Sprintf("%s - %s%s", t.Artist, t.Title, t.Format.Extension()))
+}
+
+// EnsureUnlocked returns ErrTrackLocked if the track is locked.
+// Services call this before modifying or deleting a track on behalf of a user.
+func (t *Track) EnsureUnlocked() error {
+	if t.Locked {
+		return ErrTrackLocked
+	}
+	return nil
+}
+
 // GetVisibility returns the track's visibility, defaulting to private if not set.
 func (t *Track) GetVisibility() TrackVisibility {
 	if t.Visibility == "" {
@@ -160,6 +160,36 @@ func TestNewTrackItemWithEmptyArtist(t *testing.T) {
 	assert.Empty(t, item.GSI1SK)
 }
 
+// TestNewTrackItemHarmonicIndex verifies GSI4 keys for key/tempo queries
+func TestNewTrackItemHarmonicIndex(t *testing.T) {
+	track := Track{ID: "track-123", UserID: "user-456", KeyCamelot: "8A", BPM: 128}
+	item := NewTrackItem(track)
+	assert.Equal(t, "USER#user-456#KEY#8A", item.GSI4PK)
+	assert.Equal(t, "BPM#032#TRACK#track-123", item.GSI4SK)
+
+	// Tracks not yet analyzed are still indexed so mixing queries can include them
+	item = NewTrackItem(Track{ID: "track-123", UserID: "user-456"})
+	assert.Equal(t, "USER#user-456#KEY#NONE", item.GSI4PK)
+	assert.Equal(t, "BPM#---#TRACK#track-123", item.GSI4SK)
+	assert.Less(t, GetBPMBucketSK(-1), GetBPMBucketSK(0))
+}
+
+func TestHLSStatusInProgress(t *testing.T) {
+	assert.True(t, HLSStatusPending.InProgress())
+	assert.True(t, HLSStatusProcessing.InProgress())
+	assert.False(t, HLSStatusReady.InProgress())
+	assert.False(t, HLSStatusFailed.InProgress())
+	assert.False(t, HLSStatus("").InProgress())
+}
+
+func TestBPMBucket(t *testing.T) {
+	assert.Equal(t, -1, BPMBucket(0))
+	assert.Equal(t, 32, BPMBucket(128))
+	assert.Equal(t, 32, BPMBucket(131))
+	assert.Equal(t, 33, BPMBucket(132))
+	assert.Equal(t, MaxBPMBucket, BPMBucket(100000))
+}
+
 // TestTrackToResponse verifies API response conversion
 func TestTrackToResponse(t *testing.T) {
 	now := time.Now()
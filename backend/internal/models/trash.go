@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EntityTrashedTrack represents the entity type for a track in the trash
+const EntityTrashedTrack EntityType = "TRASHED_TRACK"
+
+// TrashPrefix is where the files of trashed tracks are kept. The S3 lifecycle
+// rule on the trash/ prefix removes them after a day, well past UndoWindow.
+const TrashPrefix = "trash/"
+
+// TrashKey returns the key a file is kept under while its track is trashed
+func TrashKey(key string) string {
+	return TrashPrefix + key
+}
+
+// TrashedTrack is a deleted track kept for UndoWindow so an undo can restore
+// it as it was. Its files wait under TrashPrefix meanwhile.
+type TrashedTrack struct {
+	Track     Track     `json:"track" dynamodbav:"track"`
+	DeletedAt time.Time `json:"deletedAt" dynamodbav:"deletedAt"`
+	ExpiresAt time.Time `json:"expiresAt" dynamodbav:"expiresAt"`
+}
+
+// MediaKeys returns the keys of the files that move to the trash with the
+// track: the original, the cover and its thumbnail, and other artwork. HLS
+// renditions are not kept; a restored track plays its original until it is
+// transcoded again.
+func (t *Track) MediaKeys() []string {
+	var keys []string
+	if t.S3Key != "" {
+		keys = append(keys, t.S3Key)
+	}
+	if t.CoverArtKey != "" {
+		keys = append(keys, t.CoverArtKey)
+	}
+	if t.CoverStyle != nil && t.CoverStyle.ThumbnailKey != "" {
+		keys = append(keys, t.CoverStyle.ThumbnailKey)
+	}
+	for _, artwork := range t.Artwork {
+		keys = append(keys, artwork.Key)
+	}
+	return keys
+}
+
+// TrashedTrackItem represents a TrashedTrack in DynamoDB single-table design
+type TrashedTrackItem struct {
+	DynamoDBItem
+	TrashedTrack
+	TTL int64 `dynamodbav:"ExpiresAt"` // Unix seconds, read by the table's TTL
+}
+
+// NewTrashedTrackItem creates a DynamoDB item for a trashed track.
+// Primary key pattern: PK=USER#{ownerID}, SK=TRASH#{trackID}
+func NewTrashedTrackItem(trashed TrashedTrack) TrashedTrackItem {
+	return TrashedTrackItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   fmt.Sprintf("USER#%s", trashed.Track.UserID),
+			SK:   GetTrashedTrackSK(trashed.Track.ID),
+			Type: string(EntityTrashedTrack),
+		},
+		TrashedTrack: trashed,
+		TTL:          trashed.ExpiresAt.Unix(),
+	}
+}
+
+// GetTrashedTrackSK returns the sort key of a trashed track
+func GetTrashedTrackSK(trackID string) string {
+	return fmt.Sprintf("TRASH#%s", trackID)
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack_MediaKeys(t *testing.T) {
+	track := Track{
+		S3Key:       "media/u1/t1.flac",
+		CoverArtKey: "covers/u1/t1.jpg",
+		CoverStyle:  &CoverStyle{ThumbnailKey: "covers/u1/t1-thumb.jpg"},
+		Artwork:     []Artwork{{Type: ArtworkBackCover, Key: "artwork/u1/t1-back.jpg"}},
+	}
+
+	assert.Equal(t, []string{
+		"media/u1/t1.flac", "covers/u1/t1.jpg", "covers/u1/t1-thumb.jpg", "artwork/u1/t1-back.jpg",
+	}, track.MediaKeys())
+	assert.Empty(t, (&Track{}).MediaKeys())
+	assert.Equal(t, "trash/media/u1/t1.flac", TrashKey(track.S3Key))
+}
+
+func TestNewTrashedTrackItem(t *testing.T) {
+	expires := time.Date(2026, 10, 1, 12, 15, 0, 0, time.UTC)
+	item := NewTrashedTrackItem(TrashedTrack{Track: Track{ID: "t1", UserID: "u1"}, ExpiresAt: expires})
+
+	assert.Equal(t, "USER#u1", item.PK)
+	assert.Equal(t, "TRASH#t1", item.SK)
+	assert.Equal(t, string(EntityTrashedTrack), item.Type)
+	assert.Equal(t, expires.Unix(), item.TTL)
+}
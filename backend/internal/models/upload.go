@@ -2,7 +2,10 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/i18n"
 )
 
 // Upload represents a file upload and its processing status
@@ -16,6 +19,14 @@ type Upload struct {
 	Status      UploadStatus `json:"status" dynamodbav:"status"`
 	ErrorMsg    string       `json:"errorMsg,omitempty" dynamodbav:"errorMsg,omitempty"`
 	TrackID     string       `json:"trackId,omitempty" dynamodbav:"trackId,omitempty"` // Set after successful processing
+	// FailureReason classifies ErrorMsg for users; set on failed uploads
+	FailureReason UploadFailureReason `json:"failureReason,omitempty" dynamodbav:"failureReason,omitempty"`
+	// Set when the upload replaces the audio file of an existing track
+	ReplaceTrackID string `json:"replaceTrackId,omitempty" dynamodbav:"replaceTrackId,omitempty"`
+	// Set when tags look decoded with the wrong character set; the fixes are
+	// suggestions applied through POST /uploads/:id/encoding-fixes
+	SuspectEncoding bool          `json:"suspectEncoding,omitempty" dynamodbav:"suspectEncoding,omitempty"`
+	EncodingFixes   []EncodingFix `json:"encodingFixes,omitempty" dynamodbav:"encodingFixes,omitempty"`
 	Timestamps
 	CompletedAt *time.Time `json:"completedAt,omitempty" dynamodbav:"completedAt,omitempty"`
 
@@ -53,6 +64,39 @@ func NewUploadItem(upload Upload) UploadItem {
 	}
 }
 
+// QuarantineKey returns the S3 key an upload flagged by the malware scan is
+// moved to. Quarantined files live under quarantine/, outside every prefix the
+// API serves, so they stay available for review without being playable.
+func QuarantineKey(userID, uploadID, fileName string) string {
+	return fmt.Sprintf("quarantine/%s/%s/%s", userID, uploadID, fileName)
+}
+
+// UploadFailureReason identifies why processing an upload failed, in terms
+// users can act on; i18n translates it as "upload.failed.<reason>"
+type UploadFailureReason string
+
+const (
+	UploadFailedMalware     UploadFailureReason = "malware_detected"
+	UploadFailedInvalidFile UploadFailureReason = "invalid_file"
+	UploadFailedTimeout     UploadFailureReason = "timeout"
+	UploadFailedProcessing  UploadFailureReason = "processing_failed"
+)
+
+// ClassifyUploadFailure maps the error a processing step failed with, as the
+// Error and Cause the state machine caught, to the reason shown to users
+func ClassifyUploadFailure(errorName, cause string) UploadFailureReason {
+	switch {
+	case errorName == "MalwareDetected":
+		return UploadFailedMalware
+	case errorName == "States.Timeout" || strings.Contains(cause, "Task timed out"):
+		return UploadFailedTimeout
+	case strings.Contains(cause, "file validation failed") || strings.Contains(cause, "failed to extract metadata"):
+		return UploadFailedInvalidFile
+	default:
+		return UploadFailedProcessing
+	}
+}
+
 // PresignedUploadRequest represents a request to get a presigned URL for uploading
 type PresignedUploadRequest struct {
 	FileName    string `json:"fileName" validate:"required,min=1,max=500"`
@@ -61,6 +105,43 @@ type PresignedUploadRequest struct {
 	IsMultipart bool   `json:"isMultipart,omitempty"` // Request multipart upload for large files
 }
 
+// MaxUploadFileSize is the largest file a presigned upload accepts
+const MaxUploadFileSize int64 = 1073741824 // 1GB
+
+// UploadPrecheckRequest describes a file the client is about to upload
+type UploadPrecheckRequest struct {
+	FileName string `json:"fileName" validate:"required,min=1,max=500"`
+	FileSize int64  `json:"fileSize" validate:"required,min=1"`
+	// ContentHash is the SHA-256 of the file, hex encoded; without it
+	// duplicates are not checked
+	ContentHash string `json:"contentHash,omitempty" validate:"omitempty,len=64,hexadecimal"`
+}
+
+// UploadRejectionReason identifies why an upload would be rejected
+type UploadRejectionReason string
+
+const (
+	UploadRejectedFormat    UploadRejectionReason = "unsupported_format"
+	UploadRejectedSize      UploadRejectionReason = "file_too_large"
+	UploadRejectedQuota     UploadRejectionReason = "over_quota"
+	UploadRejectedDuplicate UploadRejectionReason = "duplicate"
+)
+
+// UploadRejection is one reason an upload would be rejected
+type UploadRejection struct {
+	Reason  UploadRejectionReason `json:"reason"`
+	Message string                `json:"message"`
+	// TrackID is the library track a duplicate is identical to
+	TrackID string `json:"trackId,omitempty"`
+}
+
+// UploadPrecheckResponse reports whether an upload would be accepted, so
+// clients can warn before transferring the file
+type UploadPrecheckResponse struct {
+	Allowed    bool              `json:"allowed"`
+	Rejections []UploadRejection `json:"rejections"`
+}
+
 // PresignedUploadResponse represents a response with presigned URL for uploading
 type PresignedUploadResponse struct {
 	UploadID     string            `json:"uploadId"`
@@ -73,6 +154,9 @@ type PresignedUploadResponse struct {
 	IsMultipart bool                     `json:"isMultipart,omitempty"`
 	MultipartID string                   `json:"multipartId,omitempty"`
 	PartURLs    []MultipartUploadPartURL `json:"partUrls,omitempty"` // Presigned URLs for each part
+
+	// Set for file replacement uploads (POST /tracks/:id/replace-file)
+	ReplaceTrackID string `json:"replaceTrackId,omitempty"`
 }
 
 // MultipartUploadPartURL represents a presigned URL for a single multipart upload part
@@ -103,7 +187,12 @@ type UploadResponse struct {
 	ContentType string       `json:"contentType"`
 	Status      UploadStatus `json:"status"`
 	ErrorMsg    string       `json:"errorMsg,omitempty"`
+	// FailureReason is the stable code of ErrorMsg on failed uploads
+	FailureReason UploadFailureReason `json:"failureReason,omitempty"`
 	TrackID     string       `json:"trackId,omitempty"`
+	ReplaceTrackID string    `json:"replaceTrackId,omitempty"`
+	SuspectEncoding bool          `json:"suspectEncoding,omitempty"`
+	EncodingFixes   []EncodingFix `json:"encodingFixes,omitempty"`
 	CreatedAt   time.Time    `json:"createdAt"`
 	CompletedAt *time.Time   `json:"completedAt,omitempty"`
 
@@ -130,7 +219,11 @@ func (u *Upload) ToResponse() UploadResponse {
 		ContentType: u.ContentType,
 		Status:      u.Status,
 		ErrorMsg:    u.ErrorMsg,
+		FailureReason: u.FailureReason,
 		TrackID:     u.TrackID,
+		ReplaceTrackID: u.ReplaceTrackID,
+		SuspectEncoding: u.SuspectEncoding,
+		EncodingFixes:   u.EncodingFixes,
 		CreatedAt:   u.CreatedAt,
 		CompletedAt: u.CompletedAt,
 		Steps: UploadSteps{
@@ -143,6 +236,18 @@ func (u *Upload) ToResponse() UploadResponse {
 	}
 }
 
+// Localize replaces the error message of a failed upload with the
+// explanation of its failure reason in lang; the pipeline's own message,
+// meant for operators, stays in the stored upload
+func (r *UploadResponse) Localize(lang string) {
+	if r.FailureReason == "" {
+		return
+	}
+	if text, ok := i18n.Text(lang, "upload.failed."+string(r.FailureReason), nil); ok {
+		r.ErrorMsg = text
+	}
+}
+
 // UploadFilter represents filter options for listing uploads
 type UploadFilter struct {
 	Status    UploadStatus `query:"status"`
@@ -171,6 +276,11 @@ type UploadMetadata struct {
 	Composer    string `json:"composer,omitempty"`
 	Comment     string `json:"comment,omitempty"`
 	Lyrics      string `json:"lyrics,omitempty"`
+	Chapters    []Chapter `json:"chapters,omitempty"`
+	// EncodingFixes suggests repairs for tags decoded with the wrong character set
+	EncodingFixes []EncodingFix `json:"encodingFixes,omitempty"`
+	// ContentHash is the SHA-256 of the uploaded file, hex encoded
+	ContentHash string `json:"contentHash,omitempty"`
 }
 
 // ProcessingStep represents a step in the upload processing pipeline
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+)
+
+// Upload debug bundle limits. They keep the bundle of an upload that was
+// reprocessed many times, or whose Lambdas logged heavily, a readable size.
+const (
+	MaxDebugExecutions      = 10
+	MaxDebugExecutionEvents = 500
+	MaxDebugLogExcerpts     = 50
+	MaxDebugLogLines        = 500
+	// MaxDebugDataLength caps the state input and output kept per event, in bytes
+	MaxDebugDataLength = 4096
+	// DebugLogWindow is how long after the upload was created logs are searched
+	DebugLogWindow = 7 * 24 * time.Hour
+)
+
+// UploadDebugSource names a part of the upload debug bundle
+type UploadDebugSource string
+
+const (
+	DebugSourceTrack      UploadDebugSource = "track"
+	DebugSourceExecutions UploadDebugSource = "executions"
+	DebugSourceLogs       UploadDebugSource = "logs"
+	DebugSourceObjects    UploadDebugSource = "objects"
+	DebugSourceSearch     UploadDebugSource = "search"
+)
+
+// UploadDebugBundle is everything the system recorded about one upload, for
+// support. Each source is read independently: one that is not configured or
+// fails is listed in Errors and the rest of the bundle is still returned.
+type UploadDebugBundle struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Upload      Upload    `json:"upload"`
+	// Track is the track the upload created or replaced, if any
+	Track          *Track             `json:"track,omitempty"`
+	Executions     []UploadExecution  `json:"executions"`
+	Logs           []UploadLogExcerpt `json:"logs"`
+	ObjectVersions []S3ObjectVersion  `json:"objectVersions"`
+	SearchEntry    *UploadSearchEntry `json:"searchEntry,omitempty"`
+	Errors         []UploadDebugError `json:"errors,omitempty"`
+}
+
+// UploadExecution is a run of the upload processing state machine, newest
+// first in the bundle
+type UploadExecution struct {
+	ExecutionARN string                 `json:"executionArn"`
+	Name         string                 `json:"name"`
+	Status       string                 `json:"status"`
+	StartedAt    time.Time              `json:"startedAt"`
+	StoppedAt    *time.Time             `json:"stoppedAt,omitempty"`
+	Events       []UploadExecutionEvent `json:"events"`
+	// EventsTruncated is set when the history had more than MaxDebugExecutionEvents events
+	EventsTruncated bool `json:"eventsTruncated,omitempty"`
+}
+
+// UploadExecutionEvent is one event of an execution's history
+type UploadExecutionEvent struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	// State is the state entered or exited
+	State string `json:"state,omitempty"`
+	// Resource is the Lambda function a task invoked
+	Resource string `json:"resource,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Cause    string `json:"cause,omitempty"`
+	// Data is the state's input or output, cut at MaxDebugDataLength
+	Data string `json:"data,omitempty"`
+}
+
+// UploadLogExcerpt is the log output of one Lambda invocation that mentioned
+// the upload or its track
+type UploadLogExcerpt struct {
+	LogGroup  string          `json:"logGroup"`
+	LogStream string          `json:"logStream"`
+	RequestID string          `json:"requestId"`
+	Lines     []UploadLogLine `json:"lines"`
+	// Truncated is set when the invocation logged more than MaxDebugLogLines
+	// lines or its end was not found
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// UploadLogLine is one line of a log excerpt
+type UploadLogLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Message   string    `json:"message"`
+}
+
+// S3ObjectVersion is a version, or delete marker, of an object the upload
+// wrote: the uploaded file, its quarantined copy, the track's audio file or
+// its cover art
+type S3ObjectVersion struct {
+	Key          string    `json:"key"`
+	VersionID    string    `json:"versionId"`
+	IsLatest     bool      `json:"isLatest"`
+	DeleteMarker bool      `json:"deleteMarker,omitempty"`
+	Size         int64     `json:"size,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// UploadSearchEntry is the search index document of the upload's track as
+// the index holds it; Document is nil when the track is not indexed
+type UploadSearchEntry struct {
+	TrackID  string                `json:"trackId"`
+	Indexed  bool                  `json:"indexed"`
+	Document *searchproto.Document `json:"document,omitempty"`
+}
+
+// UploadDebugError reports a part of the bundle that could not be collected
+type UploadDebugError struct {
+	Source  UploadDebugSource `json:"source"`
+	Message string            `json:"message"`
+}
+
+// TruncateDebugData cuts s to MaxDebugDataLength bytes without splitting a
+// UTF-8 sequence
+func TruncateDebugData(s string) string {
+	if len(s) <= MaxDebugDataLength {
+		return s
+	}
+	cut := MaxDebugDataLength
+	for cut > 0 && s[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateDebugData(t *testing.T) {
+	assert.Equal(t, `{"uploadId":"u1"}`, TruncateDebugData(`{"uploadId":"u1"}`))
+
+	long := strings.Repeat("a", MaxDebugDataLength-1) + "é" + "tail"
+	cut := TruncateDebugData(long)
+	assert.True(t, utf8.ValidString(cut), "multi-byte characters are not split")
+	assert.Equal(t, strings.Repeat("a", MaxDebugDataLength-1)+"…", cut)
+}
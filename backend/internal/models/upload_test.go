@@ -469,3 +469,42 @@ func TestPresignedUploadRequestMaxFileSize(t *testing.T) {
 	assert.Equal(t, int64(1073741824), req.FileSize)
 	assert.True(t, req.IsMultipart)
 }
+
+// TestClassifyUploadFailure verifies pipeline errors map to user-facing reasons
+func TestClassifyUploadFailure(t *testing.T) {
+	tests := []struct {
+		errorName, cause string
+		want             UploadFailureReason
+	}{
+		{"MalwareDetected", "Eicar-Signature FOUND", UploadFailedMalware},
+		{"States.Timeout", "", UploadFailedTimeout},
+		{"Sandbox.Timedout", `{"errorMessage":"Task timed out after 60.00 seconds"}`, UploadFailedTimeout},
+		{"errorString", `{"errorMessage":"file validation failed: not an audio file"}`, UploadFailedInvalidFile},
+		{"wrapError", `{"errorMessage":"failed to extract metadata: unexpected EOF"}`, UploadFailedInvalidFile},
+		{"Lambda.Unknown", "", UploadFailedProcessing},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyUploadFailure(tt.errorName, tt.cause), tt.errorName)
+	}
+}
+
+// TestUploadResponseLocalize verifies failed uploads explain their reason
+func TestUploadResponseLocalize(t *testing.T) {
+	upload := Upload{
+		ID:            "upload-123",
+		Status:        UploadStatusFailed,
+		ErrorMsg:      "MalwareDetected: Eicar-Signature FOUND",
+		FailureReason: UploadFailedMalware,
+	}
+
+	response := upload.ToResponse()
+	response.Localize("de")
+	assert.Equal(t, "Die Datei wurde abgelehnt, weil sie Schadsoftware enthalten könnte", response.ErrorMsg)
+	assert.Equal(t, UploadFailedMalware, response.FailureReason)
+
+	// Uploads failed before reasons were recorded keep the pipeline message
+	upload.FailureReason = ""
+	response = upload.ToResponse()
+	response.Localize("de")
+	assert.Equal(t, "MalwareDetected: Eicar-Signature FOUND", response.ErrorMsg)
+}
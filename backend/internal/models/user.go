@@ -22,6 +22,7 @@ type User struct {
 	TrackCount    int        `json:"trackCount" dynamodbav:"trackCount"`
 	AlbumCount    int        `json:"albumCount" dynamodbav:"albumCount"`
 	PlaylistCount int        `json:"playlistCount" dynamodbav:"playlistCount"`
+	HouseholdID   string     `json:"householdId,omitempty" dynamodbav:"householdId,omitempty"` // Set when the user shares a household library
 }
 
 // UserItem represents a User in DynamoDB single-table design
@@ -113,6 +114,7 @@ type UserResponse struct {
 	TrackCount     int              `json:"trackCount"`
 	AlbumCount     int              `json:"albumCount"`
 	PlaylistCount  int              `json:"playlistCount"`
+	HouseholdID    string           `json:"householdId,omitempty"`
 }
 
 // ToResponse converts a User to a UserResponse
@@ -142,5 +144,6 @@ func (u *User) ToResponse() UserResponse {
 		TrackCount:     u.TrackCount,
 		AlbumCount:     u.AlbumCount,
 		PlaylistCount:  u.PlaylistCount,
+		HouseholdID:    u.HouseholdID,
 	}
 }
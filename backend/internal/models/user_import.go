@@ -0,0 +1,163 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+)
+
+// MaxUserImportRows is the most users one import provisions; each row makes
+// several Cognito and DynamoDB calls within the API's request timeout
+const MaxUserImportRows = 100
+
+// ImportUsersRequest provisions a batch of users from CSV. The CSV has a
+// header row naming its columns: email (required), name, role and
+// storageLimit (bytes, -1 for unlimited). Empty role and storageLimit cells
+// take the request's defaults.
+type ImportUsersRequest struct {
+	CSV                 string `json:"csv" validate:"required"`
+	DefaultRole         string `json:"defaultRole,omitempty" validate:"omitempty,oneof=guest subscriber artist admin"`
+	DefaultStorageLimit int64  `json:"defaultStorageLimit,omitempty" validate:"omitempty,min=-1"`
+	// SendInvites has Cognito email each user their temporary password
+	// instead of returning it in the report
+	SendInvites bool `json:"sendInvites,omitempty"`
+	// DryRun checks every row without creating anyone
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// UserImportRow is one parsed row of an import. Err is set when the row
+// cannot be provisioned; the other rows are still imported.
+type UserImportRow struct {
+	Line         int
+	Email        string
+	DisplayName  string
+	Role         UserRole
+	StorageLimit int64
+	Err          string
+}
+
+// UserImportStatus is the outcome of one import row
+type UserImportStatus string
+
+const (
+	UserImportCreated UserImportStatus = "created"
+	UserImportExists  UserImportStatus = "exists"
+	UserImportFailed  UserImportStatus = "failed"
+	// UserImportValid is reported by dry runs for rows that would be created
+	UserImportValid UserImportStatus = "valid"
+)
+
+// UserImportResult reports what happened to one row
+type UserImportResult struct {
+	Line         int              `json:"line"`
+	Email        string           `json:"email"`
+	Status       UserImportStatus `json:"status"`
+	UserID       string           `json:"userId,omitempty"`
+	Role         UserRole         `json:"role,omitempty"`
+	StorageLimit int64            `json:"storageLimit,omitempty"`
+	// TemporaryPassword must be changed at first sign-in; it is omitted when
+	// Cognito emailed it to the user
+	TemporaryPassword string `json:"temporaryPassword,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// UserImportResponse is the per-row report of an import. In a dry run,
+// Created counts the rows that would be created.
+type UserImportResponse struct {
+	Results  []UserImportResult `json:"results"`
+	Created  int                `json:"created"`
+	Existing int                `json:"existing"`
+	Failed   int                `json:"failed"`
+	DryRun   bool               `json:"dryRun,omitempty"`
+}
+
+// ParseUserImportCSV reads the rows of an import. Rows with an invalid email,
+// role or storage limit, or repeating an earlier email, come back with Err
+// set; an unreadable CSV, a missing email column or too many rows fail the
+// whole import.
+func ParseUserImportCSV(data string, defaultRole UserRole, defaultStorageLimit int64) ([]UserImportRow, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, NewValidationError("csv is empty")
+	}
+	if err != nil {
+		return nil, NewValidationError(fmt.Sprintf("invalid csv: %v", err))
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, NewValidationError("csv header must include an email column")
+	}
+	cell := func(record []string, column string) string {
+		if i, ok := columns[strings.ToLower(column)]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []UserImportRow
+	seen := make(map[string]bool)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, NewValidationError(fmt.Sprintf("invalid csv: %v", err))
+		}
+		line, _ := reader.FieldPos(0)
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if len(rows) == MaxUserImportRows {
+			return nil, NewValidationError(fmt.Sprintf("csv has more than %d users", MaxUserImportRows))
+		}
+
+		row := UserImportRow{
+			Line:         line,
+			Email:        strings.ToLower(cell(record, "email")),
+			DisplayName:  cell(record, "name"),
+			Role:         defaultRole,
+			StorageLimit: defaultStorageLimit,
+		}
+		if value := cell(record, "role"); value != "" {
+			row.Role = UserRole(strings.ToLower(value))
+		}
+		if value := cell(record, "storageLimit"); value != "" {
+			limit, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || limit < -1 {
+				row.Err = fmt.Sprintf("invalid storage limit %q", value)
+			}
+			row.StorageLimit = limit
+		}
+
+		switch {
+		case row.Err != "":
+		case !validImportEmail(row.Email):
+			row.Err = fmt.Sprintf("invalid email %q", row.Email)
+		case seen[row.Email]:
+			row.Err = "email is repeated in the csv"
+		case !row.Role.IsValid():
+			row.Err = fmt.Sprintf("invalid role %q", row.Role)
+		}
+		seen[row.Email] = true
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// validImportEmail accepts bare addresses only, without display names
+func validImportEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
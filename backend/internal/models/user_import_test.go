@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUserImportCSV(t *testing.T) {
+	t.Run("reads rows and applies defaults", func(t *testing.T) {
+		data := "Email,Name,Role,storageLimit\n" +
+			" Alice@Example.com ,Alice,artist,\n" +
+			"\n" +
+			"bob@example.com,,,-1\n" +
+			"not-an-email,Carol,,\n" +
+			"alice@example.com,Again,,\n" +
+			"dave@example.com,Dave,superuser,\n" +
+			"erin@example.com,Erin,,lots\n"
+
+		rows, err := ParseUserImportCSV(data, RoleSubscriber, 1024)
+		require.NoError(t, err)
+		require.Len(t, rows, 6)
+
+		assert.Equal(t, UserImportRow{Line: 2, Email: "alice@example.com", DisplayName: "Alice", Role: RoleArtist, StorageLimit: 1024}, rows[0])
+		assert.Equal(t, UserImportRow{Line: 4, Email: "bob@example.com", Role: RoleSubscriber, StorageLimit: -1}, rows[1])
+		assert.Contains(t, rows[2].Err, "invalid email")
+		assert.Equal(t, "email is repeated in the csv", rows[3].Err)
+		assert.Contains(t, rows[4].Err, "invalid role")
+		assert.Contains(t, rows[5].Err, "invalid storage limit")
+	})
+
+	t.Run("rejects unusable csv", func(t *testing.T) {
+		tooMany := "email\n" + strings.Repeat("x@example.com\n", MaxUserImportRows+1)
+		for name, data := range map[string]string{
+			"empty":           "",
+			"no email column": "name,role\nAlice,artist\n",
+			"too many rows":   tooMany,
+			"malformed":       "email\n\"alice@example.com\n",
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := ParseUserImportCSV(data, RoleSubscriber, 0)
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr, fmt.Sprintf("data %q", data))
+				assert.Equal(t, 400, apiErr.StatusCode)
+			})
+		}
+	})
+}
@@ -1,6 +1,12 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
+	"github.com/gvasels/personal-music-searchengine/internal/i18n"
+)
 
 // ProfileVisibility represents visibility options for user profile
 type ProfileVisibility string
@@ -35,6 +41,12 @@ type UserSettings struct {
 	Privacy       PrivacySettings      `json:"privacy" dynamodbav:"privacy"`
 	Player        PlayerSettings       `json:"player" dynamodbav:"player"`
 	Library       LibrarySettings      `json:"library" dynamodbav:"library"`
+	// Language is the language of error messages and notifications (e.g.
+	// "de", "fr"); empty to follow the browser's Accept-Language
+	Language string `json:"language,omitempty" dynamodbav:"language,omitempty"`
+	// TimeZone is the IANA time zone the user's days and weeks start in
+	// (e.g. "Europe/Berlin"); empty for UTC
+	TimeZone string `json:"timeZone,omitempty" dynamodbav:"timeZone,omitempty"`
 }
 
 // NotificationSettings represents notification preferences
@@ -59,6 +71,9 @@ type PlayerSettings struct {
 	CrossfadeDuration int          `json:"crossfadeDuration" dynamodbav:"crossfadeDuration"` // seconds
 	AudioQuality      AudioQuality `json:"audioQuality" dynamodbav:"audioQuality"`
 	NormalizeVolume   bool         `json:"normalizeVolume" dynamodbav:"normalizeVolume"`
+	// DataSaver makes lists show cover thumbnails and streams start on the
+	// lowest-bitrate HLS variant
+	DataSaver bool `json:"dataSaver" dynamodbav:"dataSaver"`
 }
 
 // LibrarySettings represents library organization preferences
@@ -66,6 +81,9 @@ type LibrarySettings struct {
 	AutoOrganize      bool              `json:"autoOrganize" dynamodbav:"autoOrganize"`
 	DuplicateHandling DuplicateHandling `json:"duplicateHandling" dynamodbav:"duplicateHandling"`
 	ExtractMetadata   bool              `json:"extractMetadata" dynamodbav:"extractMetadata"`
+	// SortLocale is the BCP 47 locale titles and names are sorted in (e.g.
+	// "fr", "sv", "zh-Hans"); empty for the language-neutral order
+	SortLocale string `json:"sortLocale,omitempty" dynamodbav:"sortLocale,omitempty"`
 }
 
 // DefaultUserSettings returns the default settings for a new user
@@ -139,5 +157,31 @@ func (s *UserSettings) Validate() error {
 		return fmt.Errorf("invalid duplicateHandling: %s", s.Library.DuplicateHandling)
 	}
 
+	// Validate sort locale
+	if err := collation.Validate(s.Library.SortLocale); err != nil {
+		return fmt.Errorf("invalid sortLocale: %s", s.Library.SortLocale)
+	}
+
+	// Validate language
+	if err := i18n.Validate(s.Language); err != nil {
+		return fmt.Errorf("invalid language: %s", s.Language)
+	}
+
+	// Validate time zone
+	if _, err := s.Location(); err != nil {
+		return fmt.Errorf("invalid timeZone: %s", s.TimeZone)
+	}
+
 	return nil
 }
+
+// Location returns the user's time zone, UTC if none is set
+func (s *UserSettings) Location() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.UTC, nil
+	}
+	if s.TimeZone == "Local" {
+		return nil, fmt.Errorf("unknown time zone %s", s.TimeZone)
+	}
+	return time.LoadLocation(s.TimeZone)
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserSettingsTimeZone(t *testing.T) {
+	settings := DefaultUserSettings()
+	loc, err := settings.Location()
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	settings.TimeZone = "Europe/Berlin"
+	assert.NoError(t, settings.Validate())
+	loc, err = settings.Location()
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", loc.String())
+
+	for _, zone := range []string{"Mars/Olympus", "Local", "+02:00"} {
+		settings.TimeZone = zone
+		assert.EqualError(t, settings.Validate(), "invalid timeZone: "+zone)
+	}
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// EntityDownloadToken represents the entity type for a watermarked download
+const EntityDownloadToken EntityType = "DOWNLOAD_TOKEN"
+
+// WatermarkMode sets how downloads of a track by other users are marked
+type WatermarkMode string
+
+const (
+	// WatermarkNone hands out the original file
+	WatermarkNone WatermarkMode = ""
+	// WatermarkAudible mixes in a faint warble listeners can hear
+	WatermarkAudible WatermarkMode = "audible"
+	// WatermarkInaudible mixes in tones above what most adults hear
+	WatermarkInaudible WatermarkMode = "inaudible"
+)
+
+// WatermarkedFileLifetime is how long a watermarked copy stays in the bucket.
+// The S3 lifecycle rule on the watermarked/ prefix removes it after a day;
+// its token is kept for tracing.
+const WatermarkedFileLifetime = 24 * time.Hour
+
+// DownloadTokenStatus represents where a watermarked copy stands
+type DownloadTokenStatus string
+
+const (
+	DownloadTokenPending DownloadTokenStatus = "PENDING"
+	DownloadTokenReady   DownloadTokenStatus = "READY"
+	DownloadTokenFailed  DownloadTokenStatus = "FAILED"
+)
+
+// DownloadToken records one watermarked download of a track: the token mixed
+// into the copy and who it was handed to. The owner looks a token found in a
+// leaked copy up to trace the leak.
+type DownloadToken struct {
+	Token          string              `json:"token" dynamodbav:"token"`
+	TrackID        string              `json:"trackId" dynamodbav:"trackId"`
+	OwnerID        string              `json:"ownerId" dynamodbav:"ownerId"`
+	RecipientID    string              `json:"recipientId" dynamodbav:"recipientId"`
+	RecipientEmail string              `json:"recipientEmail,omitempty" dynamodbav:"recipientEmail,omitempty"`
+	Mode           WatermarkMode       `json:"mode" dynamodbav:"mode"`
+	Status         DownloadTokenStatus `json:"status" dynamodbav:"status"`
+	FileName       string              `json:"fileName" dynamodbav:"fileName"`
+	S3Key          string              `json:"-" dynamodbav:"s3Key,omitempty"` // Watermarked copy, set once ready
+	Error          string              `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	CreatedAt      time.Time           `json:"createdAt" dynamodbav:"createdAt"`
+	ReadyAt        *time.Time          `json:"readyAt,omitempty" dynamodbav:"readyAt,omitempty"`
+}
+
+// NewDownloadToken returns a random token of 16 hex characters, the size the
+// watermark carries
+func NewDownloadToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// DownloadProtectionRequest sets how other users' downloads of a track are
+// watermarked
+type DownloadProtectionRequest struct {
+	Watermark string `json:"watermark" validate:"required,oneof=none audible inaudible"`
+}
+
+// Mode returns the requested watermark mode
+func (r DownloadProtectionRequest) Mode() WatermarkMode {
+	if r.Watermark == "none" {
+		return WatermarkNone
+	}
+	return WatermarkMode(r.Watermark)
+}
+
+// DownloadProtectionResponse reports a track's download protection
+type DownloadProtectionResponse struct {
+	TrackID   string `json:"trackId"`
+	Watermark string `json:"watermark"`
+}
+
+// NewDownloadProtectionResponse returns the download protection of track
+func NewDownloadProtectionResponse(track Track) DownloadProtectionResponse {
+	watermark := string(track.DownloadWatermark)
+	if watermark == "" {
+		watermark = "none"
+	}
+	return DownloadProtectionResponse{TrackID: track.ID, Watermark: watermark}
+}
+
+// WatermarkRequest asks the watermark Lambda to produce a download's copy
+type WatermarkRequest struct {
+	OwnerID string `json:"ownerId"`
+	TrackID string `json:"trackId"`
+	Token   string `json:"token"`
+	// TenantID is set in multi-tenant mode
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// WatermarkedFileKey returns the S3 key of a download's watermarked copy.
+// Copies live under watermarked/ so an S3 lifecycle rule can expire them.
+func WatermarkedFileKey(ownerID, token string, format AudioFormat) string {
+	return fmt.Sprintf("watermarked/%s/%s%s", ownerID, token, format.Extension())
+}
+
+// DownloadTokenItem represents a DownloadToken in DynamoDB single-table design
+type DownloadTokenItem struct {
+	DynamoDBItem
+	DownloadToken
+}
+
+// NewDownloadTokenItem creates a DynamoDB item for a watermarked download.
+// Primary key pattern: PK=USER#{ownerID}, SK=DOWNLOAD#{trackID}#{token}
+func NewDownloadTokenItem(token DownloadToken) DownloadTokenItem {
+	return DownloadTokenItem{
+		DynamoDBItem: DynamoDBItem{
+			PK:   fmt.Sprintf("USER#%s", token.OwnerID),
+			SK:   GetDownloadTokenSK(token.TrackID, token.Token),
+			Type: string(EntityDownloadToken),
+		},
+		DownloadToken: token,
+	}
+}
+
+// GetDownloadTokenSKPrefix returns the sort key prefix of a track's watermarked downloads
+func GetDownloadTokenSKPrefix(trackID string) string {
+	return fmt.Sprintf("DOWNLOAD#%s#", trackID)
+}
+
+// GetDownloadTokenSK returns the sort key for a watermarked download
+func GetDownloadTokenSK(trackID, token string) string {
+	return GetDownloadTokenSKPrefix(trackID) + token
+}
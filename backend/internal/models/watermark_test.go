@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDownloadToken(t *testing.T) {
+	token := NewDownloadToken()
+
+	assert.Regexp(t, `^[0-9a-f]{16}$`, token)
+	assert.NotEqual(t, token, NewDownloadToken())
+}
+
+func TestDownloadProtectionRequest_Mode(t *testing.T) {
+	assert.Equal(t, WatermarkNone, DownloadProtectionRequest{Watermark: "none"}.Mode())
+	assert.Equal(t, WatermarkAudible, DownloadProtectionRequest{Watermark: "audible"}.Mode())
+	assert.Equal(t, WatermarkInaudible, DownloadProtectionRequest{Watermark: "inaudible"}.Mode())
+}
+
+func TestNewDownloadProtectionResponse(t *testing.T) {
+	assert.Equal(t, DownloadProtectionResponse{TrackID: "t1", Watermark: "none"}, NewDownloadProtectionResponse(Track{ID: "t1"}))
+	assert.Equal(t, DownloadProtectionResponse{TrackID: "t1", Watermark: "inaudible"}, NewDownloadProtectionResponse(Track{ID: "t1", DownloadWatermark: WatermarkInaudible}))
+}
+
+func TestNewDownloadTokenItem(t *testing.T) {
+	item := NewDownloadTokenItem(DownloadToken{Token: "0123456789abcdef", TrackID: "track-1", OwnerID: "owner-1", RecipientID: "user-2"})
+
+	assert.Equal(t, "USER#owner-1", item.PK)
+	assert.Equal(t, "DOWNLOAD#track-1#0123456789abcdef", item.SK)
+	assert.Equal(t, "DOWNLOAD_TOKEN", item.Type)
+	assert.Equal(t, "watermarked/owner-1/0123456789abcdef.flac", WatermarkedFileKey("owner-1", "0123456789abcdef", AudioFormatFLAC))
+}
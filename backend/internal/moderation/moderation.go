@@ -0,0 +1,194 @@
+// Package moderation checks tracks for policy violations before they are made
+// public. A Checker transcribes speech from a snippet of the audio (when a
+// Transcriber is configured) and asks a language model to classify the
+// transcript together with the track's metadata and lyrics.
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// maxFieldLength bounds each text field sent to the classifier
+const maxFieldLength = 4000
+
+// Content is what the classifier sees of a track
+type Content struct {
+	Title      string
+	Artist     string
+	Album      string
+	Genre      string
+	Comment    string
+	Lyrics     string
+	Transcript string
+}
+
+// Completer sends one prompt to a language model and returns its reply
+type Completer interface {
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// Transcriber turns the speech in an audio snippet into text
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio io.Reader, fileName string) (string, error)
+}
+
+// Checker runs the moderation check of one track
+type Checker struct {
+	transcriber Transcriber
+	completer   Completer
+}
+
+// NewChecker classifies with completer; transcriber may be nil to classify
+// metadata and lyrics only
+func NewChecker(transcriber Transcriber, completer Completer) *Checker {
+	return &Checker{transcriber: transcriber, completer: completer}
+}
+
+// Check transcribes snippet (when both it and a transcriber are present) and
+// classifies the result. An unreadable classifier reply flags the track, so
+// a human decides rather than nobody.
+func (c *Checker) Check(ctx context.Context, content Content, snippet io.Reader, fileName string) (models.ModerationVerdict, error) {
+	if c.transcriber != nil && snippet != nil {
+		transcript, err := c.transcriber.Transcribe(ctx, snippet, fileName)
+		if err != nil {
+			return models.ModerationVerdict{}, fmt.Errorf("failed to transcribe audio: %w", err)
+		}
+		content.Transcript = strings.TrimSpace(transcript)
+	}
+
+	reply, err := c.completer.Complete(ctx, buildPrompt(content))
+	if err != nil {
+		return models.ModerationVerdict{}, fmt.Errorf("failed to classify track: %w", err)
+	}
+
+	verdict, err := parseVerdict(reply)
+	if err != nil {
+		verdict = models.ModerationVerdict{Flagged: true, Reason: "automatic classification was inconclusive"}
+	}
+	verdict.Transcript = content.Transcript
+	return verdict, nil
+}
+
+// buildPrompt asks for a JSON verdict on the track's text
+func buildPrompt(content Content) string {
+	var b strings.Builder
+	b.WriteString(`You review tracks a user wants to publish on a music platform. Decide whether the text below violates any of these policies:
+- hate: attacks on people based on protected characteristics
+- harassment: threats or abuse aimed at a real, identifiable person
+- sexual_minors: any sexual content involving minors
+- violent_extremism: promotion of terrorism or violent extremist groups
+- illegal: instructions for serious crimes or weapons
+- spam: advertising, scams or links unrelated to the music
+
+Profanity, explicit lyrics and mature themes that are common in music are not violations on their own.
+
+Answer with only a JSON object: {"flagged": true|false, "categories": ["<policy>", ...], "reason": "<one sentence>"}
+
+`)
+	writeField(&b, "Title", content.Title)
+	writeField(&b, "Artist", content.Artist)
+	writeField(&b, "Album", content.Album)
+	writeField(&b, "Genre", content.Genre)
+	writeField(&b, "Comment", content.Comment)
+	writeField(&b, "Lyrics", content.Lyrics)
+	writeField(&b, "Transcript of the audio", content.Transcript)
+	return b.String()
+}
+
+func writeField(b *strings.Builder, name, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	if len(value) > maxFieldLength {
+		value = strings.ToValidUTF8(value[:maxFieldLength], "")
+	}
+	fmt.Fprintf(b, "<%s>\n%s\n</%s>\n", name, value, name)
+}
+
+// parseVerdict reads the JSON object in the model's reply, ignoring any
+// text around it
+func parseVerdict(reply string) (models.ModerationVerdict, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return models.ModerationVerdict{}, errors.New("no JSON object in classifier reply")
+	}
+
+	var verdict models.ModerationVerdict
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &verdict); err != nil {
+		return models.ModerationVerdict{}, fmt.Errorf("failed to parse classifier reply: %w", err)
+	}
+	return verdict, nil
+}
+
+// HTTPTranscriber calls an OpenAI-compatible speech-to-text endpoint
+// (POST /v1/audio/transcriptions), such as a self-hosted Whisper server
+type HTTPTranscriber struct {
+	url    string
+	model  string
+	client *http.Client
+}
+
+// NewHTTPTranscriber transcribes through the endpoint at url with model;
+// client may be nil
+func NewHTTPTranscriber(url, model string, client *http.Client) *HTTPTranscriber {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPTranscriber{url: url, model: model, client: client}
+}
+
+// Transcribe uploads the snippet as a multipart form and returns the text
+func (t *HTTPTranscriber) Transcribe(ctx context.Context, audio io.Reader, fileName string) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("model", t.model); err != nil {
+		return "", err
+	}
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return "", fmt.Errorf("failed to read audio snippet: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create transcription request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("transcription request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("transcriber returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse transcription: %w", err)
+	}
+	return result.Text, nil
+}
@@ -0,0 +1,104 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubCompleter struct {
+	reply  string
+	err    error
+	prompt string
+}
+
+func (s *stubCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	s.prompt = prompt
+	return s.reply, s.err
+}
+
+type stubTranscriber struct {
+	text string
+}
+
+func (s stubTranscriber) Transcribe(ctx context.Context, audio io.Reader, fileName string) (string, error) {
+	return s.text, nil
+}
+
+func TestChecker_Check(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("classifies metadata, lyrics and transcript", func(t *testing.T) {
+		completer := &stubCompleter{reply: `Here you go: {"flagged": true, "categories": ["hate"], "reason": "slurs in chorus"}`}
+		checker := NewChecker(stubTranscriber{text: " spoken intro "}, completer)
+
+		verdict, err := checker.Check(ctx, Content{Title: "Song", Lyrics: "la la"}, strings.NewReader("audio"), "song.mp3")
+
+		require.NoError(t, err)
+		assert.True(t, verdict.Flagged)
+		assert.Equal(t, []string{"hate"}, verdict.Categories)
+		assert.Equal(t, "slurs in chorus", verdict.Reason)
+		assert.Equal(t, "spoken intro", verdict.Transcript)
+		assert.Contains(t, completer.prompt, "<Title>\nSong\n</Title>")
+		assert.Contains(t, completer.prompt, "<Transcript of the audio>\nspoken intro\n</Transcript of the audio>")
+		assert.NotContains(t, completer.prompt, "<Album>", "empty fields are left out")
+	})
+
+	t.Run("skips transcription without a transcriber", func(t *testing.T) {
+		checker := NewChecker(nil, &stubCompleter{reply: `{"flagged": false}`})
+
+		verdict, err := checker.Check(ctx, Content{Title: "Song"}, strings.NewReader("audio"), "song.mp3")
+
+		require.NoError(t, err)
+		assert.False(t, verdict.Flagged)
+		assert.Empty(t, verdict.Transcript)
+	})
+
+	t.Run("flags unreadable replies for human review", func(t *testing.T) {
+		checker := NewChecker(nil, &stubCompleter{reply: "I cannot help with that."})
+
+		verdict, err := checker.Check(ctx, Content{Title: "Song"}, nil, "")
+
+		require.NoError(t, err)
+		assert.True(t, verdict.Flagged)
+		assert.Equal(t, "automatic classification was inconclusive", verdict.Reason)
+	})
+
+	t.Run("returns classifier errors", func(t *testing.T) {
+		checker := NewChecker(nil, &stubCompleter{err: errors.New("throttled")})
+
+		_, err := checker.Check(ctx, Content{Title: "Song"}, nil, "")
+
+		assert.ErrorContains(t, err, "throttled")
+	})
+}
+
+func TestHTTPTranscriber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "whisper-1", r.FormValue("model"))
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer file.Close()
+		audio, _ := io.ReadAll(file)
+		assert.Equal(t, "song.mp3", header.Filename)
+		assert.Equal(t, "audio bytes", string(audio))
+		_ = json.NewEncoder(w).Encode(map[string]string{"text": "hello world"})
+	}))
+	defer server.Close()
+
+	transcriber := NewHTTPTranscriber(server.URL, "whisper-1", nil)
+
+	text, err := transcriber.Transcribe(context.Background(), strings.NewReader("audio bytes"), "song.mp3")
+
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", text)
+}
@@ -0,0 +1,841 @@
+// Package nixiesearch is the search engine behind the Nixiesearch Lambda
+// (cmd/nixiesearch). It keeps one index per process in memory and persists it
+// as index.json in a Store: the index bucket in Lambda, or the media store of
+// a self-hosted API, which runs the engine in-process.
+package nixiesearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gvasels/personal-music-searchengine/internal/alerting"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+)
+
+// indexKey is the object the index is persisted to
+const indexKey = "index.json"
+
+// Store holds the index file and export files, e.g. an S3 bucket
+type Store interface {
+	// Get opens the object at key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Put writes the object at key, replacing any previous one
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+var (
+	store Store
+	// location names the store in alerts, e.g. its bucket
+	location string
+	// alerter reports an undecodable index; nil when ALERT_TOPIC_ARN is not set
+	alerter     *alerting.Alerter
+	index       *SearchIndex
+	indexMutex  sync.RWMutex
+	initialized bool
+
+	// Reported by the stats operation; guarded by indexMutex
+	indexBytes  int64
+	lastSavedAt time.Time
+	lastSyncAt  time.Time
+)
+
+// SearchIndex holds the in-memory search index
+type SearchIndex struct {
+	Documents map[string]searchproto.Document `json:"documents"`
+	UpdatedAt time.Time                       `json:"updatedAt"`
+}
+
+// Configure sets the store the index is kept in and drops any index already
+// loaded, so the next request loads it from there. location names the store
+// in alerts.
+func Configure(indexStore Store, storeLocation string, indexAlerter *alerting.Alerter) {
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+	store = indexStore
+	location = storeLocation
+	alerter = indexAlerter
+	index = nil
+	initialized = false
+}
+
+func loadIndex(ctx context.Context) error {
+	if initialized {
+		return nil
+	}
+
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+
+	if initialized {
+		return nil
+	}
+	if store == nil {
+		return errors.New("search index store is not configured")
+	}
+
+	// Try to load from the store
+	body, err := store.Get(ctx, indexKey)
+	if err != nil {
+		// Index doesn't exist yet, create empty
+		index = &SearchIndex{
+			Documents: make(map[string]searchproto.Document),
+			UpdatedAt: time.Now(),
+		}
+		initialized = true
+		return nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	var loadedIndex SearchIndex
+	if err := json.Unmarshal(data, &loadedIndex); err != nil {
+		// Searches fail until index.json is repaired or rebuilt, so tell operators
+		alerter.Send(ctx, alerting.Alert{
+			Kind:    alerting.KindIndexCorruption,
+			Key:     location,
+			Subject: "Search index is corrupt",
+			Message: "The stored search index could not be decoded; searches fail until it is rebuilt.",
+			Details: map[string]string{
+				"bucket": location,
+				"key":    indexKey,
+				"bytes":  fmt.Sprint(len(data)),
+				"error":  err.Error(),
+			},
+		})
+		return fmt.Errorf("failed to decode index: %w", err)
+	}
+
+	index = &loadedIndex
+	indexBytes = int64(len(data))
+	lastSyncAt = time.Now()
+	initialized = true
+	return nil
+}
+
+func saveIndex(ctx context.Context) error {
+	indexMutex.RLock()
+	data, err := json.Marshal(index)
+	indexMutex.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	if err := store.Put(ctx, indexKey, data, "application/json"); err != nil {
+		return fmt.Errorf("failed to save index: %w", err)
+	}
+
+	// index.json is rewritten whole, so every save also compacts it
+	indexMutex.Lock()
+	indexBytes = int64(len(data))
+	lastSavedAt = time.Now()
+	lastSyncAt = lastSavedAt
+	indexMutex.Unlock()
+	return nil
+}
+
+// HandleRequest loads the index on first use and runs one operation. Failures
+// are reported in the response rather than as an error, as the search client
+// expects.
+func HandleRequest(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+	if err := Warm(ctx); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	return dispatch(ctx, req)
+}
+
+// Warm loads the search index from the store
+func Warm(ctx context.Context) error {
+	return loadIndex(ctx)
+}
+
+// dispatch routes a request to its operation handler once the index is loaded
+func dispatch(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+	switch req.Operation {
+	case searchproto.OpSearch:
+		return handleSearch(ctx, req)
+	case searchproto.OpIndex:
+		return handleIndex(ctx, req)
+	case searchproto.OpDelete:
+		return handleDelete(ctx, req)
+	case searchproto.OpBulkIndex:
+		return handleBulkIndex(ctx, req)
+	case searchproto.OpStats:
+		return handleStats()
+	case searchproto.OpUpdateStatus:
+		return handleUpdateStatus(ctx, req)
+	case searchproto.OpGet:
+		return handleGet(req)
+	case searchproto.OpExport:
+		return handleExport(ctx, req)
+	case searchproto.OpImport:
+		return handleImport(ctx, req)
+	default:
+		return searchproto.ErrorResponse("unknown operation: %s", req.Operation), nil
+	}
+}
+
+func handleSearch(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+	var query searchproto.SearchQuery
+	if err := req.Decode(&query); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+
+	indexMutex.RLock()
+	defer indexMutex.RUnlock()
+
+	var results []searchproto.SearchResult
+	queryLower := strings.ToLower(query.Query)
+
+	for _, doc := range index.Documents {
+		// Filter by user
+		if query.Filters.UserID != "" && doc.UserID != query.Filters.UserID {
+			continue
+		}
+
+		// Apply filters
+		if query.Filters.Artist != "" && !strings.Contains(strings.ToLower(doc.Artist), strings.ToLower(query.Filters.Artist)) {
+			continue
+		}
+		if query.Filters.Album != "" && !strings.Contains(strings.ToLower(doc.Album), strings.ToLower(query.Filters.Album)) {
+			continue
+		}
+		if query.Filters.Genre != "" && doc.Genre != query.Filters.Genre {
+			continue
+		}
+		if query.Filters.YearFrom > 0 && doc.Year < query.Filters.YearFrom {
+			continue
+		}
+		if query.Filters.YearTo > 0 && doc.Year > query.Filters.YearTo {
+			continue
+		}
+		if query.Filters.DurationFrom > 0 && doc.Duration < query.Filters.DurationFrom {
+			continue
+		}
+		if query.Filters.DurationTo > 0 && doc.Duration > query.Filters.DurationTo {
+			continue
+		}
+		// A bitrate range only matches documents that have a bitrate
+		if (query.Filters.BitrateFrom > 0 || query.Filters.BitrateTo > 0) && doc.Bitrate == 0 {
+			continue
+		}
+		if query.Filters.BitrateFrom > 0 && doc.Bitrate < query.Filters.BitrateFrom {
+			continue
+		}
+		if query.Filters.BitrateTo > 0 && doc.Bitrate > query.Filters.BitrateTo {
+			continue
+		}
+		if query.Filters.HLSStatus != "" && doc.HLSStatus != query.Filters.HLSStatus {
+			continue
+		}
+		if query.Filters.Processing != nil && doc.Processing != *query.Filters.Processing {
+			continue
+		}
+		if query.Filters.StatusUpdatedBefore != nil &&
+			(doc.StatusUpdatedAt == nil || !doc.StatusUpdatedAt.Before(*query.Filters.StatusUpdatedBefore)) {
+			continue
+		}
+
+		// Calculate relevance score
+		score := calculateScore(doc, queryLower)
+		if queryLower == "" || score > 0 {
+			results = append(results, searchproto.SearchResult{
+				ID:       doc.ID,
+				Title:    doc.Title,
+				Artist:   doc.Artist,
+				Album:    doc.Album,
+				Genre:    doc.Genre,
+				Year:     doc.Year,
+				Duration: doc.Duration,
+				Score:    score,
+
+				UserID:          doc.UserID,
+				HLSStatus:       doc.HLSStatus,
+				StatusUpdatedAt: doc.StatusUpdatedAt,
+			})
+		}
+	}
+
+	// Sort by score descending
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	// Apply limit
+	total := len(results)
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return searchproto.NewResponse(searchproto.SearchResponse{
+		Results: results,
+		Total:   total,
+	})
+}
+
+func calculateScore(doc searchproto.Document, query string) float64 {
+	if query == "" {
+		return 1.0
+	}
+
+	var score float64
+
+	// Score each field with different weights
+	score += scoreField(doc.Title, query, 3.0)    // Title: highest weight
+	score += scoreField(doc.Artist, query, 2.0)   // Artist: high weight
+	score += scoreField(doc.Album, query, 1.5)    // Album: medium weight
+	score += scoreField(doc.Filename, query, 0.5) // Filename: low weight
+
+	// Queries spanning fields, like "beatles hey jude"
+	score += crossFieldScore(doc, query)
+
+	return score
+}
+
+// crossFieldScore scores multi-word queries whose words are spread over the
+// title, artist and album, which scoreField misses since it matches the whole
+// query against one field. Every query word must start a word of one of those
+// fields. The query can then also split into an artist phrase and a title (or
+// album) phrase, in either order, for a bonus as if both phrases were matched
+// on their own, so exact combined matches rank first.
+func crossFieldScore(doc searchproto.Document, query string) float64 {
+	terms := matchTerms(query)
+	if len(terms) < 2 {
+		return 0
+	}
+
+	title, artist, album := matchTerms(doc.Title), matchTerms(doc.Artist), matchTerms(doc.Album)
+
+	// Conjunction: each word scores by the best field it matches
+	var score float64
+	for _, term := range terms {
+		best := 0.0
+		if hasWordPrefix(title, term) {
+			best = 3.0
+		} else if hasWordPrefix(artist, term) {
+			best = 2.0
+		} else if hasWordPrefix(album, term) {
+			best = 1.5
+		}
+		if best == 0 {
+			return 0
+		}
+		score += best * 1.5
+	}
+
+	// Phrases: the best split into an artist part and a title or album part
+	var bestSplit float64
+	for i := 1; i < len(terms); i++ {
+		head, tail := terms[:i], terms[i:]
+		for _, other := range []struct {
+			words  []string
+			weight float64
+		}{{title, 3.0}, {album, 1.5}} {
+			split := max(
+				splitScore(phraseScore(artist, head, 2.0), phraseScore(other.words, tail, other.weight)),
+				splitScore(phraseScore(other.words, head, other.weight), phraseScore(artist, tail, 2.0)),
+			)
+			if split > bestSplit {
+				bestSplit = split
+			}
+		}
+	}
+
+	return score + bestSplit
+}
+
+// phraseScore scores phrase against a field's words: an exact field scores
+// like an exact scoreField match, the phrase as consecutive words of the field
+// like a word prefix match, and consecutive word prefixes (a query still being
+// typed) lower
+func phraseScore(field, phrase []string, weight float64) float64 {
+	if slices.Equal(field, phrase) {
+		return weight * 5.0
+	}
+	var score float64
+	for i := 0; i+len(phrase) <= len(field); i++ {
+		words := field[i : i+len(phrase)]
+		if slices.Equal(words, phrase) {
+			return weight * 2.5
+		}
+		if score == 0 && slices.EqualFunc(words, phrase, strings.HasPrefix) {
+			score = weight * 1.5
+		}
+	}
+	return score
+}
+
+// splitScore adds the scores of a query's two phrases, or returns 0 unless
+// both matched
+func splitScore(head, tail float64) float64 {
+	if head == 0 || tail == 0 {
+		return 0
+	}
+	return head + tail
+}
+
+// matchTerms lowercases text and splits it into words, dropping punctuation
+func matchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// hasWordPrefix reports whether any of words starts with term
+func hasWordPrefix(words []string, term string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// scoreField calculates match score for a single field using multiple strategies
+func scoreField(field, query string, weight float64) float64 {
+	if field == "" {
+		return 0
+	}
+
+	fieldLower := strings.ToLower(field)
+	queryLower := strings.ToLower(query)
+	var score float64
+
+	// Strategy 1: Exact match (highest score)
+	if fieldLower == queryLower {
+		return weight * 5.0
+	}
+
+	// Strategy 2: Prefix match on full field
+	if strings.HasPrefix(fieldLower, queryLower) {
+		score += weight * 3.0
+	}
+
+	// Strategy 3: Contains match
+	if strings.Contains(fieldLower, queryLower) {
+		score += weight * 2.0
+	}
+
+	// Strategy 4: Word prefix match (any word starts with query)
+	words := strings.Fields(fieldLower)
+	for _, word := range words {
+		if strings.HasPrefix(word, queryLower) {
+			score += weight * 2.5
+			break
+		}
+	}
+
+	// Strategy 5: Fuzzy match using Levenshtein distance
+	// Check each word in the field
+	for _, word := range words {
+		distance := levenshtein(word, queryLower)
+		wordLen := len(word)
+		queryLen := len(queryLower)
+		maxLen := wordLen
+		if queryLen > maxLen {
+			maxLen = queryLen
+		}
+
+		// Allow up to 2 character differences for short words, more for longer
+		maxDistance := 1
+		if maxLen > 4 {
+			maxDistance = 2
+		}
+		if maxLen > 8 {
+			maxDistance = 3
+		}
+
+		if distance <= maxDistance && distance > 0 {
+			// Fuzzy match found - score inversely proportional to distance
+			fuzzyScore := weight * (1.0 - float64(distance)/float64(maxLen+1))
+			if fuzzyScore > score {
+				score = fuzzyScore
+			}
+		}
+	}
+
+	return score
+}
+
+// levenshtein calculates the Levenshtein distance between two strings
+func levenshtein(a, b string) int {
+	if len(a) == 0 {
+		return len(b)
+	}
+	if len(b) == 0 {
+		return len(a)
+	}
+
+	// Create matrix
+	matrix := make([][]int, len(a)+1)
+	for i := range matrix {
+		matrix[i] = make([]int, len(b)+1)
+		matrix[i][0] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		matrix[0][j] = j
+	}
+
+	// Fill matrix
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			matrix[i][j] = min(
+				matrix[i-1][j]+1,      // deletion
+				matrix[i][j-1]+1,      // insertion
+				matrix[i-1][j-1]+cost, // substitution
+			)
+		}
+	}
+
+	return matrix[len(a)][len(b)]
+}
+
+// min returns the minimum of three integers
+func min(a, b, c int) int {
+	if a < b {
+		if a < c {
+			return a
+		}
+		return c
+	}
+	if b < c {
+		return b
+	}
+	return c
+}
+
+func handleIndex(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+	var payload searchproto.IndexRequest
+	if err := req.Decode(&payload); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+	if err := payload.Document.Validate(); err != nil {
+		var docErr *searchproto.DocumentError
+		if errors.As(err, &docErr) {
+			return searchproto.ValidationResponse(docErr), nil
+		}
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	payload.Document.IndexedAt = time.Now()
+
+	indexMutex.Lock()
+	index.Documents[payload.Document.ID] = payload.Document
+	index.UpdatedAt = time.Now()
+	indexMutex.Unlock()
+
+	if err := saveIndex(ctx); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	return searchproto.NewResponse(searchproto.IndexResponse{
+		ID:      payload.Document.ID,
+		Indexed: true,
+	})
+}
+
+func handleDelete(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+	var payload searchproto.DeleteRequest
+	if err := req.Decode(&payload); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	indexMutex.Lock()
+	_, exists := index.Documents[payload.ID]
+	if exists {
+		delete(index.Documents, payload.ID)
+		index.UpdatedAt = time.Now()
+	}
+	indexMutex.Unlock()
+
+	if exists {
+		if err := saveIndex(ctx); err != nil {
+			return searchproto.ErrorResponse("%s", err), nil
+		}
+	}
+
+	return searchproto.NewResponse(searchproto.DeleteResponse{
+		ID:      payload.ID,
+		Deleted: exists,
+	})
+}
+
+// handleUpdateStatus changes the processing fields of an indexed document,
+// e.g. when its HLS transcode completes. An update older than the document's
+// status is ignored, so late events cannot undo newer ones.
+func handleUpdateStatus(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+	var payload searchproto.StatusUpdate
+	if err := req.Decode(&payload); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	indexMutex.Lock()
+	doc, exists := index.Documents[payload.ID]
+	stale := exists && doc.StatusUpdatedAt != nil && payload.UpdatedAt.Before(*doc.StatusUpdatedAt)
+	if exists && !stale {
+		doc.HLSStatus = payload.HLSStatus
+		doc.Processing = payload.Processing
+		doc.StatusUpdatedAt = timePtr(payload.UpdatedAt)
+		index.Documents[payload.ID] = doc
+		index.UpdatedAt = time.Now()
+	}
+	indexMutex.Unlock()
+
+	if exists && !stale {
+		if err := saveIndex(ctx); err != nil {
+			return searchproto.ErrorResponse("%s", err), nil
+		}
+	}
+
+	return searchproto.NewResponse(searchproto.StatusUpdateResponse{
+		ID:      payload.ID,
+		Updated: exists && !stale,
+	})
+}
+
+func handleBulkIndex(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+	var payload searchproto.BulkIndexRequest
+	if err := req.Decode(&payload); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	// Invalid documents are reported by position and the rest still indexed,
+	// so the caller can resend only the failures
+	result := searchproto.BulkIndexResponse{}
+	valid := make([]searchproto.Document, 0, len(payload.Documents))
+	for i, doc := range payload.Documents {
+		if err := doc.Validate(); err != nil {
+			failure := searchproto.BulkIndexFailure{Index: i, ID: truncateID(doc.ID), Error: err.Error()}
+			var docErr *searchproto.DocumentError
+			if errors.As(err, &docErr) {
+				failure.Field, failure.Code = docErr.Field, docErr.Code
+			}
+			result.Failures = append(result.Failures, failure)
+			continue
+		}
+		valid = append(valid, doc)
+	}
+	result.Indexed, result.Failed = len(valid), len(result.Failures)
+	if len(valid) == 0 {
+		return searchproto.NewResponse(result)
+	}
+
+	indexMutex.Lock()
+	now := time.Now()
+	for _, doc := range valid {
+		doc.IndexedAt = now
+		index.Documents[doc.ID] = doc
+	}
+	index.UpdatedAt = now
+	indexMutex.Unlock()
+
+	if err := saveIndex(ctx); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	return searchproto.NewResponse(result)
+}
+
+func handleStats() (searchproto.Response, error) {
+	indexMutex.RLock()
+	defer indexMutex.RUnlock()
+
+	perUser := make(map[string]int)
+	for _, doc := range index.Documents {
+		perUser[doc.UserID]++
+	}
+	users := make([]searchproto.UserDocumentCount, 0, len(perUser))
+	for userID, count := range perUser {
+		users = append(users, searchproto.UserDocumentCount{UserID: userID, Documents: count})
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Documents != users[j].Documents {
+			return users[i].Documents > users[j].Documents
+		}
+		return users[i].UserID < users[j].UserID
+	})
+
+	return searchproto.NewResponse(searchproto.StatsResponse{
+		Documents:        len(index.Documents),
+		IndexBytes:       indexBytes,
+		Users:            users,
+		UpdatedAt:        index.UpdatedAt,
+		LastCompactionAt: timePtr(lastSavedAt),
+		LastSyncAt:       timePtr(lastSyncAt),
+	})
+}
+
+// handleGet returns one document as the index holds it, for diagnostics
+func handleGet(req searchproto.Request) (searchproto.Response, error) {
+	var payload searchproto.GetRequest
+	if err := req.Decode(&payload); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	indexMutex.RLock()
+	doc, exists := index.Documents[payload.ID]
+	indexMutex.RUnlock()
+
+	result := searchproto.GetResponse{ID: payload.ID, Found: exists}
+	if exists {
+		result.Document = &doc
+	}
+	return searchproto.NewResponse(result)
+}
+
+// handleExport writes the index, or one user's documents, to a new export
+// file under exports/ in the index bucket
+func handleExport(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+	var payload searchproto.ExportRequest
+	if err := req.Decode(&payload); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	now := time.Now()
+	docs := exportDocuments(payload.UserID)
+	var buf bytes.Buffer
+	if err := searchproto.WriteExport(&buf, searchproto.ExportHeader{ExportedAt: now, UserID: payload.UserID}, docs); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	key := searchproto.ExportKey(payload.UserID, now)
+	size := int64(buf.Len())
+	if err := store.Put(ctx, key, buf.Bytes(), "application/x-ndjson"); err != nil {
+		return searchproto.ErrorResponse("failed to save export: %s", err), nil
+	}
+
+	return searchproto.NewResponse(searchproto.ExportResponse{
+		Key:        key,
+		UserID:     payload.UserID,
+		Documents:  len(docs),
+		Bytes:      size,
+		ExportedAt: now,
+	})
+}
+
+// exportDocuments returns the documents of userID, or every document when
+// userID is empty, ordered by ID
+func exportDocuments(userID string) []searchproto.Document {
+	indexMutex.RLock()
+	docs := make([]searchproto.Document, 0, len(index.Documents))
+	for _, doc := range index.Documents {
+		if userID == "" || doc.UserID == userID {
+			docs = append(docs, doc)
+		}
+	}
+	indexMutex.RUnlock()
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	return docs
+}
+
+// handleImport loads an export file from the index bucket into the index
+func handleImport(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+	var payload searchproto.ImportRequest
+	if err := req.Decode(&payload); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+	if err := searchproto.ValidateExportKey(payload.Key); err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+
+	body, err := store.Get(ctx, payload.Key)
+	if err != nil {
+		return searchproto.ErrorResponse("failed to read export %s: %s", payload.Key, err), nil
+	}
+	defer body.Close()
+
+	result, err := importDocuments(body, payload)
+	if err != nil {
+		return searchproto.ErrorResponse("%s", err), nil
+	}
+	if result.Imported > 0 {
+		if err := saveIndex(ctx); err != nil {
+			return searchproto.ErrorResponse("%s", err), nil
+		}
+	}
+
+	return searchproto.NewResponse(result)
+}
+
+// importDocuments reads an export file and indexes its valid documents,
+// keeping their indexedAt. Documents that fail validation are reported by
+// line and the rest still imported; a file that cannot be read imports
+// nothing.
+func importDocuments(r io.Reader, payload searchproto.ImportRequest) (searchproto.ImportResponse, error) {
+	result := searchproto.ImportResponse{Key: payload.Key}
+	var docs []searchproto.Document
+	_, err := searchproto.ReadExport(r, func(line int, doc searchproto.Document, err error) error {
+		if err == nil && payload.UserID != "" && doc.UserID != payload.UserID {
+			result.Skipped++
+			return nil
+		}
+		if err == nil {
+			err = doc.Validate()
+		}
+		if err != nil {
+			result.Failed++
+			if len(result.Failures) < searchproto.MaxImportFailures {
+				result.Failures = append(result.Failures, searchproto.ImportFailure{Line: line, ID: truncateID(doc.ID), Error: err.Error()})
+			}
+			return nil
+		}
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if len(docs) > 0 {
+		indexMutex.Lock()
+		for _, doc := range docs {
+			index.Documents[doc.ID] = doc
+		}
+		index.UpdatedAt = time.Now()
+		indexMutex.Unlock()
+	}
+	result.Imported = len(docs)
+	return result, nil
+}
+
+// timePtr returns nil for the zero time
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// truncateID shortens an over-long document ID for echoing in a failure
+func truncateID(id string) string {
+	if len(id) <= searchproto.MaxDocumentIDLength {
+		return id
+	}
+	return id[:searchproto.MaxDocumentIDLength]
+}
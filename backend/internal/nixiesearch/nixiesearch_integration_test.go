@@ -0,0 +1,116 @@
+//go:build integration
+
+package nixiesearch
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useLocalStackIndex points the handler at the test bucket and drops any
+// index already loaded, as a cold start would.
+func useLocalStackIndex(tc *testutil.TestContext) {
+	Configure(NewS3Store(tc.S3, tc.BucketName), tc.BucketName, nil)
+}
+
+// TestIntegration_SearchLambda runs the API against this Lambda's handler in
+// process: the API's search client invokes HandleRequest, which persists its
+// index to LocalStack S3.
+func TestIntegration_SearchLambda(t *testing.T) {
+	invoker := testutil.InProcessLambda(HandleRequest)
+	tsc, cleanup := testutil.SetupTestServer(t, testutil.WithSearchLambda(invoker))
+	defer cleanup()
+
+	useLocalStackIndex(tsc.TestContext)
+	tsc.RegisterS3Cleanup("index.json")
+
+	ctx := context.Background()
+	lib := tsc.SeedLibrary(t, "search-lambda-user")
+	other := tsc.SeedLibrary(t, "search-lambda-other")
+	for _, track := range append(lib.Tracks, other.Tracks...) {
+		require.NoError(t, tsc.Services.Search.IndexTrack(ctx, track))
+	}
+
+	search := func(t *testing.T, userID, query string) models.SearchResponse {
+		t.Helper()
+		resp := tsc.DoRequest(t, http.MethodGet, "/api/v1/search",
+			testutil.AsUser(userID, models.RoleSubscriber),
+			testutil.WithQuery("q", query),
+		)
+		testutil.AssertStatus(t, resp, http.StatusOK)
+		return testutil.DecodeJSON[models.SearchResponse](t, resp)
+	}
+
+	t.Run("finds a track by title", func(t *testing.T) {
+		result := search(t, lib.User.ID, "midnight")
+		require.NotEmpty(t, result.Tracks)
+		assert.Equal(t, lib.TrackByTitle(t, "Midnight Drive").ID, result.Tracks[0].ID)
+	})
+
+	t.Run("results are scoped to the caller's library", func(t *testing.T) {
+		owned := make(map[string]bool)
+		for _, track := range lib.Tracks {
+			owned[track.ID] = true
+		}
+
+		result := search(t, lib.User.ID, "lowtide")
+		titles := make([]string, 0, len(result.Tracks))
+		for _, track := range result.Tracks {
+			assert.True(t, owned[track.ID], "track %s belongs to another user", track.ID)
+			titles = append(titles, track.Title)
+		}
+		assert.ElementsMatch(t, []string{"Deep Water", "Harbor Lights"}, titles)
+	})
+
+	t.Run("index survives a cold start", func(t *testing.T) {
+		useLocalStackIndex(tsc.TestContext)
+		result := search(t, lib.User.ID, "harbor")
+		require.NotEmpty(t, result.Tracks)
+		assert.Equal(t, lib.TrackByTitle(t, "Harbor Lights").ID, result.Tracks[0].ID)
+	})
+
+	t.Run("removed tracks are no longer found", func(t *testing.T) {
+		require.NoError(t, tsc.Services.Search.RemoveTrack(ctx, lib.TrackByTitle(t, "Slow Burn").ID))
+		result := search(t, lib.User.ID, "slow burn")
+		for _, track := range result.Tracks {
+			assert.NotEqual(t, "Slow Burn", track.Title)
+		}
+	})
+
+	t.Run("filters by HLS readiness after a status update", func(t *testing.T) {
+		ready := lib.TrackByTitle(t, "Harbor Lights")
+		req, err := searchproto.NewRequest(searchproto.OpUpdateStatus, searchproto.StatusUpdate{
+			ID: ready.ID, HLSStatus: string(models.HLSStatusReady), UpdatedAt: time.Now(),
+		})
+		require.NoError(t, err)
+		resp, err := HandleRequest(ctx, req)
+		require.NoError(t, err)
+		require.True(t, resp.Success, resp.Error)
+
+		httpResp := tsc.DoRequest(t, http.MethodPost, "/api/v1/search",
+			testutil.AsUser(lib.User.ID, models.RoleSubscriber),
+			testutil.WithJSON(models.SearchRequest{Query: "harbor", Filters: models.SearchFilters{HLSStatus: "READY"}}),
+		)
+		testutil.AssertStatus(t, httpResp, http.StatusOK)
+		result := testutil.DecodeJSON[models.SearchResponse](t, httpResp)
+		require.Len(t, result.Tracks, 1)
+		assert.Equal(t, ready.ID, result.Tracks[0].ID)
+	})
+
+	t.Run("unknown operations fail", func(t *testing.T) {
+		resp, err := HandleRequest(ctx, searchproto.Request{Operation: "reindex"})
+		require.NoError(t, err)
+		assert.False(t, resp.Success)
+		assert.Contains(t, resp.Error, "unknown operation")
+	})
+
+	assert.Positive(t, invoker.Invocations())
+}
@@ -0,0 +1,579 @@
+package nixiesearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+)
+
+// useIndex installs docs as the loaded index so dispatch runs without S3
+func useIndex(t *testing.T, docs ...searchproto.Document) {
+	t.Helper()
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+
+	index = &SearchIndex{Documents: make(map[string]searchproto.Document)}
+	for _, doc := range docs {
+		index.Documents[doc.ID] = doc
+	}
+	initialized = true
+	t.Cleanup(func() {
+		index = nil
+		initialized = false
+		indexBytes, lastSavedAt, lastSyncAt = 0, time.Time{}, time.Time{}
+	})
+}
+
+// invoke sends payload through JSON the way the Lambda runtime does and
+// returns the response as the API client would receive it
+func invoke(t *testing.T, op searchproto.Operation, payload interface{}) searchproto.Response {
+	t.Helper()
+	req, err := searchproto.NewRequest(op, payload)
+	require.NoError(t, err)
+	wire, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	var received searchproto.Request
+	require.NoError(t, json.Unmarshal(wire, &received))
+	resp, err := dispatch(context.Background(), received)
+	require.NoError(t, err)
+
+	wire, err = json.Marshal(resp)
+	require.NoError(t, err)
+	var decoded searchproto.Response
+	require.NoError(t, json.Unmarshal(wire, &decoded))
+	return decoded
+}
+
+const testTrackID = "6f1c2a9e-4b7d-4c3e-9a51-2d8e7f0b1c34"
+
+func TestDispatch_SearchContract(t *testing.T) {
+	useIndex(t,
+		searchproto.Document{ID: "t1", UserID: "u1", Title: "Midnight Drive", Artist: "Neon Coast", Genre: "Synthwave", Year: 2019, Duration: 245},
+		searchproto.Document{ID: "t2", UserID: "u1", Title: "Deep Water", Artist: "Lowtide", Genre: "Ambient", Year: 2021},
+		searchproto.Document{ID: "t3", UserID: "u2", Title: "Midnight Run", Artist: "Other"},
+	)
+
+	resp := invoke(t, searchproto.OpSearch, searchproto.SearchQuery{
+		Query:   "midnight",
+		Filters: searchproto.SearchFilters{UserID: "u1"},
+		Limit:   10,
+	})
+	require.True(t, resp.Success, resp.Error)
+
+	var result searchproto.SearchResponse
+	require.NoError(t, resp.Decode(&result))
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, 1, result.Total)
+	hit := result.Results[0]
+	assert.Equal(t, "t1", hit.ID)
+	assert.Equal(t, "Neon Coast", hit.Artist)
+	assert.Equal(t, "Synthwave", hit.Genre)
+	assert.Equal(t, 2019, hit.Year)
+	assert.Equal(t, 245, hit.Duration)
+	assert.Positive(t, hit.Score)
+}
+
+func TestDispatch_SearchRangeFilters(t *testing.T) {
+	useIndex(t,
+		searchproto.Document{ID: "t1", UserID: "u1", Title: "Night Drive", Duration: 390, Bitrate: 320},
+		searchproto.Document{ID: "t2", UserID: "u1", Title: "Night Bus", Duration: 210, Bitrate: 320},
+		searchproto.Document{ID: "t3", UserID: "u1", Title: "Night Shift", Duration: 420, Bitrate: 192},
+		searchproto.Document{ID: "t4", UserID: "u1", Title: "Night Walk", Duration: 450},
+	)
+
+	tests := []struct {
+		name    string
+		filters searchproto.SearchFilters
+		ids     []string
+	}{
+		{"duration range", searchproto.SearchFilters{DurationFrom: 300, DurationTo: 480}, []string{"t1", "t3", "t4"}},
+		{"minimum bitrate", searchproto.SearchFilters{BitrateFrom: 320}, []string{"t1", "t2"}},
+		{"both", searchproto.SearchFilters{DurationFrom: 300, DurationTo: 480, BitrateFrom: 320}, []string{"t1"}},
+		{"maximum bitrate skips unknown bitrates", searchproto.SearchFilters{BitrateTo: 256}, []string{"t3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filters.UserID = "u1"
+			resp := invoke(t, searchproto.OpSearch, searchproto.SearchQuery{Query: "night", Filters: tt.filters, Limit: 10})
+			require.True(t, resp.Success, resp.Error)
+
+			var result searchproto.SearchResponse
+			require.NoError(t, resp.Decode(&result))
+			ids := make([]string, len(result.Results))
+			for i, hit := range result.Results {
+				ids[i] = hit.ID
+			}
+			assert.ElementsMatch(t, tt.ids, ids)
+		})
+	}
+}
+
+func TestDispatch_SearchProcessingFilters(t *testing.T) {
+	hourAgo := time.Now().Add(-time.Hour)
+	useIndex(t,
+		searchproto.Document{ID: "t1", UserID: "u1", Title: "Night Drive", HLSStatus: "READY"},
+		searchproto.Document{ID: "t2", UserID: "u1", Title: "Night Bus", HLSStatus: "PROCESSING", Processing: true, StatusUpdatedAt: &hourAgo},
+		searchproto.Document{ID: "t3", UserID: "u2", Title: "Night Shift", HLSStatus: "PENDING", Processing: true, StatusUpdatedAt: timePtr(time.Now())},
+		searchproto.Document{ID: "t4", UserID: "u1", Title: "Night Walk"},
+	)
+	processing, done := true, false
+	halfHourAgo := time.Now().Add(-30 * time.Minute)
+
+	tests := []struct {
+		name    string
+		filters searchproto.SearchFilters
+		ids     []string
+	}{
+		{"ready to stream", searchproto.SearchFilters{UserID: "u1", HLSStatus: "READY"}, []string{"t1"}},
+		{"not processing", searchproto.SearchFilters{UserID: "u1", Processing: &done}, []string{"t1", "t4"}},
+		{"stuck across libraries", searchproto.SearchFilters{Processing: &processing, StatusUpdatedBefore: &halfHourAgo}, []string{"t2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := invoke(t, searchproto.OpSearch, searchproto.SearchQuery{Filters: tt.filters, Limit: 10})
+			require.True(t, resp.Success, resp.Error)
+
+			var result searchproto.SearchResponse
+			require.NoError(t, resp.Decode(&result))
+			ids := make([]string, len(result.Results))
+			for i, hit := range result.Results {
+				ids[i] = hit.ID
+			}
+			assert.ElementsMatch(t, tt.ids, ids)
+		})
+	}
+}
+
+func TestDispatch_UpdateStatusSkipsUnknownAndStale(t *testing.T) {
+	changedAt := time.Now()
+	useIndex(t, searchproto.Document{ID: "t1", UserID: "u1", HLSStatus: "READY", StatusUpdatedAt: &changedAt})
+
+	for name, update := range map[string]searchproto.StatusUpdate{
+		"unknown document": {ID: "t2", HLSStatus: "READY", UpdatedAt: changedAt},
+		"stale update":     {ID: "t1", HLSStatus: "PROCESSING", Processing: true, UpdatedAt: changedAt.Add(-time.Minute)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := invoke(t, searchproto.OpUpdateStatus, update)
+			require.True(t, resp.Success, resp.Error)
+
+			var result searchproto.StatusUpdateResponse
+			require.NoError(t, resp.Decode(&result))
+			assert.False(t, result.Updated)
+			assert.Equal(t, "READY", index.Documents["t1"].HLSStatus)
+		})
+	}
+}
+
+func TestCalculateScore_CrossField(t *testing.T) {
+	heyJude := searchproto.Document{ID: "t1", Title: "Hey Jude", Artist: "The Beatles", Album: "Past Masters"}
+	letItBe := searchproto.Document{ID: "t2", Title: "Let It Be", Artist: "The Beatles", Album: "Let It Be"}
+	cover := searchproto.Document{ID: "t3", Title: "Hey Jude", Artist: "Wilson Pickett", Album: "Hey Jude"}
+	tribute := searchproto.Document{ID: "t4", Title: "Hey Jude (Beatles Tribute)", Artist: "Studio Band"}
+
+	tests := []struct {
+		name  string
+		query string
+		order []string
+	}{
+		{"artist then title", "beatles hey jude", []string{"t1", "t4"}},
+		{"title then artist", "hey jude beatles", []string{"t1", "t4"}},
+		{"full artist name", "the beatles hey jude", []string{"t1"}},
+		{"artist then album", "beatles past masters", []string{"t1"}},
+		{"word prefixes", "beat hey ju", []string{"t1", "t4"}},
+		{"other artist", "pickett hey jude", []string{"t3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matched []searchproto.Document
+			for _, doc := range []searchproto.Document{heyJude, letItBe, cover, tribute} {
+				if calculateScore(doc, tt.query) > 0 {
+					matched = append(matched, doc)
+				}
+			}
+			sort.SliceStable(matched, func(i, j int) bool {
+				return calculateScore(matched[i], tt.query) > calculateScore(matched[j], tt.query)
+			})
+			ids := make([]string, len(matched))
+			for i, doc := range matched {
+				ids[i] = doc.ID
+			}
+			assert.Equal(t, tt.order, ids)
+		})
+	}
+
+	t.Run("exact combined match ranks above a partial one", func(t *testing.T) {
+		assert.Greater(t, calculateScore(heyJude, "the beatles hey jude"), calculateScore(heyJude, "beatles hey jude"))
+	})
+
+	t.Run("single words are scored per field only", func(t *testing.T) {
+		assert.Zero(t, crossFieldScore(heyJude, "beatles"))
+	})
+
+	t.Run("every word must match", func(t *testing.T) {
+		assert.Zero(t, crossFieldScore(heyJude, "beatles yesterday"))
+	})
+}
+
+func TestDispatch_SearchCrossField(t *testing.T) {
+	useIndex(t,
+		searchproto.Document{ID: "t1", UserID: "u1", Title: "Hey Jude", Artist: "The Beatles"},
+		searchproto.Document{ID: "t2", UserID: "u1", Title: "Hey Jude", Artist: "Wilson Pickett"},
+		searchproto.Document{ID: "t3", UserID: "u1", Title: "Yesterday", Artist: "The Beatles"},
+	)
+
+	resp := invoke(t, searchproto.OpSearch, searchproto.SearchQuery{
+		Query:   "Beatles Hey Jude",
+		Filters: searchproto.SearchFilters{UserID: "u1"},
+		Limit:   10,
+	})
+	require.True(t, resp.Success, resp.Error)
+
+	var result searchproto.SearchResponse
+	require.NoError(t, resp.Decode(&result))
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, "t1", result.Results[0].ID)
+}
+
+func TestDispatch_Errors(t *testing.T) {
+	useIndex(t)
+
+	t.Run("unknown operation", func(t *testing.T) {
+		resp := invoke(t, "reindex", searchproto.DeleteRequest{ID: "t1"})
+		assert.False(t, resp.Success)
+		assert.Equal(t, "unknown operation: reindex", resp.Error)
+	})
+
+	t.Run("missing payload", func(t *testing.T) {
+		resp, err := dispatch(context.Background(), searchproto.Request{Operation: searchproto.OpSearch})
+		require.NoError(t, err)
+		assert.False(t, resp.Success)
+		assert.Contains(t, resp.Error, "missing search payload")
+	})
+}
+
+func TestDispatch_BulkIndexReportsInvalidDocuments(t *testing.T) {
+	useIndex(t)
+
+	// No document is valid, so nothing is saved and S3 is not needed
+	resp := invoke(t, searchproto.OpBulkIndex, searchproto.BulkIndexRequest{Documents: []searchproto.Document{
+		{ID: testTrackID, Title: "No owner"},
+		{UserID: "u1", Title: "No ID"},
+		{ID: "t3", UserID: "u1", Title: "Not a UUID"},
+		{ID: testTrackID, UserID: "u1", Title: strings.Repeat("x", searchproto.MaxTextFieldLength+1)},
+	}})
+	require.True(t, resp.Success, resp.Error)
+
+	var result searchproto.BulkIndexResponse
+	require.NoError(t, resp.Decode(&result))
+	assert.Equal(t, 0, result.Indexed)
+	assert.Equal(t, 4, result.Failed)
+	assert.Equal(t, []searchproto.BulkIndexFailure{
+		{Index: 0, ID: testTrackID, Error: "userId is required", Field: "userId", Code: searchproto.CodeRequired},
+		{Index: 1, Error: "id is required", Field: "id", Code: searchproto.CodeRequired},
+		{Index: 2, ID: "t3", Error: "id must be a UUID", Field: "id", Code: searchproto.CodeInvalidFormat},
+		{Index: 3, ID: testTrackID, Error: "title is longer than 1000 bytes", Field: "title", Code: searchproto.CodeTooLong},
+	}, result.Failures)
+	assert.Empty(t, index.Documents)
+}
+
+func TestDispatch_IndexRejectsInvalidDocument(t *testing.T) {
+	useIndex(t)
+
+	resp := invoke(t, searchproto.OpIndex, searchproto.IndexRequest{Document: searchproto.Document{ID: "t1", UserID: "u1"}})
+	assert.False(t, resp.Success)
+	assert.Equal(t, "id must be a UUID", resp.Error)
+	assert.Equal(t, &searchproto.DocumentError{Field: "id", Code: searchproto.CodeInvalidFormat, Message: "id must be a UUID"}, resp.Validation)
+	assert.Empty(t, index.Documents)
+}
+
+func TestDispatch_Stats(t *testing.T) {
+	useIndex(t,
+		searchproto.Document{ID: "t1", UserID: "u1"},
+		searchproto.Document{ID: "t2", UserID: "u2"},
+		searchproto.Document{ID: "t3", UserID: "u2"},
+	)
+
+	resp := invoke(t, searchproto.OpStats, searchproto.StatsRequest{})
+	require.True(t, resp.Success, resp.Error)
+
+	var stats searchproto.StatsResponse
+	require.NoError(t, resp.Decode(&stats))
+	assert.Equal(t, 3, stats.Documents)
+	assert.Equal(t, []searchproto.UserDocumentCount{{UserID: "u2", Documents: 2}, {UserID: "u1", Documents: 1}}, stats.Users)
+	assert.Zero(t, stats.IndexBytes)
+	assert.Nil(t, stats.LastCompactionAt, "never saved")
+	assert.Nil(t, stats.LastSyncAt)
+}
+
+func TestDispatch_Get(t *testing.T) {
+	useIndex(t, searchproto.Document{ID: "t1", UserID: "u1", Title: "Midnight Drive", HLSStatus: "READY"})
+
+	resp := invoke(t, searchproto.OpGet, searchproto.GetRequest{ID: "t1"})
+	require.True(t, resp.Success, resp.Error)
+	var found searchproto.GetResponse
+	require.NoError(t, resp.Decode(&found))
+	assert.True(t, found.Found)
+	require.NotNil(t, found.Document)
+	assert.Equal(t, "Midnight Drive", found.Document.Title)
+	assert.Equal(t, "READY", found.Document.HLSStatus)
+
+	resp = invoke(t, searchproto.OpGet, searchproto.GetRequest{ID: "missing"})
+	require.True(t, resp.Success, resp.Error)
+	var missing searchproto.GetResponse
+	require.NoError(t, resp.Decode(&missing))
+	assert.False(t, missing.Found)
+	assert.Nil(t, missing.Document)
+}
+
+func TestExportDocuments(t *testing.T) {
+	useIndex(t,
+		searchproto.Document{ID: "t2", UserID: "u1"},
+		searchproto.Document{ID: "t1", UserID: "u1"},
+		searchproto.Document{ID: "t3", UserID: "u2"},
+	)
+
+	ids := func(docs []searchproto.Document) []string {
+		out := make([]string, len(docs))
+		for i, doc := range docs {
+			out[i] = doc.ID
+		}
+		return out
+	}
+	assert.Equal(t, []string{"t1", "t2", "t3"}, ids(exportDocuments("")))
+	assert.Equal(t, []string{"t1", "t2"}, ids(exportDocuments("u1")))
+	assert.Empty(t, exportDocuments("nobody"))
+}
+
+func TestImportDocuments(t *testing.T) {
+	indexedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var file bytes.Buffer
+	require.NoError(t, searchproto.WriteExport(&file, searchproto.ExportHeader{ExportedAt: indexedAt}, []searchproto.Document{
+		{ID: "550e8400-e29b-41d4-a716-446655440001", UserID: "u1", Title: "Imported", IndexedAt: indexedAt},
+		{ID: "550e8400-e29b-41d4-a716-446655440002", UserID: "u2", Title: "Other user"},
+		{ID: "not-a-uuid", UserID: "u1", Title: "Bad"},
+	}))
+	file.WriteString("not json\n")
+	exported := file.Bytes()
+
+	t.Run("valid documents are upserted and bad lines reported", func(t *testing.T) {
+		useIndex(t,
+			searchproto.Document{ID: "550e8400-e29b-41d4-a716-446655440001", UserID: "u1", Title: "Stale"},
+			searchproto.Document{ID: "kept", UserID: "u3"},
+		)
+
+		result, err := importDocuments(bytes.NewReader(exported), searchproto.ImportRequest{Key: "exports/a.ndjson"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Imported)
+		assert.Equal(t, 2, result.Failed)
+		require.Len(t, result.Failures, 2)
+		assert.Equal(t, 4, result.Failures[0].Line)
+		assert.Equal(t, "not-a-uuid", result.Failures[0].ID)
+		assert.Equal(t, 5, result.Failures[1].Line)
+
+		imported := index.Documents["550e8400-e29b-41d4-a716-446655440001"]
+		assert.Equal(t, "Imported", imported.Title)
+		assert.Equal(t, indexedAt, imported.IndexedAt, "indexedAt is kept")
+		assert.Contains(t, index.Documents, "kept", "documents missing from the file are left alone")
+	})
+
+	t.Run("a user import skips other users", func(t *testing.T) {
+		useIndex(t)
+
+		result, err := importDocuments(bytes.NewReader(exported), searchproto.ImportRequest{UserID: "u1"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Imported)
+		assert.Equal(t, 1, result.Skipped)
+		assert.Len(t, index.Documents, 1)
+	})
+
+	t.Run("an unreadable file imports nothing", func(t *testing.T) {
+		useIndex(t)
+
+		_, err := importDocuments(strings.NewReader(`{"format":"search-index","version":9}`), searchproto.ImportRequest{})
+		assert.ErrorContains(t, err, "unsupported export version")
+		assert.Empty(t, index.Documents)
+	})
+}
+
+// memoryStore keeps objects in memory
+type memoryStore map[string][]byte
+
+func (m memoryStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m memoryStore) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	m[key] = append([]byte(nil), body...)
+	return nil
+}
+
+// useStore configures the engine with an empty memory store
+func useStore(t *testing.T) memoryStore {
+	t.Helper()
+	store := memoryStore{}
+	Configure(store, "test", nil)
+	t.Cleanup(func() { Configure(nil, "", nil) })
+	return store
+}
+
+// handle runs one operation through HandleRequest, as the Lambda and the
+// in-process client do
+func handle(t *testing.T, op searchproto.Operation, payload interface{}) searchproto.Response {
+	t.Helper()
+	req, err := searchproto.NewRequest(op, payload)
+	require.NoError(t, err)
+	resp, err := HandleRequest(context.Background(), req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestHandleRequest_Store(t *testing.T) {
+	doc := searchproto.Document{ID: "550e8400-e29b-41d4-a716-446655440001", UserID: "u1", Title: "Midnight Drive"}
+
+	t.Run("the index is persisted and loaded again", func(t *testing.T) {
+		store := useStore(t)
+		resp := handle(t, searchproto.OpIndex, searchproto.IndexRequest{Document: doc})
+		require.True(t, resp.Success, resp.Error)
+		require.Contains(t, store, indexKey)
+
+		// A new process loads the saved index
+		Configure(store, "test", nil)
+		resp = handle(t, searchproto.OpGet, searchproto.GetRequest{ID: doc.ID})
+		var found searchproto.GetResponse
+		require.NoError(t, resp.Decode(&found))
+		assert.True(t, found.Found)
+	})
+
+	t.Run("exports can be imported into another store", func(t *testing.T) {
+		source := useStore(t)
+		handle(t, searchproto.OpIndex, searchproto.IndexRequest{Document: doc})
+		resp := handle(t, searchproto.OpExport, searchproto.ExportRequest{})
+		require.True(t, resp.Success, resp.Error)
+		var exported searchproto.ExportResponse
+		require.NoError(t, resp.Decode(&exported))
+		assert.Equal(t, 1, exported.Documents)
+
+		target := useStore(t)
+		target[exported.Key] = source[exported.Key]
+		resp = handle(t, searchproto.OpImport, searchproto.ImportRequest{Key: exported.Key})
+		require.True(t, resp.Success, resp.Error)
+		var imported searchproto.ImportResponse
+		require.NoError(t, resp.Decode(&imported))
+		assert.Equal(t, 1, imported.Imported)
+		assert.Contains(t, target, indexKey)
+	})
+
+	t.Run("a directory store keeps the index on disk", func(t *testing.T) {
+		dir := t.TempDir()
+		Configure(NewDirStore(dir), dir, nil)
+		t.Cleanup(func() { Configure(nil, "", nil) })
+		resp := handle(t, searchproto.OpIndex, searchproto.IndexRequest{Document: doc})
+		require.True(t, resp.Success, resp.Error)
+		assert.FileExists(t, filepath.Join(dir, indexKey))
+
+		Configure(NewDirStore(dir), dir, nil)
+		resp = handle(t, searchproto.OpStats, searchproto.StatsRequest{})
+		var stats searchproto.StatsResponse
+		require.NoError(t, resp.Decode(&stats))
+		assert.Equal(t, 1, stats.Documents)
+
+		_, err := NewDirStore(dir).Get(context.Background(), "../outside.json")
+		assert.Error(t, err)
+	})
+
+	t.Run("a corrupt index fails requests", func(t *testing.T) {
+		store := useStore(t)
+		store[indexKey] = []byte("{not json")
+		resp := handle(t, searchproto.OpStats, searchproto.StatsRequest{})
+		assert.False(t, resp.Success)
+		assert.Contains(t, resp.Error, "failed to decode index")
+	})
+
+	t.Run("requests fail without a store", func(t *testing.T) {
+		Configure(nil, "", nil)
+		resp := handle(t, searchproto.OpStats, searchproto.StatsRequest{})
+		assert.False(t, resp.Success)
+		assert.Contains(t, resp.Error, "not configured")
+	})
+}
+
+// Benchmark tests
+
+// benchmarkDocuments builds n documents for user u1 from a small vocabulary,
+// so that queries match a realistic share of the index
+func benchmarkDocuments(n int) []searchproto.Document {
+	words := []string{"midnight", "drive", "golden", "river", "electric", "heart", "neon", "city", "silent", "storm", "velvet", "harbor"}
+	docs := make([]searchproto.Document, n)
+	for i := range docs {
+		docs[i] = searchproto.Document{
+			ID:       fmt.Sprintf("t%d", i),
+			UserID:   "u1",
+			Title:    words[i%len(words)] + " " + words[(i/len(words))%len(words)],
+			Artist:   words[(i/7)%len(words)] + " collective",
+			Album:    words[(i/13)%len(words)],
+			Filename: fmt.Sprintf("media/u1/t%d.mp3", i),
+		}
+	}
+	return docs
+}
+
+func BenchmarkCalculateScore(b *testing.B) {
+	doc := searchproto.Document{Title: "Midnight Drive", Artist: "Neon Coast", Album: "Afterglow", Filename: "media/u1/t1.mp3"}
+	queries := map[string]string{
+		"exact":       "midnight drive",
+		"prefix":      "midn",
+		"fuzzy":       "midnihgt",
+		"miss":        "harbor",
+		"cross-field": "neon coast midnight drive",
+	}
+	for name, query := range queries {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				calculateScore(doc, query)
+			}
+		})
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("docs=%d", size), func(b *testing.B) {
+			index = &SearchIndex{Documents: make(map[string]searchproto.Document, size)}
+			for _, doc := range benchmarkDocuments(size) {
+				index.Documents[doc.ID] = doc
+			}
+			req, err := searchproto.NewRequest(searchproto.OpSearch, searchproto.SearchQuery{
+				Query:   "midnight",
+				Filters: searchproto.SearchFilters{UserID: "u1"},
+				Limit:   20,
+			})
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := dispatch(context.Background(), req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+	index = nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// RecordTrackAccess stores an access event and adds it to the track's access
+// counters in one transaction, so the counters match the events recorded
+func (r *DynamoDBRepository) RecordTrackAccess(ctx context.Context, event models.TrackAccessEvent) error {
+	av, err := attributevalue.MarshalMap(models.NewTrackAccessEventItem(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal access event: %w", err)
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName: aws.String(r.tableName),
+				Item:      av,
+			}},
+			{Update: &types.Update{
+				TableName: aws.String(r.tableName),
+				Key: map[string]types.AttributeValue{
+					"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", event.OwnerID)},
+					"SK": &types.AttributeValueMemberS{Value: models.GetTrackAccessCountsSK(event.TrackID)},
+				},
+				UpdateExpression: aws.String("ADD #counter :one SET #type = :type, trackId = :trackId, lastAccessedAt = :at"),
+				ExpressionAttributeNames: map[string]string{
+					"#counter": event.Kind.CounterName(),
+					"#type":    "Type",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":one":     &types.AttributeValueMemberN{Value: "1"},
+					":type":    &types.AttributeValueMemberS{Value: string(models.EntityTrackAccessCounts)},
+					":trackId": &types.AttributeValueMemberS{Value: event.TrackID},
+					":at":      &types.AttributeValueMemberS{Value: event.AccessedAt.UTC().Format(time.RFC3339Nano)},
+				},
+			}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record track access: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrackAccessCounts retrieves the access counters of one of an owner's
+// tracks; a track never accessed from outside its library has zero counts
+func (r *DynamoDBRepository) GetTrackAccessCounts(ctx context.Context, ownerID, trackID string) (*models.TrackAccessCounts, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", ownerID)},
+			"SK": &types.AttributeValueMemberS{Value: models.GetTrackAccessCountsSK(trackID)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track access counts: %w", err)
+	}
+
+	var counts models.TrackAccessCounts
+	if result.Item != nil {
+		if err := attributevalue.UnmarshalMap(result.Item, &counts); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal track access counts: %w", err)
+		}
+	}
+	return &counts, nil
+}
+
+// ListTrackAccess lists the access events of one of an owner's tracks, newest first
+func (r *DynamoDBRepository) ListTrackAccess(ctx context.Context, ownerID, trackID string, filter models.TrackAccessFilter) (*PaginatedResult[models.TrackAccessEvent], error) {
+	keyCondition := expression.Key("PK").Equal(expression.Value(fmt.Sprintf("USER#%s", ownerID))).
+		And(expression.Key("SK").BeginsWith(models.GetTrackAccessSKPrefix(trackID)))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     aws.Int32(int32(limit)),
+		ScanIndexForward:          aws.Bool(false),
+	}
+	if filter.Cursor != "" {
+		startKey, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	result, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list track access: %w", err)
+	}
+
+	events := make([]models.TrackAccessEvent, 0, len(result.Items))
+	for _, item := range result.Items {
+		var eventItem models.TrackAccessEventItem
+		if err := attributevalue.UnmarshalMap(item, &eventItem); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal access event: %w", err)
+		}
+		events = append(events, eventItem.TrackAccessEvent)
+	}
+
+	var nextCursor string
+	if result.LastEvaluatedKey != nil {
+		nextCursor, err = encodeCursor(result.LastEvaluatedKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	return &PaginatedResult[models.TrackAccessEvent]{
+		Items:      events,
+		NextCursor: nextCursor,
+		HasMore:    result.LastEvaluatedKey != nil,
+	}, nil
+}
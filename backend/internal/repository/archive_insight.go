@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// GetArchiveInsight retrieves the cold track report of a library
+func (r *DynamoDBRepository) GetArchiveInsight(ctx context.Context, libraryID string) (*models.ArchiveInsight, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       archiveInsightKey(libraryID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archive insight: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.ArchiveInsightItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal archive insight: %w", err)
+	}
+
+	return &item.ArchiveInsight, nil
+}
+
+// PutArchiveInsight stores the cold track report of a library, replacing the last
+func (r *DynamoDBRepository) PutArchiveInsight(ctx context.Context, insight models.ArchiveInsight) error {
+	av, err := attributevalue.MarshalMap(models.NewArchiveInsightItem(insight))
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive insight: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put archive insight: %w", err)
+	}
+
+	return nil
+}
+
+func archiveInsightKey(libraryID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + libraryID},
+		"SK": &types.AttributeValueMemberS{Value: models.ArchiveInsightSK},
+	}
+}
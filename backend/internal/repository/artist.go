@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 )
 
@@ -21,6 +22,9 @@ import (
 func (r *DynamoDBRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
 	artist.CreatedAt = time.Now()
 	artist.UpdatedAt = artist.CreatedAt
+	if artist.SortLocale == "" {
+		artist.SortLocale = collation.FromContext(ctx)
+	}
 
 	item := models.NewArtistItem(artist)
 	av, err := attributevalue.MarshalMap(item)
@@ -108,13 +112,19 @@ func (r *DynamoDBRepository) ListArtists(ctx context.Context, userID string, fil
 		limit = 20
 	}
 
+	// Filter only active artists by default
+	filterExpr := expression.Name("isActive").Equal(expression.Value(true))
+
+	// Name order comes from the sort index
+	if filter.SortBy == "name" {
+		return listSorted(ctx, r, userID, models.EntityArtist, limit, filter.LastKey, filter.SortOrder == "desc", &filterExpr,
+			func(item models.ArtistItem) models.Artist { return item.Artist })
+	}
+
 	keyCondition := expression.Key("PK").Equal(expression.Value(fmt.Sprintf("USER#%s", userID))).
 		And(expression.Key("SK").BeginsWith("ARTIST#"))
 
 	builder := expression.NewBuilder().WithKeyCondition(keyCondition)
-
-	// Filter only active artists by default
-	filterExpr := expression.Name("isActive").Equal(expression.Value(true))
 	builder = builder.WithFilter(filterExpr)
 
 	expr, err := builder.Build()
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// PutCollection creates or replaces a library collection
+func (r *DynamoDBRepository) PutCollection(ctx context.Context, collection models.Collection) error {
+	av, err := attributevalue.MarshalMap(models.NewCollectionItem(collection))
+	if err != nil {
+		return fmt.Errorf("failed to marshal collection: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put collection: %w", err)
+	}
+
+	return nil
+}
+
+// GetCollection retrieves a library collection
+func (r *DynamoDBRepository) GetCollection(ctx context.Context, libraryID, collectionID string) (*models.Collection, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            collectionKey(libraryID, collectionID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.CollectionItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal collection: %w", err)
+	}
+
+	return &item.Collection, nil
+}
+
+// ListCollections retrieves every collection of a library, in no particular order
+func (r *DynamoDBRepository) ListCollections(ctx context.Context, libraryID string) ([]models.Collection, error) {
+	var items []models.CollectionItem
+	if err := r.queryPrefix(ctx, "USER#"+libraryID, models.CollectionSKPrefix, &items); err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	collections := make([]models.Collection, 0, len(items))
+	for _, item := range items {
+		collections = append(collections, item.Collection)
+	}
+	return collections, nil
+}
+
+// UpdateCollection saves a collection's name, filter and position. The count
+// and members are left alone, since track changes adjust them concurrently.
+func (r *DynamoDBRepository) UpdateCollection(ctx context.Context, collection models.Collection) error {
+	names := map[string]string{
+		"#name":      "name",
+		"#position":  "position",
+		"#updatedAt": "updatedAt",
+	}
+	values := map[string]types.AttributeValue{
+		":name":      &types.AttributeValueMemberS{Value: collection.Name},
+		":position":  &types.AttributeValueMemberN{Value: strconv.Itoa(collection.Position)},
+		":updatedAt": &types.AttributeValueMemberS{Value: collection.UpdatedAt.Format(time.RFC3339Nano)},
+	}
+	updateExpr := "SET #name = :name, #position = :position, #updatedAt = :updatedAt"
+	if collection.Filter != nil {
+		filter, err := attributevalue.Marshal(collection.Filter)
+		if err != nil {
+			return fmt.Errorf("failed to marshal collection filter: %w", err)
+		}
+		names["#filter"] = "filter"
+		values[":filter"] = filter
+		updateExpr += ", #filter = :filter"
+	}
+
+	return r.updateCollection(ctx, "update collection", collection.UserID, collection.ID, updateExpr, names, values)
+}
+
+// DeleteCollection removes a library collection
+func (r *DynamoDBRepository) DeleteCollection(ctx context.Context, libraryID, collectionID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       collectionKey(libraryID, collectionID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	return nil
+}
+
+// AdjustCollectionCount adds delta to a collection's track count, returning
+// ErrNotFound if the collection was deleted
+func (r *DynamoDBRepository) AdjustCollectionCount(ctx context.Context, libraryID, collectionID string, delta int) error {
+	return r.updateCollection(ctx, "adjust collection count", libraryID, collectionID, "ADD #count :delta",
+		map[string]string{"#count": "trackCount"},
+		map[string]types.AttributeValue{":delta": &types.AttributeValueMemberN{Value: strconv.Itoa(delta)}})
+}
+
+// SetCollectionCount replaces a collection's track count after a recount
+func (r *DynamoDBRepository) SetCollectionCount(ctx context.Context, libraryID, collectionID string, count int) error {
+	return r.updateCollection(ctx, "set collection count", libraryID, collectionID, "SET #count = :count",
+		map[string]string{"#count": "trackCount"},
+		map[string]types.AttributeValue{":count": &types.AttributeValueMemberN{Value: strconv.Itoa(count)}})
+}
+
+// AddCollectionTracks adds tracks to a manual collection and counts them. The
+// caller passes only tracks that are not members yet.
+func (r *DynamoDBRepository) AddCollectionTracks(ctx context.Context, libraryID, collectionID string, trackIDs []string) error {
+	if len(trackIDs) == 0 {
+		return nil
+	}
+	return r.updateCollection(ctx, "add collection tracks", libraryID, collectionID, "ADD #trackIds :trackIds, #count :delta",
+		map[string]string{"#trackIds": "trackIds", "#count": "trackCount"},
+		map[string]types.AttributeValue{
+			":trackIds": &types.AttributeValueMemberSS{Value: trackIDs},
+			":delta":    &types.AttributeValueMemberN{Value: strconv.Itoa(len(trackIDs))},
+		})
+}
+
+// RemoveCollectionTracks removes tracks from a manual collection and uncounts
+// them. The caller passes only tracks that are members.
+func (r *DynamoDBRepository) RemoveCollectionTracks(ctx context.Context, libraryID, collectionID string, trackIDs []string) error {
+	if len(trackIDs) == 0 {
+		return nil
+	}
+	return r.updateCollection(ctx, "remove collection tracks", libraryID, collectionID, "DELETE #trackIds :trackIds ADD #count :delta",
+		map[string]string{"#trackIds": "trackIds", "#count": "trackCount"},
+		map[string]types.AttributeValue{
+			":trackIds": &types.AttributeValueMemberSS{Value: trackIDs},
+			":delta":    &types.AttributeValueMemberN{Value: strconv.Itoa(-len(trackIDs))},
+		})
+}
+
+// updateCollection applies an update expression to an existing collection,
+// returning ErrNotFound if it does not exist
+func (r *DynamoDBRepository) updateCollection(ctx context.Context, op, libraryID, collectionID, updateExpr string, names map[string]string, values map[string]types.AttributeValue) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       collectionKey(libraryID, collectionID),
+		UpdateExpression:          aws.String(updateExpr),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ConditionExpression:       aws.String("attribute_exists(PK)"),
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if errors.As(err, &condErr) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to %s: %w", op, err)
+	}
+	return nil
+}
+
+func collectionKey(libraryID, collectionID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + libraryID},
+		"SK": &types.AttributeValueMemberS{Value: models.GetCollectionSK(collectionID)},
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// PutComparisonConsent stores a user's consent to compare libraries with a
+// peer, replacing an earlier one
+func (r *DynamoDBRepository) PutComparisonConsent(ctx context.Context, consent models.ComparisonConsent) error {
+	av, err := attributevalue.MarshalMap(models.NewComparisonConsentItem(consent))
+	if err != nil {
+		return fmt.Errorf("failed to marshal comparison consent: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put comparison consent: %w", err)
+	}
+
+	return nil
+}
+
+// GetComparisonConsent retrieves a user's consent to compare with a peer
+func (r *DynamoDBRepository) GetComparisonConsent(ctx context.Context, userID, peerID string) (*models.ComparisonConsent, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       comparisonConsentKey(userID, peerID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comparison consent: %w", err)
+	}
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.ComparisonConsentItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal comparison consent: %w", err)
+	}
+
+	return &item.ComparisonConsent, nil
+}
+
+// DeleteComparisonConsent withdraws a user's consent to compare with a peer
+func (r *DynamoDBRepository) DeleteComparisonConsent(ctx context.Context, userID, peerID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 comparisonConsentKey(userID, peerID),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if ok := isConditionalCheckFailed(err, &condErr); ok {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete comparison consent: %w", err)
+	}
+
+	return nil
+}
+
+// ListComparisonConsents lists the consents a user has given
+func (r *DynamoDBRepository) ListComparisonConsents(ctx context.Context, userID string) ([]models.ComparisonConsent, error) {
+	var items []models.ComparisonConsentItem
+	if err := r.queryPrefix(ctx, "USER#"+userID, models.GetComparisonConsentSK(""), &items); err != nil {
+		return nil, fmt.Errorf("failed to list comparison consents: %w", err)
+	}
+
+	consents := make([]models.ComparisonConsent, 0, len(items))
+	for _, item := range items {
+		consents = append(consents, item.ComparisonConsent)
+	}
+	return consents, nil
+}
+
+// ListReceivedComparisonConsents lists the consents given to a user (via GSI1)
+func (r *DynamoDBRepository) ListReceivedComparisonConsents(ctx context.Context, peerID string) ([]models.ComparisonConsent, error) {
+	var consents []models.ComparisonConsent
+	var lastKey map[string]types.AttributeValue
+
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			IndexName:              aws.String("GSI1"),
+			KeyConditionExpression: aws.String("GSI1PK = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: models.GetComparisonConsentGSI1PK(peerID)},
+			},
+			ExclusiveStartKey: lastKey,
+		}
+
+		result, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list received comparison consents: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var consentItem models.ComparisonConsentItem
+			if err := attributevalue.UnmarshalMap(item, &consentItem); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal comparison consent: %w", err)
+			}
+			consents = append(consents, consentItem.ComparisonConsent)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+
+	return consents, nil
+}
+
+func comparisonConsentKey(userID, peerID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"SK": &types.AttributeValueMemberS{Value: models.GetComparisonConsentSK(peerID)},
+	}
+}
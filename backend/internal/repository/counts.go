@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CountTracks returns the number of tracks in a user's library
+func (r *DynamoDBRepository) CountTracks(ctx context.Context, userID string) (int, error) {
+	count, err := r.countItems(ctx, userID, "TRACK#")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tracks: %w", err)
+	}
+	return count, nil
+}
+
+// CountAlbums returns the number of albums in a user's library
+func (r *DynamoDBRepository) CountAlbums(ctx context.Context, userID string) (int, error) {
+	count, err := r.countItems(ctx, userID, "ALBUM#")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count albums: %w", err)
+	}
+	return count, nil
+}
+
+// CountPlaylists returns the number of playlists a user owns
+func (r *DynamoDBRepository) CountPlaylists(ctx context.Context, userID string) (int, error) {
+	count, err := r.countItems(ctx, userID, "PLAYLIST#")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count playlists: %w", err)
+	}
+	return count, nil
+}
+
+// countItems counts the items of a user's partition whose SK starts with
+// skPrefix. Select=COUNT returns no items, but DynamoDB still stops each page
+// at 1 MB read, so pages are summed until the partition is exhausted.
+func (r *DynamoDBRepository) countItems(ctx context.Context, userID, skPrefix string) (int, error) {
+	keyCondition := expression.Key("PK").Equal(expression.Value(fmt.Sprintf("USER#%s", userID))).
+		And(expression.Key("SK").BeginsWith(skPrefix))
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	count := 0
+	var lastKey map[string]types.AttributeValue
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:                 aws.String(r.tableName),
+			KeyConditionExpression:    expr.KeyCondition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			Select:                    types.SelectCount,
+		}
+		if lastKey != nil {
+			input.ExclusiveStartKey = lastKey
+		}
+
+		result, err := r.client.Query(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+		count += int(result.Count)
+
+		if result.LastEvaluatedKey == nil {
+			return count, nil
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+}
+
+// CountAllTracks returns the number of tracks across all users (requires
+// GLOBAL permission). Like the global track listing this scans the table, so
+// it costs a full read of it.
+func (r *DynamoDBRepository) CountAllTracks(ctx context.Context) (int, error) {
+	expr, err := expression.NewBuilder().WithFilter(expression.Name("SK").BeginsWith("TRACK#")).Build()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	count := 0
+	var lastKey map[string]types.AttributeValue
+	for {
+		input := &dynamodb.ScanInput{
+			TableName:                 aws.String(r.tableName),
+			FilterExpression:          expr.Filter(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			Select:                    types.SelectCount,
+		}
+		if lastKey != nil {
+			input.ExclusiveStartKey = lastKey
+		}
+
+		result, err := r.client.Scan(ctx, input)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count all tracks: %w", err)
+		}
+		count += int(result.Count)
+
+		if result.LastEvaluatedKey == nil {
+			return count, nil
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// PutDownloadToken stores a watermarked download. Tokens have no TTL; they
+// are kept for tracing leaked copies.
+func (r *DynamoDBRepository) PutDownloadToken(ctx context.Context, token models.DownloadToken) error {
+	av, err := attributevalue.MarshalMap(models.NewDownloadTokenItem(token))
+	if err != nil {
+		return fmt.Errorf("failed to marshal download token: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put download token: %w", err)
+	}
+
+	return nil
+}
+
+// GetDownloadToken retrieves a watermarked download of one of an owner's tracks
+func (r *DynamoDBRepository) GetDownloadToken(ctx context.Context, ownerID, trackID, token string) (*models.DownloadToken, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "USER#" + ownerID},
+			"SK": &types.AttributeValueMemberS{Value: models.GetDownloadTokenSK(trackID, token)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get download token: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.DownloadTokenItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal download token: %w", err)
+	}
+
+	return &item.DownloadToken, nil
+}
+
+// ListDownloadTokens retrieves every watermarked download of one of an owner's tracks
+func (r *DynamoDBRepository) ListDownloadTokens(ctx context.Context, ownerID, trackID string) ([]models.DownloadToken, error) {
+	var items []models.DownloadTokenItem
+	if err := r.queryPrefix(ctx, "USER#"+ownerID, models.GetDownloadTokenSKPrefix(trackID), &items); err != nil {
+		return nil, fmt.Errorf("failed to list download tokens: %w", err)
+	}
+
+	tokens := make([]models.DownloadToken, 0, len(items))
+	for _, item := range items {
+		tokens = append(tokens, item.DownloadToken)
+	}
+	return tokens, nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
@@ -12,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 )
 
@@ -49,6 +52,9 @@ func NewDynamoDBRepository(client DynamoDBClient, tableName string) *DynamoDBRep
 func (r *DynamoDBRepository) CreateTrack(ctx context.Context, track models.Track) error {
 	track.CreatedAt = time.Now()
 	track.UpdatedAt = track.CreatedAt
+	if track.SortLocale == "" {
+		track.SortLocale = collation.FromContext(ctx)
+	}
 
 	item := models.NewTrackItem(track)
 	av, err := attributevalue.MarshalMap(item)
@@ -194,6 +200,12 @@ func (r *DynamoDBRepository) ListTracks(ctx context.Context, userID string, filt
 		return r.listAllTracks(ctx, limit, filter)
 	}
 
+	// Title order comes from the sort index
+	if filter.SortBy == "title" {
+		return listSorted(ctx, r, userID, models.EntityTrack, limit, filter.LastKey, filter.SortOrder == "desc", nil,
+			func(item models.TrackItem) models.Track { return item.Track })
+	}
+
 	// User-scoped query (default behavior)
 	keyCondition := expression.Key("PK").Equal(expression.Value(fmt.Sprintf("USER#%s", userID))).
 		And(expression.Key("SK").BeginsWith("TRACK#"))
@@ -524,20 +536,22 @@ func (r *DynamoDBRepository) UpdateTrackVisibility(ctx context.Context, userID,
 
 func (r *DynamoDBRepository) GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error) {
 	// Generate consistent album ID from name and artist
-	albumID := generateAlbumID(albumName, artist)
+	albumID := AlbumID(albumName, artist)
 
-	// Try to get existing album
-	album, err := r.GetAlbum(ctx, userID, albumID)
-	if err == nil {
-		return album, nil
-	}
-	if err != ErrNotFound {
-		return nil, err
+	// Try to get existing album, under its legacy ID if not yet migrated
+	for _, id := range []string{albumID, legacyAlbumID(albumName, artist)} {
+		album, err := r.GetAlbum(ctx, userID, id)
+		if err == nil {
+			return album, nil
+		}
+		if err != ErrNotFound {
+			return nil, err
+		}
 	}
 
 	// Create new album
 	now := time.Now()
-	album = &models.Album{
+	album := &models.Album{
 		ID:            albumID,
 		UserID:        userID,
 		Title:         albumName,
@@ -545,6 +559,7 @@ func (r *DynamoDBRepository) GetOrCreateAlbum(ctx context.Context, userID, album
 		Year:          0, // Will be updated when tracks are added
 		TrackCount:    0,
 		TotalDuration: 0,
+		SortLocale:    collation.FromContext(ctx),
 	}
 	album.CreatedAt = now
 	album.UpdatedAt = now
@@ -602,6 +617,12 @@ func (r *DynamoDBRepository) ListAlbums(ctx context.Context, userID string, filt
 		limit = 20
 	}
 
+	// Title order comes from the sort index
+	if filter.SortBy == "title" {
+		return listSorted(ctx, r, userID, models.EntityAlbum, limit, filter.LastKey, filter.SortOrder == "desc", nil,
+			func(item models.AlbumItem) models.Album { return item.Album })
+	}
+
 	keyCondition := expression.Key("PK").Equal(expression.Value(fmt.Sprintf("USER#%s", userID))).
 		And(expression.Key("SK").BeginsWith("ALBUM#"))
 
@@ -729,6 +750,48 @@ func (r *DynamoDBRepository) UpdateAlbumStats(ctx context.Context, userID, album
 	return nil
 }
 
+// SetAlbumCover gives an album without cover art the given cover and style.
+// An album that has a cover by then keeps it and ErrConflict is returned.
+func (r *DynamoDBRepository) SetAlbumCover(ctx context.Context, userID, albumID, coverArtKey string, style *models.CoverStyle) error {
+	update := expression.Set(
+		expression.Name("coverArtKey"), expression.Value(coverArtKey),
+	).Set(
+		expression.Name("updatedAt"), expression.Value(time.Now().Format(time.RFC3339)),
+	)
+	if style != nil {
+		update = update.Set(expression.Name("coverStyle"), expression.Value(style))
+	}
+	condition := expression.AttributeExists(expression.Name("PK")).And(
+		expression.AttributeNotExists(expression.Name("coverArtKey")),
+	)
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("ALBUM#%s", albumID)},
+		},
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConditionExpression:       expr.Condition(),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to set album cover: %w", err)
+	}
+
+	return nil
+}
+
 // ============================================================================
 // User Operations
 // ============================================================================
@@ -2122,6 +2185,12 @@ func cursorToAttributeValue(cursor models.PaginationCursor) map[string]types.Att
 	if cursor.GSI1SK != "" {
 		av["GSI1SK"] = &types.AttributeValueMemberS{Value: cursor.GSI1SK}
 	}
+	if cursor.GSI5PK != "" {
+		av["GSI5PK"] = &types.AttributeValueMemberS{Value: cursor.GSI5PK}
+	}
+	if cursor.GSI5SK != "" {
+		av["GSI5SK"] = &types.AttributeValueMemberS{Value: cursor.GSI5SK}
+	}
 
 	return av
 }
@@ -2146,6 +2215,12 @@ func encodeCursor(key map[string]types.AttributeValue) (string, error) {
 	if gsi1sk, ok := key["GSI1SK"].(*types.AttributeValueMemberS); ok {
 		cursor.GSI1SK = gsi1sk.Value
 	}
+	if gsi5pk, ok := key["GSI5PK"].(*types.AttributeValueMemberS); ok {
+		cursor.GSI5PK = gsi5pk.Value
+	}
+	if gsi5sk, ok := key["GSI5SK"].(*types.AttributeValueMemberS); ok {
+		cursor.GSI5SK = gsi5sk.Value
+	}
 
 	return models.EncodeCursor(cursor), nil
 }
@@ -2164,11 +2239,17 @@ func decodeCursor(cursor string) (map[string]types.AttributeValue, error) {
 	return cursorToAttributeValue(paginationCursor), nil
 }
 
-// generateAlbumID creates a consistent album ID from name and artist
-func generateAlbumID(albumName, artist string) string {
-	// Use a simple hash for consistent ID generation
-	combined := fmt.Sprintf("%s-%s", albumName, artist)
-	// For now, just use the combined string as ID
-	// In production, you might want to use a proper hash function
-	return combined
+// AlbumID returns the consistent ID of a user's album with the given name and
+// artist. It is a hash of both, so names with slashes, spaces or other
+// characters that do not belong in a URL path still give a usable ID.
+func AlbumID(albumName, artist string) string {
+	sum := sha256.Sum256([]byte(albumName + "\x00" + artist))
+	return hex.EncodeToString(sum[:16])
+}
+
+// legacyAlbumID is the "name-artist" ID albums were created with before
+// AlbumID. The rewrite-album-ids migration moves them to AlbumID; until it has
+// run, GetOrCreateAlbum keeps finding them under the old ID.
+func legacyAlbumID(albumName, artist string) string {
+	return fmt.Sprintf("%s-%s", albumName, artist)
 }
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// maxBatchGetAttempts bounds how often unprocessed keys of a BatchGetItem are
+// retried before giving up
+const maxBatchGetAttempts = 5
+
+// PutTrackEmbedding stores a track's embedding for its model, replacing any
+// previous vector from the same model
+func (r *DynamoDBRepository) PutTrackEmbedding(ctx context.Context, embedding models.TrackEmbedding) error {
+	av, err := attributevalue.MarshalMap(models.NewTrackEmbeddingItem(embedding))
+	if err != nil {
+		return fmt.Errorf("failed to marshal track embedding: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put track embedding: %w", err)
+	}
+
+	return nil
+}
+
+// BatchGetTrackEmbeddings retrieves the model's embeddings of the given
+// tracks, keyed by track ID. Tracks without an embedding are left out.
+func (r *DynamoDBRepository) BatchGetTrackEmbeddings(ctx context.Context, userID, model string, trackIDs []string) (map[string]models.TrackEmbedding, error) {
+	result := make(map[string]models.TrackEmbedding, len(trackIDs))
+
+	// Process in batches of 100 (DynamoDB limit)
+	for i := 0; i < len(trackIDs); i += 100 {
+		end := i + 100
+		if end > len(trackIDs) {
+			end = len(trackIDs)
+		}
+
+		keys := make([]map[string]types.AttributeValue, 0, end-i)
+		for _, trackID := range trackIDs[i:end] {
+			keys = append(keys, map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+				"SK": &types.AttributeValueMemberS{Value: models.GetTrackEmbeddingSK(model, trackID)},
+			})
+		}
+
+		// Embeddings are large, so throttled batches commonly come back with
+		// unprocessed keys; retry those rather than reporting them missing
+		request := map[string]types.KeysAndAttributes{r.tableName: {Keys: keys}}
+		for attempt := 0; len(request) > 0; attempt++ {
+			if attempt == maxBatchGetAttempts {
+				return nil, fmt.Errorf("failed to batch get track embeddings: keys still unprocessed after %d attempts", attempt)
+			}
+
+			batchResult, err := r.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				return nil, fmt.Errorf("failed to batch get track embeddings: %w", err)
+			}
+
+			var items []models.TrackEmbeddingItem
+			if err := attributevalue.UnmarshalListOfMaps(batchResult.Responses[r.tableName], &items); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal track embeddings: %w", err)
+			}
+			for _, item := range items {
+				embedding, err := item.Embedding()
+				if err != nil {
+					return nil, err
+				}
+				result[embedding.TrackID] = embedding
+			}
+
+			request = batchResult.UnprocessedKeys
+		}
+	}
+
+	return result, nil
+}
+
+// ListTrackEmbeddings retrieves every embedding a user holds for the model, or
+// for all models when model is empty
+func (r *DynamoDBRepository) ListTrackEmbeddings(ctx context.Context, userID, model string) ([]models.TrackEmbedding, error) {
+	var embeddings []models.TrackEmbedding
+	var lastKey map[string]types.AttributeValue
+
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :skPrefix)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":       &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+				":skPrefix": &types.AttributeValueMemberS{Value: models.GetTrackEmbeddingSKPrefix(model)},
+			},
+		}
+
+		if lastKey != nil {
+			input.ExclusiveStartKey = lastKey
+		}
+
+		result, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list track embeddings: %w", err)
+		}
+
+		var items []models.TrackEmbeddingItem
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal track embeddings: %w", err)
+		}
+
+		for _, item := range items {
+			embedding, err := item.Embedding()
+			if err != nil {
+				return nil, err
+			}
+			embeddings = append(embeddings, embedding)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+
+	return embeddings, nil
+}
+
+// DeleteTrackEmbedding removes a track's embedding for the model
+func (r *DynamoDBRepository) DeleteTrackEmbedding(ctx context.Context, userID, model, trackID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &types.AttributeValueMemberS{Value: models.GetTrackEmbeddingSK(model, trackID)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete track embedding: %w", err)
+	}
+
+	return nil
+}
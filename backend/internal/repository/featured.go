@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// PutFeaturedItem creates or replaces a featured item
+func (r *DynamoDBRepository) PutFeaturedItem(ctx context.Context, item models.FeaturedItem) error {
+	av, err := attributevalue.MarshalMap(models.NewFeaturedItemItem(item))
+	if err != nil {
+		return fmt.Errorf("failed to marshal featured item: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put featured item: %w", err)
+	}
+
+	return nil
+}
+
+// GetFeaturedItem retrieves a featured item
+func (r *DynamoDBRepository) GetFeaturedItem(ctx context.Context, itemID string) (*models.FeaturedItem, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       featuredItemKey(itemID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get featured item: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.FeaturedItemItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal featured item: %w", err)
+	}
+
+	return &item.FeaturedItem, nil
+}
+
+// ListFeaturedItems retrieves every featured item, whatever its schedule, in
+// no particular order
+func (r *DynamoDBRepository) ListFeaturedItems(ctx context.Context) ([]models.FeaturedItem, error) {
+	var items []models.FeaturedItemItem
+	if err := r.queryPrefix(ctx, models.FeaturedPK, "ITEM#", &items); err != nil {
+		return nil, fmt.Errorf("failed to list featured items: %w", err)
+	}
+
+	featured := make([]models.FeaturedItem, 0, len(items))
+	for _, item := range items {
+		featured = append(featured, item.FeaturedItem)
+	}
+	return featured, nil
+}
+
+// DeleteFeaturedItem removes a featured item
+func (r *DynamoDBRepository) DeleteFeaturedItem(ctx context.Context, itemID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       featuredItemKey(itemID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete featured item: %w", err)
+	}
+
+	return nil
+}
+
+func featuredItemKey(itemID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: models.FeaturedPK},
+		"SK": &types.AttributeValueMemberS{Value: models.GetFeaturedItemSK(itemID)},
+	}
+}
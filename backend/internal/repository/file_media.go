@@ -0,0 +1,573 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+)
+
+// Directories of a FileMediaStore kept beside the objects. Object keys may not
+// have segments starting with a dot, so they never reach these.
+const (
+	fileMetaDir      = ".meta"
+	fileMultipartDir = ".multipart"
+	fileTempDir      = ".tmp"
+)
+
+// metaHeaderPrefix marks the request headers stored as user metadata, as S3
+// does
+const metaHeaderPrefix = "X-Amz-Meta-"
+
+// FileMediaStore is a MediaStore over a local directory, for self-hosted
+// servers without S3. Objects are plain files under the directory, named by
+// their keys. Presigned URLs point at the store's own ServeHTTP, which the API
+// serves at baseURL, and carry a token signed with the store's keyring.
+type FileMediaStore struct {
+	dir     string
+	baseURL string
+	keys    reqsign.Keyring
+	now     func() time.Time
+}
+
+// fileObjectMeta is the sidecar file of an object
+type fileObjectMeta struct {
+	ContentType string            `json:"contentType,omitempty"`
+	ETag        string            `json:"etag"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// fileMultipartUpload is the record of a multipart upload in progress
+type fileMultipartUpload struct {
+	Key         string `json:"key"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// NewFileMediaStore creates a store over dir, creating it if needed. baseURL is
+// where the API serves the store, e.g. http://localhost:8080/media; keys sign
+// its URLs.
+func NewFileMediaStore(dir, baseURL string, keys reqsign.Keyring) (*FileMediaStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("file media store needs a signing key")
+	}
+	for _, sub := range []string{"", fileMetaDir, fileMultipartDir, fileTempDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create media directory: %w", err)
+		}
+	}
+	return &FileMediaStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		keys:    keys,
+		now:     time.Now,
+	}, nil
+}
+
+// validateFileKey rejects keys that would leave the directory or reach the
+// store's own files
+func validateFileKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.ContainsAny(key, "\\\x00") {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || strings.HasPrefix(segment, ".") {
+			return fmt.Errorf("invalid object key %q", key)
+		}
+	}
+	return nil
+}
+
+func (s *FileMediaStore) objectPath(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+func (s *FileMediaStore) metaPath(key string) string {
+	return filepath.Join(s.dir, fileMetaDir, filepath.FromSlash(key)+".json")
+}
+
+func (s *FileMediaStore) multipartPath(uploadID string) string {
+	return filepath.Join(s.dir, fileMultipartDir, uploadID)
+}
+
+// signedURL builds the URL of one operation on key. The token signs the
+// method, key and every parameter, so none can be changed.
+func (s *FileMediaStore) signedURL(method, key string, expiry time.Duration, params url.Values) (string, error) {
+	if err := validateFileKey(key); err != nil {
+		return "", err
+	}
+	subject := url.Values{"method": {method}, "key": {key}}
+	for name, values := range params {
+		subject[name] = values
+	}
+	token, err := s.keys.SignToken(subject.Encode(), s.now().Add(expiry))
+	if err != nil {
+		return "", err
+	}
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.baseURL + "/" + strings.Join(segments, "/") + "?token=" + token, nil
+}
+
+func (s *FileMediaStore) GeneratePresignedUploadURL(ctx context.Context, key, contentType string, expiry time.Duration) (string, error) {
+	return s.signedURL(http.MethodPut, key, expiry, url.Values{"contentType": {contentType}})
+}
+
+func (s *FileMediaStore) GeneratePresignedDownloadURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return s.signedURL(http.MethodGet, key, expiry, nil)
+}
+
+func (s *FileMediaStore) GeneratePresignedDownloadURLWithFilename(ctx context.Context, key string, expiry time.Duration, filename string) (string, error) {
+	disposition := sanitize.ContentDisposition(filename)
+	return s.signedURL(http.MethodGet, key, expiry, url.Values{"disposition": {disposition}})
+}
+
+func (s *FileMediaStore) InitiateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	if err := validateFileKey(key); err != nil {
+		return "", err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to initiate multipart upload: %w", err)
+	}
+	uploadID := hex.EncodeToString(id)
+
+	record, err := json.Marshal(fileMultipartUpload{Key: key, ContentType: contentType})
+	if err != nil {
+		return "", err
+	}
+	if err := os.Mkdir(s.multipartPath(uploadID), 0o755); err != nil {
+		return "", fmt.Errorf("failed to initiate multipart upload: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.multipartPath(uploadID), "upload.json"), record, 0o644); err != nil {
+		return "", fmt.Errorf("failed to initiate multipart upload: %w", err)
+	}
+	return uploadID, nil
+}
+
+func (s *FileMediaStore) GenerateMultipartUploadURLs(ctx context.Context, key, uploadID string, numParts int, expiry time.Duration) ([]models.MultipartUploadPartURL, error) {
+	if _, err := s.multipartUpload(key, uploadID); err != nil {
+		return nil, err
+	}
+
+	expiresAt := s.now().Add(expiry)
+	urls := make([]models.MultipartUploadPartURL, 0, numParts)
+	for partNumber := 1; partNumber <= numParts; partNumber++ {
+		uploadURL, err := s.signedURL(http.MethodPut, key, expiry, url.Values{
+			"uploadId":   {uploadID},
+			"partNumber": {strconv.Itoa(partNumber)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, models.MultipartUploadPartURL{
+			PartNumber: partNumber,
+			UploadURL:  uploadURL,
+			ExpiresAt:  expiresAt,
+		})
+	}
+	return urls, nil
+}
+
+// CompleteMultipartUpload joins the listed parts, in ascending part order as
+// S3 requires, into the object
+func (s *FileMediaStore) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []models.CompletedPartInfo) error {
+	upload, err := s.multipartUpload(key, uploadID)
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("failed to complete multipart upload: no parts")
+	}
+	if !sort.SliceIsSorted(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber }) {
+		return fmt.Errorf("failed to complete multipart upload: parts are not in ascending order")
+	}
+
+	if err := s.joinParts(key, uploadID, upload.ContentType, parts); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return os.RemoveAll(s.multipartPath(uploadID))
+}
+
+// joinParts writes the parts of uploadID, in order, as the object key
+func (s *FileMediaStore) joinParts(key, uploadID, contentType string, parts []models.CompletedPartInfo) error {
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		file, err := os.Open(filepath.Join(s.multipartPath(uploadID), strconv.Itoa(part.PartNumber)))
+		if err != nil {
+			return fmt.Errorf("part %d was not uploaded", part.PartNumber)
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+	return s.writeObject(key, io.MultiReader(readers...), contentType, nil)
+}
+
+func (s *FileMediaStore) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	if _, err := s.multipartUpload(key, uploadID); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return os.RemoveAll(s.multipartPath(uploadID))
+}
+
+// multipartUpload reads the record of uploadID, verifying it was initiated
+// for key
+func (s *FileMediaStore) multipartUpload(key, uploadID string) (*fileMultipartUpload, error) {
+	if uploadID == "" || strings.ContainsAny(uploadID, "/\\.") {
+		return nil, fmt.Errorf("no such upload %s for key %s: %w", uploadID, key, ErrNotFound)
+	}
+	data, err := os.ReadFile(filepath.Join(s.multipartPath(uploadID), "upload.json"))
+	if err != nil {
+		return nil, fmt.Errorf("no such upload %s for key %s: %w", uploadID, key, ErrNotFound)
+	}
+	var upload fileMultipartUpload
+	if err := json.Unmarshal(data, &upload); err != nil || upload.Key != key {
+		return nil, fmt.Errorf("no such upload %s for key %s: %w", uploadID, key, ErrNotFound)
+	}
+	return &upload, nil
+}
+
+// writeObject stores body as key through a temporary file, so readers never
+// see a partial object
+func (s *FileMediaStore) writeObject(key string, body io.Reader, contentType string, metadata map[string]string) error {
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, fileTempDir), "object-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := md5.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	meta := fileObjectMeta{ContentType: contentType, ETag: `"` + hex.EncodeToString(hash.Sum(nil)) + `"`, Metadata: metadata}
+	if err := s.writeMeta(key, meta); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.objectPath(key)), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.objectPath(key))
+}
+
+func (s *FileMediaStore) writeMeta(key string, meta fileObjectMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.metaPath(key)), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.metaPath(key), data, 0o644)
+}
+
+// readMeta returns an object's sidecar, or an empty one for files placed in
+// the directory by hand
+func (s *FileMediaStore) readMeta(key string) fileObjectMeta {
+	var meta fileObjectMeta
+	if data, err := os.ReadFile(s.metaPath(key)); err == nil {
+		_ = json.Unmarshal(data, &meta)
+	}
+	return meta
+}
+
+// DeleteObject removes an object; like S3, deleting a missing key succeeds
+func (s *FileMediaStore) DeleteObject(ctx context.Context, key string) error {
+	if err := validateFileKey(key); err != nil {
+		return err
+	}
+	if err := os.Remove(s.objectPath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	if err := os.Remove(s.metaPath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// DeleteByPrefix removes every object whose key starts with prefix
+func (s *FileMediaStore) DeleteByPrefix(ctx context.Context, prefix string) error {
+	if prefix == "" {
+		return fmt.Errorf("prefix cannot be empty")
+	}
+
+	var failed []string
+	err := filepath.WalkDir(s.dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if p != s.dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			if err := s.DeleteObject(ctx, key); err != nil {
+				failed = append(failed, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list objects: %w", err)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete %d objects under %s: %s", len(failed), prefix, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (s *FileMediaStore) CopyObject(ctx context.Context, sourceKey, destKey string) error {
+	if err := validateFileKey(sourceKey); err != nil {
+		return err
+	}
+	if err := validateFileKey(destKey); err != nil {
+		return err
+	}
+	source, err := os.Open(s.objectPath(sourceKey))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to copy object: %w", ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+	defer source.Close()
+
+	meta := s.readMeta(sourceKey)
+	if err := s.writeObject(destKey, source, meta.ContentType, meta.Metadata); err != nil {
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+	return nil
+}
+
+// ReadObject opens an object for reading; the caller closes it
+func (s *FileMediaStore) ReadObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := validateFileKey(key); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(s.objectPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read object: %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	if info, err := file.Stat(); err != nil || info.IsDir() {
+		file.Close()
+		return nil, fmt.Errorf("failed to read object: %w", ErrNotFound)
+	}
+	return file, nil
+}
+
+// WriteObject stores data as an object, replacing any already at key
+func (s *FileMediaStore) WriteObject(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := validateFileKey(key); err != nil {
+		return err
+	}
+	if err := s.writeObject(key, bytes.NewReader(data), contentType, nil); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	return nil
+}
+
+// GetObjectMetadata returns the same fields as S3's HEAD: content type,
+// length, ETag, last modified and the user metadata
+func (s *FileMediaStore) GetObjectMetadata(ctx context.Context, key string) (map[string]string, error) {
+	if err := validateFileKey(key); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(s.objectPath(key))
+	if err != nil || info.IsDir() {
+		return nil, fmt.Errorf("failed to get object metadata: %w", ErrNotFound)
+	}
+
+	meta := s.readMeta(key)
+	metadata := copyMetadata(meta.Metadata)
+	if meta.ContentType != "" {
+		metadata["content-type"] = meta.ContentType
+	}
+	if meta.ETag != "" {
+		metadata["etag"] = meta.ETag
+	}
+	metadata["content-length"] = strconv.FormatInt(info.Size(), 10)
+	metadata["last-modified"] = info.ModTime().UTC().Format(time.RFC3339)
+	return metadata, nil
+}
+
+func (s *FileMediaStore) ObjectExists(ctx context.Context, key string) (bool, error) {
+	if err := validateFileKey(key); err != nil {
+		return false, err
+	}
+	info, err := os.Stat(s.objectPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check object existence: %w", err)
+	}
+	return !info.IsDir(), nil
+}
+
+// ListObjectVersions reports a stored object as its only, unversioned
+// ("null") version; files are not versioned
+func (s *FileMediaStore) ListObjectVersions(ctx context.Context, key string) ([]models.S3ObjectVersion, error) {
+	if err := validateFileKey(key); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(s.objectPath(key))
+	if err != nil || info.IsDir() {
+		return []models.S3ObjectVersion{}, nil
+	}
+	return []models.S3ObjectVersion{{
+		Key:          key,
+		VersionID:    "null",
+		IsLatest:     true,
+		Size:         info.Size(),
+		ETag:         s.readMeta(key).ETag,
+		LastModified: info.ModTime().UTC(),
+	}}, nil
+}
+
+// ServeHTTP answers the store's presigned URLs, mounted at the path of
+// baseURL with that path stripped: GET and HEAD download an object, with
+// range requests, and PUT uploads an object or a part of a multipart upload.
+func (s *FileMediaStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	subject, err := s.keys.VerifyToken(r.URL.Query().Get("token"), s.now())
+	if err != nil {
+		http.Error(w, "invalid or expired media URL", http.StatusForbidden)
+		return
+	}
+	signed, err := url.ParseQuery(subject)
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	if err != nil || signed.Get("key") != key || signed.Get("method") != method || validateFileKey(key) != nil {
+		http.Error(w, "media URL does not match the request", http.StatusForbidden)
+		return
+	}
+
+	switch method {
+	case http.MethodGet:
+		s.serveObject(w, r, key, signed.Get("disposition"))
+	case http.MethodPut:
+		if signed.Get("contentType") != "" && r.Header.Get("Content-Type") != signed.Get("contentType") {
+			http.Error(w, "Content-Type does not match the media URL", http.StatusForbidden)
+			return
+		}
+		if uploadID := signed.Get("uploadId"); uploadID != "" {
+			s.storePart(w, r, key, uploadID, signed.Get("partNumber"))
+			return
+		}
+		s.storeObject(w, r, key)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *FileMediaStore) serveObject(w http.ResponseWriter, r *http.Request, key, disposition string) {
+	file, err := os.Open(s.objectPath(key))
+	if err != nil {
+		http.Error(w, "object not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.Error(w, "object not found", http.StatusNotFound)
+		return
+	}
+
+	meta := s.readMeta(key)
+	if meta.ContentType != "" {
+		w.Header().Set("Content-Type", meta.ContentType)
+	}
+	if meta.ETag != "" {
+		w.Header().Set("ETag", meta.ETag)
+	}
+	if disposition != "" {
+		w.Header().Set("Content-Disposition", disposition)
+	}
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
+
+func (s *FileMediaStore) storeObject(w http.ResponseWriter, r *http.Request, key string) {
+	metadata := make(map[string]string)
+	for name, values := range r.Header {
+		if strings.HasPrefix(name, metaHeaderPrefix) && len(values) > 0 {
+			metadata[strings.ToLower(strings.TrimPrefix(name, metaHeaderPrefix))] = values[0]
+		}
+	}
+	if err := s.writeObject(key, r.Body, r.Header.Get("Content-Type"), metadata); err != nil {
+		http.Error(w, "failed to store object", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", s.readMeta(key).ETag)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *FileMediaStore) storePart(w http.ResponseWriter, r *http.Request, key, uploadID, partNumber string) {
+	if _, err := s.multipartUpload(key, uploadID); err != nil {
+		http.Error(w, "no such upload", http.StatusNotFound)
+		return
+	}
+	number, err := strconv.Atoi(partNumber)
+	if err != nil || number < 1 {
+		http.Error(w, "invalid part number", http.StatusBadRequest)
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, fileTempDir), "part-*")
+	if err != nil {
+		http.Error(w, "failed to store part", http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	hash := md5.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), r.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(s.multipartPath(uploadID), strconv.Itoa(number)))
+	}
+	if err != nil {
+		http.Error(w, "failed to store part", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", `"`+hex.EncodeToString(hash.Sum(nil))+`"`)
+	w.WriteHeader(http.StatusOK)
+}
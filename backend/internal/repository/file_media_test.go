@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFileMediaTest(t *testing.T) (*FileMediaStore, *httptest.Server) {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	keys := reqsign.Keyring{{ID: "k1", Secret: []byte("media-secret-0123456789abcdef0123")}}
+	store, err := NewFileMediaStore(t.TempDir(), server.URL+"/media", keys)
+	require.NoError(t, err)
+	mux.Handle("/media/", http.StripPrefix("/media", store))
+	return store, server
+}
+
+func doMediaRequest(t *testing.T, method, url, contentType, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestFileMediaStore(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newFileMediaTest(t)
+
+	t.Run("uploads and downloads through signed URLs", func(t *testing.T) {
+		uploadURL, err := store.GeneratePresignedUploadURL(ctx, "uploads/u1/my song.mp3", "audio/mpeg", time.Minute)
+		require.NoError(t, err)
+		resp := doMediaRequest(t, http.MethodPut, uploadURL, "audio/mpeg", "ID3 audio")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		metadata, err := store.GetObjectMetadata(ctx, "uploads/u1/my song.mp3")
+		require.NoError(t, err)
+		assert.Equal(t, "audio/mpeg", metadata["content-type"])
+		assert.Equal(t, "9", metadata["content-length"])
+		assert.Equal(t, resp.Header.Get("ETag"), metadata["etag"])
+
+		downloadURL, err := store.GeneratePresignedDownloadURLWithFilename(ctx, "uploads/u1/my song.mp3", time.Minute, "My Song.mp3")
+		require.NoError(t, err)
+		resp = doMediaRequest(t, http.MethodGet, downloadURL, "", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "ID3 audio", string(body))
+		assert.Equal(t, "audio/mpeg", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "My Song.mp3")
+	})
+
+	t.Run("rejects URLs that were changed or have expired", func(t *testing.T) {
+		downloadURL, err := store.GeneratePresignedDownloadURL(ctx, "uploads/u1/my song.mp3", time.Minute)
+		require.NoError(t, err)
+
+		otherKey := strings.Replace(downloadURL, "my%20song.mp3", "other.mp3", 1)
+		assert.Equal(t, http.StatusForbidden, doMediaRequest(t, http.MethodGet, otherKey, "", "").StatusCode)
+		assert.Equal(t, http.StatusForbidden, doMediaRequest(t, http.MethodPut, downloadURL, "audio/mpeg", "x").StatusCode, "a download URL cannot upload")
+
+		uploadURL, err := store.GeneratePresignedUploadURL(ctx, "uploads/u1/b.mp3", "audio/mpeg", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, doMediaRequest(t, http.MethodPut, uploadURL, "text/html", "x").StatusCode, "the content type is signed")
+
+		store.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		defer func() { store.now = time.Now }()
+		assert.Equal(t, http.StatusForbidden, doMediaRequest(t, http.MethodGet, downloadURL, "", "").StatusCode)
+	})
+
+	t.Run("joins multipart uploads in part order", func(t *testing.T) {
+		uploadID, err := store.InitiateMultipartUpload(ctx, "uploads/u1/big.flac", "audio/flac")
+		require.NoError(t, err)
+		urls, err := store.GenerateMultipartUploadURLs(ctx, "uploads/u1/big.flac", uploadID, 2, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, urls, 2)
+
+		var parts []models.CompletedPartInfo
+		for i, body := range []string{"first-", "second"} {
+			resp := doMediaRequest(t, http.MethodPut, urls[i].UploadURL, "", body)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			parts = append(parts, models.CompletedPartInfo{PartNumber: urls[i].PartNumber, ETag: resp.Header.Get("ETag")})
+		}
+
+		err = store.CompleteMultipartUpload(ctx, "uploads/u1/big.flac", uploadID, []models.CompletedPartInfo{parts[1], parts[0]})
+		assert.ErrorContains(t, err, "ascending")
+		require.NoError(t, store.CompleteMultipartUpload(ctx, "uploads/u1/big.flac", uploadID, parts))
+
+		metadata, err := store.GetObjectMetadata(ctx, "uploads/u1/big.flac")
+		require.NoError(t, err)
+		assert.Equal(t, "12", metadata["content-length"])
+		assert.Equal(t, "audio/flac", metadata["content-type"])
+
+		err = store.AbortMultipartUpload(ctx, "uploads/u1/big.flac", uploadID)
+		assert.ErrorIs(t, err, ErrNotFound, "a completed upload is gone")
+	})
+
+	t.Run("copies and deletes objects", func(t *testing.T) {
+		require.NoError(t, store.CopyObject(ctx, "uploads/u1/big.flac", "media/u1/t1.flac"))
+		exists, err := store.ObjectExists(ctx, "media/u1/t1.flac")
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.ErrorIs(t, store.CopyObject(ctx, "uploads/u1/missing.flac", "media/u1/t2.flac"), ErrNotFound)
+
+		require.NoError(t, store.DeleteByPrefix(ctx, "uploads/u1/"))
+		for _, key := range []string{"uploads/u1/my song.mp3", "uploads/u1/big.flac"} {
+			exists, err := store.ObjectExists(ctx, key)
+			require.NoError(t, err)
+			assert.False(t, exists, key)
+		}
+		_, err = store.GetObjectMetadata(ctx, "uploads/u1/big.flac")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		versions, err := store.ListObjectVersions(ctx, "media/u1/t1.flac")
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		assert.Equal(t, int64(12), versions[0].Size)
+
+		require.NoError(t, store.DeleteObject(ctx, "media/u1/t1.flac"))
+		require.NoError(t, store.DeleteObject(ctx, "media/u1/t1.flac"), "deleting a missing object succeeds")
+	})
+
+	t.Run("reads and writes objects", func(t *testing.T) {
+		require.NoError(t, store.WriteObject(ctx, "covers/u1/t1-thumb.jpg", []byte("jpeg"), "image/jpeg"))
+		metadata, err := store.GetObjectMetadata(ctx, "covers/u1/t1-thumb.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", metadata["content-type"])
+
+		body, err := store.ReadObject(ctx, "covers/u1/t1-thumb.jpg")
+		require.NoError(t, err)
+		data, err := io.ReadAll(body)
+		body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", string(data))
+
+		_, err = store.ReadObject(ctx, "covers/u1/missing.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = store.ReadObject(ctx, "covers/u1")
+		assert.ErrorIs(t, err, ErrNotFound, "directories are not objects")
+	})
+
+	t.Run("keys stay inside the directory", func(t *testing.T) {
+		for _, key := range []string{"", "/etc/passwd", "media/../../secret", ".meta/a.json", "media//a", `media\a`} {
+			_, err := store.ObjectExists(ctx, key)
+			assert.Error(t, err, key)
+		}
+	})
+}
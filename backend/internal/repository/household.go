@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// CreateHousehold creates a household with its owner as the first member.
+// The owner's profile is linked in the same transaction; it fails with
+// ErrAlreadyExists if the owner already belongs to a household.
+func (r *DynamoDBRepository) CreateHousehold(ctx context.Context, household models.Household, owner models.HouseholdMember) error {
+	householdAV, err := attributevalue.MarshalMap(models.NewHouseholdItem(household))
+	if err != nil {
+		return fmt.Errorf("failed to marshal household: %w", err)
+	}
+	memberAV, err := attributevalue.MarshalMap(models.NewHouseholdMemberItem(owner))
+	if err != nil {
+		return fmt.Errorf("failed to marshal household member: %w", err)
+	}
+	linkUser, err := r.linkUserToHousehold(owner.UserID, household.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:           aws.String(r.tableName),
+				Item:                householdAV,
+				ConditionExpression: aws.String("attribute_not_exists(PK)"),
+			}},
+			{Put: &types.Put{
+				TableName: aws.String(r.tableName),
+				Item:      memberAV,
+			}},
+			{Update: linkUser},
+		},
+	})
+	if err != nil {
+		if isTransactionCanceled(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to create household: %w", err)
+	}
+
+	return nil
+}
+
+// GetHousehold retrieves a household by ID
+func (r *DynamoDBRepository) GetHousehold(ctx context.Context, householdID string) (*models.Household, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.GetHouseholdPK(householdID)},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.HouseholdItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal household: %w", err)
+	}
+
+	return &item.Household, nil
+}
+
+// UpdateHousehold overwrites an existing household's metadata
+func (r *DynamoDBRepository) UpdateHousehold(ctx context.Context, household models.Household) error {
+	household.UpdatedAt = time.Now()
+
+	av, err := attributevalue.MarshalMap(models.NewHouseholdItem(household))
+	if err != nil {
+		return fmt.Errorf("failed to marshal household: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if ok := isConditionalCheckFailed(err, &condErr); ok {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update household: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteHousehold removes a household, its member records and the household link
+// on every member's profile in a single transaction.
+func (r *DynamoDBRepository) DeleteHousehold(ctx context.Context, householdID string, memberIDs []string) error {
+	items := []types.TransactWriteItem{
+		{Delete: &types.Delete{
+			TableName: aws.String(r.tableName),
+			Key: map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: models.GetHouseholdPK(householdID)},
+				"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+			},
+		}},
+	}
+	for _, userID := range memberIDs {
+		items = append(items,
+			types.TransactWriteItem{Delete: &types.Delete{
+				TableName: aws.String(r.tableName),
+				Key: map[string]types.AttributeValue{
+					"PK": &types.AttributeValueMemberS{Value: models.GetHouseholdPK(householdID)},
+					"SK": &types.AttributeValueMemberS{Value: models.GetHouseholdMemberSK(userID)},
+				},
+			}},
+			types.TransactWriteItem{Update: r.unlinkUserFromHousehold(userID)},
+		)
+	}
+
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		return fmt.Errorf("failed to delete household: %w", err)
+	}
+
+	return nil
+}
+
+// AddHouseholdMember attaches a user to a household, links their profile and
+// increments the member count. Fails with ErrAlreadyExists if the user already
+// belongs to a household.
+func (r *DynamoDBRepository) AddHouseholdMember(ctx context.Context, member models.HouseholdMember) error {
+	memberAV, err := attributevalue.MarshalMap(models.NewHouseholdMemberItem(member))
+	if err != nil {
+		return fmt.Errorf("failed to marshal household member: %w", err)
+	}
+	linkUser, err := r.linkUserToHousehold(member.UserID, member.HouseholdID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:           aws.String(r.tableName),
+				Item:                memberAV,
+				ConditionExpression: aws.String("attribute_not_exists(PK)"),
+			}},
+			{Update: linkUser},
+			{Update: r.adjustHouseholdMemberCount(member.HouseholdID, 1)},
+		},
+	})
+	if err != nil {
+		if isTransactionCanceled(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to add household member: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveHouseholdMember detaches a user from a household and unlinks their profile
+func (r *DynamoDBRepository) RemoveHouseholdMember(ctx context.Context, householdID, userID string) error {
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Delete: &types.Delete{
+				TableName: aws.String(r.tableName),
+				Key: map[string]types.AttributeValue{
+					"PK": &types.AttributeValueMemberS{Value: models.GetHouseholdPK(householdID)},
+					"SK": &types.AttributeValueMemberS{Value: models.GetHouseholdMemberSK(userID)},
+				},
+				ConditionExpression: aws.String("attribute_exists(PK)"),
+			}},
+			{Update: r.unlinkUserFromHousehold(userID)},
+			{Update: r.adjustHouseholdMemberCount(householdID, -1)},
+		},
+	})
+	if err != nil {
+		if isTransactionCanceled(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to remove household member: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateHouseholdMember overwrites an existing member record (e.g. a role change)
+func (r *DynamoDBRepository) UpdateHouseholdMember(ctx context.Context, member models.HouseholdMember) error {
+	av, err := attributevalue.MarshalMap(models.NewHouseholdMemberItem(member))
+	if err != nil {
+		return fmt.Errorf("failed to marshal household member: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if ok := isConditionalCheckFailed(err, &condErr); ok {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update household member: %w", err)
+	}
+
+	return nil
+}
+
+// GetHouseholdMember retrieves a single member of a household
+func (r *DynamoDBRepository) GetHouseholdMember(ctx context.Context, householdID, userID string) (*models.HouseholdMember, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.GetHouseholdPK(householdID)},
+			"SK": &types.AttributeValueMemberS{Value: models.GetHouseholdMemberSK(userID)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household member: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.HouseholdMemberItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal household member: %w", err)
+	}
+
+	return &item.HouseholdMember, nil
+}
+
+// ListHouseholdMembers lists all members of a household
+func (r *DynamoDBRepository) ListHouseholdMembers(ctx context.Context, householdID string) ([]models.HouseholdMember, error) {
+	keyCondition := expression.Key("PK").Equal(expression.Value(models.GetHouseholdPK(householdID))).
+		And(expression.Key("SK").BeginsWith("MEMBER#"))
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list household members: %w", err)
+	}
+
+	members := make([]models.HouseholdMember, 0, len(result.Items))
+	for _, item := range result.Items {
+		var memberItem models.HouseholdMemberItem
+		if err := attributevalue.UnmarshalMap(item, &memberItem); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal household member: %w", err)
+		}
+		members = append(members, memberItem.HouseholdMember)
+	}
+
+	return members, nil
+}
+
+// linkUserToHousehold builds an update that sets householdId on a user profile,
+// guarded so a user can only belong to one household at a time.
+func (r *DynamoDBRepository) linkUserToHousehold(userID, householdID string) (*types.Update, error) {
+	update := expression.Set(expression.Name("householdId"), expression.Value(householdID)).
+		Set(expression.Name("updatedAt"), expression.Value(time.Now().Format(time.RFC3339)))
+	condition := expression.AttributeExists(expression.Name("PK")).
+		And(expression.AttributeNotExists(expression.Name("householdId")))
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	return &types.Update{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &types.AttributeValueMemberS{Value: "PROFILE"},
+		},
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}, nil
+}
+
+// unlinkUserFromHousehold builds an update that removes householdId from a user profile
+func (r *DynamoDBRepository) unlinkUserFromHousehold(userID string) *types.Update {
+	return &types.Update{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &types.AttributeValueMemberS{Value: "PROFILE"},
+		},
+		UpdateExpression: aws.String("REMOVE householdId"),
+	}
+}
+
+// adjustHouseholdMemberCount builds an update that adds delta to a household's member count
+func (r *DynamoDBRepository) adjustHouseholdMemberCount(householdID string, delta int) *types.Update {
+	return &types.Update{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.GetHouseholdPK(householdID)},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+		UpdateExpression:    aws.String("ADD memberCount :delta"),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", delta)},
+		},
+	}
+}
+
+// isTransactionCanceled returns true if a transactional write was rejected,
+// typically because one of its condition checks failed.
+func isTransactionCanceled(err error) bool {
+	var canceled *types.TransactionCanceledException
+	return errors.As(err, &canceled)
+}
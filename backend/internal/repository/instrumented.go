@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// CallObserver is told the duration and outcome of every call an
+// instrumented client makes; service is "dynamodb" or "s3"
+type CallObserver func(service, operation string, d time.Duration, err error)
+
+// observe times fn and reports it to observer
+func observe[T any](observer CallObserver, service, operation string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	out, err := fn()
+	observer(service, operation, time.Since(start), err)
+	return out, err
+}
+
+// InstrumentedDynamoDBClient decorates a DynamoDBClient, reporting each
+// call's latency to a CallObserver
+type InstrumentedDynamoDBClient struct {
+	inner    DynamoDBClient
+	observer CallObserver
+}
+
+// NewInstrumentedDynamoDBClient wraps client so every call is reported to observer
+func NewInstrumentedDynamoDBClient(client DynamoDBClient, observer CallObserver) *InstrumentedDynamoDBClient {
+	return &InstrumentedDynamoDBClient{inner: client, observer: observer}
+}
+
+func (c *InstrumentedDynamoDBClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return observe(c.observer, "dynamodb", "PutItem", func() (*dynamodb.PutItemOutput, error) {
+		return c.inner.PutItem(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedDynamoDBClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return observe(c.observer, "dynamodb", "GetItem", func() (*dynamodb.GetItemOutput, error) {
+		return c.inner.GetItem(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedDynamoDBClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return observe(c.observer, "dynamodb", "UpdateItem", func() (*dynamodb.UpdateItemOutput, error) {
+		return c.inner.UpdateItem(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedDynamoDBClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return observe(c.observer, "dynamodb", "DeleteItem", func() (*dynamodb.DeleteItemOutput, error) {
+		return c.inner.DeleteItem(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedDynamoDBClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return observe(c.observer, "dynamodb", "Query", func() (*dynamodb.QueryOutput, error) {
+		return c.inner.Query(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedDynamoDBClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return observe(c.observer, "dynamodb", "Scan", func() (*dynamodb.ScanOutput, error) {
+		return c.inner.Scan(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedDynamoDBClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return observe(c.observer, "dynamodb", "BatchWriteItem", func() (*dynamodb.BatchWriteItemOutput, error) {
+		return c.inner.BatchWriteItem(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedDynamoDBClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return observe(c.observer, "dynamodb", "BatchGetItem", func() (*dynamodb.BatchGetItemOutput, error) {
+		return c.inner.BatchGetItem(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedDynamoDBClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return observe(c.observer, "dynamodb", "TransactWriteItems", func() (*dynamodb.TransactWriteItemsOutput, error) {
+		return c.inner.TransactWriteItems(ctx, params, optFns...)
+	})
+}
+
+// InstrumentedS3Client decorates an S3Client, reporting each call's latency
+// to a CallObserver
+type InstrumentedS3Client struct {
+	inner    S3Client
+	observer CallObserver
+}
+
+// NewInstrumentedS3Client wraps client so every call is reported to observer
+func NewInstrumentedS3Client(client S3Client, observer CallObserver) *InstrumentedS3Client {
+	return &InstrumentedS3Client{inner: client, observer: observer}
+}
+
+func (c *InstrumentedS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	return observe(c.observer, "s3", "PutObject", func() (*s3.PutObjectOutput, error) {
+		return c.inner.PutObject(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return observe(c.observer, "s3", "GetObject", func() (*s3.GetObjectOutput, error) {
+		return c.inner.GetObject(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	return observe(c.observer, "s3", "DeleteObject", func() (*s3.DeleteObjectOutput, error) {
+		return c.inner.DeleteObject(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	return observe(c.observer, "s3", "DeleteObjects", func() (*s3.DeleteObjectsOutput, error) {
+		return c.inner.DeleteObjects(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return observe(c.observer, "s3", "ListObjectsV2", func() (*s3.ListObjectsV2Output, error) {
+		return c.inner.ListObjectsV2(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	return observe(c.observer, "s3", "ListObjectVersions", func() (*s3.ListObjectVersionsOutput, error) {
+		return c.inner.ListObjectVersions(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	return observe(c.observer, "s3", "CopyObject", func() (*s3.CopyObjectOutput, error) {
+		return c.inner.CopyObject(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return observe(c.observer, "s3", "HeadObject", func() (*s3.HeadObjectOutput, error) {
+		return c.inner.HeadObject(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return observe(c.observer, "s3", "CreateMultipartUpload", func() (*s3.CreateMultipartUploadOutput, error) {
+		return c.inner.CreateMultipartUpload(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return observe(c.observer, "s3", "CompleteMultipartUpload", func() (*s3.CompleteMultipartUploadOutput, error) {
+		return c.inner.CompleteMultipartUpload(ctx, params, optFns...)
+	})
+}
+
+func (c *InstrumentedS3Client) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return observe(c.observer, "s3", "AbortMultipartUpload", func() (*s3.AbortMultipartUploadOutput, error) {
+		return c.inner.AbortMultipartUpload(ctx, params, optFns...)
+	})
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingGetDynamoDBClient fails GetItem and records PutItem
+type failingGetDynamoDBClient struct {
+	recordingDynamoDBClient
+}
+
+func (c *failingGetDynamoDBClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return nil, errors.New("throttled")
+}
+
+func TestInstrumentedDynamoDBClient_ObservesCalls(t *testing.T) {
+	type call struct {
+		service, operation string
+		failed             bool
+	}
+	var calls []call
+	observer := func(service, operation string, d time.Duration, err error) {
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		calls = append(calls, call{service, operation, err != nil})
+	}
+
+	inner := &failingGetDynamoDBClient{}
+	client := NewInstrumentedDynamoDBClient(inner, observer)
+	ctx := context.Background()
+
+	put := &dynamodb.PutItemInput{}
+	_, err := client.PutItem(ctx, put)
+	assert.NoError(t, err)
+	assert.Same(t, put, inner.put, "inputs are forwarded unchanged")
+
+	_, err = client.GetItem(ctx, &dynamodb.GetItemInput{})
+	assert.EqualError(t, err, "throttled", "errors are returned unchanged")
+
+	assert.Equal(t, []call{
+		{"dynamodb", "PutItem", false},
+		{"dynamodb", "GetItem", true},
+	}, calls)
+}
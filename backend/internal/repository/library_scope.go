@@ -0,0 +1,544 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// LibraryResolver maps a user ID to the ID of the library partition that user
+// reads and writes. Users outside a household resolve to themselves.
+type LibraryResolver func(ctx context.Context, userID string) (string, error)
+
+// LibraryScopedRepository decorates a Repository so that library data (tracks,
+// albums, artists, tags and uploads) is keyed by the caller's library ID rather
+// than their user ID. This lets household members share one library partition
+// while profiles, settings, follows and playlists stay per-user.
+type LibraryScopedRepository struct {
+	Repository
+	resolve LibraryResolver
+}
+
+// NewLibraryScopedRepository wraps repo with household library scoping
+func NewLibraryScopedRepository(repo Repository, resolve LibraryResolver) *LibraryScopedRepository {
+	return &LibraryScopedRepository{
+		Repository: repo,
+		resolve:    resolve,
+	}
+}
+
+// ResolveLibraryID returns the library partition for a user
+func (r *LibraryScopedRepository) ResolveLibraryID(ctx context.Context, userID string) (string, error) {
+	if r.resolve == nil || userID == "" {
+		return userID, nil
+	}
+	return r.resolve(ctx, userID)
+}
+
+// Track operations
+
+func (r *LibraryScopedRepository) CreateTrack(ctx context.Context, track models.Track) error {
+	libraryID, err := r.ResolveLibraryID(ctx, track.UserID)
+	if err != nil {
+		return err
+	}
+	track.UserID = libraryID
+	return r.Repository.CreateTrack(ctx, track)
+}
+
+func (r *LibraryScopedRepository) GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.GetTrack(ctx, libraryID, trackID)
+}
+
+func (r *LibraryScopedRepository) DeleteTrack(ctx context.Context, userID, trackID string) error {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.Repository.DeleteTrack(ctx, libraryID, trackID)
+}
+
+func (r *LibraryScopedRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*PaginatedResult[models.Track], error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.ListTracks(ctx, libraryID, filter)
+}
+
+// harmonicIndex is the optional key/tempo index of the wrapped repository
+type harmonicIndex interface {
+	ListTracksByKeyAndBPMBucket(ctx context.Context, userID, camelotKey string, minBucket, maxBucket int) ([]models.Track, error)
+}
+
+// ListTracksByKeyAndBPMBucket fails with ErrNotFound when the wrapped repository
+// has no harmonic index
+func (r *LibraryScopedRepository) ListTracksByKeyAndBPMBucket(ctx context.Context, userID, camelotKey string, minBucket, maxBucket int) ([]models.Track, error) {
+	index, ok := r.Repository.(harmonicIndex)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return index.ListTracksByKeyAndBPMBucket(ctx, libraryID, camelotKey, minBucket, maxBucket)
+}
+
+// contentHashIndex is the optional content hash lookup of the wrapped repository
+type contentHashIndex interface {
+	FindTracksByContentHash(ctx context.Context, userID, contentHash string) ([]models.Track, error)
+}
+
+// FindTracksByContentHash fails with ErrNotFound when the wrapped repository
+// cannot look tracks up by content hash
+func (r *LibraryScopedRepository) FindTracksByContentHash(ctx context.Context, userID, contentHash string) ([]models.Track, error) {
+	index, ok := r.Repository.(contentHashIndex)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return index.FindTracksByContentHash(ctx, libraryID, contentHash)
+}
+
+// sortKeyStore is the optional alphabetical browse index of the wrapped repository
+type sortKeyStore interface {
+	SetSortLocale(ctx context.Context, userID, locale string) (int, error)
+}
+
+// SetSortLocale rekeys the caller's library; it fails with ErrNotFound when
+// the wrapped repository keeps no sort keys
+func (r *LibraryScopedRepository) SetSortLocale(ctx context.Context, userID, locale string) (int, error) {
+	store, ok := r.Repository.(sortKeyStore)
+	if !ok {
+		return 0, ErrNotFound
+	}
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return store.SetSortLocale(ctx, libraryID, locale)
+}
+
+// trackNeighborStore is the optional neighbor cache of the wrapped repository
+type trackNeighborStore interface {
+	GetTrackNeighbors(ctx context.Context, userID, trackID string) (*models.TrackNeighbors, error)
+	PutTrackNeighbors(ctx context.Context, neighbors models.TrackNeighbors) error
+}
+
+// GetTrackNeighbors returns ErrNotFound when the wrapped repository has no neighbor cache
+func (r *LibraryScopedRepository) GetTrackNeighbors(ctx context.Context, userID, trackID string) (*models.TrackNeighbors, error) {
+	store, ok := r.Repository.(trackNeighborStore)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetTrackNeighbors(ctx, libraryID, trackID)
+}
+
+// PutTrackNeighbors is a no-op when the wrapped repository has no neighbor cache
+func (r *LibraryScopedRepository) PutTrackNeighbors(ctx context.Context, neighbors models.TrackNeighbors) error {
+	store, ok := r.Repository.(trackNeighborStore)
+	if !ok {
+		return nil
+	}
+	libraryID, err := r.ResolveLibraryID(ctx, neighbors.UserID)
+	if err != nil {
+		return err
+	}
+	neighbors.UserID = libraryID
+	return store.PutTrackNeighbors(ctx, neighbors)
+}
+
+// trackEmbeddingStore is the optional embedding store of the wrapped repository
+type trackEmbeddingStore interface {
+	PutTrackEmbedding(ctx context.Context, embedding models.TrackEmbedding) error
+	BatchGetTrackEmbeddings(ctx context.Context, userID, model string, trackIDs []string) (map[string]models.TrackEmbedding, error)
+	ListTrackEmbeddings(ctx context.Context, userID, model string) ([]models.TrackEmbedding, error)
+	DeleteTrackEmbedding(ctx context.Context, userID, model, trackID string) error
+}
+
+// embeddingStore returns the wrapped repository's embedding store and the
+// library the user's embeddings live in. It fails with ErrNotFound when the
+// wrapped repository stores no embeddings.
+func (r *LibraryScopedRepository) embeddingStore(ctx context.Context, userID string) (trackEmbeddingStore, string, error) {
+	store, ok := r.Repository.(trackEmbeddingStore)
+	if !ok {
+		return nil, "", ErrNotFound
+	}
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	return store, libraryID, nil
+}
+
+func (r *LibraryScopedRepository) PutTrackEmbedding(ctx context.Context, embedding models.TrackEmbedding) error {
+	store, libraryID, err := r.embeddingStore(ctx, embedding.UserID)
+	if err != nil {
+		return err
+	}
+	embedding.UserID = libraryID
+	return store.PutTrackEmbedding(ctx, embedding)
+}
+
+func (r *LibraryScopedRepository) BatchGetTrackEmbeddings(ctx context.Context, userID, model string, trackIDs []string) (map[string]models.TrackEmbedding, error) {
+	store, libraryID, err := r.embeddingStore(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return store.BatchGetTrackEmbeddings(ctx, libraryID, model, trackIDs)
+}
+
+func (r *LibraryScopedRepository) ListTrackEmbeddings(ctx context.Context, userID, model string) ([]models.TrackEmbedding, error) {
+	store, libraryID, err := r.embeddingStore(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return store.ListTrackEmbeddings(ctx, libraryID, model)
+}
+
+func (r *LibraryScopedRepository) DeleteTrackEmbedding(ctx context.Context, userID, model, trackID string) error {
+	store, libraryID, err := r.embeddingStore(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return store.DeleteTrackEmbedding(ctx, libraryID, model, trackID)
+}
+
+// itemCounter is the optional Select=COUNT support of the wrapped repository
+type itemCounter interface {
+	CountTracks(ctx context.Context, userID string) (int, error)
+	CountAllTracks(ctx context.Context) (int, error)
+	CountAlbums(ctx context.Context, userID string) (int, error)
+	CountPlaylists(ctx context.Context, userID string) (int, error)
+}
+
+// counter returns the wrapped repository's item counter, failing with
+// ErrNotFound when it cannot count without listing
+func (r *LibraryScopedRepository) counter() (itemCounter, error) {
+	counter, ok := r.Repository.(itemCounter)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return counter, nil
+}
+
+func (r *LibraryScopedRepository) CountTracks(ctx context.Context, userID string) (int, error) {
+	counter, err := r.counter()
+	if err != nil {
+		return 0, err
+	}
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return counter.CountTracks(ctx, libraryID)
+}
+
+func (r *LibraryScopedRepository) CountAllTracks(ctx context.Context) (int, error) {
+	counter, err := r.counter()
+	if err != nil {
+		return 0, err
+	}
+	return counter.CountAllTracks(ctx)
+}
+
+func (r *LibraryScopedRepository) CountAlbums(ctx context.Context, userID string) (int, error) {
+	counter, err := r.counter()
+	if err != nil {
+		return 0, err
+	}
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return counter.CountAlbums(ctx, libraryID)
+}
+
+// CountPlaylists is not library scoped; playlists stay with their owner
+func (r *LibraryScopedRepository) CountPlaylists(ctx context.Context, userID string) (int, error) {
+	counter, err := r.counter()
+	if err != nil {
+		return 0, err
+	}
+	return counter.CountPlaylists(ctx, userID)
+}
+
+// trackBatchGetter is the optional batch track read of the wrapped repository
+type trackBatchGetter interface {
+	BatchGetTracks(ctx context.Context, userID string, trackIDs []string) (map[string]models.Track, error)
+}
+
+func (r *LibraryScopedRepository) BatchGetTracks(ctx context.Context, userID string, trackIDs []string) (map[string]models.Track, error) {
+	getter, ok := r.Repository.(trackBatchGetter)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return getter.BatchGetTracks(ctx, libraryID, trackIDs)
+}
+
+func (r *LibraryScopedRepository) ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.Track, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.ListTracksByArtist(ctx, libraryID, artist)
+}
+
+func (r *LibraryScopedRepository) UpdateTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.Repository.UpdateTrackVisibility(ctx, libraryID, trackID, visibility)
+}
+
+// Album operations
+
+func (r *LibraryScopedRepository) GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.GetOrCreateAlbum(ctx, libraryID, albumName, artist)
+}
+
+func (r *LibraryScopedRepository) GetAlbum(ctx context.Context, userID, albumID string) (*models.Album, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.GetAlbum(ctx, libraryID, albumID)
+}
+
+func (r *LibraryScopedRepository) ListAlbums(ctx context.Context, userID string, filter models.AlbumFilter) (*PaginatedResult[models.Album], error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.ListAlbums(ctx, libraryID, filter)
+}
+
+func (r *LibraryScopedRepository) ListAlbumsByArtist(ctx context.Context, userID, artist string) ([]models.Album, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.ListAlbumsByArtist(ctx, libraryID, artist)
+}
+
+func (r *LibraryScopedRepository) UpdateAlbumStats(ctx context.Context, userID, albumID string, trackCount, totalDuration int) error {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.Repository.UpdateAlbumStats(ctx, libraryID, albumID, trackCount, totalDuration)
+}
+
+// Artist operations
+
+func (r *LibraryScopedRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
+	libraryID, err := r.ResolveLibraryID(ctx, artist.UserID)
+	if err != nil {
+		return err
+	}
+	artist.UserID = libraryID
+	return r.Repository.CreateArtist(ctx, artist)
+}
+
+func (r *LibraryScopedRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.GetArtist(ctx, libraryID, artistID)
+}
+
+func (r *LibraryScopedRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.GetArtistByName(ctx, libraryID, name)
+}
+
+func (r *LibraryScopedRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*PaginatedResult[models.Artist], error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.ListArtists(ctx, libraryID, filter)
+}
+
+func (r *LibraryScopedRepository) DeleteArtist(ctx context.Context, userID, artistID string) error {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.Repository.DeleteArtist(ctx, libraryID, artistID)
+}
+
+func (r *LibraryScopedRepository) BatchGetArtists(ctx context.Context, userID string, artistIDs []string) (map[string]*models.Artist, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.BatchGetArtists(ctx, libraryID, artistIDs)
+}
+
+func (r *LibraryScopedRepository) SearchArtists(ctx context.Context, userID, query string, limit int) ([]*models.Artist, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.SearchArtists(ctx, libraryID, query, limit)
+}
+
+func (r *LibraryScopedRepository) GetArtistTrackCount(ctx context.Context, userID, artistID string) (int, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return r.Repository.GetArtistTrackCount(ctx, libraryID, artistID)
+}
+
+func (r *LibraryScopedRepository) GetArtistAlbumCount(ctx context.Context, userID, artistID string) (int, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return r.Repository.GetArtistAlbumCount(ctx, libraryID, artistID)
+}
+
+func (r *LibraryScopedRepository) GetArtistTotalPlays(ctx context.Context, userID, artistID string) (int, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return r.Repository.GetArtistTotalPlays(ctx, libraryID, artistID)
+}
+
+// Tag operations
+
+func (r *LibraryScopedRepository) CreateTag(ctx context.Context, tag models.Tag) error {
+	libraryID, err := r.ResolveLibraryID(ctx, tag.UserID)
+	if err != nil {
+		return err
+	}
+	tag.UserID = libraryID
+	return r.Repository.CreateTag(ctx, tag)
+}
+
+func (r *LibraryScopedRepository) GetTag(ctx context.Context, userID, tagName string) (*models.Tag, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.GetTag(ctx, libraryID, tagName)
+}
+
+func (r *LibraryScopedRepository) DeleteTag(ctx context.Context, userID, tagName string) error {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.Repository.DeleteTag(ctx, libraryID, tagName)
+}
+
+func (r *LibraryScopedRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.ListTags(ctx, libraryID)
+}
+
+func (r *LibraryScopedRepository) AddTagsToTrack(ctx context.Context, userID, trackID string, tagNames []string) error {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.Repository.AddTagsToTrack(ctx, libraryID, trackID, tagNames)
+}
+
+func (r *LibraryScopedRepository) RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.Repository.RemoveTagFromTrack(ctx, libraryID, trackID, tagName)
+}
+
+func (r *LibraryScopedRepository) GetTrackTags(ctx context.Context, userID, trackID string) ([]string, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.GetTrackTags(ctx, libraryID, trackID)
+}
+
+func (r *LibraryScopedRepository) GetTracksByTag(ctx context.Context, userID, tagName string) ([]models.Track, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.GetTracksByTag(ctx, libraryID, tagName)
+}
+
+// Upload operations
+
+func (r *LibraryScopedRepository) CreateUpload(ctx context.Context, upload models.Upload) error {
+	libraryID, err := r.ResolveLibraryID(ctx, upload.UserID)
+	if err != nil {
+		return err
+	}
+	upload.UserID = libraryID
+	return r.Repository.CreateUpload(ctx, upload)
+}
+
+func (r *LibraryScopedRepository) GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.GetUpload(ctx, libraryID, uploadID)
+}
+
+func (r *LibraryScopedRepository) UpdateUploadStatus(ctx context.Context, userID, uploadID string, status models.UploadStatus, errorMsg string, trackID string) error {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.Repository.UpdateUploadStatus(ctx, libraryID, uploadID, status, errorMsg, trackID)
+}
+
+func (r *LibraryScopedRepository) UpdateUploadStep(ctx context.Context, userID, uploadID string, step models.ProcessingStep, success bool) error {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return r.Repository.UpdateUploadStep(ctx, libraryID, uploadID, step, success)
+}
+
+func (r *LibraryScopedRepository) ListUploads(ctx context.Context, userID string, filter models.UploadFilter) (*PaginatedResult[models.Upload], error) {
+	libraryID, err := r.ResolveLibraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.Repository.ListUploads(ctx, libraryID, filter)
+}
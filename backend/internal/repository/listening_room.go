@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// PutListeningRoom stores a room if the stored one is still at version
+// previous (0 for a new room). Otherwise nothing is written and ErrConflict
+// is returned.
+func (r *DynamoDBRepository) PutListeningRoom(ctx context.Context, room models.ListeningRoom, previous int64) error {
+	av, err := attributevalue.MarshalMap(models.NewListeningRoomItem(room))
+	if err != nil {
+		return fmt.Errorf("failed to marshal listening room: %w", err)
+	}
+
+	condition := expression.AttributeNotExists(expression.Name("PK"))
+	if previous > 0 {
+		condition = expression.Name("version").Equal(expression.Value(previous))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(r.tableName),
+		Item:                      av,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to put listening room: %w", err)
+	}
+
+	return nil
+}
+
+// GetListeningRoom retrieves a room with a consistent read, so members polling
+// it see the host's last change. Expired rooms are returned until the TTL
+// sweep removes them.
+func (r *DynamoDBRepository) GetListeningRoom(ctx context.Context, roomID string) (*models.ListeningRoom, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            listeningRoomKey(roomID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listening room: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.ListeningRoomItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal listening room: %w", err)
+	}
+
+	return &item.ListeningRoom, nil
+}
+
+// DeleteListeningRoom closes a room. Its members can no longer reach it and
+// expire with their TTL.
+func (r *DynamoDBRepository) DeleteListeningRoom(ctx context.Context, roomID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       listeningRoomKey(roomID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete listening room: %w", err)
+	}
+
+	return nil
+}
+
+// PutRoomMember adds a listener to a room, or refreshes their membership
+func (r *DynamoDBRepository) PutRoomMember(ctx context.Context, member models.RoomMember) error {
+	av, err := attributevalue.MarshalMap(models.NewRoomMemberItem(member))
+	if err != nil {
+		return fmt.Errorf("failed to marshal room member: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put room member: %w", err)
+	}
+
+	return nil
+}
+
+// GetRoomMember retrieves a listener's membership of a room
+func (r *DynamoDBRepository) GetRoomMember(ctx context.Context, roomID, userID string) (*models.RoomMember, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       roomMemberKey(roomID, userID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room member: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.RoomMemberItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal room member: %w", err)
+	}
+
+	return &item.RoomMember, nil
+}
+
+// DeleteRoomMember removes a listener from a room
+func (r *DynamoDBRepository) DeleteRoomMember(ctx context.Context, roomID, userID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       roomMemberKey(roomID, userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete room member: %w", err)
+	}
+
+	return nil
+}
+
+// ListRoomMembers retrieves every listener who joined a room
+func (r *DynamoDBRepository) ListRoomMembers(ctx context.Context, roomID string) ([]models.RoomMember, error) {
+	var items []models.RoomMemberItem
+	if err := r.queryPrefix(ctx, models.GetRoomPK(roomID), models.RoomMemberSKPrefix, &items); err != nil {
+		return nil, fmt.Errorf("failed to list room members: %w", err)
+	}
+
+	members := make([]models.RoomMember, 0, len(items))
+	for _, item := range items {
+		members = append(members, item.RoomMember)
+	}
+	return members, nil
+}
+
+func listeningRoomKey(roomID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: models.GetRoomPK(roomID)},
+		"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+	}
+}
+
+func roomMemberKey(roomID, userID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: models.GetRoomPK(roomID)},
+		"SK": &types.AttributeValueMemberS{Value: models.GetRoomMemberSK(userID)},
+	}
+}
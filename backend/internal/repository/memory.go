@@ -0,0 +1,2202 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// Compile-time checks that the in-memory stores satisfy the storage interfaces
+var (
+	_ Repository = (*MemoryRepository)(nil)
+	_ MediaStore = (*MemoryS3Repository)(nil)
+)
+
+// MemoryRepository is a thread-safe in-memory implementation of Repository,
+// plus the share, household and track neighbor methods the API needs. It mirrors the
+// DynamoDB implementation's semantics (timestamps, ErrNotFound /
+// ErrAlreadyExists, cursor pagination) so unit tests and demo mode behave like
+// production without any AWS dependency.
+//
+// Entities are stored and returned by value; nested slices and maps are shared
+// with the caller, as with any struct copy.
+type MemoryRepository struct {
+	mu memoryLock
+
+	tracks         map[string]models.Track            // userID#trackID
+	albums         map[string]models.Album            // userID#albumID
+	artists        map[string]models.Artist           // userID#artistID
+	users          map[string]models.User             // userID
+	playlists      map[string]models.Playlist         // userID#playlistID
+	playlistTracks map[string]models.PlaylistTrack    // playlistID#position
+	profiles       map[string]models.ArtistProfile    // userID
+	follows        map[string]models.Follow           // followerID#followedID
+	tags           map[string]models.Tag              // userID#tagName
+	trackTags      map[string]models.TrackTag         // userID#trackID#tagName
+	uploads        map[string]models.Upload           // userID#uploadID
+	shares         map[string]models.TrackShare       // recipientID#shareID
+	households     map[string]models.Household        // householdID
+	members        map[string]models.HouseholdMember  // householdID#userID
+	neighbors      map[string]models.TrackNeighbors   // userID#trackID
+	embeddings     map[string]models.TrackEmbedding   // userID#model#trackID
+	migrations     map[int]models.MigrationState      // version
+	moderation     map[string]models.ModerationReview // trackID
+	searchHistory  map[string]models.SearchHistory    // userID
+	searchBoosts   map[string]models.SearchBoosts     // userID
+	playerStates   map[string]models.PlayerState      // userID
+	devices        map[string]models.DeviceSession    // userID#deviceID
+	remoteCommands map[string][]models.RemoteCommand  // userID#deviceID
+	parties        map[string]models.Party            // partyID
+	partyRequests  map[string]models.PartyRequest     // partyID#requestID
+	downloadTokens map[string]models.DownloadToken    // ownerID#trackID#token
+	operations     map[string]models.Operation        // userID#operationID
+	trashed        map[string]models.TrashedTrack     // ownerID#trackID
+	collections    map[string]models.Collection       // libraryID#collectionID
+	featured       map[string]models.FeaturedItem     // itemID
+
+	// accessEvents (ownerID#trackID#eventID) and accessCounts (ownerID#trackID)
+	// hold the access logs of tracks reached from outside their library
+	accessEvents map[string]models.TrackAccessEvent
+	accessCounts map[string]models.TrackAccessCounts
+
+	// comparisonConsents (userID#peerID) are consents to compare libraries
+	comparisonConsents map[string]models.ComparisonConsent
+
+	// rooms (roomID) and roomMembers (roomID#userID) are shared listening rooms
+	rooms       map[string]models.ListeningRoom
+	roomMembers map[string]models.RoomMember
+
+	// archiveInsights (libraryID) are the insights job's cold track reports
+	archiveInsights map[string]models.ArchiveInsight
+
+	// observeTracks is told about track writes, like the table's stream consumers
+	observeTracks TrackObserver
+}
+
+// TrackObserver is told about a track written through CreateTrack,
+// UpdateTrack or DeleteTrack, with the track before and after the write (nil
+// when it did not exist or was deleted). It stands in for the table's
+// DynamoDB stream in demo mode and tests.
+type TrackObserver func(ctx context.Context, before, after *models.Track)
+
+// NewMemoryRepository creates an empty in-memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		tracks:         make(map[string]models.Track),
+		albums:         make(map[string]models.Album),
+		artists:        make(map[string]models.Artist),
+		users:          make(map[string]models.User),
+		playlists:      make(map[string]models.Playlist),
+		playlistTracks: make(map[string]models.PlaylistTrack),
+		profiles:       make(map[string]models.ArtistProfile),
+		follows:        make(map[string]models.Follow),
+		tags:           make(map[string]models.Tag),
+		trackTags:      make(map[string]models.TrackTag),
+		uploads:        make(map[string]models.Upload),
+		shares:         make(map[string]models.TrackShare),
+		households:     make(map[string]models.Household),
+		members:        make(map[string]models.HouseholdMember),
+		neighbors:      make(map[string]models.TrackNeighbors),
+		embeddings:     make(map[string]models.TrackEmbedding),
+		migrations:     make(map[int]models.MigrationState),
+		moderation:     make(map[string]models.ModerationReview),
+		searchHistory:  make(map[string]models.SearchHistory),
+		searchBoosts:   make(map[string]models.SearchBoosts),
+		playerStates:   make(map[string]models.PlayerState),
+		devices:        make(map[string]models.DeviceSession),
+		remoteCommands: make(map[string][]models.RemoteCommand),
+		parties:        make(map[string]models.Party),
+		partyRequests:  make(map[string]models.PartyRequest),
+		downloadTokens: make(map[string]models.DownloadToken),
+		operations:     make(map[string]models.Operation),
+		trashed:        make(map[string]models.TrashedTrack),
+		collections:    make(map[string]models.Collection),
+		featured:       make(map[string]models.FeaturedItem),
+		accessEvents:   make(map[string]models.TrackAccessEvent),
+		accessCounts:   make(map[string]models.TrackAccessCounts),
+
+		comparisonConsents: make(map[string]models.ComparisonConsent),
+		rooms:              make(map[string]models.ListeningRoom),
+		roomMembers:        make(map[string]models.RoomMember),
+
+		archiveInsights: make(map[string]models.ArchiveInsight),
+	}
+}
+
+// memoryKey joins key parts the way the DynamoDB keys separate them
+func memoryKey(parts ...string) string {
+	return strings.Join(parts, "#")
+}
+
+// memoryPage sorts items by key and returns the page that follows cursor.
+// Cursors are encoded PaginationCursors whose SK holds the last key returned,
+// so they are as opaque to clients as the DynamoDB ones.
+func memoryPage[T any](items []T, key func(T) string, pk string, limit int, cursor string, desc bool) (*PaginatedResult[T], error) {
+	start, err := models.DecodeCursor(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if desc {
+			return key(items[i]) > key(items[j])
+		}
+		return key(items[i]) < key(items[j])
+	})
+
+	if start.SK != "" {
+		offset := sort.Search(len(items), func(i int) bool {
+			if desc {
+				return key(items[i]) < start.SK
+			}
+			return key(items[i]) > start.SK
+		})
+		items = items[offset:]
+	}
+
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(items) > 0 {
+		nextCursor = models.EncodeCursor(models.NewPaginationCursor(pk, key(items[len(items)-1])))
+	}
+
+	return &PaginatedResult[T]{
+		Items:      items,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// ============================================================================
+// Track Operations
+// ============================================================================
+
+// ObserveTracks installs the observer told about track writes once they are
+// made, outside the repository's lock
+func (r *MemoryRepository) ObserveTracks(observer TrackObserver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observeTracks = observer
+}
+
+func (r *MemoryRepository) CreateTrack(ctx context.Context, track models.Track) error {
+	r.mu.Lock()
+	key := memoryKey(track.UserID, track.ID)
+	if _, ok := r.tracks[key]; ok {
+		r.mu.Unlock()
+		return fmt.Errorf("failed to create track: %w", ErrAlreadyExists)
+	}
+
+	track.CreatedAt = time.Now()
+	track.UpdatedAt = track.CreatedAt
+	if track.SortLocale == "" {
+		track.SortLocale = collation.FromContext(ctx)
+	}
+	r.tracks[key] = track
+	observer := r.observeTracks
+	r.mu.Unlock()
+
+	if observer != nil {
+		observer(ctx, nil, &track)
+	}
+	return nil
+}
+
+func (r *MemoryRepository) GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	track, ok := r.tracks[memoryKey(userID, trackID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &track, nil
+}
+
+// BatchGetTracks retrieves several of a user's tracks by ID, keyed by track ID
+func (r *MemoryRepository) BatchGetTracks(ctx context.Context, userID string, trackIDs []string) (map[string]models.Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]models.Track, len(trackIDs))
+	for _, trackID := range trackIDs {
+		if track, ok := r.tracks[memoryKey(userID, trackID)]; ok {
+			result[trackID] = track
+		}
+	}
+	return result, nil
+}
+
+// GetTrackByID retrieves a track by ID regardless of owner
+func (r *MemoryRepository) GetTrackByID(ctx context.Context, trackID string) (*models.Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, track := range r.tracks {
+		if track.ID == trackID {
+			return &track, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryRepository) UpdateTrack(ctx context.Context, track models.Track) error {
+	r.mu.Lock()
+	key := memoryKey(track.UserID, track.ID)
+	before, ok := r.tracks[key]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("failed to update track: %w", ErrNotFound)
+	}
+
+	track.UpdatedAt = time.Now()
+	r.tracks[key] = track
+	observer := r.observeTracks
+	r.mu.Unlock()
+
+	if observer != nil {
+		observer(ctx, &before, &track)
+	}
+	return nil
+}
+
+func (r *MemoryRepository) DeleteTrack(ctx context.Context, userID, trackID string) error {
+	r.mu.Lock()
+	key := memoryKey(userID, trackID)
+	before, ok := r.tracks[key]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("failed to delete track: %w", ErrNotFound)
+	}
+	delete(r.tracks, key)
+	observer := r.observeTracks
+	r.mu.Unlock()
+
+	if observer != nil {
+		observer(ctx, &before, nil)
+	}
+	return nil
+}
+
+func (r *MemoryRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*PaginatedResult[models.Track], error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	tracks := make([]models.Track, 0)
+	for _, track := range r.tracks {
+		if filter.GlobalScope || track.UserID == userID {
+			tracks = append(tracks, track)
+		}
+	}
+	r.mu.RUnlock()
+
+	// Global scope spans partitions, so the key includes the owner like the scan cursor does
+	if filter.GlobalScope {
+		return memoryPage(tracks, func(t models.Track) string {
+			return memoryKey(t.UserID, t.ID)
+		}, "TRACKS", limit, filter.LastKey, false)
+	}
+	if filter.SortBy == "title" {
+		return memoryPage(tracks, func(t models.Track) string {
+			return sortableTrack(t).GSI5SK
+		}, models.GetSortGSI5PK(userID, models.EntityTrack), limit, filter.LastKey, filter.SortOrder == "desc")
+	}
+	return memoryPage(tracks, func(t models.Track) string {
+		return t.ID
+	}, fmt.Sprintf("USER#%s", userID), limit, filter.LastKey, filter.SortOrder == "desc")
+}
+
+func (r *MemoryRepository) ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tracks := make([]models.Track, 0)
+	for _, track := range r.tracks {
+		if track.UserID == userID && track.Artist == artist {
+			tracks = append(tracks, track)
+		}
+	}
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].ID < tracks[j].ID })
+	return tracks, nil
+}
+
+// ListPublicTracks lists public tracks from all users, most recently published first
+func (r *MemoryRepository) ListPublicTracks(ctx context.Context, limit int, cursor string) (*PaginatedResult[models.Track], error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	tracks := make([]models.Track, 0)
+	for _, track := range r.tracks {
+		if track.Visibility == models.VisibilityPublic {
+			tracks = append(tracks, track)
+		}
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(tracks, publishedKey, "PUBLIC_TRACK", limit, cursor, true)
+}
+
+// publishedKey orders public tracks the way GSI3SK does
+func publishedKey(track models.Track) string {
+	var published time.Time
+	if track.PublishedAt != nil {
+		published = *track.PublishedAt
+	}
+	return memoryKey(published.UTC().Format(time.RFC3339Nano), track.ID)
+}
+
+// UpdateTrackVisibility updates a track's visibility, stamping PublishedAt when it goes public
+func (r *MemoryRepository) UpdateTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, trackID)
+	track, ok := r.tracks[key]
+	if !ok {
+		return ErrNotFound
+	}
+
+	now := time.Now()
+	track.Visibility = visibility
+	track.UpdatedAt = now
+	if visibility == models.VisibilityPublic {
+		track.PublishedAt = &now
+	}
+	r.tracks[key] = track
+	return nil
+}
+
+// FindTracksByContentHash returns a user's tracks whose audio file has the given SHA-256
+func (r *MemoryRepository) FindTracksByContentHash(ctx context.Context, userID, contentHash string) ([]models.Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tracks := make([]models.Track, 0)
+	for _, track := range r.tracks {
+		if track.UserID == userID && track.ContentHash == contentHash {
+			tracks = append(tracks, track)
+		}
+	}
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].ID < tracks[j].ID })
+	return tracks, nil
+}
+
+// ListTracksByKeyAndBPMBucket returns a user's tracks in one Camelot key whose
+// tempo band is between minBucket and maxBucket, in GSI4 order
+func (r *MemoryRepository) ListTracksByKeyAndBPMBucket(ctx context.Context, userID, camelotKey string, minBucket, maxBucket int) ([]models.Track, error) {
+	r.mu.RLock()
+	tracks := make([]models.Track, 0)
+	for _, track := range r.tracks {
+		bucket := models.BPMBucket(track.BPM)
+		if track.UserID == userID && track.KeyCamelot == camelotKey && bucket >= minBucket && bucket <= maxBucket {
+			tracks = append(tracks, track)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(tracks, func(i, j int) bool {
+		bi, bj := models.BPMBucket(tracks[i].BPM), models.BPMBucket(tracks[j].BPM)
+		if bi != bj {
+			return bi < bj
+		}
+		return tracks[i].ID < tracks[j].ID
+	})
+	return tracks, nil
+}
+
+// ============================================================================
+// Album Operations
+// ============================================================================
+
+func (r *MemoryRepository) GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	albumID := AlbumID(albumName, artist)
+	key := memoryKey(userID, albumID)
+	for _, id := range []string{albumID, legacyAlbumID(albumName, artist)} {
+		if album, ok := r.albums[memoryKey(userID, id)]; ok {
+			return &album, nil
+		}
+	}
+
+	now := time.Now()
+	album := models.Album{
+		ID:         albumID,
+		UserID:     userID,
+		Title:      albumName,
+		Artist:     artist,
+		SortLocale: collation.FromContext(ctx),
+		Timestamps: models.Timestamps{
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	r.albums[key] = album
+	return &album, nil
+}
+
+func (r *MemoryRepository) GetAlbum(ctx context.Context, userID, albumID string) (*models.Album, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	album, ok := r.albums[memoryKey(userID, albumID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &album, nil
+}
+
+func (r *MemoryRepository) ListAlbums(ctx context.Context, userID string, filter models.AlbumFilter) (*PaginatedResult[models.Album], error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	albums := make([]models.Album, 0)
+	for _, album := range r.albums {
+		if album.UserID == userID {
+			albums = append(albums, album)
+		}
+	}
+	r.mu.RUnlock()
+
+	if filter.SortBy == "title" {
+		return memoryPage(albums, func(a models.Album) string {
+			return sortableAlbum(a).GSI5SK
+		}, models.GetSortGSI5PK(userID, models.EntityAlbum), limit, filter.LastKey, filter.SortOrder == "desc")
+	}
+	return memoryPage(albums, func(a models.Album) string {
+		return a.ID
+	}, fmt.Sprintf("USER#%s", userID), limit, filter.LastKey, filter.SortOrder == "desc")
+}
+
+func (r *MemoryRepository) ListAlbumsByArtist(ctx context.Context, userID, artist string) ([]models.Album, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	albums := make([]models.Album, 0)
+	for _, album := range r.albums {
+		if album.UserID == userID && album.Artist == artist {
+			albums = append(albums, album)
+		}
+	}
+	sort.Slice(albums, func(i, j int) bool { return albums[i].ID < albums[j].ID })
+	return albums, nil
+}
+
+func (r *MemoryRepository) UpdateAlbumStats(ctx context.Context, userID, albumID string, trackCount, totalDuration int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, albumID)
+	album, ok := r.albums[key]
+	if !ok {
+		return fmt.Errorf("failed to update album stats: %w", ErrNotFound)
+	}
+
+	album.TrackCount = trackCount
+	album.TotalDuration = totalDuration
+	album.UpdatedAt = time.Now()
+	r.albums[key] = album
+	return nil
+}
+
+func (r *MemoryRepository) SetAlbumCover(ctx context.Context, userID, albumID, coverArtKey string, style *models.CoverStyle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, albumID)
+	album, ok := r.albums[key]
+	if !ok || album.CoverArtKey != "" {
+		return ErrConflict
+	}
+
+	album.CoverArtKey = coverArtKey
+	if style != nil {
+		album.CoverStyle = style
+	}
+	album.UpdatedAt = time.Now()
+	r.albums[key] = album
+	return nil
+}
+
+// ============================================================================
+// Artist Operations
+// ============================================================================
+
+func (r *MemoryRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(artist.UserID, artist.ID)
+	if _, ok := r.artists[key]; ok {
+		return fmt.Errorf("failed to create artist: %w", ErrAlreadyExists)
+	}
+
+	artist.CreatedAt = time.Now()
+	artist.UpdatedAt = artist.CreatedAt
+	if artist.SortLocale == "" {
+		artist.SortLocale = collation.FromContext(ctx)
+	}
+	r.artists[key] = artist
+	return nil
+}
+
+func (r *MemoryRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	artist, ok := r.artists[memoryKey(userID, artistID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &artist, nil
+}
+
+func (r *MemoryRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	artists := make([]*models.Artist, 0)
+	for _, artist := range r.artists {
+		if artist.UserID == userID && artist.Name == name {
+			artist := artist
+			artists = append(artists, &artist)
+		}
+	}
+	sort.Slice(artists, func(i, j int) bool { return artists[i].ID < artists[j].ID })
+	return artists, nil
+}
+
+func (r *MemoryRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*PaginatedResult[models.Artist], error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	artists := make([]models.Artist, 0)
+	for _, artist := range r.artists {
+		if artist.UserID != userID {
+			continue
+		}
+		if filter.Name != "" && !strings.Contains(strings.ToLower(artist.Name), strings.ToLower(filter.Name)) {
+			continue
+		}
+		artists = append(artists, artist)
+	}
+	r.mu.RUnlock()
+
+	if filter.SortBy == "name" {
+		return memoryPage(artists, func(a models.Artist) string {
+			return sortableArtist(a).GSI5SK
+		}, models.GetSortGSI5PK(userID, models.EntityArtist), limit, filter.LastKey, filter.SortOrder == "desc")
+	}
+	return memoryPage(artists, func(a models.Artist) string {
+		return a.ID
+	}, fmt.Sprintf("USER#%s", userID), limit, filter.LastKey, filter.SortOrder == "desc")
+}
+
+func (r *MemoryRepository) UpdateArtist(ctx context.Context, artist models.Artist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(artist.UserID, artist.ID)
+	if _, ok := r.artists[key]; !ok {
+		return fmt.Errorf("failed to update artist: %w", ErrNotFound)
+	}
+
+	artist.UpdatedAt = time.Now()
+	r.artists[key] = artist
+	return nil
+}
+
+// DeleteArtist soft-deletes an artist by marking it inactive
+func (r *MemoryRepository) DeleteArtist(ctx context.Context, userID, artistID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, artistID)
+	artist, ok := r.artists[key]
+	if !ok {
+		return fmt.Errorf("failed to delete artist: %w", ErrNotFound)
+	}
+
+	artist.IsActive = false
+	artist.UpdatedAt = time.Now()
+	r.artists[key] = artist
+	return nil
+}
+
+func (r *MemoryRepository) BatchGetArtists(ctx context.Context, userID string, artistIDs []string) (map[string]*models.Artist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]*models.Artist, len(artistIDs))
+	for _, id := range artistIDs {
+		if artist, ok := r.artists[memoryKey(userID, id)]; ok {
+			result[id] = &artist
+		}
+	}
+	return result, nil
+}
+
+// SearchArtists returns active artists whose name starts with query (case-insensitive)
+func (r *MemoryRepository) SearchArtists(ctx context.Context, userID, query string, limit int) ([]*models.Artist, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefix := strings.ToLower(query)
+	artists := make([]*models.Artist, 0)
+	for _, artist := range r.artists {
+		if artist.UserID == userID && artist.IsActive && strings.HasPrefix(strings.ToLower(artist.Name), prefix) {
+			artist := artist
+			artists = append(artists, &artist)
+		}
+	}
+	sort.Slice(artists, func(i, j int) bool { return artists[i].Name < artists[j].Name })
+	if len(artists) > limit {
+		artists = artists[:limit]
+	}
+	return artists, nil
+}
+
+func (r *MemoryRepository) GetArtistTrackCount(ctx context.Context, userID, artistID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, track := range r.tracks {
+		if track.UserID == userID && track.ArtistID == artistID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetArtistAlbumCount counts the distinct albums among an artist's tracks
+func (r *MemoryRepository) GetArtistAlbumCount(ctx context.Context, userID, artistID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	albums := make(map[string]struct{})
+	for _, track := range r.tracks {
+		if track.UserID == userID && track.ArtistID == artistID && track.Album != "" {
+			albums[track.Album] = struct{}{}
+		}
+	}
+	return len(albums), nil
+}
+
+func (r *MemoryRepository) GetArtistTotalPlays(ctx context.Context, userID, artistID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plays := 0
+	for _, track := range r.tracks {
+		if track.UserID == userID && track.ArtistID == artistID {
+			plays += track.PlayCount
+		}
+	}
+	return plays, nil
+}
+
+// ============================================================================
+// Tag Operations
+// ============================================================================
+
+func (r *MemoryRepository) CreateTag(ctx context.Context, tag models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(tag.UserID, tag.Name)
+	if _, ok := r.tags[key]; ok {
+		return fmt.Errorf("failed to create tag: %w", ErrAlreadyExists)
+	}
+
+	tag.CreatedAt = time.Now()
+	tag.UpdatedAt = tag.CreatedAt
+	r.tags[key] = tag
+	return nil
+}
+
+func (r *MemoryRepository) GetTag(ctx context.Context, userID, tagName string) (*models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tag, ok := r.tags[memoryKey(userID, tagName)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &tag, nil
+}
+
+func (r *MemoryRepository) UpdateTag(ctx context.Context, tag models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(tag.UserID, tag.Name)
+	if _, ok := r.tags[key]; !ok {
+		return fmt.Errorf("failed to update tag: %w", ErrNotFound)
+	}
+
+	tag.UpdatedAt = time.Now()
+	r.tags[key] = tag
+	return nil
+}
+
+func (r *MemoryRepository) DeleteTag(ctx context.Context, userID, tagName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.tags, memoryKey(userID, tagName))
+	return nil
+}
+
+func (r *MemoryRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make([]models.Tag, 0)
+	for _, tag := range r.tags {
+		if tag.UserID == userID {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *MemoryRepository) AddTagsToTrack(ctx context.Context, userID, trackID string, tagNames []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, tagName := range tagNames {
+		r.trackTags[memoryKey(userID, trackID, tagName)] = models.TrackTag{
+			UserID:  userID,
+			TrackID: trackID,
+			TagName: tagName,
+			AddedAt: now,
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.trackTags, memoryKey(userID, trackID, tagName))
+	return nil
+}
+
+func (r *MemoryRepository) GetTrackTags(ctx context.Context, userID, trackID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make([]string, 0)
+	for _, trackTag := range r.trackTags {
+		if trackTag.UserID == userID && trackTag.TrackID == trackID {
+			tags = append(tags, trackTag.TagName)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// GetTracksByTag returns the tagged tracks, skipping associations whose track was deleted
+func (r *MemoryRepository) GetTracksByTag(ctx context.Context, userID, tagName string) ([]models.Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tracks := make([]models.Track, 0)
+	for _, trackTag := range r.trackTags {
+		if trackTag.UserID != userID || trackTag.TagName != tagName {
+			continue
+		}
+		if track, ok := r.tracks[memoryKey(userID, trackTag.TrackID)]; ok {
+			tracks = append(tracks, track)
+		}
+	}
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].ID < tracks[j].ID })
+	return tracks, nil
+}
+
+// ============================================================================
+// Upload Operations
+// ============================================================================
+
+func (r *MemoryRepository) CreateUpload(ctx context.Context, upload models.Upload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(upload.UserID, upload.ID)
+	if _, ok := r.uploads[key]; ok {
+		return fmt.Errorf("failed to create upload: %w", ErrAlreadyExists)
+	}
+
+	upload.CreatedAt = time.Now()
+	upload.UpdatedAt = upload.CreatedAt
+	r.uploads[key] = upload
+	return nil
+}
+
+func (r *MemoryRepository) GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	upload, ok := r.uploads[memoryKey(userID, uploadID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &upload, nil
+}
+
+func (r *MemoryRepository) UpdateUpload(ctx context.Context, upload models.Upload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(upload.UserID, upload.ID)
+	if _, ok := r.uploads[key]; !ok {
+		return fmt.Errorf("failed to update upload: %w", ErrNotFound)
+	}
+
+	upload.UpdatedAt = time.Now()
+	r.uploads[key] = upload
+	return nil
+}
+
+func (r *MemoryRepository) UpdateUploadStatus(ctx context.Context, userID, uploadID string, status models.UploadStatus, errorMsg string, trackID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, uploadID)
+	upload, ok := r.uploads[key]
+	if !ok {
+		return fmt.Errorf("failed to update upload status: %w", ErrNotFound)
+	}
+
+	now := time.Now()
+	upload.Status = status
+	upload.UpdatedAt = now
+	if errorMsg != "" {
+		upload.ErrorMsg = errorMsg
+	}
+	if trackID != "" {
+		upload.TrackID = trackID
+	}
+	if status == models.UploadStatusCompleted {
+		upload.CompletedAt = &now
+	}
+	r.uploads[key] = upload
+	return nil
+}
+
+func (r *MemoryRepository) UpdateUploadStep(ctx context.Context, userID, uploadID string, step models.ProcessingStep, success bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, uploadID)
+	upload, ok := r.uploads[key]
+	if !ok {
+		return fmt.Errorf("failed to update upload step: %w", ErrNotFound)
+	}
+
+	switch step {
+	case models.StepExtractMetadata:
+		upload.MetadataExtracted = success
+	case models.StepExtractCover:
+		upload.CoverArtExtracted = success
+	case models.StepCreateTrack:
+		upload.TrackCreated = success
+	case models.StepIndex:
+		upload.Indexed = success
+	case models.StepMoveFile:
+		upload.FileMoved = success
+	default:
+		return fmt.Errorf("unknown processing step: %s", step)
+	}
+	upload.UpdatedAt = time.Now()
+	r.uploads[key] = upload
+	return nil
+}
+
+// ListUploads lists a user's uploads, newest first, optionally filtered by status
+func (r *MemoryRepository) ListUploads(ctx context.Context, userID string, filter models.UploadFilter) (*PaginatedResult[models.Upload], error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	uploads := make([]models.Upload, 0)
+	for _, upload := range r.uploads {
+		if upload.UserID != userID {
+			continue
+		}
+		if filter.Status != "" && upload.Status != filter.Status {
+			continue
+		}
+		uploads = append(uploads, upload)
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(uploads, func(u models.Upload) string {
+		return u.ID
+	}, fmt.Sprintf("USER#%s", userID), limit, filter.LastKey, true)
+}
+
+func (r *MemoryRepository) ListUploadsByStatus(ctx context.Context, status models.UploadStatus) ([]models.Upload, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	uploads := make([]models.Upload, 0)
+	for _, upload := range r.uploads {
+		if upload.Status == status {
+			uploads = append(uploads, upload)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool { return uploads[i].CreatedAt.Before(uploads[j].CreatedAt) })
+	return uploads, nil
+}
+
+// ============================================================================
+// Track Neighbor Operations
+// ============================================================================
+
+// GetTrackNeighbors returns a track's cached neighbors. Entries are not expired
+// here; callers check TrackNeighbors.IsFresh as they do against DynamoDB, where
+// the TTL sweep can lag by hours.
+func (r *MemoryRepository) GetTrackNeighbors(ctx context.Context, userID, trackID string) (*models.TrackNeighbors, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	neighbors, ok := r.neighbors[memoryKey(userID, trackID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &neighbors, nil
+}
+
+// PutTrackNeighbors stores a track's neighbors, replacing any previous list
+func (r *MemoryRepository) PutTrackNeighbors(ctx context.Context, neighbors models.TrackNeighbors) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.neighbors[memoryKey(neighbors.UserID, neighbors.TrackID)] = neighbors
+	return nil
+}
+
+// ============================================================================
+// Track Embedding Operations
+// ============================================================================
+
+// PutTrackEmbedding stores a track's embedding for its model
+func (r *MemoryRepository) PutTrackEmbedding(ctx context.Context, embedding models.TrackEmbedding) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	embedding.Dims = len(embedding.Vector)
+	embedding.Vector = append([]float32(nil), embedding.Vector...)
+	r.embeddings[memoryKey(embedding.UserID, embedding.Model, embedding.TrackID)] = embedding
+	return nil
+}
+
+// BatchGetTrackEmbeddings returns the model's embeddings of the given tracks
+func (r *MemoryRepository) BatchGetTrackEmbeddings(ctx context.Context, userID, model string, trackIDs []string) (map[string]models.TrackEmbedding, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]models.TrackEmbedding, len(trackIDs))
+	for _, trackID := range trackIDs {
+		if embedding, ok := r.embeddings[memoryKey(userID, model, trackID)]; ok {
+			result[trackID] = embedding
+		}
+	}
+	return result, nil
+}
+
+// ListTrackEmbeddings returns a user's embeddings for the model, or for all
+// models when model is empty, in sort key order
+func (r *MemoryRepository) ListTrackEmbeddings(ctx context.Context, userID, model string) ([]models.TrackEmbedding, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var embeddings []models.TrackEmbedding
+	for _, embedding := range r.embeddings {
+		if embedding.UserID == userID && (model == "" || embedding.Model == model) {
+			embeddings = append(embeddings, embedding)
+		}
+	}
+	sort.Slice(embeddings, func(i, j int) bool {
+		return models.GetTrackEmbeddingSK(embeddings[i].Model, embeddings[i].TrackID) <
+			models.GetTrackEmbeddingSK(embeddings[j].Model, embeddings[j].TrackID)
+	})
+	return embeddings, nil
+}
+
+// DeleteTrackEmbedding removes a track's embedding for the model
+func (r *MemoryRepository) DeleteTrackEmbedding(ctx context.Context, userID, model, trackID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.embeddings, memoryKey(userID, model, trackID))
+	return nil
+}
+
+// ============================================================================
+// Count Operations
+// ============================================================================
+
+// CountTracks returns the number of tracks in a user's library
+func (r *MemoryRepository) CountTracks(ctx context.Context, userID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, track := range r.tracks {
+		if track.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountAllTracks returns the number of tracks across all users
+func (r *MemoryRepository) CountAllTracks(ctx context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.tracks), nil
+}
+
+// CountAlbums returns the number of albums in a user's library
+func (r *MemoryRepository) CountAlbums(ctx context.Context, userID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, album := range r.albums {
+		if album.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountPlaylists returns the number of playlists a user owns
+func (r *MemoryRepository) CountPlaylists(ctx context.Context, userID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, playlist := range r.playlists {
+		if playlist.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ============================================================================
+// Object Key Operations
+// ============================================================================
+
+// UpdateTrackObjectKey points a track's key field (and, with albumID, the
+// album's cover art key) from one S3 key to another, or fails with
+// ErrConflict without changing anything when either no longer holds from
+func (r *MemoryRepository) UpdateTrackObjectKey(ctx context.Context, userID, trackID string, field models.TrackKeyField, from, to, albumID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trackKey := memoryKey(userID, trackID)
+	track, ok := r.tracks[trackKey]
+	if !ok || track.ObjectKey(field) != from {
+		return ErrConflict
+	}
+	albumKey := memoryKey(userID, albumID)
+	album, ok := r.albums[albumKey]
+	if albumID != "" && (!ok || album.CoverArtKey != from) {
+		return ErrConflict
+	}
+
+	track.SetObjectKey(field, to)
+	r.tracks[trackKey] = track
+	if albumID != "" {
+		album.CoverArtKey = to
+		r.albums[albumKey] = album
+	}
+	return nil
+}
+
+// ============================================================================
+// Migration Operations
+// ============================================================================
+
+// GetMigrationState returns a data migration's checkpoint, or ErrNotFound
+func (r *MemoryRepository) GetMigrationState(ctx context.Context, version int) (*models.MigrationState, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.migrations[version]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &state, nil
+}
+
+// PutMigrationState saves a data migration's checkpoint
+func (r *MemoryRepository) PutMigrationState(ctx context.Context, state models.MigrationState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state.Description = ""
+	r.migrations[state.Version] = state
+	return nil
+}
+
+// ListMigrationStates returns every saved checkpoint in version order
+func (r *MemoryRepository) ListMigrationStates(ctx context.Context) ([]models.MigrationState, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	states := make([]models.MigrationState, 0, len(r.migrations))
+	for _, state := range r.migrations {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
+}
+
+// ============================================================================
+// Search History and Boost Operations
+// ============================================================================
+
+// GetSearchHistory retrieves a user's recent searches
+func (r *MemoryRepository) GetSearchHistory(ctx context.Context, userID string) (*models.SearchHistory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history, ok := r.searchHistory[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &history, nil
+}
+
+// PutSearchHistory creates or replaces a user's recent searches
+func (r *MemoryRepository) PutSearchHistory(ctx context.Context, history models.SearchHistory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.searchHistory[history.UserID] = history
+	return nil
+}
+
+// DeleteSearchHistory clears a user's recent searches
+func (r *MemoryRepository) DeleteSearchHistory(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.searchHistory, userID)
+	return nil
+}
+
+// GetSearchBoosts retrieves a user's pinned results and artist boosts
+func (r *MemoryRepository) GetSearchBoosts(ctx context.Context, userID string) (*models.SearchBoosts, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	boosts, ok := r.searchBoosts[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &boosts, nil
+}
+
+// PutSearchBoosts creates or replaces a user's pinned results and artist boosts
+func (r *MemoryRepository) PutSearchBoosts(ctx context.Context, boosts models.SearchBoosts) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.searchBoosts[boosts.UserID] = boosts
+	return nil
+}
+
+// ============================================================================
+// Player State Operations
+// ============================================================================
+
+// GetPlayerState retrieves a user's play queue
+func (r *MemoryRepository) GetPlayerState(ctx context.Context, userID string) (*models.PlayerState, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.playerStates[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	state.TrackIDs = append([]string{}, state.TrackIDs...)
+	return &state, nil
+}
+
+// PutPlayerState stores a user's play queue if the stored one is still at
+// version previous (0 for a user who has none), and fails with ErrConflict
+// otherwise
+func (r *MemoryRepository) PutPlayerState(ctx context.Context, state models.PlayerState, previous int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.playerStates[state.UserID].Version != previous {
+		return ErrConflict
+	}
+	state.TrackIDs = append([]string{}, state.TrackIDs...)
+	r.playerStates[state.UserID] = state
+	return nil
+}
+
+// ============================================================================
+// Remote Control Operations
+// ============================================================================
+
+// PutDeviceSession creates or refreshes a device's remote control session
+func (r *MemoryRepository) PutDeviceSession(ctx context.Context, session models.DeviceSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.devices[memoryKey(session.UserID, session.DeviceID)] = session
+	return nil
+}
+
+// GetDeviceSession retrieves a device's session. Expired sessions are kept,
+// as DynamoDB keeps them until its TTL sweep.
+func (r *MemoryRepository) GetDeviceSession(ctx context.Context, userID, deviceID string) (*models.DeviceSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.devices[memoryKey(userID, deviceID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+// ListDeviceSessions retrieves every session of a user's devices, ordered by
+// device ID like the DynamoDB sort key
+func (r *MemoryRepository) ListDeviceSessions(ctx context.Context, userID string) ([]models.DeviceSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sessions := make([]models.DeviceSession, 0)
+	for _, session := range r.devices {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].DeviceID < sessions[j].DeviceID })
+	return sessions, nil
+}
+
+// DeleteDeviceSession ends a device's session
+func (r *MemoryRepository) DeleteDeviceSession(ctx context.Context, userID, deviceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.devices, memoryKey(userID, deviceID))
+	return nil
+}
+
+// PutRemoteCommand queues a command for a device
+func (r *MemoryRepository) PutRemoteCommand(ctx context.Context, cmd models.RemoteCommand) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(cmd.UserID, cmd.DeviceID)
+	r.remoteCommands[key] = append(r.remoteCommands[key], cmd)
+	return nil
+}
+
+// TakeRemoteCommands removes and returns the commands waiting for a device,
+// oldest first
+func (r *MemoryRepository) TakeRemoteCommands(ctx context.Context, userID, deviceID string) ([]models.RemoteCommand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, deviceID)
+	commands := append([]models.RemoteCommand{}, r.remoteCommands[key]...)
+	delete(r.remoteCommands, key)
+	return commands, nil
+}
+
+// ============================================================================
+// Party Operations
+// ============================================================================
+
+// PutParty creates a guest DJ party
+func (r *MemoryRepository) PutParty(ctx context.Context, party models.Party) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.parties[party.ID] = party
+	return nil
+}
+
+// GetParty retrieves a party. Expired parties are kept, as DynamoDB keeps
+// them until its TTL sweep.
+func (r *MemoryRepository) GetParty(ctx context.Context, partyID string) (*models.Party, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	party, ok := r.parties[partyID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &party, nil
+}
+
+// DeleteParty ends a party along with its requests
+func (r *MemoryRepository) DeleteParty(ctx context.Context, partyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.parties, partyID)
+	for key, req := range r.partyRequests {
+		if req.PartyID == partyID {
+			delete(r.partyRequests, key)
+		}
+	}
+	return nil
+}
+
+// PutPartyRequest stores a guest's track request
+func (r *MemoryRepository) PutPartyRequest(ctx context.Context, req models.PartyRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.partyRequests[memoryKey(req.PartyID, req.ID)] = req
+	return nil
+}
+
+// GetPartyRequest retrieves a guest's track request
+func (r *MemoryRepository) GetPartyRequest(ctx context.Context, partyID, requestID string) (*models.PartyRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	req, ok := r.partyRequests[memoryKey(partyID, requestID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &req, nil
+}
+
+// ListPartyRequests retrieves every request made at a party, ordered by
+// request ID like the DynamoDB sort key
+func (r *MemoryRepository) ListPartyRequests(ctx context.Context, partyID string) ([]models.PartyRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	requests := make([]models.PartyRequest, 0)
+	for _, req := range r.partyRequests {
+		if req.PartyID == partyID {
+			requests = append(requests, req)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].ID < requests[j].ID })
+	return requests, nil
+}
+
+// RespondToPartyRequest stores the host's answer to a request if it is still
+// pending, and fails with ErrConflict otherwise
+func (r *MemoryRepository) RespondToPartyRequest(ctx context.Context, req models.PartyRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(req.PartyID, req.ID)
+	if r.partyRequests[key].Status != models.PartyRequestPending {
+		return ErrConflict
+	}
+	r.partyRequests[key] = req
+	return nil
+}
+
+// ============================================================================
+// Download Token Operations
+// ============================================================================
+
+// PutDownloadToken stores a watermarked download
+func (r *MemoryRepository) PutDownloadToken(ctx context.Context, token models.DownloadToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.downloadTokens[memoryKey(token.OwnerID, token.TrackID, token.Token)] = token
+	return nil
+}
+
+// GetDownloadToken retrieves a watermarked download of one of an owner's tracks
+func (r *MemoryRepository) GetDownloadToken(ctx context.Context, ownerID, trackID, token string) (*models.DownloadToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.downloadTokens[memoryKey(ownerID, trackID, token)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &t, nil
+}
+
+// ListDownloadTokens retrieves every watermarked download of one of an
+// owner's tracks, ordered by token like the DynamoDB sort key
+func (r *MemoryRepository) ListDownloadTokens(ctx context.Context, ownerID, trackID string) ([]models.DownloadToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := make([]models.DownloadToken, 0)
+	for _, t := range r.downloadTokens {
+		if t.OwnerID == ownerID && t.TrackID == trackID {
+			tokens = append(tokens, t)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Token < tokens[j].Token })
+	return tokens, nil
+}
+
+// ============================================================================
+// Access Log Operations
+// ============================================================================
+
+// RecordTrackAccess stores an access event and adds it to the track's access counters
+func (r *MemoryRepository) RecordTrackAccess(ctx context.Context, event models.TrackAccessEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accessEvents[memoryKey(event.OwnerID, event.TrackID, event.ID)] = event
+	key := memoryKey(event.OwnerID, event.TrackID)
+	counts := r.accessCounts[key]
+	counts.Add(event.Kind, event.AccessedAt)
+	r.accessCounts[key] = counts
+	return nil
+}
+
+// GetTrackAccessCounts retrieves the access counters of one of an owner's tracks
+func (r *MemoryRepository) GetTrackAccessCounts(ctx context.Context, ownerID, trackID string) (*models.TrackAccessCounts, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := r.accessCounts[memoryKey(ownerID, trackID)]
+	return &counts, nil
+}
+
+// ListTrackAccess lists the access events of one of an owner's tracks,
+// newest first. Expired events are kept, as DynamoDB keeps them until its
+// TTL sweep.
+func (r *MemoryRepository) ListTrackAccess(ctx context.Context, ownerID, trackID string, filter models.TrackAccessFilter) (*PaginatedResult[models.TrackAccessEvent], error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	events := make([]models.TrackAccessEvent, 0)
+	for _, event := range r.accessEvents {
+		if event.OwnerID == ownerID && event.TrackID == trackID {
+			events = append(events, event)
+		}
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(events, func(e models.TrackAccessEvent) string {
+		return models.NewTrackAccessEventItem(e).SK
+	}, "USER#"+ownerID, limit, filter.Cursor, true)
+}
+
+// ============================================================================
+// Listening Room Operations
+// ============================================================================
+
+// PutListeningRoom stores a room if the stored one is still at version
+// previous (0 for a new room), and fails with ErrConflict otherwise
+func (r *MemoryRepository) PutListeningRoom(ctx context.Context, room models.ListeningRoom, previous int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rooms[room.ID].Version != previous {
+		return ErrConflict
+	}
+	room.TrackIDs = append([]string{}, room.TrackIDs...)
+	r.rooms[room.ID] = room
+	return nil
+}
+
+// GetListeningRoom retrieves a room. Expired rooms are kept, as DynamoDB
+// keeps them until its TTL sweep.
+func (r *MemoryRepository) GetListeningRoom(ctx context.Context, roomID string) (*models.ListeningRoom, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	room, ok := r.rooms[roomID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	room.TrackIDs = append([]string{}, room.TrackIDs...)
+	return &room, nil
+}
+
+// DeleteListeningRoom closes a room along with its memberships
+func (r *MemoryRepository) DeleteListeningRoom(ctx context.Context, roomID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.rooms, roomID)
+	for key, member := range r.roomMembers {
+		if member.RoomID == roomID {
+			delete(r.roomMembers, key)
+		}
+	}
+	return nil
+}
+
+// PutRoomMember adds a listener to a room, or refreshes their membership
+func (r *MemoryRepository) PutRoomMember(ctx context.Context, member models.RoomMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.roomMembers[memoryKey(member.RoomID, member.UserID)] = member
+	return nil
+}
+
+// GetRoomMember retrieves a listener's membership of a room
+func (r *MemoryRepository) GetRoomMember(ctx context.Context, roomID, userID string) (*models.RoomMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.roomMembers[memoryKey(roomID, userID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &member, nil
+}
+
+// DeleteRoomMember removes a listener from a room
+func (r *MemoryRepository) DeleteRoomMember(ctx context.Context, roomID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.roomMembers, memoryKey(roomID, userID))
+	return nil
+}
+
+// ListRoomMembers retrieves every listener who joined a room, ordered by
+// user ID like the DynamoDB sort key
+func (r *MemoryRepository) ListRoomMembers(ctx context.Context, roomID string) ([]models.RoomMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]models.RoomMember, 0)
+	for _, member := range r.roomMembers {
+		if member.RoomID == roomID {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members, nil
+}
+
+// ============================================================================
+// Library Comparison Operations
+// ============================================================================
+
+// PutComparisonConsent stores a user's consent to compare libraries with a
+// peer, replacing an earlier one
+func (r *MemoryRepository) PutComparisonConsent(ctx context.Context, consent models.ComparisonConsent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.comparisonConsents[memoryKey(consent.UserID, consent.PeerID)] = consent
+	return nil
+}
+
+// GetComparisonConsent retrieves a user's consent to compare with a peer
+func (r *MemoryRepository) GetComparisonConsent(ctx context.Context, userID, peerID string) (*models.ComparisonConsent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	consent, ok := r.comparisonConsents[memoryKey(userID, peerID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &consent, nil
+}
+
+// DeleteComparisonConsent withdraws a user's consent to compare with a peer
+func (r *MemoryRepository) DeleteComparisonConsent(ctx context.Context, userID, peerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, peerID)
+	if _, ok := r.comparisonConsents[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.comparisonConsents, key)
+	return nil
+}
+
+// ListComparisonConsents lists the consents a user has given, by peer
+func (r *MemoryRepository) ListComparisonConsents(ctx context.Context, userID string) ([]models.ComparisonConsent, error) {
+	return r.listComparisonConsents(func(c models.ComparisonConsent) bool { return c.UserID == userID }), nil
+}
+
+// ListReceivedComparisonConsents lists the consents given to a user, by giver
+func (r *MemoryRepository) ListReceivedComparisonConsents(ctx context.Context, peerID string) ([]models.ComparisonConsent, error) {
+	consents := r.listComparisonConsents(func(c models.ComparisonConsent) bool { return c.PeerID == peerID })
+	sort.Slice(consents, func(i, j int) bool { return consents[i].UserID < consents[j].UserID })
+	return consents, nil
+}
+
+func (r *MemoryRepository) listComparisonConsents(keep func(models.ComparisonConsent) bool) []models.ComparisonConsent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	consents := make([]models.ComparisonConsent, 0)
+	for _, consent := range r.comparisonConsents {
+		if keep(consent) {
+			consents = append(consents, consent)
+		}
+	}
+	sort.Slice(consents, func(i, j int) bool { return consents[i].PeerID < consents[j].PeerID })
+	return consents
+}
+
+// ============================================================================
+// Archive Insight Operations
+// ============================================================================
+
+// GetArchiveInsight retrieves the cold track report of a library
+func (r *MemoryRepository) GetArchiveInsight(ctx context.Context, libraryID string) (*models.ArchiveInsight, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	insight, ok := r.archiveInsights[libraryID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	insight.Tracks = append([]models.ColdTrack{}, insight.Tracks...)
+	insight.Dismissed = append([]string(nil), insight.Dismissed...)
+	return &insight, nil
+}
+
+// PutArchiveInsight stores the cold track report of a library, replacing the last
+func (r *MemoryRepository) PutArchiveInsight(ctx context.Context, insight models.ArchiveInsight) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	insight.Tracks = append([]models.ColdTrack{}, insight.Tracks...)
+	insight.Dismissed = append([]string(nil), insight.Dismissed...)
+	r.archiveInsights[insight.LibraryID] = insight
+	return nil
+}
+
+// ============================================================================
+// Undo Operations
+// ============================================================================
+
+// GetOperation retrieves the undo record of a user's bulk operation. Expired
+// records are kept, as DynamoDB keeps them until its TTL sweep.
+func (r *MemoryRepository) GetOperation(ctx context.Context, userID, operationID string) (*models.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	op, ok := r.operations[memoryKey(userID, operationID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	op.Changes = append([]models.FieldChange(nil), op.Changes...)
+	op.Trashed = slices.Clone(op.Trashed)
+	return &op, nil
+}
+
+// PutOperation stores the undo record of a bulk operation
+func (r *MemoryRepository) PutOperation(ctx context.Context, op models.Operation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	op.Changes = append([]models.FieldChange(nil), op.Changes...)
+	op.Trashed = slices.Clone(op.Trashed)
+	r.operations[memoryKey(op.UserID, op.ID)] = op
+	return nil
+}
+
+// DeleteOperation removes the undo record of a bulk operation
+func (r *MemoryRepository) DeleteOperation(ctx context.Context, userID, operationID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.operations, memoryKey(userID, operationID))
+	return nil
+}
+
+// ============================================================================
+// Trash Operations
+// ============================================================================
+
+// GetTrashedTrack retrieves a track from the trash of its owner's library.
+// Expired items are kept, as DynamoDB keeps them until its TTL sweep.
+func (r *MemoryRepository) GetTrashedTrack(ctx context.Context, ownerID, trackID string) (*models.TrashedTrack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trashed, ok := r.trashed[memoryKey(ownerID, trackID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &trashed, nil
+}
+
+// PutTrashedTrack keeps a deleted track in the trash of its owner's library
+func (r *MemoryRepository) PutTrashedTrack(ctx context.Context, trashed models.TrashedTrack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.trashed[memoryKey(trashed.Track.UserID, trashed.Track.ID)] = trashed
+	return nil
+}
+
+// DeleteTrashedTrack removes a track from the trash
+func (r *MemoryRepository) DeleteTrashedTrack(ctx context.Context, ownerID, trackID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.trashed, memoryKey(ownerID, trackID))
+	return nil
+}
+
+// ============================================================================
+// Collection Operations
+// ============================================================================
+
+// PutCollection creates or replaces a library collection
+func (r *MemoryRepository) PutCollection(ctx context.Context, collection models.Collection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	collection.TrackIDs = slices.Clone(collection.TrackIDs)
+	r.collections[memoryKey(collection.UserID, collection.ID)] = collection
+	return nil
+}
+
+// GetCollection retrieves a library collection
+func (r *MemoryRepository) GetCollection(ctx context.Context, libraryID, collectionID string) (*models.Collection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	collection, ok := r.collections[memoryKey(libraryID, collectionID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &collection, nil
+}
+
+// ListCollections retrieves every collection of a library, ordered by ID
+// like the DynamoDB sort key
+func (r *MemoryRepository) ListCollections(ctx context.Context, libraryID string) ([]models.Collection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	collections := make([]models.Collection, 0)
+	for _, collection := range r.collections {
+		if collection.UserID == libraryID {
+			collections = append(collections, collection)
+		}
+	}
+	sort.Slice(collections, func(i, j int) bool { return collections[i].ID < collections[j].ID })
+	return collections, nil
+}
+
+// UpdateCollection saves a collection's name, filter and position, leaving
+// its count and members alone
+func (r *MemoryRepository) UpdateCollection(ctx context.Context, collection models.Collection) error {
+	return r.updateCollection(collection.UserID, collection.ID, func(stored *models.Collection) {
+		stored.Name = collection.Name
+		stored.Position = collection.Position
+		stored.UpdatedAt = collection.UpdatedAt
+		if collection.Filter != nil {
+			stored.Filter = collection.Filter
+		}
+	})
+}
+
+// DeleteCollection removes a library collection
+func (r *MemoryRepository) DeleteCollection(ctx context.Context, libraryID, collectionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.collections, memoryKey(libraryID, collectionID))
+	return nil
+}
+
+// AdjustCollectionCount adds delta to a collection's track count
+func (r *MemoryRepository) AdjustCollectionCount(ctx context.Context, libraryID, collectionID string, delta int) error {
+	return r.updateCollection(libraryID, collectionID, func(stored *models.Collection) {
+		stored.TrackCount += delta
+	})
+}
+
+// SetCollectionCount replaces a collection's track count
+func (r *MemoryRepository) SetCollectionCount(ctx context.Context, libraryID, collectionID string, count int) error {
+	return r.updateCollection(libraryID, collectionID, func(stored *models.Collection) {
+		stored.TrackCount = count
+	})
+}
+
+// AddCollectionTracks adds tracks that are not members yet to a manual
+// collection and counts them
+func (r *MemoryRepository) AddCollectionTracks(ctx context.Context, libraryID, collectionID string, trackIDs []string) error {
+	return r.updateCollection(libraryID, collectionID, func(stored *models.Collection) {
+		stored.TrackIDs = append(slices.Clone(stored.TrackIDs), trackIDs...)
+		stored.TrackCount += len(trackIDs)
+	})
+}
+
+// RemoveCollectionTracks removes member tracks from a manual collection and
+// uncounts them
+func (r *MemoryRepository) RemoveCollectionTracks(ctx context.Context, libraryID, collectionID string, trackIDs []string) error {
+	return r.updateCollection(libraryID, collectionID, func(stored *models.Collection) {
+		stored.TrackIDs = slices.DeleteFunc(slices.Clone(stored.TrackIDs), func(id string) bool {
+			return slices.Contains(trackIDs, id)
+		})
+		stored.TrackCount -= len(trackIDs)
+	})
+}
+
+func (r *MemoryRepository) updateCollection(libraryID, collectionID string, update func(*models.Collection)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(libraryID, collectionID)
+	collection, ok := r.collections[key]
+	if !ok {
+		return ErrNotFound
+	}
+	update(&collection)
+	r.collections[key] = collection
+	return nil
+}
+
+// ============================================================================
+// Featured Content Operations
+// ============================================================================
+
+// PutFeaturedItem creates or replaces a featured item
+func (r *MemoryRepository) PutFeaturedItem(ctx context.Context, item models.FeaturedItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.featured[item.ID] = item
+	return nil
+}
+
+// GetFeaturedItem retrieves a featured item
+func (r *MemoryRepository) GetFeaturedItem(ctx context.Context, itemID string) (*models.FeaturedItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	item, ok := r.featured[itemID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &item, nil
+}
+
+// ListFeaturedItems retrieves every featured item in ID order
+func (r *MemoryRepository) ListFeaturedItems(ctx context.Context) ([]models.FeaturedItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]models.FeaturedItem, 0, len(r.featured))
+	for _, item := range r.featured {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+// DeleteFeaturedItem removes a featured item
+func (r *MemoryRepository) DeleteFeaturedItem(ctx context.Context, itemID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.featured, itemID)
+	return nil
+}
+
+// ============================================================================
+// Moderation Operations
+// ============================================================================
+
+// PutModerationReview creates or replaces the moderation review of a track
+func (r *MemoryRepository) PutModerationReview(ctx context.Context, review models.ModerationReview) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review.UpdatedAt = time.Now()
+	r.moderation[review.TrackID] = review
+	return nil
+}
+
+// GetModerationReview retrieves the moderation review of a track
+func (r *MemoryRepository) GetModerationReview(ctx context.Context, trackID string) (*models.ModerationReview, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	review, ok := r.moderation[trackID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &review, nil
+}
+
+// ListModerationReviews lists reviews in one status, oldest first
+func (r *MemoryRepository) ListModerationReviews(ctx context.Context, filter models.ModerationFilter) (*PaginatedResult[models.ModerationReview], error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	reviews := make([]models.ModerationReview, 0)
+	for _, review := range r.moderation {
+		if review.Status == filter.Status {
+			reviews = append(reviews, review)
+		}
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(reviews, func(m models.ModerationReview) string {
+		return memoryKey(m.CreatedAt.UTC().Format(time.RFC3339Nano), m.TrackID)
+	}, models.GetModerationStatusGSI1PK(filter.Status), limit, filter.Cursor, false)
+}
+
+// ScanTrackItems pages through every user's tracks as items. Memory items
+// are built on read, so their indexes are always current.
+func (r *MemoryRepository) ScanTrackItems(ctx context.Context, cursor string, limit int) (*PaginatedResult[models.TrackItem], error) {
+	r.mu.RLock()
+	items := make([]models.TrackItem, 0, len(r.tracks))
+	for _, track := range r.tracks {
+		items = append(items, models.NewTrackItem(track))
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(items, func(item models.TrackItem) string {
+		return memoryKey(item.UserID, item.ID)
+	}, "TRACKS", limit, cursor, false)
+}
+
+// ScanAlbums pages through every user's albums
+func (r *MemoryRepository) ScanAlbums(ctx context.Context, cursor string, limit int) (*PaginatedResult[models.Album], error) {
+	r.mu.RLock()
+	albums := make([]models.Album, 0, len(r.albums))
+	for _, album := range r.albums {
+		albums = append(albums, album)
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(albums, func(album models.Album) string {
+		return memoryKey(album.UserID, album.ID)
+	}, "ALBUMS", limit, cursor, false)
+}
+
+// PutTrackIndexes checks the track is unchanged since item was built; memory
+// tracks have no stored indexes to write
+func (r *MemoryRepository) PutTrackIndexes(ctx context.Context, item models.TrackItem) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	track, ok := r.tracks[memoryKey(item.UserID, item.ID)]
+	if !ok || !track.UpdatedAt.Equal(item.UpdatedAt) {
+		return ErrConflict
+	}
+	return nil
+}
+
+// ScanSortableItems pages through the sort keys of every user's items of one
+// entity. Memory items are built on read, so their keys match their locale.
+func (r *MemoryRepository) ScanSortableItems(ctx context.Context, entity models.EntityType, cursor string, limit int) (*PaginatedResult[models.SortableItem], error) {
+	r.mu.RLock()
+	var items []models.SortableItem
+	switch entity {
+	case models.EntityTrack:
+		for _, track := range r.tracks {
+			items = append(items, sortableTrack(track))
+		}
+	case models.EntityAlbum:
+		for _, album := range r.albums {
+			items = append(items, sortableAlbum(album))
+		}
+	case models.EntityArtist:
+		for _, artist := range r.artists {
+			items = append(items, sortableArtist(artist))
+		}
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(items, func(item models.SortableItem) string {
+		return memoryKey(item.UserID, item.ID)
+	}, string(entity)+"S", limit, cursor, false)
+}
+
+// PutSortKey records the locale of item if it is unchanged since it was read
+func (r *MemoryRepository) PutSortKey(ctx context.Context, item models.SortableItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(item.UserID, item.ID)
+	switch models.EntityType(item.Type) {
+	case models.EntityTrack:
+		track, ok := r.tracks[key]
+		if !ok || !track.UpdatedAt.Equal(item.UpdatedAt) {
+			return ErrConflict
+		}
+		track.SortLocale = item.SortLocale
+		r.tracks[key] = track
+	case models.EntityAlbum:
+		album, ok := r.albums[key]
+		if !ok || !album.UpdatedAt.Equal(item.UpdatedAt) {
+			return ErrConflict
+		}
+		album.SortLocale = item.SortLocale
+		r.albums[key] = album
+	case models.EntityArtist:
+		artist, ok := r.artists[key]
+		if !ok || !artist.UpdatedAt.Equal(item.UpdatedAt) {
+			return ErrConflict
+		}
+		artist.SortLocale = item.SortLocale
+		r.artists[key] = artist
+	default:
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetSortLocale keys a user's tracks, albums and artists in locale and
+// returns how many were keyed in another
+func (r *MemoryRepository) SetSortLocale(ctx context.Context, userID, locale string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated := 0
+	for key, track := range r.tracks {
+		if track.UserID == userID && track.SortLocale != locale {
+			track.SortLocale = locale
+			r.tracks[key] = track
+			updated++
+		}
+	}
+	for key, album := range r.albums {
+		if album.UserID == userID && album.SortLocale != locale {
+			album.SortLocale = locale
+			r.albums[key] = album
+			updated++
+		}
+	}
+	for key, artist := range r.artists {
+		if artist.UserID == userID && artist.SortLocale != locale {
+			artist.SortLocale = locale
+			r.artists[key] = artist
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// sortableTrack, sortableAlbum and sortableArtist build the sort key
+// attributes DynamoDB stores for an item
+func sortableTrack(track models.Track) models.SortableItem {
+	item := models.NewTrackItem(track)
+	return models.SortableItem{
+		PK: item.PK, SK: item.SK, Type: item.Type, ID: track.ID, UserID: track.UserID,
+		Title: track.Title, SortLocale: track.SortLocale,
+		GSI5PK: item.GSI5PK, GSI5SK: item.GSI5SK, UpdatedAt: track.UpdatedAt,
+	}
+}
+
+func sortableAlbum(album models.Album) models.SortableItem {
+	item := models.NewAlbumItem(album)
+	return models.SortableItem{
+		PK: item.PK, SK: item.SK, Type: item.Type, ID: album.ID, UserID: album.UserID,
+		Title: album.Title, SortLocale: album.SortLocale,
+		GSI5PK: item.GSI5PK, GSI5SK: item.GSI5SK, UpdatedAt: album.UpdatedAt,
+	}
+}
+
+func sortableArtist(artist models.Artist) models.SortableItem {
+	item := models.NewArtistItem(artist)
+	return models.SortableItem{
+		PK: item.PK, SK: item.SK, Type: item.Type, ID: artist.ID, UserID: artist.UserID,
+		Name: artist.Name, SortName: artist.SortName, SortLocale: artist.SortLocale,
+		GSI5PK: item.GSI5PK, GSI5SK: item.GSI5SK, UpdatedAt: artist.UpdatedAt,
+	}
+}
+
+// SetDefaultTrackVisibility sets a track's visibility only if it has none
+func (r *MemoryRepository) SetDefaultTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, trackID)
+	track, ok := r.tracks[key]
+	if !ok || track.Visibility != "" {
+		return false, nil
+	}
+	track.Visibility = visibility
+	r.tracks[key] = track
+	return true, nil
+}
+
+// RekeyAlbum moves an album and its tracks to a new ID, keeping an album
+// that already has the new ID
+func (r *MemoryRepository) RekeyAlbum(ctx context.Context, userID, fromID, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fromKey := memoryKey(userID, fromID)
+	album, ok := r.albums[fromKey]
+	if !ok {
+		return ErrNotFound
+	}
+	toKey := memoryKey(userID, toID)
+	if _, exists := r.albums[toKey]; !exists {
+		album.ID = toID
+		album.UpdatedAt = time.Now()
+		r.albums[toKey] = album
+	}
+
+	for key, track := range r.tracks {
+		if track.UserID == userID && track.AlbumID == fromID {
+			track.AlbumID = toID
+			r.tracks[key] = track
+		}
+	}
+	delete(r.albums, fromKey)
+	return nil
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+)
+
+// MemoryS3Repository is a thread-safe in-memory implementation of MediaStore.
+// It tracks object keys and their metadata, and the bodies of objects written
+// with WriteObject; presigned URLs are stub URLs under baseURL that nothing
+// serves.
+type MemoryS3Repository struct {
+	mu        sync.RWMutex
+	baseURL   string
+	objects   map[string]map[string]string // key -> metadata
+	bodies    map[string][]byte            // key -> body, of written objects
+	multipart map[string]string            // uploadID -> key
+	nextID    int
+}
+
+// NewMemoryS3Repository creates an empty object store. Presigned URLs are
+// generated under baseURL, defaulting to memory://media.
+func NewMemoryS3Repository(baseURL string) *MemoryS3Repository {
+	if baseURL == "" {
+		baseURL = "memory://media"
+	}
+	return &MemoryS3Repository{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		objects:   make(map[string]map[string]string),
+		bodies:    make(map[string][]byte),
+		multipart: make(map[string]string),
+	}
+}
+
+// PutObject stores an object's metadata, e.g. to seed fixtures or stand in for
+// a client upload to a presigned URL
+func (r *MemoryS3Repository) PutObject(key string, metadata map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.objects[key] = copyMetadata(metadata)
+	delete(r.bodies, key)
+}
+
+// Keys returns every stored key with the given prefix
+func (r *MemoryS3Repository) Keys(prefix string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]string, 0)
+	for key := range r.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// presignedURL builds a stub URL that carries the key, operation and expiry
+func (r *MemoryS3Repository) presignedURL(key, method string, expiry time.Duration, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("method", method)
+	query.Set("expires", time.Now().Add(expiry).UTC().Format(time.RFC3339))
+	return fmt.Sprintf("%s/%s?%s", r.baseURL, key, query.Encode())
+}
+
+func (r *MemoryS3Repository) GeneratePresignedUploadURL(ctx context.Context, key, contentType string, expiry time.Duration) (string, error) {
+	return r.presignedURL(key, "PUT", expiry, url.Values{"contentType": {contentType}}), nil
+}
+
+func (r *MemoryS3Repository) GeneratePresignedDownloadURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return r.presignedURL(key, "GET", expiry, nil), nil
+}
+
+func (r *MemoryS3Repository) GeneratePresignedDownloadURLWithFilename(ctx context.Context, key string, expiry time.Duration, filename string) (string, error) {
+	disposition := sanitize.ContentDisposition(filename)
+	return r.presignedURL(key, "GET", expiry, url.Values{"response-content-disposition": {disposition}}), nil
+}
+
+func (r *MemoryS3Repository) InitiateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	uploadID := fmt.Sprintf("memory-upload-%d", r.nextID)
+	r.multipart[uploadID] = key
+	return uploadID, nil
+}
+
+func (r *MemoryS3Repository) GenerateMultipartUploadURLs(ctx context.Context, key, uploadID string, numParts int, expiry time.Duration) ([]models.MultipartUploadPartURL, error) {
+	if err := r.checkMultipart(key, uploadID); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	urls := make([]models.MultipartUploadPartURL, 0, numParts)
+	for partNumber := 1; partNumber <= numParts; partNumber++ {
+		query := url.Values{
+			"uploadId":   {uploadID},
+			"partNumber": {fmt.Sprintf("%d", partNumber)},
+		}
+		urls = append(urls, models.MultipartUploadPartURL{
+			PartNumber: partNumber,
+			UploadURL:  r.presignedURL(key, "PUT", expiry, query),
+			ExpiresAt:  expiresAt,
+		})
+	}
+	return urls, nil
+}
+
+// CompleteMultipartUpload creates the object the upload was started for
+func (r *MemoryS3Repository) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []models.CompletedPartInfo) error {
+	if err := r.checkMultipart(key, uploadID); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.multipart, uploadID)
+	r.objects[key] = map[string]string{}
+	return nil
+}
+
+func (r *MemoryS3Repository) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	if err := r.checkMultipart(key, uploadID); err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.multipart, uploadID)
+	return nil
+}
+
+// checkMultipart verifies uploadID was initiated for key
+func (r *MemoryS3Repository) checkMultipart(key, uploadID string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.multipart[uploadID] != key {
+		return fmt.Errorf("no such upload %s for key %s: %w", uploadID, key, ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteObject removes an object; like S3, deleting a missing key succeeds
+func (r *MemoryS3Repository) DeleteObject(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.objects, key)
+	delete(r.bodies, key)
+	return nil
+}
+
+// DeleteByPrefix removes every object under prefix
+func (r *MemoryS3Repository) DeleteByPrefix(ctx context.Context, prefix string) error {
+	if prefix == "" {
+		return fmt.Errorf("prefix cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.objects {
+		if strings.HasPrefix(key, prefix) {
+			delete(r.objects, key)
+			delete(r.bodies, key)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryS3Repository) CopyObject(ctx context.Context, sourceKey, destKey string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	metadata, ok := r.objects[sourceKey]
+	if !ok {
+		return fmt.Errorf("failed to copy object: %w", ErrNotFound)
+	}
+	r.objects[destKey] = copyMetadata(metadata)
+	if body, ok := r.bodies[sourceKey]; ok {
+		r.bodies[destKey] = body
+	} else {
+		delete(r.bodies, destKey)
+	}
+	return nil
+}
+
+// ReadObject opens an object for reading. Objects stored with PutObject have
+// an empty body.
+func (r *MemoryS3Repository) ReadObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.objects[key]; !ok {
+		return nil, fmt.Errorf("failed to read object: %w", ErrNotFound)
+	}
+	return io.NopCloser(bytes.NewReader(r.bodies[key])), nil
+}
+
+// WriteObject stores data as an object, replacing any already at key
+func (r *MemoryS3Repository) WriteObject(ctx context.Context, key string, data []byte, contentType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.objects[key] = map[string]string{
+		"content-type":   contentType,
+		"content-length": strconv.Itoa(len(data)),
+	}
+	r.bodies[key] = bytes.Clone(data)
+	return nil
+}
+
+func (r *MemoryS3Repository) GetObjectMetadata(ctx context.Context, key string) (map[string]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	metadata, ok := r.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("failed to get object metadata: %w", ErrNotFound)
+	}
+	return copyMetadata(metadata), nil
+}
+
+func (r *MemoryS3Repository) ObjectExists(ctx context.Context, key string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.objects[key]
+	return ok, nil
+}
+
+// ListObjectVersions reports a stored object as its only, unversioned
+// ("null") version, the way S3 lists objects written before versioning
+func (r *MemoryS3Repository) ListObjectVersions(ctx context.Context, key string) ([]models.S3ObjectVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.objects[key]; !ok {
+		return []models.S3ObjectVersion{}, nil
+	}
+	return []models.S3ObjectVersion{{Key: key, VersionID: "null", IsLatest: true}}, nil
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	copied := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	return copied
+}
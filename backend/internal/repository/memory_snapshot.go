@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// memoryLock guards a MemoryRepository and counts the writes it guarded, so
+// a snapshot is only saved when something changed
+type memoryLock struct {
+	sync.RWMutex
+	writes atomic.Uint64
+}
+
+// Unlock releases a write lock
+func (l *memoryLock) Unlock() {
+	l.writes.Add(1)
+	l.RWMutex.Unlock()
+}
+
+// memorySnapshot points at the stores of a MemoryRepository, for gob to
+// encode them or decode into them. Stores missing from a snapshot written by
+// an older version are left empty.
+type memorySnapshot struct {
+	Tracks             *map[string]models.Track
+	Albums             *map[string]models.Album
+	Artists            *map[string]models.Artist
+	Users              *map[string]models.User
+	Playlists          *map[string]models.Playlist
+	PlaylistTracks     *map[string]models.PlaylistTrack
+	Profiles           *map[string]models.ArtistProfile
+	Follows            *map[string]models.Follow
+	Tags               *map[string]models.Tag
+	TrackTags          *map[string]models.TrackTag
+	Uploads            *map[string]models.Upload
+	Shares             *map[string]models.TrackShare
+	Households         *map[string]models.Household
+	Members            *map[string]models.HouseholdMember
+	Neighbors          *map[string]models.TrackNeighbors
+	Embeddings         *map[string]models.TrackEmbedding
+	Migrations         *map[int]models.MigrationState
+	Moderation         *map[string]models.ModerationReview
+	SearchHistory      *map[string]models.SearchHistory
+	SearchBoosts       *map[string]models.SearchBoosts
+	PlayerStates       *map[string]models.PlayerState
+	Devices            *map[string]models.DeviceSession
+	RemoteCommands     *map[string][]models.RemoteCommand
+	Parties            *map[string]models.Party
+	PartyRequests      *map[string]models.PartyRequest
+	DownloadTokens     *map[string]models.DownloadToken
+	Operations         *map[string]models.Operation
+	Trashed            *map[string]models.TrashedTrack
+	Collections        *map[string]models.Collection
+	Featured           *map[string]models.FeaturedItem
+	AccessEvents       *map[string]models.TrackAccessEvent
+	AccessCounts       *map[string]models.TrackAccessCounts
+	ComparisonConsents *map[string]models.ComparisonConsent
+	Rooms              *map[string]models.ListeningRoom
+	RoomMembers        *map[string]models.RoomMember
+	ArchiveInsights    *map[string]models.ArchiveInsight
+}
+
+// snapshot returns the snapshot of the repository's stores
+func (r *MemoryRepository) snapshot() *memorySnapshot {
+	return &memorySnapshot{
+		Tracks:             &r.tracks,
+		Albums:             &r.albums,
+		Artists:            &r.artists,
+		Users:              &r.users,
+		Playlists:          &r.playlists,
+		PlaylistTracks:     &r.playlistTracks,
+		Profiles:           &r.profiles,
+		Follows:            &r.follows,
+		Tags:               &r.tags,
+		TrackTags:          &r.trackTags,
+		Uploads:            &r.uploads,
+		Shares:             &r.shares,
+		Households:         &r.households,
+		Members:            &r.members,
+		Neighbors:          &r.neighbors,
+		Embeddings:         &r.embeddings,
+		Migrations:         &r.migrations,
+		Moderation:         &r.moderation,
+		SearchHistory:      &r.searchHistory,
+		SearchBoosts:       &r.searchBoosts,
+		PlayerStates:       &r.playerStates,
+		Devices:            &r.devices,
+		RemoteCommands:     &r.remoteCommands,
+		Parties:            &r.parties,
+		PartyRequests:      &r.partyRequests,
+		DownloadTokens:     &r.downloadTokens,
+		Operations:         &r.operations,
+		Trashed:            &r.trashed,
+		Collections:        &r.collections,
+		Featured:           &r.featured,
+		AccessEvents:       &r.accessEvents,
+		AccessCounts:       &r.accessCounts,
+		ComparisonConsents: &r.comparisonConsents,
+		Rooms:              &r.rooms,
+		RoomMembers:        &r.roomMembers,
+		ArchiveInsights:    &r.archiveInsights,
+	}
+}
+
+// Writes counts the writes made to the repository since it was created
+func (r *MemoryRepository) Writes() uint64 {
+	return r.mu.writes.Load()
+}
+
+// WriteSnapshot writes everything the repository holds to w
+func (r *MemoryRepository) WriteSnapshot(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if err := gob.NewEncoder(w).Encode(r.snapshot()); err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return nil
+}
+
+// SaveSnapshot writes the repository's snapshot to path. The file is
+// replaced whole, so a crash while saving leaves the previous snapshot.
+func (r *MemoryRepository) SaveSnapshot(path string) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if err := r.WriteSnapshot(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadMemorySnapshot creates a repository holding the snapshot read from r
+func ReadMemorySnapshot(r io.Reader) (*MemoryRepository, error) {
+	repo := NewMemoryRepository()
+	if err := gob.NewDecoder(r).Decode(repo.snapshot()); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return repo, nil
+}
+
+// OpenMemoryRepository creates a repository holding the snapshot saved at
+// path, or an empty one when there is none yet. Self-hosted servers keep
+// their data this way, saving it with SaveSnapshot as it changes.
+func OpenMemoryRepository(path string) (*MemoryRepository, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewMemoryRepository(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+	return ReadMemorySnapshot(file)
+}
@@ -0,0 +1,519 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepository_Tracks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("create, get, update and delete", func(t *testing.T) {
+		repo := NewMemoryRepository()
+		require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1", Title: "One"}))
+
+		err := repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1"})
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+
+		track, err := repo.GetTrack(ctx, "u1", "t1")
+		require.NoError(t, err)
+		assert.Equal(t, "One", track.Title)
+		assert.False(t, track.CreatedAt.IsZero())
+
+		track.Title = "Uno"
+		require.NoError(t, repo.UpdateTrack(ctx, *track))
+		byID, err := repo.GetTrackByID(ctx, "t1")
+		require.NoError(t, err)
+		assert.Equal(t, "Uno", byID.Title)
+
+		require.NoError(t, repo.DeleteTrack(ctx, "u1", "t1"))
+		_, err = repo.GetTrack(ctx, "u1", "t1")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, repo.UpdateTrack(ctx, *track), ErrNotFound)
+	})
+
+	t.Run("returned tracks are copies", func(t *testing.T) {
+		repo := NewMemoryRepository()
+		require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1", Title: "One"}))
+
+		track, err := repo.GetTrack(ctx, "u1", "t1")
+		require.NoError(t, err)
+		track.Title = "changed"
+
+		stored, err := repo.GetTrack(ctx, "u1", "t1")
+		require.NoError(t, err)
+		assert.Equal(t, "One", stored.Title)
+	})
+
+	t.Run("list pages through a user's tracks", func(t *testing.T) {
+		repo := NewMemoryRepository()
+		for i := 0; i < 5; i++ {
+			require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: fmt.Sprintf("t%d", i), UserID: "u1"}))
+		}
+		require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "other", UserID: "u2"}))
+
+		first, err := repo.ListTracks(ctx, "u1", models.TrackFilter{Limit: 3})
+		require.NoError(t, err)
+		assert.True(t, first.HasMore)
+		assert.Equal(t, []string{"t0", "t1", "t2"}, trackIDs(first.Items))
+
+		second, err := repo.ListTracks(ctx, "u1", models.TrackFilter{Limit: 3, LastKey: first.NextCursor})
+		require.NoError(t, err)
+		assert.False(t, second.HasMore)
+		assert.Equal(t, []string{"t3", "t4"}, trackIDs(second.Items))
+
+		all, err := repo.ListTracks(ctx, "", models.TrackFilter{GlobalScope: true})
+		require.NoError(t, err)
+		assert.Len(t, all.Items, 6)
+
+		_, err = repo.ListTracks(ctx, "u1", models.TrackFilter{LastKey: "%%%"})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("public visibility stamps PublishedAt", func(t *testing.T) {
+		repo := NewMemoryRepository()
+		require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1"}))
+		require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t2", UserID: "u2"}))
+
+		require.NoError(t, repo.UpdateTrackVisibility(ctx, "u1", "t1", models.VisibilityPublic))
+		assert.ErrorIs(t, repo.UpdateTrackVisibility(ctx, "u1", "missing", models.VisibilityPublic), ErrNotFound)
+
+		public, err := repo.ListPublicTracks(ctx, 10, "")
+		require.NoError(t, err)
+		require.Len(t, public.Items, 1)
+		assert.Equal(t, "t1", public.Items[0].ID)
+		assert.NotNil(t, public.Items[0].PublishedAt)
+	})
+}
+
+func TestMemoryRepository_TagsSkipDeletedTracks(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1"}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t2", UserID: "u1"}))
+	require.NoError(t, repo.AddTagsToTrack(ctx, "u1", "t1", []string{"house", "deep"}))
+	require.NoError(t, repo.AddTagsToTrack(ctx, "u1", "t2", []string{"house"}))
+	require.NoError(t, repo.DeleteTrack(ctx, "u1", "t2"))
+
+	tags, err := repo.GetTrackTags(ctx, "u1", "t1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deep", "house"}, tags)
+
+	tracks, err := repo.GetTracksByTag(ctx, "u1", "house")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1"}, trackIDs(tracks))
+}
+
+func TestMemoryRepository_PlaylistTracks(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "p1", UserID: "u1", Name: "Warmup"}))
+	require.NoError(t, repo.AddTracksToPlaylist(ctx, "p1", []string{"a", "b", "c"}, 0))
+	require.NoError(t, repo.RemoveTracksFromPlaylist(ctx, "p1", []string{"b"}))
+
+	tracks, err := repo.GetPlaylistTracks(ctx, "p1")
+	require.NoError(t, err)
+	require.Len(t, tracks, 2)
+	assert.Equal(t, 2, tracks[1].Position)
+
+	require.NoError(t, repo.ReorderPlaylistTracks(ctx, "p1", []models.PlaylistTrack{tracks[1], tracks[0]}))
+	tracks, err = repo.GetPlaylistTracks(ctx, "p1")
+	require.NoError(t, err)
+	assert.Equal(t, "c", tracks[0].TrackID)
+	assert.Equal(t, 1, tracks[1].Position)
+
+	require.NoError(t, repo.DeletePlaylist(ctx, "u1", "p1"))
+	tracks, err = repo.GetPlaylistTracks(ctx, "p1")
+	require.NoError(t, err)
+	assert.Empty(t, tracks)
+}
+
+func TestMemoryRepository_Households(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	require.NoError(t, repo.CreateUser(ctx, models.User{ID: "owner", Email: "owner@example.com"}))
+	require.NoError(t, repo.CreateUser(ctx, models.User{ID: "kid", Email: "kid@example.com"}))
+
+	household := models.Household{ID: "h1", OwnerID: "owner", MemberCount: 1}
+	owner := models.HouseholdMember{HouseholdID: "h1", UserID: "owner", Role: models.HouseholdRoleOwner}
+	require.NoError(t, repo.CreateHousehold(ctx, household, owner))
+
+	member := models.HouseholdMember{HouseholdID: "h1", UserID: "kid", Role: models.HouseholdRoleMember, JoinedAt: time.Now()}
+	require.NoError(t, repo.AddHouseholdMember(ctx, member))
+	assert.ErrorIs(t, repo.AddHouseholdMember(ctx, member), ErrAlreadyExists)
+
+	kid, err := repo.GetUserByEmail(ctx, "KID@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "h1", kid.HouseholdID)
+
+	stored, err := repo.GetHousehold(ctx, "h1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.MemberCount)
+
+	require.NoError(t, repo.DeleteHousehold(ctx, "h1", []string{"owner", "kid"}))
+	members, err := repo.ListHouseholdMembers(ctx, "h1")
+	require.NoError(t, err)
+	assert.Empty(t, members)
+	kid, err = repo.GetUser(ctx, "kid")
+	require.NoError(t, err)
+	assert.Empty(t, kid.HouseholdID)
+}
+
+func TestMemoryRepository_HarmonicIndex(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	for _, track := range []models.Track{
+		{ID: "t1", UserID: "u1", KeyCamelot: "8A", BPM: 128},
+		{ID: "t2", UserID: "u1", KeyCamelot: "8A", BPM: 64},
+		{ID: "t3", UserID: "u1", KeyCamelot: "8A"},
+		{ID: "t4", UserID: "u1", KeyCamelot: "9A", BPM: 128},
+		{ID: "t5", UserID: "u2", KeyCamelot: "8A", BPM: 128},
+		{ID: "t6", UserID: "u1", BPM: 128},
+	} {
+		require.NoError(t, repo.CreateTrack(ctx, track))
+	}
+
+	ids := func(tracks []models.Track) []string {
+		out := make([]string, len(tracks))
+		for i, track := range tracks {
+			out[i] = track.ID
+		}
+		return out
+	}
+
+	tracks, err := repo.ListTracksByKeyAndBPMBucket(ctx, "u1", "8A", 30, 34)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1"}, ids(tracks))
+
+	tracks, err = repo.ListTracksByKeyAndBPMBucket(ctx, "u1", "8A", -1, models.MaxBPMBucket)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t3", "t2", "t1"}, ids(tracks), "unknown tempo sorts first")
+
+	tracks, err = repo.ListTracksByKeyAndBPMBucket(ctx, "u1", "", 0, models.MaxBPMBucket)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t6"}, ids(tracks))
+}
+
+func TestMemoryRepository_SetAlbumCover(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	album, err := repo.GetOrCreateAlbum(ctx, "u1", "Album", "Artist")
+	require.NoError(t, err)
+
+	style := &models.CoverStyle{Colors: []string{"#102030"}, BlurHash: "LKO2?U%2Tw=w]~RBVZRi};RPxuwH"}
+	require.NoError(t, repo.SetAlbumCover(ctx, "u1", album.ID, "covers/u1/a.jpg", style))
+	assert.ErrorIs(t, repo.SetAlbumCover(ctx, "u1", album.ID, "covers/u1/b.jpg", nil), ErrConflict)
+	assert.ErrorIs(t, repo.SetAlbumCover(ctx, "u1", "missing", "covers/u1/b.jpg", nil), ErrConflict)
+
+	got, err := repo.GetAlbum(ctx, "u1", album.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "covers/u1/a.jpg", got.CoverArtKey)
+	assert.Equal(t, style, got.CoverStyle)
+}
+
+func TestMemoryRepository_SortLocale(t *testing.T) {
+	ctx := collation.WithLocale(context.Background(), "sv")
+	repo := NewMemoryRepository()
+	for id, title := range map[string]string{"t1": "Zebra", "t2": "Ödla", "t3": "apple"} {
+		require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: id, UserID: "u1", Title: title}))
+	}
+	titles := func(result *PaginatedResult[models.Track]) []string {
+		var titles []string
+		for _, track := range result.Items {
+			titles = append(titles, track.Title)
+		}
+		return titles
+	}
+
+	// Swedish sorts Ö after Z
+	page, err := repo.ListTracks(ctx, "u1", models.TrackFilter{SortBy: "title", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apple", "Zebra"}, titles(page))
+	require.True(t, page.HasMore)
+	page, err = repo.ListTracks(ctx, "u1", models.TrackFilter{SortBy: "title", Limit: 2, LastKey: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Ödla"}, titles(page))
+
+	updated, err := repo.SetSortLocale(ctx, "u1", "de")
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated)
+	page, err = repo.ListTracks(ctx, "u1", models.TrackFilter{SortBy: "title"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apple", "Ödla", "Zebra"}, titles(page))
+
+	updated, err = repo.SetSortLocale(ctx, "u1", "de")
+	require.NoError(t, err)
+	assert.Zero(t, updated)
+}
+
+func TestMemoryRepository_PutSortKey(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	require.NoError(t, repo.CreateArtist(ctx, models.Artist{ID: "a1", UserID: "u1", Name: "Édith Piaf"}))
+
+	page, err := repo.ScanSortableItems(ctx, models.EntityArtist, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	item := page.Items[0]
+	assert.True(t, item.SortKeyCurrent(collation.Root))
+
+	require.NoError(t, repo.PutSortKey(ctx, item.WithSortLocale("fr")))
+	artist, err := repo.GetArtist(ctx, "u1", "a1")
+	require.NoError(t, err)
+	assert.Equal(t, "fr", artist.SortLocale)
+
+	// An artist edited since it was read keeps its writer's key
+	require.NoError(t, repo.UpdateArtist(ctx, *artist))
+	assert.ErrorIs(t, repo.PutSortKey(ctx, item.WithSortLocale("de")), ErrConflict)
+}
+
+func TestMemoryRepository_FindTracksByContentHash(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t2", UserID: "u1", ContentHash: "abc"}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1", ContentHash: "abc"}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t3", UserID: "u1", ContentHash: "def"}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t4", UserID: "u2", ContentHash: "abc"}))
+
+	tracks, err := repo.FindTracksByContentHash(ctx, "u1", "abc")
+	require.NoError(t, err)
+	require.Len(t, tracks, 2)
+	assert.Equal(t, "t1", tracks[0].ID)
+	assert.Equal(t, "t2", tracks[1].ID)
+
+	tracks, err = repo.FindTracksByContentHash(ctx, "u2", "def")
+	require.NoError(t, err)
+	assert.Empty(t, tracks)
+}
+
+func TestMemoryRepository_TrackEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	for _, embedding := range []models.TrackEmbedding{
+		{UserID: "u1", TrackID: "t2", Model: "m1", Vector: []float32{1, 0}},
+		{UserID: "u1", TrackID: "t1", Model: "m1", Vector: []float32{0, 1}},
+		{UserID: "u1", TrackID: "t1", Model: "m2", Vector: []float32{1, 1, 1}},
+		{UserID: "u2", TrackID: "t1", Model: "m1", Vector: []float32{1, 1}},
+	} {
+		require.NoError(t, repo.PutTrackEmbedding(ctx, embedding))
+	}
+
+	got, err := repo.BatchGetTrackEmbeddings(ctx, "u1", "m1", []string{"t1", "t3"})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, []float32{0, 1}, got["t1"].Vector)
+	assert.Equal(t, 2, got["t1"].Dims)
+
+	m1, err := repo.ListTrackEmbeddings(ctx, "u1", "m1")
+	require.NoError(t, err)
+	require.Len(t, m1, 2)
+	assert.Equal(t, "t1", m1[0].TrackID)
+
+	all, err := repo.ListTrackEmbeddings(ctx, "u1", "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	require.NoError(t, repo.DeleteTrackEmbedding(ctx, "u1", "m2", "t1"))
+	all, err = repo.ListTrackEmbeddings(ctx, "u1", "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestMemoryRepository_ObserveTracks(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	type write struct{ before, after string }
+	var writes []write
+	title := func(track *models.Track) string {
+		if track == nil {
+			return ""
+		}
+		return track.Title
+	}
+	repo.ObserveTracks(func(ctx context.Context, before, after *models.Track) {
+		// The observer may read the repository back
+		_, _ = repo.ListCollections(ctx, "u1")
+		writes = append(writes, write{title(before), title(after)})
+	})
+
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1", Title: "One"}))
+	require.NoError(t, repo.UpdateTrack(ctx, models.Track{ID: "t1", UserID: "u1", Title: "Uno"}))
+	require.NoError(t, repo.DeleteTrack(ctx, "u1", "t1"))
+	assert.ErrorIs(t, repo.DeleteTrack(ctx, "u1", "t1"), ErrNotFound)
+
+	assert.Equal(t, []write{{"", "One"}, {"One", "Uno"}, {"Uno", ""}}, writes)
+}
+
+func TestMemoryRepository_Collections(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	require.NoError(t, repo.PutCollection(ctx, models.Collection{ID: "c1", UserID: "lib", Name: "Vinyl rips", TrackCount: 3}))
+	require.NoError(t, repo.AdjustCollectionCount(ctx, "lib", "c1", -1))
+	require.NoError(t, repo.AddCollectionTracks(ctx, "lib", "c1", []string{"t1", "t2"}))
+	require.NoError(t, repo.RemoveCollectionTracks(ctx, "lib", "c1", []string{"t1"}))
+	require.NoError(t, repo.UpdateCollection(ctx, models.Collection{ID: "c1", UserID: "lib", Name: "Vinyl", Position: 4}))
+
+	collection, err := repo.GetCollection(ctx, "lib", "c1")
+	require.NoError(t, err)
+	assert.Equal(t, "Vinyl", collection.Name)
+	assert.Equal(t, 4, collection.Position)
+	assert.Equal(t, []string{"t2"}, collection.TrackIDs)
+	assert.Equal(t, 3, collection.TrackCount, "updates leave the count alone")
+
+	require.NoError(t, repo.SetCollectionCount(ctx, "lib", "c1", 1))
+	collections, err := repo.ListCollections(ctx, "lib")
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	assert.Equal(t, 1, collections[0].TrackCount)
+
+	require.NoError(t, repo.DeleteCollection(ctx, "lib", "c1"))
+	assert.ErrorIs(t, repo.AdjustCollectionCount(ctx, "lib", "c1", 1), ErrNotFound)
+	_, err = repo.GetCollection(ctx, "lib", "c1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepository_ConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	require.NoError(t, repo.CreateUser(ctx, models.User{ID: "u1"}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = repo.CreateTrack(ctx, models.Track{ID: fmt.Sprintf("t%02d", i), UserID: "u1"})
+			_ = repo.IncrementUserFollowingCount(ctx, "u1", 1)
+		}(i)
+	}
+	wg.Wait()
+
+	tracks, err := repo.ListTracks(ctx, "u1", models.TrackFilter{Limit: 100})
+	require.NoError(t, err)
+	assert.Len(t, tracks.Items, 50)
+	user, err := repo.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 50, user.FollowingCount)
+}
+
+func TestMemoryRepository_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data.gob")
+
+	repo, err := OpenMemoryRepository(path)
+	require.NoError(t, err, "a missing snapshot opens empty")
+	require.NoError(t, repo.CreateUser(ctx, models.User{ID: "u1", Email: "one@example.com"}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1", Title: "One", ContentHash: "abc"}))
+	require.NoError(t, repo.CreateUpload(ctx, models.Upload{ID: "up1", UserID: "u1", FileName: "one.mp3"}))
+	writes := repo.Writes()
+	assert.NotZero(t, writes)
+	_, err = repo.GetTrack(ctx, "u1", "t1")
+	require.NoError(t, err)
+	assert.Equal(t, writes, repo.Writes(), "reads are not counted")
+	require.NoError(t, repo.SaveSnapshot(path))
+
+	reopened, err := OpenMemoryRepository(path)
+	require.NoError(t, err)
+	track, err := reopened.GetTrack(ctx, "u1", "t1")
+	require.NoError(t, err)
+	assert.Equal(t, "One", track.Title)
+	assert.Equal(t, "abc", track.ContentHash, "fields left out of the JSON are kept")
+	user, err := reopened.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "one@example.com", user.Email)
+	_, err = reopened.GetUpload(ctx, "u1", "up1")
+	require.NoError(t, err)
+	require.NoError(t, reopened.CreateTrack(ctx, models.Track{ID: "t2", UserID: "u1"}), "stores are writable after a load")
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	_, err = ReadMemorySnapshot(strings.NewReader("not a snapshot"))
+	assert.Error(t, err)
+}
+
+func TestMemoryS3Repository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("objects, copies and prefix deletes", func(t *testing.T) {
+		store := NewMemoryS3Repository("")
+		store.PutObject("uploads/u1/a.mp3", map[string]string{"content-type": "audio/mpeg"})
+		store.PutObject("uploads/u1/b.mp3", nil)
+
+		require.NoError(t, store.CopyObject(ctx, "uploads/u1/a.mp3", "media/u1/a.mp3"))
+		metadata, err := store.GetObjectMetadata(ctx, "media/u1/a.mp3")
+		require.NoError(t, err)
+		assert.Equal(t, "audio/mpeg", metadata["content-type"])
+
+		require.NoError(t, store.DeleteByPrefix(ctx, "uploads/"))
+		exists, err := store.ObjectExists(ctx, "uploads/u1/b.mp3")
+		require.NoError(t, err)
+		assert.False(t, exists)
+		assert.Equal(t, []string{"media/u1/a.mp3"}, store.Keys(""))
+
+		_, err = store.GetObjectMetadata(ctx, "missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Error(t, store.DeleteByPrefix(ctx, ""))
+	})
+
+	t.Run("written objects keep their body through copies", func(t *testing.T) {
+		store := NewMemoryS3Repository("")
+		require.NoError(t, store.WriteObject(ctx, "covers/u1/a.jpg", []byte("jpeg"), "image/jpeg"))
+		require.NoError(t, store.CopyObject(ctx, "covers/u1/a.jpg", "trash/covers/u1/a.jpg"))
+		require.NoError(t, store.DeleteObject(ctx, "covers/u1/a.jpg"))
+
+		body, err := store.ReadObject(ctx, "trash/covers/u1/a.jpg")
+		require.NoError(t, err)
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", string(data))
+		metadata, err := store.GetObjectMetadata(ctx, "trash/covers/u1/a.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", metadata["content-type"])
+
+		_, err = store.ReadObject(ctx, "covers/u1/a.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("multipart upload creates the object on completion", func(t *testing.T) {
+		store := NewMemoryS3Repository("http://localhost:4566/media/")
+		uploadID, err := store.InitiateMultipartUpload(ctx, "uploads/big.flac", "audio/flac")
+		require.NoError(t, err)
+
+		urls, err := store.GenerateMultipartUploadURLs(ctx, "uploads/big.flac", uploadID, 3, time.Hour)
+		require.NoError(t, err)
+		require.Len(t, urls, 3)
+		assert.Contains(t, urls[2].UploadURL, "http://localhost:4566/media/uploads/big.flac?")
+		assert.Contains(t, urls[2].UploadURL, "partNumber=3")
+
+		require.NoError(t, store.CompleteMultipartUpload(ctx, "uploads/big.flac", uploadID, nil))
+		exists, err := store.ObjectExists(ctx, "uploads/big.flac")
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		assert.ErrorIs(t, store.AbortMultipartUpload(ctx, "uploads/big.flac", uploadID), ErrNotFound)
+	})
+}
+
+func trackIDs(tracks []models.Track) []string {
+	ids := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		ids = append(ids, track.ID)
+	}
+	return ids
+}
@@ -0,0 +1,899 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// ============================================================================
+// User Operations
+// ============================================================================
+
+func (r *MemoryRepository) CreateUser(ctx context.Context, user models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; ok {
+		return fmt.Errorf("failed to create user: %w", ErrAlreadyExists)
+	}
+
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *MemoryRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+// GetUserByEmail finds a user by email (case-insensitive)
+func (r *MemoryRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetUserByCognitoID finds a user by Cognito ID; user IDs are Cognito subs
+func (r *MemoryRepository) GetUserByCognitoID(ctx context.Context, cognitoID string) (*models.User, error) {
+	return r.GetUser(ctx, cognitoID)
+}
+
+func (r *MemoryRepository) UpdateUser(ctx context.Context, user models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return fmt.Errorf("failed to update user: %w", ErrNotFound)
+	}
+
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = user
+	return nil
+}
+
+// updateUser applies fn to a stored user under the write lock
+func (r *MemoryRepository) updateUser(userID string, fn func(user *models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	fn(&user)
+	user.UpdatedAt = time.Now()
+	r.users[userID] = user
+	return nil
+}
+
+func (r *MemoryRepository) UpdateUserStats(ctx context.Context, userID string, storageUsed int64, trackCount, albumCount, playlistCount int) error {
+	return r.updateUser(userID, func(user *models.User) {
+		user.StorageUsed = storageUsed
+		user.TrackCount = trackCount
+		user.AlbumCount = albumCount
+		user.PlaylistCount = playlistCount
+	})
+}
+
+// ScanUsers pages through every user
+func (r *MemoryRepository) ScanUsers(ctx context.Context, cursor string, limit int) (*PaginatedResult[models.User], error) {
+	r.mu.RLock()
+	users := make([]models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(users, func(user models.User) string {
+		return memoryKey(user.ID)
+	}, "USERS", limit, cursor, false)
+}
+
+// UpdateUserRole updates a user's role
+func (r *MemoryRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
+	return r.updateUser(userID, func(user *models.User) {
+		user.Role = role
+	})
+}
+
+// ListUsersByRole lists users with a specific role
+func (r *MemoryRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*PaginatedResult[models.User], error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	users := make([]models.User, 0)
+	for _, user := range r.users {
+		if user.Role == role {
+			users = append(users, user)
+		}
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(users, func(u models.User) string {
+		return u.ID
+	}, fmt.Sprintf("ROLE#%s", role), limit, cursor, false)
+}
+
+// SearchUsers searches for users by email or display name (partial match)
+func (r *MemoryRepository) SearchUsers(ctx context.Context, query string, limit int) ([]models.User, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]models.User, 0)
+	for _, user := range r.users {
+		if strings.Contains(user.Email, query) || strings.Contains(user.DisplayName, query) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+// SearchUsersByEmail searches users whose lowercased email starts with emailPrefix
+func (r *MemoryRepository) SearchUsersByEmail(ctx context.Context, emailPrefix string, limit int, cursor string) ([]UserSearchResult, string, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	prefix := strings.ToLower(emailPrefix)
+	r.mu.RLock()
+	users := make([]models.User, 0)
+	for _, user := range r.users {
+		if strings.HasPrefix(strings.ToLower(user.Email), prefix) {
+			users = append(users, user)
+		}
+	}
+	r.mu.RUnlock()
+
+	page, err := memoryPage(users, func(u models.User) string {
+		return memoryKey(strings.ToLower(u.Email), u.ID)
+	}, "EMAIL", limit, cursor, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	results := make([]UserSearchResult, 0, len(page.Items))
+	for _, user := range page.Items {
+		results = append(results, UserSearchResult{
+			ID:          user.ID,
+			Email:       user.Email,
+			DisplayName: user.DisplayName,
+			Role:        user.Role,
+			Disabled:    user.Disabled,
+			CreatedAt:   user.CreatedAt,
+		})
+	}
+	return results, page.NextCursor, nil
+}
+
+// SetUserDisabled sets the disabled status of a user
+func (r *MemoryRepository) SetUserDisabled(ctx context.Context, userID string, disabled bool) error {
+	return r.updateUser(userID, func(user *models.User) {
+		user.Disabled = disabled
+	})
+}
+
+// GetUserDisplayName returns a user's display name, falling back to email if not set
+func (r *MemoryRepository) GetUserDisplayName(ctx context.Context, userID string) (string, error) {
+	user, err := r.GetUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user.DisplayName != "" {
+		return user.DisplayName, nil
+	}
+	if user.Email != "" {
+		return user.Email, nil
+	}
+	return "Unknown", nil
+}
+
+// GetFollowerCount returns the number of followers for a user's artist profile
+func (r *MemoryRepository) GetFollowerCount(ctx context.Context, userID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// No artist profile means no followers
+	return r.profiles[userID].FollowerCount, nil
+}
+
+// ============================================================================
+// User Settings Operations
+// ============================================================================
+
+// GetUserSettings retrieves just the settings for a user
+func (r *MemoryRepository) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
+	user, err := r.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &user.Settings, nil
+}
+
+// UpdateUserSettings merges the non-nil sections of update into the user's settings
+func (r *MemoryRepository) UpdateUserSettings(ctx context.Context, userID string, update *UserSettingsUpdate) (*models.UserSettings, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if update.Notifications != nil {
+		user.Settings.Notifications = *update.Notifications
+	}
+	if update.Privacy != nil {
+		user.Settings.Privacy = *update.Privacy
+	}
+	if update.Player != nil {
+		user.Settings.Player = *update.Player
+	}
+	if update.Library != nil {
+		user.Settings.Library = *update.Library
+	}
+	if update.Language != nil {
+		user.Settings.Language = *update.Language
+	}
+	if update.TimeZone != nil {
+		user.Settings.TimeZone = *update.TimeZone
+	}
+
+	if err := user.Settings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid settings: %w", err)
+	}
+
+	user.UpdatedAt = time.Now()
+	r.users[userID] = user
+	settings := user.Settings
+	return &settings, nil
+}
+
+// ============================================================================
+// Playlist Operations
+// ============================================================================
+
+func (r *MemoryRepository) CreatePlaylist(ctx context.Context, playlist models.Playlist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(playlist.UserID, playlist.ID)
+	if _, ok := r.playlists[key]; ok {
+		return fmt.Errorf("failed to create playlist: %w", ErrAlreadyExists)
+	}
+
+	playlist.CreatedAt = time.Now()
+	playlist.UpdatedAt = playlist.CreatedAt
+	r.playlists[key] = playlist
+	return nil
+}
+
+func (r *MemoryRepository) GetPlaylist(ctx context.Context, userID, playlistID string) (*models.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	playlist, ok := r.playlists[memoryKey(userID, playlistID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &playlist, nil
+}
+
+func (r *MemoryRepository) UpdatePlaylist(ctx context.Context, playlist models.Playlist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(playlist.UserID, playlist.ID)
+	if _, ok := r.playlists[key]; !ok {
+		return fmt.Errorf("failed to update playlist: %w", ErrNotFound)
+	}
+
+	playlist.UpdatedAt = time.Now()
+	r.playlists[key] = playlist
+	return nil
+}
+
+// DeletePlaylist removes a playlist and its track entries
+func (r *MemoryRepository) DeletePlaylist(ctx context.Context, userID, playlistID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, track := range r.playlistTracks {
+		if track.PlaylistID == playlistID {
+			delete(r.playlistTracks, key)
+		}
+	}
+	delete(r.playlists, memoryKey(userID, playlistID))
+	return nil
+}
+
+func (r *MemoryRepository) ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*PaginatedResult[models.Playlist], error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	playlists := make([]models.Playlist, 0)
+	for _, playlist := range r.playlists {
+		if playlist.UserID == userID {
+			playlists = append(playlists, playlist)
+		}
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(playlists, func(p models.Playlist) string {
+		return p.ID
+	}, fmt.Sprintf("USER#%s", userID), limit, filter.LastKey, filter.SortOrder == "desc")
+}
+
+// SearchPlaylists returns a user's playlists whose name contains query (case-insensitive)
+func (r *MemoryRepository) SearchPlaylists(ctx context.Context, userID, query string, limit int) ([]models.Playlist, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	queryLower := strings.ToLower(query)
+	playlists := make([]models.Playlist, 0)
+	for _, playlist := range r.playlists {
+		if playlist.UserID == userID && strings.Contains(strings.ToLower(playlist.Name), queryLower) {
+			playlists = append(playlists, playlist)
+		}
+	}
+	sort.Slice(playlists, func(i, j int) bool { return playlists[i].ID < playlists[j].ID })
+	if len(playlists) > limit {
+		playlists = playlists[:limit]
+	}
+	return playlists, nil
+}
+
+// playlistTrackKey keys playlist entries by position like POSITION#%08d
+func playlistTrackKey(playlistID string, position int) string {
+	return memoryKey(playlistID, fmt.Sprintf("%08d", position))
+}
+
+// AddTracksToPlaylist stores trackIDs at consecutive positions starting at position
+func (r *MemoryRepository) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string, position int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for i, trackID := range trackIDs {
+		r.playlistTracks[playlistTrackKey(playlistID, position+i)] = models.PlaylistTrack{
+			PlaylistID: playlistID,
+			TrackID:    trackID,
+			Position:   position + i,
+			AddedAt:    now,
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) RemoveTracksFromPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remove := make(map[string]bool, len(trackIDs))
+	for _, id := range trackIDs {
+		remove[id] = true
+	}
+	for key, track := range r.playlistTracks {
+		if track.PlaylistID == playlistID && remove[track.TrackID] {
+			delete(r.playlistTracks, key)
+		}
+	}
+	return nil
+}
+
+// GetPlaylistTracks returns a playlist's entries ordered by position
+func (r *MemoryRepository) GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tracks := make([]models.PlaylistTrack, 0)
+	for _, track := range r.playlistTracks {
+		if track.PlaylistID == playlistID {
+			tracks = append(tracks, track)
+		}
+	}
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].Position < tracks[j].Position })
+	return tracks, nil
+}
+
+// ReorderPlaylistTracks replaces a playlist's entries, renumbering positions by slice index
+func (r *MemoryRepository) ReorderPlaylistTracks(ctx context.Context, playlistID string, tracks []models.PlaylistTrack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, track := range r.playlistTracks {
+		if track.PlaylistID == playlistID {
+			delete(r.playlistTracks, key)
+		}
+	}
+	for i, track := range tracks {
+		track.Position = i
+		track.PlaylistID = playlistID
+		r.playlistTracks[playlistTrackKey(playlistID, i)] = track
+	}
+	return nil
+}
+
+// UpdatePlaylistVisibility updates a playlist's visibility
+func (r *MemoryRepository) UpdatePlaylistVisibility(ctx context.Context, userID, playlistID string, visibility models.PlaylistVisibility) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(userID, playlistID)
+	playlist, ok := r.playlists[key]
+	if !ok {
+		return ErrNotFound
+	}
+
+	playlist.Visibility = visibility
+	playlist.UpdatedAt = time.Now()
+	r.playlists[key] = playlist
+	return nil
+}
+
+// ListPublicPlaylists lists discoverable playlists from all users
+func (r *MemoryRepository) ListPublicPlaylists(ctx context.Context, limit int, cursor string) (*PaginatedResult[models.Playlist], error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	playlists := make([]models.Playlist, 0)
+	for _, playlist := range r.playlists {
+		if playlist.Visibility.IsDiscoverable() {
+			playlists = append(playlists, playlist)
+		}
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(playlists, func(p models.Playlist) string {
+		return p.ID
+	}, "PUBLIC_PLAYLIST", limit, cursor, false)
+}
+
+// ============================================================================
+// Artist Profile Operations
+// ============================================================================
+
+func (r *MemoryRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.profiles[profile.UserID]; ok {
+		return ErrAlreadyExists
+	}
+
+	profile.CreatedAt = time.Now()
+	profile.UpdatedAt = profile.CreatedAt
+	r.profiles[profile.UserID] = profile
+	return nil
+}
+
+func (r *MemoryRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &profile, nil
+}
+
+func (r *MemoryRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.profiles[profile.UserID]; !ok {
+		return ErrNotFound
+	}
+
+	profile.UpdatedAt = time.Now()
+	r.profiles[profile.UserID] = profile
+	return nil
+}
+
+func (r *MemoryRepository) DeleteArtistProfile(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.profiles[userID]; !ok {
+		return ErrNotFound
+	}
+	delete(r.profiles, userID)
+	return nil
+}
+
+func (r *MemoryRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*PaginatedResult[models.ArtistProfile], error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	profiles := make([]models.ArtistProfile, 0, len(r.profiles))
+	for _, profile := range r.profiles {
+		profiles = append(profiles, profile)
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(profiles, func(p models.ArtistProfile) string {
+		return p.UserID
+	}, "ARTIST_PROFILE", limit, cursor, false)
+}
+
+// IncrementArtistFollowerCount adds delta to an artist profile's follower count
+func (r *MemoryRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	profile.FollowerCount += delta
+	profile.UpdatedAt = time.Now()
+	r.profiles[userID] = profile
+	return nil
+}
+
+// ============================================================================
+// Follow Operations
+// ============================================================================
+
+func (r *MemoryRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(follow.FollowerID, follow.FollowedID)
+	if _, ok := r.follows[key]; ok {
+		return ErrAlreadyExists
+	}
+
+	if follow.CreatedAt.IsZero() {
+		follow.CreatedAt = time.Now()
+	}
+	r.follows[key] = follow
+	return nil
+}
+
+func (r *MemoryRepository) DeleteFollow(ctx context.Context, followerID, followedID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(followerID, followedID)
+	if _, ok := r.follows[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.follows, key)
+	return nil
+}
+
+func (r *MemoryRepository) GetFollow(ctx context.Context, followerID, followedID string) (*models.Follow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	follow, ok := r.follows[memoryKey(followerID, followedID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &follow, nil
+}
+
+// ListFollowers lists the users following userID
+func (r *MemoryRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*PaginatedResult[models.Follow], error) {
+	return r.listFollows(limit, cursor, fmt.Sprintf("FOLLOWERS#%s", userID), func(f models.Follow) (bool, string) {
+		return f.FollowedID == userID, f.FollowerID
+	})
+}
+
+// ListFollowing lists the users userID follows
+func (r *MemoryRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*PaginatedResult[models.Follow], error) {
+	return r.listFollows(limit, cursor, fmt.Sprintf("FOLLOWING#%s", userID), func(f models.Follow) (bool, string) {
+		return f.FollowerID == userID, f.FollowedID
+	})
+}
+
+// listFollows pages the follows selected by match, ordered by the key it returns
+func (r *MemoryRepository) listFollows(limit int, cursor, pk string, match func(models.Follow) (bool, string)) (*PaginatedResult[models.Follow], error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	follows := make([]models.Follow, 0)
+	for _, follow := range r.follows {
+		if ok, _ := match(follow); ok {
+			follows = append(follows, follow)
+		}
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(follows, func(f models.Follow) string {
+		_, key := match(f)
+		return key
+	}, pk, limit, cursor, false)
+}
+
+// IncrementUserFollowingCount adds delta to a user's following count
+func (r *MemoryRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
+	return r.updateUser(userID, func(user *models.User) {
+		user.FollowingCount += delta
+	})
+}
+
+// ============================================================================
+// Track Share Operations
+// ============================================================================
+
+// CreateTrackShare stores a new share in the recipient's inbox
+func (r *MemoryRepository) CreateTrackShare(ctx context.Context, share models.TrackShare) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(share.RecipientID, share.ID)
+	if _, ok := r.shares[key]; ok {
+		return ErrAlreadyExists
+	}
+
+	share.CreatedAt = time.Now()
+	share.UpdatedAt = share.CreatedAt
+	r.shares[key] = share
+	return nil
+}
+
+// GetTrackShare retrieves a share from the recipient's inbox
+func (r *MemoryRepository) GetTrackShare(ctx context.Context, recipientID, shareID string) (*models.TrackShare, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	share, ok := r.shares[memoryKey(recipientID, shareID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &share, nil
+}
+
+// UpdateTrackShare overwrites an existing share
+func (r *MemoryRepository) UpdateTrackShare(ctx context.Context, share models.TrackShare) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(share.RecipientID, share.ID)
+	if _, ok := r.shares[key]; !ok {
+		return ErrNotFound
+	}
+
+	share.UpdatedAt = time.Now()
+	r.shares[key] = share
+	return nil
+}
+
+// ListReceivedTrackShares lists shares sent to a user, newest first
+func (r *MemoryRepository) ListReceivedTrackShares(ctx context.Context, recipientID string, filter models.TrackShareFilter) (*PaginatedResult[models.TrackShare], error) {
+	return r.listTrackShares(filter, fmt.Sprintf("SHARE_TO#%s", recipientID), func(s models.TrackShare) bool {
+		return s.RecipientID == recipientID
+	})
+}
+
+// ListSentTrackShares lists shares a user has sent, newest first
+func (r *MemoryRepository) ListSentTrackShares(ctx context.Context, ownerID string, filter models.TrackShareFilter) (*PaginatedResult[models.TrackShare], error) {
+	return r.listTrackShares(filter, fmt.Sprintf("SHARE_FROM#%s", ownerID), func(s models.TrackShare) bool {
+		return s.OwnerID == ownerID
+	})
+}
+
+func (r *MemoryRepository) listTrackShares(filter models.TrackShareFilter, pk string, match func(models.TrackShare) bool) (*PaginatedResult[models.TrackShare], error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	r.mu.RLock()
+	shares := make([]models.TrackShare, 0)
+	for _, share := range r.shares {
+		if !match(share) {
+			continue
+		}
+		if filter.Status != "" && share.Status != filter.Status {
+			continue
+		}
+		shares = append(shares, share)
+	}
+	r.mu.RUnlock()
+
+	return memoryPage(shares, func(s models.TrackShare) string {
+		return memoryKey(s.CreatedAt.UTC().Format(time.RFC3339Nano), s.ID)
+	}, pk, limit, filter.Cursor, true)
+}
+
+// ============================================================================
+// Household Operations
+// ============================================================================
+
+// CreateHousehold creates a household with its owner as the first member and
+// links the owner's profile. Fails with ErrAlreadyExists if the owner already
+// belongs to a household.
+func (r *MemoryRepository) CreateHousehold(ctx context.Context, household models.Household, owner models.HouseholdMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[owner.UserID]
+	if !ok || user.HouseholdID != "" {
+		return ErrAlreadyExists
+	}
+	if _, ok := r.households[household.ID]; ok {
+		return ErrAlreadyExists
+	}
+
+	r.households[household.ID] = household
+	r.members[memoryKey(household.ID, owner.UserID)] = owner
+	r.linkUser(user, household.ID)
+	return nil
+}
+
+// GetHousehold retrieves a household by ID
+func (r *MemoryRepository) GetHousehold(ctx context.Context, householdID string) (*models.Household, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	household, ok := r.households[householdID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &household, nil
+}
+
+// UpdateHousehold overwrites an existing household record
+func (r *MemoryRepository) UpdateHousehold(ctx context.Context, household models.Household) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.households[household.ID]; !ok {
+		return ErrNotFound
+	}
+	r.households[household.ID] = household
+	return nil
+}
+
+// DeleteHousehold removes a household, its member records and every member's household link
+func (r *MemoryRepository) DeleteHousehold(ctx context.Context, householdID string, memberIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.households, householdID)
+	for _, userID := range memberIDs {
+		delete(r.members, memoryKey(householdID, userID))
+		if user, ok := r.users[userID]; ok {
+			r.linkUser(user, "")
+		}
+	}
+	return nil
+}
+
+// AddHouseholdMember attaches a user to a household and increments the member
+// count. Fails with ErrAlreadyExists if the user already belongs to a household.
+func (r *MemoryRepository) AddHouseholdMember(ctx context.Context, member models.HouseholdMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(member.HouseholdID, member.UserID)
+	user, userOK := r.users[member.UserID]
+	household, householdOK := r.households[member.HouseholdID]
+	if _, exists := r.members[key]; exists || !userOK || !householdOK || user.HouseholdID != "" {
+		return ErrAlreadyExists
+	}
+
+	r.members[key] = member
+	r.linkUser(user, member.HouseholdID)
+	household.MemberCount++
+	r.households[member.HouseholdID] = household
+	return nil
+}
+
+// RemoveHouseholdMember detaches a user from a household and unlinks their profile
+func (r *MemoryRepository) RemoveHouseholdMember(ctx context.Context, householdID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(householdID, userID)
+	household, householdOK := r.households[householdID]
+	if _, ok := r.members[key]; !ok || !householdOK {
+		return ErrNotFound
+	}
+
+	delete(r.members, key)
+	if user, ok := r.users[userID]; ok {
+		r.linkUser(user, "")
+	}
+	household.MemberCount--
+	r.households[householdID] = household
+	return nil
+}
+
+// UpdateHouseholdMember overwrites an existing member record (e.g. a role change)
+func (r *MemoryRepository) UpdateHouseholdMember(ctx context.Context, member models.HouseholdMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memoryKey(member.HouseholdID, member.UserID)
+	if _, ok := r.members[key]; !ok {
+		return ErrNotFound
+	}
+	r.members[key] = member
+	return nil
+}
+
+// GetHouseholdMember retrieves a single member of a household
+func (r *MemoryRepository) GetHouseholdMember(ctx context.Context, householdID, userID string) (*models.HouseholdMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[memoryKey(householdID, userID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &member, nil
+}
+
+// ListHouseholdMembers lists all members of a household ordered by user ID
+func (r *MemoryRepository) ListHouseholdMembers(ctx context.Context, householdID string) ([]models.HouseholdMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]models.HouseholdMember, 0)
+	for _, member := range r.members {
+		if member.HouseholdID == householdID {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members, nil
+}
+
+// linkUser sets (or clears, when householdID is empty) a user's household link.
+// Callers must hold the write lock.
+func (r *MemoryRepository) linkUser(user models.User, householdID string) {
+	user.HouseholdID = householdID
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = user
+}
@@ -0,0 +1,392 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// GetMigrationState returns a data migration's checkpoint, or ErrNotFound if
+// it has never run
+func (r *DynamoDBRepository) GetMigrationState(ctx context.Context, version int) (*models.MigrationState, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.MigrationStatePK},
+			"SK": &types.AttributeValueMemberS{Value: models.GetMigrationStateSK(version)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration state: %w", err)
+	}
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.MigrationStateItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal migration state: %w", err)
+	}
+	return &item.MigrationState, nil
+}
+
+// PutMigrationState saves a data migration's checkpoint
+func (r *DynamoDBRepository) PutMigrationState(ctx context.Context, state models.MigrationState) error {
+	av, err := attributevalue.MarshalMap(models.NewMigrationStateItem(state))
+	if err != nil {
+		return fmt.Errorf("failed to marshal migration state: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save migration state: %w", err)
+	}
+	return nil
+}
+
+// ListMigrationStates returns the checkpoint of every migration that has run,
+// in version order
+func (r *DynamoDBRepository) ListMigrationStates(ctx context.Context) ([]models.MigrationState, error) {
+	var states []models.MigrationState
+	var lastKey map[string]types.AttributeValue
+	for {
+		result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :skPrefix)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":       &types.AttributeValueMemberS{Value: models.MigrationStatePK},
+				":skPrefix": &types.AttributeValueMemberS{Value: "MIGRATION#"},
+			},
+			ExclusiveStartKey: lastKey,
+			ConsistentRead:    aws.Bool(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list migration states: %w", err)
+		}
+
+		var items []models.MigrationStateItem
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal migration states: %w", err)
+		}
+		for _, item := range items {
+			states = append(states, item.MigrationState)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			return states, nil
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+}
+
+// ScanTrackItems reads one page of track items across every user, with their
+// index attributes. A page may hold fewer than limit tracks (the scan limit
+// applies before the filter); NextCursor is empty once the table is scanned.
+func (r *DynamoDBRepository) ScanTrackItems(ctx context.Context, cursor string, limit int) (*PaginatedResult[models.TrackItem], error) {
+	result, err := r.scanEntity(ctx, "TRACK#", cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan tracks: %w", err)
+	}
+
+	var items []models.TrackItem
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tracks: %w", err)
+	}
+	return scanPage(items, result.LastEvaluatedKey)
+}
+
+// ScanAlbums reads one page of albums across every user, paged like
+// ScanTrackItems
+func (r *DynamoDBRepository) ScanAlbums(ctx context.Context, cursor string, limit int) (*PaginatedResult[models.Album], error) {
+	result, err := r.scanEntity(ctx, "ALBUM#", cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan albums: %w", err)
+	}
+
+	var items []models.AlbumItem
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal albums: %w", err)
+	}
+	albums := make([]models.Album, 0, len(items))
+	for _, item := range items {
+		albums = append(albums, item.Album)
+	}
+	return scanPage(albums, result.LastEvaluatedKey)
+}
+
+// ScanUsers reads one page of user profiles across the table, paged like
+// ScanTrackItems
+func (r *DynamoDBRepository) ScanUsers(ctx context.Context, cursor string, limit int) (*PaginatedResult[models.User], error) {
+	result, err := r.scanEntity(ctx, "PROFILE", cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan users: %w", err)
+	}
+
+	var items []models.UserItem
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal users: %w", err)
+	}
+	users := make([]models.User, 0, len(items))
+	for _, item := range items {
+		users = append(users, item.User)
+	}
+	return scanPage(users, result.LastEvaluatedKey)
+}
+
+// scanEntity scans up to limit items of the table for those of one entity,
+// by sort key prefix
+func (r *DynamoDBRepository) scanEntity(ctx context.Context, skPrefix, cursor string, limit int) (*dynamodb.ScanOutput, error) {
+	startKey, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return r.client.Scan(ctx, &dynamodb.ScanInput{
+		TableName:        aws.String(r.tableName),
+		FilterExpression: aws.String("begins_with(SK, :skPrefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":skPrefix": &types.AttributeValueMemberS{Value: skPrefix},
+		},
+		ExclusiveStartKey: startKey,
+		Limit:             aws.Int32(int32(limit)),
+	})
+}
+
+// scanPage wraps scanned items with the cursor of the next page
+func scanPage[T any](items []T, lastKey map[string]types.AttributeValue) (*PaginatedResult[T], error) {
+	nextCursor, err := encodeCursor(lastKey)
+	if err != nil {
+		return nil, err
+	}
+	return &PaginatedResult[T]{
+		Items:      items,
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	}, nil
+}
+
+// PutTrackIndexes writes the index attributes (GSI1 to GSI4) of a track item,
+// removing those the item should not have, without touching the track itself.
+// The track must not have changed since item was built from it (its
+// updatedAt is compared); a track updated in the meantime, whose writer set
+// its indexes, fails with ErrConflict.
+func (r *DynamoDBRepository) PutTrackIndexes(ctx context.Context, item models.TrackItem) error {
+	updatedAt, err := attributevalue.Marshal(item.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal track timestamp: %w", err)
+	}
+
+	set := []string{}
+	remove := []string{}
+	values := map[string]types.AttributeValue{":updatedAt": updatedAt}
+	for _, attr := range []struct{ name, value string }{
+		{"GSI1PK", item.GSI1PK}, {"GSI1SK", item.GSI1SK},
+		{"GSI3PK", item.GSI3PK}, {"GSI3SK", item.GSI3SK},
+		{"GSI4PK", item.GSI4PK}, {"GSI4SK", item.GSI4SK},
+	} {
+		if attr.value == "" {
+			remove = append(remove, attr.name)
+			continue
+		}
+		set = append(set, fmt.Sprintf("%s = :%s", attr.name, attr.name))
+		values[":"+attr.name] = &types.AttributeValueMemberS{Value: attr.value}
+	}
+
+	update := ""
+	if len(set) > 0 {
+		update = "SET " + strings.Join(set, ", ")
+	}
+	if len(remove) > 0 {
+		update += " REMOVE " + strings.Join(remove, ", ")
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: item.PK},
+			"SK": &types.AttributeValueMemberS{Value: item.SK},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("updatedAt = :updatedAt"),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if isConditionalCheckFailed(err, &condErr) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to update track indexes: %w", err)
+	}
+	return nil
+}
+
+// ScanSortableItems reads one page of the sort key attributes of one entity's
+// items across every user, paged like ScanTrackItems
+func (r *DynamoDBRepository) ScanSortableItems(ctx context.Context, entity models.EntityType, cursor string, limit int) (*PaginatedResult[models.SortableItem], error) {
+	result, err := r.scanEntity(ctx, string(entity)+"#", cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s sort keys: %w", strings.ToLower(string(entity)), err)
+	}
+
+	var items []models.SortableItem
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sort keys: %w", err)
+	}
+	return scanPage(items, result.LastEvaluatedKey)
+}
+
+// PutSortKey writes the sort locale and GSI5 keys of an item without touching
+// the rest of it. Like PutTrackIndexes it fails with ErrConflict if the item
+// was updated since it was read; its writer rebuilt the key.
+func (r *DynamoDBRepository) PutSortKey(ctx context.Context, item models.SortableItem) error {
+	updatedAt, err := attributevalue.Marshal(item.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal timestamp: %w", err)
+	}
+
+	update := "SET GSI5PK = :gsi5pk, GSI5SK = :gsi5sk REMOVE sortLocale"
+	values := map[string]types.AttributeValue{
+		":updatedAt": updatedAt,
+		":gsi5pk":    &types.AttributeValueMemberS{Value: item.GSI5PK},
+		":gsi5sk":    &types.AttributeValueMemberS{Value: item.GSI5SK},
+	}
+	if item.SortLocale != "" {
+		update = "SET sortLocale = :sortLocale, GSI5PK = :gsi5pk, GSI5SK = :gsi5sk"
+		values[":sortLocale"] = &types.AttributeValueMemberS{Value: item.SortLocale}
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: item.PK},
+			"SK": &types.AttributeValueMemberS{Value: item.SK},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("updatedAt = :updatedAt"),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if isConditionalCheckFailed(err, &condErr) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to update sort key: %w", err)
+	}
+	return nil
+}
+
+// SetDefaultTrackVisibility sets a track's visibility only if it has none,
+// reporting whether it did. A visibility set in the meantime is kept.
+func (r *DynamoDBRepository) SetDefaultTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) (bool, error) {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("TRACK#%s", trackID)},
+		},
+		UpdateExpression:    aws.String("SET Visibility = :visibility"),
+		ConditionExpression: aws.String("attribute_exists(PK) AND (attribute_not_exists(Visibility) OR Visibility = :empty)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":visibility": &types.AttributeValueMemberS{Value: string(visibility)},
+			":empty":      &types.AttributeValueMemberS{Value: ""},
+		},
+	})
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if isConditionalCheckFailed(err, &condErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to set track visibility: %w", err)
+	}
+	return true, nil
+}
+
+// RekeyAlbum moves an album to a new ID: the album is copied to the new ID
+// (unless an album already has it), every track of the album is repointed,
+// and the old album is deleted last. Each step is safe to repeat, so an
+// interrupted move is finished by running it again.
+func (r *DynamoDBRepository) RekeyAlbum(ctx context.Context, userID, fromID, toID string) error {
+	album, err := r.GetAlbum(ctx, userID, fromID)
+	if err != nil {
+		return err
+	}
+
+	moved := *album
+	moved.ID = toID
+	moved.UpdatedAt = time.Now()
+	av, err := attributevalue.MarshalMap(models.NewAlbumItem(moved))
+	if err != nil {
+		return fmt.Errorf("failed to marshal album: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+	})
+	var condErr *types.ConditionalCheckFailedException
+	if err != nil && !isConditionalCheckFailed(err, &condErr) {
+		return fmt.Errorf("failed to copy album: %w", err)
+	}
+
+	var lastKey map[string]types.AttributeValue
+	for {
+		result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :skPrefix)"),
+			FilterExpression:       aws.String("albumId = :from"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":       &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+				":skPrefix": &types.AttributeValueMemberS{Value: "TRACK#"},
+				":from":     &types.AttributeValueMemberS{Value: fromID},
+			},
+			ProjectionExpression: aws.String("PK, SK"),
+			ExclusiveStartKey:    lastKey,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list album tracks: %w", err)
+		}
+
+		for _, key := range result.Items {
+			_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName:           aws.String(r.tableName),
+				Key:                 key,
+				UpdateExpression:    aws.String("SET albumId = :to"),
+				ConditionExpression: aws.String("albumId = :from"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":from": &types.AttributeValueMemberS{Value: fromID},
+					":to":   &types.AttributeValueMemberS{Value: toID},
+				},
+			})
+			// A track moved to another album in the meantime is left alone
+			if err != nil && !isConditionalCheckFailed(err, &condErr) {
+				return fmt.Errorf("failed to repoint track: %w", err)
+			}
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+
+	_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("ALBUM#%s", fromID)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete album: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// PutModerationReview creates or replaces the moderation review of a track
+func (r *DynamoDBRepository) PutModerationReview(ctx context.Context, review models.ModerationReview) error {
+	review.UpdatedAt = time.Now()
+	item := models.NewModerationReviewItem(review)
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal moderation review: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put moderation review: %w", err)
+	}
+
+	return nil
+}
+
+// GetModerationReview retrieves the moderation review of a track
+func (r *DynamoDBRepository) GetModerationReview(ctx context.Context, trackID string) (*models.ModerationReview, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.ModerationPK},
+			"SK": &types.AttributeValueMemberS{Value: models.GetModerationReviewSK(trackID)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation review: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.ModerationReviewItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal moderation review: %w", err)
+	}
+
+	return &item.ModerationReview, nil
+}
+
+// ListModerationReviews lists reviews in one status (via GSI1), oldest first
+// so the review queue is worked in order
+func (r *DynamoDBRepository) ListModerationReviews(ctx context.Context, filter models.ModerationFilter) (*PaginatedResult[models.ModerationReview], error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("GSI1"),
+		KeyConditionExpression: aws.String("GSI1PK = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: models.GetModerationStatusGSI1PK(filter.Status)},
+		},
+		Limit:            aws.Int32(int32(limit)),
+		ScanIndexForward: aws.Bool(true),
+	}
+
+	if filter.Cursor != "" {
+		startKey, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	result, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderation reviews: %w", err)
+	}
+
+	reviews := make([]models.ModerationReview, 0, len(result.Items))
+	for _, item := range result.Items {
+		var reviewItem models.ModerationReviewItem
+		if err := attributevalue.UnmarshalMap(item, &reviewItem); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal moderation review: %w", err)
+		}
+		reviews = append(reviews, reviewItem.ModerationReview)
+	}
+
+	var nextCursor string
+	if result.LastEvaluatedKey != nil {
+		nextCursor, err = encodeCursor(result.LastEvaluatedKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	return &PaginatedResult[models.ModerationReview]{
+		Items:      reviews,
+		NextCursor: nextCursor,
+		HasMore:    result.LastEvaluatedKey != nil,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// GetTrackNeighbors retrieves a track's cached similar and mixable tracks
+func (r *DynamoDBRepository) GetTrackNeighbors(ctx context.Context, userID, trackID string) (*models.TrackNeighbors, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &types.AttributeValueMemberS{Value: models.GetTrackNeighborsSK(trackID)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track neighbors: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.TrackNeighborsItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal track neighbors: %w", err)
+	}
+
+	return &item.TrackNeighbors, nil
+}
+
+// PutTrackNeighbors stores a track's neighbors, replacing any previous list
+func (r *DynamoDBRepository) PutTrackNeighbors(ctx context.Context, neighbors models.TrackNeighbors) error {
+	av, err := attributevalue.MarshalMap(models.NewTrackNeighborsItem(neighbors))
+	if err != nil {
+		return fmt.Errorf("failed to marshal track neighbors: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put track neighbors: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// UpdateTrackObjectKey points a track's key field from one S3 key to another.
+// When albumID is set the album's cover art key is moved in the same
+// transaction. Each item is only updated while it still references from, so
+// a track edited in the meantime fails with ErrConflict and nothing changes.
+func (r *DynamoDBRepository) UpdateTrackObjectKey(ctx context.Context, userID, trackID string, field models.TrackKeyField, from, to, albumID string) error {
+	items := []types.TransactWriteItem{
+		{Update: r.moveObjectKey(userID, fmt.Sprintf("TRACK#%s", trackID), string(field), from, to)},
+	}
+	if albumID != "" {
+		items = append(items, types.TransactWriteItem{
+			Update: r.moveObjectKey(userID, fmt.Sprintf("ALBUM#%s", albumID), string(models.TrackKeyFieldCoverArt), from, to),
+		})
+	}
+
+	_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		if isTransactionCanceled(err) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to update object key: %w", err)
+	}
+	return nil
+}
+
+// moveObjectKey sets attribute to "to" on an item of the user's partition
+// whose attribute is still "from"
+func (r *DynamoDBRepository) moveObjectKey(userID, sk, attribute, from, to string) *types.Update {
+	return &types.Update{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			"SK": &types.AttributeValueMemberS{Value: sk},
+		},
+		UpdateExpression:         aws.String("SET #key = :to"),
+		ConditionExpression:      aws.String("#key = :from"),
+		ExpressionAttributeNames: map[string]string{"#key": attribute},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":from": &types.AttributeValueMemberS{Value: from},
+			":to":   &types.AttributeValueMemberS{Value: to},
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// GetOperation retrieves the undo record of a user's bulk operation. Records
+// past their TTL may still be returned until DynamoDB removes them.
+func (r *DynamoDBRepository) GetOperation(ctx context.Context, userID, operationID string) (*models.Operation, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       operationKey(userID, operationID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operation: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.OperationItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal operation: %w", err)
+	}
+
+	return &item.Operation, nil
+}
+
+// PutOperation stores the undo record of a bulk operation
+func (r *DynamoDBRepository) PutOperation(ctx context.Context, op models.Operation) error {
+	av, err := attributevalue.MarshalMap(models.NewOperationItem(op))
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put operation: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOperation removes the undo record of a bulk operation
+func (r *DynamoDBRepository) DeleteOperation(ctx context.Context, userID, operationID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       operationKey(userID, operationID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete operation: %w", err)
+	}
+
+	return nil
+}
+
+func operationKey(userID, operationID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"SK": &types.AttributeValueMemberS{Value: models.GetOperationSK(operationID)},
+	}
+}
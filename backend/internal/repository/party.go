@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// PutParty creates a guest DJ party
+func (r *DynamoDBRepository) PutParty(ctx context.Context, party models.Party) error {
+	av, err := attributevalue.MarshalMap(models.NewPartyItem(party))
+	if err != nil {
+		return fmt.Errorf("failed to marshal party: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put party: %w", err)
+	}
+
+	return nil
+}
+
+// GetParty retrieves a party. Expired parties are returned until the TTL
+// sweep removes them.
+func (r *DynamoDBRepository) GetParty(ctx context.Context, partyID string) (*models.Party, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.GetPartyPK(partyID)},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get party: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.PartyItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal party: %w", err)
+	}
+
+	return &item.Party, nil
+}
+
+// DeleteParty ends a party. Its requests can no longer be reached and expire
+// with their TTL.
+func (r *DynamoDBRepository) DeleteParty(ctx context.Context, partyID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.GetPartyPK(partyID)},
+			"SK": &types.AttributeValueMemberS{Value: "METADATA"},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete party: %w", err)
+	}
+
+	return nil
+}
+
+// PutPartyRequest stores a guest's track request
+func (r *DynamoDBRepository) PutPartyRequest(ctx context.Context, req models.PartyRequest) error {
+	av, err := attributevalue.MarshalMap(models.NewPartyRequestItem(req))
+	if err != nil {
+		return fmt.Errorf("failed to marshal party request: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put party request: %w", err)
+	}
+
+	return nil
+}
+
+// GetPartyRequest retrieves a guest's track request
+func (r *DynamoDBRepository) GetPartyRequest(ctx context.Context, partyID, requestID string) (*models.PartyRequest, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       partyRequestKey(partyID, requestID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get party request: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.PartyRequestItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal party request: %w", err)
+	}
+
+	return &item.PartyRequest, nil
+}
+
+// ListPartyRequests retrieves every request made at a party
+func (r *DynamoDBRepository) ListPartyRequests(ctx context.Context, partyID string) ([]models.PartyRequest, error) {
+	var items []models.PartyRequestItem
+	if err := r.queryPrefix(ctx, models.GetPartyPK(partyID), models.PartyRequestSKPrefix, &items); err != nil {
+		return nil, fmt.Errorf("failed to list party requests: %w", err)
+	}
+
+	requests := make([]models.PartyRequest, 0, len(items))
+	for _, item := range items {
+		requests = append(requests, item.PartyRequest)
+	}
+	return requests, nil
+}
+
+// RespondToPartyRequest stores the host's answer to a request if it is still
+// pending, and returns ErrConflict if it was answered already
+func (r *DynamoDBRepository) RespondToPartyRequest(ctx context.Context, req models.PartyRequest) error {
+	av, err := attributevalue.MarshalMap(models.NewPartyRequestItem(req))
+	if err != nil {
+		return fmt.Errorf("failed to marshal party request: %w", err)
+	}
+
+	condition := expression.Name("status").Equal(expression.Value(models.PartyRequestPending))
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(r.tableName),
+		Item:                      av,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to update party request: %w", err)
+	}
+
+	return nil
+}
+
+func partyRequestKey(partyID, requestID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: models.GetPartyPK(partyID)},
+		"SK": &types.AttributeValueMemberS{Value: models.GetPartyRequestSK(requestID)},
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// GetPlayerState retrieves a user's play queue
+func (r *DynamoDBRepository) GetPlayerState(ctx context.Context, userID string) (*models.PlayerState, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            playerStateKey(userID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player state: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.PlayerStateItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal player state: %w", err)
+	}
+
+	return &item.PlayerState, nil
+}
+
+// PutPlayerState stores a user's play queue if the stored one is still at
+// version previous (0 for a user who has none). Otherwise nothing is written
+// and ErrConflict is returned.
+func (r *DynamoDBRepository) PutPlayerState(ctx context.Context, state models.PlayerState, previous int64) error {
+	av, err := attributevalue.MarshalMap(models.NewPlayerStateItem(state))
+	if err != nil {
+		return fmt.Errorf("failed to marshal player state: %w", err)
+	}
+
+	condition := expression.AttributeNotExists(expression.Name("PK"))
+	if previous > 0 {
+		condition = expression.Name("version").Equal(expression.Value(previous))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(r.tableName),
+		Item:                      av,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to put player state: %w", err)
+	}
+
+	return nil
+}
+
+func playerStateKey(userID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"SK": &types.AttributeValueMemberS{Value: models.PlayerStateSK},
+	}
+}
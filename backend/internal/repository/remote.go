@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// PutDeviceSession creates or refreshes a device's remote control session
+func (r *DynamoDBRepository) PutDeviceSession(ctx context.Context, session models.DeviceSession) error {
+	av, err := attributevalue.MarshalMap(models.NewDeviceSessionItem(session))
+	if err != nil {
+		return fmt.Errorf("failed to marshal device session: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put device session: %w", err)
+	}
+
+	return nil
+}
+
+// GetDeviceSession retrieves a device's session. Expired sessions are returned
+// until the TTL sweep removes them.
+func (r *DynamoDBRepository) GetDeviceSession(ctx context.Context, userID, deviceID string) (*models.DeviceSession, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       deviceSessionKey(userID, deviceID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device session: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.DeviceSessionItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal device session: %w", err)
+	}
+
+	return &item.DeviceSession, nil
+}
+
+// ListDeviceSessions retrieves every session of a user's devices, expired
+// ones included until the TTL sweep removes them
+func (r *DynamoDBRepository) ListDeviceSessions(ctx context.Context, userID string) ([]models.DeviceSession, error) {
+	var items []models.DeviceSessionItem
+	if err := r.queryPrefix(ctx, "USER#"+userID, models.DeviceSessionSKPrefix, &items); err != nil {
+		return nil, fmt.Errorf("failed to list device sessions: %w", err)
+	}
+
+	sessions := make([]models.DeviceSession, 0, len(items))
+	for _, item := range items {
+		sessions = append(sessions, item.DeviceSession)
+	}
+	return sessions, nil
+}
+
+// DeleteDeviceSession ends a device's session. Commands still waiting for the
+// device expire with their TTL.
+func (r *DynamoDBRepository) DeleteDeviceSession(ctx context.Context, userID, deviceID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       deviceSessionKey(userID, deviceID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete device session: %w", err)
+	}
+
+	return nil
+}
+
+// PutRemoteCommand queues a command for a device
+func (r *DynamoDBRepository) PutRemoteCommand(ctx context.Context, cmd models.RemoteCommand) error {
+	av, err := attributevalue.MarshalMap(models.NewRemoteCommandItem(cmd))
+	if err != nil {
+		return fmt.Errorf("failed to marshal remote command: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put remote command: %w", err)
+	}
+
+	return nil
+}
+
+// TakeRemoteCommands removes and returns the commands waiting for a device,
+// oldest first. Each command is deleted conditionally, so when two listeners
+// for the same device race only one of them receives it.
+func (r *DynamoDBRepository) TakeRemoteCommands(ctx context.Context, userID, deviceID string) ([]models.RemoteCommand, error) {
+	var items []models.RemoteCommandItem
+	if err := r.queryPrefix(ctx, "USER#"+userID, models.GetRemoteCommandSKPrefix(deviceID), &items); err != nil {
+		return nil, fmt.Errorf("failed to list remote commands: %w", err)
+	}
+
+	commands := make([]models.RemoteCommand, 0, len(items))
+	for _, item := range items {
+		_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(r.tableName),
+			Key: map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: item.PK},
+				"SK": &types.AttributeValueMemberS{Value: item.SK},
+			},
+			ConditionExpression: aws.String("attribute_exists(PK)"),
+		})
+		if err != nil {
+			var conditionFailed *types.ConditionalCheckFailedException
+			if errors.As(err, &conditionFailed) {
+				continue
+			}
+			return commands, fmt.Errorf("failed to delete remote command: %w", err)
+		}
+		commands = append(commands, item.RemoteCommand)
+	}
+
+	return commands, nil
+}
+
+// queryPrefix reads every item in partition pk whose sort key starts with
+// prefix into out, a pointer to a slice of items
+func (r *DynamoDBRepository) queryPrefix(ctx context.Context, pk, prefix string, out any) error {
+	var all []map[string]types.AttributeValue
+	var lastKey map[string]types.AttributeValue
+
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :skPrefix)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":       &types.AttributeValueMemberS{Value: pk},
+				":skPrefix": &types.AttributeValueMemberS{Value: prefix},
+			},
+			ConsistentRead: aws.Bool(true),
+		}
+
+		if lastKey != nil {
+			input.ExclusiveStartKey = lastKey
+		}
+
+		result, err := r.client.Query(ctx, input)
+		if err != nil {
+			return err
+		}
+		all = append(all, result.Items...)
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+
+	return attributevalue.UnmarshalListOfMaps(all, out)
+}
+
+func deviceSessionKey(userID, deviceID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"SK": &types.AttributeValueMemberS{Value: models.GetDeviceSessionSK(deviceID)},
+	}
+}
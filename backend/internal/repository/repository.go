@@ -17,6 +17,7 @@ var (
 	ErrUserNotFound  = errors.New("user not found")
 	ErrTrackNotFound = errors.New("track not found")
 	ErrPlaylistNotFound = errors.New("playlist not found")
+	ErrConflict      = errors.New("item was modified concurrently")
 )
 
 // UserSearchResult represents a user in search results
@@ -35,6 +36,8 @@ type UserSettingsUpdate struct {
 	Privacy       *models.PrivacySettings      `json:"privacy,omitempty"`
 	Player        *models.PlayerSettings       `json:"player,omitempty"`
 	Library       *models.LibrarySettings      `json:"library,omitempty"`
+	Language      *string                      `json:"language,omitempty"`
+	TimeZone      *string                      `json:"timeZone,omitempty"`
 }
 
 // PaginatedResult represents a paginated query result
@@ -147,8 +150,10 @@ type Repository interface {
 	ListUploadsByStatus(ctx context.Context, status models.UploadStatus) ([]models.Upload, error)
 }
 
-// S3Repository defines media storage operations
-type S3Repository interface {
+// MediaStore defines media storage operations. S3RepositoryImpl keeps media
+// in an S3 bucket (AWS, or MinIO through a custom endpoint), FileMediaStore in
+// a local directory and MemoryS3Repository in memory for tests.
+type MediaStore interface {
 	// Presigned URL operations
 	GeneratePresignedUploadURL(ctx context.Context, key, contentType string, expiry time.Duration) (string, error)
 	GeneratePresignedDownloadURL(ctx context.Context, key string, expiry time.Duration) (string, error)
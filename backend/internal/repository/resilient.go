@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
+)
+
+// guard runs fn through dependency's retries and circuit breaker
+func guard[T any](ctx context.Context, dependency *resilience.Dependency, fn func(context.Context) (T, error)) (T, error) {
+	var out T
+	err := dependency.Do(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = fn(ctx)
+		return err
+	})
+	return out, err
+}
+
+// ResilientDynamoDBClient decorates a DynamoDBClient with a resilience
+// policy: retries with backoff and a circuit breaker. The SDK client's own
+// retries should be turned off so attempts are not multiplied.
+type ResilientDynamoDBClient struct {
+	inner      DynamoDBClient
+	dependency *resilience.Dependency
+}
+
+// NewResilientDynamoDBClient wraps client so every call goes through dependency
+func NewResilientDynamoDBClient(client DynamoDBClient, dependency *resilience.Dependency) *ResilientDynamoDBClient {
+	return &ResilientDynamoDBClient{inner: client, dependency: dependency}
+}
+
+func (c *ResilientDynamoDBClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return c.inner.PutItem(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientDynamoDBClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return c.inner.GetItem(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientDynamoDBClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return c.inner.UpdateItem(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientDynamoDBClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*dynamodb.DeleteItemOutput, error) {
+		return c.inner.DeleteItem(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientDynamoDBClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*dynamodb.QueryOutput, error) {
+		return c.inner.Query(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientDynamoDBClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*dynamodb.ScanOutput, error) {
+		return c.inner.Scan(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientDynamoDBClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*dynamodb.BatchWriteItemOutput, error) {
+		return c.inner.BatchWriteItem(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientDynamoDBClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*dynamodb.BatchGetItemOutput, error) {
+		return c.inner.BatchGetItem(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientDynamoDBClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*dynamodb.TransactWriteItemsOutput, error) {
+		return c.inner.TransactWriteItems(ctx, params, optFns...)
+	})
+}
+
+// ScanLimitedDynamoDBClient bounds how many table scans run at once, so
+// global listings, counts and migrations cannot use up the table's read
+// capacity. A slot is held per scan page; other calls pass straight through.
+type ScanLimitedDynamoDBClient struct {
+	DynamoDBClient
+	scans *resilience.Limiter
+}
+
+// NewScanLimitedDynamoDBClient wraps client so every Scan takes a slot of
+// scans (nil admits every scan)
+func NewScanLimitedDynamoDBClient(client DynamoDBClient, scans *resilience.Limiter) *ScanLimitedDynamoDBClient {
+	return &ScanLimitedDynamoDBClient{DynamoDBClient: client, scans: scans}
+}
+
+func (c *ScanLimitedDynamoDBClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	release, err := c.scans.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.DynamoDBClient.Scan(ctx, params, optFns...)
+}
+
+// ResilientS3Client decorates an S3Client with a resilience policy like
+// ResilientDynamoDBClient
+type ResilientS3Client struct {
+	inner      S3Client
+	dependency *resilience.Dependency
+}
+
+// NewResilientS3Client wraps client so every call goes through dependency
+func NewResilientS3Client(client S3Client, dependency *resilience.Dependency) *ResilientS3Client {
+	return &ResilientS3Client{inner: client, dependency: dependency}
+}
+
+// PutObject is retried only when the body can be rewound; a consumed stream
+// cannot be sent again, so other uploads get a single attempt
+func (c *ResilientS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, seekable := params.Body.(io.Seeker)
+	if params.Body != nil && !seekable {
+		var out *s3.PutObjectOutput
+		err := c.dependency.Once(ctx, func(ctx context.Context) error {
+			var err error
+			out, err = c.inner.PutObject(ctx, params, optFns...)
+			return err
+		})
+		return out, err
+	}
+
+	var start int64
+	if seekable {
+		offset, err := body.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		start = offset
+	}
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.PutObjectOutput, error) {
+		if seekable {
+			if _, err := body.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		return c.inner.PutObject(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.GetObjectOutput, error) {
+		return c.inner.GetObject(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.DeleteObjectOutput, error) {
+		return c.inner.DeleteObject(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.DeleteObjectsOutput, error) {
+		return c.inner.DeleteObjects(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.ListObjectsV2Output, error) {
+		return c.inner.ListObjectsV2(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.ListObjectVersionsOutput, error) {
+		return c.inner.ListObjectVersions(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.CopyObjectOutput, error) {
+		return c.inner.CopyObject(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.HeadObjectOutput, error) {
+		return c.inner.HeadObject(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.CreateMultipartUploadOutput, error) {
+		return c.inner.CreateMultipartUpload(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.CompleteMultipartUploadOutput, error) {
+		return c.inner.CompleteMultipartUpload(ctx, params, optFns...)
+	})
+}
+
+func (c *ResilientS3Client) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return guard(ctx, c.dependency, func(ctx context.Context) (*s3.AbortMultipartUploadOutput, error) {
+		return c.inner.AbortMultipartUpload(ctx, params, optFns...)
+	})
+}
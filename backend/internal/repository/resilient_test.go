@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
+)
+
+var errFlaky = errors.New("flaky")
+
+// flakyDynamoDBClient fails the first GetItem calls
+type flakyDynamoDBClient struct {
+	DynamoDBClient
+	failures int
+	calls    int
+}
+
+func (c *flakyDynamoDBClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return nil, errFlaky
+	}
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+// flakyS3Client fails every PutObject after reading its body
+type flakyS3Client struct {
+	S3Client
+	bodies []string
+}
+
+func (c *flakyS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, _ := io.ReadAll(params.Body)
+	c.bodies = append(c.bodies, string(body))
+	return nil, errFlaky
+}
+
+func retryFlaky() *resilience.Dependency {
+	return resilience.NewDependency("test", resilience.Policy{
+		MaxAttempts: 3,
+		Retryable:   func(err error) bool { return errors.Is(err, errFlaky) },
+	})
+}
+
+func TestResilientDynamoDBClient_Retries(t *testing.T) {
+	inner := &flakyDynamoDBClient{failures: 2}
+	client := NewResilientDynamoDBClient(inner, retryFlaky())
+
+	out, err := client.GetItem(context.Background(), &dynamodb.GetItemInput{})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, out)
+	assert.Equal(t, 3, inner.calls)
+}
+
+// blockingScanClient holds every Scan until release is closed
+type blockingScanClient struct {
+	DynamoDBClient
+	started chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingScanClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	c.started <- struct{}{}
+	<-c.release
+	return &dynamodb.ScanOutput{}, nil
+}
+
+func TestScanLimitedDynamoDBClient(t *testing.T) {
+	ctx := context.Background()
+	inner := &blockingScanClient{started: make(chan struct{}, 1), release: make(chan struct{})}
+	client := NewScanLimitedDynamoDBClient(inner, resilience.NewLimiter(resilience.LimitScan, 1, 0))
+
+	done := make(chan error)
+	go func() {
+		_, err := client.Scan(ctx, &dynamodb.ScanInput{})
+		done <- err
+	}()
+	<-inner.started
+
+	_, err := client.Scan(ctx, &dynamodb.ScanInput{})
+	assert.ErrorIs(t, err, resilience.ErrConcurrencyLimit)
+
+	close(inner.release)
+	assert.NoError(t, <-done)
+	_, err = client.Scan(ctx, &dynamodb.ScanInput{})
+	assert.NoError(t, err)
+}
+
+func TestResilientS3Client_PutObject(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rewinds seekable bodies between attempts", func(t *testing.T) {
+		inner := &flakyS3Client{}
+		client := NewResilientS3Client(inner, retryFlaky())
+
+		_, err := client.PutObject(ctx, &s3.PutObjectInput{Body: strings.NewReader("audio")})
+
+		assert.ErrorIs(t, err, errFlaky)
+		assert.Equal(t, []string{"audio", "audio", "audio"}, inner.bodies)
+	})
+
+	t.Run("sends streams once", func(t *testing.T) {
+		inner := &flakyS3Client{}
+		client := NewResilientS3Client(inner, retryFlaky())
+
+		_, err := client.PutObject(ctx, &s3.PutObjectInput{Body: io.NopCloser(strings.NewReader("audio"))})
+
+		assert.ErrorIs(t, err, errFlaky)
+		assert.Equal(t, []string{"audio"}, inner.bodies)
+	})
+}
@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -11,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
 )
 
 // S3Client interface for testability
@@ -20,6 +24,7 @@ type S3Client interface {
 	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
 	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
 	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error)
 	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
 	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
 	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
@@ -34,7 +39,8 @@ type S3PresignClient interface {
 	PresignUploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
 }
 
-// S3RepositoryImpl implements S3Repository
+// S3RepositoryImpl implements MediaStore over an S3 bucket (AWS, LocalStack or
+// MinIO)
 type S3RepositoryImpl struct {
 	client        S3Client
 	presignClient S3PresignClient
@@ -86,7 +92,7 @@ func (r *S3RepositoryImpl) GeneratePresignedDownloadURL(ctx context.Context, key
 // GeneratePresignedDownloadURLWithFilename generates a presigned URL with Content-Disposition header
 // to force the browser to download the file with the specified filename
 func (r *S3RepositoryImpl) GeneratePresignedDownloadURLWithFilename(ctx context.Context, key string, expiry time.Duration, filename string) (string, error) {
-	contentDisposition := fmt.Sprintf("attachment; filename=\"%s\"", filename)
+	contentDisposition := sanitize.ContentDisposition(filename)
 	request, err := r.presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
 		Bucket:                     aws.String(r.bucketName),
 		Key:                        aws.String(key),
@@ -226,8 +232,9 @@ func (r *S3RepositoryImpl) DeleteByPrefix(ctx context.Context, prefix string) er
 			})
 		}
 
-		// Delete the batch of objects
-		_, err = r.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		// Delete the batch of objects; a listing page holds at most 1000 keys,
+		// which is also the DeleteObjects limit
+		deleteResult, err := r.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
 			Bucket: aws.String(r.bucketName),
 			Delete: &types.Delete{
 				Objects: objectsToDelete,
@@ -237,9 +244,15 @@ func (r *S3RepositoryImpl) DeleteByPrefix(ctx context.Context, prefix string) er
 		if err != nil {
 			return fmt.Errorf("failed to delete objects with prefix %s: %w", prefix, err)
 		}
+		// Quiet mode only reports the keys that could not be deleted
+		if len(deleteResult.Errors) > 0 {
+			failed := deleteResult.Errors[0]
+			return fmt.Errorf("failed to delete %d objects with prefix %s: %s: %s",
+				len(deleteResult.Errors), prefix, aws.ToString(failed.Key), aws.ToString(failed.Message))
+		}
 
 		// Check if there are more objects
-		if !*listResult.IsTruncated {
+		if !aws.ToBool(listResult.IsTruncated) {
 			break
 		}
 		continuationToken = listResult.NextContinuationToken
@@ -248,6 +261,60 @@ func (r *S3RepositoryImpl) DeleteByPrefix(ctx context.Context, prefix string) er
 	return nil
 }
 
+// ListObjectVersions returns the versions and delete markers of one key,
+// newest first. The media bucket is versioned, so an overwritten or deleted
+// object keeps its history here.
+func (r *S3RepositoryImpl) ListObjectVersions(ctx context.Context, key string) ([]models.S3ObjectVersion, error) {
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(r.bucketName),
+		Prefix: aws.String(key),
+	}
+
+	var versions []models.S3ObjectVersion
+	for {
+		result, err := r.client.ListObjectVersions(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of %s: %w", key, err)
+		}
+
+		// The prefix also matches longer keys, e.g. cover art sizes
+		for _, v := range result.Versions {
+			if aws.ToString(v.Key) == key {
+				versions = append(versions, models.S3ObjectVersion{
+					Key:          key,
+					VersionID:    aws.ToString(v.VersionId),
+					IsLatest:     aws.ToBool(v.IsLatest),
+					Size:         aws.ToInt64(v.Size),
+					ETag:         aws.ToString(v.ETag),
+					LastModified: aws.ToTime(v.LastModified),
+				})
+			}
+		}
+		for _, m := range result.DeleteMarkers {
+			if aws.ToString(m.Key) == key {
+				versions = append(versions, models.S3ObjectVersion{
+					Key:          key,
+					VersionID:    aws.ToString(m.VersionId),
+					IsLatest:     aws.ToBool(m.IsLatest),
+					DeleteMarker: true,
+					LastModified: aws.ToTime(m.LastModified),
+				})
+			}
+		}
+
+		if !aws.ToBool(result.IsTruncated) {
+			break
+		}
+		input.KeyMarker = result.NextKeyMarker
+		input.VersionIdMarker = result.NextVersionIdMarker
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].LastModified.After(versions[j].LastModified)
+	})
+	return versions, nil
+}
+
 // CopyObject copies an object within S3
 func (r *S3RepositoryImpl) CopyObject(ctx context.Context, sourceKey, destKey string) error {
 	_, err := r.client.CopyObject(ctx, &s3.CopyObjectInput{
@@ -263,6 +330,36 @@ func (r *S3RepositoryImpl) CopyObject(ctx context.Context, sourceKey, destKey st
 	return nil
 }
 
+// ReadObject opens an object for reading; the caller closes it
+func (r *S3RepositoryImpl) ReadObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	result, err := r.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, fmt.Errorf("failed to read object: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	return result.Body, nil
+}
+
+// WriteObject stores data as an object, replacing any already at key
+func (r *S3RepositoryImpl) WriteObject(ctx context.Context, key string, data []byte, contentType string) error {
+	_, err := r.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:       aws.String(r.bucketName),
+		Key:          aws.String(key),
+		Body:         bytes.NewReader(data),
+		ContentType:  aws.String(contentType),
+		StorageClass: types.StorageClassIntelligentTiering,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	return nil
+}
+
 // GetObjectMetadata retrieves metadata for an S3 object
 func (r *S3RepositoryImpl) GetObjectMetadata(ctx context.Context, key string) (map[string]string, error) {
 	result, err := r.client.HeadObject(ctx, &s3.HeadObjectInput{
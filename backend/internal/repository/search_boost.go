@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// GetSearchBoosts retrieves a user's pinned results and artist boosts
+func (r *DynamoDBRepository) GetSearchBoosts(ctx context.Context, userID string) (*models.SearchBoosts, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: "USER#" + userID},
+			"SK": &types.AttributeValueMemberS{Value: models.SearchBoostsSK},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search boosts: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.SearchBoostsItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal search boosts: %w", err)
+	}
+
+	return &item.SearchBoosts, nil
+}
+
+// PutSearchBoosts creates or replaces a user's pinned results and artist boosts
+func (r *DynamoDBRepository) PutSearchBoosts(ctx context.Context, boosts models.SearchBoosts) error {
+	av, err := attributevalue.MarshalMap(models.NewSearchBoostsItem(boosts))
+	if err != nil {
+		return fmt.Errorf("failed to marshal search boosts: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put search boosts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// GetSearchHistory retrieves a user's recent searches
+func (r *DynamoDBRepository) GetSearchHistory(ctx context.Context, userID string) (*models.SearchHistory, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       searchHistoryKey(userID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search history: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.SearchHistoryItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal search history: %w", err)
+	}
+
+	return &item.SearchHistory, nil
+}
+
+// PutSearchHistory creates or replaces a user's recent searches
+func (r *DynamoDBRepository) PutSearchHistory(ctx context.Context, history models.SearchHistory) error {
+	av, err := attributevalue.MarshalMap(models.NewSearchHistoryItem(history))
+	if err != nil {
+		return fmt.Errorf("failed to marshal search history: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put search history: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSearchHistory clears a user's recent searches
+func (r *DynamoDBRepository) DeleteSearchHistory(ctx context.Context, userID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       searchHistoryKey(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete search history: %w", err)
+	}
+
+	return nil
+}
+
+func searchHistoryKey(userID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"SK": &types.AttributeValueMemberS{Value: models.SearchHistorySK},
+	}
+}
@@ -0,0 +1,79 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration_SeededLibrary exercises the GSI-backed queries against the
+// sample library rather than single hand-made items.
+func TestIntegration_SeededLibrary(t *testing.T) {
+	tc, cleanup := testutil.SetupLocalStack(t)
+	defer cleanup()
+
+	repo := repository.NewDynamoDBRepository(tc.DynamoDB, tc.TableName)
+	ctx := context.Background()
+	lib := tc.SeedLibrary(t, "seeded-repo-user")
+
+	t.Run("list pages through every track", func(t *testing.T) {
+		seen := make(map[string]bool)
+		cursor := ""
+		for {
+			page, err := repo.ListTracks(ctx, lib.User.ID, models.TrackFilter{Limit: 4, LastKey: cursor})
+			require.NoError(t, err)
+			for _, track := range page.Items {
+				seen[track.ID] = true
+			}
+			if !page.HasMore {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		assert.Len(t, seen, len(lib.Tracks))
+	})
+
+	t.Run("tracks by artist use GSI1", func(t *testing.T) {
+		tracks, err := repo.ListTracksByArtist(ctx, lib.User.ID, "Lowtide")
+		require.NoError(t, err)
+		titles := make([]string, 0, len(tracks))
+		for _, track := range tracks {
+			titles = append(titles, track.Title)
+		}
+		assert.ElementsMatch(t, []string{"Deep Water", "Harbor Lights"}, titles)
+	})
+
+	t.Run("tracks by tag", func(t *testing.T) {
+		tracks, err := repo.GetTracksByTag(ctx, lib.User.ID, "night")
+		require.NoError(t, err)
+		assert.Len(t, tracks, 3)
+
+		tags, err := repo.GetTrackTags(ctx, lib.User.ID, lib.TrackByTitle(t, "Deep Water").ID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"night", "dj-set"}, tags)
+	})
+
+	t.Run("playlist keeps its order", func(t *testing.T) {
+		entries, err := repo.GetPlaylistTracks(ctx, lib.Playlist.ID)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.Equal(t, lib.TrackByTitle(t, "Midnight Drive").ID, entries[0].TrackID)
+		assert.Equal(t, lib.TrackByTitle(t, "Slow Burn").ID, entries[2].TrackID)
+	})
+
+	t.Run("audio objects exist in S3", func(t *testing.T) {
+		s3Repo := repository.NewS3Repository(tc.S3, nil, tc.BucketName)
+		for _, track := range lib.Tracks {
+			exists, err := s3Repo.ObjectExists(ctx, track.S3Key)
+			require.NoError(t, err)
+			assert.True(t, exists, track.S3Key)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// CreateTrackShare creates a pending track share in the recipient's partition
+func (r *DynamoDBRepository) CreateTrackShare(ctx context.Context, share models.TrackShare) error {
+	item := models.NewTrackShareItem(share)
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal track share: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+	})
+
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if ok := isConditionalCheckFailed(err, &condErr); ok {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to create track share: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrackShare retrieves a share addressed to the given recipient
+func (r *DynamoDBRepository) GetTrackShare(ctx context.Context, recipientID, shareID string) (*models.TrackShare, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", recipientID)},
+			"SK": &types.AttributeValueMemberS{Value: models.GetTrackShareSK(shareID)},
+		},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track share: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.TrackShareItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal track share: %w", err)
+	}
+
+	return &item.TrackShare, nil
+}
+
+// UpdateTrackShare overwrites an existing track share
+func (r *DynamoDBRepository) UpdateTrackShare(ctx context.Context, share models.TrackShare) error {
+	share.UpdatedAt = time.Now()
+	item := models.NewTrackShareItem(share)
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal track share: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+	})
+
+	if err != nil {
+		var condErr *types.ConditionalCheckFailedException
+		if ok := isConditionalCheckFailed(err, &condErr); ok {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update track share: %w", err)
+	}
+
+	return nil
+}
+
+// ListReceivedTrackShares lists shares addressed to a user, optionally filtered by status
+func (r *DynamoDBRepository) ListReceivedTrackShares(ctx context.Context, recipientID string, filter models.TrackShareFilter) (*PaginatedResult[models.TrackShare], error) {
+	keyCondition := expression.Key("PK").Equal(expression.Value(fmt.Sprintf("USER#%s", recipientID))).
+		And(expression.Key("SK").BeginsWith("SHARE#"))
+
+	return r.queryTrackShares(ctx, "", keyCondition, filter)
+}
+
+// ListSentTrackShares lists shares a user has sent to others (via GSI1), newest first
+func (r *DynamoDBRepository) ListSentTrackShares(ctx context.Context, ownerID string, filter models.TrackShareFilter) (*PaginatedResult[models.TrackShare], error) {
+	keyCondition := expression.Key("GSI1PK").Equal(expression.Value(models.GetSharesSentGSI1PK(ownerID)))
+
+	return r.queryTrackShares(ctx, "GSI1", keyCondition, filter)
+}
+
+func (r *DynamoDBRepository) queryTrackShares(ctx context.Context, indexName string, keyCondition expression.KeyConditionBuilder, filter models.TrackShareFilter) (*PaginatedResult[models.TrackShare], error) {
+	builder := expression.NewBuilder().WithKeyCondition(keyCondition)
+	if filter.Status != "" {
+		builder = builder.WithFilter(expression.Name("status").Equal(expression.Value(string(filter.Status))))
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     aws.Int32(int32(limit)),
+		ScanIndexForward:          aws.Bool(false),
+	}
+	if indexName != "" {
+		input.IndexName = aws.String(indexName)
+	}
+
+	if filter.Cursor != "" {
+		startKey, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	result, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list track shares: %w", err)
+	}
+
+	shares := make([]models.TrackShare, 0, len(result.Items))
+	for _, item := range result.Items {
+		var shareItem models.TrackShareItem
+		if err := attributevalue.UnmarshalMap(item, &shareItem); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal track share: %w", err)
+		}
+		shares = append(shares, shareItem.TrackShare)
+	}
+
+	var nextCursor string
+	if result.LastEvaluatedKey != nil {
+		nextCursor, err = encodeCursor(result.LastEvaluatedKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	return &PaginatedResult[models.TrackShare]{
+		Items:      shares,
+		NextCursor: nextCursor,
+		HasMore:    result.LastEvaluatedKey != nil,
+	}, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// ============================================================================
+// Alphabetical Browse (GSI5)
+// ============================================================================
+
+// sortableProjection reads only the attributes of a SortableItem
+const sortableProjection = "PK, SK, #type, id, userId, title, #name, sortName, sortLocale, GSI5PK, GSI5SK, updatedAt"
+
+var sortableProjectionNames = map[string]string{"#type": "Type", "#name": "name"}
+
+// sortableEntities are the entities kept in collation order on GSI5
+var sortableEntities = []models.EntityType{models.EntityTrack, models.EntityAlbum, models.EntityArtist}
+
+// listSorted returns one page of a user's items of one entity in collation
+// order, read from GSI5. Items written before the index existed are missing
+// until the sort key migrations have run.
+func listSorted[I, T any](ctx context.Context, r *DynamoDBRepository, userID string, entity models.EntityType, limit int, lastKey string, desc bool, filter *expression.ConditionBuilder, value func(I) T) (*PaginatedResult[T], error) {
+	keyCondition := expression.Key("GSI5PK").Equal(expression.Value(models.GetSortGSI5PK(userID, entity)))
+
+	builder := expression.NewBuilder().WithKeyCondition(keyCondition)
+	if filter != nil {
+		builder = builder.WithFilter(*filter)
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression: %w", err)
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		IndexName:                 aws.String("GSI5"),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     aws.Int32(int32(limit + 1)), // Get one extra to check hasMore
+		ScanIndexForward:          aws.Bool(!desc),
+	}
+
+	if lastKey != "" {
+		cursor, err := models.DecodeCursor(lastKey)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		input.ExclusiveStartKey = cursorToAttributeValue(cursor)
+	}
+
+	name := strings.ToLower(string(entity)) + "s"
+	result, err := r.client.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", name, err)
+	}
+
+	var items []I
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", name, err)
+	}
+
+	hasMore := len(items) > limit
+	var nextCursor string
+	if hasMore {
+		items = items[:limit]
+		nextCursor = sortCursor(result.Items[limit-1])
+	}
+
+	values := make([]T, 0, len(items))
+	for _, item := range items {
+		values = append(values, value(item))
+	}
+
+	return &PaginatedResult[T]{
+		Items:      values,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// sortCursor returns the cursor continuing a GSI5 query after item. It holds
+// exactly the table and index keys, as DynamoDB requires of a start key.
+func sortCursor(item map[string]types.AttributeValue) string {
+	cursor := models.PaginationCursor{}
+	for name, field := range map[string]*string{
+		"PK": &cursor.PK, "SK": &cursor.SK, "GSI5PK": &cursor.GSI5PK, "GSI5SK": &cursor.GSI5SK,
+	} {
+		if s, ok := item[name].(*types.AttributeValueMemberS); ok {
+			*field = s.Value
+		}
+	}
+	return models.EncodeCursor(cursor)
+}
+
+// SetSortLocale rebuilds the sort keys of a user's tracks, albums and artists
+// in locale and returns how many it rewrote. Items already keyed in locale
+// are skipped, so it can be repeated after a failure; items edited meanwhile
+// keep the key their writer built.
+func (r *DynamoDBRepository) SetSortLocale(ctx context.Context, userID, locale string) (int, error) {
+	updated := 0
+	for _, entity := range sortableEntities {
+		var startKey map[string]types.AttributeValue
+		for {
+			result, err := r.client.Query(ctx, &dynamodb.QueryInput{
+				TableName:                aws.String(r.tableName),
+				KeyConditionExpression:   aws.String("PK = :pk AND begins_with(SK, :skPrefix)"),
+				ProjectionExpression:     aws.String(sortableProjection),
+				ExpressionAttributeNames: sortableProjectionNames,
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":pk":       &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+					":skPrefix": &types.AttributeValueMemberS{Value: string(entity) + "#"},
+				},
+				ExclusiveStartKey: startKey,
+			})
+			if err != nil {
+				return updated, fmt.Errorf("failed to query %s sort keys: %w", strings.ToLower(string(entity)), err)
+			}
+
+			var items []models.SortableItem
+			if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+				return updated, fmt.Errorf("failed to unmarshal sort keys: %w", err)
+			}
+			for _, item := range items {
+				if item.SortKeyCurrent(locale) {
+					continue
+				}
+				err := r.PutSortKey(ctx, item.WithSortLocale(locale))
+				if errors.Is(err, ErrConflict) {
+					continue
+				}
+				if err != nil {
+					return updated, err
+				}
+				updated++
+			}
+
+			if result.LastEvaluatedKey == nil {
+				break
+			}
+			startKey = result.LastEvaluatedKey
+		}
+	}
+	return updated, nil
+}
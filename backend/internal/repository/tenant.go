@@ -0,0 +1,636 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+)
+
+// tenantPartitionAttributes are the table's partition key attributes. Sort keys
+// are left untouched so existing begins_with(SK, ...) queries keep working.
+var tenantPartitionAttributes = map[string]bool{
+	"PK":     true,
+	"GSI1PK": true,
+	"GSI2PK": true,
+	"GSI3PK": true,
+	"GSI4PK": true,
+	"GSI5PK": true,
+}
+
+var (
+	// Matches "<attr> = :value" in key conditions, filters, conditions and SET clauses
+	tenantEqualityPattern = regexp.MustCompile(`(#[A-Za-z0-9_]+|\b[A-Za-z][A-Za-z0-9_]*\b)\s*=\s*(:[A-Za-z0-9_]+)`)
+	// Matches "begins_with(<attr>, :value)"
+	tenantBeginsWithPattern = regexp.MustCompile(`begins_with\s*\(\s*(#[A-Za-z0-9_]+|[A-Za-z][A-Za-z0-9_]*)\s*,\s*(:[A-Za-z0-9_]+)\s*\)`)
+)
+
+const (
+	tenantScanAttrName  = "#tenantPK"
+	tenantScanAttrValue = ":tenantPrefix"
+)
+
+// TenantDynamoDBClient decorates a DynamoDBClient so that every partition key
+// (PK and GSI*PK) is prefixed with the tenant from the request context. Items
+// read back have the prefix stripped, so repositories stay tenant-unaware.
+// Calls made without a tenant in context fail with tenant.ErrMissingTenant.
+type TenantDynamoDBClient struct {
+	inner DynamoDBClient
+}
+
+// NewTenantDynamoDBClient wraps client with tenant partition key isolation
+func NewTenantDynamoDBClient(client DynamoDBClient) *TenantDynamoDBClient {
+	return &TenantDynamoDBClient{inner: client}
+}
+
+func (c *TenantDynamoDBClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	prefix, err := tenantKeyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Item = prefixItem(prefix, in.Item)
+	in.ExpressionAttributeValues = prefixExpressionValues(prefix, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.ConditionExpression)
+
+	out, err := c.inner.PutItem(ctx, &in, optFns...)
+	if out != nil {
+		out.Attributes = stripItem(prefix, out.Attributes)
+	}
+	return out, err
+}
+
+func (c *TenantDynamoDBClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	prefix, err := tenantKeyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = prefixItem(prefix, in.Key)
+
+	out, err := c.inner.GetItem(ctx, &in, optFns...)
+	if out != nil {
+		out.Item = stripItem(prefix, out.Item)
+	}
+	return out, err
+}
+
+func (c *TenantDynamoDBClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	prefix, err := tenantKeyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = prefixItem(prefix, in.Key)
+	in.ExpressionAttributeValues = prefixExpressionValues(prefix, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.UpdateExpression, in.ConditionExpression)
+
+	out, err := c.inner.UpdateItem(ctx, &in, optFns...)
+	if out != nil {
+		out.Attributes = stripItem(prefix, out.Attributes)
+	}
+	return out, err
+}
+
+func (c *TenantDynamoDBClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	prefix, err := tenantKeyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = prefixItem(prefix, in.Key)
+	in.ExpressionAttributeValues = prefixExpressionValues(prefix, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.ConditionExpression)
+
+	out, err := c.inner.DeleteItem(ctx, &in, optFns...)
+	if out != nil {
+		out.Attributes = stripItem(prefix, out.Attributes)
+	}
+	return out, err
+}
+
+func (c *TenantDynamoDBClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	prefix, err := tenantKeyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	in.ExclusiveStartKey = prefixItem(prefix, in.ExclusiveStartKey)
+	in.ExpressionAttributeValues = prefixExpressionValues(prefix, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.KeyConditionExpression, in.FilterExpression)
+
+	out, err := c.inner.Query(ctx, &in, optFns...)
+	if out != nil {
+		out.Items = stripItems(prefix, out.Items)
+		out.LastEvaluatedKey = stripItem(prefix, out.LastEvaluatedKey)
+	}
+	return out, err
+}
+
+// Scan restricts the scan to the tenant's partitions with an extra
+// begins_with(PK, prefix) filter. Scans still read (and bill for) the whole table.
+func (c *TenantDynamoDBClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	prefix, err := tenantKeyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	in.ExclusiveStartKey = prefixItem(prefix, in.ExclusiveStartKey)
+	in.ExpressionAttributeValues = prefixExpressionValues(prefix, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.FilterExpression)
+
+	tenantFilter := "begins_with(" + tenantScanAttrName + ", " + tenantScanAttrValue + ")"
+	if in.FilterExpression != nil && *in.FilterExpression != "" {
+		in.FilterExpression = aws.String("(" + *in.FilterExpression + ") AND " + tenantFilter)
+	} else {
+		in.FilterExpression = aws.String(tenantFilter)
+	}
+	names := make(map[string]string, len(in.ExpressionAttributeNames)+1)
+	for k, v := range in.ExpressionAttributeNames {
+		names[k] = v
+	}
+	names[tenantScanAttrName] = "PK"
+	in.ExpressionAttributeNames = names
+	values := make(map[string]types.AttributeValue, len(in.ExpressionAttributeValues)+1)
+	for k, v := range in.ExpressionAttributeValues {
+		values[k] = v
+	}
+	values[tenantScanAttrValue] = &types.AttributeValueMemberS{Value: prefix}
+	in.ExpressionAttributeValues = values
+
+	out, err := c.inner.Scan(ctx, &in, optFns...)
+	if out != nil {
+		out.Items = stripItems(prefix, out.Items)
+		out.LastEvaluatedKey = stripItem(prefix, out.LastEvaluatedKey)
+	}
+	return out, err
+}
+
+func (c *TenantDynamoDBClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	prefix, err := tenantKeyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	in.RequestItems = mapWriteRequests(in.RequestItems, func(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+		return prefixItem(prefix, item)
+	})
+
+	out, err := c.inner.BatchWriteItem(ctx, &in, optFns...)
+	if out != nil {
+		out.UnprocessedItems = mapWriteRequests(out.UnprocessedItems, func(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+			return stripItem(prefix, item)
+		})
+	}
+	return out, err
+}
+
+func (c *TenantDynamoDBClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	prefix, err := tenantKeyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	in.RequestItems = mapKeysAndAttributes(in.RequestItems, func(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+		return prefixItem(prefix, item)
+	})
+
+	out, err := c.inner.BatchGetItem(ctx, &in, optFns...)
+	if out != nil {
+		responses := make(map[string][]map[string]types.AttributeValue, len(out.Responses))
+		for table, items := range out.Responses {
+			responses[table] = stripItems(prefix, items)
+		}
+		out.Responses = responses
+		out.UnprocessedKeys = mapKeysAndAttributes(out.UnprocessedKeys, func(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+			return stripItem(prefix, item)
+		})
+	}
+	return out, err
+}
+
+func (c *TenantDynamoDBClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	prefix, err := tenantKeyPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	items := make([]types.TransactWriteItem, len(in.TransactItems))
+	for i, item := range in.TransactItems {
+		if item.Put != nil {
+			put := *item.Put
+			put.Item = prefixItem(prefix, put.Item)
+			put.ExpressionAttributeValues = prefixExpressionValues(prefix, put.ExpressionAttributeNames, put.ExpressionAttributeValues, put.ConditionExpression)
+			item.Put = &put
+		}
+		if item.Update != nil {
+			update := *item.Update
+			update.Key = prefixItem(prefix, update.Key)
+			update.ExpressionAttributeValues = prefixExpressionValues(prefix, update.ExpressionAttributeNames, update.ExpressionAttributeValues, update.UpdateExpression, update.ConditionExpression)
+			item.Update = &update
+		}
+		if item.Delete != nil {
+			del := *item.Delete
+			del.Key = prefixItem(prefix, del.Key)
+			del.ExpressionAttributeValues = prefixExpressionValues(prefix, del.ExpressionAttributeNames, del.ExpressionAttributeValues, del.ConditionExpression)
+			item.Delete = &del
+		}
+		if item.ConditionCheck != nil {
+			check := *item.ConditionCheck
+			check.Key = prefixItem(prefix, check.Key)
+			check.ExpressionAttributeValues = prefixExpressionValues(prefix, check.ExpressionAttributeNames, check.ExpressionAttributeValues, check.ConditionExpression)
+			item.ConditionCheck = &check
+		}
+		items[i] = item
+	}
+	in.TransactItems = items
+
+	return c.inner.TransactWriteItems(ctx, &in, optFns...)
+}
+
+// tenantKeyPrefix returns the partition key prefix for the tenant in ctx
+func tenantKeyPrefix(ctx context.Context) (string, error) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", tenant.ErrMissingTenant
+	}
+	return tenant.KeyPrefix(id), nil
+}
+
+// prefixItem returns a copy of item with its partition key attributes prefixed
+func prefixItem(prefix string, item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	return rewritePartitionKeys(item, func(v string) string {
+		return prefix + v
+	})
+}
+
+// stripItem returns a copy of item with the tenant prefix removed from its partition keys
+func stripItem(prefix string, item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	return rewritePartitionKeys(item, func(v string) string {
+		return strings.TrimPrefix(v, prefix)
+	})
+}
+
+func stripItems(prefix string, items []map[string]types.AttributeValue) []map[string]types.AttributeValue {
+	if items == nil {
+		return nil
+	}
+	stripped := make([]map[string]types.AttributeValue, len(items))
+	for i, item := range items {
+		stripped[i] = stripItem(prefix, item)
+	}
+	return stripped
+}
+
+func rewritePartitionKeys(item map[string]types.AttributeValue, rewrite func(string) string) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	out := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		if s, ok := value.(*types.AttributeValueMemberS); ok && tenantPartitionAttributes[name] {
+			value = &types.AttributeValueMemberS{Value: rewrite(s.Value)}
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// prefixExpressionValues prefixes the expression values that are compared with
+// (or assigned to) a partition key attribute in any of the given expressions.
+func prefixExpressionValues(prefix string, names map[string]string, values map[string]types.AttributeValue, expressions ...*string) map[string]types.AttributeValue {
+	if len(values) == 0 {
+		return values
+	}
+
+	placeholders := make(map[string]bool)
+	for _, expr := range expressions {
+		if expr == nil {
+			continue
+		}
+		for _, pattern := range []*regexp.Regexp{tenantEqualityPattern, tenantBeginsWithPattern} {
+			for _, match := range pattern.FindAllStringSubmatch(*expr, -1) {
+				attr := match[1]
+				if resolved, ok := names[attr]; ok {
+					attr = resolved
+				}
+				if tenantPartitionAttributes[attr] {
+					placeholders[match[2]] = true
+				}
+			}
+		}
+	}
+	if len(placeholders) == 0 {
+		return values
+	}
+
+	out := make(map[string]types.AttributeValue, len(values))
+	for name, value := range values {
+		if s, ok := value.(*types.AttributeValueMemberS); ok && placeholders[name] {
+			value = &types.AttributeValueMemberS{Value: prefix + s.Value}
+		}
+		out[name] = value
+	}
+	return out
+}
+
+func mapWriteRequests(requests map[string][]types.WriteRequest, rewrite func(map[string]types.AttributeValue) map[string]types.AttributeValue) map[string][]types.WriteRequest {
+	if requests == nil {
+		return nil
+	}
+	out := make(map[string][]types.WriteRequest, len(requests))
+	for table, writes := range requests {
+		mapped := make([]types.WriteRequest, len(writes))
+		for i, w := range writes {
+			if w.PutRequest != nil {
+				w.PutRequest = &types.PutRequest{Item: rewrite(w.PutRequest.Item)}
+			}
+			if w.DeleteRequest != nil {
+				w.DeleteRequest = &types.DeleteRequest{Key: rewrite(w.DeleteRequest.Key)}
+			}
+			mapped[i] = w
+		}
+		out[table] = mapped
+	}
+	return out
+}
+
+func mapKeysAndAttributes(requests map[string]types.KeysAndAttributes, rewrite func(map[string]types.AttributeValue) map[string]types.AttributeValue) map[string]types.KeysAndAttributes {
+	if requests == nil {
+		return nil
+	}
+	out := make(map[string]types.KeysAndAttributes, len(requests))
+	for table, ka := range requests {
+		keys := make([]map[string]types.AttributeValue, len(ka.Keys))
+		for i, key := range ka.Keys {
+			keys[i] = rewrite(key)
+		}
+		ka.Keys = keys
+		out[table] = ka
+	}
+	return out
+}
+
+// TenantS3Client decorates an S3Client so that every object key lives under
+// the tenant's prefix (tenants/{tenantId}/). Keys returned by listings have the
+// prefix stripped. Without a tenant in context, calls fail with tenant.ErrMissingTenant.
+type TenantS3Client struct {
+	inner S3Client
+}
+
+// NewTenantS3Client wraps client with tenant object key isolation
+func NewTenantS3Client(client S3Client) *TenantS3Client {
+	return &TenantS3Client{inner: client}
+}
+
+func (c *TenantS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	return c.inner.PutObject(ctx, &in, optFns...)
+}
+
+func (c *TenantS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	return c.inner.GetObject(ctx, &in, optFns...)
+}
+
+func (c *TenantS3Client) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	return c.inner.DeleteObject(ctx, &in, optFns...)
+}
+
+func (c *TenantS3Client) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	if in.Delete != nil {
+		del := *in.Delete
+		objects := make([]s3types.ObjectIdentifier, len(del.Objects))
+		for i, obj := range del.Objects {
+			obj.Key = tenantObjectKey(ctx, obj.Key)
+			objects[i] = obj
+		}
+		del.Objects = objects
+		in.Delete = &del
+	}
+
+	out, err := c.inner.DeleteObjects(ctx, &in, optFns...)
+	if out != nil {
+		for i := range out.Deleted {
+			out.Deleted[i].Key = stripTenantObjectKey(ctx, out.Deleted[i].Key)
+		}
+		for i := range out.Errors {
+			out.Errors[i].Key = stripTenantObjectKey(ctx, out.Errors[i].Key)
+		}
+	}
+	return out, err
+}
+
+func (c *TenantS3Client) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	id, _ := tenant.FromContext(ctx)
+	in.Prefix = aws.String(tenant.ObjectPrefix(id) + aws.ToString(in.Prefix))
+	if in.StartAfter != nil {
+		in.StartAfter = tenantObjectKey(ctx, in.StartAfter)
+	}
+
+	out, err := c.inner.ListObjectsV2(ctx, &in, optFns...)
+	if out != nil {
+		out.Prefix = params.Prefix
+		for i := range out.Contents {
+			out.Contents[i].Key = stripTenantObjectKey(ctx, out.Contents[i].Key)
+		}
+		for i := range out.CommonPrefixes {
+			out.CommonPrefixes[i].Prefix = stripTenantObjectKey(ctx, out.CommonPrefixes[i].Prefix)
+		}
+	}
+	return out, err
+}
+
+// ListObjectVersions scopes the prefix and key marker like ListObjectsV2
+func (c *TenantS3Client) ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	id, _ := tenant.FromContext(ctx)
+	in.Prefix = aws.String(tenant.ObjectPrefix(id) + aws.ToString(in.Prefix))
+	if in.KeyMarker != nil {
+		in.KeyMarker = tenantObjectKey(ctx, in.KeyMarker)
+	}
+
+	out, err := c.inner.ListObjectVersions(ctx, &in, optFns...)
+	if out != nil {
+		out.Prefix = params.Prefix
+		out.NextKeyMarker = stripTenantObjectKey(ctx, out.NextKeyMarker)
+		for i := range out.Versions {
+			out.Versions[i].Key = stripTenantObjectKey(ctx, out.Versions[i].Key)
+		}
+		for i := range out.DeleteMarkers {
+			out.DeleteMarkers[i].Key = stripTenantObjectKey(ctx, out.DeleteMarkers[i].Key)
+		}
+	}
+	return out, err
+}
+
+// CopyObject rewrites both the destination key and the "bucket/key" copy source
+func (c *TenantS3Client) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	if in.CopySource != nil {
+		if bucket, key, ok := strings.Cut(strings.TrimPrefix(*in.CopySource, "/"), "/"); ok {
+			in.CopySource = aws.String(bucket + "/" + tenant.ObjectKey(ctx, key))
+		}
+	}
+	return c.inner.CopyObject(ctx, &in, optFns...)
+}
+
+func (c *TenantS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	return c.inner.HeadObject(ctx, &in, optFns...)
+}
+
+func (c *TenantS3Client) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	out, err := c.inner.CreateMultipartUpload(ctx, &in, optFns...)
+	if out != nil {
+		out.Key = params.Key
+	}
+	return out, err
+}
+
+func (c *TenantS3Client) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	out, err := c.inner.CompleteMultipartUpload(ctx, &in, optFns...)
+	if out != nil {
+		out.Key = params.Key
+	}
+	return out, err
+}
+
+func (c *TenantS3Client) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	return c.inner.AbortMultipartUpload(ctx, &in, optFns...)
+}
+
+// TenantS3PresignClient decorates an S3PresignClient so presigned URLs point
+// at the tenant's physical object keys.
+type TenantS3PresignClient struct {
+	inner S3PresignClient
+}
+
+// NewTenantS3PresignClient wraps client with tenant object key isolation
+func NewTenantS3PresignClient(client S3PresignClient) *TenantS3PresignClient {
+	return &TenantS3PresignClient{inner: client}
+}
+
+func (c *TenantS3PresignClient) PresignPutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	return c.inner.PresignPutObject(ctx, &in, optFns...)
+}
+
+func (c *TenantS3PresignClient) PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	return c.inner.PresignGetObject(ctx, &in, optFns...)
+}
+
+func (c *TenantS3PresignClient) PresignUploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	if err := requireTenant(ctx); err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Key = tenantObjectKey(ctx, in.Key)
+	return c.inner.PresignUploadPart(ctx, &in, optFns...)
+}
+
+// TenantCloudFrontSigner decorates a CloudFrontSigner so signed URLs point at
+// the tenant's physical object keys.
+type TenantCloudFrontSigner struct {
+	inner CloudFrontSigner
+}
+
+// NewTenantCloudFrontSigner wraps signer with tenant object key isolation
+func NewTenantCloudFrontSigner(signer CloudFrontSigner) *TenantCloudFrontSigner {
+	return &TenantCloudFrontSigner{inner: signer}
+}
+
+func (s *TenantCloudFrontSigner) GenerateSignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := requireTenant(ctx); err != nil {
+		return "", err
+	}
+	return s.inner.GenerateSignedURL(ctx, tenant.ObjectKey(ctx, key), expiry)
+}
+
+func (s *TenantCloudFrontSigner) GenerateSignedDownloadURL(ctx context.Context, key string, expiry time.Duration, filename string) (string, error) {
+	if err := requireTenant(ctx); err != nil {
+		return "", err
+	}
+	return s.inner.GenerateSignedDownloadURL(ctx, tenant.ObjectKey(ctx, key), expiry, filename)
+}
+
+func requireTenant(ctx context.Context) error {
+	if _, ok := tenant.FromContext(ctx); !ok {
+		return tenant.ErrMissingTenant
+	}
+	return nil
+}
+
+func tenantObjectKey(ctx context.Context, key *string) *string {
+	if key == nil {
+		return nil
+	}
+	return aws.String(tenant.ObjectKey(ctx, *key))
+}
+
+func stripTenantObjectKey(ctx context.Context, key *string) *string {
+	id, ok := tenant.FromContext(ctx)
+	if key == nil || !ok {
+		return key
+	}
+	return aws.String(strings.TrimPrefix(*key, tenant.ObjectPrefix(id)))
+}
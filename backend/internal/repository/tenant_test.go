@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDynamoDBClient captures the inputs the tenant decorator forwards
+type recordingDynamoDBClient struct {
+	DynamoDBClient
+	put   *dynamodb.PutItemInput
+	query *dynamodb.QueryInput
+	items []map[string]types.AttributeValue
+}
+
+func (c *recordingDynamoDBClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	c.put = params
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (c *recordingDynamoDBClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	c.query = params
+	return &dynamodb.QueryOutput{Items: c.items}, nil
+}
+
+func stringValue(t *testing.T, item map[string]types.AttributeValue, name string) string {
+	t.Helper()
+	s, ok := item[name].(*types.AttributeValueMemberS)
+	require.True(t, ok, "attribute %s is not a string", name)
+	return s.Value
+}
+
+func TestTenantDynamoDBClient_PrefixesPartitionKeys(t *testing.T) {
+	inner := &recordingDynamoDBClient{}
+	client := NewTenantDynamoDBClient(inner)
+	ctx := tenant.WithID(context.Background(), "acme")
+
+	item := map[string]types.AttributeValue{
+		"PK":     &types.AttributeValueMemberS{Value: "USER#u1"},
+		"SK":     &types.AttributeValueMemberS{Value: "TRACK#t1"},
+		"GSI1PK": &types.AttributeValueMemberS{Value: "USER#u1#ARTIST"},
+	}
+	_, err := client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item})
+	require.NoError(t, err)
+
+	assert.Equal(t, "TENANT#acme#USER#u1", stringValue(t, inner.put.Item, "PK"))
+	assert.Equal(t, "TRACK#t1", stringValue(t, inner.put.Item, "SK"))
+	assert.Equal(t, "TENANT#acme#USER#u1#ARTIST", stringValue(t, inner.put.Item, "GSI1PK"))
+	// The caller's input is not mutated
+	assert.Equal(t, "USER#u1", stringValue(t, item, "PK"))
+}
+
+func TestTenantDynamoDBClient_QueryRewritesKeyConditionAndStripsResults(t *testing.T) {
+	inner := &recordingDynamoDBClient{
+		items: []map[string]types.AttributeValue{{
+			"PK": &types.AttributeValueMemberS{Value: "TENANT#acme#USER#u1"},
+			"SK": &types.AttributeValueMemberS{Value: "TRACK#t1"},
+		}},
+	}
+	client := NewTenantDynamoDBClient(inner)
+	ctx := tenant.WithID(context.Background(), "acme")
+
+	keyCond := expression.Key("PK").Equal(expression.Value("USER#u1")).
+		And(expression.Key("SK").BeginsWith("TRACK#"))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	require.NoError(t, err)
+
+	out, err := client.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String("t"),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	require.NoError(t, err)
+
+	var pkValue, skValue string
+	for _, v := range inner.query.ExpressionAttributeValues {
+		s := v.(*types.AttributeValueMemberS).Value
+		if s == "TRACK#" {
+			skValue = s
+		} else {
+			pkValue = s
+		}
+	}
+	assert.Equal(t, "TENANT#acme#USER#u1", pkValue)
+	assert.Equal(t, "TRACK#", skValue)
+	require.Len(t, out.Items, 1)
+	assert.Equal(t, "USER#u1", stringValue(t, out.Items[0], "PK"))
+}
+
+func TestTenantDynamoDBClient_RequiresTenant(t *testing.T) {
+	client := NewTenantDynamoDBClient(&recordingDynamoDBClient{})
+
+	_, err := client.PutItem(context.Background(), &dynamodb.PutItemInput{})
+
+	assert.ErrorIs(t, err, tenant.ErrMissingTenant)
+}
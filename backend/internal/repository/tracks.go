@@ -107,3 +107,212 @@ func (r *DynamoDBRepository) GetTracksWithBPMRange(ctx context.Context, userID s
 
 	return tracks, nil
 }
+
+// FindTracksByContentHash retrieves a user's tracks whose audio file has the given SHA-256
+func (r *DynamoDBRepository) FindTracksByContentHash(ctx context.Context, userID, contentHash string) ([]models.Track, error) {
+	pk := fmt.Sprintf("USER#%s", userID)
+
+	var tracks []models.Track
+	var lastKey map[string]types.AttributeValue
+
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :skPrefix)"),
+			FilterExpression:       aws.String("contentHash = :hash"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":       &types.AttributeValueMemberS{Value: pk},
+				":skPrefix": &types.AttributeValueMemberS{Value: "TRACK#"},
+				":hash":     &types.AttributeValueMemberS{Value: contentHash},
+			},
+		}
+
+		if lastKey != nil {
+			input.ExclusiveStartKey = lastKey
+		}
+
+		result, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query tracks by content hash: %w", err)
+		}
+
+		var items []models.TrackItem
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tracks: %w", err)
+		}
+
+		for _, item := range items {
+			tracks = append(tracks, item.Track)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+
+	return tracks, nil
+}
+
+// ListTracksByKeyAndBPMBucket queries GSI4 for a user's tracks in one Camelot
+// key ("" for tracks with no detected key) whose tempo band is between
+// minBucket and maxBucket inclusive; a minBucket of -1 includes unknown tempos
+func (r *DynamoDBRepository) ListTracksByKeyAndBPMBucket(ctx context.Context, userID, camelotKey string, minBucket, maxBucket int) ([]models.Track, error) {
+	var tracks []models.Track
+	var lastKey map[string]types.AttributeValue
+
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			IndexName:              aws.String("GSI4"),
+			KeyConditionExpression: aws.String("GSI4PK = :pk AND GSI4SK BETWEEN :from AND :to"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":   &types.AttributeValueMemberS{Value: models.GetHarmonicGSI4PK(userID, camelotKey)},
+				":from": &types.AttributeValueMemberS{Value: models.GetBPMBucketSK(minBucket)},
+				// "~" sorts after every track ID, so the last band is included in full
+				":to": &types.AttributeValueMemberS{Value: models.GetBPMBucketSK(maxBucket) + "#~"},
+			},
+		}
+
+		if lastKey != nil {
+			input.ExclusiveStartKey = lastKey
+		}
+
+		result, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query tracks by key and BPM: %w", err)
+		}
+
+		var items []models.TrackItem
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tracks: %w", err)
+		}
+
+		for _, item := range items {
+			tracks = append(tracks, item.Track)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+
+	return tracks, nil
+}
+
+// BackfillHarmonicIndex writes GSI4 keys on a user's tracks saved before the
+// index existed, without touching any other attribute. It is idempotent and
+// returns the number of tracks updated.
+func (r *DynamoDBRepository) BackfillHarmonicIndex(ctx context.Context, userID string) (int, error) {
+	pk := fmt.Sprintf("USER#%s", userID)
+
+	updated := 0
+	var lastKey map[string]types.AttributeValue
+
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :skPrefix)"),
+			FilterExpression:       aws.String("attribute_not_exists(GSI4PK)"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":       &types.AttributeValueMemberS{Value: pk},
+				":skPrefix": &types.AttributeValueMemberS{Value: "TRACK#"},
+			},
+		}
+
+		if lastKey != nil {
+			input.ExclusiveStartKey = lastKey
+		}
+
+		result, err := r.client.Query(ctx, input)
+		if err != nil {
+			return updated, fmt.Errorf("failed to list tracks for backfill: %w", err)
+		}
+
+		var items []models.TrackItem
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return updated, fmt.Errorf("failed to unmarshal tracks: %w", err)
+		}
+
+		for _, item := range items {
+			keys := models.NewTrackItem(item.Track)
+			_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName: aws.String(r.tableName),
+				Key: map[string]types.AttributeValue{
+					"PK": &types.AttributeValueMemberS{Value: keys.PK},
+					"SK": &types.AttributeValueMemberS{Value: keys.SK},
+				},
+				UpdateExpression:    aws.String("SET GSI4PK = :gsi4pk, GSI4SK = :gsi4sk"),
+				ConditionExpression: aws.String("attribute_exists(PK)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":gsi4pk": &types.AttributeValueMemberS{Value: keys.GSI4PK},
+					":gsi4sk": &types.AttributeValueMemberS{Value: keys.GSI4SK},
+				},
+			})
+			if err != nil {
+				return updated, fmt.Errorf("failed to backfill track %s: %w", item.Track.ID, err)
+			}
+			updated++
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastKey = result.LastEvaluatedKey
+	}
+
+	return updated, nil
+}
+
+// BatchGetTracks retrieves several of a user's tracks by ID, keyed by track
+// ID. Tracks that do not exist are left out.
+func (r *DynamoDBRepository) BatchGetTracks(ctx context.Context, userID string, trackIDs []string) (map[string]models.Track, error) {
+	result := make(map[string]models.Track, len(trackIDs))
+
+	// Process in batches of 100 (DynamoDB limit)
+	for i := 0; i < len(trackIDs); i += 100 {
+		end := i + 100
+		if end > len(trackIDs) {
+			end = len(trackIDs)
+		}
+
+		keys := make([]map[string]types.AttributeValue, 0, end-i)
+		seen := make(map[string]bool, end-i)
+		for _, trackID := range trackIDs[i:end] {
+			// BatchGetItem rejects duplicate keys
+			if seen[trackID] {
+				continue
+			}
+			seen[trackID] = true
+			keys = append(keys, map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+				"SK": &types.AttributeValueMemberS{Value: fmt.Sprintf("TRACK#%s", trackID)},
+			})
+		}
+
+		request := map[string]types.KeysAndAttributes{r.tableName: {Keys: keys}}
+		for attempt := 0; len(request) > 0; attempt++ {
+			if attempt == maxBatchGetAttempts {
+				return nil, fmt.Errorf("failed to batch get tracks: keys still unprocessed after %d attempts", attempt)
+			}
+
+			batchResult, err := r.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				return nil, fmt.Errorf("failed to batch get tracks: %w", err)
+			}
+
+			var items []models.TrackItem
+			if err := attributevalue.UnmarshalListOfMaps(batchResult.Responses[r.tableName], &items); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal tracks: %w", err)
+			}
+			for _, item := range items {
+				result[item.Track.ID] = item.Track
+			}
+
+			request = batchResult.UnprocessedKeys
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// GetTrashedTrack retrieves a track from the trash of its owner's library.
+// Items past their TTL may still be returned until DynamoDB removes them.
+func (r *DynamoDBRepository) GetTrashedTrack(ctx context.Context, ownerID, trackID string) (*models.TrashedTrack, error) {
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       trashedTrackKey(ownerID, trackID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed track: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var item models.TrashedTrackItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trashed track: %w", err)
+	}
+
+	return &item.TrashedTrack, nil
+}
+
+// PutTrashedTrack keeps a deleted track in the trash of its owner's library
+func (r *DynamoDBRepository) PutTrashedTrack(ctx context.Context, trashed models.TrashedTrack) error {
+	av, err := attributevalue.MarshalMap(models.NewTrashedTrackItem(trashed))
+	if err != nil {
+		return fmt.Errorf("failed to marshal trashed track: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put trashed track: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTrashedTrack removes a track from the trash
+func (r *DynamoDBRepository) DeleteTrashedTrack(ctx context.Context, ownerID, trackID string) error {
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       trashedTrackKey(ownerID, trackID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete trashed track: %w", err)
+	}
+
+	return nil
+}
+
+func trashedTrackKey(ownerID, trackID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "USER#" + ownerID},
+		"SK": &types.AttributeValueMemberS{Value: models.GetTrashedTrackSK(trackID)},
+	}
+}
@@ -168,6 +168,12 @@ func (r *DynamoDBRepository) UpdateUserSettings(ctx context.Context, userID stri
 	if update.Library != nil {
 		user.Settings.Library = *update.Library
 	}
+	if update.Language != nil {
+		user.Settings.Language = *update.Language
+	}
+	if update.TimeZone != nil {
+		user.Settings.TimeZone = *update.TimeZone
+	}
 
 	// Validate the updated settings
 	if err := user.Settings.Validate(); err != nil {
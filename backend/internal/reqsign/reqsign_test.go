@@ -0,0 +1,106 @@
+package reqsign
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	oldSecret = "old-secret-0123456789abcdef0123456789"
+	newSecret = "new-secret-0123456789abcdef0123456789"
+)
+
+// newSignedRequest signs a request with the first of keys, as a caller would
+func newSignedRequest(t *testing.T, keys Keyring, body string, at time.Time) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/embeddings?model=titan", strings.NewReader(body))
+	bodyHash, err := hashBody(req)
+	require.NoError(t, err)
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req.Header.Set(HeaderKeyID, keys[0].ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, sign(keys[0], req, timestamp, bodyHash))
+	return req
+}
+
+func TestParseKeyring(t *testing.T) {
+	keys, err := ParseKeyring(" k2:" + newSecret + ", k1:" + oldSecret)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "k2", keys[0].ID, "the first key signs")
+
+	keys, err = ParseKeyring("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = ParseKeyring("k1:short")
+	assert.ErrorContains(t, err, "shorter")
+	_, err = ParseKeyring("k1:" + oldSecret + ",k1:" + newSecret)
+	assert.ErrorContains(t, err, "twice")
+	_, err = ParseKeyring(oldSecret)
+	assert.ErrorContains(t, err, "id:secret")
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	keys := Keyring{{ID: "k1", Secret: []byte(oldSecret)}}
+	verifier := NewVerifier(keys, 0)
+	verifier.now = func() time.Time { return now }
+
+	t.Run("accepts a signed request and restores its body", func(t *testing.T) {
+		req := newSignedRequest(t, keys, `{"input":"hi"}`, now.Add(-time.Minute))
+
+		keyID, err := verifier.Verify(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "k1", keyID)
+		body, _ := io.ReadAll(req.Body)
+		assert.Equal(t, `{"input":"hi"}`, string(body))
+	})
+
+	t.Run("rejects tampering", func(t *testing.T) {
+		req := newSignedRequest(t, keys, `{"input":"hi"}`, now)
+		req.Body = io.NopCloser(strings.NewReader(`{"input":"bye"}`))
+		_, err := verifier.Verify(req)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+
+		req = newSignedRequest(t, keys, "", now)
+		req.URL.RawQuery = "model=other"
+		_, err = verifier.Verify(req)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("rejects stale, unknown and missing signatures", func(t *testing.T) {
+		_, err := verifier.Verify(newSignedRequest(t, keys, "", now.Add(-10*time.Minute)))
+		assert.ErrorIs(t, err, ErrExpired)
+
+		_, err = verifier.Verify(newSignedRequest(t, Keyring{{ID: "k9", Secret: []byte(newSecret)}}, "", now))
+		assert.ErrorIs(t, err, ErrUnknownKey)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.False(t, Signed(req))
+		_, err = verifier.Verify(req)
+		assert.ErrorIs(t, err, ErrMissingSignature)
+	})
+
+	t.Run("verifies with every key during rotation", func(t *testing.T) {
+		rotating := NewVerifier(Keyring{{ID: "k2", Secret: []byte(newSecret)}, keys[0]}, 0)
+		rotating.now = verifier.now
+
+		keyID, err := rotating.Verify(newSignedRequest(t, keys, "", now))
+		require.NoError(t, err)
+		assert.Equal(t, "k1", keyID)
+
+		keyID, err = rotating.Verify(newSignedRequest(t, Keyring{{ID: "k2", Secret: []byte(newSecret)}}, "", now))
+		require.NoError(t, err)
+		assert.Equal(t, "k2", keyID)
+	})
+}
@@ -0,0 +1,71 @@
+package reqsign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMalformedToken is returned for a token that is not keyID.payload.signature
+var ErrMalformedToken = errors.New("token is malformed")
+
+// SignToken returns a URL-safe token carrying subject until expires, signed
+// with the first key of the keyring. Tokens are bearer credentials for links
+// that work without an account; the subject is readable by anyone holding
+// one, so it must not be secret.
+func (k Keyring) SignToken(subject string, expires time.Time) (string, error) {
+	if len(k) == 0 {
+		return "", errors.New("no signing key configured")
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(subject + "\n" + strconv.FormatInt(expires.Unix(), 10)))
+	return k[0].ID + "." + payload + "." + signToken(k[0], payload), nil
+}
+
+// VerifyToken checks a token made by SignToken with any key of the keyring
+// and returns its subject, unless it has expired at now
+func (k Keyring) VerifyToken(token string, now time.Time) (string, error) {
+	keyID, rest, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrMalformedToken
+	}
+	payload, signature, ok := strings.Cut(rest, ".")
+	if !ok {
+		return "", ErrMalformedToken
+	}
+
+	key, ok := k.find(keyID)
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	if !hmac.Equal([]byte(signToken(key, payload)), []byte(signature)) {
+		return "", ErrInvalidSignature
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrMalformedToken
+	}
+	subject, expiry, ok := strings.Cut(string(raw), "\n")
+	if !ok {
+		return "", ErrMalformedToken
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", ErrMalformedToken
+	}
+	if !now.Before(time.Unix(unix, 0)) {
+		return "", ErrExpired
+	}
+	return subject, nil
+}
+
+// signToken returns the base64url HMAC of a token's key ID and payload
+func signToken(key Key, payload string) string {
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte(key.ID + "." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
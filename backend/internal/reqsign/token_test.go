@@ -0,0 +1,63 @@
+package reqsign
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToken(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	oldKeys := Keyring{{ID: "k1", Secret: []byte(oldSecret)}}
+	rotated := Keyring{{ID: "k2", Secret: []byte(newSecret)}, {ID: "k1", Secret: []byte(oldSecret)}}
+
+	token, err := oldKeys.SignToken("user-1/track-1", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.NotContains(t, token, "/", "tokens are safe in a URL path")
+
+	t.Run("returns the subject", func(t *testing.T) {
+		subject, err := oldKeys.VerifyToken(token, now)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1/track-1", subject)
+	})
+
+	t.Run("verifies with any key of a rotated keyring", func(t *testing.T) {
+		subject, err := rotated.VerifyToken(token, now)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1/track-1", subject)
+
+		signed, err := rotated.SignToken("s", now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(signed, "k2."))
+		_, err = oldKeys.VerifyToken(signed, now)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("rejects expired tokens", func(t *testing.T) {
+		_, err := oldKeys.VerifyToken(token, now.Add(time.Hour))
+		assert.ErrorIs(t, err, ErrExpired)
+	})
+
+	t.Run("rejects tampered tokens", func(t *testing.T) {
+		parts := strings.Split(token, ".")
+		forged, err := oldKeys.SignToken("user-2/track-9", now.Add(time.Hour))
+		require.NoError(t, err)
+		parts[1] = strings.Split(forged, ".")[1]
+
+		_, err = oldKeys.VerifyToken(strings.Join(parts, "."), now)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("rejects malformed tokens", func(t *testing.T) {
+		_, err := oldKeys.VerifyToken("not-a-token", now)
+		assert.ErrorIs(t, err, ErrMalformedToken)
+	})
+
+	t.Run("cannot sign without keys", func(t *testing.T) {
+		_, err := Keyring{}.SignToken("s", now)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,40 @@
+package resilience
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+)
+
+// awsRetryables are the SDK's own checks: throttling, 5xx responses, request
+// timeouts and connection errors, but not cancellations
+var awsRetryables = retry.IsErrorRetryables(retry.DefaultRetryables)
+
+// AWSRetryable reports whether an AWS SDK error is worth retrying. Use it as
+// Policy.Retryable for clients whose SDK retries are turned off.
+func AWSRetryable(err error) bool {
+	return awsRetryables.IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// AWSFailure reports whether an AWS SDK error means the service is unhealthy.
+// Caller mistakes such as missing items, failed conditions and validation
+// errors do not count, so they never open a breaker. Use it as
+// Policy.Failure.
+func AWSFailure(err error) bool {
+	if AWSRetryable(err) {
+		return true
+	}
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode() >= http.StatusInternalServerError
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorFault() != smithy.FaultClient
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Names of the operation classes the API limits. Expensive operations share
+// the table with interactive traffic, so each class gets a bounded number of
+// concurrent slots instead of being free to use up the table's capacity.
+const (
+	// LimitScan bounds concurrent table scans (global track listing and
+	// counts, user search)
+	LimitScan = "scan"
+	// LimitBulk bounds concurrent bulk edits (cleanup and its undo, DJ
+	// library and user imports, track transfers)
+	LimitBulk = "bulk"
+	// LimitReindex bounds concurrent search index rebuilds
+	LimitReindex = "reindex"
+)
+
+// ErrConcurrencyLimit is matched by the LimitError returned when no slot of
+// a limiter freed up in time
+var ErrConcurrencyLimit = errors.New("resilience: concurrency limit reached")
+
+// LimitError reports which limiter turned an operation away
+type LimitError struct {
+	Name string
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("resilience: too many concurrent %s operations", e.Name)
+}
+
+// Unwrap lets errors.Is match ErrConcurrencyLimit
+func (e *LimitError) Unwrap() error {
+	return ErrConcurrencyLimit
+}
+
+// Limiter is a semaphore bounding how many operations of one class run at
+// once. Slots are per process: a Lambda sandbox serves one request at a time,
+// so the limits bite on the standalone server and in the sandbox's own
+// fan-out. A nil Limiter admits everything.
+type Limiter struct {
+	name  string
+	slots chan struct{}
+	// wait is how long an operation queues for a slot before it is refused
+	wait time.Duration
+}
+
+// NewLimiter creates a limiter admitting limit concurrent operations, or nil
+// (no limit) when limit is not positive
+func NewLimiter(name string, limit int, wait time.Duration) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &Limiter{name: name, slots: make(chan struct{}, limit), wait: wait}
+}
+
+// Acquire takes a slot, waiting up to the limiter's wait time or until ctx is
+// done. The returned release must be called once the operation finishes.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	default:
+	}
+	if l.wait <= 0 {
+		return nil, &LimitError{Name: l.name}
+	}
+
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, &LimitError{Name: l.name}
+	}
+}
+
+// Do runs fn holding a slot
+func (l *Limiter) Do(ctx context.Context, fn func(context.Context) error) error {
+	release, err := l.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn(ctx)
+}
+
+func (l *Limiter) release() {
+	<-l.slots
+}
+
+// LimitStatus is the occupancy of a limiter as reported on GET /status
+type LimitStatus struct {
+	Name  string `json:"name"`
+	Limit int    `json:"limit"`
+	InUse int    `json:"inUse"`
+}
+
+// Status reports the limiter's slots in use
+func (l *Limiter) Status() LimitStatus {
+	return LimitStatus{Name: l.name, Limit: cap(l.slots), InUse: len(l.slots)}
+}
+
+// RegisterLimit creates a limiter and records it under name for status
+// reports. Unlimited classes (limit 0) return nil and are not reported.
+func (r *Registry) RegisterLimit(name string, limit int, wait time.Duration) *Limiter {
+	l := NewLimiter(name, limit, wait)
+	if r == nil || l == nil {
+		return l
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits[name] = l
+	return l
+}
+
+// Limiter returns the limiter registered under name, or nil (no limit)
+func (r *Registry) Limiter(name string) *Limiter {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.limits[name]
+}
+
+// LimitStatuses returns the status of every registered limiter sorted by name
+func (r *Registry) LimitStatuses() []LimitStatus {
+	statuses := []LimitStatus{}
+	if r == nil {
+		return statuses
+	}
+
+	r.mu.RLock()
+	for _, l := range r.limits {
+		statuses = append(statuses, l.Status())
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
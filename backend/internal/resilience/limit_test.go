@@ -0,0 +1,104 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("nil limiter admits everything", func(t *testing.T) {
+		limiter := NewLimiter(LimitScan, 0, time.Second)
+		assert.Nil(t, limiter)
+		release, err := limiter.Acquire(ctx)
+		require.NoError(t, err)
+		release()
+		assert.NoError(t, limiter.Do(ctx, func(context.Context) error { return nil }))
+	})
+
+	t.Run("refuses past the limit without waiting", func(t *testing.T) {
+		limiter := NewLimiter(LimitBulk, 2, 0)
+		first, err := limiter.Acquire(ctx)
+		require.NoError(t, err)
+		second, err := limiter.Acquire(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, LimitStatus{Name: LimitBulk, Limit: 2, InUse: 2}, limiter.Status())
+
+		_, err = limiter.Acquire(ctx)
+		var limitErr *LimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, LimitBulk, limitErr.Name)
+		assert.ErrorIs(t, err, ErrConcurrencyLimit)
+
+		first()
+		second()
+		assert.Equal(t, 0, limiter.Status().InUse)
+	})
+
+	t.Run("waits for a slot to free up", func(t *testing.T) {
+		limiter := NewLimiter(LimitReindex, 1, time.Second)
+		release, err := limiter.Acquire(ctx)
+		require.NoError(t, err)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			release()
+		}()
+
+		assert.NoError(t, limiter.Do(ctx, func(context.Context) error { return nil }))
+	})
+
+	t.Run("gives up after the wait time", func(t *testing.T) {
+		limiter := NewLimiter(LimitScan, 1, 10*time.Millisecond)
+		release, err := limiter.Acquire(ctx)
+		require.NoError(t, err)
+		defer release()
+
+		err = limiter.Do(ctx, func(context.Context) error { return nil })
+		assert.ErrorIs(t, err, ErrConcurrencyLimit)
+	})
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		limiter := NewLimiter(LimitScan, 1, time.Minute)
+		release, err := limiter.Acquire(ctx)
+		require.NoError(t, err)
+		defer release()
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = limiter.Acquire(cancelled)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("releases the slot when fn fails", func(t *testing.T) {
+		limiter := NewLimiter(LimitBulk, 1, 0)
+		failure := errors.New("boom")
+		assert.ErrorIs(t, limiter.Do(ctx, func(context.Context) error { return failure }), failure)
+		assert.Equal(t, 0, limiter.Status().InUse)
+	})
+}
+
+func TestRegistry_LimitStatuses(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterLimit(LimitScan, 2, time.Second)
+	registry.RegisterLimit(LimitBulk, 1, time.Second)
+	assert.Nil(t, registry.RegisterLimit(LimitReindex, 0, time.Second))
+
+	assert.Equal(t, []LimitStatus{
+		{Name: LimitBulk, Limit: 1},
+		{Name: LimitScan, Limit: 2},
+	}, registry.LimitStatuses())
+
+	assert.NotNil(t, registry.Limiter(LimitScan))
+	assert.Nil(t, registry.Limiter(LimitReindex))
+
+	var nilRegistry *Registry
+	assert.NotNil(t, nilRegistry.RegisterLimit(LimitScan, 1, 0))
+	assert.Empty(t, nilRegistry.LimitStatuses())
+	assert.Nil(t, nilRegistry.Limiter(LimitScan))
+}
@@ -0,0 +1,228 @@
+// Package resilience is the retry and circuit-breaker layer shared by the
+// clients that call AWS (DynamoDB, S3 and the search Lambda). Each dependency
+// gets its own breaker, so a failing search Lambda does not stop table reads;
+// retries back off with full jitter and give up early when the caller's
+// deadline leaves no time for another attempt. Breaker state is reported on
+// GET /status.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the dependency while its
+// circuit breaker is open after repeated failures.
+var ErrCircuitOpen = errors.New("resilience: circuit breaker open")
+
+// Policy configures retries and circuit breaking for one dependency.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, the first included
+	// (values below 1 mean 1)
+	MaxAttempts int
+	// BaseDelay is the backoff cap of the first retry; it doubles per retry
+	// up to MaxDelay, and each delay is drawn uniformly below its cap
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MinAttemptTime is the least time that must be left before the context
+	// deadline, after the backoff, for a retry to start
+	MinAttemptTime time.Duration
+	// BreakerThreshold consecutive failures open the breaker for
+	// BreakerCooldown (0 disables the breaker)
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Retryable reports whether a failed attempt may be retried (nil retries
+	// nothing)
+	Retryable func(error) bool
+	// Failure reports whether an error counts against the breaker (nil counts
+	// every error)
+	Failure func(error) bool
+}
+
+// Dependency applies a Policy to the calls made to one external dependency.
+// A nil Dependency calls straight through.
+type Dependency struct {
+	name    string
+	policy  Policy
+	breaker *Breaker
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewDependency creates a dependency named for status reports
+func NewDependency(name string, policy Policy) *Dependency {
+	d := &Dependency{name: name, policy: policy, sleep: sleep}
+	if policy.BreakerThreshold > 0 {
+		d.breaker = NewBreaker(policy.BreakerThreshold, policy.BreakerCooldown)
+	}
+	return d
+}
+
+// Name returns the dependency name
+func (d *Dependency) Name() string {
+	return d.name
+}
+
+// Do calls fn, retrying retryable failures with backoff while the policy,
+// the breaker and the context deadline allow. It returns the last error.
+func (d *Dependency) Do(ctx context.Context, fn func(context.Context) error) error {
+	if d == nil {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if !d.allow() {
+			if err != nil {
+				return err
+			}
+			return ErrCircuitOpen
+		}
+		err = fn(ctx)
+		d.record(ctx, err)
+
+		if err == nil || ctx.Err() != nil || attempt >= d.policy.MaxAttempts || !d.retryable(err) {
+			return err
+		}
+		delay := d.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay+d.policy.MinAttemptTime {
+			return err
+		}
+		if d.sleep(ctx, delay) != nil {
+			return err
+		}
+	}
+}
+
+// Once calls fn a single time through the breaker, for calls that cannot be
+// repeated (e.g. uploads from a stream that has been consumed)
+func (d *Dependency) Once(ctx context.Context, fn func(context.Context) error) error {
+	if d == nil {
+		return fn(ctx)
+	}
+	if !d.allow() {
+		return ErrCircuitOpen
+	}
+	err := fn(ctx)
+	d.record(ctx, err)
+	return err
+}
+
+// Status is the breaker state of a dependency as reported on GET /status
+type Status struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	// ConsecutiveFailures is the current run of failed calls
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// RetryAt is when an open breaker lets the next trial call through
+	RetryAt *time.Time `json:"retryAt,omitempty"`
+}
+
+// Status reports the dependency's breaker; dependencies without one are
+// always closed
+func (d *Dependency) Status() Status {
+	status := Status{Name: d.name, State: StateClosed}
+	if d.breaker == nil {
+		return status
+	}
+	state, failures, retryAt := d.breaker.State()
+	status.State, status.ConsecutiveFailures = state, failures
+	if !retryAt.IsZero() {
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+func (d *Dependency) allow() bool {
+	return d.breaker == nil || d.breaker.Allow()
+}
+
+// record reports an attempt to the breaker; attempts the caller cancelled
+// say nothing about the dependency's health
+func (d *Dependency) record(ctx context.Context, err error) {
+	if d.breaker == nil {
+		return
+	}
+	if err != nil && ctx.Err() != nil {
+		d.breaker.Release()
+		return
+	}
+	d.breaker.Record(err != nil && (d.policy.Failure == nil || d.policy.Failure(err)))
+}
+
+func (d *Dependency) retryable(err error) bool {
+	return d.policy.Retryable != nil && d.policy.Retryable(err)
+}
+
+// backoff returns the full-jitter delay before retry number attempt
+func (d *Dependency) backoff(attempt int) time.Duration {
+	ceiling := d.policy.BaseDelay
+	for i := 1; i < attempt && ceiling < d.policy.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	if d.policy.MaxDelay > 0 && ceiling > d.policy.MaxDelay {
+		ceiling = d.policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Registry holds the dependencies and concurrency limiters of a binary for
+// status reports. It is safe for concurrent use; a nil Registry registers
+// nothing and reports no dependencies.
+type Registry struct {
+	mu     sync.RWMutex
+	deps   map[string]*Dependency
+	limits map[string]*Limiter
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{deps: make(map[string]*Dependency), limits: make(map[string]*Limiter)}
+}
+
+// Register creates a dependency with policy and records it under name
+func (r *Registry) Register(name string, policy Policy) *Dependency {
+	d := NewDependency(name, policy)
+	if r == nil {
+		return d
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deps[name] = d
+	return d
+}
+
+// Statuses returns the status of every registered dependency sorted by name
+func (r *Registry) Statuses() []Status {
+	statuses := []Status{}
+	if r == nil {
+		return statuses
+	}
+
+	r.mu.RLock()
+	for _, d := range r.deps {
+		statuses = append(statuses, d.Status())
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
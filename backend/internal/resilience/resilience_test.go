@@ -0,0 +1,178 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errThrottled = errors.New("throttled")
+
+// testDependency retries errThrottled without sleeping
+func testDependency(policy Policy) *Dependency {
+	if policy.Retryable == nil {
+		policy.Retryable = func(err error) bool { return errors.Is(err, errThrottled) }
+	}
+	d := NewDependency("test", policy)
+	d.sleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	return d
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBreaker(2, 30*time.Second)
+	b.now = func() time.Time { return now }
+
+	require.True(t, b.Allow())
+	b.Record(true)
+	require.True(t, b.Allow())
+	b.Record(true)
+	assert.False(t, b.Allow(), "opens after threshold failures")
+	state, failures, retryAt := b.State()
+	assert.Equal(t, StateOpen, state)
+	assert.Equal(t, 2, failures)
+	assert.Equal(t, now.Add(30*time.Second), retryAt)
+
+	now = now.Add(31 * time.Second)
+	require.True(t, b.Allow(), "trial call after the cooldown")
+	assert.False(t, b.Allow(), "one trial at a time")
+	b.Record(true)
+	assert.False(t, b.Allow(), "a failed trial reopens")
+
+	now = now.Add(31 * time.Second)
+	require.True(t, b.Allow())
+	b.Release()
+	require.True(t, b.Allow(), "a released trial lets the next call try")
+	b.Record(false)
+	state, _, _ = b.State()
+	assert.Equal(t, StateClosed, state)
+}
+
+func TestDependency_Do(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries retryable errors", func(t *testing.T) {
+		d := testDependency(Policy{MaxAttempts: 3})
+		calls := 0
+		err := d.Do(ctx, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return errThrottled
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("stops at max attempts and on other errors", func(t *testing.T) {
+		d := testDependency(Policy{MaxAttempts: 2})
+		calls := 0
+		err := d.Do(ctx, func(context.Context) error { calls++; return errThrottled })
+		assert.ErrorIs(t, err, errThrottled)
+		assert.Equal(t, 2, calls)
+
+		calls = 0
+		err = d.Do(ctx, func(context.Context) error { calls++; return errors.New("bad request") })
+		assert.EqualError(t, err, "bad request")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("does not retry past the deadline budget", func(t *testing.T) {
+		d := testDependency(Policy{MaxAttempts: 5, MinAttemptTime: time.Minute})
+		deadlineCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		calls := 0
+		err := d.Do(deadlineCtx, func(context.Context) error { calls++; return errThrottled })
+		assert.ErrorIs(t, err, errThrottled)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("open breaker fails fast", func(t *testing.T) {
+		d := testDependency(Policy{MaxAttempts: 1, BreakerThreshold: 1, BreakerCooldown: time.Minute})
+		require.Error(t, d.Do(ctx, func(context.Context) error { return errThrottled }))
+
+		called := false
+		err := d.Do(ctx, func(context.Context) error { called = true; return nil })
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.False(t, called)
+		assert.Equal(t, StateOpen, d.Status().State)
+		assert.NotNil(t, d.Status().RetryAt)
+	})
+
+	t.Run("caller errors and cancellations do not trip the breaker", func(t *testing.T) {
+		d := testDependency(Policy{
+			MaxAttempts:      1,
+			BreakerThreshold: 1,
+			BreakerCooldown:  time.Minute,
+			Failure:          func(err error) bool { return errors.Is(err, errThrottled) },
+		})
+		require.Error(t, d.Do(ctx, func(context.Context) error { return errors.New("not found") }))
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		require.Error(t, d.Do(cancelled, func(ctx context.Context) error { return ctx.Err() }))
+
+		assert.Equal(t, Status{Name: "test", State: StateClosed}, d.Status())
+	})
+
+	t.Run("nil dependency calls through", func(t *testing.T) {
+		var d *Dependency
+		calls := 0
+		assert.NoError(t, d.Do(ctx, func(context.Context) error { calls++; return nil }))
+		assert.NoError(t, d.Once(ctx, func(context.Context) error { calls++; return nil }))
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestDependency_Backoff(t *testing.T) {
+	d := NewDependency("test", Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond})
+	for i := 0; i < 50; i++ {
+		assert.Less(t, d.backoff(1), 100*time.Millisecond)
+		assert.Less(t, d.backoff(2), 200*time.Millisecond)
+		assert.Less(t, d.backoff(5), 300*time.Millisecond)
+	}
+}
+
+func TestRegistry_Statuses(t *testing.T) {
+	r := NewRegistry()
+	r.Register("s3", Policy{})
+	r.Register("dynamodb", Policy{BreakerThreshold: 5})
+
+	assert.Equal(t, []Status{
+		{Name: "dynamodb", State: StateClosed},
+		{Name: "s3", State: StateClosed},
+	}, r.Statuses())
+
+	var none *Registry
+	assert.Empty(t, none.Statuses())
+	assert.NotNil(t, none.Register("search", Policy{}))
+}
+
+func TestAWSErrors(t *testing.T) {
+	responseError := func(status int, err error) error {
+		return &awshttp.ResponseError{ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+			Err:      err,
+		}}
+	}
+	throttle := &smithy.GenericAPIError{Code: "ThrottlingException", Fault: smithy.FaultClient}
+	conditional := &smithy.GenericAPIError{Code: "ConditionalCheckFailedException", Fault: smithy.FaultClient}
+
+	assert.True(t, AWSRetryable(responseError(http.StatusBadRequest, throttle)))
+	assert.True(t, AWSFailure(responseError(http.StatusBadRequest, throttle)))
+	assert.True(t, AWSRetryable(responseError(http.StatusServiceUnavailable, errors.New("unavailable"))))
+
+	assert.False(t, AWSRetryable(responseError(http.StatusBadRequest, conditional)))
+	assert.False(t, AWSFailure(responseError(http.StatusBadRequest, conditional)))
+	assert.False(t, AWSFailure(conditional))
+	assert.False(t, AWSRetryable(context.Canceled))
+}
@@ -0,0 +1,202 @@
+// Package sanitize normalizes user-supplied file names and validates the
+// segments of S3 keys, so uploads, processors and download endpoints build
+// names and keys the same way.
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Length limits
+const (
+	MaxFileNameBytes  = 255 // Common file system limit for a single name
+	MaxExtensionBytes = 10  // Including the leading dot
+	MaxSegmentBytes   = 255 // One S3 key segment (IDs, prefixes, file names)
+	MaxKeyBytes       = 1024
+)
+
+// DefaultFileName replaces names that sanitize to nothing
+const DefaultFileName = "untitled"
+
+// ErrInvalidKey is returned for S3 key segments that could escape their prefix
+var ErrInvalidKey = errors.New("invalid key")
+
+// reservedChars are replaced in file names: path separators and the
+// characters Windows, macOS and Content-Disposition headers reject.
+const reservedChars = `<>:"/\|?*`
+
+// windowsReserved are device names that cannot be used as file stems
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// FileName makes a user-supplied name safe to store and download: NFC
+// normalized, without directories, control, invisible formatting or reserved
+// characters, with runs of whitespace collapsed and at most MaxFileNameBytes
+// long (the extension is kept when shortening).
+func FileName(name string) string {
+	name = norm.NFC.String(strings.ToValidUTF8(name, ""))
+
+	// Only the last path element counts, whichever separator the client used
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	var b strings.Builder
+	space := false
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		if strings.ContainsRune(reservedChars, r) {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+
+	// Leading dots hide files (and ".." climbs); trailing dots are dropped by Windows
+	name = strings.Trim(b.String(), ". ")
+	if name == "" {
+		return DefaultFileName
+	}
+
+	stem, ext := splitExt(name)
+	if windowsReserved[strings.ToUpper(stem)] {
+		stem = "_" + stem
+	}
+	if len(stem)+len(ext) > MaxFileNameBytes {
+		stem = strings.TrimRight(truncate(stem, MaxFileNameBytes-len(ext)), ". ")
+	}
+	return stem + ext
+}
+
+// Extension returns the lowercased extension of a file name or key, including
+// the dot, or "" when there is none or it is not a short run of letters and
+// digits.
+func Extension(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	i := strings.LastIndexByte(name, '.')
+	if i <= 0 {
+		return ""
+	}
+	ext := strings.ToLower(name[i:])
+	if len(ext) < 2 || len(ext) > MaxExtensionBytes {
+		return ""
+	}
+	for _, c := range ext[1:] {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return ""
+		}
+	}
+	return ext
+}
+
+// KeySegment checks that s can be used as one element of an S3 key: not
+// empty, not "." or "..", and free of separators and control characters.
+func KeySegment(s string) error {
+	switch {
+	case s == "":
+		return fmt.Errorf("%w: empty segment", ErrInvalidKey)
+	case s == "." || s == "..":
+		return fmt.Errorf("%w: relative segment %q", ErrInvalidKey, s)
+	case len(s) > MaxSegmentBytes:
+		return fmt.Errorf("%w: segment longer than %d bytes", ErrInvalidKey, MaxSegmentBytes)
+	case !utf8.ValidString(s):
+		return fmt.Errorf("%w: segment is not valid UTF-8", ErrInvalidKey)
+	}
+	for _, r := range s {
+		if r == '/' || r == '\\' || unicode.IsControl(r) {
+			return fmt.Errorf("%w: segment %q contains %q", ErrInvalidKey, s, r)
+		}
+	}
+	return nil
+}
+
+// Key joins validated segments with "/". Segments are not rewritten, so IDs
+// and file names should already be sanitized; anything that could escape the
+// intended prefix is an error.
+func Key(segments ...string) (string, error) {
+	for _, segment := range segments {
+		if err := KeySegment(segment); err != nil {
+			return "", err
+		}
+	}
+	key := strings.Join(segments, "/")
+	if len(key) > MaxKeyBytes {
+		return "", fmt.Errorf("%w: key longer than %d bytes", ErrInvalidKey, MaxKeyBytes)
+	}
+	return key, nil
+}
+
+// UniqueFileName returns name, or name with " (2)", " (3)", ... before the
+// extension, whichever taken reports free first. Comparisons are up to the
+// caller, e.g. case-insensitive for names that end up on a user's disk.
+func UniqueFileName(name string, taken func(string) bool) string {
+	if !taken(name) {
+		return name
+	}
+	stem, ext := splitExt(name)
+	for n := 2; ; n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		candidate := truncate(stem, MaxFileNameBytes-len(ext)-len(suffix)) + suffix + ext
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// ContentDisposition returns an attachment Content-Disposition value for a
+// download: an ASCII fallback name for old clients and the UTF-8 name as
+// RFC 5987 filename*.
+func ContentDisposition(name string) string {
+	name = FileName(name)
+
+	fallback := make([]byte, 0, len(name))
+	for _, r := range name {
+		if r < 0x20 || r > 0x7e || r == '%' {
+			fallback = append(fallback, '_')
+			continue
+		}
+		fallback = append(fallback, byte(r))
+	}
+
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback, strings.ReplaceAll(url.QueryEscape(name), "+", "%20"))
+}
+
+// splitExt splits a name into stem and Extension; names whose extension is
+// not recognized are all stem.
+func splitExt(name string) (string, string) {
+	ext := Extension(name)
+	return name[:len(name)-len(ext)], name[len(name)-len(ext):]
+}
+
+// truncate shortens s to at most n bytes without splitting a character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
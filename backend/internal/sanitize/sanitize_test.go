@@ -0,0 +1,95 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "Burial - Archangel.mp3", "Burial - Archangel.mp3"},
+		{"nfc", "Bjo\u0308rk - Joga.flac", "Bj\u00f6rk - Joga.flac"},
+		{"unix traversal", "../../etc/passwd", "passwd"},
+		{"windows path", `C:\Users\me\Music\track.wav`, "track.wav"},
+		{"reserved characters", `What? "Live" <edit>*.mp3`, "What_ _Live_ _edit__.mp3"},
+		{"control and invisible", "Track\x00\u202e1\u200b.mp3", "Track1.mp3"},
+		{"whitespace runs", "  Side\tA \n  Intro .mp3", "Side A Intro .mp3"},
+		{"dot names", "..", DefaultFileName},
+		{"hidden file", ".htaccess", "htaccess"},
+		{"trailing dots", "mix...", "mix"},
+		{"windows device", "con.mp3", "_con.mp3"},
+		{"invalid utf-8", "a\xffb.mp3", "ab.mp3"},
+		{"empty", "", DefaultFileName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FileName(tt.input))
+		})
+	}
+}
+
+func TestFileName_Length(t *testing.T) {
+	name := FileName(strings.Repeat("é", 200) + ".flac")
+	assert.LessOrEqual(t, len(name), MaxFileNameBytes)
+	assert.True(t, strings.HasSuffix(name, ".flac"), "the extension survives truncation")
+	assert.Equal(t, name, FileName(name), "sanitizing is idempotent")
+}
+
+func TestExtension(t *testing.T) {
+	assert.Equal(t, ".mp3", Extension("uploads/u1/x/Song.MP3"))
+	assert.Equal(t, ".flac", Extension(`dir\song.flac`))
+	assert.Empty(t, Extension("README"))
+	assert.Empty(t, Extension(".hidden"))
+	assert.Empty(t, Extension("song.mp3?x=1"))
+	assert.Empty(t, Extension("song.averyverylongext"))
+	assert.Empty(t, Extension("media/u1.d/track"), "dots in directories are not extensions")
+}
+
+func TestKey(t *testing.T) {
+	key, err := Key("media", "u1", "t1.mp3")
+	require.NoError(t, err)
+	assert.Equal(t, "media/u1/t1.mp3", key)
+
+	for _, segments := range [][]string{
+		{"hls", "", "t1"},
+		{"hls", "..", "t1"},
+		{"covers", "u1/../u2", "c.jpg"},
+		{"covers", `u1\u2`, "c.jpg"},
+		{"covers", "u1", "c\n.jpg"},
+		{"media", strings.Repeat("a", MaxSegmentBytes+1)},
+	} {
+		_, err := Key(segments...)
+		assert.ErrorIs(t, err, ErrInvalidKey, "%q", segments)
+	}
+}
+
+func TestUniqueFileName(t *testing.T) {
+	taken := map[string]bool{"mix.mp3": true, "mix (2).mp3": true}
+	isTaken := func(name string) bool { return taken[strings.ToLower(name)] }
+
+	assert.Equal(t, "other.mp3", UniqueFileName("other.mp3", isTaken))
+	assert.Equal(t, "Mix (3).mp3", UniqueFileName("Mix.mp3", isTaken))
+
+	long := strings.Repeat("a", MaxFileNameBytes-4) + ".mp3"
+	taken[long] = true
+	unique := UniqueFileName(long, isTaken)
+	assert.Len(t, unique, MaxFileNameBytes)
+	assert.True(t, strings.HasSuffix(unique, " (2).mp3"))
+}
+
+func TestContentDisposition(t *testing.T) {
+	assert.Equal(t,
+		`attachment; filename="Bj_rk - J_ga 100_.flac"; filename*=UTF-8''Bj%C3%B6rk%20-%20J%C3%B3ga%20100%25.flac`,
+		ContentDisposition("Björk - Jóga 100%.flac"))
+	assert.Equal(t,
+		`attachment; filename="_Quoted_.mp3"; filename*=UTF-8''_Quoted_.mp3`,
+		ContentDisposition(`"Quoted".mp3`))
+}
@@ -0,0 +1,149 @@
+// Package scan checks uploaded files for malware before the upload pipeline
+// parses or publishes them. Scanners are pluggable: ClamScan runs clamscan
+// from a ClamAV Lambda layer, HTTPScanner calls an external scanning service.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// Verdict is the outcome of scanning one file
+type Verdict struct {
+	Clean bool `json:"clean"`
+	// Signature names the detected threat when the file is not clean
+	Signature string `json:"signature,omitempty"`
+	// Engine names the scanner that produced the verdict
+	Engine string `json:"engine,omitempty"`
+}
+
+// Reason describes a rejected file for the uploader
+func (v Verdict) Reason() string {
+	if v.Clean {
+		return ""
+	}
+	if v.Signature == "" {
+		return "file rejected by malware scan"
+	}
+	return fmt.Sprintf("file rejected by malware scan: %s detected", v.Signature)
+}
+
+// Scanner inspects a file's content; name is informational (logs, reports)
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader, name string) (Verdict, error)
+}
+
+// Engine names
+const (
+	EngineClamAV = "clamav"
+	EngineHTTP   = "http"
+)
+
+// ClamScan scans with the clamscan binary, reading the file from stdin
+type ClamScan struct {
+	binary      string
+	databaseDir string
+}
+
+// NewClamScan scans with binary (e.g. /opt/bin/clamscan from the layer)
+// using the signature database in databaseDir, or clamscan's default when empty
+func NewClamScan(binary, databaseDir string) *ClamScan {
+	return &ClamScan{binary: binary, databaseDir: databaseDir}
+}
+
+// Scan runs clamscan; exit code 1 means a signature matched
+func (s *ClamScan) Scan(ctx context.Context, r io.Reader, name string) (Verdict, error) {
+	args := []string{"--no-summary", "--stdout"}
+	if s.databaseDir != "" {
+		args = append(args, "--database="+s.databaseDir)
+	}
+	args = append(args, "-")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.binary, args...)
+	cmd.Stdin = r
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return Verdict{Clean: true, Engine: EngineClamAV}, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return Verdict{Signature: parseClamSignature(stdout.String()), Engine: EngineClamAV}, nil
+	default:
+		return Verdict{}, fmt.Errorf("clamscan failed on %s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+}
+
+// parseClamSignature reads the signature from a "stdin: Eicar-Signature FOUND" line
+func parseClamSignature(output string) string {
+	lines := bufio.NewScanner(strings.NewReader(output))
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if !strings.HasSuffix(line, " FOUND") {
+			continue
+		}
+		line = strings.TrimSuffix(line, " FOUND")
+		if _, signature, ok := strings.Cut(line, ": "); ok {
+			return signature
+		}
+		return line
+	}
+	return ""
+}
+
+// HTTPScanner posts files to an external scanning service, which answers
+// with a JSON Verdict ({"clean": false, "signature": "..."})
+type HTTPScanner struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPScanner scans through the service at url; client may be nil, or
+// carry auth in its Transport
+func NewHTTPScanner(url string, client *http.Client) *HTTPScanner {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPScanner{url: url, client: client}
+}
+
+// Scan streams the file to the service
+func (s *HTTPScanner) Scan(ctx context.Context, r io.Reader, name string) (Verdict, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, r)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to create scan request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-File-Name", name)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("scan request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Verdict{}, fmt.Errorf("scanner returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var verdict Verdict
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return Verdict{}, fmt.Errorf("failed to parse scan verdict: %w", err)
+	}
+	if verdict.Engine == "" {
+		verdict.Engine = EngineHTTP
+	}
+	return verdict, nil
+}
@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamScan writes a clamscan stand-in that flags stdin containing EICAR
+func fakeClamScan(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "clamscan")
+	script := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *EICAR*) echo "stdin: Eicar-Signature FOUND"; exit 1 ;;
+  *BROKEN*) echo "LibClamAV Error: database not found" >&2; exit 2 ;;
+esac
+echo "stdin: OK"
+`
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+func TestClamScan(t *testing.T) {
+	scanner := NewClamScan(fakeClamScan(t), "")
+	ctx := context.Background()
+
+	verdict, err := scanner.Scan(ctx, strings.NewReader("ID3 audio"), "song.mp3")
+	require.NoError(t, err)
+	assert.True(t, verdict.Clean)
+	assert.Empty(t, verdict.Reason())
+
+	verdict, err = scanner.Scan(ctx, strings.NewReader("X5O EICAR test"), "song.mp3")
+	require.NoError(t, err)
+	assert.False(t, verdict.Clean)
+	assert.Equal(t, "Eicar-Signature", verdict.Signature)
+	assert.Equal(t, EngineClamAV, verdict.Engine)
+	assert.Equal(t, "file rejected by malware scan: Eicar-Signature detected", verdict.Reason())
+
+	_, err = scanner.Scan(ctx, strings.NewReader("BROKEN"), "song.mp3")
+	assert.ErrorContains(t, err, "database not found", "scanner errors are not verdicts")
+}
+
+func TestParseClamSignature(t *testing.T) {
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", parseClamSignature("stdin: Win.Test.EICAR_HDB-1 FOUND\n"))
+	assert.Empty(t, parseClamSignature("stdin: OK\n"))
+}
+
+func TestHTTPScanner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "song.mp3", r.Header.Get("X-File-Name"))
+		if strings.Contains(string(body), "fail") {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Verdict{Clean: !strings.Contains(string(body), "EICAR"), Signature: "EICAR"})
+	}))
+	defer server.Close()
+
+	scanner := NewHTTPScanner(server.URL, nil)
+	ctx := context.Background()
+
+	verdict, err := scanner.Scan(ctx, strings.NewReader("X5O EICAR"), "song.mp3")
+	require.NoError(t, err)
+	assert.False(t, verdict.Clean)
+	assert.Equal(t, EngineHTTP, verdict.Engine)
+
+	verdict, err = scanner.Scan(ctx, strings.NewReader("audio"), "song.mp3")
+	require.NoError(t, err)
+	assert.True(t, verdict.Clean)
+
+	_, err = scanner.Scan(ctx, strings.NewReader("fail"), "song.mp3")
+	assert.ErrorContains(t, err, "503")
+}
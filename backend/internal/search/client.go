@@ -1,11 +1,18 @@
+// Package search provides the Nixiesearch client and search functionality.
+// The request and response types are the wire contract in internal/searchproto.
 package search
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
 )
 
 // LambdaInvoker defines the interface for invoking Lambda functions.
@@ -13,10 +20,28 @@ type LambdaInvoker interface {
 	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
 }
 
+// Observer is told the duration and outcome of every search Lambda call.
+type Observer func(operation string, d time.Duration, err error)
+
+// ErrTimeout is returned when a Lambda call outlives the client timeout.
+var ErrTimeout = errors.New("search: lambda call timed out")
+
+// TransferTimeout bounds export and import calls, which move the index to
+// and from S3, when it is longer than the client timeout. It stays under the
+// Lambda's own 30s limit.
+const TransferTimeout = 25 * time.Second
+
+// ErrCircuitOpen is returned without calling the Lambda while the circuit
+// breaker is open after repeated failures.
+var ErrCircuitOpen = resilience.ErrCircuitOpen
+
 // Client provides search operations via Nixiesearch Lambda.
 type Client struct {
 	lambdaClient LambdaInvoker
 	functionName string
+	observer     Observer
+	timeout      time.Duration
+	dependency   *resilience.Dependency
 }
 
 // NewClient creates a new search client.
@@ -27,107 +52,172 @@ func NewClient(lambdaClient LambdaInvoker, functionName string) *Client {
 	}
 }
 
+// SetObserver reports every call to observer, e.g. for metrics.
+func (c *Client) SetObserver(observer Observer) {
+	c.observer = observer
+}
+
+// SetTimeout bounds every Lambda call to d (0, the default, leaves calls
+// bounded only by the caller's context). Calls that run out of time fail
+// with ErrTimeout.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.timeout = d
+}
+
+// SetDependency retries and circuit-breaks Lambda calls with dependency's
+// policy; each attempt gets its own timeout. An open breaker fails calls
+// with ErrCircuitOpen.
+func (c *Client) SetDependency(dependency *resilience.Dependency) {
+	c.dependency = dependency
+}
+
 // Search executes a search query and returns results.
-func (c *Client) Search(ctx context.Context, userID string, query SearchQuery) (*SearchResponse, error) {
+func (c *Client) Search(ctx context.Context, userID string, query searchproto.SearchQuery) (*searchproto.SearchResponse, error) {
 	// Add user filter to scope results
 	query.Filters.UserID = userID
 
-	req := NixiesearchRequest{
-		Operation: "search",
-		Payload:   query,
-	}
-
-	resp, err := c.invoke(ctx, req)
-	if err != nil {
+	var searchResp searchproto.SearchResponse
+	if err := c.call(ctx, searchproto.OpSearch, query, &searchResp); err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
+	return &searchResp, nil
+}
 
-	var searchResp SearchResponse
-	data, err := json.Marshal(resp.Data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+// Index adds or updates a document in the search index.
+func (c *Client) Index(ctx context.Context, doc searchproto.Document) (*searchproto.IndexResponse, error) {
+	var indexResp searchproto.IndexResponse
+	if err := c.call(ctx, searchproto.OpIndex, searchproto.IndexRequest{Document: doc}, &indexResp); err != nil {
+		return nil, fmt.Errorf("index failed: %w", err)
 	}
-	if err := json.Unmarshal(data, &searchResp); err != nil {
-		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	return &indexResp, nil
+}
+
+// Delete removes a document from the search index.
+func (c *Client) Delete(ctx context.Context, docID string) (*searchproto.DeleteResponse, error) {
+	var deleteResp searchproto.DeleteResponse
+	if err := c.call(ctx, searchproto.OpDelete, searchproto.DeleteRequest{ID: docID}, &deleteResp); err != nil {
+		return nil, fmt.Errorf("delete failed: %w", err)
 	}
+	return &deleteResp, nil
+}
 
-	return &searchResp, nil
+// BulkIndex adds multiple documents to the search index.
+func (c *Client) BulkIndex(ctx context.Context, docs []searchproto.Document) (*searchproto.BulkIndexResponse, error) {
+	var bulkResp searchproto.BulkIndexResponse
+	if err := c.call(ctx, searchproto.OpBulkIndex, searchproto.BulkIndexRequest{Documents: docs}, &bulkResp); err != nil {
+		return nil, fmt.Errorf("bulk index failed: %w", err)
+	}
+	return &bulkResp, nil
 }
 
-// Index adds or updates a document in the search index.
-func (c *Client) Index(ctx context.Context, doc Document) (*IndexResponse, error) {
-	req := NixiesearchRequest{
-		Operation: "index",
-		Payload:   IndexRequest{Document: doc},
+// UpdateStatus changes the processing state of an indexed document.
+func (c *Client) UpdateStatus(ctx context.Context, update searchproto.StatusUpdate) (*searchproto.StatusUpdateResponse, error) {
+	var statusResp searchproto.StatusUpdateResponse
+	if err := c.call(ctx, searchproto.OpUpdateStatus, update, &statusResp); err != nil {
+		return nil, fmt.Errorf("status update failed: %w", err)
 	}
+	return &statusResp, nil
+}
 
-	resp, err := c.invoke(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("index failed: %w", err)
+// Stats reports the size of the index held by the Lambda.
+func (c *Client) Stats(ctx context.Context) (*searchproto.StatsResponse, error) {
+	var statsResp searchproto.StatsResponse
+	if err := c.call(ctx, searchproto.OpStats, searchproto.StatsRequest{}, &statsResp); err != nil {
+		return nil, fmt.Errorf("index stats failed: %w", err)
 	}
+	return &statsResp, nil
+}
 
-	var indexResp IndexResponse
-	data, err := json.Marshal(resp.Data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+// Get reads one document as the index holds it.
+func (c *Client) Get(ctx context.Context, docID string) (*searchproto.GetResponse, error) {
+	var getResp searchproto.GetResponse
+	if err := c.call(ctx, searchproto.OpGet, searchproto.GetRequest{ID: docID}, &getResp); err != nil {
+		return nil, fmt.Errorf("get document failed: %w", err)
 	}
-	if err := json.Unmarshal(data, &indexResp); err != nil {
-		return nil, fmt.Errorf("failed to parse index response: %w", err)
+	return &getResp, nil
+}
+
+// ExportIndex writes the index, or userID's documents when userID is set, to
+// a new export file in the index bucket.
+func (c *Client) ExportIndex(ctx context.Context, userID string) (*searchproto.ExportResponse, error) {
+	var exportResp searchproto.ExportResponse
+	if err := c.call(ctx, searchproto.OpExport, searchproto.ExportRequest{UserID: userID}, &exportResp); err != nil {
+		return nil, fmt.Errorf("index export failed: %w", err)
 	}
+	return &exportResp, nil
+}
 
-	return &indexResp, nil
+// ImportIndex loads an export file from the index bucket into the index.
+func (c *Client) ImportIndex(ctx context.Context, req searchproto.ImportRequest) (*searchproto.ImportResponse, error) {
+	var importResp searchproto.ImportResponse
+	if err := c.call(ctx, searchproto.OpImport, req, &importResp); err != nil {
+		return nil, fmt.Errorf("index import failed: %w", err)
+	}
+	return &importResp, nil
 }
 
-// Delete removes a document from the search index.
-func (c *Client) Delete(ctx context.Context, docID string) (*DeleteResponse, error) {
-	req := NixiesearchRequest{
-		Operation: "delete",
-		Payload:   DeleteRequest{ID: docID},
+// call invokes op with payload and decodes the response data into result.
+func (c *Client) call(ctx context.Context, op searchproto.Operation, payload, result interface{}) (err error) {
+	if c.observer != nil {
+		start := time.Now()
+		defer func() { c.observer(string(op), time.Since(start), err) }()
 	}
 
-	resp, err := c.invoke(ctx, req)
+	req, err := searchproto.NewRequest(op, payload)
 	if err != nil {
-		return nil, fmt.Errorf("delete failed: %w", err)
+		return err
 	}
 
-	var deleteResp DeleteResponse
-	data, err := json.Marshal(resp.Data)
+	var resp *searchproto.Response
+	err = c.dependency.Do(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.invokeWithTimeout(ctx, req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+		return err
 	}
-	if err := json.Unmarshal(data, &deleteResp); err != nil {
-		return nil, fmt.Errorf("failed to parse delete response: %w", err)
-	}
-
-	return &deleteResp, nil
+	return resp.Decode(result)
 }
 
-// BulkIndex adds multiple documents to the search index.
-func (c *Client) BulkIndex(ctx context.Context, docs []Document) (*BulkIndexResponse, error) {
-	req := NixiesearchRequest{
-		Operation: "bulk_index",
-		Payload:   BulkIndexRequest{Documents: docs},
+// Failure reports whether a search error counts against the circuit breaker.
+// Documents the Lambda rejected as malformed do not, since the Lambda itself
+// answered. Use it as resilience.Policy.Failure for the search dependency.
+func Failure(err error) bool {
+	var docErr *searchproto.DocumentError
+	if errors.As(err, &docErr) {
+		return false
 	}
+	return resilience.AWSFailure(err)
+}
 
-	resp, err := c.invoke(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("bulk index failed: %w", err)
+// invokeWithTimeout calls invoke under the client timeout, if any.
+func (c *Client) invokeWithTimeout(ctx context.Context, req searchproto.Request) (*searchproto.Response, error) {
+	timeout := c.timeoutFor(req.Operation)
+	if timeout <= 0 {
+		return c.invoke(ctx, req)
 	}
 
-	var bulkResp BulkIndexResponse
-	data, err := json.Marshal(resp.Data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
-	}
-	if err := json.Unmarshal(data, &bulkResp); err != nil {
-		return nil, fmt.Errorf("failed to parse bulk index response: %w", err)
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := c.invoke(callCtx, req)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w after %s", ErrTimeout, timeout)
 	}
+	return resp, err
+}
 
-	return &bulkResp, nil
+// timeoutFor returns the timeout of op: the client timeout, raised to
+// TransferTimeout for exports and imports.
+func (c *Client) timeoutFor(op searchproto.Operation) time.Duration {
+	if c.timeout > 0 && c.timeout < TransferTimeout && (op == searchproto.OpExport || op == searchproto.OpImport) {
+		return TransferTimeout
+	}
+	return c.timeout
 }
 
 // invoke calls the Nixiesearch Lambda function.
-func (c *Client) invoke(ctx context.Context, req NixiesearchRequest) (*NixiesearchResponse, error) {
+func (c *Client) invoke(ctx context.Context, req searchproto.Request) (*searchproto.Response, error) {
 	payload, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -145,12 +235,15 @@ func (c *Client) invoke(ctx context.Context, req NixiesearchRequest) (*Nixiesear
 		return nil, fmt.Errorf("lambda function error: %s", *result.FunctionError)
 	}
 
-	var resp NixiesearchResponse
+	var resp searchproto.Response
 	if err := json.Unmarshal(result.Payload, &resp); err != nil {
 		return nil, fmt.Errorf("failed to parse lambda response: %w", err)
 	}
 
 	if !resp.Success {
+		if resp.Validation != nil {
+			return nil, fmt.Errorf("search operation failed: %w", resp.Validation)
+		}
 		return nil, fmt.Errorf("search operation failed: %s", resp.Error)
 	}
 
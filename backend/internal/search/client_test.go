@@ -3,12 +3,18 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
 )
 
 // mockLambdaClient implements LambdaInvoker for testing
@@ -27,18 +33,24 @@ func (m *mockLambdaClient) Invoke(ctx context.Context, params *lambda.InvokeInpu
 	return m.response, m.err
 }
 
+// successPayload encodes a successful Lambda response carrying data
+func successPayload(t *testing.T, data interface{}) []byte {
+	t.Helper()
+	resp, err := searchproto.NewResponse(data)
+	require.NoError(t, err)
+	payload, err := json.Marshal(resp)
+	require.NoError(t, err)
+	return payload
+}
+
 func TestSearch_SimpleQuery(t *testing.T) {
-	mockResp := NixiesearchResponse{
-		Success: true,
-		Data: SearchResponse{
-			Results: []SearchResult{
-				{ID: "track-1", Title: "Test Song", Artist: "Test Artist", Score: 0.95},
-				{ID: "track-2", Title: "Another Song", Artist: "Test Artist", Score: 0.85},
-			},
-			Total: 2,
+	payload := successPayload(t, searchproto.SearchResponse{
+		Results: []searchproto.SearchResult{
+			{ID: "track-1", Title: "Test Song", Artist: "Test Artist", Score: 0.95},
+			{ID: "track-2", Title: "Another Song", Artist: "Test Artist", Score: 0.85},
 		},
-	}
-	payload, _ := json.Marshal(mockResp)
+		Total: 2,
+	})
 
 	mockClient := &mockLambdaClient{
 		response: &lambda.InvokeOutput{
@@ -47,7 +59,7 @@ func TestSearch_SimpleQuery(t *testing.T) {
 	}
 
 	client := NewClient(mockClient, "nixiesearch-lambda")
-	resp, err := client.Search(context.Background(), "user-123", SearchQuery{
+	resp, err := client.Search(context.Background(), "user-123", searchproto.SearchQuery{
 		Query: "test",
 		Limit: 20,
 	})
@@ -59,16 +71,12 @@ func TestSearch_SimpleQuery(t *testing.T) {
 }
 
 func TestSearch_WithFilters(t *testing.T) {
-	mockResp := NixiesearchResponse{
-		Success: true,
-		Data: SearchResponse{
-			Results: []SearchResult{
-				{ID: "track-1", Title: "Rock Song", Artist: "Rock Band", Genre: "Rock", Score: 0.9},
-			},
-			Total: 1,
+	payload := successPayload(t, searchproto.SearchResponse{
+		Results: []searchproto.SearchResult{
+			{ID: "track-1", Title: "Rock Song", Artist: "Rock Band", Genre: "Rock", Score: 0.9},
 		},
-	}
-	payload, _ := json.Marshal(mockResp)
+		Total: 1,
+	})
 
 	mockClient := &mockLambdaClient{
 		response: &lambda.InvokeOutput{
@@ -77,9 +85,9 @@ func TestSearch_WithFilters(t *testing.T) {
 	}
 
 	client := NewClient(mockClient, "nixiesearch-lambda")
-	resp, err := client.Search(context.Background(), "user-123", SearchQuery{
+	resp, err := client.Search(context.Background(), "user-123", searchproto.SearchQuery{
 		Query: "rock",
-		Filters: SearchFilters{
+		Filters: searchproto.SearchFilters{
 			Genre:    "Rock",
 			YearFrom: 2020,
 			YearTo:   2024,
@@ -91,27 +99,21 @@ func TestSearch_WithFilters(t *testing.T) {
 	assert.Equal(t, "Rock", resp.Results[0].Genre)
 
 	// Verify user filter was added
-	var req NixiesearchRequest
+	var req searchproto.Request
 	err = json.Unmarshal(mockClient.lastInput.Payload, &req)
 	require.NoError(t, err)
-	queryPayload, err := json.Marshal(req.Payload)
-	require.NoError(t, err)
-	var query SearchQuery
-	err = json.Unmarshal(queryPayload, &query)
-	require.NoError(t, err)
+	assert.Equal(t, searchproto.OpSearch, req.Operation)
+	var query searchproto.SearchQuery
+	require.NoError(t, req.Decode(&query))
 	assert.Equal(t, "user-123", query.Filters.UserID)
 }
 
 func TestSearch_Pagination(t *testing.T) {
-	mockResp := NixiesearchResponse{
-		Success: true,
-		Data: SearchResponse{
-			Results:    []SearchResult{{ID: "track-21", Title: "Song 21", Score: 0.5}},
-			Total:      50,
-			NextCursor: "cursor-page-3",
-		},
-	}
-	payload, _ := json.Marshal(mockResp)
+	payload := successPayload(t, searchproto.SearchResponse{
+		Results:    []searchproto.SearchResult{{ID: "track-21", Title: "Song 21", Score: 0.5}},
+		Total:      50,
+		NextCursor: "cursor-page-3",
+	})
 
 	mockClient := &mockLambdaClient{
 		response: &lambda.InvokeOutput{
@@ -120,7 +122,7 @@ func TestSearch_Pagination(t *testing.T) {
 	}
 
 	client := NewClient(mockClient, "nixiesearch-lambda")
-	resp, err := client.Search(context.Background(), "user-123", SearchQuery{
+	resp, err := client.Search(context.Background(), "user-123", searchproto.SearchQuery{
 		Query:  "song",
 		Limit:  20,
 		Cursor: "cursor-page-2",
@@ -132,14 +134,10 @@ func TestSearch_Pagination(t *testing.T) {
 }
 
 func TestIndex_NewDocument(t *testing.T) {
-	mockResp := NixiesearchResponse{
-		Success: true,
-		Data: IndexResponse{
-			ID:      "track-new",
-			Indexed: true,
-		},
-	}
-	payload, _ := json.Marshal(mockResp)
+	payload := successPayload(t, searchproto.IndexResponse{
+		ID:      "track-new",
+		Indexed: true,
+	})
 
 	mockClient := &mockLambdaClient{
 		response: &lambda.InvokeOutput{
@@ -148,7 +146,7 @@ func TestIndex_NewDocument(t *testing.T) {
 	}
 
 	client := NewClient(mockClient, "nixiesearch-lambda")
-	resp, err := client.Index(context.Background(), Document{
+	resp, err := client.Index(context.Background(), searchproto.Document{
 		ID:     "track-new",
 		UserID: "user-123",
 		Title:  "New Song",
@@ -161,14 +159,10 @@ func TestIndex_NewDocument(t *testing.T) {
 }
 
 func TestDelete_RemovesFromIndex(t *testing.T) {
-	mockResp := NixiesearchResponse{
-		Success: true,
-		Data: DeleteResponse{
-			ID:      "track-delete",
-			Deleted: true,
-		},
-	}
-	payload, _ := json.Marshal(mockResp)
+	payload := successPayload(t, searchproto.DeleteResponse{
+		ID:      "track-delete",
+		Deleted: true,
+	})
 
 	mockClient := &mockLambdaClient{
 		response: &lambda.InvokeOutput{
@@ -183,15 +177,86 @@ func TestDelete_RemovesFromIndex(t *testing.T) {
 	assert.True(t, resp.Deleted)
 }
 
-func TestBulkIndex_Success(t *testing.T) {
-	mockResp := NixiesearchResponse{
-		Success: true,
-		Data: BulkIndexResponse{
-			Indexed: 5,
-			Failed:  0,
+func TestStats(t *testing.T) {
+	payload := successPayload(t, searchproto.StatsResponse{
+		Documents:  3,
+		IndexBytes: 2048,
+		Users:      []searchproto.UserDocumentCount{{UserID: "user-123", Documents: 3}},
+	})
+
+	mockClient := &mockLambdaClient{
+		response: &lambda.InvokeOutput{
+			Payload: payload,
+		},
+	}
+
+	client := NewClient(mockClient, "nixiesearch-lambda")
+	resp, err := client.Stats(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, resp.Documents)
+	assert.Equal(t, int64(2048), resp.IndexBytes)
+	assert.Len(t, resp.Users, 1)
+}
+
+func TestGet(t *testing.T) {
+	payload := successPayload(t, searchproto.GetResponse{
+		ID:       "track-1",
+		Found:    true,
+		Document: &searchproto.Document{ID: "track-1", UserID: "user-123", Title: "Test Song"},
+	})
+
+	mockClient := &mockLambdaClient{
+		response: &lambda.InvokeOutput{
+			Payload: payload,
+		},
+	}
+
+	client := NewClient(mockClient, "nixiesearch-lambda")
+	resp, err := client.Get(context.Background(), "track-1")
+
+	require.NoError(t, err)
+	assert.True(t, resp.Found)
+	require.NotNil(t, resp.Document)
+	assert.Equal(t, "Test Song", resp.Document.Title)
+
+	var sent searchproto.Request
+	require.NoError(t, json.Unmarshal(mockClient.lastInput.Payload, &sent))
+	assert.Equal(t, searchproto.OpGet, sent.Operation)
+}
+
+func TestExportIndex(t *testing.T) {
+	payload := successPayload(t, searchproto.ExportResponse{
+		Key:       "exports/20261016T093000Z-user-user-123.ndjson",
+		UserID:    "user-123",
+		Documents: 3,
+	})
+
+	mockClient := &mockLambdaClient{
+		response: &lambda.InvokeOutput{
+			Payload: payload,
 		},
 	}
-	payload, _ := json.Marshal(mockResp)
+
+	client := NewClient(mockClient, "nixiesearch-lambda")
+	resp, err := client.ExportIndex(context.Background(), "user-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, resp.Documents)
+
+	var sent searchproto.Request
+	require.NoError(t, json.Unmarshal(mockClient.lastInput.Payload, &sent))
+	assert.Equal(t, searchproto.OpExport, sent.Operation)
+	assert.JSONEq(t, `{"userId":"user-123"}`, string(sent.Payload))
+}
+
+func TestImportIndex(t *testing.T) {
+	payload := successPayload(t, searchproto.ImportResponse{
+		Key:      "exports/a.ndjson",
+		Imported: 2,
+		Failed:   1,
+		Failures: []searchproto.ImportFailure{{Line: 3, ID: "bad", Error: "id must be a UUID"}},
+	})
 
 	mockClient := &mockLambdaClient{
 		response: &lambda.InvokeOutput{
@@ -200,7 +265,75 @@ func TestBulkIndex_Success(t *testing.T) {
 	}
 
 	client := NewClient(mockClient, "nixiesearch-lambda")
-	docs := []Document{
+	resp, err := client.ImportIndex(context.Background(), searchproto.ImportRequest{Key: "exports/a.ndjson"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Imported)
+	assert.Len(t, resp.Failures, 1)
+}
+
+func TestTimeoutFor(t *testing.T) {
+	client := NewClient(&mockLambdaClient{}, "nixiesearch-lambda")
+	assert.Zero(t, client.timeoutFor(searchproto.OpExport), "no client timeout, none for transfers")
+
+	client.SetTimeout(5 * time.Second)
+	assert.Equal(t, 5*time.Second, client.timeoutFor(searchproto.OpSearch))
+	assert.Equal(t, TransferTimeout, client.timeoutFor(searchproto.OpExport))
+	assert.Equal(t, TransferTimeout, client.timeoutFor(searchproto.OpImport))
+
+	client.SetTimeout(time.Minute)
+	assert.Equal(t, time.Minute, client.timeoutFor(searchproto.OpImport))
+}
+
+func TestInProcessClient(t *testing.T) {
+	var received searchproto.Request
+	client := NewInProcessClient(func(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+		received = req
+		return searchproto.NewResponse(searchproto.GetResponse{ID: "track-1", Found: true})
+	})
+
+	resp, err := client.Get(context.Background(), "track-1")
+	require.NoError(t, err)
+	assert.True(t, resp.Found)
+	assert.Equal(t, searchproto.OpGet, received.Operation)
+
+	failing := NewInProcessClient(func(ctx context.Context, req searchproto.Request) (searchproto.Response, error) {
+		return searchproto.Response{}, errors.New("boom")
+	})
+	_, err = failing.Get(context.Background(), "track-1")
+	assert.ErrorContains(t, err, "lambda function error")
+}
+
+func TestUpdateStatus(t *testing.T) {
+	payload := successPayload(t, searchproto.StatusUpdateResponse{ID: "track-1", Updated: true})
+
+	mockClient := &mockLambdaClient{
+		response: &lambda.InvokeOutput{
+			Payload: payload,
+		},
+	}
+
+	client := NewClient(mockClient, "nixiesearch-lambda")
+	resp, err := client.UpdateStatus(context.Background(), searchproto.StatusUpdate{ID: "track-1", HLSStatus: "READY"})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Updated)
+}
+
+func TestBulkIndex_Success(t *testing.T) {
+	payload := successPayload(t, searchproto.BulkIndexResponse{
+		Indexed: 5,
+		Failed:  0,
+	})
+
+	mockClient := &mockLambdaClient{
+		response: &lambda.InvokeOutput{
+			Payload: payload,
+		},
+	}
+
+	client := NewClient(mockClient, "nixiesearch-lambda")
+	docs := []searchproto.Document{
 		{ID: "track-1", Title: "Song 1"},
 		{ID: "track-2", Title: "Song 2"},
 		{ID: "track-3", Title: "Song 3"},
@@ -224,18 +357,14 @@ func TestSearch_LambdaError(t *testing.T) {
 	}
 
 	client := NewClient(mockClient, "nixiesearch-lambda")
-	_, err := client.Search(context.Background(), "user-123", SearchQuery{Query: "test"})
+	_, err := client.Search(context.Background(), "user-123", searchproto.SearchQuery{Query: "test"})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "lambda function error")
 }
 
 func TestSearch_OperationError(t *testing.T) {
-	mockResp := NixiesearchResponse{
-		Success: false,
-		Error:   "index not found",
-	}
-	payload, _ := json.Marshal(mockResp)
+	payload, _ := json.Marshal(searchproto.ErrorResponse("index not found"))
 
 	mockClient := &mockLambdaClient{
 		response: &lambda.InvokeOutput{
@@ -244,8 +373,93 @@ func TestSearch_OperationError(t *testing.T) {
 	}
 
 	client := NewClient(mockClient, "nixiesearch-lambda")
-	_, err := client.Search(context.Background(), "user-123", SearchQuery{Query: "test"})
+	_, err := client.Search(context.Background(), "user-123", searchproto.SearchQuery{Query: "test"})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "index not found")
 }
+
+func TestIndex_ValidationError(t *testing.T) {
+	docErr := &searchproto.DocumentError{Field: "id", Code: searchproto.CodeInvalidFormat, Message: "id must be a UUID"}
+	payload, _ := json.Marshal(searchproto.ValidationResponse(docErr))
+
+	mockClient := &mockLambdaClient{
+		response: &lambda.InvokeOutput{
+			Payload: payload,
+		},
+	}
+
+	client := NewClient(mockClient, "nixiesearch-lambda")
+	_, err := client.Index(context.Background(), searchproto.Document{ID: "track-new", UserID: "user-123"})
+
+	var got *searchproto.DocumentError
+	require.ErrorAs(t, err, &got)
+	assert.Equal(t, docErr, got)
+	assert.False(t, Failure(err), "a rejected document does not count against the breaker")
+	assert.True(t, Failure(errors.New("lambda invocation failed")))
+}
+
+func TestSearch_Timeout(t *testing.T) {
+	mockClient := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	client := NewClient(mockClient, "nixiesearch-lambda")
+	client.SetTimeout(10 * time.Millisecond)
+	_, err := client.Search(context.Background(), "user-123", searchproto.SearchQuery{Query: "test"})
+
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestSearch_CallerCancelIsNotTimeout(t *testing.T) {
+	mockClient := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	client := NewClient(mockClient, "nixiesearch-lambda")
+	client.SetTimeout(time.Minute)
+	dependency := resilience.NewDependency("search", resilience.Policy{MaxAttempts: 1, BreakerThreshold: 1, BreakerCooldown: time.Minute})
+	client.SetDependency(dependency)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Search(ctx, "user-123", searchproto.SearchQuery{Query: "test"})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, resilience.StateClosed, dependency.Status().State, "cancelled calls do not trip the breaker")
+}
+
+func TestSearch_Resilience(t *testing.T) {
+	calls := 0
+	mockClient := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+			calls++
+			return nil, &smithy.GenericAPIError{Code: "TooManyRequestsException", Fault: smithy.FaultClient}
+		},
+	}
+
+	client := NewClient(mockClient, "nixiesearch-lambda")
+	client.SetDependency(resilience.NewDependency("search", resilience.Policy{
+		MaxAttempts:      2,
+		Retryable:        resilience.AWSRetryable,
+		Failure:          resilience.AWSFailure,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	}))
+	search := func() error {
+		_, err := client.Search(context.Background(), "user-123", searchproto.SearchQuery{Query: "test"})
+		return err
+	}
+
+	assert.ErrorContains(t, search(), "TooManyRequestsException")
+	assert.Equal(t, 2, calls, "throttled calls are retried")
+
+	assert.ErrorIs(t, search(), ErrCircuitOpen)
+	assert.Equal(t, 2, calls, "open circuit fails without calling the Lambda")
+}
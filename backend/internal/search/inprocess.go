@@ -0,0 +1,52 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+)
+
+// Handler runs one search operation, as the Nixiesearch Lambda does.
+type Handler func(ctx context.Context, req searchproto.Request) (searchproto.Response, error)
+
+// InProcessFunctionName names the search function of clients built with
+// NewInProcessClient in metrics and logs.
+const InProcessFunctionName = "in-process"
+
+// inProcessInvoker implements LambdaInvoker by calling a handler in this
+// process. Requests and responses go through the same JSON encoding as a
+// Lambda invocation, and a handler error is reported as a function error.
+type inProcessInvoker struct {
+	handler Handler
+}
+
+// NewInProcessClient creates a search client that runs handler, e.g.
+// nixiesearch.HandleRequest, in this process instead of invoking a Lambda.
+func NewInProcessClient(handler Handler) *Client {
+	return NewClient(inProcessInvoker{handler: handler}, InProcessFunctionName)
+}
+
+// Invoke runs the handler with the request payload
+func (i inProcessInvoker) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	var req searchproto.Request
+	if err := json.Unmarshal(params.Payload, &req); err != nil {
+		return nil, fmt.Errorf("failed to decode search request: %w", err)
+	}
+
+	resp, err := i.handler(ctx, req)
+	if err != nil {
+		payload, _ := json.Marshal(map[string]string{"errorMessage": err.Error()})
+		return &lambda.InvokeOutput{StatusCode: 200, FunctionError: aws.String("Unhandled"), Payload: payload}, nil
+	}
+
+	payload, err := json.Marshal(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search response: %w", err)
+	}
+	return &lambda.InvokeOutput{StatusCode: 200, Payload: payload}, nil
+}
@@ -0,0 +1,165 @@
+package searchproto
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// OpExport writes the index, or one user's documents, to an export file
+	OpExport Operation = "export"
+	// OpImport loads the documents of an export file into the index
+	OpImport Operation = "import"
+)
+
+const (
+	// ExportFormat names the export file format in its header line
+	ExportFormat = "search-index"
+	// ExportVersion is the export format version this package writes and reads
+	ExportVersion = 1
+	// ExportPrefix is where export files live in the index bucket
+	ExportPrefix = "exports/"
+	// ExportExtension ends every export file key
+	ExportExtension = ".ndjson"
+	// MaxImportFailures bounds the failures an import response lists; the
+	// rest are only counted
+	MaxImportFailures = 100
+	// maxExportLineBytes bounds one line of an export file, well above the
+	// largest valid document
+	maxExportLineBytes = 1 << 20
+)
+
+// ExportHeader is the first line of an export file. Every later line is one
+// Document as JSON, ordered by ID.
+type ExportHeader struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	// UserID is set when the file holds one user's documents only
+	UserID    string `json:"userId,omitempty"`
+	Documents int    `json:"documents"`
+}
+
+// ExportRequest is the payload of an OpExport request. An empty UserID
+// exports every document.
+type ExportRequest struct {
+	UserID string `json:"userId,omitempty"`
+}
+
+// ExportResponse is the data of an OpExport response
+type ExportResponse struct {
+	Key        string    `json:"key"`
+	UserID     string    `json:"userId,omitempty"`
+	Documents  int       `json:"documents"`
+	Bytes      int64     `json:"bytes"`
+	ExportedAt time.Time `json:"exportedAt"`
+}
+
+// ImportRequest is the payload of an OpImport request. Key names an export
+// file in the index bucket; a UserID imports that user's documents only.
+type ImportRequest struct {
+	Key    string `json:"key"`
+	UserID string `json:"userId,omitempty"`
+}
+
+// ImportResponse is the data of an OpImport response. Documents are added or
+// replace the indexed document with the same ID; others are left alone.
+type ImportResponse struct {
+	Key      string `json:"key"`
+	Imported int    `json:"imported"`
+	// Skipped documents belong to users other than the requested one
+	Skipped  int             `json:"skipped,omitempty"`
+	Failed   int             `json:"failed"`
+	Failures []ImportFailure `json:"failures,omitempty"`
+}
+
+// ImportFailure is a line of an export file that was not imported
+type ImportFailure struct {
+	// Line is the 1-based line of the file; the header is line 1
+	Line  int    `json:"line"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// ExportKey returns the key of a new export file of userID's documents, or of
+// the whole index when userID is empty
+func ExportKey(userID string, at time.Time) string {
+	scope := "all"
+	if userID != "" {
+		scope = "user-" + userID
+	}
+	return ExportPrefix + at.UTC().Format("20060102T150405Z") + "-" + scope + ExportExtension
+}
+
+// ValidateExportKey rejects keys outside ExportPrefix, so an import can only
+// read export files
+func ValidateExportKey(key string) error {
+	if !strings.HasPrefix(key, ExportPrefix) || !strings.HasSuffix(key, ExportExtension) ||
+		strings.Contains(key, "..") || len(key) > MaxFilenameLength {
+		return fmt.Errorf("key must name a %s file under %s", ExportExtension, ExportPrefix)
+	}
+	return nil
+}
+
+// WriteExport writes an export file: the header, then one document per line.
+// The header's Format, Version and Documents are filled in.
+func WriteExport(w io.Writer, header ExportHeader, docs []Document) error {
+	header.Format, header.Version, header.Documents = ExportFormat, ExportVersion, len(docs)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("failed to write export header: %w", err)
+	}
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("failed to write document %s: %w", doc.ID, err)
+		}
+	}
+	return nil
+}
+
+// ReadExport reads an export file, calling visit with each document and its
+// line, or with the error of a line that is not a document. A missing or
+// unsupported header fails the whole read, as does an error from visit.
+func ReadExport(r io.Reader, visit func(line int, doc Document, err error) error) (*ExportHeader, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxExportLineBytes)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read export header: %w", err)
+		}
+		return nil, fmt.Errorf("export file is empty")
+	}
+	var header ExportHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Format != ExportFormat {
+		return nil, fmt.Errorf("not a %s export file", ExportFormat)
+	}
+	if header.Version != ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d (want %d)", header.Version, ExportVersion)
+	}
+
+	line := 1
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var doc Document
+		var lineErr error
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			lineErr = fmt.Errorf("invalid document: %w", err)
+		}
+		if err := visit(line, doc, lineErr); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read export line %d: %w", line+1, err)
+	}
+	return &header, nil
+}
@@ -0,0 +1,76 @@
+package searchproto
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport_RoundTrip(t *testing.T) {
+	exportedAt := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	docs := []Document{
+		{ID: "a", UserID: "u1", Title: "Rock & Roll <Live>", IndexedAt: exportedAt},
+		{ID: "b", UserID: "u2", Title: "Second", HLSStatus: "READY", StatusUpdatedAt: &exportedAt},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteExport(&buf, ExportHeader{ExportedAt: exportedAt}, docs))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"format":"search-index","version":1,"exportedAt":"2026-10-16T09:30:00Z","documents":2}`, lines[0])
+	assert.Contains(t, lines[1], "Rock & Roll <Live>", "text is not HTML-escaped")
+
+	var read []Document
+	header, err := ReadExport(&buf, func(line int, doc Document, err error) error {
+		require.NoError(t, err)
+		read = append(read, doc)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, header.Documents)
+	assert.Equal(t, docs, read)
+}
+
+func TestReadExport_Errors(t *testing.T) {
+	visitAll := func(int, Document, error) error { return nil }
+
+	_, err := ReadExport(strings.NewReader(""), visitAll)
+	assert.ErrorContains(t, err, "empty")
+
+	_, err = ReadExport(strings.NewReader(`{"id":"a"}`+"\n"), visitAll)
+	assert.ErrorContains(t, err, "not a search-index export file")
+
+	_, err = ReadExport(strings.NewReader(`{"format":"search-index","version":2}`+"\n"), visitAll)
+	assert.ErrorContains(t, err, "unsupported export version 2")
+
+	file := `{"format":"search-index","version":1,"documents":2}` + "\n" + `{"id":"a"}` + "\n\nnot json\n"
+	var failed []int
+	_, err = ReadExport(strings.NewReader(file), func(line int, doc Document, err error) error {
+		if err != nil {
+			failed = append(failed, line)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{4}, failed, "bad lines are reported by line; blank lines are skipped")
+
+	stop := errors.New("stop")
+	_, err = ReadExport(strings.NewReader(file), func(int, Document, error) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestExportKey(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 30, 5, 0, time.FixedZone("CEST", 2*60*60))
+	assert.Equal(t, "exports/20261016T073005Z-all.ndjson", ExportKey("", at))
+	assert.Equal(t, "exports/20261016T073005Z-user-u1.ndjson", ExportKey("u1", at))
+
+	assert.NoError(t, ValidateExportKey(ExportKey("u1", at)))
+	for _, key := range []string{"index.json", "exports/../index.json", "exports/a.json", "other/a.ndjson"} {
+		assert.Error(t, ValidateExportKey(key), key)
+	}
+}
@@ -0,0 +1,367 @@
+// Package searchproto defines the wire contract between the API's search
+// client (internal/search) and the Nixiesearch Lambda (cmd/nixiesearch). Both
+// sides encode and decode these types, so a field added or renamed here is
+// seen by both at compile time instead of silently dropping out of the JSON.
+package searchproto
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Operation names the action a Request asks the Lambda to perform
+type Operation string
+
+const (
+	OpSearch    Operation = "search"
+	OpIndex     Operation = "index"
+	OpDelete    Operation = "delete"
+	OpBulkIndex Operation = "bulk_index"
+	OpStats     Operation = "stats"
+	// OpUpdateStatus changes the processing state of an indexed document
+	OpUpdateStatus Operation = "update_status"
+	// OpGet reads one indexed document as stored
+	OpGet Operation = "get"
+)
+
+// Request is the Lambda invocation payload. Payload holds the operation's
+// request type (SearchQuery, IndexRequest, DeleteRequest, BulkIndexRequest,
+// StatsRequest, StatusUpdate or GetRequest).
+type Request struct {
+	Operation Operation       `json:"operation"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// NewRequest encodes payload into a request for op
+func NewRequest(op Operation, payload interface{}) (Request, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Request{}, fmt.Errorf("failed to marshal %s payload: %w", op, err)
+	}
+	return Request{Operation: op, Payload: data}, nil
+}
+
+// Decode unmarshals the payload into v
+func (r Request) Decode(v interface{}) error {
+	if len(r.Payload) == 0 {
+		return fmt.Errorf("missing %s payload", r.Operation)
+	}
+	if err := json.Unmarshal(r.Payload, v); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", r.Operation, err)
+	}
+	return nil
+}
+
+// Response is the Lambda result. Operation failures are reported with
+// Success=false and Error set rather than as a Lambda function error; a
+// rejected document also sets Validation.
+type Response struct {
+	Success    bool            `json:"success"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Validation *DocumentError  `json:"validation,omitempty"`
+}
+
+// NewResponse encodes data into a successful response
+func NewResponse(data interface{}) (Response, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return Response{Success: true, Data: encoded}, nil
+}
+
+// ErrorResponse reports a failed operation
+func ErrorResponse(format string, args ...interface{}) Response {
+	return Response{Success: false, Error: fmt.Sprintf(format, args...)}
+}
+
+// ValidationResponse reports a document rejected by Validate
+func ValidationResponse(err *DocumentError) Response {
+	return Response{Success: false, Error: err.Message, Validation: err}
+}
+
+// Decode unmarshals the response data into v
+func (r Response) Decode(v interface{}) error {
+	if len(r.Data) == 0 {
+		return fmt.Errorf("response has no data")
+	}
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		return fmt.Errorf("failed to parse response data: %w", err)
+	}
+	return nil
+}
+
+// Document field limits, in bytes. The Lambda rejects longer documents so a
+// single bad payload cannot bloat the index it keeps in memory and in S3.
+const (
+	MaxDocumentIDLength = 128
+	MaxTextFieldLength  = 1000
+	MaxFilenameLength   = 1024
+)
+
+// Document represents a searchable track in the index
+type Document struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	Title     string    `json:"title"`
+	Artist    string    `json:"artist"`
+	Album     string    `json:"album"`
+	Genre     string    `json:"genre"`
+	Year      int       `json:"year,omitempty"`
+	Duration  int       `json:"duration,omitempty"`
+	Bitrate   int       `json:"bitrate,omitempty"` // kbps
+	Filename  string    `json:"filename"`
+	IndexedAt time.Time `json:"indexedAt"`
+	// HLSStatus is the track's HLS transcode status (PENDING, PROCESSING,
+	// READY or FAILED; empty before a transcode starts). Processing is set
+	// while the transcode is pending or running.
+	HLSStatus  string `json:"hlsStatus,omitempty"`
+	Processing bool   `json:"processing,omitempty"`
+	// StatusUpdatedAt is when HLSStatus last changed, as far as the index knows
+	StatusUpdatedAt *time.Time `json:"statusUpdatedAt,omitempty"`
+}
+
+// Validation error codes reported in DocumentError.Code
+const (
+	CodeRequired      = "required"
+	CodeTooLong       = "too_long"
+	CodeInvalidFormat = "invalid_format"
+)
+
+// DocumentError is a structured validation failure of one document field.
+// The Lambda returns it in Response.Validation so callers can tell a
+// malformed document from a failing index.
+type DocumentError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *DocumentError) Error() string {
+	return e.Message
+}
+
+// Validate reports why the document cannot be indexed: a missing ID or
+// UserID, an ID that is not a UUID, or a field over its length limit
+func (d Document) Validate() error {
+	if d.ID == "" {
+		return &DocumentError{Field: "id", Code: CodeRequired, Message: "id is required"}
+	}
+	if d.UserID == "" {
+		return &DocumentError{Field: "userId", Code: CodeRequired, Message: "userId is required"}
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"id", d.ID, MaxDocumentIDLength},
+		{"userId", d.UserID, MaxDocumentIDLength},
+		{"title", d.Title, MaxTextFieldLength},
+		{"artist", d.Artist, MaxTextFieldLength},
+		{"album", d.Album, MaxTextFieldLength},
+		{"genre", d.Genre, MaxTextFieldLength},
+		{"filename", d.Filename, MaxFilenameLength},
+	} {
+		if len(field.value) > field.max {
+			return &DocumentError{
+				Field:   field.name,
+				Code:    CodeTooLong,
+				Message: fmt.Sprintf("%s is longer than %d bytes", field.name, field.max),
+			}
+		}
+	}
+	if !isUUID(d.ID) {
+		return &DocumentError{Field: "id", Code: CodeInvalidFormat, Message: "id must be a UUID"}
+	}
+	return nil
+}
+
+// isUUID reports whether s is a UUID in its canonical 36-character form.
+// Track IDs are generated with uuid.New, so anything else did not come from
+// the API.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SearchQuery is the payload of an OpSearch request
+type SearchQuery struct {
+	Query   string        `json:"query"`
+	Filters SearchFilters `json:"filters,omitempty"`
+	Sort    *SortOption   `json:"sort,omitempty"`
+	Limit   int           `json:"limit,omitempty"`
+	Cursor  string        `json:"cursor,omitempty"`
+}
+
+// SearchFilters narrow a search. UserID scopes results to one library and is
+// always set by the client.
+type SearchFilters struct {
+	UserID   string `json:"userId,omitempty"`
+	Artist   string `json:"artist,omitempty"`
+	Album    string `json:"album,omitempty"`
+	Genre    string `json:"genre,omitempty"`
+	YearFrom int    `json:"yearFrom,omitempty"`
+	YearTo   int    `json:"yearTo,omitempty"`
+	// Duration (seconds) and bitrate (kbps) ranges; a zero bound is open.
+	// Documents without a bitrate never match a bitrate range.
+	DurationFrom int `json:"durationFrom,omitempty"`
+	DurationTo   int `json:"durationTo,omitempty"`
+	BitrateFrom  int `json:"bitrateFrom,omitempty"`
+	BitrateTo    int `json:"bitrateTo,omitempty"`
+	// HLSStatus and Processing match the document fields exactly.
+	// StatusUpdatedBefore keeps documents whose status has not changed since,
+	// which with Processing finds stuck transcodes.
+	HLSStatus           string     `json:"hlsStatus,omitempty"`
+	Processing          *bool      `json:"processing,omitempty"`
+	StatusUpdatedBefore *time.Time `json:"statusUpdatedBefore,omitempty"`
+}
+
+// SortOption orders results
+type SortOption struct {
+	Field string `json:"field"` // title, artist, album, year, duration
+	Order string `json:"order"` // asc, desc
+}
+
+// SearchResult is a single search hit
+type SearchResult struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Artist      string  `json:"artist"`
+	Album       string  `json:"album"`
+	Genre       string  `json:"genre"`
+	Year        int     `json:"year,omitempty"`
+	Duration    int     `json:"duration,omitempty"`
+	CoverArtURL string  `json:"coverArtUrl,omitempty"`
+	Score       float64 `json:"score"`
+	// UserID, HLSStatus and StatusUpdatedAt are copied from the document
+	UserID          string     `json:"userId,omitempty"`
+	HLSStatus       string     `json:"hlsStatus,omitempty"`
+	StatusUpdatedAt *time.Time `json:"statusUpdatedAt,omitempty"`
+}
+
+// SearchResponse is the data of an OpSearch response
+type SearchResponse struct {
+	Results    []SearchResult `json:"results"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"cursor,omitempty"`
+}
+
+// IndexRequest is the payload of an OpIndex request
+type IndexRequest struct {
+	Document Document `json:"document"`
+}
+
+// IndexResponse is the data of an OpIndex response
+type IndexResponse struct {
+	ID      string `json:"id"`
+	Indexed bool   `json:"indexed"`
+}
+
+// DeleteRequest is the payload of an OpDelete request
+type DeleteRequest struct {
+	ID string `json:"id"`
+}
+
+// DeleteResponse is the data of an OpDelete response
+type DeleteResponse struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+}
+
+// BulkIndexRequest is the payload of an OpBulkIndex request
+type BulkIndexRequest struct {
+	Documents []Document `json:"documents"`
+}
+
+// BulkIndexResponse is the data of an OpBulkIndex response. Valid documents
+// are indexed even when others in the batch fail; Failures lists the rest.
+type BulkIndexResponse struct {
+	Indexed  int                `json:"indexed"`
+	Failed   int                `json:"failed"`
+	Failures []BulkIndexFailure `json:"failures,omitempty"`
+}
+
+// BulkIndexFailure is a document of a bulk request that was not indexed
+type BulkIndexFailure struct {
+	// Index is the document's position in BulkIndexRequest.Documents
+	Index int    `json:"index"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+	// Field and Code are set when the document failed validation
+	Field string `json:"field,omitempty"`
+	Code  string `json:"code,omitempty"`
+}
+
+// StatsRequest is the payload of an OpStats request
+type StatsRequest struct{}
+
+// StatsResponse is the data of an OpStats response: the size of the index the
+// Lambda instance holds, for capacity monitoring
+type StatsResponse struct {
+	Documents int `json:"documents"`
+	// IndexBytes is the size of index.json as last loaded from or written to
+	// S3 (0 before the first write)
+	IndexBytes int64 `json:"indexBytes"`
+	// Users lists document counts per user, largest first
+	Users     []UserDocumentCount `json:"users"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+	// LastCompactionAt is when this instance last rewrote index.json whole,
+	// dropping deleted documents
+	LastCompactionAt *time.Time `json:"lastCompactionAt,omitempty"`
+	// LastSyncAt is when this instance last loaded index.json from S3 or
+	// wrote it back
+	LastSyncAt *time.Time `json:"lastSyncAt,omitempty"`
+}
+
+// StatusUpdate is the payload of an OpUpdateStatus request. It changes the
+// processing fields of an indexed document and leaves the rest as indexed.
+type StatusUpdate struct {
+	ID         string    `json:"id"`
+	HLSStatus  string    `json:"hlsStatus"`
+	Processing bool      `json:"processing"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// StatusUpdateResponse is the data of an OpUpdateStatus response. Updated is
+// false when the document is not indexed.
+type StatusUpdateResponse struct {
+	ID      string `json:"id"`
+	Updated bool   `json:"updated"`
+}
+
+// GetRequest is the payload of an OpGet request
+type GetRequest struct {
+	ID string `json:"id"`
+}
+
+// GetResponse is the data of an OpGet response. Document is nil when the ID
+// is not indexed.
+type GetResponse struct {
+	ID       string    `json:"id"`
+	Found    bool      `json:"found"`
+	Document *Document `json:"document,omitempty"`
+}
+
+// UserDocumentCount is the number of indexed documents of one user
+type UserDocumentCount struct {
+	UserID    string `json:"userId"`
+	Documents int    `json:"documents"`
+}
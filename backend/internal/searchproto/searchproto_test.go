@@ -0,0 +1,159 @@
+package searchproto
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip encodes payload the way the client does, sends it through JSON as
+// the Lambda runtime does, and decodes it the way the Lambda does.
+func roundTrip[T any](t *testing.T, op Operation, payload T) T {
+	t.Helper()
+	req, err := NewRequest(op, payload)
+	require.NoError(t, err)
+
+	wire, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	var received Request
+	require.NoError(t, json.Unmarshal(wire, &received))
+	assert.Equal(t, op, received.Operation)
+
+	var decoded T
+	require.NoError(t, received.Decode(&decoded))
+	return decoded
+}
+
+func TestRequest_RoundTrip(t *testing.T) {
+	indexedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	doc := Document{
+		ID: "t1", UserID: "u1", Title: "Midnight Drive", Artist: "Neon Coast", Album: "Afterglow",
+		Genre: "Synthwave", Year: 2019, Duration: 245, Bitrate: 320, Filename: "media/u1/t1.mp3", IndexedAt: indexedAt,
+		HLSStatus: "PROCESSING", Processing: true, StatusUpdatedAt: &indexedAt,
+	}
+	processing := true
+
+	t.Run("search", func(t *testing.T) {
+		query := SearchQuery{
+			Query: "midnight",
+			Filters: SearchFilters{
+				UserID: "u1", Artist: "Neon", Genre: "Synthwave", YearFrom: 2010, YearTo: 2020,
+				DurationFrom: 300, DurationTo: 480, BitrateFrom: 320,
+				HLSStatus: "PROCESSING", Processing: &processing, StatusUpdatedBefore: &indexedAt,
+			},
+			Sort:   &SortOption{Field: "year", Order: "desc"},
+			Limit:  50,
+			Cursor: "page-2",
+		}
+		assert.Equal(t, query, roundTrip(t, OpSearch, query))
+	})
+
+	t.Run("index", func(t *testing.T) {
+		req := IndexRequest{Document: doc}
+		assert.Equal(t, req, roundTrip(t, OpIndex, req))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		req := DeleteRequest{ID: "t1"}
+		assert.Equal(t, req, roundTrip(t, OpDelete, req))
+	})
+
+	t.Run("bulk index", func(t *testing.T) {
+		req := BulkIndexRequest{Documents: []Document{doc, {ID: "t2", UserID: "u1", Title: "Second"}}}
+		assert.Equal(t, req, roundTrip(t, OpBulkIndex, req))
+	})
+
+	t.Run("update status", func(t *testing.T) {
+		req := StatusUpdate{ID: "t1", HLSStatus: "READY", UpdatedAt: indexedAt}
+		assert.Equal(t, req, roundTrip(t, OpUpdateStatus, req))
+	})
+
+	t.Run("get", func(t *testing.T) {
+		req := GetRequest{ID: "t1"}
+		assert.Equal(t, req, roundTrip(t, OpGet, req))
+	})
+}
+
+func TestResponse_RoundTrip(t *testing.T) {
+	sent := SearchResponse{
+		Results:    []SearchResult{{ID: "t1", Title: "Midnight Drive", Artist: "Neon Coast", Year: 2019, Score: 12.5}},
+		Total:      31,
+		NextCursor: "page-3",
+	}
+	resp, err := NewResponse(sent)
+	require.NoError(t, err)
+
+	wire, err := json.Marshal(resp)
+	require.NoError(t, err)
+
+	var received Response
+	require.NoError(t, json.Unmarshal(wire, &received))
+	assert.True(t, received.Success)
+
+	var decoded SearchResponse
+	require.NoError(t, received.Decode(&decoded))
+	assert.Equal(t, sent, decoded)
+}
+
+// TestWireFormat pins the JSON field names, which deployed Lambdas and API
+// versions must agree on independently of the Go field names.
+func TestWireFormat(t *testing.T) {
+	req, err := NewRequest(OpSearch, SearchQuery{Query: "q", Filters: SearchFilters{UserID: "u1"}, Limit: 5})
+	require.NoError(t, err)
+	wire, err := json.Marshal(req)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"operation":"search","payload":{"query":"q","filters":{"userId":"u1"},"limit":5}}`, string(wire))
+
+	resp, err := NewResponse(SearchResponse{Results: []SearchResult{}, Total: 0, NextCursor: "c"})
+	require.NoError(t, err)
+	wire, err = json.Marshal(resp)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"success":true,"data":{"results":[],"total":0,"cursor":"c"}}`, string(wire))
+
+	wire, err = json.Marshal(ErrorResponse("unknown operation: %s", "reindex"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"success":false,"error":"unknown operation: reindex"}`, string(wire))
+}
+
+func TestDecode_Errors(t *testing.T) {
+	var query SearchQuery
+	assert.ErrorContains(t, Request{Operation: OpSearch}.Decode(&query), "missing search payload")
+	assert.ErrorContains(t, Request{Operation: OpSearch, Payload: json.RawMessage(`"text"`)}.Decode(&query), "invalid search payload")
+
+	var result SearchResponse
+	assert.Error(t, ErrorResponse("boom").Decode(&result))
+}
+
+func TestDocument_Validate(t *testing.T) {
+	valid := Document{ID: "6f1c2a9e-4b7d-4c3e-9a51-2d8e7f0b1c34", UserID: "u1", Title: "Midnight Drive"}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(d *Document)
+		want   DocumentError
+	}{
+		{"missing id", func(d *Document) { d.ID = "" }, DocumentError{"id", CodeRequired, "id is required"}},
+		{"missing user", func(d *Document) { d.UserID = "" }, DocumentError{"userId", CodeRequired, "userId is required"}},
+		{"long id", func(d *Document) { d.ID = strings.Repeat("a", MaxDocumentIDLength+1) }, DocumentError{"id", CodeTooLong, "id is longer than 128 bytes"}},
+		{"long artist", func(d *Document) { d.Artist = strings.Repeat("a", MaxTextFieldLength+1) }, DocumentError{"artist", CodeTooLong, "artist is longer than 1000 bytes"}},
+		{"long filename", func(d *Document) { d.Filename = strings.Repeat("a", MaxFilenameLength+1) }, DocumentError{"filename", CodeTooLong, "filename is longer than 1024 bytes"}},
+		{"id not a uuid", func(d *Document) { d.ID = "t1" }, DocumentError{"id", CodeInvalidFormat, "id must be a UUID"}},
+		{"id with misplaced hyphen", func(d *Document) { d.ID = "6f1c2a9e4-b7d-4c3e-9a51-2d8e7f0b1c34" }, DocumentError{"id", CodeInvalidFormat, "id must be a UUID"}},
+		{"id with non-hex digit", func(d *Document) { d.ID = "6f1c2a9e-4b7d-4c3e-9a51-2d8e7f0b1c3z" }, DocumentError{"id", CodeInvalidFormat, "id must be a UUID"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := valid
+			tt.modify(&doc)
+			var docErr *DocumentError
+			require.ErrorAs(t, doc.Validate(), &docErr)
+			assert.Equal(t, tt.want, *docErr)
+		})
+	}
+}
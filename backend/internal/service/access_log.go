@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// AccessLogRepository defines the repository interface for track access logs
+type AccessLogRepository interface {
+	RecordTrackAccess(ctx context.Context, event models.TrackAccessEvent) error
+	GetTrackAccessCounts(ctx context.Context, ownerID, trackID string) (*models.TrackAccessCounts, error)
+	ListTrackAccess(ctx context.Context, ownerID, trackID string, filter models.TrackAccessFilter) (*repository.PaginatedResult[models.TrackAccessEvent], error)
+}
+
+// AccessRecorder records accesses to tracks from outside their library
+type AccessRecorder interface {
+	Record(ctx context.Context, track models.Track, kind models.AccessKind, token string)
+}
+
+// AccessLogAware is implemented by services that hand out streams, downloads
+// or previews; Services.LogAccess installs the recorder.
+type AccessLogAware interface {
+	SetAccessLog(recorder AccessRecorder)
+}
+
+type accessClientKey struct{}
+
+// WithAccessClient returns a context carrying the address and user agent of
+// the request, for the access log of the tracks it reaches
+func WithAccessClient(ctx context.Context, client models.AccessClient) context.Context {
+	return context.WithValue(ctx, accessClientKey{}, client)
+}
+
+func accessClientFrom(ctx context.Context) models.AccessClient {
+	client, _ := ctx.Value(accessClientKey{}).(models.AccessClient)
+	return client
+}
+
+// AccessLogService keeps the access log of tracks reached from outside their
+// library: streams and downloads of public and unlisted tracks, and opened
+// preview links. Events are kept in the partition of the track's library with
+// counters that add up every event; owners' tracks are read through the
+// library-scoped repository so household members see the shared library's logs.
+type AccessLogService struct {
+	repo   AccessLogRepository
+	tracks repository.Repository
+	now    func() time.Time
+}
+
+// NewAccessLogService creates a new access log service
+func NewAccessLogService(repo AccessLogRepository, tracks repository.Repository) *AccessLogService {
+	return &AccessLogService{repo: repo, tracks: tracks, now: time.Now}
+}
+
+// Record adds an access to track by the client in ctx to its log. Logging is
+// best effort: a failure is reported and the access goes ahead.
+func (s *AccessLogService) Record(ctx context.Context, track models.Track, kind models.AccessKind, token string) {
+	event := models.NewTrackAccessEvent(uuid.New().String(), track, kind, token, accessClientFrom(ctx), s.now())
+	if err := s.repo.RecordTrackAccess(ctx, event); err != nil {
+		fmt.Printf("Warning: failed to record %s of track %s: %v\n", kind, track.ID, err)
+	}
+}
+
+// List returns a page of the access log of one of the user's tracks, newest
+// first, with the track's counters
+func (s *AccessLogService) List(ctx context.Context, userID, trackID string, filter models.TrackAccessFilter) (*models.TrackAccessLogResponse, error) {
+	track, err := s.tracks.GetTrack(ctx, userID, trackID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+
+	page, err := s.repo.ListTrackAccess(ctx, track.UserID, trackID, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, models.ErrInvalidCursor
+		}
+		return nil, err
+	}
+	counts, err := s.repo.GetTrackAccessCounts(ctx, track.UserID, trackID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TrackAccessLogResponse{
+		TrackID:    trackID,
+		Counts:     *counts,
+		Items:      page.Items,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+	}, nil
+}
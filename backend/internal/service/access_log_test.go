@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogService(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	s3Repo := repository.NewMemoryS3Repository("http://localhost/media")
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{
+		ID: "public", UserID: "owner", Title: "Song", S3Key: "media/owner/public.mp3", Visibility: models.VisibilityPublic,
+		PreviewStatus: models.HLSStatusReady, PreviewKey: "previews/owner/public.mp3",
+	}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "private", UserID: "owner", S3Key: "media/owner/private.mp3"}))
+
+	services := NewServices(repo, s3Repo, nil, "media", "")
+	keys, err := reqsign.ParseKeyring("k1:preview-secret-0123456789abcdefghij")
+	require.NoError(t, err)
+	services.Previews = NewPreviewService(repo, s3Repo, nil, keys)
+	svc := NewAccessLogService(repo, repo)
+	at := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { at = at.Add(time.Second); return at }
+	services.LogAccess(svc)
+
+	listener := WithAccessClient(ctx, models.AccessClient{Addr: "203.0.113.77", UserAgent: "Mozilla/5.0"})
+
+	t.Run("records accesses from outside the library", func(t *testing.T) {
+		_, err := services.Stream.GetStreamURL(listener, "listener", "public", false)
+		require.NoError(t, err)
+		_, err = services.Stream.GetDownloadURL(listener, "listener", "public", false)
+		require.NoError(t, err)
+		link, err := services.Previews.Link(ctx, "public")
+		require.NoError(t, err)
+		_, err = services.Previews.Resolve(listener, strings.TrimPrefix(link.URL, "/api/v1/previews/"))
+		require.NoError(t, err)
+
+		// Neither the owner's own plays nor an admin's access to a private track count
+		_, err = services.Stream.GetStreamURL(ctx, "owner", "public", false)
+		require.NoError(t, err)
+		_, err = services.Stream.GetStreamURL(ctx, "admin", "private", true)
+		require.NoError(t, err)
+
+		log, err := svc.List(ctx, "owner", "public", models.TrackAccessFilter{})
+		require.NoError(t, err)
+		require.Len(t, log.Items, 3)
+		assert.Equal(t, models.AccessPreview, log.Items[0].Kind, "newest first")
+		assert.Equal(t, link.TokenID, log.Items[0].Token)
+		assert.Equal(t, models.AccessDownload, log.Items[1].Kind)
+		assert.Equal(t, models.AccessStream, log.Items[2].Kind)
+		assert.Equal(t, "203.0.113.0", log.Items[2].ClientIP)
+		assert.Equal(t, "Mozilla/5.0", log.Items[2].UserAgent)
+		assert.Equal(t, models.VisibilityPublic, log.Items[2].Visibility)
+
+		assert.Equal(t, int64(1), log.Counts.Views)
+		assert.Equal(t, int64(1), log.Counts.Plays)
+		assert.Equal(t, int64(1), log.Counts.Downloads)
+		assert.Equal(t, log.Items[0].AccessedAt, *log.Counts.LastAccessedAt)
+
+		private, err := svc.List(ctx, "owner", "private", models.TrackAccessFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, private.Items)
+		assert.Zero(t, private.Counts.Plays)
+	})
+
+	t.Run("pages through the log", func(t *testing.T) {
+		first, err := svc.List(ctx, "owner", "public", models.TrackAccessFilter{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, first.Items, 2)
+		require.True(t, first.HasMore)
+
+		second, err := svc.List(ctx, "owner", "public", models.TrackAccessFilter{Limit: 2, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, second.Items, 1)
+		assert.False(t, second.HasMore)
+		assert.Equal(t, models.AccessStream, second.Items[0].Kind)
+
+		_, err = svc.List(ctx, "owner", "public", models.TrackAccessFilter{Cursor: "not-a-cursor"})
+		assert.Equal(t, models.ErrInvalidCursor, err)
+	})
+
+	t.Run("only the library sees the log", func(t *testing.T) {
+		_, err := svc.List(ctx, "listener", "public", models.TrackAccessFilter{})
+		assert.Equal(t, models.NewNotFoundError("Track", "public"), err)
+	})
+}
@@ -45,6 +45,7 @@ type AdminService interface {
 type adminService struct {
 	repo    AdminRepository
 	cognito CognitoClient
+	users   *UserCache
 }
 
 // NewAdminService creates a new AdminService.
@@ -55,6 +56,20 @@ func NewAdminService(repo AdminRepository, cognito CognitoClient) AdminService {
 	}
 }
 
+// SetUserCache makes role and status changes evict the user from cache.
+// Admin operations always read users from storage.
+func (s *adminService) SetUserCache(cache *UserCache) {
+	s.users = cache
+}
+
+// invalidate evicts a changed user from the cache, including after a failed
+// change whose rollback may itself have failed
+func (s *adminService) invalidate(ctx context.Context, userID string) {
+	if s.users != nil {
+		s.users.Invalidate(ctx, userID)
+	}
+}
+
 // SearchUsers searches for users by email in Cognito.
 func (s *adminService) SearchUsers(ctx context.Context, query string, limit int) ([]models.UserSummary, error) {
 	if limit <= 0 {
@@ -142,6 +157,7 @@ func (s *adminService) UpdateUserRole(ctx context.Context, userID string, newRol
 	}
 
 	oldRole := user.Role
+	defer s.invalidate(ctx, userID)
 
 	// Cognito uses email as the username, not the sub (userID)
 	cognitoUsername := user.Email
@@ -210,6 +226,7 @@ func (s *adminService) SetUserStatus(ctx context.Context, userID string, disable
 	if cognitoUsername == "" {
 		return fmt.Errorf("user has no email address for Cognito operations")
 	}
+	defer s.invalidate(ctx, userID)
 
 	// Step 1: Update DynamoDB
 	if err := s.repo.SetUserDisabled(ctx, userID, disabled); err != nil {
@@ -8,47 +8,12 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockAdminRepository is a mock implementation for admin repository operations.
-type MockAdminRepository struct {
-	mock.Mock
-}
-
-func (m *MockAdminRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.User), args.Error(1)
-}
-
-func (m *MockAdminRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	args := m.Called(ctx, userID, role)
-	return args.Error(0)
-}
-
-func (m *MockAdminRepository) SearchUsers(ctx context.Context, query string, limit int) ([]models.User, error) {
-	args := m.Called(ctx, query, limit)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.User), args.Error(1)
-}
-
-func (m *MockAdminRepository) SetUserDisabled(ctx context.Context, userID string, disabled bool) error {
-	args := m.Called(ctx, userID, disabled)
-	return args.Error(0)
-}
-
-func (m *MockAdminRepository) GetFollowerCount(ctx context.Context, userID string) (int, error) {
-	args := m.Called(ctx, userID)
-	return args.Int(0), args.Error(1)
-}
-
 // MockCognitoClient is a mock implementation of CognitoClient.
 type MockCognitoClient struct {
 	mock.Mock
@@ -95,9 +60,19 @@ func (m *MockCognitoClient) SearchUsers(ctx context.Context, query string, limit
 	return args.Get(0).([]CognitoUser), args.Error(1)
 }
 
+func (m *MockCognitoClient) CreateUser(ctx context.Context, email, name, temporaryPassword string, sendInvite bool) (string, error) {
+	args := m.Called(ctx, email, name, temporaryPassword, sendInvite)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCognitoClient) DeleteUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 func TestNewAdminService(t *testing.T) {
 	t.Run("creates service with dependencies", func(t *testing.T) {
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		svc := NewAdminService(mockRepo, mockCognito)
@@ -108,7 +83,7 @@ func TestNewAdminService(t *testing.T) {
 func TestAdminService_SearchUsers(t *testing.T) {
 	t.Run("returns matching users", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		now := time.Now()
@@ -157,7 +132,7 @@ func TestAdminService_SearchUsers(t *testing.T) {
 
 	t.Run("returns empty slice when no matches", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		mockCognito.On("SearchUsers", ctx, "nonexistent", 20).Return([]CognitoUser{}, nil)
@@ -171,7 +146,7 @@ func TestAdminService_SearchUsers(t *testing.T) {
 
 	t.Run("applies default limit", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		mockCognito.On("SearchUsers", ctx, "test", 20).Return([]CognitoUser{}, nil)
@@ -185,7 +160,7 @@ func TestAdminService_SearchUsers(t *testing.T) {
 
 	t.Run("handles cognito error", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		mockCognito.On("SearchUsers", ctx, "error", 20).Return(([]CognitoUser)(nil), errors.New("cognito error"))
@@ -201,7 +176,7 @@ func TestAdminService_SearchUsers(t *testing.T) {
 func TestAdminService_GetUserDetails(t *testing.T) {
 	t.Run("returns full user details", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		now := time.Now()
@@ -236,7 +211,7 @@ func TestAdminService_GetUserDetails(t *testing.T) {
 
 	t.Run("returns error when user not found", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		mockRepo.On("GetUser", ctx, "nonexistent").Return((*models.User)(nil), repository.ErrNotFound)
@@ -250,7 +225,7 @@ func TestAdminService_GetUserDetails(t *testing.T) {
 
 	t.Run("handles follower count error gracefully", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		user := &models.User{
@@ -277,7 +252,7 @@ func TestAdminService_GetUserDetails(t *testing.T) {
 func TestAdminService_UpdateUserRole(t *testing.T) {
 	t.Run("updates role in both DynamoDB and Cognito", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		user := &models.User{
@@ -303,7 +278,7 @@ func TestAdminService_UpdateUserRole(t *testing.T) {
 
 	t.Run("rollbacks DynamoDB on Cognito failure", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		user := &models.User{
@@ -332,7 +307,7 @@ func TestAdminService_UpdateUserRole(t *testing.T) {
 
 	t.Run("returns error for invalid role", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		svc := NewAdminService(mockRepo, mockCognito)
@@ -344,7 +319,7 @@ func TestAdminService_UpdateUserRole(t *testing.T) {
 
 	t.Run("returns error when user not found", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		mockRepo.On("GetUser", ctx, "nonexistent").Return((*models.User)(nil), repository.ErrNotFound)
@@ -360,7 +335,7 @@ func TestAdminService_UpdateUserRole(t *testing.T) {
 		// Note: The userID context key check is done at handler level, not service level.
 		// This test verifies the UpdateUserRoleByAdmin method prevents self-modification.
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		svc := NewAdminService(mockRepo, mockCognito)
@@ -372,7 +347,7 @@ func TestAdminService_UpdateUserRole(t *testing.T) {
 
 	t.Run("skips Cognito update when role unchanged", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		user := &models.User{
@@ -394,7 +369,7 @@ func TestAdminService_UpdateUserRole(t *testing.T) {
 
 	t.Run("demotes to guest and adds to guest Cognito group", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		user := &models.User{
@@ -422,7 +397,7 @@ func TestAdminService_UpdateUserRole(t *testing.T) {
 func TestAdminService_SetUserStatus(t *testing.T) {
 	t.Run("disables user in DynamoDB and Cognito", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		user := &models.User{
@@ -446,7 +421,7 @@ func TestAdminService_SetUserStatus(t *testing.T) {
 
 	t.Run("enables user in DynamoDB and Cognito", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		user := &models.User{
@@ -470,7 +445,7 @@ func TestAdminService_SetUserStatus(t *testing.T) {
 
 	t.Run("returns error when user not found", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		mockRepo.On("GetUser", ctx, "nonexistent").Return((*models.User)(nil), repository.ErrNotFound)
@@ -484,7 +459,7 @@ func TestAdminService_SetUserStatus(t *testing.T) {
 
 	t.Run("rollbacks DynamoDB on Cognito failure when disabling", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockAdminRepository)
+		mockRepo := new(mocks.Repository)
 		mockCognito := new(MockCognitoClient)
 
 		user := &models.User{
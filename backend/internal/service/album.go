@@ -5,6 +5,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
 )
@@ -12,11 +13,11 @@ import (
 // albumService implements AlbumService
 type albumService struct {
 	repo   repository.Repository
-	s3Repo repository.S3Repository
+	s3Repo repository.MediaStore
 }
 
 // NewAlbumService creates a new album service
-func NewAlbumService(repo repository.Repository, s3Repo repository.S3Repository) AlbumService {
+func NewAlbumService(repo repository.Repository, s3Repo repository.MediaStore) AlbumService {
 	return &albumService{
 		repo:   repo,
 		s3Repo: s3Repo,
@@ -51,12 +52,13 @@ func (s *albumService) GetAlbum(ctx context.Context, userID, albumID string) (*m
 	}
 
 	// Filter to only tracks matching this album and convert to responses
+	saveData := dataSaver(ctx, s.repo, userID)
 	var tracks []models.TrackResponse
 	for _, track := range trackResult.Items {
 		if track.Album == album.Title && track.Artist == album.Artist {
 			trackCoverURL := ""
 			if track.CoverArtKey != "" {
-				url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+				url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData), 24*time.Hour)
 				if err == nil {
 					trackCoverURL = url
 				}
@@ -124,6 +126,7 @@ func (s *albumService) ListAlbums(ctx context.Context, userID string, filter mod
 		albumStatsMap[key].totalDuration += track.Duration
 	}
 
+	saveData := dataSaver(ctx, s.repo, userID)
 	responses := make([]models.AlbumResponse, 0, len(result.Items))
 	for _, album := range result.Items {
 		// Look up actual track count
@@ -137,7 +140,7 @@ func (s *albumService) ListAlbums(ctx context.Context, userID string, filter mod
 
 		coverArtURL := ""
 		if album.CoverArtKey != "" {
-			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, album.CoverArtKey, 24*time.Hour)
+			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, album.CoverStyle.ListCoverKey(album.CoverArtKey, saveData), 24*time.Hour)
 			if err == nil {
 				coverArtURL = url
 			}
@@ -190,6 +193,7 @@ func (s *albumService) ListAlbumsByArtist(ctx context.Context, userID, artist st
 		albumStatsMap[track.Album].totalDuration += track.Duration
 	}
 
+	saveData := dataSaver(ctx, s.repo, userID)
 	responses := make([]models.AlbumResponse, 0, len(albums))
 	for _, album := range albums {
 		// Look up actual track count
@@ -202,7 +206,7 @@ func (s *albumService) ListAlbumsByArtist(ctx context.Context, userID, artist st
 
 		coverArtURL := ""
 		if album.CoverArtKey != "" {
-			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, album.CoverArtKey, 24*time.Hour)
+			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, album.CoverStyle.ListCoverKey(album.CoverArtKey, saveData), 24*time.Hour)
 			if err == nil {
 				coverArtURL = url
 			}
@@ -281,12 +285,16 @@ func (s *albumService) ListArtists(ctx context.Context, userID string, filter mo
 			}
 			return artists[i].AlbumCount < artists[j].AlbumCount
 		})
-	default: // name
+	default: // name, in the library's collation order
+		locale, err := librarySortLocale(ctx, s.repo, userID)
+		if err != nil {
+			return nil, err
+		}
 		sort.Slice(artists, func(i, j int) bool {
 			if filter.SortOrder == "desc" {
-				return artists[i].Name > artists[j].Name
+				return collation.Compare(locale, artists[i].Name, artists[j].Name) > 0
 			}
-			return artists[i].Name < artists[j].Name
+			return collation.Compare(locale, artists[i].Name, artists[j].Name) < 0
 		})
 	}
 
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// archiveScanPageSize is how many items the insights job scans per page
+const archiveScanPageSize = 500
+
+// ArchiveSuggestionRepository defines the repository interface for archive
+// insights
+type ArchiveSuggestionRepository interface {
+	GetArchiveInsight(ctx context.Context, libraryID string) (*models.ArchiveInsight, error)
+	PutArchiveInsight(ctx context.Context, insight models.ArchiveInsight) error
+	ScanTrackItems(ctx context.Context, cursor string, limit int) (*repository.PaginatedResult[models.TrackItem], error)
+}
+
+// ArchiveSuggestionService suggests deleting tracks nobody has played in
+// models.DefaultColdAfter. The insights job reports each library's cold
+// tracks; a reviewer reads the report and accepts suggestions in bulk, by
+// moving the tracks to the trash or dismissing them to keep them. Cold files need no
+// archive step of their own: the media bucket's Intelligent-Tiering moves
+// objects unread for 90 days to its archive tiers.
+type ArchiveSuggestionService struct {
+	repo    ArchiveSuggestionRepository
+	library repository.Repository
+	// trash takes the tracks of accepted deletions; nil for the insights job
+	trash *TrashService
+	// operations keeps the undo record of deletions; nil keeps none
+	operations TrashRecorder
+	coldAfter  time.Duration
+	now        func() time.Time
+}
+
+// NewArchiveSuggestionService creates a new archive suggestion service.
+// library reads tracks through the library-scoped repository; trash may be
+// nil where no suggestion is accepted.
+func NewArchiveSuggestionService(repo ArchiveSuggestionRepository, library repository.Repository, trash *TrashService) *ArchiveSuggestionService {
+	return &ArchiveSuggestionService{
+		repo:      repo,
+		library:   library,
+		trash:     trash,
+		coldAfter: models.DefaultColdAfter,
+		now:       time.Now,
+	}
+}
+
+// SetOperations keeps an undo record of each accepted batch of deletions
+func (s *ArchiveSuggestionService) SetOperations(recorder TrashRecorder) {
+	s.operations = recorder
+}
+
+// GenerateAll is the insights job: it scans every track in the table and
+// replaces the report of each library that has tracks. It returns how many
+// libraries were reported.
+func (s *ArchiveSuggestionService) GenerateAll(ctx context.Context) (int, error) {
+	now := s.now()
+	insights := make(map[string]*models.ArchiveInsight)
+	cursor := ""
+	for {
+		page, err := s.repo.ScanTrackItems(ctx, cursor, archiveScanPageSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, item := range page.Items {
+			insight, ok := insights[item.UserID]
+			if !ok {
+				insight = models.NewArchiveInsight(item.UserID, s.coldAfter, now)
+				insights[item.UserID] = insight
+			}
+			insight.Add(item.Track)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	saved := 0
+	for _, insight := range insights {
+		if err := s.save(ctx, insight); err != nil {
+			return saved, fmt.Errorf("failed to save archive insight of %s: %w", insight.LibraryID, err)
+		}
+		saved++
+	}
+	return saved, nil
+}
+
+// Generate replaces the report of the user's library from its tracks now
+func (s *ArchiveSuggestionService) Generate(ctx context.Context, userID string) (*models.ArchiveInsight, error) {
+	libraryID, err := libraryOwner(ctx, s.library, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	insight := models.NewArchiveInsight(libraryID, s.coldAfter, s.now())
+	cursor := ""
+	for {
+		page, err := s.library.ListTracks(ctx, userID, models.TrackFilter{Limit: 100, LastKey: cursor})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tracks: %w", err)
+		}
+		for _, track := range page.Items {
+			// ListTracks mixes in other users' public tracks
+			if track.UserID == libraryID {
+				insight.Add(track)
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if err := s.save(ctx, insight); err != nil {
+		return nil, err
+	}
+	return insight, nil
+}
+
+// save stores a finished report, carrying over the dismissals of the last
+func (s *ArchiveSuggestionService) save(ctx context.Context, insight *models.ArchiveInsight) error {
+	previous, err := s.repo.GetArchiveInsight(ctx, insight.LibraryID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if previous != nil {
+		insight.Dismiss(previous.Dismissed)
+	}
+	insight.Finish()
+	return s.repo.PutArchiveInsight(ctx, *insight)
+}
+
+// insight returns the report of the user's library, generating it when the
+// insights job has not reported the library yet
+func (s *ArchiveSuggestionService) insight(ctx context.Context, userID string) (*models.ArchiveInsight, error) {
+	libraryID, err := libraryOwner(ctx, s.library, userID)
+	if err != nil {
+		return nil, err
+	}
+	insight, err := s.repo.GetArchiveInsight(ctx, libraryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return s.Generate(ctx, userID)
+	}
+	return insight, err
+}
+
+// Suggestions lists the cold tracks of the user's library from its last
+// report, oldest first. Tracks deleted or played since are left out.
+func (s *ArchiveSuggestionService) Suggestions(ctx context.Context, userID string) (*models.ArchiveSuggestionsResponse, error) {
+	insight, err := s.insight(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trackIDs := make([]string, len(insight.Tracks))
+	for i, cold := range insight.Tracks {
+		trackIDs[i] = cold.TrackID
+	}
+	tracks, err := s.getTracks(ctx, userID, trackIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks: %w", err)
+	}
+
+	now := s.now()
+	response := &models.ArchiveSuggestionsResponse{
+		Suggestions:   []models.ArchiveSuggestion{},
+		ColdTracks:    insight.ColdTracks,
+		ColdBytes:     insight.ColdBytes,
+		ColdAfterDays: insight.ColdAfter,
+		GeneratedAt:   insight.GeneratedAt,
+	}
+	for _, id := range trackIDs {
+		track, ok := tracks[id]
+		if !ok || !track.IsCold(now, s.coldAfter) {
+			continue
+		}
+		response.Suggestions = append(response.Suggestions, models.NewArchiveSuggestion(track))
+	}
+	return response, nil
+}
+
+// getTracks loads tracks through the library's TrackBatchGetter when it has
+// one, and one by one otherwise. Missing tracks are left out.
+func (s *ArchiveSuggestionService) getTracks(ctx context.Context, userID string, trackIDs []string) (map[string]models.Track, error) {
+	if getter, ok := s.library.(TrackBatchGetter); ok {
+		tracks, err := getter.BatchGetTracks(ctx, userID, trackIDs)
+		if !errors.Is(err, repository.ErrNotFound) {
+			return tracks, err
+		}
+	}
+
+	tracks := make(map[string]models.Track, len(trackIDs))
+	for _, trackID := range trackIDs {
+		track, err := s.library.GetTrack(ctx, userID, trackID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tracks[trackID] = *track
+	}
+	return tracks, nil
+}
+
+// Apply accepts suggestions in bulk. Deleted tracks go to the trash, and the
+// batch can be undone within models.UndoWindow when an operation recorder is
+// set. Tracks the report does not suggest, and tracks played since, missing
+// or locked, are skipped rather than failing the request. A dry run returns
+// the same result without changing anything. When a change fails, the report
+// and undo record still cover the changes made before it, and the result of
+// those changes is returned with the error.
+func (s *ArchiveSuggestionService) Apply(ctx context.Context, userID string, req models.ApplyArchiveSuggestionsRequest) (*models.ArchiveSuggestionsResult, error) {
+	if req.Action == models.ArchiveActionDelete && s.trash == nil {
+		return nil, models.NewServiceUnavailableError("archive suggestions", "tracks cannot be deleted here")
+	}
+	insight, err := s.insight(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.planApply(ctx, userID, insight, req)
+	if err != nil {
+		return nil, err
+	}
+	done, applyErr := plan.execute(ctx, req.DryRun, func(ctx context.Context, track models.Track) error {
+		switch req.Action {
+		case models.ArchiveActionDelete:
+			if err := s.trash.Trash(ctx, track); err != nil {
+				return err
+			}
+			insight.Remove(track.ID)
+		case models.ArchiveActionDismiss:
+			insight.Dismiss([]string{track.ID})
+		}
+		return nil
+	})
+	if req.DryRun {
+		return plan.result, nil
+	}
+
+	result := plan.result
+	if applyErr != nil {
+		result.Applied = result.Applied[:done]
+		result.FreedBytes = 0
+		for _, track := range plan.changes[:done] {
+			result.FreedBytes += track.FileSize
+		}
+	}
+	if err := s.repo.PutArchiveInsight(ctx, *insight); err != nil {
+		return nil, errors.Join(applyErr, err)
+	}
+	if req.Action == models.ArchiveActionDelete {
+		s.recordOperation(ctx, userID, insight.LibraryID, result)
+	}
+	if applyErr != nil {
+		return result, fmt.Errorf("failed after %d of %d tracks: %w", done, len(plan.changes), applyErr)
+	}
+	return result, nil
+}
+
+// recordOperation keeps the undo record of trashed tracks. The tracks are in
+// the trash either way, so a failure only loses the undo.
+func (s *ArchiveSuggestionService) recordOperation(ctx context.Context, userID, libraryID string, result *models.ArchiveSuggestionsResult) {
+	if s.operations == nil {
+		return
+	}
+	op, err := s.operations.RecordTrash(ctx, userID, libraryID, result.Applied)
+	if err != nil {
+		fmt.Printf("Warning: failed to record archive operation for %s: %v\n", userID, err)
+		return
+	}
+	if op != nil {
+		result.OperationID = op.ID
+		result.UndoExpiresAt = &op.ExpiresAt
+	}
+}
+
+// planApply picks the requested tracks the suggestion still holds for;
+// nothing is changed, though tracks found missing are dropped from the
+// in-memory report. The plan trashes or dismisses the tracks read.
+func (s *ArchiveSuggestionService) planApply(ctx context.Context, userID string, insight *models.ArchiveInsight, req models.ApplyArchiveSuggestionsRequest) (*bulkPlan[models.Track, *models.ArchiveSuggestionsResult], error) {
+	plan := &bulkPlan[models.Track, *models.ArchiveSuggestionsResult]{result: &models.ArchiveSuggestionsResult{Action: req.Action, Applied: []string{}, DryRun: req.DryRun}}
+	result := plan.result
+	now := s.now()
+	seen := make(map[string]bool, len(req.TrackIDs))
+	for _, id := range req.TrackIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if _, ok := insight.Find(id); !ok {
+			result.Skipped = append(result.Skipped, id)
+			continue
+		}
+		track, err := s.library.GetTrack(ctx, userID, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			insight.Remove(id)
+			result.Skipped = append(result.Skipped, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !track.IsCold(now, s.coldAfter) || (req.Action == models.ArchiveActionDelete && track.Locked) {
+			result.Skipped = append(result.Skipped, id)
+			continue
+		}
+
+		plan.changes = append(plan.changes, *track)
+		result.Applied = append(result.Applied, id)
+		if req.Action == models.ArchiveActionDelete {
+			result.FreedBytes += track.FileSize
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveSuggestionService(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	s3Repo := repository.NewMemoryS3Repository("http://localhost/media")
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	lastYear := now.AddDate(-1, 0, 0)
+	added := func(years int) models.Timestamps {
+		return models.Timestamps{CreatedAt: now.AddDate(-years, 0, 0)}
+	}
+	for _, track := range []models.Track{
+		{ID: "old", UserID: "u1", Title: "Old", FileSize: 100, Timestamps: added(4)},
+		{ID: "older", UserID: "u1", Title: "Older", FileSize: 200, Timestamps: added(6)},
+		{ID: "played", UserID: "u1", Title: "Played", FileSize: 400, LastPlayed: &lastYear, Timestamps: added(6)},
+		{ID: "locked", UserID: "u1", Title: "Locked", FileSize: 800, Locked: true, Timestamps: added(5)},
+		{ID: "other", UserID: "u2", Title: "Other", FileSize: 1600, Timestamps: added(5)},
+	} {
+		require.NoError(t, repo.CreateTrack(ctx, track))
+		// CreateTrack stamps the track as added now
+		require.NoError(t, repo.UpdateTrack(ctx, track))
+	}
+
+	svc := NewArchiveSuggestionService(repo, repo, NewTrashService(repo, s3Repo))
+	svc.now = func() time.Time { return now }
+
+	t.Run("the insights job reports each library", func(t *testing.T) {
+		libraries, err := svc.GenerateAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, libraries)
+
+		suggestions, err := svc.Suggestions(ctx, "u1")
+		require.NoError(t, err)
+		assert.Equal(t, 3, suggestions.ColdTracks)
+		assert.Equal(t, int64(1100), suggestions.ColdBytes)
+		require.Len(t, suggestions.Suggestions, 3)
+		assert.Equal(t, "older", suggestions.Suggestions[0].TrackID, "oldest first")
+	})
+
+	t.Run("a dry run changes nothing", func(t *testing.T) {
+		result, err := svc.Apply(ctx, "u1", models.ApplyArchiveSuggestionsRequest{
+			TrackIDs: []string{"old"}, Action: models.ArchiveActionDelete, DryRun: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"old"}, result.Applied)
+		assert.Equal(t, int64(100), result.FreedBytes)
+
+		_, err = repo.GetTrack(ctx, "u1", "old")
+		assert.NoError(t, err)
+	})
+
+	t.Run("accepted deletions move the tracks to the trash", func(t *testing.T) {
+		result, err := svc.Apply(ctx, "u1", models.ApplyArchiveSuggestionsRequest{
+			TrackIDs: []string{"old", "locked", "played", "other"}, Action: models.ArchiveActionDelete,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"old"}, result.Applied)
+		assert.Equal(t, []string{"locked", "played", "other"}, result.Skipped)
+
+		_, err = repo.GetTrack(ctx, "u1", "old")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		_, err = repo.GetTrashedTrack(ctx, "u1", "old")
+		assert.NoError(t, err)
+	})
+
+	t.Run("dismissed tracks stay dismissed", func(t *testing.T) {
+		_, err := svc.Apply(ctx, "u1", models.ApplyArchiveSuggestionsRequest{
+			TrackIDs: []string{"older"}, Action: models.ArchiveActionDismiss,
+		})
+		require.NoError(t, err)
+
+		_, err = svc.GenerateAll(ctx)
+		require.NoError(t, err)
+		suggestions, err := svc.Suggestions(ctx, "u1")
+		require.NoError(t, err)
+		require.Len(t, suggestions.Suggestions, 1)
+		assert.Equal(t, "locked", suggestions.Suggestions[0].TrackID)
+	})
+
+	t.Run("libraries the job has not reported are reported on request", func(t *testing.T) {
+		suggestions, err := svc.Suggestions(ctx, "u3")
+		require.NoError(t, err)
+		assert.Empty(t, suggestions.Suggestions)
+		assert.Zero(t, suggestions.ColdTracks)
+	})
+}
+
+// failingTrashRepository fails to delete one track
+type failingTrashRepository struct {
+	*repository.MemoryRepository
+	failOn string
+}
+
+func (r *failingTrashRepository) DeleteTrack(ctx context.Context, userID, trackID string) error {
+	if trackID == r.failOn {
+		return errors.New("table unavailable")
+	}
+	return r.MemoryRepository.DeleteTrack(ctx, userID, trackID)
+}
+
+func TestArchiveSuggestionService_Undo(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// newUndoableArchive wires a library of three cold tracks whose deletions
+	// are recorded, failing to delete failOn
+	newUndoableArchive := func(t *testing.T, failOn string) (*ArchiveSuggestionService, *OperationService, *repository.MemoryRepository) {
+		t.Helper()
+		var tracks []models.Track
+		for _, id := range []string{"a", "b", "c"} {
+			track := builders.NewTrackBuilder("u1", id).Build()
+			track.CreatedAt = now.AddDate(-3, 0, 0)
+			tracks = append(tracks, track)
+		}
+		repo := newSeededRepo(t, tracks...)
+		for _, track := range tracks {
+			// CreateTrack stamps the track as added now
+			require.NoError(t, repo.UpdateTrack(ctx, track))
+		}
+
+		trash := NewTrashService(&failingTrashRepository{MemoryRepository: repo, failOn: failOn}, repository.NewMemoryS3Repository(""))
+		svc := NewArchiveSuggestionService(repo, repo, trash)
+		svc.now = func() time.Time { return now }
+		ops := NewOperationService(repo, repo, trash)
+		services := &Services{ArchiveSuggestions: svc}
+		services.RecordOperations(ops)
+		return svc, ops, repo
+	}
+	deleteAll := models.ApplyArchiveSuggestionsRequest{TrackIDs: []string{"a", "b", "c"}, Action: models.ArchiveActionDelete}
+
+	t.Run("deletions are restored from the trash", func(t *testing.T) {
+		svc, ops, repo := newUndoableArchive(t, "")
+
+		result, err := svc.Apply(ctx, "u1", deleteAll)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.OperationID)
+		_, err = repo.GetTrack(ctx, "u1", "a")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+
+		undone, err := ops.Undo(ctx, "u1", result.OperationID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, undone.Restored)
+		track, err := repo.GetTrack(ctx, "u1", "a")
+		require.NoError(t, err)
+		assert.True(t, track.CreatedAt.Equal(now.AddDate(-3, 0, 0)))
+	})
+
+	t.Run("a failure keeps the report and undo of the tracks deleted before it", func(t *testing.T) {
+		svc, ops, repo := newUndoableArchive(t, "b")
+
+		result, err := svc.Apply(ctx, "u1", deleteAll)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "after 1 of 3 tracks")
+		assert.Equal(t, []string{"a"}, result.Applied)
+
+		insight, err := repo.GetArchiveInsight(ctx, "u1")
+		require.NoError(t, err)
+		_, listed := insight.Find("a")
+		assert.False(t, listed, "the deleted track left the report")
+		assert.Equal(t, 2, insight.ColdTracks)
+
+		op, err := repo.GetOperation(ctx, "u1", result.OperationID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, op.Trashed)
+		undone, err := ops.Undo(ctx, "u1", op.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, undone.Restored)
+	})
+}
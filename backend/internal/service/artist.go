@@ -41,11 +41,11 @@ type ArtistRepository interface {
 // artistService implements ArtistService
 type artistService struct {
 	artistRepo ArtistRepository
-	s3Repo     repository.S3Repository
+	s3Repo     repository.MediaStore
 }
 
 // NewArtistService creates a new ArtistService
-func NewArtistService(artistRepo ArtistRepository, s3Repo repository.S3Repository) ArtistService {
+func NewArtistService(artistRepo ArtistRepository, s3Repo repository.MediaStore) ArtistService {
 	return &artistService{
 		artistRepo: artistRepo,
 		s3Repo:     s3Repo,
@@ -232,11 +232,13 @@ func (s *artistService) GetArtistTracks(ctx context.Context, userID, artistID st
 		return nil, fmt.Errorf("failed to list tracks: %w", err)
 	}
 
+	settings, _ := s.artistRepo.(UserSettingsReader)
+	saveData := dataSaver(ctx, settings, userID)
 	responses := make([]models.TrackResponse, 0, len(tracks))
 	for _, track := range tracks {
 		coverArtURL := ""
 		if track.CoverArtKey != "" && s.s3Repo != nil {
-			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData), 24*time.Hour)
 			if err == nil {
 				coverArtURL = url
 			}
@@ -7,63 +7,15 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockArtistProfileRepository is a mock implementation for artist profile operations
-type MockArtistProfileRepository struct {
-	mock.Mock
-}
-
-func (m *MockArtistProfileRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	args := m.Called(ctx, profile)
-	return args.Error(0)
-}
-
-func (m *MockArtistProfileRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.ArtistProfile), args.Error(1)
-}
-
-func (m *MockArtistProfileRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	args := m.Called(ctx, profile)
-	return args.Error(0)
-}
-
-func (m *MockArtistProfileRepository) DeleteArtistProfile(ctx context.Context, userID string) error {
-	args := m.Called(ctx, userID)
-	return args.Error(0)
-}
-
-func (m *MockArtistProfileRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.ArtistProfile], error) {
-	args := m.Called(ctx, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.ArtistProfile]), args.Error(1)
-}
-
-func (m *MockArtistProfileRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
-	args := m.Called(ctx, userID, delta)
-	return args.Error(0)
-}
-
-func (m *MockArtistProfileRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.User), args.Error(1)
-}
-
 func TestNewArtistProfileService(t *testing.T) {
 	t.Run("creates service with repository", func(t *testing.T) {
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 		svc := NewArtistProfileService(mockRepo)
 		require.NotNil(t, svc)
 	})
@@ -72,7 +24,7 @@ func TestNewArtistProfileService(t *testing.T) {
 func TestArtistProfileService_CreateProfile(t *testing.T) {
 	t.Run("creates profile for artist user", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -98,7 +50,7 @@ func TestArtistProfileService_CreateProfile(t *testing.T) {
 
 	t.Run("rejects non-artist user", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -118,7 +70,7 @@ func TestArtistProfileService_CreateProfile(t *testing.T) {
 
 	t.Run("returns error when profile already exists", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -141,7 +93,7 @@ func TestArtistProfileService_CreateProfile(t *testing.T) {
 func TestArtistProfileService_GetProfile(t *testing.T) {
 	t.Run("returns profile by user ID", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		profile := &models.ArtistProfile{
 			UserID:        "user-123",
@@ -166,7 +118,7 @@ func TestArtistProfileService_GetProfile(t *testing.T) {
 
 	t.Run("returns error when profile not found", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("GetArtistProfile", ctx, "nonexistent").Return((*models.ArtistProfile)(nil), repository.ErrNotFound)
 
@@ -181,7 +133,7 @@ func TestArtistProfileService_GetProfile(t *testing.T) {
 func TestArtistProfileService_UpdateProfile(t *testing.T) {
 	t.Run("updates profile as owner", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		existingProfile := &models.ArtistProfile{
 			UserID:      "user-123",
@@ -207,7 +159,7 @@ func TestArtistProfileService_UpdateProfile(t *testing.T) {
 
 	t.Run("rejects update from non-owner", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		svc := NewArtistProfileService(mockRepo)
 		_, err := svc.UpdateProfile(ctx, "other-user", "user-123", models.UpdateArtistProfileRequest{
@@ -220,7 +172,7 @@ func TestArtistProfileService_UpdateProfile(t *testing.T) {
 
 	t.Run("partially updates profile", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		existingProfile := &models.ArtistProfile{
 			UserID:      "user-123",
@@ -246,7 +198,7 @@ func TestArtistProfileService_UpdateProfile(t *testing.T) {
 func TestArtistProfileService_DeleteProfile(t *testing.T) {
 	t.Run("deletes profile as owner", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("DeleteArtistProfile", ctx, "user-123").Return(nil)
 
@@ -259,7 +211,7 @@ func TestArtistProfileService_DeleteProfile(t *testing.T) {
 
 	t.Run("rejects delete from non-owner", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		svc := NewArtistProfileService(mockRepo)
 		err := svc.DeleteProfile(ctx, "other-user", "user-123")
@@ -272,7 +224,7 @@ func TestArtistProfileService_DeleteProfile(t *testing.T) {
 func TestArtistProfileService_ListProfiles(t *testing.T) {
 	t.Run("lists artist profiles for discovery", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		profiles := &repository.PaginatedResult[models.ArtistProfile]{
 			Items: []models.ArtistProfile{
@@ -293,7 +245,7 @@ func TestArtistProfileService_ListProfiles(t *testing.T) {
 
 	t.Run("handles pagination", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockArtistProfileRepository)
+		mockRepo := new(mocks.Repository)
 
 		profiles := &repository.PaginatedResult[models.ArtistProfile]{
 			Items: []models.ArtistProfile{
@@ -8,246 +8,19 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockArtistRepository is a mock implementation of ArtistRepository
-type MockArtistRepository struct {
-	mock.Mock
-}
-
-func (m *MockArtistRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
-	args := m.Called(ctx, artist)
-	return args.Error(0)
-}
-
-func (m *MockArtistRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
-	args := m.Called(ctx, userID, artistID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Artist), args.Error(1)
-}
-
-func (m *MockArtistRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
-	args := m.Called(ctx, userID, name)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Artist), args.Error(1)
-}
-
-func (m *MockArtistRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*repository.PaginatedResult[models.Artist], error) {
-	args := m.Called(ctx, userID, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Artist]), args.Error(1)
-}
-
-func (m *MockArtistRepository) UpdateArtist(ctx context.Context, artist models.Artist) error {
-	args := m.Called(ctx, artist)
-	return args.Error(0)
-}
-
-func (m *MockArtistRepository) DeleteArtist(ctx context.Context, userID, artistID string) error {
-	args := m.Called(ctx, userID, artistID)
-	return args.Error(0)
-}
-
-func (m *MockArtistRepository) BatchGetArtists(ctx context.Context, userID string, artistIDs []string) (map[string]*models.Artist, error) {
-	args := m.Called(ctx, userID, artistIDs)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(map[string]*models.Artist), args.Error(1)
-}
-
-func (m *MockArtistRepository) SearchArtists(ctx context.Context, userID, query string, limit int) ([]*models.Artist, error) {
-	args := m.Called(ctx, userID, query, limit)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Artist), args.Error(1)
-}
-
-func (m *MockArtistRepository) GetArtistTrackCount(ctx context.Context, userID, artistID string) (int, error) {
-	args := m.Called(ctx, userID, artistID)
-	return args.Int(0), args.Error(1)
-}
-
-func (m *MockArtistRepository) GetArtistAlbumCount(ctx context.Context, userID, artistID string) (int, error) {
-	args := m.Called(ctx, userID, artistID)
-	return args.Int(0), args.Error(1)
-}
-
-func (m *MockArtistRepository) GetArtistTotalPlays(ctx context.Context, userID, artistID string) (int, error) {
-	args := m.Called(ctx, userID, artistID)
-	return args.Int(0), args.Error(1)
-}
-
-func (m *MockArtistRepository) ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.Track, error) {
-	args := m.Called(ctx, userID, artist)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.Track), args.Error(1)
-}
-
-// User role methods
-func (m *MockArtistRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	return nil
-}
-func (m *MockArtistRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
-	return nil, nil
-}
-
-// Playlist visibility methods
-func (m *MockArtistRepository) UpdatePlaylistVisibility(ctx context.Context, userID, playlistID string, visibility models.PlaylistVisibility) error {
-	return nil
-}
-func (m *MockArtistRepository) ListPublicPlaylists(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-
-// ArtistProfile methods
-func (m *MockArtistRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockArtistRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
-	return nil, nil
-}
-func (m *MockArtistRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockArtistRepository) DeleteArtistProfile(ctx context.Context, userID string) error {
-	return nil
-}
-func (m *MockArtistRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.ArtistProfile], error) {
-	return nil, nil
-}
-func (m *MockArtistRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Follow methods
-func (m *MockArtistRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
-	return nil
-}
-func (m *MockArtistRepository) DeleteFollow(ctx context.Context, followerID, followedID string) error {
-	return nil
-}
-func (m *MockArtistRepository) GetFollow(ctx context.Context, followerID, followedID string) (*models.Follow, error) {
-	return nil, nil
-}
-func (m *MockArtistRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockArtistRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockArtistRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// ArtistMockS3Repository for artist service tests (separate from search_test mock)
-type ArtistMockS3Repository struct {
-	mock.Mock
-}
-
-func (m *ArtistMockS3Repository) GeneratePresignedUploadURL(ctx context.Context, key, contentType string, expiry time.Duration) (string, error) {
-	args := m.Called(ctx, key, contentType, expiry)
-	return args.String(0), args.Error(1)
-}
-
-func (m *ArtistMockS3Repository) GeneratePresignedDownloadURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
-	args := m.Called(ctx, key, expiry)
-	return args.String(0), args.Error(1)
-}
-
-func (m *ArtistMockS3Repository) GeneratePresignedDownloadURLWithFilename(ctx context.Context, key string, expiry time.Duration, filename string) (string, error) {
-	args := m.Called(ctx, key, expiry, filename)
-	return args.String(0), args.Error(1)
-}
-
-func (m *ArtistMockS3Repository) InitiateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
-	args := m.Called(ctx, key, contentType)
-	return args.String(0), args.Error(1)
-}
-
-func (m *ArtistMockS3Repository) GenerateMultipartUploadURLs(ctx context.Context, key, uploadID string, numParts int, expiry time.Duration) ([]models.MultipartUploadPartURL, error) {
-	args := m.Called(ctx, key, uploadID, numParts, expiry)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.MultipartUploadPartURL), args.Error(1)
-}
-
-func (m *ArtistMockS3Repository) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []models.CompletedPartInfo) error {
-	args := m.Called(ctx, key, uploadID, parts)
-	return args.Error(0)
-}
-
-func (m *ArtistMockS3Repository) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
-	args := m.Called(ctx, key, uploadID)
-	return args.Error(0)
-}
-
-func (m *ArtistMockS3Repository) DeleteObject(ctx context.Context, key string) error {
-	args := m.Called(ctx, key)
-	return args.Error(0)
-}
-
-func (m *ArtistMockS3Repository) CopyObject(ctx context.Context, sourceKey, destKey string) error {
-	args := m.Called(ctx, sourceKey, destKey)
-	return args.Error(0)
-}
-
-func (m *ArtistMockS3Repository) GetObjectMetadata(ctx context.Context, key string) (map[string]string, error) {
-	args := m.Called(ctx, key)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(map[string]string), args.Error(1)
-}
-
-func (m *ArtistMockS3Repository) GetObject(ctx context.Context, key string) ([]byte, error) {
-	args := m.Called(ctx, key)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]byte), args.Error(1)
-}
-
-func (m *ArtistMockS3Repository) PutObject(ctx context.Context, key string, data []byte, contentType string) error {
-	args := m.Called(ctx, key, data, contentType)
-	return args.Error(0)
-}
-
-func (m *ArtistMockS3Repository) GetObjectSize(ctx context.Context, key string) (int64, error) {
-	args := m.Called(ctx, key)
-	return args.Get(0).(int64), args.Error(1)
-}
-
-func (m *ArtistMockS3Repository) ObjectExists(ctx context.Context, key string) (bool, error) {
-	args := m.Called(ctx, key)
-	return args.Bool(0), args.Error(1)
-}
-
-func (m *ArtistMockS3Repository) DeleteByPrefix(ctx context.Context, prefix string) error {
-	args := m.Called(ctx, prefix)
-	return args.Error(0)
-}
-
 func TestArtistService_CreateArtist(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 
 	t.Run("creates artist successfully", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		req := models.CreateArtistRequest{
@@ -271,8 +44,8 @@ func TestArtistService_CreateArtist(t *testing.T) {
 	})
 
 	t.Run("uses provided sort name", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		req := models.CreateArtistRequest{
@@ -291,8 +64,8 @@ func TestArtistService_CreateArtist(t *testing.T) {
 	})
 
 	t.Run("returns error on repository failure", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		req := models.CreateArtistRequest{
@@ -317,8 +90,8 @@ func TestArtistService_GetArtist(t *testing.T) {
 	artistID := "artist-456"
 
 	t.Run("returns artist with stats", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		artist := &models.Artist{
@@ -346,8 +119,8 @@ func TestArtistService_GetArtist(t *testing.T) {
 	})
 
 	t.Run("returns not found error", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		mockRepo.On("GetArtist", ctx, userID, artistID).Return(nil, repository.ErrNotFound)
@@ -365,8 +138,8 @@ func TestArtistService_GetArtist(t *testing.T) {
 	})
 
 	t.Run("handles stats fetch errors gracefully", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		artist := &models.Artist{
@@ -398,8 +171,8 @@ func TestArtistService_UpdateArtist(t *testing.T) {
 	artistID := "artist-456"
 
 	t.Run("updates artist successfully", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		existingArtist := &models.Artist{
@@ -428,8 +201,8 @@ func TestArtistService_UpdateArtist(t *testing.T) {
 	})
 
 	t.Run("updates specific fields only", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		existingArtist := &models.Artist{
@@ -458,8 +231,8 @@ func TestArtistService_UpdateArtist(t *testing.T) {
 	})
 
 	t.Run("returns not found error", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		newName := "New Name"
@@ -488,8 +261,8 @@ func TestArtistService_DeleteArtist(t *testing.T) {
 	artistID := "artist-456"
 
 	t.Run("deletes artist successfully", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		artist := &models.Artist{
@@ -508,8 +281,8 @@ func TestArtistService_DeleteArtist(t *testing.T) {
 	})
 
 	t.Run("returns not found error", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		mockRepo.On("GetArtist", ctx, userID, artistID).Return(nil, repository.ErrNotFound)
@@ -531,8 +304,8 @@ func TestArtistService_ListArtists(t *testing.T) {
 	userID := "user-123"
 
 	t.Run("returns paginated artists", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		artists := []models.Artist{
@@ -562,8 +335,8 @@ func TestArtistService_ListArtists(t *testing.T) {
 	})
 
 	t.Run("returns empty list", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		filter := models.ArtistFilter{}
@@ -588,8 +361,8 @@ func TestArtistService_SearchArtists(t *testing.T) {
 	userID := "user-123"
 
 	t.Run("searches artists successfully", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		artists := []*models.Artist{
@@ -609,8 +382,8 @@ func TestArtistService_SearchArtists(t *testing.T) {
 	})
 
 	t.Run("uses default limit when zero", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		mockRepo.On("SearchArtists", ctx, userID, "test", 10).Return([]*models.Artist{}, nil)
@@ -628,8 +401,8 @@ func TestArtistService_GetArtistTracks(t *testing.T) {
 	artistID := "artist-456"
 
 	t.Run("returns artist tracks", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		artist := &models.Artist{
@@ -645,6 +418,7 @@ func TestArtistService_GetArtistTracks(t *testing.T) {
 
 		mockRepo.On("GetArtist", ctx, userID, artistID).Return(artist, nil)
 		mockRepo.On("ListTracksByArtist", ctx, userID, "Test Artist").Return(tracks, nil)
+		mockRepo.On("GetUserSettings", ctx, userID).Return(nil, repository.ErrNotFound)
 
 		result, err := service.GetArtistTracks(ctx, userID, artistID)
 
@@ -655,8 +429,8 @@ func TestArtistService_GetArtistTracks(t *testing.T) {
 	})
 
 	t.Run("returns not found for non-existent artist", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		mockRepo.On("GetArtist", ctx, userID, artistID).Return(nil, repository.ErrNotFound)
@@ -674,8 +448,8 @@ func TestArtistService_GetArtistTracks(t *testing.T) {
 	})
 
 	t.Run("generates cover art URLs", func(t *testing.T) {
-		mockRepo := new(MockArtistRepository)
-		mockS3 := new(ArtistMockS3Repository)
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
 		service := NewArtistService(mockRepo, mockS3)
 
 		artist := &models.Artist{
@@ -689,6 +463,7 @@ func TestArtistService_GetArtistTracks(t *testing.T) {
 		}
 
 		mockRepo.On("GetArtist", ctx, userID, artistID).Return(artist, nil)
+		mockRepo.On("GetUserSettings", ctx, userID).Return(nil, repository.ErrNotFound)
 		mockRepo.On("ListTracksByArtist", ctx, userID, "Test Artist").Return(tracks, nil)
 		mockS3.On("GeneratePresignedDownloadURL", ctx, "covers/track-1.jpg", 24*time.Hour).Return("https://example.com/cover.jpg", nil)
 
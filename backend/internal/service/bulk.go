@@ -0,0 +1,28 @@
+package service
+
+import "context"
+
+// bulkPlan is what a bulk destructive request would do, worked out without
+// changing anything: the changes to make, in order, and the result reported
+// for them. Cleanup apply, DJ import, user import and archive suggestion
+// apply all plan first and then execute the plan, so their dry runs report
+// exactly what a real run does.
+type bulkPlan[C, R any] struct {
+	changes []C
+	result  R
+}
+
+// execute makes the planned changes in order through apply, unless dryRun.
+// It stops at the first failure and returns how many changes were made
+// before it, so the caller can record or report a partial run.
+func (p *bulkPlan[C, R]) execute(ctx context.Context, dryRun bool, apply func(context.Context, C) error) (int, error) {
+	if dryRun {
+		return 0, nil
+	}
+	for i, change := range p.changes {
+		if err := apply(ctx, change); err != nil {
+			return i, err
+		}
+	}
+	return len(p.changes), nil
+}
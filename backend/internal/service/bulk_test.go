@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkPlan_Execute(t *testing.T) {
+	ctx := context.Background()
+	plan := &bulkPlan[string, int]{changes: []string{"a", "b", "c"}}
+
+	t.Run("dry runs change nothing", func(t *testing.T) {
+		var applied []string
+		done, err := plan.execute(ctx, true, func(ctx context.Context, change string) error {
+			applied = append(applied, change)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Zero(t, done)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("applies every change in order", func(t *testing.T) {
+		var applied []string
+		done, err := plan.execute(ctx, false, func(ctx context.Context, change string) error {
+			applied = append(applied, change)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, done)
+		assert.Equal(t, []string{"a", "b", "c"}, applied)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		failure := errors.New("throttled")
+		done, err := plan.execute(ctx, false, func(ctx context.Context, change string) error {
+			if change == "b" {
+				return failure
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, 1, done, "only the changes before the failure were made")
+	})
+}
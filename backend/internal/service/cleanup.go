@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// CleanupRepository defines the repository interface for metadata cleanup
+type CleanupRepository interface {
+	ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error)
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	UpdateTrack(ctx context.Context, track models.Track) error
+}
+
+// CleanupServiceImpl suggests normalized titles and artist names for a
+// library and applies the suggestions a reviewer accepts. Nothing is changed
+// until a suggestion is accepted.
+type CleanupServiceImpl struct {
+	repo CleanupRepository
+	// operations keeps an undo record of each applied batch; nil keeps none
+	operations OperationRecorder
+}
+
+// NewCleanupService creates a new metadata cleanup service
+func NewCleanupService(repo CleanupRepository) CleanupService {
+	return &CleanupServiceImpl{repo: repo}
+}
+
+// SetOperations keeps an undo record of each applied batch of cleanups
+func (s *CleanupServiceImpl) SetOperations(recorder OperationRecorder) {
+	s.operations = recorder
+}
+
+var (
+	// placeholderTitle matches titles written by rippers and exporters in
+	// place of the song name: "Track 01", "Track01", "Audio Track 3", "Untitled"
+	placeholderTitle = regexp.MustCompile(`(?i)^(?:audio\s*)?(?:track|piste|titel|untitled)[\s_-]*\d*$`)
+	// featuredInTitle matches a featured-artist credit at the end of a title,
+	// with or without brackets: "Song (feat. X)", "Song ft. X", "Song [featuring X]"
+	featuredInTitle = regexp.MustCompile(`(?i)\s*[(\[]?\s*\b(?:feat\.?|ft\.?|featuring)\s+([^)\]]+?)\s*[)\]]?$`)
+	// featuredCredit matches the spellings of "feat." within an artist credit
+	featuredCredit = regexp.MustCompile(`(?i)\s+(?:feat\.?|ft\.?|featuring)\s+`)
+	// romanNumeral matches roman numerals up to 39, kept upper case by title-casing
+	romanNumeral = regexp.MustCompile(`^X{0,3}(?:IX|IV|V?I{0,3})$`)
+)
+
+// minorWords stay lower case inside a title-cased value
+var minorWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "but": true, "or": true, "nor": true,
+	"of": true, "in": true, "on": true, "at": true, "to": true, "for": true, "by": true,
+	"vs": true, "vs.": true, "feat.": true,
+}
+
+// SuggestCleanups returns cleanup suggestions for every track the user owns.
+// A track gets at most one suggestion per field, carrying the most significant
+// reason found.
+func (s *CleanupServiceImpl) SuggestCleanups(ctx context.Context, userID string) (*models.CleanupSuggestionsResponse, error) {
+	result := &models.CleanupSuggestionsResponse{Suggestions: []models.CleanupSuggestion{}}
+
+	cursor := ""
+	for {
+		page, err := s.repo.ListTracks(ctx, userID, models.TrackFilter{Limit: 100, LastKey: cursor})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tracks: %w", err)
+		}
+		for _, track := range page.Items {
+			// ListTracks mixes in other users' public tracks
+			if track.UserID != userID {
+				continue
+			}
+			result.TracksScanned++
+			result.Suggestions = append(result.Suggestions, suggestCleanups(track)...)
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	return result, nil
+}
+
+// ApplyCleanups applies accepted suggestions, editing each track once.
+// Suggestions for missing or locked tracks, and for fields changed since the
+// suggestion was made, are skipped rather than failing the request. A dry run
+// returns the same result without saving the edits; a real run can be undone
+// within models.UndoWindow when an operation recorder is set.
+func (s *CleanupServiceImpl) ApplyCleanups(ctx context.Context, userID string, req models.ApplyCleanupRequest) (*models.CleanupResult, error) {
+	plan, err := s.planCleanups(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	plan.result.DryRun = req.DryRun
+	if _, err := plan.execute(ctx, req.DryRun, s.repo.UpdateTrack); err != nil {
+		return nil, err
+	}
+	if !req.DryRun {
+		s.recordOperation(ctx, userID, plan.result)
+	}
+	return plan.result, nil
+}
+
+// recordOperation keeps the undo record of applied cleanups. The edits are
+// saved either way, so a failure only loses the undo.
+func (s *CleanupServiceImpl) recordOperation(ctx context.Context, userID string, result *models.CleanupResult) {
+	if s.operations == nil {
+		return
+	}
+	changes := make([]models.FieldChange, len(result.Applied))
+	for i, applied := range result.Applied {
+		changes[i] = models.FieldChange{TrackID: applied.TrackID, Field: applied.Field, Before: applied.Original, After: applied.Suggested}
+	}
+	op, err := s.operations.Record(ctx, userID, models.OperationCleanup, changes)
+	if err != nil {
+		fmt.Printf("Warning: failed to record cleanup operation for %s: %v\n", userID, err)
+		return
+	}
+	if op != nil {
+		result.OperationID = op.ID
+		result.UndoExpiresAt = &op.ExpiresAt
+	}
+}
+
+// planCleanups reads the tracks the suggestions target and applies the
+// suggestions to copies, which the plan saves; nothing is saved here
+func (s *CleanupServiceImpl) planCleanups(ctx context.Context, userID string, req models.ApplyCleanupRequest) (*bulkPlan[models.Track, *models.CleanupResult], error) {
+	plan := &bulkPlan[models.Track, *models.CleanupResult]{result: &models.CleanupResult{Applied: []models.CleanupSuggestion{}, UpdatedTracks: []string{}}}
+	result := plan.result
+
+	var order []string
+	byTrack := make(map[string][]models.CleanupSuggestion)
+	for _, suggestion := range req.Suggestions {
+		if _, ok := byTrack[suggestion.TrackID]; !ok {
+			order = append(order, suggestion.TrackID)
+		}
+		byTrack[suggestion.TrackID] = append(byTrack[suggestion.TrackID], suggestion)
+	}
+
+	for _, trackID := range order {
+		suggestions := byTrack[trackID]
+		track, err := s.repo.GetTrack(ctx, userID, trackID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				result.Skipped = append(result.Skipped, suggestions...)
+				continue
+			}
+			return nil, err
+		}
+		if track.EnsureUnlocked() != nil {
+			result.Skipped = append(result.Skipped, suggestions...)
+			continue
+		}
+
+		var applied []models.CleanupSuggestion
+		for _, suggestion := range suggestions {
+			if track.ApplyCleanup(suggestion) {
+				applied = append(applied, suggestion)
+			} else {
+				result.Skipped = append(result.Skipped, suggestion)
+			}
+		}
+		if len(applied) == 0 {
+			continue
+		}
+		plan.changes = append(plan.changes, *track)
+		result.Applied = append(result.Applied, applied...)
+		result.UpdatedTracks = append(result.UpdatedTracks, track.ID)
+	}
+	return plan, nil
+}
+
+// suggestCleanups returns the title and artist suggestions for a track
+func suggestCleanups(track models.Track) []models.CleanupSuggestion {
+	title, titleReason := collapseWhitespace(track.Title)
+	artist, artistReason := collapseWhitespace(track.Artist)
+
+	if placeholderTitle.MatchString(title) {
+		// The reviewer supplies the real title
+		title, titleReason = "", models.CleanupPlaceholder
+	} else if isAllCaps(title) {
+		title, titleReason = titleCase(title), models.CleanupAllCaps
+	}
+	if isAllCaps(artist) {
+		artist, artistReason = titleCase(artist), models.CleanupAllCaps
+	}
+
+	if credit := featuredCredit.ReplaceAllString(artist, " feat. "); credit != artist {
+		artist, artistReason = credit, models.CleanupFeaturedArtist
+	}
+	if titleReason != models.CleanupPlaceholder {
+		if m := featuredInTitle.FindStringSubmatchIndex(title); m != nil && m[0] > 0 {
+			guests := title[m[2]:m[3]]
+			title, titleReason = title[:m[0]], models.CleanupFeaturedArtist
+			if !strings.Contains(strings.ToLower(artist), strings.ToLower(guests)) {
+				if artist == "" {
+					artist = guests
+				} else {
+					artist += " feat. " + guests
+				}
+				artistReason = models.CleanupFeaturedArtist
+			}
+		}
+	}
+
+	var suggestions []models.CleanupSuggestion
+	if titleReason != "" && title != track.Title {
+		suggestions = append(suggestions, models.CleanupSuggestion{
+			TrackID: track.ID, Field: models.TagFieldTitle, Original: track.Title, Suggested: title, Reason: titleReason,
+		})
+	}
+	if artistReason != "" && artist != track.Artist {
+		suggestions = append(suggestions, models.CleanupSuggestion{
+			TrackID: track.ID, Field: models.TagFieldArtist, Original: track.Artist, Suggested: artist, Reason: artistReason,
+		})
+	}
+	return suggestions
+}
+
+// collapseWhitespace trims a value and collapses runs of whitespace
+func collapseWhitespace(value string) (string, models.CleanupReason) {
+	collapsed := strings.Join(strings.Fields(value), " ")
+	if collapsed != value {
+		return collapsed, models.CleanupWhitespace
+	}
+	return value, ""
+}
+
+// isAllCaps reports whether a value of two or more words has letters and none
+// of them lower case. Single words are left alone: most are acronyms or
+// stylized names (ABBA, MGMT, AC/DC) rather than shouting.
+func isAllCaps(value string) bool {
+	if len(strings.Fields(value)) < 2 {
+		return false
+	}
+	hasUpper := false
+	for _, r := range value {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsUpper(r) {
+			hasUpper = true
+		}
+	}
+	return hasUpper
+}
+
+// titleCase converts an all-caps value to title case. Minor words stay lower
+// case except at the start, the end and after an opening bracket; roman
+// numerals and words containing digits are kept as written.
+func titleCase(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		bare := strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if (bare != "" && romanNumeral.MatchString(bare)) || strings.ContainsFunc(word, unicode.IsDigit) {
+			continue
+		}
+
+		lower := strings.ToLower(word)
+		opensGroup := strings.ContainsAny(word[:1], `([{`)
+		afterColon := i > 0 && strings.HasSuffix(words[i-1], ":")
+		if i > 0 && i < len(words)-1 && !opensGroup && !afterColon && minorWords[lower] {
+			words[i] = lower
+			continue
+		}
+		words[i] = capitalizeSegments(lower)
+	}
+	return strings.Join(words, " ")
+}
+
+// capitalizeSegments upper-cases the first letter of each hyphen- or
+// slash-separated segment of a lower-case word
+func capitalizeSegments(word string) string {
+	runes := []rune(word)
+	start := true
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == '/':
+			start = true
+		case start && unicode.IsLetter(r):
+			runes[i] = unicode.ToUpper(r)
+			start = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			start = false
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"HELLO WORLD", "Hello World"},
+		{"THE END OF THE WORLD", "The End of the World"},
+		{"SYMPHONY NO. 9 IN D MINOR", "Symphony No. 9 in D Minor"},
+		{"ROCKY IV THEME", "Rocky IV Theme"},
+		{"DON'T STOP ME NOW", "Don't Stop Me Now"},
+		{"SELF-TITLED (THE REMIX)", "Self-Titled (The Remix)"},
+		{"ÉTÉ ÉTERNEL", "Été Éternel"},
+		{"WHAT IT IS TO", "What It Is To"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, titleCase(tt.in))
+		})
+	}
+}
+
+func TestSuggestCleanups(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		artist string
+		want   []models.CleanupSuggestion
+	}{
+		{
+			name:   "clean track",
+			title:  "Around the World",
+			artist: "Daft Punk",
+		},
+		{
+			name:   "single-word caps are left alone",
+			title:  "SOS",
+			artist: "ABBA",
+		},
+		{
+			name:   "all caps",
+			title:  "AROUND THE WORLD",
+			artist: "DAFT PUNK",
+			want: []models.CleanupSuggestion{
+				{TrackID: "t", Field: models.TagFieldTitle, Original: "AROUND THE WORLD", Suggested: "Around the World", Reason: models.CleanupAllCaps},
+				{TrackID: "t", Field: models.TagFieldArtist, Original: "DAFT PUNK", Suggested: "Daft Punk", Reason: models.CleanupAllCaps},
+			},
+		},
+		{
+			name:   "placeholder title",
+			title:  "Track 01",
+			artist: "Daft Punk",
+			want: []models.CleanupSuggestion{
+				{TrackID: "t", Field: models.TagFieldTitle, Original: "Track 01", Suggested: "", Reason: models.CleanupPlaceholder},
+			},
+		},
+		{
+			name:   "featured artist in title",
+			title:  "Get Lucky (ft. Pharrell Williams)",
+			artist: "Daft Punk",
+			want: []models.CleanupSuggestion{
+				{TrackID: "t", Field: models.TagFieldTitle, Original: "Get Lucky (ft. Pharrell Williams)", Suggested: "Get Lucky", Reason: models.CleanupFeaturedArtist},
+				{TrackID: "t", Field: models.TagFieldArtist, Original: "Daft Punk", Suggested: "Daft Punk feat. Pharrell Williams", Reason: models.CleanupFeaturedArtist},
+			},
+		},
+		{
+			name:   "featured artist already credited",
+			title:  "Get Lucky featuring Pharrell Williams",
+			artist: "Daft Punk Ft. Pharrell Williams",
+			want: []models.CleanupSuggestion{
+				{TrackID: "t", Field: models.TagFieldTitle, Original: "Get Lucky featuring Pharrell Williams", Suggested: "Get Lucky", Reason: models.CleanupFeaturedArtist},
+				{TrackID: "t", Field: models.TagFieldArtist, Original: "Daft Punk Ft. Pharrell Williams", Suggested: "Daft Punk feat. Pharrell Williams", Reason: models.CleanupFeaturedArtist},
+			},
+		},
+		{
+			name:   "whitespace",
+			title:  " Around  the World",
+			artist: "Daft Punk",
+			want: []models.CleanupSuggestion{
+				{TrackID: "t", Field: models.TagFieldTitle, Original: " Around  the World", Suggested: "Around the World", Reason: models.CleanupWhitespace},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track := builders.NewTrackBuilder("user-1", "t").WithTitle(tt.title).WithArtist(tt.artist).Build()
+			assert.Equal(t, tt.want, suggestCleanups(track))
+		})
+	}
+}
+
+// placeholderTrack is user-1's track with a placeholder title and an
+// all-caps artist
+func placeholderTrack() *builders.TrackBuilder {
+	return builders.NewTrackBuilder("user-1", "track-2").WithTitle("Track 02").WithArtist("DAFT PUNK")
+}
+
+// newCleanupService holds placeholder, another all-caps track of user-1 and
+// a track of user-2
+func newCleanupService(t *testing.T, placeholder models.Track) (CleanupService, *repository.MemoryRepository) {
+	t.Helper()
+	repo := newSeededRepo(t,
+		builders.NewTrackBuilder("user-1", "track-1").WithTitle("HARDER BETTER FASTER").WithArtist("Daft Punk").Build(),
+		placeholder,
+		builders.NewTrackBuilder("user-2", "track-3").WithTitle("SOMEONE ELSES SONG").WithArtist("Other").Build(),
+	)
+	return NewCleanupService(repo), repo
+}
+
+func TestCleanupService_SuggestCleanups(t *testing.T) {
+	svc, _ := newCleanupService(t, placeholderTrack().Build())
+
+	result, err := svc.SuggestCleanups(context.Background(), "user-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.TracksScanned)
+	assert.Len(t, result.Suggestions, 3)
+	for _, suggestion := range result.Suggestions {
+		assert.NotEqual(t, "track-3", suggestion.TrackID)
+	}
+}
+
+func TestCleanupService_ApplyCleanups(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies suggestions in bulk, edited by the reviewer", func(t *testing.T) {
+		svc, repo := newCleanupService(t, placeholderTrack().Build())
+
+		result, err := svc.ApplyCleanups(ctx, "user-1", models.ApplyCleanupRequest{Suggestions: []models.CleanupSuggestion{
+			{TrackID: "track-1", Field: models.TagFieldTitle, Original: "HARDER BETTER FASTER", Suggested: "Harder, Better, Faster"},
+			{TrackID: "track-2", Field: models.TagFieldTitle, Original: "Track 02", Suggested: "Digital Love"},
+			{TrackID: "track-2", Field: models.TagFieldArtist, Original: "DAFT PUNK", Suggested: "Daft Punk"},
+		}})
+
+		require.NoError(t, err)
+		assert.Len(t, result.Applied, 3)
+		assert.Empty(t, result.Skipped)
+		assert.Equal(t, []string{"track-1", "track-2"}, result.UpdatedTracks)
+
+		track, err := repo.GetTrack(ctx, "user-1", "track-2")
+		require.NoError(t, err)
+		assert.Equal(t, "Digital Love", track.Title)
+		assert.Equal(t, "Daft Punk", track.Artist)
+	})
+
+	t.Run("dry run reports the edits without saving them", func(t *testing.T) {
+		svc, repo := newCleanupService(t, placeholderTrack().Build())
+		suggestions := []models.CleanupSuggestion{
+			{TrackID: "track-2", Field: models.TagFieldArtist, Original: "DAFT PUNK", Suggested: "Daft Punk"},
+			{TrackID: "track-1", Field: models.TagFieldTitle, Original: "Harder Better Faster", Suggested: "Harder, Better, Faster"},
+		}
+
+		result, err := svc.ApplyCleanups(ctx, "user-1", models.ApplyCleanupRequest{Suggestions: suggestions, DryRun: true})
+
+		require.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, suggestions[:1], result.Applied)
+		assert.Equal(t, suggestions[1:], result.Skipped)
+		assert.Equal(t, []string{"track-2"}, result.UpdatedTracks)
+
+		track, err := repo.GetTrack(ctx, "user-1", "track-2")
+		require.NoError(t, err)
+		assert.Equal(t, "DAFT PUNK", track.Artist)
+	})
+
+	t.Run("skips stale, locked and foreign tracks", func(t *testing.T) {
+		svc, repo := newCleanupService(t, placeholderTrack().Locked().Build())
+
+		stale := models.CleanupSuggestion{TrackID: "track-1", Field: models.TagFieldTitle, Original: "Harder Better Faster", Suggested: "Harder, Better, Faster"}
+		locked := models.CleanupSuggestion{TrackID: "track-2", Field: models.TagFieldArtist, Original: "DAFT PUNK", Suggested: "Daft Punk"}
+		foreign := models.CleanupSuggestion{TrackID: "track-3", Field: models.TagFieldTitle, Original: "SOMEONE ELSES SONG", Suggested: "Someone Elses Song"}
+		result, err := svc.ApplyCleanups(ctx, "user-1", models.ApplyCleanupRequest{Suggestions: []models.CleanupSuggestion{stale, locked, foreign}})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Applied)
+		assert.Equal(t, []models.CleanupSuggestion{stale, locked, foreign}, result.Skipped)
+
+		track, err := repo.GetTrack(ctx, "user-2", "track-3")
+		require.NoError(t, err)
+		assert.Equal(t, "SOMEONE ELSES SONG", track.Title)
+	})
+}
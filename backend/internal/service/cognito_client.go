@@ -10,6 +10,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
 )
 
+// ErrCognitoUserExists is returned when creating a user whose username is taken
+var ErrCognitoUserExists = errors.New("cognito user already exists")
+
 // CognitoUser represents a user from Cognito for admin operations.
 type CognitoUser struct {
 	ID       string
@@ -44,6 +47,14 @@ type CognitoClient interface {
 
 	// GetUserStatus gets the enabled/disabled status of a user from Cognito.
 	GetUserStatus(ctx context.Context, userID string) (enabled bool, err error)
+
+	// CreateUser creates a user with a temporary password that must be changed
+	// at first sign-in and returns the user's sub. Cognito emails the password
+	// when sendInvite is true.
+	CreateUser(ctx context.Context, email, name, temporaryPassword string, sendInvite bool) (sub string, err error)
+
+	// DeleteUser deletes a user from Cognito.
+	DeleteUser(ctx context.Context, userID string) error
 }
 
 // CognitoIdentityProviderAPI defines the subset of Cognito operations we use.
@@ -56,6 +67,8 @@ type CognitoIdentityProviderAPI interface {
 	AdminEnableUser(ctx context.Context, params *cognitoidentityprovider.AdminEnableUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminEnableUserOutput, error)
 	ListUsers(ctx context.Context, params *cognitoidentityprovider.ListUsersInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListUsersOutput, error)
 	AdminGetUser(ctx context.Context, params *cognitoidentityprovider.AdminGetUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminGetUserOutput, error)
+	AdminCreateUser(ctx context.Context, params *cognitoidentityprovider.AdminCreateUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminCreateUserOutput, error)
+	AdminDeleteUser(ctx context.Context, params *cognitoidentityprovider.AdminDeleteUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDeleteUserOutput, error)
 }
 
 // cognitoClient implements CognitoClient using AWS SDK v2.
@@ -175,6 +188,62 @@ func (c *cognitoClient) GetUserStatus(ctx context.Context, userID string) (bool,
 	return output.Enabled, nil
 }
 
+// CreateUser creates a user with a verified email and a temporary password.
+// The email is the username, matching users who sign up themselves.
+func (c *cognitoClient) CreateUser(ctx context.Context, email, name, temporaryPassword string, sendInvite bool) (string, error) {
+	attributes := []types.AttributeType{
+		{Name: aws.String("email"), Value: aws.String(email)},
+		{Name: aws.String("email_verified"), Value: aws.String("true")},
+	}
+	if name != "" {
+		attributes = append(attributes, types.AttributeType{Name: aws.String("name"), Value: aws.String(name)})
+	}
+
+	input := &cognitoidentityprovider.AdminCreateUserInput{
+		UserPoolId:        aws.String(c.userPoolID),
+		Username:          aws.String(email),
+		TemporaryPassword: aws.String(temporaryPassword),
+		UserAttributes:    attributes,
+	}
+	if sendInvite {
+		input.DesiredDeliveryMediums = []types.DeliveryMediumType{types.DeliveryMediumTypeEmail}
+	} else {
+		input.MessageAction = types.MessageActionTypeSuppress
+	}
+
+	output, err := c.api.AdminCreateUser(ctx, input)
+	if err != nil {
+		var exists *types.UsernameExistsException
+		if errors.As(err, &exists) {
+			return "", ErrCognitoUserExists
+		}
+		return "", c.wrapCognitoError(err, "create user")
+	}
+
+	if output.User != nil {
+		for _, attr := range output.User.Attributes {
+			if aws.ToString(attr.Name) == "sub" {
+				return aws.ToString(attr.Value), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to create user: no sub returned")
+}
+
+// DeleteUser deletes a user from Cognito.
+func (c *cognitoClient) DeleteUser(ctx context.Context, userID string) error {
+	input := &cognitoidentityprovider.AdminDeleteUserInput{
+		UserPoolId: aws.String(c.userPoolID),
+		Username:   aws.String(userID),
+	}
+
+	_, err := c.api.AdminDeleteUser(ctx, input)
+	if err != nil {
+		return c.wrapCognitoError(err, "delete user")
+	}
+	return nil
+}
+
 // SearchUsers searches for users by email in Cognito.
 func (c *cognitoClient) SearchUsers(ctx context.Context, query string, limit int) ([]CognitoUser, error) {
 	if limit <= 0 {
@@ -74,6 +74,22 @@ func (m *MockCognitoIdentityProviderAPI) AdminGetUser(ctx context.Context, param
 	return args.Get(0).(*cognitoidentityprovider.AdminGetUserOutput), args.Error(1)
 }
 
+func (m *MockCognitoIdentityProviderAPI) AdminCreateUser(ctx context.Context, params *cognitoidentityprovider.AdminCreateUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminCreateUserOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminCreateUserOutput), args.Error(1)
+}
+
+func (m *MockCognitoIdentityProviderAPI) AdminDeleteUser(ctx context.Context, params *cognitoidentityprovider.AdminDeleteUserInput, optFns ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDeleteUserOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminDeleteUserOutput), args.Error(1)
+}
+
 func TestCognitoClient_AddUserToGroup(t *testing.T) {
 	t.Run("successfully adds user to group", func(t *testing.T) {
 		ctx := context.Background()
@@ -360,6 +376,50 @@ func TestCognitoClient_EnableUser(t *testing.T) {
 	})
 }
 
+func TestCognitoClient_CreateUser(t *testing.T) {
+	t.Run("creates user without sending an invite", func(t *testing.T) {
+		ctx := context.Background()
+		mockAPI := new(MockCognitoIdentityProviderAPI)
+		userPoolID := "us-east-1_abc123"
+
+		mockAPI.On("AdminCreateUser", ctx, mock.MatchedBy(func(input *cognitoidentityprovider.AdminCreateUserInput) bool {
+			return *input.UserPoolId == userPoolID &&
+				*input.Username == "new@example.com" &&
+				*input.TemporaryPassword == "Temp-Passw0rd!" &&
+				input.MessageAction == types.MessageActionTypeSuppress &&
+				len(input.UserAttributes) == 3
+		})).Return(&cognitoidentityprovider.AdminCreateUserOutput{
+			User: &types.UserType{Attributes: []types.AttributeType{
+				{Name: aws.String("sub"), Value: aws.String("sub-123")},
+			}},
+		}, nil)
+
+		client := NewCognitoClientWithAPI(mockAPI, userPoolID)
+		sub, err := client.CreateUser(ctx, "new@example.com", "New User", "Temp-Passw0rd!", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, "sub-123", sub)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("returns ErrCognitoUserExists for a taken username", func(t *testing.T) {
+		ctx := context.Background()
+		mockAPI := new(MockCognitoIdentityProviderAPI)
+
+		mockAPI.On("AdminCreateUser", ctx, mock.MatchedBy(func(input *cognitoidentityprovider.AdminCreateUserInput) bool {
+			return input.MessageAction == "" && len(input.DesiredDeliveryMediums) == 1
+		})).Return(
+			(*cognitoidentityprovider.AdminCreateUserOutput)(nil),
+			&types.UsernameExistsException{Message: aws.String("User account already exists")},
+		)
+
+		client := NewCognitoClientWithAPI(mockAPI, "us-east-1_abc123")
+		_, err := client.CreateUser(ctx, "taken@example.com", "", "Temp-Passw0rd!", true)
+
+		assert.ErrorIs(t, err, ErrCognitoUserExists)
+	})
+}
+
 func TestCognitoClient_Constructor(t *testing.T) {
 	t.Run("creates client with user pool ID", func(t *testing.T) {
 		mockAPI := new(MockCognitoIdentityProviderAPI)
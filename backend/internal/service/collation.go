@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// SortLocaleRepository rebuilds the alphabetical browse keys of a library
+// when its sort locale changes
+type SortLocaleRepository interface {
+	SetSortLocale(ctx context.Context, userID, locale string) (int, error)
+}
+
+// libraryOwner returns the user whose settings govern the library userID
+// browses: the household owner, or the user themselves
+func libraryOwner(ctx context.Context, repo repository.Repository, userID string) (string, error) {
+	if resolver, ok := repo.(LibraryIDResolver); ok {
+		return resolver.ResolveLibraryID(ctx, userID)
+	}
+	return userID, nil
+}
+
+// librarySortLocale returns the collation locale of the library userID
+// browses, the root collation if its owner has none or no settings
+func librarySortLocale(ctx context.Context, repo repository.Repository, userID string) (string, error) {
+	ownerID, err := libraryOwner(ctx, repo, userID)
+	if err != nil {
+		return "", err
+	}
+	settings, err := repo.GetUserSettings(ctx, ownerID)
+	if errors.Is(err, repository.ErrNotFound) || errors.Is(err, repository.ErrUserNotFound) {
+		return collation.Root, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return settings.Library.SortLocale, nil
+}
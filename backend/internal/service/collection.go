@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// CollectionRepository defines the repository interface for library collections
+type CollectionRepository interface {
+	PutCollection(ctx context.Context, collection models.Collection) error
+	GetCollection(ctx context.Context, libraryID, collectionID string) (*models.Collection, error)
+	ListCollections(ctx context.Context, libraryID string) ([]models.Collection, error)
+	UpdateCollection(ctx context.Context, collection models.Collection) error
+	DeleteCollection(ctx context.Context, libraryID, collectionID string) error
+	AdjustCollectionCount(ctx context.Context, libraryID, collectionID string, delta int) error
+	SetCollectionCount(ctx context.Context, libraryID, collectionID string, count int) error
+	AddCollectionTracks(ctx context.Context, libraryID, collectionID string, trackIDs []string) error
+	RemoveCollectionTracks(ctx context.Context, libraryID, collectionID string, trackIDs []string) error
+}
+
+// CollectionService manages the named views of a library shown in the
+// sidebar. Collections belong to the library, so household members share
+// them. Track counts are counted once when a collection is created or its
+// filter changes; after that ApplyTrackChange adjusts them as tracks are
+// added, edited and deleted, so listing collections never reads the library.
+type CollectionService struct {
+	repo   CollectionRepository
+	tracks repository.Repository
+	s3Repo repository.MediaStore
+	now    func() time.Time
+
+	availability *PlaybackAvailabilityService
+}
+
+// NewCollectionService creates a new collection service. tracks is the
+// library-scoped repository; s3Repo signs cover art and may be nil where
+// collection tracks are not listed.
+func NewCollectionService(repo CollectionRepository, tracks repository.Repository, s3Repo repository.MediaStore) *CollectionService {
+	return &CollectionService{repo: repo, tracks: tracks, s3Repo: s3Repo, now: time.Now}
+}
+
+// SetPlaybackAvailability adds playback options to the tracks of collections
+func (s *CollectionService) SetPlaybackAvailability(availability *PlaybackAvailabilityService) {
+	s.availability = availability
+}
+
+// List returns the collections of the user's library in sidebar order
+func (s *CollectionService) List(ctx context.Context, userID string) ([]models.CollectionResponse, error) {
+	libraryID, err := libraryOwner(ctx, s.tracks, userID)
+	if err != nil {
+		return nil, err
+	}
+	collections, err := s.sorted(ctx, libraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.CollectionResponse, 0, len(collections))
+	for _, collection := range collections {
+		responses = append(responses, collection.ToResponse())
+	}
+	return responses, nil
+}
+
+// Create adds a collection at the end of the sidebar. Filter collections are
+// counted now; manual ones start empty.
+func (s *CollectionService) Create(ctx context.Context, userID string, req models.CreateCollectionRequest) (*models.CollectionResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, models.NewValidationError("name cannot be blank")
+	}
+	if req.Filter != nil {
+		if err := req.Filter.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	libraryID, err := libraryOwner(ctx, s.tracks, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.sorted(ctx, libraryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= models.MaxCollectionsPerLibrary {
+		return nil, models.NewValidationError(fmt.Sprintf("maximum number of collections (%d) reached", models.MaxCollectionsPerLibrary))
+	}
+
+	now := s.now()
+	collection := models.Collection{
+		ID:         uuid.New().String(),
+		UserID:     libraryID,
+		Name:       name,
+		Filter:     req.Filter,
+		Timestamps: models.Timestamps{CreatedAt: now, UpdatedAt: now},
+	}
+	if len(existing) > 0 {
+		collection.Position = existing[len(existing)-1].Position + 1
+	}
+	if collection.Filter != nil {
+		if collection.TrackCount, err = s.count(ctx, userID, &collection); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.PutCollection(ctx, collection); err != nil {
+		return nil, err
+	}
+	response := collection.ToResponse()
+	return &response, nil
+}
+
+// Update renames a collection or replaces its filter, recounting it when the
+// filter changes. A manual collection cannot be given a filter.
+func (s *CollectionService) Update(ctx context.Context, userID, collectionID string, req models.UpdateCollectionRequest) (*models.CollectionResponse, error) {
+	libraryID, collection, err := s.get(ctx, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, models.NewValidationError("name cannot be blank")
+		}
+		collection.Name = name
+	}
+	recount := false
+	if req.Filter != nil {
+		if collection.Filter == nil {
+			return nil, models.NewValidationError("tracks are added to a manual collection by hand; it cannot be given a filter")
+		}
+		if err := req.Filter.Validate(); err != nil {
+			return nil, err
+		}
+		collection.Filter = req.Filter
+		recount = true
+	}
+
+	collection.UpdatedAt = s.now()
+	if err := s.repo.UpdateCollection(ctx, *collection); err != nil {
+		return nil, s.notFound(err, collectionID)
+	}
+	if recount {
+		if collection.TrackCount, err = s.count(ctx, userID, collection); err != nil {
+			return nil, err
+		}
+		if err := s.repo.SetCollectionCount(ctx, libraryID, collectionID, collection.TrackCount); err != nil {
+			return nil, s.notFound(err, collectionID)
+		}
+	}
+
+	response := collection.ToResponse()
+	return &response, nil
+}
+
+// Delete removes a collection; its tracks stay in the library
+func (s *CollectionService) Delete(ctx context.Context, userID, collectionID string) error {
+	libraryID, _, err := s.get(ctx, userID, collectionID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteCollection(ctx, libraryID, collectionID)
+}
+
+// Reorder puts the library's collections in the sidebar order given, which
+// must list each of them once
+func (s *CollectionService) Reorder(ctx context.Context, userID string, req models.ReorderCollectionsRequest) ([]models.CollectionResponse, error) {
+	libraryID, err := libraryOwner(ctx, s.tracks, userID)
+	if err != nil {
+		return nil, err
+	}
+	collections, err := s.repo.ListCollections(ctx, libraryID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.Collection, len(collections))
+	for _, collection := range collections {
+		byID[collection.ID] = collection
+	}
+	listed := make(map[string]bool, len(req.CollectionIDs))
+	for _, id := range req.CollectionIDs {
+		if _, ok := byID[id]; !ok || listed[id] {
+			break
+		}
+		listed[id] = true
+	}
+	if len(listed) != len(req.CollectionIDs) || len(listed) != len(byID) {
+		return nil, models.NewValidationError("collectionIds must list every collection once")
+	}
+
+	now := s.now()
+	responses := make([]models.CollectionResponse, 0, len(req.CollectionIDs))
+	for position, id := range req.CollectionIDs {
+		collection := byID[id]
+		if collection.Position != position {
+			collection.Position = position
+			collection.UpdatedAt = now
+			if err := s.repo.UpdateCollection(ctx, collection); err != nil {
+				return nil, s.notFound(err, id)
+			}
+		}
+		responses = append(responses, collection.ToResponse())
+	}
+	return responses, nil
+}
+
+// AddTracks adds library tracks to a manual collection. Tracks that are
+// members already are skipped.
+func (s *CollectionService) AddTracks(ctx context.Context, userID, collectionID string, req models.CollectionTracksRequest) (*models.CollectionResponse, error) {
+	libraryID, collection, err := s.manual(ctx, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for _, trackID := range req.TrackIDs {
+		if slices.Contains(collection.TrackIDs, trackID) || slices.Contains(added, trackID) {
+			continue
+		}
+		if _, err := s.tracks.GetTrack(ctx, userID, trackID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, models.NewNotFoundError("Track", trackID)
+			}
+			return nil, err
+		}
+		added = append(added, trackID)
+	}
+	if len(collection.TrackIDs)+len(added) > models.MaxCollectionTracks {
+		return nil, models.NewValidationError(fmt.Sprintf("a collection holds at most %d tracks", models.MaxCollectionTracks))
+	}
+
+	if err := s.repo.AddCollectionTracks(ctx, libraryID, collectionID, added); err != nil {
+		return nil, s.notFound(err, collectionID)
+	}
+	collection.TrackIDs = append(collection.TrackIDs, added...)
+	collection.TrackCount += len(added)
+	response := collection.ToResponse()
+	return &response, nil
+}
+
+// RemoveTracks removes tracks from a manual collection; they stay in the
+// library. Tracks that are not members are skipped.
+func (s *CollectionService) RemoveTracks(ctx context.Context, userID, collectionID string, req models.CollectionTracksRequest) (*models.CollectionResponse, error) {
+	libraryID, collection, err := s.manual(ctx, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, trackID := range req.TrackIDs {
+		if slices.Contains(collection.TrackIDs, trackID) && !slices.Contains(removed, trackID) {
+			removed = append(removed, trackID)
+		}
+	}
+
+	if err := s.repo.RemoveCollectionTracks(ctx, libraryID, collectionID, removed); err != nil {
+		return nil, s.notFound(err, collectionID)
+	}
+	collection.TrackIDs = slices.DeleteFunc(collection.TrackIDs, func(id string) bool {
+		return slices.Contains(removed, id)
+	})
+	collection.TrackCount -= len(removed)
+	response := collection.ToResponse()
+	return &response, nil
+}
+
+// Tracks returns a page of the collection's tracks in library order. Pages
+// follow the library's pages rather than the collection, so a page can hold
+// fewer or (up to twice) more than limit tracks; HasMore says whether to go on.
+func (s *CollectionService) Tracks(ctx context.Context, userID, collectionID string, limit int, cursor string) (*repository.PaginatedResult[models.TrackResponse], error) {
+	_, collection, err := s.get(ctx, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	saveData := dataSaver(ctx, s.tracks, userID)
+	result := &repository.PaginatedResult[models.TrackResponse]{Items: []models.TrackResponse{}}
+	for {
+		page, err := s.tracks.ListTracks(ctx, userID, models.TrackFilter{Limit: limit, LastKey: cursor})
+		if err != nil {
+			return nil, err
+		}
+		for _, track := range page.Items {
+			if collection.Contains(&track) {
+				result.Items = append(result.Items, s.trackResponse(ctx, track, saveData))
+			}
+		}
+		cursor = page.NextCursor
+		result.HasMore = page.HasMore && cursor != ""
+		if !result.HasMore || len(result.Items) >= limit {
+			break
+		}
+	}
+	if result.HasMore {
+		result.NextCursor = cursor
+	}
+	return result, nil
+}
+
+// ApplyTrackChange adjusts the counts of the library's collections for a
+// track write, given the track before and after it (nil when the track did
+// not exist or was deleted). Deleted tracks also leave manual collections.
+// Collections deleted meanwhile are skipped.
+//
+// It is called once per write by the table's stream consumer (or the memory
+// repository's observer); a write applied twice, as a retried stream batch
+// may, skews the count until the collection is next recounted.
+func (s *CollectionService) ApplyTrackChange(ctx context.Context, before, after *models.Track) error {
+	var libraryID string
+	switch {
+	case after != nil:
+		libraryID = after.UserID
+	case before != nil:
+		libraryID = before.UserID
+	default:
+		return nil
+	}
+
+	collections, err := s.repo.ListCollections(ctx, libraryID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, collection := range collections {
+		var err error
+		switch {
+		case after == nil && collection.Filter == nil && collection.Contains(before):
+			err = s.repo.RemoveCollectionTracks(ctx, libraryID, collection.ID, []string{before.ID})
+		default:
+			delta := boolToInt(collection.Contains(after)) - boolToInt(collection.Contains(before))
+			if delta != 0 {
+				err = s.repo.AdjustCollectionCount(ctx, libraryID, collection.ID, delta)
+			}
+		}
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			errs = append(errs, fmt.Errorf("collection %s: %w", collection.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sorted returns the library's collections in sidebar order, oldest first
+// among equal positions
+func (s *CollectionService) sorted(ctx context.Context, libraryID string) ([]models.Collection, error) {
+	collections, err := s.repo.ListCollections(ctx, libraryID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(collections, func(i, j int) bool {
+		if collections[i].Position != collections[j].Position {
+			return collections[i].Position < collections[j].Position
+		}
+		return collections[i].CreatedAt.Before(collections[j].CreatedAt)
+	})
+	return collections, nil
+}
+
+// get returns a collection of the user's library along with the library ID
+func (s *CollectionService) get(ctx context.Context, userID, collectionID string) (string, *models.Collection, error) {
+	libraryID, err := libraryOwner(ctx, s.tracks, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	collection, err := s.repo.GetCollection(ctx, libraryID, collectionID)
+	if err != nil {
+		return "", nil, s.notFound(err, collectionID)
+	}
+	return libraryID, collection, nil
+}
+
+// manual returns a manual collection of the user's library
+func (s *CollectionService) manual(ctx context.Context, userID, collectionID string) (string, *models.Collection, error) {
+	libraryID, collection, err := s.get(ctx, userID, collectionID)
+	if err != nil {
+		return "", nil, err
+	}
+	if collection.Filter != nil {
+		return "", nil, models.NewValidationError("the tracks of a filter collection follow its filter and cannot be added or removed by hand")
+	}
+	return libraryID, collection, nil
+}
+
+// count pages through the user's library and counts the collection's tracks
+func (s *CollectionService) count(ctx context.Context, userID string, collection *models.Collection) (int, error) {
+	total := 0
+	cursor := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		page, err := s.tracks.ListTracks(ctx, userID, models.TrackFilter{Limit: countPageSize, LastKey: cursor})
+		if err != nil {
+			return 0, err
+		}
+		for _, track := range page.Items {
+			if collection.Contains(&track) {
+				total++
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			return total, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func (s *CollectionService) trackResponse(ctx context.Context, track models.Track, saveData bool) models.TrackResponse {
+	coverArtURL := ""
+	if track.CoverArtKey != "" && s.s3Repo != nil {
+		url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData), 24*time.Hour)
+		if err == nil {
+			coverArtURL = url
+		}
+	}
+	return s.availability.TrackResponse(track, coverArtURL)
+}
+
+// notFound maps a missing collection to its API error
+func (s *CollectionService) notFound(err error, collectionID string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.NewNotFoundError("Collection", collectionID)
+	}
+	return err
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCollectionTestService(t *testing.T) (*CollectionService, *repository.MemoryRepository) {
+	t.Helper()
+	repo := repository.NewMemoryRepository()
+	svc := NewCollectionService(repo, repo, nil)
+	repo.ObserveTracks(func(ctx context.Context, before, after *models.Track) {
+		require.NoError(t, svc.ApplyTrackChange(ctx, before, after))
+	})
+	return svc, repo
+}
+
+func collectionCount(t *testing.T, svc *CollectionService, id string) int {
+	t.Helper()
+	collections, err := svc.List(context.Background(), "u1")
+	require.NoError(t, err)
+	for _, collection := range collections {
+		if collection.ID == id {
+			return collection.TrackCount
+		}
+	}
+	t.Fatalf("collection %s not listed", id)
+	return 0
+}
+
+func TestCollectionService_FilterCounts(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newCollectionTestService(t)
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1", Genre: "Jazz", Format: models.AudioFormatFLAC}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t2", UserID: "u1", Genre: "Rock", Format: models.AudioFormatFLAC}))
+
+	jazz, err := svc.Create(ctx, "u1", models.CreateCollectionRequest{Name: " Jazz ", Filter: &models.CollectionFilter{Genre: "jazz"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Jazz", jazz.Name)
+	assert.Equal(t, 1, jazz.TrackCount, "counted on create")
+
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t3", UserID: "u1", Genre: "Jazz"}))
+	assert.Equal(t, 2, collectionCount(t, svc, jazz.ID))
+
+	require.NoError(t, repo.UpdateTrack(ctx, models.Track{ID: "t2", UserID: "u1", Genre: "Jazz", Format: models.AudioFormatFLAC}))
+	assert.Equal(t, 3, collectionCount(t, svc, jazz.ID), "edited into the filter")
+
+	require.NoError(t, repo.UpdateTrack(ctx, models.Track{ID: "t1", UserID: "u1", Genre: "Jazz", Format: models.AudioFormatFLAC, Title: "Renamed"}))
+	assert.Equal(t, 3, collectionCount(t, svc, jazz.ID), "edits that keep the match leave the count")
+
+	require.NoError(t, repo.DeleteTrack(ctx, "u1", "t3"))
+	assert.Equal(t, 2, collectionCount(t, svc, jazz.ID))
+
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "other", UserID: "u2", Genre: "Jazz"}))
+	assert.Equal(t, 2, collectionCount(t, svc, jazz.ID), "other libraries are not counted")
+
+	updated, err := svc.Update(ctx, "u1", jazz.ID, models.UpdateCollectionRequest{Filter: &models.CollectionFilter{Formats: []models.AudioFormat{models.AudioFormatFLAC}}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.TrackCount, "recounted when the filter changes")
+
+	tracks, err := svc.Tracks(ctx, "u1", jazz.ID, 10, "")
+	require.NoError(t, err)
+	assert.Len(t, tracks.Items, 2)
+	assert.False(t, tracks.HasMore)
+
+	_, err = svc.Create(ctx, "u1", models.CreateCollectionRequest{Name: "All", Filter: &models.CollectionFilter{}})
+	assert.Error(t, err, "an empty filter is the whole library")
+}
+
+func TestCollectionService_ManualTracks(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newCollectionTestService(t)
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1"}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t2", UserID: "u1"}))
+
+	manual, err := svc.Create(ctx, "u1", models.CreateCollectionRequest{Name: "Vinyl rips"})
+	require.NoError(t, err)
+	assert.Equal(t, models.CollectionKindManual, manual.Kind)
+	assert.Zero(t, manual.TrackCount)
+
+	manual, err = svc.AddTracks(ctx, "u1", manual.ID, models.CollectionTracksRequest{TrackIDs: []string{"t1", "t2", "t1"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, manual.TrackCount)
+	manual, err = svc.AddTracks(ctx, "u1", manual.ID, models.CollectionTracksRequest{TrackIDs: []string{"t2"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, manual.TrackCount, "members are skipped")
+
+	_, err = svc.AddTracks(ctx, "u1", manual.ID, models.CollectionTracksRequest{TrackIDs: []string{"missing"}})
+	assert.Error(t, err)
+
+	_, err = svc.Update(ctx, "u1", manual.ID, models.UpdateCollectionRequest{Filter: &models.CollectionFilter{Genre: "Jazz"}})
+	assert.Error(t, err, "manual collections cannot be given a filter")
+
+	require.NoError(t, repo.UpdateTrack(ctx, models.Track{ID: "t1", UserID: "u1", Title: "Edited"}))
+	assert.Equal(t, 2, collectionCount(t, svc, manual.ID))
+
+	require.NoError(t, repo.DeleteTrack(ctx, "u1", "t1"))
+	assert.Equal(t, 1, collectionCount(t, svc, manual.ID), "deleted tracks leave manual collections")
+	stored, err := repo.GetCollection(ctx, "u1", manual.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t2"}, stored.TrackIDs)
+
+	manual, err = svc.RemoveTracks(ctx, "u1", manual.ID, models.CollectionTracksRequest{TrackIDs: []string{"t2", "t9"}})
+	require.NoError(t, err)
+	assert.Zero(t, manual.TrackCount)
+	stored, err = repo.GetCollection(ctx, "u1", manual.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.TrackIDs)
+}
+
+func TestCollectionService_Reorder(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newCollectionTestService(t)
+
+	var ids []string
+	for _, name := range []string{"A", "B", "C"} {
+		collection, err := svc.Create(ctx, "u1", models.CreateCollectionRequest{Name: name})
+		require.NoError(t, err)
+		ids = append(ids, collection.ID)
+	}
+
+	_, err := svc.Reorder(ctx, "u1", models.ReorderCollectionsRequest{CollectionIDs: []string{ids[2], ids[0]}})
+	assert.Error(t, err, "every collection must be listed")
+	_, err = svc.Reorder(ctx, "u1", models.ReorderCollectionsRequest{CollectionIDs: []string{ids[2], ids[0], ids[0]}})
+	assert.Error(t, err, "each collection once")
+
+	_, err = svc.Reorder(ctx, "u1", models.ReorderCollectionsRequest{CollectionIDs: []string{ids[2], ids[0], ids[1]}})
+	require.NoError(t, err)
+
+	collections, err := svc.List(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, collections, 3)
+	assert.Equal(t, []string{"C", "A", "B"}, []string{collections[0].Name, collections[1].Name, collections[2].Name})
+
+	require.NoError(t, svc.Delete(ctx, "u1", ids[0]))
+	assert.Error(t, svc.Delete(ctx, "u1", ids[0]))
+}
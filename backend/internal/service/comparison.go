@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// LibraryComparisonRepository defines the repository interface for
+// comparison consents
+type LibraryComparisonRepository interface {
+	PutComparisonConsent(ctx context.Context, consent models.ComparisonConsent) error
+	GetComparisonConsent(ctx context.Context, userID, peerID string) (*models.ComparisonConsent, error)
+	DeleteComparisonConsent(ctx context.Context, userID, peerID string) error
+	ListComparisonConsents(ctx context.Context, userID string) ([]models.ComparisonConsent, error)
+	ListReceivedComparisonConsents(ctx context.Context, peerID string) ([]models.ComparisonConsent, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+// LibraryComparisonService compares the libraries of two users who have both
+// agreed to it. Recordings are matched on identical audio files, then on
+// artist, title and duration; tracks and playlists are read through the
+// library-scoped repository so each side is their whole household library.
+type LibraryComparisonService struct {
+	repo    LibraryComparisonRepository
+	library repository.Repository
+	now     func() time.Time
+}
+
+// NewLibraryComparisonService creates a new library comparison service
+func NewLibraryComparisonService(repo LibraryComparisonRepository, library repository.Repository) *LibraryComparisonService {
+	return &LibraryComparisonService{repo: repo, library: library, now: time.Now}
+}
+
+// Consent records the user's consent to compare libraries with the user
+// registered under req.Email. The comparison opens once the peer consents too.
+func (s *LibraryComparisonService) Consent(ctx context.Context, userID string, req models.CreateComparisonConsentRequest) (*models.ComparisonPeer, error) {
+	email := strings.TrimSpace(req.Email)
+	peer, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) || errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("User", email)
+		}
+		return nil, err
+	}
+	if peer.ID == userID {
+		return nil, models.NewValidationError("cannot compare a library with itself")
+	}
+	if _, _, err := s.libraries(ctx, userID, peer.ID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) || errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("User", userID)
+		}
+		return nil, err
+	}
+
+	consent := models.ComparisonConsent{
+		UserID:    userID,
+		UserEmail: user.Email,
+		PeerID:    peer.ID,
+		PeerEmail: peer.Email,
+		CreatedAt: s.now(),
+	}
+	if err := s.repo.PutComparisonConsent(ctx, consent); err != nil {
+		return nil, err
+	}
+
+	mutual, err := s.hasConsent(ctx, peer.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ComparisonPeer{UserID: peer.ID, Email: peer.Email, Mutual: mutual, ConsentedAt: consent.CreatedAt}, nil
+}
+
+// ListConsents lists the consents the user gave and received
+func (s *LibraryComparisonService) ListConsents(ctx context.Context, userID string) (*models.ComparisonConsentsResponse, error) {
+	given, err := s.repo.ListComparisonConsents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	received, err := s.repo.ListReceivedComparisonConsents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	gave := make(map[string]bool, len(given))
+	for _, consent := range given {
+		gave[consent.PeerID] = true
+	}
+	got := make(map[string]bool, len(received))
+	for _, consent := range received {
+		got[consent.UserID] = true
+	}
+
+	response := &models.ComparisonConsentsResponse{
+		Given:    make([]models.ComparisonPeer, 0, len(given)),
+		Received: make([]models.ComparisonPeer, 0, len(received)),
+	}
+	for _, consent := range given {
+		response.Given = append(response.Given, models.ComparisonPeer{
+			UserID: consent.PeerID, Email: consent.PeerEmail, Mutual: got[consent.PeerID], ConsentedAt: consent.CreatedAt,
+		})
+	}
+	for _, consent := range received {
+		response.Received = append(response.Received, models.ComparisonPeer{
+			UserID: consent.UserID, Email: consent.UserEmail, Mutual: gave[consent.UserID], ConsentedAt: consent.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// Withdraw removes the user's consent to compare with peerID, closing the
+// comparison for both of them
+func (s *LibraryComparisonService) Withdraw(ctx context.Context, userID, peerID string) error {
+	if err := s.repo.DeleteComparisonConsent(ctx, userID, peerID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.NewNotFoundError("ComparisonConsent", peerID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Compare compares the user's library with peerID's: the recordings both
+// hold, those only one holds, and the playlists of either user whose tracks
+// both libraries mostly hold, for listening together
+func (s *LibraryComparisonService) Compare(ctx context.Context, userID, peerID string) (*models.LibraryComparison, error) {
+	if peerID == userID {
+		return nil, models.NewValidationError("cannot compare a library with itself")
+	}
+	for _, pair := range [][2]string{{userID, peerID}, {peerID, userID}} {
+		ok, err := s.hasConsent(ctx, pair[0], pair[1])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, models.NewForbiddenError("comparing libraries needs the consent of both users")
+		}
+	}
+
+	mineID, theirsID, err := s.libraries(ctx, userID, peerID)
+	if err != nil {
+		return nil, err
+	}
+	mine, err := s.libraryTracks(ctx, userID, mineID)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := s.libraryTracks(ctx, peerID, theirsID)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs, onlyMine, onlyTheirs := models.MatchRecordings(mine, theirs)
+	comparison := &models.LibraryComparison{
+		PeerID:          peerID,
+		MyTrackCount:    len(mine),
+		TheirTrackCount: len(theirs),
+		OverlapCount:    len(pairs),
+		OnlyMineCount:   len(onlyMine),
+		OnlyTheirsCount: len(onlyTheirs),
+		Overlap:         make([]models.SharedRecording, 0),
+		OnlyMine:        comparedTracks(onlyMine),
+		OnlyTheirs:      comparedTracks(onlyTheirs),
+	}
+	if distinct := len(mine) + len(theirs) - len(pairs); distinct > 0 {
+		comparison.Similarity = float64(len(pairs)) / float64(distinct)
+	}
+
+	sharedMine := make(map[string]bool, len(pairs))
+	sharedTheirs := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		sharedMine[pair.Mine.ID] = true
+		sharedTheirs[pair.Theirs.ID] = true
+		if len(comparison.Overlap) < models.MaxComparisonTracks {
+			comparison.Overlap = append(comparison.Overlap, models.SharedRecording{
+				Mine:      models.NewComparedTrack(pair.Mine),
+				Theirs:    models.NewComparedTrack(pair.Theirs),
+				MatchedBy: pair.MatchedBy,
+			})
+		}
+	}
+	comparison.Truncated = len(pairs) > models.MaxComparisonTracks ||
+		len(onlyMine) > models.MaxComparisonTracks || len(onlyTheirs) > models.MaxComparisonTracks
+
+	myPlaylists, err := s.jointPlaylists(ctx, userID, sharedMine)
+	if err != nil {
+		return nil, err
+	}
+	theirPlaylists, err := s.jointPlaylists(ctx, peerID, sharedTheirs)
+	if err != nil {
+		return nil, err
+	}
+	comparison.JointPlaylists = append(myPlaylists, theirPlaylists...)
+	models.SortJointPlaylists(comparison.JointPlaylists)
+
+	return comparison, nil
+}
+
+// hasConsent reports whether userID consented to compare with peerID
+func (s *LibraryComparisonService) hasConsent(ctx context.Context, userID, peerID string) (bool, error) {
+	_, err := s.repo.GetComparisonConsent(ctx, userID, peerID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// libraries resolves the libraries of two users, refusing members of the
+// same household library, who have nothing to compare
+func (s *LibraryComparisonService) libraries(ctx context.Context, userID, peerID string) (string, string, error) {
+	mineID, err := libraryOwner(ctx, s.library, userID)
+	if err != nil {
+		return "", "", err
+	}
+	theirsID, err := libraryOwner(ctx, s.library, peerID)
+	if err != nil {
+		return "", "", err
+	}
+	if mineID == theirsID {
+		return "", "", models.NewValidationError("you already share a library with this user")
+	}
+	return mineID, theirsID, nil
+}
+
+// libraryTracks reads every track of the library userID browses. Listings
+// include other users' public tracks, which are left out.
+func (s *LibraryComparisonService) libraryTracks(ctx context.Context, userID, libraryID string) ([]models.Track, error) {
+	var tracks []models.Track
+	cursor := ""
+	for {
+		page, err := s.library.ListTracks(ctx, userID, models.TrackFilter{Limit: 100, LastKey: cursor})
+		if err != nil {
+			return nil, err
+		}
+		for _, track := range page.Items {
+			if track.UserID == libraryID {
+				tracks = append(tracks, track)
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			return tracks, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// jointPlaylists returns the playlists of userID, up to
+// MaxComparisonPlaylists of them, whose tracks are mostly in shared
+func (s *LibraryComparisonService) jointPlaylists(ctx context.Context, userID string, shared map[string]bool) ([]models.JointPlaylist, error) {
+	var joint []models.JointPlaylist
+	checked := 0
+	cursor := ""
+	for checked < models.MaxComparisonPlaylists {
+		page, err := s.library.ListPlaylists(ctx, userID, models.PlaylistFilter{Limit: 100, LastKey: cursor})
+		if err != nil {
+			return nil, err
+		}
+		for _, playlist := range page.Items {
+			if checked == models.MaxComparisonPlaylists {
+				break
+			}
+			checked++
+
+			entries, err := s.library.GetPlaylistTracks(ctx, playlist.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(entries) == 0 {
+				continue
+			}
+			held := 0
+			for _, entry := range entries {
+				if shared[entry.TrackID] {
+					held++
+				}
+			}
+			coverage := float64(held) / float64(len(entries))
+			if coverage >= models.MinJointPlaylistCoverage {
+				joint = append(joint, models.JointPlaylist{
+					ID:           playlist.ID,
+					Name:         playlist.Name,
+					OwnerID:      playlist.UserID,
+					TrackCount:   len(entries),
+					SharedTracks: held,
+					Coverage:     coverage,
+				})
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	return joint, nil
+}
+
+// comparedTracks returns the comparison view of up to MaxComparisonTracks tracks
+func comparedTracks(tracks []models.Track) []models.ComparedTrack {
+	if len(tracks) > models.MaxComparisonTracks {
+		tracks = tracks[:models.MaxComparisonTracks]
+	}
+	out := make([]models.ComparedTrack, 0, len(tracks))
+	for _, track := range tracks {
+		out = append(out, models.NewComparedTrack(track))
+	}
+	return out
+}
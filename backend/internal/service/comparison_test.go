@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibraryComparisonService(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	for _, user := range []models.User{{ID: "ann", Email: "ann@example.com"}, {ID: "bob", Email: "bob@example.com"}} {
+		require.NoError(t, repo.CreateUser(ctx, user))
+	}
+	for _, track := range []models.Track{
+		{ID: "a1", UserID: "ann", Title: "Shared File", Artist: "X", ContentHash: "h1"},
+		{ID: "a2", UserID: "ann", Title: "Same Song", Artist: "Band", Duration: 180},
+		{ID: "a3", UserID: "ann", Title: "Ann Only", Artist: "Band"},
+		{ID: "b1", UserID: "bob", Title: "Renamed", Artist: "Y", ContentHash: "h1"},
+		{ID: "b2", UserID: "bob", Title: "same song!", Artist: "band", Duration: 181},
+		{ID: "b3", UserID: "bob", Title: "Bob Only", Artist: "Band", Visibility: models.VisibilityPublic},
+	} {
+		require.NoError(t, repo.CreateTrack(ctx, track))
+	}
+	require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "joint", UserID: "ann", Name: "Together"}))
+	require.NoError(t, repo.AddTracksToPlaylist(ctx, "joint", []string{"a1", "a2"}, 0))
+	require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "solo", UserID: "bob", Name: "Mine"}))
+	require.NoError(t, repo.AddTracksToPlaylist(ctx, "solo", []string{"b1", "b3"}, 0))
+
+	svc := NewLibraryComparisonService(repo, repo)
+
+	t.Run("needs both users to consent", func(t *testing.T) {
+		peer, err := svc.Consent(ctx, "ann", models.CreateComparisonConsentRequest{Email: "bob@example.com"})
+		require.NoError(t, err)
+		assert.False(t, peer.Mutual)
+
+		_, err = svc.Compare(ctx, "ann", "bob")
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "FORBIDDEN", apiErr.Code)
+
+		peer, err = svc.Consent(ctx, "bob", models.CreateComparisonConsentRequest{Email: "ann@example.com"})
+		require.NoError(t, err)
+		assert.True(t, peer.Mutual)
+
+		consents, err := svc.ListConsents(ctx, "ann")
+		require.NoError(t, err)
+		require.Len(t, consents.Given, 1)
+		require.Len(t, consents.Received, 1)
+		assert.Equal(t, "bob@example.com", consents.Given[0].Email)
+		assert.Equal(t, "bob@example.com", consents.Received[0].Email)
+		assert.True(t, consents.Received[0].Mutual)
+	})
+
+	t.Run("compares the libraries", func(t *testing.T) {
+		comparison, err := svc.Compare(ctx, "ann", "bob")
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, comparison.OverlapCount)
+		require.Len(t, comparison.Overlap, 2)
+		assert.Equal(t, models.MatchContent, comparison.Overlap[0].MatchedBy)
+		assert.Equal(t, models.MatchMetadata, comparison.Overlap[1].MatchedBy)
+		require.Len(t, comparison.OnlyMine, 1)
+		assert.Equal(t, "a3", comparison.OnlyMine[0].ID)
+		require.Len(t, comparison.OnlyTheirs, 1)
+		assert.Equal(t, "b3", comparison.OnlyTheirs[0].ID, "bob's public track counts once, as his")
+		assert.Equal(t, 3, comparison.MyTrackCount)
+		assert.InDelta(t, 0.5, comparison.Similarity, 0.001)
+
+		require.Len(t, comparison.JointPlaylists, 1)
+		assert.Equal(t, "joint", comparison.JointPlaylists[0].ID)
+		assert.Equal(t, 1.0, comparison.JointPlaylists[0].Coverage)
+	})
+
+	t.Run("withdrawing closes the comparison", func(t *testing.T) {
+		require.NoError(t, svc.Withdraw(ctx, "bob", "ann"))
+		_, err := svc.Compare(ctx, "ann", "bob")
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "FORBIDDEN", apiErr.Code)
+
+		assert.Equal(t, models.NewNotFoundError("ComparisonConsent", "ann"), svc.Withdraw(ctx, "bob", "ann"))
+	})
+
+	t.Run("rejects comparing with yourself", func(t *testing.T) {
+		_, err := svc.Consent(ctx, "ann", models.CreateComparisonConsentRequest{Email: "ann@example.com"})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+	})
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// CountRepository counts library items with Select=COUNT queries instead of
+// reading them. The DynamoDB, in-memory and library-scoped repositories
+// implement it; services fall back to paging through the list otherwise.
+type CountRepository interface {
+	CountTracks(ctx context.Context, userID string) (int, error)
+	CountAllTracks(ctx context.Context) (int, error)
+	CountAlbums(ctx context.Context, userID string) (int, error)
+	CountPlaylists(ctx context.Context, userID string) (int, error)
+}
+
+// countPageSize is the page size used when counting by listing
+const countPageSize = 100
+
+// countWith counts through the repository's CountRepository when it has one,
+// and pages through list otherwise. A library-scoped repository whose wrapped
+// repository cannot count reports ErrNotFound, which also falls back.
+func countWith[T any](ctx context.Context, repo repository.Repository, count func(CountRepository) (int, error), list func(cursor string) (*repository.PaginatedResult[T], error)) (int, error) {
+	if counter, ok := repo.(CountRepository); ok {
+		n, err := count(counter)
+		if !errors.Is(err, repository.ErrNotFound) {
+			return n, err
+		}
+	}
+
+	total := 0
+	cursor := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		page, err := list(cursor)
+		if err != nil {
+			return 0, err
+		}
+		total += len(page.Items)
+		if !page.HasMore || page.NextCursor == "" {
+			return total, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CountTracks returns the number of tracks in the user's library, or across
+// all users when hasGlobal is set. Public tracks of other users, which
+// ListTracks mixes into a regular user's pages, are not counted.
+func (s *trackService) CountTracks(ctx context.Context, userID string, hasGlobal bool) (int, error) {
+	return countWith(ctx, s.repo,
+		func(counter CountRepository) (int, error) {
+			if hasGlobal {
+				return counter.CountAllTracks(ctx)
+			}
+			return counter.CountTracks(ctx, userID)
+		},
+		func(cursor string) (*repository.PaginatedResult[models.Track], error) {
+			return s.repo.ListTracks(ctx, userID, models.TrackFilter{Limit: countPageSize, LastKey: cursor, GlobalScope: hasGlobal})
+		})
+}
+
+// CountAlbums returns the number of albums in the user's library
+func (s *albumService) CountAlbums(ctx context.Context, userID string) (int, error) {
+	return countWith(ctx, s.repo,
+		func(counter CountRepository) (int, error) {
+			return counter.CountAlbums(ctx, userID)
+		},
+		func(cursor string) (*repository.PaginatedResult[models.Album], error) {
+			return s.repo.ListAlbums(ctx, userID, models.AlbumFilter{Limit: countPageSize, LastKey: cursor})
+		})
+}
+
+// CountPlaylists returns the number of playlists the user owns
+func (s *playlistService) CountPlaylists(ctx context.Context, userID string) (int, error) {
+	return countWith(ctx, s.repo,
+		func(counter CountRepository) (int, error) {
+			return counter.CountPlaylists(ctx, userID)
+		},
+		func(cursor string) (*repository.PaginatedResult[models.Playlist], error) {
+			return s.repo.ListPlaylists(ctx, userID, models.PlaylistFilter{Limit: countPageSize, LastKey: cursor})
+		})
+}
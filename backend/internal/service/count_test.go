@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCountTestRepo(t *testing.T) *repository.MemoryRepository {
+	t.Helper()
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	for i := 0; i < 150; i++ {
+		require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: fmt.Sprintf("t%03d", i), UserID: "u1"}))
+	}
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "other", UserID: "u2"}))
+	for _, name := range []string{"Untrue", "Rival Dealer"} {
+		_, err := repo.GetOrCreateAlbum(ctx, "u1", name, "Burial")
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "p1", UserID: "u1", Name: "Night bus"}))
+	return repo
+}
+
+func TestCountServices(t *testing.T) {
+	ctx := context.Background()
+	repo := newCountTestRepo(t)
+
+	// plainRepository hides the counting methods, so the services page
+	// through the lists instead; both must agree
+	for name, r := range map[string]repository.Repository{
+		"select count": repo,
+		"listing":      plainRepository{repo},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tracks := &trackService{repo: r}
+			count, err := tracks.CountTracks(ctx, "u1", false)
+			require.NoError(t, err)
+			assert.Equal(t, 150, count)
+
+			count, err = tracks.CountTracks(ctx, "u1", true)
+			require.NoError(t, err)
+			assert.Equal(t, 151, count, "global viewers count every user's tracks")
+
+			count, err = (&albumService{repo: r}).CountAlbums(ctx, "u1")
+			require.NoError(t, err)
+			assert.Equal(t, 2, count)
+
+			count, err = (&playlistService{repo: r}).CountPlaylists(ctx, "u2")
+			require.NoError(t, err)
+			assert.Zero(t, count)
+		})
+	}
+}
+
+func TestCountTracks_LibraryScoped(t *testing.T) {
+	ctx := context.Background()
+	repo := newCountTestRepo(t)
+	scoped := repository.NewLibraryScopedRepository(repo, func(ctx context.Context, userID string) (string, error) {
+		if userID == "member" {
+			return "u1", nil
+		}
+		return userID, nil
+	})
+
+	count, err := (&trackService{repo: scoped}).CountTracks(ctx, "member", false)
+	require.NoError(t, err)
+	assert.Equal(t, 150, count, "household members count the shared library")
+
+	plainScoped := repository.NewLibraryScopedRepository(plainRepository{repo}, func(ctx context.Context, userID string) (string, error) {
+		return "u1", nil
+	})
+	count, err = (&trackService{repo: plainScoped}).CountTracks(ctx, "member", false)
+	require.NoError(t, err)
+	assert.Equal(t, 150, count, "falls back to listing when the wrapped repository cannot count")
+}
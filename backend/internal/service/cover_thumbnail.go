@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gvasels/personal-music-searchengine/internal/artwork"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// thumbnailScanPageSize is how many items the thumbnail job scans per page
+const thumbnailScanPageSize = 500
+
+// DefaultThumbnailBatch bounds the thumbnails one run makes, so a run stays
+// well inside the job queue's visibility timeout
+const DefaultThumbnailBatch = 100
+
+// ObjectReadWriter reads and writes whole objects of the media store. Every
+// store the API selects implements it.
+type ObjectReadWriter interface {
+	ReadObject(ctx context.Context, key string) (io.ReadCloser, error)
+	WriteObject(ctx context.Context, key string, data []byte, contentType string) error
+}
+
+// CoverThumbnailRepository defines the repository interface for the
+// thumbnail job. Tracks are addressed by their owner, as they are scanned.
+type CoverThumbnailRepository interface {
+	ScanTrackItems(ctx context.Context, cursor string, limit int) (*repository.PaginatedResult[models.TrackItem], error)
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	UpdateTrack(ctx context.Context, track models.Track) error
+}
+
+// CoverThumbnailService makes the cover thumbnails data saver lists show for
+// tracks that lack one. The cover art step of the upload pipeline makes them
+// as it analyzes a cover; this fills in those it failed to store, and those of
+// tracks analyzed before thumbnails existed.
+type CoverThumbnailService struct {
+	repo    CoverThumbnailRepository
+	objects ObjectReadWriter
+	batch   int
+}
+
+// NewCoverThumbnailService creates a new cover thumbnail service
+func NewCoverThumbnailService(repo CoverThumbnailRepository, objects ObjectReadWriter) *CoverThumbnailService {
+	return &CoverThumbnailService{repo: repo, objects: objects, batch: DefaultThumbnailBatch}
+}
+
+// GenerateMissing makes up to DefaultThumbnailBatch missing thumbnails across
+// every library and returns how many it made. Only covers the pipeline could
+// analyze are thumbnailed; one that fails is logged and left for the next run.
+func (s *CoverThumbnailService) GenerateMissing(ctx context.Context) (int, error) {
+	made := 0
+	cursor := ""
+	for {
+		page, err := s.repo.ScanTrackItems(ctx, cursor, thumbnailScanPageSize)
+		if err != nil {
+			return made, err
+		}
+		for _, item := range page.Items {
+			if !needsThumbnail(item.Track) {
+				continue
+			}
+			if err := s.generate(ctx, item.UserID, item.ID); err != nil {
+				fmt.Printf("Warning: failed to make cover thumbnail of track %s: %v\n", item.ID, err)
+				continue
+			}
+			made++
+			if made == s.batch {
+				return made, nil
+			}
+		}
+		if page.NextCursor == "" {
+			return made, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// needsThumbnail reports whether a track has an analyzed cover but no
+// thumbnail of it
+func needsThumbnail(track models.Track) bool {
+	return track.CoverArtKey != "" && track.CoverStyle != nil && track.CoverStyle.ThumbnailKey == ""
+}
+
+// generate stores the thumbnail of one track's cover and records it on the
+// track, read again so edits since the scan are kept
+func (s *CoverThumbnailService) generate(ctx context.Context, ownerID, trackID string) error {
+	track, err := s.repo.GetTrack(ctx, ownerID, trackID)
+	if err != nil {
+		return err
+	}
+	if !needsThumbnail(*track) {
+		return nil
+	}
+
+	body, err := s.objects.ReadObject(ctx, track.CoverArtKey)
+	if err != nil {
+		return err
+	}
+	cover, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read cover: %w", err)
+	}
+	thumbnail, err := artwork.Thumbnail(cover, artwork.ThumbnailEdge)
+	if err != nil {
+		return err
+	}
+
+	key := coverThumbnailKey(track.UserID, track.ID)
+	if err := s.objects.WriteObject(ctx, key, thumbnail, "image/jpeg"); err != nil {
+		return err
+	}
+	style := *track.CoverStyle
+	style.ThumbnailKey = key
+	track.CoverStyle = &style
+	return s.repo.UpdateTrack(ctx, *track)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func coverPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 600, 600))
+	for x := 0; x < 600; x++ {
+		for y := 0; y < 600; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x / 3), G: 80, B: uint8(y / 3), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestCoverThumbnailService(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	s3Repo := repository.NewMemoryS3Repository("")
+	require.NoError(t, s3Repo.WriteObject(ctx, "covers/u1/cover.png", coverPNG(t), "image/png"))
+	require.NoError(t, s3Repo.WriteObject(ctx, "covers/u1/broken.png", []byte("not an image"), "image/png"))
+
+	missing := builders.NewTrackBuilder("u1", "missing").WithCoverArt("covers/u1/cover.png").Build()
+	missing.CoverStyle = &models.CoverStyle{Colors: []string{"#502850"}}
+	broken := builders.NewTrackBuilder("u1", "broken").WithCoverArt("covers/u1/broken.png").Build()
+	broken.CoverStyle = &models.CoverStyle{}
+	done := builders.NewTrackBuilder("u1", "done").WithCoverArt("covers/u1/cover.png").Build()
+	done.CoverStyle = &models.CoverStyle{ThumbnailKey: "covers/u1/done-thumb.jpg"}
+	unanalyzed := builders.NewTrackBuilder("u1", "unanalyzed").WithCoverArt("covers/u1/cover.png").Build()
+	for _, track := range []models.Track{missing, broken, done, unanalyzed, builders.NewTrackBuilder("u2", "plain").Build()} {
+		require.NoError(t, repo.CreateTrack(ctx, track))
+	}
+
+	svc := NewCoverThumbnailService(repo, s3Repo)
+	made, err := svc.GenerateMissing(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, made, "the broken cover is logged and skipped")
+
+	track, err := repo.GetTrack(ctx, "u1", "missing")
+	require.NoError(t, err)
+	require.NotNil(t, track.CoverStyle)
+	assert.Equal(t, "covers/u1/missing-thumb.jpg", track.CoverStyle.ThumbnailKey)
+	assert.Equal(t, []string{"#502850"}, track.CoverStyle.Colors, "the rest of the style is kept")
+
+	body, err := s3Repo.ReadObject(ctx, "covers/u1/missing-thumb.jpg")
+	require.NoError(t, err)
+	thumbnail, err := io.ReadAll(body)
+	require.NoError(t, err)
+	config, format, err := image.DecodeConfig(bytes.NewReader(thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 256, config.Width)
+
+	track, err = repo.GetTrack(ctx, "u1", "unanalyzed")
+	require.NoError(t, err)
+	assert.Nil(t, track.CoverStyle, "covers the pipeline could not analyze are left alone")
+
+	made, err = svc.GenerateMissing(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, made)
+}
+
+func TestCoverThumbnailService_Batch(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	s3Repo := repository.NewMemoryS3Repository("")
+	require.NoError(t, s3Repo.WriteObject(ctx, "covers/u1/cover.png", coverPNG(t), "image/png"))
+	for _, id := range []string{"t1", "t2", "t3"} {
+		track := builders.NewTrackBuilder("u1", id).WithCoverArt("covers/u1/cover.png").Build()
+		track.CoverStyle = &models.CoverStyle{}
+		require.NoError(t, repo.CreateTrack(ctx, track))
+	}
+
+	svc := NewCoverThumbnailService(repo, s3Repo)
+	svc.batch = 2
+	made, err := svc.GenerateMissing(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, made)
+
+	made, err = svc.GenerateMissing(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, made, "the next run picks up the rest")
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// UserSettingsReader reads a user's settings. Repository implements it; the
+// narrower repositories of some services may too.
+type UserSettingsReader interface {
+	GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error)
+}
+
+// dataSaver reports whether userID turned on data saver, false when reader
+// is nil or their settings cannot be read
+func dataSaver(ctx context.Context, reader UserSettingsReader, userID string) bool {
+	if reader == nil || userID == "" {
+		return false
+	}
+	settings, err := reader.GetUserSettings(ctx, userID)
+	if err != nil || settings == nil {
+		return false
+	}
+	return settings.Player.DataSaver
+}
+
+// coverThumbnailKey is where a track's cover thumbnail is stored when the
+// server makes it, or copies it as the track is copied or moved to another
+// user
+func coverThumbnailKey(userID, trackID string) string {
+	return fmt.Sprintf("covers/%s/%s-thumb.jpg", userID, trackID)
+}
+
+// copyCoverStyle returns the style of a copied cover, copying its thumbnail
+// to key. The copy has no thumbnail if that fails.
+func copyCoverStyle(ctx context.Context, s3Repo repository.MediaStore, style *models.CoverStyle, key string) *models.CoverStyle {
+	if style == nil || style.ThumbnailKey == "" {
+		return style
+	}
+	copied := *style
+	copied.ThumbnailKey = ""
+	if err := s3Repo.CopyObject(ctx, style.ThumbnailKey, key); err == nil {
+		copied.ThumbnailKey = key
+	}
+	return &copied
+}
+
+// deleteCoverThumbnail removes the thumbnail of a cover (best effort)
+func deleteCoverThumbnail(ctx context.Context, s3Repo repository.MediaStore, style *models.CoverStyle) {
+	if style != nil && style.ThumbnailKey != "" {
+		_ = s3Repo.DeleteObject(ctx, style.ThumbnailKey)
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// fakeSigner signs CloudFront URLs as cdn://{key}
+type fakeSigner struct{}
+
+func (fakeSigner) GenerateSignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "cdn://" + key, nil
+}
+
+func (fakeSigner) GenerateSignedDownloadURL(ctx context.Context, key string, expiry time.Duration, filename string) (string, error) {
+	return "cdn://" + key, nil
+}
+
+func newDataSaverFixture(t *testing.T) (*repository.MemoryRepository, *repository.MemoryS3Repository) {
+	t.Helper()
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	s3 := repository.NewMemoryS3Repository("http://localhost/media")
+
+	saver := models.DefaultUserSettings()
+	saver.Player.DataSaver = true
+	require.NoError(t, repo.CreateUser(ctx, models.User{ID: "saver", Settings: saver}))
+	require.NoError(t, repo.CreateUser(ctx, models.User{ID: "full", Settings: models.DefaultUserSettings()}))
+
+	for _, userID := range []string{"saver", "full"} {
+		require.NoError(t, repo.CreateTrack(ctx, models.Track{
+			ID:             userID + "-t1",
+			UserID:         userID,
+			Artist:         "Burial",
+			CoverArtKey:    "covers/" + userID + "/up1.png",
+			CoverStyle:     &models.CoverStyle{ThumbnailKey: "covers/" + userID + "/up1-thumb.jpg"},
+			HLSStatus:      models.HLSStatusReady,
+			HLSPlaylistKey: "hls/" + userID + "/" + userID + "-t1/master.m3u8",
+		}))
+	}
+	return repo, s3
+}
+
+func TestDataSaver_ListThumbnails(t *testing.T) {
+	ctx := context.Background()
+	repo, s3 := newDataSaverFixture(t)
+	svc := NewTrackService(repo, s3)
+
+	tracks, err := svc.ListTracksByArtist(ctx, "saver", "Burial")
+	require.NoError(t, err)
+	require.Len(t, tracks, 1)
+	assert.True(t, strings.HasPrefix(tracks[0].CoverArtURL, "http://localhost/media/covers/saver/up1-thumb.jpg?"))
+
+	tracks, err = svc.ListTracksByArtist(ctx, "full", "Burial")
+	require.NoError(t, err)
+	require.Len(t, tracks, 1)
+	assert.True(t, strings.HasPrefix(tracks[0].CoverArtURL, "http://localhost/media/covers/full/up1.png?"))
+}
+
+func TestDataSaver_StreamVariant(t *testing.T) {
+	ctx := context.Background()
+	repo, s3 := newDataSaverFixture(t)
+	svc := NewStreamService(repo, fakeSigner{}, s3)
+
+	resp, err := svc.GetStreamURL(ctx, "saver", "saver-t1", false)
+	require.NoError(t, err)
+	assert.Equal(t, "cdn://hls/saver/saver-t1/master96k.m3u8", resp.HLSURL)
+	assert.Equal(t, HLSVariantLow, resp.HLSVariant)
+
+	resp, err = svc.GetStreamURL(ctx, "full", "full-t1", false)
+	require.NoError(t, err)
+	assert.Equal(t, "cdn://hls/full/full-t1/master.m3u8", resp.HLSURL)
+	assert.Empty(t, resp.HLSVariant)
+}
+
+func TestCopyCoverStyle(t *testing.T) {
+	ctx := context.Background()
+	s3 := repository.NewMemoryS3Repository("")
+	s3.PutObject("covers/u1/up1-thumb.jpg", nil)
+	style := &models.CoverStyle{BlurHash: "hash", ThumbnailKey: "covers/u1/up1-thumb.jpg"}
+
+	copied := copyCoverStyle(ctx, s3, style, coverThumbnailKey("u2", "t9"))
+	assert.Equal(t, "covers/u2/t9-thumb.jpg", copied.ThumbnailKey)
+	assert.Equal(t, "hash", copied.BlurHash)
+	assert.Equal(t, "covers/u1/up1-thumb.jpg", style.ThumbnailKey, "source style is unchanged")
+	assert.Contains(t, s3.Keys("covers/u2/"), "covers/u2/t9-thumb.jpg")
+
+	missing := &models.CoverStyle{ThumbnailKey: "covers/u1/gone-thumb.jpg"}
+	assert.Empty(t, copyCoverStyle(ctx, s3, missing, "covers/u2/t10-thumb.jpg").ThumbnailKey)
+	assert.Nil(t, copyCoverStyle(ctx, s3, nil, "covers/u2/t11-thumb.jpg"))
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// FeaturedRepository defines the repository interface for featured items
+type FeaturedRepository interface {
+	PutFeaturedItem(ctx context.Context, item models.FeaturedItem) error
+	GetFeaturedItem(ctx context.Context, itemID string) (*models.FeaturedItem, error)
+	ListFeaturedItems(ctx context.Context) ([]models.FeaturedItem, error)
+	DeleteFeaturedItem(ctx context.Context, itemID string) error
+}
+
+// DiscoverService builds the discover feed and manages the featured items
+// admins curate for it. The feed shows the items in effect ahead of the
+// public playlists, artist profiles and tracks listed algorithmically;
+// featured playlists and artists that were since made private or deleted
+// are left out rather than failing the feed.
+type DiscoverService struct {
+	featured FeaturedRepository
+	repo     repository.Repository
+	s3Repo   repository.MediaStore
+	now      func() time.Time
+
+	availability *PlaybackAvailabilityService
+}
+
+// NewDiscoverService creates a new discover service. repo must not be
+// library-scoped, since featured playlists are read from their owners'
+// partitions; s3Repo signs cover art and may be nil.
+func NewDiscoverService(featured FeaturedRepository, repo repository.Repository, s3Repo repository.MediaStore) *DiscoverService {
+	return &DiscoverService{featured: featured, repo: repo, s3Repo: s3Repo, now: time.Now}
+}
+
+// SetPlaybackAvailability adds playback options to the feed's new tracks
+func (s *DiscoverService) SetPlaybackAvailability(availability *PlaybackAvailabilityService) {
+	s.availability = availability
+}
+
+// Discover returns the discover feed as userID sees it
+func (s *DiscoverService) Discover(ctx context.Context, userID string) (*models.DiscoverResponse, error) {
+	items, err := s.sorted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &models.DiscoverResponse{
+		Sections:  []models.FeaturedBlurb{},
+		Playlists: []models.DiscoverPlaylist{},
+		Artists:   []models.DiscoverArtist{},
+		NewTracks: []models.TrackResponse{},
+	}
+	playlists := make(map[string]bool)
+	artists := make(map[string]bool)
+
+	now := s.now()
+	for _, item := range items {
+		if item.Status(now) != models.FeaturedActive {
+			continue
+		}
+		blurb := item.Blurb()
+		switch item.Kind {
+		case models.FeaturedKindSection:
+			feed.Sections = append(feed.Sections, blurb)
+		case models.FeaturedKindPlaylist:
+			if playlists[item.PlaylistID] {
+				continue
+			}
+			playlist, err := s.publicPlaylist(ctx, item.OwnerID, item.PlaylistID)
+			if err != nil {
+				return nil, err
+			}
+			if playlist != nil {
+				playlists[playlist.ID] = true
+				feed.Playlists = append(feed.Playlists, models.DiscoverPlaylist{PlaylistResponse: s.playlistResponse(ctx, *playlist), Featured: &blurb})
+			}
+		case models.FeaturedKindArtist:
+			if artists[item.OwnerID] {
+				continue
+			}
+			profile, err := s.repo.GetArtistProfile(ctx, item.OwnerID)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			artists[profile.UserID] = true
+			feed.Artists = append(feed.Artists, models.DiscoverArtist{ArtistProfileResponse: profile.ToResponse(), Featured: &blurb})
+		}
+	}
+
+	// Curated entries come first; the listings fill the rest of each list
+	if len(feed.Playlists) < models.DiscoverListSize {
+		public, err := s.repo.ListPublicPlaylists(ctx, models.DiscoverListSize, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, playlist := range public.Items {
+			if len(feed.Playlists) >= models.DiscoverListSize {
+				break
+			}
+			if !playlists[playlist.ID] {
+				playlists[playlist.ID] = true
+				feed.Playlists = append(feed.Playlists, models.DiscoverPlaylist{PlaylistResponse: s.playlistResponse(ctx, playlist)})
+			}
+		}
+	}
+	if len(feed.Artists) < models.DiscoverListSize {
+		profiles, err := s.repo.ListArtistProfiles(ctx, models.DiscoverListSize, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, profile := range profiles.Items {
+			if len(feed.Artists) >= models.DiscoverListSize {
+				break
+			}
+			if !artists[profile.UserID] {
+				artists[profile.UserID] = true
+				feed.Artists = append(feed.Artists, models.DiscoverArtist{ArtistProfileResponse: profile.ToResponse()})
+			}
+		}
+	}
+
+	tracks, err := s.repo.ListPublicTracks(ctx, models.DiscoverListSize, "")
+	if err != nil {
+		return nil, err
+	}
+	saveData := dataSaver(ctx, s.repo, userID)
+	for _, track := range tracks.Items {
+		feed.NewTracks = append(feed.NewTracks, s.trackResponse(ctx, track, saveData))
+	}
+
+	return feed, nil
+}
+
+// ListFeatured returns every featured item with its schedule status, in feed order
+func (s *DiscoverService) ListFeatured(ctx context.Context) ([]models.FeaturedItemResponse, error) {
+	items, err := s.sorted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	responses := make([]models.FeaturedItemResponse, 0, len(items))
+	for _, item := range items {
+		responses = append(responses, item.ToResponse(now))
+	}
+	return responses, nil
+}
+
+// CreateFeatured features a public playlist, an artist profile or an
+// editorial section
+func (s *DiscoverService) CreateFeatured(ctx context.Context, adminID string, req models.CreateFeaturedItemRequest) (*models.FeaturedItemResponse, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch req.Kind {
+	case models.FeaturedKindPlaylist:
+		playlist, err := s.publicPlaylist(ctx, req.OwnerID, req.PlaylistID)
+		if err != nil {
+			return nil, err
+		}
+		if playlist == nil {
+			return nil, models.NewValidationError("only an existing public playlist can be featured")
+		}
+	case models.FeaturedKindArtist:
+		if _, err := s.repo.GetArtistProfile(ctx, req.OwnerID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, models.NewNotFoundError("artist profile", req.OwnerID)
+			}
+			return nil, err
+		}
+	default:
+		req.OwnerID, req.PlaylistID = "", ""
+	}
+
+	existing, err := s.featured.ListFeaturedItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= models.MaxFeaturedItems {
+		return nil, models.NewValidationError(fmt.Sprintf("maximum number of featured items (%d) reached; delete expired ones first", models.MaxFeaturedItems))
+	}
+
+	now := s.now()
+	item := models.FeaturedItem{
+		ID:            uuid.New().String(),
+		Kind:          req.Kind,
+		OwnerID:       req.OwnerID,
+		PlaylistID:    req.PlaylistID,
+		Title:         req.Title,
+		Body:          req.Body,
+		LinkURL:       req.LinkURL,
+		Position:      req.Position,
+		FeaturedFrom:  req.FeaturedFrom,
+		FeaturedUntil: req.FeaturedUntil,
+		CreatedBy:     adminID,
+		Timestamps:    models.Timestamps{CreatedAt: now, UpdatedAt: now},
+	}
+	if err := s.featured.PutFeaturedItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	response := item.ToResponse(now)
+	return &response, nil
+}
+
+// UpdateFeatured replaces the text, position and schedule of a featured item
+func (s *DiscoverService) UpdateFeatured(ctx context.Context, itemID string, req models.UpdateFeaturedItemRequest) (*models.FeaturedItemResponse, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	if err := req.FeaturedSchedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	item, err := s.featured.GetFeaturedItem(ctx, itemID)
+	if err != nil {
+		return nil, s.notFound(err, itemID)
+	}
+	if item.Kind == models.FeaturedKindSection && req.Title == "" {
+		return nil, models.NewValidationError("an editorial section needs a title")
+	}
+
+	now := s.now()
+	item.Title = req.Title
+	item.Body = req.Body
+	item.LinkURL = req.LinkURL
+	item.Position = req.Position
+	item.FeaturedFrom = req.FeaturedFrom
+	item.FeaturedUntil = req.FeaturedUntil
+	item.UpdatedAt = now
+	if err := s.featured.PutFeaturedItem(ctx, *item); err != nil {
+		return nil, err
+	}
+
+	response := item.ToResponse(now)
+	return &response, nil
+}
+
+// DeleteFeatured stops featuring an item
+func (s *DiscoverService) DeleteFeatured(ctx context.Context, itemID string) error {
+	if _, err := s.featured.GetFeaturedItem(ctx, itemID); err != nil {
+		return s.notFound(err, itemID)
+	}
+	return s.featured.DeleteFeaturedItem(ctx, itemID)
+}
+
+// sorted returns every featured item in feed order, oldest first among
+// equal positions
+func (s *DiscoverService) sorted(ctx context.Context) ([]models.FeaturedItem, error) {
+	items, err := s.featured.ListFeaturedItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Position != items[j].Position {
+			return items[i].Position < items[j].Position
+		}
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+// publicPlaylist returns a playlist if it exists and is public, nil otherwise
+func (s *DiscoverService) publicPlaylist(ctx context.Context, ownerID, playlistID string) (*models.Playlist, error) {
+	playlist, err := s.repo.GetPlaylist(ctx, ownerID, playlistID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !playlist.Visibility.IsDiscoverable() {
+		return nil, nil
+	}
+	return playlist, nil
+}
+
+func (s *DiscoverService) playlistResponse(ctx context.Context, playlist models.Playlist) models.PlaylistResponse {
+	response := playlist.ToResponse(s.coverArtURL(ctx, playlist.CoverArtKey))
+	if tracks, err := s.repo.GetPlaylistTracks(ctx, playlist.ID); err == nil {
+		response.TrackCount = len(tracks)
+	}
+	return response
+}
+
+func (s *DiscoverService) trackResponse(ctx context.Context, track models.Track, saveData bool) models.TrackResponse {
+	coverArtURL := ""
+	if track.CoverArtKey != "" {
+		coverArtURL = s.coverArtURL(ctx, track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData))
+	}
+	return s.availability.TrackResponse(track, coverArtURL)
+}
+
+func (s *DiscoverService) coverArtURL(ctx context.Context, key string) string {
+	if key == "" || s.s3Repo == nil {
+		return ""
+	}
+	url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, key, 24*time.Hour)
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// notFound maps a missing featured item to its API error
+func (s *DiscoverService) notFound(err error, itemID string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.NewNotFoundError("Featured item", itemID)
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverService_Discover(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	svc := NewDiscoverService(repo, repo, nil)
+	svc.now = func() time.Time { return now }
+
+	require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "a-public", UserID: "u1", Name: "Public", Visibility: models.VisibilityPublic}))
+	require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "z-curated", UserID: "u2", Name: "Curated", Visibility: models.VisibilityPublic}))
+	require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "private", UserID: "u2", Name: "Private", Visibility: models.VisibilityPrivate}))
+	require.NoError(t, repo.CreateArtistProfile(ctx, models.ArtistProfile{UserID: "artist-1", DisplayName: "Burial"}))
+	require.NoError(t, repo.CreateArtistProfile(ctx, models.ArtistProfile{UserID: "artist-2", DisplayName: "Kode9"}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t1", UserID: "u1", Title: "Archangel", Visibility: models.VisibilityPublic}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "t2", UserID: "u1", Title: "Unreleased", Visibility: models.VisibilityPrivate}))
+
+	_, err := svc.CreateFeatured(ctx, "admin", models.CreateFeaturedItemRequest{Kind: models.FeaturedKindPlaylist, OwnerID: "u2", PlaylistID: "private"})
+	assert.Error(t, err, "private playlists cannot be featured")
+
+	playlist, err := svc.CreateFeatured(ctx, "admin", models.CreateFeaturedItemRequest{Kind: models.FeaturedKindPlaylist, OwnerID: "u2", PlaylistID: "z-curated", Title: "Staff pick"})
+	require.NoError(t, err)
+	assert.Equal(t, models.FeaturedActive, playlist.Status)
+	_, err = svc.CreateFeatured(ctx, "admin", models.CreateFeaturedItemRequest{Kind: models.FeaturedKindArtist, OwnerID: "artist-2"})
+	require.NoError(t, err)
+	_, err = svc.CreateFeatured(ctx, "admin", models.CreateFeaturedItemRequest{Kind: models.FeaturedKindSection, Title: "Summer sounds", Position: 1})
+	require.NoError(t, err)
+
+	later := now.Add(24 * time.Hour)
+	scheduled := models.CreateFeaturedItemRequest{Kind: models.FeaturedKindSection, Title: "Coming soon"}
+	scheduled.FeaturedFrom = &later
+	upcoming, err := svc.CreateFeatured(ctx, "admin", scheduled)
+	require.NoError(t, err)
+	assert.Equal(t, models.FeaturedScheduled, upcoming.Status)
+
+	feed, err := svc.Discover(ctx, "viewer")
+	require.NoError(t, err)
+	require.Len(t, feed.Sections, 1, "scheduled sections wait for featuredFrom")
+	assert.Equal(t, "Summer sounds", feed.Sections[0].Title)
+
+	require.Len(t, feed.Playlists, 2)
+	assert.Equal(t, "z-curated", feed.Playlists[0].ID, "curated playlists come first")
+	require.NotNil(t, feed.Playlists[0].Featured)
+	assert.Equal(t, "Staff pick", feed.Playlists[0].Featured.Title)
+	assert.Equal(t, "a-public", feed.Playlists[1].ID)
+	assert.Nil(t, feed.Playlists[1].Featured)
+
+	require.Len(t, feed.Artists, 2)
+	assert.Equal(t, "artist-2", feed.Artists[0].UserID)
+	assert.NotNil(t, feed.Artists[0].Featured)
+
+	require.Len(t, feed.NewTracks, 1)
+	assert.Equal(t, "t1", feed.NewTracks[0].ID)
+
+	// Once the window opens the scheduled section shows; a playlist made
+	// private since is left out
+	now = later
+	require.NoError(t, repo.UpdatePlaylistVisibility(ctx, "u2", "z-curated", models.VisibilityPrivate))
+	feed, err = svc.Discover(ctx, "viewer")
+	require.NoError(t, err)
+	assert.Len(t, feed.Sections, 2)
+	require.Len(t, feed.Playlists, 1)
+	assert.Equal(t, "a-public", feed.Playlists[0].ID)
+}
+
+func TestDiscoverService_FeaturedItems(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	svc := NewDiscoverService(repo, repo, nil)
+	svc.now = func() time.Time { return now }
+
+	_, err := svc.CreateFeatured(ctx, "admin", models.CreateFeaturedItemRequest{Kind: models.FeaturedKindArtist, OwnerID: "nobody"})
+	assert.Error(t, err, "the artist must have a profile")
+
+	section, err := svc.CreateFeatured(ctx, "admin", models.CreateFeaturedItemRequest{Kind: models.FeaturedKindSection, Title: " New this week ", Position: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "New this week", section.Title)
+	assert.Equal(t, "admin", section.CreatedBy)
+
+	until := now.Add(-time.Hour)
+	update := models.UpdateFeaturedItemRequest{Title: "Last week", Position: 0}
+	update.FeaturedUntil = &until
+	updated, err := svc.UpdateFeatured(ctx, section.ID, update)
+	require.NoError(t, err)
+	assert.Equal(t, models.FeaturedExpired, updated.Status)
+
+	_, err = svc.UpdateFeatured(ctx, section.ID, models.UpdateFeaturedItemRequest{})
+	assert.Error(t, err, "sections keep a title")
+
+	items, err := svc.ListFeatured(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Last week", items[0].Title)
+
+	feed, err := svc.Discover(ctx, "viewer")
+	require.NoError(t, err)
+	assert.Empty(t, feed.Sections, "expired sections are not shown")
+
+	require.NoError(t, svc.DeleteFeatured(ctx, section.ID))
+	assert.Error(t, svc.DeleteFeatured(ctx, section.ID))
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gvasels/personal-music-searchengine/internal/djexport"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+)
+
+// DJExportRepository defines the repository interface for DJ software exports
+type DJExportRepository interface {
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	GetPlaylist(ctx context.Context, userID, playlistID string) (*models.Playlist, error)
+	GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error)
+}
+
+// DJExportServiceImpl exports track analysis for import into DJ software
+type DJExportServiceImpl struct {
+	repo DJExportRepository
+}
+
+// NewDJExportService creates a new DJ export service
+func NewDJExportService(repo DJExportRepository) DJExportService {
+	return &DJExportServiceImpl{repo: repo}
+}
+
+// ExportTrack exports the analysis of a single track
+func (s *DJExportServiceImpl) ExportTrack(ctx context.Context, userID, trackID string, req models.DJExportRequest) (*models.DJExport, error) {
+	track, err := s.repo.GetTrack(ctx, userID, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+	return s.export(track.Artist+" - "+track.Title, []models.Track{*track}, nil, req)
+}
+
+// ExportPlaylist exports the analysis of a playlist's tracks, in playlist
+// order. Tracks deleted since they were added are left out.
+func (s *DJExportServiceImpl) ExportPlaylist(ctx context.Context, userID, playlistID string, req models.DJExportRequest) (*models.DJExport, error) {
+	playlist, err := s.repo.GetPlaylist(ctx, userID, playlistID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Playlist", playlistID)
+		}
+		return nil, err
+	}
+
+	playlistTracks, err := s.repo.GetPlaylistTracks(ctx, playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := make([]models.Track, 0, len(playlistTracks))
+	for _, pt := range playlistTracks {
+		track, err := s.repo.GetTrack(ctx, userID, pt.TrackID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				continue // Skip deleted tracks
+			}
+			return nil, err
+		}
+		tracks = append(tracks, *track)
+	}
+
+	return s.export(playlist.Name, tracks, &djexport.Playlist{Name: playlist.Name, Tracks: tracks}, req)
+}
+
+// export encodes tracks in the requested format. Rekordbox gets an XML
+// library; Serato gets the tag payloads of each track as JSON.
+func (s *DJExportServiceImpl) export(name string, tracks []models.Track, playlist *djexport.Playlist, req models.DJExportRequest) (*models.DJExport, error) {
+	switch req.Format {
+	case models.DJExportRekordbox:
+		var buf bytes.Buffer
+		if err := djexport.WriteRekordbox(&buf, tracks, playlist, djexport.Options{Root: req.Root}); err != nil {
+			return nil, err
+		}
+		return &models.DJExport{FileName: sanitize.FileName(name + ".xml"), ContentType: "application/xml", Body: buf.Bytes()}, nil
+	case models.DJExportSerato:
+		tags := make([]djexport.SeratoTags, 0, len(tracks))
+		for _, track := range tracks {
+			tags = append(tags, djexport.Serato(track))
+		}
+		body, err := json.Marshal(tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode serato tags: %w", err)
+		}
+		return &models.DJExport{FileName: sanitize.FileName(name + ".serato.json"), ContentType: "application/json", Body: body}, nil
+	default:
+		return nil, models.NewValidationError(fmt.Sprintf("unsupported export format %q", req.Format))
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/djexport"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDJExportTest(t *testing.T) DJExportService {
+	t.Helper()
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{
+		ID: "track-1", UserID: "user-1", Title: "Strobe", Artist: "deadmau5", Format: models.AudioFormatMP3,
+		BPM: 128, MusicalKey: "Bbm", BeatGrid: []int64{352, 821},
+	}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{
+		ID: "track-2", UserID: "user-1", Title: "Ghosts 'n' Stuff", Artist: "deadmau5", Format: models.AudioFormatFLAC,
+	}))
+	require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "playlist-1", UserID: "user-1", Name: "Warmup"}))
+	require.NoError(t, repo.AddTracksToPlaylist(ctx, "playlist-1", []string{"track-2", "deleted", "track-1"}, 0))
+	return NewDJExportService(repo)
+}
+
+func TestDJExportService_ExportTrack(t *testing.T) {
+	ctx := context.Background()
+	svc := newDJExportTest(t)
+
+	t.Run("rekordbox", func(t *testing.T) {
+		export, err := svc.ExportTrack(ctx, "user-1", "track-1", models.DJExportRequest{Format: models.DJExportRekordbox})
+
+		require.NoError(t, err)
+		assert.Equal(t, "deadmau5 - Strobe.xml", export.FileName)
+		assert.Equal(t, "application/xml", export.ContentType)
+		assert.Contains(t, string(export.Body), `Inizio="0.352"`)
+		assert.Contains(t, string(export.Body), `Tonality="Bbm"`)
+	})
+
+	t.Run("serato", func(t *testing.T) {
+		export, err := svc.ExportTrack(ctx, "user-1", "track-1", models.DJExportRequest{Format: models.DJExportSerato})
+
+		require.NoError(t, err)
+		var tags []djexport.SeratoTags
+		require.NoError(t, json.Unmarshal(export.Body, &tags))
+		require.Len(t, tags, 1)
+		assert.Equal(t, djexport.SeratoBeatGrid(0.352, 128), tags[0].BeatGrid)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := svc.ExportTrack(ctx, "user-2", "track-1", models.DJExportRequest{Format: models.DJExportRekordbox})
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+	})
+}
+
+func TestDJExportService_ExportPlaylist(t *testing.T) {
+	svc := newDJExportTest(t)
+
+	export, err := svc.ExportPlaylist(context.Background(), "user-1", "playlist-1", models.DJExportRequest{Format: models.DJExportRekordbox})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Warmup.xml", export.FileName)
+	body := string(export.Body)
+	assert.Contains(t, body, `<COLLECTION Entries="2">`)
+	assert.Contains(t, body, `Name="Warmup" KeyType="0" Entries="2"`)
+	assert.Less(t, strings.Index(body, "Ghosts"), strings.Index(body, "Strobe"), "tracks keep playlist order")
+}
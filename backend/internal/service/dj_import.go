@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gvasels/personal-music-searchengine/internal/djimport"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// DJImportRepository defines the repository interface for DJ library imports
+type DJImportRepository interface {
+	ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error)
+	UpdateTrack(ctx context.Context, track models.Track) error
+}
+
+// DJImportServiceImpl imports cue points, ratings and crates from DJ software
+// library exports into the tracks they match
+type DJImportServiceImpl struct {
+	repo      DJImportRepository
+	playlists PlaylistService
+	tags      TagService
+}
+
+// NewDJImportService creates a new DJ library import service. Crates are
+// created through the playlist and tag services so counts stay in step.
+func NewDJImportService(repo DJImportRepository, playlists PlaylistService, tags TagService) DJImportService {
+	return &DJImportServiceImpl{repo: repo, playlists: playlists, tags: tags}
+}
+
+// Limits on names of imported crates
+const (
+	maxImportedPlaylistName = 200
+	maxImportedTagName      = 50
+	// djImportDurationSlack is how far, in seconds, an exported track's
+	// duration may be from a library track's to tell duplicates apart
+	djImportDurationSlack = 2
+)
+
+// defaultSeratoCrate names a Serato crate imported without its file name
+const defaultSeratoCrate = "Serato Crate.crate"
+
+// djImportChange is one change an import makes: saving a track's imported
+// cues and rating, or importing a crate
+type djImportChange struct {
+	track *models.Track
+	crate *djImportCrate
+}
+
+// djImportCrate is a crate's matched tracks and the entry of the result
+// reporting it
+type djImportCrate struct {
+	tracks   []*models.Track
+	imported *models.DJImportedCrate
+}
+
+// ImportLibrary matches the export's tracks against the user's library and
+// imports their hot cues and ratings, then recreates the export's crates and
+// playlists from the matched tracks. Existing hot cues and ratings are kept
+// unless the request overwrites them; locked tracks are left unchanged.
+func (s *DJImportServiceImpl) ImportLibrary(ctx context.Context, userID string, req models.DJImportRequest) (*models.DJImportResult, error) {
+	plan, err := s.planImport(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	_, err = plan.execute(ctx, req.DryRun, func(ctx context.Context, change djImportChange) error {
+		if change.track != nil {
+			return s.repo.UpdateTrack(ctx, *change.track)
+		}
+		return s.importCrate(ctx, userID, req.CratesAs, change.crate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := plan.result
+	for _, change := range plan.changes {
+		if change.crate != nil {
+			result.Crates = append(result.Crates, *change.crate.imported)
+		}
+	}
+	return result, nil
+}
+
+// planImport parses the export, matches its tracks and applies their cues
+// and ratings to copies of the library's tracks; nothing is saved. The plan
+// saves the edited tracks first, then imports the crates.
+func (s *DJImportServiceImpl) planImport(ctx context.Context, userID string, req models.DJImportRequest) (*bulkPlan[djImportChange, *models.DJImportResult], error) {
+	library, err := parseDJLibrary(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks, err := s.listOwnTracks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	index := newDJTrackIndex(tracks)
+
+	plan := &bulkPlan[djImportChange, *models.DJImportResult]{result: &models.DJImportResult{
+		TracksInExport: len(library.Tracks),
+		Crates:         []models.DJImportedCrate{},
+		Unmatched:      []models.DJImportEntry{},
+		DryRun:         req.DryRun,
+	}}
+	result := plan.result
+
+	matched := make(map[string]*models.Track, len(library.Tracks))
+	for _, entry := range library.Tracks {
+		track := index.match(entry)
+		if track == nil {
+			result.Unmatched = append(result.Unmatched, models.DJImportEntry{Title: entry.Title, Artist: entry.Artist, Location: entry.Location})
+			continue
+		}
+		result.Matched++
+		matched[entry.Key] = track
+		if track.EnsureUnlocked() != nil {
+			result.Locked = append(result.Locked, track.ID)
+			continue
+		}
+
+		cues, rated := applyDJImport(track, entry, req.Overwrite)
+		if cues == 0 && !rated {
+			continue
+		}
+		result.CuesImported += cues
+		if rated {
+			result.RatingsImported++
+		}
+		plan.changes = append(plan.changes, djImportChange{track: track})
+	}
+
+	for _, crate := range library.Crates {
+		if planned := planCrate(crate, matched, req.CratesAs); planned != nil {
+			plan.changes = append(plan.changes, djImportChange{crate: planned})
+		}
+	}
+	return plan, nil
+}
+
+// planCrate picks the crate's matched tracks; tagging skips locked tracks.
+// Crates with no matched tracks are left out.
+func planCrate(crate djimport.Crate, matched map[string]*models.Track, cratesAs string) *djImportCrate {
+	var tracks []*models.Track
+	seen := make(map[string]bool, len(crate.TrackKeys))
+	for _, key := range crate.TrackKeys {
+		track, ok := matched[key]
+		if !ok || seen[track.ID] {
+			continue
+		}
+		// Tagging edits the track, which a lock forbids
+		if cratesAs == models.DJImportCratesAsTag && track.Locked {
+			continue
+		}
+		seen[track.ID] = true
+		tracks = append(tracks, track)
+	}
+	if len(tracks) == 0 {
+		return nil
+	}
+
+	name := strings.TrimSpace(crate.Name)
+	if name == "" {
+		name = "Imported"
+	}
+	imported := &models.DJImportedCrate{Name: name, TrackCount: len(tracks)}
+	if cratesAs == models.DJImportCratesAsTag {
+		imported.Tag = normalizeTagName(truncateRunes(name, maxImportedTagName))
+	}
+	return &djImportCrate{tracks: tracks, imported: imported}
+}
+
+// importCrate tags a crate's tracks with its name, or creates a playlist of
+// them and records its ID in the result
+func (s *DJImportServiceImpl) importCrate(ctx context.Context, userID, cratesAs string, crate *djImportCrate) error {
+	if cratesAs == models.DJImportCratesAsTag {
+		for _, track := range crate.tracks {
+			if _, err := s.tags.AddTagsToTrack(ctx, userID, track.ID, models.AddTagsToTrackRequest{Tags: []string{crate.imported.Tag}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	playlist, err := s.playlists.CreatePlaylist(ctx, userID, models.CreatePlaylistRequest{Name: truncateRunes(crate.imported.Name, maxImportedPlaylistName)})
+	if err != nil {
+		return err
+	}
+	crate.imported.PlaylistID = playlist.ID
+
+	ids := make([]string, 0, len(crate.tracks))
+	for _, track := range crate.tracks {
+		ids = append(ids, track.ID)
+	}
+	for start := 0; start < len(ids); start += 100 {
+		end := min(start+100, len(ids))
+		if _, err := s.playlists.AddTracks(ctx, userID, crate.imported.PlaylistID, models.AddTracksToPlaylistRequest{TrackIDs: ids[start:end]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listOwnTracks returns every track the user owns
+func (s *DJImportServiceImpl) listOwnTracks(ctx context.Context, userID string) ([]models.Track, error) {
+	var tracks []models.Track
+	cursor := ""
+	for {
+		page, err := s.repo.ListTracks(ctx, userID, models.TrackFilter{Limit: 100, LastKey: cursor})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tracks: %w", err)
+		}
+		for _, track := range page.Items {
+			// ListTracks mixes in other users' public tracks
+			if track.UserID == userID {
+				tracks = append(tracks, track)
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			return tracks, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// parseDJLibrary parses the export in the request, reporting unreadable
+// exports as validation errors
+func parseDJLibrary(req models.DJImportRequest) (*djimport.Library, error) {
+	var library *djimport.Library
+	var err error
+	switch req.Format {
+	case models.DJImportRekordbox:
+		library, err = djimport.ParseRekordbox(strings.NewReader(req.Content))
+	case models.DJImportTraktor:
+		library, err = djimport.ParseTraktor(strings.NewReader(req.Content))
+	case models.DJImportSerato:
+		data, decodeErr := base64.StdEncoding.DecodeString(req.Content)
+		if decodeErr != nil {
+			return nil, models.NewValidationError("serato content must be a base64-encoded .crate file")
+		}
+		fileName := req.FileName
+		if fileName == "" {
+			fileName = defaultSeratoCrate
+		}
+		library, err = djimport.ParseSerato(fileName, data)
+	default:
+		return nil, models.NewValidationError(fmt.Sprintf("unsupported import format %q", req.Format))
+	}
+	if err != nil {
+		if errors.Is(err, djimport.ErrInvalidLibrary) {
+			return nil, models.NewValidationError(err.Error())
+		}
+		return nil, err
+	}
+	return library, nil
+}
+
+// applyDJImport copies an exported track's hot cues and rating onto a library
+// track, returning the number of cues set and whether the rating changed.
+// Cues past the end of the track are dropped.
+func applyDJImport(track *models.Track, entry djimport.Track, overwrite bool) (int, bool) {
+	cues := 0
+	now := time.Now()
+	for _, cue := range entry.Cues {
+		if !models.IsValidSlot(cue.Slot) || cue.Position < 0 || (track.Duration > 0 && cue.Position > float64(track.Duration)) {
+			continue
+		}
+		existing := track.HotCues[cue.Slot]
+		if existing != nil && !overwrite {
+			continue
+		}
+		color := models.HotCueColor(cue.Color)
+		if color == "" {
+			color = models.GetDefaultColorForSlot(cue.Slot)
+		}
+		hotCue := &models.HotCue{Slot: cue.Slot, Position: cue.Position, Label: truncateRunes(cue.Label, 50), Color: color, CreatedAt: now, UpdatedAt: now}
+		if existing != nil {
+			hotCue.CreatedAt = existing.CreatedAt
+		}
+		if track.HotCues == nil {
+			track.HotCues = make(map[int]*models.HotCue)
+		}
+		track.HotCues[cue.Slot] = hotCue
+		cues++
+	}
+
+	rated := false
+	if entry.Rating > 0 && entry.Rating != track.Rating && (track.Rating == 0 || overwrite) {
+		track.Rating = entry.Rating
+		rated = true
+	}
+	return cues, rated
+}
+
+// djTrackIndex finds library tracks for exported tracks, by artist and title
+// when the export has tags and by file name otherwise
+type djTrackIndex struct {
+	byTags  map[string][]*models.Track // artist + title
+	byName  map[string][]*models.Track // "artist - title" as a file name
+	byTitle map[string][]*models.Track // title alone, used only when unique
+}
+
+func newDJTrackIndex(tracks []models.Track) *djTrackIndex {
+	index := &djTrackIndex{
+		byTags:  make(map[string][]*models.Track, len(tracks)),
+		byName:  make(map[string][]*models.Track, len(tracks)),
+		byTitle: make(map[string][]*models.Track, len(tracks)),
+	}
+	for i := range tracks {
+		track := &tracks[i]
+		title := matchKey(track.Title)
+		if title == "" {
+			continue
+		}
+		artist := matchKey(track.Artist)
+		index.byTags[artist+"\x00"+title] = append(index.byTags[artist+"\x00"+title], track)
+		index.byName[matchKey(track.Artist+" "+track.Title)] = append(index.byName[matchKey(track.Artist+" "+track.Title)], track)
+		index.byTitle[title] = append(index.byTitle[title], track)
+	}
+	return index
+}
+
+// match returns the library track for an exported track, or nil
+func (x *djTrackIndex) match(entry djimport.Track) *models.Track {
+	if title := matchKey(entry.Title); title != "" {
+		if track := pickDJMatch(x.byTags[matchKey(entry.Artist)+"\x00"+title], entry.Duration); track != nil {
+			return track
+		}
+	}
+	name := matchKey(entry.FileName())
+	if name == "" {
+		return nil
+	}
+	if track := pickDJMatch(x.byName[name], entry.Duration); track != nil {
+		return track
+	}
+	if candidates := x.byTitle[name]; len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// pickDJMatch chooses among tracks with the same name, preferring one whose
+// duration matches the export's
+func pickDJMatch(candidates []*models.Track, duration int) *models.Track {
+	if len(candidates) == 0 {
+		return nil
+	}
+	if duration > 0 {
+		for _, track := range candidates {
+			if diff := track.Duration - duration; diff >= -djImportDurationSlack && diff <= djImportDurationSlack {
+				return track
+			}
+		}
+	}
+	return candidates[0]
+}
+
+// matchKey reduces a name to lower-case words of letters and digits, so
+// punctuation, case and spacing differences between libraries don't matter
+func matchKey(value string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// truncateRunes shortens a value to at most n runes
+func truncateRunes(value string, n int) string {
+	runes := []rune(value)
+	if len(runes) <= n {
+		return value
+	}
+	return strings.TrimSpace(string(runes[:n]))
+}
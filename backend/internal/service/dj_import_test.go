@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/djimport"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchKey(t *testing.T) {
+	assert.Equal(t, "deadmau5 strobe", matchKey("deadmau5 - Strobe"))
+	assert.Equal(t, "don t stop me now", matchKey("  Don't  STOP me now! "))
+	assert.Equal(t, "café été", matchKey("Café – Été"))
+}
+
+func TestDJTrackIndex_Match(t *testing.T) {
+	index := newDJTrackIndex([]models.Track{
+		builders.NewTrackBuilder("user-1", "strobe").WithTitle("Strobe").WithArtist("deadmau5").WithDuration(634).Build(),
+		builders.NewTrackBuilder("user-1", "strobe-edit").WithTitle("Strobe").WithArtist("deadmau5").WithDuration(215).Build(),
+		builders.NewTrackBuilder("user-1", "intro-a").WithTitle("Intro").WithArtist("A").Build(),
+		builders.NewTrackBuilder("user-1", "intro-b").WithTitle("Intro").WithArtist("B").Build(),
+		builders.NewTrackBuilder("user-1", "ghosts").WithTitle("Ghosts 'n' Stuff").WithArtist("deadmau5").Build(),
+	})
+
+	tests := []struct {
+		name  string
+		entry djimport.Track
+		want  string
+	}{
+		{"by tags", djimport.Track{Title: "STROBE", Artist: "Deadmau5"}, "strobe"},
+		{"duration tells duplicates apart", djimport.Track{Title: "Strobe", Artist: "deadmau5", Duration: 214}, "strobe-edit"},
+		{"by artist - title file name", djimport.Track{Location: "Music/deadmau5 - Ghosts n Stuff.mp3"}, "ghosts"},
+		{"by unique title file name", djimport.Track{Location: `C:\Music\Ghosts 'n' Stuff.flac`}, "ghosts"},
+		{"ambiguous title file name", djimport.Track{Location: "Music/Intro.mp3"}, ""},
+		{"unknown", djimport.Track{Title: "Raise Your Weapon", Artist: "deadmau5"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := index.match(tt.entry)
+			if tt.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want, got.ID)
+		})
+	}
+}
+
+// ratedTrack is a five-minute track rated 2 with a red cue in slot 2
+func ratedTrack() *models.Track {
+	track := builders.NewTrackBuilder("user-1", "track-1").WithDuration(300).BuildPtr()
+	track.Rating = 2
+	track.HotCues = map[int]*models.HotCue{
+		2: {Slot: 2, Position: 12, Color: models.HotCueColorRed},
+	}
+	return track
+}
+
+func TestApplyDJImport(t *testing.T) {
+	entry := djimport.Track{
+		Rating: 4,
+		Cues: []djimport.Cue{
+			{Slot: 1, Position: 0.35, Label: "Start", Color: "#28E214"},
+			{Slot: 2, Position: 60},
+			{Slot: 3, Position: 999},
+		},
+	}
+
+	t.Run("keeps existing cues and ratings", func(t *testing.T) {
+		track := ratedTrack()
+
+		cues, rated := applyDJImport(track, entry, false)
+
+		assert.Equal(t, 1, cues)
+		assert.False(t, rated)
+		assert.Equal(t, 2, track.Rating)
+		assert.Equal(t, models.HotCueColor("#28E214"), track.HotCues[1].Color)
+		assert.Equal(t, 12.0, track.HotCues[2].Position)
+		assert.Nil(t, track.HotCues[3], "cues past the end are dropped")
+	})
+
+	t.Run("overwrites", func(t *testing.T) {
+		track := ratedTrack()
+
+		cues, rated := applyDJImport(track, entry, true)
+
+		assert.Equal(t, 2, cues)
+		assert.True(t, rated)
+		assert.Equal(t, 4, track.Rating)
+		assert.Equal(t, 60.0, track.HotCues[2].Position)
+		assert.Equal(t, models.GetDefaultColorForSlot(2), track.HotCues[2].Color)
+	})
+}
+
+const djImportRekordboxXML = `<DJ_PLAYLISTS Version="1.0.0">
+  <COLLECTION Entries="3">
+    <TRACK TrackID="1" Name="Strobe" Artist="deadmau5" Rating="204" Location="file://localhost/Music/Strobe.mp3">
+      <POSITION_MARK Name="Drop" Type="0" Start="96.5" Num="0" Red="40" Green="226" Blue="20"/>
+    </TRACK>
+    <TRACK TrackID="2" Name="Locked Song" Artist="deadmau5" Rating="255" Location="file://localhost/Music/Locked.mp3"/>
+    <TRACK TrackID="3" Name="Not In Library" Artist="Someone" Location="file://localhost/Music/Missing.mp3"/>
+  </COLLECTION>
+  <PLAYLISTS>
+    <NODE Type="0" Name="ROOT" Count="1">
+      <NODE Type="1" Name="Peak Time" KeyType="0" Entries="3">
+        <TRACK Key="1"/><TRACK Key="2"/><TRACK Key="3"/>
+      </NODE>
+    </NODE>
+  </PLAYLISTS>
+</DJ_PLAYLISTS>`
+
+const (
+	djStrobeID = "11111111-1111-1111-1111-111111111111"
+	djLockedID = "22222222-2222-2222-2222-222222222222"
+)
+
+// newDJImportService holds an analysed copy of Strobe and a locked track
+func newDJImportService(t *testing.T) (DJImportService, *repository.MemoryRepository) {
+	t.Helper()
+	repo := newSeededRepo(t,
+		builders.NewTrackBuilder("user-1", djStrobeID).WithTitle("Strobe").WithArtist("deadmau5").WithDuration(634).WithBPM(128).WithKey("Fm", "4A").Build(),
+		builders.NewTrackBuilder("user-1", djLockedID).WithTitle("Locked Song").WithArtist("deadmau5").WithDuration(200).Locked().Build(),
+	)
+	return NewDJImportService(repo, NewPlaylistService(repo, nil), NewTagService(repo)), repo
+}
+
+func TestDJImportService_ImportLibrary(t *testing.T) {
+	ctx := context.Background()
+	req := models.DJImportRequest{Format: models.DJImportRekordbox, Content: djImportRekordboxXML}
+
+	t.Run("imports cues, ratings and playlists", func(t *testing.T) {
+		svc, repo := newDJImportService(t)
+
+		result, err := svc.ImportLibrary(ctx, "user-1", req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.TracksInExport)
+		assert.Equal(t, 2, result.Matched)
+		assert.Equal(t, 1, result.CuesImported)
+		assert.Equal(t, 1, result.RatingsImported)
+		assert.Equal(t, []string{djLockedID}, result.Locked)
+		assert.Equal(t, []models.DJImportEntry{{Title: "Not In Library", Artist: "Someone", Location: "/Music/Missing.mp3"}}, result.Unmatched)
+		require.Len(t, result.Crates, 1)
+		assert.Equal(t, "Peak Time", result.Crates[0].Name)
+		assert.Equal(t, 2, result.Crates[0].TrackCount)
+
+		track, err := repo.GetTrack(ctx, "user-1", djStrobeID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, track.Rating)
+		assert.Equal(t, "Drop", track.HotCues[1].Label)
+		assert.Equal(t, 128, track.BPM, "analysis is not overwritten")
+		assert.Equal(t, "4A", track.KeyCamelot)
+
+		playlistTracks, err := repo.GetPlaylistTracks(ctx, result.Crates[0].PlaylistID)
+		require.NoError(t, err)
+		assert.Len(t, playlistTracks, 2)
+	})
+
+	t.Run("crates as tags skip locked tracks", func(t *testing.T) {
+		svc, repo := newDJImportService(t)
+		tagReq := req
+		tagReq.CratesAs = models.DJImportCratesAsTag
+
+		result, err := svc.ImportLibrary(ctx, "user-1", tagReq)
+
+		require.NoError(t, err)
+		require.Len(t, result.Crates, 1)
+		assert.Equal(t, "peak time", result.Crates[0].Tag)
+		assert.Equal(t, 1, result.Crates[0].TrackCount)
+		track, err := repo.GetTrack(ctx, "user-1", djStrobeID)
+		require.NoError(t, err)
+		assert.Contains(t, track.Tags, "peak time")
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		svc, repo := newDJImportService(t)
+		dryReq := req
+		dryReq.DryRun = true
+
+		result, err := svc.ImportLibrary(ctx, "user-1", dryReq)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.CuesImported)
+		assert.Empty(t, result.Crates[0].PlaylistID)
+		track, err := repo.GetTrack(ctx, "user-1", djStrobeID)
+		require.NoError(t, err)
+		assert.Zero(t, track.Rating)
+		assert.Empty(t, track.HotCues)
+	})
+
+	t.Run("rejects unreadable exports", func(t *testing.T) {
+		svc, _ := newDJImportService(t)
+
+		_, err := svc.ImportLibrary(ctx, "user-1", models.DJImportRequest{Format: models.DJImportSerato, Content: "not base64!"})
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+	})
+}
@@ -27,6 +27,7 @@ type BedrockEmbeddingClient interface {
 // using AWS Bedrock Titan text embeddings model.
 type EmbeddingService struct {
 	client BedrockEmbeddingClient
+	model  string
 }
 
 // NewEmbeddingService creates a new EmbeddingService with the given Bedrock client.
@@ -37,9 +38,24 @@ func NewEmbeddingService(client BedrockEmbeddingClient) *EmbeddingService {
 	}
 	return &EmbeddingService{
 		client: client,
+		model:  EmbeddingModelID,
 	}
 }
 
+// WithModel returns a copy of the service that embeds with the given model.
+// Stored embeddings are tagged with the model, so changing it is followed by
+// EmbeddingStore.MigrateEmbeddings.
+func (s *EmbeddingService) WithModel(model string) *EmbeddingService {
+	clone := *s
+	clone.model = model
+	return &clone
+}
+
+// Model returns the identifier of the model the service embeds with
+func (s *EmbeddingService) Model() string {
+	return s.model
+}
+
 // ComposeEmbedText creates a text representation of track metadata for embedding generation.
 // The text includes title, artist, album, genre, tags, BPM, and key.
 // Truncates to 8000 characters max (Titan model limit).
@@ -101,7 +117,7 @@ func (s *EmbeddingService) GenerateTrackEmbedding(ctx context.Context, track mod
 
 	// Call the Bedrock client
 	req := clients.EmbeddingRequest{
-		Model: EmbeddingModelID,
+		Model: s.model,
 		Input: text,
 	}
 
@@ -134,7 +150,7 @@ func (s *EmbeddingService) GenerateQueryEmbedding(ctx context.Context, query str
 
 	// Call the Bedrock client
 	req := clients.EmbeddingRequest{
-		Model: EmbeddingModelID,
+		Model: s.model,
 		Input: trimmed,
 	}
 
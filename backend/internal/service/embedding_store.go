@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/vector"
+)
+
+// EmbeddingRepository stores track embeddings tagged by model. The DynamoDB,
+// in-memory and library-scoped repositories implement it.
+type EmbeddingRepository interface {
+	ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error)
+	PutTrackEmbedding(ctx context.Context, embedding models.TrackEmbedding) error
+	BatchGetTrackEmbeddings(ctx context.Context, userID, model string, trackIDs []string) (map[string]models.TrackEmbedding, error)
+	ListTrackEmbeddings(ctx context.Context, userID, model string) ([]models.TrackEmbedding, error)
+	DeleteTrackEmbedding(ctx context.Context, userID, model, trackID string) error
+}
+
+// embeddingBatchSize is the number of tracks whose stored embeddings are
+// checked per BatchGetTrackEmbeddings call
+const embeddingBatchSize = 100
+
+// EmbeddingStore persists track embeddings for the current embedding model and
+// serves them in bulk to similarity, radio and semantic search. Reads work
+// without an EmbeddingService; embedding tracks requires one.
+type EmbeddingStore struct {
+	repo     EmbeddingRepository
+	embedder *EmbeddingService
+	model    string
+	now      func() time.Time
+}
+
+// NewEmbeddingStore creates an EmbeddingStore for the embedder's model, or for
+// EmbeddingModelID when embedder is nil.
+func NewEmbeddingStore(repo EmbeddingRepository, embedder *EmbeddingService) *EmbeddingStore {
+	model := EmbeddingModelID
+	if embedder != nil {
+		model = embedder.Model()
+	}
+	return &EmbeddingStore{
+		repo:     repo,
+		embedder: embedder,
+		model:    model,
+		now:      time.Now,
+	}
+}
+
+// Model returns the embedding model whose vectors the store reads and writes
+func (s *EmbeddingStore) Model() string {
+	return s.model
+}
+
+// EmbedTracks stores a current-model embedding for each track whose metadata
+// changed since it was last embedded, or that has none. It continues past
+// individual failures and returns how many tracks were embedded, with an error
+// only if every attempted track failed or ctx was cancelled.
+func (s *EmbeddingStore) EmbedTracks(ctx context.Context, userID string, tracks []models.Track) (int, error) {
+	if s.embedder == nil {
+		return 0, errors.New("embedding store has no embedding service")
+	}
+
+	embedded, failed := 0, 0
+	var lastErr error
+	for start := 0; start < len(tracks); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(tracks))
+		batch := tracks[start:end]
+
+		ids := make([]string, len(batch))
+		for i, track := range batch {
+			ids[i] = track.ID
+		}
+		stored, err := s.repo.BatchGetTrackEmbeddings(ctx, userID, s.model, ids)
+		if err != nil {
+			return embedded, fmt.Errorf("failed to get stored embeddings: %w", err)
+		}
+
+		for _, track := range batch {
+			if err := ctx.Err(); err != nil {
+				return embedded, err
+			}
+
+			text := s.embedder.ComposeEmbedText(track)
+			hash := models.EmbeddingTextHash(text)
+			if existing, ok := stored[track.ID]; ok && existing.TextHash == hash {
+				continue
+			}
+
+			vector, err := s.embedder.GenerateTrackEmbedding(ctx, track)
+			if err == nil {
+				err = s.repo.PutTrackEmbedding(ctx, models.TrackEmbedding{
+					UserID:    userID,
+					TrackID:   track.ID,
+					Model:     s.model,
+					Vector:    vector,
+					TextHash:  hash,
+					CreatedAt: s.now(),
+				})
+			}
+			if err != nil {
+				lastErr = err
+				failed++
+				continue
+			}
+			embedded++
+		}
+	}
+
+	if embedded == 0 && failed > 0 {
+		return 0, fmt.Errorf("all %d embeddings failed: %w", failed, lastErr)
+	}
+	return embedded, nil
+}
+
+// GetEmbeddings returns the current-model vectors of the given tracks, keyed
+// by track ID. Tracks that have not been embedded are left out.
+func (s *EmbeddingStore) GetEmbeddings(ctx context.Context, userID string, trackIDs []string) (map[string][]float32, error) {
+	vectors := make(map[string][]float32, len(trackIDs))
+	for start := 0; start < len(trackIDs); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(trackIDs))
+		stored, err := s.repo.BatchGetTrackEmbeddings(ctx, userID, s.model, trackIDs[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get embeddings: %w", err)
+		}
+		for trackID, embedding := range stored {
+			vectors[trackID] = embedding.Vector
+		}
+	}
+	return vectors, nil
+}
+
+// LoadIndex builds a search index over every current-model embedding in the
+// user's library. The index is a snapshot; callers answering many queries
+// should keep it rather than reload it per query.
+func (s *EmbeddingStore) LoadIndex(ctx context.Context, userID string) (*vector.Index, error) {
+	embeddings, err := s.repo.ListTrackEmbeddings(ctx, userID, s.model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embeddings: %w", err)
+	}
+	if len(embeddings) == 0 {
+		return vector.NewIndex(0, 0), nil
+	}
+
+	index := vector.NewIndex(len(embeddings[0].Vector), len(embeddings))
+	for _, embedding := range embeddings {
+		if err := index.Add(embedding.TrackID, embedding.Vector); err != nil {
+			return nil, fmt.Errorf("failed to index embedding: %w", err)
+		}
+	}
+	return index, nil
+}
+
+// EmbeddingMigrationResult summarizes a MigrateEmbeddings run
+type EmbeddingMigrationResult struct {
+	Model    string `json:"model"`
+	Tracks   int    `json:"tracks"`   // Tracks in the library
+	Embedded int    `json:"embedded"` // Tracks given a new current-model embedding
+	Removed  int    `json:"removed"`  // Superseded or orphaned embeddings deleted
+}
+
+// MigrateEmbeddings brings a user's library onto the current model: every
+// track without an up-to-date current-model embedding is embedded, then
+// embeddings from other models are deleted for tracks that now have a current
+// one, along with embeddings of tracks no longer in the library. A track that
+// fails to embed keeps its old vectors, so rerunning the migration resumes it.
+func (s *EmbeddingStore) MigrateEmbeddings(ctx context.Context, userID string) (*EmbeddingMigrationResult, error) {
+	result := &EmbeddingMigrationResult{Model: s.model}
+
+	var tracks []models.Track
+	cursor := ""
+	for {
+		page, err := s.repo.ListTracks(ctx, userID, models.TrackFilter{Limit: 100, LastKey: cursor})
+		if err != nil {
+			return result, fmt.Errorf("failed to list tracks: %w", err)
+		}
+		tracks = append(tracks, page.Items...)
+		if !page.HasMore || page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	result.Tracks = len(tracks)
+
+	embedded, err := s.EmbedTracks(ctx, userID, tracks)
+	result.Embedded = embedded
+	if err != nil {
+		return result, err
+	}
+
+	inLibrary := make(map[string]bool, len(tracks))
+	for _, track := range tracks {
+		inLibrary[track.ID] = true
+	}
+
+	embeddings, err := s.repo.ListTrackEmbeddings(ctx, userID, "")
+	if err != nil {
+		return result, fmt.Errorf("failed to list embeddings: %w", err)
+	}
+	current := make(map[string]bool, len(embeddings))
+	for _, embedding := range embeddings {
+		if embedding.Model == s.model {
+			current[embedding.TrackID] = true
+		}
+	}
+
+	for _, embedding := range embeddings {
+		orphaned := !inLibrary[embedding.TrackID]
+		superseded := embedding.Model != s.model && current[embedding.TrackID]
+		if !orphaned && !superseded {
+			continue
+		}
+		if err := s.repo.DeleteTrackEmbedding(ctx, userID, embedding.Model, embedding.TrackID); err != nil {
+			return result, fmt.Errorf("failed to delete embedding: %w", err)
+		}
+		result.Removed++
+	}
+
+	return result, nil
+}
+
+// findEmbeddingSimilar ranks the user's tracks by the cosine similarity of
+// their embeddings to the source track's. Index matches are fetched in
+// growing batches until opts.Limit tracks survive the album filter.
+func (s *SimilarityService) findEmbeddingSimilar(ctx context.Context, userID string, sourceTrack *models.Track, opts SimilarityOptions) ([]SimilarTrack, error) {
+	if s.embeddings == nil {
+		return nil, models.NewServiceUnavailableError("embedding similarity", "the repository stores no embeddings")
+	}
+
+	vectors, err := s.embeddings.GetEmbeddings(ctx, userID, []string{sourceTrack.ID})
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, models.NewServiceUnavailableError("embedding similarity", "the repository stores no embeddings")
+	}
+	if err != nil {
+		return nil, err
+	}
+	query, ok := vectors[sourceTrack.ID]
+	if !ok {
+		return nil, models.NewNotFoundError("Embedding", sourceTrack.ID)
+	}
+
+	index, err := s.embeddings.LoadIndex(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	skipSource := func(id string) bool { return id == sourceTrack.ID }
+	fetched := make(map[string]*models.Track)
+	var similar []SimilarTrack
+	for k := opts.Limit + 1; ; k *= 2 {
+		matches := index.Search(query, k, skipSource)
+		similar = similar[:0]
+		exhausted := len(matches) < k
+
+		for _, match := range matches {
+			if match.Score < opts.MinSimilarity {
+				exhausted = true
+				break
+			}
+
+			track, seen := fetched[match.ID]
+			if !seen {
+				track, err = s.repo.GetTrack(ctx, userID, match.ID)
+				if err != nil && !errors.Is(err, repository.ErrNotFound) {
+					return nil, fmt.Errorf("failed to get track: %w", err)
+				}
+				// Embeddings of deleted tracks linger until the next migration
+				fetched[match.ID] = track
+			}
+			if track == nil {
+				continue
+			}
+			if !opts.IncludeSameAlbum && track.Album == sourceTrack.Album && track.Album != "" {
+				continue
+			}
+
+			similar = append(similar, SimilarTrack{
+				Track:         track.ToResponse(""),
+				Similarity:    match.Score,
+				BPMDiff:       bpmDifference(sourceTrack.BPM, track.BPM),
+				KeyCompatible: IsKeyCompatible(sourceTrack.KeyCamelot, track.KeyCamelot),
+				MatchReasons:  []string{"Similar embedding"},
+			})
+			if len(similar) == opts.Limit {
+				return similar, nil
+			}
+		}
+
+		if exhausted {
+			return similar, nil
+		}
+	}
+}
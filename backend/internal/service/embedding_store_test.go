@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"strings"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/clients"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wordEmbeddingClient embeds text as a bag of hashed words, so tracks sharing
+// artist, album or genre words get similar vectors. It counts calls per model
+// and fails for any input containing failOn.
+type wordEmbeddingClient struct {
+	calls  map[string]int
+	failOn string
+}
+
+func (c *wordEmbeddingClient) CreateEmbedding(ctx context.Context, req clients.EmbeddingRequest) (*clients.EmbeddingResponse, error) {
+	if c.calls == nil {
+		c.calls = make(map[string]int)
+	}
+	c.calls[req.Model]++
+
+	text := req.Input.(string)
+	if c.failOn != "" && strings.Contains(text, c.failOn) {
+		return nil, errors.New("model overloaded")
+	}
+
+	vector := make([]float32, 32)
+	for _, word := range strings.Fields(text) {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(word))
+		vector[h.Sum32()%32]++
+	}
+	return &clients.EmbeddingResponse{
+		Model: req.Model,
+		Data:  []clients.EmbeddingData{{Embedding: vector}},
+	}, nil
+}
+
+func newEmbeddingTestRepo(t *testing.T) *repository.MemoryRepository {
+	t.Helper()
+	repo := repository.NewMemoryRepository()
+	for _, track := range []models.Track{
+		createSimilarityTestTrack("t1", "Burial", "Untrue", "Garage", "8A", 138, nil),
+		createSimilarityTestTrack("t2", "Burial", "Untrue", "Garage", "8A", 136, nil),
+		createSimilarityTestTrack("t3", "Burial", "Rival Dealer", "Garage", "9A", 140, nil),
+		createSimilarityTestTrack("t4", "Abba", "Arrival", "Pop", "3B", 100, nil),
+	} {
+		require.NoError(t, repo.CreateTrack(context.Background(), track))
+	}
+	return repo
+}
+
+func TestEmbeddingStore_EmbedTracksSkipsUnchanged(t *testing.T) {
+	ctx := context.Background()
+	repo := newEmbeddingTestRepo(t)
+	client := &wordEmbeddingClient{}
+	store := NewEmbeddingStore(repo, NewEmbeddingService(client))
+
+	tracks, err := repo.ListTracks(ctx, "user-123", models.TrackFilter{})
+	require.NoError(t, err)
+
+	embedded, err := store.EmbedTracks(ctx, "user-123", tracks.Items)
+	require.NoError(t, err)
+	assert.Equal(t, 4, embedded)
+
+	embedded, err = store.EmbedTracks(ctx, "user-123", tracks.Items)
+	require.NoError(t, err)
+	assert.Zero(t, embedded, "unchanged tracks are not re-embedded")
+
+	tracks.Items[0].Genre = "Dubstep"
+	embedded, err = store.EmbedTracks(ctx, "user-123", tracks.Items)
+	require.NoError(t, err)
+	assert.Equal(t, 1, embedded)
+	assert.Equal(t, 5, client.calls[EmbeddingModelID])
+
+	vectors, err := store.GetEmbeddings(ctx, "user-123", []string{"t1", "t4", "missing"})
+	require.NoError(t, err)
+	assert.Len(t, vectors, 2)
+	assert.Len(t, vectors["t1"], 32)
+}
+
+func TestEmbeddingStore_EmbedTracksAllFail(t *testing.T) {
+	ctx := context.Background()
+	repo := newEmbeddingTestRepo(t)
+	store := NewEmbeddingStore(repo, NewEmbeddingService(&wordEmbeddingClient{failOn: "Track"}))
+
+	tracks, err := repo.ListTracks(ctx, "user-123", models.TrackFilter{})
+	require.NoError(t, err)
+
+	_, err = store.EmbedTracks(ctx, "user-123", tracks.Items)
+	assert.Error(t, err)
+
+	_, err = NewEmbeddingStore(repo, nil).EmbedTracks(ctx, "user-123", tracks.Items)
+	assert.Error(t, err, "read-only stores cannot embed")
+}
+
+func TestEmbeddingStore_MigrateEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	repo := newEmbeddingTestRepo(t)
+	client := &wordEmbeddingClient{}
+	embedder := NewEmbeddingService(client)
+
+	result, err := NewEmbeddingStore(repo, embedder).MigrateEmbeddings(ctx, "user-123")
+	require.NoError(t, err)
+	assert.Equal(t, &EmbeddingMigrationResult{Model: EmbeddingModelID, Tracks: 4, Embedded: 4}, result)
+
+	// A track deleted since is orphaned; t3 fails under the new model
+	require.NoError(t, repo.DeleteTrack(ctx, "user-123", "t4"))
+	client.failOn = "Rival"
+	next := NewEmbeddingStore(repo, embedder.WithModel("model-v2"))
+
+	result, err = next.MigrateEmbeddings(ctx, "user-123")
+	require.NoError(t, err)
+	assert.Equal(t, &EmbeddingMigrationResult{Model: "model-v2", Tracks: 3, Embedded: 2, Removed: 3}, result)
+
+	remaining, err := repo.ListTrackEmbeddings(ctx, "user-123", "")
+	require.NoError(t, err)
+	var keys []string
+	for _, embedding := range remaining {
+		keys = append(keys, embedding.Model+"/"+embedding.TrackID)
+	}
+	assert.Equal(t, []string{"model-v2/t1", "model-v2/t2", EmbeddingModelID + "/t3"}, keys,
+		"the failed track keeps its old vector")
+
+	// Rerunning resumes the failed track
+	client.failOn = ""
+	result, err = next.MigrateEmbeddings(ctx, "user-123")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Embedded)
+	assert.Equal(t, 1, result.Removed)
+}
+
+func TestFindSimilarTracks_EmbeddingMode(t *testing.T) {
+	ctx := context.Background()
+	repo := newEmbeddingTestRepo(t)
+	embedder := NewEmbeddingService(&wordEmbeddingClient{})
+	_, err := NewEmbeddingStore(repo, embedder).MigrateEmbeddings(ctx, "user-123")
+	require.NoError(t, err)
+
+	svc := NewSimilarityService(nil, repo, embedder)
+	opts := SimilarityOptions{Limit: 10, Mode: "embedding", MinSimilarity: 0.1, IncludeSameAlbum: true}
+
+	result, err := svc.FindSimilarTracks(ctx, "user-123", "t1", opts)
+	require.NoError(t, err)
+	require.Len(t, result.Similar, 3)
+	assert.Equal(t, "t2", result.Similar[0].Track.ID, "same artist and album ranks first")
+	assert.Equal(t, "t4", result.Similar[2].Track.ID)
+	assert.Equal(t, 2, result.Similar[0].BPMDiff)
+
+	t.Run("limit and album filter", func(t *testing.T) {
+		opts := opts
+		opts.Limit = 1
+		opts.IncludeSameAlbum = false
+		result, err := svc.FindSimilarTracks(ctx, "user-123", "t1", opts)
+		require.NoError(t, err)
+		require.Len(t, result.Similar, 1)
+		assert.Equal(t, "t3", result.Similar[0].Track.ID)
+	})
+
+	t.Run("deleted tracks are skipped", func(t *testing.T) {
+		require.NoError(t, repo.DeleteTrack(ctx, "user-123", "t2"))
+		result, err := svc.FindSimilarTracks(ctx, "user-123", "t1", opts)
+		require.NoError(t, err)
+		assert.Len(t, result.Similar, 2)
+	})
+
+	t.Run("track without embedding", func(t *testing.T) {
+		require.NoError(t, repo.CreateTrack(ctx, createSimilarityTestTrack("t5", "New", "New", "New", "", 0, nil)))
+		_, err := svc.FindSimilarTracks(ctx, "user-123", "t5", opts)
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 404, apiErr.StatusCode)
+	})
+
+	t.Run("repository without embeddings", func(t *testing.T) {
+		plain := NewSimilarityService(nil, plainRepository{repo}, embedder)
+		_, err := plain.FindSimilarTracks(ctx, "user-123", "t1", opts)
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 503, apiErr.StatusCode)
+	})
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+// newSeededRepo returns a memory repository holding tracks, which tests build
+// with builders.NewTrackBuilder
+func newSeededRepo(t *testing.T, tracks ...models.Track) *repository.MemoryRepository {
+	t.Helper()
+	repo := repository.NewMemoryRepository()
+	for _, track := range tracks {
+		require.NoError(t, repo.CreateTrack(context.Background(), track))
+	}
+	return repo
+}
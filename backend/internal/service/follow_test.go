@@ -7,71 +7,15 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockFollowRepository is a mock implementation for follow operations
-type MockFollowRepository struct {
-	mock.Mock
-}
-
-func (m *MockFollowRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
-	args := m.Called(ctx, follow)
-	return args.Error(0)
-}
-
-func (m *MockFollowRepository) DeleteFollow(ctx context.Context, followerID, followedID string) error {
-	args := m.Called(ctx, followerID, followedID)
-	return args.Error(0)
-}
-
-func (m *MockFollowRepository) GetFollow(ctx context.Context, followerID, followedID string) (*models.Follow, error) {
-	args := m.Called(ctx, followerID, followedID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Follow), args.Error(1)
-}
-
-func (m *MockFollowRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	args := m.Called(ctx, userID, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Follow]), args.Error(1)
-}
-
-func (m *MockFollowRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	args := m.Called(ctx, userID, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Follow]), args.Error(1)
-}
-
-func (m *MockFollowRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
-	args := m.Called(ctx, userID, delta)
-	return args.Error(0)
-}
-
-func (m *MockFollowRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
-	args := m.Called(ctx, userID, delta)
-	return args.Error(0)
-}
-
-func (m *MockFollowRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.ArtistProfile), args.Error(1)
-}
-
 func TestNewFollowService(t *testing.T) {
 	t.Run("creates service with repository", func(t *testing.T) {
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 		svc := NewFollowService(mockRepo)
 		require.NotNil(t, svc)
 	})
@@ -80,7 +24,7 @@ func TestNewFollowService(t *testing.T) {
 func TestFollowService_Follow(t *testing.T) {
 	t.Run("creates follow relationship", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		profile := &models.ArtistProfile{
 			UserID:      "artist-123",
@@ -103,7 +47,7 @@ func TestFollowService_Follow(t *testing.T) {
 
 	t.Run("prevents self-follow", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		svc := NewFollowService(mockRepo)
 		err := svc.Follow(ctx, "user-123", "user-123")
@@ -114,7 +58,7 @@ func TestFollowService_Follow(t *testing.T) {
 
 	t.Run("returns error when artist profile not found", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("GetArtistProfile", ctx, "nonexistent").Return((*models.ArtistProfile)(nil), repository.ErrNotFound)
 
@@ -127,7 +71,7 @@ func TestFollowService_Follow(t *testing.T) {
 
 	t.Run("returns error when already following", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		profile := &models.ArtistProfile{
 			UserID:      "artist-123",
@@ -148,7 +92,7 @@ func TestFollowService_Follow(t *testing.T) {
 func TestFollowService_Unfollow(t *testing.T) {
 	t.Run("deletes follow relationship", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("DeleteFollow", ctx, "user-123", "artist-123").Return(nil)
 		mockRepo.On("IncrementArtistFollowerCount", ctx, "artist-123", -1).Return(nil)
@@ -163,7 +107,7 @@ func TestFollowService_Unfollow(t *testing.T) {
 
 	t.Run("returns error when not following", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("DeleteFollow", ctx, "user-123", "artist-123").Return(repository.ErrNotFound)
 
@@ -178,7 +122,7 @@ func TestFollowService_Unfollow(t *testing.T) {
 func TestFollowService_IsFollowing(t *testing.T) {
 	t.Run("returns true when following", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		follow := &models.Follow{
 			FollowerID: "user-123",
@@ -197,7 +141,7 @@ func TestFollowService_IsFollowing(t *testing.T) {
 
 	t.Run("returns false when not following", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("GetFollow", ctx, "user-123", "artist-123").Return((*models.Follow)(nil), repository.ErrNotFound)
 
@@ -212,7 +156,7 @@ func TestFollowService_IsFollowing(t *testing.T) {
 func TestFollowService_GetFollowers(t *testing.T) {
 	t.Run("returns list of followers", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		follows := &repository.PaginatedResult[models.Follow]{
 			Items: []models.Follow{
@@ -233,7 +177,7 @@ func TestFollowService_GetFollowers(t *testing.T) {
 
 	t.Run("handles pagination", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		follows := &repository.PaginatedResult[models.Follow]{
 			Items: []models.Follow{
@@ -257,7 +201,7 @@ func TestFollowService_GetFollowers(t *testing.T) {
 func TestFollowService_GetFollowing(t *testing.T) {
 	t.Run("returns list of users being followed", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		follows := &repository.PaginatedResult[models.Follow]{
 			Items: []models.Follow{
@@ -278,7 +222,7 @@ func TestFollowService_GetFollowing(t *testing.T) {
 
 	t.Run("handles empty following list", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockFollowRepository)
+		mockRepo := new(mocks.Repository)
 
 		follows := &repository.PaginatedResult[models.Follow]{
 			Items:   []models.Follow{},
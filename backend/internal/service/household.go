@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// HouseholdRepository defines the repository interface for households
+type HouseholdRepository interface {
+	CreateHousehold(ctx context.Context, household models.Household, owner models.HouseholdMember) error
+	GetHousehold(ctx context.Context, householdID string) (*models.Household, error)
+	UpdateHousehold(ctx context.Context, household models.Household) error
+	DeleteHousehold(ctx context.Context, householdID string, memberIDs []string) error
+	AddHouseholdMember(ctx context.Context, member models.HouseholdMember) error
+	RemoveHouseholdMember(ctx context.Context, householdID, userID string) error
+	UpdateHouseholdMember(ctx context.Context, member models.HouseholdMember) error
+	GetHouseholdMember(ctx context.Context, householdID, userID string) (*models.HouseholdMember, error)
+	ListHouseholdMembers(ctx context.Context, householdID string) ([]models.HouseholdMember, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+// LibraryIDResolver is implemented by repositories that scope library data to a household
+type LibraryIDResolver interface {
+	ResolveLibraryID(ctx context.Context, userID string) (string, error)
+}
+
+// HouseholdServiceImpl implements HouseholdService
+type HouseholdServiceImpl struct {
+	repo HouseholdRepository
+}
+
+// NewHouseholdService creates a new household service
+func NewHouseholdService(repo HouseholdRepository) *HouseholdServiceImpl {
+	return &HouseholdServiceImpl{repo: repo}
+}
+
+// CreateHousehold creates a household owned by the user. The owner's library
+// becomes the shared library and their storage quota the shared quota.
+func (s *HouseholdServiceImpl) CreateHousehold(ctx context.Context, userID string, req models.CreateHouseholdRequest) (*models.HouseholdResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HouseholdID != "" {
+		return nil, models.NewConflictError("you already belong to a household")
+	}
+
+	now := time.Now()
+	household := models.Household{
+		ID:          uuid.New().String(),
+		Name:        strings.TrimSpace(req.Name),
+		OwnerID:     userID,
+		MemberCount: 1,
+		Timestamps: models.Timestamps{
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	owner := models.HouseholdMember{
+		HouseholdID: household.ID,
+		UserID:      userID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Role:        models.HouseholdRoleOwner,
+		JoinedAt:    now,
+	}
+
+	if err := s.repo.CreateHousehold(ctx, household, owner); err != nil {
+		if err == repository.ErrAlreadyExists {
+			return nil, models.NewConflictError("you already belong to a household")
+		}
+		return nil, err
+	}
+
+	response := household.ToResponse([]models.HouseholdMember{owner})
+	return &response, nil
+}
+
+// GetHousehold returns the user's household and its members
+func (s *HouseholdServiceImpl) GetHousehold(ctx context.Context, userID string) (*models.HouseholdResponse, error) {
+	household, _, err := s.getMembership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListHouseholdMembers(ctx, household.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := household.ToResponse(members)
+	return &response, nil
+}
+
+// UpdateHousehold renames the household. Owners and managers only.
+func (s *HouseholdServiceImpl) UpdateHousehold(ctx context.Context, userID string, req models.UpdateHouseholdRequest) (*models.HouseholdResponse, error) {
+	household, member, err := s.getMembership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManageMembers() {
+		return nil, models.NewForbiddenError("only household owners and managers can update the household")
+	}
+
+	household.Name = strings.TrimSpace(req.Name)
+	if err := s.repo.UpdateHousehold(ctx, *household); err != nil {
+		return nil, err
+	}
+
+	return s.GetHousehold(ctx, userID)
+}
+
+// DeleteHousehold dissolves the household. Owner only. Tracks stay in the owner's
+// library; members return to their own libraries. A dry run reports the
+// household and its members without dissolving it.
+func (s *HouseholdServiceImpl) DeleteHousehold(ctx context.Context, userID string, dryRun bool) (*models.DeleteHouseholdResult, error) {
+	household, member, err := s.getMembership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != models.HouseholdRoleOwner {
+		return nil, models.NewForbiddenError("only the household owner can delete the household")
+	}
+
+	members, err := s.repo.ListHouseholdMembers(ctx, household.ID)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs := make([]string, 0, len(members))
+	for _, m := range members {
+		memberIDs = append(memberIDs, m.UserID)
+	}
+
+	plan := &bulkPlan[string, *models.DeleteHouseholdResult]{
+		changes: []string{household.ID},
+		result:  &models.DeleteHouseholdResult{ID: household.ID, Name: household.Name, MemberIDs: memberIDs, DryRun: dryRun},
+	}
+	if _, err := plan.execute(ctx, dryRun, func(ctx context.Context, id string) error {
+		return s.repo.DeleteHousehold(ctx, id, memberIDs)
+	}); err != nil {
+		return nil, err
+	}
+	return plan.result, nil
+}
+
+// AddMember attaches an existing user, found by email, to the household.
+// Owners and managers only; the user must not already belong to a household.
+func (s *HouseholdServiceImpl) AddMember(ctx context.Context, userID string, req models.AddHouseholdMemberRequest) (*models.HouseholdMember, error) {
+	household, member, err := s.getMembership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManageMembers() {
+		return nil, models.NewForbiddenError("only household owners and managers can add members")
+	}
+	if household.MemberCount >= models.MaxHouseholdMembers {
+		return nil, models.NewValidationError(fmt.Sprintf("a household can have at most %d members", models.MaxHouseholdMembers))
+	}
+
+	email := strings.TrimSpace(req.Email)
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == repository.ErrUserNotFound || err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("User", email)
+		}
+		return nil, err
+	}
+	if user.HouseholdID != "" {
+		return nil, models.NewConflictError("user already belongs to a household")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.HouseholdRoleMember
+	}
+	// Managers cannot appoint other managers
+	if role == models.HouseholdRoleManager && member.Role != models.HouseholdRoleOwner {
+		return nil, models.NewForbiddenError("only the household owner can add managers")
+	}
+
+	newMember := models.HouseholdMember{
+		HouseholdID: household.ID,
+		UserID:      user.ID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	}
+	if err := s.repo.AddHouseholdMember(ctx, newMember); err != nil {
+		if err == repository.ErrAlreadyExists {
+			return nil, models.NewConflictError("user already belongs to a household")
+		}
+		return nil, err
+	}
+
+	return &newMember, nil
+}
+
+// UpdateMemberRole changes a member's role. Owner only; ownership cannot be transferred here.
+func (s *HouseholdServiceImpl) UpdateMemberRole(ctx context.Context, userID, memberID string, req models.UpdateHouseholdMemberRoleRequest) (*models.HouseholdMember, error) {
+	household, member, err := s.getMembership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != models.HouseholdRoleOwner {
+		return nil, models.NewForbiddenError("only the household owner can change member roles")
+	}
+
+	target, err := s.repo.GetHouseholdMember(ctx, household.ID, memberID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Household member", memberID)
+		}
+		return nil, err
+	}
+	if target.Role == models.HouseholdRoleOwner {
+		return nil, models.NewValidationError("the owner's role cannot be changed")
+	}
+
+	target.Role = req.Role
+	if err := s.repo.UpdateHouseholdMember(ctx, *target); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// RemoveMember detaches a member from the household. Members may remove themselves
+// (leave); owners and managers may remove members, but only the owner may remove a
+// manager. The owner cannot leave and must delete the household instead.
+func (s *HouseholdServiceImpl) RemoveMember(ctx context.Context, userID, memberID string) error {
+	household, member, err := s.getMembership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.repo.GetHouseholdMember(ctx, household.ID, memberID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return models.NewNotFoundError("Household member", memberID)
+		}
+		return err
+	}
+	if target.Role == models.HouseholdRoleOwner {
+		return models.NewValidationError("the household owner cannot be removed; delete the household instead")
+	}
+
+	if memberID != userID {
+		if !member.Role.CanManageMembers() {
+			return models.NewForbiddenError("only household owners and managers can remove members")
+		}
+		if target.Role == models.HouseholdRoleManager && member.Role != models.HouseholdRoleOwner {
+			return models.NewForbiddenError("only the household owner can remove managers")
+		}
+	}
+
+	if err := s.repo.RemoveHouseholdMember(ctx, household.ID, memberID); err != nil {
+		if err == repository.ErrNotFound {
+			return models.NewNotFoundError("Household member", memberID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ResolveLibraryID returns the shared library partition for household members,
+// or the user's own ID otherwise. Used by repository.LibraryScopedRepository.
+func (s *HouseholdServiceImpl) ResolveLibraryID(ctx context.Context, userID string) (string, error) {
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return userID, nil
+		}
+		return "", err
+	}
+	if user.HouseholdID == "" {
+		return userID, nil
+	}
+
+	household, err := s.repo.GetHousehold(ctx, user.HouseholdID)
+	if err != nil {
+		// A dangling link falls back to the user's own library
+		if err == repository.ErrNotFound {
+			return userID, nil
+		}
+		return "", err
+	}
+
+	return household.LibraryID(), nil
+}
+
+// getMembership loads the user's household and their member record
+func (s *HouseholdServiceImpl) getMembership(ctx context.Context, userID string) (*models.Household, *models.HouseholdMember, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.HouseholdID == "" {
+		return nil, nil, models.ErrNotInHousehold
+	}
+
+	household, err := s.repo.GetHousehold(ctx, user.HouseholdID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, nil, models.ErrNotInHousehold
+		}
+		return nil, nil, err
+	}
+
+	member, err := s.repo.GetHouseholdMember(ctx, household.ID, userID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, nil, models.ErrNotInHousehold
+		}
+		return nil, nil, err
+	}
+
+	return household, member, nil
+}
+
+func (s *HouseholdServiceImpl) getUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("User", userID)
+		}
+		return nil, err
+	}
+	return user, nil
+}
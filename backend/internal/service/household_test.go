@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMembership stubs the lookups behind getMembership for a user with the given role
+func setupMembership(ctx context.Context, mockRepo *mocks.HouseholdRepository, userID string, role models.HouseholdRole) {
+	household := &models.Household{ID: "hh-1", Name: "Home", OwnerID: "owner-1", MemberCount: 2}
+	mockRepo.On("GetUser", ctx, userID).Return(&models.User{ID: userID, HouseholdID: "hh-1"}, nil)
+	mockRepo.On("GetHousehold", ctx, "hh-1").Return(household, nil)
+	mockRepo.On("GetHouseholdMember", ctx, "hh-1", userID).Return(&models.HouseholdMember{HouseholdID: "hh-1", UserID: userID, Role: role}, nil)
+}
+
+func TestHouseholdService_CreateHousehold(t *testing.T) {
+	t.Run("creates household with the caller as owner", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+
+		mockRepo.On("GetUser", ctx, "user-1").Return(&models.User{ID: "user-1", Email: "a@example.com"}, nil)
+		mockRepo.On("CreateHousehold", ctx, mock.AnythingOfType("models.Household"), mock.MatchedBy(func(m models.HouseholdMember) bool {
+			return m.UserID == "user-1" && m.Role == models.HouseholdRoleOwner
+		})).Return(nil)
+
+		svc := NewHouseholdService(mockRepo)
+		resp, err := svc.CreateHousehold(ctx, "user-1", models.CreateHouseholdRequest{Name: " Home "})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Home", resp.Name)
+		assert.Equal(t, "user-1", resp.LibraryID)
+		assert.Len(t, resp.Members, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects users already in a household", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+
+		mockRepo.On("GetUser", ctx, "user-1").Return(&models.User{ID: "user-1", HouseholdID: "hh-9"}, nil)
+
+		svc := NewHouseholdService(mockRepo)
+		_, err := svc.CreateHousehold(ctx, "user-1", models.CreateHouseholdRequest{Name: "Home"})
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 409, apiErr.StatusCode)
+	})
+}
+
+func TestHouseholdService_AddMember(t *testing.T) {
+	t.Run("manager adds a member", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		setupMembership(ctx, mockRepo, "manager-1", models.HouseholdRoleManager)
+
+		mockRepo.On("GetUserByEmail", ctx, "kid@example.com").Return(&models.User{ID: "kid-1", Email: "kid@example.com"}, nil)
+		mockRepo.On("AddHouseholdMember", ctx, mock.MatchedBy(func(m models.HouseholdMember) bool {
+			return m.UserID == "kid-1" && m.Role == models.HouseholdRoleMember
+		})).Return(nil)
+
+		svc := NewHouseholdService(mockRepo)
+		member, err := svc.AddMember(ctx, "manager-1", models.AddHouseholdMemberRequest{Email: "kid@example.com"})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.HouseholdRoleMember, member.Role)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("plain members cannot add members", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		setupMembership(ctx, mockRepo, "member-1", models.HouseholdRoleMember)
+
+		svc := NewHouseholdService(mockRepo)
+		_, err := svc.AddMember(ctx, "member-1", models.AddHouseholdMemberRequest{Email: "kid@example.com"})
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 403, apiErr.StatusCode)
+		mockRepo.AssertNotCalled(t, "AddHouseholdMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("managers cannot appoint managers", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		setupMembership(ctx, mockRepo, "manager-1", models.HouseholdRoleManager)
+
+		mockRepo.On("GetUserByEmail", ctx, "kid@example.com").Return(&models.User{ID: "kid-1"}, nil)
+
+		svc := NewHouseholdService(mockRepo)
+		_, err := svc.AddMember(ctx, "manager-1", models.AddHouseholdMemberRequest{Email: "kid@example.com", Role: models.HouseholdRoleManager})
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 403, apiErr.StatusCode)
+	})
+}
+
+func TestHouseholdService_RemoveMember(t *testing.T) {
+	t.Run("member can leave", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		setupMembership(ctx, mockRepo, "member-1", models.HouseholdRoleMember)
+		mockRepo.On("RemoveHouseholdMember", ctx, "hh-1", "member-1").Return(nil)
+
+		svc := NewHouseholdService(mockRepo)
+		err := svc.RemoveMember(ctx, "member-1", "member-1")
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("owner cannot be removed", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		setupMembership(ctx, mockRepo, "manager-1", models.HouseholdRoleManager)
+		mockRepo.On("GetHouseholdMember", ctx, "hh-1", "owner-1").Return(&models.HouseholdMember{UserID: "owner-1", Role: models.HouseholdRoleOwner}, nil)
+
+		svc := NewHouseholdService(mockRepo)
+		err := svc.RemoveMember(ctx, "manager-1", "owner-1")
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 400, apiErr.StatusCode)
+	})
+}
+
+func TestHouseholdService_DeleteHousehold(t *testing.T) {
+	t.Run("owner dissolves the household", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		setupMembership(ctx, mockRepo, "owner-1", models.HouseholdRoleOwner)
+		mockRepo.On("ListHouseholdMembers", ctx, "hh-1").Return([]models.HouseholdMember{{UserID: "owner-1"}, {UserID: "kid-1"}}, nil)
+		mockRepo.On("DeleteHousehold", ctx, "hh-1", []string{"owner-1", "kid-1"}).Return(nil)
+
+		svc := NewHouseholdService(mockRepo)
+		result, err := svc.DeleteHousehold(ctx, "owner-1", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"owner-1", "kid-1"}, result.MemberIDs)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("a dry run reports the members without dissolving it", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		setupMembership(ctx, mockRepo, "owner-1", models.HouseholdRoleOwner)
+		mockRepo.On("ListHouseholdMembers", ctx, "hh-1").Return([]models.HouseholdMember{{UserID: "owner-1"}, {UserID: "kid-1"}}, nil)
+
+		svc := NewHouseholdService(mockRepo)
+		result, err := svc.DeleteHousehold(ctx, "owner-1", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.DeleteHouseholdResult{ID: "hh-1", Name: "Home", MemberIDs: []string{"owner-1", "kid-1"}, DryRun: true}, result)
+		mockRepo.AssertNotCalled(t, "DeleteHousehold", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("only the owner can dissolve it", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		setupMembership(ctx, mockRepo, "manager-1", models.HouseholdRoleManager)
+
+		svc := NewHouseholdService(mockRepo)
+		_, err := svc.DeleteHousehold(ctx, "manager-1", true)
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 403, apiErr.StatusCode)
+	})
+}
+
+func TestHouseholdService_ResolveLibraryID(t *testing.T) {
+	t.Run("members resolve to the owner's library", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		mockRepo.On("GetUser", ctx, "member-1").Return(&models.User{ID: "member-1", HouseholdID: "hh-1"}, nil)
+		mockRepo.On("GetHousehold", ctx, "hh-1").Return(&models.Household{ID: "hh-1", OwnerID: "owner-1"}, nil)
+
+		libraryID, err := NewHouseholdService(mockRepo).ResolveLibraryID(ctx, "member-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "owner-1", libraryID)
+	})
+
+	t.Run("users without a household keep their own library", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.HouseholdRepository)
+		mockRepo.On("GetUser", ctx, "solo-1").Return(nil, repository.ErrNotFound)
+
+		libraryID, err := NewHouseholdService(mockRepo).ResolveLibraryID(ctx, "solo-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "solo-1", libraryID)
+	})
+}
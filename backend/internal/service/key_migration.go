@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+)
+
+// Key migration batch limits
+const (
+	defaultKeyMigrationBatchSize = 100
+	maxKeyMigrationBatchSize     = 1000
+)
+
+// KeyLayout is an S3 key template such as "covers/{userId}/{uploadId}{ext}".
+// {userId}, {trackId} and {albumId} are filled from the track when building a
+// key; {ext} matches a file extension; any other {name} matches one segment
+// (or the rest of one) and is carried from the old key to the new one.
+type KeyLayout string
+
+// KeyMigration moves the objects referenced by one track field from one key
+// layout to another.
+type KeyMigration struct {
+	Name  string               `json:"name,omitempty"`
+	Field models.TrackKeyField `json:"field"`
+	From  KeyLayout            `json:"from"`
+	To    KeyLayout            `json:"to"`
+}
+
+// KeyMigrations are the layout changes known by name
+var KeyMigrations = map[string]KeyMigration{
+	"covers-by-track": {
+		Name:  "covers-by-track",
+		Field: models.TrackKeyFieldCoverArt,
+		From:  "covers/{userId}/{uploadId}{ext}",
+		To:    "covers/{userId}/{trackId}/cover{ext}",
+	},
+}
+
+// KeyMigrationRequest selects a migration and the batch to run. Runs are
+// resumed by passing the previous result's NextCursor.
+type KeyMigrationRequest struct {
+	Migration KeyMigration `json:"migration"`
+	// UserID limits the run to one library; empty migrates every user's tracks
+	UserID string `json:"userId,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+	// BatchSize is the number of tracks read per batch (default 100, max 1000)
+	BatchSize int `json:"batchSize,omitempty"`
+	// MaxBatches stops the run after this many batches (default 1)
+	MaxBatches int `json:"maxBatches,omitempty"`
+	// DryRun reports what would move without copying or updating anything
+	DryRun bool `json:"dryRun,omitempty"`
+	// Until stops the run from starting new batches after this time
+	Until time.Time `json:"-"`
+}
+
+// KeyMigrationResult summarizes one run
+type KeyMigrationResult struct {
+	Migration  string    `json:"migration"`
+	Scanned    int       `json:"scanned"`
+	Moved      int       `json:"moved"`   // Would move, on dry runs
+	Skipped    int       `json:"skipped"` // Empty, already migrated or in another layout
+	Conflicts  int       `json:"conflicts"`
+	Errors     []string  `json:"errors,omitempty"`
+	DryRun     bool      `json:"dryRun"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+	Done       bool      `json:"done"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// KeyMigrationRepository is what layout migrations need from the repository
+type KeyMigrationRepository interface {
+	ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error)
+	GetAlbum(ctx context.Context, userID, albumID string) (*models.Album, error)
+	UpdateTrackObjectKey(ctx context.Context, userID, trackID string, field models.TrackKeyField, from, to, albumID string) error
+}
+
+// KeyMigrationService moves S3 objects to a new key layout and repoints the
+// DynamoDB items that reference them.
+//
+// Each object is copied, then the track (and an album sharing the cover) is
+// updated only if it still references the old key, and only then is the old
+// object deleted. Every step is safe to repeat, so an interrupted run is
+// resumed from its last cursor, or simply rerun.
+type KeyMigrationService struct {
+	repo   KeyMigrationRepository
+	s3Repo repository.MediaStore
+}
+
+// NewKeyMigrationService creates a new KeyMigrationService
+func NewKeyMigrationService(repo KeyMigrationRepository, s3Repo repository.MediaStore) *KeyMigrationService {
+	return &KeyMigrationService{repo: repo, s3Repo: s3Repo}
+}
+
+// Run migrates up to MaxBatches batches of tracks starting at the cursor
+func (s *KeyMigrationService) Run(ctx context.Context, req KeyMigrationRequest) (*KeyMigrationResult, error) {
+	from, err := compileKeyLayout(req.Migration.From)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateKeyMigration(req.Migration, from); err != nil {
+		return nil, err
+	}
+
+	batchSize := req.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultKeyMigrationBatchSize
+	}
+	batchSize = min(batchSize, maxKeyMigrationBatchSize)
+	maxBatches := max(req.MaxBatches, 1)
+
+	result := &KeyMigrationResult{
+		Migration: req.Migration.Name,
+		DryRun:    req.DryRun,
+		StartedAt: time.Now(),
+		Errors:    []string{},
+	}
+
+	cursor := req.Cursor
+	for batch := 0; batch < maxBatches; batch++ {
+		page, err := s.repo.ListTracks(ctx, req.UserID, models.TrackFilter{
+			Limit:       batchSize,
+			LastKey:     cursor,
+			GlobalScope: req.UserID == "",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tracks: %w", err)
+		}
+
+		for _, track := range page.Items {
+			result.Scanned++
+			s.migrateTrack(ctx, req, from, track, result)
+		}
+
+		cursor = page.NextCursor
+		if !page.HasMore || cursor == "" {
+			result.Done = true
+			cursor = ""
+			break
+		}
+		if ctx.Err() != nil || (!req.Until.IsZero() && time.Now().After(req.Until)) {
+			break
+		}
+	}
+
+	result.NextCursor = cursor
+	result.FinishedAt = time.Now()
+	return result, nil
+}
+
+// migrateTrack moves one track's object and records the outcome in result
+func (s *KeyMigrationService) migrateTrack(ctx context.Context, req KeyMigrationRequest, from *regexp.Regexp, track models.Track, result *KeyMigrationResult) {
+	field := req.Migration.Field
+	oldKey := track.ObjectKey(field)
+	newKey, ok, err := rewriteKey(from, req.Migration.To, track, oldKey)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("track %s: %v", track.ID, err))
+		return
+	}
+	if !ok || newKey == oldKey {
+		result.Skipped++
+		return
+	}
+	if req.DryRun {
+		result.Moved++
+		return
+	}
+
+	// An earlier run may have copied the object and stopped before the update
+	exists, err := s.s3Repo.ObjectExists(ctx, oldKey)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("track %s: %v", track.ID, err))
+		return
+	}
+	if exists {
+		if err := s.s3Repo.CopyObject(ctx, oldKey, newKey); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("track %s: %v", track.ID, err))
+			return
+		}
+	} else if copied, err := s.s3Repo.ObjectExists(ctx, newKey); err != nil || !copied {
+		result.Errors = append(result.Errors, fmt.Sprintf("track %s: object %s is missing", track.ID, oldKey))
+		return
+	}
+
+	albumID := ""
+	if field == models.TrackKeyFieldCoverArt && track.AlbumID != "" {
+		album, err := s.repo.GetAlbum(ctx, track.UserID, track.AlbumID)
+		if err == nil && album.CoverArtKey == oldKey {
+			albumID = album.ID
+		}
+	}
+
+	err = s.repo.UpdateTrackObjectKey(ctx, track.UserID, track.ID, field, oldKey, newKey, albumID)
+	if errors.Is(err, repository.ErrConflict) {
+		// The track changed since it was read; a rerun picks it up again
+		result.Conflicts++
+		return
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("track %s: %v", track.ID, err))
+		return
+	}
+
+	// Nothing references the old object anymore; a failed delete only leaves an orphan
+	if err := s.s3Repo.DeleteObject(ctx, oldKey); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("track %s: moved, but failed to delete %s: %v", track.ID, oldKey, err))
+	}
+	result.Moved++
+}
+
+// keyLayoutPlaceholder matches {name} in a key layout
+var keyLayoutPlaceholder = regexp.MustCompile(`\{([A-Za-z]+)\}`)
+
+// trackKeyVars are the placeholders filled from the track
+var trackKeyVars = map[string]bool{"userId": true, "trackId": true, "albumId": true}
+
+// compileKeyLayout turns a layout into a regexp with one named group per
+// placeholder
+func compileKeyLayout(layout KeyLayout) (*regexp.Regexp, error) {
+	if layout == "" {
+		return nil, fmt.Errorf("key layout is required")
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	seen := make(map[string]bool)
+	last := 0
+	for _, loc := range keyLayoutPlaceholder.FindAllStringSubmatchIndex(string(layout), -1) {
+		pattern.WriteString(regexp.QuoteMeta(string(layout[last:loc[0]])))
+		name := string(layout[loc[2]:loc[3]])
+		if seen[name] {
+			return nil, fmt.Errorf("key layout %q repeats {%s}", layout, name)
+		}
+		seen[name] = true
+		if name == "ext" {
+			pattern.WriteString(`(?P<ext>\.[A-Za-z0-9]{1,9})`)
+		} else {
+			pattern.WriteString(`(?P<` + name + `>[^/]+?)`)
+		}
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(string(layout[last:])))
+	pattern.WriteString("$")
+	return regexp.Compile(pattern.String())
+}
+
+// validateKeyMigration checks that every placeholder of the new layout can be
+// filled from the track or the old key
+func validateKeyMigration(migration KeyMigration, from *regexp.Regexp) error {
+	if migration.Field != models.TrackKeyFieldAudio && migration.Field != models.TrackKeyFieldCoverArt {
+		return fmt.Errorf("unsupported key field %q", migration.Field)
+	}
+	if _, err := compileKeyLayout(migration.To); err != nil {
+		return err
+	}
+	for _, match := range keyLayoutPlaceholder.FindAllStringSubmatch(string(migration.To), -1) {
+		if !trackKeyVars[match[1]] && from.SubexpIndex(match[1]) < 0 {
+			return fmt.Errorf("key layout %q uses {%s}, which %q does not provide", migration.To, match[1], migration.From)
+		}
+	}
+	return nil
+}
+
+// rewriteKey builds the new key of an object in the old layout. ok is false
+// for keys in any other layout and for keys whose {userId} is not the track
+// owner's.
+func rewriteKey(from *regexp.Regexp, to KeyLayout, track models.Track, key string) (newKey string, ok bool, err error) {
+	match := from.FindStringSubmatch(key)
+	if match == nil {
+		return "", false, nil
+	}
+
+	vars := map[string]string{
+		"userId":  track.UserID,
+		"trackId": track.ID,
+		"albumId": track.AlbumID,
+	}
+	for i, name := range from.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if trackKeyVars[name] && match[i] != vars[name] {
+			return "", false, nil
+		}
+		vars[name] = match[i]
+	}
+
+	var missing string
+	newKey = keyLayoutPlaceholder.ReplaceAllStringFunc(string(to), func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if vars[name] == "" {
+			missing = name
+		}
+		return vars[name]
+	})
+	if missing != "" {
+		return "", false, fmt.Errorf("no {%s} for %s", missing, key)
+	}
+
+	// The new key is checked like any other, so a captured value cannot climb out of its prefix
+	if _, err := sanitize.Key(strings.Split(newKey, "/")...); err != nil {
+		return "", false, err
+	}
+	return newKey, true, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeyMigrationFixture(t *testing.T) (*repository.MemoryRepository, *repository.MemoryS3Repository) {
+	t.Helper()
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	s3 := repository.NewMemoryS3Repository("http://localhost/media")
+
+	album, err := repo.GetOrCreateAlbum(ctx, "u1", "Untrue", "Burial")
+	require.NoError(t, err)
+
+	for _, track := range []models.Track{
+		{ID: "t1", UserID: "u1", AlbumID: album.ID, CoverArtKey: "covers/u1/up1.jpg"},
+		{ID: "t2", UserID: "u1", CoverArtKey: "covers/u1/up2.png"},
+		{ID: "t3", UserID: "u1", CoverArtKey: "covers/u1/t3/cover.png"}, // already migrated
+		{ID: "t4", UserID: "u1"},                                   // no cover
+		{ID: "t5", UserID: "u2", CoverArtKey: "covers/u1/up1.jpg"}, // another owner's key
+	} {
+		require.NoError(t, repo.CreateTrack(ctx, track))
+		if track.CoverArtKey != "" {
+			s3.PutObject(track.CoverArtKey, nil)
+		}
+	}
+	return repo, s3
+}
+
+func TestKeyMigration_CoversByTrack(t *testing.T) {
+	ctx := context.Background()
+	repo, s3 := newKeyMigrationFixture(t)
+	svc := NewKeyMigrationService(repo, s3)
+	req := KeyMigrationRequest{Migration: KeyMigrations["covers-by-track"], BatchSize: 2, MaxBatches: 10}
+
+	dry := req
+	dry.DryRun = true
+	result, err := svc.Run(ctx, dry)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Moved)
+	assert.ElementsMatch(t, []string{"covers/u1/up1.jpg", "covers/u1/up2.png", "covers/u1/t3/cover.png"}, s3.Keys("covers/"), "dry runs change nothing")
+
+	result, err = svc.Run(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Scanned)
+	assert.Equal(t, 2, result.Moved)
+	assert.Equal(t, 3, result.Skipped)
+	assert.Empty(t, result.Errors)
+	assert.True(t, result.Done)
+	assert.Empty(t, result.NextCursor)
+
+	t1, err := repo.GetTrack(ctx, "u1", "t1")
+	require.NoError(t, err)
+	assert.Equal(t, "covers/u1/t1/cover.jpg", t1.CoverArtKey)
+	t5, err := repo.GetTrack(ctx, "u2", "t5")
+	require.NoError(t, err)
+	assert.Equal(t, "covers/u1/up1.jpg", t5.CoverArtKey)
+	assert.ElementsMatch(t, []string{"covers/u1/t1/cover.jpg", "covers/u1/t2/cover.png", "covers/u1/t3/cover.png"}, s3.Keys("covers/"))
+
+	result, err = svc.Run(ctx, req)
+	require.NoError(t, err)
+	assert.Zero(t, result.Moved, "rerunning is a no-op")
+}
+
+func TestKeyMigration_Resume(t *testing.T) {
+	ctx := context.Background()
+	repo, s3 := newKeyMigrationFixture(t)
+	svc := NewKeyMigrationService(repo, s3)
+
+	// A run interrupted after copying t2's cover but before updating the track
+	s3.PutObject("covers/u1/t2/cover.png", nil)
+	require.NoError(t, s3.DeleteObject(ctx, "covers/u1/up2.png"))
+
+	req := KeyMigrationRequest{Migration: KeyMigrations["covers-by-track"], UserID: "u1", BatchSize: 1}
+	moved := 0
+	for runs := 0; ; runs++ {
+		require.Less(t, runs, 10)
+		result, err := svc.Run(ctx, req)
+		require.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		moved += result.Moved
+		if result.Done {
+			break
+		}
+		req.Cursor = result.NextCursor
+	}
+	assert.Equal(t, 2, moved)
+
+	t2, err := repo.GetTrack(ctx, "u1", "t2")
+	require.NoError(t, err)
+	assert.Equal(t, "covers/u1/t2/cover.png", t2.CoverArtKey)
+}
+
+func TestKeyMigration_Conflict(t *testing.T) {
+	ctx := context.Background()
+	repo, s3 := newKeyMigrationFixture(t)
+
+	// The track's cover is replaced between the read and the update
+	err := repo.UpdateTrackObjectKey(ctx, "u1", "t2", models.TrackKeyFieldCoverArt, "covers/u1/other.png", "x", "")
+	assert.ErrorIs(t, err, repository.ErrConflict)
+
+	svc := NewKeyMigrationService(&racingKeyRepository{MemoryRepository: repo}, s3)
+	result, err := svc.Run(ctx, KeyMigrationRequest{Migration: KeyMigrations["covers-by-track"], UserID: "u1"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Conflicts)
+	assert.True(t, hasKey(s3, "covers/u1/up2.png"), "sources are kept when the update loses")
+}
+
+func TestKeyMigration_InvalidLayouts(t *testing.T) {
+	repo, s3 := newKeyMigrationFixture(t)
+	svc := NewKeyMigrationService(repo, s3)
+
+	for name, migration := range map[string]KeyMigration{
+		"unknown placeholder": {Field: models.TrackKeyFieldCoverArt, From: "covers/{userId}/{uploadId}", To: "covers/{userId}/{hash}"},
+		"repeated":            {Field: models.TrackKeyFieldCoverArt, From: "covers/{userId}/{userId}", To: "covers/{userId}"},
+		"unsupported field":   {Field: "hlsPlaylistKey", From: "hls/{userId}/{trackId}", To: "streams/{userId}/{trackId}"},
+	} {
+		_, err := svc.Run(context.Background(), KeyMigrationRequest{Migration: migration})
+		assert.Error(t, err, name)
+	}
+
+	// Captured values cannot climb out of the new prefix
+	from, err := compileKeyLayout("legacy/{userId}/{rest}")
+	require.NoError(t, err)
+	_, ok, err := rewriteKey(from, "covers/{userId}/{rest}/../x", models.Track{ID: "t1", UserID: "u1"}, "legacy/u1/a")
+	assert.False(t, ok)
+	assert.Error(t, err)
+}
+
+// racingKeyRepository changes each track's key just before it is updated
+type racingKeyRepository struct {
+	*repository.MemoryRepository
+}
+
+func (r *racingKeyRepository) UpdateTrackObjectKey(ctx context.Context, userID, trackID string, field models.TrackKeyField, from, to, albumID string) error {
+	if err := r.MemoryRepository.UpdateTrackObjectKey(ctx, userID, trackID, field, from, from+".edited", ""); err != nil {
+		return err
+	}
+	return r.MemoryRepository.UpdateTrackObjectKey(ctx, userID, trackID, field, from, to, albumID)
+}
+
+func hasKey(s3 *repository.MemoryS3Repository, key string) bool {
+	for _, k := range s3.Keys(key) {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+)
+
+// ListeningRoomRepository defines the repository interface for shared
+// listening rooms
+type ListeningRoomRepository interface {
+	// PutListeningRoom fails with repository.ErrConflict unless the stored
+	// room is still at version previous
+	PutListeningRoom(ctx context.Context, room models.ListeningRoom, previous int64) error
+	GetListeningRoom(ctx context.Context, roomID string) (*models.ListeningRoom, error)
+	DeleteListeningRoom(ctx context.Context, roomID string) error
+	PutRoomMember(ctx context.Context, member models.RoomMember) error
+	GetRoomMember(ctx context.Context, roomID, userID string) (*models.RoomMember, error)
+	DeleteRoomMember(ctx context.Context, roomID, userID string) error
+	ListRoomMembers(ctx context.Context, roomID string) ([]models.RoomMember, error)
+
+	GetPlayerState(ctx context.Context, userID string) (*models.PlayerState, error)
+}
+
+// ListeningRoomService runs shared listening rooms: the host opens a room on
+// their play queue and hands out an invite signed with the share link
+// keyring, listeners with an account join with it, and everyone in the room
+// plays the track the room says at the position it says. Only the host
+// controls playback. Tracks are read through the library-scoped repository,
+// as the host's library; members stream them through the room whatever
+// their visibility.
+type ListeningRoomService struct {
+	repo       ListeningRoomRepository
+	tracks     repository.Repository
+	s3Repo     repository.MediaStore
+	cloudfront repository.CloudFrontSigner
+	keys       reqsign.Keyring
+	now        func() time.Time
+}
+
+// NewListeningRoomService creates a new listening room service
+func NewListeningRoomService(repo ListeningRoomRepository, tracks repository.Repository, s3Repo repository.MediaStore, cloudfront repository.CloudFrontSigner, keys reqsign.Keyring) *ListeningRoomService {
+	return &ListeningRoomService{repo: repo, tracks: tracks, s3Repo: s3Repo, cloudfront: cloudfront, keys: keys, now: time.Now}
+}
+
+// Open starts a room on the host's play queue, paused at the queue's current
+// track, and returns its invite
+func (s *ListeningRoomService) Open(ctx context.Context, hostID string, req models.CreateListeningRoomRequest) (*models.ListeningRoomInviteResponse, error) {
+	queue, err := s.repo.GetPlayerState(ctx, hostID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if queue == nil || len(queue.TrackIDs) == 0 {
+		return nil, models.NewValidationError("add tracks to your play queue before opening a room")
+	}
+
+	now := s.now()
+	room := models.ListeningRoom{
+		ID:           uuid.New().String(),
+		HostID:       hostID,
+		Name:         req.Name,
+		TrackIDs:     append([]string{}, queue.TrackIDs...),
+		CurrentIndex: min(max(queue.CurrentIndex, 0), len(queue.TrackIDs)-1),
+		Version:      1,
+		UpdatedAt:    now,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(req.Duration()),
+	}
+	token, err := s.keys.SignToken(models.RoomTokenSubject(room.ID), room.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign room invite: %w", err)
+	}
+	if err := s.repo.PutListeningRoom(ctx, room, 0); err != nil {
+		return nil, err
+	}
+
+	return &models.ListeningRoomInviteResponse{
+		Room:  models.NewListeningRoomState(room, hostID, 0, now),
+		Token: token,
+	}, nil
+}
+
+// Close ends one of the host's rooms; its invite stops working at once
+func (s *ListeningRoomService) Close(ctx context.Context, hostID, roomID string) error {
+	if _, err := s.hostRoom(ctx, hostID, roomID); err != nil {
+		return err
+	}
+	return s.repo.DeleteListeningRoom(ctx, roomID)
+}
+
+// Control changes a room's playback for everyone in it and returns the new
+// state. With req.Version set the change only applies to that version of
+// the room; without it, it applies to the latest one.
+func (s *ListeningRoomService) Control(ctx context.Context, hostID, roomID string, req models.RoomPlaybackRequest) (*models.ListeningRoomState, error) {
+	for attempt := 1; ; attempt++ {
+		room, err := s.hostRoom(ctx, hostID, roomID)
+		if err != nil {
+			return nil, err
+		}
+		now := s.now()
+		if _, err := s.advance(ctx, room, now); err != nil {
+			return nil, err
+		}
+		if req.Version != nil && *req.Version != room.Version {
+			return nil, s.roomConflict(ctx, hostID, roomID)
+		}
+
+		previous := room.Version
+		if err := room.Apply(req, now); err != nil {
+			return nil, err
+		}
+		room.Version++
+
+		err = s.repo.PutListeningRoom(ctx, *room, previous)
+		if err == nil {
+			return s.view(ctx, *room, hostID, now)
+		}
+		if !errors.Is(err, repository.ErrConflict) {
+			return nil, err
+		}
+		if req.Version != nil || attempt == queueWriteAttempts {
+			return nil, s.roomConflict(ctx, hostID, roomID)
+		}
+	}
+}
+
+// Join adds the user to the room an invite points at and returns its state.
+// Joining again, or joining one's own room, changes nothing.
+func (s *ListeningRoomService) Join(ctx context.Context, userID, token string) (*models.ListeningRoomState, error) {
+	subject, err := s.keys.VerifyToken(token, s.now())
+	if err != nil {
+		return nil, models.NewNotFoundError("Room", token)
+	}
+	roomID, ok := models.ParseRoomTokenSubject(subject)
+	if !ok {
+		return nil, models.NewNotFoundError("Room", token)
+	}
+	room, err := s.activeRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if room.HostID != userID {
+		_, err := s.repo.GetRoomMember(ctx, roomID, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			members, err := s.repo.ListRoomMembers(ctx, roomID)
+			if err != nil {
+				return nil, err
+			}
+			if len(members) >= models.MaxRoomMembers {
+				return nil, models.NewConflictError(fmt.Sprintf("the room is full (%d listeners)", models.MaxRoomMembers))
+			}
+			err = s.repo.PutRoomMember(ctx, models.RoomMember{
+				RoomID:    roomID,
+				UserID:    userID,
+				JoinedAt:  s.now(),
+				ExpiresAt: room.ExpiresAt,
+			})
+			if err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.State(ctx, userID, roomID)
+}
+
+// Leave removes the user from a room they joined
+func (s *ListeningRoomService) Leave(ctx context.Context, userID, roomID string) error {
+	room, err := s.memberRoom(ctx, userID, roomID)
+	if err != nil {
+		return err
+	}
+	if room.HostID == userID {
+		return models.NewValidationError("the host closes the room rather than leaving it")
+	}
+	return s.repo.DeleteRoomMember(ctx, roomID, userID)
+}
+
+// State returns where the room's playback is now, moving it past tracks that
+// have finished since it last changed
+func (s *ListeningRoomService) State(ctx context.Context, userID, roomID string) (*models.ListeningRoomState, error) {
+	room, err := s.memberRoom(ctx, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	room, err = s.sync(ctx, room, now)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(ctx, *room, userID, now)
+}
+
+// Stream returns a URL for the track the room is playing. Every member gets
+// the original audio file, so they all seek in the same stream.
+func (s *ListeningRoomService) Stream(ctx context.Context, userID, roomID string) (*models.StreamResponse, error) {
+	room, err := s.memberRoom(ctx, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+	room, err = s.sync(ctx, room, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	trackID := room.CurrentTrackID()
+	track, err := s.tracks.GetTrack(ctx, room.HostID, trackID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+
+	var url string
+	if s.cloudfront != nil {
+		url, err = s.cloudfront.GenerateSignedURL(ctx, track.S3Key, streamURLExpiry)
+	} else {
+		url, err = s.s3Repo.GeneratePresignedDownloadURL(ctx, track.S3Key, streamURLExpiry)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate stream URL: %w", err)
+	}
+
+	return &models.StreamResponse{
+		TrackID:          track.ID,
+		StreamURL:        url,
+		FallbackURL:      url,
+		ExpiresAt:        s.now().Add(streamURLExpiry),
+		Format:           string(track.Format),
+		Bitrate:          track.Bitrate,
+		PlaybackSettings: track.PlaybackSettings,
+	}, nil
+}
+
+// sync stores the room moved past the tracks that have finished by now. If
+// the host changed it in the meantime, the host's change is returned instead.
+func (s *ListeningRoomService) sync(ctx context.Context, room *models.ListeningRoom, now time.Time) (*models.ListeningRoom, error) {
+	previous := room.Version
+	changed, err := s.advance(ctx, room, now)
+	if err != nil || !changed {
+		return room, err
+	}
+	room.Version++
+
+	err = s.repo.PutListeningRoom(ctx, *room, previous)
+	if errors.Is(err, repository.ErrConflict) {
+		return s.activeRoom(ctx, room.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// advance moves room past the tracks that have finished by now, reading
+// their durations from the host's library. A track that is no longer there
+// has no known length and plays on until the host moves on.
+func (s *ListeningRoomService) advance(ctx context.Context, room *models.ListeningRoom, now time.Time) (bool, error) {
+	var readErr error
+	changed := room.Advance(now, func(trackID string) int {
+		track, err := s.tracks.GetTrack(ctx, room.HostID, trackID)
+		if err != nil {
+			if !errors.Is(err, repository.ErrNotFound) && readErr == nil {
+				readErr = err
+			}
+			return 0
+		}
+		return track.Duration
+	})
+	if readErr != nil {
+		return false, readErr
+	}
+	return changed, nil
+}
+
+// view returns the room as userID sees it at now
+func (s *ListeningRoomService) view(ctx context.Context, room models.ListeningRoom, userID string, now time.Time) (*models.ListeningRoomState, error) {
+	members, err := s.repo.ListRoomMembers(ctx, room.ID)
+	if err != nil {
+		return nil, err
+	}
+	state := models.NewListeningRoomState(room, userID, len(members), now)
+	return &state, nil
+}
+
+// roomConflict reports a change made against an outdated room, with the
+// current state in its details so the host can retry without another read
+func (s *ListeningRoomService) roomConflict(ctx context.Context, hostID, roomID string) error {
+	current, err := s.State(ctx, hostID, roomID)
+	if err != nil {
+		return err
+	}
+	conflict := models.NewConflictError("the room was changed in the meantime")
+	conflict.Details = current
+	return conflict
+}
+
+// activeRoom loads an open room; closed and expired rooms are not found
+func (s *ListeningRoomService) activeRoom(ctx context.Context, roomID string) (*models.ListeningRoom, error) {
+	room, err := s.repo.GetListeningRoom(ctx, roomID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && !room.Active(s.now())) {
+		return nil, models.NewNotFoundError("Room", roomID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// memberRoom loads an open room the user hosts or joined; other rooms are
+// not found
+func (s *ListeningRoomService) memberRoom(ctx context.Context, userID, roomID string) (*models.ListeningRoom, error) {
+	room, err := s.activeRoom(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room.HostID == userID {
+		return room, nil
+	}
+	if _, err := s.repo.GetRoomMember(ctx, roomID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("Room", roomID)
+		}
+		return nil, err
+	}
+	return room, nil
+}
+
+// hostRoom loads an open room for a change only its host may make. Members
+// are told so; everyone else does not find the room.
+func (s *ListeningRoomService) hostRoom(ctx context.Context, userID, roomID string) (*models.ListeningRoom, error) {
+	room, err := s.memberRoom(ctx, userID, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room.HostID != userID {
+		return nil, models.NewForbiddenError("only the host controls the room")
+	}
+	return room, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListeningRoomService(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	s3Repo := repository.NewMemoryS3Repository("http://localhost/media")
+	for _, track := range []models.Track{
+		{ID: "t1", UserID: "host", Title: "One", Duration: 60, S3Key: "media/host/t1.mp3"},
+		{ID: "t2", UserID: "host", Title: "Two", Duration: 60, S3Key: "media/host/t2.mp3"},
+	} {
+		require.NoError(t, repo.CreateTrack(ctx, track))
+	}
+	require.NoError(t, repo.PutPlayerState(ctx, models.PlayerState{UserID: "host", TrackIDs: []string{"t1", "t2"}, Version: 1}, 0))
+
+	keys, err := reqsign.ParseKeyring("k1:room-secret-0123456789abcdefghijklm")
+	require.NoError(t, err)
+	svc := NewListeningRoomService(repo, repo, s3Repo, nil, keys)
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	invite, err := svc.Open(ctx, "host", models.CreateListeningRoomRequest{Name: "Friday"})
+	require.NoError(t, err)
+	roomID := invite.Room.ID
+	assert.True(t, invite.Room.IsHost)
+	assert.False(t, invite.Room.Playing)
+	assert.Equal(t, "t1", invite.Room.CurrentTrackID)
+
+	t.Run("listeners join with the invite", func(t *testing.T) {
+		_, err := svc.State(ctx, "guest", roomID)
+		assert.Equal(t, models.NewNotFoundError("Room", roomID), err, "outsiders do not find the room")
+
+		state, err := svc.Join(ctx, "guest", invite.Token)
+		require.NoError(t, err)
+		assert.False(t, state.IsHost)
+		assert.Equal(t, 1, state.MemberCount)
+
+		_, err = svc.Join(ctx, "guest", "not-a-token")
+		assert.Equal(t, models.NewNotFoundError("Room", "not-a-token"), err)
+	})
+
+	t.Run("only the host controls playback", func(t *testing.T) {
+		_, err := svc.Control(ctx, "guest", roomID, models.RoomPlaybackRequest{Action: models.RoomPlay})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "FORBIDDEN", apiErr.Code)
+
+		state, err := svc.Control(ctx, "host", roomID, models.RoomPlaybackRequest{Action: models.RoomPlay})
+		require.NoError(t, err)
+		assert.True(t, state.Playing)
+
+		stale := state.Version - 1
+		_, err = svc.Control(ctx, "host", roomID, models.RoomPlaybackRequest{Action: models.RoomPause, Version: &stale})
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "CONFLICT", apiErr.Code)
+		assert.Equal(t, state.Version, apiErr.Details.(*models.ListeningRoomState).Version)
+	})
+
+	t.Run("members follow the room's clock", func(t *testing.T) {
+		now = now.Add(70 * time.Second)
+		state, err := svc.State(ctx, "guest", roomID)
+		require.NoError(t, err)
+		assert.Equal(t, "t2", state.CurrentTrackID, "the room moved on when the first track ended")
+		assert.Equal(t, int64(10000), state.PositionMs)
+
+		stream, err := svc.Stream(ctx, "guest", roomID)
+		require.NoError(t, err)
+		assert.Equal(t, "t2", stream.TrackID)
+		assert.NotEmpty(t, stream.StreamURL)
+	})
+
+	t.Run("leaving and closing", func(t *testing.T) {
+		require.NoError(t, svc.Leave(ctx, "guest", roomID))
+		_, err := svc.Stream(ctx, "guest", roomID)
+		assert.Equal(t, models.NewNotFoundError("Room", roomID), err)
+
+		require.NoError(t, svc.Close(ctx, "host", roomID))
+		_, err = svc.Join(ctx, "guest", invite.Token)
+		assert.Equal(t, models.NewNotFoundError("Room", roomID), err)
+	})
+}
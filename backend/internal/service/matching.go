@@ -94,6 +94,7 @@ func (s *MatchingService) FindCompatibleTracks(ctx context.Context, userID, trac
 		// Calculate key compatibility
 		if keyEnabled && sourceTrack.MusicalKey != "" && track.MusicalKey != "" {
 			keyScore, keyRelation = calculateKeyCompatibility(sourceTrack.MusicalKey, track.MusicalKey)
+			keyScore = discountKeyScore(keyScore, 0.5, sourceTrack, track)
 		}
 
 		// Calculate overall score (weighted average)
@@ -202,6 +203,7 @@ func (s *MatchingService) FindKeyCompatible(ctx context.Context, userID, targetK
 		}
 
 		score, relation := calculateKeyCompatibility(targetKey, track.MusicalKey)
+		score = discountKeyScore(score, 0.5, track)
 		if score >= 0.5 {
 			results = append(results, MatchResult{
 				Track:            track,
@@ -355,6 +357,17 @@ func calculateKeyCompatibility(sourceKey, targetKey string) (float64, string) {
 	return 0.3, "incompatible"
 }
 
+// discountKeyScore pulls a key compatibility score toward the neutral score
+// used for unknown keys, in proportion to how unsure the analyzer was of the
+// least reliable key involved
+func discountKeyScore(score, neutral float64, tracks ...*models.Track) float64 {
+	reliability := 1.0
+	for _, track := range tracks {
+		reliability = math.Min(reliability, track.KeyReliability())
+	}
+	return neutral + (score-neutral)*reliability
+}
+
 // normalizeKey normalizes key notation
 func normalizeKey(key string) string {
 	key = strings.TrimSpace(key)
@@ -91,6 +91,18 @@ func TestCalculateKeyCompatibility(t *testing.T) {
 	}
 }
 
+func TestDiscountKeyScore(t *testing.T) {
+	confident := &models.Track{KeyConfidence: 0.9}
+	unsure := &models.Track{KeyConfidence: 0.2}
+	chosen := &models.Track{KeyConfidence: 0.2, KeyOverridden: true}
+	legacy := &models.Track{}
+
+	assert.InDelta(t, 1.0, discountKeyScore(1.0, 0.5, legacy, chosen), 1e-9, "trusted keys are not discounted")
+	assert.InDelta(t, 0.95, discountKeyScore(1.0, 0.5, confident, legacy), 1e-9)
+	assert.InDelta(t, 0.6, discountKeyScore(1.0, 0.5, confident, unsure), 1e-9, "the least reliable key decides")
+	assert.InDelta(t, 0.46, discountKeyScore(0.3, 0.5, unsure), 1e-9, "clashes are softened too")
+}
+
 func TestCamelotDistance(t *testing.T) {
 	tests := []struct {
 		a, b     int
@@ -93,6 +93,12 @@ func (s *migrationService) MigrateArtists(ctx context.Context, userID string) (*
 				continue
 			}
 
+			// Skip locked tracks - bulk operations never modify them
+			if track.Locked {
+				result.TracksSkipped++
+				continue
+			}
+
 			// Skip tracks with no artist name
 			if track.Artist == "" {
 				result.TracksSkipped++
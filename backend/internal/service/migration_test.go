@@ -8,163 +8,12 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockMigrationRepository is a mock implementation of MigrationRepository
-type MockMigrationRepository struct {
-	mock.Mock
-}
-
-func (m *MockMigrationRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
-	args := m.Called(ctx, artist)
-	return args.Error(0)
-}
-
-func (m *MockMigrationRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
-	args := m.Called(ctx, userID, artistID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Artist), args.Error(1)
-}
-
-func (m *MockMigrationRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
-	args := m.Called(ctx, userID, name)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]*models.Artist), args.Error(1)
-}
-
-func (m *MockMigrationRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*repository.PaginatedResult[models.Artist], error) {
-	args := m.Called(ctx, userID, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Artist]), args.Error(1)
-}
-
-func (m *MockMigrationRepository) UpdateTrack(ctx context.Context, track models.Track) error {
-	args := m.Called(ctx, track)
-	return args.Error(0)
-}
-
-func (m *MockMigrationRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error) {
-	args := m.Called(ctx, userID, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Track]), args.Error(1)
-}
-
-// User role management methods
-func (m *MockMigrationRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	args := m.Called(ctx, userID, role)
-	return args.Error(0)
-}
-
-func (m *MockMigrationRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
-	args := m.Called(ctx, role, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.User]), args.Error(1)
-}
-
-// Playlist visibility methods
-func (m *MockMigrationRepository) UpdatePlaylistVisibility(ctx context.Context, userID, playlistID string, visibility models.PlaylistVisibility) error {
-	args := m.Called(ctx, userID, playlistID, visibility)
-	return args.Error(0)
-}
-
-func (m *MockMigrationRepository) ListPublicPlaylists(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Playlist], error) {
-	args := m.Called(ctx, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Playlist]), args.Error(1)
-}
-
-// ArtistProfile methods
-func (m *MockMigrationRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	args := m.Called(ctx, profile)
-	return args.Error(0)
-}
-
-func (m *MockMigrationRepository) GetArtistProfile(ctx context.Context, artistUserID string) (*models.ArtistProfile, error) {
-	args := m.Called(ctx, artistUserID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.ArtistProfile), args.Error(1)
-}
-
-func (m *MockMigrationRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	args := m.Called(ctx, profile)
-	return args.Error(0)
-}
-
-func (m *MockMigrationRepository) DeleteArtistProfile(ctx context.Context, artistUserID string) error {
-	args := m.Called(ctx, artistUserID)
-	return args.Error(0)
-}
-
-func (m *MockMigrationRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.ArtistProfile], error) {
-	args := m.Called(ctx, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.ArtistProfile]), args.Error(1)
-}
-
-func (m *MockMigrationRepository) IncrementArtistFollowerCount(ctx context.Context, artistUserID string, delta int) error {
-	args := m.Called(ctx, artistUserID, delta)
-	return args.Error(0)
-}
-
-// Follow methods
-func (m *MockMigrationRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
-	args := m.Called(ctx, follow)
-	return args.Error(0)
-}
-
-func (m *MockMigrationRepository) DeleteFollow(ctx context.Context, followerUserID, followedUserID string) error {
-	args := m.Called(ctx, followerUserID, followedUserID)
-	return args.Error(0)
-}
-
-func (m *MockMigrationRepository) GetFollow(ctx context.Context, followerUserID, followedUserID string) (*models.Follow, error) {
-	args := m.Called(ctx, followerUserID, followedUserID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Follow), args.Error(1)
-}
-
-func (m *MockMigrationRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	args := m.Called(ctx, userID, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Follow]), args.Error(1)
-}
-
-func (m *MockMigrationRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	args := m.Called(ctx, userID, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Follow]), args.Error(1)
-}
-
-func (m *MockMigrationRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
-	args := m.Called(ctx, userID, delta)
-	return args.Error(0)
-}
-
 // Helper function to create test tracks
 func createMigrationTestTrack(userID, trackID, artist, artistID string) models.Track {
 	now := time.Now().UTC()
@@ -197,7 +46,7 @@ func createMigrationTestArtist(userID, artistID, name string) *models.Artist {
 
 func TestNewMigrationService(t *testing.T) {
 	t.Run("creates service with repository", func(t *testing.T) {
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 		svc := NewMigrationService(mockRepo)
 		require.NotNil(t, svc)
 	})
@@ -207,7 +56,7 @@ func TestMigrateArtists_Success(t *testing.T) {
 	t.Run("migrates tracks and creates artists", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracks := []models.Track{
 			createMigrationTestTrack(userID, "track-1", "Artist A", ""),
@@ -251,7 +100,7 @@ func TestMigrateArtists_SkipsAlreadyMigrated(t *testing.T) {
 	t.Run("skips tracks with existing artistId", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracks := []models.Track{
 			createMigrationTestTrack(userID, "track-1", "Artist A", "existing-artist-id"),
@@ -279,7 +128,7 @@ func TestMigrateArtists_SkipsNoArtist(t *testing.T) {
 	t.Run("skips tracks without artist name", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracks := []models.Track{
 			createMigrationTestTrack(userID, "track-1", "", ""),
@@ -307,7 +156,7 @@ func TestMigrateArtists_ReusesExistingArtist(t *testing.T) {
 	t.Run("reuses existing artist from database", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracks := []models.Track{
 			createMigrationTestTrack(userID, "track-1", "Existing Artist", ""),
@@ -342,7 +191,7 @@ func TestMigrateArtists_UsesCache(t *testing.T) {
 	t.Run("uses cache for same artist across multiple tracks", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracks := []models.Track{
 			createMigrationTestTrack(userID, "track-1", "Same Artist", ""),
@@ -380,7 +229,7 @@ func TestMigrateArtists_Pagination(t *testing.T) {
 	t.Run("handles multiple pages of tracks", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracksPage1 := []models.Track{
 			createMigrationTestTrack(userID, "track-1", "Artist A", ""),
@@ -427,7 +276,7 @@ func TestMigrateArtists_ListTracksError(t *testing.T) {
 	t.Run("returns error when listing tracks fails", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("ListTracks", ctx, userID, mock.AnythingOfType("models.TrackFilter")).Return((*repository.PaginatedResult[models.Track])(nil), errors.New("database error"))
 
@@ -444,7 +293,7 @@ func TestGetMigrationStatus_NotStarted(t *testing.T) {
 	t.Run("returns not_started when no tracks are migrated", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracks := []models.Track{
 			createMigrationTestTrack(userID, "track-1", "Artist A", ""),
@@ -479,7 +328,7 @@ func TestGetMigrationStatus_Completed(t *testing.T) {
 	t.Run("returns completed when all tracks are migrated", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracks := []models.Track{
 			createMigrationTestTrack(userID, "track-1", "Artist A", "artist-id-1"),
@@ -514,7 +363,7 @@ func TestGetMigrationStatus_Partial(t *testing.T) {
 	t.Run("returns partial when some tracks are migrated", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracks := []models.Track{
 			createMigrationTestTrack(userID, "track-1", "Artist A", "artist-id-1"),
@@ -550,7 +399,7 @@ func TestGetMigrationStatus_NoTracks(t *testing.T) {
 	t.Run("returns completed when there are no tracks", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		tracksResult := &repository.PaginatedResult[models.Track]{
 			Items:   []models.Track{},
@@ -577,7 +426,7 @@ func TestGetMigrationStatus_ListTracksError(t *testing.T) {
 	t.Run("returns error when listing tracks fails", func(t *testing.T) {
 		ctx := context.Background()
 		userID := "user-123"
-		mockRepo := new(MockMigrationRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("ListTracks", ctx, userID, mock.AnythingOfType("models.TrackFilter")).Return((*repository.PaginatedResult[models.Track])(nil), errors.New("database error"))
 
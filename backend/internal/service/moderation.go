@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+)
+
+// ModerationRepository defines the repository interface for moderation reviews
+type ModerationRepository interface {
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	UpdateTrack(ctx context.Context, track models.Track) error
+	UpdateTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error
+	PutModerationReview(ctx context.Context, review models.ModerationReview) error
+	GetModerationReview(ctx context.Context, trackID string) (*models.ModerationReview, error)
+	ListModerationReviews(ctx context.Context, filter models.ModerationFilter) (*repository.PaginatedResult[models.ModerationReview], error)
+}
+
+// ModerationDispatcher starts the automatic check of a track without waiting for it
+type ModerationDispatcher interface {
+	Dispatch(ctx context.Context, req models.ModerationRequest) error
+}
+
+// TrackModerator holds visibility changes until they pass moderation
+type TrackModerator interface {
+	Submit(ctx context.Context, track models.Track, visibility models.TrackVisibility) (*models.ModerationReview, error)
+}
+
+// ModerationAware is implemented by services whose changes can be held for
+// moderation; Services.Moderate installs the moderator.
+type ModerationAware interface {
+	SetModerator(moderator TrackModerator)
+}
+
+// ModerationService runs the moderation workflow: a track made public is
+// held as pending, checked asynchronously, and published when the check
+// passes or an admin approves it.
+type ModerationService struct {
+	repo       ModerationRepository
+	dispatcher ModerationDispatcher
+}
+
+// NewModerationService creates a new moderation service
+func NewModerationService(repo ModerationRepository, dispatcher ModerationDispatcher) *ModerationService {
+	return &ModerationService{repo: repo, dispatcher: dispatcher}
+}
+
+// RequiresModeration reports whether changing track to visibility must be checked first
+func RequiresModeration(track models.Track, visibility models.TrackVisibility) bool {
+	return visibility == models.VisibilityPublic && track.GetVisibility() != models.VisibilityPublic
+}
+
+// Submit holds the change of track to visibility and starts the automatic
+// check. When the check cannot be started the review is flagged, so an admin
+// decides instead of the change being lost or applied unchecked.
+func (s *ModerationService) Submit(ctx context.Context, track models.Track, visibility models.TrackVisibility) (*models.ModerationReview, error) {
+	now := time.Now()
+	review := models.ModerationReview{
+		TrackID:             track.ID,
+		UserID:              track.UserID,
+		TrackTitle:          track.Title,
+		TrackArtist:         track.Artist,
+		RequestedVisibility: visibility,
+		Status:              models.ModerationPending,
+		Timestamps:          models.Timestamps{CreatedAt: now, UpdatedAt: now},
+	}
+	if err := s.repo.PutModerationReview(ctx, review); err != nil {
+		return nil, err
+	}
+
+	track.PendingVisibility = visibility
+	track.ModerationStatus = models.ModerationPending
+	if err := s.repo.UpdateTrack(ctx, track); err != nil {
+		return nil, err
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+	err := s.dispatcher.Dispatch(ctx, models.ModerationRequest{UserID: track.UserID, TrackID: track.ID, TenantID: tenantID})
+	if err != nil {
+		verdict := models.ModerationVerdict{Flagged: true, Reason: fmt.Sprintf("automatic check could not start: %v", err)}
+		return s.RecordVerdict(ctx, track.UserID, track.ID, verdict)
+	}
+
+	return &review, nil
+}
+
+// RecordVerdict stores the result of the automatic check: a clean track is
+// published, a flagged one waits in the admin review queue. Verdicts for
+// reviews that are no longer pending are ignored, so retried checks are safe.
+func (s *ModerationService) RecordVerdict(ctx context.Context, userID, trackID string, verdict models.ModerationVerdict) (*models.ModerationReview, error) {
+	review, err := s.getReview(ctx, trackID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != userID || review.Status != models.ModerationPending {
+		return review, nil
+	}
+
+	now := time.Now()
+	review.Categories = verdict.Categories
+	review.Reason = verdict.Reason
+	review.Transcript = verdict.Transcript
+	review.CheckedAt = &now
+
+	if !verdict.Flagged {
+		return s.decide(ctx, review, true, "", "")
+	}
+
+	track, err := s.pendingTrack(ctx, review)
+	if err != nil || track == nil {
+		return review, err
+	}
+	review.Status = models.ModerationFlagged
+	if err := s.repo.PutModerationReview(ctx, *review); err != nil {
+		return nil, err
+	}
+	track.ModerationStatus = models.ModerationFlagged
+	if err := s.repo.UpdateTrack(ctx, *track); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// ListReviews lists the review queue, flagged reviews by default
+func (s *ModerationService) ListReviews(ctx context.Context, filter models.ModerationFilter) (*repository.PaginatedResult[models.ModerationReview], error) {
+	if filter.Status == "" {
+		filter.Status = models.ModerationFlagged
+	}
+	return s.repo.ListModerationReviews(ctx, filter)
+}
+
+// Approve publishes a held change on behalf of an admin
+func (s *ModerationService) Approve(ctx context.Context, adminID, trackID, note string) (*models.ModerationReview, error) {
+	return s.review(ctx, adminID, trackID, note, true)
+}
+
+// Reject refuses a held change on behalf of an admin; the track keeps its
+// current visibility
+func (s *ModerationService) Reject(ctx context.Context, adminID, trackID, note string) (*models.ModerationReview, error) {
+	return s.review(ctx, adminID, trackID, note, false)
+}
+
+func (s *ModerationService) review(ctx context.Context, adminID, trackID, note string, approve bool) (*models.ModerationReview, error) {
+	review, err := s.getReview(ctx, trackID)
+	if err != nil {
+		return nil, err
+	}
+	if !review.Status.IsOpen() {
+		return nil, models.NewConflictError(fmt.Sprintf("moderation review is already %s", review.Status))
+	}
+	return s.decide(ctx, review, approve, adminID, note)
+}
+
+// decide closes an open review and applies or discards the held change
+func (s *ModerationService) decide(ctx context.Context, review *models.ModerationReview, approve bool, adminID, note string) (*models.ModerationReview, error) {
+	track, err := s.pendingTrack(ctx, review)
+	if err != nil || track == nil {
+		return review, err
+	}
+
+	now := time.Now()
+	review.Status = models.ModerationRejected
+	if approve {
+		review.Status = models.ModerationApproved
+	}
+	review.ReviewedBy = adminID
+	review.ReviewNote = note
+	if adminID != "" {
+		review.ReviewedAt = &now
+	}
+
+	track.PendingVisibility = ""
+	track.ModerationStatus = review.Status
+	if err := s.repo.UpdateTrack(ctx, *track); err != nil {
+		return nil, err
+	}
+	if approve {
+		// Also maintains the public discovery index
+		if err := s.repo.UpdateTrackVisibility(ctx, track.UserID, track.ID, review.RequestedVisibility); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.PutModerationReview(ctx, *review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// pendingTrack returns the track still waiting on review, or nil after
+// marking the review withdrawn when the track was deleted or the owner has
+// since chosen another visibility
+func (s *ModerationService) pendingTrack(ctx context.Context, review *models.ModerationReview) (*models.Track, error) {
+	track, err := s.repo.GetTrack(ctx, review.UserID, review.TrackID)
+	if err != nil && err != repository.ErrNotFound {
+		return nil, err
+	}
+	if err == nil && track.PendingVisibility == review.RequestedVisibility {
+		return track, nil
+	}
+
+	review.Status = models.ModerationWithdrawn
+	if err := s.repo.PutModerationReview(ctx, *review); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *ModerationService) getReview(ctx context.Context, trackID string) (*models.ModerationReview, error) {
+	review, err := s.repo.GetModerationReview(ctx, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("ModerationReview", trackID)
+		}
+		return nil, err
+	}
+	return review, nil
+}
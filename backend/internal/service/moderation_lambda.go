@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/search"
+)
+
+// LambdaModerationDispatcher starts moderation checks by invoking the
+// moderation Lambda (cmd/processor/moderation) asynchronously
+type LambdaModerationDispatcher struct {
+	client       search.LambdaInvoker
+	functionName string
+}
+
+// NewLambdaModerationDispatcher creates a dispatcher for the named function
+func NewLambdaModerationDispatcher(client search.LambdaInvoker, functionName string) *LambdaModerationDispatcher {
+	return &LambdaModerationDispatcher{client: client, functionName: functionName}
+}
+
+// Dispatch queues the check; Lambda retries failed asynchronous invocations itself
+func (d *LambdaModerationDispatcher) Dispatch(ctx context.Context, req models.ModerationRequest) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal moderation request: %w", err)
+	}
+
+	_, err = d.client.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(d.functionName),
+		InvocationType: types.InvocationTypeEvent,
+		Payload:        payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to invoke moderation function: %w", err)
+	}
+	return nil
+}
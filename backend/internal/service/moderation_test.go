@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubModerationDispatcher struct {
+	requests []models.ModerationRequest
+	err      error
+}
+
+func (d *stubModerationDispatcher) Dispatch(ctx context.Context, req models.ModerationRequest) error {
+	d.requests = append(d.requests, req)
+	return d.err
+}
+
+// newModerationService moderates a private track of user-1
+func newModerationService(t *testing.T) (*ModerationService, *repository.MemoryRepository, *stubModerationDispatcher, models.Track) {
+	t.Helper()
+	track := builders.NewTrackBuilder("user-1", "track-1").Build()
+	repo := newSeededRepo(t, track)
+	dispatcher := &stubModerationDispatcher{}
+	return NewModerationService(repo, dispatcher), repo, dispatcher, track
+}
+
+func TestRequiresModeration(t *testing.T) {
+	private := builders.NewTrackBuilder("user-1", "track-1").Build()
+	public := builders.NewTrackBuilder("user-1", "track-2").Public().Build()
+
+	assert.True(t, RequiresModeration(private, models.VisibilityPublic))
+	assert.False(t, RequiresModeration(public, models.VisibilityPublic))
+	assert.False(t, RequiresModeration(public, models.VisibilityPrivate))
+}
+
+func TestModerationService_Submit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("holds the change and starts the check", func(t *testing.T) {
+		svc, repo, dispatcher, track := newModerationService(t)
+
+		review, err := svc.Submit(ctx, track, models.VisibilityPublic)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ModerationPending, review.Status)
+		require.Len(t, dispatcher.requests, 1)
+		assert.Equal(t, models.ModerationRequest{UserID: "user-1", TrackID: "track-1"}, dispatcher.requests[0])
+
+		stored, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityPrivate, stored.Visibility)
+		assert.Equal(t, models.VisibilityPublic, stored.PendingVisibility)
+		assert.Equal(t, models.ModerationPending, stored.ModerationStatus)
+	})
+
+	t.Run("flags the review when the check cannot start", func(t *testing.T) {
+		svc, repo, dispatcher, track := newModerationService(t)
+		dispatcher.err = errors.New("throttled")
+
+		review, err := svc.Submit(ctx, track, models.VisibilityPublic)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ModerationFlagged, review.Status)
+		assert.Contains(t, review.Reason, "throttled")
+
+		stored, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityPrivate, stored.Visibility)
+		assert.Equal(t, models.ModerationFlagged, stored.ModerationStatus)
+	})
+}
+
+func TestModerationService_RecordVerdict(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("publishes a clean track", func(t *testing.T) {
+		svc, repo, _, track := newModerationService(t)
+		_, err := svc.Submit(ctx, track, models.VisibilityPublic)
+		require.NoError(t, err)
+
+		review, err := svc.RecordVerdict(ctx, "user-1", "track-1", models.ModerationVerdict{})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ModerationApproved, review.Status)
+		assert.Empty(t, review.ReviewedBy)
+		assert.NotNil(t, review.CheckedAt)
+
+		stored, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityPublic, stored.Visibility)
+		assert.Empty(t, stored.PendingVisibility)
+		assert.Equal(t, models.ModerationApproved, stored.ModerationStatus)
+	})
+
+	t.Run("queues a flagged track for an admin", func(t *testing.T) {
+		svc, repo, _, track := newModerationService(t)
+		_, err := svc.Submit(ctx, track, models.VisibilityPublic)
+		require.NoError(t, err)
+
+		verdict := models.ModerationVerdict{Flagged: true, Categories: []string{"spam"}, Reason: "advert"}
+		review, err := svc.RecordVerdict(ctx, "user-1", "track-1", verdict)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ModerationFlagged, review.Status)
+		assert.Equal(t, []string{"spam"}, review.Categories)
+
+		queue, err := svc.ListReviews(ctx, models.ModerationFilter{})
+		require.NoError(t, err)
+		require.Len(t, queue.Items, 1)
+		assert.Equal(t, "track-1", queue.Items[0].TrackID)
+
+		stored, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityPrivate, stored.Visibility)
+	})
+
+	t.Run("ignores verdicts for closed reviews", func(t *testing.T) {
+		svc, _, _, track := newModerationService(t)
+		_, err := svc.Submit(ctx, track, models.VisibilityPublic)
+		require.NoError(t, err)
+		_, err = svc.RecordVerdict(ctx, "user-1", "track-1", models.ModerationVerdict{})
+		require.NoError(t, err)
+
+		review, err := svc.RecordVerdict(ctx, "user-1", "track-1", models.ModerationVerdict{Flagged: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ModerationApproved, review.Status)
+	})
+
+	t.Run("withdraws the review when the owner changed visibility", func(t *testing.T) {
+		svc, repo, _, track := newModerationService(t)
+		_, err := svc.Submit(ctx, track, models.VisibilityPublic)
+		require.NoError(t, err)
+		stored, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		stored.PendingVisibility = ""
+		require.NoError(t, repo.UpdateTrack(ctx, *stored))
+
+		review, err := svc.RecordVerdict(ctx, "user-1", "track-1", models.ModerationVerdict{})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ModerationWithdrawn, review.Status)
+		stored, err = repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityPrivate, stored.Visibility)
+	})
+
+	t.Run("returns not found without a review", func(t *testing.T) {
+		svc, _, _, _ := newModerationService(t)
+
+		_, err := svc.RecordVerdict(ctx, "user-1", "track-1", models.ModerationVerdict{})
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+	})
+}
+
+func TestModerationService_Decisions(t *testing.T) {
+	ctx := context.Background()
+	flag := func(t *testing.T) (*ModerationService, *repository.MemoryRepository) {
+		svc, repo, _, track := newModerationService(t)
+		_, err := svc.Submit(ctx, track, models.VisibilityPublic)
+		require.NoError(t, err)
+		_, err = svc.RecordVerdict(ctx, "user-1", "track-1", models.ModerationVerdict{Flagged: true})
+		require.NoError(t, err)
+		return svc, repo
+	}
+
+	t.Run("approve publishes the track", func(t *testing.T) {
+		svc, repo := flag(t)
+
+		review, err := svc.Approve(ctx, "admin-1", "track-1", "fine")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ModerationApproved, review.Status)
+		assert.Equal(t, "admin-1", review.ReviewedBy)
+		assert.Equal(t, "fine", review.ReviewNote)
+		assert.NotNil(t, review.ReviewedAt)
+
+		stored, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityPublic, stored.Visibility)
+	})
+
+	t.Run("reject keeps the track private", func(t *testing.T) {
+		svc, repo := flag(t)
+
+		review, err := svc.Reject(ctx, "admin-1", "track-1", "spam")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ModerationRejected, review.Status)
+
+		stored, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.VisibilityPrivate, stored.Visibility)
+		assert.Empty(t, stored.PendingVisibility)
+		assert.Equal(t, models.ModerationRejected, stored.ModerationStatus)
+	})
+
+	t.Run("a decided review cannot be decided again", func(t *testing.T) {
+		svc, _ := flag(t)
+		_, err := svc.Reject(ctx, "admin-1", "track-1", "")
+		require.NoError(t, err)
+
+		_, err = svc.Approve(ctx, "admin-1", "track-1", "")
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "CONFLICT", apiErr.Code)
+	})
+}
@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// TrackNeighborRepository is implemented by repositories that cache each
+// track's precomputed similar and mixable tracks
+type TrackNeighborRepository interface {
+	GetTrackNeighbors(ctx context.Context, userID, trackID string) (*models.TrackNeighbors, error)
+	PutTrackNeighbors(ctx context.Context, neighbors models.TrackNeighbors) error
+}
+
+// HarmonicIndexRepository is implemented by repositories that index tracks by
+// Camelot key and BPM bucket (GSI4), so mixing candidates can be queried
+// directly instead of filtered from the whole library. An empty camelotKey
+// selects tracks with no detected key; bucket -1 selects unknown tempos.
+type HarmonicIndexRepository interface {
+	ListTracksByKeyAndBPMBucket(ctx context.Context, userID, camelotKey string, minBucket, maxBucket int) ([]models.Track, error)
+}
+
+// similarBPMRange is the largest BPM difference that contributes to feature similarity
+const similarBPMRange = 10
+
+// bucketKey identifies one Camelot key and BPM band; an empty key or band -1
+// holds tracks whose key or BPM is unknown
+type bucketKey struct {
+	camelot string
+	band    int
+}
+
+// trackBuckets indexes a library by Camelot key and BPM band, and by artist,
+// genre and tag, so a similarity query scores only the tracks that can match
+// instead of the whole library. Every track outside a query's candidates would
+// score zero similarity or fail the mixing filters.
+type trackBuckets struct {
+	tracks   []models.Track
+	byBucket map[bucketKey][]int
+	keys     map[string]bool
+	bands    map[int]bool
+	byArtist map[string][]int
+	byGenre  map[string][]int
+	byTag    map[string][]int
+}
+
+// newTrackBuckets indexes tracks
+func newTrackBuckets(tracks []models.Track) *trackBuckets {
+	b := &trackBuckets{
+		tracks:   tracks,
+		byBucket: make(map[bucketKey][]int),
+		keys:     make(map[string]bool),
+		bands:    make(map[int]bool),
+		byArtist: make(map[string][]int),
+		byGenre:  make(map[string][]int),
+		byTag:    make(map[string][]int),
+	}
+	for i, track := range tracks {
+		key := bucketKey{camelot: track.KeyCamelot, band: models.BPMBucket(track.BPM)}
+		b.byBucket[key] = append(b.byBucket[key], i)
+		b.keys[key.camelot] = true
+		b.bands[key.band] = true
+		if track.Artist != "" {
+			b.byArtist[track.Artist] = append(b.byArtist[track.Artist], i)
+		}
+		if track.Genre != "" {
+			b.byGenre[track.Genre] = append(b.byGenre[track.Genre], i)
+		}
+		for _, tag := range track.Tags {
+			b.byTag[tag] = append(b.byTag[tag], i)
+		}
+	}
+	return b
+}
+
+// similarCandidates returns the tracks that can have non-zero similarity to
+// source in any mode: those sharing its artist, genre or a tag, within
+// similarBPMRange of its BPM, or in a harmonically compatible key
+func (b *trackBuckets) similarCandidates(source *models.Track) []*models.Track {
+	seen := make([]bool, len(b.tracks))
+	var out []int
+	add := func(indexes []int) {
+		for _, i := range indexes {
+			if !seen[i] {
+				seen[i] = true
+				out = append(out, i)
+			}
+		}
+	}
+
+	if source.Artist != "" {
+		add(b.byArtist[source.Artist])
+	}
+	if source.Genre != "" {
+		add(b.byGenre[source.Genre])
+	}
+	for _, tag := range source.Tags {
+		add(b.byTag[tag])
+	}
+	if source.BPM > 0 {
+		bands := bandsFor([2]int{source.BPM - similarBPMRange, source.BPM + similarBPMRange})
+		b.collect(b.allKeys(), bands, add)
+	}
+	if keys := GetCompatibleKeys(source.KeyCamelot); keys != nil {
+		b.collect(keys, b.allBands(), add)
+	}
+
+	return b.resolve(out)
+}
+
+// mixCandidates returns the tracks in the key and BPM buckets FindMixableTracks
+// can accept for source under opts, including tracks with unknown key or BPM
+func (b *trackBuckets) mixCandidates(source *models.Track, opts MixingOptions) []*models.Track {
+	keys := mixKeys(source, opts)
+	if keys == nil {
+		keys = b.allKeys()
+	}
+	bands := mixBands(source, opts)
+	if bands == nil {
+		bands = b.allBands()
+	}
+
+	var out []int
+	b.collect(keys, bands, func(indexes []int) { out = append(out, indexes...) })
+	return b.resolve(out)
+}
+
+// mixKeys returns the Camelot keys a mixable track may have, or nil for any key
+func mixKeys(source *models.Track, opts MixingOptions) []string {
+	if opts.KeyMode == "any" || source.KeyCamelot == "" {
+		return nil
+	}
+	if opts.KeyMode == "exact" {
+		return []string{"", source.KeyCamelot}
+	}
+	return append([]string{""}, GetCompatibleKeys(source.KeyCamelot)...)
+}
+
+// mixBands returns the BPM bands a mixable track may be in, or nil for any
+// band. The ranges cover direct, half-time and double-time matches with a
+// margin for integer halving, so they are a superset of GetBPMCompatibility.
+func mixBands(source *models.Track, opts MixingOptions) []int {
+	if source.BPM <= 0 {
+		return nil
+	}
+	bpm, tol := source.BPM, opts.BPMTolerance
+	bands := bandsFor(
+		[2]int{bpm - tol, bpm + tol},
+		[2]int{bpm/2 - tol - 1, bpm/2 + tol + 1},
+		[2]int{2*(bpm-tol) - 1, 2*(bpm+tol) + 1},
+	)
+	return append(bands, models.BPMBucket(0))
+}
+
+// bucketRanges merges bands into inclusive runs of consecutive bands, one
+// index query each
+func bucketRanges(bands []int) [][2]int {
+	sorted := append([]int(nil), bands...)
+	sort.Ints(sorted)
+	var ranges [][2]int
+	for _, band := range sorted {
+		if n := len(ranges); n > 0 && band <= ranges[n-1][1]+1 {
+			if band > ranges[n-1][1] {
+				ranges[n-1][1] = band
+			}
+			continue
+		}
+		ranges = append(ranges, [2]int{band, band})
+	}
+	return ranges
+}
+
+// bandsFor returns the BPM bands overlapping the given inclusive BPM ranges
+func bandsFor(ranges ...[2]int) []int {
+	seen := make(map[int]bool)
+	var bands []int
+	for _, r := range ranges {
+		lo, hi := r[0], r[1]
+		if lo < 1 {
+			lo = 1
+		}
+		for band := models.BPMBucket(lo); hi >= lo && band <= models.BPMBucket(hi); band++ {
+			if !seen[band] {
+				seen[band] = true
+				bands = append(bands, band)
+			}
+		}
+	}
+	return bands
+}
+
+func (b *trackBuckets) collect(keys []string, bands []int, add func([]int)) {
+	for _, key := range keys {
+		for _, band := range bands {
+			add(b.byBucket[bucketKey{camelot: key, band: band}])
+		}
+	}
+}
+
+func (b *trackBuckets) allKeys() []string {
+	keys := make([]string, 0, len(b.keys))
+	for key := range b.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (b *trackBuckets) allBands() []int {
+	bands := make([]int, 0, len(b.bands))
+	for band := range b.bands {
+		bands = append(bands, band)
+	}
+	return bands
+}
+
+// resolve maps indexes to tracks in library order, so rankings do not depend
+// on bucket iteration order
+func (b *trackBuckets) resolve(indexes []int) []*models.Track {
+	sort.Ints(indexes)
+	out := make([]*models.Track, 0, len(indexes))
+	prev := -1
+	for _, i := range indexes {
+		if i != prev {
+			out = append(out, &b.tracks[i])
+			prev = i
+		}
+	}
+	return out
+}
+
+// queryMixCandidates reads the tracks in the key and BPM buckets compatible
+// with source straight from the harmonic index. It reports false when the
+// repository has no index, in which case candidates come from the library.
+func (s *SimilarityService) queryMixCandidates(ctx context.Context, userID string, source *models.Track, opts MixingOptions) ([]*models.Track, bool, error) {
+	index, ok := s.repo.(HarmonicIndexRepository)
+	if !ok {
+		return nil, false, nil
+	}
+
+	keys := mixKeys(source, opts)
+	if keys == nil {
+		keys = []string{""}
+		for key := range CamelotWheel {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+	ranges := [][2]int{{-1, models.MaxBPMBucket}}
+	if bands := mixBands(source, opts); bands != nil {
+		ranges = bucketRanges(bands)
+	}
+
+	var candidates []*models.Track
+	for _, key := range keys {
+		for _, r := range ranges {
+			tracks, err := index.ListTracksByKeyAndBPMBucket(ctx, userID, key, r[0], r[1])
+			if err != nil {
+				return nil, false, err
+			}
+			for i := range tracks {
+				candidates = append(candidates, &tracks[i])
+			}
+		}
+	}
+	return candidates, true, nil
+}
+
+// neighborCache returns the repository's neighbor cache, or nil if it has none
+func (s *SimilarityService) neighborCache() TrackNeighborRepository {
+	cache, _ := s.repo.(TrackNeighborRepository)
+	return cache
+}
+
+// cachedNeighbors returns the source track's neighbors if a fresh list is cached
+func (s *SimilarityService) cachedNeighbors(ctx context.Context, userID string, source *models.Track) *models.TrackNeighbors {
+	cache := s.neighborCache()
+	if cache == nil {
+		return nil
+	}
+	neighbors, err := cache.GetTrackNeighbors(ctx, userID, source.ID)
+	if err != nil || !neighbors.IsFresh(source, s.now()) {
+		return nil
+	}
+	return neighbors
+}
+
+// loadBuckets reads the library once, indexes it, and caches the source
+// track's neighbors so the next query for it reads only those tracks
+func (s *SimilarityService) loadBuckets(ctx context.Context, userID string, source *models.Track) (*trackBuckets, error) {
+	tracks, err := s.getAllUserTracks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	buckets := newTrackBuckets(tracks)
+
+	if cache := s.neighborCache(); cache != nil {
+		// Best effort: a failed write only costs the next query a library read
+		_ = cache.PutTrackNeighbors(ctx, s.computeNeighbors(userID, source, buckets))
+	}
+	return buckets, nil
+}
+
+// computeNeighbors ranks the source track's best similar tracks in combined
+// mode and best mixable tracks under the default mixing options
+func (s *SimilarityService) computeNeighbors(userID string, source *models.Track, buckets *trackBuckets) models.TrackNeighbors {
+	similarOpts := SimilarityOptions{Mode: "combined", MinSimilarity: 0, IncludeSameAlbum: true}
+	similar := s.rankSimilar(source, buckets.similarCandidates(source), similarOpts)
+	mixOpts := DefaultMixingOptions()
+	mixable := s.rankMixable(source, buckets.mixCandidates(source, mixOpts), mixOpts)
+
+	neighbors := models.TrackNeighbors{
+		UserID:          userID,
+		TrackID:         source.ID,
+		SimilarComplete: len(similar) <= models.MaxTrackNeighbors,
+		MixableComplete: len(mixable) <= models.MaxTrackNeighbors,
+		ComputedAt:      s.now(),
+	}
+	for i := 0; i < len(similar) && i < models.MaxTrackNeighbors; i++ {
+		neighbors.Similar = append(neighbors.Similar, models.Neighbor{TrackID: similar[i].Track.ID, Score: similar[i].Similarity})
+	}
+	for i := 0; i < len(mixable) && i < models.MaxTrackNeighbors; i++ {
+		neighbors.Mixable = append(neighbors.Mixable, models.Neighbor{TrackID: mixable[i].Track.ID, Score: mixable[i].MixScore})
+	}
+	return neighbors
+}
+
+// similarFromCache answers a combined-mode query from cached neighbors. It
+// reports false when the list cannot answer exactly: a listed track changed
+// since it was computed, or the list was truncated before the query's limit
+// and minimum similarity were reached.
+func (s *SimilarityService) similarFromCache(ctx context.Context, userID string, source *models.Track, neighbors *models.TrackNeighbors, opts SimilarityOptions) ([]SimilarTrack, bool, error) {
+	if opts.Mode != "" && opts.Mode != "combined" {
+		return nil, false, nil
+	}
+
+	var results []SimilarTrack
+	exhausted := neighbors.SimilarComplete
+	for _, neighbor := range neighbors.Similar {
+		if len(results) == opts.Limit {
+			break
+		}
+		if neighbor.Score < opts.MinSimilarity {
+			exhausted = true
+			break
+		}
+		track, ok, err := s.neighborTrack(ctx, userID, neighbor.TrackID, neighbors.ComputedAt)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		if track == nil {
+			continue
+		}
+		if result, ok := s.scoreSimilar(source, track, opts); ok {
+			results = append(results, result)
+		}
+	}
+	return results, exhausted || len(results) == opts.Limit, nil
+}
+
+// mixableFromCache answers a mixing query from cached neighbors when its key
+// mode and BPM tolerance are no looser than the defaults the list was built
+// with, under the same conditions as similarFromCache
+func (s *SimilarityService) mixableFromCache(ctx context.Context, userID string, source *models.Track, neighbors *models.TrackNeighbors, opts MixingOptions) ([]MixableTrack, bool, error) {
+	defaults := DefaultMixingOptions()
+	if opts.KeyMode == "any" || opts.BPMTolerance > defaults.BPMTolerance {
+		return nil, false, nil
+	}
+
+	var results []MixableTrack
+	for _, neighbor := range neighbors.Mixable {
+		if len(results) == opts.Limit {
+			break
+		}
+		track, ok, err := s.neighborTrack(ctx, userID, neighbor.TrackID, neighbors.ComputedAt)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		if track == nil {
+			continue
+		}
+		if result, ok := s.scoreMixable(source, track, opts); ok {
+			results = append(results, result)
+		}
+	}
+	return results, neighbors.MixableComplete || len(results) == opts.Limit, nil
+}
+
+// neighborTrack fetches a cached neighbor. It returns a nil track for one
+// deleted since, and false for one updated since, which invalidates the list.
+func (s *SimilarityService) neighborTrack(ctx context.Context, userID, trackID string, computedAt time.Time) (*models.Track, bool, error) {
+	track, err := s.repo.GetTrack(ctx, userID, trackID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if track.UpdatedAt.After(computedAt) {
+		return nil, false, nil
+	}
+	return track, true, nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomLibrary returns n deterministic tracks spread over keys, tempos,
+// artists, genres and tags, including tracks with unknown key or BPM
+func randomLibrary(n int) []models.Track {
+	rng := rand.New(rand.NewSource(1))
+	keys := []string{""}
+	for key := range CamelotWheel {
+		keys = append(keys, key)
+	}
+	tags := []string{"dark", "vocal", "peak", "deep", "classic", "warmup"}
+
+	tracks := make([]models.Track, n)
+	for i := range tracks {
+		bpm := 60 + rng.Intn(120)
+		if rng.Intn(10) == 0 {
+			bpm = 0
+		}
+		tracks[i] = createSimilarityTestTrack(
+			fmt.Sprintf("track-%04d", i),
+			fmt.Sprintf("Artist %d", rng.Intn(40)),
+			fmt.Sprintf("Album %d", rng.Intn(80)),
+			fmt.Sprintf("Genre %d", rng.Intn(8)),
+			keys[rng.Intn(len(keys))],
+			bpm,
+			[]string{tags[rng.Intn(len(tags))]},
+		)
+	}
+	return tracks
+}
+
+func libraryPointers(tracks []models.Track) []*models.Track {
+	out := make([]*models.Track, len(tracks))
+	for i := range tracks {
+		out[i] = &tracks[i]
+	}
+	return out
+}
+
+func TestTrackBuckets_MatchFullScan(t *testing.T) {
+	tracks := randomLibrary(2000)
+	buckets := newTrackBuckets(tracks)
+	all := libraryPointers(tracks)
+	svc := NewSimilarityService(nil, nil, nil)
+
+	for _, i := range []int{0, 1, 7, 42, 99, 500, 1234} {
+		source := &tracks[i]
+
+		for _, mode := range []string{"combined", "semantic", "features"} {
+			opts := SimilarityOptions{Mode: mode, MinSimilarity: 0.1, IncludeSameAlbum: true}
+			want := svc.rankSimilar(source, all, opts)
+			got := svc.rankSimilar(source, buckets.similarCandidates(source), opts)
+			assert.Equal(t, want, got, "source=%s mode=%s", source.ID, mode)
+		}
+
+		for _, keyMode := range []string{"harmonic", "exact", "any"} {
+			for _, tolerance := range []int{1, 5, 12} {
+				opts := MixingOptions{BPMTolerance: tolerance, KeyMode: keyMode}
+				want := svc.rankMixable(source, all, opts)
+				got := svc.rankMixable(source, buckets.mixCandidates(source, opts), opts)
+				assert.Equal(t, want, got, "source=%s key=%s tolerance=%d", source.ID, keyMode, tolerance)
+			}
+		}
+	}
+}
+
+func TestTrackBuckets_ReadOnlyCandidates(t *testing.T) {
+	tracks := randomLibrary(2000)
+	buckets := newTrackBuckets(tracks)
+	source := &tracks[0]
+	source.BPM, source.KeyCamelot = 128, "8A"
+
+	candidates := buckets.mixCandidates(source, DefaultMixingOptions())
+	assert.Less(t, len(candidates), len(tracks)/4)
+}
+
+func TestBucketRanges(t *testing.T) {
+	assert.Equal(t, [][2]int{{-1, 1}, {16, 17}, {31, 33}}, bucketRanges([]int{33, 16, -1, 31, 0, 32, 1, 17, 32}))
+	assert.Empty(t, bucketRanges(nil))
+}
+
+// countingRepository counts library reads and harmonic index queries so tests
+// can tell cached answers from recomputed ones
+type countingRepository struct {
+	*repository.MemoryRepository
+	lists   int
+	queries int
+}
+
+func (r *countingRepository) ListTracksByKeyAndBPMBucket(ctx context.Context, userID, camelotKey string, minBucket, maxBucket int) ([]models.Track, error) {
+	r.queries++
+	return r.MemoryRepository.ListTracksByKeyAndBPMBucket(ctx, userID, camelotKey, minBucket, maxBucket)
+}
+
+func (r *countingRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error) {
+	if filter.LastKey == "" {
+		r.lists++
+	}
+	return r.MemoryRepository.ListTracks(ctx, userID, filter)
+}
+
+func newNeighborTestService(t *testing.T) (*SimilarityService, *countingRepository) {
+	t.Helper()
+	repo := &countingRepository{MemoryRepository: repository.NewMemoryRepository()}
+	for _, track := range randomLibrary(300) {
+		track.UserID = "user-123"
+		require.NoError(t, repo.CreateTrack(context.Background(), track))
+	}
+	return NewSimilarityService(nil, repo, nil), repo
+}
+
+func TestFindSimilarTracks_NeighborCache(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newNeighborTestService(t)
+	opts := DefaultSimilarityOptions()
+	opts.MinSimilarity = 0.3
+
+	first, err := svc.FindSimilarTracks(ctx, "user-123", "track-0001", opts)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.Similar)
+	assert.Equal(t, 1, repo.lists)
+
+	t.Run("served from cache", func(t *testing.T) {
+		cached, err := svc.FindSimilarTracks(ctx, "user-123", "track-0001", opts)
+		require.NoError(t, err)
+		assert.Equal(t, first, cached)
+		assert.Equal(t, 1, repo.lists)
+	})
+
+	t.Run("other modes are computed", func(t *testing.T) {
+		semantic := opts
+		semantic.Mode = "semantic"
+		_, err := svc.FindSimilarTracks(ctx, "user-123", "track-0001", semantic)
+		require.NoError(t, err)
+		assert.Equal(t, 2, repo.lists)
+	})
+
+	t.Run("updated neighbor invalidates", func(t *testing.T) {
+		neighbor, err := repo.GetTrack(ctx, "user-123", first.Similar[0].Track.ID)
+		require.NoError(t, err)
+		require.NoError(t, repo.UpdateTrack(ctx, *neighbor))
+		before := repo.lists
+
+		_, err = svc.FindSimilarTracks(ctx, "user-123", "track-0001", opts)
+		require.NoError(t, err)
+		assert.Equal(t, before+1, repo.lists)
+
+		_, err = svc.FindSimilarTracks(ctx, "user-123", "track-0001", opts)
+		require.NoError(t, err)
+		assert.Equal(t, before+1, repo.lists, "recomputed list is cached again")
+	})
+
+	t.Run("expired list is recomputed", func(t *testing.T) {
+		before := repo.lists
+		svc.now = func() time.Time { return time.Now().Add(models.TrackNeighborsTTL + time.Minute) }
+		defer func() { svc.now = time.Now }()
+
+		_, err := svc.FindSimilarTracks(ctx, "user-123", "track-0001", opts)
+		require.NoError(t, err)
+		assert.Equal(t, before+1, repo.lists)
+	})
+}
+
+// plainRepository hides the optional neighbor cache and harmonic index, so the
+// service falls back to reading the whole library
+type plainRepository struct {
+	repository.Repository
+}
+
+func TestFindMixableTracks_HarmonicIndex(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newNeighborTestService(t)
+	full := NewSimilarityService(nil, plainRepository{repo.MemoryRepository}, nil)
+
+	for _, opts := range []MixingOptions{
+		DefaultMixingOptions(),
+		{Limit: 50, BPMTolerance: 3, KeyMode: "exact"},
+		{Limit: 50, BPMTolerance: 8, KeyMode: "any"},
+	} {
+		for _, trackID := range []string{"track-0002", "track-0010", "track-0150"} {
+			want, err := full.FindMixableTracks(ctx, "user-123", trackID, opts)
+			require.NoError(t, err)
+			got, err := svc.FindMixableTracks(ctx, "user-123", trackID, opts)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, "track=%s opts=%+v", trackID, opts)
+		}
+	}
+	assert.Zero(t, repo.lists, "mixing queries read the index, not the library")
+	assert.NotZero(t, repo.queries)
+}
+
+func TestFindMixableTracks_NeighborCache(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newNeighborTestService(t)
+	full := NewSimilarityService(nil, plainRepository{repo.MemoryRepository}, nil)
+
+	// A similarity query reads the library once and caches both neighbor lists
+	_, err := svc.FindSimilarTracks(ctx, "user-123", "track-0002", DefaultSimilarityOptions())
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.lists)
+	neighbors, err := repo.GetTrackNeighbors(ctx, "user-123", "track-0002")
+	require.NoError(t, err)
+	require.NotEmpty(t, neighbors.Mixable)
+
+	// The default query and stricter ones are answered from the list
+	for _, opts := range []MixingOptions{
+		DefaultMixingOptions(),
+		{Limit: 5, BPMTolerance: 2, KeyMode: "exact"},
+	} {
+		want, err := full.FindMixableTracks(ctx, "user-123", "track-0002", opts)
+		require.NoError(t, err)
+		got, err := svc.FindMixableTracks(ctx, "user-123", "track-0002", opts)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	assert.Equal(t, 1, repo.lists)
+	assert.Zero(t, repo.queries)
+
+	// A looser query is answered from the index
+	_, err = svc.FindMixableTracks(ctx, "user-123", "track-0002", MixingOptions{KeyMode: "any"})
+	require.NoError(t, err)
+	assert.NotZero(t, repo.queries)
+}
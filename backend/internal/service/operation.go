@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// OperationRepository defines the repository interface for the undo records
+// of bulk operations
+type OperationRepository interface {
+	GetOperation(ctx context.Context, userID, operationID string) (*models.Operation, error)
+	PutOperation(ctx context.Context, op models.Operation) error
+	DeleteOperation(ctx context.Context, userID, operationID string) error
+}
+
+// OperationTrackRepository reads and saves the tracks an undo reverts
+type OperationTrackRepository interface {
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	UpdateTrack(ctx context.Context, track models.Track) error
+}
+
+// OperationRecorder keeps the undo record of a bulk operation
+type OperationRecorder interface {
+	Record(ctx context.Context, userID string, kind models.OperationKind, changes []models.FieldChange) (*models.Operation, error)
+}
+
+// TrashRecorder keeps the undo record of tracks moved to the trash
+type TrashRecorder interface {
+	RecordTrash(ctx context.Context, userID, libraryID string, trackIDs []string) (*models.Operation, error)
+}
+
+// OperationAware is implemented by services whose bulk edits can be undone;
+// Services.RecordOperations installs the recorder.
+type OperationAware interface {
+	SetOperations(recorder OperationRecorder)
+}
+
+// OperationService keeps the field changes of each bulk operation for
+// models.UndoWindow and reverts them on request. Records belong to the user
+// who ran the operation; tracks are read through the library-scoped
+// repository so household edits revert in the shared library. Tracks an
+// operation moved to the trash are restored from it.
+type OperationService struct {
+	repo   OperationRepository
+	tracks OperationTrackRepository
+	// trash restores trashed tracks; nil where nothing is trashed
+	trash *TrashService
+	now   func() time.Time
+}
+
+// NewOperationService creates a new undo service. trash may be nil where no
+// operation trashes tracks.
+func NewOperationService(repo OperationRepository, tracks OperationTrackRepository, trash *TrashService) *OperationService {
+	return &OperationService{repo: repo, tracks: tracks, trash: trash, now: time.Now}
+}
+
+// Record stores the undo record of a bulk operation. Operations that changed
+// nothing are not recorded and return nil.
+func (s *OperationService) Record(ctx context.Context, userID string, kind models.OperationKind, changes []models.FieldChange) (*models.Operation, error) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return s.put(ctx, models.Operation{UserID: userID, Kind: kind, Changes: changes})
+}
+
+// RecordTrash stores the undo record of tracks moved to the trash of a
+// library. Operations that trashed nothing are not recorded and return nil.
+func (s *OperationService) RecordTrash(ctx context.Context, userID, libraryID string, trackIDs []string) (*models.Operation, error) {
+	if len(trackIDs) == 0 {
+		return nil, nil
+	}
+	return s.put(ctx, models.Operation{
+		UserID:    userID,
+		Kind:      models.OperationArchiveDelete,
+		Changes:   []models.FieldChange{},
+		Trashed:   trackIDs,
+		LibraryID: libraryID,
+	})
+}
+
+// put stores a new operation open for models.UndoWindow from now
+func (s *OperationService) put(ctx context.Context, op models.Operation) (*models.Operation, error) {
+	now := s.now()
+	op.ID = uuid.New().String()
+	op.CreatedAt = now
+	op.ExpiresAt = now.Add(models.UndoWindow)
+	if err := s.repo.PutOperation(ctx, op); err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// Undo reverts the changes of an operation and discards its record, so an
+// operation is undone at most once. Changes to missing or locked tracks, and
+// to fields edited again since the operation, are skipped. Trashed tracks are
+// restored unless they have left the trash.
+func (s *OperationService) Undo(ctx context.Context, userID, operationID string) (*models.UndoResult, error) {
+	op, err := s.repo.GetOperation(ctx, userID, operationID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Operation", operationID)
+		}
+		return nil, err
+	}
+	if op.Expired(s.now()) {
+		return nil, models.ErrUndoExpired
+	}
+
+	result := &models.UndoResult{OperationID: op.ID, Reverted: []models.FieldChange{}}
+
+	var order []string
+	byTrack := make(map[string][]models.FieldChange)
+	for _, change := range op.Changes {
+		if _, ok := byTrack[change.TrackID]; !ok {
+			order = append(order, change.TrackID)
+		}
+		byTrack[change.TrackID] = append(byTrack[change.TrackID], change)
+	}
+
+	for _, trackID := range order {
+		changes := byTrack[trackID]
+		track, err := s.tracks.GetTrack(ctx, userID, trackID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				result.Skipped = append(result.Skipped, changes...)
+				continue
+			}
+			return nil, err
+		}
+		if track.EnsureUnlocked() != nil {
+			result.Skipped = append(result.Skipped, changes...)
+			continue
+		}
+
+		var reverted []models.FieldChange
+		for _, change := range changes {
+			if track.RevertChange(change) {
+				reverted = append(reverted, change)
+			} else {
+				result.Skipped = append(result.Skipped, change)
+			}
+		}
+		if len(reverted) == 0 {
+			continue
+		}
+		if err := s.tracks.UpdateTrack(ctx, *track); err != nil {
+			return nil, err
+		}
+		result.Reverted = append(result.Reverted, reverted...)
+	}
+
+	if len(op.Trashed) > 0 && s.trash != nil {
+		for _, trackID := range op.Trashed {
+			if _, err := s.trash.Restore(ctx, op.LibraryID, trackID); err != nil {
+				if err == repository.ErrNotFound {
+					continue
+				}
+				return nil, err
+			}
+			result.Restored = append(result.Restored, trackID)
+		}
+	}
+
+	if err := s.repo.DeleteOperation(ctx, userID, op.ID); err != nil {
+		// The changes are reverted; a second undo finds nothing left to revert
+		fmt.Printf("Warning: failed to delete operation %s: %v\n", op.ID, err)
+	}
+	return result, nil
+}
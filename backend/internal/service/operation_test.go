@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUndoTest wires a cleanup service that records its batches with an
+// operation service whose clock the test controls
+func newUndoTest(t *testing.T, now *time.Time) (CleanupService, *OperationService, *repository.MemoryRepository) {
+	t.Helper()
+	cleanup, repo := newCleanupService(t, placeholderTrack().Build())
+	ops := NewOperationService(repo, repo, nil)
+	ops.now = func() time.Time { return *now }
+	services := &Services{Cleanup: cleanup}
+	services.RecordOperations(ops)
+	return cleanup, ops, repo
+}
+
+var undoSuggestions = []models.CleanupSuggestion{
+	{TrackID: "track-1", Field: models.TagFieldTitle, Original: "HARDER BETTER FASTER", Suggested: "Harder Better Faster"},
+	{TrackID: "track-2", Field: models.TagFieldTitle, Original: "Track 02", Suggested: "Digital Love"},
+	{TrackID: "track-2", Field: models.TagFieldArtist, Original: "DAFT PUNK", Suggested: "Daft Punk"},
+}
+
+func TestOperationService_Undo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reverts an applied cleanup once", func(t *testing.T) {
+		now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+		cleanup, ops, repo := newUndoTest(t, &now)
+
+		applied, err := cleanup.ApplyCleanups(ctx, "user-1", models.ApplyCleanupRequest{Suggestions: undoSuggestions})
+		require.NoError(t, err)
+		require.NotEmpty(t, applied.OperationID)
+		assert.Equal(t, now.Add(models.UndoWindow), *applied.UndoExpiresAt)
+
+		now = now.Add(models.UndoWindow - time.Second)
+		result, err := ops.Undo(ctx, "user-1", applied.OperationID)
+		require.NoError(t, err)
+		assert.Len(t, result.Reverted, 3)
+		assert.Empty(t, result.Skipped)
+
+		track, err := repo.GetTrack(ctx, "user-1", "track-2")
+		require.NoError(t, err)
+		assert.Equal(t, "Track 02", track.Title)
+		assert.Equal(t, "DAFT PUNK", track.Artist)
+
+		_, err = ops.Undo(ctx, "user-1", applied.OperationID)
+		assert.Equal(t, models.NewNotFoundError("Operation", applied.OperationID), err)
+	})
+
+	t.Run("skips fields edited since and locked tracks", func(t *testing.T) {
+		now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+		cleanup, ops, repo := newUndoTest(t, &now)
+		applied, err := cleanup.ApplyCleanups(ctx, "user-1", models.ApplyCleanupRequest{Suggestions: undoSuggestions})
+		require.NoError(t, err)
+
+		track, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		track.Title = "Harder, Better, Faster, Stronger"
+		require.NoError(t, repo.UpdateTrack(ctx, *track))
+		track, err = repo.GetTrack(ctx, "user-1", "track-2")
+		require.NoError(t, err)
+		track.Locked = true
+		require.NoError(t, repo.UpdateTrack(ctx, *track))
+
+		result, err := ops.Undo(ctx, "user-1", applied.OperationID)
+		require.NoError(t, err)
+		assert.Empty(t, result.Reverted)
+		assert.Len(t, result.Skipped, 3)
+
+		track, err = repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, "Harder, Better, Faster, Stronger", track.Title)
+	})
+
+	t.Run("refuses once the window has closed", func(t *testing.T) {
+		now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+		cleanup, ops, _ := newUndoTest(t, &now)
+		applied, err := cleanup.ApplyCleanups(ctx, "user-1", models.ApplyCleanupRequest{Suggestions: undoSuggestions})
+		require.NoError(t, err)
+
+		now = now.Add(models.UndoWindow)
+		_, err = ops.Undo(ctx, "user-1", applied.OperationID)
+		assert.Equal(t, models.ErrUndoExpired, err)
+	})
+
+	t.Run("only the user who ran the operation can undo it", func(t *testing.T) {
+		now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+		cleanup, ops, _ := newUndoTest(t, &now)
+		applied, err := cleanup.ApplyCleanups(ctx, "user-1", models.ApplyCleanupRequest{Suggestions: undoSuggestions})
+		require.NoError(t, err)
+
+		_, err = ops.Undo(ctx, "user-2", applied.OperationID)
+		assert.Equal(t, models.NewNotFoundError("Operation", applied.OperationID), err)
+	})
+
+	t.Run("dry runs and empty batches keep no record", func(t *testing.T) {
+		now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+		cleanup, _, _ := newUndoTest(t, &now)
+
+		dryRun, err := cleanup.ApplyCleanups(ctx, "user-1", models.ApplyCleanupRequest{Suggestions: undoSuggestions, DryRun: true})
+		require.NoError(t, err)
+		assert.Empty(t, dryRun.OperationID)
+
+		stale := []models.CleanupSuggestion{{TrackID: "track-1", Field: models.TagFieldTitle, Original: "Something Else", Suggested: "X"}}
+		empty, err := cleanup.ApplyCleanups(ctx, "user-1", models.ApplyCleanupRequest{Suggestions: stale})
+		require.NoError(t, err)
+		assert.Empty(t, empty.OperationID)
+		assert.Nil(t, empty.UndoExpiresAt)
+	})
+}
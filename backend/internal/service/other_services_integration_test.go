@@ -92,7 +92,7 @@ func TestIntegration_TagService_CRUD(t *testing.T) {
 	})
 
 	t.Run("delete tag", func(t *testing.T) {
-		err := tagSvc.DeleteTag(ctx, "tag-user", "electronic")
+		_, err := tagSvc.DeleteTag(ctx, "tag-user", "electronic", false)
 		require.NoError(t, err)
 
 		_, err = tagSvc.GetTag(ctx, "tag-user", "electronic")
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+)
+
+const (
+	// partyScanLimit bounds the host tracks (or playlist entries) a guest
+	// search reads
+	partyScanLimit = 500
+	// partySearchLimit is how many matches a guest search returns by default
+	partySearchLimit = 20
+	// partyFullRetry is when a guest may try again while the host has
+	// MaxPendingPartyRequests requests to answer
+	partyFullRetry = time.Minute
+)
+
+// PartyRepository defines the repository interface for guest DJ parties
+type PartyRepository interface {
+	PutParty(ctx context.Context, party models.Party) error
+	GetParty(ctx context.Context, partyID string) (*models.Party, error)
+	DeleteParty(ctx context.Context, partyID string) error
+	PutPartyRequest(ctx context.Context, req models.PartyRequest) error
+	GetPartyRequest(ctx context.Context, partyID, requestID string) (*models.PartyRequest, error)
+	ListPartyRequests(ctx context.Context, partyID string) ([]models.PartyRequest, error)
+	// RespondToPartyRequest fails with repository.ErrConflict unless the
+	// stored request is still pending
+	RespondToPartyRequest(ctx context.Context, req models.PartyRequest) error
+
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error)
+	GetPlaylist(ctx context.Context, userID, playlistID string) (*models.Playlist, error)
+	GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error)
+}
+
+// PartyQueue adds approved requests to the host's play queue
+type PartyQueue interface {
+	ApplyQueueAction(ctx context.Context, userID string, req models.QueueActionRequest) (*models.PlayerState, error)
+}
+
+// PartyService runs guest DJ parties: the host hands out a link signed with
+// the share link keyring, guests without an account search the tracks it
+// offers and request them, and approved requests join the host's play queue.
+type PartyService struct {
+	repo  PartyRepository
+	queue PartyQueue
+	keys  reqsign.Keyring
+	now   func() time.Time
+}
+
+// NewPartyService creates a new party service
+func NewPartyService(repo PartyRepository, queue PartyQueue, keys reqsign.Keyring) *PartyService {
+	return &PartyService{repo: repo, queue: queue, keys: keys, now: time.Now}
+}
+
+// Create starts a party offering the host's public tracks, or the tracks of
+// one of their playlists, and returns its link
+func (s *PartyService) Create(ctx context.Context, hostID string, req models.CreatePartyRequest) (*models.PartyLinkResponse, error) {
+	if req.PlaylistID != "" {
+		if _, err := s.repo.GetPlaylist(ctx, hostID, req.PlaylistID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, models.NewNotFoundError("Playlist", req.PlaylistID)
+			}
+			return nil, err
+		}
+	}
+
+	now := s.now()
+	party := models.Party{
+		ID:         uuid.New().String(),
+		HostID:     hostID,
+		Name:       req.Name,
+		PlaylistID: req.PlaylistID,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(req.Duration()),
+	}
+	token, err := s.keys.SignToken(models.PartyTokenSubject(party.ID), party.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign party link: %w", err)
+	}
+	if err := s.repo.PutParty(ctx, party); err != nil {
+		return nil, err
+	}
+
+	return &models.PartyLinkResponse{Party: party, URL: "/api/v1/parties/" + token}, nil
+}
+
+// End stops a party; its link stops working at once
+func (s *PartyService) End(ctx context.Context, hostID, partyID string) error {
+	if _, err := s.hostParty(ctx, hostID, partyID); err != nil {
+		return err
+	}
+	return s.repo.DeleteParty(ctx, partyID)
+}
+
+// ListRequests returns a party's requests, oldest first
+func (s *PartyService) ListRequests(ctx context.Context, hostID, partyID string) ([]models.PartyRequest, error) {
+	if _, err := s.hostParty(ctx, hostID, partyID); err != nil {
+		return nil, err
+	}
+
+	requests, err := s.repo.ListPartyRequests(ctx, partyID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].CreatedAt.Before(requests[j].CreatedAt) })
+	return requests, nil
+}
+
+// Respond approves or declines a pending request. An approved track is added
+// to the end of the host's play queue.
+func (s *PartyService) Respond(ctx context.Context, hostID, partyID, requestID string, approve bool) (*models.PartyRequest, error) {
+	party, err := s.hostParty(ctx, hostID, partyID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.repo.GetPartyRequest(ctx, partyID, requestID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("Party request", requestID)
+		}
+		return nil, err
+	}
+
+	if pending.Status != models.PartyRequestPending {
+		return nil, models.NewConflictError("the request was already answered")
+	}
+
+	answered := *pending
+	answered.Status = models.PartyRequestDeclined
+	if approve {
+		answered.Status = models.PartyRequestApproved
+	}
+	respondedAt := s.now()
+	answered.RespondedAt = &respondedAt
+
+	// Answering before queueing means a request approved twice at once is
+	// queued once
+	if err := s.repo.RespondToPartyRequest(ctx, answered); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, models.NewConflictError("the request was already answered")
+		}
+		return nil, err
+	}
+
+	if approve {
+		_, err := s.queue.ApplyQueueAction(ctx, party.HostID, models.QueueActionRequest{
+			Action:   models.QueueActionAddLast,
+			TrackIDs: []string{pending.TrackID},
+		})
+		if err != nil {
+			// Best effort: put the request back so the host can approve it again
+			_ = s.repo.PutPartyRequest(ctx, *pending)
+			return nil, err
+		}
+	}
+
+	return &answered, nil
+}
+
+// Open returns what a party link shows its guests
+func (s *PartyService) Open(ctx context.Context, token string) (*models.PartyGuestView, error) {
+	party, err := s.guestParty(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PartyGuestView{Name: party.Name, ExpiresAt: party.ExpiresAt}, nil
+}
+
+// Search returns the party's tracks whose title, artist or album contains the
+// query, ignoring case; an empty query matches every track
+func (s *PartyService) Search(ctx context.Context, token string, filter models.PartySearchFilter) ([]models.PartyTrack, error) {
+	party, err := s.guestParty(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = partySearchLimit
+	}
+
+	tracks, err := s.partyTracks(ctx, party)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.ToLower(strings.TrimSpace(filter.Query))
+	results := make([]models.PartyTrack, 0, limit)
+	for _, track := range tracks {
+		if len(results) == limit {
+			break
+		}
+		if query == "" || strings.Contains(strings.ToLower(track.Title), query) ||
+			strings.Contains(strings.ToLower(track.Artist), query) ||
+			strings.Contains(strings.ToLower(track.Album), query) {
+			results = append(results, models.NewPartyTrack(track))
+		}
+	}
+	return results, nil
+}
+
+// RequestTrack asks the host to queue one of the party's tracks. A guest,
+// told apart by the address they connect from, may make
+// PartyGuestRequestLimit requests per PartyGuestRequestWindow, and a party
+// takes no more requests while MaxPendingPartyRequests await the host.
+func (s *PartyService) RequestTrack(ctx context.Context, token, guestAddr string, req models.GuestTrackRequest) (*models.PartyRequest, error) {
+	party, err := s.guestParty(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	track, err := s.partyTrack(ctx, party, req.TrackID)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := s.repo.ListPartyRequests(ctx, party.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	key := guestKey(party.ID, guestAddr)
+	pending := 0
+	var recent []time.Time
+	for _, r := range requests {
+		if r.Status == models.PartyRequestPending {
+			if r.TrackID == track.ID {
+				return nil, models.NewConflictError("the track was already requested")
+			}
+			pending++
+		}
+		if r.GuestKey == key && now.Sub(r.CreatedAt) < models.PartyGuestRequestWindow {
+			recent = append(recent, r.CreatedAt)
+		}
+	}
+	if len(recent) >= models.PartyGuestRequestLimit {
+		oldest := recent[0]
+		for _, at := range recent[1:] {
+			if at.Before(oldest) {
+				oldest = at
+			}
+		}
+		return nil, models.NewRateLimitedError(oldest.Add(models.PartyGuestRequestWindow).Sub(now))
+	}
+	if pending >= models.MaxPendingPartyRequests {
+		return nil, models.NewRateLimitedError(partyFullRetry)
+	}
+
+	request := models.PartyRequest{
+		ID:          uuid.New().String(),
+		PartyID:     party.ID,
+		TrackID:     track.ID,
+		TrackTitle:  track.Title,
+		TrackArtist: track.Artist,
+		GuestName:   strings.TrimSpace(req.GuestName),
+		GuestKey:    key,
+		Status:      models.PartyRequestPending,
+		CreatedAt:   now,
+		ExpiresAt:   party.ExpiresAt,
+	}
+	if err := s.repo.PutPartyRequest(ctx, request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// hostParty loads a party of the host; other hosts' parties are not found
+func (s *PartyService) hostParty(ctx context.Context, hostID, partyID string) (*models.Party, error) {
+	party, err := s.repo.GetParty(ctx, partyID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && party.HostID != hostID) {
+		return nil, models.NewNotFoundError("Party", partyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return party, nil
+}
+
+// guestParty loads the active party a link points at. Invalid, expired and
+// ended links, and links of other kinds, are not found.
+func (s *PartyService) guestParty(ctx context.Context, token string) (*models.Party, error) {
+	subject, err := s.keys.VerifyToken(token, s.now())
+	if err != nil {
+		return nil, models.NewNotFoundError("Party", token)
+	}
+	partyID, ok := models.ParsePartyTokenSubject(subject)
+	if !ok {
+		return nil, models.NewNotFoundError("Party", token)
+	}
+
+	party, err := s.repo.GetParty(ctx, partyID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && !party.Active(s.now())) {
+		return nil, models.NewNotFoundError("Party", token)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return party, nil
+}
+
+// partyTracks returns the tracks a party offers: the host's tracks on its
+// playlist, or the host's public tracks. At most partyScanLimit are read.
+func (s *PartyService) partyTracks(ctx context.Context, party *models.Party) ([]models.Track, error) {
+	var tracks []models.Track
+
+	if party.PlaylistID != "" {
+		entries, err := s.repo.GetPlaylistTracks(ctx, party.PlaylistID)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Position < entries[j].Position })
+		for _, entry := range entries[:min(len(entries), partyScanLimit)] {
+			track, err := s.repo.GetTrack(ctx, party.HostID, entry.TrackID)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			tracks = append(tracks, *track)
+		}
+		return tracks, nil
+	}
+
+	cursor := ""
+	for scanned := 0; scanned < partyScanLimit; {
+		page, err := s.repo.ListTracks(ctx, party.HostID, models.TrackFilter{Limit: 100, LastKey: cursor})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tracks: %w", err)
+		}
+		for _, track := range page.Items {
+			// ListTracks mixes in other users' public tracks
+			if track.UserID != party.HostID {
+				continue
+			}
+			scanned++
+			if track.Visibility == models.VisibilityPublic {
+				tracks = append(tracks, track)
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	return tracks, nil
+}
+
+// partyTrack loads a track guests of the party may request
+func (s *PartyService) partyTrack(ctx context.Context, party *models.Party, trackID string) (*models.Track, error) {
+	track, err := s.repo.GetTrack(ctx, party.HostID, trackID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, models.NewNotFoundError("Track", trackID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if party.PlaylistID == "" {
+		if track.Visibility != models.VisibilityPublic {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return track, nil
+	}
+
+	entries, err := s.repo.GetPlaylistTracks(ctx, party.PlaylistID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.TrackID == trackID {
+			return track, nil
+		}
+	}
+	return nil, models.NewNotFoundError("Track", trackID)
+}
+
+// guestKey identifies a guest of a party by the address they connect from,
+// hashed so the address is not stored
+func guestKey(partyID, addr string) string {
+	sum := sha256.Sum256([]byte(partyID + "\n" + addr))
+	return hex.EncodeToString(sum[:8])
+}
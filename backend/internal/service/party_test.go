@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	partyPublic  = "11111111-1111-4111-8111-111111111111"
+	partyPrivate = "22222222-2222-4222-8222-222222222222"
+)
+
+// newPartyService serves a host with a public and a private track
+func newPartyService(t *testing.T) (*PartyService, *repository.MemoryRepository, *time.Time) {
+	t.Helper()
+	repo := newSeededRepo(t,
+		builders.NewTrackBuilder("host", partyPublic).WithTitle("Dancing Queen").WithArtist("ABBA").Public().Build(),
+		builders.NewTrackBuilder("host", partyPrivate).WithTitle("Demo Take").WithArtist("ABBA").Build(),
+	)
+
+	keys, err := reqsign.ParseKeyring("k1:preview-secret-0123456789abcdefghij")
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	svc := NewPartyService(repo, NewPlayerStateService(repo), keys)
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+func partyToken(link *models.PartyLinkResponse) string {
+	return strings.TrimPrefix(link.URL, "/api/v1/parties/")
+}
+
+func TestPartyService(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newPartyService(t)
+
+	link, err := svc.Create(ctx, "host", models.CreatePartyRequest{Name: "Friday"})
+	require.NoError(t, err)
+	token := partyToken(link)
+
+	view, err := svc.Open(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "Friday", view.Name)
+
+	t.Run("guests find only public tracks", func(t *testing.T) {
+		tracks, err := svc.Search(ctx, token, models.PartySearchFilter{Query: "abba"})
+		require.NoError(t, err)
+		require.Len(t, tracks, 1)
+		assert.Equal(t, partyPublic, tracks[0].ID)
+
+		_, err = svc.RequestTrack(ctx, token, "203.0.113.7", models.GuestTrackRequest{TrackID: partyPrivate})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+	})
+
+	t.Run("approved requests join the host's queue once", func(t *testing.T) {
+		request, err := svc.RequestTrack(ctx, token, "203.0.113.7", models.GuestTrackRequest{TrackID: partyPublic, GuestName: "Sam"})
+		require.NoError(t, err)
+		assert.Equal(t, models.PartyRequestPending, request.Status)
+
+		_, err = svc.RequestTrack(ctx, token, "198.51.100.1", models.GuestTrackRequest{TrackID: partyPublic})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "CONFLICT", apiErr.Code)
+
+		_, err = svc.Respond(ctx, "someone-else", link.Party.ID, request.ID, true)
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+
+		approved, err := svc.Respond(ctx, "host", link.Party.ID, request.ID, true)
+		require.NoError(t, err)
+		assert.Equal(t, models.PartyRequestApproved, approved.Status)
+
+		_, err = svc.Respond(ctx, "host", link.Party.ID, request.ID, true)
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "CONFLICT", apiErr.Code)
+
+		state, err := repo.GetPlayerState(ctx, "host")
+		require.NoError(t, err)
+		assert.Equal(t, []string{partyPublic}, state.TrackIDs)
+	})
+
+	t.Run("ended parties are not found", func(t *testing.T) {
+		require.NoError(t, svc.End(ctx, "host", link.Party.ID))
+		_, err := svc.Open(ctx, token)
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+	})
+}
+
+func TestPartyService_RateLimits(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, now := newPartyService(t)
+
+	link, err := svc.Create(ctx, "host", models.CreatePartyRequest{Name: "Friday"})
+	require.NoError(t, err)
+	token := partyToken(link)
+
+	// Each request is for a different track, approved or not
+	for i := 0; i < models.PartyGuestRequestLimit; i++ {
+		require.NoError(t, repo.PutPartyRequest(ctx, models.PartyRequest{
+			ID: string(rune('a' + i)), PartyID: link.Party.ID, TrackID: string(rune('a' + i)),
+			GuestKey: guestKey(link.Party.ID, "203.0.113.7"), Status: models.PartyRequestApproved,
+			CreatedAt: now.Add(-time.Duration(i) * time.Minute),
+		}))
+	}
+
+	_, err = svc.RequestTrack(ctx, token, "203.0.113.7", models.GuestTrackRequest{TrackID: partyPublic})
+	var apiErr *models.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "RATE_LIMITED", apiErr.Code)
+	// The oldest request, 4 minutes ago, leaves the window in 6 minutes
+	assert.Equal(t, "360", apiErr.MessageArgs["seconds"])
+
+	_, err = svc.RequestTrack(ctx, token, "198.51.100.1", models.GuestTrackRequest{TrackID: partyPublic})
+	require.NoError(t, err)
+
+	*now = now.Add(models.PartyGuestRequestWindow)
+	_, err = svc.RequestTrack(ctx, token, "203.0.113.7", models.GuestTrackRequest{TrackID: partyPublic})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "CONFLICT", apiErr.Code, "past the window only the pending duplicate is refused")
+}
+
+func TestPartyService_PlaylistScope(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newPartyService(t)
+	require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "party-mix", UserID: "host", Name: "Party mix"}))
+	require.NoError(t, repo.AddTracksToPlaylist(ctx, "party-mix", []string{partyPrivate}, 0))
+
+	link, err := svc.Create(ctx, "host", models.CreatePartyRequest{Name: "Friday", PlaylistID: "party-mix"})
+	require.NoError(t, err)
+	token := partyToken(link)
+
+	tracks, err := svc.Search(ctx, token, models.PartySearchFilter{})
+	require.NoError(t, err)
+	require.Len(t, tracks, 1)
+	assert.Equal(t, partyPrivate, tracks[0].ID)
+
+	_, err = svc.RequestTrack(ctx, token, "203.0.113.7", models.GuestTrackRequest{TrackID: partyPublic})
+	var apiErr *models.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "NOT_FOUND", apiErr.Code)
+}
@@ -0,0 +1,34 @@
+package service
+
+import "github.com/gvasels/personal-music-searchengine/internal/models"
+
+// PlaybackAvailabilityAware is implemented by services that return tracks;
+// Services.DescribePlayback installs the service that describes their
+// playback options.
+type PlaybackAvailabilityAware interface {
+	SetPlaybackAvailability(availability *PlaybackAvailabilityService)
+}
+
+// PlaybackAvailabilityService builds track responses that tell clients which
+// ways of playing each track they can offer, so track, playlist and search
+// responses describe playback alike
+type PlaybackAvailabilityService struct {
+	features models.PlaybackFeatures
+}
+
+// NewPlaybackAvailabilityService creates a playback availability service for
+// a deployment with the given features
+func NewPlaybackAvailabilityService(features models.PlaybackFeatures) *PlaybackAvailabilityService {
+	return &PlaybackAvailabilityService{features: features}
+}
+
+// TrackResponse converts track to its response with its playback
+// availability. A nil service leaves the availability out, like ToResponse.
+func (s *PlaybackAvailabilityService) TrackResponse(track models.Track, coverArtURL string) models.TrackResponse {
+	response := track.ToResponse(coverArtURL)
+	if s != nil {
+		availability := track.PlaybackAvailability(s.features)
+		response.Playback = &availability
+	}
+	return response
+}
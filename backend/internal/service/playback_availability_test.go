@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaybackAvailabilityService_TrackResponse(t *testing.T) {
+	track := models.Track{ID: "track-1", S3Key: "media/u/track-1.mp3", Format: models.AudioFormatMP3, HLSStatus: models.HLSStatusProcessing}
+
+	var none *PlaybackAvailabilityService
+	assert.Nil(t, none.TrackResponse(track, "").Playback)
+
+	response := NewPlaybackAvailabilityService(models.PlaybackFeatures{HLSStreaming: true}).TrackResponse(track, "https://cover")
+	assert.Equal(t, "https://cover", response.CoverArtURL)
+	require.NotNil(t, response.Playback)
+	assert.Equal(t, models.PlaybackOriginal, response.Playback.Preferred)
+	assert.Equal(t, models.PlaybackReasonTranscoding, response.Playback.HLS.Reason)
+}
+
+func TestServices_DescribePlayback(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{
+		ID: "track-1", UserID: "user-1", S3Key: "media/user-1/track-1.mp3", Format: models.AudioFormatMP3,
+		HLSStatus: models.HLSStatusReady, HLSPlaylistKey: "hls/user-1/track-1/master.m3u8",
+	}))
+	services := NewServices(repo, repository.NewMemoryS3Repository("https://media.example.com"), nil, "media", "")
+
+	before, err := services.Track.GetTrack(ctx, "user-1", "track-1", false)
+	require.NoError(t, err)
+	assert.Nil(t, before.Playback)
+
+	services.DescribePlayback(NewPlaybackAvailabilityService(models.PlaybackFeatures{HLSStreaming: true}))
+
+	after, err := services.Track.GetTrack(ctx, "user-1", "track-1", false)
+	require.NoError(t, err)
+	require.NotNil(t, after.Playback)
+	assert.Equal(t, models.PlaybackHLS, after.Playback.Preferred)
+	assert.NotNil(t, services.Playback)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// queueWriteAttempts bounds how often a change without a client version is
+// re-applied after losing a race with another device
+const queueWriteAttempts = 3
+
+// PlayerStateRepository defines the repository interface for synced player state
+type PlayerStateRepository interface {
+	GetPlayerState(ctx context.Context, userID string) (*models.PlayerState, error)
+	// PutPlayerState fails with repository.ErrConflict unless the stored
+	// state is still at version previous
+	PutPlayerState(ctx context.Context, state models.PlayerState, previous int64) error
+}
+
+// PlayerStateService keeps each user's play queue in sync across their
+// devices. Every change bumps the queue's version; a change made against an
+// older version fails with a conflict carrying the current queue.
+type PlayerStateService struct {
+	repo PlayerStateRepository
+	now  func() time.Time
+}
+
+// NewPlayerStateService creates a new player state service
+func NewPlayerStateService(repo PlayerStateRepository) *PlayerStateService {
+	return &PlayerStateService{repo: repo, now: time.Now}
+}
+
+// Get returns the user's play queue, an empty one at version 0 if they have none
+func (s *PlayerStateService) Get(ctx context.Context, userID string) (*models.PlayerState, error) {
+	state, err := s.repo.GetPlayerState(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &models.PlayerState{UserID: userID, TrackIDs: []string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ApplyQueueAction changes the user's play queue and returns it. With
+// req.Version set the change only applies to that version of the queue;
+// without it, it applies to the latest one.
+func (s *PlayerStateService) ApplyQueueAction(ctx context.Context, userID string, req models.QueueActionRequest) (*models.PlayerState, error) {
+	if req.Action == models.QueueActionRemove && req.Version == nil {
+		return nil, models.NewValidationError("version is required to remove a track")
+	}
+
+	for attempt := 1; ; attempt++ {
+		state, err := s.Get(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if req.Version != nil && *req.Version != state.Version {
+			return nil, queueConflict(state)
+		}
+
+		previous := state.Version
+		if err := state.Apply(req); err != nil {
+			return nil, err
+		}
+		state.Version++
+		state.UpdatedAt = s.now()
+
+		err = s.repo.PutPlayerState(ctx, *state, previous)
+		if err == nil {
+			return state, nil
+		}
+		if !errors.Is(err, repository.ErrConflict) {
+			return nil, err
+		}
+		if req.Version != nil || attempt == queueWriteAttempts {
+			current, getErr := s.Get(ctx, userID)
+			if getErr != nil {
+				return nil, getErr
+			}
+			return nil, queueConflict(current)
+		}
+	}
+}
+
+// queueConflict reports a change made against an outdated queue, with the
+// current one in its details so the client can retry without another read
+func queueConflict(current *models.PlayerState) error {
+	err := models.NewConflictError("the play queue was changed by another device")
+	err.Details = current
+	return err
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	queueTrack1 = "11111111-1111-4111-8111-111111111111"
+	queueTrack2 = "22222222-2222-4222-8222-222222222222"
+	queueTrack3 = "33333333-3333-4333-8333-333333333333"
+)
+
+func queueVersion(v int64) *int64 { return &v }
+
+func TestPlayerStateService(t *testing.T) {
+	ctx := context.Background()
+	svc := NewPlayerStateService(repository.NewMemoryRepository())
+
+	state, err := svc.Get(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), state.Version)
+	assert.NotNil(t, state.TrackIDs)
+
+	state, err = svc.ApplyQueueAction(ctx, "u1", models.QueueActionRequest{
+		Action: models.QueueActionAddLast, TrackIDs: []string{queueTrack1, queueTrack3},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), state.Version)
+
+	state, err = svc.ApplyQueueAction(ctx, "u1", models.QueueActionRequest{
+		Action: models.QueueActionAddNext, TrackIDs: []string{queueTrack2}, Version: queueVersion(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{queueTrack1, queueTrack2, queueTrack3}, state.TrackIDs)
+	assert.Equal(t, int64(2), state.Version)
+
+	t.Run("stale version is refused with the current queue", func(t *testing.T) {
+		idx := 0
+		_, err := svc.ApplyQueueAction(ctx, "u1", models.QueueActionRequest{
+			Action: models.QueueActionRemove, Index: &idx, Version: queueVersion(1),
+		})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "CONFLICT", apiErr.Code)
+		current, ok := apiErr.Details.(*models.PlayerState)
+		require.True(t, ok)
+		assert.Equal(t, int64(2), current.Version)
+	})
+
+	t.Run("remove requires a version", func(t *testing.T) {
+		idx := 0
+		_, err := svc.ApplyQueueAction(ctx, "u1", models.QueueActionRequest{Action: models.QueueActionRemove, Index: &idx})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+	})
+
+	t.Run("remove at index", func(t *testing.T) {
+		idx := 1
+		state, err := svc.ApplyQueueAction(ctx, "u1", models.QueueActionRequest{
+			Action: models.QueueActionRemove, Index: &idx, Version: queueVersion(2),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{queueTrack1, queueTrack3}, state.TrackIDs)
+	})
+
+	t.Run("queues are per user", func(t *testing.T) {
+		other, err := svc.Get(ctx, "u2")
+		require.NoError(t, err)
+		assert.Empty(t, other.TrackIDs)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		state, err := svc.ApplyQueueAction(ctx, "u1", models.QueueActionRequest{Action: models.QueueActionClear})
+		require.NoError(t, err)
+		assert.Empty(t, state.TrackIDs)
+		assert.Equal(t, int64(4), state.Version)
+	})
+}
+
+// racingPlayerStateRepo lets another device change the queue between the
+// service's read and its first write
+type racingPlayerStateRepo struct {
+	*repository.MemoryRepository
+	raced bool
+}
+
+func (r *racingPlayerStateRepo) PutPlayerState(ctx context.Context, state models.PlayerState, previous int64) error {
+	if !r.raced {
+		r.raced = true
+		other := models.PlayerState{UserID: state.UserID, TrackIDs: []string{queueTrack3}, Version: previous + 1}
+		if err := r.MemoryRepository.PutPlayerState(ctx, other, previous); err != nil {
+			return err
+		}
+	}
+	return r.MemoryRepository.PutPlayerState(ctx, state, previous)
+}
+
+func TestPlayerStateService_Race(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("change without version is reapplied", func(t *testing.T) {
+		svc := NewPlayerStateService(&racingPlayerStateRepo{MemoryRepository: repository.NewMemoryRepository()})
+		state, err := svc.ApplyQueueAction(ctx, "u1", models.QueueActionRequest{
+			Action: models.QueueActionAddLast, TrackIDs: []string{queueTrack1},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{queueTrack3, queueTrack1}, state.TrackIDs)
+		assert.Equal(t, int64(2), state.Version)
+	})
+
+	t.Run("change with version conflicts", func(t *testing.T) {
+		svc := NewPlayerStateService(&racingPlayerStateRepo{MemoryRepository: repository.NewMemoryRepository()})
+		_, err := svc.ApplyQueueAction(ctx, "u1", models.QueueActionRequest{
+			Action: models.QueueActionAddLast, TrackIDs: []string{queueTrack1}, Version: queueVersion(0),
+		})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "CONFLICT", apiErr.Code)
+		assert.Equal(t, []string{queueTrack3}, apiErr.Details.(*models.PlayerState).TrackIDs)
+	})
+}
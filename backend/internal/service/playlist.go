@@ -12,17 +12,24 @@ import (
 // playlistService implements PlaylistService
 type playlistService struct {
 	repo   repository.Repository
-	s3Repo repository.S3Repository
+	s3Repo repository.MediaStore
+	// availability describes tracks' playback options; nil leaves them out
+	availability *PlaybackAvailabilityService
 }
 
 // NewPlaylistService creates a new playlist service
-func NewPlaylistService(repo repository.Repository, s3Repo repository.S3Repository) PlaylistService {
+func NewPlaylistService(repo repository.Repository, s3Repo repository.MediaStore) PlaylistService {
 	return &playlistService{
 		repo:   repo,
 		s3Repo: s3Repo,
 	}
 }
 
+// SetPlaybackAvailability describes the playback options of playlist tracks
+func (s *playlistService) SetPlaybackAvailability(availability *PlaybackAvailabilityService) {
+	s.availability = availability
+}
+
 func (s *playlistService) CreatePlaylist(ctx context.Context, userID string, req models.CreatePlaylistRequest) (*models.PlaylistResponse, error) {
 	now := time.Now()
 	playlist := models.Playlist{
@@ -69,6 +76,7 @@ func (s *playlistService) GetPlaylist(ctx context.Context, userID, playlistID st
 	}
 
 	// Get full track details for each playlist track
+	saveData := dataSaver(ctx, s.repo, userID)
 	tracks := make([]models.TrackResponse, 0, len(playlistTracks))
 	for _, pt := range playlistTracks {
 		track, err := s.repo.GetTrack(ctx, userID, pt.TrackID)
@@ -81,12 +89,12 @@ func (s *playlistService) GetPlaylist(ctx context.Context, userID, playlistID st
 
 		trackCoverURL := ""
 		if track.CoverArtKey != "" {
-			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData), 24*time.Hour)
 			if err == nil {
 				trackCoverURL = url
 			}
 		}
-		tracks = append(tracks, track.ToResponse(trackCoverURL))
+		tracks = append(tracks, s.availability.TrackResponse(*track, trackCoverURL))
 	}
 
 	playlistResp := playlist.ToResponse(coverArtURL)
@@ -135,16 +143,27 @@ func (s *playlistService) UpdatePlaylist(ctx context.Context, userID, playlistID
 	return &response, nil
 }
 
-func (s *playlistService) DeletePlaylist(ctx context.Context, userID, playlistID string) error {
-	_, err := s.repo.GetPlaylist(ctx, userID, playlistID)
+// DeletePlaylist deletes a playlist and its track list; a dry run reports
+// the playlist without deleting it
+func (s *playlistService) DeletePlaylist(ctx context.Context, userID, playlistID string, dryRun bool) (*models.DeletePlaylistResult, error) {
+	playlist, err := s.repo.GetPlaylist(ctx, userID, playlistID)
 	if err != nil {
 		if err == repository.ErrNotFound {
-			return models.NewNotFoundError("Playlist", playlistID)
+			return nil, models.NewNotFoundError("Playlist", playlistID)
 		}
-		return err
+		return nil, err
 	}
 
-	return s.repo.DeletePlaylist(ctx, userID, playlistID)
+	plan := &bulkPlan[string, *models.DeletePlaylistResult]{
+		changes: []string{playlistID},
+		result:  &models.DeletePlaylistResult{ID: playlist.ID, Name: playlist.Name, TrackCount: playlist.TrackCount, DryRun: dryRun},
+	}
+	if _, err := plan.execute(ctx, dryRun, func(ctx context.Context, id string) error {
+		return s.repo.DeletePlaylist(ctx, userID, id)
+	}); err != nil {
+		return nil, err
+	}
+	return plan.result, nil
 }
 
 func (s *playlistService) ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*repository.PaginatedResult[models.PlaylistResponse], error) {
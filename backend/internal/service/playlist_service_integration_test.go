@@ -97,7 +97,7 @@ func TestIntegration_PlaylistService_CRUD(t *testing.T) {
 	})
 
 	t.Run("delete playlist", func(t *testing.T) {
-		err := svc.DeletePlaylist(ctx, "pl-user", playlistID)
+		_, err := svc.DeletePlaylist(ctx, "pl-user", playlistID, false)
 		require.NoError(t, err)
 
 		_, err = svc.GetPlaylist(ctx, "pl-user", playlistID)
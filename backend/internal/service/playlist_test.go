@@ -8,378 +8,24 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // =============================================================================
 // Playlist Service Tests (Epic 4)
 // =============================================================================
 
-// MockPlaylistRepository provides mockable repository methods for playlist service tests
-type MockPlaylistRepository struct {
-	mock.Mock
-}
-
-// Playlist repository methods
-func (m *MockPlaylistRepository) CreatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	args := m.Called(ctx, playlist)
-	return args.Error(0)
-}
-
-func (m *MockPlaylistRepository) GetPlaylist(ctx context.Context, userID, playlistID string) (*models.Playlist, error) {
-	args := m.Called(ctx, userID, playlistID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Playlist), args.Error(1)
-}
-
-func (m *MockPlaylistRepository) UpdatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	args := m.Called(ctx, playlist)
-	return args.Error(0)
-}
-
-func (m *MockPlaylistRepository) DeletePlaylist(ctx context.Context, userID, playlistID string) error {
-	args := m.Called(ctx, userID, playlistID)
-	return args.Error(0)
-}
-
-func (m *MockPlaylistRepository) ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*repository.PaginatedResult[models.Playlist], error) {
-	args := m.Called(ctx, userID, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Playlist]), args.Error(1)
-}
-
-func (m *MockPlaylistRepository) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string, position int) error {
-	args := m.Called(ctx, playlistID, trackIDs, position)
-	return args.Error(0)
-}
-
-func (m *MockPlaylistRepository) RemoveTracksFromPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
-	args := m.Called(ctx, playlistID, trackIDs)
-	return args.Error(0)
-}
-
-func (m *MockPlaylistRepository) GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error) {
-	args := m.Called(ctx, playlistID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.PlaylistTrack), args.Error(1)
-}
-
-func (m *MockPlaylistRepository) ReorderPlaylistTracks(ctx context.Context, playlistID string, tracks []models.PlaylistTrack) error {
-	args := m.Called(ctx, playlistID, tracks)
-	return args.Error(0)
-}
-
-func (m *MockPlaylistRepository) GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, userID, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-func (m *MockPlaylistRepository) GetTrackByID(ctx context.Context, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-// Stub implementations for Repository interface (required but not used in playlist tests)
-func (m *MockPlaylistRepository) CreateTrack(ctx context.Context, track models.Track) error    { return nil }
-func (m *MockPlaylistRepository) UpdateTrack(ctx context.Context, track models.Track) error    { return nil }
-func (m *MockPlaylistRepository) DeleteTrack(ctx context.Context, userID, trackID string) error { return nil }
-func (m *MockPlaylistRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.Track, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) GetAlbum(ctx context.Context, userID, albumID string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) ListAlbums(ctx context.Context, userID string, filter models.AlbumFilter) (*repository.PaginatedResult[models.Album], error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) ListAlbumsByArtist(ctx context.Context, userID, artist string) ([]models.Album, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) UpdateAlbumStats(ctx context.Context, userID, albumID string, trackCount, totalDuration int) error {
-	return nil
-}
-func (m *MockPlaylistRepository) CreateUser(ctx context.Context, user models.User) error { return nil }
-func (m *MockPlaylistRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) UpdateUser(ctx context.Context, user models.User) error { return nil }
-func (m *MockPlaylistRepository) UpdateUserStats(ctx context.Context, userID string, storageUsed int64, trackCount, albumCount, playlistCount int) error {
-	return nil
-}
-func (m *MockPlaylistRepository) CreateTag(ctx context.Context, tag models.Tag) error { return nil }
-func (m *MockPlaylistRepository) GetTag(ctx context.Context, userID, tagName string) (*models.Tag, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) UpdateTag(ctx context.Context, tag models.Tag) error { return nil }
-func (m *MockPlaylistRepository) DeleteTag(ctx context.Context, userID, tagName string) error {
-	return nil
-}
-func (m *MockPlaylistRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) AddTagsToTrack(ctx context.Context, userID, trackID string, tagNames []string) error {
-	return nil
-}
-func (m *MockPlaylistRepository) RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error {
-	return nil
-}
-func (m *MockPlaylistRepository) GetTracksByTag(ctx context.Context, userID, tagName string) ([]models.Track, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) GetTrackTags(ctx context.Context, userID, trackID string) ([]string, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) CreateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockPlaylistRepository) GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) UpdateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockPlaylistRepository) UpdateUploadStatus(ctx context.Context, userID, uploadID string, status models.UploadStatus, errorMsg string, trackID string) error {
-	return nil
-}
-func (m *MockPlaylistRepository) UpdateUploadStep(ctx context.Context, userID, uploadID string, step models.ProcessingStep, success bool) error {
-	return nil
-}
-func (m *MockPlaylistRepository) ListUploads(ctx context.Context, userID string, filter models.UploadFilter) (*repository.PaginatedResult[models.Upload], error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) ListUploadsByStatus(ctx context.Context, status models.UploadStatus) ([]models.Upload, error) {
-	return nil, nil
-}
-
-// Artist-related methods
-func (m *MockPlaylistRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockPlaylistRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) UpdateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockPlaylistRepository) DeleteArtist(ctx context.Context, userID, artistID string) error {
-	return nil
-}
-func (m *MockPlaylistRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*repository.PaginatedResult[models.Artist], error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) BatchGetArtists(ctx context.Context, userID string, artistIDs []string) (map[string]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) MergeArtists(ctx context.Context, userID, primaryID string, secondaryIDs []string) error {
-	return nil
-}
-func (m *MockPlaylistRepository) GetOrCreateArtist(ctx context.Context, userID, name string) (*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) IncrementArtistStats(ctx context.Context, userID, artistID string, trackDelta, albumDelta int) error {
-	return nil
-}
-func (m *MockPlaylistRepository) GetArtistAlbumCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockPlaylistRepository) GetArtistTrackCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockPlaylistRepository) GetArtistTotalPlays(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockPlaylistRepository) SearchArtists(ctx context.Context, userID, query string, limit int) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) SearchPlaylists(ctx context.Context, userID, query string, limit int) ([]models.Playlist, error) {
-	return nil, nil
-}
-
-// User role methods
-func (m *MockPlaylistRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	return nil
-}
-func (m *MockPlaylistRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
-	return nil, nil
-}
-
-// Playlist visibility methods
-func (m *MockPlaylistRepository) UpdatePlaylistVisibility(ctx context.Context, userID, playlistID string, visibility models.PlaylistVisibility) error {
-	args := m.Called(ctx, userID, playlistID, visibility)
-	return args.Error(0)
-}
-func (m *MockPlaylistRepository) ListPublicPlaylists(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Playlist], error) {
-	args := m.Called(ctx, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Playlist]), args.Error(1)
-}
-
-// ArtistProfile methods
-func (m *MockPlaylistRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockPlaylistRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockPlaylistRepository) DeleteArtistProfile(ctx context.Context, userID string) error {
-	return nil
-}
-func (m *MockPlaylistRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.ArtistProfile], error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Follow methods
-func (m *MockPlaylistRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
-	return nil
-}
-func (m *MockPlaylistRepository) DeleteFollow(ctx context.Context, followerID, followedID string) error {
-	return nil
-}
-func (m *MockPlaylistRepository) GetFollow(ctx context.Context, followerID, followedID string) (*models.Follow, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Admin-related methods for track visibility
-func (m *MockPlaylistRepository) ListPublicTracks(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) UpdateTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error {
-	return nil
-}
-func (m *MockPlaylistRepository) SearchUsers(ctx context.Context, query string, limit int) ([]models.User, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) SetUserDisabled(ctx context.Context, userID string, disabled bool) error {
-	return nil
-}
-func (m *MockPlaylistRepository) GetUserDisplayName(ctx context.Context, userID string) (string, error) {
-	return "", nil
-}
-func (m *MockPlaylistRepository) GetFollowerCount(ctx context.Context, userID string) (int, error) {
-	return 0, nil
-}
-
-// User settings methods
-func (m *MockPlaylistRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) GetUserByCognitoID(ctx context.Context, cognitoID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) SearchUsersByEmail(ctx context.Context, emailPrefix string, limit int, cursor string) ([]repository.UserSearchResult, string, error) {
-	return nil, "", nil
-}
-func (m *MockPlaylistRepository) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
-	return nil, nil
-}
-func (m *MockPlaylistRepository) UpdateUserSettings(ctx context.Context, userID string, update *repository.UserSettingsUpdate) (*models.UserSettings, error) {
-	return nil, nil
-}
-
-// MockPlaylistS3Repository provides mockable S3 repository methods
-type MockPlaylistS3Repository struct {
-	mock.Mock
-}
-
-func (m *MockPlaylistS3Repository) GeneratePresignedUploadURL(ctx context.Context, key string, contentType string, ttl time.Duration) (string, error) {
-	args := m.Called(ctx, key, contentType, ttl)
-	return args.String(0), args.Error(1)
-}
-
-func (m *MockPlaylistS3Repository) GeneratePresignedDownloadURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
-	args := m.Called(ctx, key, ttl)
-	return args.String(0), args.Error(1)
-}
-
-func (m *MockPlaylistS3Repository) DeleteObject(ctx context.Context, key string) error {
-	args := m.Called(ctx, key)
-	return args.Error(0)
-}
-
-// Stub implementations for S3Repository interface
-func (m *MockPlaylistS3Repository) InitiateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
-	return "", nil
-}
-
-func (m *MockPlaylistS3Repository) GenerateMultipartUploadURLs(ctx context.Context, key, uploadID string, numParts int, expiry time.Duration) ([]models.MultipartUploadPartURL, error) {
-	return nil, nil
-}
-
-func (m *MockPlaylistS3Repository) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []models.CompletedPartInfo) error {
-	return nil
-}
-
-func (m *MockPlaylistS3Repository) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
-	return nil
-}
-
-func (m *MockPlaylistS3Repository) CopyObject(ctx context.Context, sourceKey, destKey string) error {
-	return nil
-}
-
-func (m *MockPlaylistS3Repository) GetObjectMetadata(ctx context.Context, key string) (map[string]string, error) {
-	return nil, nil
-}
-
-func (m *MockPlaylistS3Repository) ObjectExists(ctx context.Context, key string) (bool, error) {
-	return false, nil
-}
-
-func (m *MockPlaylistS3Repository) GeneratePresignedDownloadURLWithFilename(ctx context.Context, key string, ttl time.Duration, filename string) (string, error) {
-	return "", nil
-}
-
-func (m *MockPlaylistS3Repository) DeleteByPrefix(ctx context.Context, prefix string) error {
-	return nil
-}
-
 // =============================================================================
 // CreatePlaylist Tests
 // =============================================================================
 
 func TestCreatePlaylist_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("CreatePlaylist", ctx, mock.MatchedBy(func(p models.Playlist) bool {
@@ -407,8 +53,8 @@ func TestCreatePlaylist_Success(t *testing.T) {
 
 func TestGetPlaylist_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -440,6 +86,7 @@ func TestGetPlaylist_Success(t *testing.T) {
 		Duration: 180,
 	}, nil)
 
+	mockRepo.On("GetUserSettings", ctx, "user-123").Return(nil, repository.ErrNotFound)
 	resp, err := svc.GetPlaylist(ctx, "user-123", "playlist-1")
 
 	assert.NoError(t, err)
@@ -454,8 +101,8 @@ func TestGetPlaylist_Success(t *testing.T) {
 
 func TestGetPlaylist_NotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("GetPlaylist", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
@@ -474,8 +121,8 @@ func TestGetPlaylist_NotFound(t *testing.T) {
 
 func TestGetPlaylist_WithCoverArt(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -491,6 +138,7 @@ func TestGetPlaylist_WithCoverArt(t *testing.T) {
 
 	mockS3.On("GeneratePresignedDownloadURL", ctx, "covers/playlist-1.jpg", mock.Anything).Return("https://s3.example.com/covers/playlist-1.jpg?signed", nil)
 
+	mockRepo.On("GetUserSettings", ctx, "user-123").Return(nil, repository.ErrNotFound)
 	resp, err := svc.GetPlaylist(ctx, "user-123", "playlist-1")
 
 	assert.NoError(t, err)
@@ -506,8 +154,8 @@ func TestGetPlaylist_WithCoverArt(t *testing.T) {
 
 func TestUpdatePlaylist_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -540,8 +188,8 @@ func TestUpdatePlaylist_Success(t *testing.T) {
 
 func TestUpdatePlaylist_NotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("GetPlaylist", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
@@ -566,8 +214,8 @@ func TestUpdatePlaylist_NotFound(t *testing.T) {
 
 func TestDeletePlaylist_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("GetPlaylist", ctx, "user-123", "playlist-1").Return(&models.Playlist{
@@ -577,21 +225,42 @@ func TestDeletePlaylist_Success(t *testing.T) {
 	}, nil)
 	mockRepo.On("DeletePlaylist", ctx, "user-123", "playlist-1").Return(nil)
 
-	err := svc.DeletePlaylist(ctx, "user-123", "playlist-1")
+	result, err := svc.DeletePlaylist(ctx, "user-123", "playlist-1", false)
 
-	assert.NoError(t, err)
+	require.NoError(t, err)
+	assert.Equal(t, "playlist-1", result.ID)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestDeletePlaylist_DryRunKeepsThePlaylist(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
+	svc := NewPlaylistService(mockRepo, mockS3)
+
+	mockRepo.On("GetPlaylist", ctx, "user-123", "playlist-1").Return(&models.Playlist{
+		ID:         "playlist-1",
+		UserID:     "user-123",
+		Name:       "My Playlist",
+		TrackCount: 3,
+	}, nil)
+
+	result, err := svc.DeletePlaylist(ctx, "user-123", "playlist-1", true)
+
+	require.NoError(t, err)
+	assert.Equal(t, &models.DeletePlaylistResult{ID: "playlist-1", Name: "My Playlist", TrackCount: 3, DryRun: true}, result)
+	mockRepo.AssertNotCalled(t, "DeletePlaylist", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestDeletePlaylist_NotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("GetPlaylist", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
 
-	err := svc.DeletePlaylist(ctx, "user-123", "nonexistent")
+	_, err := svc.DeletePlaylist(ctx, "user-123", "nonexistent", false)
 
 	assert.Error(t, err)
 
@@ -608,8 +277,8 @@ func TestDeletePlaylist_NotFound(t *testing.T) {
 
 func TestListPlaylists_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -640,8 +309,8 @@ func TestListPlaylists_Success(t *testing.T) {
 
 func TestListPlaylists_Empty(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("ListPlaylists", ctx, "user-123", mock.Anything).Return(&repository.PaginatedResult[models.Playlist]{
@@ -665,8 +334,8 @@ func TestListPlaylists_Empty(t *testing.T) {
 
 func TestAddTracks_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -711,8 +380,8 @@ func TestAddTracks_Success(t *testing.T) {
 
 func TestAddTracks_AtPosition(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -753,8 +422,8 @@ func TestAddTracks_AtPosition(t *testing.T) {
 
 func TestAddTracks_TrackNotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -784,8 +453,8 @@ func TestAddTracks_TrackNotFound(t *testing.T) {
 
 func TestAddTracks_PlaylistNotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("GetPlaylist", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
@@ -811,8 +480,8 @@ func TestAddTracks_PlaylistNotFound(t *testing.T) {
 
 func TestRemoveTracks_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -851,8 +520,8 @@ func TestRemoveTracks_Success(t *testing.T) {
 
 func TestRemoveTracks_PlaylistNotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("GetPlaylist", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
@@ -878,8 +547,8 @@ func TestRemoveTracks_PlaylistNotFound(t *testing.T) {
 
 func TestUpdatePlaylistVisibility_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -901,8 +570,8 @@ func TestUpdatePlaylistVisibility_Success(t *testing.T) {
 
 func TestUpdatePlaylistVisibility_NotOwner(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -922,8 +591,8 @@ func TestUpdatePlaylistVisibility_NotOwner(t *testing.T) {
 
 func TestUpdatePlaylistVisibility_InvalidVisibility(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	err := svc.UpdateVisibility(ctx, "user-123", "playlist-1", models.PlaylistVisibility("invalid"))
@@ -934,8 +603,8 @@ func TestUpdatePlaylistVisibility_InvalidVisibility(t *testing.T) {
 
 func TestUpdatePlaylistVisibility_PlaylistNotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("GetPlaylist", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
@@ -952,8 +621,8 @@ func TestUpdatePlaylistVisibility_PlaylistNotFound(t *testing.T) {
 
 func TestListPublicPlaylists_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -993,8 +662,8 @@ func TestListPublicPlaylists_Success(t *testing.T) {
 
 func TestListPublicPlaylists_WithPagination(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	now := time.Now()
@@ -1025,8 +694,8 @@ func TestListPublicPlaylists_WithPagination(t *testing.T) {
 
 func TestListPublicPlaylists_Empty(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockPlaylistRepository)
-	mockS3 := new(MockPlaylistS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 	svc := NewPlaylistService(mockRepo, mockS3)
 
 	mockRepo.On("ListPublicPlaylists", ctx, 20, "").Return(&repository.PaginatedResult[models.Playlist]{
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+)
+
+// Preview clip jobs carry JobOutputKey=JobOutputPreview in their user
+// metadata so the completion handler can tell them from HLS jobs
+const (
+	JobOutputKey     = "output"
+	JobOutputPreview = "preview"
+)
+
+// previewURLExpiry is how long the URL a preview link redirects to is valid
+const previewURLExpiry = 15 * time.Minute
+
+// PreviewRequest represents a request to cut a track's preview clip.
+type PreviewRequest struct {
+	TrackID string
+	UserID  string
+	S3Key   string // Source audio file key
+	// TenantID places input and output under the tenant's object prefix (multi-tenant mode)
+	TenantID string
+	// Start and Length of the clip in seconds, see models.Track.PreviewClip
+	Start  int
+	Length int
+}
+
+// PreviewJob represents a started preview clip job.
+type PreviewJob struct {
+	JobID      string
+	PreviewKey string // S3 key where the MP3 clip will be created
+}
+
+// StartPreview creates a MediaConvert job cutting a short MP3 clip out of a track.
+func (s *TranscodeService) StartPreview(ctx context.Context, req PreviewRequest) (*PreviewJob, error) {
+	if req.TrackID == "" || req.UserID == "" || req.S3Key == "" {
+		return nil, fmt.Errorf("trackID, userID, and s3Key are required")
+	}
+	if req.Start < 0 || req.Length <= 0 {
+		return nil, fmt.Errorf("invalid preview clip %ds+%ds", req.Start, req.Length)
+	}
+
+	previewKey, err := BuildPreviewKey(req.UserID, req.TrackID)
+	if err != nil {
+		return nil, err
+	}
+
+	jobMetadata := map[string]string{
+		"trackId":    req.TrackID,
+		"userId":     req.UserID,
+		JobOutputKey: JobOutputPreview,
+	}
+	if req.TenantID != "" {
+		jobMetadata["tenantId"] = req.TenantID
+	}
+
+	input := &mediaconvert.CreateJobInput{
+		Role:         aws.String(s.role),
+		Queue:        aws.String(s.queue),
+		Settings:     s.buildPreviewSettings(req),
+		Tags:         jobMetadata,
+		UserMetadata: jobMetadata,
+	}
+
+	output, err := s.mcClient.CreateJob(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MediaConvert job: %w", err)
+	}
+
+	return &PreviewJob{
+		JobID:      *output.Job.Id,
+		PreviewKey: previewKey,
+	}, nil
+}
+
+// buildPreviewSettings creates MediaConvert job settings for a single MP3 clip.
+func (s *TranscodeService) buildPreviewSettings(req PreviewRequest) *types.JobSettings {
+	var objectPrefix string
+	if req.TenantID != "" {
+		objectPrefix = tenant.ObjectPrefix(req.TenantID)
+	}
+	inputS3URI := fmt.Sprintf("s3://%s/%s%s", s.bucket, objectPrefix, req.S3Key)
+	// File group destinations name the output file; MediaConvert adds the extension
+	outputS3Path := fmt.Sprintf("s3://%s/%spreviews/%s/%s", s.bucket, objectPrefix, req.UserID, req.TrackID)
+
+	return &types.JobSettings{
+		Inputs: []types.Input{
+			{
+				FileInput:      aws.String(inputS3URI),
+				TimecodeSource: types.InputTimecodeSourceZerobased,
+				InputClippings: []types.InputClipping{
+					{
+						StartTimecode: aws.String(timecode(req.Start)),
+						EndTimecode:   aws.String(timecode(req.Start + req.Length)),
+					},
+				},
+				AudioSelectors: map[string]types.AudioSelector{
+					"Audio Selector 1": {
+						DefaultSelection: types.AudioDefaultSelectionDefault,
+					},
+				},
+			},
+		},
+		OutputGroups: []types.OutputGroup{
+			{
+				Name: aws.String("Preview Group"),
+				OutputGroupSettings: &types.OutputGroupSettings{
+					Type: types.OutputGroupTypeFileGroupSettings,
+					FileGroupSettings: &types.FileGroupSettings{
+						Destination: aws.String(outputS3Path),
+					},
+				},
+				Outputs: []types.Output{
+					{
+						Extension: aws.String("mp3"),
+						ContainerSettings: &types.ContainerSettings{
+							Container: types.ContainerTypeRaw,
+						},
+						AudioDescriptions: []types.AudioDescription{
+							{
+								AudioSourceName: aws.String("Audio Selector 1"),
+								CodecSettings: &types.AudioCodecSettings{
+									Codec: types.AudioCodecMp3,
+									Mp3Settings: &types.Mp3Settings{
+										Bitrate:         aws.Int32(128000),
+										Channels:        aws.Int32(2),
+										RateControlMode: types.Mp3RateControlModeCbr,
+										SampleRate:      aws.Int32(44100),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// timecode formats seconds as a zero-based HH:MM:SS:FF timecode
+func timecode(seconds int) string {
+	return fmt.Sprintf("%02d:%02d:%02d:00", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// BuildPreviewKey builds the S3 key of a track's preview clip.
+func BuildPreviewKey(userID, trackID string) (string, error) {
+	key, err := sanitize.Key("previews", userID, trackID+".mp3")
+	if err != nil {
+		return "", fmt.Errorf("invalid preview location: %w", err)
+	}
+	return key, nil
+}
+
+// PreviewRepository defines the repository interface for preview links
+type PreviewRepository interface {
+	GetTrackByID(ctx context.Context, trackID string) (*models.Track, error)
+}
+
+// PreviewService hands out share links to the preview clips of public
+// tracks. A link is a token signed with the preview keyring that names the
+// track; it works without an account until it expires or the track stops
+// being public.
+type PreviewService struct {
+	repo       PreviewRepository
+	s3Repo     repository.MediaStore
+	cloudfront repository.CloudFrontSigner
+	keys       reqsign.Keyring
+	now        func() time.Time
+	accessLog  AccessRecorder
+}
+
+// NewPreviewService creates a new preview link service
+func NewPreviewService(repo PreviewRepository, s3Repo repository.MediaStore, cloudfront repository.CloudFrontSigner, keys reqsign.Keyring) *PreviewService {
+	return &PreviewService{repo: repo, s3Repo: s3Repo, cloudfront: cloudfront, keys: keys, now: time.Now}
+}
+
+// SetAccessLog records opened preview links
+func (s *PreviewService) SetAccessLog(recorder AccessRecorder) {
+	s.accessLog = recorder
+}
+
+// Link returns a share link to the preview clip of a public track. Private
+// tracks and tracks whose clip is not ready yet have no preview.
+func (s *PreviewService) Link(ctx context.Context, trackID string) (*models.PreviewLinkResponse, error) {
+	if _, err := s.previewTrack(ctx, trackID); err != nil {
+		return nil, err
+	}
+
+	expiresAt := s.now().Add(models.PreviewLinkTTL)
+	token, err := s.keys.SignToken(trackID, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign preview link: %w", err)
+	}
+
+	return &models.PreviewLinkResponse{
+		TrackID:   trackID,
+		URL:       "/api/v1/previews/" + token,
+		TokenID:   models.PreviewTokenID(token),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Resolve returns a short-lived URL of the clip a preview link points at.
+// Invalid and expired links, and links to tracks that are no longer public,
+// are not found.
+func (s *PreviewService) Resolve(ctx context.Context, token string) (string, error) {
+	trackID, err := s.keys.VerifyToken(token, s.now())
+	if err != nil {
+		return "", models.NewNotFoundError("Preview", token)
+	}
+	track, err := s.previewTrack(ctx, trackID)
+	if err != nil {
+		return "", err
+	}
+
+	var url string
+	if s.cloudfront != nil {
+		url, err = s.cloudfront.GenerateSignedURL(ctx, track.PreviewKey, previewURLExpiry)
+	} else {
+		url, err = s.s3Repo.GeneratePresignedDownloadURL(ctx, track.PreviewKey, previewURLExpiry)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if s.accessLog != nil {
+		s.accessLog.Record(ctx, *track, models.AccessPreview, models.PreviewTokenID(token))
+	}
+	return url, nil
+}
+
+// previewTrack loads a track whose preview clip may be served
+func (s *PreviewService) previewTrack(ctx context.Context, trackID string) (*models.Track, error) {
+	track, err := s.repo.GetTrackByID(ctx, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Preview", trackID)
+		}
+		return nil, err
+	}
+	if track.Visibility != models.VisibilityPublic || !track.PreviewReady() {
+		return nil, models.NewNotFoundError("Preview", trackID)
+	}
+	return track, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/reqsign"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartPreview_CutsClip(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockMediaConvertClient)
+	svc := NewTranscodeService(mockClient, "my-bucket", "role", "queue")
+
+	var input *mediaconvert.CreateJobInput
+	mockClient.On("CreateJob", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		input = args.Get(1).(*mediaconvert.CreateJobInput)
+	}).Return(&mediaconvert.CreateJobOutput{
+		Job: &types.Job{Id: aws.String("job-1"), Status: types.JobStatusSubmitted},
+	}, nil)
+
+	job, err := svc.StartPreview(ctx, PreviewRequest{
+		TrackID: "track-123",
+		UserID:  "user-456",
+		S3Key:   "audio/user-456/track-123/original.mp3",
+		Start:   75,
+		Length:  30,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "job-1", job.JobID)
+	assert.Equal(t, "previews/user-456/track-123.mp3", job.PreviewKey)
+	assert.Equal(t, JobOutputPreview, input.UserMetadata[JobOutputKey])
+
+	clip := input.Settings.Inputs[0].InputClippings[0]
+	assert.Equal(t, "00:01:15:00", *clip.StartTimecode)
+	assert.Equal(t, "00:01:45:00", *clip.EndTimecode)
+	group := input.Settings.OutputGroups[0].OutputGroupSettings.FileGroupSettings
+	assert.Equal(t, "s3://my-bucket/previews/user-456/track-123", *group.Destination)
+}
+
+func TestStartPreview_RejectsInvalidRequests(t *testing.T) {
+	svc := NewTranscodeService(new(MockMediaConvertClient), "my-bucket", "role", "queue")
+
+	_, err := svc.StartPreview(context.Background(), PreviewRequest{TrackID: "t", UserID: "u", S3Key: "k", Length: 0})
+	assert.Error(t, err)
+	_, err = svc.StartPreview(context.Background(), PreviewRequest{TrackID: "../t", UserID: "u", S3Key: "k", Length: 30})
+	assert.Error(t, err)
+}
+
+// newPreviewService serves a public track with a ready clip and a private one
+func newPreviewService(t *testing.T) (*PreviewService, *repository.MemoryRepository, *mocks.MediaStore) {
+	t.Helper()
+	repo := newSeededRepo(t,
+		builders.NewTrackBuilder("user-1", "public").Public().WithPreview("previews/user-1/public.mp3").Build(),
+		builders.NewTrackBuilder("user-1", "private").WithPreview("previews/user-1/private.mp3").Build(),
+	)
+
+	keys, err := reqsign.ParseKeyring("k1:preview-secret-0123456789abcdefghij")
+	require.NoError(t, err)
+	media := mocks.NewMediaStore(t)
+	return NewPreviewService(repo, media, nil, keys), repo, media
+}
+
+func TestPreviewService_Link(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("links resolve to the clip", func(t *testing.T) {
+		svc, _, media := newPreviewService(t)
+		media.On("GeneratePresignedDownloadURL", ctx, "previews/user-1/public.mp3", previewURLExpiry).
+			Return("https://s3/previews/user-1/public.mp3?sig", nil)
+
+		link, err := svc.Link(ctx, "public")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(link.URL, "/api/v1/previews/"))
+
+		url, err := svc.Resolve(ctx, strings.TrimPrefix(link.URL, "/api/v1/previews/"))
+		require.NoError(t, err)
+		assert.Equal(t, "https://s3/previews/user-1/public.mp3?sig", url)
+	})
+
+	t.Run("private tracks have no preview", func(t *testing.T) {
+		svc, _, _ := newPreviewService(t)
+		_, err := svc.Link(ctx, "private")
+		assert.Equal(t, models.NewNotFoundError("Preview", "private"), err)
+	})
+
+	t.Run("links stop working once the track is private", func(t *testing.T) {
+		svc, repo, _ := newPreviewService(t)
+		link, err := svc.Link(ctx, "public")
+		require.NoError(t, err)
+
+		track, err := repo.GetTrackByID(ctx, "public")
+		require.NoError(t, err)
+		track.Visibility = models.VisibilityPrivate
+		require.NoError(t, repo.UpdateTrack(ctx, *track))
+
+		_, err = svc.Resolve(ctx, strings.TrimPrefix(link.URL, "/api/v1/previews/"))
+		assert.Equal(t, models.NewNotFoundError("Preview", "public"), err)
+	})
+
+	t.Run("expired links are not found", func(t *testing.T) {
+		svc, _, _ := newPreviewService(t)
+		link, err := svc.Link(ctx, "public")
+		require.NoError(t, err)
+
+		svc.now = func() time.Time { return link.ExpiresAt }
+		_, err = svc.Resolve(ctx, strings.TrimPrefix(link.URL, "/api/v1/previews/"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+)
+
+// Quick search limits
+const (
+	defaultQuickSearchLimit = 5
+	maxQuickSearchLimit     = 20
+	// quickSearchTimeout bounds the slowest per-type query; types still
+	// running when it expires are reported as failed
+	quickSearchTimeout = 3 * time.Second
+	// quickSearchMaxFollows caps how many followed users are matched by name
+	quickSearchMaxFollows = 100
+	// quickSearchTrackFloor ranks tracks the index matched on fields other
+	// than title and artist (genre, tags, album) below any name match
+	quickSearchTrackFloor = 0.1
+)
+
+// QuickSearch searches tracks, albums, artists, playlists, tags and followed
+// users at once. The types are queried in parallel, ranked together by how
+// well their names match the query, and limited per type. A type whose query
+// fails is listed in FailedTypes instead of failing the search.
+func (s *searchServiceImpl) QuickSearch(ctx context.Context, userID string, req models.QuickSearchRequest) (*models.QuickSearchResponse, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, models.NewValidationError("search query cannot be empty")
+	}
+	if len(query) > MaxQueryLength {
+		return nil, models.NewValidationError(fmt.Sprintf("search query too long (maximum %d characters)", MaxQueryLength))
+	}
+	types, err := parseQuickSearchTypes(req.Types)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultQuickSearchLimit
+	}
+	if limit > maxQuickSearchLimit {
+		limit = maxQuickSearchLimit
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, quickSearchTimeout)
+	defer cancel()
+
+	// Tracks and albums come from the same index query
+	searches := map[string]func(context.Context) ([]models.QuickSearchResult, error){}
+	if types[models.QuickSearchTrack] || types[models.QuickSearchAlbum] {
+		searches[models.QuickSearchTrack] = func(ctx context.Context) ([]models.QuickSearchResult, error) {
+			return s.quickSearchLibrary(ctx, userID, query, limit, types)
+		}
+	}
+	if types[models.QuickSearchArtist] {
+		searches[models.QuickSearchArtist] = func(ctx context.Context) ([]models.QuickSearchResult, error) {
+			return s.quickSearchArtists(ctx, userID, query, limit)
+		}
+	}
+	if types[models.QuickSearchPlaylist] {
+		searches[models.QuickSearchPlaylist] = func(ctx context.Context) ([]models.QuickSearchResult, error) {
+			return s.quickSearchPlaylists(ctx, userID, query, limit)
+		}
+	}
+	if types[models.QuickSearchTag] {
+		searches[models.QuickSearchTag] = func(ctx context.Context) ([]models.QuickSearchResult, error) {
+			return s.quickSearchTags(ctx, userID, query)
+		}
+	}
+	if types[models.QuickSearchUser] {
+		searches[models.QuickSearchUser] = func(ctx context.Context) ([]models.QuickSearchResult, error) {
+			return s.quickSearchFollowing(ctx, userID, query)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []models.QuickSearchResult
+		failed  []string
+	)
+	for kind, search := range searches {
+		wg.Add(1)
+		go func(kind string, search func(context.Context) ([]models.QuickSearchResult, error)) {
+			defer wg.Done()
+			found, err := search(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if kind == models.QuickSearchTrack {
+					// The index query serves both tracks and albums
+					failed = append(failed, quickSearchLibraryTypes(types)...)
+				} else {
+					failed = append(failed, kind)
+				}
+				return
+			}
+			results = append(results, found...)
+		}(kind, search)
+	}
+	wg.Wait()
+
+	ranked := rankQuickSearch(query, results, limit)
+	counts := make(map[string]int, len(types))
+	for kind := range types {
+		counts[kind] = 0
+	}
+	for _, result := range ranked {
+		counts[result.Type]++
+	}
+	sort.Slice(failed, func(i, j int) bool { return quickSearchTypeOrder(failed[i]) < quickSearchTypeOrder(failed[j]) })
+
+	return &models.QuickSearchResponse{
+		Query:       query,
+		Results:     ranked,
+		Counts:      counts,
+		FailedTypes: failed,
+	}, nil
+}
+
+// quickSearchLibrary queries the search index for tracks, and derives albums
+// from the matched tracks
+func (s *searchServiceImpl) quickSearchLibrary(ctx context.Context, userID, query string, limit int, types map[string]bool) ([]models.QuickSearchResult, error) {
+	libraryID, err := s.libraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Over-fetch so albums and deduplicated tracks still fill their limits
+	resp, err := s.client.Search(ctx, libraryID, searchproto.SearchQuery{Query: query, Limit: limit * 4})
+	if err != nil {
+		return nil, fmt.Errorf("track search failed: %w", err)
+	}
+	hits := deduplicateSearchResults(resp.Results)
+
+	var results []models.QuickSearchResult
+	if types[models.QuickSearchTrack] {
+		for _, hit := range hits {
+			results = append(results, models.QuickSearchResult{Type: models.QuickSearchTrack, ID: hit.ID, Title: hit.Title, Subtitle: hit.Artist})
+		}
+	}
+	if types[models.QuickSearchAlbum] {
+		results = append(results, s.quickSearchAlbums(ctx, userID, hits, limit)...)
+	}
+	return results, nil
+}
+
+// quickSearchAlbums resolves the albums of matched tracks, up to limit
+// albums. Albums that cannot be resolved are left out.
+func (s *searchServiceImpl) quickSearchAlbums(ctx context.Context, userID string, hits []searchproto.SearchResult, limit int) []models.QuickSearchResult {
+	var results []models.QuickSearchResult
+	seen := make(map[string]bool)
+	byArtist := make(map[string][]models.Album)
+	for _, hit := range hits {
+		if hit.Album == "" || len(results) >= limit {
+			continue
+		}
+		key := strings.ToLower(hit.Artist) + "\x00" + strings.ToLower(hit.Album)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		albums, ok := byArtist[hit.Artist]
+		if !ok {
+			var err error
+			albums, err = s.repo.ListAlbumsByArtist(ctx, userID, hit.Artist)
+			if err != nil {
+				continue
+			}
+			byArtist[hit.Artist] = albums
+		}
+		for _, album := range albums {
+			if strings.EqualFold(album.Title, hit.Album) {
+				results = append(results, models.QuickSearchResult{Type: models.QuickSearchAlbum, ID: album.ID, Title: album.Title, Subtitle: album.Artist})
+				break
+			}
+		}
+	}
+	return results
+}
+
+func (s *searchServiceImpl) quickSearchArtists(ctx context.Context, userID, query string, limit int) ([]models.QuickSearchResult, error) {
+	artists, err := s.repo.SearchArtists(ctx, userID, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("artist search failed: %w", err)
+	}
+	results := make([]models.QuickSearchResult, 0, len(artists))
+	for _, artist := range artists {
+		results = append(results, models.QuickSearchResult{Type: models.QuickSearchArtist, ID: artist.ID, Title: artist.Name})
+	}
+	return results, nil
+}
+
+func (s *searchServiceImpl) quickSearchPlaylists(ctx context.Context, userID, query string, limit int) ([]models.QuickSearchResult, error) {
+	playlists, err := s.repo.SearchPlaylists(ctx, userID, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("playlist search failed: %w", err)
+	}
+	results := make([]models.QuickSearchResult, 0, len(playlists))
+	for _, playlist := range playlists {
+		results = append(results, models.QuickSearchResult{Type: models.QuickSearchPlaylist, ID: playlist.ID, Title: playlist.Name})
+	}
+	return results, nil
+}
+
+func (s *searchServiceImpl) quickSearchTags(ctx context.Context, userID, query string) ([]models.QuickSearchResult, error) {
+	tags, err := s.repo.ListTags(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("tag search failed: %w", err)
+	}
+	var results []models.QuickSearchResult
+	for _, tag := range tags {
+		if quickSearchScore(query, tag.Name) > 0 {
+			results = append(results, models.QuickSearchResult{
+				Type: models.QuickSearchTag, ID: tag.Name, Title: tag.Name,
+				Subtitle: strconv.Itoa(tag.TrackCount) + " tracks",
+			})
+		}
+	}
+	return results, nil
+}
+
+// quickSearchFollowing matches the display names of the artists the user
+// follows
+func (s *searchServiceImpl) quickSearchFollowing(ctx context.Context, userID, query string) ([]models.QuickSearchResult, error) {
+	follows, err := s.repo.ListFollowing(ctx, userID, quickSearchMaxFollows, "")
+	if err != nil {
+		return nil, fmt.Errorf("following search failed: %w", err)
+	}
+	var results []models.QuickSearchResult
+	for _, follow := range follows.Items {
+		profile, err := s.repo.GetArtistProfile(ctx, follow.FollowedID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		if quickSearchScore(query, profile.DisplayName) > 0 {
+			results = append(results, models.QuickSearchResult{Type: models.QuickSearchUser, ID: profile.UserID, Title: profile.DisplayName})
+		}
+	}
+	return results, nil
+}
+
+// parseQuickSearchTypes parses a comma-separated type list into a set; an
+// empty list selects every type
+func parseQuickSearchTypes(list string) (map[string]bool, error) {
+	types := make(map[string]bool, len(models.QuickSearchTypes))
+	if strings.TrimSpace(list) == "" {
+		for _, kind := range models.QuickSearchTypes {
+			types[kind] = true
+		}
+		return types, nil
+	}
+	for _, kind := range strings.Split(list, ",") {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if quickSearchTypeOrder(kind) == len(models.QuickSearchTypes) {
+			return nil, models.NewValidationError(fmt.Sprintf("unknown search type %q", kind))
+		}
+		types[kind] = true
+	}
+	return types, nil
+}
+
+// quickSearchLibraryTypes returns the requested types served by the index
+func quickSearchLibraryTypes(types map[string]bool) []string {
+	var kinds []string
+	for _, kind := range []string{models.QuickSearchTrack, models.QuickSearchAlbum} {
+		if types[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// quickSearchTypeOrder returns a type's position in the ranking tie-break
+// order, or len(QuickSearchTypes) for unknown types
+func quickSearchTypeOrder(kind string) int {
+	for i, k := range models.QuickSearchTypes {
+		if k == kind {
+			return i
+		}
+	}
+	return len(models.QuickSearchTypes)
+}
+
+// rankQuickSearch scores results against the query, keeps the best limit of
+// each type and orders them all by score, then type, then title
+func rankQuickSearch(query string, results []models.QuickSearchResult, limit int) []models.QuickSearchResult {
+	for i := range results {
+		r := &results[i]
+		r.Score = quickSearchScore(query, r.Title)
+		if sub := quickSearchScore(query, r.Subtitle) / 2; sub > r.Score {
+			r.Score = sub
+		}
+		if r.Type == models.QuickSearchTrack && r.Score < quickSearchTrackFloor {
+			r.Score = quickSearchTrackFloor
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if oa, ob := quickSearchTypeOrder(a.Type), quickSearchTypeOrder(b.Type); oa != ob {
+			return oa < ob
+		}
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	})
+
+	ranked := make([]models.QuickSearchResult, 0, len(results))
+	perType := make(map[string]int)
+	for _, r := range results {
+		if r.Score <= 0 || perType[r.Type] >= limit {
+			continue
+		}
+		perType[r.Type]++
+		ranked = append(ranked, r)
+	}
+	return ranked
+}
+
+// quickSearchScore rates how well a name matches a query: 1 for the whole
+// name, 0.8 for a prefix, 0.6 when every query word starts a word of the
+// name, 0.4 for a substring and 0 otherwise. Case and punctuation are ignored.
+func quickSearchScore(query, name string) float64 {
+	q, n := matchKey(query), matchKey(name)
+	switch {
+	case q == "" || n == "":
+		return 0
+	case n == q:
+		return 1
+	case strings.HasPrefix(n, q):
+		return 0.8
+	}
+
+	words := strings.Fields(n)
+	allWords := true
+	for _, qw := range strings.Fields(q) {
+		found := false
+		for _, w := range words {
+			if strings.HasPrefix(w, qw) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allWords = false
+			break
+		}
+	}
+	switch {
+	case allWords:
+		return 0.6
+	case strings.Contains(n, q):
+		return 0.4
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickSearchScore(t *testing.T) {
+	tests := []struct {
+		query string
+		name  string
+		want  float64
+	}{
+		{"daft punk", "Daft Punk", 1},
+		{"daft", "Daft Punk", 0.8},
+		{"punk daft", "Daft Punk", 0.6},
+		{"pun", "Daft Punk", 0.6},
+		{"aft", "Daft Punk", 0.4},
+		{"justice", "Daft Punk", 0},
+		{"ac dc", "AC/DC", 1},
+		{"daft", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, quickSearchScore(tt.query, tt.name))
+		})
+	}
+}
+
+func TestRankQuickSearch(t *testing.T) {
+	results := []models.QuickSearchResult{
+		{Type: models.QuickSearchTrack, ID: "t1", Title: "Around the World", Subtitle: "Daft Punk"},
+		{Type: models.QuickSearchTrack, ID: "t2", Title: "Daftendirekt", Subtitle: "Daft Punk"},
+		{Type: models.QuickSearchTrack, ID: "t3", Title: "Genre Match"},
+		{Type: models.QuickSearchArtist, ID: "a1", Title: "Daft Punk"},
+		{Type: models.QuickSearchPlaylist, ID: "p1", Title: "Daft Punk Essentials"},
+		{Type: models.QuickSearchPlaylist, ID: "p2", Title: "Best of Daft Punk"},
+		{Type: models.QuickSearchTag, ID: "french house", Title: "french house"},
+	}
+
+	ranked := rankQuickSearch("daft punk", results, 2)
+
+	var ids []string
+	for _, r := range ranked {
+		ids = append(ids, r.ID)
+	}
+	// Exact artist, playlist prefix, playlist word match; tracks match only by
+	// artist (half score) and two is the per-type limit; the tag is dropped
+	assert.Equal(t, []string{"a1", "p1", "p2", "t1", "t2"}, ids)
+	assert.Equal(t, 1.0, ranked[0].Score)
+	assert.Equal(t, 0.5, ranked[3].Score)
+}
+
+func TestRankQuickSearch_TrackFloor(t *testing.T) {
+	ranked := rankQuickSearch("techno", []models.QuickSearchResult{
+		{Type: models.QuickSearchTrack, ID: "t1", Title: "Untitled"},
+		{Type: models.QuickSearchPlaylist, ID: "p1", Title: "Untitled"},
+	}, 5)
+
+	require.Len(t, ranked, 1, "index hits stay, other types need a name match")
+	assert.Equal(t, "t1", ranked[0].ID)
+	assert.Equal(t, quickSearchTrackFloor, ranked[0].Score)
+}
+
+func TestParseQuickSearchTypes(t *testing.T) {
+	all, err := parseQuickSearchTypes("")
+	require.NoError(t, err)
+	assert.Len(t, all, len(models.QuickSearchTypes))
+
+	some, err := parseQuickSearchTypes("Track, tag")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"track": true, "tag": true}, some)
+
+	_, err = parseQuickSearchTypes("track,genre")
+	var apiErr *models.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// RemoteRepository defines the repository interface for device sessions and
+// the remote commands waiting for them
+type RemoteRepository interface {
+	PutDeviceSession(ctx context.Context, session models.DeviceSession) error
+	GetDeviceSession(ctx context.Context, userID, deviceID string) (*models.DeviceSession, error)
+	ListDeviceSessions(ctx context.Context, userID string) ([]models.DeviceSession, error)
+	DeleteDeviceSession(ctx context.Context, userID, deviceID string) error
+	PutRemoteCommand(ctx context.Context, cmd models.RemoteCommand) error
+	// TakeRemoteCommands removes and returns the commands waiting for a
+	// device, oldest first
+	TakeRemoteCommands(ctx context.Context, userID, deviceID string) ([]models.RemoteCommand, error)
+}
+
+// RemoteService lets one of a user's devices control playback on another.
+// Devices register a session and keep it alive while they listen; commands
+// sent to an active device wait for it until RemoteCommandTTL passes.
+type RemoteService struct {
+	repo RemoteRepository
+	now  func() time.Time
+}
+
+// NewRemoteService creates a new remote control service
+func NewRemoteService(repo RemoteRepository) *RemoteService {
+	return &RemoteService{repo: repo, now: time.Now}
+}
+
+// RegisterDevice starts or refreshes a device's session under the name other
+// devices list it by
+func (s *RemoteService) RegisterDevice(ctx context.Context, userID, deviceID string, req models.RegisterDeviceRequest) (*models.DeviceSession, error) {
+	if err := validateDeviceID(deviceID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	session := models.DeviceSession{
+		UserID:     userID,
+		DeviceID:   deviceID,
+		Name:       req.Name,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(models.DeviceSessionTTL),
+	}
+	if err := s.repo.PutDeviceSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListDevices returns the user's active devices
+func (s *RemoteService) ListDevices(ctx context.Context, userID string) ([]models.DeviceSession, error) {
+	sessions, err := s.repo.ListDeviceSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	active := make([]models.DeviceSession, 0, len(sessions))
+	for _, session := range sessions {
+		if session.Active(now) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// DisconnectDevice ends a device's session; commands still waiting for it
+// expire unread
+func (s *RemoteService) DisconnectDevice(ctx context.Context, userID, deviceID string) error {
+	if err := validateDeviceID(deviceID); err != nil {
+		return err
+	}
+	return s.repo.DeleteDeviceSession(ctx, userID, deviceID)
+}
+
+// SendCommand queues a command for one of the user's active devices
+func (s *RemoteService) SendCommand(ctx context.Context, userID, deviceID string, req models.RemoteCommandRequest) (*models.RemoteCommand, error) {
+	if err := validateDeviceID(deviceID); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.activeDevice(ctx, userID, deviceID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	cmd := models.RemoteCommand{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		DeviceID:     deviceID,
+		Command:      req.Command,
+		FromDeviceID: req.FromDeviceID,
+		PositionMs:   req.PositionMs,
+		Volume:       req.Volume,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(models.RemoteCommandTTL),
+	}
+	if err := s.repo.PutRemoteCommand(ctx, cmd); err != nil {
+		return nil, err
+	}
+	return &cmd, nil
+}
+
+// Connect keeps a listening device's session alive. Only an active device can
+// listen; one whose session lapsed registers again.
+func (s *RemoteService) Connect(ctx context.Context, userID, deviceID string) (*models.DeviceSession, error) {
+	if err := validateDeviceID(deviceID); err != nil {
+		return nil, err
+	}
+	session, err := s.activeDevice(ctx, userID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(models.DeviceSessionTTL)
+	if err := s.repo.PutDeviceSession(ctx, *session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Receive takes the commands waiting for a device, oldest first. Commands
+// that waited past their expiry are dropped.
+func (s *RemoteService) Receive(ctx context.Context, userID, deviceID string) ([]models.RemoteCommand, error) {
+	commands, err := s.repo.TakeRemoteCommands(ctx, userID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	fresh := make([]models.RemoteCommand, 0, len(commands))
+	for _, cmd := range commands {
+		if now.Before(cmd.ExpiresAt) {
+			fresh = append(fresh, cmd)
+		}
+	}
+	return fresh, nil
+}
+
+// activeDevice returns a device's session, or not found when it has none or
+// it lapsed
+func (s *RemoteService) activeDevice(ctx context.Context, userID, deviceID string) (*models.DeviceSession, error) {
+	session, err := s.repo.GetDeviceSession(ctx, userID, deviceID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, models.NewNotFoundError("Device", deviceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !session.Active(s.now()) {
+		return nil, models.NewNotFoundError("Device", deviceID)
+	}
+	return session, nil
+}
+
+// validateDeviceID requires the UUID a client generates for itself, which
+// keeps device IDs from reaching into each other's sort keys
+func validateDeviceID(deviceID string) error {
+	if _, err := uuid.Parse(deviceID); err != nil {
+		return models.NewValidationError("deviceId must be a UUID")
+	}
+	return nil
+}
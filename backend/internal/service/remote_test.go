@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	remoteSpeaker = "aaaaaaaa-aaaa-4aaa-8aaa-aaaaaaaaaaaa"
+	remotePhone   = "bbbbbbbb-bbbb-4bbb-8bbb-bbbbbbbbbbbb"
+)
+
+func TestRemoteService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	svc := NewRemoteService(repository.NewMemoryRepository())
+	svc.now = func() time.Time { return now }
+
+	_, err := svc.RegisterDevice(ctx, "u1", remoteSpeaker, models.RegisterDeviceRequest{Name: "Kitchen speaker"})
+	require.NoError(t, err)
+	_, err = svc.RegisterDevice(ctx, "u1", remotePhone, models.RegisterDeviceRequest{Name: "Phone"})
+	require.NoError(t, err)
+
+	devices, err := svc.ListDevices(ctx, "u1")
+	require.NoError(t, err)
+	assert.Len(t, devices, 2)
+
+	t.Run("commands reach the target device once, in order", func(t *testing.T) {
+		position := int64(42000)
+		_, err := svc.SendCommand(ctx, "u1", remoteSpeaker, models.RemoteCommandRequest{Command: models.RemoteCommandPause, FromDeviceID: remotePhone})
+		require.NoError(t, err)
+		_, err = svc.SendCommand(ctx, "u1", remoteSpeaker, models.RemoteCommandRequest{Command: models.RemoteCommandSeek, PositionMs: &position})
+		require.NoError(t, err)
+
+		commands, err := svc.Receive(ctx, "u1", remoteSpeaker)
+		require.NoError(t, err)
+		require.Len(t, commands, 2)
+		assert.Equal(t, models.RemoteCommandPause, commands[0].Command)
+		assert.Equal(t, remotePhone, commands[0].FromDeviceID)
+		assert.Equal(t, position, *commands[1].PositionMs)
+
+		commands, err = svc.Receive(ctx, "u1", remoteSpeaker)
+		require.NoError(t, err)
+		assert.Empty(t, commands)
+
+		commands, err = svc.Receive(ctx, "u1", remotePhone)
+		require.NoError(t, err)
+		assert.Empty(t, commands)
+	})
+
+	t.Run("seek without a position is refused", func(t *testing.T) {
+		_, err := svc.SendCommand(ctx, "u1", remoteSpeaker, models.RemoteCommandRequest{Command: models.RemoteCommandSeek})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+	})
+
+	t.Run("another user's device is not found", func(t *testing.T) {
+		_, err := svc.SendCommand(ctx, "u2", remoteSpeaker, models.RemoteCommandRequest{Command: models.RemoteCommandPlay})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+	})
+
+	t.Run("device ids must be UUIDs", func(t *testing.T) {
+		_, err := svc.RegisterDevice(ctx, "u1", "d1#2026", models.RegisterDeviceRequest{Name: "Odd"})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+	})
+
+	t.Run("expired commands are dropped", func(t *testing.T) {
+		_, err := svc.SendCommand(ctx, "u1", remoteSpeaker, models.RemoteCommandRequest{Command: models.RemoteCommandNext})
+		require.NoError(t, err)
+
+		now = now.Add(models.RemoteCommandTTL)
+		commands, err := svc.Receive(ctx, "u1", remoteSpeaker)
+		require.NoError(t, err)
+		assert.Empty(t, commands)
+	})
+
+	t.Run("listening keeps a session alive", func(t *testing.T) {
+		_, err := svc.Connect(ctx, "u1", remoteSpeaker)
+		require.NoError(t, err)
+
+		now = now.Add(models.DeviceSessionTTL - time.Second)
+		devices, err := svc.ListDevices(ctx, "u1")
+		require.NoError(t, err)
+		require.Len(t, devices, 1)
+		assert.Equal(t, remoteSpeaker, devices[0].DeviceID)
+
+		_, err = svc.SendCommand(ctx, "u1", remotePhone, models.RemoteCommandRequest{Command: models.RemoteCommandPlay})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+		_, err = svc.Connect(ctx, "u1", remotePhone)
+		require.ErrorAs(t, err, &apiErr)
+	})
+
+	t.Run("a disconnected device is not listed", func(t *testing.T) {
+		require.NoError(t, svc.DisconnectDevice(ctx, "u1", remoteSpeaker))
+		devices, err := svc.ListDevices(ctx, "u1")
+		require.NoError(t, err)
+		assert.Empty(t, devices)
+	})
+}
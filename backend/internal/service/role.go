@@ -24,7 +24,8 @@ type RoleService interface {
 }
 
 type roleService struct {
-	repo RoleRepository
+	repo  RoleRepository
+	users *UserCache
 }
 
 // NewRoleService creates a new RoleService.
@@ -32,9 +33,19 @@ func NewRoleService(repo RoleRepository) RoleService {
 	return &roleService{repo: repo}
 }
 
+// SetUserCache makes role lookups go through cache, and role changes evict
+// the user from it
+func (s *roleService) SetUserCache(cache *UserCache) {
+	s.users = cache
+}
+
 // GetUserRole retrieves the role for a user.
 func (s *roleService) GetUserRole(ctx context.Context, userID string) (models.UserRole, error) {
-	user, err := s.repo.GetUser(ctx, userID)
+	getUser := s.repo.GetUser
+	if s.users != nil {
+		getUser = s.users.GetUser
+	}
+	user, err := getUser(ctx, userID)
 	if err != nil {
 		if err == repository.ErrNotFound {
 			return "", models.NewNotFoundError("user", userID)
@@ -58,6 +69,9 @@ func (s *roleService) SetUserRole(ctx context.Context, userID string, role model
 	}
 
 	err := s.repo.UpdateUserRole(ctx, userID, role)
+	if s.users != nil {
+		s.users.Invalidate(ctx, userID)
+	}
 	if err != nil {
 		if err == repository.ErrNotFound {
 			return models.NewNotFoundError("user", userID)
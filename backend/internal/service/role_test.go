@@ -6,40 +6,14 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockRoleRepository is a mock implementation for role operations
-type MockRoleRepository struct {
-	mock.Mock
-}
-
-func (m *MockRoleRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.User), args.Error(1)
-}
-
-func (m *MockRoleRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	args := m.Called(ctx, userID, role)
-	return args.Error(0)
-}
-
-func (m *MockRoleRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
-	args := m.Called(ctx, role, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.User]), args.Error(1)
-}
-
 func TestNewRoleService(t *testing.T) {
 	t.Run("creates service with repository", func(t *testing.T) {
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 		svc := NewRoleService(mockRepo)
 		require.NotNil(t, svc)
 	})
@@ -48,7 +22,7 @@ func TestNewRoleService(t *testing.T) {
 func TestRoleService_GetUserRole(t *testing.T) {
 	t.Run("returns user role", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -67,7 +41,7 @@ func TestRoleService_GetUserRole(t *testing.T) {
 
 	t.Run("returns error when user not found", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("GetUser", ctx, "nonexistent").Return((*models.User)(nil), repository.ErrNotFound)
 
@@ -80,7 +54,7 @@ func TestRoleService_GetUserRole(t *testing.T) {
 
 	t.Run("returns default role for user without role", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -100,7 +74,7 @@ func TestRoleService_GetUserRole(t *testing.T) {
 func TestRoleService_SetUserRole(t *testing.T) {
 	t.Run("updates user role", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("UpdateUserRole", ctx, "user-123", models.RoleArtist).Return(nil)
 
@@ -113,7 +87,7 @@ func TestRoleService_SetUserRole(t *testing.T) {
 
 	t.Run("returns error for invalid role", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		svc := NewRoleService(mockRepo)
 		err := svc.SetUserRole(ctx, "user-123", models.UserRole("invalid"))
@@ -124,7 +98,7 @@ func TestRoleService_SetUserRole(t *testing.T) {
 
 	t.Run("returns error when user not found", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		mockRepo.On("UpdateUserRole", ctx, "nonexistent", models.RoleSubscriber).Return(repository.ErrNotFound)
 
@@ -138,7 +112,7 @@ func TestRoleService_SetUserRole(t *testing.T) {
 func TestRoleService_HasPermission(t *testing.T) {
 	t.Run("subscriber has listen permission", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -156,7 +130,7 @@ func TestRoleService_HasPermission(t *testing.T) {
 
 	t.Run("subscriber does not have publish permission", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -174,7 +148,7 @@ func TestRoleService_HasPermission(t *testing.T) {
 
 	t.Run("artist has publish permission", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -192,7 +166,7 @@ func TestRoleService_HasPermission(t *testing.T) {
 
 	t.Run("admin has all permissions", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -210,7 +184,7 @@ func TestRoleService_HasPermission(t *testing.T) {
 
 	t.Run("guest has limited permissions", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		user := &models.User{
 			ID:   "user-123",
@@ -237,7 +211,7 @@ func TestRoleService_HasPermission(t *testing.T) {
 func TestRoleService_ListUsersByRole(t *testing.T) {
 	t.Run("lists users by role", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		users := &repository.PaginatedResult[models.User]{
 			Items: []models.User{
@@ -259,7 +233,7 @@ func TestRoleService_ListUsersByRole(t *testing.T) {
 
 	t.Run("handles pagination", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockRoleRepository)
+		mockRepo := new(mocks.Repository)
 
 		users := &repository.PaginatedResult[models.User]{
 			Items: []models.User{
@@ -2,13 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/resilience"
 	"github.com/gvasels/personal-music-searchengine/internal/search"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
 )
 
 // Search query validation limits
@@ -20,11 +24,23 @@ const (
 type searchServiceImpl struct {
 	client *search.Client
 	repo   repository.Repository
-	s3Repo repository.S3Repository
+	s3Repo repository.MediaStore
+	// boosts applies personal pins and artist boosts; nil applies none
+	boosts *SearchBoostService
+	// reindex bounds concurrent index rebuilds; nil admits every rebuild
+	reindex *resilience.Limiter
+	// availability describes hydrated tracks' playback options; nil leaves them out
+	availability *PlaybackAvailabilityService
+}
+
+// ReindexLimitAware is implemented by search services whose index rebuilds
+// take a slot of a concurrency limiter
+type ReindexLimitAware interface {
+	SetReindexLimiter(limiter *resilience.Limiter)
 }
 
 // NewSearchService creates a new search service.
-func NewSearchService(client *search.Client, repo repository.Repository, s3Repo repository.S3Repository) SearchService {
+func NewSearchService(client *search.Client, repo repository.Repository, s3Repo repository.MediaStore) SearchService {
 	return &searchServiceImpl{
 		client: client,
 		repo:   repo,
@@ -32,6 +48,25 @@ func NewSearchService(client *search.Client, repo repository.Repository, s3Repo
 	}
 }
 
+// SetReindexLimiter bounds how many index rebuilds run at once, since each
+// reads a whole library from the table
+func (s *searchServiceImpl) SetReindexLimiter(limiter *resilience.Limiter) {
+	s.reindex = limiter
+}
+
+// SetPlaybackAvailability describes the playback options of hydrated results
+func (s *searchServiceImpl) SetPlaybackAvailability(availability *PlaybackAvailabilityService) {
+	s.availability = availability
+}
+
+// libraryID returns the library partition indexed for the user's tracks.
+func (s *searchServiceImpl) libraryID(ctx context.Context, userID string) (string, error) {
+	if scoped, ok := s.repo.(LibraryIDResolver); ok {
+		return scoped.ResolveLibraryID(ctx, userID)
+	}
+	return userID, nil
+}
+
 // Search executes a search query scoped to the user.
 func (s *searchServiceImpl) Search(ctx context.Context, userID string, req models.SearchRequest) (*models.SearchResponse, error) {
 	if req.Query == "" {
@@ -49,8 +84,8 @@ func (s *searchServiceImpl) Search(ctx context.Context, userID string, req model
 		limit = 100
 	}
 
-	// Convert models.SearchRequest to search.SearchQuery
-	searchQuery := search.SearchQuery{
+	// Convert models.SearchRequest to searchproto.SearchQuery
+	searchQuery := searchproto.SearchQuery{
 		Query:  req.Query,
 		Limit:  limit,
 		Cursor: req.Cursor,
@@ -61,16 +96,31 @@ func (s *searchServiceImpl) Search(ctx context.Context, userID string, req model
 
 	// Convert sort
 	if req.Sort.Field != "" {
-		searchQuery.Sort = &search.SortOption{
+		searchQuery.Sort = &searchproto.SortOption{
 			Field: req.Sort.Field,
 			Order: req.Sort.Order,
 		}
 	}
 
-	// Execute search
-	resp, err := s.client.Search(ctx, userID, searchQuery)
+	// Execute search against the user's library (shared for household members)
+	libraryID, err := s.libraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Search(ctx, libraryID, searchQuery)
+	degraded := false
 	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
+		// Nobody is waiting for the answer when the caller went away
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+		// Answer from the library itself while the index is unavailable
+		fmt.Printf("Warning: search index unavailable, scanning the library: %v\n", err)
+		resp, err = s.fallbackSearch(ctx, userID, libraryID, req, limit)
+		if err != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+		degraded = true
 	}
 
 	// Deduplicate results by track ID (in case same track was indexed multiple times)
@@ -84,6 +134,9 @@ func (s *searchServiceImpl) Search(ctx context.Context, userID string, req model
 		}
 	}
 
+	// Apply the user's pinned results and artist boosts
+	results = s.applyBoosts(ctx, userID, req, results)
+
 	// Convert to API response
 	tracks := make([]models.TrackResponse, 0, len(results))
 	for _, result := range results {
@@ -91,8 +144,13 @@ func (s *searchServiceImpl) Search(ctx context.Context, userID string, req model
 		tracks = append(tracks, track)
 	}
 
-	// Enrich with cover art URLs
-	s.enrichTracksWithCoverArt(ctx, userID, tracks)
+	// Enrich with cover art URLs, or replace the index fields with the full
+	// stored tracks when the caller asked for hydrated results
+	if req.Hydrate {
+		tracks = s.hydrateTracks(ctx, userID, tracks)
+	} else {
+		s.enrichTracksWithCoverArt(ctx, userID, tracks)
+	}
 
 	// Search playlists by name
 	playlistResults, err := s.repo.SearchPlaylists(ctx, userID, req.Query, 5)
@@ -126,6 +184,7 @@ func (s *searchServiceImpl) Search(ctx context.Context, userID string, req model
 		Limit:        limit,
 		NextCursor:   resp.NextCursor,
 		HasMore:      hasMore,
+		Degraded:     degraded,
 	}, nil
 }
 
@@ -139,12 +198,16 @@ func (s *searchServiceImpl) Autocomplete(ctx context.Context, userID, query stri
 	}
 
 	// Execute a limited search for autocomplete
-	searchQuery := search.SearchQuery{
+	searchQuery := searchproto.SearchQuery{
 		Query: query,
 		Limit: 10,
 	}
 
-	resp, err := s.client.Search(ctx, userID, searchQuery)
+	libraryID, err := s.libraryID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Search(ctx, libraryID, searchQuery)
 	if err != nil {
 		return nil, fmt.Errorf("autocomplete failed: %w", err)
 	}
@@ -216,20 +279,7 @@ func (s *searchServiceImpl) Autocomplete(ctx context.Context, userID, query stri
 
 // IndexTrack indexes a track in the search engine.
 func (s *searchServiceImpl) IndexTrack(ctx context.Context, track models.Track) error {
-	doc := search.Document{
-		ID:        track.ID,
-		UserID:    track.UserID,
-		Title:     track.Title,
-		Artist:    track.Artist,
-		Album:     track.Album,
-		Genre:     track.Genre,
-		Year:      track.Year,
-		Duration:  track.Duration,
-		Filename:  track.S3Key,
-		IndexedAt: time.Now(),
-	}
-
-	resp, err := s.client.Index(ctx, doc)
+	resp, err := s.client.Index(ctx, trackDocument(track))
 	if err != nil {
 		return fmt.Errorf("failed to index track %s: %w", track.ID, err)
 	}
@@ -241,6 +291,27 @@ func (s *searchServiceImpl) IndexTrack(ctx context.Context, track models.Track)
 	return nil
 }
 
+// trackDocument converts a track to its search document. The status time is
+// the track's last update, the latest its HLS status can have changed.
+func trackDocument(track models.Track) searchproto.Document {
+	return searchproto.Document{
+		ID:              track.ID,
+		UserID:          track.UserID,
+		Title:           track.Title,
+		Artist:          track.Artist,
+		Album:           track.Album,
+		Genre:           track.Genre,
+		Year:            track.Year,
+		Duration:        track.Duration,
+		Bitrate:         track.Bitrate,
+		Filename:        track.S3Key,
+		IndexedAt:       time.Now(),
+		HLSStatus:       string(track.HLSStatus),
+		Processing:      track.HLSStatus.InProgress(),
+		StatusUpdatedAt: &track.UpdatedAt,
+	}
+}
+
 // RemoveTrack removes a track from the search index.
 func (s *searchServiceImpl) RemoveTrack(ctx context.Context, trackID string) error {
 	resp, err := s.client.Delete(ctx, trackID)
@@ -256,8 +327,109 @@ func (s *searchServiceImpl) RemoveTrack(ctx context.Context, trackID string) err
 	return nil
 }
 
+// IndexStats reports the document counts and size of the search index.
+func (s *searchServiceImpl) IndexStats(ctx context.Context) (*models.SearchIndexStatsResponse, error) {
+	stats, err := s.client.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]models.SearchIndexUserCount, len(stats.Users))
+	for i, user := range stats.Users {
+		users[i] = models.SearchIndexUserCount{UserID: user.UserID, Documents: user.Documents}
+	}
+	return &models.SearchIndexStatsResponse{
+		Documents:        stats.Documents,
+		IndexBytes:       stats.IndexBytes,
+		Users:            users,
+		UpdatedAt:        stats.UpdatedAt,
+		LastCompactionAt: stats.LastCompactionAt,
+		LastSyncAt:       stats.LastSyncAt,
+	}, nil
+}
+
+// StuckTranscodes lists tracks of all libraries whose transcode has been
+// pending or running for longer than olderThan, oldest first.
+func (s *searchServiceImpl) StuckTranscodes(ctx context.Context, olderThan time.Duration) (*models.StuckTranscodesResponse, error) {
+	processing := true
+	before := time.Now().Add(-olderThan)
+	// An empty query matches every document; no user ID searches all libraries
+	resp, err := s.client.Search(ctx, "", searchproto.SearchQuery{
+		Filters: searchproto.SearchFilters{Processing: &processing, StatusUpdatedBefore: &before},
+		Limit:   100,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.StuckTranscode, len(resp.Results))
+	for i, result := range resp.Results {
+		items[i] = models.StuckTranscode{
+			TrackID:         result.ID,
+			UserID:          result.UserID,
+			Title:           result.Title,
+			Artist:          result.Artist,
+			HLSStatus:       models.HLSStatus(result.HLSStatus),
+			StatusUpdatedAt: result.StatusUpdatedAt,
+		}
+	}
+	// The status filter only matches documents with a status time
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].StatusUpdatedAt.Before(*items[j].StatusUpdatedAt)
+	})
+	return &models.StuckTranscodesResponse{Items: items, Total: resp.Total, OlderThan: olderThan.String()}, nil
+}
+
+// ExportIndex writes the search index, or one library's documents, to a new
+// export file in the search index bucket.
+func (s *searchServiceImpl) ExportIndex(ctx context.Context, req models.ExportSearchIndexRequest) (*models.SearchIndexExportResponse, error) {
+	resp, err := s.client.ExportIndex(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SearchIndexExportResponse{
+		Key:        resp.Key,
+		UserID:     resp.UserID,
+		Documents:  resp.Documents,
+		Bytes:      resp.Bytes,
+		ExportedAt: resp.ExportedAt,
+	}, nil
+}
+
+// ImportIndex loads an export file from the search index bucket into the
+// index. The file may come from another deployment; its documents keep their
+// user IDs, so they only match libraries whose IDs were carried over too.
+func (s *searchServiceImpl) ImportIndex(ctx context.Context, req models.ImportSearchIndexRequest) (*models.SearchIndexImportResponse, error) {
+	if err := searchproto.ValidateExportKey(req.Key); err != nil {
+		return nil, models.NewValidationError(err.Error())
+	}
+
+	resp, err := s.client.ImportIndex(ctx, searchproto.ImportRequest{Key: req.Key, UserID: req.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	failures := make([]models.SearchIndexImportFailure, len(resp.Failures))
+	for i, failure := range resp.Failures {
+		failures[i] = models.SearchIndexImportFailure{Line: failure.Line, ID: failure.ID, Error: failure.Error}
+	}
+	return &models.SearchIndexImportResponse{
+		Key:      resp.Key,
+		Imported: resp.Imported,
+		Skipped:  resp.Skipped,
+		Failed:   resp.Failed,
+		Failures: failures,
+	}, nil
+}
+
 // RebuildIndex rebuilds the entire search index for a user.
 func (s *searchServiceImpl) RebuildIndex(ctx context.Context, userID string) error {
+	release, err := s.reindex.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// Collect all tracks for the user using pagination
 	var allTracks []models.Track
 	cursor := ""
@@ -286,20 +458,9 @@ func (s *searchServiceImpl) RebuildIndex(ctx context.Context, userID string) err
 	}
 
 	// Convert tracks to documents
-	docs := make([]search.Document, len(allTracks))
+	docs := make([]searchproto.Document, len(allTracks))
 	for i, track := range allTracks {
-		docs[i] = search.Document{
-			ID:        track.ID,
-			UserID:    track.UserID,
-			Title:     track.Title,
-			Artist:    track.Artist,
-			Album:     track.Album,
-			Genre:     track.Genre,
-			Year:      track.Year,
-			Duration:  track.Duration,
-			Filename:  track.S3Key,
-			IndexedAt: time.Now(),
-		}
+		docs[i] = trackDocument(track)
 	}
 
 	// Bulk index in batches of 100
@@ -316,17 +477,19 @@ func (s *searchServiceImpl) RebuildIndex(ctx context.Context, userID string) err
 			return fmt.Errorf("bulk index failed at batch %d: %w", i/batchSize, err)
 		}
 
-		if resp.Failed > 0 {
-			fmt.Printf("Warning: %d documents failed to index in batch %d\n", resp.Failed, i/batchSize)
+		// The other documents of the batch are indexed; failures are data
+		// problems a resend would not fix
+		for _, failure := range resp.Failures {
+			fmt.Printf("Warning: track %s not indexed (batch %d, document %d): %s\n", failure.ID, i/batchSize, failure.Index, failure.Error)
 		}
 	}
 
 	return nil
 }
 
-// convertFilters converts models.SearchFilters to search.SearchFilters.
-func (s *searchServiceImpl) convertFilters(filters models.SearchFilters) search.SearchFilters {
-	result := search.SearchFilters{}
+// convertFilters converts models.SearchFilters to searchproto.SearchFilters.
+func (s *searchServiceImpl) convertFilters(filters models.SearchFilters) searchproto.SearchFilters {
+	result := searchproto.SearchFilters{}
 
 	// Use first artist if provided
 	if len(filters.Artists) > 0 {
@@ -358,13 +521,17 @@ func (s *searchServiceImpl) convertFilters(filters models.SearchFilters) search.
 		result.YearTo = maxYear
 	}
 
+	result.DurationFrom, result.DurationTo = filters.DurationFrom, filters.DurationTo
+	result.BitrateFrom, result.BitrateTo = filters.BitrateFrom, filters.BitrateTo
+	result.HLSStatus, result.Processing = string(filters.HLSStatus), filters.Processing
+
 	return result
 }
 
 // filterByTags filters search results to only include tracks that have ALL specified tags.
 // Returns an error if any tag does not exist.
 // Tags are deduplicated and normalized to lowercase.
-func (s *searchServiceImpl) filterByTags(ctx context.Context, userID string, results []search.SearchResult, tags []string) ([]search.SearchResult, error) {
+func (s *searchServiceImpl) filterByTags(ctx context.Context, userID string, results []searchproto.SearchResult, tags []string) ([]searchproto.SearchResult, error) {
 	if len(tags) == 0 {
 		return results, nil
 	}
@@ -424,12 +591,12 @@ func (s *searchServiceImpl) filterByTags(ctx context.Context, userID string, res
 
 		// Early exit if no tracks match
 		if len(validTrackIDs) == 0 {
-			return []search.SearchResult{}, nil
+			return []searchproto.SearchResult{}, nil
 		}
 	}
 
 	// Filter results to only include tracks with all tags
-	filtered := make([]search.SearchResult, 0, len(results))
+	filtered := make([]searchproto.SearchResult, 0, len(results))
 	for _, result := range results {
 		if validTrackIDs[result.ID] {
 			filtered = append(filtered, result)
@@ -440,7 +607,7 @@ func (s *searchServiceImpl) filterByTags(ctx context.Context, userID string, res
 }
 
 // searchResultToTrackResponse converts a search result to a track response.
-func (s *searchServiceImpl) searchResultToTrackResponse(result search.SearchResult) models.TrackResponse {
+func (s *searchServiceImpl) searchResultToTrackResponse(result searchproto.SearchResult) models.TrackResponse {
 	return models.TrackResponse{
 		ID:          result.ID,
 		Title:       result.Title,
@@ -453,6 +620,19 @@ func (s *searchServiceImpl) searchResultToTrackResponse(result search.SearchResu
 	}
 }
 
+// trackSearchResult builds a search result from a stored track
+func trackSearchResult(track models.Track) searchproto.SearchResult {
+	return searchproto.SearchResult{
+		ID:       track.ID,
+		Title:    track.Title,
+		Artist:   track.Artist,
+		Album:    track.Album,
+		Genre:    track.Genre,
+		Year:     track.Year,
+		Duration: track.Duration,
+	}
+}
+
 // enrichTracksWithCoverArt adds cover art URLs to track responses.
 func (s *searchServiceImpl) enrichTracksWithCoverArt(ctx context.Context, userID string, tracks []models.TrackResponse) {
 	for i := range tracks {
@@ -469,6 +649,81 @@ func (s *searchServiceImpl) enrichTracksWithCoverArt(ctx context.Context, userID
 	}
 }
 
+// TrackBatchGetter reads several tracks in one call. The DynamoDB, in-memory
+// and library-scoped repositories implement it; hydration falls back to one
+// GetTrack per result otherwise.
+type TrackBatchGetter interface {
+	BatchGetTracks(ctx context.Context, userID string, trackIDs []string) (map[string]models.Track, error)
+}
+
+// hydrateTracks replaces search results with the stored tracks, keeping the
+// search order, and attaches presigned cover URLs (one per distinct cover, as
+// the tracks of an album usually share theirs). Results whose track is gone
+// from the library keep their index fields.
+func (s *searchServiceImpl) hydrateTracks(ctx context.Context, userID string, tracks []models.TrackResponse) []models.TrackResponse {
+	if len(tracks) == 0 {
+		return tracks
+	}
+
+	trackIDs := make([]string, len(tracks))
+	for i, track := range tracks {
+		trackIDs[i] = track.ID
+	}
+
+	stored, err := s.batchGetTracks(ctx, userID, trackIDs)
+	if err != nil {
+		fmt.Printf("Warning: search hydration failed: %v\n", err)
+		s.enrichTracksWithCoverArt(ctx, userID, tracks)
+		return tracks
+	}
+
+	saveData := dataSaver(ctx, s.repo, userID)
+	coverURLs := make(map[string]string)
+	hydrated := make([]models.TrackResponse, len(tracks))
+	for i, result := range tracks {
+		track, ok := stored[result.ID]
+		if !ok {
+			hydrated[i] = result
+			continue
+		}
+
+		coverKey := track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData)
+		coverURL, seen := coverURLs[coverKey]
+		if !seen && coverKey != "" && s.s3Repo != nil {
+			if url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, coverKey, 24*time.Hour); err == nil {
+				coverURL = url
+			}
+			coverURLs[coverKey] = coverURL
+		}
+		hydrated[i] = s.availability.TrackResponse(track, coverURL)
+	}
+	return hydrated
+}
+
+// batchGetTracks loads tracks through the repository's TrackBatchGetter when
+// it has one, and one by one otherwise. Missing tracks are left out.
+func (s *searchServiceImpl) batchGetTracks(ctx context.Context, userID string, trackIDs []string) (map[string]models.Track, error) {
+	if getter, ok := s.repo.(TrackBatchGetter); ok {
+		tracks, err := getter.BatchGetTracks(ctx, userID, trackIDs)
+		if !errors.Is(err, repository.ErrNotFound) {
+			return tracks, err
+		}
+	}
+
+	tracks := make(map[string]models.Track, len(trackIDs))
+	for _, trackID := range trackIDs {
+		track, err := s.repo.GetTrack(ctx, userID, trackID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tracks[trackID] = *track
+	}
+	return tracks, nil
+}
+
 // formatDuration formats seconds as "M:SS".
 func formatDuration(seconds int) string {
 	if seconds <= 0 {
@@ -481,9 +736,9 @@ func formatDuration(seconds int) string {
 
 // deduplicateSearchResults removes duplicate tracks from search results (by ID).
 // This handles cases where the same track was indexed multiple times.
-func deduplicateSearchResults(results []search.SearchResult) []search.SearchResult {
+func deduplicateSearchResults(results []searchproto.SearchResult) []searchproto.SearchResult {
 	seen := make(map[string]bool)
-	deduplicated := make([]search.SearchResult, 0, len(results))
+	deduplicated := make([]searchproto.SearchResult, 0, len(results))
 	for _, result := range results {
 		if !seen[result.ID] {
 			seen[result.ID] = true
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+)
+
+// SearchBoostRepository defines the repository interface for search boosts
+type SearchBoostRepository interface {
+	GetSearchBoosts(ctx context.Context, userID string) (*models.SearchBoosts, error)
+	PutSearchBoosts(ctx context.Context, boosts models.SearchBoosts) error
+}
+
+// SearchBoostAware is implemented by search services that apply personal
+// pins and artist boosts to their results
+type SearchBoostAware interface {
+	SetBoosts(boosts *SearchBoostService)
+}
+
+// SearchBoostService manages each user's pinned results and artist boosts
+type SearchBoostService struct {
+	repo SearchBoostRepository
+	now  func() time.Time
+}
+
+// NewSearchBoostService creates a new search boost service
+func NewSearchBoostService(repo SearchBoostRepository) *SearchBoostService {
+	return &SearchBoostService{repo: repo, now: time.Now}
+}
+
+// Get returns the user's boost rules; users without any get empty rules
+func (s *SearchBoostService) Get(ctx context.Context, userID string) (*models.SearchBoosts, error) {
+	boosts, err := s.repo.GetSearchBoosts(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &models.SearchBoosts{UserID: userID, Pins: []models.SearchPin{}, ArtistBoosts: []models.ArtistBoost{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return boosts, nil
+}
+
+// PinTrack puts the track at the top of the user's results for the query.
+// Pinning it again moves it ahead of the query's other pins.
+func (s *SearchBoostService) PinTrack(ctx context.Context, userID string, req models.PinSearchResultRequest) (*models.SearchBoosts, error) {
+	query := models.NormalizeSearchQuery(req.Query)
+	if query == "" {
+		return nil, models.NewValidationError("query cannot be blank")
+	}
+
+	return s.update(ctx, userID, func(boosts *models.SearchBoosts) error {
+		pins := []models.SearchPin{{Query: query, TrackID: req.TrackID, CreatedAt: s.now()}}
+		for _, pin := range boosts.Pins {
+			if pin.Query != query || pin.TrackID != req.TrackID {
+				pins = append(pins, pin)
+			}
+		}
+		if len(pins) > models.MaxSearchPins {
+			return models.NewValidationError(fmt.Sprintf("maximum number of pinned results (%d) reached", models.MaxSearchPins))
+		}
+		boosts.Pins = pins
+		return nil
+	})
+}
+
+// UnpinTrack removes a pin; removing a pin that does not exist succeeds
+func (s *SearchBoostService) UnpinTrack(ctx context.Context, userID, query, trackID string) error {
+	query = models.NormalizeSearchQuery(query)
+	_, err := s.update(ctx, userID, func(boosts *models.SearchBoosts) error {
+		pins := boosts.Pins[:0:0]
+		for _, pin := range boosts.Pins {
+			if pin.Query != query || pin.TrackID != trackID {
+				pins = append(pins, pin)
+			}
+		}
+		boosts.Pins = pins
+		return nil
+	})
+	return err
+}
+
+// SetArtistBoost sets the weight of an artist's tracks in the user's ranking
+func (s *SearchBoostService) SetArtistBoost(ctx context.Context, userID string, req models.SetArtistBoostRequest) (*models.SearchBoosts, error) {
+	artist := strings.TrimSpace(req.Artist)
+	if artist == "" {
+		return nil, models.NewValidationError("artist cannot be blank")
+	}
+
+	return s.update(ctx, userID, func(boosts *models.SearchBoosts) error {
+		for i := range boosts.ArtistBoosts {
+			if strings.EqualFold(boosts.ArtistBoosts[i].Artist, artist) {
+				boosts.ArtistBoosts[i] = models.ArtistBoost{Artist: artist, Weight: req.Weight}
+				return nil
+			}
+		}
+		if len(boosts.ArtistBoosts) >= models.MaxArtistBoosts {
+			return models.NewValidationError(fmt.Sprintf("maximum number of artist boosts (%d) reached", models.MaxArtistBoosts))
+		}
+		boosts.ArtistBoosts = append(boosts.ArtistBoosts, models.ArtistBoost{Artist: artist, Weight: req.Weight})
+		return nil
+	})
+}
+
+// RemoveArtistBoost removes the boost of an artist (ignoring case)
+func (s *SearchBoostService) RemoveArtistBoost(ctx context.Context, userID, artist string) error {
+	_, err := s.update(ctx, userID, func(boosts *models.SearchBoosts) error {
+		kept := boosts.ArtistBoosts[:0:0]
+		for _, boost := range boosts.ArtistBoosts {
+			if !strings.EqualFold(boost.Artist, strings.TrimSpace(artist)) {
+				kept = append(kept, boost)
+			}
+		}
+		boosts.ArtistBoosts = kept
+		return nil
+	})
+	return err
+}
+
+// update applies change to the user's rules and saves them
+func (s *SearchBoostService) update(ctx context.Context, userID string, change func(boosts *models.SearchBoosts) error) (*models.SearchBoosts, error) {
+	boosts, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// The stored lists may be shared with the repository's copy
+	boosts.Pins = slices.Clone(boosts.Pins)
+	boosts.ArtistBoosts = slices.Clone(boosts.ArtistBoosts)
+	if err := change(boosts); err != nil {
+		return nil, err
+	}
+	boosts.UpdatedAt = s.now()
+	if err := s.repo.PutSearchBoosts(ctx, *boosts); err != nil {
+		return nil, err
+	}
+	return boosts, nil
+}
+
+// SetBoosts makes Search apply the users' pins and artist boosts
+func (s *searchServiceImpl) SetBoosts(boosts *SearchBoostService) {
+	s.boosts = boosts
+}
+
+// applyBoosts reorders results by the user's rules. Artist boosts rescale
+// relevance scores, so they are skipped when the request sorts by a field.
+// Pinned tracks lead the first page, loaded from the library when the index
+// did not return them, and are left out of later pages. Boosts are optional:
+// when they cannot be read the results are returned unchanged.
+func (s *searchServiceImpl) applyBoosts(ctx context.Context, userID string, req models.SearchRequest, results []searchproto.SearchResult) []searchproto.SearchResult {
+	if s.boosts == nil {
+		return results
+	}
+	boosts, err := s.boosts.Get(ctx, userID)
+	if err != nil {
+		fmt.Printf("Warning: failed to load search boosts for %s: %v\n", userID, err)
+		return results
+	}
+
+	if req.Sort.Field == "" || req.Sort.Field == "relevance" {
+		results = boostArtists(results, boosts)
+	}
+	pinned := boosts.PinnedTrackIDs(req.Query)
+	if len(pinned) == 0 {
+		return results
+	}
+	if req.Cursor != "" {
+		return withoutTracks(results, pinned)
+	}
+
+	found := make(map[string]searchproto.SearchResult, len(results))
+	for _, result := range results {
+		found[result.ID] = result
+	}
+	var missing []string
+	for _, trackID := range pinned {
+		if _, ok := found[trackID]; !ok {
+			missing = append(missing, trackID)
+		}
+	}
+	if len(missing) > 0 {
+		stored, err := s.batchGetTracks(ctx, userID, missing)
+		if err != nil {
+			fmt.Printf("Warning: failed to load pinned tracks for %s: %v\n", userID, err)
+		}
+		for trackID, track := range stored {
+			found[trackID] = trackSearchResult(track)
+		}
+	}
+
+	ordered := make([]searchproto.SearchResult, 0, len(results)+len(missing))
+	for _, trackID := range pinned {
+		if result, ok := found[trackID]; ok {
+			ordered = append(ordered, result)
+		}
+	}
+	return append(ordered, withoutTracks(results, pinned)...)
+}
+
+// boostArtists rescales each result's score by its artist's weight and
+// re-sorts by score, keeping the index order among equal scores
+func boostArtists(results []searchproto.SearchResult, boosts *models.SearchBoosts) []searchproto.SearchResult {
+	if len(boosts.ArtistBoosts) == 0 {
+		return results
+	}
+	boosted := make([]searchproto.SearchResult, len(results))
+	for i, result := range results {
+		result.Score *= boosts.ArtistWeight(result.Artist)
+		boosted[i] = result
+	}
+	sort.SliceStable(boosted, func(i, j int) bool { return boosted[i].Score > boosted[j].Score })
+	return boosted
+}
+
+// withoutTracks drops the results of the given tracks
+func withoutTracks(results []searchproto.SearchResult, trackIDs []string) []searchproto.SearchResult {
+	drop := make(map[string]bool, len(trackIDs))
+	for _, trackID := range trackIDs {
+		drop[trackID] = true
+	}
+	kept := make([]searchproto.SearchResult, 0, len(results))
+	for _, result := range results {
+		if !drop[result.ID] {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchBoostService(t *testing.T) {
+	ctx := context.Background()
+	svc := NewSearchBoostService(repository.NewMemoryRepository())
+
+	boosts, err := svc.Get(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, boosts.Pins)
+
+	_, err = svc.PinTrack(ctx, "u1", models.PinSearchResultRequest{Query: "Night  Bus", TrackID: "t1"})
+	require.NoError(t, err)
+	_, err = svc.PinTrack(ctx, "u1", models.PinSearchResultRequest{Query: "night bus", TrackID: "t2"})
+	require.NoError(t, err)
+	boosts, err = svc.PinTrack(ctx, "u1", models.PinSearchResultRequest{Query: "NIGHT BUS", TrackID: "t1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2"}, boosts.PinnedTrackIDs(" night bus "), "pinning again moves the track first")
+
+	_, err = svc.PinTrack(ctx, "u1", models.PinSearchResultRequest{Query: "  ", TrackID: "t1"})
+	assert.Error(t, err)
+
+	_, err = svc.SetArtistBoost(ctx, "u1", models.SetArtistBoostRequest{Artist: "Burial", Weight: 2})
+	require.NoError(t, err)
+	boosts, err = svc.SetArtistBoost(ctx, "u1", models.SetArtistBoostRequest{Artist: "burial", Weight: 3})
+	require.NoError(t, err)
+	assert.Len(t, boosts.ArtistBoosts, 1)
+	assert.Equal(t, 3.0, boosts.ArtistWeight("BURIAL"))
+	assert.Equal(t, 1.0, boosts.ArtistWeight("Kode9"))
+
+	require.NoError(t, svc.UnpinTrack(ctx, "u1", "night bus", "t1"))
+	require.NoError(t, svc.RemoveArtistBoost(ctx, "u1", "Burial"))
+	boosts, err = svc.Get(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t2"}, boosts.PinnedTrackIDs("night bus"))
+	assert.Empty(t, boosts.ArtistBoosts)
+}
+
+func TestSearchService_ApplyBoosts(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "pinned", UserID: "u1", Title: "Forgotten", Artist: "Kode9"}))
+	boosts := NewSearchBoostService(repo)
+	_, err := boosts.PinTrack(ctx, "u1", models.PinSearchResultRequest{Query: "burial", TrackID: "pinned"})
+	require.NoError(t, err)
+	_, err = boosts.PinTrack(ctx, "u1", models.PinSearchResultRequest{Query: "burial", TrackID: "deleted"})
+	require.NoError(t, err)
+	_, err = boosts.SetArtistBoost(ctx, "u1", models.SetArtistBoostRequest{Artist: "Burial", Weight: 2})
+	require.NoError(t, err)
+
+	svc := &searchServiceImpl{repo: repo}
+	svc.SetBoosts(boosts)
+	results := []searchproto.SearchResult{
+		{ID: "remix", Artist: "Someone", Score: 10},
+		{ID: "archangel", Artist: "Burial", Score: 6},
+		{ID: "pinned", Artist: "Kode9", Score: 1},
+	}
+	ids := func(results []searchproto.SearchResult) []string {
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	t.Run("pins lead and artist boosts rescale", func(t *testing.T) {
+		got := svc.applyBoosts(ctx, "u1", models.SearchRequest{Query: "Burial"}, results)
+		assert.Equal(t, []string{"pinned", "archangel", "remix"}, ids(got))
+		assert.Equal(t, 12.0, got[1].Score)
+	})
+
+	t.Run("loads pinned tracks the index missed", func(t *testing.T) {
+		got := svc.applyBoosts(ctx, "u1", models.SearchRequest{Query: "burial"}, results[:2])
+		assert.Equal(t, []string{"pinned", "archangel", "remix"}, ids(got))
+		assert.Equal(t, "Forgotten", got[0].Title)
+	})
+
+	t.Run("sorted requests keep their order", func(t *testing.T) {
+		got := svc.applyBoosts(ctx, "u1", models.SearchRequest{Query: "other", Sort: models.SearchSort{Field: "year"}}, results)
+		assert.Equal(t, []string{"remix", "archangel", "pinned"}, ids(got))
+	})
+
+	t.Run("later pages leave pins out", func(t *testing.T) {
+		got := svc.applyBoosts(ctx, "u1", models.SearchRequest{Query: "burial", Cursor: "page-2"}, results)
+		assert.Equal(t, []string{"archangel", "remix"}, ids(got))
+	})
+}
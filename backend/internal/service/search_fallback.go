@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+)
+
+// fallbackScanLimit is how many library tracks a degraded search looks at
+const fallbackScanLimit = 500
+
+// fallbackMinScore keeps matches where a title, artist or album word starts
+// with each query word (see quickSearchScore)
+const fallbackMinScore = 0.6
+
+// fallbackSearch answers a search from the repository while the search Lambda
+// is unavailable. It scans up to fallbackScanLimit tracks of the library,
+// keeps those whose title, artist or album words start with the query words
+// and match the filters, and returns the best matches, newest first among
+// equals. Tag filters are applied afterwards like for indexed results. There
+// is no next page: a request carrying an index cursor gets no results rather
+// than the first page again.
+func (s *searchServiceImpl) fallbackSearch(ctx context.Context, userID, libraryID string, req models.SearchRequest, limit int) (*searchproto.SearchResponse, error) {
+	if req.Cursor != "" {
+		return &searchproto.SearchResponse{Results: []searchproto.SearchResult{}}, nil
+	}
+
+	var results []searchproto.SearchResult
+	created := make(map[string]int64)
+	cursor := ""
+	for scanned := 0; scanned < fallbackScanLimit; {
+		page, err := s.repo.ListTracks(ctx, userID, models.TrackFilter{Limit: 100, LastKey: cursor, SortOrder: "desc"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tracks: %w", err)
+		}
+		for _, track := range page.Items {
+			// ListTracks mixes in other users' public tracks
+			if track.UserID != libraryID {
+				continue
+			}
+			scanned++
+			score := fallbackScore(req.Query, track)
+			if score < fallbackMinScore || !matchesSearchFilters(track, req.Filters) {
+				continue
+			}
+			created[track.ID] = track.CreatedAt.UnixNano()
+			result := trackSearchResult(track)
+			result.Score = score
+			results = append(results, result)
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return created[results[i].ID] > created[results[j].ID]
+	})
+
+	total := len(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []searchproto.SearchResult{}
+	}
+	return &searchproto.SearchResponse{Results: results, Total: total}, nil
+}
+
+// fallbackScore is the best quickSearchScore of the track's title, artist and album
+func fallbackScore(query string, track models.Track) float64 {
+	best := 0.0
+	for _, name := range []string{track.Title, track.Artist, track.Album} {
+		if score := quickSearchScore(query, name); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// matchesSearchFilters reports whether the track passes the artist, album,
+// genre and format filters (any listed value may match), the duration and
+// bitrate ranges and the transcode state, and falls within the years, which like for the index span
+// the smallest to the largest listed
+func matchesSearchFilters(track models.Track, filters models.SearchFilters) bool {
+	if !matchesAnyFold(track.Artist, filters.Artists) ||
+		!matchesAnyFold(track.Album, filters.Albums) ||
+		!matchesAnyFold(track.Genre, filters.Genres) ||
+		!matchesAnyFold(string(track.Format), filters.Formats) ||
+		!inRange(track.Duration, filters.DurationFrom, filters.DurationTo) ||
+		!inRange(track.Bitrate, filters.BitrateFrom, filters.BitrateTo) ||
+		(filters.HLSStatus != "" && track.HLSStatus != filters.HLSStatus) ||
+		(filters.Processing != nil && track.HLSStatus.InProgress() != *filters.Processing) {
+		return false
+	}
+	// Like for the index, a bitrate range skips tracks without one
+	if track.Bitrate == 0 && (filters.BitrateFrom > 0 || filters.BitrateTo > 0) {
+		return false
+	}
+	if len(filters.Years) == 0 {
+		return true
+	}
+	minYear, maxYear := filters.Years[0], filters.Years[0]
+	for _, year := range filters.Years {
+		minYear, maxYear = min(minYear, year), max(maxYear, year)
+	}
+	return track.Year >= minYear && track.Year <= maxYear
+}
+
+// inRange reports whether value lies within from and to, where a zero bound
+// is open
+func inRange(value, from, to int) bool {
+	return (from == 0 || value >= from) && (to == 0 || value <= to)
+}
+
+// matchesAnyFold reports whether value equals one of values ignoring case,
+// or values is empty
+func matchesAnyFold(value string, values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesSearchFilters(t *testing.T) {
+	track := models.Track{Artist: "Burial", Genre: "Dubstep", Year: 2007, Format: models.AudioFormatFLAC, Duration: 390, Bitrate: 320}
+
+	assert.True(t, matchesSearchFilters(track, models.SearchFilters{}))
+	assert.True(t, matchesSearchFilters(track, models.SearchFilters{Artists: []string{"Kode9", "burial"}}))
+	assert.True(t, matchesSearchFilters(track, models.SearchFilters{Years: []int{2010, 2005}}))
+	assert.True(t, matchesSearchFilters(track, models.SearchFilters{Formats: []string{"flac"}}))
+	assert.False(t, matchesSearchFilters(track, models.SearchFilters{Genres: []string{"Garage"}}))
+	assert.False(t, matchesSearchFilters(track, models.SearchFilters{Years: []int{2008, 2012}}))
+	assert.True(t, matchesSearchFilters(track, models.SearchFilters{DurationFrom: 300, DurationTo: 480, BitrateFrom: 320}))
+	assert.False(t, matchesSearchFilters(track, models.SearchFilters{DurationTo: 300}))
+	assert.False(t, matchesSearchFilters(track, models.SearchFilters{BitrateFrom: 256, BitrateTo: 256}))
+	assert.False(t, matchesSearchFilters(models.Track{}, models.SearchFilters{BitrateTo: 256}))
+
+	processing := true
+	transcoding := models.Track{HLSStatus: models.HLSStatusProcessing}
+	assert.True(t, matchesSearchFilters(transcoding, models.SearchFilters{Processing: &processing}))
+	assert.False(t, matchesSearchFilters(track, models.SearchFilters{Processing: &processing}))
+	assert.False(t, matchesSearchFilters(transcoding, models.SearchFilters{HLSStatus: models.HLSStatusReady}))
+}
+
+func TestFallbackSearch(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	now := time.Now()
+	for _, track := range []models.Track{
+		{ID: "archangel", UserID: "u1", Title: "Archangel", Artist: "Burial", Album: "Untrue", Genre: "Dubstep"},
+		{ID: "near-dark", UserID: "u1", Title: "Near Dark", Artist: "Burial", Album: "Untrue", Genre: "Dubstep"},
+		{ID: "burial-remix", UserID: "u1", Title: "Burial", Artist: "Someone", Genre: "House"},
+		{ID: "other-user", UserID: "u2", Title: "Burial", Artist: "Burial", Visibility: models.VisibilityPublic},
+		{ID: "unrelated", UserID: "u1", Title: "Windowlicker", Artist: "Aphex Twin"},
+	} {
+		require.NoError(t, repo.CreateTrack(ctx, track))
+	}
+	// CreateTrack stamps the time, so make the age order explicit
+	for i, id := range []string{"archangel", "near-dark", "burial-remix"} {
+		track, err := repo.GetTrack(ctx, "u1", id)
+		require.NoError(t, err)
+		track.CreatedAt = now.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, repo.UpdateTrack(ctx, *track))
+	}
+	svc := &searchServiceImpl{repo: repo}
+
+	t.Run("matches word prefixes, best and newest first", func(t *testing.T) {
+		resp, err := svc.fallbackSearch(ctx, "u1", "u1", models.SearchRequest{Query: "burial"}, 20)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, resp.Total)
+		var ids []string
+		for _, r := range resp.Results {
+			ids = append(ids, r.ID)
+		}
+		assert.Equal(t, []string{"burial-remix", "near-dark", "archangel"}, ids)
+	})
+
+	t.Run("applies filters and the limit", func(t *testing.T) {
+		resp, err := svc.fallbackSearch(ctx, "u1", "u1", models.SearchRequest{
+			Query:   "bur",
+			Filters: models.SearchFilters{Genres: []string{"dubstep"}},
+		}, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, resp.Total)
+		require.Len(t, resp.Results, 1)
+		assert.Equal(t, "near-dark", resp.Results[0].ID)
+	})
+
+	t.Run("has no later pages", func(t *testing.T) {
+		resp, err := svc.fallbackSearch(ctx, "u1", "u1", models.SearchRequest{Query: "burial", Cursor: "index-cursor"}, 20)
+
+		require.NoError(t, err)
+		assert.Empty(t, resp.Results)
+	})
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// recentSuggestionLimit caps the recent searches offered by autocomplete
+const recentSuggestionLimit = 5
+
+// SearchHistoryRepository defines the repository interface for recent searches
+type SearchHistoryRepository interface {
+	GetSearchHistory(ctx context.Context, userID string) (*models.SearchHistory, error)
+	PutSearchHistory(ctx context.Context, history models.SearchHistory) error
+	DeleteSearchHistory(ctx context.Context, userID string) error
+}
+
+// SearchHistoryService keeps each user's recent searches, a bounded list
+// without duplicates (see models.SearchHistory)
+type SearchHistoryService struct {
+	repo SearchHistoryRepository
+	now  func() time.Time
+}
+
+// NewSearchHistoryService creates a new search history service
+func NewSearchHistoryService(repo SearchHistoryRepository) *SearchHistoryService {
+	return &SearchHistoryService{repo: repo, now: time.Now}
+}
+
+// Record adds query to the front of the user's recent searches
+func (s *SearchHistoryService) Record(ctx context.Context, userID, query string) error {
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+
+	history, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	history.Add(query, s.now())
+	return s.repo.PutSearchHistory(ctx, *history)
+}
+
+// List returns the user's recent searches, newest first
+func (s *SearchHistoryService) List(ctx context.Context, userID string) (*models.RecentSearchesResponse, error) {
+	history, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RecentSearchesResponse{Items: history.Searches}, nil
+}
+
+// Clear forgets the user's recent searches
+func (s *SearchHistoryService) Clear(ctx context.Context, userID string) error {
+	return s.repo.DeleteSearchHistory(ctx, userID)
+}
+
+// Suggest returns the user's most recent searches starting with prefix
+// (ignoring case) for autocomplete. Suggestions are optional, so a failed
+// lookup is logged and returns none.
+func (s *SearchHistoryService) Suggest(ctx context.Context, userID, prefix string) []models.RecentSearch {
+	history, err := s.get(ctx, userID)
+	if err != nil {
+		fmt.Printf("Warning: failed to load recent searches for %s: %v\n", userID, err)
+		return nil
+	}
+
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	var matches []models.RecentSearch
+	for _, search := range history.Searches {
+		if len(matches) == recentSuggestionLimit {
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(search.Query), prefix) {
+			matches = append(matches, search)
+		}
+	}
+	return matches
+}
+
+// get loads the user's history, or an empty one if they have none
+func (s *SearchHistoryService) get(ctx context.Context, userID string) (*models.SearchHistory, error) {
+	history, err := s.repo.GetSearchHistory(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &models.SearchHistory{UserID: userID, Searches: []models.RecentSearch{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchHistoryService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	svc := NewSearchHistoryService(repository.NewMemoryRepository())
+	svc.now = func() time.Time { now = now.Add(time.Minute); return now }
+
+	t.Run("empty history", func(t *testing.T) {
+		resp, err := svc.List(ctx, "u1")
+		require.NoError(t, err)
+		assert.Empty(t, resp.Items)
+		assert.NotNil(t, resp.Items)
+	})
+
+	for _, query := range []string{"burial", "aphex twin", "", "Bur", "BURIAL"} {
+		require.NoError(t, svc.Record(ctx, "u1", query))
+	}
+	require.NoError(t, svc.Record(ctx, "u2", "boards of canada"))
+
+	t.Run("newest first without duplicates", func(t *testing.T) {
+		resp, err := svc.List(ctx, "u1")
+		require.NoError(t, err)
+		queries := make([]string, len(resp.Items))
+		for i, item := range resp.Items {
+			queries[i] = item.Query
+		}
+		assert.Equal(t, []string{"BURIAL", "Bur", "aphex twin"}, queries)
+	})
+
+	t.Run("suggests by prefix", func(t *testing.T) {
+		suggestions := svc.Suggest(ctx, "u1", "bu")
+		require.Len(t, suggestions, 2)
+		assert.Equal(t, "BURIAL", suggestions[0].Query)
+		assert.Empty(t, svc.Suggest(ctx, "u1", "boards"), "other users' searches are not suggested")
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		require.NoError(t, svc.Clear(ctx, "u1"))
+		resp, err := svc.List(ctx, "u1")
+		require.NoError(t, err)
+		assert.Empty(t, resp.Items)
+
+		resp, err = svc.List(ctx, "u2")
+		require.NoError(t, err)
+		require.Len(t, resp.Items, 1)
+		assert.Equal(t, "boards of canada", resp.Items[0].Query)
+	})
+}
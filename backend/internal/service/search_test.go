@@ -10,7 +10,8 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
-	"github.com/gvasels/personal-music-searchengine/internal/search"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,378 +21,54 @@ type MockSearchClient struct {
 	mock.Mock
 }
 
-func (m *MockSearchClient) Search(ctx context.Context, userID string, query search.SearchQuery) (*search.SearchResponse, error) {
+func (m *MockSearchClient) Search(ctx context.Context, userID string, query searchproto.SearchQuery) (*searchproto.SearchResponse, error) {
 	args := m.Called(ctx, userID, query)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*search.SearchResponse), args.Error(1)
+	return args.Get(0).(*searchproto.SearchResponse), args.Error(1)
 }
 
-func (m *MockSearchClient) Index(ctx context.Context, doc search.Document) (*search.IndexResponse, error) {
+func (m *MockSearchClient) Index(ctx context.Context, doc searchproto.Document) (*searchproto.IndexResponse, error) {
 	args := m.Called(ctx, doc)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*search.IndexResponse), args.Error(1)
+	return args.Get(0).(*searchproto.IndexResponse), args.Error(1)
 }
 
-func (m *MockSearchClient) Delete(ctx context.Context, docID string) (*search.DeleteResponse, error) {
+func (m *MockSearchClient) Delete(ctx context.Context, docID string) (*searchproto.DeleteResponse, error) {
 	args := m.Called(ctx, docID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*search.DeleteResponse), args.Error(1)
+	return args.Get(0).(*searchproto.DeleteResponse), args.Error(1)
 }
 
-func (m *MockSearchClient) BulkIndex(ctx context.Context, docs []search.Document) (*search.BulkIndexResponse, error) {
+func (m *MockSearchClient) BulkIndex(ctx context.Context, docs []searchproto.Document) (*searchproto.BulkIndexResponse, error) {
 	args := m.Called(ctx, docs)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*search.BulkIndexResponse), args.Error(1)
-}
-
-// MockRepository mocks the repository.Repository
-type MockRepository struct {
-	mock.Mock
-}
-
-func (m *MockRepository) CreateTrack(ctx context.Context, track models.Track) error {
-	args := m.Called(ctx, track)
-	return args.Error(0)
-}
-
-func (m *MockRepository) GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, userID, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-func (m *MockRepository) GetTrackByID(ctx context.Context, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-func (m *MockRepository) UpdateTrack(ctx context.Context, track models.Track) error {
-	args := m.Called(ctx, track)
-	return args.Error(0)
-}
-
-func (m *MockRepository) DeleteTrack(ctx context.Context, userID, trackID string) error {
-	args := m.Called(ctx, userID, trackID)
-	return args.Error(0)
-}
-
-func (m *MockRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error) {
-	args := m.Called(ctx, userID, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Track]), args.Error(1)
-}
-
-func (m *MockRepository) ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.Track, error) {
-	args := m.Called(ctx, userID, artist)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.Track), args.Error(1)
-}
-
-// Stub implementations for other Repository methods
-func (m *MockRepository) GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockRepository) GetAlbum(ctx context.Context, userID, albumID string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockRepository) ListAlbums(ctx context.Context, userID string, filter models.AlbumFilter) (*repository.PaginatedResult[models.Album], error) {
-	return nil, nil
-}
-func (m *MockRepository) ListAlbumsByArtist(ctx context.Context, userID, artist string) ([]models.Album, error) {
-	return nil, nil
-}
-func (m *MockRepository) UpdateAlbumStats(ctx context.Context, userID, albumID string, trackCount, totalDuration int) error {
-	return nil
-}
-func (m *MockRepository) CreateUser(ctx context.Context, user models.User) error       { return nil }
-func (m *MockRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockRepository) UpdateUser(ctx context.Context, user models.User) error { return nil }
-func (m *MockRepository) UpdateUserStats(ctx context.Context, userID string, storageUsed int64, trackCount, albumCount, playlistCount int) error {
-	return nil
-}
-func (m *MockRepository) CreatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockRepository) GetPlaylist(ctx context.Context, userID, playlistID string) (*models.Playlist, error) {
-	return nil, nil
-}
-func (m *MockRepository) UpdatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockRepository) DeletePlaylist(ctx context.Context, userID, playlistID string) error {
-	return nil
-}
-func (m *MockRepository) ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-func (m *MockRepository) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string, position int) error {
-	return nil
-}
-func (m *MockRepository) RemoveTracksFromPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
-	return nil
-}
-func (m *MockRepository) GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error) {
-	return nil, nil
-}
-func (m *MockRepository) ReorderPlaylistTracks(ctx context.Context, playlistID string, tracks []models.PlaylistTrack) error {
-	return nil
-}
-func (m *MockRepository) CreateTag(ctx context.Context, tag models.Tag) error { return nil }
-func (m *MockRepository) GetTag(ctx context.Context, userID, tagName string) (*models.Tag, error) {
-	return nil, nil
-}
-func (m *MockRepository) UpdateTag(ctx context.Context, tag models.Tag) error { return nil }
-func (m *MockRepository) DeleteTag(ctx context.Context, userID, tagName string) error {
-	return nil
-}
-func (m *MockRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
-	return nil, nil
-}
-func (m *MockRepository) AddTagsToTrack(ctx context.Context, userID, trackID string, tagNames []string) error {
-	return nil
-}
-func (m *MockRepository) RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error {
-	return nil
-}
-func (m *MockRepository) GetTrackTags(ctx context.Context, userID, trackID string) ([]string, error) {
-	return nil, nil
-}
-func (m *MockRepository) GetTracksByTag(ctx context.Context, userID, tagName string) ([]models.Track, error) {
-	return nil, nil
-}
-func (m *MockRepository) CreateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockRepository) GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error) {
-	return nil, nil
-}
-func (m *MockRepository) UpdateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockRepository) UpdateUploadStatus(ctx context.Context, userID, uploadID string, status models.UploadStatus, errorMsg string, trackID string) error {
-	return nil
-}
-func (m *MockRepository) UpdateUploadStep(ctx context.Context, userID, uploadID string, step models.ProcessingStep, success bool) error {
-	return nil
-}
-func (m *MockRepository) ListUploads(ctx context.Context, userID string, filter models.UploadFilter) (*repository.PaginatedResult[models.Upload], error) {
-	return nil, nil
-}
-func (m *MockRepository) ListUploadsByStatus(ctx context.Context, status models.UploadStatus) ([]models.Upload, error) {
-	return nil, nil
-}
-
-// Artist-related methods for Repository interface
-func (m *MockRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockRepository) UpdateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockRepository) DeleteArtist(ctx context.Context, userID, artistID string) error {
-	return nil
-}
-func (m *MockRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*repository.PaginatedResult[models.Artist], error) {
-	return nil, nil
-}
-func (m *MockRepository) BatchGetArtists(ctx context.Context, userID string, artistIDs []string) (map[string]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockRepository) SearchArtists(ctx context.Context, userID, query string, limit int) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockRepository) GetArtistTrackCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockRepository) GetArtistAlbumCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockRepository) GetArtistTotalPlays(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockRepository) SearchPlaylists(ctx context.Context, userID, query string, limit int) ([]models.Playlist, error) {
-	return nil, nil
-}
-
-// User role methods
-func (m *MockRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	return nil
-}
-func (m *MockRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
-	return nil, nil
-}
-
-// Playlist visibility methods
-func (m *MockRepository) UpdatePlaylistVisibility(ctx context.Context, userID, playlistID string, visibility models.PlaylistVisibility) error {
-	return nil
-}
-func (m *MockRepository) ListPublicPlaylists(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-
-// ArtistProfile methods
-func (m *MockRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
-	return nil, nil
-}
-func (m *MockRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockRepository) DeleteArtistProfile(ctx context.Context, userID string) error {
-	return nil
-}
-func (m *MockRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.ArtistProfile], error) {
-	return nil, nil
-}
-func (m *MockRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Follow methods
-func (m *MockRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
-	return nil
-}
-func (m *MockRepository) DeleteFollow(ctx context.Context, followerID, followedID string) error {
-	return nil
-}
-func (m *MockRepository) GetFollow(ctx context.Context, followerID, followedID string) (*models.Follow, error) {
-	return nil, nil
-}
-func (m *MockRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Admin-related methods for track visibility
-func (m *MockRepository) ListPublicTracks(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-func (m *MockRepository) UpdateTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error {
-	return nil
-}
-func (m *MockRepository) SearchUsers(ctx context.Context, query string, limit int) ([]models.User, error) {
-	return nil, nil
-}
-func (m *MockRepository) SetUserDisabled(ctx context.Context, userID string, disabled bool) error {
-	return nil
-}
-func (m *MockRepository) GetUserDisplayName(ctx context.Context, userID string) (string, error) {
-	return "", nil
-}
-func (m *MockRepository) GetFollowerCount(ctx context.Context, userID string) (int, error) {
-	return 0, nil
-}
-
-// User settings methods
-func (m *MockRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockRepository) GetUserByCognitoID(ctx context.Context, cognitoID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockRepository) SearchUsersByEmail(ctx context.Context, emailPrefix string, limit int, cursor string) ([]repository.UserSearchResult, string, error) {
-	return nil, "", nil
-}
-func (m *MockRepository) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
-	return nil, nil
-}
-func (m *MockRepository) UpdateUserSettings(ctx context.Context, userID string, update *repository.UserSettingsUpdate) (*models.UserSettings, error) {
-	return nil, nil
-}
-
-// MockS3Repository mocks the repository.S3Repository
-type MockS3Repository struct {
-	mock.Mock
-}
-
-func (m *MockS3Repository) GeneratePresignedUploadURL(ctx context.Context, key, contentType string, expiry time.Duration) (string, error) {
-	args := m.Called(ctx, key, contentType, expiry)
-	return args.String(0), args.Error(1)
-}
-
-func (m *MockS3Repository) GeneratePresignedDownloadURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
-	args := m.Called(ctx, key, expiry)
-	return args.String(0), args.Error(1)
-}
-
-func (m *MockS3Repository) InitiateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
-	return "", nil
-}
-func (m *MockS3Repository) GenerateMultipartUploadURLs(ctx context.Context, key, uploadID string, numParts int, expiry time.Duration) ([]models.MultipartUploadPartURL, error) {
-	return nil, nil
-}
-func (m *MockS3Repository) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []models.CompletedPartInfo) error {
-	return nil
-}
-func (m *MockS3Repository) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
-	return nil
-}
-func (m *MockS3Repository) DeleteObject(ctx context.Context, key string) error { return nil }
-func (m *MockS3Repository) CopyObject(ctx context.Context, sourceKey, destKey string) error {
-	return nil
-}
-func (m *MockS3Repository) GetObjectMetadata(ctx context.Context, key string) (map[string]string, error) {
-	return nil, nil
-}
-func (m *MockS3Repository) GeneratePresignedDownloadURLWithFilename(ctx context.Context, key string, expiry time.Duration, filename string) (string, error) {
-	return "", nil
-}
-func (m *MockS3Repository) ObjectExists(ctx context.Context, key string) (bool, error) {
-	return false, nil
-}
-
-func (m *MockS3Repository) DeleteByPrefix(ctx context.Context, prefix string) error {
-	return nil
+	return args.Get(0).(*searchproto.BulkIndexResponse), args.Error(1)
 }
 
 // SearchClient interface for mocking
 type SearchClient interface {
-	Search(ctx context.Context, userID string, query search.SearchQuery) (*search.SearchResponse, error)
-	Index(ctx context.Context, doc search.Document) (*search.IndexResponse, error)
-	Delete(ctx context.Context, docID string) (*search.DeleteResponse, error)
-	BulkIndex(ctx context.Context, docs []search.Document) (*search.BulkIndexResponse, error)
+	Search(ctx context.Context, userID string, query searchproto.SearchQuery) (*searchproto.SearchResponse, error)
+	Index(ctx context.Context, doc searchproto.Document) (*searchproto.IndexResponse, error)
+	Delete(ctx context.Context, docID string) (*searchproto.DeleteResponse, error)
+	BulkIndex(ctx context.Context, docs []searchproto.Document) (*searchproto.BulkIndexResponse, error)
 }
 
 // testSearchService allows injecting mock client
 type testSearchService struct {
 	client SearchClient
 	repo   repository.Repository
-	s3Repo repository.S3Repository
+	s3Repo repository.MediaStore
 }
 
-func newTestSearchService(client SearchClient, repo repository.Repository, s3Repo repository.S3Repository) *testSearchService {
+func newTestSearchService(client SearchClient, repo repository.Repository, s3Repo repository.MediaStore) *testSearchService {
 	return &testSearchService{
 		client: client,
 		repo:   repo,
@@ -415,7 +92,7 @@ func (s *testSearchService) Search(ctx context.Context, userID string, req model
 		limit = 100
 	}
 
-	searchQuery := search.SearchQuery{
+	searchQuery := searchproto.SearchQuery{
 		Query:  req.Query,
 		Limit:  limit,
 		Cursor: req.Cursor,
@@ -433,7 +110,7 @@ func (s *testSearchService) Search(ctx context.Context, userID string, req model
 	}
 
 	if req.Sort.Field != "" {
-		searchQuery.Sort = &search.SortOption{
+		searchQuery.Sort = &searchproto.SortOption{
 			Field: req.Sort.Field,
 			Order: req.Sort.Order,
 		}
@@ -476,7 +153,7 @@ func (s *testSearchService) Autocomplete(ctx context.Context, userID, query stri
 		}, nil
 	}
 
-	searchQuery := search.SearchQuery{
+	searchQuery := searchproto.SearchQuery{
 		Query: query,
 		Limit: 10,
 	}
@@ -525,7 +202,7 @@ func (s *testSearchService) Autocomplete(ctx context.Context, userID, query stri
 }
 
 func (s *testSearchService) IndexTrack(ctx context.Context, track models.Track) error {
-	doc := search.Document{
+	doc := searchproto.Document{
 		ID:        track.ID,
 		UserID:    track.UserID,
 		Title:     track.Title,
@@ -565,19 +242,19 @@ func (s *testSearchService) RemoveTrack(ctx context.Context, trackID string) err
 func TestSearch_SimpleQuery(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
-	expectedResults := []search.SearchResult{
+	expectedResults := []searchproto.SearchResult{
 		{ID: "track-1", Title: "Hey Jude", Artist: "The Beatles", Album: "Past Masters", Duration: 180},
 		{ID: "track-2", Title: "Let It Be", Artist: "The Beatles", Album: "Let It Be", Duration: 240},
 	}
 
-	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q search.SearchQuery) bool {
+	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q searchproto.SearchQuery) bool {
 		return q.Query == "beatles" && q.Limit == 20
-	})).Return(&search.SearchResponse{
+	})).Return(&searchproto.SearchResponse{
 		Results:    expectedResults,
 		Total:      2,
 		NextCursor: "",
@@ -601,15 +278,15 @@ func TestSearch_SimpleQuery(t *testing.T) {
 func TestSearch_WithFilters(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
-	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q search.SearchQuery) bool {
+	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q searchproto.SearchQuery) bool {
 		return q.Query == "love" && q.Filters.Artist == "The Beatles" && q.Filters.Genre == "Rock"
-	})).Return(&search.SearchResponse{
-		Results: []search.SearchResult{
+	})).Return(&searchproto.SearchResponse{
+		Results: []searchproto.SearchResult{
 			{ID: "track-1", Title: "All You Need Is Love", Artist: "The Beatles", Genre: "Rock"},
 		},
 		Total: 1,
@@ -632,18 +309,38 @@ func TestSearch_WithFilters(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestConvertFilters(t *testing.T) {
+	svc := &searchServiceImpl{}
+	filters := svc.convertFilters(models.SearchFilters{
+		Artists:      []string{"The Beatles", "Wings"},
+		Years:        []int{1970, 1965, 1968},
+		DurationFrom: 300,
+		DurationTo:   480,
+		BitrateFrom:  320,
+	})
+
+	assert.Equal(t, searchproto.SearchFilters{
+		Artist:       "The Beatles",
+		YearFrom:     1965,
+		YearTo:       1970,
+		DurationFrom: 300,
+		DurationTo:   480,
+		BitrateFrom:  320,
+	}, filters)
+}
+
 func TestSearch_WithPagination(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
-	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q search.SearchQuery) bool {
+	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q searchproto.SearchQuery) bool {
 		return q.Query == "rock" && q.Limit == 10 && q.Cursor == "cursor-abc"
-	})).Return(&search.SearchResponse{
-		Results:    []search.SearchResult{{ID: "track-3", Title: "Track 3"}},
+	})).Return(&searchproto.SearchResponse{
+		Results:    []searchproto.SearchResult{{ID: "track-3", Title: "Track 3"}},
 		Total:      30,
 		NextCursor: "cursor-xyz",
 	}, nil)
@@ -667,8 +364,8 @@ func TestSearch_WithPagination(t *testing.T) {
 func TestSearch_EmptyQuery(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
@@ -682,8 +379,8 @@ func TestSearch_EmptyQuery(t *testing.T) {
 func TestSearch_QueryTooLong(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
@@ -707,17 +404,17 @@ func TestSearch_QueryTooLong(t *testing.T) {
 func TestSearch_QueryAtMaxLength(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
 	// Create a query exactly at MaxQueryLength (500 characters)
 	maxQuery := strings.Repeat("a", MaxQueryLength)
 
-	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q search.SearchQuery) bool {
+	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q searchproto.SearchQuery) bool {
 		return len(q.Query) == MaxQueryLength
-	})).Return(&search.SearchResponse{Results: []search.SearchResult{}, Total: 0}, nil)
+	})).Return(&searchproto.SearchResponse{Results: []searchproto.SearchResult{}, Total: 0}, nil)
 
 	req := models.SearchRequest{Query: maxQuery}
 	_, err := svc.Search(ctx, "user-123", req)
@@ -729,15 +426,15 @@ func TestSearch_QueryAtMaxLength(t *testing.T) {
 func TestSearch_LimitClamping(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
 	// Test limit clamping to 100
-	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q search.SearchQuery) bool {
+	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q searchproto.SearchQuery) bool {
 		return q.Limit == 100
-	})).Return(&search.SearchResponse{Results: []search.SearchResult{}, Total: 0}, nil)
+	})).Return(&searchproto.SearchResponse{Results: []searchproto.SearchResult{}, Total: 0}, nil)
 
 	req := models.SearchRequest{Query: "test", Limit: 500}
 	_, err := svc.Search(ctx, "user-123", req)
@@ -749,15 +446,15 @@ func TestSearch_LimitClamping(t *testing.T) {
 func TestAutocomplete_Success(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
-	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q search.SearchQuery) bool {
+	mockClient.On("Search", ctx, "user-123", mock.MatchedBy(func(q searchproto.SearchQuery) bool {
 		return q.Query == "beat" && q.Limit == 10
-	})).Return(&search.SearchResponse{
-		Results: []search.SearchResult{
+	})).Return(&searchproto.SearchResponse{
+		Results: []searchproto.SearchResult{
 			{ID: "t1", Title: "Beat It", Artist: "Michael Jackson", Album: "Thriller"},
 			{ID: "t2", Title: "Heart Beat", Artist: "The Beatles", Album: "Love"},
 		},
@@ -794,8 +491,8 @@ func TestAutocomplete_Success(t *testing.T) {
 func TestAutocomplete_EmptyQuery(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
@@ -809,8 +506,8 @@ func TestAutocomplete_EmptyQuery(t *testing.T) {
 func TestIndexTrack_Success(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
@@ -826,9 +523,9 @@ func TestIndexTrack_Success(t *testing.T) {
 		S3Key:    "audio/track-123.mp3",
 	}
 
-	mockClient.On("Index", ctx, mock.MatchedBy(func(doc search.Document) bool {
+	mockClient.On("Index", ctx, mock.MatchedBy(func(doc searchproto.Document) bool {
 		return doc.ID == "track-123" && doc.UserID == "user-123" && doc.Title == "Test Track"
-	})).Return(&search.IndexResponse{ID: "track-123", Indexed: true}, nil)
+	})).Return(&searchproto.IndexResponse{ID: "track-123", Indexed: true}, nil)
 
 	err := svc.IndexTrack(ctx, track)
 
@@ -839,14 +536,14 @@ func TestIndexTrack_Success(t *testing.T) {
 func TestIndexTrack_Failure(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
 	track := models.Track{ID: "track-123", UserID: "user-123", Title: "Test"}
 
-	mockClient.On("Index", ctx, mock.Anything).Return(&search.IndexResponse{ID: "track-123", Indexed: false}, nil)
+	mockClient.On("Index", ctx, mock.Anything).Return(&searchproto.IndexResponse{ID: "track-123", Indexed: false}, nil)
 
 	err := svc.IndexTrack(ctx, track)
 
@@ -857,12 +554,12 @@ func TestIndexTrack_Failure(t *testing.T) {
 func TestRemoveTrack_Success(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
-	mockClient.On("Delete", ctx, "track-123").Return(&search.DeleteResponse{ID: "track-123", Deleted: true}, nil)
+	mockClient.On("Delete", ctx, "track-123").Return(&searchproto.DeleteResponse{ID: "track-123", Deleted: true}, nil)
 
 	err := svc.RemoveTrack(ctx, "track-123")
 
@@ -873,13 +570,13 @@ func TestRemoveTrack_Success(t *testing.T) {
 func TestRemoveTrack_NotFound(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockSearchClient)
-	mockRepo := new(MockRepository)
-	mockS3 := new(MockS3Repository)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	svc := newTestSearchService(mockClient, mockRepo, mockS3)
 
 	// Even if not found, should not error
-	mockClient.On("Delete", ctx, "track-999").Return(&search.DeleteResponse{ID: "track-999", Deleted: false}, nil)
+	mockClient.On("Delete", ctx, "track-999").Return(&searchproto.DeleteResponse{ID: "track-999", Deleted: false}, nil)
 
 	err := svc.RemoveTrack(ctx, "track-999")
 
@@ -911,281 +608,10 @@ func TestFormatDuration(t *testing.T) {
 // filterByTags Tests (Epic 4)
 // =============================================================================
 
-// MockFilterTagsRepository provides mockable GetTag and GetTracksByTag for filterByTags tests
-type MockFilterTagsRepository struct {
-	mock.Mock
-}
-
-func (m *MockFilterTagsRepository) GetTag(ctx context.Context, userID, tagName string) (*models.Tag, error) {
-	args := m.Called(ctx, userID, tagName)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Tag), args.Error(1)
-}
-
-func (m *MockFilterTagsRepository) GetTracksByTag(ctx context.Context, userID, tagName string) ([]models.Track, error) {
-	args := m.Called(ctx, userID, tagName)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.Track), args.Error(1)
-}
-
-// Stub implementations for Repository interface (required but not used in filterByTags tests)
-func (m *MockFilterTagsRepository) CreateTrack(ctx context.Context, track models.Track) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) GetTrackByID(ctx context.Context, trackID string) (*models.Track, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) UpdateTrack(ctx context.Context, track models.Track) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) DeleteTrack(ctx context.Context, userID, trackID string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.Track, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) GetAlbum(ctx context.Context, userID, albumID string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) ListAlbums(ctx context.Context, userID string, filter models.AlbumFilter) (*repository.PaginatedResult[models.Album], error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) ListAlbumsByArtist(ctx context.Context, userID, artist string) ([]models.Album, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) UpdateAlbumStats(ctx context.Context, userID, albumID string, trackCount, totalDuration int) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) CreateUser(ctx context.Context, user models.User) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) UpdateUser(ctx context.Context, user models.User) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) UpdateUserStats(ctx context.Context, userID string, storageUsed int64, trackCount, albumCount, playlistCount int) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) CreatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetPlaylist(ctx context.Context, userID, playlistID string) (*models.Playlist, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) UpdatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) DeletePlaylist(ctx context.Context, userID, playlistID string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string, position int) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) RemoveTracksFromPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) ReorderPlaylistTracks(ctx context.Context, playlistID string, tracks []models.PlaylistTrack) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) CreateTag(ctx context.Context, tag models.Tag) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) UpdateTag(ctx context.Context, tag models.Tag) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) DeleteTag(ctx context.Context, userID, tagName string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) AddTagsToTrack(ctx context.Context, userID, trackID string, tagNames []string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetTrackTags(ctx context.Context, userID, trackID string) ([]string, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) CreateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) UpdateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) UpdateUploadStatus(ctx context.Context, userID, uploadID string, status models.UploadStatus, errorMsg string, trackID string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) UpdateUploadStep(ctx context.Context, userID, uploadID string, step models.ProcessingStep, success bool) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) ListUploads(ctx context.Context, userID string, filter models.UploadFilter) (*repository.PaginatedResult[models.Upload], error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) ListUploadsByStatus(ctx context.Context, status models.UploadStatus) ([]models.Upload, error) {
-	return nil, nil
-}
-
-// Artist-related methods
-func (m *MockFilterTagsRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) UpdateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) DeleteArtist(ctx context.Context, userID, artistID string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*repository.PaginatedResult[models.Artist], error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) BatchGetArtists(ctx context.Context, userID string, artistIDs []string) (map[string]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) SearchArtists(ctx context.Context, userID, query string, limit int) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) GetArtistTrackCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockFilterTagsRepository) GetArtistAlbumCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockFilterTagsRepository) GetArtistTotalPlays(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockFilterTagsRepository) SearchPlaylists(ctx context.Context, userID, query string, limit int) ([]models.Playlist, error) {
-	return nil, nil
-}
-
-// User role methods
-func (m *MockFilterTagsRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
-	return nil, nil
-}
-
-// Playlist visibility methods
-func (m *MockFilterTagsRepository) UpdatePlaylistVisibility(ctx context.Context, userID, playlistID string, visibility models.PlaylistVisibility) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) ListPublicPlaylists(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-
-// ArtistProfile methods
-func (m *MockFilterTagsRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) DeleteArtistProfile(ctx context.Context, userID string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.ArtistProfile], error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Follow methods
-func (m *MockFilterTagsRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) DeleteFollow(ctx context.Context, followerID, followedID string) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetFollow(ctx context.Context, followerID, followedID string) (*models.Follow, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Admin-related methods for track visibility
-func (m *MockFilterTagsRepository) ListPublicTracks(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) UpdateTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) SearchUsers(ctx context.Context, query string, limit int) ([]models.User, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) SetUserDisabled(ctx context.Context, userID string, disabled bool) error {
-	return nil
-}
-func (m *MockFilterTagsRepository) GetUserDisplayName(ctx context.Context, userID string) (string, error) {
-	return "", nil
-}
-func (m *MockFilterTagsRepository) GetFollowerCount(ctx context.Context, userID string) (int, error) {
-	return 0, nil
-}
-
-// User settings methods
-func (m *MockFilterTagsRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) GetUserByCognitoID(ctx context.Context, cognitoID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) SearchUsersByEmail(ctx context.Context, emailPrefix string, limit int, cursor string) ([]repository.UserSearchResult, string, error) {
-	return nil, "", nil
-}
-func (m *MockFilterTagsRepository) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
-	return nil, nil
-}
-func (m *MockFilterTagsRepository) UpdateUserSettings(ctx context.Context, userID string, update *repository.UserSettingsUpdate) (*models.UserSettings, error) {
-	return nil, nil
-}
-
 // TestFilterByTags_EmptyTags verifies that empty tags array returns all results unchanged
 func TestFilterByTags_EmptyTags(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockFilterTagsRepository)
+	mockRepo := new(mocks.Repository)
 
 	svc := &searchServiceImpl{
 		client: nil,
@@ -1193,7 +619,7 @@ func TestFilterByTags_EmptyTags(t *testing.T) {
 		s3Repo: nil,
 	}
 
-	results := []search.SearchResult{
+	results := []searchproto.SearchResult{
 		{ID: "track-1", Title: "Track One"},
 		{ID: "track-2", Title: "Track Two"},
 	}
@@ -1209,7 +635,7 @@ func TestFilterByTags_EmptyTags(t *testing.T) {
 // TestFilterByTags_TagNotFound verifies NotFoundError when tag doesn't exist
 func TestFilterByTags_TagNotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockFilterTagsRepository)
+	mockRepo := new(mocks.Repository)
 
 	svc := &searchServiceImpl{
 		client: nil,
@@ -1217,7 +643,7 @@ func TestFilterByTags_TagNotFound(t *testing.T) {
 		s3Repo: nil,
 	}
 
-	results := []search.SearchResult{
+	results := []searchproto.SearchResult{
 		{ID: "track-1", Title: "Track One"},
 	}
 
@@ -1241,7 +667,7 @@ func TestFilterByTags_TagNotFound(t *testing.T) {
 // TestFilterByTags_SingleTag_Success verifies single tag filters correctly
 func TestFilterByTags_SingleTag_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockFilterTagsRepository)
+	mockRepo := new(mocks.Repository)
 
 	svc := &searchServiceImpl{
 		client: nil,
@@ -1249,7 +675,7 @@ func TestFilterByTags_SingleTag_Success(t *testing.T) {
 		s3Repo: nil,
 	}
 
-	results := []search.SearchResult{
+	results := []searchproto.SearchResult{
 		{ID: "track-1", Title: "Track One"},
 		{ID: "track-2", Title: "Track Two"},
 		{ID: "track-3", Title: "Track Three"},
@@ -1279,7 +705,7 @@ func TestFilterByTags_SingleTag_Success(t *testing.T) {
 // TestFilterByTags_MultipleTags_ANDLogic verifies multiple tags use AND logic
 func TestFilterByTags_MultipleTags_ANDLogic(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockFilterTagsRepository)
+	mockRepo := new(mocks.Repository)
 
 	svc := &searchServiceImpl{
 		client: nil,
@@ -1287,7 +713,7 @@ func TestFilterByTags_MultipleTags_ANDLogic(t *testing.T) {
 		s3Repo: nil,
 	}
 
-	results := []search.SearchResult{
+	results := []searchproto.SearchResult{
 		{ID: "track-1", Title: "Track One"},
 		{ID: "track-2", Title: "Track Two"},
 		{ID: "track-3", Title: "Track Three"},
@@ -1321,7 +747,7 @@ func TestFilterByTags_MultipleTags_ANDLogic(t *testing.T) {
 // TestFilterByTags_SecondTagNotFound verifies error on second tag returns NotFoundError
 func TestFilterByTags_SecondTagNotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockFilterTagsRepository)
+	mockRepo := new(mocks.Repository)
 
 	svc := &searchServiceImpl{
 		client: nil,
@@ -1329,7 +755,7 @@ func TestFilterByTags_SecondTagNotFound(t *testing.T) {
 		s3Repo: nil,
 	}
 
-	results := []search.SearchResult{
+	results := []searchproto.SearchResult{
 		{ID: "track-1", Title: "Track One"},
 	}
 
@@ -1354,7 +780,7 @@ func TestFilterByTags_SecondTagNotFound(t *testing.T) {
 // TestFilterByTags_NoMatchingTracks verifies empty array when no tracks match
 func TestFilterByTags_NoMatchingTracks(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockFilterTagsRepository)
+	mockRepo := new(mocks.Repository)
 
 	svc := &searchServiceImpl{
 		client: nil,
@@ -1362,7 +788,7 @@ func TestFilterByTags_NoMatchingTracks(t *testing.T) {
 		s3Repo: nil,
 	}
 
-	results := []search.SearchResult{
+	results := []searchproto.SearchResult{
 		{ID: "track-1", Title: "Track One"},
 		{ID: "track-2", Title: "Track Two"},
 	}
@@ -1381,7 +807,7 @@ func TestFilterByTags_NoMatchingTracks(t *testing.T) {
 // TestFilterByTags_DeduplicatesTags verifies duplicate tags are deduplicated silently
 func TestFilterByTags_DeduplicatesTags(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockFilterTagsRepository)
+	mockRepo := new(mocks.Repository)
 
 	svc := &searchServiceImpl{
 		client: nil,
@@ -1389,7 +815,7 @@ func TestFilterByTags_DeduplicatesTags(t *testing.T) {
 		s3Repo: nil,
 	}
 
-	results := []search.SearchResult{
+	results := []searchproto.SearchResult{
 		{ID: "track-1", Title: "Track One"},
 	}
 
@@ -1410,7 +836,7 @@ func TestFilterByTags_DeduplicatesTags(t *testing.T) {
 // TestFilterByTags_NormalizesCase verifies tags are normalized to lowercase
 func TestFilterByTags_NormalizesCase(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockFilterTagsRepository)
+	mockRepo := new(mocks.Repository)
 
 	svc := &searchServiceImpl{
 		client: nil,
@@ -1418,7 +844,7 @@ func TestFilterByTags_NormalizesCase(t *testing.T) {
 		s3Repo: nil,
 	}
 
-	results := []search.SearchResult{
+	results := []searchproto.SearchResult{
 		{ID: "track-1", Title: "Track One"},
 	}
 
@@ -1435,3 +861,53 @@ func TestFilterByTags_NormalizesCase(t *testing.T) {
 	assert.Len(t, filtered, 1)
 	mockRepo.AssertExpectations(t)
 }
+
+// countingPresigner presigns cover keys and counts the calls
+type countingPresigner struct {
+	repository.MediaStore
+	calls int
+}
+
+func (s *countingPresigner) GeneratePresignedDownloadURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	s.calls++
+	return "https://covers.example/" + key, nil
+}
+
+func TestHydrateTracks(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	for _, track := range []models.Track{
+		{ID: "t1", UserID: "u1", Title: "Archangel", Duration: 239, CoverArtKey: "covers/u1/untrue.jpg", Tags: []string{"night"}},
+		{ID: "t2", UserID: "u1", Title: "Near Dark", Duration: 238, CoverArtKey: "covers/u1/untrue.jpg"},
+		{ID: "t3", UserID: "u1", Title: "Loner", Duration: 427},
+	} {
+		assert.NoError(t, repo.CreateTrack(ctx, track))
+	}
+	results := []models.TrackResponse{
+		{ID: "t3", Title: "Loner"},
+		{ID: "gone", Title: "Deleted since indexing", Duration: 61, DurationStr: "1:01"},
+		{ID: "t1", Title: "Archangel"},
+		{ID: "t2", Title: "Near Dark"},
+	}
+
+	// plainRepository hides BatchGetTracks, so the second run reads one by one
+	for name, r := range map[string]repository.Repository{
+		"batch":      repo,
+		"one by one": plainRepository{repo},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s3 := &countingPresigner{}
+			svc := &searchServiceImpl{repo: r, s3Repo: s3}
+
+			tracks := svc.hydrateTracks(ctx, "u1", results)
+			assert.Equal(t, []string{"t3", "gone", "t1", "t2"}, []string{tracks[0].ID, tracks[1].ID, tracks[2].ID, tracks[3].ID}, "search order is kept")
+			assert.Equal(t, "7:07", tracks[0].DurationStr)
+			assert.Empty(t, tracks[0].CoverArtURL)
+			assert.Equal(t, "Deleted since indexing", tracks[1].Title, "missing tracks keep their index fields")
+			assert.Equal(t, []string{"night"}, tracks[2].Tags, "stored fields are returned")
+			assert.Equal(t, "https://covers.example/covers/u1/untrue.jpg", tracks[2].CoverArtURL)
+			assert.Equal(t, tracks[2].CoverArtURL, tracks[3].CoverArtURL)
+			assert.Equal(t, 1, s3.calls, "shared covers are presigned once")
+		})
+	}
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"net/http"
+	"time"
 
+	"github.com/gvasels/personal-music-searchengine/internal/jobs"
+	"github.com/gvasels/personal-music-searchengine/internal/migrations"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
 )
@@ -13,6 +17,17 @@ type LibraryStats struct {
 	TotalAlbums   int `json:"totalAlbums"`
 	TotalArtists  int `json:"totalArtists"`
 	TotalDuration int `json:"totalDuration"` // in seconds
+	// Tracks added, and tracks last played, since midnight and since Monday
+	// in TimeZone. Only the last play of a track is kept, so a track played
+	// today and earlier this week counts once.
+	AddedToday     int `json:"addedToday"`
+	AddedThisWeek  int `json:"addedThisWeek"`
+	PlayedToday    int `json:"playedToday"`
+	PlayedThisWeek int `json:"playedThisWeek"`
+	// AddedByMonth counts tracks by the month they were added in TimeZone ("2026-10")
+	AddedByMonth map[string]int `json:"addedByMonth"`
+	// TimeZone is the IANA zone of the user's settings the stats are bucketed in
+	TimeZone string `json:"timeZone"`
 }
 
 // StatsScope defines what data to include in stats
@@ -30,10 +45,17 @@ type TrackService interface {
 	UpdateTrack(ctx context.Context, userID, trackID string, req models.UpdateTrackRequest) (*models.TrackResponse, error)
 	DeleteTrack(ctx context.Context, userID, trackID string, hasGlobal bool) error
 	ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.TrackResponse], error)
+	CountTracks(ctx context.Context, userID string, hasGlobal bool) (int, error)
 	ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.TrackResponse, error)
 	IncrementPlayCount(ctx context.Context, userID, trackID string) error
 	// Visibility operations
-	UpdateVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error
+	UpdateVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) (*models.TrackVisibilityUpdate, error)
+	// Lock operations
+	SetLocked(ctx context.Context, userID, trackID string, locked bool) (*models.TrackResponse, error)
+	// Analysis corrections
+	UpdateAnalysis(ctx context.Context, userID, trackID string, req models.UpdateTrackAnalysisRequest) (*models.TrackResponse, error)
+	// Playback settings
+	UpdatePlaybackSettings(ctx context.Context, userID, trackID string, req models.UpdatePlaybackSettingsRequest) (*models.TrackResponse, error)
 	// Stats operations
 	GetLibraryStats(ctx context.Context, userID string, scope StatsScope, hasGlobal bool) (*LibraryStats, error)
 }
@@ -42,6 +64,7 @@ type TrackService interface {
 type AlbumService interface {
 	GetAlbum(ctx context.Context, userID, albumID string) (*models.AlbumWithTracks, error)
 	ListAlbums(ctx context.Context, userID string, filter models.AlbumFilter) (*repository.PaginatedResult[models.AlbumResponse], error)
+	CountAlbums(ctx context.Context, userID string) (int, error)
 	ListAlbumsByArtist(ctx context.Context, userID, artist string) ([]models.AlbumResponse, error)
 	ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) ([]models.ArtistSummary, error)
 }
@@ -65,8 +88,9 @@ type PlaylistService interface {
 	CreatePlaylist(ctx context.Context, userID string, req models.CreatePlaylistRequest) (*models.PlaylistResponse, error)
 	GetPlaylist(ctx context.Context, userID, playlistID string) (*models.PlaylistWithTracks, error)
 	UpdatePlaylist(ctx context.Context, userID, playlistID string, req models.UpdatePlaylistRequest) (*models.PlaylistResponse, error)
-	DeletePlaylist(ctx context.Context, userID, playlistID string) error
+	DeletePlaylist(ctx context.Context, userID, playlistID string, dryRun bool) (*models.DeletePlaylistResult, error)
 	ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*repository.PaginatedResult[models.PlaylistResponse], error)
+	CountPlaylists(ctx context.Context, userID string) (int, error)
 	AddTracks(ctx context.Context, userID, playlistID string, req models.AddTracksToPlaylistRequest) (*models.PlaylistResponse, error)
 	RemoveTracks(ctx context.Context, userID, playlistID string, req models.RemoveTracksFromPlaylistRequest) (*models.PlaylistResponse, error)
 	ReorderTracks(ctx context.Context, userID, playlistID string, req models.ReorderPlaylistTracksRequest) (*models.PlaylistResponse, error)
@@ -80,7 +104,7 @@ type TagService interface {
 	CreateTag(ctx context.Context, userID string, req models.CreateTagRequest) (*models.TagResponse, error)
 	GetTag(ctx context.Context, userID, tagName string) (*models.TagResponse, error)
 	UpdateTag(ctx context.Context, userID, tagName string, req models.UpdateTagRequest) (*models.TagResponse, error)
-	DeleteTag(ctx context.Context, userID, tagName string) error
+	DeleteTag(ctx context.Context, userID, tagName string, dryRun bool) (*models.DeleteTagResult, error)
 	ListTags(ctx context.Context, userID string) ([]models.TagResponse, error)
 	AddTagsToTrack(ctx context.Context, userID, trackID string, req models.AddTagsToTrackRequest) ([]string, error)
 	RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error
@@ -90,6 +114,8 @@ type TagService interface {
 // UploadService defines upload and processing operations
 type UploadService interface {
 	CreatePresignedUpload(ctx context.Context, userID string, req models.PresignedUploadRequest) (*models.PresignedUploadResponse, error)
+	CreateReplaceFileUpload(ctx context.Context, userID, trackID string, req models.PresignedUploadRequest) (*models.PresignedUploadResponse, error)
+	PrecheckUpload(ctx context.Context, userID string, req models.UploadPrecheckRequest) (*models.UploadPrecheckResponse, error)
 	ConfirmUpload(ctx context.Context, userID string, req models.ConfirmUploadRequest) (*models.ConfirmUploadResponse, error)
 	CompleteMultipartUpload(ctx context.Context, userID string, req models.CompleteMultipartUploadRequest) (*models.ConfirmUploadResponse, error)
 	GetUploadStatus(ctx context.Context, userID, uploadID string) (*models.UploadResponse, error)
@@ -98,6 +124,28 @@ type UploadService interface {
 	UploadCoverArt(ctx context.Context, userID, trackID string, req models.CoverArtUploadRequest) (*models.CoverArtUploadResponse, error)
 }
 
+// TagRepairService defines repairs of tags damaged before upload
+type TagRepairService interface {
+	ApplyEncodingFixes(ctx context.Context, userID, uploadID string, req models.ApplyEncodingFixesRequest) (*models.EncodingFixResult, error)
+}
+
+// CleanupService defines suggested cleanups of track titles and artist names
+type CleanupService interface {
+	SuggestCleanups(ctx context.Context, userID string) (*models.CleanupSuggestionsResponse, error)
+	ApplyCleanups(ctx context.Context, userID string, req models.ApplyCleanupRequest) (*models.CleanupResult, error)
+}
+
+// DJExportService defines exports of track analysis for DJ software
+type DJExportService interface {
+	ExportTrack(ctx context.Context, userID, trackID string, req models.DJExportRequest) (*models.DJExport, error)
+	ExportPlaylist(ctx context.Context, userID, playlistID string, req models.DJExportRequest) (*models.DJExport, error)
+}
+
+// DJImportService defines imports of DJ software libraries
+type DJImportService interface {
+	ImportLibrary(ctx context.Context, userID string, req models.DJImportRequest) (*models.DJImportResult, error)
+}
+
 // StreamService defines streaming and download operations
 type StreamService interface {
 	GetStreamURL(ctx context.Context, userID, trackID string, hasGlobal bool) (*models.StreamResponse, error)
@@ -109,41 +157,235 @@ type StreamService interface {
 type SearchService interface {
 	Search(ctx context.Context, userID string, req models.SearchRequest) (*models.SearchResponse, error)
 	Autocomplete(ctx context.Context, userID, query string) (*models.AutocompleteResponse, error)
+	QuickSearch(ctx context.Context, userID string, req models.QuickSearchRequest) (*models.QuickSearchResponse, error)
 	RemoveTrack(ctx context.Context, trackID string) error
 	IndexTrack(ctx context.Context, track models.Track) error
+	IndexStats(ctx context.Context) (*models.SearchIndexStatsResponse, error)
+	StuckTranscodes(ctx context.Context, olderThan time.Duration) (*models.StuckTranscodesResponse, error)
+	ExportIndex(ctx context.Context, req models.ExportSearchIndexRequest) (*models.SearchIndexExportResponse, error)
+	ImportIndex(ctx context.Context, req models.ImportSearchIndexRequest) (*models.SearchIndexImportResponse, error)
+}
+
+// ShareService defines cross-user track sharing operations
+type ShareService interface {
+	ShareTrack(ctx context.Context, ownerID, trackID string, req models.CreateTrackShareRequest) (*models.TrackShareResponse, error)
+	ListReceivedShares(ctx context.Context, userID string, filter models.TrackShareFilter) (*repository.PaginatedResult[models.TrackShareResponse], error)
+	ListSentShares(ctx context.Context, userID string, filter models.TrackShareFilter) (*repository.PaginatedResult[models.TrackShareResponse], error)
+	AcceptShare(ctx context.Context, userID, shareID string) (*models.TrackResponse, error)
+	DeclineShare(ctx context.Context, userID, shareID string) (*models.TrackShareResponse, error)
+}
+
+// HouseholdService defines family/household account operations
+type HouseholdService interface {
+	CreateHousehold(ctx context.Context, userID string, req models.CreateHouseholdRequest) (*models.HouseholdResponse, error)
+	GetHousehold(ctx context.Context, userID string) (*models.HouseholdResponse, error)
+	UpdateHousehold(ctx context.Context, userID string, req models.UpdateHouseholdRequest) (*models.HouseholdResponse, error)
+	DeleteHousehold(ctx context.Context, userID string, dryRun bool) (*models.DeleteHouseholdResult, error)
+	AddMember(ctx context.Context, userID string, req models.AddHouseholdMemberRequest) (*models.HouseholdMember, error)
+	UpdateMemberRole(ctx context.Context, userID, memberID string, req models.UpdateHouseholdMemberRoleRequest) (*models.HouseholdMember, error)
+	RemoveMember(ctx context.Context, userID, memberID string) error
+	ResolveLibraryID(ctx context.Context, userID string) (string, error)
 }
 
 // Services holds all service implementations
 type Services struct {
-	Track    TrackService
-	Album    AlbumService
-	Artist   ArtistService
-	User     UserService
-	Playlist PlaylistService
-	Tag      TagService
-	Upload   UploadService
-	Stream   StreamService
-	Search   SearchService
-	Admin    AdminService
+	Track     TrackService
+	Album     AlbumService
+	Artist    ArtistService
+	User      UserService
+	Playlist  PlaylistService
+	Tag       TagService
+	Upload    UploadService
+	TagRepair TagRepairService
+	Cleanup   CleanupService
+	DJExport  DJExportService
+	DJImport  DJImportService
+	Stream    StreamService
+	Search    SearchService
+	Admin     AdminService
+	Share     ShareService
+	Household HouseholdService
+	// SearchHistory keeps recent searches; nil when not wired
+	SearchHistory *SearchHistoryService
+	// PlayerState syncs each user's play queue across devices; nil when not wired
+	PlayerState *PlayerStateService
+	// Remote relays playback commands between a user's devices; nil when not wired
+	Remote *RemoteService
+	// SearchBoosts manages pinned results and artist boosts; nil when not wired
+	SearchBoosts *SearchBoostService
+	// Migrations reports data migration progress to admins; nil in demo mode
+	Migrations *migrations.Runner
+	// Moderation holds tracks made public for review; nil publishes immediately
+	Moderation *ModerationService
+	// TrackTransfer moves tracks between users for admins; nil when not wired
+	TrackTransfer *TrackTransferService
+	// UserImport provisions batches of users for admins; nil without Cognito
+	UserImport *UserImportService
+	// Operations undoes recent bulk edits; nil keeps no undo records
+	Operations *OperationService
+	// Previews hands out share links to preview clips; nil without a signing keyring
+	Previews *PreviewService
+	// Party runs guest DJ parties; nil without a signing keyring
+	Party *PartyService
+	// Rooms runs shared listening rooms; nil without a signing keyring
+	Rooms *ListeningRoomService
+	// Watermarks marks other users' downloads of protected tracks; nil
+	// without the watermark Lambda
+	Watermarks *WatermarkService
+	// Playback describes tracks' playback options in responses; nil leaves
+	// them out
+	Playback *PlaybackAvailabilityService
+	// Collections manages the saved views of each library; nil when not wired
+	Collections *CollectionService
+	// Discover builds the discover feed and its featured items; nil when not wired
+	Discover *DiscoverService
+	// UploadDebug assembles the support debug bundle of an upload; nil in demo mode
+	UploadDebug *UploadDebugService
+	// AccessLog keeps the access logs of tracks reached from outside their
+	// library; nil keeps none
+	AccessLog *AccessLogService
+	// Comparisons compares the libraries of consenting users; nil when not wired
+	Comparisons *LibraryComparisonService
+	// ArchiveSuggestions suggests deleting tracks unplayed for two years;
+	// nil when not wired
+	ArchiveSuggestions *ArchiveSuggestionService
+	// MediaFiles answers the presigned URLs of a local media store; nil when
+	// media is kept in S3
+	MediaFiles http.Handler
+	// Jobs queues the background work a self-hosted server runs in-process
+	// instead of on scheduled Lambdas; nil elsewhere
+	Jobs jobs.Queue
+	// CoverThumbnails makes missing cover thumbnails on the job workers; nil
+	// when jobs are not run
+	CoverThumbnails *CoverThumbnailService
+	// StatsRollup recomputes user stats on the job workers; nil when jobs are
+	// not run
+	StatsRollup *StatsRollupService
+	// UploadPipeline processes confirmed uploads on the job workers instead
+	// of Step Functions; nil when jobs are not run
+	UploadPipeline *UploadPipelineService
+
+	// users is the cache installed by CacheUsers, released by Close
+	users *UserCache
 }
 
 // NewServices creates a new Services instance with all dependencies
 func NewServices(
 	repo repository.Repository,
-	s3Repo repository.S3Repository,
+	s3Repo repository.MediaStore,
 	cloudfront repository.CloudFrontSigner,
 	mediaBucket string,
 	stepFunctionsARN string,
 ) *Services {
-	return &Services{
-		Track:    NewTrackService(repo, s3Repo),
-		Album:    NewAlbumService(repo, s3Repo),
-		Artist:   NewArtistService(repo, s3Repo),
-		User:     NewUserService(repo),
-		Playlist: NewPlaylistService(repo, s3Repo),
-		Tag:      NewTagService(repo),
-		Upload:   NewUploadService(repo, s3Repo, mediaBucket, stepFunctionsARN),
-		Stream:   NewStreamService(repo, cloudfront, s3Repo),
+	services := &Services{
+		Track:     NewTrackService(repo, s3Repo),
+		Album:     NewAlbumService(repo, s3Repo),
+		Artist:    NewArtistService(repo, s3Repo),
+		User:      NewUserService(repo),
+		Playlist:  NewPlaylistService(repo, s3Repo),
+		Tag:       NewTagService(repo),
+		Upload:    NewUploadService(repo, s3Repo, mediaBucket, stepFunctionsARN),
+		TagRepair: NewTagRepairService(repo),
+		Cleanup:   NewCleanupService(repo),
+		DJExport:  NewDJExportService(repo),
+		Stream:    NewStreamService(repo, cloudfront, s3Repo),
 		// Search service requires Nixiesearch client - initialized separately
 	}
+	services.DJImport = NewDJImportService(repo, services.Playlist, services.Tag)
+	return services
+}
+
+// CacheUsers routes the role and profile lookups of the user and admin
+// services through a shared UserCache with the given process TTL, so role
+// checks made by middleware and handlers during a request (and, within ttl,
+// across requests) read DynamoDB once. Call it after Admin is wired. Requests
+// only share lookups under a context from WithRequestUserCache.
+func (s *Services) CacheUsers(repo repository.Repository, ttl time.Duration) *UserCache {
+	cache := NewUserCache(repo.GetUser, ttl)
+	for _, svc := range []any{s.User, s.Admin} {
+		if aware, ok := svc.(UserCacheAware); ok {
+			aware.SetUserCache(cache)
+		}
+	}
+	s.users = cache
+	return cache
+}
+
+// Moderate holds changes to public track visibility for the moderation
+// workflow of svc. Call it after Track is wired.
+func (s *Services) Moderate(svc *ModerationService) {
+	if aware, ok := s.Track.(ModerationAware); ok {
+		aware.SetModerator(svc)
+	}
+	s.Moderation = svc
+}
+
+// ProtectDownloads watermarks other users' downloads of protected tracks
+// through svc. Call it after Stream is wired.
+func (s *Services) ProtectDownloads(svc *WatermarkService) {
+	if aware, ok := s.Stream.(WatermarkAware); ok {
+		aware.SetWatermarker(svc)
+	}
+	s.Watermarks = svc
+}
+
+// LogAccess records the streams, downloads and preview link opens of tracks
+// reached from outside their library through svc. Call it after Stream and
+// Previews are wired.
+func (s *Services) LogAccess(svc *AccessLogService) {
+	if aware, ok := s.Stream.(AccessLogAware); ok {
+		aware.SetAccessLog(svc)
+	}
+	if s.Previews != nil {
+		s.Previews.SetAccessLog(svc)
+	}
+	s.AccessLog = svc
+}
+
+// BoostSearch applies the pins and artist boosts of svc to search results.
+// Call it after Search is wired.
+func (s *Services) BoostSearch(svc *SearchBoostService) {
+	if aware, ok := s.Search.(SearchBoostAware); ok {
+		aware.SetBoosts(svc)
+	}
+	s.SearchBoosts = svc
+}
+
+// DescribePlayback adds the playback options svc describes to the tracks
+// returned by the track, playlist, search, collection and discover services.
+// Call it after Search, Collections and Discover are wired.
+func (s *Services) DescribePlayback(svc *PlaybackAvailabilityService) {
+	for _, target := range []any{s.Track, s.Playlist, s.Search} {
+		if aware, ok := target.(PlaybackAvailabilityAware); ok {
+			aware.SetPlaybackAvailability(svc)
+		}
+	}
+	if s.Collections != nil {
+		s.Collections.SetPlaybackAvailability(svc)
+	}
+	if s.Discover != nil {
+		s.Discover.SetPlaybackAvailability(svc)
+	}
+	s.Playback = svc
+}
+
+// RecordOperations keeps an undo record of the bulk edits of services that
+// support it. Call it after Cleanup and ArchiveSuggestions are wired.
+func (s *Services) RecordOperations(svc *OperationService) {
+	if aware, ok := s.Cleanup.(OperationAware); ok {
+		aware.SetOperations(svc)
+	}
+	if s.ArchiveSuggestions != nil {
+		s.ArchiveSuggestions.SetOperations(svc)
+	}
+	s.Operations = svc
+}
+
+// Close releases the caches held by the services. Call it after the last
+// request has been served.
+func (s *Services) Close(ctx context.Context) error {
+	if s.users != nil {
+		s.users.Close()
+	}
+	return nil
 }
@@ -2,11 +2,19 @@ package service
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sfn"
+	"github.com/aws/aws-sdk-go-v2/service/sfn/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
 )
 
+// maxExecutionPages bounds how many pages of executions ListExecutions reads
+// looking for matching names
+const maxExecutionPages = 50
+
 // SFNClientAdapter adapts the AWS SFN client to our StepFunctionsClient interface
 type SFNClientAdapter struct {
 	client *sfn.Client
@@ -33,3 +41,115 @@ func (a *SFNClientAdapter) StartExecution(ctx context.Context, input *StepFuncti
 		StartDate:    aws.ToTime(result.StartDate),
 	}, nil
 }
+
+// ListExecutions returns up to limit executions of a state machine whose
+// names start with namePrefix, newest first. Step Functions lists newest
+// first, so the search stops at the first execution started before since.
+func (a *SFNClientAdapter) ListExecutions(ctx context.Context, stateMachineARN, namePrefix string, since time.Time, limit int) ([]models.UploadExecution, error) {
+	input := &sfn.ListExecutionsInput{
+		StateMachineArn: aws.String(stateMachineARN),
+		MaxResults:      100,
+	}
+
+	var executions []models.UploadExecution
+	for page := 0; page < maxExecutionPages; page++ {
+		result, err := a.client.ListExecutions(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Executions {
+			if aws.ToTime(item.StartDate).Before(since) {
+				return executions, nil
+			}
+			if !strings.HasPrefix(aws.ToString(item.Name), namePrefix) {
+				continue
+			}
+			executions = append(executions, models.UploadExecution{
+				ExecutionARN: aws.ToString(item.ExecutionArn),
+				Name:         aws.ToString(item.Name),
+				Status:       string(item.Status),
+				StartedAt:    aws.ToTime(item.StartDate),
+				StoppedAt:    item.StopDate,
+			})
+			if len(executions) >= limit {
+				return executions, nil
+			}
+		}
+		if result.NextToken == nil {
+			break
+		}
+		input.NextToken = result.NextToken
+	}
+	return executions, nil
+}
+
+// GetExecutionHistory returns up to limit events of an execution, oldest
+// first, and whether there were more
+func (a *SFNClientAdapter) GetExecutionHistory(ctx context.Context, executionARN string, limit int) ([]models.UploadExecutionEvent, bool, error) {
+	input := &sfn.GetExecutionHistoryInput{
+		ExecutionArn: aws.String(executionARN),
+		MaxResults:   1000,
+	}
+
+	var events []models.UploadExecutionEvent
+	for {
+		result, err := a.client.GetExecutionHistory(ctx, input)
+		if err != nil {
+			return nil, false, err
+		}
+		for i, event := range result.Events {
+			if len(events) >= limit {
+				return events, i < len(result.Events) || result.NextToken != nil, nil
+			}
+			events = append(events, executionEvent(event))
+		}
+		if result.NextToken == nil {
+			return events, false, nil
+		}
+		input.NextToken = result.NextToken
+	}
+}
+
+// executionEvent keeps the parts of a history event support reads: the
+// state, the Lambda invoked and how it failed
+func executionEvent(e types.HistoryEvent) models.UploadExecutionEvent {
+	event := models.UploadExecutionEvent{
+		ID:        e.Id,
+		Type:      string(e.Type),
+		Timestamp: aws.ToTime(e.Timestamp),
+	}
+	failure := func(errorName, cause *string) {
+		event.Error = aws.ToString(errorName)
+		event.Cause = models.TruncateDebugData(aws.ToString(cause))
+	}
+
+	switch {
+	case e.StateEnteredEventDetails != nil:
+		event.State = aws.ToString(e.StateEnteredEventDetails.Name)
+		event.Data = models.TruncateDebugData(aws.ToString(e.StateEnteredEventDetails.Input))
+	case e.StateExitedEventDetails != nil:
+		event.State = aws.ToString(e.StateExitedEventDetails.Name)
+		event.Data = models.TruncateDebugData(aws.ToString(e.StateExitedEventDetails.Output))
+	case e.LambdaFunctionScheduledEventDetails != nil:
+		event.Resource = aws.ToString(e.LambdaFunctionScheduledEventDetails.Resource)
+	case e.TaskScheduledEventDetails != nil:
+		event.Resource = aws.ToString(e.TaskScheduledEventDetails.Resource)
+	case e.LambdaFunctionFailedEventDetails != nil:
+		failure(e.LambdaFunctionFailedEventDetails.Error, e.LambdaFunctionFailedEventDetails.Cause)
+	case e.LambdaFunctionTimedOutEventDetails != nil:
+		failure(e.LambdaFunctionTimedOutEventDetails.Error, e.LambdaFunctionTimedOutEventDetails.Cause)
+	case e.TaskFailedEventDetails != nil:
+		event.Resource = aws.ToString(e.TaskFailedEventDetails.Resource)
+		failure(e.TaskFailedEventDetails.Error, e.TaskFailedEventDetails.Cause)
+	case e.TaskTimedOutEventDetails != nil:
+		event.Resource = aws.ToString(e.TaskTimedOutEventDetails.Resource)
+		failure(e.TaskTimedOutEventDetails.Error, e.TaskTimedOutEventDetails.Cause)
+	case e.ExecutionFailedEventDetails != nil:
+		failure(e.ExecutionFailedEventDetails.Error, e.ExecutionFailedEventDetails.Cause)
+	case e.ExecutionTimedOutEventDetails != nil:
+		failure(e.ExecutionTimedOutEventDetails.Error, e.ExecutionTimedOutEventDetails.Cause)
+	case e.ExecutionAbortedEventDetails != nil:
+		failure(e.ExecutionAbortedEventDetails.Error, e.ExecutionAbortedEventDetails.Cause)
+	}
+	return event
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// ShareRepository defines the repository interface for cross-user track shares
+type ShareRepository interface {
+	CreateTrackShare(ctx context.Context, share models.TrackShare) error
+	GetTrackShare(ctx context.Context, recipientID, shareID string) (*models.TrackShare, error)
+	UpdateTrackShare(ctx context.Context, share models.TrackShare) error
+	ListReceivedTrackShares(ctx context.Context, recipientID string, filter models.TrackShareFilter) (*repository.PaginatedResult[models.TrackShare], error)
+	ListSentTrackShares(ctx context.Context, ownerID string, filter models.TrackShareFilter) (*repository.PaginatedResult[models.TrackShare], error)
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	CreateTrack(ctx context.Context, track models.Track) error
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+// ShareServiceImpl implements ShareService
+type ShareServiceImpl struct {
+	repo   ShareRepository
+	s3Repo repository.MediaStore
+}
+
+// NewShareService creates a new share service
+func NewShareService(repo ShareRepository, s3Repo repository.MediaStore) ShareService {
+	return &ShareServiceImpl{
+		repo:   repo,
+		s3Repo: s3Repo,
+	}
+}
+
+// ShareTrack offers one of the owner's tracks to another user, identified by email.
+// The recipient sees a pending share until they accept or decline it.
+func (s *ShareServiceImpl) ShareTrack(ctx context.Context, ownerID, trackID string, req models.CreateTrackShareRequest) (*models.TrackShareResponse, error) {
+	track, err := s.repo.GetTrack(ctx, ownerID, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+
+	email := strings.TrimSpace(req.RecipientEmail)
+	recipient, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == repository.ErrUserNotFound || err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("User", email)
+		}
+		return nil, err
+	}
+
+	if recipient.ID == ownerID {
+		return nil, models.NewValidationError("cannot share a track with yourself")
+	}
+
+	now := time.Now()
+	share := models.TrackShare{
+		ID:             uuid.New().String(),
+		TrackID:        track.ID,
+		OwnerID:        ownerID,
+		RecipientID:    recipient.ID,
+		RecipientEmail: email,
+		TrackTitle:     track.Title,
+		TrackArtist:    track.Artist,
+		FileSize:       track.FileSize,
+		Message:        req.Message,
+		Status:         models.ShareStatusPending,
+		Timestamps: models.Timestamps{
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	if err := s.repo.CreateTrackShare(ctx, share); err != nil {
+		return nil, err
+	}
+
+	response := share.ToResponse()
+	return &response, nil
+}
+
+// ListReceivedShares lists shares addressed to the user
+func (s *ShareServiceImpl) ListReceivedShares(ctx context.Context, userID string, filter models.TrackShareFilter) (*repository.PaginatedResult[models.TrackShareResponse], error) {
+	result, err := s.repo.ListReceivedTrackShares(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return toShareResponses(result), nil
+}
+
+// ListSentShares lists shares the user has sent to others
+func (s *ShareServiceImpl) ListSentShares(ctx context.Context, userID string, filter models.TrackShareFilter) (*repository.PaginatedResult[models.TrackShareResponse], error) {
+	result, err := s.repo.ListSentTrackShares(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return toShareResponses(result), nil
+}
+
+// AcceptShare copies the shared track's audio file, cover art and metadata into the
+// recipient's library as a new private track. The copy counts against the recipient's
+// storage quota and records its origin for auditing.
+func (s *ShareServiceImpl) AcceptShare(ctx context.Context, userID, shareID string) (*models.TrackResponse, error) {
+	share, err := s.getPendingShare(ctx, userID, shareID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.repo.GetTrack(ctx, share.OwnerID, share.TrackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Track", share.TrackID)
+		}
+		return nil, err
+	}
+
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil && err != repository.ErrNotFound {
+		return nil, err
+	}
+	if user != nil && exceedsStorageLimit(user, source.FileSize) {
+		return nil, models.ErrStorageLimitExceeded
+	}
+
+	now := time.Now()
+	track := copySharedTrack(*source, userID, uuid.New().String(), now)
+	track.Origin = &models.TrackOrigin{
+		ShareID:  share.ID,
+		OwnerID:  share.OwnerID,
+		TrackID:  share.TrackID,
+		SharedAt: share.CreatedAt,
+	}
+
+	if err := s.s3Repo.CopyObject(ctx, source.S3Key, track.S3Key); err != nil {
+		return nil, fmt.Errorf("failed to copy shared audio file: %w", err)
+	}
+
+	// Cover art is optional; a failed copy leaves the new track without artwork
+	if source.CoverArtKey != "" {
+		coverKey := fmt.Sprintf("covers/%s/%s%s", userID, track.ID, path.Ext(source.CoverArtKey))
+		if err := s.s3Repo.CopyObject(ctx, source.CoverArtKey, coverKey); err == nil {
+			track.CoverArtKey = coverKey
+			track.CoverStyle = copyCoverStyle(ctx, s.s3Repo, source.CoverStyle, coverThumbnailKey(userID, track.ID))
+		}
+	}
+
+	// The copy sorts in the recipient's collation order
+	if user != nil {
+		ctx = collation.WithLocale(ctx, user.Settings.Library.SortLocale)
+	}
+	if err := s.repo.CreateTrack(ctx, track); err != nil {
+		_ = s.s3Repo.DeleteObject(ctx, track.S3Key)
+		if track.CoverArtKey != "" {
+			_ = s.s3Repo.DeleteObject(ctx, track.CoverArtKey)
+			deleteCoverThumbnail(ctx, s.s3Repo, track.CoverStyle)
+		}
+		return nil, err
+	}
+
+	share.Status = models.ShareStatusAccepted
+	share.AcceptedTrackID = track.ID
+	share.RespondedAt = &now
+	if err := s.repo.UpdateTrackShare(ctx, *share); err != nil {
+		return nil, err
+	}
+
+	coverArtURL := ""
+	if track.CoverArtKey != "" {
+		if url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour); err == nil {
+			coverArtURL = url
+		}
+	}
+
+	response := track.ToResponse(coverArtURL)
+	return &response, nil
+}
+
+// DeclineShare marks a pending share as declined
+func (s *ShareServiceImpl) DeclineShare(ctx context.Context, userID, shareID string) (*models.TrackShareResponse, error) {
+	share, err := s.getPendingShare(ctx, userID, shareID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	share.Status = models.ShareStatusDeclined
+	share.RespondedAt = &now
+	if err := s.repo.UpdateTrackShare(ctx, *share); err != nil {
+		return nil, err
+	}
+
+	response := share.ToResponse()
+	return &response, nil
+}
+
+// getPendingShare loads a share addressed to the user and ensures it has not been answered yet
+func (s *ShareServiceImpl) getPendingShare(ctx context.Context, userID, shareID string) (*models.TrackShare, error) {
+	share, err := s.repo.GetTrackShare(ctx, userID, shareID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Share", shareID)
+		}
+		return nil, err
+	}
+
+	if !share.IsPending() {
+		return nil, models.NewConflictError(fmt.Sprintf("share has already been %s", strings.ToLower(string(share.Status))))
+	}
+
+	return share, nil
+}
+
+// copySharedTrack builds the recipient's copy of a shared track. Metadata and audio
+// analysis carry over; per-user state (plays, tags, visibility, lock, HLS output and
+// references to the owner's artist/album entities) starts fresh.
+func copySharedTrack(source models.Track, userID, trackID string, now time.Time) models.Track {
+	track := source
+	track.ID = trackID
+	track.UserID = userID
+	track.S3Key = fmt.Sprintf("media/%s/%s%s", userID, trackID, path.Ext(source.S3Key))
+	track.CoverArtKey = ""
+	track.CoverStyle = nil
+	track.SortLocale = ""
+	track.Artwork = nil
+	track.ArtistID = ""
+	track.Artists = nil
+	track.ArtistLegacy = ""
+	track.AlbumID = ""
+	track.PlayCount = 0
+	track.LastPlayed = nil
+	track.Tags = nil
+	track.HLSStatus = ""
+	track.HLSPlaylistKey = ""
+	track.HLSJobID = ""
+	track.HLSTranscodedAt = nil
+	track.PreviewStatus = ""
+	track.PreviewKey = ""
+	track.PreviewJobID = ""
+	track.WaveformURL = ""
+	track.Visibility = models.VisibilityPrivate
+	track.PublishedAt = nil
+	track.PendingVisibility = ""
+	track.ModerationStatus = ""
+	track.Locked = false
+	track.LockedAt = nil
+	track.FileReplacedAt = nil
+	track.ArchivedS3Key = ""
+	track.ArchiveExpiresAt = nil
+	track.OwnerDisplayName = ""
+	track.Timestamps = models.Timestamps{
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return track
+}
+
+func toShareResponses(result *repository.PaginatedResult[models.TrackShare]) *repository.PaginatedResult[models.TrackShareResponse] {
+	responses := make([]models.TrackShareResponse, 0, len(result.Items))
+	for _, share := range result.Items {
+		responses = append(responses, share.ToResponse())
+	}
+	return &repository.PaginatedResult[models.TrackShareResponse]{
+		Items:      responses,
+		NextCursor: result.NextCursor,
+		HasMore:    result.HasMore,
+	}
+}
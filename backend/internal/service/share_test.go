@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareService_ShareTrack(t *testing.T) {
+	req := models.CreateTrackShareRequest{RecipientEmail: "friend@example.com"}
+
+	t.Run("creates a pending share for the recipient", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.ShareRepository)
+
+		track := &models.Track{ID: "track-1", UserID: "owner-1", Title: "Night Drive", FileSize: 1024}
+		mockRepo.On("GetTrack", ctx, "owner-1", "track-1").Return(track, nil)
+		mockRepo.On("GetUserByEmail", ctx, "friend@example.com").Return(&models.User{ID: "friend-1"}, nil)
+		mockRepo.On("CreateTrackShare", ctx, mock.MatchedBy(func(s models.TrackShare) bool {
+			return s.RecipientID == "friend-1" && s.OwnerID == "owner-1" && s.Status == models.ShareStatusPending
+		})).Return(nil)
+
+		svc := NewShareService(mockRepo, new(mocks.MediaStore))
+		resp, err := svc.ShareTrack(ctx, "owner-1", "track-1", req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "PENDING", resp.Status)
+		assert.Equal(t, "Night Drive", resp.TrackTitle)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects sharing with yourself", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.ShareRepository)
+
+		mockRepo.On("GetTrack", ctx, "owner-1", "track-1").Return(&models.Track{ID: "track-1", UserID: "owner-1"}, nil)
+		mockRepo.On("GetUserByEmail", ctx, "friend@example.com").Return(&models.User{ID: "owner-1"}, nil)
+
+		svc := NewShareService(mockRepo, new(mocks.MediaStore))
+		_, err := svc.ShareTrack(ctx, "owner-1", "track-1", req)
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 400, apiErr.StatusCode)
+		mockRepo.AssertNotCalled(t, "CreateTrackShare", mock.Anything, mock.Anything)
+	})
+}
+
+func TestShareService_AcceptShare(t *testing.T) {
+	sharedAt := time.Now().Add(-time.Hour)
+	pendingShare := func() *models.TrackShare {
+		return &models.TrackShare{
+			ID:          "share-1",
+			TrackID:     "track-1",
+			OwnerID:     "owner-1",
+			RecipientID: "friend-1",
+			Status:      models.ShareStatusPending,
+			Timestamps:  models.Timestamps{CreatedAt: sharedAt},
+		}
+	}
+	source := &models.Track{
+		ID:        "track-1",
+		UserID:    "owner-1",
+		Title:     "Night Drive",
+		FileSize:  1024,
+		S3Key:     "media/owner-1/track-1.flac",
+		PlayCount: 42,
+		Tags:      []string{"favorites"},
+		Locked:    true,
+	}
+
+	t.Run("copies the track into the recipient library with its origin", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.ShareRepository)
+
+		mockRepo.On("GetTrackShare", ctx, "friend-1", "share-1").Return(pendingShare(), nil)
+		mockRepo.On("GetTrack", ctx, "owner-1", "track-1").Return(source, nil)
+		mockRepo.On("GetUser", ctx, "friend-1").Return(&models.User{ID: "friend-1", StorageLimit: -1}, nil)
+		mockRepo.On("CreateTrack", mock.Anything, mock.MatchedBy(func(tr models.Track) bool {
+			return tr.UserID == "friend-1" && tr.ID != "track-1" &&
+				tr.S3Key == "media/friend-1/"+tr.ID+".flac" &&
+				tr.PlayCount == 0 && tr.Tags == nil && !tr.Locked &&
+				tr.Origin != nil && tr.Origin.OwnerID == "owner-1" && tr.Origin.TrackID == "track-1"
+		})).Return(nil)
+		mockRepo.On("UpdateTrackShare", mock.Anything, mock.MatchedBy(func(s models.TrackShare) bool {
+			return s.Status == models.ShareStatusAccepted && s.AcceptedTrackID != "" && s.RespondedAt != nil
+		})).Return(nil)
+
+		mockS3 := new(mocks.MediaStore)
+		mockS3.On("CopyObject", ctx, "media/owner-1/track-1.flac", mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "media/friend-1/")
+		})).Return(nil)
+
+		svc := NewShareService(mockRepo, mockS3)
+		resp, err := svc.AcceptShare(ctx, "friend-1", "share-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Night Drive", resp.Title)
+		assert.Equal(t, "private", resp.Visibility)
+		require.NotNil(t, resp.Origin)
+		assert.Equal(t, "share-1", resp.Origin.ShareID)
+		mockRepo.AssertExpectations(t)
+		mockS3.AssertExpectations(t)
+	})
+
+	t.Run("respects the recipient storage quota", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.ShareRepository)
+
+		mockRepo.On("GetTrackShare", ctx, "friend-1", "share-1").Return(pendingShare(), nil)
+		mockRepo.On("GetTrack", ctx, "owner-1", "track-1").Return(source, nil)
+		mockRepo.On("GetUser", ctx, "friend-1").Return(&models.User{ID: "friend-1", StorageLimit: 2048, StorageUsed: 2000}, nil)
+
+		svc := NewShareService(mockRepo, new(mocks.MediaStore))
+		_, err := svc.AcceptShare(ctx, "friend-1", "share-1")
+
+		assert.ErrorIs(t, err, models.ErrStorageLimitExceeded)
+		mockRepo.AssertNotCalled(t, "CreateTrack", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a share that was already answered", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.ShareRepository)
+
+		declined := pendingShare()
+		declined.Status = models.ShareStatusDeclined
+		mockRepo.On("GetTrackShare", ctx, "friend-1", "share-1").Return(declined, nil)
+
+		svc := NewShareService(mockRepo, new(mocks.MediaStore))
+		_, err := svc.AcceptShare(ctx, "friend-1", "share-1")
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 409, apiErr.StatusCode)
+	})
+}
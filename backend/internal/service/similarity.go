@@ -3,18 +3,19 @@ package service
 import (
 	"context"
 	"fmt"
-	"math"
 	"sort"
+	"time"
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
 	"github.com/gvasels/personal-music-searchengine/internal/search"
+	"github.com/gvasels/personal-music-searchengine/internal/vector"
 )
 
 // SimilarityOptions configures the similar tracks search.
 type SimilarityOptions struct {
 	Limit            int     `json:"limit"`            // Maximum number of similar tracks to return
-	Mode             string  `json:"mode"`             // "semantic", "features", "combined"
+	Mode             string  `json:"mode"`             // "semantic", "features", "combined", "embedding"
 	MinSimilarity    float64 `json:"minSimilarity"`    // Minimum similarity score (0.0-1.0)
 	IncludeSameAlbum bool    `json:"includeSameAlbum"` // Whether to include tracks from same album
 }
@@ -75,11 +76,18 @@ type MixableTracksResponse struct {
 	Mixable     []MixableTrack       `json:"mixable"`
 }
 
-// SimilarityService finds similar and mixable tracks.
+// SimilarityService finds similar and mixable tracks. When the repository
+// implements TrackNeighborRepository, each track's best neighbors are cached
+// so repeat queries read only those tracks; otherwise, and on a cache miss,
+// the library is read once and only its candidate key/BPM buckets are scored.
+// When the repository implements EmbeddingRepository, the "embedding" mode
+// ranks tracks by the cosine similarity of their stored embeddings.
 type SimilarityService struct {
 	searchClient     *search.Client
 	repo             repository.Repository
 	embeddingService *EmbeddingService
+	embeddings       *EmbeddingStore
+	now              func() time.Time
 }
 
 // NewSimilarityService creates a new SimilarityService.
@@ -88,11 +96,16 @@ func NewSimilarityService(
 	repo repository.Repository,
 	embeddingService *EmbeddingService,
 ) *SimilarityService {
-	return &SimilarityService{
+	s := &SimilarityService{
 		searchClient:     searchClient,
 		repo:             repo,
 		embeddingService: embeddingService,
+		now:              time.Now,
 	}
+	if store, ok := repo.(EmbeddingRepository); ok {
+		s.embeddings = NewEmbeddingStore(store, embeddingService)
+	}
+	return s
 }
 
 // FindSimilarTracks finds tracks similar to the given track.
@@ -115,68 +128,35 @@ func (s *SimilarityService) FindSimilarTracks(
 		opts.MinSimilarity = 0.5
 	}
 
-	// Get all user tracks for comparison
-	// In a production system, this would use vector search
-	allTracks, err := s.getAllUserTracks(ctx, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user tracks: %w", err)
+	if opts.Mode == "embedding" {
+		similar, err := s.findEmbeddingSimilar(ctx, userID, sourceTrack, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &SimilarTracksResponse{
+			SourceTrack:  sourceTrack.ToResponse(""),
+			Similar:      similar,
+			TotalMatches: len(similar),
+		}, nil
 	}
 
-	// Calculate similarity for each track
 	var candidates []SimilarTrack
-	for _, track := range allTracks {
-		// Skip the source track
-		if track.ID == sourceTrack.ID {
-			continue
-		}
-
-		// Skip same album if not wanted
-		if !opts.IncludeSameAlbum && track.Album == sourceTrack.Album && track.Album != "" {
-			continue
-		}
-
-		// Calculate similarity based on mode
-		var similarity float64
-		var matchReasons []string
-
-		switch opts.Mode {
-		case "semantic":
-			similarity, matchReasons = s.calculateSemanticSimilarity(sourceTrack, &track)
-		case "features":
-			similarity, matchReasons = s.calculateFeatureSimilarity(sourceTrack, &track)
-		default: // "combined"
-			semanticSim, semanticReasons := s.calculateSemanticSimilarity(sourceTrack, &track)
-			featureSim, featureReasons := s.calculateFeatureSimilarity(sourceTrack, &track)
-			// Weight: 60% semantic, 40% features
-			similarity = semanticSim*0.6 + featureSim*0.4
-			matchReasons = append(matchReasons, semanticReasons...)
-			matchReasons = append(matchReasons, featureReasons...)
+	cached := false
+	if neighbors := s.cachedNeighbors(ctx, userID, sourceTrack); neighbors != nil {
+		candidates, cached, err = s.similarFromCache(ctx, userID, sourceTrack, neighbors, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get neighbor tracks: %w", err)
 		}
+	}
 
-		if similarity >= opts.MinSimilarity {
-			bpmDiff := 0
-			if sourceTrack.BPM > 0 && track.BPM > 0 {
-				bpmDiff = sourceTrack.BPM - track.BPM
-				if bpmDiff < 0 {
-					bpmDiff = -bpmDiff
-				}
-			}
-
-			candidates = append(candidates, SimilarTrack{
-				Track:         track.ToResponse(""),
-				Similarity:    similarity,
-				BPMDiff:       bpmDiff,
-				KeyCompatible: IsKeyCompatible(sourceTrack.KeyCamelot, track.KeyCamelot),
-				MatchReasons:  matchReasons,
-			})
+	if !cached {
+		buckets, err := s.loadBuckets(ctx, userID, sourceTrack)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user tracks: %w", err)
 		}
+		candidates = s.rankSimilar(sourceTrack, buckets.similarCandidates(sourceTrack), opts)
 	}
 
-	// Sort by similarity (descending)
-	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].Similarity > candidates[j].Similarity
-	})
-
 	// Limit results
 	if len(candidates) > opts.Limit {
 		candidates = candidates[:opts.Limit]
@@ -189,6 +169,78 @@ func (s *SimilarityService) FindSimilarTracks(
 	}, nil
 }
 
+// rankSimilar scores candidates against the source track and returns those
+// meeting opts.MinSimilarity, most similar first
+func (s *SimilarityService) rankSimilar(sourceTrack *models.Track, tracks []*models.Track, opts SimilarityOptions) []SimilarTrack {
+	var candidates []SimilarTrack
+	for _, track := range tracks {
+		if result, ok := s.scoreSimilar(sourceTrack, track, opts); ok {
+			candidates = append(candidates, result)
+		}
+	}
+
+	// Sort by similarity (descending), ties by ID so cached lists rank the same
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Similarity != candidates[j].Similarity {
+			return candidates[i].Similarity > candidates[j].Similarity
+		}
+		return candidates[i].Track.ID < candidates[j].Track.ID
+	})
+	return candidates
+}
+
+// scoreSimilar scores one track against the source track, reporting false
+// when it is excluded or scores below opts.MinSimilarity
+func (s *SimilarityService) scoreSimilar(sourceTrack, track *models.Track, opts SimilarityOptions) (SimilarTrack, bool) {
+	// Skip the source track
+	if track.ID == sourceTrack.ID {
+		return SimilarTrack{}, false
+	}
+
+	// Skip same album if not wanted
+	if !opts.IncludeSameAlbum && track.Album == sourceTrack.Album && track.Album != "" {
+		return SimilarTrack{}, false
+	}
+
+	// Calculate similarity based on mode
+	var similarity float64
+	var matchReasons []string
+
+	switch opts.Mode {
+	case "semantic":
+		similarity, matchReasons = s.calculateSemanticSimilarity(sourceTrack, track)
+	case "features":
+		similarity, matchReasons = s.calculateFeatureSimilarity(sourceTrack, track)
+	default: // "combined"
+		semanticSim, semanticReasons := s.calculateSemanticSimilarity(sourceTrack, track)
+		featureSim, featureReasons := s.calculateFeatureSimilarity(sourceTrack, track)
+		// Weight: 60% semantic, 40% features
+		similarity = semanticSim*0.6 + featureSim*0.4
+		matchReasons = append(matchReasons, semanticReasons...)
+		matchReasons = append(matchReasons, featureReasons...)
+	}
+
+	if similarity <= 0 || similarity < opts.MinSimilarity {
+		return SimilarTrack{}, false
+	}
+
+	return SimilarTrack{
+		Track:         track.ToResponse(""),
+		Similarity:    similarity,
+		BPMDiff:       bpmDifference(sourceTrack.BPM, track.BPM),
+		KeyCompatible: IsKeyCompatible(sourceTrack.KeyCamelot, track.KeyCamelot),
+		MatchReasons:  matchReasons,
+	}, true
+}
+
+// bpmDifference returns the absolute BPM difference, or 0 when either BPM is unknown
+func bpmDifference(a, b int) int {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	return abs(a - b)
+}
+
 // FindMixableTracks finds tracks that can be DJ-mixed with the given track.
 func (s *SimilarityService) FindMixableTracks(
 	ctx context.Context,
@@ -212,61 +264,30 @@ func (s *SimilarityService) FindMixableTracks(
 		opts.KeyMode = "harmonic"
 	}
 
-	// Get all user tracks for comparison
-	allTracks, err := s.getAllUserTracks(ctx, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user tracks: %w", err)
-	}
-
-	// Filter for mixable tracks
 	var candidates []MixableTrack
-	for _, track := range allTracks {
-		// Skip the source track
-		if track.ID == sourceTrack.ID {
-			continue
+	cached := false
+	if neighbors := s.cachedNeighbors(ctx, userID, sourceTrack); neighbors != nil {
+		candidates, cached, err = s.mixableFromCache(ctx, userID, sourceTrack, neighbors, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get neighbor tracks: %w", err)
 		}
+	}
 
-		// Check BPM compatibility
-		bpmDiff, bpmCompatible := GetBPMCompatibility(sourceTrack.BPM, track.BPM, opts.BPMTolerance)
-		if !bpmCompatible && sourceTrack.BPM > 0 && track.BPM > 0 {
-			continue
+	if !cached {
+		tracks, indexed, err := s.queryMixCandidates(ctx, userID, sourceTrack, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query harmonic index: %w", err)
 		}
-
-		// Check key compatibility
-		keyCompatible := true
-		keyTransition := ""
-		if opts.KeyMode != "any" && sourceTrack.KeyCamelot != "" && track.KeyCamelot != "" {
-			if opts.KeyMode == "exact" {
-				keyCompatible = sourceTrack.KeyCamelot == track.KeyCamelot
-				if keyCompatible {
-					keyTransition = "Same Key"
-				}
-			} else { // "harmonic"
-				keyCompatible = IsKeyCompatible(sourceTrack.KeyCamelot, track.KeyCamelot)
-				keyTransition = GetKeyTransition(sourceTrack.KeyCamelot, track.KeyCamelot)
+		if !indexed {
+			buckets, err := s.loadBuckets(ctx, userID, sourceTrack)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get user tracks: %w", err)
 			}
+			tracks = buckets.mixCandidates(sourceTrack, opts)
 		}
-
-		if !keyCompatible {
-			continue
-		}
-
-		// Calculate mix score (0.0-1.0)
-		mixScore := s.calculateMixScore(sourceTrack, &track, bpmDiff)
-
-		candidates = append(candidates, MixableTrack{
-			Track:         track.ToResponse(""),
-			BPMDiff:       bpmDiff,
-			KeyTransition: keyTransition,
-			MixScore:      mixScore,
-		})
+		candidates = s.rankMixable(sourceTrack, tracks, opts)
 	}
 
-	// Sort by mix score (descending)
-	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].MixScore > candidates[j].MixScore
-	})
-
 	// Limit results
 	if len(candidates) > opts.Limit {
 		candidates = candidates[:opts.Limit]
@@ -278,6 +299,67 @@ func (s *SimilarityService) FindMixableTracks(
 	}, nil
 }
 
+// rankMixable returns the candidates that can be mixed with the source track,
+// best mix first
+func (s *SimilarityService) rankMixable(sourceTrack *models.Track, tracks []*models.Track, opts MixingOptions) []MixableTrack {
+	var candidates []MixableTrack
+	for _, track := range tracks {
+		if result, ok := s.scoreMixable(sourceTrack, track, opts); ok {
+			candidates = append(candidates, result)
+		}
+	}
+
+	// Sort by mix score (descending), ties by ID so cached lists rank the same
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].MixScore != candidates[j].MixScore {
+			return candidates[i].MixScore > candidates[j].MixScore
+		}
+		return candidates[i].Track.ID < candidates[j].Track.ID
+	})
+	return candidates
+}
+
+// scoreMixable scores one track for mixing with the source track, reporting
+// false when its BPM or key is incompatible
+func (s *SimilarityService) scoreMixable(sourceTrack, track *models.Track, opts MixingOptions) (MixableTrack, bool) {
+	// Skip the source track
+	if track.ID == sourceTrack.ID {
+		return MixableTrack{}, false
+	}
+
+	// Check BPM compatibility
+	bpmDiff, bpmCompatible := GetBPMCompatibility(sourceTrack.BPM, track.BPM, opts.BPMTolerance)
+	if !bpmCompatible && sourceTrack.BPM > 0 && track.BPM > 0 {
+		return MixableTrack{}, false
+	}
+
+	// Check key compatibility
+	keyCompatible := true
+	keyTransition := ""
+	if opts.KeyMode != "any" && sourceTrack.KeyCamelot != "" && track.KeyCamelot != "" {
+		if opts.KeyMode == "exact" {
+			keyCompatible = sourceTrack.KeyCamelot == track.KeyCamelot
+			if keyCompatible {
+				keyTransition = "Same Key"
+			}
+		} else { // "harmonic"
+			keyCompatible = IsKeyCompatible(sourceTrack.KeyCamelot, track.KeyCamelot)
+			keyTransition = GetKeyTransition(sourceTrack.KeyCamelot, track.KeyCamelot)
+		}
+	}
+
+	if !keyCompatible {
+		return MixableTrack{}, false
+	}
+
+	return MixableTrack{
+		Track:         track.ToResponse(""),
+		BPMDiff:       bpmDiff,
+		KeyTransition: keyTransition,
+		MixScore:      s.calculateMixScore(sourceTrack, track, bpmDiff), // 0.0-1.0
+	}, true
+}
+
 // calculateSemanticSimilarity calculates similarity based on metadata text.
 // In a full implementation, this would use actual vector embeddings.
 func (s *SimilarityService) calculateSemanticSimilarity(track1, track2 *models.Track) (float64, []string) {
@@ -368,13 +450,15 @@ func (s *SimilarityService) calculateMixScore(track1, track2 *models.Track, bpmD
 		score += 0.25 // Neutral score if BPM unknown
 	}
 
-	// Key compatibility (40% weight)
+	// Key compatibility (40% weight), discounted for low-confidence keys
 	if track1.KeyCamelot != "" && track2.KeyCamelot != "" {
+		keyScore := 0.0
 		if track1.KeyCamelot == track2.KeyCamelot {
-			score += 0.4 // Perfect key match
+			keyScore = 0.4 // Perfect key match
 		} else if IsKeyCompatible(track1.KeyCamelot, track2.KeyCamelot) {
-			score += 0.35 // Harmonic match
+			keyScore = 0.35 // Harmonic match
 		}
+		score += discountKeyScore(keyScore, 0.2, track1, track2)
 	} else {
 		score += 0.2 // Neutral score if key unknown
 	}
@@ -387,8 +471,7 @@ func (s *SimilarityService) calculateMixScore(track1, track2 *models.Track, bpmD
 	return score
 }
 
-// getAllUserTracks fetches all tracks for a user.
-// In production, this would use pagination and possibly caching.
+// getAllUserTracks fetches all tracks for a user, page by page.
 func (s *SimilarityService) getAllUserTracks(ctx context.Context, userID string) ([]models.Track, error) {
 	var allTracks []models.Track
 	cursor := ""
@@ -416,23 +499,9 @@ func (s *SimilarityService) getAllUserTracks(ctx context.Context, userID string)
 }
 
 // CosineSimilarity calculates the cosine similarity between two vectors.
+// To rank many vectors against one query, use a vector.Index instead.
 func CosineSimilarity(a, b []float32) float64 {
-	if len(a) != len(b) || len(a) == 0 {
-		return 0
-	}
-
-	var dotProduct, normA, normB float64
-	for i := range a {
-		dotProduct += float64(a[i]) * float64(b[i])
-		normA += float64(a[i]) * float64(a[i])
-		normB += float64(b[i]) * float64(b[i])
-	}
-
-	if normA == 0 || normB == 0 {
-		return 0
-	}
-
-	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+	return vector.Cosine(a, b)
 }
 
 // countOverlappingTags counts how many tags appear in both lists.
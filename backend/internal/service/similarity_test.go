@@ -3,298 +3,18 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockSimilarityRepository is a mock implementation of repository.Repository for similarity tests
-type MockSimilarityRepository struct {
-	mock.Mock
-}
-
-func (m *MockSimilarityRepository) GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, userID, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-func (m *MockSimilarityRepository) GetTrackByID(ctx context.Context, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-func (m *MockSimilarityRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error) {
-	args := m.Called(ctx, userID, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Track]), args.Error(1)
-}
-
-// Implement other repository methods as stubs
-func (m *MockSimilarityRepository) CreateTrack(ctx context.Context, track models.Track) error {
-	return nil
-}
-func (m *MockSimilarityRepository) UpdateTrack(ctx context.Context, track models.Track) error {
-	return nil
-}
-func (m *MockSimilarityRepository) DeleteTrack(ctx context.Context, userID, trackID string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetAlbum(ctx context.Context, userID, albumID string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) ListAlbums(ctx context.Context, userID string, filter models.AlbumFilter) (*repository.PaginatedResult[models.Album], error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) CreateAlbum(ctx context.Context, album models.Album) error {
-	return nil
-}
-func (m *MockSimilarityRepository) UpdateAlbum(ctx context.Context, album models.Album) error {
-	return nil
-}
-func (m *MockSimilarityRepository) DeleteAlbum(ctx context.Context, userID, albumID string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) CreateUser(ctx context.Context, user models.User) error {
-	return nil
-}
-func (m *MockSimilarityRepository) UpdateUser(ctx context.Context, user models.User) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetPlaylist(ctx context.Context, userID, playlistID string) (*models.Playlist, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) CreatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockSimilarityRepository) UpdatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockSimilarityRepository) DeletePlaylist(ctx context.Context, userID, playlistID string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) ListUploads(ctx context.Context, userID string, filter models.UploadFilter) (*repository.PaginatedResult[models.Upload], error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) CreateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockSimilarityRepository) UpdateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetTag(ctx context.Context, userID, tagName string) (*models.Tag, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) CreateTag(ctx context.Context, tag models.Tag) error {
-	return nil
-}
-func (m *MockSimilarityRepository) UpdateTag(ctx context.Context, tag models.Tag) error {
-	return nil
-}
-func (m *MockSimilarityRepository) DeleteTag(ctx context.Context, userID, tagName string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) AddTagsToTrack(ctx context.Context, userID, trackID string, tagNames []string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetTrackTags(ctx context.Context, userID, trackID string) ([]string, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) GetTracksByTag(ctx context.Context, userID, tagName string) ([]models.Track, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*repository.PaginatedResult[models.Artist], error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockSimilarityRepository) UpdateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockSimilarityRepository) DeleteArtist(ctx context.Context, userID, artistID string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) BatchGetArtists(ctx context.Context, userID string, artistIDs []string) (map[string]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) SearchArtists(ctx context.Context, userID, query string, limit int) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) GetArtistTrackCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockSimilarityRepository) GetArtistAlbumCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockSimilarityRepository) GetArtistTotalPlays(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockSimilarityRepository) ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.Track, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) ListAlbumsByArtist(ctx context.Context, userID, artist string) ([]models.Album, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) UpdateAlbumStats(ctx context.Context, userID, albumID string, trackCount, totalDuration int) error {
-	return nil
-}
-func (m *MockSimilarityRepository) UpdateUserStats(ctx context.Context, userID string, storageUsed int64, trackCount, albumCount, playlistCount int) error {
-	return nil
-}
-func (m *MockSimilarityRepository) SearchPlaylists(ctx context.Context, userID, query string, limit int) ([]models.Playlist, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string, position int) error {
-	return nil
-}
-func (m *MockSimilarityRepository) RemoveTracksFromPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) ReorderPlaylistTracks(ctx context.Context, playlistID string, tracks []models.PlaylistTrack) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) UpdateUploadStatus(ctx context.Context, userID, uploadID string, status models.UploadStatus, errorMsg string, trackID string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) UpdateUploadStep(ctx context.Context, userID, uploadID string, step models.ProcessingStep, success bool) error {
-	return nil
-}
-func (m *MockSimilarityRepository) ListUploadsByStatus(ctx context.Context, status models.UploadStatus) ([]models.Upload, error) {
-	return nil, nil
-}
-
-// User role methods
-func (m *MockSimilarityRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	return nil
-}
-func (m *MockSimilarityRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
-	return nil, nil
-}
-
-// Playlist visibility methods
-func (m *MockSimilarityRepository) UpdatePlaylistVisibility(ctx context.Context, userID, playlistID string, visibility models.PlaylistVisibility) error {
-	return nil
-}
-func (m *MockSimilarityRepository) ListPublicPlaylists(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-
-// ArtistProfile methods
-func (m *MockSimilarityRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockSimilarityRepository) DeleteArtistProfile(ctx context.Context, userID string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.ArtistProfile], error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Follow methods
-func (m *MockSimilarityRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
-	return nil
-}
-func (m *MockSimilarityRepository) DeleteFollow(ctx context.Context, followerID, followedID string) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetFollow(ctx context.Context, followerID, followedID string) (*models.Follow, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Admin-related methods for track visibility
-func (m *MockSimilarityRepository) ListPublicTracks(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) UpdateTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error {
-	return nil
-}
-func (m *MockSimilarityRepository) SearchUsers(ctx context.Context, query string, limit int) ([]models.User, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) SetUserDisabled(ctx context.Context, userID string, disabled bool) error {
-	return nil
-}
-func (m *MockSimilarityRepository) GetUserDisplayName(ctx context.Context, userID string) (string, error) {
-	return "", nil
-}
-func (m *MockSimilarityRepository) GetFollowerCount(ctx context.Context, userID string) (int, error) {
-	return 0, nil
-}
-
-// User settings methods
-func (m *MockSimilarityRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) GetUserByCognitoID(ctx context.Context, cognitoID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) SearchUsersByEmail(ctx context.Context, emailPrefix string, limit int, cursor string) ([]repository.UserSearchResult, string, error) {
-	return nil, "", nil
-}
-func (m *MockSimilarityRepository) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
-	return nil, nil
-}
-func (m *MockSimilarityRepository) UpdateUserSettings(ctx context.Context, userID string, update *repository.UserSettingsUpdate) (*models.UserSettings, error) {
-	return nil, nil
-}
-
 // Helper function to create test tracks
 func createSimilarityTestTrack(id, artist, album, genre, keyCamelot string, bpm int, tags []string) models.Track {
 	return models.Track{
@@ -328,7 +48,7 @@ func TestDefaultMixingOptions(t *testing.T) {
 }
 
 func TestNewSimilarityService(t *testing.T) {
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	svc := NewSimilarityService(nil, mockRepo, nil)
 
@@ -460,7 +180,7 @@ func TestFindSimilarTracks_Success(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "Rock", "8A", 120, []string{"energetic"})
 	similarTrack := createSimilarityTestTrack("track-2", "Artist A", "Album 2", "Rock", "8A", 122, []string{"energetic"})
@@ -486,7 +206,7 @@ func TestFindSimilarTracks_SourceTrackNotFound(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "nonexistent"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	mockRepo.On("GetTrack", ctx, userID, trackID).Return(nil, errors.New("track not found"))
 
@@ -502,7 +222,7 @@ func TestFindSimilarTracks_ExcludeSameAlbum(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "Rock", "8A", 120, nil)
 	sameAlbumTrack := createSimilarityTestTrack("track-2", "Artist A", "Album 1", "Rock", "8A", 122, nil)
@@ -531,7 +251,7 @@ func TestFindSimilarTracks_SemanticMode(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "Rock", "8A", 120, []string{"rock"})
 	similarTrack := createSimilarityTestTrack("track-2", "Artist A", "Album 2", "Rock", "1B", 80, []string{"rock"})
@@ -559,7 +279,7 @@ func TestFindSimilarTracks_FeaturesMode(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "Rock", "8A", 120, nil)
 	similarTrack := createSimilarityTestTrack("track-2", "Artist B", "Album 2", "Jazz", "8A", 122, nil)
@@ -587,7 +307,7 @@ func TestFindMixableTracks_Success(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "House", "8A", 128, nil)
 	mixableTrack := createSimilarityTestTrack("track-2", "Artist B", "Album 2", "House", "8A", 130, nil)
@@ -620,7 +340,7 @@ func TestFindMixableTracks_SourceTrackNotFound(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "nonexistent"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	mockRepo.On("GetTrack", ctx, userID, trackID).Return(nil, errors.New("track not found"))
 
@@ -636,7 +356,7 @@ func TestFindMixableTracks_ExactKeyMode(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "House", "8A", 128, nil)
 	sameKeyTrack := createSimilarityTestTrack("track-2", "Artist B", "Album 2", "House", "8A", 130, nil)
@@ -666,7 +386,7 @@ func TestFindMixableTracks_AnyKeyMode(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "House", "8A", 128, nil)
 	differentKeyTrack := createSimilarityTestTrack("track-2", "Artist B", "Album 2", "House", "1B", 130, nil)
@@ -693,7 +413,7 @@ func TestFindMixableTracks_BPMTolerance(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "House", "8A", 128, nil)
 	closeTrack := createSimilarityTestTrack("track-2", "Artist B", "Album 2", "House", "8A", 130, nil)    // 2 BPM diff
@@ -733,7 +453,7 @@ func TestFindSimilarTracks_Limit(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "Rock", "8A", 120, nil)
 
@@ -773,7 +493,7 @@ func TestFindMixableTracks_Limit(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "House", "8A", 128, nil)
 
@@ -812,7 +532,7 @@ func TestFindSimilarTracks_ListTracksError(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "Rock", "8A", 120, nil)
 
@@ -831,7 +551,7 @@ func TestFindMixableTracks_ListTracksError(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "House", "8A", 128, nil)
 
@@ -850,7 +570,7 @@ func TestSimilarTrack_KeyCompatibleFlag(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "Rock", "8A", 120, nil)
 	harmonicTrack := createSimilarityTestTrack("track-2", "Artist A", "Album 2", "Rock", "7A", 122, nil) // Harmonic
@@ -885,7 +605,7 @@ func TestFindSimilarTracks_DefaultOptions(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "Rock", "8A", 120, nil)
 
@@ -909,7 +629,7 @@ func TestFindMixableTracks_DefaultOptions(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-123"
 	trackID := "track-1"
-	mockRepo := new(MockSimilarityRepository)
+	mockRepo := new(mocks.Repository)
 
 	sourceTrack := createSimilarityTestTrack("track-1", "Artist A", "Album 1", "House", "8A", 128, nil)
 
@@ -928,3 +648,79 @@ func TestFindMixableTracks_DefaultOptions(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, result)
 }
+
+// Benchmark tests
+
+// randomVectors returns n deterministic embeddings of dims dimensions
+func randomVectors(n, dims int) [][]float32 {
+	rng := rand.New(rand.NewSource(1))
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		vectors[i] = make([]float32, dims)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.Float32()*2 - 1
+		}
+	}
+	return vectors
+}
+
+func BenchmarkCosineSimilarity(b *testing.B) {
+	for _, dims := range []int{128, 768, 1536} {
+		b.Run(fmt.Sprintf("dims=%d", dims), func(b *testing.B) {
+			vectors := randomVectors(2, dims)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				CosineSimilarity(vectors[0], vectors[1])
+			}
+		})
+	}
+}
+
+// BenchmarkCosineSimilarity_Library scores one query embedding against a
+// whole library, which is what semantic similarity does per request
+func BenchmarkCosineSimilarity_Library(b *testing.B) {
+	const tracks, dims = 10000, 768
+	vectors := randomVectors(tracks+1, dims)
+	query, library := vectors[0], vectors[1:]
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, v := range library {
+			CosineSimilarity(query, v)
+		}
+	}
+}
+
+func BenchmarkCountOverlappingTags(b *testing.B) {
+	for _, size := range []int{5, 50} {
+		b.Run(fmt.Sprintf("tags=%d", size), func(b *testing.B) {
+			tags1 := make([]string, size)
+			tags2 := make([]string, size)
+			for i := 0; i < size; i++ {
+				tags1[i] = fmt.Sprintf("tag-%d", i)
+				tags2[i] = fmt.Sprintf("tag-%d", i+size/2)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				countOverlappingTags(tags1, tags2)
+			}
+		})
+	}
+}
+
+func BenchmarkCalculateSimilarity(b *testing.B) {
+	svc := NewSimilarityService(nil, nil, nil)
+	source := createSimilarityTestTrack("t1", "Artist A", "Album 1", "House", "8A", 128, []string{"deep", "vocal", "summer", "peak-time"})
+	candidate := createSimilarityTestTrack("t2", "Artist A", "Album 2", "House", "9A", 126, []string{"deep", "summer", "warm-up"})
+
+	b.Run("semantic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			svc.calculateSemanticSimilarity(&source, &candidate)
+		}
+	})
+	b.Run("features", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			svc.calculateFeatureSimilarity(&source, &candidate)
+		}
+	})
+}
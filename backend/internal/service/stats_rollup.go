@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// statsScanPageSize is how many items the stats job scans per page
+const statsScanPageSize = 500
+
+// StatsRollupRepository defines the repository interface for recomputing
+// user stats
+type StatsRollupRepository interface {
+	ScanUsers(ctx context.Context, cursor string, limit int) (*repository.PaginatedResult[models.User], error)
+	ScanTrackItems(ctx context.Context, cursor string, limit int) (*repository.PaginatedResult[models.TrackItem], error)
+	ScanAlbums(ctx context.Context, cursor string, limit int) (*repository.PaginatedResult[models.Album], error)
+	UpdateUserStats(ctx context.Context, userID string, storageUsed int64, trackCount, albumCount, playlistCount int) error
+}
+
+// StatsRollupService recomputes the storage used and the track and album
+// counts stored on each user, which storage quotas and the admin user list
+// read, from the tracks and albums of their library. Track transfers adjust
+// them as they go; the rollup corrects whatever drifted. Playlist counts are
+// kept as they are.
+type StatsRollupService struct {
+	repo StatsRollupRepository
+}
+
+// NewStatsRollupService creates a new stats rollup service
+func NewStatsRollupService(repo StatsRollupRepository) *StatsRollupService {
+	return &StatsRollupService{repo: repo}
+}
+
+// libraryTotals is what a library's tracks and albums add up to
+type libraryTotals struct {
+	storageUsed int64
+	tracks      int
+	albums      int
+}
+
+// RollupAll recomputes every user's stats and returns how many changed.
+// Members of a household hold no tracks of their own, so their counts go to
+// zero; the library's owner is counted in full.
+func (s *StatsRollupService) RollupAll(ctx context.Context) (int, error) {
+	totals := make(map[string]*libraryTotals)
+	totalsOf := func(userID string) *libraryTotals {
+		t, ok := totals[userID]
+		if !ok {
+			t = &libraryTotals{}
+			totals[userID] = t
+		}
+		return t
+	}
+
+	if err := scanAll(ctx, s.repo.ScanTrackItems, func(item models.TrackItem) {
+		t := totalsOf(item.UserID)
+		t.storageUsed += item.FileSize
+		t.tracks++
+	}); err != nil {
+		return 0, fmt.Errorf("failed to scan tracks: %w", err)
+	}
+	if err := scanAll(ctx, s.repo.ScanAlbums, func(album models.Album) {
+		totalsOf(album.UserID).albums++
+	}); err != nil {
+		return 0, fmt.Errorf("failed to scan albums: %w", err)
+	}
+
+	var users []models.User
+	if err := scanAll(ctx, s.repo.ScanUsers, func(user models.User) {
+		users = append(users, user)
+	}); err != nil {
+		return 0, fmt.Errorf("failed to scan users: %w", err)
+	}
+
+	updated := 0
+	for _, user := range users {
+		t := totalsOf(user.ID)
+		if user.StorageUsed == t.storageUsed && user.TrackCount == t.tracks && user.AlbumCount == t.albums {
+			continue
+		}
+		if err := s.repo.UpdateUserStats(ctx, user.ID, t.storageUsed, t.tracks, t.albums, user.PlaylistCount); err != nil {
+			return updated, fmt.Errorf("failed to update stats of %s: %w", user.ID, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// scanAll calls visit with every item of a paged scan
+func scanAll[T any](ctx context.Context, scan func(context.Context, string, int) (*repository.PaginatedResult[T], error), visit func(T)) error {
+	cursor := ""
+	for {
+		page, err := scan(ctx, cursor, statsScanPageSize)
+		if err != nil {
+			return err
+		}
+		for _, item := range page.Items {
+			visit(item)
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsRollupService(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	for _, user := range []models.User{{ID: "u1"}, {ID: "u2"}, {ID: "u3"}} {
+		require.NoError(t, repo.CreateUser(ctx, user))
+	}
+	// u2 deleted everything since its stats were last written
+	require.NoError(t, repo.UpdateUserStats(ctx, "u2", 4096, 2, 1, 3))
+	require.NoError(t, repo.UpdateUserStats(ctx, "u1", 0, 0, 0, 5))
+
+	for _, track := range []models.Track{
+		builders.NewTrackBuilder("u1", "t1").WithAlbum("First").WithFileSize(100).Build(),
+		builders.NewTrackBuilder("u1", "t2").WithAlbum("First").WithFileSize(200).Build(),
+		builders.NewTrackBuilder("u1", "t3").WithAlbum("Second").WithFileSize(400).Build(),
+	} {
+		require.NoError(t, repo.CreateTrack(ctx, track))
+	}
+	for _, album := range []string{"First", "Second"} {
+		_, err := repo.GetOrCreateAlbum(ctx, "u1", album, "Test Artist")
+		require.NoError(t, err)
+	}
+
+	svc := NewStatsRollupService(repo)
+	updated, err := svc.RollupAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated, "u3 had nothing to change")
+
+	u1, err := repo.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(700), u1.StorageUsed)
+	assert.Equal(t, 3, u1.TrackCount)
+	assert.Equal(t, 2, u1.AlbumCount)
+	assert.Equal(t, 5, u1.PlaylistCount, "playlist counts are kept")
+
+	u2, err := repo.GetUser(ctx, "u2")
+	require.NoError(t, err)
+	assert.Zero(t, u2.StorageUsed)
+	assert.Zero(t, u2.TrackCount)
+	assert.Zero(t, u2.AlbumCount)
+	assert.Equal(t, 3, u2.PlaylistCount)
+
+	updated, err = svc.RollupAll(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, updated)
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"time"
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
@@ -19,13 +18,15 @@ const (
 
 // streamService implements StreamService
 type streamService struct {
-	repo       repository.Repository
-	cloudfront repository.CloudFrontSigner
-	s3Repo     repository.S3Repository
+	repo        repository.Repository
+	cloudfront  repository.CloudFrontSigner
+	s3Repo      repository.MediaStore
+	watermarker DownloadWatermarker
+	accessLog   AccessRecorder
 }
 
 // NewStreamService creates a new stream service
-func NewStreamService(repo repository.Repository, cloudfront repository.CloudFrontSigner, s3Repo repository.S3Repository) StreamService {
+func NewStreamService(repo repository.Repository, cloudfront repository.CloudFrontSigner, s3Repo repository.MediaStore) StreamService {
 	return &streamService{
 		repo:       repo,
 		cloudfront: cloudfront,
@@ -36,6 +37,8 @@ func NewStreamService(repo repository.Repository, cloudfront repository.CloudFro
 func (s *streamService) GetStreamURL(ctx context.Context, userID, trackID string, hasGlobal bool) (*models.StreamResponse, error) {
 	var track *models.Track
 	var err error
+	// shared is set when the track is reached from outside its library
+	var shared bool
 
 	// First try to get as owner
 	track, err = s.repo.GetTrack(ctx, userID, trackID)
@@ -64,17 +67,26 @@ func (s *streamService) GetStreamURL(ctx context.Context, userID, trackID string
 			// Private track - return 403 Forbidden
 			return nil, models.NewForbiddenError("you do not have permission to stream this track")
 		}
+		shared = track.Visibility == models.VisibilityPublic || track.Visibility == models.VisibilityUnlisted
 	}
 
-	var hlsURL, fallbackURL string
+	var hlsURL, hlsVariant, fallbackURL string
 
-	// Generate HLS URL if available
+	// Generate HLS URL if available. Data saver pins playback to the lowest
+	// bitrate instead of letting the player pick from the master playlist.
 	if track.HLSStatus == models.HLSStatusReady && track.HLSPlaylistKey != "" {
 		if s.cloudfront != nil {
-			hlsURL, err = s.cloudfront.GenerateSignedURL(ctx, track.HLSPlaylistKey, streamURLExpiry)
+			playlistKey, variant := track.HLSPlaylistKey, ""
+			if dataSaver(ctx, s.repo, userID) {
+				variant = HLSVariantLow
+				playlistKey = BuildHLSVariantKey(track.HLSPlaylistKey, variant)
+			}
+			hlsURL, err = s.cloudfront.GenerateSignedURL(ctx, playlistKey, streamURLExpiry)
 			if err != nil {
 				// Log error but continue with fallback
 				fmt.Printf("Warning: failed to generate HLS URL: %v\n", err)
+			} else {
+				hlsVariant = variant
 			}
 		}
 	}
@@ -98,6 +110,9 @@ func (s *streamService) GetStreamURL(ctx context.Context, userID, trackID string
 		streamURL = fallbackURL
 	}
 
+	// Recorded before the play count goroutine takes over track
+	s.recordAccess(ctx, shared, *track, models.AccessStream, "")
+
 	// Increment play count asynchronously (best effort)
 	go func() {
 		bgCtx := context.Background()
@@ -113,15 +128,31 @@ func (s *streamService) GetStreamURL(ctx context.Context, userID, trackID string
 		HLSURL:      hlsURL,
 		FallbackURL: fallbackURL,
 		HLSReady:    track.HLSStatus == models.HLSStatusReady,
+		HLSVariant:  hlsVariant,
 		ExpiresAt:   time.Now().Add(streamURLExpiry),
 		Format:      string(track.Format),
 		Bitrate:     track.Bitrate,
+
+		PlaybackSettings: track.PlaybackSettings,
 	}, nil
 }
 
+// SetWatermarker hands other users' downloads of protected tracks to watermarker
+func (s *streamService) SetWatermarker(watermarker DownloadWatermarker) {
+	s.watermarker = watermarker
+}
+
+// SetAccessLog records streams and downloads of tracks reached from outside
+// their library
+func (s *streamService) SetAccessLog(recorder AccessRecorder) {
+	s.accessLog = recorder
+}
+
 func (s *streamService) GetDownloadURL(ctx context.Context, userID, trackID string, hasGlobal bool) (*models.DownloadResponse, error) {
 	var track *models.Track
 	var err error
+	// shared is set when the track is reached from outside its library
+	var shared bool
 
 	// First try to get as owner
 	track, err = s.repo.GetTrack(ctx, userID, trackID)
@@ -150,10 +181,24 @@ func (s *streamService) GetDownloadURL(ctx context.Context, userID, trackID stri
 			// Private track - return 403 Forbidden
 			return nil, models.NewForbiddenError("you do not have permission to download this track")
 		}
+		shared = track.Visibility == models.VisibilityPublic || track.Visibility == models.VisibilityUnlisted
+
+		// Other users get a watermarked copy of a protected track; admins get the original
+		if track.DownloadWatermark != models.WatermarkNone && !hasGlobal {
+			if s.watermarker == nil {
+				return nil, models.NewServiceUnavailableError("download protection", "watermarking is not configured")
+			}
+			resp, err := s.watermarker.Issue(ctx, userID, *track)
+			if err != nil {
+				return nil, err
+			}
+			s.recordAccess(ctx, shared, *track, models.AccessDownload, resp.WatermarkToken)
+			return resp, nil
+		}
 	}
 
 	// Generate friendly filename
-	fileName := fmt.Sprintf("%s - %s%s", track.Artist, track.Title, getExtensionFromFormat(track.Format))
+	fileName := track.DownloadFileName()
 
 	// Use S3 presigned URL for downloads - it supports Content-Disposition header natively
 	// CloudFront would require query string forwarding configuration to support this
@@ -161,6 +206,7 @@ func (s *streamService) GetDownloadURL(ctx context.Context, userID, trackID stri
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate download URL: %w", err)
 	}
+	s.recordAccess(ctx, shared, *track, models.AccessDownload, "")
 
 	return &models.DownloadResponse{
 		TrackID:     trackID,
@@ -172,6 +218,14 @@ func (s *streamService) GetDownloadURL(ctx context.Context, userID, trackID stri
 	}, nil
 }
 
+// recordAccess adds a stream or download of a track reached from outside its
+// library to the track's access log
+func (s *streamService) recordAccess(ctx context.Context, shared bool, track models.Track, kind models.AccessKind, token string) {
+	if shared && s.accessLog != nil {
+		s.accessLog.Record(ctx, track, kind, token)
+	}
+}
+
 func (s *streamService) GetCoverArtURL(ctx context.Context, userID, trackID string) (string, error) {
 	track, err := s.repo.GetTrack(ctx, userID, trackID)
 	if err != nil {
@@ -191,21 +245,3 @@ func (s *streamService) GetCoverArtURL(ctx context.Context, userID, trackID stri
 	}
 	return s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, coverArtURLExpiry)
 }
-
-// getExtensionFromFormat returns the file extension for an audio format
-func getExtensionFromFormat(format models.AudioFormat) string {
-	switch format {
-	case models.AudioFormatMP3:
-		return ".mp3"
-	case models.AudioFormatFLAC:
-		return ".flac"
-	case models.AudioFormatWAV:
-		return ".wav"
-	case models.AudioFormatAAC:
-		return ".m4a"
-	case models.AudioFormatOGG:
-		return ".ogg"
-	default:
-		return filepath.Ext(string(format))
-	}
-}
@@ -111,18 +111,28 @@ func (s *tagService) UpdateTag(ctx context.Context, userID, tagName string, req
 	return &response, nil
 }
 
-func (s *tagService) DeleteTag(ctx context.Context, userID, tagName string) error {
+// DeleteTag deletes a tag; a dry run reports the tag without deleting it
+func (s *tagService) DeleteTag(ctx context.Context, userID, tagName string, dryRun bool) (*models.DeleteTagResult, error) {
 	normalizedName := normalizeTagName(tagName)
 
-	_, err := s.repo.GetTag(ctx, userID, normalizedName)
+	tag, err := s.repo.GetTag(ctx, userID, normalizedName)
 	if err != nil {
 		if err == repository.ErrNotFound {
-			return models.NewNotFoundError("Tag", normalizedName)
+			return nil, models.NewNotFoundError("Tag", normalizedName)
 		}
-		return err
+		return nil, err
 	}
 
-	return s.repo.DeleteTag(ctx, userID, normalizedName)
+	plan := &bulkPlan[string, *models.DeleteTagResult]{
+		changes: []string{normalizedName},
+		result:  &models.DeleteTagResult{Name: tag.Name, TrackCount: tag.TrackCount, DryRun: dryRun},
+	}
+	if _, err := plan.execute(ctx, dryRun, func(ctx context.Context, name string) error {
+		return s.repo.DeleteTag(ctx, userID, name)
+	}); err != nil {
+		return nil, err
+	}
+	return plan.result, nil
 }
 
 func (s *tagService) ListTags(ctx context.Context, userID string) ([]models.TagResponse, error) {
@@ -149,6 +159,10 @@ func (s *tagService) AddTagsToTrack(ctx context.Context, userID, trackID string,
 		return nil, err
 	}
 
+	if err := track.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+
 	// Normalize all tag names
 	normalizedTags := make([]string, 0, len(req.Tags))
 	for _, tagName := range req.Tags {
@@ -228,6 +242,10 @@ func (s *tagService) RemoveTagFromTrack(ctx context.Context, userID, trackID, ta
 		return err
 	}
 
+	if err := track.EnsureUnlocked(); err != nil {
+		return err
+	}
+
 	// Check if track actually has this tag
 	hasTag := false
 	for _, t := range track.Tags {
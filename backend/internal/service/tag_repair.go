@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// TagRepairRepository defines the repository interface for applying tag repairs
+type TagRepairRepository interface {
+	GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error)
+	UpdateUpload(ctx context.Context, upload models.Upload) error
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	UpdateTrack(ctx context.Context, track models.Track) error
+}
+
+// TagRepairServiceImpl applies the character-encoding fixes suggested while
+// an upload was processed to the track it created
+type TagRepairServiceImpl struct {
+	repo TagRepairRepository
+}
+
+// NewTagRepairService creates a new tag repair service
+func NewTagRepairService(repo TagRepairRepository) TagRepairService {
+	return &TagRepairServiceImpl{repo: repo}
+}
+
+// ApplyEncodingFixes applies the selected fixes of an upload to its track.
+// Fixes for fields edited since the upload are skipped rather than
+// overwriting the edit. Applied and skipped fixes are removed from the upload.
+func (s *TagRepairServiceImpl) ApplyEncodingFixes(ctx context.Context, userID, uploadID string, req models.ApplyEncodingFixesRequest) (*models.EncodingFixResult, error) {
+	upload, err := s.repo.GetUpload(ctx, userID, uploadID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.ErrUploadNotFound
+		}
+		return nil, err
+	}
+	if len(upload.EncodingFixes) == 0 {
+		return nil, models.NewConflictError("upload has no suggested encoding fixes")
+	}
+
+	trackID := upload.TrackID
+	if upload.ReplaceTrackID != "" {
+		trackID = upload.ReplaceTrackID
+	}
+	if trackID == "" {
+		return nil, models.NewConflictError("upload has not created a track yet")
+	}
+
+	track, err := s.repo.GetTrack(ctx, userID, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+	if err := track.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+
+	result := &models.EncodingFixResult{TrackID: trackID, Applied: []models.EncodingFix{}}
+	for _, fix := range upload.EncodingFixes {
+		switch {
+		case len(req.Fields) > 0 && !slices.Contains(req.Fields, fix.Field):
+			result.Remaining = append(result.Remaining, fix)
+		case track.ApplyEncodingFix(fix):
+			result.Applied = append(result.Applied, fix)
+		default:
+			result.Skipped = append(result.Skipped, fix)
+		}
+	}
+
+	if len(result.Applied) > 0 {
+		if err := s.repo.UpdateTrack(ctx, *track); err != nil {
+			return nil, err
+		}
+	}
+
+	upload.EncodingFixes = result.Remaining
+	upload.SuspectEncoding = len(result.Remaining) > 0
+	if err := s.repo.UpdateUpload(ctx, *upload); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
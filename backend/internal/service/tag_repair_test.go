@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	titleFix  = models.EncodingFix{Field: models.TagFieldTitle, Original: "CafÃ©", Suggested: "Café", Encoding: "utf-8"}
+	artistFix = models.EncodingFix{Field: models.TagFieldArtist, Original: "Êèíî", Suggested: "Кино", Encoding: "windows-1251"}
+)
+
+// mojibakeTrack is a tagged track whose title and artist were decoded with
+// the wrong charset
+func mojibakeTrack() *builders.TrackBuilder {
+	return builders.NewTrackBuilder("user-1", "track-1").WithTitle("CafÃ©").WithArtist("Êèíî").WithTags("chill", "russian")
+}
+
+// newTagRepairService holds track and an upload of it suggesting both fixes
+func newTagRepairService(t *testing.T, track models.Track) (TagRepairService, *repository.MemoryRepository) {
+	t.Helper()
+	repo := newSeededRepo(t, track)
+	require.NoError(t, repo.CreateUpload(context.Background(), models.Upload{
+		ID:              "upload-1",
+		UserID:          "user-1",
+		TrackID:         "track-1",
+		SuspectEncoding: true,
+		EncodingFixes:   []models.EncodingFix{titleFix, artistFix},
+	}))
+	return NewTagRepairService(repo), repo
+}
+
+func TestTagRepairService_ApplyEncodingFixes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies every fix by default", func(t *testing.T) {
+		svc, repo := newTagRepairService(t, mojibakeTrack().Build())
+
+		result, err := svc.ApplyEncodingFixes(ctx, "user-1", "upload-1", models.ApplyEncodingFixesRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.EncodingFix{titleFix, artistFix}, result.Applied)
+
+		track, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, "Café", track.Title)
+		assert.Equal(t, "Кино", track.Artist)
+		assert.Equal(t, []string{"chill", "russian"}, track.Tags)
+
+		upload, err := repo.GetUpload(ctx, "user-1", "upload-1")
+		require.NoError(t, err)
+		assert.False(t, upload.SuspectEncoding)
+		assert.Empty(t, upload.EncodingFixes)
+	})
+
+	t.Run("keeps unselected fixes for later", func(t *testing.T) {
+		svc, repo := newTagRepairService(t, mojibakeTrack().Build())
+
+		result, err := svc.ApplyEncodingFixes(ctx, "user-1", "upload-1", models.ApplyEncodingFixesRequest{Fields: []string{models.TagFieldArtist}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.EncodingFix{artistFix}, result.Applied)
+		assert.Equal(t, []models.EncodingFix{titleFix}, result.Remaining)
+
+		upload, err := repo.GetUpload(ctx, "user-1", "upload-1")
+		require.NoError(t, err)
+		assert.True(t, upload.SuspectEncoding)
+		assert.Equal(t, []models.EncodingFix{titleFix}, upload.EncodingFixes)
+	})
+
+	t.Run("skips fields edited since upload", func(t *testing.T) {
+		svc, repo := newTagRepairService(t, mojibakeTrack().WithTitle("Café (edited)").Build())
+
+		result, err := svc.ApplyEncodingFixes(ctx, "user-1", "upload-1", models.ApplyEncodingFixesRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.EncodingFix{artistFix}, result.Applied)
+		assert.Equal(t, []models.EncodingFix{titleFix}, result.Skipped)
+
+		track, err := repo.GetTrack(ctx, "user-1", "track-1")
+		require.NoError(t, err)
+		assert.Equal(t, "Café (edited)", track.Title)
+	})
+
+	t.Run("rejects locked tracks", func(t *testing.T) {
+		svc, _ := newTagRepairService(t, mojibakeTrack().Locked().Build())
+
+		_, err := svc.ApplyEncodingFixes(ctx, "user-1", "upload-1", models.ApplyEncodingFixesRequest{})
+
+		assert.True(t, errors.Is(err, models.ErrTrackLocked))
+	})
+
+	t.Run("unknown upload", func(t *testing.T) {
+		svc, _ := newTagRepairService(t, mojibakeTrack().Build())
+
+		_, err := svc.ApplyEncodingFixes(ctx, "user-1", "missing", models.ApplyEncodingFixesRequest{})
+
+		assert.Equal(t, models.ErrUploadNotFound, err)
+	})
+
+	t.Run("upload without suggestions", func(t *testing.T) {
+		svc, repo := newTagRepairService(t, mojibakeTrack().Build())
+		require.NoError(t, repo.CreateUpload(ctx, models.Upload{ID: "upload-2", UserID: "user-1", TrackID: "track-1"}))
+
+		_, err := svc.ApplyEncodingFixes(ctx, "user-1", "upload-2", models.ApplyEncodingFixesRequest{})
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "CONFLICT", apiErr.Code)
+	})
+}
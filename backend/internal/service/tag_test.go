@@ -8,313 +8,19 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-// =============================================================================
-// Tag Service Tests (Epic 4)
-// =============================================================================
-
-// MockTagRepository provides mockable repository methods for tag service tests
-type MockTagRepository struct {
-	mock.Mock
-}
-
-func (m *MockTagRepository) GetTag(ctx context.Context, userID, tagName string) (*models.Tag, error) {
-	args := m.Called(ctx, userID, tagName)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Tag), args.Error(1)
-}
-
-func (m *MockTagRepository) CreateTag(ctx context.Context, tag models.Tag) error {
-	args := m.Called(ctx, tag)
-	return args.Error(0)
-}
-
-func (m *MockTagRepository) UpdateTag(ctx context.Context, tag models.Tag) error {
-	args := m.Called(ctx, tag)
-	return args.Error(0)
-}
-
-func (m *MockTagRepository) DeleteTag(ctx context.Context, userID, tagName string) error {
-	args := m.Called(ctx, userID, tagName)
-	return args.Error(0)
-}
-
-func (m *MockTagRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
-	args := m.Called(ctx, userID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.Tag), args.Error(1)
-}
-
-func (m *MockTagRepository) GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, userID, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-func (m *MockTagRepository) GetTrackByID(ctx context.Context, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-func (m *MockTagRepository) UpdateTrack(ctx context.Context, track models.Track) error {
-	args := m.Called(ctx, track)
-	return args.Error(0)
-}
-
-func (m *MockTagRepository) AddTagsToTrack(ctx context.Context, userID, trackID string, tagNames []string) error {
-	args := m.Called(ctx, userID, trackID, tagNames)
-	return args.Error(0)
-}
-
-func (m *MockTagRepository) RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error {
-	args := m.Called(ctx, userID, trackID, tagName)
-	return args.Error(0)
-}
-
-func (m *MockTagRepository) GetTracksByTag(ctx context.Context, userID, tagName string) ([]models.Track, error) {
-	args := m.Called(ctx, userID, tagName)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]models.Track), args.Error(1)
-}
-
-// Stub implementations for Repository interface (required but not used in tag tests)
-func (m *MockTagRepository) CreateTrack(ctx context.Context, track models.Track) error { return nil }
-func (m *MockTagRepository) DeleteTrack(ctx context.Context, userID, trackID string) error {
-	return nil
-}
-func (m *MockTagRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-func (m *MockTagRepository) ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.Track, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) GetAlbum(ctx context.Context, userID, albumID string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) ListAlbums(ctx context.Context, userID string, filter models.AlbumFilter) (*repository.PaginatedResult[models.Album], error) {
-	return nil, nil
-}
-func (m *MockTagRepository) ListAlbumsByArtist(ctx context.Context, userID, artist string) ([]models.Album, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) UpdateAlbumStats(ctx context.Context, userID, albumID string, trackCount, totalDuration int) error {
-	return nil
-}
-func (m *MockTagRepository) CreateUser(ctx context.Context, user models.User) error { return nil }
-func (m *MockTagRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) UpdateUser(ctx context.Context, user models.User) error { return nil }
-func (m *MockTagRepository) UpdateUserStats(ctx context.Context, userID string, storageUsed int64, trackCount, albumCount, playlistCount int) error {
-	return nil
-}
-func (m *MockTagRepository) CreatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockTagRepository) GetPlaylist(ctx context.Context, userID, playlistID string) (*models.Playlist, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) UpdatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockTagRepository) DeletePlaylist(ctx context.Context, userID, playlistID string) error {
-	return nil
-}
-func (m *MockTagRepository) ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-func (m *MockTagRepository) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string, position int) error {
-	return nil
-}
-func (m *MockTagRepository) RemoveTracksFromPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
-	return nil
-}
-func (m *MockTagRepository) GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) ReorderPlaylistTracks(ctx context.Context, playlistID string, tracks []models.PlaylistTrack) error {
-	return nil
-}
-func (m *MockTagRepository) GetTrackTags(ctx context.Context, userID, trackID string) ([]string, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) CreateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockTagRepository) GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) UpdateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockTagRepository) UpdateUploadStatus(ctx context.Context, userID, uploadID string, status models.UploadStatus, errorMsg string, trackID string) error {
-	return nil
-}
-func (m *MockTagRepository) UpdateUploadStep(ctx context.Context, userID, uploadID string, step models.ProcessingStep, success bool) error {
-	return nil
-}
-func (m *MockTagRepository) ListUploads(ctx context.Context, userID string, filter models.UploadFilter) (*repository.PaginatedResult[models.Upload], error) {
-	return nil, nil
-}
-func (m *MockTagRepository) ListUploadsByStatus(ctx context.Context, status models.UploadStatus) ([]models.Upload, error) {
-	return nil, nil
-}
-
-// Artist-related methods
-func (m *MockTagRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockTagRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) UpdateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockTagRepository) DeleteArtist(ctx context.Context, userID, artistID string) error {
-	return nil
-}
-func (m *MockTagRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*repository.PaginatedResult[models.Artist], error) {
-	return nil, nil
-}
-func (m *MockTagRepository) BatchGetArtists(ctx context.Context, userID string, artistIDs []string) (map[string]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) SearchArtists(ctx context.Context, userID, query string, limit int) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) GetArtistTrackCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockTagRepository) GetArtistAlbumCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockTagRepository) GetArtistTotalPlays(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockTagRepository) SearchPlaylists(ctx context.Context, userID, query string, limit int) ([]models.Playlist, error) {
-	return nil, nil
-}
-
-// User role methods
-func (m *MockTagRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	return nil
-}
-func (m *MockTagRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
-	return nil, nil
-}
-
-// Playlist visibility methods
-func (m *MockTagRepository) UpdatePlaylistVisibility(ctx context.Context, userID, playlistID string, visibility models.PlaylistVisibility) error {
-	return nil
-}
-func (m *MockTagRepository) ListPublicPlaylists(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-
-// ArtistProfile methods
-func (m *MockTagRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockTagRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockTagRepository) DeleteArtistProfile(ctx context.Context, userID string) error {
-	return nil
-}
-func (m *MockTagRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.ArtistProfile], error) {
-	return nil, nil
-}
-func (m *MockTagRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Follow methods
-func (m *MockTagRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
-	return nil
-}
-func (m *MockTagRepository) DeleteFollow(ctx context.Context, followerID, followedID string) error {
-	return nil
-}
-func (m *MockTagRepository) GetFollow(ctx context.Context, followerID, followedID string) (*models.Follow, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockTagRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockTagRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Admin-related methods for track visibility
-func (m *MockTagRepository) ListPublicTracks(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-func (m *MockTagRepository) UpdateTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error {
-	return nil
-}
-func (m *MockTagRepository) SearchUsers(ctx context.Context, query string, limit int) ([]models.User, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) SetUserDisabled(ctx context.Context, userID string, disabled bool) error {
-	return nil
-}
-func (m *MockTagRepository) GetUserDisplayName(ctx context.Context, userID string) (string, error) {
-	return "", nil
-}
-func (m *MockTagRepository) GetFollowerCount(ctx context.Context, userID string) (int, error) {
-	return 0, nil
-}
-
-// User settings methods
-func (m *MockTagRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) GetUserByCognitoID(ctx context.Context, cognitoID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) SearchUsersByEmail(ctx context.Context, emailPrefix string, limit int, cursor string) ([]repository.UserSearchResult, string, error) {
-	return nil, "", nil
-}
-func (m *MockTagRepository) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
-	return nil, nil
-}
-func (m *MockTagRepository) UpdateUserSettings(ctx context.Context, userID string, update *repository.UserSettingsUpdate) (*models.UserSettings, error) {
-	return nil, nil
-}
-
 // =============================================================================
 // CreateTag Tests
 // =============================================================================
 
 func TestCreateTag_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	// Tag doesn't exist yet
@@ -333,12 +39,11 @@ func TestCreateTag_Success(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Equal(t, "favorites", resp.Name)
 	assert.Equal(t, "#FF0000", resp.Color)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestCreateTag_AlreadyExists(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	// Tag already exists
@@ -357,7 +62,6 @@ func TestCreateTag_AlreadyExists(t *testing.T) {
 	if errors.As(err, &apiErr) {
 		assert.Equal(t, "CONFLICT", apiErr.Code)
 	}
-	mockRepo.AssertExpectations(t)
 }
 
 // =============================================================================
@@ -366,7 +70,7 @@ func TestCreateTag_AlreadyExists(t *testing.T) {
 
 func TestGetTag_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "favorites").Return(&models.Tag{
@@ -383,12 +87,11 @@ func TestGetTag_Success(t *testing.T) {
 	assert.Equal(t, "favorites", resp.Name)
 	assert.Equal(t, "#FF0000", resp.Color)
 	assert.Equal(t, 5, resp.TrackCount)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestGetTag_NotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
@@ -402,7 +105,6 @@ func TestGetTag_NotFound(t *testing.T) {
 	if errors.As(err, &apiErr) {
 		assert.Equal(t, "NOT_FOUND", apiErr.Code)
 	}
-	mockRepo.AssertExpectations(t)
 }
 
 // =============================================================================
@@ -411,7 +113,7 @@ func TestGetTag_NotFound(t *testing.T) {
 
 func TestUpdateTag_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "favorites").Return(&models.Tag{
@@ -430,12 +132,11 @@ func TestUpdateTag_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, "#00FF00", resp.Color)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestUpdateTag_Rename(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	// Original tag exists
@@ -456,12 +157,11 @@ func TestUpdateTag_Rename(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, "new-name", resp.Name)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestUpdateTag_RenameConflict(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	// Original tag exists
@@ -486,12 +186,11 @@ func TestUpdateTag_RenameConflict(t *testing.T) {
 	if errors.As(err, &apiErr) {
 		assert.Equal(t, "CONFLICT", apiErr.Code)
 	}
-	mockRepo.AssertExpectations(t)
 }
 
 func TestUpdateTag_NotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
@@ -507,7 +206,6 @@ func TestUpdateTag_NotFound(t *testing.T) {
 	if errors.As(err, &apiErr) {
 		assert.Equal(t, "NOT_FOUND", apiErr.Code)
 	}
-	mockRepo.AssertExpectations(t)
 }
 
 // =============================================================================
@@ -516,7 +214,7 @@ func TestUpdateTag_NotFound(t *testing.T) {
 
 func TestDeleteTag_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "favorites").Return(&models.Tag{
@@ -525,20 +223,39 @@ func TestDeleteTag_Success(t *testing.T) {
 	}, nil)
 	mockRepo.On("DeleteTag", ctx, "user-123", "favorites").Return(nil)
 
-	err := svc.DeleteTag(ctx, "user-123", "favorites")
+	result, err := svc.DeleteTag(ctx, "user-123", "favorites", false)
 
-	assert.NoError(t, err)
-	mockRepo.AssertExpectations(t)
+	require.NoError(t, err)
+	assert.Equal(t, "favorites", result.Name)
+	assert.False(t, result.DryRun)
+}
+
+func TestDeleteTag_DryRunKeepsTheTag(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewRepository(t)
+	svc := NewTagService(mockRepo)
+
+	mockRepo.On("GetTag", ctx, "user-123", "favorites").Return(&models.Tag{
+		UserID:     "user-123",
+		Name:       "favorites",
+		TrackCount: 12,
+	}, nil)
+
+	result, err := svc.DeleteTag(ctx, "user-123", "favorites", true)
+
+	require.NoError(t, err)
+	assert.Equal(t, &models.DeleteTagResult{Name: "favorites", TrackCount: 12, DryRun: true}, result)
+	mockRepo.AssertNotCalled(t, "DeleteTag", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestDeleteTag_NotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
 
-	err := svc.DeleteTag(ctx, "user-123", "nonexistent")
+	_, err := svc.DeleteTag(ctx, "user-123", "nonexistent", false)
 
 	assert.Error(t, err)
 
@@ -546,7 +263,6 @@ func TestDeleteTag_NotFound(t *testing.T) {
 	if errors.As(err, &apiErr) {
 		assert.Equal(t, "NOT_FOUND", apiErr.Code)
 	}
-	mockRepo.AssertExpectations(t)
 }
 
 // =============================================================================
@@ -555,7 +271,7 @@ func TestDeleteTag_NotFound(t *testing.T) {
 
 func TestListTags_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	now := time.Now()
@@ -570,12 +286,11 @@ func TestListTags_Success(t *testing.T) {
 	assert.Len(t, resp, 2)
 	assert.Equal(t, "favorites", resp[0].Name)
 	assert.Equal(t, "rock", resp[1].Name)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestListTags_Empty(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("ListTags", ctx, "user-123").Return([]models.Tag{}, nil)
@@ -584,7 +299,6 @@ func TestListTags_Empty(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Len(t, resp, 0)
-	mockRepo.AssertExpectations(t)
 }
 
 // =============================================================================
@@ -593,7 +307,7 @@ func TestListTags_Empty(t *testing.T) {
 
 func TestAddTagsToTrack_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	// Track exists
@@ -627,12 +341,11 @@ func TestAddTagsToTrack_Success(t *testing.T) {
 	assert.Contains(t, tags, "existing-tag")
 	assert.Contains(t, tags, "favorites")
 	assert.Contains(t, tags, "rock")
-	mockRepo.AssertExpectations(t)
 }
 
 func TestAddTagsToTrack_TrackNotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTrack", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
@@ -647,7 +360,6 @@ func TestAddTagsToTrack_TrackNotFound(t *testing.T) {
 	if errors.As(err, &apiErr) {
 		assert.Equal(t, "NOT_FOUND", apiErr.Code)
 	}
-	mockRepo.AssertExpectations(t)
 }
 
 // =============================================================================
@@ -656,7 +368,7 @@ func TestAddTagsToTrack_TrackNotFound(t *testing.T) {
 
 func TestRemoveTagFromTrack_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTrack", ctx, "user-123", "track-1").Return(&models.Track{
@@ -677,7 +389,6 @@ func TestRemoveTagFromTrack_Success(t *testing.T) {
 	err := svc.RemoveTagFromTrack(ctx, "user-123", "track-1", "favorites")
 
 	assert.NoError(t, err)
-	mockRepo.AssertExpectations(t)
 }
 
 // =============================================================================
@@ -686,7 +397,7 @@ func TestRemoveTagFromTrack_Success(t *testing.T) {
 
 func TestGetTracksByTag_Success(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "favorites").Return(&models.Tag{
@@ -704,12 +415,11 @@ func TestGetTracksByTag_Success(t *testing.T) {
 	assert.Len(t, resp, 2)
 	assert.Equal(t, "track-1", resp[0].ID)
 	assert.Equal(t, "track-2", resp[1].ID)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestGetTracksByTag_TagNotFound(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "nonexistent").Return(nil, repository.ErrNotFound)
@@ -723,7 +433,6 @@ func TestGetTracksByTag_TagNotFound(t *testing.T) {
 	if errors.As(err, &apiErr) {
 		assert.Equal(t, "NOT_FOUND", apiErr.Code)
 	}
-	mockRepo.AssertExpectations(t)
 }
 
 // =============================================================================
@@ -732,7 +441,7 @@ func TestGetTracksByTag_TagNotFound(t *testing.T) {
 
 func TestCreateTag_NormalizesName(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	// When creating "Rock", should check for and create as "rock"
@@ -750,12 +459,11 @@ func TestCreateTag_NormalizesName(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, "rock", resp.Name) // returned as lowercase
-	mockRepo.AssertExpectations(t)
 }
 
 func TestGetTag_CaseInsensitive(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	// When looking up "ROCK", should query for "rock"
@@ -770,12 +478,11 @@ func TestGetTag_CaseInsensitive(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, "rock", resp.Name)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestDeleteTag_NormalizesName(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "rock").Return(&models.Tag{
@@ -784,15 +491,14 @@ func TestDeleteTag_NormalizesName(t *testing.T) {
 	}, nil)
 	mockRepo.On("DeleteTag", ctx, "user-123", "rock").Return(nil)
 
-	err := svc.DeleteTag(ctx, "user-123", "ROCK") // uppercase input
+	_, err := svc.DeleteTag(ctx, "user-123", "ROCK", false) // uppercase input
 
 	assert.NoError(t, err)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestAddTagsToTrack_NormalizesNames(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	// Track exists
@@ -824,12 +530,11 @@ func TestAddTagsToTrack_NormalizesNames(t *testing.T) {
 	// Returned tags should be lowercase
 	assert.Contains(t, tags, "rock")
 	assert.Contains(t, tags, "favorites")
-	mockRepo.AssertExpectations(t)
 }
 
 func TestRemoveTagFromTrack_NormalizesName(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTrack", ctx, "user-123", "track-1").Return(&models.Track{
@@ -849,12 +554,11 @@ func TestRemoveTagFromTrack_NormalizesName(t *testing.T) {
 	err := svc.RemoveTagFromTrack(ctx, "user-123", "track-1", "ROCK") // uppercase
 
 	assert.NoError(t, err)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestGetTracksByTag_NormalizesName(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	mockRepo.On("GetTag", ctx, "user-123", "rock").Return(&models.Tag{
@@ -869,12 +573,11 @@ func TestGetTracksByTag_NormalizesName(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Len(t, resp, 1)
-	mockRepo.AssertExpectations(t)
 }
 
 func TestUpdateTag_NormalizesNames(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTagRepository)
+	mockRepo := mocks.NewRepository(t)
 	svc := NewTagService(mockRepo)
 
 	// Lookup should be normalized
@@ -896,5 +599,4 @@ func TestUpdateTag_NormalizesNames(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, "metal", resp.Name)
-	mockRepo.AssertExpectations(t)
 }
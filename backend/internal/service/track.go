@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
@@ -11,14 +12,20 @@ import (
 // trackService implements TrackService
 type trackService struct {
 	repo   repository.Repository
-	s3Repo repository.S3Repository
+	s3Repo repository.MediaStore
+	// moderator holds changes to public visibility; nil publishes immediately
+	moderator TrackModerator
+	// availability describes tracks' playback options; nil leaves them out
+	availability *PlaybackAvailabilityService
+	now          func() time.Time
 }
 
 // NewTrackService creates a new track service
-func NewTrackService(repo repository.Repository, s3Repo repository.S3Repository) TrackService {
+func NewTrackService(repo repository.Repository, s3Repo repository.MediaStore) TrackService {
 	return &trackService{
 		repo:   repo,
 		s3Repo: s3Repo,
+		now:    time.Now,
 	}
 }
 
@@ -80,7 +87,14 @@ func (s *trackService) GetTrack(ctx context.Context, requesterID, trackID string
 		}
 	}
 
-	response := track.ToResponse(coverArtURL)
+	response := s.availability.TrackResponse(*track, coverArtURL)
+	for _, artwork := range track.Artwork {
+		url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, artwork.Key, 24*time.Hour)
+		if err != nil {
+			continue
+		}
+		response.Artwork = append(response.Artwork, models.ArtworkResponse{Type: artwork.Type, URL: url, Description: artwork.Description})
+	}
 	return &response, nil
 }
 
@@ -93,6 +107,10 @@ func (s *trackService) UpdateTrack(ctx context.Context, userID, trackID string,
 		return nil, err
 	}
 
+	if err := track.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+
 	// Apply updates
 	if req.Title != nil {
 		track.Title = *req.Title
@@ -140,7 +158,7 @@ func (s *trackService) UpdateTrack(ctx context.Context, userID, trackID string,
 		}
 	}
 
-	response := track.ToResponse(coverArtURL)
+	response := s.availability.TrackResponse(*track, coverArtURL)
 	return &response, nil
 }
 
@@ -173,6 +191,11 @@ func (s *trackService) DeleteTrack(ctx context.Context, userID, trackID string,
 		return models.NewNotFoundError("Track", trackID)
 	}
 
+	// Locked tracks must be unlocked by their owner first, even for admins
+	if err := track.EnsureUnlocked(); err != nil {
+		return err
+	}
+
 	// Delete from repository using the actual owner's ID
 	if err := s.repo.DeleteTrack(ctx, ownerID, trackID); err != nil {
 		return err
@@ -185,11 +208,15 @@ func (s *trackService) DeleteTrack(ctx context.Context, userID, trackID string,
 	if track.CoverArtKey != "" {
 		_ = s.s3Repo.DeleteObject(ctx, track.CoverArtKey)
 	}
+	deleteCoverThumbnail(ctx, s.s3Repo, track.CoverStyle)
+	for _, artwork := range track.Artwork {
+		_ = s.s3Repo.DeleteObject(ctx, artwork.Key)
+	}
 
-	// Delete HLS transcoded files if they exist (best effort)
-	// HLS files are stored at hls/{userID}/{trackID}/
-	if track.HLSPlaylistKey != "" {
-		hlsPrefix := "hls/" + ownerID + "/" + trackID + "/"
+	// Delete HLS transcoded files (best effort), stored at hls/{userID}/{trackID}/.
+	// Failed and unfinished jobs leave segments without a playlist key, so the
+	// prefix is cleared whatever the track's HLS status.
+	if hlsPrefix, err := BuildHLSPrefix(ownerID, trackID); err == nil {
 		_ = s.s3Repo.DeleteByPrefix(ctx, hlsPrefix)
 	}
 
@@ -233,11 +260,12 @@ func (s *trackService) listAllTracks(ctx context.Context, userID string, filter
 		}
 	}
 
+	saveData := dataSaver(ctx, s.repo, userID)
 	responses := make([]models.TrackResponse, 0, len(result.Items))
 	for _, track := range result.Items {
 		coverArtURL := ""
 		if track.CoverArtKey != "" {
-			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData), 24*time.Hour)
 			if err == nil {
 				coverArtURL = url
 			}
@@ -249,7 +277,7 @@ func (s *trackService) listAllTracks(ctx context.Context, userID string, filter
 		} else {
 			track.OwnerDisplayName = displayNames[track.UserID]
 		}
-		responses = append(responses, track.ToResponse(coverArtURL))
+		responses = append(responses, s.availability.TrackResponse(track, coverArtURL))
 	}
 
 	return &repository.PaginatedResult[models.TrackResponse]{
@@ -269,6 +297,7 @@ func (s *trackService) listTracksForRegularUser(ctx context.Context, userID stri
 
 	// Track IDs we've seen for deduplication
 	seenIDs := make(map[string]bool)
+	saveData := dataSaver(ctx, s.repo, userID)
 	responses := make([]models.TrackResponse, 0)
 
 	// Process own tracks
@@ -276,12 +305,12 @@ func (s *trackService) listTracksForRegularUser(ctx context.Context, userID stri
 		seenIDs[track.ID] = true
 		coverArtURL := ""
 		if track.CoverArtKey != "" {
-			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData), 24*time.Hour)
 			if err == nil {
 				coverArtURL = url
 			}
 		}
-		responses = append(responses, track.ToResponse(coverArtURL))
+		responses = append(responses, s.availability.TrackResponse(track, coverArtURL))
 	}
 
 	// Also fetch public tracks from other users
@@ -300,12 +329,12 @@ func (s *trackService) listTracksForRegularUser(ctx context.Context, userID stri
 
 			coverArtURL := ""
 			if track.CoverArtKey != "" {
-				url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+				url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData), 24*time.Hour)
 				if err == nil {
 					coverArtURL = url
 				}
 			}
-			responses = append(responses, track.ToResponse(coverArtURL))
+			responses = append(responses, s.availability.TrackResponse(track, coverArtURL))
 		}
 	}
 
@@ -322,16 +351,17 @@ func (s *trackService) ListTracksByArtist(ctx context.Context, userID, artist st
 		return nil, err
 	}
 
+	saveData := dataSaver(ctx, s.repo, userID)
 	responses := make([]models.TrackResponse, 0, len(tracks))
 	for _, track := range tracks {
 		coverArtURL := ""
 		if track.CoverArtKey != "" {
-			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+			url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverStyle.ListCoverKey(track.CoverArtKey, saveData), 24*time.Hour)
 			if err == nil {
 				coverArtURL = url
 			}
 		}
-		responses = append(responses, track.ToResponse(coverArtURL))
+		responses = append(responses, s.availability.TrackResponse(track, coverArtURL))
 	}
 
 	return responses, nil
@@ -353,28 +383,106 @@ func (s *trackService) IncrementPlayCount(ctx context.Context, userID, trackID s
 	return s.repo.UpdateTrack(ctx, *track)
 }
 
+// SetModerator holds changes to public visibility for moderation
+func (s *trackService) SetModerator(moderator TrackModerator) {
+	s.moderator = moderator
+}
+
+// SetPlaybackAvailability describes the playback options of returned tracks
+func (s *trackService) SetPlaybackAvailability(availability *PlaybackAvailabilityService) {
+	s.availability = availability
+}
+
 // UpdateVisibility updates the visibility of a track.
-// Only the track owner can update visibility.
-func (s *trackService) UpdateVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error {
+// Only the track owner can update visibility. With a moderator, making a
+// track public is held as pending until the moderation check passes.
+func (s *trackService) UpdateVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) (*models.TrackVisibilityUpdate, error) {
 	// Validate visibility value
 	if !visibility.IsValid() {
-		return models.NewValidationError("invalid visibility value")
+		return nil, models.NewValidationError("invalid visibility value")
 	}
 
 	// Verify track exists and belongs to user
-	_, err := s.repo.GetTrack(ctx, userID, trackID)
+	track, err := s.repo.GetTrack(ctx, userID, trackID)
 	if err != nil {
 		if err == repository.ErrNotFound {
-			return models.NewNotFoundError("Track", trackID)
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+
+	if err := track.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+
+	if s.moderator != nil && RequiresModeration(*track, visibility) {
+		if track.PendingVisibility == visibility {
+			// Already waiting on moderation
+			return &models.TrackVisibilityUpdate{TrackID: trackID, Visibility: track.GetVisibility(), PendingVisibility: visibility, ModerationStatus: track.ModerationStatus}, nil
+		}
+		review, err := s.moderator.Submit(ctx, *track, visibility)
+		if err != nil {
+			return nil, err
+		}
+		return &models.TrackVisibilityUpdate{TrackID: trackID, Visibility: track.GetVisibility(), PendingVisibility: visibility, ModerationStatus: review.Status}, nil
+	}
+
+	// Any other change supersedes a change still held for moderation
+	if track.PendingVisibility != "" {
+		track.PendingVisibility = ""
+		track.ModerationStatus = ""
+		if err := s.repo.UpdateTrack(ctx, *track); err != nil {
+			return nil, err
 		}
-		return err
 	}
 
 	// Update visibility in repository (this also updates GSI3 keys for public discovery)
-	return s.repo.UpdateTrackVisibility(ctx, userID, trackID, visibility)
+	if err := s.repo.UpdateTrackVisibility(ctx, userID, trackID, visibility); err != nil {
+		return nil, err
+	}
+	return &models.TrackVisibilityUpdate{TrackID: trackID, Visibility: visibility}, nil
+}
+
+// SetLocked locks or unlocks a track.
+// Only the track owner can change the lock; a locked track rejects metadata edits,
+// deletion and bulk operations until it is unlocked again.
+func (s *trackService) SetLocked(ctx context.Context, userID, trackID string, locked bool) (*models.TrackResponse, error) {
+	track, err := s.repo.GetTrack(ctx, userID, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+
+	if track.Locked != locked {
+		track.Locked = locked
+		if locked {
+			now := time.Now()
+			track.LockedAt = &now
+		} else {
+			track.LockedAt = nil
+		}
+
+		if err := s.repo.UpdateTrack(ctx, *track); err != nil {
+			return nil, err
+		}
+	}
+
+	coverArtURL := ""
+	if track.CoverArtKey != "" {
+		url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+		if err == nil {
+			coverArtURL = url
+		}
+	}
+
+	response := s.availability.TrackResponse(*track, coverArtURL)
+	return &response, nil
 }
 
-// GetLibraryStats returns aggregated library statistics based on scope
+// GetLibraryStats returns aggregated library statistics based on scope.
+// Days, weeks and months are those of the user's settings.timeZone.
 func (s *trackService) GetLibraryStats(ctx context.Context, userID string, scope StatsScope, hasGlobal bool) (*LibraryStats, error) {
 	var tracks []models.Track
 
@@ -425,10 +533,20 @@ func (s *trackService) GetLibraryStats(ctx context.Context, userID string, scope
 		}
 	}
 
+	loc, err := s.statsLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	// Weeks start on Monday
+	week := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+
 	// Aggregate stats
 	albums := make(map[string]bool)
 	artists := make(map[string]bool)
 	totalDuration := 0
+	stats := &LibraryStats{AddedByMonth: map[string]int{}, TimeZone: loc.String()}
 
 	for _, track := range tracks {
 		if track.Album != "" {
@@ -438,12 +556,47 @@ func (s *trackService) GetLibraryStats(ctx context.Context, userID string, scope
 			artists[track.Artist] = true
 		}
 		totalDuration += track.Duration
+
+		if !track.CreatedAt.IsZero() {
+			added := track.CreatedAt.In(loc)
+			stats.AddedByMonth[added.Format("2006-01")]++
+			if !added.Before(week) {
+				stats.AddedThisWeek++
+			}
+			if !added.Before(today) {
+				stats.AddedToday++
+			}
+		}
+		if track.LastPlayed != nil && !track.LastPlayed.IsZero() {
+			if !track.LastPlayed.Before(week) {
+				stats.PlayedThisWeek++
+			}
+			if !track.LastPlayed.Before(today) {
+				stats.PlayedToday++
+			}
+		}
 	}
 
-	return &LibraryStats{
-		TotalTracks:   len(tracks),
-		TotalAlbums:   len(albums),
-		TotalArtists:  len(artists),
-		TotalDuration: totalDuration,
-	}, nil
+	stats.TotalTracks = len(tracks)
+	stats.TotalAlbums = len(albums)
+	stats.TotalArtists = len(artists)
+	stats.TotalDuration = totalDuration
+	return stats, nil
+}
+
+// statsLocation returns the time zone the user's stats are bucketed in, UTC
+// for users without settings or with a zone no longer known
+func (s *trackService) statsLocation(ctx context.Context, userID string) (*time.Location, error) {
+	settings, err := s.repo.GetUserSettings(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) || errors.Is(err, repository.ErrUserNotFound) {
+		return time.UTC, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	loc, err := settings.Location()
+	if err != nil {
+		return time.UTC, nil
+	}
+	return loc, nil
 }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/analysis"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// UpdateAnalysis corrects a track's analyzed BPM or key. Corrected values are
+// marked as overridden so that reanalysis, such as after a file replacement,
+// does not replace them.
+func (s *trackService) UpdateAnalysis(ctx context.Context, userID, trackID string, req models.UpdateTrackAnalysisRequest) (*models.TrackResponse, error) {
+	if req.BPM != nil && req.BPMAction != "" {
+		return nil, models.NewValidationError("bpm and bpmAction cannot be combined")
+	}
+
+	track, err := s.repo.GetTrack(ctx, userID, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+
+	if err := track.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+
+	if req.ClearOverrides {
+		track.BPMOverridden = false
+		track.KeyOverridden = false
+	}
+
+	if req.BPM != nil {
+		track.BPM = *req.BPM
+		track.BPMOverridden = true
+	}
+	if req.BPMAction != "" {
+		bpm, err := correctBPM(track.BPM, req.BPMAction)
+		if err != nil {
+			return nil, err
+		}
+		track.BPM = bpm
+		track.BPMOverridden = true
+	}
+
+	if req.MusicalKey != nil {
+		if err := setMusicalKey(track, *req.MusicalKey); err != nil {
+			return nil, err
+		}
+		track.KeyOverridden = true
+	}
+
+	if err := s.repo.UpdateTrack(ctx, *track); err != nil {
+		return nil, err
+	}
+
+	coverArtURL := ""
+	if track.CoverArtKey != "" {
+		url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+		if err == nil {
+			coverArtURL = url
+		}
+	}
+
+	response := s.availability.TrackResponse(*track, coverArtURL)
+	return &response, nil
+}
+
+// correctBPM doubles or halves a BPM the analyzer locked onto at the wrong
+// metrical level, keeping the result within the valid BPM range
+func correctBPM(bpm int, action string) (int, error) {
+	if bpm == 0 {
+		return 0, models.NewValidationError("track has no BPM to correct")
+	}
+
+	corrected := bpm * 2
+	if action == models.BPMActionHalve {
+		corrected = (bpm + 1) / 2
+	}
+	if corrected < models.MinBPM || corrected > models.MaxBPM {
+		return 0, models.NewValidationError(fmt.Sprintf("corrected BPM %d is outside %d-%d", corrected, models.MinBPM, models.MaxBPM))
+	}
+	return corrected, nil
+}
+
+// setMusicalKey sets the key, mode and Camelot notation of a track from a key
+// such as "Am" or "F#". An empty key marks the key as unknown.
+func setMusicalKey(track *models.Track, key string) error {
+	// The analyzer's confidence and candidates do not apply to a chosen key
+	track.KeyConfidence, track.KeyCandidates = 0, nil
+	if key == "" {
+		track.MusicalKey, track.KeyMode, track.KeyCamelot = "", "", ""
+		return nil
+	}
+
+	camelot, ok := analysis.CamelotWheel[key]
+	if !ok {
+		return models.NewValidationError(fmt.Sprintf("unknown musical key %q", key))
+	}
+	mode := "major"
+	if strings.HasSuffix(key, "m") {
+		mode = "minor"
+	}
+	track.MusicalKey, track.KeyMode, track.KeyCamelot = key, mode, camelot
+	return nil
+}
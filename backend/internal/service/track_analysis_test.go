@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackService_UpdateAnalysis(t *testing.T) {
+	ptr := func(v int) *int { return &v }
+	str := func(v string) *string { return &v }
+
+	tests := []struct {
+		name  string
+		track models.Track
+		req   models.UpdateTrackAnalysisRequest
+		check func(t *testing.T, tr models.Track)
+	}{
+		{
+			name:  "doubles a half-time BPM",
+			track: models.Track{BPM: 87},
+			req:   models.UpdateTrackAnalysisRequest{BPMAction: models.BPMActionDouble},
+			check: func(t *testing.T, tr models.Track) {
+				assert.Equal(t, 174, tr.BPM)
+				assert.True(t, tr.BPMOverridden)
+				assert.False(t, tr.KeyOverridden)
+			},
+		},
+		{
+			name:  "halves a double-time BPM",
+			track: models.Track{BPM: 255},
+			req:   models.UpdateTrackAnalysisRequest{BPMAction: models.BPMActionHalve},
+			check: func(t *testing.T, tr models.Track) {
+				assert.Equal(t, 128, tr.BPM)
+			},
+		},
+		{
+			name:  "sets BPM and key manually",
+			track: models.Track{BPM: 120, MusicalKey: "C", KeyMode: "major", KeyCamelot: "8B", KeyConfidence: 0.4, KeyCandidates: []models.KeyCandidate{{Key: "Am"}}},
+			req:   models.UpdateTrackAnalysisRequest{BPM: ptr(122), MusicalKey: str("F#m")},
+			check: func(t *testing.T, tr models.Track) {
+				assert.Equal(t, 122, tr.BPM)
+				assert.Equal(t, "F#m", tr.MusicalKey)
+				assert.Equal(t, "minor", tr.KeyMode)
+				assert.Equal(t, "11A", tr.KeyCamelot)
+				assert.True(t, tr.BPMOverridden)
+				assert.True(t, tr.KeyOverridden)
+				assert.Zero(t, tr.KeyConfidence)
+				assert.Empty(t, tr.KeyCandidates)
+			},
+		},
+		{
+			name:  "clears overrides without changing values",
+			track: models.Track{BPM: 174, BPMOverridden: true, KeyOverridden: true},
+			req:   models.UpdateTrackAnalysisRequest{ClearOverrides: true},
+			check: func(t *testing.T, tr models.Track) {
+				assert.Equal(t, 174, tr.BPM)
+				assert.False(t, tr.BPMOverridden)
+				assert.False(t, tr.KeyOverridden)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockRepo := new(mocks.Repository)
+			mockS3 := new(mocks.MediaStore)
+
+			track := tt.track
+			track.ID, track.UserID = "track-1", "user-1"
+			var saved models.Track
+			mockRepo.On("GetTrack", ctx, "user-1", "track-1").Return(&track, nil)
+			mockRepo.On("UpdateTrack", ctx, mock.AnythingOfType("models.Track")).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(models.Track)
+			}).Return(nil)
+
+			svc := NewTrackService(mockRepo, mockS3)
+			result, err := svc.UpdateAnalysis(ctx, "user-1", "track-1", tt.req)
+
+			require.NoError(t, err)
+			assert.Equal(t, saved.BPM, result.BPM)
+			tt.check(t, saved)
+		})
+	}
+}
+
+func TestTrackService_UpdateAnalysis_Rejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		track models.Track
+		req   models.UpdateTrackAnalysisRequest
+		want  error
+	}{
+		{
+			name:  "doubling past the BPM range",
+			track: models.Track{BPM: 160},
+			req:   models.UpdateTrackAnalysisRequest{BPMAction: models.BPMActionDouble},
+		},
+		{
+			name: "correcting an unknown BPM",
+			req:  models.UpdateTrackAnalysisRequest{BPMAction: models.BPMActionHalve},
+		},
+		{
+			name: "unknown key",
+			req:  models.UpdateTrackAnalysisRequest{MusicalKey: func() *string { k := "H"; return &k }()},
+		},
+		{
+			name:  "locked track",
+			track: models.Track{BPM: 87, Locked: true},
+			req:   models.UpdateTrackAnalysisRequest{BPMAction: models.BPMActionDouble},
+			want:  models.ErrTrackLocked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockRepo := new(mocks.Repository)
+			mockS3 := new(mocks.MediaStore)
+
+			track := tt.track
+			mockRepo.On("GetTrack", ctx, "user-1", "track-1").Return(&track, nil)
+
+			svc := NewTrackService(mockRepo, mockS3)
+			_, err := svc.UpdateAnalysis(ctx, "user-1", "track-1", tt.req)
+
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+			} else {
+				var apiErr *models.APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+			}
+			mockRepo.AssertNotCalled(t, "UpdateTrack", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("bpm combined with bpmAction", func(t *testing.T) {
+		bpm := 120
+		svc := NewTrackService(new(mocks.Repository), new(mocks.MediaStore))
+
+		_, err := svc.UpdateAnalysis(context.Background(), "user-1", "track-1", models.UpdateTrackAnalysisRequest{BPM: &bpm, BPMAction: models.BPMActionDouble})
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+	})
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackService_SetLocked(t *testing.T) {
+	t.Run("locks an unlocked track", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
+
+		track := &models.Track{ID: "track-1", UserID: "user-1", Title: "Archive Cut"}
+		mockRepo.On("GetTrack", ctx, "user-1", "track-1").Return(track, nil)
+		mockRepo.On("UpdateTrack", ctx, mock.MatchedBy(func(tr models.Track) bool {
+			return tr.Locked && tr.LockedAt != nil
+		})).Return(nil)
+
+		svc := NewTrackService(mockRepo, mockS3)
+		result, err := svc.SetLocked(ctx, "user-1", "track-1", true)
+
+		require.NoError(t, err)
+		assert.True(t, result.Locked)
+		assert.NotNil(t, result.LockedAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("skips the write when the lock state is unchanged", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
+
+		track := &models.Track{ID: "track-1", UserID: "user-1", Locked: true}
+		mockRepo.On("GetTrack", ctx, "user-1", "track-1").Return(track, nil)
+
+		svc := NewTrackService(mockRepo, mockS3)
+		result, err := svc.SetLocked(ctx, "user-1", "track-1", true)
+
+		require.NoError(t, err)
+		assert.True(t, result.Locked)
+		mockRepo.AssertNotCalled(t, "UpdateTrack", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTrackService_LockedTrackRejectsChanges(t *testing.T) {
+	title := "New Title"
+
+	t.Run("update returns TRACK_LOCKED", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
+
+		track := &models.Track{ID: "track-1", UserID: "user-1", Locked: true}
+		mockRepo.On("GetTrack", ctx, "user-1", "track-1").Return(track, nil)
+
+		svc := NewTrackService(mockRepo, mockS3)
+		_, err := svc.UpdateTrack(ctx, "user-1", "track-1", models.UpdateTrackRequest{Title: &title})
+
+		assert.ErrorIs(t, err, models.ErrTrackLocked)
+		mockRepo.AssertNotCalled(t, "UpdateTrack", mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete returns TRACK_LOCKED even for admins", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
+
+		track := &models.Track{ID: "track-1", UserID: "owner-1", Locked: true}
+		mockRepo.On("GetTrack", ctx, "admin-1", "track-1").Return(nil, nil)
+		mockRepo.On("GetTrackByID", ctx, "track-1").Return(track, nil)
+
+		svc := NewTrackService(mockRepo, mockS3)
+		err := svc.DeleteTrack(ctx, "admin-1", "track-1", true)
+
+		assert.ErrorIs(t, err, models.ErrTrackLocked)
+	})
+
+	t.Run("visibility change returns TRACK_LOCKED", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
+
+		track := &models.Track{ID: "track-1", UserID: "user-1", Locked: true}
+		mockRepo.On("GetTrack", ctx, "user-1", "track-1").Return(track, nil)
+
+		svc := NewTrackService(mockRepo, mockS3)
+		_, err := svc.UpdateVisibility(ctx, "user-1", "track-1", models.VisibilityPublic)
+
+		assert.ErrorIs(t, err, models.ErrTrackLocked)
+	})
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// UpdatePlaybackSettings changes a track's gain offset and EQ preset. They
+// are returned with the track's stream URL so every client applies them.
+// Locked tracks accept the change, since it leaves the track's metadata alone.
+func (s *trackService) UpdatePlaybackSettings(ctx context.Context, userID, trackID string, req models.UpdatePlaybackSettingsRequest) (*models.TrackResponse, error) {
+	if req.GainDB == nil && req.EQPreset == nil {
+		return nil, models.NewValidationError("gainDb or eqPreset is required")
+	}
+
+	track, err := s.repo.GetTrack(ctx, userID, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+
+	track.ApplyPlaybackSettings(req)
+
+	if err := s.repo.UpdateTrack(ctx, *track); err != nil {
+		return nil, err
+	}
+
+	coverArtURL := ""
+	if track.CoverArtKey != "" {
+		url, err := s.s3Repo.GeneratePresignedDownloadURL(ctx, track.CoverArtKey, 24*time.Hour)
+		if err == nil {
+			coverArtURL = url
+		}
+	}
+
+	response := s.availability.TrackResponse(*track, coverArtURL)
+	return &response, nil
+}
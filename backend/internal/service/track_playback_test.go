@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackService_UpdatePlaybackSettings(t *testing.T) {
+	gain := func(v float64) *float64 { return &v }
+	preset := func(v string) *string { return &v }
+
+	t.Run("stores settings on a locked track", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
+
+		track := models.Track{ID: "track-1", UserID: "user-1", Locked: true}
+		var saved models.Track
+		mockRepo.On("GetTrack", ctx, "user-1", "track-1").Return(&track, nil)
+		mockRepo.On("UpdateTrack", ctx, mock.AnythingOfType("models.Track")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(models.Track)
+		}).Return(nil)
+
+		svc := NewTrackService(mockRepo, mockS3)
+		result, err := svc.UpdatePlaybackSettings(ctx, "user-1", "track-1", models.UpdatePlaybackSettingsRequest{GainDB: gain(-6), EQPreset: preset("treble_cut")})
+
+		require.NoError(t, err)
+		want := &models.TrackPlaybackSettings{GainDB: -6, EQPreset: "treble_cut"}
+		assert.Equal(t, want, saved.PlaybackSettings)
+		assert.Equal(t, want, result.PlaybackSettings)
+	})
+
+	t.Run("clears settings", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
+
+		track := models.Track{ID: "track-1", UserID: "user-1", PlaybackSettings: &models.TrackPlaybackSettings{GainDB: 3}}
+		var saved models.Track
+		mockRepo.On("GetTrack", ctx, "user-1", "track-1").Return(&track, nil)
+		mockRepo.On("UpdateTrack", ctx, mock.AnythingOfType("models.Track")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(models.Track)
+		}).Return(nil)
+
+		svc := NewTrackService(mockRepo, mockS3)
+		result, err := svc.UpdatePlaybackSettings(ctx, "user-1", "track-1", models.UpdatePlaybackSettingsRequest{GainDB: gain(0)})
+
+		require.NoError(t, err)
+		assert.Nil(t, saved.PlaybackSettings)
+		assert.Nil(t, result.PlaybackSettings)
+	})
+
+	t.Run("rejects an empty request", func(t *testing.T) {
+		mockRepo := new(mocks.Repository)
+		svc := NewTrackService(mockRepo, new(mocks.MediaStore))
+
+		_, err := svc.UpdatePlaybackSettings(context.Background(), "user-1", "track-1", models.UpdatePlaybackSettingsRequest{})
+
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+		mockRepo.AssertNotCalled(t, "GetTrack", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
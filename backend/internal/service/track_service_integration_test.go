@@ -121,6 +121,12 @@ func TestIntegration_TrackService_AdminDelete(t *testing.T) {
 		// Verify track is gone from DynamoDB
 		_, err = repo.GetTrack(ctx, "del-owner", "del-track")
 		assert.Error(t, err)
+
+		// HLS output goes too, even though the track had no playlist key
+		_, err = tc.S3.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(tc.BucketName), Key: aws.String("hls/del-owner/del-track/master.m3u8"),
+		})
+		assert.Error(t, err)
 	})
 }
 
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// TrackTransferRepository defines the repository interface for moving tracks between users
+type TrackTransferRepository interface {
+	GetTrackByID(ctx context.Context, trackID string) (*models.Track, error)
+	CreateTrack(ctx context.Context, track models.Track) error
+	DeleteTrack(ctx context.Context, userID, trackID string) error
+	GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	UpdateUserStats(ctx context.Context, userID string, storageUsed int64, trackCount, albumCount, playlistCount int) error
+	RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error
+	GetTag(ctx context.Context, userID, tagName string) (*models.Tag, error)
+	UpdateTag(ctx context.Context, tag models.Tag) error
+	ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*repository.PaginatedResult[models.Playlist], error)
+	GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error)
+	RemoveTracksFromPlaylist(ctx context.Context, playlistID string, trackIDs []string) error
+	UpdatePlaylist(ctx context.Context, playlist models.Playlist) error
+}
+
+// TrackIndexer writes a track's search document
+type TrackIndexer interface {
+	IndexTrack(ctx context.Context, track models.Track) error
+}
+
+// TrackTransferService moves tracks from one user's library to another's for admins
+type TrackTransferService struct {
+	repo    TrackTransferRepository
+	s3Repo  repository.MediaStore
+	indexer TrackIndexer
+}
+
+// NewTrackTransferService creates a new track transfer service
+func NewTrackTransferService(repo TrackTransferRepository, s3Repo repository.MediaStore) *TrackTransferService {
+	return &TrackTransferService{repo: repo, s3Repo: s3Repo}
+}
+
+// SetIndexer makes transfers update the track's search document
+func (s *TrackTransferService) SetIndexer(indexer TrackIndexer) {
+	s.indexer = indexer
+}
+
+// Transfer moves a track, keeping its ID, to the library of req.ToUserID.
+// The audio file and artwork are copied under the new owner's keys before the
+// track is re-keyed, so a failure up to that point leaves the source intact.
+// Afterwards the previous owner's tags, playlist entries and files are
+// removed, and both users' storage is adjusted. HLS renditions are dropped
+// and the track streams from its audio file until it is transcoded again.
+func (s *TrackTransferService) Transfer(ctx context.Context, trackID string, req models.TransferTrackRequest) (*models.TrackTransferResponse, error) {
+	source, err := s.repo.GetTrackByID(ctx, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+
+	// Locked tracks must be unlocked by their owner first, even for admins
+	if err := source.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+	if source.ModerationStatus.IsOpen() {
+		return nil, models.NewConflictError("track has a visibility change awaiting moderation")
+	}
+
+	toUserID := req.ToUserID
+	if scoped, ok := s.repo.(LibraryIDResolver); ok {
+		if toUserID, err = scoped.ResolveLibraryID(ctx, toUserID); err != nil {
+			return nil, err
+		}
+	}
+	if toUserID == source.UserID {
+		return nil, models.NewConflictError("track already belongs to that library")
+	}
+
+	recipient, err := s.repo.GetUser(ctx, toUserID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("User", req.ToUserID)
+		}
+		return nil, err
+	}
+	if exceedsStorageLimit(recipient, source.FileSize) {
+		return nil, models.ErrStorageLimitExceeded
+	}
+
+	track := movedTrack(*source, toUserID, time.Now())
+	// The moved track and any album made for it sort in the new library's order
+	ctx = collation.WithLocale(ctx, recipient.Settings.Library.SortLocale)
+	if err := s.s3Repo.CopyObject(ctx, source.S3Key, track.S3Key); err != nil {
+		return nil, fmt.Errorf("failed to copy audio file: %w", err)
+	}
+	copied := []string{track.S3Key}
+
+	// Artwork is optional; an image that fails to copy is left off the track
+	if source.CoverArtKey != "" {
+		coverKey := fmt.Sprintf("covers/%s/%s%s", toUserID, track.ID, path.Ext(source.CoverArtKey))
+		if err := s.s3Repo.CopyObject(ctx, source.CoverArtKey, coverKey); err == nil {
+			track.CoverArtKey = coverKey
+			track.CoverStyle = copyCoverStyle(ctx, s.s3Repo, source.CoverStyle, coverThumbnailKey(toUserID, track.ID))
+			copied = append(copied, coverKey)
+			if track.CoverStyle != nil && track.CoverStyle.ThumbnailKey != "" {
+				copied = append(copied, track.CoverStyle.ThumbnailKey)
+			}
+		}
+	}
+	for _, artwork := range source.Artwork {
+		key := fmt.Sprintf("covers/%s/%s/%s", toUserID, track.ID, path.Base(artwork.Key))
+		if err := s.s3Repo.CopyObject(ctx, artwork.Key, key); err == nil {
+			artwork.Key = key
+			track.Artwork = append(track.Artwork, artwork)
+			copied = append(copied, key)
+		}
+	}
+
+	if track.Album != "" {
+		if album, err := s.repo.GetOrCreateAlbum(ctx, toUserID, track.Album, track.Artist); err == nil {
+			track.AlbumID = album.ID
+		} else {
+			fmt.Printf("Warning: failed to create album for transferred track %s: %v\n", track.ID, err)
+		}
+	}
+
+	if err := s.repo.CreateTrack(ctx, track); err != nil {
+		for _, key := range copied {
+			_ = s.s3Repo.DeleteObject(ctx, key)
+		}
+		return nil, err
+	}
+	if err := s.repo.DeleteTrack(ctx, source.UserID, source.ID); err != nil {
+		return nil, fmt.Errorf("track copied to %s but not removed from %s: %w", toUserID, source.UserID, err)
+	}
+
+	resp := &models.TrackTransferResponse{TrackID: track.ID, FromUserID: source.UserID, ToUserID: toUserID}
+
+	// The rest only tidies up after the previous owner, so failures are logged
+	s.removeTags(ctx, *source)
+	if resp.PlaylistsUpdated, err = s.removeFromPlaylists(ctx, *source); err != nil {
+		fmt.Printf("Warning: failed to remove transferred track %s from playlists: %v\n", source.ID, err)
+	}
+	s.deleteFiles(ctx, *source)
+	if err := s.adjustStorage(ctx, source.UserID, -source.FileSize, -1); err != nil {
+		fmt.Printf("Warning: failed to adjust storage of %s: %v\n", source.UserID, err)
+	}
+	if err := s.adjustStorage(ctx, toUserID, source.FileSize, 1); err != nil {
+		fmt.Printf("Warning: failed to adjust storage of %s: %v\n", toUserID, err)
+	}
+
+	if s.indexer != nil {
+		if err := s.indexer.IndexTrack(ctx, track); err != nil {
+			fmt.Printf("Warning: failed to reindex transferred track %s: %v\n", track.ID, err)
+		} else {
+			resp.Reindexed = true
+		}
+	}
+
+	return resp, nil
+}
+
+// movedTrack builds the new owner's record of a transferred track. Plays,
+// analysis and visibility carry over; the previous owner's tags and the
+// references to their artist and album entities do not.
+func movedTrack(source models.Track, userID string, now time.Time) models.Track {
+	track := source
+	track.UserID = userID
+	track.S3Key = fmt.Sprintf("media/%s/%s%s", userID, source.ID, path.Ext(source.S3Key))
+	track.CoverArtKey = ""
+	track.CoverStyle = nil
+	track.SortLocale = ""
+	track.Artwork = nil
+	track.ArtistID = ""
+	track.Artists = nil
+	for _, contribution := range source.Artists {
+		contribution.ArtistID = ""
+		track.Artists = append(track.Artists, contribution)
+	}
+	track.AlbumID = ""
+	track.Tags = nil
+	track.HLSStatus = ""
+	track.HLSPlaylistKey = ""
+	track.HLSJobID = ""
+	track.HLSTranscodedAt = nil
+	track.HLSFailures = 0
+	track.PreviewStatus = ""
+	track.PreviewKey = ""
+	track.PreviewJobID = ""
+	track.ArchivedS3Key = ""
+	track.ArchiveExpiresAt = nil
+	track.OwnerDisplayName = ""
+	track.UpdatedAt = now
+	return track
+}
+
+// removeTags drops the track from the previous owner's tags
+func (s *TrackTransferService) removeTags(ctx context.Context, source models.Track) {
+	for _, tagName := range source.Tags {
+		if err := s.repo.RemoveTagFromTrack(ctx, source.UserID, source.ID, tagName); err != nil {
+			fmt.Printf("Warning: failed to untag transferred track %s: %v\n", source.ID, err)
+			continue
+		}
+		tag, err := s.repo.GetTag(ctx, source.UserID, tagName)
+		if err == nil && tag.TrackCount > 0 {
+			tag.TrackCount--
+			tag.UpdatedAt = time.Now()
+			_ = s.repo.UpdateTag(ctx, *tag)
+		}
+	}
+}
+
+// removeFromPlaylists removes the track from every playlist of its previous
+// owner and returns how many playlists changed
+func (s *TrackTransferService) removeFromPlaylists(ctx context.Context, source models.Track) (int, error) {
+	updated := 0
+	filter := models.PlaylistFilter{Limit: 100}
+	for {
+		page, err := s.repo.ListPlaylists(ctx, source.UserID, filter)
+		if err != nil {
+			return updated, err
+		}
+		for _, playlist := range page.Items {
+			entries, err := s.repo.GetPlaylistTracks(ctx, playlist.ID)
+			if err != nil {
+				return updated, err
+			}
+			removed := 0
+			for _, entry := range entries {
+				if entry.TrackID == source.ID {
+					removed++
+				}
+			}
+			if removed == 0 {
+				continue
+			}
+			if err := s.repo.RemoveTracksFromPlaylist(ctx, playlist.ID, []string{source.ID}); err != nil {
+				return updated, err
+			}
+			playlist.TrackCount = max(playlist.TrackCount-removed, 0)
+			playlist.TotalDuration = max(playlist.TotalDuration-removed*source.Duration, 0)
+			if err := s.repo.UpdatePlaylist(ctx, playlist); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+		if !page.HasMore {
+			return updated, nil
+		}
+		filter.LastKey = page.NextCursor
+	}
+}
+
+// deleteFiles removes the previous owner's copies of the track's files (best effort)
+func (s *TrackTransferService) deleteFiles(ctx context.Context, source models.Track) {
+	_ = s.s3Repo.DeleteObject(ctx, source.S3Key)
+	if source.CoverArtKey != "" {
+		_ = s.s3Repo.DeleteObject(ctx, source.CoverArtKey)
+	}
+	deleteCoverThumbnail(ctx, s.s3Repo, source.CoverStyle)
+	for _, artwork := range source.Artwork {
+		_ = s.s3Repo.DeleteObject(ctx, artwork.Key)
+	}
+	if source.ArchivedS3Key != "" {
+		_ = s.s3Repo.DeleteObject(ctx, source.ArchivedS3Key)
+	}
+	if hlsPrefix, err := BuildHLSPrefix(source.UserID, source.ID); err == nil {
+		_ = s.s3Repo.DeleteByPrefix(ctx, hlsPrefix)
+	}
+}
+
+// adjustStorage changes a user's storage used and track count by the given amounts
+func (s *TrackTransferService) adjustStorage(ctx context.Context, userID string, bytes int64, tracks int) error {
+	user, err := s.repo.GetUser(ctx, userID)
+	if err == repository.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	storage := user.StorageUsed + bytes
+	if storage < 0 {
+		storage = 0
+	}
+	return s.repo.UpdateUserStats(ctx, userID, storage, max(user.TrackCount+tracks, 0), user.AlbumCount, user.PlaylistCount)
+}
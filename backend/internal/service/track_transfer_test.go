@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingIndexer records the tracks it is asked to index
+type recordingIndexer struct {
+	indexed []models.Track
+	err     error
+}
+
+func (i *recordingIndexer) IndexTrack(ctx context.Context, track models.Track) error {
+	i.indexed = append(i.indexed, track)
+	return i.err
+}
+
+func TestTrackTransferService_Transfer(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*repository.MemoryRepository, *repository.MemoryS3Repository, *TrackTransferService, *recordingIndexer) {
+		repo := repository.NewMemoryRepository()
+		s3 := repository.NewMemoryS3Repository("")
+		require.NoError(t, repo.CreateUser(ctx, models.User{ID: "alice", StorageUsed: 100, TrackCount: 2}))
+		require.NoError(t, repo.CreateUser(ctx, models.User{ID: "bob", StorageUsed: 10, TrackCount: 1}))
+		require.NoError(t, repo.CreateTrack(ctx, models.Track{
+			ID: "t1", UserID: "alice", Title: "Archangel", Artist: "Burial", Album: "Untrue",
+			AlbumID: "alice-album", ArtistID: "alice-artist", Duration: 240, FileSize: 40, PlayCount: 7,
+			S3Key: "media/alice/t1.mp3", CoverArtKey: "covers/alice/t1.jpg",
+			Artwork:        []models.Artwork{{Type: "back", Key: "covers/alice/u1/2-back.png"}},
+			Tags:           []string{"night"},
+			HLSStatus:      models.HLSStatusReady,
+			HLSPlaylistKey: "hls/alice/t1/master.m3u8",
+		}))
+		require.NoError(t, repo.CreateTag(ctx, models.Tag{UserID: "alice", Name: "night", TrackCount: 1}))
+		require.NoError(t, repo.AddTagsToTrack(ctx, "alice", "t1", []string{"night"}))
+		require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "p1", UserID: "alice", TrackCount: 2, TotalDuration: 480}))
+		require.NoError(t, repo.AddTracksToPlaylist(ctx, "p1", []string{"t1", "t2"}, 0))
+		require.NoError(t, repo.CreatePlaylist(ctx, models.Playlist{ID: "p2", UserID: "alice", TrackCount: 1, TotalDuration: 200}))
+		require.NoError(t, repo.AddTracksToPlaylist(ctx, "p2", []string{"t2"}, 0))
+		for _, key := range []string{"media/alice/t1.mp3", "covers/alice/t1.jpg", "covers/alice/u1/2-back.png", "hls/alice/t1/master.m3u8"} {
+			s3.PutObject(key, nil)
+		}
+
+		indexer := &recordingIndexer{}
+		svc := NewTrackTransferService(repo, s3)
+		svc.SetIndexer(indexer)
+		return repo, s3, svc, indexer
+	}
+
+	t.Run("moves the track and its files", func(t *testing.T) {
+		repo, s3, svc, indexer := setup(t)
+
+		resp, err := svc.Transfer(ctx, "t1", models.TransferTrackRequest{ToUserID: "bob"})
+		require.NoError(t, err)
+		assert.Equal(t, &models.TrackTransferResponse{TrackID: "t1", FromUserID: "alice", ToUserID: "bob", PlaylistsUpdated: 1, Reindexed: true}, resp)
+
+		_, err = repo.GetTrack(ctx, "alice", "t1")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		track, err := repo.GetTrack(ctx, "bob", "t1")
+		require.NoError(t, err)
+		assert.Equal(t, "media/bob/t1.mp3", track.S3Key)
+		assert.Equal(t, "covers/bob/t1.jpg", track.CoverArtKey)
+		require.Len(t, track.Artwork, 1)
+		assert.Equal(t, "covers/bob/t1/2-back.png", track.Artwork[0].Key)
+		assert.Equal(t, 7, track.PlayCount)
+		assert.Empty(t, track.Tags)
+		assert.Empty(t, track.ArtistID)
+		assert.NotEmpty(t, track.AlbumID)
+		assert.NotEqual(t, "alice-album", track.AlbumID)
+		assert.Empty(t, track.HLSStatus)
+
+		assert.ElementsMatch(t, []string{"media/bob/t1.mp3", "covers/bob/t1.jpg", "covers/bob/t1/2-back.png"}, s3.Keys(""))
+
+		entries, err := repo.GetPlaylistTracks(ctx, "p1")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "t2", entries[0].TrackID)
+		playlist, err := repo.GetPlaylist(ctx, "alice", "p1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, playlist.TrackCount)
+		assert.Equal(t, 240, playlist.TotalDuration)
+
+		tags, err := repo.GetTrackTags(ctx, "alice", "t1")
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+		tag, err := repo.GetTag(ctx, "alice", "night")
+		require.NoError(t, err)
+		assert.Equal(t, 0, tag.TrackCount)
+
+		alice, err := repo.GetUser(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, int64(60), alice.StorageUsed)
+		assert.Equal(t, 1, alice.TrackCount)
+		bob, err := repo.GetUser(ctx, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, int64(50), bob.StorageUsed)
+		assert.Equal(t, 2, bob.TrackCount)
+
+		require.Len(t, indexer.indexed, 1)
+		assert.Equal(t, "bob", indexer.indexed[0].UserID)
+	})
+
+	t.Run("reports a failed reindex", func(t *testing.T) {
+		_, _, svc, indexer := setup(t)
+		indexer.err = errors.New("search unavailable")
+
+		resp, err := svc.Transfer(ctx, "t1", models.TransferTrackRequest{ToUserID: "bob"})
+		require.NoError(t, err)
+		assert.False(t, resp.Reindexed)
+	})
+
+	t.Run("rejects invalid transfers", func(t *testing.T) {
+		repo, s3, svc, _ := setup(t)
+		require.NoError(t, repo.CreateUser(ctx, models.User{ID: "carol", StorageUsed: 10, StorageLimit: 20}))
+
+		tests := []struct {
+			name     string
+			trackID  string
+			toUserID string
+			status   int
+		}{
+			{"missing track", "missing", "bob", 404},
+			{"missing user", "t1", "dave", 404},
+			{"same owner", "t1", "alice", 409},
+			{"over quota", "t1", "carol", 402},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := svc.Transfer(ctx, tt.trackID, models.TransferTrackRequest{ToUserID: tt.toUserID})
+				var apiErr *models.APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tt.status, apiErr.StatusCode)
+			})
+		}
+
+		_, err := repo.GetTrack(ctx, "alice", "t1")
+		assert.NoError(t, err)
+		assert.Len(t, s3.Keys(""), 4)
+	})
+}
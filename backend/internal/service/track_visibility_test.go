@@ -8,47 +8,12 @@ import (
 
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-// MockTrackVisibilityRepository mocks the repository for track visibility tests.
-type MockTrackVisibilityRepository struct {
-	mock.Mock
-}
-
-func (m *MockTrackVisibilityRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error) {
-	args := m.Called(ctx, userID, filter)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Track]), args.Error(1)
-}
-
-func (m *MockTrackVisibilityRepository) ListPublicTracks(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Track], error) {
-	args := m.Called(ctx, limit, cursor)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*repository.PaginatedResult[models.Track]), args.Error(1)
-}
-
-func (m *MockTrackVisibilityRepository) GetUserDisplayName(ctx context.Context, userID string) (string, error) {
-	args := m.Called(ctx, userID)
-	return args.String(0), args.Error(1)
-}
-
-// MockS3RepoForVisibility mocks S3 repository for visibility tests.
-type MockS3RepoForVisibility struct {
-	mock.Mock
-}
-
-func (m *MockS3RepoForVisibility) GeneratePresignedDownloadURL(ctx context.Context, key string, duration time.Duration) (string, error) {
-	args := m.Called(ctx, key, duration)
-	return args.String(0), args.Error(1)
-}
-
 // MockRoleServiceForVisibility mocks the role service.
 type MockRoleServiceForVisibility struct {
 	mock.Mock
@@ -67,7 +32,7 @@ func (m *MockRoleServiceForVisibility) HasPermission(ctx context.Context, userID
 func TestListTracksWithVisibility_AdminSeesAll(t *testing.T) {
 	t.Run("admin user sees all tracks from all users", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockTrackVisibilityRepository)
+		mockRepo := new(mocks.Repository)
 		mockRole := new(MockRoleServiceForVisibility)
 
 		adminID := "admin-123"
@@ -104,7 +69,7 @@ func TestListTracksWithVisibility_AdminSeesAll(t *testing.T) {
 func TestListTracksWithVisibility_GlobalReaderSeesAll(t *testing.T) {
 	t.Run("global reader sees all tracks from all users", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockTrackVisibilityRepository)
+		mockRepo := new(mocks.Repository)
 		mockRole := new(MockRoleServiceForVisibility)
 
 		globalReaderID := "global-reader-123"
@@ -136,7 +101,7 @@ func TestListTracksWithVisibility_GlobalReaderSeesAll(t *testing.T) {
 func TestListTracksWithVisibility_RegularUserSeesOwnAndPublic(t *testing.T) {
 	t.Run("regular user sees own tracks plus public tracks", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockTrackVisibilityRepository)
+		mockRepo := new(mocks.Repository)
 		mockRole := new(MockRoleServiceForVisibility)
 
 		userID := "user-123"
@@ -176,7 +141,7 @@ func TestListTracksWithVisibility_RegularUserSeesOwnAndPublic(t *testing.T) {
 
 	t.Run("regular user without IncludePublic sees only own tracks", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockTrackVisibilityRepository)
+		mockRepo := new(mocks.Repository)
 		mockRole := new(MockRoleServiceForVisibility)
 
 		userID := "user-123"
@@ -206,7 +171,7 @@ func TestListTracksWithVisibility_RegularUserSeesOwnAndPublic(t *testing.T) {
 func TestListTracksWithVisibility_DeduplicatesPublicTracks(t *testing.T) {
 	t.Run("deduplicates when user's public track appears in both queries", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockTrackVisibilityRepository)
+		mockRepo := new(mocks.Repository)
 		mockRole := new(MockRoleServiceForVisibility)
 
 		userID := "user-123"
@@ -248,7 +213,7 @@ func TestListTracksWithVisibility_DeduplicatesPublicTracks(t *testing.T) {
 func TestListTracksWithVisibility_OwnerDisplayName(t *testing.T) {
 	t.Run("sets OwnerDisplayName to 'You' for own tracks", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockTrackVisibilityRepository)
+		mockRepo := new(mocks.Repository)
 		mockRole := new(MockRoleServiceForVisibility)
 
 		userID := "user-123"
@@ -295,7 +260,7 @@ func TestListTracksWithVisibility_OwnerDisplayName(t *testing.T) {
 func TestListTracksWithVisibility_DefaultsPrivateVisibility(t *testing.T) {
 	t.Run("defaults visibility to private for tracks without visibility set", func(t *testing.T) {
 		ctx := context.Background()
-		mockRepo := new(MockTrackVisibilityRepository)
+		mockRepo := new(mocks.Repository)
 		mockRole := new(MockRoleServiceForVisibility)
 
 		userID := "user-123"
@@ -327,333 +292,10 @@ func TestListTracksWithVisibility_DefaultsPrivateVisibility(t *testing.T) {
 // TrackService.GetTrack Visibility Tests (Access Control Bug Fixes Task 1.4)
 // =============================================================================
 
-// MockTrackServiceRepository provides a mock for testing trackService.GetTrack
-type MockTrackServiceRepository struct {
-	mock.Mock
-}
-
-func (m *MockTrackServiceRepository) GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, userID, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-func (m *MockTrackServiceRepository) GetTrackByID(ctx context.Context, trackID string) (*models.Track, error) {
-	args := m.Called(ctx, trackID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Track), args.Error(1)
-}
-
-// Required Repository interface stubs
-func (m *MockTrackServiceRepository) UpdateTrack(ctx context.Context, track models.Track) error {
-	args := m.Called(ctx, track)
-	return args.Error(0)
-}
-
-func (m *MockTrackServiceRepository) DeleteTrack(ctx context.Context, userID, trackID string) error {
-	return nil
-}
-
-func (m *MockTrackServiceRepository) CreateTrack(ctx context.Context, track models.Track) error {
-	return nil
-}
-
-func (m *MockTrackServiceRepository) ListTracks(ctx context.Context, userID string, filter models.TrackFilter) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-
-func (m *MockTrackServiceRepository) ListTracksByArtist(ctx context.Context, userID, artist string) ([]models.Track, error) {
-	return nil, nil
-}
-
-func (m *MockTrackServiceRepository) ListPublicTracks(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Track], error) {
-	return nil, nil
-}
-
-func (m *MockTrackServiceRepository) UpdateTrackVisibility(ctx context.Context, userID, trackID string, visibility models.TrackVisibility) error {
-	return nil
-}
-
-// Album stubs
-func (m *MockTrackServiceRepository) GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) GetAlbum(ctx context.Context, userID, albumID string) (*models.Album, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) ListAlbums(ctx context.Context, userID string, filter models.AlbumFilter) (*repository.PaginatedResult[models.Album], error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) ListAlbumsByArtist(ctx context.Context, userID, artist string) ([]models.Album, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdateAlbumStats(ctx context.Context, userID, albumID string, trackCount, totalDuration int) error {
-	return nil
-}
-
-// User stubs
-func (m *MockTrackServiceRepository) CreateUser(ctx context.Context, user models.User) error { return nil }
-func (m *MockTrackServiceRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdateUser(ctx context.Context, user models.User) error { return nil }
-func (m *MockTrackServiceRepository) UpdateUserStats(ctx context.Context, userID string, storageUsed int64, trackCount, albumCount, playlistCount int) error {
-	return nil
-}
-
-// Playlist stubs
-func (m *MockTrackServiceRepository) CreatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) GetPlaylist(ctx context.Context, userID, playlistID string) (*models.Playlist, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdatePlaylist(ctx context.Context, playlist models.Playlist) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) DeletePlaylist(ctx context.Context, userID, playlistID string) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) ListPlaylists(ctx context.Context, userID string, filter models.PlaylistFilter) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) AddTracksToPlaylist(ctx context.Context, playlistID string, trackIDs []string, position int) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) RemoveTracksFromPlaylist(ctx context.Context, playlistID string, trackIDs []string) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) GetPlaylistTracks(ctx context.Context, playlistID string) ([]models.PlaylistTrack, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) ReorderPlaylistTracks(ctx context.Context, playlistID string, tracks []models.PlaylistTrack) error {
-	return nil
-}
-
-// Tag stubs
-func (m *MockTrackServiceRepository) CreateTag(ctx context.Context, tag models.Tag) error { return nil }
-func (m *MockTrackServiceRepository) GetTag(ctx context.Context, userID, tagName string) (*models.Tag, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdateTag(ctx context.Context, tag models.Tag) error { return nil }
-func (m *MockTrackServiceRepository) DeleteTag(ctx context.Context, userID, tagName string) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) AddTagsToTrack(ctx context.Context, userID, trackID string, tagNames []string) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) RemoveTagFromTrack(ctx context.Context, userID, trackID, tagName string) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) GetTracksByTag(ctx context.Context, userID, tagName string) ([]models.Track, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) GetTrackTags(ctx context.Context, userID, trackID string) ([]string, error) {
-	return nil, nil
-}
-
-// Artist stubs
-func (m *MockTrackServiceRepository) CreateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) GetArtist(ctx context.Context, userID, artistID string) (*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) GetArtistByName(ctx context.Context, userID, name string) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) ListArtists(ctx context.Context, userID string, filter models.ArtistFilter) (*repository.PaginatedResult[models.Artist], error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdateArtist(ctx context.Context, artist models.Artist) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) DeleteArtist(ctx context.Context, userID, artistID string) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) BatchGetArtists(ctx context.Context, userID string, artistIDs []string) (map[string]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) SearchArtists(ctx context.Context, userID, query string, limit int) ([]*models.Artist, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) GetArtistTrackCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockTrackServiceRepository) GetArtistAlbumCount(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-func (m *MockTrackServiceRepository) GetArtistTotalPlays(ctx context.Context, userID, artistID string) (int, error) {
-	return 0, nil
-}
-
-// User extended stubs
-func (m *MockTrackServiceRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) GetUserByCognitoID(ctx context.Context, cognitoID string) (*models.User, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) SearchUsers(ctx context.Context, query string, limit int) ([]models.User, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) SearchUsersByEmail(ctx context.Context, emailPrefix string, limit int, cursor string) ([]repository.UserSearchResult, string, error) {
-	return nil, "", nil
-}
-func (m *MockTrackServiceRepository) SetUserDisabled(ctx context.Context, userID string, disabled bool) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) GetUserDisplayName(ctx context.Context, userID string) (string, error) {
-	return "", nil
-}
-func (m *MockTrackServiceRepository) GetFollowerCount(ctx context.Context, userID string) (int, error) {
-	return 0, nil
-}
-func (m *MockTrackServiceRepository) GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdateUserSettings(ctx context.Context, userID string, update *repository.UserSettingsUpdate) (*models.UserSettings, error) {
-	return nil, nil
-}
-
-// Playlist extended stubs
-func (m *MockTrackServiceRepository) SearchPlaylists(ctx context.Context, userID, query string, limit int) ([]models.Playlist, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdatePlaylistVisibility(ctx context.Context, userID, playlistID string, visibility models.PlaylistVisibility) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) ListPublicPlaylists(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.Playlist], error) {
-	return nil, nil
-}
-
-// ArtistProfile stubs
-func (m *MockTrackServiceRepository) CreateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) GetArtistProfile(ctx context.Context, userID string) (*models.ArtistProfile, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdateArtistProfile(ctx context.Context, profile models.ArtistProfile) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) DeleteArtistProfile(ctx context.Context, userID string) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) ListArtistProfiles(ctx context.Context, limit int, cursor string) (*repository.PaginatedResult[models.ArtistProfile], error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) IncrementArtistFollowerCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Follow stubs
-func (m *MockTrackServiceRepository) CreateFollow(ctx context.Context, follow models.Follow) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) DeleteFollow(ctx context.Context, followerID, followedID string) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) GetFollow(ctx context.Context, followerID, followedID string) (*models.Follow, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) ListFollowers(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) ListFollowing(ctx context.Context, userID string, limit int, cursor string) (*repository.PaginatedResult[models.Follow], error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) IncrementUserFollowingCount(ctx context.Context, userID string, delta int) error {
-	return nil
-}
-
-// Upload stubs
-func (m *MockTrackServiceRepository) CreateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) UpdateUpload(ctx context.Context, upload models.Upload) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) UpdateUploadStatus(ctx context.Context, userID, uploadID string, status models.UploadStatus, errorMsg string, trackID string) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) UpdateUploadStep(ctx context.Context, userID, uploadID string, step models.ProcessingStep, success bool) error {
-	return nil
-}
-func (m *MockTrackServiceRepository) ListUploads(ctx context.Context, userID string, filter models.UploadFilter) (*repository.PaginatedResult[models.Upload], error) {
-	return nil, nil
-}
-func (m *MockTrackServiceRepository) ListUploadsByStatus(ctx context.Context, status models.UploadStatus) ([]models.Upload, error) {
-	return nil, nil
-}
-
-// MockS3RepoForTrackService mocks S3 repository for track service tests.
-type MockS3RepoForTrackService struct {
-	mock.Mock
-}
-
-func (m *MockS3RepoForTrackService) GeneratePresignedDownloadURL(ctx context.Context, key string, duration time.Duration) (string, error) {
-	args := m.Called(ctx, key, duration)
-	return args.String(0), args.Error(1)
-}
-
-func (m *MockS3RepoForTrackService) GeneratePresignedUploadURL(ctx context.Context, key string, contentType string, duration time.Duration) (string, error) {
-	return "", nil
-}
-
-func (m *MockS3RepoForTrackService) DeleteObject(ctx context.Context, key string) error {
-	return nil
-}
-
-// S3 extended stubs
-func (m *MockS3RepoForTrackService) GeneratePresignedDownloadURLWithFilename(ctx context.Context, key string, expiry time.Duration, filename string) (string, error) {
-	return "", nil
-}
-func (m *MockS3RepoForTrackService) InitiateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
-	return "", nil
-}
-func (m *MockS3RepoForTrackService) GenerateMultipartUploadURLs(ctx context.Context, key, uploadID string, numParts int, expiry time.Duration) ([]models.MultipartUploadPartURL, error) {
-	return nil, nil
-}
-func (m *MockS3RepoForTrackService) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []models.CompletedPartInfo) error {
-	return nil
-}
-func (m *MockS3RepoForTrackService) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
-	return nil
-}
-func (m *MockS3RepoForTrackService) CopyObject(ctx context.Context, sourceKey, destKey string) error {
-	return nil
-}
-func (m *MockS3RepoForTrackService) GetObjectMetadata(ctx context.Context, key string) (map[string]string, error) {
-	return nil, nil
-}
-func (m *MockS3RepoForTrackService) ObjectExists(ctx context.Context, key string) (bool, error) {
-	return false, nil
-}
-func (m *MockS3RepoForTrackService) DeleteByPrefix(ctx context.Context, prefix string) error {
-	return nil
-}
-
 func TestTrackService_GetTrack_OwnerCanAccessPrivate(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTrackServiceRepository)
-	mockS3 := new(MockS3RepoForTrackService)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	ownerID := "user-123"
 	trackID := "track-456"
@@ -681,8 +323,8 @@ func TestTrackService_GetTrack_OwnerCanAccessPrivate(t *testing.T) {
 
 func TestTrackService_GetTrack_AdminCanAccessAnyTrack(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTrackServiceRepository)
-	mockS3 := new(MockS3RepoForTrackService)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	adminID := "admin-123"
 	ownerID := "user-456"
@@ -700,6 +342,7 @@ func TestTrackService_GetTrack_AdminCanAccessAnyTrack(t *testing.T) {
 	// Admin requests someone else's private track
 	mockRepo.On("GetTrack", ctx, adminID, trackID).Return(nil, repository.ErrNotFound)
 	mockRepo.On("GetTrackByID", ctx, trackID).Return(privateTrack, nil)
+	mockRepo.On("GetUserDisplayName", ctx, ownerID).Return("Dana", nil)
 
 	svc := NewTrackService(mockRepo, mockS3)
 	result, err := svc.GetTrack(ctx, adminID, trackID, true) // hasGlobal=true (admin)
@@ -707,13 +350,14 @@ func TestTrackService_GetTrack_AdminCanAccessAnyTrack(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, trackID, result.ID)
 	assert.Equal(t, "Other User Private Track", result.Title)
+	assert.Equal(t, "Dana", result.OwnerDisplayName)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestTrackService_GetTrack_NonOwnerForbiddenForPrivate(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTrackServiceRepository)
-	mockS3 := new(MockS3RepoForTrackService)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	requesterID := "subscriber-123"
 	ownerID := "owner-456"
@@ -747,8 +391,8 @@ func TestTrackService_GetTrack_NonOwnerForbiddenForPrivate(t *testing.T) {
 
 func TestTrackService_GetTrack_NonOwnerCanAccessPublic(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTrackServiceRepository)
-	mockS3 := new(MockS3RepoForTrackService)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	requesterID := "subscriber-123"
 	ownerID := "owner-456"
@@ -778,8 +422,8 @@ func TestTrackService_GetTrack_NonOwnerCanAccessPublic(t *testing.T) {
 
 func TestTrackService_GetTrack_NonOwnerCanAccessUnlisted(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTrackServiceRepository)
-	mockS3 := new(MockS3RepoForTrackService)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	requesterID := "subscriber-123"
 	ownerID := "owner-456"
@@ -809,8 +453,8 @@ func TestTrackService_GetTrack_NonOwnerCanAccessUnlisted(t *testing.T) {
 
 func TestTrackService_GetTrack_NotFoundReturns404(t *testing.T) {
 	ctx := context.Background()
-	mockRepo := new(MockTrackServiceRepository)
-	mockS3 := new(MockS3RepoForTrackService)
+	mockRepo := new(mocks.Repository)
+	mockS3 := new(mocks.MediaStore)
 
 	requesterID := "user-123"
 	trackID := "nonexistent-track"
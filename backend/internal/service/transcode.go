@@ -3,12 +3,14 @@ package service
 import (
 	"context"
 	"fmt"
-	"path"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
 	"github.com/aws/aws-sdk-go-v2/service/mediaconvert/types"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 )
 
 // MediaConvertClient defines the interface for MediaConvert operations.
@@ -17,13 +19,40 @@ type MediaConvertClient interface {
 	GetJob(ctx context.Context, params *mediaconvert.GetJobInput, optFns ...func(*mediaconvert.Options)) (*mediaconvert.GetJobOutput, error)
 }
 
+// TranscodePriority tells interactive transcodes, which a user is waiting
+// on, from bulk re-transcodes.
+type TranscodePriority string
+
+const (
+	// TranscodePriorityInteractive is the priority of uploads; an empty
+	// priority is interactive
+	TranscodePriorityInteractive TranscodePriority = "interactive"
+	// TranscodePriorityBulk is the priority of backfills and re-transcodes
+	TranscodePriorityBulk TranscodePriority = "bulk"
+)
+
+// MediaConvert job priorities (-50 to 50) within a queue, so interactive jobs
+// still go first when both priorities share one queue
+const (
+	interactiveJobPriority int32 = 10
+	bulkJobPriority        int32 = -10
+)
+
+// HLSOutputCleaner removes the objects under a prefix; repository.MediaStore
+// implements it.
+type HLSOutputCleaner interface {
+	DeleteByPrefix(ctx context.Context, prefix string) error
+}
+
 // TranscodeService provides HLS transcoding operations.
 type TranscodeService struct {
 	mcClient     MediaConvertClient
 	bucket       string
 	role         string
 	queue        string
+	bulkQueue    string
 	outputPrefix string
+	cleaner      HLSOutputCleaner
 }
 
 // NewTranscodeService creates a new transcode service.
@@ -37,11 +66,31 @@ func NewTranscodeService(mcClient MediaConvertClient, bucket, role, queue string
 	}
 }
 
+// SetBulkQueue sends bulk transcodes to their own MediaConvert queue, so a
+// backfill cannot hold up uploads. Without one they share the default queue
+// at a lower job priority.
+func (s *TranscodeService) SetBulkQueue(queue string) {
+	s.bulkQueue = queue
+}
+
+// SetOutputCleaner clears a track's earlier HLS output before it is
+// transcoded again, so segments of a previous or failed job are not left
+// behind next to the new ones.
+func (s *TranscodeService) SetOutputCleaner(cleaner HLSOutputCleaner) {
+	s.cleaner = cleaner
+}
+
 // TranscodeRequest represents a request to transcode a track.
 type TranscodeRequest struct {
 	TrackID string
 	UserID  string
 	S3Key   string // Source audio file key
+	// TenantID places input and output under the tenant's object prefix (multi-tenant mode)
+	TenantID string
+	// Priority picks the queue; empty is interactive
+	Priority TranscodePriority
+	// Fallback retries a failed job with settings tolerant of odd inputs
+	Fallback bool
 }
 
 // TranscodeResponse represents the response from starting a transcode job.
@@ -56,18 +105,52 @@ func (s *TranscodeService) StartTranscode(ctx context.Context, req TranscodeRequ
 	if req.TrackID == "" || req.UserID == "" || req.S3Key == "" {
 		return nil, fmt.Errorf("trackID, userID, and s3Key are required")
 	}
+	if req.Priority == "" {
+		req.Priority = TranscodePriorityInteractive
+	}
+
+	// IDs end up in the output path, so reject any that could leave it
+	outputKey, err := sanitize.Key(s.outputPrefix, req.UserID, req.TrackID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transcode request: %w", err)
+	}
+
+	queue, priority, err := s.queueFor(req.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	// MediaConvert overwrites same-named files only, so stale segments are
+	// removed first (best effort; a leftover costs storage, not playback)
+	if s.cleaner != nil {
+		if err := s.cleaner.DeleteByPrefix(ctx, outputKey+"/"); err != nil {
+			fmt.Printf("Warning: failed to clear earlier HLS output of track %s: %v\n", req.TrackID, err)
+		}
+	}
 
 	// Build job settings
 	jobSettings := s.buildJobSettings(req)
 
+	jobMetadata := map[string]string{
+		"trackId":  req.TrackID,
+		"userId":   req.UserID,
+		"priority": string(req.Priority),
+	}
+	if req.TenantID != "" {
+		jobMetadata["tenantId"] = req.TenantID
+	}
+	if req.Fallback {
+		jobMetadata[JobFallbackKey] = JobFallbackValue
+	}
+
+	// UserMetadata is echoed back in the completion event; tags are for billing and lookup
 	input := &mediaconvert.CreateJobInput{
-		Role:     aws.String(s.role),
-		Queue:    aws.String(s.queue),
-		Settings: jobSettings,
-		Tags: map[string]string{
-			"trackId": req.TrackID,
-			"userId":  req.UserID,
-		},
+		Role:         aws.String(s.role),
+		Queue:        aws.String(queue),
+		Priority:     aws.Int32(priority),
+		Settings:     jobSettings,
+		Tags:         jobMetadata,
+		UserMetadata: jobMetadata,
 	}
 
 	output, err := s.mcClient.CreateJob(ctx, input)
@@ -75,7 +158,7 @@ func (s *TranscodeService) StartTranscode(ctx context.Context, req TranscodeRequ
 		return nil, fmt.Errorf("failed to create MediaConvert job: %w", err)
 	}
 
-	playlistKey := fmt.Sprintf("%s/%s/%s/master.m3u8", s.outputPrefix, req.UserID, req.TrackID)
+	playlistKey := outputKey + "/master.m3u8"
 
 	return &TranscodeResponse{
 		JobID:       *output.Job.Id,
@@ -84,6 +167,22 @@ func (s *TranscodeService) StartTranscode(ctx context.Context, req TranscodeRequ
 	}, nil
 }
 
+// queueFor returns the MediaConvert queue and job priority of a transcode
+// priority
+func (s *TranscodeService) queueFor(priority TranscodePriority) (string, int32, error) {
+	switch priority {
+	case TranscodePriorityInteractive:
+		return s.queue, interactiveJobPriority, nil
+	case TranscodePriorityBulk:
+		if s.bulkQueue != "" {
+			return s.bulkQueue, bulkJobPriority, nil
+		}
+		return s.queue, bulkJobPriority, nil
+	default:
+		return "", 0, fmt.Errorf("unknown transcode priority %q", priority)
+	}
+}
+
 // GetTranscodeStatus retrieves the status of a MediaConvert job.
 func (s *TranscodeService) GetTranscodeStatus(ctx context.Context, jobID string) (*TranscodeJobStatus, error) {
 	input := &mediaconvert.GetJobInput{
@@ -119,12 +218,18 @@ type TranscodeJobStatus struct {
 	ErrorMessage string
 }
 
-// buildJobSettings creates MediaConvert job settings for HLS output.
+// buildJobSettings creates MediaConvert job settings for HLS output. Fallback
+// requests get input settings tolerant of odd files.
 func (s *TranscodeService) buildJobSettings(req TranscodeRequest) *types.JobSettings {
-	inputS3URI := fmt.Sprintf("s3://%s/%s", s.bucket, req.S3Key)
-	outputS3Path := fmt.Sprintf("s3://%s/%s/%s/%s/", s.bucket, s.outputPrefix, req.UserID, req.TrackID)
+	// MediaConvert addresses physical object keys, so the tenant prefix is applied here
+	var objectPrefix string
+	if req.TenantID != "" {
+		objectPrefix = tenant.ObjectPrefix(req.TenantID)
+	}
+	inputS3URI := fmt.Sprintf("s3://%s/%s%s", s.bucket, objectPrefix, req.S3Key)
+	outputS3Path := fmt.Sprintf("s3://%s/%s%s/%s/%s/", s.bucket, objectPrefix, s.outputPrefix, req.UserID, req.TrackID)
 
-	return &types.JobSettings{
+	settings := &types.JobSettings{
 		Inputs: []types.Input{
 			{
 				FileInput: aws.String(inputS3URI),
@@ -151,15 +256,19 @@ func (s *TranscodeService) buildJobSettings(req TranscodeRequest) *types.JobSett
 				},
 				Outputs: []types.Output{
 					// 96 kbps AAC (low quality for poor connections)
-					s.buildAACOutput("96k", 96000),
+					s.buildAACOutput(HLSVariantLow, 96000),
 					// 192 kbps AAC (medium quality)
-					s.buildAACOutput("192k", 192000),
+					s.buildAACOutput(HLSVariantMedium, 192000),
 					// 320 kbps AAC (high quality)
-					s.buildAACOutput("320k", 320000),
+					s.buildAACOutput(HLSVariantHigh, 320000),
 				},
 			},
 		},
 	}
+	if req.Fallback {
+		applyFallbackSettings(settings)
+	}
+	return settings
 }
 
 // buildAACOutput creates an HLS output configuration for a specific bitrate.
@@ -190,6 +299,14 @@ func (s *TranscodeService) buildAACOutput(nameModifier string, bitrate int32) ty
 	}
 }
 
+// HLS variant name modifiers. MediaConvert names each variant's playlist
+// after the master playlist with the modifier appended.
+const (
+	HLSVariantLow    = "96k"
+	HLSVariantMedium = "192k"
+	HLSVariantHigh   = "320k"
+)
+
 // HLSQualityLevel represents an HLS quality level.
 type HLSQualityLevel struct {
 	Name    string
@@ -205,9 +322,29 @@ func GetHLSQualities() []HLSQualityLevel {
 	}
 }
 
+// BuildHLSPrefix builds the S3 prefix holding a track's HLS output. It ends
+// in a slash so that deleting it never touches a track whose ID extends this one.
+func BuildHLSPrefix(userID, trackID string) (string, error) {
+	prefix, err := sanitize.Key("hls", userID, trackID)
+	if err != nil {
+		return "", fmt.Errorf("invalid HLS location: %w", err)
+	}
+	return prefix + "/", nil
+}
+
 // BuildHLSPlaylistKey builds the S3 key for the master HLS playlist.
-func BuildHLSPlaylistKey(userID, trackID string) string {
-	return path.Join("hls", userID, trackID, "master.m3u8")
+func BuildHLSPlaylistKey(userID, trackID string) (string, error) {
+	prefix, err := BuildHLSPrefix(userID, trackID)
+	if err != nil {
+		return "", err
+	}
+	return prefix + "master.m3u8", nil
+}
+
+// BuildHLSVariantKey builds the S3 key of one variant's playlist from the
+// key of the master playlist next to it.
+func BuildHLSVariantKey(playlistKey, variant string) string {
+	return strings.TrimSuffix(playlistKey, ".m3u8") + variant + ".m3u8"
 }
 
 // ParseMediaConvertEvent parses a MediaConvert EventBridge event.
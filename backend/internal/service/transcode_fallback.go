@@ -0,0 +1,72 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert/types"
+)
+
+// Fallback jobs carry JobFallbackKey=JobFallbackValue in their user metadata,
+// so a failed fallback is not retried again
+const (
+	JobFallbackKey   = "fallback"
+	JobFallbackValue = "true"
+)
+
+// fallbackErrorHints are phrases of MediaConvert errors about an input it
+// could not demux or decode, which the fallback settings may get past
+var fallbackErrorHints = []string{
+	"codec",
+	"container",
+	"demux",
+	"decod",
+	"unsupported",
+	"not supported",
+	"corrupt",
+	"timecode",
+}
+
+// IsFallbackError reports whether a failed transcode looks like a codec or
+// container problem worth retrying with the fallback settings. S3 access
+// errors (3000s) fail the same way however the job is set up.
+func IsFallbackError(code int, message string) bool {
+	if code >= 3000 && code < 4000 {
+		return false
+	}
+	message = strings.ToLower(message)
+	for _, hint := range fallbackErrorHints {
+		if strings.Contains(message, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldRetryTranscode reports whether the complete handler starts a fallback
+// job for a failed HLS job: the error must be a codec or container problem,
+// the failed job must not already be a fallback, and the track must have
+// retries left.
+func ShouldRetryTranscode(detail MediaConvertEventDetail, retries, maxRetries int) bool {
+	if detail.UserMetadata[JobFallbackKey] == JobFallbackValue || retries >= maxRetries {
+		return false
+	}
+	return IsFallbackError(detail.ErrorCode, detail.ErrorMessage)
+}
+
+// applyFallbackSettings makes a job's input tolerant of files the default
+// settings fail on: timecodes are rebuilt from zero rather than read from the
+// container, and only the first audio track is decoded, so extra streams and
+// odd track layouts are ignored. The outputs are unchanged.
+func applyFallbackSettings(settings *types.JobSettings) {
+	for i := range settings.Inputs {
+		input := &settings.Inputs[i]
+		input.TimecodeSource = types.InputTimecodeSourceZerobased
+		input.AudioSelectors = map[string]types.AudioSelector{
+			"Audio Selector 1": {
+				DefaultSelection: types.AudioDefaultSelectionDefault,
+				SelectorType:     types.AudioSelectorTypeTrack,
+				Tracks:           []int32{1},
+			},
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsFallbackError(t *testing.T) {
+	tests := []struct {
+		code    int
+		message string
+		want    bool
+	}{
+		{1010, "Unsupported codec in input audio track", true},
+		{1030, "Demuxer: Error parsing the container", true},
+		{1040, "Failed to decode audio frame", true},
+		{1999, "Invalid timecode in input", true},
+		{3400, "Unable to open input file: unsupported container", false},
+		{3450, "Access denied", false},
+		{1550, "Job settings are not valid", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsFallbackError(tt.code, tt.message), tt.message)
+	}
+}
+
+func TestShouldRetryTranscode(t *testing.T) {
+	codecError := MediaConvertEventDetail{ErrorCode: 1010, ErrorMessage: "Unsupported codec", UserMetadata: map[string]string{"trackId": "t1"}}
+
+	assert.True(t, ShouldRetryTranscode(codecError, 0, 1))
+	assert.False(t, ShouldRetryTranscode(codecError, 1, 1), "no retries left")
+	assert.False(t, ShouldRetryTranscode(codecError, 0, 0), "fallback disabled")
+
+	fallback := codecError
+	fallback.UserMetadata = map[string]string{"trackId": "t1", JobFallbackKey: JobFallbackValue}
+	assert.False(t, ShouldRetryTranscode(fallback, 0, 2), "a failed fallback is final")
+
+	other := MediaConvertEventDetail{ErrorCode: 3450, ErrorMessage: "Access denied"}
+	assert.False(t, ShouldRetryTranscode(other, 0, 1))
+}
+
+func TestBuildJobSettings_Fallback(t *testing.T) {
+	svc := NewTranscodeService(new(MockMediaConvertClient), "my-bucket", "role-arn", "queue-arn")
+	req := TranscodeRequest{TrackID: "track-123", UserID: "user-456", S3Key: "media/user-456/track-123.m4a"}
+
+	normal := svc.buildJobSettings(req)
+	req.Fallback = true
+	fallback := svc.buildJobSettings(req)
+
+	assert.Empty(t, normal.Inputs[0].TimecodeSource)
+	input := fallback.Inputs[0]
+	assert.Equal(t, types.InputTimecodeSourceZerobased, input.TimecodeSource)
+	selector := input.AudioSelectors["Audio Selector 1"]
+	assert.Equal(t, types.AudioSelectorTypeTrack, selector.SelectorType)
+	assert.Equal(t, []int32{1}, selector.Tracks)
+	assert.Equal(t, normal.OutputGroups, fallback.OutputGroups, "fallback jobs keep the HLS variants")
+}
+
+func TestStartTranscode_FallbackMetadata(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockMediaConvertClient)
+	svc := NewTranscodeService(mockClient, "my-bucket", "role-arn", "queue-arn")
+
+	mockClient.On("CreateJob", ctx, mock.MatchedBy(func(input *mediaconvert.CreateJobInput) bool {
+		return input.UserMetadata[JobFallbackKey] == JobFallbackValue
+	})).Return(&mediaconvert.CreateJobOutput{
+		Job: &types.Job{Id: aws.String("job-2"), Status: types.JobStatusSubmitted},
+	}, nil)
+
+	resp, err := svc.StartTranscode(ctx, TranscodeRequest{
+		TrackID:  "track-123",
+		UserID:   "user-456",
+		S3Key:    "media/user-456/track-123.m4a",
+		Fallback: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "job-2", resp.JobID)
+	mockClient.AssertExpectations(t)
+}
@@ -7,8 +7,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
 	"github.com/aws/aws-sdk-go-v2/service/mediaconvert/types"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockMediaConvertClient mocks MediaConvert operations
@@ -102,6 +105,87 @@ func TestStartTranscode_MissingS3Key(t *testing.T) {
 	assert.Contains(t, err.Error(), "s3Key")
 }
 
+func TestStartTranscode_QueueByPriority(t *testing.T) {
+	tests := []struct {
+		name         string
+		bulkQueue    string
+		priority     TranscodePriority
+		wantQueue    string
+		wantPriority int32
+		wantMetadata string
+	}{
+		{"empty is interactive", "bulk-queue-arn", "", "queue-arn", interactiveJobPriority, "interactive"},
+		{"interactive", "bulk-queue-arn", TranscodePriorityInteractive, "queue-arn", interactiveJobPriority, "interactive"},
+		{"bulk", "bulk-queue-arn", TranscodePriorityBulk, "bulk-queue-arn", bulkJobPriority, "bulk"},
+		{"bulk without its own queue", "", TranscodePriorityBulk, "queue-arn", bulkJobPriority, "bulk"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := new(MockMediaConvertClient)
+			svc := NewTranscodeService(mockClient, "my-bucket", "role-arn", "queue-arn")
+			svc.SetBulkQueue(tt.bulkQueue)
+
+			mockClient.On("CreateJob", ctx, mock.MatchedBy(func(input *mediaconvert.CreateJobInput) bool {
+				return *input.Queue == tt.wantQueue &&
+					*input.Priority == tt.wantPriority &&
+					input.UserMetadata["priority"] == tt.wantMetadata
+			})).Return(&mediaconvert.CreateJobOutput{
+				Job: &types.Job{Id: aws.String("job-1"), Status: types.JobStatusSubmitted},
+			}, nil)
+
+			_, err := svc.StartTranscode(ctx, TranscodeRequest{
+				TrackID:  "track-123",
+				UserID:   "user-456",
+				S3Key:    "media/user-456/track-123.mp3",
+				Priority: tt.priority,
+			})
+
+			require.NoError(t, err)
+			mockClient.AssertExpectations(t)
+		})
+	}
+
+	t.Run("unknown priority", func(t *testing.T) {
+		svc := NewTranscodeService(new(MockMediaConvertClient), "my-bucket", "role-arn", "queue-arn")
+
+		_, err := svc.StartTranscode(context.Background(), TranscodeRequest{
+			TrackID:  "track-123",
+			UserID:   "user-456",
+			S3Key:    "media/user-456/track-123.mp3",
+			Priority: "urgent",
+		})
+
+		assert.ErrorContains(t, err, "unknown transcode priority")
+	})
+}
+
+func TestStartTranscode_ClearsEarlierOutput(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockMediaConvertClient)
+	objects := repository.NewMemoryS3Repository("https://media.example.com")
+	objects.PutObject("hls/user-456/track-123/master.m3u8", nil)
+	objects.PutObject("hls/user-456/track-123/master96k_00001.ts", nil)
+	objects.PutObject("hls/user-456/track-1234/master.m3u8", nil)
+	svc := NewTranscodeService(mockClient, "my-bucket", "role-arn", "queue-arn")
+	svc.SetOutputCleaner(objects)
+
+	mockClient.On("CreateJob", ctx, mock.Anything).Return(&mediaconvert.CreateJobOutput{
+		Job: &types.Job{Id: aws.String("job-1"), Status: types.JobStatusSubmitted},
+	}, nil)
+
+	_, err := svc.StartTranscode(ctx, TranscodeRequest{
+		TrackID: "track-123",
+		UserID:  "user-456",
+		S3Key:   "media/user-456/track-123.mp3",
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, objects.Keys("hls/user-456/track-123/"))
+	assert.Equal(t, []string{"hls/user-456/track-1234/master.m3u8"}, objects.Keys("hls/user-456/"), "a track whose ID extends this one keeps its output")
+}
+
 func TestBuildJobSettings_ThreeQualities(t *testing.T) {
 	mockClient := new(MockMediaConvertClient)
 	svc := NewTranscodeService(mockClient, "my-bucket", "role-arn", "queue-arn")
@@ -162,6 +246,24 @@ func TestBuildJobSettings_CorrectPaths(t *testing.T) {
 	assert.Equal(t, "s3://my-bucket/hls/user-456/track-123/", outputPath)
 }
 
+func TestBuildJobSettings_TenantPaths(t *testing.T) {
+	mockClient := new(MockMediaConvertClient)
+	svc := NewTranscodeService(mockClient, "my-bucket", "role-arn", "queue-arn")
+
+	req := TranscodeRequest{
+		TrackID:  "track-123",
+		UserID:   "user-456",
+		S3Key:    "media/user-456/track-123.mp3",
+		TenantID: "acme",
+	}
+
+	settings := svc.buildJobSettings(req)
+
+	assert.Equal(t, "s3://my-bucket/tenants/acme/media/user-456/track-123.mp3", *settings.Inputs[0].FileInput)
+	outputPath := *settings.OutputGroups[0].OutputGroupSettings.HlsGroupSettings.Destination
+	assert.Equal(t, "s3://my-bucket/tenants/acme/hls/user-456/track-123/", outputPath)
+}
+
 func TestGetTranscodeStatus_Success(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(MockMediaConvertClient)
@@ -227,6 +329,17 @@ func TestGetHLSQualities(t *testing.T) {
 }
 
 func TestBuildHLSPlaylistKey(t *testing.T) {
-	key := BuildHLSPlaylistKey("user-123", "track-456")
+	key, err := BuildHLSPlaylistKey("user-123", "track-456")
+	require.NoError(t, err)
 	assert.Equal(t, "hls/user-123/track-456/master.m3u8", key)
+
+	_, err = BuildHLSPlaylistKey("user-123", "..")
+	assert.ErrorIs(t, err, sanitize.ErrInvalidKey)
+}
+
+func TestBuildHLSVariantKey(t *testing.T) {
+	assert.Equal(t, "hls/user-123/track-456/master96k.m3u8",
+		BuildHLSVariantKey("hls/user-123/track-456/master.m3u8", HLSVariantLow))
+	assert.Equal(t, "hls/user-123/track-456/song320k.m3u8",
+		BuildHLSVariantKey("hls/user-123/track-456/song.m3u8", HLSVariantHigh))
 }
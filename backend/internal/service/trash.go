@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// TrashRepository defines the repository interface for trashing and
+// restoring tracks. Tracks are addressed by their owner, not the acting user.
+type TrashRepository interface {
+	CreateTrack(ctx context.Context, track models.Track) error
+	UpdateTrack(ctx context.Context, track models.Track) error
+	DeleteTrack(ctx context.Context, userID, trackID string) error
+	GetTrashedTrack(ctx context.Context, ownerID, trackID string) (*models.TrashedTrack, error)
+	PutTrashedTrack(ctx context.Context, trashed models.TrashedTrack) error
+	DeleteTrashedTrack(ctx context.Context, ownerID, trackID string) error
+}
+
+// TrashService deletes tracks so they can be restored within
+// models.UndoWindow: the track record is kept in the trash and its files are
+// moved under models.TrashPrefix, where the bucket's lifecycle rule removes
+// them. Undoing the operation that trashed a track restores it.
+type TrashService struct {
+	repo  TrashRepository
+	media repository.MediaStore
+	now   func() time.Time
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService(repo TrashRepository, media repository.MediaStore) *TrashService {
+	return &TrashService{repo: repo, media: media, now: time.Now}
+}
+
+// Trash deletes a track, keeping it and its files for models.UndoWindow.
+// Locked tracks are refused. Files are moved best effort: one that cannot be
+// copied stays where it is, so a restore still finds it.
+func (s *TrashService) Trash(ctx context.Context, track models.Track) error {
+	if err := track.EnsureUnlocked(); err != nil {
+		return err
+	}
+
+	now := s.now()
+	trashed := models.TrashedTrack{Track: track, DeletedAt: now, ExpiresAt: now.Add(models.UndoWindow)}
+	if err := s.repo.PutTrashedTrack(ctx, trashed); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTrack(ctx, track.UserID, track.ID); err != nil {
+		return err
+	}
+
+	for _, key := range track.MediaKeys() {
+		if err := s.media.CopyObject(ctx, key, models.TrashKey(key)); err != nil {
+			fmt.Printf("Warning: failed to move %s to the trash: %v\n", key, err)
+			continue
+		}
+		_ = s.media.DeleteObject(ctx, key)
+	}
+	// HLS renditions are not kept; they are cut again from the original
+	if hlsPrefix, err := BuildHLSPrefix(track.UserID, track.ID); err == nil {
+		_ = s.media.DeleteByPrefix(ctx, hlsPrefix)
+	}
+	return nil
+}
+
+// Restore puts a trashed track back in its owner's library as it was, less
+// its HLS rendition, and moves its files back. It returns
+// repository.ErrNotFound when the track is not in the trash.
+func (s *TrashService) Restore(ctx context.Context, ownerID, trackID string) (*models.Track, error) {
+	trashed, err := s.repo.GetTrashedTrack(ctx, ownerID, trackID)
+	if err != nil {
+		return nil, err
+	}
+
+	track := trashed.Track
+	for _, key := range track.MediaKeys() {
+		if err := s.media.CopyObject(ctx, models.TrashKey(key), key); err != nil {
+			// Left in place when the move to the trash failed
+			continue
+		}
+		_ = s.media.DeleteObject(ctx, models.TrashKey(key))
+	}
+
+	track.HLSStatus = ""
+	track.HLSPlaylistKey = ""
+	track.HLSJobID = ""
+	track.HLSTranscodedAt = nil
+	if err := s.repo.CreateTrack(ctx, track); err != nil {
+		return nil, err
+	}
+	// CreateTrack stamps the track as added now; keep when it was added
+	if err := s.repo.UpdateTrack(ctx, track); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.DeleteTrashedTrack(ctx, ownerID, trackID); err != nil {
+		// The track is back; an expired item is removed by the table TTL
+		fmt.Printf("Warning: failed to delete trashed track %s: %v\n", trackID, err)
+	}
+	return &track, nil
+}
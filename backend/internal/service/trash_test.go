@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trashedTrack is a track with a cover and an HLS rendition, added a year
+// before the test clock
+func trashedTrack(now time.Time) models.Track {
+	track := builders.NewTrackBuilder("u1", "t1").WithCoverArt("covers/u1/t1.jpg").Build()
+	track.CreatedAt = now.AddDate(-1, 0, 0)
+	track.HLSStatus = models.HLSStatusReady
+	track.HLSPlaylistKey = "hls/u1/t1/master.m3u8"
+	return track
+}
+
+// newTrashService wires a trash over a library holding track and its files
+func newTrashService(t *testing.T, now time.Time, track models.Track) (*TrashService, *repository.MemoryRepository, *repository.MemoryS3Repository) {
+	t.Helper()
+	repo := newSeededRepo(t, track)
+	// CreateTrack stamps the track as added now
+	require.NoError(t, repo.UpdateTrack(context.Background(), track))
+	media := repository.NewMemoryS3Repository("http://localhost/media")
+	for _, key := range append(track.MediaKeys(), track.HLSPlaylistKey) {
+		media.PutObject(key, nil)
+	}
+	trash := NewTrashService(repo, media)
+	trash.now = func() time.Time { return now }
+	return trash, repo, media
+}
+
+func TestTrashService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("moves a track and its files to the trash and back", func(t *testing.T) {
+		track := trashedTrack(now)
+		trash, repo, media := newTrashService(t, now, track)
+
+		require.NoError(t, trash.Trash(ctx, track))
+		_, err := repo.GetTrack(ctx, "u1", "t1")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		trashed, err := repo.GetTrashedTrack(ctx, "u1", "t1")
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(models.UndoWindow), trashed.ExpiresAt)
+		assert.ElementsMatch(t, []string{"trash/media/u1/t1.mp3", "trash/covers/u1/t1.jpg"}, media.Keys(""))
+
+		restored, err := trash.Restore(ctx, "u1", "t1")
+		require.NoError(t, err)
+		assert.Empty(t, restored.HLSStatus, "the rendition was not kept")
+
+		got, err := repo.GetTrack(ctx, "u1", "t1")
+		require.NoError(t, err)
+		assert.Equal(t, track.Title, got.Title)
+		assert.True(t, track.CreatedAt.Equal(got.CreatedAt), "keeps when it was added")
+		assert.Empty(t, got.HLSPlaylistKey)
+		assert.ElementsMatch(t, []string{"media/u1/t1.mp3", "covers/u1/t1.jpg"}, media.Keys(""))
+
+		_, err = repo.GetTrashedTrack(ctx, "u1", "t1")
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		_, err = trash.Restore(ctx, "u1", "t1")
+		assert.ErrorIs(t, err, repository.ErrNotFound, "restored once")
+	})
+
+	t.Run("refuses locked tracks", func(t *testing.T) {
+		track := trashedTrack(now)
+		track.Locked = true
+		trash, repo, _ := newTrashService(t, now, track)
+
+		assert.ErrorIs(t, trash.Trash(ctx, track), models.ErrTrackLocked)
+		_, err := repo.GetTrack(ctx, "u1", "t1")
+		assert.NoError(t, err)
+	})
+}
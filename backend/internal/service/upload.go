@@ -4,11 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/gvasels/personal-music-searchengine/internal/alerting"
+	"github.com/gvasels/personal-music-searchengine/internal/i18n"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
 )
 
 const (
@@ -45,14 +51,15 @@ type StepFunctionsStartOutput struct {
 // UploadServiceImpl implements UploadService (exported for type assertion)
 type UploadServiceImpl struct {
 	repo             repository.Repository
-	s3Repo           repository.S3Repository
+	s3Repo           repository.MediaStore
 	mediaBucket      string
 	stepFunctionsARN string
 	sfnClient        StepFunctionsClient
+	alerter          *alerting.Alerter
 }
 
 // NewUploadService creates a new upload service
-func NewUploadService(repo repository.Repository, s3Repo repository.S3Repository, mediaBucket string, stepFunctionsARN string) UploadService {
+func NewUploadService(repo repository.Repository, s3Repo repository.MediaStore, mediaBucket string, stepFunctionsARN string) UploadService {
 	return &UploadServiceImpl{
 		repo:             repo,
 		s3Repo:           s3Repo,
@@ -61,44 +68,184 @@ func NewUploadService(repo repository.Repository, s3Repo repository.S3Repository
 	}
 }
 
-// SetStepFunctionsClient sets the Step Functions client for triggering workflows
+// SetStepFunctionsClient sets the client that starts the processing pipeline
+// of confirmed uploads; without one they stay processing
 func (s *UploadServiceImpl) SetStepFunctionsClient(client StepFunctionsClient) {
 	s.sfnClient = client
 }
 
+// SetAlerter reports uploads refused for going over a storage limit
+func (s *UploadServiceImpl) SetAlerter(alerter *alerting.Alerter) {
+	s.alerter = alerter
+}
+
 func (s *UploadServiceImpl) CreatePresignedUpload(ctx context.Context, userID string, req models.PresignedUploadRequest) (*models.PresignedUploadResponse, error) {
-	// Check user storage limit
-	user, err := s.repo.GetUser(ctx, userID)
-	if err != nil && err != repository.ErrNotFound {
+	if err := s.checkStorageLimit(ctx, userID, req.FileSize); err != nil {
+		s.alertOverQuota(ctx, userID, req, err)
+		return nil, err
+	}
+
+	return s.createPresignedUpload(ctx, userID, req, "")
+}
+
+// CreateReplaceFileUpload issues a presigned upload for a new audio file that will
+// replace the file of an existing track. Once processed, the track keeps its ID,
+// play counts, tags and playlist membership; the previous file is archived.
+func (s *UploadServiceImpl) CreateReplaceFileUpload(ctx context.Context, userID, trackID string, req models.PresignedUploadRequest) (*models.PresignedUploadResponse, error) {
+	track, err := s.repo.GetTrack(ctx, userID, trackID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+
+	if err := track.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+
+	// Only the size difference counts against the quota, since the old file is released
+	if err := s.checkStorageLimit(ctx, userID, req.FileSize-track.FileSize); err != nil {
+		s.alertOverQuota(ctx, userID, req, err)
+		return nil, err
+	}
+
+	return s.createPresignedUpload(ctx, userID, req, track.ID)
+}
+
+// ContentHashRepository is implemented by repositories that can look tracks
+// up by the SHA-256 of their audio file
+type ContentHashRepository interface {
+	FindTracksByContentHash(ctx context.Context, userID, contentHash string) ([]models.Track, error)
+}
+
+// PrecheckUpload reports every reason the described upload would be rejected,
+// without creating an upload. Duplicates are only found among tracks whose
+// hash was recorded when they were processed.
+func (s *UploadServiceImpl) PrecheckUpload(ctx context.Context, userID string, req models.UploadPrecheckRequest) (*models.UploadPrecheckResponse, error) {
+	resp := &models.UploadPrecheckResponse{Rejections: []models.UploadRejection{}}
+	reject := func(rejection models.UploadRejection) {
+		resp.Rejections = append(resp.Rejections, rejection)
+	}
+
+	ext := sanitize.Extension(req.FileName)
+	if _, ok := models.AudioFormatForExtension(ext); !ok {
+		reject(models.UploadRejection{
+			Reason:  models.UploadRejectedFormat,
+			Message: fmt.Sprintf("unsupported file format %q", ext),
+		})
+	}
+
+	if req.FileSize > models.MaxUploadFileSize {
+		reject(models.UploadRejection{
+			Reason:  models.UploadRejectedSize,
+			Message: fmt.Sprintf("file is larger than the %d byte limit", models.MaxUploadFileSize),
+		})
+	}
+
+	if err := s.checkStorageLimit(ctx, userID, req.FileSize); err == models.ErrStorageLimitExceeded {
+		reject(models.UploadRejection{
+			Reason:  models.UploadRejectedQuota,
+			Message: "the file would take you over your storage limit",
+		})
+	} else if err != nil {
 		return nil, err
 	}
-	if user != nil {
-		limit := user.StorageLimit
-		// StorageLimit of 0 means field was never set - use default
-		if limit == 0 {
-			limit = defaultStorageLimit
+
+	if index, ok := s.repo.(ContentHashRepository); ok && req.ContentHash != "" {
+		tracks, err := index.FindTracksByContentHash(ctx, userID, strings.ToLower(req.ContentHash))
+		if err != nil && err != repository.ErrNotFound {
+			return nil, err
 		}
-		// StorageLimit of -1 means unlimited storage
-		if limit > 0 && user.StorageUsed+req.FileSize > limit {
-			return nil, models.ErrStorageLimitExceeded
+		if len(tracks) > 0 {
+			reject(models.UploadRejection{
+				Reason:  models.UploadRejectedDuplicate,
+				Message: fmt.Sprintf("identical to %q by %s", tracks[0].Title, tracks[0].Artist),
+				TrackID: tracks[0].ID,
+			})
 		}
 	}
 
-	// Generate upload ID and S3 key
+	resp.Allowed = len(resp.Rejections) == 0
+	return resp, nil
+}
+
+// checkStorageLimit returns ErrStorageLimitExceeded if adding additionalBytes
+// would take the user over their storage limit.
+func (s *UploadServiceImpl) checkStorageLimit(ctx context.Context, userID string, additionalBytes int64) error {
+	// Household members draw on the shared library owner's quota
+	if scoped, ok := s.repo.(LibraryIDResolver); ok {
+		libraryID, err := scoped.ResolveLibraryID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		userID = libraryID
+	}
+
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil && err != repository.ErrNotFound {
+		return err
+	}
+	if user != nil && exceedsStorageLimit(user, additionalBytes) {
+		return models.ErrStorageLimitExceeded
+	}
+	return nil
+}
+
+// alertOverQuota reports an upload refused by checkStorageLimit; other
+// errors are not alerts
+func (s *UploadServiceImpl) alertOverQuota(ctx context.Context, userID string, req models.PresignedUploadRequest, err error) {
+	if err != models.ErrStorageLimitExceeded {
+		return
+	}
+	s.alerter.Send(ctx, alerting.Alert{
+		Kind:    alerting.KindQuotaExceeded,
+		Key:     userID,
+		Subject: "Upload refused over storage limit",
+		Message: fmt.Sprintf("User %s tried to upload past their storage limit.", userID),
+		Details: map[string]string{
+			"userId":   userID,
+			"fileName": req.FileName,
+			"fileSize": strconv.FormatInt(req.FileSize, 10),
+		},
+	})
+}
+
+// exceedsStorageLimit reports whether adding additionalBytes would take the user
+// over their storage limit.
+func exceedsStorageLimit(user *models.User, additionalBytes int64) bool {
+	limit := user.StorageLimit
+	// StorageLimit of 0 means field was never set - use default
+	if limit == 0 {
+		limit = defaultStorageLimit
+	}
+	// StorageLimit of -1 means unlimited storage
+	return limit > 0 && user.StorageUsed+additionalBytes > limit
+}
+
+// createPresignedUpload creates the upload record and presigned URL(s).
+// replaceTrackID is empty for regular uploads.
+func (s *UploadServiceImpl) createPresignedUpload(ctx context.Context, userID string, req models.PresignedUploadRequest, replaceTrackID string) (*models.PresignedUploadResponse, error) {
+	// Generate upload ID and S3 key from the sanitized file name
 	uploadID := uuid.New().String()
-	s3Key := fmt.Sprintf("uploads/%s/%s/%s", userID, uploadID, req.FileName)
+	fileName := sanitize.FileName(req.FileName)
+	s3Key, err := sanitize.Key("uploads", userID, uploadID, fileName)
+	if err != nil {
+		return nil, models.NewValidationError(err.Error())
+	}
 
 	// Create upload record
 	now := time.Now()
 	upload := models.Upload{
-		ID:          uploadID,
-		UserID:      userID,
-		FileName:    req.FileName,
-		FileSize:    req.FileSize,
-		ContentType: req.ContentType,
-		S3Key:       s3Key,
-		Status:      models.UploadStatusPending,
-		IsMultipart: req.IsMultipart || req.FileSize > multipartThreshold,
+		ID:             uploadID,
+		UserID:         userID,
+		FileName:       fileName,
+		FileSize:       req.FileSize,
+		ContentType:    req.ContentType,
+		S3Key:          s3Key,
+		Status:         models.UploadStatusPending,
+		IsMultipart:    req.IsMultipart || req.FileSize > multipartThreshold,
+		ReplaceTrackID: replaceTrackID,
 	}
 	upload.CreatedAt = now
 	upload.UpdatedAt = now
@@ -108,10 +255,11 @@ func (s *UploadServiceImpl) CreatePresignedUpload(ctx context.Context, userID st
 	}
 
 	response := &models.PresignedUploadResponse{
-		UploadID:    uploadID,
-		ExpiresAt:   now.Add(uploadURLExpiry),
-		MaxFileSize: req.FileSize,
-		IsMultipart: upload.IsMultipart,
+		UploadID:       uploadID,
+		ExpiresAt:      now.Add(uploadURLExpiry),
+		MaxFileSize:    req.FileSize,
+		IsMultipart:    upload.IsMultipart,
+		ReplaceTrackID: replaceTrackID,
 	}
 
 	// Generate presigned URL(s)
@@ -181,15 +329,23 @@ func (s *UploadServiceImpl) ConfirmUpload(ctx context.Context, userID string, re
 		return nil, err
 	}
 
-	// Trigger Step Functions workflow for processing
-	if s.sfnClient != nil && s.stepFunctionsARN != "" {
+	// Trigger the processing pipeline: the Step Functions workflow, or the
+	// in-process pipeline of a self-hosted server
+	if s.sfnClient != nil {
 		input := map[string]interface{}{
 			"uploadId":   upload.ID,
 			"userId":     upload.UserID,
 			"s3Key":      upload.S3Key,
 			"fileName":   upload.FileName,
 			"bucketName": s.mediaBucket,
+			// Empty for regular uploads; the pipeline swaps the file of this track otherwise
+			"replaceTrackId": upload.ReplaceTrackID,
+			// The uploader is waiting on the result, so the transcode goes to the fast queue
+			"priority": string(TranscodePriorityInteractive),
 		}
+		// Empty in single-tenant mode; processors re-establish the tenant from it otherwise
+		tenantID, _ := tenant.FromContext(ctx)
+		input["tenantId"] = tenantID
 		inputJSON, err := json.Marshal(input)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal Step Functions input: %w", err)
@@ -254,6 +410,7 @@ func (s *UploadServiceImpl) GetUploadStatus(ctx context.Context, userID, uploadI
 	}
 
 	response := upload.ToResponse()
+	response.Localize(i18n.FromContext(ctx))
 	return &response, nil
 }
 
@@ -263,9 +420,12 @@ func (s *UploadServiceImpl) ListUploads(ctx context.Context, userID string, filt
 		return nil, err
 	}
 
+	lang := i18n.FromContext(ctx)
 	responses := make([]models.UploadResponse, 0, len(result.Items))
 	for _, upload := range result.Items {
-		responses = append(responses, upload.ToResponse())
+		response := upload.ToResponse()
+		response.Localize(lang)
+		responses = append(responses, response)
 	}
 
 	return &repository.PaginatedResult[models.UploadResponse]{
@@ -313,8 +473,15 @@ func (s *UploadServiceImpl) UploadCoverArt(ctx context.Context, userID, trackID
 		return nil, err
 	}
 
+	if err := track.EnsureUnlocked(); err != nil {
+		return nil, err
+	}
+
 	// Generate S3 key for cover art
-	s3Key := fmt.Sprintf("media/%s/%s/cover%s", userID, trackID, getFileExtension(req.FileName))
+	s3Key, err := sanitize.Key("media", userID, trackID, "cover"+sanitize.Extension(req.FileName))
+	if err != nil {
+		return nil, models.NewValidationError(err.Error())
+	}
 
 	// Generate presigned URL
 	uploadURL, err := s.s3Repo.GeneratePresignedUploadURL(ctx, s3Key, req.ContentType, uploadURLExpiry)
@@ -322,8 +489,10 @@ func (s *UploadServiceImpl) UploadCoverArt(ctx context.Context, userID, trackID
 		return nil, fmt.Errorf("failed to generate presigned URL: %w", err)
 	}
 
-	// Update track with cover art key (will be applied after upload)
+	// Update track with cover art key (will be applied after upload). The
+	// style and thumbnail belonged to the old image; the new one is not analyzed.
 	track.CoverArtKey = s3Key
+	track.CoverStyle = nil
 	if err := s.repo.UpdateTrack(ctx, *track); err != nil {
 		return nil, err
 	}
@@ -335,13 +504,3 @@ func (s *UploadServiceImpl) UploadCoverArt(ctx context.Context, userID, trackID
 		MaxFileSize: req.FileSize,
 	}, nil
 }
-
-// getFileExtension extracts the file extension from a filename
-func getFileExtension(filename string) string {
-	for i := len(filename) - 1; i >= 0; i-- {
-		if filename[i] == '.' {
-			return filename[i:]
-		}
-	}
-	return ""
-}
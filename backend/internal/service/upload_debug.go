@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/logs"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+)
+
+// maxInvocationTime is the longest a Lambda invocation can run; a stream is
+// read this far around the lines that matched to reach the invocation's
+// START and REPORT lines
+const maxInvocationTime = 15 * time.Minute
+
+// maxStreamEvents bounds how much of one log stream is read around matches
+const maxStreamEvents = 5000
+
+// ExecutionHistoryReader lists the upload state machine's executions and
+// reads their history; SFNClientAdapter implements it
+type ExecutionHistoryReader interface {
+	ListExecutions(ctx context.Context, stateMachineARN, namePrefix string, since time.Time, limit int) ([]models.UploadExecution, error)
+	GetExecutionHistory(ctx context.Context, executionARN string, limit int) ([]models.UploadExecutionEvent, bool, error)
+}
+
+// LogEventReader filters Lambda log events; logs.Reader implements it
+type LogEventReader interface {
+	FilterEvents(ctx context.Context, filter logs.Filter) ([]logs.Event, error)
+}
+
+// ObjectVersionLister lists the versions of one object key
+type ObjectVersionLister interface {
+	ListObjectVersions(ctx context.Context, key string) ([]models.S3ObjectVersion, error)
+}
+
+// SearchDocumentReader reads one document of the search index
+type SearchDocumentReader interface {
+	Get(ctx context.Context, docID string) (*searchproto.GetResponse, error)
+}
+
+// UploadDebugService assembles the debug bundle support reads to follow one
+// upload through the system: the upload record and its track, the state
+// machine executions started for it, the log output of every pipeline
+// Lambda invocation that mentioned the upload or its track, the versions of
+// its objects and its search index entry. Sources that are not configured
+// or fail are reported in the bundle rather than failing it.
+type UploadDebugService struct {
+	repo    repository.Repository
+	objects ObjectVersionLister
+	now     func() time.Time
+
+	executions      ExecutionHistoryReader
+	stateMachineARN string
+	logs            LogEventReader
+	logGroups       []string
+	index           SearchDocumentReader
+}
+
+// NewUploadDebugService creates a new upload debug service. repo resolves
+// the uploader's library; objects may be nil.
+func NewUploadDebugService(repo repository.Repository, objects ObjectVersionLister) *UploadDebugService {
+	return &UploadDebugService{repo: repo, objects: objects, now: time.Now}
+}
+
+// SetExecutions adds the executions of the upload state machine to bundles
+func (s *UploadDebugService) SetExecutions(executions ExecutionHistoryReader, stateMachineARN string) {
+	s.executions = executions
+	s.stateMachineARN = stateMachineARN
+}
+
+// SetLogs adds the log output of the pipeline Lambdas logging to logGroups
+func (s *UploadDebugService) SetLogs(reader LogEventReader, logGroups []string) {
+	s.logs = reader
+	s.logGroups = nil
+	for _, group := range logGroups {
+		if group = strings.TrimSpace(group); group != "" {
+			s.logGroups = append(s.logGroups, group)
+		}
+	}
+}
+
+// SetSearchIndex adds the track's search index entry to bundles
+func (s *UploadDebugService) SetSearchIndex(index SearchDocumentReader) {
+	s.index = index
+}
+
+// Bundle assembles the debug bundle of an upload of userID's library
+func (s *UploadDebugService) Bundle(ctx context.Context, userID, uploadID string) (*models.UploadDebugBundle, error) {
+	upload, err := s.repo.GetUpload(ctx, userID, uploadID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	bundle := &models.UploadDebugBundle{
+		GeneratedAt:    s.now(),
+		Upload:         *upload,
+		Executions:     []models.UploadExecution{},
+		Logs:           []models.UploadLogExcerpt{},
+		ObjectVersions: []models.S3ObjectVersion{},
+	}
+	report := func(source models.UploadDebugSource, format string, args ...interface{}) {
+		bundle.Errors = append(bundle.Errors, models.UploadDebugError{Source: source, Message: fmt.Sprintf(format, args...)})
+	}
+
+	trackID := upload.TrackID
+	if trackID == "" {
+		trackID = upload.ReplaceTrackID
+	}
+	if trackID != "" {
+		track, err := s.repo.GetTrack(ctx, userID, trackID)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			report(models.DebugSourceTrack, "track %s no longer exists", trackID)
+		case err != nil:
+			report(models.DebugSourceTrack, "%v", err)
+		default:
+			bundle.Track = track
+		}
+	}
+
+	// Executions
+	if s.executions == nil || s.stateMachineARN == "" {
+		report(models.DebugSourceExecutions, "Step Functions is not configured")
+	} else {
+		executions, err := s.executions.ListExecutions(ctx, s.stateMachineARN, fmt.Sprintf("upload-%s-", upload.ID), upload.CreatedAt.Add(-time.Minute), models.MaxDebugExecutions)
+		if err != nil {
+			report(models.DebugSourceExecutions, "%v", err)
+		}
+		for i := range executions {
+			events, truncated, err := s.executions.GetExecutionHistory(ctx, executions[i].ExecutionARN, models.MaxDebugExecutionEvents)
+			if err != nil {
+				report(models.DebugSourceExecutions, "history of %s: %v", executions[i].Name, err)
+				events = []models.UploadExecutionEvent{}
+			}
+			executions[i].Events = events
+			executions[i].EventsTruncated = truncated
+			bundle.Executions = append(bundle.Executions, executions[i])
+		}
+	}
+
+	// Logs
+	if s.logs == nil || len(s.logGroups) == 0 {
+		report(models.DebugSourceLogs, "pipeline log groups are not configured")
+	} else {
+		ids := []string{upload.ID}
+		if trackID != "" {
+			ids = append(ids, trackID)
+		}
+		for _, group := range s.logGroups {
+			if len(bundle.Logs) >= models.MaxDebugLogExcerpts {
+				break
+			}
+			excerpts, err := s.logExcerpts(ctx, group, ids, upload.CreatedAt, models.MaxDebugLogExcerpts-len(bundle.Logs))
+			if err != nil {
+				report(models.DebugSourceLogs, "%s: %v", group, err)
+			}
+			bundle.Logs = append(bundle.Logs, excerpts...)
+		}
+		sort.SliceStable(bundle.Logs, func(i, j int) bool {
+			return bundle.Logs[i].Lines[0].Timestamp.Before(bundle.Logs[j].Lines[0].Timestamp)
+		})
+	}
+
+	// Object versions
+	if s.objects == nil {
+		report(models.DebugSourceObjects, "object storage is not configured")
+	} else {
+		for _, key := range debugObjectKeys(upload, bundle.Track) {
+			versions, err := s.objects.ListObjectVersions(ctx, key)
+			if err != nil {
+				report(models.DebugSourceObjects, "%v", err)
+				continue
+			}
+			bundle.ObjectVersions = append(bundle.ObjectVersions, versions...)
+		}
+	}
+
+	// Search index entry; an upload that made no track has none
+	if trackID != "" {
+		if s.index == nil {
+			report(models.DebugSourceSearch, "search is not configured")
+		} else if entry, err := s.index.Get(ctx, trackID); err != nil {
+			report(models.DebugSourceSearch, "%v", err)
+		} else {
+			bundle.SearchEntry = &models.UploadSearchEntry{TrackID: trackID, Indexed: entry.Found, Document: entry.Document}
+		}
+	}
+
+	return bundle, nil
+}
+
+// logExcerpts returns up to limit invocations logged to group that mention
+// any of ids. The pipeline Lambdas log the upload ID when they start, and
+// the track ID where they refer to the track. Matching lines are found
+// first; their streams are then read around them, since the START and
+// REPORT lines that bound an invocation do not mention the upload.
+func (s *UploadDebugService) logExcerpts(ctx context.Context, group string, ids []string, createdAt time.Time, limit int) ([]models.UploadLogExcerpt, error) {
+	terms := make([]string, len(ids))
+	for i, id := range ids {
+		terms[i] = fmt.Sprintf("?%q", id)
+	}
+	start := createdAt.Add(-time.Minute)
+	end := s.now()
+	if end.Sub(start) > models.DebugLogWindow {
+		end = start.Add(models.DebugLogWindow)
+	}
+
+	matches, err := s.logs.FilterEvents(ctx, logs.Filter{
+		LogGroup: group,
+		Pattern:  strings.Join(terms, " "),
+		Start:    start,
+		End:      end,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type window struct{ first, last time.Time }
+	var streams []string
+	windows := make(map[string]*window)
+	for _, match := range matches {
+		w, ok := windows[match.LogStream]
+		if !ok {
+			streams = append(streams, match.LogStream)
+			windows[match.LogStream] = &window{first: match.Timestamp, last: match.Timestamp}
+			continue
+		}
+		if match.Timestamp.Before(w.first) {
+			w.first = match.Timestamp
+		}
+		if match.Timestamp.After(w.last) {
+			w.last = match.Timestamp
+		}
+	}
+
+	excerpts := []models.UploadLogExcerpt{}
+	for _, stream := range streams {
+		events, err := s.logs.FilterEvents(ctx, logs.Filter{
+			LogGroup:   group,
+			LogStreams: []string{stream},
+			Start:      windows[stream].first.Add(-maxInvocationTime),
+			End:        windows[stream].last.Add(maxInvocationTime),
+			Limit:      maxStreamEvents,
+		})
+		if err != nil {
+			return excerpts, err
+		}
+		for _, invocation := range logs.Invocations(events) {
+			if !mentionsAny(invocation.Events, ids) {
+				continue
+			}
+			excerpts = append(excerpts, logExcerpt(group, stream, invocation))
+			if len(excerpts) >= limit {
+				return excerpts, nil
+			}
+		}
+	}
+	return excerpts, nil
+}
+
+func mentionsAny(events []logs.Event, ids []string) bool {
+	for _, event := range events {
+		for _, id := range ids {
+			if strings.Contains(event.Message, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func logExcerpt(group, stream string, invocation logs.Invocation) models.UploadLogExcerpt {
+	events := invocation.Events
+	truncated := !invocation.Complete
+	if len(events) > models.MaxDebugLogLines {
+		events = events[:models.MaxDebugLogLines]
+		truncated = true
+	}
+
+	lines := make([]models.UploadLogLine, len(events))
+	for i, event := range events {
+		lines[i] = models.UploadLogLine{Timestamp: event.Timestamp, Message: event.Message}
+	}
+	return models.UploadLogExcerpt{
+		LogGroup:  group,
+		LogStream: stream,
+		RequestID: invocation.RequestID,
+		Lines:     lines,
+		Truncated: truncated,
+	}
+}
+
+// debugObjectKeys lists the object keys an upload may have written: the
+// uploaded file, its quarantined copy, and the track's audio file and cover
+func debugObjectKeys(upload *models.Upload, track *models.Track) []string {
+	keys := []string{upload.S3Key}
+	if upload.S3Key != "" {
+		keys = append(keys, models.QuarantineKey(upload.UserID, upload.ID, path.Base(upload.S3Key)))
+	}
+	if track != nil {
+		keys = append(keys, track.S3Key, track.CoverArtKey)
+	}
+
+	seen := make(map[string]bool)
+	unique := keys[:0]
+	for _, key := range keys {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/logs"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/searchproto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExecutionHistory struct {
+	namePrefix string
+	executions []models.UploadExecution
+	events     map[string][]models.UploadExecutionEvent
+}
+
+func (f *fakeExecutionHistory) ListExecutions(ctx context.Context, stateMachineARN, namePrefix string, since time.Time, limit int) ([]models.UploadExecution, error) {
+	f.namePrefix = namePrefix
+	return f.executions, nil
+}
+
+func (f *fakeExecutionHistory) GetExecutionHistory(ctx context.Context, executionARN string, limit int) ([]models.UploadExecutionEvent, bool, error) {
+	events, ok := f.events[executionARN]
+	if !ok {
+		return nil, false, errors.New("ExecutionDoesNotExist")
+	}
+	return events, false, nil
+}
+
+// fakeLogGroup answers pattern searches with the lines mentioning a quoted
+// term and stream reads with the whole stream
+type fakeLogGroup map[string][]logs.Event
+
+func (f fakeLogGroup) FilterEvents(ctx context.Context, filter logs.Filter) ([]logs.Event, error) {
+	events := f[filter.LogGroup]
+	if len(filter.LogStreams) > 0 {
+		var stream []logs.Event
+		for _, event := range events {
+			if event.LogStream == filter.LogStreams[0] {
+				stream = append(stream, event)
+			}
+		}
+		return stream, nil
+	}
+
+	var matches []logs.Event
+	for _, event := range events {
+		for _, term := range strings.Fields(filter.Pattern) {
+			if strings.Contains(event.Message, strings.Trim(term, `?"`)) {
+				matches = append(matches, event)
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+type fakeSearchIndex map[string]searchproto.Document
+
+func (f fakeSearchIndex) Get(ctx context.Context, docID string) (*searchproto.GetResponse, error) {
+	doc, ok := f[docID]
+	if !ok {
+		return &searchproto.GetResponse{ID: docID}, nil
+	}
+	return &searchproto.GetResponse{ID: docID, Found: true, Document: &doc}, nil
+}
+
+func TestUploadDebugService_Bundle(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	objects := repository.NewMemoryS3Repository("")
+	svc := NewUploadDebugService(repo, objects)
+
+	require.NoError(t, repo.CreateUpload(ctx, models.Upload{ID: "up-1", UserID: "u1", FileName: "song.mp3", S3Key: "uploads/u1/up-1/song.mp3", Status: models.UploadStatusCompleted, TrackID: "tr-1"}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "tr-1", UserID: "u1", Title: "Song", S3Key: "media/u1/tr-1.mp3"}))
+	objects.PutObject("media/u1/tr-1.mp3", nil)
+
+	t.Run("reports unconfigured sources", func(t *testing.T) {
+		bundle, err := svc.Bundle(ctx, "u1", "up-1")
+		require.NoError(t, err)
+
+		assert.Equal(t, "up-1", bundle.Upload.ID)
+		require.NotNil(t, bundle.Track)
+		assert.Equal(t, "Song", bundle.Track.Title)
+		assert.Empty(t, bundle.Executions)
+		assert.Empty(t, bundle.Logs)
+		assert.Nil(t, bundle.SearchEntry)
+
+		sources := make([]models.UploadDebugSource, 0, len(bundle.Errors))
+		for _, e := range bundle.Errors {
+			sources = append(sources, e.Source)
+		}
+		assert.ElementsMatch(t, []models.UploadDebugSource{models.DebugSourceExecutions, models.DebugSourceLogs, models.DebugSourceSearch}, sources)
+
+		require.Len(t, bundle.ObjectVersions, 1, "only the track's audio file exists")
+		assert.Equal(t, "media/u1/tr-1.mp3", bundle.ObjectVersions[0].Key)
+	})
+
+	t.Run("assembles every source", func(t *testing.T) {
+		started := time.Now()
+		executions := &fakeExecutionHistory{
+			executions: []models.UploadExecution{
+				{ExecutionARN: "arn:exec:2", Name: "upload-up-1-2", Status: "SUCCEEDED", StartedAt: started},
+				{ExecutionARN: "arn:exec:1", Name: "upload-up-1-1", Status: "FAILED", StartedAt: started.Add(-time.Hour)},
+			},
+			events: map[string][]models.UploadExecutionEvent{
+				"arn:exec:2": {{ID: 1, Type: "ExecutionStarted"}, {ID: 2, Type: "TaskStateEntered", State: "ExtractMetadata"}},
+			},
+		}
+		svc.SetExecutions(executions, "arn:states:upload")
+
+		at := func(seconds int) time.Time { return started.Add(time.Duration(seconds) * time.Second) }
+		svc.SetLogs(fakeLogGroup{
+			"/aws/lambda/metadata": {
+				{LogStream: "s1", Timestamp: at(1), Message: "START RequestId: req-1 Version: $LATEST"},
+				{LogStream: "s1", Timestamp: at(2), Message: "Processing upload up-1"},
+				{LogStream: "s1", Timestamp: at(3), Message: "REPORT RequestId: req-1\tDuration: 5 ms"},
+				{LogStream: "s1", Timestamp: at(4), Message: "START RequestId: req-2 Version: $LATEST"},
+				{LogStream: "s1", Timestamp: at(5), Message: "Processing upload up-other"},
+				{LogStream: "s1", Timestamp: at(6), Message: "REPORT RequestId: req-2\tDuration: 5 ms"},
+			},
+			"/aws/lambda/transcode-start": {
+				{LogStream: "s9", Timestamp: at(0), Message: "START RequestId: req-3 Version: $LATEST"},
+				{LogStream: "s9", Timestamp: at(0), Message: "Starting transcode of track tr-1"},
+			},
+		}, []string{" /aws/lambda/metadata", "/aws/lambda/transcode-start", ""})
+		svc.SetSearchIndex(fakeSearchIndex{"tr-1": {ID: "tr-1", UserID: "u1", Title: "Song", HLSStatus: "READY"}})
+
+		bundle, err := svc.Bundle(ctx, "u1", "up-1")
+		require.NoError(t, err)
+
+		assert.Equal(t, "upload-up-1-", executions.namePrefix)
+		require.Len(t, bundle.Executions, 2)
+		assert.Len(t, bundle.Executions[0].Events, 2)
+		assert.Empty(t, bundle.Executions[1].Events, "an unreadable history is reported, not fatal")
+		require.Len(t, bundle.Errors, 1)
+		assert.Equal(t, models.DebugSourceExecutions, bundle.Errors[0].Source)
+		assert.Contains(t, bundle.Errors[0].Message, "upload-up-1-1")
+
+		require.Len(t, bundle.Logs, 2, "invocations for other uploads are left out")
+		assert.Equal(t, "req-3", bundle.Logs[0].RequestID, "excerpts are in time order")
+		assert.True(t, bundle.Logs[0].Truncated, "the invocation's end was not found")
+		assert.Equal(t, "/aws/lambda/metadata", bundle.Logs[1].LogGroup)
+		assert.Equal(t, "req-1", bundle.Logs[1].RequestID)
+		assert.Len(t, bundle.Logs[1].Lines, 3)
+		assert.False(t, bundle.Logs[1].Truncated)
+
+		require.NotNil(t, bundle.SearchEntry)
+		assert.True(t, bundle.SearchEntry.Indexed)
+		assert.Equal(t, "READY", bundle.SearchEntry.Document.HLSStatus)
+	})
+
+	t.Run("unknown upload", func(t *testing.T) {
+		_, err := svc.Bundle(ctx, "u1", "missing")
+		assert.ErrorIs(t, err, models.ErrUploadNotFound)
+	})
+}
+
+func TestDebugObjectKeys(t *testing.T) {
+	upload := &models.Upload{ID: "up-1", UserID: "u1", S3Key: "uploads/u1/up-1/song.mp3"}
+	track := &models.Track{S3Key: "media/u1/tr-1.mp3", CoverArtKey: "covers/u1/tr-1.jpg"}
+
+	assert.Equal(t, []string{
+		"uploads/u1/up-1/song.mp3",
+		"quarantine/u1/up-1/song.mp3",
+		"media/u1/tr-1.mp3",
+		"covers/u1/tr-1.jpg",
+	}, debugObjectKeys(upload, track))
+	assert.Equal(t, []string{"uploads/u1/up-1/song.mp3", "quarantine/u1/up-1/song.mp3"}, debugObjectKeys(upload, nil))
+}
@@ -0,0 +1,622 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gvasels/personal-music-searchengine/internal/artwork"
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
+	"github.com/gvasels/personal-music-searchengine/internal/jobs"
+	"github.com/gvasels/personal-music-searchengine/internal/metadata"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/sanitize"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+	"github.com/gvasels/personal-music-searchengine/internal/validation"
+)
+
+// UploadPipelineJob is the job type of the in-process upload pipeline's steps
+const UploadPipelineJob = "upload-pipeline"
+
+// Steps of the in-process upload pipeline, run in this order as one job each.
+// They do the work of the state machine's Lambdas of the same names.
+const (
+	pipelineStepMetadata = "metadata"
+	pipelineStepCoverArt = "cover-art"
+	pipelineStepTrack    = "track"
+	pipelineStepMove     = "move"
+	pipelineStepIndex    = "index"
+)
+
+// maxPipelineArtwork bounds how many embedded images besides the cover are stored
+const maxPipelineArtwork = 16
+
+// UploadPipelineRepository defines the repository interface for the upload
+// pipeline. Uploads and tracks are addressed by their library, as the upload
+// service stored them.
+type UploadPipelineRepository interface {
+	GetUpload(ctx context.Context, userID, uploadID string) (*models.Upload, error)
+	UpdateUpload(ctx context.Context, upload models.Upload) error
+	UpdateUploadStatus(ctx context.Context, userID, uploadID string, status models.UploadStatus, errorMsg string, trackID string) error
+	UpdateUploadStep(ctx context.Context, userID, uploadID string, step models.ProcessingStep, success bool) error
+	GetUserSettings(ctx context.Context, userID string) (*models.UserSettings, error)
+	CreateTrack(ctx context.Context, track models.Track) error
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	UpdateTrack(ctx context.Context, track models.Track) error
+	GetOrCreateAlbum(ctx context.Context, userID, albumName, artist string) (*models.Album, error)
+	SetAlbumCover(ctx context.Context, userID, albumID, coverArtKey string, style *models.CoverStyle) error
+}
+
+// UploadPipelineMedia is the part of the media store the pipeline reads,
+// writes and moves uploaded files with
+type UploadPipelineMedia interface {
+	ObjectReadWriter
+	CopyObject(ctx context.Context, sourceKey, destKey string) error
+	DeleteObject(ctx context.Context, key string) error
+	GetObjectMetadata(ctx context.Context, key string) (map[string]string, error)
+}
+
+// pipelineState is the payload of a pipeline job: the upload, as the upload
+// service starts the pipeline with it, and what the steps so far found
+type pipelineState struct {
+	UploadID       string `json:"uploadId"`
+	UserID         string `json:"userId"`
+	S3Key          string `json:"s3Key"`
+	FileName       string `json:"fileName"`
+	ReplaceTrackID string `json:"replaceTrackId,omitempty"`
+	TenantID       string `json:"tenantId,omitempty"`
+
+	Step string `json:"step"`
+	// TrackID is chosen when the pipeline starts, so a step delivered again
+	// finds the track it created instead of creating another
+	TrackID  string                 `json:"trackId,omitempty"`
+	Metadata *models.UploadMetadata `json:"metadata,omitempty"`
+	CoverArt *pipelineCoverArt      `json:"coverArt,omitempty"`
+}
+
+// pipelineCoverArt is what the cover art step stored
+type pipelineCoverArt struct {
+	CoverArtKey string             `json:"coverArtKey"`
+	Artwork     []models.Artwork   `json:"artwork,omitempty"`
+	CoverStyle  *models.CoverStyle `json:"coverStyle,omitempty"`
+	// ThumbnailKey is not part of CoverStyle's JSON, so it travels separately
+	ThumbnailKey string `json:"thumbnailKey,omitempty"`
+}
+
+// style returns the cover's style with its thumbnail attached
+func (c *pipelineCoverArt) style() *models.CoverStyle {
+	if c.CoverStyle == nil {
+		return nil
+	}
+	style := *c.CoverStyle
+	style.ThumbnailKey = c.ThumbnailKey
+	return &style
+}
+
+// UploadPipelineService processes confirmed uploads on the job workers of a
+// self-hosted server, where there is no state machine. It implements
+// StepFunctionsClient, so the upload service starts it as it would start an
+// execution. Each step runs as a job and enqueues the next: metadata, cover
+// art, track, move and index, then the upload is marked completed. As in the
+// state machine, a failed cover art or index step is passed over and any
+// other failure marks the upload failed. Uploads are neither scanned for
+// malware nor transcoded, so their tracks play from the original file.
+type UploadPipelineService struct {
+	repo      UploadPipelineRepository
+	media     UploadPipelineMedia
+	queue     jobs.Queue
+	indexer   TrackIndexer
+	extractor *metadata.Extractor
+	now       func() time.Time
+}
+
+// NewUploadPipelineService creates a new upload pipeline service running its
+// steps on queue
+func NewUploadPipelineService(repo UploadPipelineRepository, media UploadPipelineMedia, queue jobs.Queue) *UploadPipelineService {
+	return &UploadPipelineService{
+		repo:      repo,
+		media:     media,
+		queue:     queue,
+		extractor: metadata.NewExtractor(),
+		now:       time.Now,
+	}
+}
+
+// SetIndexer indexes processed tracks for search; without one the index step
+// is passed over
+func (s *UploadPipelineService) SetIndexer(indexer TrackIndexer) {
+	s.indexer = indexer
+}
+
+// StartExecution queues the first step of an upload's pipeline. The input is
+// the upload service's execution input; the state machine ARN is ignored.
+func (s *UploadPipelineService) StartExecution(ctx context.Context, input *StepFunctionsStartInput) (*StepFunctionsStartOutput, error) {
+	var state pipelineState
+	if err := json.Unmarshal([]byte(input.Input), &state); err != nil {
+		return nil, fmt.Errorf("invalid pipeline input: %w", err)
+	}
+	state.Step = pipelineStepMetadata
+	if state.ReplaceTrackID != "" {
+		state.TrackID = state.ReplaceTrackID
+	} else {
+		state.TrackID = uuid.New().String()
+	}
+
+	job, err := s.enqueue(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	return &StepFunctionsStartOutput{ExecutionArn: "job:" + job.ID, StartDate: job.EnqueuedAt}, nil
+}
+
+// enqueue queues the job running state's step
+func (s *UploadPipelineService) enqueue(ctx context.Context, state pipelineState) (jobs.Job, error) {
+	job, err := jobs.New(UploadPipelineJob, state)
+	if err != nil {
+		return jobs.Job{}, err
+	}
+	if err := s.queue.Enqueue(ctx, job, 0); err != nil {
+		return jobs.Job{}, fmt.Errorf("failed to queue pipeline step %s: %w", state.Step, err)
+	}
+	return job, nil
+}
+
+// RunStep runs the step of a pipeline job and queues the next one. A step
+// that fails marks the upload failed; only a failure to record that, or to
+// queue the next step, is returned for the job to be retried.
+func (s *UploadPipelineService) RunStep(ctx context.Context, job jobs.Job) error {
+	var state pipelineState
+	if err := job.Decode(&state); err != nil {
+		return err
+	}
+	if err := validation.ValidateUUID(state.UploadID, "uploadId"); err != nil {
+		return jobs.Permanent(err)
+	}
+	ctx = tenant.WithID(ctx, state.TenantID)
+
+	var next string
+	var err error
+	switch state.Step {
+	case pipelineStepMetadata:
+		err = s.extractMetadata(ctx, &state)
+		next = pipelineStepCoverArt
+	case pipelineStepCoverArt:
+		// Tracks are created without cover art when it cannot be stored
+		if coverErr := s.processCoverArt(ctx, &state); coverErr != nil {
+			fmt.Printf("Warning: failed to process cover art of upload %s: %v\n", state.UploadID, coverErr)
+		}
+		next = pipelineStepTrack
+	case pipelineStepTrack:
+		err = s.createTrack(ctx, &state)
+		next = pipelineStepMove
+	case pipelineStepMove:
+		err = s.moveFile(ctx, &state)
+		next = pipelineStepIndex
+	case pipelineStepIndex:
+		s.indexTrack(ctx, &state)
+		return s.complete(ctx, state)
+	default:
+		return jobs.Permanent(fmt.Errorf("unknown pipeline step %q", state.Step))
+	}
+	if err != nil {
+		return s.fail(ctx, state, err)
+	}
+
+	state.Step = next
+	_, err = s.enqueue(ctx, state)
+	return err
+}
+
+// extractMetadata reads the uploaded file's tags and records its hash
+func (s *UploadPipelineService) extractMetadata(ctx context.Context, state *pipelineState) error {
+	data, err := s.readUpload(ctx, state.S3Key)
+	if err != nil {
+		return err
+	}
+	meta, err := s.extractor.Extract(bytes.NewReader(data), state.FileName)
+	if err != nil {
+		return fmt.Errorf("failed to extract metadata: %w", err)
+	}
+
+	// Record the file's hash so later uploads of the same bytes are recognized
+	sum := sha256.Sum256(data)
+	meta.ContentHash = hex.EncodeToString(sum[:])
+
+	// Flag uploads whose tags look mis-decoded so the owner can review the fixes
+	if len(meta.EncodingFixes) > 0 {
+		if err := s.flagSuspectEncoding(ctx, state, meta.EncodingFixes); err != nil {
+			fmt.Printf("Warning: failed to flag suspect tag encoding: %v\n", err)
+		}
+	}
+
+	state.Metadata = meta
+	s.markStep(ctx, state, models.StepExtractMetadata)
+	return nil
+}
+
+// readUpload reads an uploaded file, refusing files over the size the
+// pipeline processes in memory
+func (s *UploadPipelineService) readUpload(ctx context.Context, key string) ([]byte, error) {
+	meta, err := s.media.GetObjectMetadata(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("file validation failed: %w", err)
+	}
+	if size, err := strconv.ParseInt(meta["content-length"], 10, 64); err == nil && size > validation.MaxFileSizeBytes {
+		return nil, fmt.Errorf("file validation failed: file size %d bytes exceeds maximum allowed size of %d bytes", size, validation.MaxFileSizeBytes)
+	}
+
+	body, err := s.media.ReadObject(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+func (s *UploadPipelineService) flagSuspectEncoding(ctx context.Context, state *pipelineState, fixes []models.EncodingFix) error {
+	upload, err := s.repo.GetUpload(ctx, state.UserID, state.UploadID)
+	if err != nil {
+		return err
+	}
+	upload.SuspectEncoding = true
+	upload.EncodingFixes = fixes
+	return s.repo.UpdateUpload(ctx, *upload)
+}
+
+// processCoverArt stores the embedded front cover, its thumbnail and the
+// other embedded images
+func (s *UploadPipelineService) processCoverArt(ctx context.Context, state *pipelineState) error {
+	defer s.markStep(ctx, state, models.StepExtractCover)
+	if state.Metadata == nil || !state.Metadata.HasCoverArt {
+		return nil
+	}
+
+	data, err := s.readUpload(ctx, state.S3Key)
+	if err != nil {
+		return err
+	}
+	images, err := s.extractor.ExtractImages(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to extract cover art: %w", err)
+	}
+	cover := metadata.FrontCover(images)
+	if cover < 0 {
+		return nil
+	}
+
+	coverImage := images[cover]
+	coverKey, err := sanitize.Key("covers", state.UserID, state.UploadID+imageExtension(coverImage.MIMEType))
+	if err != nil {
+		return fmt.Errorf("failed to build cover art key: %w", err)
+	}
+	if err := s.media.WriteObject(ctx, coverKey, coverImage.Data, coverImage.MIMEType); err != nil {
+		return fmt.Errorf("failed to store cover art: %w", err)
+	}
+	result := &pipelineCoverArt{CoverArtKey: coverKey}
+
+	// The style is a display hint, so an image Go cannot decode (e.g. WebP)
+	// still gets stored without one
+	if result.CoverStyle, err = artwork.Analyze(coverImage.Data); err != nil {
+		fmt.Printf("Warning: failed to analyze cover art: %v\n", err)
+	} else if result.ThumbnailKey, err = s.storeThumbnail(ctx, state, coverImage.Data); err != nil {
+		fmt.Printf("Warning: failed to store cover thumbnail: %v\n", err)
+	}
+
+	// The remaining images go under covers/{userId}/{uploadId}/
+	for i, image := range images {
+		if i == cover || len(image.Data) == 0 {
+			continue
+		}
+		if len(result.Artwork) == maxPipelineArtwork {
+			fmt.Printf("Skipping %d further embedded images\n", len(images)-i)
+			break
+		}
+		name := fmt.Sprintf("%d-%s%s", i, image.Type, imageExtension(image.MIMEType))
+		key, err := sanitize.Key("covers", state.UserID, state.UploadID, name)
+		if err != nil {
+			return fmt.Errorf("failed to build artwork key: %w", err)
+		}
+		if err := s.media.WriteObject(ctx, key, image.Data, image.MIMEType); err != nil {
+			return fmt.Errorf("failed to store artwork: %w", err)
+		}
+		result.Artwork = append(result.Artwork, models.Artwork{
+			Type:        image.Type,
+			Key:         key,
+			MIMEType:    image.MIMEType,
+			Description: image.Description,
+		})
+	}
+
+	state.CoverArt = result
+	return nil
+}
+
+// storeThumbnail stores the small JPEG copy of the cover next to it
+func (s *UploadPipelineService) storeThumbnail(ctx context.Context, state *pipelineState, cover []byte) (string, error) {
+	thumbnail, err := artwork.Thumbnail(cover, artwork.ThumbnailEdge)
+	if err != nil {
+		return "", err
+	}
+	key, err := sanitize.Key("covers", state.UserID, state.UploadID+"-thumb.jpg")
+	if err != nil {
+		return "", err
+	}
+	if err := s.media.WriteObject(ctx, key, thumbnail, "image/jpeg"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// imageExtension returns the file extension of an image's MIME type
+func imageExtension(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
+
+// createTrack creates the upload's track, or refreshes the file-derived
+// properties of the track it replaces
+func (s *UploadPipelineService) createTrack(ctx context.Context, state *pipelineState) error {
+	if state.ReplaceTrackID != "" {
+		return s.replaceTrackFile(ctx, state)
+	}
+
+	// A step delivered again finds the track it created
+	if _, err := s.repo.GetTrack(ctx, state.UserID, state.TrackID); err == nil {
+		s.markStep(ctx, state, models.StepCreateTrack)
+		return nil
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to get track: %w", err)
+	}
+
+	// The track and any album created for it sort in the library's order
+	if settings, err := s.repo.GetUserSettings(ctx, state.UserID); err != nil {
+		fmt.Printf("Warning: failed to read sort locale: %v\n", err)
+	} else {
+		ctx = collation.WithLocale(ctx, settings.Library.SortLocale)
+	}
+
+	meta := state.Metadata
+	if meta == nil {
+		meta = &models.UploadMetadata{}
+	}
+	track := models.Track{
+		ID:          state.TrackID,
+		UserID:      state.UserID,
+		Title:       meta.Title,
+		Artist:      meta.Artist,
+		Album:       meta.Album,
+		Genre:       meta.Genre,
+		Year:        meta.Year,
+		Duration:    meta.Duration,
+		Format:      models.AudioFormat(meta.Format),
+		Bitrate:     meta.Bitrate,
+		Chapters:    meta.Chapters,
+		ContentHash: meta.ContentHash,
+		// Moved to media storage by the next step
+		S3Key: state.S3Key,
+	}
+	if track.Title == "" {
+		track.Title = state.FileName
+	}
+	if track.Artist == "" {
+		track.Artist = "Unknown Artist"
+	}
+	if track.Format == "" {
+		track.Format = models.AudioFormatMP3
+	}
+	if upload, err := s.repo.GetUpload(ctx, state.UserID, state.UploadID); err == nil {
+		track.FileSize = upload.FileSize
+	}
+	if state.CoverArt != nil && state.CoverArt.CoverArtKey != "" {
+		track.CoverArtKey = state.CoverArt.CoverArtKey
+		track.Artwork = state.CoverArt.Artwork
+		track.CoverStyle = state.CoverArt.style()
+	}
+	now := s.now()
+	track.CreatedAt = now
+	track.UpdatedAt = now
+
+	if err := s.repo.CreateTrack(ctx, track); err != nil {
+		return fmt.Errorf("failed to create track: %w", err)
+	}
+	s.markStep(ctx, state, models.StepCreateTrack)
+
+	if track.Album == "" {
+		return nil
+	}
+	album, err := s.repo.GetOrCreateAlbum(ctx, state.UserID, track.Album, track.Artist)
+	if err != nil {
+		// The track is created already
+		fmt.Printf("Warning: failed to create/update album: %v\n", err)
+		return nil
+	}
+	// An album without cover art takes the cover of its first track that has
+	// one; ErrConflict means another track of the album set it first
+	if album.CoverArtKey == "" && track.CoverArtKey != "" {
+		err := s.repo.SetAlbumCover(ctx, album.UserID, album.ID, track.CoverArtKey, track.CoverStyle)
+		if err != nil && !errors.Is(err, repository.ErrConflict) {
+			fmt.Printf("Warning: failed to set album cover: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// replaceTrackFile refreshes the file-derived properties of an existing track
+// from a replacement upload. Everything the user curated is preserved; the
+// file is swapped by the move step.
+func (s *UploadPipelineService) replaceTrackFile(ctx context.Context, state *pipelineState) error {
+	track, err := s.repo.GetTrack(ctx, state.UserID, state.ReplaceTrackID)
+	if err != nil {
+		return fmt.Errorf("failed to get track to replace: %w", err)
+	}
+
+	if meta := state.Metadata; meta != nil {
+		if meta.Format != "" {
+			track.Format = models.AudioFormat(meta.Format)
+		}
+		if meta.Duration != 0 {
+			track.Duration = meta.Duration
+		}
+		track.Bitrate = meta.Bitrate
+		track.SampleRate = meta.SampleRate
+		track.Channels = meta.Channels
+		track.Chapters = meta.Chapters
+		track.ContentHash = meta.ContentHash
+	}
+	if upload, err := s.repo.GetUpload(ctx, state.UserID, state.UploadID); err == nil {
+		track.FileSize = upload.FileSize
+	}
+
+	// Keep user-supplied cover art; only fill it in when the track has none
+	if track.CoverArtKey == "" && state.CoverArt != nil && state.CoverArt.CoverArtKey != "" {
+		track.CoverArtKey = state.CoverArt.CoverArtKey
+		track.CoverStyle = state.CoverArt.style()
+	}
+	if len(track.Artwork) == 0 && state.CoverArt != nil {
+		track.Artwork = state.CoverArt.Artwork
+	}
+
+	// HLS renditions and the preview clip belong to the old file. Nothing
+	// transcodes in-process, so the track plays from its new file.
+	track.HLSStatus = ""
+	track.HLSPlaylistKey = ""
+	track.HLSJobID = ""
+	track.HLSTranscodedAt = nil
+	track.PreviewStatus = ""
+	track.PreviewKey = ""
+	track.PreviewJobID = ""
+
+	if err := s.repo.UpdateTrack(ctx, *track); err != nil {
+		return fmt.Errorf("failed to update track: %w", err)
+	}
+	s.markStep(ctx, state, models.StepCreateTrack)
+	return nil
+}
+
+// moveFile moves the uploaded file to the track's media key, archiving the
+// file a replacement upload replaces
+func (s *UploadPipelineService) moveFile(ctx context.Context, state *pipelineState) error {
+	// A step delivered again after the move has nothing left to move
+	if upload, err := s.repo.GetUpload(ctx, state.UserID, state.UploadID); err == nil && upload.FileMoved {
+		return nil
+	}
+
+	ext := sanitize.Extension(state.S3Key)
+	if ext == "" {
+		ext = ".mp3"
+	}
+	destKey, err := sanitize.Key("media", state.UserID, state.TrackID+ext)
+	if err != nil {
+		return fmt.Errorf("failed to build destination key: %w", err)
+	}
+
+	track, err := s.repo.GetTrack(ctx, state.UserID, state.TrackID)
+	if err != nil {
+		return fmt.Errorf("failed to get track: %w", err)
+	}
+
+	// For file replacements, archive the current file before the new one can overwrite it
+	replacing := state.ReplaceTrackID != "" && track.S3Key != ""
+	previousKey := track.S3Key
+	if replacing {
+		now := s.now()
+		archiveKey := models.ArchivedTrackFileKey(state.UserID, state.TrackID, now, sanitize.Extension(previousKey))
+		if err := s.media.CopyObject(ctx, previousKey, archiveKey); err != nil {
+			return fmt.Errorf("failed to archive previous file: %w", err)
+		}
+		expiresAt := now.Add(models.ReplacedFileGracePeriod)
+		track.ArchivedS3Key = archiveKey
+		track.ArchiveExpiresAt = &expiresAt
+		track.FileReplacedAt = &now
+	}
+
+	if err := s.media.CopyObject(ctx, state.S3Key, destKey); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := s.media.DeleteObject(ctx, state.S3Key); err != nil {
+		// The file is copied already
+		fmt.Printf("Warning: failed to delete original file: %v\n", err)
+	}
+	// A replacement with a different extension leaves the old file behind; it is archived already
+	if replacing && previousKey != destKey {
+		if err := s.media.DeleteObject(ctx, previousKey); err != nil {
+			fmt.Printf("Warning: failed to delete replaced file: %v\n", err)
+		}
+	}
+
+	track.S3Key = destKey
+	if err := s.repo.UpdateTrack(ctx, *track); err != nil {
+		return fmt.Errorf("failed to update track S3 key: %w", err)
+	}
+	s.markStep(ctx, state, models.StepMoveFile)
+	return nil
+}
+
+// indexTrack indexes the track for search. Search is optional for an
+// upload, so a failure is logged and the upload completed all the same.
+func (s *UploadPipelineService) indexTrack(ctx context.Context, state *pipelineState) {
+	if s.indexer == nil {
+		return
+	}
+	track, err := s.repo.GetTrack(ctx, state.UserID, state.TrackID)
+	if err == nil {
+		err = s.indexer.IndexTrack(ctx, *track)
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to index track %s: %v\n", state.TrackID, err)
+		return
+	}
+	s.markStep(ctx, state, models.StepIndex)
+}
+
+// complete marks the upload completed with its track, stamping its completion time
+func (s *UploadPipelineService) complete(ctx context.Context, state pipelineState) error {
+	if err := s.repo.UpdateUploadStatus(ctx, state.UserID, state.UploadID, models.UploadStatusCompleted, "", state.TrackID); err != nil {
+		return fmt.Errorf("failed to update upload status: %w", err)
+	}
+	return nil
+}
+
+// fail marks the upload failed with the reason users see
+func (s *UploadPipelineService) fail(ctx context.Context, state pipelineState, cause error) error {
+	fmt.Printf("Upload %s failed at step %s: %v\n", state.UploadID, state.Step, cause)
+	if err := s.repo.UpdateUploadStatus(ctx, state.UserID, state.UploadID, models.UploadStatusFailed, cause.Error(), ""); err != nil {
+		return fmt.Errorf("failed to update upload status: %w", err)
+	}
+	upload, err := s.repo.GetUpload(ctx, state.UserID, state.UploadID)
+	if err != nil {
+		fmt.Printf("Warning: failed to record failure reason: %v\n", err)
+		return nil
+	}
+	upload.FailureReason = models.ClassifyUploadFailure("", cause.Error())
+	if err := s.repo.UpdateUpload(ctx, *upload); err != nil {
+		fmt.Printf("Warning: failed to record failure reason: %v\n", err)
+	}
+	return nil
+}
+
+// markStep records a finished step on the upload for its progress display
+func (s *UploadPipelineService) markStep(ctx context.Context, state *pipelineState, step models.ProcessingStep) {
+	if err := s.repo.UpdateUploadStep(ctx, state.UserID, state.UploadID, step, true); err != nil {
+		fmt.Printf("Warning: failed to update step progress: %v\n", err)
+	}
+}
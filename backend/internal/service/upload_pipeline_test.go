@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/jobs"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pipelineUserID   = "11111111-1111-4111-8111-111111111111"
+	pipelineUploadID = "22222222-2222-4222-8222-222222222222"
+)
+
+type pipelineTest struct {
+	ctx     context.Context
+	repo    *repository.MemoryRepository
+	media   *repository.MemoryS3Repository
+	queue   *jobs.MemoryQueue
+	indexer *recordingIndexer
+	svc     *UploadPipelineService
+}
+
+func newPipelineTest(t *testing.T) *pipelineTest {
+	t.Helper()
+	pt := &pipelineTest{
+		ctx:     context.Background(),
+		repo:    repository.NewMemoryRepository(),
+		media:   repository.NewMemoryS3Repository(""),
+		queue:   jobs.NewMemoryQueue(0),
+		indexer: &recordingIndexer{},
+	}
+	pt.svc = NewUploadPipelineService(pt.repo, pt.media, pt.queue)
+	pt.svc.SetIndexer(pt.indexer)
+	return pt
+}
+
+// upload stores an uploaded file and its upload record, as confirmed
+func (pt *pipelineTest) upload(t *testing.T, fileName string, data []byte, replaceTrackID string) models.Upload {
+	t.Helper()
+	key := "uploads/" + pipelineUserID + "/" + pipelineUploadID + "/" + fileName
+	require.NoError(t, pt.media.WriteObject(pt.ctx, key, data, "audio/mpeg"))
+	upload := models.Upload{
+		ID:             pipelineUploadID,
+		UserID:         pipelineUserID,
+		FileName:       fileName,
+		FileSize:       int64(len(data)),
+		S3Key:          key,
+		Status:         models.UploadStatusProcessing,
+		ReplaceTrackID: replaceTrackID,
+	}
+	require.NoError(t, pt.repo.CreateUpload(pt.ctx, upload))
+	return upload
+}
+
+// start starts the pipeline as the upload service does
+func (pt *pipelineTest) start(t *testing.T, upload models.Upload) {
+	t.Helper()
+	input, err := json.Marshal(map[string]interface{}{
+		"uploadId":       upload.ID,
+		"userId":         upload.UserID,
+		"s3Key":          upload.S3Key,
+		"fileName":       upload.FileName,
+		"bucketName":     "media",
+		"replaceTrackId": upload.ReplaceTrackID,
+	})
+	require.NoError(t, err)
+	out, err := pt.svc.StartExecution(pt.ctx, &StepFunctionsStartInput{Input: string(input)})
+	require.NoError(t, err)
+	assert.NotEmpty(t, out.ExecutionArn)
+}
+
+// run runs queued pipeline steps until none are left and returns the steps run
+func (pt *pipelineTest) run(t *testing.T) []string {
+	t.Helper()
+	var steps []string
+	for {
+		deliveries, err := pt.queue.Receive(pt.ctx, 1, time.Millisecond)
+		require.NoError(t, err)
+		if len(deliveries) == 0 {
+			return steps
+		}
+		var state pipelineState
+		require.NoError(t, deliveries[0].Job.Decode(&state))
+		steps = append(steps, state.Step)
+		require.NoError(t, pt.svc.RunStep(pt.ctx, deliveries[0].Job))
+		require.NoError(t, pt.queue.Ack(pt.ctx, deliveries[0]))
+	}
+}
+
+func TestUploadPipelineService(t *testing.T) {
+	pt := newPipelineTest(t)
+	upload := pt.upload(t, "Night Drive.mp3", []byte("not really audio"), "")
+
+	pt.start(t, upload)
+	assert.Equal(t, []string{"metadata", "cover-art", "track", "move", "index"}, pt.run(t))
+
+	stored, err := pt.repo.GetUpload(pt.ctx, pipelineUserID, pipelineUploadID)
+	require.NoError(t, err)
+	assert.Equal(t, models.UploadStatusCompleted, stored.Status)
+	assert.NotNil(t, stored.CompletedAt)
+	assert.True(t, stored.MetadataExtracted)
+	assert.True(t, stored.CoverArtExtracted)
+	assert.True(t, stored.TrackCreated)
+	assert.True(t, stored.FileMoved)
+	assert.True(t, stored.Indexed)
+	require.NotEmpty(t, stored.TrackID)
+
+	track, err := pt.repo.GetTrack(pt.ctx, pipelineUserID, stored.TrackID)
+	require.NoError(t, err)
+	assert.Equal(t, "Night Drive", track.Title, "untagged files are named after the file")
+	assert.Equal(t, "Unknown Artist", track.Artist)
+	assert.Equal(t, models.AudioFormatMP3, track.Format)
+	assert.Equal(t, upload.FileSize, track.FileSize)
+	assert.Len(t, track.ContentHash, 64)
+	assert.Equal(t, "media/"+pipelineUserID+"/"+track.ID+".mp3", track.S3Key)
+
+	exists, err := pt.media.ObjectExists(pt.ctx, track.S3Key)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = pt.media.ObjectExists(pt.ctx, upload.S3Key)
+	require.NoError(t, err)
+	assert.False(t, exists, "the uploaded file is moved")
+
+	require.Len(t, pt.indexer.indexed, 1)
+	assert.Equal(t, track.S3Key, pt.indexer.indexed[0].S3Key)
+}
+
+func TestUploadPipelineService_StepDeliveredAgain(t *testing.T) {
+	pt := newPipelineTest(t)
+	pt.start(t, pt.upload(t, "song.mp3", []byte("audio"), ""))
+
+	for _, step := range []string{"metadata", "cover-art"} {
+		deliveries, err := pt.queue.Receive(pt.ctx, 1, time.Millisecond)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1, step)
+		require.NoError(t, pt.svc.RunStep(pt.ctx, deliveries[0].Job))
+		require.NoError(t, pt.queue.Ack(pt.ctx, deliveries[0]))
+	}
+	deliveries, err := pt.queue.Receive(pt.ctx, 1, time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	// The track step runs twice, as after a worker stopped before acknowledging it
+	require.NoError(t, pt.svc.RunStep(pt.ctx, deliveries[0].Job))
+	require.NoError(t, pt.queue.Ack(pt.ctx, deliveries[0]))
+	require.NoError(t, pt.svc.RunStep(pt.ctx, deliveries[0].Job))
+	pt.run(t)
+
+	tracks, err := pt.repo.ListTracks(pt.ctx, pipelineUserID, models.TrackFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, tracks.Items, 1)
+	stored, err := pt.repo.GetUpload(pt.ctx, pipelineUserID, pipelineUploadID)
+	require.NoError(t, err)
+	assert.Equal(t, models.UploadStatusCompleted, stored.Status)
+}
+
+func TestUploadPipelineService_Replace(t *testing.T) {
+	pt := newPipelineTest(t)
+	const trackID = "33333333-3333-4333-8333-333333333333"
+	oldKey := "media/" + pipelineUserID + "/" + trackID + ".flac"
+	require.NoError(t, pt.media.WriteObject(pt.ctx, oldKey, []byte("old audio"), "audio/flac"))
+	existing := builders.NewTrackBuilder(pipelineUserID, trackID).WithTitle("Curated Title").Build()
+	existing.S3Key = oldKey
+	existing.HLSStatus = models.HLSStatusReady
+	existing.HLSPlaylistKey = "hls/" + pipelineUserID + "/" + trackID + "/master.m3u8"
+	require.NoError(t, pt.repo.CreateTrack(pt.ctx, existing))
+
+	pt.start(t, pt.upload(t, "better.mp3", []byte("new audio"), trackID))
+	pt.run(t)
+
+	track, err := pt.repo.GetTrack(pt.ctx, pipelineUserID, trackID)
+	require.NoError(t, err)
+	assert.Equal(t, "Curated Title", track.Title, "curated fields are kept")
+	assert.Equal(t, "media/"+pipelineUserID+"/"+trackID+".mp3", track.S3Key)
+	assert.Empty(t, track.HLSStatus, "nothing transcodes in-process, so the new file plays")
+	assert.Empty(t, track.HLSPlaylistKey)
+	require.NotEmpty(t, track.ArchivedS3Key)
+	require.NotNil(t, track.FileReplacedAt)
+
+	exists, err := pt.media.ObjectExists(pt.ctx, oldKey)
+	require.NoError(t, err)
+	assert.False(t, exists, "the replaced file is archived")
+	exists, err = pt.media.ObjectExists(pt.ctx, track.ArchivedS3Key)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	stored, err := pt.repo.GetUpload(pt.ctx, pipelineUserID, pipelineUploadID)
+	require.NoError(t, err)
+	assert.Equal(t, models.UploadStatusCompleted, stored.Status)
+	assert.Equal(t, trackID, stored.TrackID)
+}
+
+func TestUploadPipelineService_Failures(t *testing.T) {
+	t.Run("a missing file fails the upload", func(t *testing.T) {
+		pt := newPipelineTest(t)
+		upload := pt.upload(t, "song.mp3", []byte("audio"), "")
+		require.NoError(t, pt.media.DeleteObject(pt.ctx, upload.S3Key))
+
+		pt.start(t, upload)
+		assert.Equal(t, []string{"metadata"}, pt.run(t))
+
+		stored, err := pt.repo.GetUpload(pt.ctx, pipelineUserID, pipelineUploadID)
+		require.NoError(t, err)
+		assert.Equal(t, models.UploadStatusFailed, stored.Status)
+		assert.Equal(t, models.UploadFailedInvalidFile, stored.FailureReason)
+		assert.NotEmpty(t, stored.ErrorMsg)
+	})
+
+	t.Run("a failed index completes the upload", func(t *testing.T) {
+		pt := newPipelineTest(t)
+		pt.indexer.err = errors.New("search is down")
+		pt.start(t, pt.upload(t, "song.mp3", []byte("audio"), ""))
+		pt.run(t)
+
+		stored, err := pt.repo.GetUpload(pt.ctx, pipelineUserID, pipelineUploadID)
+		require.NoError(t, err)
+		assert.Equal(t, models.UploadStatusCompleted, stored.Status)
+		assert.False(t, stored.Indexed)
+	})
+
+	t.Run("a replacement of a missing track fails", func(t *testing.T) {
+		pt := newPipelineTest(t)
+		pt.start(t, pt.upload(t, "song.mp3", []byte("audio"), "44444444-4444-4444-8444-444444444444"))
+		assert.Equal(t, []string{"metadata", "cover-art", "track"}, pt.run(t))
+
+		stored, err := pt.repo.GetUpload(pt.ctx, pipelineUserID, pipelineUploadID)
+		require.NoError(t, err)
+		assert.Equal(t, models.UploadStatusFailed, stored.Status)
+		assert.Equal(t, models.UploadFailedProcessing, stored.FailureReason)
+	})
+
+	t.Run("invalid jobs are given up", func(t *testing.T) {
+		pt := newPipelineTest(t)
+		job, err := jobs.New(UploadPipelineJob, map[string]string{"uploadId": "not-a-uuid", "step": "metadata"})
+		require.NoError(t, err)
+		assert.True(t, jobs.IsPermanent(pt.svc.RunStep(pt.ctx, job)))
+
+		job, err = jobs.New(UploadPipelineJob, map[string]string{"uploadId": pipelineUploadID, "step": "transcode"})
+		require.NoError(t, err)
+		assert.True(t, jobs.IsPermanent(pt.svc.RunStep(pt.ctx, job)))
+	})
+}
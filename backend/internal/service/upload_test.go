@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadService_CreateReplaceFileUpload(t *testing.T) {
+	req := models.PresignedUploadRequest{
+		FileName:    "song.flac",
+		FileSize:    30 * 1024 * 1024,
+		ContentType: "audio/flac",
+	}
+
+	t.Run("issues presigned upload linked to the track", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
+
+		track := &models.Track{ID: "track-1", UserID: "user-1", FileSize: 5 * 1024 * 1024}
+		mockRepo.On("GetTrack", ctx, "user-1", "track-1").Return(track, nil)
+		mockRepo.On("GetUser", ctx, "user-1").Return(&models.User{ID: "user-1", StorageLimit: -1}, nil)
+		mockRepo.On("CreateUpload", ctx, mock.MatchedBy(func(u models.Upload) bool {
+			return u.ReplaceTrackID == "track-1"
+		})).Return(nil)
+		mockS3.On("GeneratePresignedUploadURL", ctx, mock.AnythingOfType("string"), "audio/flac", uploadURLExpiry).Return("https://upload.example.com", nil)
+
+		svc := NewUploadService(mockRepo, mockS3, "media-bucket", "")
+		resp, err := svc.CreateReplaceFileUpload(ctx, "user-1", "track-1", req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "track-1", resp.ReplaceTrackID)
+		assert.Equal(t, "https://upload.example.com", resp.UploadURL)
+		assert.NotEmpty(t, resp.UploadID)
+		mockRepo.AssertExpectations(t)
+		mockS3.AssertExpectations(t)
+	})
+
+	t.Run("returns not found for missing track", func(t *testing.T) {
+		ctx := context.Background()
+		mockRepo := new(mocks.Repository)
+		mockS3 := new(mocks.MediaStore)
+
+		mockRepo.On("GetTrack", ctx, "user-1", "missing").Return(nil, repository.ErrNotFound)
+
+		svc := NewUploadService(mockRepo, mockS3, "media-bucket", "")
+		resp, err := svc.CreateReplaceFileUpload(ctx, "user-1", "missing", req)
+
+		require.Error(t, err)
+		assert.Nil(t, resp)
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 404, apiErr.StatusCode)
+	})
+}
+
+func TestUploadService_PrecheckUpload(t *testing.T) {
+	ctx := context.Background()
+	hash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	repo := repository.NewMemoryRepository()
+	require.NoError(t, repo.CreateUser(ctx, models.User{ID: "user-1", StorageUsed: 9 * 1024 * 1024 * 1024}))
+	require.NoError(t, repo.CreateTrack(ctx, models.Track{ID: "track-1", UserID: "user-1", Title: "Archangel", Artist: "Burial", ContentHash: hash}))
+	svc := NewUploadService(repo, nil, "media-bucket", "")
+
+	reasons := func(resp *models.UploadPrecheckResponse) []models.UploadRejectionReason {
+		out := make([]models.UploadRejectionReason, len(resp.Rejections))
+		for i, rejection := range resp.Rejections {
+			out[i] = rejection.Reason
+		}
+		return out
+	}
+
+	t.Run("allows a new file", func(t *testing.T) {
+		resp, err := svc.PrecheckUpload(ctx, "user-1", models.UploadPrecheckRequest{FileName: "song.flac", FileSize: 30 * 1024 * 1024})
+		require.NoError(t, err)
+		assert.True(t, resp.Allowed)
+		assert.Empty(t, resp.Rejections)
+	})
+
+	t.Run("reports every rejection", func(t *testing.T) {
+		resp, err := svc.PrecheckUpload(ctx, "user-1", models.UploadPrecheckRequest{FileName: "song.wma", FileSize: 2 * 1024 * 1024 * 1024})
+		require.NoError(t, err)
+		assert.False(t, resp.Allowed)
+		assert.Equal(t, []models.UploadRejectionReason{models.UploadRejectedFormat, models.UploadRejectedSize, models.UploadRejectedQuota}, reasons(resp))
+	})
+
+	t.Run("finds byte-identical duplicates", func(t *testing.T) {
+		resp, err := svc.PrecheckUpload(ctx, "user-1", models.UploadPrecheckRequest{FileName: "Archangel.MP3", FileSize: 8 * 1024 * 1024, ContentHash: strings.ToUpper(hash)})
+		require.NoError(t, err)
+		assert.False(t, resp.Allowed)
+		require.Len(t, resp.Rejections, 1)
+		assert.Equal(t, models.UploadRejectedDuplicate, resp.Rejections[0].Reason)
+		assert.Equal(t, "track-1", resp.Rejections[0].TrackID)
+
+		resp, err = svc.PrecheckUpload(ctx, "user-2", models.UploadPrecheckRequest{FileName: "Archangel.mp3", FileSize: 8 * 1024 * 1024, ContentHash: hash})
+		require.NoError(t, err)
+		assert.True(t, resp.Allowed, "other users' tracks are not duplicates")
+	})
+}
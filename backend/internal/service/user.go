@@ -3,9 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/gvasels/personal-music-searchengine/internal/collation"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
 )
@@ -22,11 +24,14 @@ type UserSettingsUpdateInput struct {
 	Privacy       *models.PrivacySettings      `json:"privacy,omitempty"`
 	Player        *models.PlayerSettings       `json:"player,omitempty"`
 	Library       *models.LibrarySettings      `json:"library,omitempty"`
+	Language      *string                      `json:"language,omitempty"`
+	TimeZone      *string                      `json:"timeZone,omitempty"`
 }
 
 // userService implements UserService
 type userService struct {
-	repo repository.Repository
+	repo  repository.Repository
+	users *UserCache
 }
 
 // NewUserService creates a new user service
@@ -36,8 +41,29 @@ func NewUserService(repo repository.Repository) UserService {
 	}
 }
 
+// SetUserCache makes profile and role reads go through cache, and profile,
+// settings and account creation evict the user from it
+func (s *userService) SetUserCache(cache *UserCache) {
+	s.users = cache
+}
+
+// getUser reads a user through the cache when one is set
+func (s *userService) getUser(ctx context.Context, userID string) (*models.User, error) {
+	if s.users != nil {
+		return s.users.GetUser(ctx, userID)
+	}
+	return s.repo.GetUser(ctx, userID)
+}
+
+// invalidate evicts a changed user from the cache
+func (s *userService) invalidate(ctx context.Context, userID string) {
+	if s.users != nil {
+		s.users.Invalidate(ctx, userID)
+	}
+}
+
 func (s *userService) GetProfile(ctx context.Context, userID string) (*models.UserResponse, error) {
-	user, err := s.repo.GetUser(ctx, userID)
+	user, err := s.getUser(ctx, userID)
 	if err != nil {
 		if err == repository.ErrNotFound {
 			return nil, models.NewNotFoundError("User", userID)
@@ -69,6 +95,7 @@ func (s *userService) UpdateProfile(ctx context.Context, userID string, req mode
 	if err := s.repo.UpdateUser(ctx, *user); err != nil {
 		return nil, err
 	}
+	s.invalidate(ctx, userID)
 
 	response := user.ToResponse()
 	return &response, nil
@@ -101,6 +128,9 @@ func (s *userService) CreateUserIfNotExists(ctx context.Context, userID, email,
 	user.CreatedAt = now
 	user.UpdatedAt = now
 
+	// A lookup earlier in the request may have memoized the user as missing
+	defer s.invalidate(ctx, userID)
+
 	if err := s.repo.CreateUser(ctx, *user); err != nil {
 		// If user was created by another request, get it
 		existingUser, getErr := s.repo.GetUser(ctx, userID)
@@ -127,14 +157,23 @@ func (s *userService) GetSettings(ctx context.Context, userID string) (*models.U
 
 // UpdateSettings performs a partial update of user settings
 func (s *userService) UpdateSettings(ctx context.Context, userID string, input *UserSettingsUpdateInput) (*models.UserSettings, error) {
+	if input.Library != nil {
+		if err := s.applySortLocale(ctx, userID, input.Library.SortLocale); err != nil {
+			return nil, err
+		}
+	}
+
 	update := &repository.UserSettingsUpdate{
 		Notifications: input.Notifications,
 		Privacy:       input.Privacy,
 		Player:        input.Player,
 		Library:       input.Library,
+		Language:      input.Language,
+		TimeZone:      input.TimeZone,
 	}
 
 	settings, err := s.repo.UpdateUserSettings(ctx, userID, update)
+	s.invalidate(ctx, userID)
 	if err != nil {
 		if err == repository.ErrNotFound || err == repository.ErrUserNotFound {
 			return nil, ErrUserNotFound
@@ -148,6 +187,42 @@ func (s *userService) UpdateSettings(ctx context.Context, userID string, input *
 	return settings, nil
 }
 
+// applySortLocale rekeys the user's library for alphabetical browse before a
+// new sort locale is saved, so a failed rekey leaves the old setting in place
+// and saving again resumes it. Only a library's owner sets its order; the
+// locale of a household member is stored but not applied.
+func (s *userService) applySortLocale(ctx context.Context, userID, locale string) error {
+	if err := collation.Validate(locale); err != nil {
+		return ErrValidation
+	}
+	current, err := s.repo.GetUserSettings(ctx, userID)
+	if err != nil {
+		if err == repository.ErrNotFound || err == repository.ErrUserNotFound {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if current.Library.SortLocale == locale {
+		return nil
+	}
+
+	rekeyer, ok := s.repo.(SortLocaleRepository)
+	if !ok {
+		return nil
+	}
+	ownerID, err := libraryOwner(ctx, s.repo, userID)
+	if err != nil {
+		return err
+	}
+	if ownerID != userID {
+		return nil
+	}
+	if _, err := rekeyer.SetSortLocale(ctx, userID, locale); err != nil {
+		return fmt.Errorf("failed to apply sort locale: %w", err)
+	}
+	return nil
+}
+
 // CreateUserFromCognito creates a new user from Cognito signup event
 func (s *userService) CreateUserFromCognito(ctx context.Context, cognitoSub, email, displayName string) (*models.User, error) {
 	// Check if user already exists
@@ -162,6 +237,7 @@ func (s *userService) CreateUserFromCognito(ctx context.Context, cognitoSub, ema
 	// Create new user from Cognito data
 	user := models.NewUserFromCognito(cognitoSub, email, displayName)
 	user.StorageLimit = 10 * 1024 * 1024 * 1024 // 10 GB default limit
+	defer s.invalidate(ctx, cognitoSub)
 
 	if err := s.repo.CreateUser(ctx, user); err != nil {
 		// If user was created by another request, get it
@@ -178,7 +254,7 @@ func (s *userService) CreateUserFromCognito(ctx context.Context, cognitoSub, ema
 // GetUserRole returns the user's current role from the database.
 // This allows real-time role checking without requiring re-login.
 func (s *userService) GetUserRole(ctx context.Context, userID string) (models.UserRole, error) {
-	user, err := s.repo.GetUser(ctx, userID)
+	user, err := s.getUser(ctx, userID)
 	if err != nil {
 		if err == repository.ErrNotFound || err == repository.ErrUserNotFound {
 			// User not in DB yet - default to subscriber
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+)
+
+// DefaultUserCacheTTL bounds how long a role change made by another Lambda
+// instance can go unnoticed by this one
+const DefaultUserCacheTTL = 30 * time.Second
+
+// UserLoader reads a user from storage
+type UserLoader func(ctx context.Context, userID string) (*models.User, error)
+
+// UserCache memoizes user lookups for role and permission checks at two
+// levels: for the lifetime of a request (see WithRequestUserCache), and for
+// ttl across requests in the process. Services that change a user's role or
+// status evict it with Invalidate; changes made elsewhere are picked up once
+// the entry expires. Callers receive copies and may modify them freely.
+type UserCache struct {
+	load UserLoader
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]userCacheEntry
+}
+
+type userCacheEntry struct {
+	user    models.User
+	expires time.Time
+}
+
+// NewUserCache creates a UserCache reading through load. A ttl of zero
+// disables the process cache, leaving only per-request memoization.
+func NewUserCache(load UserLoader, ttl time.Duration) *UserCache {
+	return &UserCache{
+		load:    load,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]userCacheEntry),
+	}
+}
+
+// UserCacheAware is implemented by services that read users through a
+// UserCache and evict the users they change
+type UserCacheAware interface {
+	SetUserCache(cache *UserCache)
+}
+
+// GetUser returns the user from the request cache, then the process cache,
+// then storage. Lookup errors, including not found, are memoized only for the
+// current request.
+func (c *UserCache) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	scope := requestUserCacheFrom(ctx)
+	if scope != nil {
+		if entry, ok := scope.get(userID); ok {
+			return entry.user, entry.err
+		}
+	}
+
+	key := userCacheKey(ctx, userID)
+	if c.ttl > 0 {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+		if ok && c.now().Before(entry.expires) {
+			if scope != nil {
+				scope.put(userID, &entry.user, nil)
+			}
+			user := entry.user
+			return &user, nil
+		}
+	}
+
+	user, err := c.load(ctx, userID)
+	if scope != nil {
+		scope.put(userID, user, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ttl > 0 {
+		c.mu.Lock()
+		c.entries[key] = userCacheEntry{user: *user, expires: c.now().Add(c.ttl)}
+		c.mu.Unlock()
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// Invalidate evicts a user from the process cache and the current request's
+// cache, so the next lookup reads storage
+func (c *UserCache) Invalidate(ctx context.Context, userID string) {
+	c.mu.Lock()
+	delete(c.entries, userCacheKey(ctx, userID))
+	c.mu.Unlock()
+
+	if scope := requestUserCacheFrom(ctx); scope != nil {
+		scope.delete(userID)
+	}
+}
+
+// Close drops every cached user. The API server calls it once requests have
+// drained on shutdown.
+func (c *UserCache) Close() {
+	c.mu.Lock()
+	c.entries = make(map[string]userCacheEntry)
+	c.mu.Unlock()
+}
+
+// userCacheKey scopes cached users to the request's tenant, since user IDs
+// are only unique within a tenant's partition
+func userCacheKey(ctx context.Context, userID string) string {
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		return tenantID + "/" + userID
+	}
+	return userID
+}
+
+type requestUserCacheKey struct{}
+
+// requestUserCache holds the users looked up while serving one request
+type requestUserCache struct {
+	mu    sync.Mutex
+	users map[string]requestUserCacheEntry
+}
+
+type requestUserCacheEntry struct {
+	user *models.User
+	err  error
+}
+
+// WithRequestUserCache returns a context under which UserCache lookups are
+// memoized until the request ends. The API installs it per request.
+func WithRequestUserCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestUserCacheKey{}, &requestUserCache{
+		users: make(map[string]requestUserCacheEntry),
+	})
+}
+
+func requestUserCacheFrom(ctx context.Context) *requestUserCache {
+	scope, _ := ctx.Value(requestUserCacheKey{}).(*requestUserCache)
+	return scope
+}
+
+func (r *requestUserCache) get(userID string) (requestUserCacheEntry, bool) {
+	r.mu.Lock()
+	entry, ok := r.users[userID]
+	r.mu.Unlock()
+	if ok && entry.user != nil {
+		user := *entry.user
+		entry.user = &user
+	}
+	return entry, ok
+}
+
+func (r *requestUserCache) put(userID string, user *models.User, err error) {
+	entry := requestUserCacheEntry{err: err}
+	if user != nil {
+		copied := *user
+		entry.user = &copied
+	}
+	r.mu.Lock()
+	r.users[userID] = entry
+	r.mu.Unlock()
+}
+
+func (r *requestUserCache) delete(userID string) {
+	r.mu.Lock()
+	delete(r.users, userID)
+	r.mu.Unlock()
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRoleRepository serves users from a map and counts GetUser calls
+type countingRoleRepository struct {
+	users map[string]models.User
+	reads int
+}
+
+func (r *countingRoleRepository) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	r.reads++
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *countingRoleRepository) UpdateUserRole(ctx context.Context, userID string, role models.UserRole) error {
+	user := r.users[userID]
+	user.Role = role
+	r.users[userID] = user
+	return nil
+}
+
+func (r *countingRoleRepository) ListUsersByRole(ctx context.Context, role models.UserRole, limit int, cursor string) (*repository.PaginatedResult[models.User], error) {
+	return &repository.PaginatedResult[models.User]{}, nil
+}
+
+func TestUserCache_RequestScope(t *testing.T) {
+	repo := &countingRoleRepository{users: map[string]models.User{"u1": {ID: "u1", Role: models.RoleArtist}}}
+	cache := NewUserCache(repo.GetUser, 0)
+
+	ctx := WithRequestUserCache(context.Background())
+	for range 3 {
+		user, err := cache.GetUser(ctx, "u1")
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleArtist, user.Role)
+	}
+	assert.Equal(t, 1, repo.reads)
+
+	_, err := cache.GetUser(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = cache.GetUser(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.Equal(t, 2, repo.reads, "not found is memoized for the request")
+
+	_, err = cache.GetUser(WithRequestUserCache(context.Background()), "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, repo.reads, "without a TTL nothing is shared across requests")
+
+	user, err := cache.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	user.Role = models.RoleAdmin
+	user, err = cache.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleArtist, user.Role, "callers get copies")
+}
+
+func TestUserCache_TTL(t *testing.T) {
+	repo := &countingRoleRepository{users: map[string]models.User{"u1": {ID: "u1", Role: models.RoleArtist}}}
+	cache := NewUserCache(repo.GetUser, time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := cache.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	_, err = cache.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.reads)
+
+	_, err = cache.GetUser(tenant.WithID(ctx, "acme"), "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.reads, "entries are per tenant")
+
+	now = now.Add(time.Minute)
+	_, err = cache.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, repo.reads, "expired entries are reloaded")
+}
+
+func TestRoleService_UserCache(t *testing.T) {
+	repo := &countingRoleRepository{users: map[string]models.User{"u1": {ID: "u1", Role: models.RoleSubscriber}}}
+	svc := NewRoleService(repo)
+	svc.(UserCacheAware).SetUserCache(NewUserCache(repo.GetUser, time.Minute))
+	ctx := WithRequestUserCache(context.Background())
+
+	ok, err := svc.HasPermission(ctx, "u1", models.PermissionListen)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	role, err := svc.GetUserRole(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleSubscriber, role)
+	assert.Equal(t, 1, repo.reads)
+
+	require.NoError(t, svc.SetUserRole(ctx, "u1", models.RoleArtist))
+	role, err = svc.GetUserRole(context.Background(), "u1")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleArtist, role, "role changes evict the cached user")
+	assert.Equal(t, 2, repo.reads)
+}
+
+func TestUserCache_Close(t *testing.T) {
+	repo := &countingRoleRepository{users: map[string]models.User{"u1": {ID: "u1", Role: models.RoleArtist}}}
+	cache := NewUserCache(repo.GetUser, time.Minute)
+	ctx := context.Background()
+
+	_, err := cache.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	cache.Close()
+	_, err = cache.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.reads, "closing drops cached users")
+}
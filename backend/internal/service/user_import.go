@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+)
+
+// UserImportRepository defines the repository interface for provisioning users
+type UserImportRepository interface {
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	CreateUser(ctx context.Context, user models.User) error
+}
+
+// UserImportService provisions batches of users for admins
+type UserImportService struct {
+	repo     UserImportRepository
+	cognito  CognitoClient
+	password func() (string, error)
+}
+
+// NewUserImportService creates a new user import service
+func NewUserImportService(repo UserImportRepository, cognito CognitoClient) *UserImportService {
+	return &UserImportService{repo: repo, cognito: cognito, password: temporaryPassword}
+}
+
+// Import provisions every row of the request's CSV. Each new user gets a
+// Cognito account with a temporary password, their role's group and a
+// profile with the row's role and storage limit. Users already in the
+// library or in Cognito are reported as existing and left unchanged. A row
+// that fails does not stop the others.
+func (s *UserImportService) Import(ctx context.Context, req models.ImportUsersRequest) (*models.UserImportResponse, error) {
+	plan, err := s.planImport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	// A failed row is reported in its result, so creating never stops the plan
+	_, _ = plan.execute(ctx, req.DryRun, func(ctx context.Context, change userImportChange) error {
+		results := plan.result.Results
+		results[change.index] = s.createUser(ctx, change.row, results[change.index], req.SendInvites)
+		return nil
+	})
+
+	resp := plan.result
+	for _, result := range resp.Results {
+		switch result.Status {
+		case models.UserImportCreated, models.UserImportValid:
+			resp.Created++
+		case models.UserImportExists:
+			resp.Existing++
+		case models.UserImportFailed:
+			resp.Failed++
+		}
+	}
+	return resp, nil
+}
+
+// userImportChange is a row of a new user and the index of its result
+type userImportChange struct {
+	index int
+	row   models.UserImportRow
+}
+
+// planImport checks every row of the CSV against the library; nothing is
+// created. Rows of new users are reported valid, and the plan creates them.
+func (s *UserImportService) planImport(ctx context.Context, req models.ImportUsersRequest) (*bulkPlan[userImportChange, *models.UserImportResponse], error) {
+	defaultRole := models.DefaultUserRole()
+	if req.DefaultRole != "" {
+		defaultRole = models.UserRole(req.DefaultRole)
+	}
+	storageLimit := req.DefaultStorageLimit
+	if storageLimit == 0 {
+		storageLimit = defaultStorageLimit
+	}
+
+	rows, err := models.ParseUserImportCSV(req.CSV, defaultRole, storageLimit)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, models.NewValidationError("csv has no users")
+	}
+
+	plan := &bulkPlan[userImportChange, *models.UserImportResponse]{result: &models.UserImportResponse{Results: make([]models.UserImportResult, 0, len(rows)), DryRun: req.DryRun}}
+	for _, row := range rows {
+		result := s.checkRow(ctx, row)
+		if result.Status == models.UserImportValid {
+			plan.changes = append(plan.changes, userImportChange{index: len(plan.result.Results), row: row})
+		}
+		plan.result.Results = append(plan.result.Results, result)
+	}
+	return plan, nil
+}
+
+// failedImport reports a row that could not be imported
+func failedImport(result models.UserImportResult, err error) models.UserImportResult {
+	result.Status = models.UserImportFailed
+	result.UserID = ""
+	result.Error = err.Error()
+	return result
+}
+
+// checkRow reports a row as invalid, as an existing user, or as valid when
+// the user is new
+func (s *UserImportService) checkRow(ctx context.Context, row models.UserImportRow) models.UserImportResult {
+	result := models.UserImportResult{Line: row.Line, Email: row.Email, Role: row.Role, StorageLimit: row.StorageLimit}
+	if row.Err != "" {
+		return failedImport(result, errors.New(row.Err))
+	}
+
+	existing, err := s.repo.GetUserByEmail(ctx, row.Email)
+	if err == nil {
+		result.Status = models.UserImportExists
+		result.UserID = existing.ID
+		result.Role = existing.Role
+		result.StorageLimit = existing.StorageLimit
+		return result
+	}
+	if !errors.Is(err, repository.ErrUserNotFound) && !errors.Is(err, repository.ErrNotFound) {
+		return failedImport(result, err)
+	}
+	result.Status = models.UserImportValid
+	return result
+}
+
+// createUser provisions the new user of a valid row. The Cognito account is
+// deleted again when the user cannot be fully set up, so the row can simply
+// be imported again.
+func (s *UserImportService) createUser(ctx context.Context, row models.UserImportRow, result models.UserImportResult, sendInvites bool) models.UserImportResult {
+	password, err := s.password()
+	if err != nil {
+		return failedImport(result, err)
+	}
+	sub, err := s.cognito.CreateUser(ctx, row.Email, row.DisplayName, password, sendInvites)
+	if errors.Is(err, ErrCognitoUserExists) {
+		result.Status = models.UserImportExists
+		result.Role = ""
+		result.StorageLimit = 0
+		return result
+	}
+	if err != nil {
+		return failedImport(result, err)
+	}
+	result.UserID = sub
+
+	rollback := func(err error) models.UserImportResult {
+		if deleteErr := s.cognito.DeleteUser(ctx, row.Email); deleteErr != nil {
+			fmt.Printf("Warning: failed to remove Cognito user %s after failed import: %v\n", row.Email, deleteErr)
+		}
+		return failedImport(result, err)
+	}
+	if err := s.cognito.AddUserToGroup(ctx, row.Email, row.Role.CognitoGroupName()); err != nil {
+		return rollback(err)
+	}
+
+	user := models.NewUserFromCognito(sub, row.Email, row.DisplayName)
+	user.Role = row.Role
+	user.StorageLimit = row.StorageLimit
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		return rollback(fmt.Errorf("failed to create user profile: %w", err))
+	}
+
+	result.Status = models.UserImportCreated
+	if !sendInvites {
+		result.TemporaryPassword = password
+	}
+	return result
+}
+
+// temporaryPasswordAlphabets are the character classes Cognito's default
+// password policy requires; every temporary password has one of each
+var temporaryPasswordAlphabets = []string{
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"abcdefghijkmnopqrstuvwxyz",
+	"23456789",
+	"!@#$%^&*-_=+?",
+}
+
+// temporaryPasswordLength exceeds Cognito's default minimum of 8
+const temporaryPasswordLength = 16
+
+// temporaryPassword generates a random password meeting Cognito's default policy
+func temporaryPassword() (string, error) {
+	all := ""
+	for _, alphabet := range temporaryPasswordAlphabets {
+		all += alphabet
+	}
+
+	password := make([]byte, temporaryPasswordLength)
+	for i := range password {
+		alphabet := all
+		if i < len(temporaryPasswordAlphabets) {
+			alphabet = temporaryPasswordAlphabets[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate temporary password: %w", err)
+		}
+		password[i] = alphabet[n.Int64()]
+	}
+
+	// Shuffle so the required classes are not always first
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate temporary password: %w", err)
+		}
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
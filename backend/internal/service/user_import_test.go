@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserImportService_Import(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*repository.MemoryRepository, *MockCognitoClient, *UserImportService) {
+		repo := repository.NewMemoryRepository()
+		require.NoError(t, repo.CreateUser(ctx, models.User{ID: "existing-sub", Email: "existing@example.com", Role: models.RoleArtist}))
+		cognito := new(MockCognitoClient)
+		svc := NewUserImportService(repo, cognito)
+		svc.password = func() (string, error) { return "Temp-Passw0rd!", nil }
+		return repo, cognito, svc
+	}
+
+	t.Run("provisions new users and reports each row", func(t *testing.T) {
+		repo, cognito, svc := setup(t)
+		cognito.On("CreateUser", ctx, "alice@example.com", "Alice", "Temp-Passw0rd!", false).Return("alice-sub", nil)
+		cognito.On("AddUserToGroup", ctx, "alice@example.com", "artist").Return(nil)
+		cognito.On("CreateUser", ctx, "taken@example.com", "", "Temp-Passw0rd!", false).Return("", ErrCognitoUserExists)
+
+		resp, err := svc.Import(ctx, models.ImportUsersRequest{
+			CSV: "email,name,role,storageLimit\n" +
+				"alice@example.com,Alice,artist,-1\n" +
+				"existing@example.com,,,\n" +
+				"taken@example.com,,,\n" +
+				"bad-email,,,\n",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Created)
+		assert.Equal(t, 2, resp.Existing)
+		assert.Equal(t, 1, resp.Failed)
+		require.Len(t, resp.Results, 4)
+
+		assert.Equal(t, models.UserImportResult{
+			Line: 2, Email: "alice@example.com", Status: models.UserImportCreated, UserID: "alice-sub",
+			Role: models.RoleArtist, StorageLimit: -1, TemporaryPassword: "Temp-Passw0rd!",
+		}, resp.Results[0])
+		assert.Equal(t, models.UserImportExists, resp.Results[1].Status)
+		assert.Equal(t, "existing-sub", resp.Results[1].UserID)
+		assert.Equal(t, models.UserImportExists, resp.Results[2].Status)
+		assert.Equal(t, models.UserImportFailed, resp.Results[3].Status)
+
+		user, err := repo.GetUser(ctx, "alice-sub")
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Equal(t, models.RoleArtist, user.Role)
+		assert.Equal(t, int64(-1), user.StorageLimit)
+		cognito.AssertExpectations(t)
+	})
+
+	t.Run("applies defaults and keeps passwords out of invited rows", func(t *testing.T) {
+		repo, cognito, svc := setup(t)
+		cognito.On("CreateUser", ctx, "bob@example.com", "", "Temp-Passw0rd!", true).Return("bob-sub", nil)
+		cognito.On("AddUserToGroup", ctx, "bob@example.com", "subscriber").Return(nil)
+
+		resp, err := svc.Import(ctx, models.ImportUsersRequest{CSV: "email\nbob@example.com\n", SendInvites: true})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		assert.Empty(t, resp.Results[0].TemporaryPassword)
+
+		user, err := repo.GetUser(ctx, "bob-sub")
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleSubscriber, user.Role)
+		assert.Equal(t, int64(defaultStorageLimit), user.StorageLimit)
+	})
+
+	t.Run("removes the Cognito user when setup fails", func(t *testing.T) {
+		repo, cognito, svc := setup(t)
+		cognito.On("CreateUser", ctx, "carol@example.com", "", "Temp-Passw0rd!", false).Return("carol-sub", nil)
+		cognito.On("AddUserToGroup", ctx, "carol@example.com", "subscriber").Return(errors.New("group missing"))
+		cognito.On("DeleteUser", ctx, "carol@example.com").Return(nil)
+
+		resp, err := svc.Import(ctx, models.ImportUsersRequest{CSV: "email\ncarol@example.com\n"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Failed)
+		assert.Empty(t, resp.Results[0].UserID)
+		assert.Contains(t, resp.Results[0].Error, "group missing")
+
+		_, err = repo.GetUser(ctx, "carol-sub")
+		assert.Error(t, err)
+		cognito.AssertExpectations(t)
+	})
+
+	t.Run("dry run creates nothing", func(t *testing.T) {
+		_, cognito, svc := setup(t)
+
+		resp, err := svc.Import(ctx, models.ImportUsersRequest{CSV: "email\ndave@example.com\nexisting@example.com\n", DryRun: true})
+		require.NoError(t, err)
+		assert.True(t, resp.DryRun)
+		assert.Equal(t, models.UserImportValid, resp.Results[0].Status)
+		assert.Equal(t, models.UserImportExists, resp.Results[1].Status)
+		cognito.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a csv without users", func(t *testing.T) {
+		_, _, svc := setup(t)
+
+		_, err := svc.Import(ctx, models.ImportUsersRequest{CSV: "email\n"})
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 400, apiErr.StatusCode)
+	})
+}
+
+func TestTemporaryPassword(t *testing.T) {
+	password, err := temporaryPassword()
+	require.NoError(t, err)
+	assert.Len(t, password, temporaryPasswordLength)
+	assert.True(t, strings.IndexFunc(password, unicode.IsUpper) >= 0)
+	assert.True(t, strings.IndexFunc(password, unicode.IsLower) >= 0)
+	assert.True(t, strings.IndexFunc(password, unicode.IsDigit) >= 0)
+	assert.True(t, strings.ContainsAny(password, temporaryPasswordAlphabets[3]))
+}
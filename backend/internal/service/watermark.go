@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/tenant"
+)
+
+// watermarkedURLExpiry bounds the download URL of a watermarked copy; the
+// copy itself is removed after models.WatermarkedFileLifetime
+const watermarkedURLExpiry = time.Hour
+
+// WatermarkRepository defines the repository interface for watermarked downloads
+type WatermarkRepository interface {
+	PutDownloadToken(ctx context.Context, token models.DownloadToken) error
+	GetDownloadToken(ctx context.Context, ownerID, trackID, token string) (*models.DownloadToken, error)
+	ListDownloadTokens(ctx context.Context, ownerID, trackID string) ([]models.DownloadToken, error)
+
+	GetTrackByID(ctx context.Context, trackID string) (*models.Track, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+}
+
+// WatermarkTrackRepository reads and updates the tracks whose downloads are
+// protected
+type WatermarkTrackRepository interface {
+	GetTrack(ctx context.Context, userID, trackID string) (*models.Track, error)
+	UpdateTrack(ctx context.Context, track models.Track) error
+}
+
+// WatermarkDispatcher starts producing the watermarked copy of a download
+type WatermarkDispatcher interface {
+	Dispatch(ctx context.Context, req models.WatermarkRequest) error
+}
+
+// DownloadWatermarker hands out watermarked copies of tracks whose owner
+// protects downloads
+type DownloadWatermarker interface {
+	Issue(ctx context.Context, recipientID string, track models.Track) (*models.DownloadResponse, error)
+}
+
+// WatermarkAware is implemented by services that hand out downloads;
+// Services.ProtectDownloads installs the watermarker.
+type WatermarkAware interface {
+	SetWatermarker(watermarker DownloadWatermarker)
+}
+
+// WatermarkService protects downloads of tracks made available to others:
+// every download gets its own token, a copy of the track with the token mixed
+// in is produced by the watermark Lambda, and the token is kept with the
+// recipient so the owner can trace a leaked copy. Tokens are kept in the
+// partition of the track's library; owners' tracks are read through the
+// library-scoped repository so household members protect the shared library.
+type WatermarkService struct {
+	repo       WatermarkRepository
+	tracks     WatermarkTrackRepository
+	s3Repo     repository.MediaStore
+	dispatcher WatermarkDispatcher
+	now        func() time.Time
+}
+
+// NewWatermarkService creates a new watermark service
+func NewWatermarkService(repo WatermarkRepository, tracks WatermarkTrackRepository, s3Repo repository.MediaStore, dispatcher WatermarkDispatcher) *WatermarkService {
+	return &WatermarkService{repo: repo, tracks: tracks, s3Repo: s3Repo, dispatcher: dispatcher, now: time.Now}
+}
+
+// SetProtection sets how other users' downloads of one of the user's tracks
+// are watermarked. Locked tracks accept the change, since it leaves their
+// metadata alone.
+func (s *WatermarkService) SetProtection(ctx context.Context, userID, trackID string, mode models.WatermarkMode) (*models.DownloadProtectionResponse, error) {
+	track, err := s.getOwnTrack(ctx, userID, trackID)
+	if err != nil {
+		return nil, err
+	}
+
+	track.DownloadWatermark = mode
+	if err := s.tracks.UpdateTrack(ctx, *track); err != nil {
+		return nil, err
+	}
+
+	response := models.NewDownloadProtectionResponse(*track)
+	return &response, nil
+}
+
+// Issue records a new download token of track for the recipient and starts
+// producing its watermarked copy. The response has no URL yet; the recipient
+// polls GetDownload with the token.
+func (s *WatermarkService) Issue(ctx context.Context, recipientID string, track models.Track) (*models.DownloadResponse, error) {
+	token := models.DownloadToken{
+		Token:       models.NewDownloadToken(),
+		TrackID:     track.ID,
+		OwnerID:     track.UserID,
+		RecipientID: recipientID,
+		Mode:        track.DownloadWatermark,
+		Status:      models.DownloadTokenPending,
+		FileName:    track.DownloadFileName(),
+		CreatedAt:   s.now(),
+	}
+	// The email makes a traced token readable; a missing profile leaves the ID
+	if user, err := s.repo.GetUser(ctx, recipientID); err == nil {
+		token.RecipientEmail = user.Email
+	}
+	if err := s.repo.PutDownloadToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	tenantID, _ := tenant.FromContext(ctx)
+	err := s.dispatcher.Dispatch(ctx, models.WatermarkRequest{OwnerID: token.OwnerID, TrackID: token.TrackID, Token: token.Token, TenantID: tenantID})
+	if err != nil {
+		if _, recordErr := s.Finish(ctx, token, "", fmt.Errorf("watermarking could not start: %w", err)); recordErr != nil {
+			fmt.Printf("Warning: failed to record download token %s as failed: %v\n", token.Token, recordErr)
+		}
+		return nil, models.NewServiceUnavailableError("download protection", "watermarking could not start")
+	}
+
+	return s.response(ctx, &token, track)
+}
+
+// GetDownload returns the state of one of the user's watermarked downloads,
+// with a download URL once the copy is ready
+func (s *WatermarkService) GetDownload(ctx context.Context, userID, trackID, tokenID string) (*models.DownloadResponse, error) {
+	track, err := s.repo.GetTrackByID(ctx, trackID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("Download", tokenID)
+		}
+		return nil, err
+	}
+
+	token, err := s.repo.GetDownloadToken(ctx, track.UserID, trackID, tokenID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("Download", tokenID)
+		}
+		return nil, err
+	}
+	// Tokens are only shown to the person they were handed to
+	if token.RecipientID != userID {
+		return nil, models.NewNotFoundError("Download", tokenID)
+	}
+
+	return s.response(ctx, token, *track)
+}
+
+// ListDownloads returns the watermarked downloads of one of the user's
+// tracks, with who each was handed to
+func (s *WatermarkService) ListDownloads(ctx context.Context, userID, trackID string) ([]models.DownloadToken, error) {
+	track, err := s.getOwnTrack(ctx, userID, trackID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListDownloadTokens(ctx, track.UserID, trackID)
+}
+
+// TraceDownload returns who a token found in a leaked copy of one of the
+// user's tracks was handed to
+func (s *WatermarkService) TraceDownload(ctx context.Context, userID, trackID, tokenID string) (*models.DownloadToken, error) {
+	track, err := s.getOwnTrack(ctx, userID, trackID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.repo.GetDownloadToken(ctx, track.UserID, trackID, tokenID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("Download", tokenID)
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+// Claim returns a pending download token and its track for the watermark
+// Lambda. It returns nil for tokens that are no longer pending, so retried
+// invocations do not produce a copy twice.
+func (s *WatermarkService) Claim(ctx context.Context, req models.WatermarkRequest) (*models.DownloadToken, *models.Track, error) {
+	token, err := s.repo.GetDownloadToken(ctx, req.OwnerID, req.TrackID, req.Token)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	if token.Status != models.DownloadTokenPending {
+		return nil, nil, nil
+	}
+
+	track, err := s.tracks.GetTrack(ctx, req.OwnerID, req.TrackID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			_, err = s.Finish(ctx, *token, "", errors.New("track was deleted"))
+		}
+		return nil, nil, err
+	}
+	return token, track, nil
+}
+
+// Finish records the watermarked copy stored at s3Key, or why producing it
+// failed
+func (s *WatermarkService) Finish(ctx context.Context, token models.DownloadToken, s3Key string, produceErr error) (*models.DownloadToken, error) {
+	if produceErr != nil {
+		token.Status = models.DownloadTokenFailed
+		token.Error = produceErr.Error()
+	} else {
+		now := s.now()
+		token.Status = models.DownloadTokenReady
+		token.S3Key = s3Key
+		token.ReadyAt = &now
+	}
+	if err := s.repo.PutDownloadToken(ctx, token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// response describes a download token to its recipient
+func (s *WatermarkService) response(ctx context.Context, token *models.DownloadToken, track models.Track) (*models.DownloadResponse, error) {
+	response := &models.DownloadResponse{
+		TrackID:         track.ID,
+		FileName:        token.FileName,
+		Format:          string(track.Format),
+		WatermarkToken:  token.Token,
+		WatermarkStatus: token.Status,
+	}
+	if token.Status != models.DownloadTokenReady {
+		return response, nil
+	}
+
+	expiresAt := token.ReadyAt.Add(models.WatermarkedFileLifetime)
+	if !s.now().Before(expiresAt) {
+		return nil, models.NewConflictError("the watermarked copy has expired; download the track again")
+	}
+	url, err := s.s3Repo.GeneratePresignedDownloadURLWithFilename(ctx, token.S3Key, watermarkedURLExpiry, token.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate download URL: %w", err)
+	}
+	response.DownloadURL = url
+	response.ExpiresAt = s.now().Add(watermarkedURLExpiry)
+	return response, nil
+}
+
+func (s *WatermarkService) getOwnTrack(ctx context.Context, userID, trackID string) (*models.Track, error) {
+	track, err := s.tracks.GetTrack(ctx, userID, trackID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, models.NewNotFoundError("Track", trackID)
+		}
+		return nil, err
+	}
+	return track, nil
+}
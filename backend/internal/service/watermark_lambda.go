@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/search"
+)
+
+// LambdaWatermarkDispatcher starts watermarked copies by invoking the
+// watermark Lambda (cmd/processor/watermark) asynchronously
+type LambdaWatermarkDispatcher struct {
+	client       search.LambdaInvoker
+	functionName string
+}
+
+// NewLambdaWatermarkDispatcher creates a dispatcher for the named function
+func NewLambdaWatermarkDispatcher(client search.LambdaInvoker, functionName string) *LambdaWatermarkDispatcher {
+	return &LambdaWatermarkDispatcher{client: client, functionName: functionName}
+}
+
+// Dispatch queues the copy; Lambda retries failed asynchronous invocations itself
+func (d *LambdaWatermarkDispatcher) Dispatch(ctx context.Context, req models.WatermarkRequest) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal watermark request: %w", err)
+	}
+
+	_, err = d.client.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   aws.String(d.functionName),
+		InvocationType: types.InvocationTypeEvent,
+		Payload:        payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to invoke watermark function: %w", err)
+	}
+	return nil
+}
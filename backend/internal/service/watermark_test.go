@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubWatermarkDispatcher struct {
+	requests []models.WatermarkRequest
+	err      error
+}
+
+func (d *stubWatermarkDispatcher) Dispatch(ctx context.Context, req models.WatermarkRequest) error {
+	d.requests = append(d.requests, req)
+	return d.err
+}
+
+// newWatermarkService serves an owner's public track, protected with an
+// inaudible watermark, to a fan
+func newWatermarkService(t *testing.T) (*WatermarkService, *repository.MemoryRepository, *stubWatermarkDispatcher, models.Track) {
+	t.Helper()
+	track := builders.NewTrackBuilder("owner", "track-1").Public().WithWatermark(models.WatermarkInaudible).Build()
+	repo := newSeededRepo(t, track)
+	require.NoError(t, repo.CreateUser(context.Background(), models.User{ID: "fan", Email: "fan@example.com"}))
+	dispatcher := &stubWatermarkDispatcher{}
+	return NewWatermarkService(repo, repo, repository.NewMemoryS3Repository("https://media.example.com"), dispatcher), repo, dispatcher, track
+}
+
+func TestWatermarkService_SetProtection(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, _ := newWatermarkService(t)
+
+	protection, err := svc.SetProtection(ctx, "owner", "track-1", models.WatermarkAudible)
+	require.NoError(t, err)
+	assert.Equal(t, models.DownloadProtectionResponse{TrackID: "track-1", Watermark: "audible"}, *protection)
+
+	stored, err := repo.GetTrack(ctx, "owner", "track-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.WatermarkAudible, stored.DownloadWatermark)
+
+	_, err = svc.SetProtection(ctx, "fan", "track-1", models.WatermarkNone)
+	var apiErr *models.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "NOT_FOUND", apiErr.Code)
+}
+
+func TestWatermarkService_Download(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	svc, _, dispatcher, track := newWatermarkService(t)
+	svc.now = func() time.Time { return now }
+
+	issued, err := svc.Issue(ctx, "fan", track)
+	require.NoError(t, err)
+	assert.Equal(t, models.DownloadTokenPending, issued.WatermarkStatus)
+	assert.Empty(t, issued.DownloadURL)
+	assert.Len(t, issued.WatermarkToken, 16)
+	require.Len(t, dispatcher.requests, 1)
+	assert.Equal(t, models.WatermarkRequest{OwnerID: "owner", TrackID: "track-1", Token: issued.WatermarkToken}, dispatcher.requests[0])
+
+	t.Run("only the recipient sees the download", func(t *testing.T) {
+		_, err := svc.GetDownload(ctx, "someone-else", "track-1", issued.WatermarkToken)
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+
+		download, err := svc.GetDownload(ctx, "fan", "track-1", issued.WatermarkToken)
+		require.NoError(t, err)
+		assert.Equal(t, models.DownloadTokenPending, download.WatermarkStatus)
+	})
+
+	t.Run("the copy is claimed once and handed out when ready", func(t *testing.T) {
+		token, claimed, err := svc.Claim(ctx, dispatcher.requests[0])
+		require.NoError(t, err)
+		require.NotNil(t, token)
+		assert.Equal(t, "track-1", claimed.ID)
+		assert.Equal(t, models.WatermarkInaudible, token.Mode)
+
+		key := models.WatermarkedFileKey(token.OwnerID, token.Token, claimed.Format)
+		_, err = svc.Finish(ctx, *token, key, nil)
+		require.NoError(t, err)
+
+		token, _, err = svc.Claim(ctx, dispatcher.requests[0])
+		require.NoError(t, err)
+		assert.Nil(t, token, "a retried invocation does not produce the copy again")
+
+		download, err := svc.GetDownload(ctx, "fan", "track-1", issued.WatermarkToken)
+		require.NoError(t, err)
+		assert.Equal(t, models.DownloadTokenReady, download.WatermarkStatus)
+		assert.Contains(t, download.DownloadURL, "watermarked/owner/"+issued.WatermarkToken+".mp3")
+		assert.Equal(t, now.Add(time.Hour), download.ExpiresAt)
+	})
+
+	t.Run("the owner traces the token to the recipient", func(t *testing.T) {
+		traced, err := svc.TraceDownload(ctx, "owner", "track-1", issued.WatermarkToken)
+		require.NoError(t, err)
+		assert.Equal(t, "fan", traced.RecipientID)
+		assert.Equal(t, "fan@example.com", traced.RecipientEmail)
+
+		downloads, err := svc.ListDownloads(ctx, "owner", "track-1")
+		require.NoError(t, err)
+		assert.Len(t, downloads, 1)
+
+		_, err = svc.TraceDownload(ctx, "fan", "track-1", issued.WatermarkToken)
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+	})
+
+	t.Run("expired copies are not handed out", func(t *testing.T) {
+		svc.now = func() time.Time { return now.Add(models.WatermarkedFileLifetime) }
+		defer func() { svc.now = func() time.Time { return now } }()
+
+		_, err := svc.GetDownload(ctx, "fan", "track-1", issued.WatermarkToken)
+		var apiErr *models.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "CONFLICT", apiErr.Code)
+	})
+}
+
+func TestWatermarkService_IssueDispatchFailure(t *testing.T) {
+	ctx := context.Background()
+	svc, _, dispatcher, track := newWatermarkService(t)
+	dispatcher.err = errors.New("lambda unavailable")
+
+	_, err := svc.Issue(ctx, "fan", track)
+
+	var apiErr *models.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "SERVICE_UNAVAILABLE", apiErr.Code)
+	downloads, err := svc.ListDownloads(ctx, "owner", "track-1")
+	require.NoError(t, err)
+	require.Len(t, downloads, 1)
+	assert.Equal(t, models.DownloadTokenFailed, downloads[0].Status)
+}
@@ -0,0 +1,86 @@
+// Package tenant carries the tenant dimension used when one deployment hosts
+// several isolated instances (multi-tenant mode). The tenant ID travels on the
+// request context; storage decorators in the repository package read it to
+// prefix DynamoDB partition keys and S3 object keys.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// KeyPrefixSeparator separates the tenant prefix from the original partition key
+const KeyPrefixSeparator = "#"
+
+// ObjectPrefixRoot is the S3 prefix under which every tenant's objects live
+const ObjectPrefixRoot = "tenants/"
+
+var (
+	// ErrMissingTenant is returned when a tenant-scoped operation runs without a tenant
+	ErrMissingTenant = errors.New("tenant: no tenant in context")
+	// ErrInvalidTenant is returned for tenant IDs that are not safe to embed in keys
+	ErrInvalidTenant = errors.New("tenant: invalid tenant id")
+
+	idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+)
+
+type contextKey struct{}
+
+// ValidateID checks that a tenant ID is lowercase alphanumeric (with dashes), 2-63 chars
+func ValidateID(id string) error {
+	if !idPattern.MatchString(id) {
+		return ErrInvalidTenant
+	}
+	return nil
+}
+
+// WithID returns a copy of ctx carrying the tenant ID
+func WithID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ID carried by ctx, if any
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// KeyPrefix returns the partition key prefix for a tenant, e.g. "TENANT#acme#"
+func KeyPrefix(id string) string {
+	return "TENANT" + KeyPrefixSeparator + id + KeyPrefixSeparator
+}
+
+// SplitKey splits a partition key read outside a tenant-scoped client, such as
+// one in a stream record, into its tenant ID and the original key. Keys
+// without a tenant prefix are returned unchanged with ok false.
+func SplitKey(key string) (id, original string, ok bool) {
+	rest, found := strings.CutPrefix(key, "TENANT"+KeyPrefixSeparator)
+	if !found {
+		return "", key, false
+	}
+	id, original, found = strings.Cut(rest, KeyPrefixSeparator)
+	if !found || ValidateID(id) != nil {
+		return "", key, false
+	}
+	return id, original, true
+}
+
+// ObjectPrefix returns the S3 key prefix for a tenant, e.g. "tenants/acme/"
+func ObjectPrefix(id string) string {
+	return ObjectPrefixRoot + id + "/"
+}
+
+// ObjectKey maps a logical S3 key to the tenant's physical key. Keys are
+// returned unchanged when ctx carries no tenant (single-tenant mode).
+func ObjectKey(ctx context.Context, key string) string {
+	id, ok := FromContext(ctx)
+	if !ok || key == "" {
+		return key
+	}
+	return ObjectPrefix(id) + key
+}
@@ -0,0 +1,74 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateID(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		valid bool
+	}{
+		{"simple", "acme", true},
+		{"with dash", "acme-records", true},
+		{"digits", "42", true},
+		{"empty", "", false},
+		{"single char", "a", false},
+		{"uppercase", "Acme", false},
+		{"hash", "acme#1", false},
+		{"slash", "acme/1", false},
+		{"leading dash", "-acme", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateID(tt.input)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidTenant)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+
+	id, ok := FromContext(WithID(ctx, "acme"))
+	assert.True(t, ok)
+	assert.Equal(t, "acme", id)
+
+	_, ok = FromContext(WithID(ctx, ""))
+	assert.False(t, ok)
+}
+
+func TestObjectKey(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, "media/u1/t1.mp3", ObjectKey(ctx, "media/u1/t1.mp3"))
+	assert.Equal(t, "tenants/acme/media/u1/t1.mp3", ObjectKey(WithID(ctx, "acme"), "media/u1/t1.mp3"))
+	assert.Equal(t, "", ObjectKey(WithID(ctx, "acme"), ""))
+	assert.Equal(t, "TENANT#acme#", KeyPrefix("acme"))
+}
+
+func TestSplitKey(t *testing.T) {
+	id, key, ok := SplitKey(KeyPrefix("acme") + "USER#u1")
+	assert.True(t, ok)
+	assert.Equal(t, "acme", id)
+	assert.Equal(t, "USER#u1", key)
+
+	for _, unprefixed := range []string{"USER#u1", "TENANT#", "TENANT#Acme#USER#u1"} {
+		id, key, ok := SplitKey(unprefixed)
+		assert.False(t, ok, unprefixed)
+		assert.Empty(t, id)
+		assert.Equal(t, unprefixed, key)
+	}
+}
@@ -0,0 +1,190 @@
+package builders
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+)
+
+// TrackBuilder builds models.Track fixtures for unit tests. Only the fields a
+// test cares about need setting; the rest get valid defaults.
+type TrackBuilder struct {
+	track models.Track
+}
+
+// NewTrackBuilder starts a private MP3 track owned by userID
+func NewTrackBuilder(userID, trackID string) *TrackBuilder {
+	now := time.Now()
+	return &TrackBuilder{track: models.Track{
+		ID:         trackID,
+		UserID:     userID,
+		Title:      "Track " + trackID,
+		Artist:     "Test Artist",
+		Duration:   180,
+		Format:     models.AudioFormatMP3,
+		S3Key:      fmt.Sprintf("media/%s/%s.mp3", userID, trackID),
+		Visibility: models.VisibilityPrivate,
+		Timestamps: models.Timestamps{CreatedAt: now, UpdatedAt: now},
+	}}
+}
+
+func (b *TrackBuilder) WithTitle(title string) *TrackBuilder {
+	b.track.Title = title
+	return b
+}
+
+func (b *TrackBuilder) WithArtist(artist string) *TrackBuilder {
+	b.track.Artist = artist
+	return b
+}
+
+func (b *TrackBuilder) WithAlbum(album string) *TrackBuilder {
+	b.track.Album = album
+	return b
+}
+
+func (b *TrackBuilder) WithGenre(genre string) *TrackBuilder {
+	b.track.Genre = genre
+	return b
+}
+
+func (b *TrackBuilder) WithDuration(seconds int) *TrackBuilder {
+	b.track.Duration = seconds
+	return b
+}
+
+// WithFileSize sets the size of the track's original file in bytes
+func (b *TrackBuilder) WithFileSize(bytes int64) *TrackBuilder {
+	b.track.FileSize = bytes
+	return b
+}
+
+func (b *TrackBuilder) WithBPM(bpm int) *TrackBuilder {
+	b.track.BPM = bpm
+	return b
+}
+
+// WithKey sets the musical key and its Camelot notation, e.g. ("Am", "8A")
+func (b *TrackBuilder) WithKey(key, camelot string) *TrackBuilder {
+	b.track.MusicalKey = key
+	b.track.KeyCamelot = camelot
+	return b
+}
+
+func (b *TrackBuilder) WithTags(tags ...string) *TrackBuilder {
+	b.track.Tags = tags
+	return b
+}
+
+func (b *TrackBuilder) WithCoverArt(key string) *TrackBuilder {
+	b.track.CoverArtKey = key
+	return b
+}
+
+// WithPreview marks the track's preview clip as transcoded to key
+func (b *TrackBuilder) WithPreview(key string) *TrackBuilder {
+	b.track.PreviewStatus = models.HLSStatusReady
+	b.track.PreviewKey = key
+	return b
+}
+
+// WithWatermark sets how other users' downloads of the track are marked
+func (b *TrackBuilder) WithWatermark(mode models.WatermarkMode) *TrackBuilder {
+	b.track.DownloadWatermark = mode
+	return b
+}
+
+// Public makes the track public and stamps PublishedAt
+func (b *TrackBuilder) Public() *TrackBuilder {
+	now := time.Now()
+	b.track.Visibility = models.VisibilityPublic
+	b.track.PublishedAt = &now
+	return b
+}
+
+func (b *TrackBuilder) Locked() *TrackBuilder {
+	now := time.Now()
+	b.track.Locked = true
+	b.track.LockedAt = &now
+	return b
+}
+
+// Build returns a copy, so one builder can produce several variants
+func (b *TrackBuilder) Build() models.Track {
+	track := b.track
+	track.Tags = append([]string(nil), b.track.Tags...)
+	return track
+}
+
+// BuildPtr returns a pointer to a copy, matching the repository Get* signatures
+func (b *TrackBuilder) BuildPtr() *models.Track {
+	track := b.Build()
+	return &track
+}
+
+// PlaylistBuilder builds models.Playlist fixtures together with their track
+// entries, keeping TrackCount and TotalDuration consistent with the tracks.
+type PlaylistBuilder struct {
+	playlist models.Playlist
+	tracks   []models.PlaylistTrack
+}
+
+// NewPlaylistBuilder starts an empty private playlist owned by userID
+func NewPlaylistBuilder(userID, playlistID string) *PlaylistBuilder {
+	now := time.Now()
+	return &PlaylistBuilder{playlist: models.Playlist{
+		ID:         playlistID,
+		UserID:     userID,
+		Name:       "Playlist " + playlistID,
+		Visibility: models.VisibilityPrivate,
+		Timestamps: models.Timestamps{CreatedAt: now, UpdatedAt: now},
+	}}
+}
+
+func (b *PlaylistBuilder) WithName(name string) *PlaylistBuilder {
+	b.playlist.Name = name
+	return b
+}
+
+func (b *PlaylistBuilder) WithDescription(description string) *PlaylistBuilder {
+	b.playlist.Description = description
+	return b
+}
+
+func (b *PlaylistBuilder) WithVisibility(visibility models.PlaylistVisibility) *PlaylistBuilder {
+	b.playlist.Visibility = visibility
+	b.playlist.IsPublic = visibility == models.VisibilityPublic
+	return b
+}
+
+// WithTracks appends tracks in order and adds their durations to the total
+func (b *PlaylistBuilder) WithTracks(tracks ...models.Track) *PlaylistBuilder {
+	for _, track := range tracks {
+		b.tracks = append(b.tracks, models.PlaylistTrack{
+			PlaylistID: b.playlist.ID,
+			TrackID:    track.ID,
+			Position:   len(b.tracks),
+			AddedAt:    time.Now(),
+		})
+		b.playlist.TotalDuration += track.Duration
+	}
+	b.playlist.TrackCount = len(b.tracks)
+	return b
+}
+
+// Build returns the playlist
+func (b *PlaylistBuilder) Build() models.Playlist {
+	return b.playlist
+}
+
+// BuildPtr returns a pointer to a copy of the playlist
+func (b *PlaylistBuilder) BuildPtr() *models.Playlist {
+	playlist := b.playlist
+	return &playlist
+}
+
+// Tracks returns the playlist's track entries in position order
+func (b *PlaylistBuilder) Tracks() []models.PlaylistTrack {
+	return append([]models.PlaylistTrack(nil), b.tracks...)
+}
@@ -0,0 +1,91 @@
+//go:build integration
+
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/gvasels/personal-music-searchengine/internal/service"
+)
+
+// InProcessLambda adapts a Lambda handler to search.LambdaInvoker so the API's
+// Lambda clients can call the real handler in the test process. The payload
+// goes through the same JSON encoding as a real invocation; a handler error is
+// reported as a FunctionError, as the Lambda service would.
+func InProcessLambda[Req, Resp any](handler func(context.Context, Req) (Resp, error)) *InProcessInvoker {
+	return &InProcessInvoker{
+		invoke: func(ctx context.Context, payload []byte) ([]byte, error) {
+			var req Req
+			if err := json.Unmarshal(payload, &req); err != nil {
+				return nil, fmt.Errorf("failed to decode Lambda payload: %w", err)
+			}
+			resp, err := handler(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(resp)
+		},
+	}
+}
+
+// InProcessInvoker implements the Lambda Invoke call against an in-process handler
+type InProcessInvoker struct {
+	mu          sync.Mutex
+	invoke      func(ctx context.Context, payload []byte) ([]byte, error)
+	invocations int
+}
+
+// Invoke runs the handler with the request payload
+func (i *InProcessInvoker) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	i.mu.Lock()
+	i.invocations++
+	i.mu.Unlock()
+
+	payload, err := i.invoke(ctx, params.Payload)
+	if err != nil {
+		errPayload, _ := json.Marshal(map[string]string{"errorMessage": err.Error()})
+		return &lambda.InvokeOutput{
+			StatusCode:    200,
+			FunctionError: aws.String("Unhandled"),
+			Payload:       errPayload,
+		}, nil
+	}
+	return &lambda.InvokeOutput{StatusCode: 200, Payload: payload}, nil
+}
+
+// Invocations returns how many times the handler was invoked
+func (i *InProcessInvoker) Invocations() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.invocations
+}
+
+// StepFunctionsRecorder records upload-pipeline executions instead of starting
+// them, so tests can assert on the input the state machine would receive.
+type StepFunctionsRecorder struct {
+	mu         sync.Mutex
+	executions []service.StepFunctionsStartInput
+}
+
+// StartExecution records the execution
+func (r *StepFunctionsRecorder) StartExecution(ctx context.Context, input *service.StepFunctionsStartInput) (*service.StepFunctionsStartOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executions = append(r.executions, *input)
+	return &service.StepFunctionsStartOutput{
+		ExecutionArn: fmt.Sprintf("%s:execution:%s", input.StateMachineArn, input.Name),
+	}, nil
+}
+
+// Executions returns the recorded executions in start order
+func (r *StepFunctionsRecorder) Executions() []service.StepFunctionsStartInput {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]service.StepFunctionsStartInput(nil), r.executions...)
+}
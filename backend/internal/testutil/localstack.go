@@ -53,23 +53,25 @@ type cleanupItem struct {
 
 // LocalStackConfig holds configuration for LocalStack connection.
 type LocalStackConfig struct {
-	Endpoint    string
-	Region      string
-	TableName   string
-	BucketName  string
-	UserPoolID  string
-	ClientID    string
+	Endpoint         string
+	DynamoDBEndpoint string // DynamoDB Local, when tables are not served by LocalStack
+	Region           string
+	TableName        string
+	BucketName       string
+	UserPoolID       string
+	ClientID         string
 }
 
 // DefaultConfig returns the default LocalStack configuration.
 func DefaultConfig() LocalStackConfig {
 	return LocalStackConfig{
-		Endpoint:   getEnvOrDefault("LOCALSTACK_ENDPOINT", DefaultLocalStackEndpoint),
-		Region:     getEnvOrDefault("AWS_REGION", "us-east-1"),
-		TableName:  getEnvOrDefault("DYNAMODB_TABLE_NAME", DefaultTableName),
-		BucketName: getEnvOrDefault("MEDIA_BUCKET", DefaultBucketName),
-		UserPoolID: os.Getenv("COGNITO_USER_POOL_ID"),
-		ClientID:   os.Getenv("COGNITO_CLIENT_ID"),
+		Endpoint:         getEnvOrDefault("LOCALSTACK_ENDPOINT", DefaultLocalStackEndpoint),
+		DynamoDBEndpoint: os.Getenv("DYNAMODB_ENDPOINT"),
+		Region:           getEnvOrDefault("AWS_REGION", "us-east-1"),
+		TableName:        getEnvOrDefault("DYNAMODB_TABLE_NAME", DefaultTableName),
+		BucketName:       getEnvOrDefault("MEDIA_BUCKET", DefaultBucketName),
+		UserPoolID:       os.Getenv("COGNITO_USER_POOL_ID"),
+		ClientID:         os.Getenv("COGNITO_CLIENT_ID"),
 	}
 }
 
@@ -111,8 +113,12 @@ func SetupLocalStack(t *testing.T) (*TestContext, func()) {
 	}
 
 	// Create clients with LocalStack endpoint
+	dynamoEndpoint := cfg.Endpoint
+	if cfg.DynamoDBEndpoint != "" {
+		dynamoEndpoint = cfg.DynamoDBEndpoint
+	}
 	dynamoClient := dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
-		o.BaseEndpoint = aws.String(cfg.Endpoint)
+		o.BaseEndpoint = aws.String(dynamoEndpoint)
 	})
 
 	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
@@ -144,6 +150,11 @@ func SetupLocalStack(t *testing.T) (*TestContext, func()) {
 		cleanupItems: make([]cleanupItem, 0),
 	}
 
+	// Create the table and bucket so tests also run against a fresh container
+	if err := tc.EnsureSchema(ctx); err != nil {
+		t.Fatalf("Failed to create LocalStack resources: %v", err)
+	}
+
 	cleanup := func() {
 		tc.runCleanup(t)
 	}
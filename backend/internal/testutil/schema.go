@@ -0,0 +1,106 @@
+//go:build integration
+
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// tableActiveTimeout bounds how long EnsureTable waits for a new table
+const tableActiveTimeout = 30 * time.Second
+
+// gsiNames are the global secondary indexes defined in infrastructure/shared/dynamodb.tf
+var gsiNames = []string{"GSI1", "GSI2", "GSI3", "GSI4", "GSI5"}
+
+// EnsureSchema creates the DynamoDB table and media bucket if they do not exist,
+// so integration tests do not depend on docker/localstack-init having run.
+func (tc *TestContext) EnsureSchema(ctx context.Context) error {
+	if err := tc.EnsureTable(ctx); err != nil {
+		return err
+	}
+	return tc.EnsureBucket(ctx)
+}
+
+// EnsureTable creates the single-table design (PK/SK plus GSI1-GSI5, all
+// projecting ALL attributes) and waits for it to become active.
+func (tc *TestContext) EnsureTable(ctx context.Context) error {
+	_, err := tc.DynamoDB.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(tc.TableName),
+	})
+	if err == nil {
+		return nil
+	}
+	var notFound *dynamodbtypes.ResourceNotFoundException
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("failed to describe table %s: %w", tc.TableName, err)
+	}
+
+	attributes := []dynamodbtypes.AttributeDefinition{
+		{AttributeName: aws.String("PK"), AttributeType: dynamodbtypes.ScalarAttributeTypeS},
+		{AttributeName: aws.String("SK"), AttributeType: dynamodbtypes.ScalarAttributeTypeS},
+	}
+	indexes := make([]dynamodbtypes.GlobalSecondaryIndex, 0, len(gsiNames))
+	for _, name := range gsiNames {
+		attributes = append(attributes,
+			dynamodbtypes.AttributeDefinition{AttributeName: aws.String(name + "PK"), AttributeType: dynamodbtypes.ScalarAttributeTypeS},
+			dynamodbtypes.AttributeDefinition{AttributeName: aws.String(name + "SK"), AttributeType: dynamodbtypes.ScalarAttributeTypeS},
+		)
+		indexes = append(indexes, dynamodbtypes.GlobalSecondaryIndex{
+			IndexName: aws.String(name),
+			KeySchema: []dynamodbtypes.KeySchemaElement{
+				{AttributeName: aws.String(name + "PK"), KeyType: dynamodbtypes.KeyTypeHash},
+				{AttributeName: aws.String(name + "SK"), KeyType: dynamodbtypes.KeyTypeRange},
+			},
+			Projection: &dynamodbtypes.Projection{ProjectionType: dynamodbtypes.ProjectionTypeAll},
+		})
+	}
+
+	_, err = tc.DynamoDB.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:            aws.String(tc.TableName),
+		AttributeDefinitions: attributes,
+		KeySchema: []dynamodbtypes.KeySchemaElement{
+			{AttributeName: aws.String("PK"), KeyType: dynamodbtypes.KeyTypeHash},
+			{AttributeName: aws.String("SK"), KeyType: dynamodbtypes.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: indexes,
+		BillingMode:            dynamodbtypes.BillingModePayPerRequest,
+	})
+	if err != nil {
+		var inUse *dynamodbtypes.ResourceInUseException
+		if !errors.As(err, &inUse) {
+			return fmt.Errorf("failed to create table %s: %w", tc.TableName, err)
+		}
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(tc.DynamoDB)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tc.TableName)}, tableActiveTimeout); err != nil {
+		return fmt.Errorf("table %s did not become active: %w", tc.TableName, err)
+	}
+	return nil
+}
+
+// EnsureBucket creates the media bucket if it does not exist
+func (tc *TestContext) EnsureBucket(ctx context.Context) error {
+	_, err := tc.S3.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(tc.BucketName)})
+	if err == nil {
+		return nil
+	}
+
+	_, err = tc.S3.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(tc.BucketName)})
+	if err != nil {
+		var owned *s3types.BucketAlreadyOwnedByYou
+		if !errors.As(err, &owned) {
+			return fmt.Errorf("failed to create bucket %s: %w", tc.BucketName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+//go:build integration
+
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil/builders"
+)
+
+// SeededLibrary is a small but realistic library written through the real
+// DynamoDB repository: a user, tracks across several artists and genres with
+// audio objects in S3, tags and a playlist.
+type SeededLibrary struct {
+	User     models.User
+	Tracks   []models.Track
+	Tags     []models.Tag
+	Playlist models.Playlist
+}
+
+// seedTrack describes one track of the sample library
+type seedTrack struct {
+	title, artist, album, genre string
+	year, bpm                   int
+	key, camelot                string
+	tags                        []string
+}
+
+// sampleLibrary is the track list SeedLibrary writes; titles and artists are
+// distinct enough that search assertions can target a single track.
+var sampleLibrary = []seedTrack{
+	{"Midnight Drive", "Neon Coast", "Afterglow", "Synthwave", 2019, 100, "Am", "8A", []string{"night", "driving"}},
+	{"Sunset Boulevard", "Neon Coast", "Afterglow", "Synthwave", 2019, 104, "C", "8B", []string{"driving"}},
+	{"Deep Water", "Lowtide", "Currents", "Deep House", 2021, 122, "Fm", "4A", []string{"night", "dj-set"}},
+	{"Harbor Lights", "Lowtide", "Currents", "Deep House", 2021, 124, "Cm", "5A", []string{"dj-set"}},
+	{"Paper Planes", "The Folding Chairs", "Origami", "Indie Rock", 2016, 138, "G", "9B", nil},
+	{"Slow Burn", "Amber Vale", "Embers", "Soul", 2014, 84, "Dm", "7A", []string{"night"}},
+}
+
+// SeedLibrary creates a user and the sample library for them, including S3
+// objects for every track's audio file. Everything is removed when the test
+// ends. Returns the seeded entities as stored.
+func (tc *TestContext) SeedLibrary(t *testing.T, userID string) *SeededLibrary {
+	t.Helper()
+
+	ctx := context.Background()
+	repo := repository.NewDynamoDBRepository(tc.DynamoDB, tc.TableName)
+	lib := &SeededLibrary{}
+
+	lib.User = models.User{
+		ID:           userID,
+		Email:        userID + "@test.local",
+		DisplayName:  "Seeded " + userID,
+		Role:         models.RoleSubscriber,
+		Settings:     models.DefaultUserSettings(),
+		StorageLimit: 10 * 1024 * 1024 * 1024,
+	}
+	if err := repo.CreateUser(ctx, lib.User); err != nil {
+		t.Fatalf("Failed to seed user: %v", err)
+	}
+	tc.RegisterCleanup("user", "USER#"+userID, "PROFILE")
+
+	tagCounts := make(map[string]int)
+	var tagOrder []string
+	for _, seed := range sampleLibrary {
+		trackID := uuid.New().String()
+		track := builders.NewTrackBuilder(userID, trackID).
+			WithTitle(seed.title).
+			WithArtist(seed.artist).
+			WithAlbum(seed.album).
+			WithGenre(seed.genre).
+			WithBPM(seed.bpm).
+			WithKey(seed.key, seed.camelot).
+			WithTags(seed.tags...).
+			Build()
+		track.Year = seed.year
+
+		if err := repo.CreateTrack(ctx, track); err != nil {
+			t.Fatalf("Failed to seed track %q: %v", seed.title, err)
+		}
+		tc.RegisterCleanup("track", "USER#"+userID, "TRACK#"+trackID)
+		tc.CreateTestS3Object(t, track.S3Key, []byte("ID3 seeded audio for "+seed.title))
+
+		if len(seed.tags) > 0 {
+			if err := repo.AddTagsToTrack(ctx, userID, trackID, seed.tags); err != nil {
+				t.Fatalf("Failed to tag track %q: %v", seed.title, err)
+			}
+			for _, name := range seed.tags {
+				tc.RegisterCleanup("track_tag", fmt.Sprintf("USER#%s#TRACK#%s", userID, trackID), "TAG#"+name)
+				if tagCounts[name] == 0 {
+					tagOrder = append(tagOrder, name)
+				}
+				tagCounts[name]++
+			}
+		}
+		lib.Tracks = append(lib.Tracks, track)
+	}
+
+	for _, name := range tagOrder {
+		tag := models.Tag{UserID: userID, Name: name, TrackCount: tagCounts[name]}
+		if err := repo.CreateTag(ctx, tag); err != nil {
+			t.Fatalf("Failed to seed tag %q: %v", name, err)
+		}
+		tc.RegisterCleanup("tag", "USER#"+userID, "TAG#"+name)
+		lib.Tags = append(lib.Tags, tag)
+	}
+
+	playlist := builders.NewPlaylistBuilder(userID, uuid.New().String()).
+		WithName("Late Night").
+		WithTracks(lib.Tracks[0], lib.Tracks[2], lib.Tracks[5])
+	lib.Playlist = playlist.Build()
+	if err := repo.CreatePlaylist(ctx, lib.Playlist); err != nil {
+		t.Fatalf("Failed to seed playlist: %v", err)
+	}
+	tc.RegisterCleanup("playlist", "USER#"+userID, "PLAYLIST#"+lib.Playlist.ID)
+
+	entries := playlist.Tracks()
+	trackIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		trackIDs = append(trackIDs, entry.TrackID)
+		tc.RegisterCleanup("playlist_track", "PLAYLIST#"+lib.Playlist.ID, fmt.Sprintf("POSITION#%08d", entry.Position))
+	}
+	if err := repo.AddTracksToPlaylist(ctx, lib.Playlist.ID, trackIDs, 0); err != nil {
+		t.Fatalf("Failed to seed playlist tracks: %v", err)
+	}
+
+	return lib
+}
+
+// TrackByTitle returns the seeded track with the given title
+func (lib *SeededLibrary) TrackByTitle(t *testing.T, title string) models.Track {
+	t.Helper()
+	for _, track := range lib.Tracks {
+		if track.Title == title {
+			return track
+		}
+	}
+	t.Fatalf("No seeded track titled %q", title)
+	return models.Track{}
+}
@@ -11,13 +11,18 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
+	"github.com/gvasels/personal-music-searchengine/internal/capability"
 	"github.com/gvasels/personal-music-searchengine/internal/handlers"
 	handlermw "github.com/gvasels/personal-music-searchengine/internal/handlers/middleware"
 	"github.com/gvasels/personal-music-searchengine/internal/models"
 	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/search"
 	"github.com/gvasels/personal-music-searchengine/internal/service"
 )
 
+// TestStateMachineARN is the upload pipeline ARN used with WithStepFunctions
+const TestStateMachineARN = "arn:aws:states:us-east-1:000000000000:stateMachine:upload-processor-test"
+
 // TestServerContext holds a running HTTP test server backed by LocalStack.
 type TestServerContext struct {
 	*TestContext                  // Embedded — DynamoDB, S3, Cognito clients for direct verification
@@ -27,6 +32,31 @@ type TestServerContext struct {
 	Services     *service.Services
 }
 
+// serverOptions holds the optional subsystems wired into a test server
+type serverOptions struct {
+	searchInvoker search.LambdaInvoker
+	stepFunctions service.StepFunctionsClient
+}
+
+// ServerOption enables an optional subsystem on the test server
+type ServerOption func(*serverOptions)
+
+// WithSearchLambda wires the search service to the given invoker, typically
+// InProcessLambda wrapping the Nixiesearch handler.
+func WithSearchLambda(invoker search.LambdaInvoker) ServerOption {
+	return func(o *serverOptions) {
+		o.searchInvoker = invoker
+	}
+}
+
+// WithStepFunctions makes upload confirmation start the pipeline through client,
+// typically a StepFunctionsRecorder.
+func WithStepFunctions(client service.StepFunctionsClient) ServerOption {
+	return func(o *serverOptions) {
+		o.stepFunctions = client
+	}
+}
+
 // customValidator implements echo.Validator (mirrors cmd/api/validator.go)
 type customValidator struct {
 	validator *validator.Validate
@@ -39,10 +69,16 @@ func (cv *customValidator) Validate(i interface{}) error {
 // SetupTestServer creates a full Echo HTTP server backed by LocalStack.
 // The server has all routes, middleware, and services wired identically to production.
 // Returns TestServerContext and cleanup function.
+// Search is disabled unless WithSearchLambda is given.
 // Skips the test if LocalStack is not running.
-func SetupTestServer(t *testing.T) (*TestServerContext, func()) {
+func SetupTestServer(t *testing.T, opts ...ServerOption) (*TestServerContext, func()) {
 	t.Helper()
 
+	options := &serverOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	// Reuse existing LocalStack setup for clients
 	tc, tcCleanup := SetupLocalStack(t)
 
@@ -51,14 +87,29 @@ func SetupTestServer(t *testing.T) (*TestServerContext, func()) {
 	presignClient := s3.NewPresignClient(tc.S3)
 	s3Repo := repository.NewS3Repository(tc.S3, presignClient, tc.BucketName)
 
+	stepFunctionsARN := ""
+	if options.stepFunctions != nil {
+		stepFunctionsARN = TestStateMachineARN
+	}
+
 	// Create services (same wiring as cmd/api/main.go setupEcho)
 	services := service.NewServices(
 		repo,
 		s3Repo,
 		nil, // No CloudFront signer in tests
 		tc.BucketName,
-		"", // No Step Functions ARN in tests
+		stepFunctionsARN,
 	)
+	if uploadSvc, ok := services.Upload.(*service.UploadServiceImpl); ok && options.stepFunctions != nil {
+		uploadSvc.SetStepFunctionsClient(options.stepFunctions)
+	}
+	if options.searchInvoker != nil {
+		searchClient := search.NewClient(options.searchInvoker, "nixiesearch-test")
+		services.Search = service.NewSearchService(searchClient, repo, s3Repo)
+	}
+
+	capabilities := capability.NewRegistry()
+	capabilities.Set(capability.Search, services.Search != nil, "no search Lambda wired (use WithSearchLambda)")
 
 	// Wire admin service if Cognito is available
 	if tc.UserPoolID != "" {
@@ -68,11 +119,13 @@ func SetupTestServer(t *testing.T) (*TestServerContext, func()) {
 
 	// Create handlers
 	h := handlers.NewHandlers(services)
+	h.SetCapabilities(capabilities)
 
 	// Create Echo instance (mirrors cmd/api/main.go setupEcho)
 	e := echo.New()
 	e.HideBanner = true
 	e.Validator = &customValidator{validator: validator.New()}
+	e.IPExtractor = handlermw.ClientIP(nil)
 
 	// Middleware
 	e.Use(middleware.Recover())
@@ -0,0 +1,103 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrZeroVector is returned when adding a vector with no direction
+var ErrZeroVector = errors.New("vector: zero vector")
+
+// blockRows is the number of rows scored per pass over the query
+const blockRows = 4
+
+// Index is an in-memory set of embeddings searched by cosine similarity. Vectors
+// are normalized once on Add and stored in one contiguous row-major slice, so a
+// search is a single sequential scan of dot products. An Index is not safe for
+// concurrent Add; concurrent Search calls are safe once it is built.
+type Index struct {
+	dims int
+	ids  []string
+	data []float32
+}
+
+// NewIndex returns an empty index of dims-dimensional vectors with room for
+// capacity vectors
+func NewIndex(dims, capacity int) *Index {
+	return &Index{
+		dims: dims,
+		ids:  make([]string, 0, capacity),
+		data: make([]float32, 0, dims*capacity),
+	}
+}
+
+// Dims returns the vector length the index accepts
+func (x *Index) Dims() int {
+	return x.dims
+}
+
+// Len returns the number of vectors in the index
+func (x *Index) Len() int {
+	return len(x.ids)
+}
+
+// Add stores a normalized copy of v under id
+func (x *Index) Add(id string, v []float32) error {
+	if len(v) != x.dims {
+		return fmt.Errorf("vector: %s has %d dimensions, index has %d", id, len(v), x.dims)
+	}
+	unit := Normalize(v)
+	if unit == nil {
+		return fmt.Errorf("%w: %s", ErrZeroVector, id)
+	}
+	x.ids = append(x.ids, id)
+	x.data = append(x.data, unit...)
+	return nil
+}
+
+// Search returns the k vectors most similar to query, best first. Vectors for
+// which skip returns true are left out; skip may be nil. A query of the wrong
+// length or a zero query matches nothing.
+func (x *Index) Search(query []float32, k int, skip func(id string) bool) []Match {
+	if len(query) != x.dims || k <= 0 {
+		return nil
+	}
+	q := Normalize(query)
+	if q == nil {
+		return nil
+	}
+
+	top := NewTopK(k)
+	push := func(row int, score float32) {
+		if top.Full() && float64(score) <= top.Min() {
+			return
+		}
+		id := x.ids[row]
+		if skip != nil && skip(id) {
+			return
+		}
+		top.Push(id, float64(score))
+	}
+
+	d := x.dims
+	n := len(x.ids)
+	row := 0
+	for ; row+blockRows <= n; row += blockRows {
+		base := row * d
+		s0, s1, s2, s3 := dot4(q,
+			x.data[base:base+d],
+			x.data[base+d:base+2*d],
+			x.data[base+2*d:base+3*d],
+			x.data[base+3*d:base+4*d],
+		)
+		push(row, s0)
+		push(row+1, s1)
+		push(row+2, s2)
+		push(row+3, s3)
+	}
+	for ; row < n; row++ {
+		push(row, Dot(q, x.data[row*d:(row+1)*d]))
+	}
+
+	return top.Results()
+}
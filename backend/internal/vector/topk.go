@@ -0,0 +1,96 @@
+package vector
+
+import "sort"
+
+// Match is a scored vector ID
+type Match struct {
+	ID    string
+	Score float64
+}
+
+// TopK keeps the k highest-scoring matches seen so far in a min-heap, so
+// selecting from n candidates costs O(n log k) time and O(k) memory instead
+// of scoring into a slice of n and sorting it.
+type TopK struct {
+	k    int
+	heap []Match // heap[0] is the lowest score kept
+}
+
+// NewTopK returns a selector for the k best matches
+func NewTopK(k int) *TopK {
+	if k < 0 {
+		k = 0
+	}
+	return &TopK{k: k, heap: make([]Match, 0, k)}
+}
+
+// Push offers a match, keeping it only if it is among the k best so far
+func (t *TopK) Push(id string, score float64) {
+	if len(t.heap) < t.k {
+		t.heap = append(t.heap, Match{ID: id, Score: score})
+		t.up(len(t.heap) - 1)
+		return
+	}
+	if t.k == 0 || score <= t.heap[0].Score {
+		return
+	}
+	t.heap[0] = Match{ID: id, Score: score}
+	t.down(0)
+}
+
+// Full reports whether k matches are held, after which Min is the score a
+// new match must beat
+func (t *TopK) Full() bool {
+	return len(t.heap) == t.k
+}
+
+// Min returns the lowest score held, or 0 when empty
+func (t *TopK) Min() float64 {
+	if len(t.heap) == 0 {
+		return 0
+	}
+	return t.heap[0].Score
+}
+
+// Results returns the matches in descending score order, ties by ID. The
+// selector should not be used afterwards.
+func (t *TopK) Results() []Match {
+	out := t.heap
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].ID < out[j].ID
+	})
+	t.heap = nil
+	return out
+}
+
+func (t *TopK) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if t.heap[parent].Score <= t.heap[i].Score {
+			return
+		}
+		t.heap[parent], t.heap[i] = t.heap[i], t.heap[parent]
+		i = parent
+	}
+}
+
+func (t *TopK) down(i int) {
+	n := len(t.heap)
+	for {
+		smallest := i
+		if l := 2*i + 1; l < n && t.heap[l].Score < t.heap[smallest].Score {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && t.heap[r].Score < t.heap[smallest].Score {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		t.heap[smallest], t.heap[i] = t.heap[i], t.heap[smallest]
+		i = smallest
+	}
+}
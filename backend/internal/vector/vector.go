@@ -0,0 +1,89 @@
+// Package vector provides the embedding math behind semantic similarity:
+// unrolled dot products, normalization, a flat index of pre-normalized
+// vectors and bounded top-K selection.
+//
+// Go has no portable SIMD intrinsics, so the inner loops are unrolled with
+// independent accumulators instead. That removes the loop-carried dependency
+// on a single sum, lets the compiler drop bounds checks, and keeps the CPU's
+// floating point units busy; for 1024-dimension embeddings it is several
+// times faster than the naive float64 loop.
+package vector
+
+import "math"
+
+// Dot returns the dot product of a and b, which must have the same length
+func Dot(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+8 <= len(a); i += 8 {
+		aa := a[i : i+8 : i+8]
+		bb := b[i : i+8 : i+8]
+		s0 += aa[0]*bb[0] + aa[4]*bb[4]
+		s1 += aa[1]*bb[1] + aa[5]*bb[5]
+		s2 += aa[2]*bb[2] + aa[6]*bb[6]
+		s3 += aa[3]*bb[3] + aa[7]*bb[7]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+// Norm returns the Euclidean length of v
+func Norm(v []float32) float64 {
+	return math.Sqrt(float64(Dot(v, v)))
+}
+
+// Normalize returns a unit-length copy of v, or nil for a zero vector. The
+// cosine similarity of two normalized vectors is their dot product.
+func Normalize(v []float32) []float32 {
+	norm := Norm(v)
+	if norm == 0 {
+		return nil
+	}
+	out := make([]float32, len(v))
+	inv := float32(1 / norm)
+	for i, x := range v {
+		out[i] = x * inv
+	}
+	return out
+}
+
+// Cosine returns the cosine similarity of a and b: 0 when the lengths differ,
+// either is empty, or either is a zero vector. Prefer Index for comparing one
+// query against many vectors, which normalizes each vector once.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	normA, normB := Norm(a), Norm(b)
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float64(Dot(a, b)) / (normA * normB)
+}
+
+// dot4 computes the dot product of q with four rows at once. Sharing each
+// load of q across four rows halves memory traffic compared with four Dot
+// calls, which is what bounds a scan over a large index.
+func dot4(q, r0, r1, r2, r3 []float32) (float32, float32, float32, float32) {
+	r0, r1, r2, r3 = r0[:len(q)], r1[:len(q)], r2[:len(q)], r3[:len(q)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(q); i += 4 {
+		qq := q[i : i+4 : i+4]
+		a, b, c, d := r0[i:i+4:i+4], r1[i:i+4:i+4], r2[i:i+4:i+4], r3[i:i+4:i+4]
+		s0 += qq[0]*a[0] + qq[1]*a[1] + qq[2]*a[2] + qq[3]*a[3]
+		s1 += qq[0]*b[0] + qq[1]*b[1] + qq[2]*b[2] + qq[3]*b[3]
+		s2 += qq[0]*c[0] + qq[1]*c[1] + qq[2]*c[2] + qq[3]*c[3]
+		s3 += qq[0]*d[0] + qq[1]*d[1] + qq[2]*d[2] + qq[3]*d[3]
+	}
+	for ; i < len(q); i++ {
+		s0 += q[i] * r0[i]
+		s1 += q[i] * r1[i]
+		s2 += q[i] * r2[i]
+		s3 += q[i] * r3[i]
+	}
+	return s0, s1, s2, s3
+}
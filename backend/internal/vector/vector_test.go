@@ -0,0 +1,202 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomVectors returns n deterministic vectors of dims dimensions
+func randomVectors(n, dims int) [][]float32 {
+	rng := rand.New(rand.NewSource(1))
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		vectors[i] = make([]float32, dims)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.Float32()*2 - 1
+		}
+	}
+	return vectors
+}
+
+// naiveCosine is the reference implementation
+func naiveCosine(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func TestDot(t *testing.T) {
+	// Lengths around the unroll width exercise the remainder loop
+	for _, dims := range []int{0, 1, 7, 8, 9, 1023, 1024} {
+		v := randomVectors(2, dims)
+		var want float64
+		for i := range v[0] {
+			want += float64(v[0][i]) * float64(v[1][i])
+		}
+		assert.InDelta(t, want, float64(Dot(v[0], v[1])), 1e-3, "dims=%d", dims)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	assert.InDelta(t, 1.0, Cosine([]float32{1, 2, 3}, []float32{2, 4, 6}), 1e-6)
+	assert.InDelta(t, -1.0, Cosine([]float32{1, 0}, []float32{-3, 0}), 1e-6)
+	assert.InDelta(t, 0.0, Cosine([]float32{1, 0}, []float32{0, 1}), 1e-6)
+	assert.Zero(t, Cosine(nil, nil))
+	assert.Zero(t, Cosine([]float32{1}, []float32{1, 0}))
+	assert.Zero(t, Cosine([]float32{0, 0}, []float32{1, 0}))
+
+	v := randomVectors(2, 1024)
+	assert.InDelta(t, naiveCosine(v[0], v[1]), Cosine(v[0], v[1]), 1e-5)
+}
+
+func TestNormalize(t *testing.T) {
+	unit := Normalize([]float32{3, 4})
+	assert.InDeltaSlice(t, []float32{0.6, 0.8}, unit, 1e-6)
+	assert.InDelta(t, 1.0, Norm(unit), 1e-6)
+	assert.Nil(t, Normalize([]float32{0, 0}))
+}
+
+func TestTopK(t *testing.T) {
+	top := NewTopK(3)
+	for i, score := range []float64{0.2, 0.9, 0.1, 0.5, 0.7, 0.5} {
+		top.Push(fmt.Sprintf("v%d", i), score)
+	}
+	assert.True(t, top.Full())
+	assert.Equal(t, 0.5, top.Min())
+	assert.Equal(t, []Match{{"v1", 0.9}, {"v4", 0.7}, {"v3", 0.5}}, top.Results())
+
+	few := NewTopK(5)
+	few.Push("a", 1)
+	assert.False(t, few.Full())
+	assert.Len(t, few.Results(), 1)
+
+	assert.Empty(t, NewTopK(0).Results())
+}
+
+func TestIndex_Search(t *testing.T) {
+	const n, dims, k = 1001, 64, 10 // n not a multiple of the block size
+	vectors := randomVectors(n+1, dims)
+	query, library := vectors[0], vectors[1:]
+
+	index := NewIndex(dims, n)
+	for i, v := range library {
+		require.NoError(t, index.Add(fmt.Sprintf("t%d", i), v))
+	}
+	assert.Equal(t, n, index.Len())
+
+	// Brute force reference ranking
+	type scored struct {
+		id    string
+		score float64
+	}
+	want := make([]scored, n)
+	for i, v := range library {
+		want[i] = scored{fmt.Sprintf("t%d", i), naiveCosine(query, v)}
+	}
+	sort.Slice(want, func(i, j int) bool { return want[i].score > want[j].score })
+
+	got := index.Search(query, k, nil)
+	require.Len(t, got, k)
+	for i := range got {
+		assert.Equal(t, want[i].id, got[i].ID, "rank %d", i)
+		assert.InDelta(t, want[i].score, got[i].Score, 1e-5)
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		best := got[0].ID
+		filtered := index.Search(query, k, func(id string) bool { return id == best })
+		require.Len(t, filtered, k)
+		assert.Equal(t, got[1].ID, filtered[0].ID)
+		for _, m := range filtered {
+			assert.NotEqual(t, best, m.ID)
+		}
+	})
+
+	t.Run("invalid queries match nothing", func(t *testing.T) {
+		assert.Empty(t, index.Search(query[:3], k, nil))
+		assert.Empty(t, index.Search(make([]float32, dims), k, nil))
+		assert.Empty(t, index.Search(query, 0, nil))
+	})
+}
+
+func TestIndex_Add(t *testing.T) {
+	index := NewIndex(3, 0)
+	assert.Error(t, index.Add("short", []float32{1, 2}))
+	assert.ErrorIs(t, index.Add("zero", []float32{0, 0, 0}), ErrZeroVector)
+	assert.Zero(t, index.Len())
+}
+
+// TestIndex_SearchLatency guards the target for semantic search in the Lambda:
+// top-10 over 100k 1024-dimension embeddings in well under 100ms. The bound is
+// loose to tolerate slow CI machines and the race detector.
+func TestIndex_SearchLatency(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a 400MB index")
+	}
+	const n, dims = 100000, 1024
+	index := NewIndex(dims, n)
+	rng := rand.New(rand.NewSource(1))
+	v := make([]float32, dims)
+	for i := 0; i < n; i++ {
+		for j := range v {
+			v[j] = rng.Float32()*2 - 1
+		}
+		require.NoError(t, index.Add(fmt.Sprintf("t%d", i), v))
+	}
+
+	start := time.Now()
+	matches := index.Search(v, 10, nil)
+	elapsed := time.Since(start)
+
+	require.Len(t, matches, 10)
+	assert.Equal(t, fmt.Sprintf("t%d", n-1), matches[0].ID)
+	assert.Less(t, elapsed, 500*time.Millisecond)
+	t.Logf("searched %d vectors in %s", n, elapsed)
+}
+
+// Benchmark tests
+
+func BenchmarkDot(b *testing.B) {
+	for _, dims := range []int{128, 1024} {
+		b.Run(fmt.Sprintf("dims=%d", dims), func(b *testing.B) {
+			v := randomVectors(2, dims)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Dot(v[0], v[1])
+			}
+		})
+	}
+}
+
+func BenchmarkIndex_Search(b *testing.B) {
+	for _, n := range []int{10000, 100000} {
+		b.Run(fmt.Sprintf("vectors=%d", n), func(b *testing.B) {
+			const dims = 1024
+			index := NewIndex(dims, n)
+			rng := rand.New(rand.NewSource(1))
+			v := make([]float32, dims)
+			for i := 0; i < n; i++ {
+				for j := range v {
+					v[j] = rng.Float32()*2 - 1
+				}
+				_ = index.Add(fmt.Sprintf("t%d", i), v)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				index.Search(v, 10, nil)
+			}
+		})
+	}
+}
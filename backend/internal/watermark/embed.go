@@ -0,0 +1,90 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsupportedFormat is returned for output files whose extension has no encoder
+var ErrUnsupportedFormat = errors.New("watermark: unsupported format")
+
+// Embedder mixes marks into audio files with ffmpeg
+type Embedder struct {
+	ffmpegPath string
+}
+
+// NewEmbedder returns an Embedder running the ffmpeg binary at ffmpegPath,
+// which must be an absolute path or "ffmpeg" to search PATH
+func NewEmbedder(ffmpegPath string) (*Embedder, error) {
+	if ffmpegPath != "ffmpeg" && (!filepath.IsAbs(ffmpegPath) || filepath.Clean(ffmpegPath) != ffmpegPath) {
+		return nil, fmt.Errorf("watermark: ffmpeg path %q is not absolute", ffmpegPath)
+	}
+	return &Embedder{ffmpegPath: ffmpegPath}, nil
+}
+
+// Embed writes inputPath to outputPath with token's mark looped under the
+// whole track. The output is encoded by its extension at a quality that keeps
+// the mark; tags and cover art are copied.
+func (e *Embedder) Embed(ctx context.Context, inputPath, outputPath string, token Token, style Style) error {
+	codec, err := codecArgs(filepath.Ext(outputPath))
+	if err != nil {
+		return err
+	}
+
+	mark, err := os.CreateTemp("", "mark-*.wav")
+	if err != nil {
+		return fmt.Errorf("failed to create mark file: %w", err)
+	}
+	defer os.Remove(mark.Name())
+	if err := WriteWAV(mark, Signal(token, style)); err != nil {
+		mark.Close()
+		return fmt.Errorf("failed to write mark: %w", err)
+	}
+	if err := mark.Close(); err != nil {
+		return fmt.Errorf("failed to write mark: %w", err)
+	}
+
+	args := []string{
+		"-y", "-loglevel", "error",
+		"-i", inputPath,
+		"-stream_loop", "-1", "-i", mark.Name(),
+		"-filter_complex", "[0:a][1:a]amix=inputs=2:duration=first:normalize=0[marked]",
+		"-map", "[marked]", "-map", "0:v?", "-c:v", "copy",
+		"-map_metadata", "0",
+	}
+	args = append(args, codec...)
+	args = append(args, outputPath)
+
+	cmd := exec.CommandContext(ctx, e.ffmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg error: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// codecArgs returns the encoder settings for an output extension. Lossy
+// formats are encoded at bitrates whose lowpass keeps the inaudible tones.
+func codecArgs(ext string) ([]string, error) {
+	switch strings.ToLower(ext) {
+	case ".mp3":
+		return []string{"-c:a", "libmp3lame", "-b:a", "320k"}, nil
+	case ".m4a":
+		return []string{"-c:a", "aac", "-b:a", "256k"}, nil
+	case ".ogg":
+		return []string{"-c:a", "libvorbis", "-q:a", "8"}, nil
+	case ".flac":
+		return []string{"-c:a", "flac"}, nil
+	case ".wav":
+		return []string{"-c:a", "pcm_s16le"}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, ext)
+	}
+}
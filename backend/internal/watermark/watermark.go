@@ -0,0 +1,191 @@
+// Package watermark marks a downloaded copy of a track with the token of the
+// download, so a leaked copy can be traced to the person it was handed to.
+// The token is sent as frequency-shift keyed tones mixed under the music: in
+// the midrange for an audible mark, near the top of the hearing range for an
+// inaudible one. The frame repeats every Period, so a clip of the track
+// still carries the token.
+package watermark
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ErrInvalidToken is returned for tokens that are not TokenBytes of hex
+var ErrInvalidToken = errors.New("watermark: invalid token")
+
+const (
+	// SampleRate is the rate of the generated mark; ffmpeg resamples it to
+	// the track's rate when mixing
+	SampleRate = 44100
+	// TokenBytes is the size of the token a mark carries
+	TokenBytes = 8
+	// Period is how often the frame repeats in the marked track
+	Period = 20 * time.Second
+
+	// bitSamples is the length of one bit's tone (40 ms)
+	bitSamples = SampleRate / 25
+	// rampSamples fades each tone in and out so bits do not click (5 ms)
+	rampSamples = SampleRate / 200
+	// syncWord starts every frame; the detector looks for it first
+	syncWord uint16 = 0xB5A3
+	syncBits        = 16
+	// frameBits is the sync word, the token and a CRC-8 of the token
+	frameBits = syncBits + TokenBytes*8 + 8
+)
+
+// Token is the identifier a mark carries
+type Token [TokenBytes]byte
+
+// ParseToken decodes a token written as 2*TokenBytes hex characters
+func ParseToken(s string) (Token, error) {
+	var t Token
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != TokenBytes {
+		return t, fmt.Errorf("%w: %q", ErrInvalidToken, s)
+	}
+	copy(t[:], b)
+	return t, nil
+}
+
+// String returns the token as lowercase hex
+func (t Token) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// Style sets the tones a mark is sent with
+type Style struct {
+	// Low and High are the tones of a 0 and a 1 bit, in Hz
+	Low, High float64
+	// Level is the tones' peak amplitude relative to full scale
+	Level float64
+}
+
+var (
+	// Audible is a faint warble in the midrange that listeners can hear and
+	// lossy encoders keep
+	Audible = Style{Low: 1200, High: 1800, Level: 0.03}
+	// Inaudible sits above what most adults hear. Encoders that cut the top
+	// of the spectrum, such as MP3 below 192 kbps, remove it.
+	Inaudible = Style{Low: 17500, High: 18500, Level: 0.01}
+)
+
+// Signal returns one Period of the mark for token: the frame followed by
+// silence, to be looped under the track
+func Signal(token Token, style Style) []int16 {
+	samples := make([]int16, int(Period/time.Second)*SampleRate)
+	for i, bit := range frame(token) {
+		freq := style.Low
+		if bit {
+			freq = style.High
+		}
+		start := i * bitSamples
+		for n := 0; n < bitSamples; n++ {
+			gain := 1.0
+			if n < rampSamples {
+				gain = float64(n) / rampSamples
+			} else if n >= bitSamples-rampSamples {
+				gain = float64(bitSamples-1-n) / rampSamples
+			}
+			v := style.Level * gain * math.Sin(2*math.Pi*freq*float64(n)/SampleRate)
+			samples[start+n] = int16(v * math.MaxInt16)
+		}
+	}
+	return samples
+}
+
+// Detect looks for a mark sent in style in mono samples at SampleRate and
+// returns the token of the first frame whose sync word and CRC check out
+func Detect(samples []float64, style Style) (Token, bool) {
+	frameSamples := frameBits * bitSamples
+	step := bitSamples / 4
+	for offset := 0; offset+frameSamples <= len(samples); offset += step {
+		if readBits(samples[offset:], style, syncBits) != uint64(syncWord) {
+			continue
+		}
+		payload := samples[offset+syncBits*bitSamples:]
+		var token Token
+		binary.BigEndian.PutUint64(token[:], readBits(payload, style, TokenBytes*8))
+		check := byte(readBits(payload[TokenBytes*8*bitSamples:], style, 8))
+		if check == crc8(token[:]) {
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
+// WriteWAV writes mono 16-bit samples at SampleRate as a WAV file
+func WriteWAV(w io.Writer, samples []int16) error {
+	dataSize := uint32(len(samples) * 2)
+	header := []any{
+		[4]byte{'R', 'I', 'F', 'F'}, 36 + dataSize, [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16),
+		uint16(1), uint16(1), uint32(SampleRate), uint32(SampleRate * 2), uint16(2), uint16(16), // PCM, mono, 16-bit
+		[4]byte{'d', 'a', 't', 'a'}, dataSize,
+	}
+	for _, field := range header {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	return binary.Write(w, binary.LittleEndian, samples)
+}
+
+// frame returns the bits of token's frame, most significant first
+func frame(token Token) []bool {
+	bits := make([]bool, 0, frameBits)
+	appendBits := func(v uint64, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, v>>uint(i)&1 == 1)
+		}
+	}
+	appendBits(uint64(syncWord), syncBits)
+	appendBits(binary.BigEndian.Uint64(token[:]), TokenBytes*8)
+	appendBits(uint64(crc8(token[:])), 8)
+	return bits
+}
+
+// readBits decodes n bits from the start of samples, taking each bit from
+// whichever of the style's tones is stronger in its window
+func readBits(samples []float64, style Style, n int) uint64 {
+	var v uint64
+	for i := 0; i < n; i++ {
+		window := samples[i*bitSamples : (i+1)*bitSamples]
+		v <<= 1
+		if goertzel(window, style.High) > goertzel(window, style.Low) {
+			v |= 1
+		}
+	}
+	return v
+}
+
+// goertzel returns the power of freq in samples
+func goertzel(samples []float64, freq float64) float64 {
+	coeff := 2 * math.Cos(2*math.Pi*freq/SampleRate)
+	var s1, s2 float64
+	for _, x := range samples {
+		s1, s2 = x+coeff*s1-s2, s1
+	}
+	return s1*s1 + s2*s2 - coeff*s1*s2
+}
+
+// crc8 is CRC-8 with polynomial 0x07
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,120 @@
+package watermark
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// music returns seconds of chords and noise at SampleRate, loud enough to
+// bury a mark
+func music(seconds float64) []float64 {
+	rng := rand.New(rand.NewSource(1))
+	samples := make([]float64, int(seconds*SampleRate))
+	for i := range samples {
+		t := float64(i) / SampleRate
+		samples[i] = 0.25*math.Sin(2*math.Pi*220*t) + 0.2*math.Sin(2*math.Pi*330*t) + 0.15*math.Sin(2*math.Pi*880*t) + 0.05*(rng.Float64()*2-1)
+	}
+	return samples
+}
+
+// mix adds the mark under samples starting at offset, as ffmpeg's amix does
+func mix(samples []float64, mark []int16, offset int) []float64 {
+	mixed := append([]float64(nil), samples...)
+	for i := offset; i < len(mixed); i++ {
+		mixed[i] += float64(mark[(i-offset)%len(mark)]) / math.MaxInt16
+	}
+	return mixed
+}
+
+func TestParseToken(t *testing.T) {
+	token, err := ParseToken("0123456789abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, Token{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}, token)
+	assert.Equal(t, "0123456789abcdef", token.String())
+
+	for _, s := range []string{"", "0123", "0123456789abcdeg", "0123456789abcdef00"} {
+		_, err := ParseToken(s)
+		assert.ErrorIs(t, err, ErrInvalidToken, s)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	token, err := ParseToken("5eed1e55c0ffee42")
+	require.NoError(t, err)
+
+	for name, style := range map[string]Style{"audible": Audible, "inaudible": Inaudible} {
+		t.Run(name, func(t *testing.T) {
+			// The clip starts partway into the track, before the next frame
+			marked := mix(music(25), Signal(token, style), 0)[3*SampleRate+1234:]
+
+			found, ok := Detect(marked, style)
+
+			require.True(t, ok)
+			assert.Equal(t, token, found)
+		})
+	}
+
+	t.Run("unmarked audio", func(t *testing.T) {
+		_, ok := Detect(music(5), Audible)
+		assert.False(t, ok)
+	})
+
+	t.Run("other style", func(t *testing.T) {
+		_, ok := Detect(mix(music(5), Signal(token, Audible), 0), Inaudible)
+		assert.False(t, ok)
+	})
+}
+
+func TestSignal(t *testing.T) {
+	mark := Signal(Token{}, Audible)
+
+	assert.Len(t, mark, 20*SampleRate)
+	peak := 0
+	for _, s := range mark {
+		peak = max(peak, int(s), -int(s))
+	}
+	assert.InDelta(t, Audible.Level*math.MaxInt16, peak, 50)
+	assert.Zero(t, mark[len(mark)-1], "the frame is followed by silence")
+}
+
+func TestWriteWAV(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, WriteWAV(&buf, []int16{1, -2, 3}))
+
+	data := buf.Bytes()
+	require.Len(t, data, 44+6)
+	assert.Equal(t, "RIFF", string(data[0:4]))
+	assert.Equal(t, uint32(36+6), binary.LittleEndian.Uint32(data[4:8]))
+	assert.Equal(t, "WAVEfmt ", string(data[8:16]))
+	assert.Equal(t, uint32(SampleRate), binary.LittleEndian.Uint32(data[24:28]))
+	assert.Equal(t, "data", string(data[36:40]))
+	assert.Equal(t, uint32(6), binary.LittleEndian.Uint32(data[40:44]))
+	assert.Equal(t, []byte{1, 0, 0xfe, 0xff, 3, 0}, data[44:])
+}
+
+func TestCodecArgs(t *testing.T) {
+	args, err := codecArgs(".MP3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-c:a", "libmp3lame", "-b:a", "320k"}, args)
+
+	_, err = codecArgs(".aiff")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestNewEmbedder(t *testing.T) {
+	for _, path := range []string{"ffmpeg", "/opt/bin/ffmpeg"} {
+		_, err := NewEmbedder(path)
+		assert.NoError(t, err, path)
+	}
+	for _, path := range []string{"bin/ffmpeg", "/opt/../tmp/ffmpeg", ""} {
+		_, err := NewEmbedder(path)
+		assert.Error(t, err, path)
+	}
+}
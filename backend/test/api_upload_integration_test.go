@@ -0,0 +1,116 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gvasels/personal-music-searchengine/internal/models"
+	"github.com/gvasels/personal-music-searchengine/internal/repository"
+	"github.com/gvasels/personal-music-searchengine/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_API_UploadFlow(t *testing.T) {
+	sfn := &testutil.StepFunctionsRecorder{}
+	tsc, cleanup := testutil.SetupTestServer(t, testutil.WithStepFunctions(sfn))
+	defer cleanup()
+
+	userID := "upload-flow-user"
+	tsc.SeedLibrary(t, userID)
+	repo := repository.NewDynamoDBRepository(tsc.DynamoDB, tsc.TableName)
+	ctx := context.Background()
+	audio := []byte("ID3 integration test audio payload")
+
+	var presigned models.PresignedUploadResponse
+	var upload *models.Upload
+
+	t.Run("presigned upload creates a pending upload", func(t *testing.T) {
+		resp := tsc.DoRequest(t, http.MethodPost, "/api/v1/upload/presigned",
+			testutil.AsUser(userID, models.RoleSubscriber),
+			testutil.WithJSON(models.PresignedUploadRequest{
+				FileName:    "new-song.mp3",
+				FileSize:    int64(len(audio)),
+				ContentType: "audio/mpeg",
+			}),
+		)
+		testutil.AssertStatus(t, resp, http.StatusOK)
+		presigned = testutil.DecodeJSON[models.PresignedUploadResponse](t, resp)
+		require.NotEmpty(t, presigned.UploadID)
+		require.NotEmpty(t, presigned.UploadURL)
+
+		var err error
+		upload, err = repo.GetUpload(ctx, userID, presigned.UploadID)
+		require.NoError(t, err)
+		assert.Equal(t, models.UploadStatusPending, upload.Status)
+		tsc.RegisterCleanup("upload", "USER#"+userID, "UPLOAD#"+upload.ID)
+		tsc.RegisterS3Cleanup(upload.S3Key)
+	})
+	require.NotNil(t, upload, "presigned upload failed")
+
+	t.Run("confirm before the file arrives is rejected", func(t *testing.T) {
+		resp := tsc.DoRequest(t, http.MethodPost, "/api/v1/upload/confirm",
+			testutil.AsUser(userID, models.RoleSubscriber),
+			testutil.WithJSON(models.ConfirmUploadRequest{UploadID: upload.ID}),
+		)
+		testutil.AssertStatus(t, resp, http.StatusBadRequest)
+		assert.Empty(t, sfn.Executions())
+	})
+
+	t.Run("client uploads to the presigned URL", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPut, presigned.UploadURL, bytes.NewReader(audio))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "audio/mpeg")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("confirm starts the pipeline", func(t *testing.T) {
+		resp := tsc.DoRequest(t, http.MethodPost, "/api/v1/upload/confirm",
+			testutil.AsUser(userID, models.RoleSubscriber),
+			testutil.WithJSON(models.ConfirmUploadRequest{UploadID: upload.ID}),
+		)
+		testutil.AssertStatus(t, resp, http.StatusOK)
+
+		executions := sfn.Executions()
+		require.Len(t, executions, 1)
+		assert.Equal(t, testutil.TestStateMachineARN, executions[0].StateMachineArn)
+
+		var input map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(executions[0].Input), &input))
+		assert.Equal(t, upload.ID, input["uploadId"])
+		assert.Equal(t, userID, input["userId"])
+		assert.Equal(t, upload.S3Key, input["s3Key"])
+		assert.Equal(t, tsc.BucketName, input["bucketName"])
+	})
+
+	t.Run("pipeline completion is visible through the API", func(t *testing.T) {
+		// Stand in for the processors: create the track and mark the upload done
+		track := testutil.NewTrackBuilder(userID, "uploaded-"+upload.ID).WithTitle("New Song").Build()
+		require.NoError(t, repo.CreateTrack(ctx, track))
+		tsc.RegisterCleanup("track", "USER#"+userID, "TRACK#"+track.ID)
+		require.NoError(t, repo.UpdateUploadStatus(ctx, userID, upload.ID, models.UploadStatusCompleted, "", track.ID))
+
+		resp := tsc.DoRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/uploads/%s", upload.ID),
+			testutil.AsUser(userID, models.RoleSubscriber),
+		)
+		testutil.AssertStatus(t, resp, http.StatusOK)
+		status := testutil.DecodeJSON[models.UploadResponse](t, resp)
+		assert.Equal(t, models.UploadStatusCompleted, status.Status)
+		assert.Equal(t, track.ID, status.TrackID)
+
+		resp = tsc.DoRequest(t, http.MethodGet, fmt.Sprintf("/api/v1/tracks/%s", track.ID),
+			testutil.AsUser(userID, models.RoleSubscriber),
+		)
+		testutil.AssertStatus(t, resp, http.StatusOK)
+	})
+}
@@ -33,6 +33,10 @@ aws --endpoint-url=http://${LOCALSTACK_HOST}:4566 dynamodb create-table \
         AttributeName=GSI2SK,AttributeType=S \
         AttributeName=GSI3PK,AttributeType=S \
         AttributeName=GSI3SK,AttributeType=S \
+        AttributeName=GSI4PK,AttributeType=S \
+        AttributeName=GSI4SK,AttributeType=S \
+        AttributeName=GSI5PK,AttributeType=S \
+        AttributeName=GSI5SK,AttributeType=S \
     --key-schema \
         AttributeName=PK,KeyType=HASH \
         AttributeName=SK,KeyType=RANGE \
@@ -60,6 +64,22 @@ aws --endpoint-url=http://${LOCALSTACK_HOST}:4566 dynamodb create-table \
                 {\"AttributeName\": \"GSI3SK\", \"KeyType\": \"RANGE\"}
             ],
             \"Projection\": {\"ProjectionType\": \"ALL\"}
+        },
+        {
+            \"IndexName\": \"GSI4\",
+            \"KeySchema\": [
+                {\"AttributeName\": \"GSI4PK\", \"KeyType\": \"HASH\"},
+                {\"AttributeName\": \"GSI4SK\", \"KeyType\": \"RANGE\"}
+            ],
+            \"Projection\": {\"ProjectionType\": \"ALL\"}
+        },
+        {
+            \"IndexName\": \"GSI5\",
+            \"KeySchema\": [
+                {\"AttributeName\": \"GSI5PK\", \"KeyType\": \"HASH\"},
+                {\"AttributeName\": \"GSI5SK\", \"KeyType\": \"RANGE\"}
+            ],
+            \"Projection\": {\"ProjectionType\": \"ALL\"}
         }]" \
     --billing-mode PAY_PER_REQUEST \
     --region ${AWS_REGION} \
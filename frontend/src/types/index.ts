@@ -185,6 +185,13 @@ export interface LibraryStats {
   totalAlbums: number;
   totalArtists: number;
   totalDuration: number; // in seconds
+  // Added and last played since midnight and since Monday in timeZone
+  addedToday: number;
+  addedThisWeek: number;
+  playedToday: number;
+  playedThisWeek: number;
+  addedByMonth: Record<string, number>; // "2026-10" -> tracks added that month
+  timeZone: string; // IANA zone of the user's settings, "UTC" by default
 }
 
 export type RepeatMode = 'off' | 'all' | 'one';
@@ -0,0 +1,92 @@
+# Operational alerts: repeated transcode failures, a corrupt search index,
+# uploads over quota and stuck upload pipelines are published to this topic
+resource "aws_sns_topic" "alerts" {
+  name = "${local.name_prefix}-alerts"
+}
+
+resource "aws_sns_topic_subscription" "alerts_email" {
+  count = var.alert_email == "" ? 0 : 1
+
+  topic_arn = aws_sns_topic.alerts.arn
+  protocol  = "email"
+  endpoint  = var.alert_email
+}
+
+# Publish permission for every role whose Lambdas raise alerts
+resource "aws_iam_role_policy" "lambda_alerts" {
+  for_each = {
+    lambda      = local.lambda_role_name
+    transcode   = aws_iam_role.transcode_lambda.name
+    nixiesearch = aws_iam_role.nixiesearch.name
+  }
+
+  name = "${local.name_prefix}-${each.key}-alerts"
+  role = each.value
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = "sns:Publish"
+        Resource = aws_sns_topic.alerts.arn
+      }
+    ]
+  })
+}
+
+# Scheduled check for uploads stuck in processing
+resource "aws_cloudwatch_event_rule" "pipeline_watchdog" {
+  name                = "${local.name_prefix}-pipeline-watchdog"
+  description         = "Alert on uploads that have been processing too long"
+  schedule_expression = "rate(10 minutes)"
+}
+
+resource "aws_lambda_function" "pipeline_watchdog" {
+  function_name = "${local.name_prefix}-pipeline-watchdog"
+  role          = local.lambda_role_arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.placeholder.output_path
+  source_code_hash = data.archive_file.placeholder.output_base64sha256
+
+  memory_size = 128
+  timeout     = 30
+
+  environment {
+    variables = {
+      DYNAMODB_TABLE_NAME        = local.dynamodb_table_name
+      MEDIA_BUCKET               = local.media_bucket_name
+      ALERT_TOPIC_ARN            = aws_sns_topic.alerts.arn
+      ALERT_PIPELINE_STUCK_AFTER = var.alert_pipeline_stuck_after
+    }
+  }
+
+  depends_on = [aws_cloudwatch_log_group.pipeline_watchdog]
+}
+
+resource "aws_cloudwatch_log_group" "pipeline_watchdog" {
+  name              = "/aws/lambda/${local.name_prefix}-pipeline-watchdog"
+  retention_in_days = 30
+}
+
+resource "aws_cloudwatch_event_target" "pipeline_watchdog" {
+  rule      = aws_cloudwatch_event_rule.pipeline_watchdog.name
+  target_id = "PipelineWatchdog"
+  arn       = aws_lambda_function.pipeline_watchdog.arn
+}
+
+resource "aws_lambda_permission" "eventbridge_pipeline_watchdog" {
+  statement_id  = "AllowEventBridgeWatchdog"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.pipeline_watchdog.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.pipeline_watchdog.arn
+}
+
+output "alert_topic_arn" {
+  description = "SNS topic receiving operational alerts"
+  value       = aws_sns_topic.alerts.arn
+}
@@ -1,15 +1,21 @@
 # API Gateway HTTP API with Cognito JWT Authorizer
 
+# Origins allowed to call the API; also passed to the API Lambda so its own
+# CORS middleware agrees with API Gateway
+locals {
+  api_cors_origins = ["http://localhost:5173", "http://localhost:3000", "https://d8wn3lkytn5qe.cloudfront.net", "https://music.vasels.com"]
+}
+
 resource "aws_apigatewayv2_api" "api" {
   name          = "${local.name_prefix}-api"
   protocol_type = "HTTP"
   description   = "Personal Music Search Engine API"
 
   cors_configuration {
-    allow_origins     = ["http://localhost:5173", "http://localhost:3000", "https://d8wn3lkytn5qe.cloudfront.net", "https://music.vasels.com"]
-    allow_methods     = ["GET", "POST", "PUT", "DELETE", "OPTIONS"]
-    allow_headers     = ["Authorization", "Content-Type", "X-User-ID"]
-    expose_headers    = ["X-Request-Id"]
+    allow_origins     = local.api_cors_origins
+    allow_methods     = ["GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"]
+    allow_headers     = ["Authorization", "Content-Type", "X-User-ID", "X-CSRF-Token"]
+    expose_headers    = ["X-Request-Id", "X-Total-Count"]
     max_age           = 86400
     allow_credentials = true
   }
@@ -53,6 +59,20 @@ resource "aws_apigatewayv2_stage" "default" {
     throttling_burst_limit = 100
     throttling_rate_limit  = 50
   }
+
+  # Party guests have no account; the API limits each guest's requests, these
+  # limits cap all guests together
+  route_settings {
+    route_key              = aws_apigatewayv2_route.search_party_tracks.route_key
+    throttling_burst_limit = 20
+    throttling_rate_limit  = 10
+  }
+
+  route_settings {
+    route_key              = aws_apigatewayv2_route.request_party_track.route_key
+    throttling_burst_limit = 10
+    throttling_rate_limit  = 5
+  }
 }
 
 # CloudWatch Log Group for API Gateway
@@ -120,6 +140,14 @@ resource "aws_apigatewayv2_route" "get_track" {
   authorizer_id      = aws_apigatewayv2_authorizer.cognito.id
 }
 
+resource "aws_apigatewayv2_route" "get_track_preview" {
+  api_id             = aws_apigatewayv2_api.api.id
+  route_key          = "GET /api/v1/tracks/{id}/preview"
+  target             = "integrations/${aws_apigatewayv2_integration.api_lambda.id}"
+  authorization_type = "JWT"
+  authorizer_id      = aws_apigatewayv2_authorizer.cognito.id
+}
+
 resource "aws_apigatewayv2_route" "update_track" {
   api_id             = aws_apigatewayv2_api.api.id
   route_key          = "PUT /api/v1/tracks/{id}"
@@ -538,6 +566,39 @@ resource "aws_apigatewayv2_route" "health" {
   target    = "integrations/${aws_apigatewayv2_integration.api_lambda.id}"
 }
 
+# Capability status for operators (no auth required)
+resource "aws_apigatewayv2_route" "status" {
+  api_id    = aws_apigatewayv2_api.api.id
+  route_key = "GET /status"
+  target    = "integrations/${aws_apigatewayv2_integration.api_lambda.id}"
+}
+
+# Preview share links (no auth required; the signed token is the credential)
+resource "aws_apigatewayv2_route" "open_preview" {
+  api_id    = aws_apigatewayv2_api.api.id
+  route_key = "GET /api/v1/previews/{token}"
+  target    = "integrations/${aws_apigatewayv2_integration.api_lambda.id}"
+}
+
+# Party links (no auth required; the signed token is the credential)
+resource "aws_apigatewayv2_route" "open_party" {
+  api_id    = aws_apigatewayv2_api.api.id
+  route_key = "GET /api/v1/parties/{token}"
+  target    = "integrations/${aws_apigatewayv2_integration.api_lambda.id}"
+}
+
+resource "aws_apigatewayv2_route" "search_party_tracks" {
+  api_id    = aws_apigatewayv2_api.api.id
+  route_key = "GET /api/v1/parties/{token}/tracks"
+  target    = "integrations/${aws_apigatewayv2_integration.api_lambda.id}"
+}
+
+resource "aws_apigatewayv2_route" "request_party_track" {
+  api_id    = aws_apigatewayv2_api.api.id
+  route_key = "POST /api/v1/parties/{token}/requests"
+  target    = "integrations/${aws_apigatewayv2_integration.api_lambda.id}"
+}
+
 # Lambda permission for API Gateway
 resource "aws_lambda_permission" "api_gateway" {
   statement_id  = "AllowAPIGatewayInvoke"
@@ -16,9 +16,13 @@ resource "aws_lambda_function" "bedrock_gateway" {
   memory_size = 512
   timeout     = 60
 
+  # Resolves API_KEY_SECRET and SIGNING_KEYS_SECRET at cold start
+  layers = [var.parameters_secrets_extension_layer_arn]
+
   environment {
     variables = {
-      API_KEY = aws_secretsmanager_secret.bedrock_gateway_api_key.name
+      API_KEY_SECRET      = aws_secretsmanager_secret.bedrock_gateway_api_key.name
+      SIGNING_KEYS_SECRET = aws_secretsmanager_secret.internal_signing_keys.name
     }
   }
 
@@ -106,7 +110,10 @@ resource "aws_iam_role_policy" "bedrock_gateway_secrets" {
         Action = [
           "secretsmanager:GetSecretValue"
         ]
-        Resource = aws_secretsmanager_secret.bedrock_gateway_api_key.arn
+        Resource = [
+          aws_secretsmanager_secret.bedrock_gateway_api_key.arn,
+          aws_secretsmanager_secret.internal_signing_keys.arn,
+        ]
       }
     ]
   })
@@ -119,6 +126,14 @@ resource "aws_secretsmanager_secret" "bedrock_gateway_api_key" {
   recovery_window_in_days = 7
 }
 
+# HMAC keys ("id:secret,id2:secret2", signing key first) for requests between
+# the API and the gateway; rotate by prepending a new key, then dropping the old
+resource "aws_secretsmanager_secret" "internal_signing_keys" {
+  name                    = "${local.name_prefix}/internal-signing-keys"
+  description             = "HMAC keys for signed requests between internal services"
+  recovery_window_in_days = 7
+}
+
 # API Gateway HTTP API for Bedrock Gateway
 # Security: API key validation happens in Lambda (reads from Secrets Manager)
 # Requests must include Authorization: Bearer <api-key> header
@@ -128,7 +143,7 @@ resource "aws_apigatewayv2_api" "bedrock_gateway" {
   description   = "OpenAI-compatible API gateway for Bedrock"
 
   cors_configuration {
-    allow_headers = ["Authorization", "Content-Type", "X-Request-ID"]
+    allow_headers = ["Authorization", "Content-Type", "X-Request-ID", "X-Signature-Key-Id", "X-Signature-Timestamp", "X-Signature"]
     allow_methods = ["GET", "POST", "OPTIONS"]
     # Restrict CORS to frontend CloudFront and localhost for development
     # Set frontend_cloudfront_domain variable after frontend deployment
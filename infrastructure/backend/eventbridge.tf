@@ -10,7 +10,7 @@ resource "aws_cloudwatch_event_rule" "mediaconvert_complete" {
     detail-type = ["MediaConvert Job State Change"]
     detail = {
       status = ["COMPLETE"]
-      queue  = [aws_media_convert_queue.default.arn]
+      queue  = [aws_media_convert_queue.default.arn, aws_media_convert_queue.bulk.arn]
     }
   })
 }
@@ -25,7 +25,7 @@ resource "aws_cloudwatch_event_rule" "mediaconvert_error" {
     detail-type = ["MediaConvert Job State Change"]
     detail = {
       status = ["ERROR", "CANCELED"]
-      queue  = [aws_media_convert_queue.default.arn]
+      queue  = [aws_media_convert_queue.default.arn, aws_media_convert_queue.bulk.arn]
     }
   })
 }
@@ -115,6 +115,43 @@ resource "aws_lambda_permission" "eventbridge_index_rebuild" {
   source_arn    = aws_cloudwatch_event_rule.daily_index_rebuild.arn
 }
 
+# Scheduled warmer: keeps the latency-sensitive Lambdas' configuration, clients
+# and search index loaded between requests
+locals {
+  warmed_functions = var.warmup_schedule == "" ? {} : {
+    api         = aws_lambda_function.api
+    nixiesearch = aws_lambda_function.nixiesearch
+    gateway     = aws_lambda_function.bedrock_gateway
+  }
+}
+
+resource "aws_cloudwatch_event_rule" "warmup" {
+  count = var.warmup_schedule == "" ? 0 : 1
+
+  name                = "${local.name_prefix}-warmup"
+  description         = "Send warmup events to the API, search and gateway Lambdas"
+  schedule_expression = var.warmup_schedule
+}
+
+resource "aws_cloudwatch_event_target" "warmup" {
+  for_each = local.warmed_functions
+
+  rule      = aws_cloudwatch_event_rule.warmup[0].name
+  target_id = "Warmup-${each.key}"
+  arn       = each.value.arn
+  input     = jsonencode({ warmup = true })
+}
+
+resource "aws_lambda_permission" "eventbridge_warmup" {
+  for_each = local.warmed_functions
+
+  statement_id  = "AllowEventBridgeWarmup"
+  action        = "lambda:InvokeFunction"
+  function_name = each.value.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.warmup[0].arn
+}
+
 # Outputs
 output "mediaconvert_complete_rule_arn" {
   description = "EventBridge rule ARN for MediaConvert completion"
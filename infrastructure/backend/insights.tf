@@ -0,0 +1,52 @@
+# Library insights computed on a schedule
+
+# Daily report of each library's tracks nobody has played in two years,
+# reviewed through GET /me/insights/archive
+resource "aws_cloudwatch_event_rule" "archive_insights" {
+  name                = "${local.name_prefix}-archive-insights"
+  description         = "Report each library's tracks unplayed for two years"
+  schedule_expression = "rate(1 day)"
+}
+
+resource "aws_lambda_function" "archive_insights" {
+  function_name = "${local.name_prefix}-archive-insights"
+  role          = local.lambda_role_arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.placeholder.output_path
+  source_code_hash = data.archive_file.placeholder.output_base64sha256
+
+  # The whole table is scanned in one run
+  memory_size = 256
+  timeout     = 60
+
+  environment {
+    variables = {
+      DYNAMODB_TABLE_NAME = local.dynamodb_table_name
+      MEDIA_BUCKET        = local.media_bucket_name
+    }
+  }
+
+  depends_on = [aws_cloudwatch_log_group.archive_insights]
+}
+
+resource "aws_cloudwatch_log_group" "archive_insights" {
+  name              = "/aws/lambda/${local.name_prefix}-archive-insights"
+  retention_in_days = 30
+}
+
+resource "aws_cloudwatch_event_target" "archive_insights" {
+  rule      = aws_cloudwatch_event_rule.archive_insights.name
+  target_id = "ArchiveInsights"
+  arn       = aws_lambda_function.archive_insights.arn
+}
+
+resource "aws_lambda_permission" "eventbridge_archive_insights" {
+  statement_id  = "AllowEventBridgeArchiveInsights"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.archive_insights.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.archive_insights.arn
+}
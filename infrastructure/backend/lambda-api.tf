@@ -14,16 +14,28 @@ resource "aws_lambda_function" "api" {
   memory_size = 256
   timeout     = 30
 
+  # Resolves CLOUDFRONT_SIGNING_KEY_SECRET and PREVIEW_SIGNING_KEYS_SECRET at cold start
+  layers = [var.parameters_secrets_extension_layer_arn]
+
   environment {
     variables = {
       DYNAMODB_TABLE_NAME           = local.dynamodb_table_name
       MEDIA_BUCKET                  = local.media_bucket_name
       STEP_FUNCTIONS_ARN            = aws_sfn_state_machine.upload_processor.arn
       NIXIESEARCH_FUNCTION_NAME     = aws_lambda_function.nixiesearch.function_name
+      MODERATION_FUNCTION_NAME      = var.content_moderation ? aws_lambda_function.moderation.function_name : ""
+      WATERMARK_FUNCTION_NAME       = var.download_watermarking ? aws_lambda_function.watermark.function_name : ""
       CLOUDFRONT_DOMAIN             = aws_cloudfront_distribution.media.domain_name
       CLOUDFRONT_KEY_PAIR_ID        = aws_cloudfront_public_key.signing.id
       CLOUDFRONT_SIGNING_KEY_SECRET = aws_secretsmanager_secret.cloudfront_signing_key.name
       COGNITO_USER_POOL_ID          = local.cognito_user_pool_id
+      MULTI_TENANT_MODE             = tostring(var.multi_tenant_mode)
+      TENANT_DOMAINS                = var.tenant_domains
+      CORS_ALLOW_ORIGINS            = join(",", local.api_cors_origins)
+      CORS_ALLOW_CREDENTIALS        = "true"
+      ALERT_TOPIC_ARN               = aws_sns_topic.alerts.arn
+      PREVIEW_SIGNING_KEYS_SECRET   = var.preview_links ? aws_secretsmanager_secret.preview_signing_keys.name : ""
+      PIPELINE_LOG_GROUPS           = join(",", [for group in local.upload_pipeline_log_groups : group.name])
     }
   }
 
@@ -36,6 +48,55 @@ resource "aws_cloudwatch_log_group" "api_lambda" {
   retention_in_days = 30
 }
 
+# Log groups of the upload pipeline Lambdas, searched for the admin upload
+# debug bundle (GET /api/v1/uploads/:id/debug)
+locals {
+  upload_pipeline_log_groups = [
+    aws_cloudwatch_log_group.upload_scanner,
+    aws_cloudwatch_log_group.metadata_extractor,
+    aws_cloudwatch_log_group.cover_art_processor,
+    aws_cloudwatch_log_group.track_creator,
+    aws_cloudwatch_log_group.file_mover,
+    aws_cloudwatch_log_group.transcode_start,
+    aws_cloudwatch_log_group.transcode_complete,
+    aws_cloudwatch_log_group.search_indexer,
+    aws_cloudwatch_log_group.upload_status_updater,
+  ]
+}
+
+# The upload debug bundle reads the upload's executions, its pipeline logs
+# and the versions of its objects
+resource "aws_iam_role_policy" "lambda_upload_debug" {
+  name = "${local.name_prefix}-lambda-upload-debug"
+  role = local.lambda_role_name
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = "states:ListExecutions"
+        Resource = aws_sfn_state_machine.upload_processor.arn
+      },
+      {
+        Effect   = "Allow"
+        Action   = "states:GetExecutionHistory"
+        Resource = "${replace(aws_sfn_state_machine.upload_processor.arn, ":stateMachine:", ":execution:")}:*"
+      },
+      {
+        Effect   = "Allow"
+        Action   = "logs:FilterLogEvents"
+        Resource = [for group in local.upload_pipeline_log_groups : "${group.arn}:*"]
+      },
+      {
+        Effect   = "Allow"
+        Action   = "s3:ListBucketVersions"
+        Resource = local.media_bucket_arn
+      }
+    ]
+  })
+}
+
 # Placeholder archive for initial deployment
 data "archive_file" "placeholder" {
   type        = "zip"
@@ -21,6 +21,7 @@ resource "aws_lambda_function" "nixiesearch" {
       SEARCH_INDEX_BUCKET = local.search_indexes_bucket_name
       DYNAMODB_TABLE_NAME = local.dynamodb_table_name
       INDEX_PATH          = "/tmp/nixiesearch"
+      ALERT_TOPIC_ARN     = aws_sns_topic.alerts.arn
     }
   }
 
@@ -104,6 +105,15 @@ resource "aws_lambda_permission" "nixiesearch_from_indexer" {
   source_arn    = aws_lambda_function.search_indexer.arn
 }
 
+# Allow transcode-complete Lambda to invoke Nixiesearch
+resource "aws_lambda_permission" "nixiesearch_from_transcode_complete" {
+  statement_id  = "AllowInvokeFromTranscodeComplete"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.nixiesearch.function_name
+  principal     = "lambda.amazonaws.com"
+  source_arn    = aws_lambda_function.transcode_complete.arn
+}
+
 # Allow API Lambda to invoke Nixiesearch
 resource "aws_lambda_permission" "nixiesearch_from_api" {
   statement_id  = "AllowInvokeFromAPI"
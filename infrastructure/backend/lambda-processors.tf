@@ -18,6 +18,7 @@ resource "aws_lambda_function" "metadata_extractor" {
     variables = {
       DYNAMODB_TABLE_NAME = local.dynamodb_table_name
       MEDIA_BUCKET        = local.media_bucket_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
     }
   }
 
@@ -47,6 +48,7 @@ resource "aws_lambda_function" "cover_art_processor" {
     variables = {
       DYNAMODB_TABLE_NAME = local.dynamodb_table_name
       MEDIA_BUCKET        = local.media_bucket_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
     }
   }
 
@@ -92,6 +94,7 @@ resource "aws_lambda_function" "audio_analyzer" {
       MEDIA_BUCKET        = local.media_bucket_name
       FFMPEG_PATH         = "/opt/bin/ffmpeg"
       FFPROBE_PATH        = "/opt/bin/ffprobe"
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
     }
   }
 
@@ -103,6 +106,260 @@ resource "aws_cloudwatch_log_group" "audio_analyzer" {
   retention_in_days = 30
 }
 
+# Upload Scanner Lambda (malware scan before processing; passes every upload when upload_scanner is empty)
+resource "aws_lambda_function" "upload_scanner" {
+  function_name = "${local.name_prefix}-upload-scanner"
+  role          = local.lambda_role_arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.placeholder.output_path
+  source_code_hash = data.archive_file.placeholder.output_base64sha256
+
+  memory_size = 2048 # clamscan loads its whole signature database into memory
+  timeout     = 300
+
+  ephemeral_storage {
+    size = 2048
+  }
+
+  layers = var.clamav_layer_arn == "" ? [] : [var.clamav_layer_arn]
+
+  environment {
+    variables = {
+      DYNAMODB_TABLE_NAME = local.dynamodb_table_name
+      MEDIA_BUCKET        = local.media_bucket_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
+      SCANNER             = var.upload_scanner
+      CLAMSCAN_PATH       = "/opt/bin/clamscan"
+      CLAMAV_DATABASE_DIR = "/opt/share/clamav"
+      SCANNER_URL         = var.upload_scanner_url
+    }
+  }
+
+  depends_on = [aws_cloudwatch_log_group.upload_scanner]
+}
+
+resource "aws_cloudwatch_log_group" "upload_scanner" {
+  name              = "/aws/lambda/${local.name_prefix}-upload-scanner"
+  retention_in_days = 30
+}
+
+# Moderation Lambda (checks tracks before they are made public; invoked asynchronously by the API)
+resource "aws_lambda_function" "moderation" {
+  function_name = "${local.name_prefix}-moderation"
+  role          = local.lambda_role_arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.placeholder.output_path
+  source_code_hash = data.archive_file.placeholder.output_base64sha256
+
+  memory_size = 256
+  timeout     = 120
+
+  environment {
+    variables = {
+      DYNAMODB_TABLE_NAME = local.dynamodb_table_name
+      MEDIA_BUCKET        = local.media_bucket_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
+      TRANSCRIBE_URL      = var.transcribe_url
+    }
+  }
+
+  depends_on = [aws_cloudwatch_log_group.moderation]
+}
+
+resource "aws_cloudwatch_log_group" "moderation" {
+  name              = "/aws/lambda/${local.name_prefix}-moderation"
+  retention_in_days = 30
+}
+
+# A check that still fails after Lambda's retries leaves the review pending
+resource "aws_lambda_function_event_invoke_config" "moderation" {
+  function_name                = aws_lambda_function.moderation.function_name
+  maximum_retry_attempts       = 2
+  maximum_event_age_in_seconds = 3600
+}
+
+# Allow API Lambda to invoke the moderation check
+resource "aws_lambda_permission" "moderation_from_api" {
+  statement_id  = "AllowInvokeFromAPI"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.moderation.function_name
+  principal     = "lambda.amazonaws.com"
+  source_arn    = aws_lambda_function.api.arn
+}
+
+# IAM Policy for Lambda base role to start and run moderation checks
+resource "aws_iam_role_policy" "lambda_moderation" {
+  name = "${local.name_prefix}-lambda-moderation"
+  role = local.lambda_role_name
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = "lambda:InvokeFunction"
+        Resource = aws_lambda_function.moderation.arn
+      },
+      {
+        Effect   = "Allow"
+        Action   = "bedrock:InvokeModel"
+        Resource = "arn:aws:bedrock:${var.aws_region}::foundation-model/anthropic.*"
+      }
+    ]
+  })
+}
+
+# Watermark Lambda (produces watermarked copies of protected downloads; invoked asynchronously by the API)
+resource "aws_lambda_function" "watermark" {
+  function_name = "${local.name_prefix}-watermark"
+  role          = local.lambda_role_arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.placeholder.output_path
+  source_code_hash = data.archive_file.placeholder.output_base64sha256
+
+  memory_size = 1024 # ffmpeg re-encodes the whole track
+  timeout     = 120
+
+  # Source and copy of tracks up to the 100MB upload limit
+  ephemeral_storage {
+    size = 1024
+  }
+
+  layers = [aws_lambda_layer_version.ffmpeg.arn]
+
+  environment {
+    variables = {
+      DYNAMODB_TABLE_NAME = local.dynamodb_table_name
+      MEDIA_BUCKET        = local.media_bucket_name
+      FFMPEG_PATH         = "/opt/bin/ffmpeg"
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
+    }
+  }
+
+  depends_on = [aws_cloudwatch_log_group.watermark]
+}
+
+resource "aws_cloudwatch_log_group" "watermark" {
+  name              = "/aws/lambda/${local.name_prefix}-watermark"
+  retention_in_days = 30
+}
+
+# Failures are recorded on the download token, so events are not retried
+resource "aws_lambda_function_event_invoke_config" "watermark" {
+  function_name                = aws_lambda_function.watermark.function_name
+  maximum_retry_attempts       = 0
+  maximum_event_age_in_seconds = 600
+}
+
+# Allow API Lambda to invoke the watermark processor
+resource "aws_lambda_permission" "watermark_from_api" {
+  statement_id  = "AllowInvokeFromAPI"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.watermark.function_name
+  principal     = "lambda.amazonaws.com"
+  source_arn    = aws_lambda_function.api.arn
+}
+
+# IAM Policy for Lambda base role to start watermarking
+resource "aws_iam_role_policy" "lambda_watermark" {
+  name = "${local.name_prefix}-lambda-watermark"
+  role = local.lambda_role_name
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = "lambda:InvokeFunction"
+        Resource = aws_lambda_function.watermark.arn
+      }
+    ]
+  })
+}
+
+# Collections Lambda (keeps library collection counts current from the table stream)
+resource "aws_lambda_function" "collections" {
+  function_name = "${local.name_prefix}-collections"
+  role          = local.lambda_role_arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.placeholder.output_path
+  source_code_hash = data.archive_file.placeholder.output_base64sha256
+
+  memory_size = 256
+  timeout     = 60
+
+  environment {
+    variables = {
+      DYNAMODB_TABLE_NAME = local.dynamodb_table_name
+      MEDIA_BUCKET        = local.media_bucket_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
+    }
+  }
+
+  depends_on = [aws_cloudwatch_log_group.collections]
+}
+
+resource "aws_cloudwatch_log_group" "collections" {
+  name              = "/aws/lambda/${local.name_prefix}-collections"
+  retention_in_days = 30
+}
+
+# Only track writes reach the function; the handler reports the first failed
+# record so the shard resumes there instead of re-applying the whole batch
+resource "aws_lambda_event_source_mapping" "collections" {
+  event_source_arn                   = local.dynamodb_stream_arn
+  function_name                      = aws_lambda_function.collections.arn
+  starting_position                  = "LATEST"
+  batch_size                         = 100
+  maximum_batching_window_in_seconds = 5
+  maximum_retry_attempts             = 5
+  bisect_batch_on_function_error     = true
+  function_response_types            = ["ReportBatchItemFailures"]
+
+  filter_criteria {
+    filter {
+      pattern = jsonencode({ dynamodb = { NewImage = { Type = { S = ["TRACK"] } } } })
+    }
+    filter {
+      pattern = jsonencode({ dynamodb = { OldImage = { Type = { S = ["TRACK"] } } } })
+    }
+  }
+}
+
+# IAM Policy for Lambda base role to read the table stream
+resource "aws_iam_role_policy" "lambda_dynamodb_stream" {
+  name = "${local.name_prefix}-lambda-dynamodb-stream"
+  role = local.lambda_role_name
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetRecords",
+          "dynamodb:GetShardIterator",
+          "dynamodb:DescribeStream",
+          "dynamodb:ListStreams"
+        ]
+        Resource = local.dynamodb_stream_arn
+      }
+    ]
+  })
+}
+
 # Track Creator Lambda
 resource "aws_lambda_function" "track_creator" {
   function_name = "${local.name_prefix}-track-creator"
@@ -121,6 +378,7 @@ resource "aws_lambda_function" "track_creator" {
     variables = {
       DYNAMODB_TABLE_NAME = local.dynamodb_table_name
       MEDIA_BUCKET        = local.media_bucket_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
     }
   }
 
@@ -150,6 +408,7 @@ resource "aws_lambda_function" "file_mover" {
     variables = {
       DYNAMODB_TABLE_NAME = local.dynamodb_table_name
       MEDIA_BUCKET        = local.media_bucket_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
     }
   }
 
@@ -161,6 +420,68 @@ resource "aws_cloudwatch_log_group" "file_mover" {
   retention_in_days = 30
 }
 
+# Key Migration Lambda (admin maintenance, invoked manually)
+resource "aws_lambda_function" "key_migration" {
+  function_name = "${local.name_prefix}-key-migration"
+  role          = local.lambda_role_arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.placeholder.output_path
+  source_code_hash = data.archive_file.placeholder.output_base64sha256
+
+  memory_size = 256
+  timeout     = 900
+
+  environment {
+    variables = {
+      DYNAMODB_TABLE_NAME = local.dynamodb_table_name
+      MEDIA_BUCKET        = local.media_bucket_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
+    }
+  }
+
+  depends_on = [aws_cloudwatch_log_group.key_migration]
+}
+
+resource "aws_cloudwatch_log_group" "key_migration" {
+  name              = "/aws/lambda/${local.name_prefix}-key-migration"
+  retention_in_days = 30
+}
+
+# Data Migration Lambda (admin maintenance, invoked manually)
+resource "aws_lambda_function" "data_migration" {
+  function_name = "${local.name_prefix}-data-migration"
+  role          = local.lambda_role_arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.placeholder.output_path
+  source_code_hash = data.archive_file.placeholder.output_base64sha256
+
+  memory_size = 256
+  timeout     = 900
+
+  # Checkpoints assume one run at a time
+  reserved_concurrent_executions = 1
+
+  environment {
+    variables = {
+      DYNAMODB_TABLE_NAME = local.dynamodb_table_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
+    }
+  }
+
+  depends_on = [aws_cloudwatch_log_group.data_migration]
+}
+
+resource "aws_cloudwatch_log_group" "data_migration" {
+  name              = "/aws/lambda/${local.name_prefix}-data-migration"
+  retention_in_days = 30
+}
+
 # Search Indexer Lambda
 resource "aws_lambda_function" "search_indexer" {
   function_name = "${local.name_prefix}-search-indexer"
@@ -179,6 +500,7 @@ resource "aws_lambda_function" "search_indexer" {
     variables = {
       DYNAMODB_TABLE_NAME       = local.dynamodb_table_name
       NIXIESEARCH_FUNCTION_NAME = aws_lambda_function.nixiesearch.function_name
+      MULTI_TENANT_MODE         = tostring(var.multi_tenant_mode)
     }
   }
 
@@ -207,6 +529,7 @@ resource "aws_lambda_function" "upload_status_updater" {
   environment {
     variables = {
       DYNAMODB_TABLE_NAME = local.dynamodb_table_name
+      MULTI_TENANT_MODE   = tostring(var.multi_tenant_mode)
     }
   }
 
@@ -57,6 +57,89 @@ variable "frontend_cloudfront_domain" {
   default     = ""
 }
 
+variable "multi_tenant_mode" {
+  description = "Host several isolated instances in this deployment, keyed by the custom:tenant_id claim; enable the shared layer's multi_tenant_mode too, which puts the claim in access tokens"
+  type        = bool
+  default     = false
+}
+
+variable "tenant_domains" {
+  description = "Comma-separated host=tenant pairs used to resolve the tenant from the request host"
+  type        = string
+  default     = ""
+}
+
+variable "upload_scanner" {
+  description = "Malware scanner run on uploads before processing: \"clamav\" (needs clamav_layer_arn), \"http\" (needs upload_scanner_url) or empty to skip scanning"
+  type        = string
+  default     = ""
+
+  validation {
+    condition     = contains(["", "clamav", "http"], var.upload_scanner)
+    error_message = "upload_scanner must be \"\", \"clamav\" or \"http\"."
+  }
+}
+
+variable "clamav_layer_arn" {
+  description = "Lambda layer (arm64) providing /opt/bin/clamscan and its signature database under /opt/share/clamav"
+  type        = string
+  default     = ""
+}
+
+variable "upload_scanner_url" {
+  description = "External scanning service used when upload_scanner is \"http\""
+  type        = string
+  default     = ""
+}
+
+variable "preview_links" {
+  description = "Serve preview share links; store keys in the preview-signing-keys secret before enabling"
+  type        = bool
+  default     = false
+}
+
+variable "content_moderation" {
+  description = "Hold tracks made public until the moderation Lambda has checked them"
+  type        = bool
+  default     = true
+}
+
+variable "download_watermarking" {
+  description = "Hand other users' downloads of protected tracks out as watermarked copies; without it those downloads are refused"
+  type        = bool
+  default     = true
+}
+
+variable "transcribe_url" {
+  description = "OpenAI-compatible speech-to-text endpoint used to transcribe audio for moderation; empty checks metadata and lyrics only"
+  type        = string
+  default     = ""
+}
+
+variable "parameters_secrets_extension_layer_arn" {
+  description = "AWS Parameters and Secrets Lambda Extension layer (arm64) used to resolve *_SECRET and *_PARAM config variables"
+  type        = string
+  default     = "arn:aws:lambda:us-east-1:177933569100:layer:AWS-Parameters-and-Secrets-Lambda-Extension-Arm64:12"
+}
+
+variable "warmup_schedule" {
+  description = "EventBridge schedule that sends {\"warmup\": true} to the API, search and gateway Lambdas to keep them warm; empty disables the warmer"
+  type        = string
+  default     = "rate(5 minutes)"
+}
+
+variable "alert_email" {
+  description = "Email address subscribed to the operational alerts topic; empty leaves the topic without subscribers"
+  type        = string
+  default     = ""
+}
+
+variable "alert_pipeline_stuck_after" {
+  description = "How long an upload may stay in processing before the watchdog raises a pipeline_stuck alert"
+  type        = string
+  default     = "30m"
+}
+
 # Data sources for shared resources
 data "terraform_remote_state" "shared" {
   backend = "s3"
@@ -80,6 +163,7 @@ locals {
   name_prefix                = "${var.project_name}-${var.environment}"
   dynamodb_table_name        = data.terraform_remote_state.shared.outputs.dynamodb_table_name
   dynamodb_table_arn         = data.terraform_remote_state.shared.outputs.dynamodb_table_arn
+  dynamodb_stream_arn        = data.terraform_remote_state.shared.outputs.dynamodb_stream_arn
   media_bucket_name          = data.terraform_remote_state.shared.outputs.media_bucket_name
   media_bucket_arn           = data.terraform_remote_state.shared.outputs.media_bucket_arn
   search_indexes_bucket_name = data.terraform_remote_state.shared.outputs.search_indexes_bucket_name
@@ -1,13 +1,21 @@
 # MediaConvert Infrastructure for HLS Transcoding
 # Converts uploaded audio files to adaptive bitrate HLS streams
 
-# MediaConvert Queue (on-demand pricing)
+# MediaConvert Queue (on-demand pricing) - uploads, which a user is waiting on
 resource "aws_media_convert_queue" "default" {
   name         = "${local.name_prefix}-transcoding"
   pricing_plan = "ON_DEMAND"
   status       = "ACTIVE"
 }
 
+# Queue for bulk re-transcodes, so a backfill cannot hold up uploads.
+# MediaConvert runs queues side by side; jobs within one run by priority.
+resource "aws_media_convert_queue" "bulk" {
+  name         = "${local.name_prefix}-transcoding-bulk"
+  pricing_plan = "ON_DEMAND"
+  status       = "ACTIVE"
+}
+
 # IAM Role for MediaConvert Jobs
 resource "aws_iam_role" "mediaconvert" {
   name = "${local.name_prefix}-mediaconvert"
@@ -51,7 +59,8 @@ resource "aws_iam_role_policy" "mediaconvert_s3" {
           "s3:PutObject"
         ]
         Resource = [
-          "${local.media_bucket_arn}/hls/*"
+          "${local.media_bucket_arn}/hls/*",
+          "${local.media_bucket_arn}/previews/*"
         ]
       },
       {
@@ -83,11 +92,13 @@ resource "aws_lambda_function" "transcode_start" {
 
   environment {
     variables = {
-      DYNAMODB_TABLE_NAME    = local.dynamodb_table_name
-      MEDIA_BUCKET           = local.media_bucket_name
-      MEDIACONVERT_ROLE_ARN  = aws_iam_role.mediaconvert.arn
-      MEDIACONVERT_QUEUE_ARN = aws_media_convert_queue.default.arn
-      MEDIACONVERT_ENDPOINT  = "https://mediaconvert.${var.aws_region}.amazonaws.com"
+      DYNAMODB_TABLE_NAME         = local.dynamodb_table_name
+      MEDIA_BUCKET                = local.media_bucket_name
+      MEDIACONVERT_ROLE_ARN       = aws_iam_role.mediaconvert.arn
+      MEDIACONVERT_QUEUE_ARN      = aws_media_convert_queue.default.arn
+      MEDIACONVERT_BULK_QUEUE_ARN = aws_media_convert_queue.bulk.arn
+      MEDIACONVERT_ENDPOINT       = "https://mediaconvert.${var.aws_region}.amazonaws.com"
+      MULTI_TENANT_MODE           = tostring(var.multi_tenant_mode)
     }
   }
 
@@ -113,10 +124,19 @@ resource "aws_lambda_function" "transcode_complete" {
   memory_size = 256
   timeout     = 30
 
+  # MediaConvert settings start the fallback job of codec and container failures
   environment {
     variables = {
-      DYNAMODB_TABLE_NAME = local.dynamodb_table_name
-      MEDIA_BUCKET        = local.media_bucket_name
+      DYNAMODB_TABLE_NAME         = local.dynamodb_table_name
+      MEDIA_BUCKET                = local.media_bucket_name
+      MULTI_TENANT_MODE           = tostring(var.multi_tenant_mode)
+      NIXIESEARCH_FUNCTION_NAME   = aws_lambda_function.nixiesearch.function_name
+      ALERT_TOPIC_ARN             = aws_sns_topic.alerts.arn
+      MEDIACONVERT_ROLE_ARN       = aws_iam_role.mediaconvert.arn
+      MEDIACONVERT_QUEUE_ARN      = aws_media_convert_queue.default.arn
+      MEDIACONVERT_BULK_QUEUE_ARN = aws_media_convert_queue.bulk.arn
+      MEDIACONVERT_ENDPOINT       = "https://mediaconvert.${var.aws_region}.amazonaws.com"
+      TRANSCODE_FALLBACK_RETRIES  = "1"
     }
   }
 
@@ -167,10 +187,13 @@ resource "aws_iam_role_policy" "transcode_lambda" {
         Effect = "Allow"
         Action = [
           "dynamodb:GetItem",
-          "dynamodb:UpdateItem"
+          "dynamodb:UpdateItem",
+          "dynamodb:Query"
         ]
         Resource = [
-          local.dynamodb_table_arn
+          local.dynamodb_table_arn,
+          # preview-generator lists public tracks through GSI3
+          "${local.dynamodb_table_arn}/index/*"
         ]
       },
       {
@@ -189,6 +212,23 @@ resource "aws_iam_role_policy" "transcode_lambda" {
           "iam:PassRole"
         ]
         Resource = aws_iam_role.mediaconvert.arn
+      },
+      {
+        # A track's earlier HLS output is cleared before it is transcoded again
+        Effect   = "Allow"
+        Action   = ["s3:ListBucket"]
+        Resource = local.media_bucket_arn
+      },
+      {
+        Effect   = "Allow"
+        Action   = ["s3:DeleteObject"]
+        Resource = "${local.media_bucket_arn}/hls/*"
+      },
+      {
+        # transcode-complete copies the HLS status to the search index
+        Effect   = "Allow"
+        Action   = ["lambda:InvokeFunction"]
+        Resource = aws_lambda_function.nixiesearch.arn
       }
     ]
   })
@@ -203,6 +243,11 @@ output "mediaconvert_queue_arn" {
   value       = aws_media_convert_queue.default.arn
 }
 
+output "mediaconvert_bulk_queue_arn" {
+  description = "MediaConvert queue ARN for bulk re-transcodes"
+  value       = aws_media_convert_queue.bulk.arn
+}
+
 output "transcode_start_lambda_arn" {
   description = "Transcode start Lambda ARN"
   value       = aws_lambda_function.transcode_start.arn
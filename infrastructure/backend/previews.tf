@@ -0,0 +1,78 @@
+# Preview clips of public tracks and their share links
+
+# HMAC keys ("id:secret,id2:secret2", signing key first) for preview share
+# links; rotate by prepending a new key, then dropping the old once the links
+# it signed have expired (7 days)
+resource "aws_secretsmanager_secret" "preview_signing_keys" {
+  name                    = "${local.name_prefix}/preview-signing-keys"
+  description             = "HMAC keys for preview share links"
+  recovery_window_in_days = 7
+}
+
+resource "aws_iam_role_policy" "lambda_preview_signing" {
+  name = "${local.name_prefix}-lambda-preview-signing"
+  role = local.lambda_role_name
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["secretsmanager:GetSecretValue"]
+        Resource = aws_secretsmanager_secret.preview_signing_keys.arn
+      }
+    ]
+  })
+}
+
+# Scheduled generation of missing preview clips
+resource "aws_cloudwatch_event_rule" "preview_generator" {
+  name                = "${local.name_prefix}-preview-generator"
+  description         = "Cut preview clips for public tracks that have none"
+  schedule_expression = "rate(15 minutes)"
+}
+
+resource "aws_lambda_function" "preview_generator" {
+  function_name = "${local.name_prefix}-preview-generator"
+  role          = aws_iam_role.transcode_lambda.arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.placeholder.output_path
+  source_code_hash = data.archive_file.placeholder.output_base64sha256
+
+  memory_size = 128
+  timeout     = 30
+
+  environment {
+    variables = {
+      DYNAMODB_TABLE_NAME    = local.dynamodb_table_name
+      MEDIA_BUCKET           = local.media_bucket_name
+      MEDIACONVERT_ROLE_ARN  = aws_iam_role.mediaconvert.arn
+      MEDIACONVERT_QUEUE_ARN = aws_media_convert_queue.default.arn
+      MEDIACONVERT_ENDPOINT  = "https://mediaconvert.${var.aws_region}.amazonaws.com"
+    }
+  }
+
+  depends_on = [aws_cloudwatch_log_group.preview_generator]
+}
+
+resource "aws_cloudwatch_log_group" "preview_generator" {
+  name              = "/aws/lambda/${local.name_prefix}-preview-generator"
+  retention_in_days = 30
+}
+
+resource "aws_cloudwatch_event_target" "preview_generator" {
+  rule      = aws_cloudwatch_event_rule.preview_generator.name
+  target_id = "PreviewGenerator"
+  arn       = aws_lambda_function.preview_generator.arn
+}
+
+resource "aws_lambda_permission" "eventbridge_preview_generator" {
+  statement_id  = "AllowEventBridgePreviewGenerator"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.preview_generator.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.preview_generator.arn
+}
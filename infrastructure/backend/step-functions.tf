@@ -1,20 +1,74 @@
 # Step Functions State Machine for Upload Processing
-# Orchestrates: Metadata Extraction → Cover Art Processing → Search Indexing
+# Orchestrates: Malware Scan → Metadata Extraction → Cover Art Processing → Search Indexing
 
 resource "aws_sfn_state_machine" "upload_processor" {
   name     = "${local.name_prefix}-upload-processor"
   role_arn = aws_iam_role.step_functions.arn
 
   definition = jsonencode({
-    Comment = "Process uploaded audio files: extract metadata, process cover art, index for search"
-    StartAt = "ExtractMetadata"
+    Comment = "Process uploaded audio files: scan for malware, extract metadata, process cover art, index for search"
+    StartAt = "ScanUpload"
     States = {
+      ScanUpload = {
+        Type     = "Task"
+        Resource = aws_lambda_function.upload_scanner.arn
+        Parameters = {
+          "uploadId.$" = "$.uploadId"
+          "userId.$"   = "$.userId"
+          "tenantId.$" = "$.tenantId"
+          "s3Key.$"    = "$.s3Key"
+          "fileName.$" = "$.fileName"
+          "bucketName" = local.media_bucket_name
+        }
+        ResultPath = "$.scan"
+        Retry = [
+          {
+            ErrorEquals     = ["Lambda.ServiceException", "Lambda.AWSLambdaException"]
+            IntervalSeconds = 2
+            MaxAttempts     = 3
+            BackoffRate     = 2
+          }
+        ]
+        Catch = [
+          {
+            ErrorEquals = ["States.ALL"]
+            ResultPath  = "$.error"
+            Next        = "MarkUploadFailed" # Uploads that could not be scanned are not processed
+          }
+        ]
+        Next = "CheckScanVerdict"
+      }
+
+      CheckScanVerdict = {
+        Type = "Choice"
+        Choices = [
+          {
+            Variable      = "$.scan.clean"
+            BooleanEquals = false
+            Next          = "RejectUpload"
+          }
+        ]
+        Default = "ExtractMetadata"
+      }
+
+      # The scanner has moved the file to quarantine/; record why the upload failed
+      RejectUpload = {
+        Type = "Pass"
+        Parameters = {
+          "Error"   = "MalwareDetected"
+          "Cause.$" = "$.scan.reason"
+        }
+        ResultPath = "$.error"
+        Next       = "MarkUploadFailed"
+      }
+
       ExtractMetadata = {
         Type     = "Task"
         Resource = aws_lambda_function.metadata_extractor.arn
         Parameters = {
           "uploadId.$" = "$.uploadId"
           "userId.$"   = "$.userId"
+          "tenantId.$" = "$.tenantId"
           "s3Key.$"    = "$.s3Key"
           "fileName.$" = "$.fileName"
           "bucketName" = local.media_bucket_name
@@ -44,6 +98,7 @@ resource "aws_sfn_state_machine" "upload_processor" {
         Parameters = {
           "uploadId.$" = "$.uploadId"
           "userId.$"   = "$.userId"
+          "tenantId.$" = "$.tenantId"
           "s3Key.$"    = "$.s3Key"
           "metadata.$" = "$.metadata"
           "bucketName" = local.media_bucket_name
@@ -73,12 +128,15 @@ resource "aws_sfn_state_machine" "upload_processor" {
         Parameters = {
           "uploadId.$" = "$.uploadId"
           "userId.$"   = "$.userId"
+          "tenantId.$" = "$.tenantId"
           "s3Key.$"    = "$.s3Key"
           "fileName.$" = "$.fileName"
           "metadata.$" = "$.metadata"
           "coverArt.$" = "$.coverArt"
           "bucketName" = local.media_bucket_name
           "tableName"  = local.dynamodb_table_name
+          # Set for file replacement uploads - updates the existing track instead of creating one
+          "replaceTrackId.$" = "$.replaceTrackId"
         }
         ResultPath = "$.track"
         Retry = [
@@ -105,9 +163,12 @@ resource "aws_sfn_state_machine" "upload_processor" {
         Parameters = {
           "uploadId.$"  = "$.uploadId"
           "userId.$"    = "$.userId"
+          "tenantId.$"  = "$.tenantId"
           "sourceKey.$" = "$.s3Key"
           "trackId.$"   = "$.track.trackId"
           "bucketName"  = local.media_bucket_name
+          # Archives the previous file of the track when replacing
+          "replaceTrackId.$" = "$.replaceTrackId"
         }
         ResultPath = "$.finalLocation"
         Retry = [
@@ -134,6 +195,8 @@ resource "aws_sfn_state_machine" "upload_processor" {
         Parameters = {
           "trackId.$"  = "$.track.trackId"
           "userId.$"   = "$.userId"
+          "tenantId.$" = "$.tenantId"
+          "priority.$" = "$.priority"
           "s3Key.$"    = "$.finalLocation.newKey"
           "format.$"   = "$.metadata.format"
           "bucketName" = local.media_bucket_name
@@ -164,6 +227,7 @@ resource "aws_sfn_state_machine" "upload_processor" {
         Parameters = {
           "trackId.$"  = "$.track.trackId"
           "userId.$"   = "$.userId"
+          "tenantId.$" = "$.tenantId"
           "metadata.$" = "$.metadata"
           "tableName"  = local.dynamodb_table_name
         }
@@ -192,6 +256,7 @@ resource "aws_sfn_state_machine" "upload_processor" {
         Parameters = {
           "uploadId.$" = "$.uploadId"
           "userId.$"   = "$.userId"
+          "tenantId.$" = "$.tenantId"
           "trackId.$"  = "$.track.trackId"
           "status"     = "COMPLETED"
           "tableName"  = local.dynamodb_table_name
@@ -205,6 +270,7 @@ resource "aws_sfn_state_machine" "upload_processor" {
         Parameters = {
           "uploadId.$" = "$.uploadId"
           "userId.$"   = "$.userId"
+          "tenantId.$" = "$.tenantId"
           "status"     = "FAILED"
           "error.$"    = "$.error"
           "tableName"  = local.dynamodb_table_name
@@ -259,6 +325,7 @@ resource "aws_iam_role_policy" "step_functions_lambda" {
           "lambda:InvokeFunction"
         ]
         Resource = [
+          aws_lambda_function.upload_scanner.arn,
           aws_lambda_function.metadata_extractor.arn,
           aws_lambda_function.cover_art_processor.arn,
           aws_lambda_function.track_creator.arn,
@@ -0,0 +1,78 @@
+# Cognito Lambda triggers
+
+# Pre-token-generation trigger (backend/cmd/triggers/pre-token-generation):
+# copies custom:tenant_id into access tokens, which the API requires of every
+# authenticated request in multi-tenant mode
+resource "aws_lambda_function" "pre_token_generation" {
+  count = var.multi_tenant_mode ? 1 : 0
+
+  function_name = "${local.name_prefix}-pre-token-generation"
+  role          = aws_iam_role.cognito_triggers[0].arn
+  handler       = "bootstrap"
+  runtime       = "provided.al2023"
+  architectures = ["arm64"]
+
+  filename         = data.archive_file.trigger_placeholder.output_path
+  source_code_hash = data.archive_file.trigger_placeholder.output_base64sha256
+
+  # Runs on every sign-in and token refresh
+  memory_size = 128
+  timeout     = 5
+
+  depends_on = [aws_cloudwatch_log_group.pre_token_generation]
+}
+
+resource "aws_cloudwatch_log_group" "pre_token_generation" {
+  count = var.multi_tenant_mode ? 1 : 0
+
+  name              = "/aws/lambda/${local.name_prefix}-pre-token-generation"
+  retention_in_days = 30
+}
+
+resource "aws_lambda_permission" "cognito_pre_token_generation" {
+  count = var.multi_tenant_mode ? 1 : 0
+
+  statement_id  = "AllowCognitoPreTokenGeneration"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.pre_token_generation[0].function_name
+  principal     = "cognito-idp.amazonaws.com"
+  source_arn    = aws_cognito_user_pool.main.arn
+}
+
+# The trigger only reads its event, so it needs nothing beyond its logs
+resource "aws_iam_role" "cognito_triggers" {
+  count = var.multi_tenant_mode ? 1 : 0
+
+  name = "${local.name_prefix}-cognito-triggers"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+        Action = "sts:AssumeRole"
+      }
+    ]
+  })
+}
+
+resource "aws_iam_role_policy_attachment" "cognito_triggers_logs" {
+  count = var.multi_tenant_mode ? 1 : 0
+
+  role       = aws_iam_role.cognito_triggers[0].name
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+}
+
+# Placeholder archive until the built trigger is deployed
+data "archive_file" "trigger_placeholder" {
+  type        = "zip"
+  output_path = "${path.module}/placeholder.zip"
+
+  source {
+    content  = "placeholder"
+    filename = "bootstrap"
+  }
+}
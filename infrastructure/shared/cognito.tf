@@ -43,6 +43,21 @@ resource "aws_cognito_user_pool" "main" {
     }
   }
 
+  # Tenant for multi-tenant deployments (read by the API as custom:tenant_id).
+  # Assigned by admins at user creation; never grant app clients write access to it.
+  schema {
+    name                     = "tenant_id"
+    attribute_data_type      = "String"
+    mutable                  = false
+    required                 = false
+    developer_only_attribute = false
+
+    string_attribute_constraints {
+      min_length = 2
+      max_length = 63
+    }
+  }
+
   # Account recovery
   account_recovery_setting {
     recovery_mechanism {
@@ -75,6 +90,21 @@ resource "aws_cognito_user_pool" "main" {
   admin_create_user_config {
     allow_admin_create_user_only = false
   }
+
+  # Access tokens carry no custom attributes, so a multi-tenant backend has
+  # the pre-token-generation trigger add the tenant to them. V2 triggers,
+  # which can change access tokens, need the Essentials plan.
+  user_pool_tier = var.multi_tenant_mode ? "ESSENTIALS" : "LITE"
+
+  dynamic "lambda_config" {
+    for_each = var.multi_tenant_mode ? [1] : []
+    content {
+      pre_token_generation_config {
+        lambda_arn     = aws_lambda_function.pre_token_generation[0].arn
+        lambda_version = "V2_0"
+      }
+    }
+  }
 }
 
 # Cognito User Pool Domain
@@ -49,6 +49,28 @@ resource "aws_dynamodb_table" "music_library" {
     type = "S"
   }
 
+  # GSI4 attributes - for harmonic neighbor queries
+  attribute {
+    name = "GSI4PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "GSI4SK"
+    type = "S"
+  }
+
+  # GSI5 attributes - for alphabetical browse
+  attribute {
+    name = "GSI5PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "GSI5SK"
+    type = "S"
+  }
+
   # Global Secondary Index 1 - For artist-based queries and tag lookups
   global_secondary_index {
     name            = "GSI1"
@@ -75,6 +97,28 @@ resource "aws_dynamodb_table" "music_library" {
     projection_type = "ALL"
   }
 
+  # Global Secondary Index 4 - For DJ mixing candidates by key and tempo
+  # GSI4PK = "USER#{userId}#KEY#{camelotKey|NONE}", GSI4SK = "BPM#{bucket:03d|---}#TRACK#{trackId}"
+  global_secondary_index {
+    name            = "GSI4"
+    hash_key        = "GSI4PK"
+    range_key       = "GSI4SK"
+    projection_type = "ALL"
+  }
+
+  # Global Secondary Index 5 - For alphabetical browse in the owner's collation order
+  # GSI5PK = "USER#{userId}#SORT#{TRACK|ALBUM|ARTIST}", GSI5SK = "{collation key}#{id}"
+  global_secondary_index {
+    name            = "GSI5"
+    hash_key        = "GSI5PK"
+    range_key       = "GSI5SK"
+    projection_type = "ALL"
+  }
+
+  # Stream of item changes; the collections Lambda keeps collection counts from track writes
+  stream_enabled   = true
+  stream_view_type = "NEW_AND_OLD_IMAGES"
+
   # Point-in-time recovery
   point_in_time_recovery {
     enabled = true
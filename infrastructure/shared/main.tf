@@ -59,6 +59,12 @@ variable "cognito_logout_urls" {
   default     = ["http://localhost:5173", "https://music.example.com"]
 }
 
+variable "multi_tenant_mode" {
+  description = "Put the custom:tenant_id attribute in access tokens for a multi-tenant backend; keep in step with the backend layer's variable"
+  type        = bool
+  default     = false
+}
+
 locals {
   name_prefix = "${var.project_name}-${var.environment}"
 }
@@ -88,6 +94,10 @@ output "dynamodb_table_arn" {
   value = aws_dynamodb_table.music_library.arn
 }
 
+output "dynamodb_stream_arn" {
+  value = aws_dynamodb_table.music_library.stream_arn
+}
+
 output "media_bucket_name" {
   value = aws_s3_bucket.media.id
 }
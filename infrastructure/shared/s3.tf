@@ -91,6 +91,65 @@ resource "aws_s3_bucket_lifecycle_configuration" "media" {
     }
   }
 
+  # Rule for replaced track files - kept for a 30 day grace period
+  # (matches models.ReplacedFileGracePeriod)
+  rule {
+    id     = "expire-replaced-files"
+    status = "Enabled"
+
+    filter {
+      prefix = "archive/"
+    }
+
+    expiration {
+      days = 30
+    }
+  }
+
+  # Rule for uploads flagged by the malware scan - kept 90 days for review
+  rule {
+    id     = "expire-quarantined-uploads"
+    status = "Enabled"
+
+    filter {
+      prefix = "quarantine/"
+    }
+
+    expiration {
+      days = 90
+    }
+  }
+
+  # Rule for watermarked download copies - removed after a day
+  # (matches models.WatermarkedFileLifetime)
+  rule {
+    id     = "expire-watermarked-copies"
+    status = "Enabled"
+
+    filter {
+      prefix = "watermarked/"
+    }
+
+    expiration {
+      days = 1
+    }
+  }
+
+  # Rule for the files of trashed tracks - removed after a day, well past
+  # the undo window (models.UndoWindow)
+  rule {
+    id     = "expire-trashed-files"
+    status = "Enabled"
+
+    filter {
+      prefix = "trash/"
+    }
+
+    expiration {
+      days = 1
+    }
+  }
+
   # Transition all objects to Intelligent-Tiering after upload
   rule {
     id     = "intelligent-tiering-transition"